@@ -0,0 +1,191 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/autocomplete"
+)
+
+const (
+	// autocompleteTimeout bounds the update of one asset's autocomplete entry
+	autocompleteTimeout = 10 * time.Second
+	// autocompleteBackfillBatch is the number of assets read at once while
+	// backfilling the autocomplete index
+	autocompleteBackfillBatch = 500
+)
+
+// ErrAutocompleteUnavailable is returned by AutocompleteAssets without an index
+var ErrAutocompleteUnavailable = errors.New("asset autocomplete unavailable")
+
+// AssetSuggestion is an asset suggested for the name typed in a search box
+type AssetSuggestion struct {
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Status       model.AssetStatus `json:"status"`
+	ThumbnailURL *string           `json:"thumbnailURL"`
+}
+
+// AutocompleteAssets returns up to limit assets of projectID whose name starts
+// with prefix, ignoring case, in the order of their names. The index only finds
+// the names; statuses and thumbnails are read from the database, so that they
+// are current and row-level security applies.
+func (r *Resolver) AutocompleteAssets(ctx context.Context, projectID, prefix string, limit int) ([]AssetSuggestion, error) {
+	if r.Autocomplete == nil {
+		return nil, ErrAutocompleteUnavailable
+	}
+	if err := r.authorize(ctx, projectID, ActionView); err != nil {
+		return nil, err
+	}
+
+	ids, err := r.Autocomplete.Search(ctx, projectID, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []AssetSuggestion{}, nil
+	}
+
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT a.id, a.name, a.status, a.thumbnail_url
+		FROM assets a
+		JOIN boards b ON a.board_id = b.id
+		WHERE a.id = ANY($1) AND b.project_id = $2
+			AND a.deleted_at IS NULL AND b.deleted_at IS NULL
+		ORDER BY lower(a.name), a.id
+	`, pq.Array(ids), projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query suggested assets: %w", err)
+	}
+	defer rows.Close()
+
+	// The index matches prefixes longer than its entries on their start only
+	normalized := autocomplete.Normalize(prefix)
+	suggestions := []AssetSuggestion{}
+	for rows.Next() {
+		var suggestion AssetSuggestion
+		if err := rows.Scan(&suggestion.ID, &suggestion.Name, &suggestion.Status, &suggestion.ThumbnailURL); err != nil {
+			return nil, fmt.Errorf("failed to scan suggested asset: %w", err)
+		}
+		if strings.HasPrefix(autocomplete.Normalize(suggestion.Name), normalized) {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query suggested assets: %w", err)
+	}
+
+	return suggestions, nil
+}
+
+// ReindexAsset updates the autocomplete entry of the asset assetID in the
+// background, after it was created, renamed, restored or deleted
+func (r *Resolver) ReindexAsset(assetID string) {
+	if r.Autocomplete == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), autocompleteTimeout)
+		defer cancel()
+
+		if err := r.reindexAsset(ctx, assetID); err != nil {
+			log.Printf("Failed to update autocomplete entry of asset %s: %v", assetID, err)
+		}
+	}()
+}
+
+// reindexAsset indexes the current name of an asset, or removes its entry once
+// the asset is deleted. It runs outside of a user transaction, as the request
+// that changed the asset may be over by then.
+func (r *Resolver) reindexAsset(ctx context.Context, assetID string) error {
+	var projectID, name string
+	var deleted bool
+	err := r.DB.Writer().QueryRowContext(ctx, `
+		SELECT b.project_id, a.name, a.deleted_at IS NOT NULL OR b.deleted_at IS NOT NULL
+		FROM assets a JOIN boards b ON b.id = a.board_id
+		WHERE a.id = $1
+	`, assetID).Scan(&projectID, &name, &deleted)
+	if err == sql.ErrNoRows || (err == nil && deleted) {
+		return r.Autocomplete.Remove(ctx, assetID)
+	} else if err != nil {
+		return fmt.Errorf("failed to query asset: %w", err)
+	}
+
+	return r.Autocomplete.Add(ctx, projectID, assetID, name)
+}
+
+// BackfillAutocomplete indexes every asset that is not deleted, unless the index
+// was backfilled since Redis last lost its data. Assets are only indexed once
+// they change otherwise, so the index misses the older ones until then.
+// Instances starting together may both backfill it, as indexing an asset
+// replaces its entries.
+func (r *Resolver) BackfillAutocomplete(ctx context.Context) error {
+	if r.Autocomplete == nil {
+		return nil
+	}
+	backfilled, err := r.Autocomplete.Backfilled(ctx)
+	if err != nil || backfilled {
+		return err
+	}
+
+	indexed := 0
+	after := uuid.Nil.String()
+	for {
+		rows, err := r.DB.Reader().QueryContext(ctx, `
+			SELECT a.id, b.project_id, a.name
+			FROM assets a JOIN boards b ON b.id = a.board_id
+			WHERE a.deleted_at IS NULL AND b.deleted_at IS NULL AND a.id > $1
+			ORDER BY a.id
+			LIMIT $2
+		`, after, autocompleteBackfillBatch)
+		if err != nil {
+			return fmt.Errorf("failed to query assets: %w", err)
+		}
+
+		type indexedAsset struct{ id, projectID, name string }
+		var batch []indexedAsset
+		for rows.Next() {
+			var asset indexedAsset
+			if err := rows.Scan(&asset.id, &asset.projectID, &asset.name); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan asset: %w", err)
+			}
+			batch = append(batch, asset)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to read assets: %w", err)
+		}
+
+		for _, asset := range batch {
+			if err := r.Autocomplete.Add(ctx, asset.projectID, asset.id, asset.name); err != nil {
+				return err
+			}
+		}
+		indexed += len(batch)
+
+		if len(batch) < autocompleteBackfillBatch {
+			break
+		}
+		after = batch[len(batch)-1].id
+	}
+
+	log.Printf("Backfilled the autocomplete index with %d assets", indexed)
+	return r.Autocomplete.MarkBackfilled(ctx)
+}
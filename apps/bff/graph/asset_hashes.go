@@ -0,0 +1,91 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+// duplicateWarningExtension is the response extension listing uploads that
+// returned an existing asset
+const duplicateWarningExtension = "duplicateWarning"
+
+// DuplicateWarning tells the client that an upload returned an existing asset of
+// the project with the same content instead of creating a new one
+type DuplicateWarning struct {
+	Message     string `json:"message"`
+	AssetID     string `json:"assetId"`
+	BoardID     string `json:"boardId"`
+	ContentHash string `json:"contentHash"`
+}
+
+// queueContentHash hashes the content of an uploaded asset in the background
+func (r *Resolver) queueContentHash(job nats.AssetHashJob) {
+	if r.NatsConn == nil {
+		return
+	}
+
+	if err := r.NatsConn.PublishAssetHashJob(job); err != nil {
+		log.Printf("Failed to queue content hash of asset %s: %v", job.AssetID, err)
+	}
+}
+
+// findDuplicateAsset returns the oldest asset in the project of boardID whose
+// file at url is already hashed, or nil if there is none. Uploads never wait for
+// a download: other copies of a file are found by the hash workers later.
+func findDuplicateAsset(ctx context.Context, tx *sql.Tx, boardID, url string) (*model.Asset, string, error) {
+	var asset model.Asset
+	var approvedBy sql.NullString
+	var contentHash string
+	err := tx.QueryRowContext(ctx, `
+		SELECT a.id, a.name, a.type, a.url, a.status, a.board_id, a.approved_by, a.approved_at, a.platform_rejection_reason, a.rejection_reason, a.duplicate_of, a.scheduled_at, a.thumbnail_url, a.created_at, a.updated_at, a.content_hash
+		FROM assets a
+		JOIN boards b ON a.board_id = b.id
+		WHERE a.content_hash = (
+				SELECT h.content_hash FROM assets h
+				WHERE h.url = $1 AND h.content_hash IS NOT NULL
+				LIMIT 1
+			)
+			AND a.deleted_at IS NULL
+			AND b.project_id = (SELECT project_id FROM boards WHERE id = $2)
+		ORDER BY a.created_at
+		LIMIT 1
+	`, url, boardID).Scan(
+		&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
+		&asset.BoardID, &approvedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+		&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt, &contentHash,
+	)
+	if err == sql.ErrNoRows {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query duplicate asset: %w", err)
+	}
+	if approvedBy.Valid {
+		asset.ApprovedBy = &model.User{ID: approvedBy.String}
+	}
+
+	return &asset, contentHash, nil
+}
+
+// addDuplicateWarning adds a warning to the duplicateWarning extension of the
+// response. Every upload of an operation that returned an existing asset is listed.
+func addDuplicateWarning(ctx context.Context, warning DuplicateWarning) {
+	// Resolvers called outside of an operation have no response to extend
+	if !graphql.HasOperationContext(ctx) {
+		return
+	}
+
+	if warnings, ok := graphql.GetExtension(ctx, duplicateWarningExtension).(*[]DuplicateWarning); ok {
+		*warnings = append(*warnings, warning)
+		return
+	}
+
+	graphql.RegisterExtension(ctx, duplicateWarningExtension, &[]DuplicateWarning{warning})
+}
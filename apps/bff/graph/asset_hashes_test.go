@@ -0,0 +1,36 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/stretchr/testify/assert"
+)
+
+// operationContext returns a context like the one resolvers get while an
+// operation is executed
+func operationContext() context.Context {
+	ctx := graphql.WithOperationContext(context.Background(), &graphql.OperationContext{})
+	return graphql.WithResponseContext(ctx, graphql.DefaultErrorPresenter, graphql.DefaultRecover)
+}
+
+func TestAddDuplicateWarning(t *testing.T) {
+	ctx := operationContext()
+
+	first := DuplicateWarning{Message: "duplicate", AssetID: "asset-1", BoardID: "board-1", ContentHash: "abc"}
+	second := DuplicateWarning{Message: "duplicate", AssetID: "asset-2", BoardID: "board-1", ContentHash: "def"}
+	addDuplicateWarning(ctx, first)
+	addDuplicateWarning(ctx, second)
+
+	warnings, ok := graphql.GetExtension(ctx, "duplicateWarning").(*[]DuplicateWarning)
+	if assert.True(t, ok) {
+		assert.Equal(t, []DuplicateWarning{first, second}, *warnings)
+	}
+}
+
+func TestAddDuplicateWarning_OutsideOperation(t *testing.T) {
+	assert.NotPanics(t, func() {
+		addDuplicateWarning(context.Background(), DuplicateWarning{AssetID: "asset-1"})
+	})
+}
@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/quality"
+)
+
+// qualityScoreTimeout bounds the scoring of one asset's copy in the background
+const qualityScoreTimeout = 30 * time.Second
+
+// qualityScore returns the quality score of the copy of asset. The asset has no
+// copy field of its own, so its name, the title it is deployed with, is scored.
+func (r *Resolver) qualityScore(ctx context.Context, asset *model.Asset) (*model.QualityScore, error) {
+	score, err := r.Quality.Score(ctx, asset.ID, asset.UpdatedAt, asset.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to score asset quality: %w", err)
+	}
+
+	return qualityScoreToModel(score), nil
+}
+
+// scoreQuality scores the copy of an uploaded asset in the background, caching
+// the score for the qualityScore field and sending it to the subscribers of the
+// asset's board
+func (r *Resolver) scoreQuality(asset model.Asset) {
+	if r.Quality == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), qualityScoreTimeout)
+		defer cancel()
+
+		score, err := r.qualityScore(ctx, &asset)
+		if err != nil {
+			log.Printf("Failed to score quality of asset %s: %v", asset.ID, err)
+			return
+		}
+
+		if r.NatsConn != nil {
+			update := &model.AssetQualityScore{AssetID: asset.ID, BoardID: asset.BoardID, Score: score}
+			if err := r.NatsConn.PublishAssetQualityScore(asset.BoardID, update); err != nil {
+				log.Printf("Failed to publish asset quality score: %v", err)
+			}
+		}
+	}()
+}
+
+func qualityScoreToModel(score *quality.Score) *model.QualityScore {
+	return &model.QualityScore{
+		Readability:    score.Readability,
+		Sentiment:      score.Sentiment,
+		KeywordDensity: score.KeywordDensity,
+		OverallScore:   score.OverallScore,
+	}
+}
@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+// thumbnailTimeout bounds the generation and storage of one asset's thumbnail
+const thumbnailTimeout = time.Minute
+
+// generateThumbnail generates the thumbnail of an uploaded image asset in the
+// background. Subscribers to the asset's status are sent the asset again once
+// its thumbnail URL is stored.
+func (r *Resolver) generateThumbnail(asset model.Asset) {
+	if r.Thumbnails == nil || asset.URL == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), thumbnailTimeout)
+		defer cancel()
+
+		thumbnailURL, err := r.Thumbnails.GenerateThumbnail(ctx, asset.ID, *asset.URL)
+		if err != nil {
+			log.Printf("Failed to generate thumbnail of asset %s: %v", asset.ID, err)
+			return
+		}
+
+		updated, err := r.saveThumbnailURL(ctx, asset.ID, thumbnailURL)
+		if err != nil {
+			log.Printf("Failed to save thumbnail of asset %s: %v", asset.ID, err)
+			return
+		}
+
+		if r.NatsConn != nil {
+			if err := r.NatsConn.PublishAssetUpdate(updated.BoardID, updated); err != nil {
+				log.Printf("Failed to publish asset update: %v", err)
+			}
+		}
+	}()
+}
+
+// saveThumbnailURL stores the thumbnail URL of an asset and returns the asset. It
+// runs outside of a user transaction, as the uploader's request is over by then.
+func (r *Resolver) saveThumbnailURL(ctx context.Context, assetID, thumbnailURL string) (*model.Asset, error) {
+	var asset model.Asset
+	var approvedBy sql.NullString
+	err := r.DB.Writer().QueryRowContext(ctx, `
+		UPDATE assets SET thumbnail_url = $2
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING id, name, type, url, status, board_id, approved_by, approved_at, platform_rejection_reason, rejection_reason, duplicate_of, scheduled_at, thumbnail_url, created_at, updated_at
+	`, assetID, thumbnailURL).Scan(
+		&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
+		&asset.BoardID, &approvedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+		&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("asset not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to update asset: %w", err)
+	}
+	if approvedBy.Valid {
+		asset.ApprovedBy = &model.User{ID: approvedBy.String}
+	}
+
+	return &asset, nil
+}
@@ -0,0 +1,92 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+// maxRejectionReasonLength is the longest reason a reviewer may give for
+// rejecting an asset
+const maxRejectionReasonLength = 1000
+
+// validateRejectionReason checks the reason given for rejecting an asset
+func validateRejectionReason(reason string) error {
+	if reason == "" {
+		return fmt.Errorf("reason is required")
+	}
+	if len([]rune(reason)) > maxRejectionReasonLength {
+		return fmt.Errorf("reason must be at most %d characters", maxRejectionReasonLength)
+	}
+	return nil
+}
+
+// transitionAsset moves the asset assetID to the status to, if the status
+// machine allows it from its current status, and publishes the change to the
+// asset's board. assignments are further column assignments made along with the
+// status, such as "approved_by = NULL", whose parameters from $4 on are args. It
+// returns the changed asset and its previous status.
+func (r *Resolver) transitionAsset(ctx context.Context, assetID string, to model.AssetStatus, assignments string, args ...interface{}) (*model.Asset, model.AssetStatus, error) {
+	tx, _, err := r.userTx(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	defer tx.Rollback()
+
+	var currentStatus model.AssetStatus
+	err = tx.QueryRowContext(ctx, `SELECT status FROM assets WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, assetID).Scan(&currentStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "", fmt.Errorf("asset not found")
+		}
+		return nil, "", fmt.Errorf("failed to query asset: %w", err)
+	}
+
+	if err := assetStatusMachine.ValidateTransition(currentStatus, to); err != nil {
+		return nil, "", err
+	}
+
+	if assignments != "" {
+		assignments = ", " + assignments
+	}
+
+	var asset model.Asset
+	var approvedBy sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		UPDATE assets
+		SET status = $1, updated_at = $2`+assignments+`
+		WHERE id = $3
+		RETURNING id, name, type, url, status, board_id, approved_by, approved_at, platform_rejection_reason, rejection_reason, duplicate_of, scheduled_at, thumbnail_url, created_at, updated_at
+	`, append([]interface{}{to, time.Now(), assetID}, args...)...).Scan(
+		&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
+		&asset.BoardID, &approvedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+		&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt,
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to update asset status: %w", err)
+	}
+	if approvedBy.Valid {
+		asset.ApprovedBy = &model.User{ID: approvedBy.String}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, "", fmt.Errorf("failed to commit asset status change: %w", err)
+	}
+
+	if r.Cache != nil {
+		r.Cache.InvalidateAsset(assetID)
+	}
+
+	if err := r.NatsConn.PublishBoardUpdate(asset.BoardID, &asset); err != nil {
+		log.Printf("Failed to publish board update: %v", err)
+	}
+	if err := r.NatsConn.PublishAssetUpdate(asset.BoardID, &asset); err != nil {
+		log.Printf("Failed to publish asset update: %v", err)
+	}
+
+	return &asset, currentStatus, nil
+}
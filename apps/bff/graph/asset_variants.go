@@ -0,0 +1,21 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxVariantGroupLength bounds the name of the A/B test an asset is a variant in
+const maxVariantGroupLength = 100
+
+// validateVariantGroup checks that variantGroup names an A/B test
+func validateVariantGroup(variantGroup string) error {
+	if strings.TrimSpace(variantGroup) == "" {
+		return fmt.Errorf("variantGroup must not be empty")
+	}
+	if utf8.RuneCountInString(variantGroup) > maxVariantGroupLength {
+		return fmt.Errorf("variantGroup must be at most %d characters", maxVariantGroupLength)
+	}
+	return nil
+}
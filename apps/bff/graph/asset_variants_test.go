@@ -0,0 +1,17 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateVariantGroup(t *testing.T) {
+	assert.NoError(t, validateVariantGroup("spring-trail-headlines"))
+	assert.NoError(t, validateVariantGroup(strings.Repeat("é", maxVariantGroupLength)))
+
+	assert.EqualError(t, validateVariantGroup(""), "variantGroup must not be empty")
+	assert.EqualError(t, validateVariantGroup("  "), "variantGroup must not be empty")
+	assert.EqualError(t, validateVariantGroup(strings.Repeat("a", maxVariantGroupLength+1)), "variantGroup must be at most 100 characters")
+}
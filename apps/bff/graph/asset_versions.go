@@ -0,0 +1,238 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+// assetVersionColumns are the columns read by scanAssetVersion
+const assetVersionColumns = `id, asset_id, version_number, name, url, status, approved_by, approved_at, changed_by, changed_at, change_reason`
+
+// scanAssetVersion scans an asset_versions row selected as assetVersionColumns
+func scanAssetVersion(row rowScanner) (*model.AssetVersion, error) {
+	var version model.AssetVersion
+	err := row.Scan(
+		&version.ID, &version.AssetID, &version.VersionNumber, &version.Name, &version.URL, &version.Status,
+		&version.ApprovedBy, &version.ApprovedAt, &version.ChangedBy, &version.ChangedAt, &version.ChangeReason,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// assetHistory returns the versions of the asset assetID, newest first
+func assetHistory(ctx context.Context, tx *sql.Tx, assetID string) ([]*model.AssetVersion, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+assetVersionColumns+`
+		FROM asset_versions
+		WHERE asset_id = $1
+		ORDER BY version_number DESC
+	`, assetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query asset history: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []*model.AssetVersion{}
+	for rows.Next() {
+		version, err := scanAssetVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan asset version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate asset history: %w", err)
+	}
+
+	return versions, nil
+}
+
+// assetVersion returns the version versionNumber of the asset assetID
+func assetVersion(ctx context.Context, tx *sql.Tx, assetID string, versionNumber int) (*model.AssetVersion, error) {
+	version, err := scanAssetVersion(tx.QueryRowContext(ctx, `
+		SELECT `+assetVersionColumns+`
+		FROM asset_versions
+		WHERE asset_id = $1 AND version_number = $2
+	`, assetID, versionNumber))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("version %d of asset not found", versionNumber)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query asset version: %w", err)
+	}
+	return version, nil
+}
+
+// assetField is a compared field of an asset version, with a nil value when unset
+type assetField struct {
+	name  string
+	value *string
+}
+
+// assetVersionFields returns the compared fields of version
+func assetVersionFields(version *model.AssetVersion) []assetField {
+	text := func(value string) *string { return &value }
+
+	var status, approvedAt *string
+	if version.Status != nil {
+		status = text(version.Status.String())
+	}
+	if version.ApprovedAt != nil {
+		approvedAt = text(version.ApprovedAt.UTC().Format(time.RFC3339))
+	}
+
+	return []assetField{
+		{name: "name", value: text(version.Name)},
+		{name: "url", value: version.URL},
+		{name: "status", value: status},
+		{name: "approvedBy", value: version.ApprovedBy},
+		{name: "approvedAt", value: approvedAt},
+	}
+}
+
+// diffAssetVersions compares the fields of two versions of an asset
+func diffAssetVersions(from, to *model.AssetVersion) *model.AssetDiff {
+	diff := &model.AssetDiff{
+		AssetID:     from.AssetID,
+		FromVersion: from.VersionNumber,
+		ToVersion:   to.VersionNumber,
+		Added:       []*model.AssetFieldChange{},
+		Removed:     []*model.AssetFieldChange{},
+		Changed:     []*model.AssetFieldChange{},
+	}
+
+	toFields := assetVersionFields(to)
+	for i, field := range assetVersionFields(from) {
+		oldValue, newValue := field.value, toFields[i].value
+		change := &model.AssetFieldChange{Field: field.name, OldValue: oldValue, NewValue: newValue}
+
+		switch {
+		case oldValue == nil && newValue != nil:
+			diff.Added = append(diff.Added, change)
+		case oldValue != nil && newValue == nil:
+			diff.Removed = append(diff.Removed, change)
+		case oldValue != nil && newValue != nil && *oldValue != *newValue:
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+
+	return diff
+}
+
+// revertAsset copies the contents of a version of the asset assetID back to the
+// asset and returns the asset before and after. The trigger on assets records
+// the replaced contents as a new version, with the revert as its reason.
+func revertAsset(ctx context.Context, tx *sql.Tx, assetID string, versionNumber int, now time.Time) (*model.Asset, *model.Asset, error) {
+	before, err := liveAsset(ctx, tx, assetID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	version, err := assetVersion(ctx, tx, assetID, versionNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reason := fmt.Sprintf("reverted to version %d", versionNumber)
+	if _, err := tx.ExecContext(ctx, `SELECT set_config('app.asset_change_reason', $1, true)`, reason); err != nil {
+		return nil, nil, fmt.Errorf("failed to set change reason: %w", err)
+	}
+
+	status, approver, approvedAt := revertedApproval(before, version)
+
+	var after model.Asset
+	var approvedBy sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		UPDATE assets
+		SET name = $1, url = $2, status = $3, approved_by = $4, approved_at = $5, updated_at = $6
+		WHERE id = $7
+		RETURNING id, name, type, url, status, board_id, approved_by, approved_at, platform_rejection_reason, rejection_reason, duplicate_of, scheduled_at, thumbnail_url, created_at, updated_at
+	`, version.Name, version.URL, status, approver, approvedAt, now, assetID).Scan(
+		&after.ID, &after.Name, &after.Type, &after.URL, &after.Status,
+		&after.BoardID, &approvedBy, &after.ApprovedAt, &after.PlatformRejectionReason, &after.RejectionReason, &after.DuplicateOfID,
+		&after.ScheduledAt, &after.ThumbnailURL, &after.CreatedAt, &after.UpdatedAt,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to revert asset: %w", err)
+	}
+	if approvedBy.Valid {
+		after.ApprovedBy = &model.User{ID: approvedBy.String}
+	}
+
+	return before, &after, nil
+}
+
+// publishRestoredApproval hands an asset whose approval a revert restored to the
+// connectors service, as approving it does. Scheduled assets are published by
+// RunScheduledDeployments once their time has come.
+func (r *Resolver) publishRestoredApproval(ctx context.Context, asset *model.Asset, prevStatus model.AssetStatus) error {
+	if asset.ScheduledAt != nil {
+		return nil
+	}
+
+	// The project owner's credentials are read, so outside of the user transaction
+	d := dueDeployment{asset: *asset}
+	err := r.DB.Writer().QueryRowContext(ctx, `
+		SELECT a.variant_group, p.id, p.owner_id
+		FROM assets a
+		JOIN boards b ON a.board_id = b.id
+		JOIN projects p ON b.project_id = p.id
+		WHERE a.id = $1
+	`, asset.ID).Scan(&d.variantGroup, &d.projectID, &d.tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to query asset project: %w", err)
+	}
+
+	return r.publishApproval(ctx, &d, prevStatus, time.Now())
+}
+
+// revertedApproval returns the status and approval a revert to version leaves
+// the asset with. The asset keeps its current ones unless the status machine
+// allows moving it to the version's status, so that a revert cannot skip review.
+func revertedApproval(current *model.Asset, version *model.AssetVersion) (model.AssetStatus, *string, *time.Time) {
+	var approvedBy *string
+	if current.ApprovedBy != nil {
+		approvedBy = &current.ApprovedBy.ID
+	}
+
+	if version.Status == nil || *version.Status == current.Status {
+		return current.Status, approvedBy, current.ApprovedAt
+	}
+	if err := assetStatusMachine.ValidateTransition(current.Status, *version.Status); err != nil {
+		return current.Status, approvedBy, current.ApprovedAt
+	}
+
+	return *version.Status, version.ApprovedBy, version.ApprovedAt
+}
+
+// liveAsset locks and returns the asset assetID, which must not be deleted
+func liveAsset(ctx context.Context, tx *sql.Tx, assetID string) (*model.Asset, error) {
+	var asset model.Asset
+	var approvedBy sql.NullString
+	err := tx.QueryRowContext(ctx, `
+		SELECT id, name, type, url, status, board_id, approved_by, approved_at, platform_rejection_reason, rejection_reason, duplicate_of, scheduled_at, thumbnail_url, created_at, updated_at
+		FROM assets
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`, assetID).Scan(
+		&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
+		&asset.BoardID, &approvedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+		&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("asset not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query asset: %w", err)
+	}
+	if approvedBy.Valid {
+		asset.ApprovedBy = &model.User{ID: approvedBy.String}
+	}
+
+	return &asset, nil
+}
@@ -0,0 +1,91 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+func TestDiffAssetVersions(t *testing.T) {
+	text := func(value string) *string { return &value }
+	status := func(value model.AssetStatus) *model.AssetStatus { return &value }
+	approvedAt := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	from := &model.AssetVersion{
+		AssetID:       "asset-1",
+		VersionNumber: 1,
+		Name:          "Spring banner",
+		URL:           text("https://cdn.example.com/banner-v1.png"),
+		Status:        status(model.AssetStatusPending),
+	}
+	to := &model.AssetVersion{
+		AssetID:       "asset-1",
+		VersionNumber: 3,
+		Name:          "Spring banner",
+		Status:        status(model.AssetStatusApproved),
+		ApprovedBy:    text("user-1"),
+		ApprovedAt:    &approvedAt,
+	}
+
+	diff := diffAssetVersions(from, to)
+	assert.Equal(t, "asset-1", diff.AssetID)
+	assert.Equal(t, 1, diff.FromVersion)
+	assert.Equal(t, 3, diff.ToVersion)
+	assert.Equal(t, []*model.AssetFieldChange{
+		{Field: "approvedBy", NewValue: text("user-1")},
+		{Field: "approvedAt", NewValue: text("2024-03-01T09:30:00Z")},
+	}, diff.Added)
+	assert.Equal(t, []*model.AssetFieldChange{
+		{Field: "url", OldValue: text("https://cdn.example.com/banner-v1.png")},
+	}, diff.Removed)
+	assert.Equal(t, []*model.AssetFieldChange{
+		{Field: "status", OldValue: text("PENDING"), NewValue: text("APPROVED")},
+	}, diff.Changed)
+
+	// Reversing the comparison swaps added and removed fields
+	reversed := diffAssetVersions(to, from)
+	assert.Len(t, reversed.Added, 1)
+	assert.Len(t, reversed.Removed, 2)
+	assert.Len(t, reversed.Changed, 1)
+
+	same := diffAssetVersions(from, from)
+	assert.Empty(t, same.Added)
+	assert.Empty(t, same.Removed)
+	assert.Empty(t, same.Changed)
+}
+
+func TestRevertedApproval(t *testing.T) {
+	text := func(value string) *string { return &value }
+	status := func(value model.AssetStatus) *model.AssetStatus { return &value }
+	approvedAt := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	approved := &model.AssetVersion{
+		Status:     status(model.AssetStatusApproved),
+		ApprovedBy: text("reviewer-1"),
+		ApprovedAt: &approvedAt,
+	}
+
+	// An asset in review may go back to an approved version
+	current := &model.Asset{Status: model.AssetStatusReview}
+	restored, approvedBy, at := revertedApproval(current, approved)
+	assert.Equal(t, model.AssetStatusApproved, restored)
+	assert.Equal(t, text("reviewer-1"), approvedBy)
+	assert.Equal(t, &approvedAt, at)
+
+	// A draft must go through review first, and keeps its status and approval
+	current = &model.Asset{Status: model.AssetStatusDraft}
+	restored, approvedBy, at = revertedApproval(current, approved)
+	assert.Equal(t, model.AssetStatusDraft, restored)
+	assert.Nil(t, approvedBy)
+	assert.Nil(t, at)
+
+	// A version with the current status does not bring back its approval
+	reapprovedAt := approvedAt.Add(time.Hour)
+	current = &model.Asset{Status: model.AssetStatusApproved, ApprovedBy: &model.User{ID: "reviewer-2"}, ApprovedAt: &reapprovedAt}
+	restored, approvedBy, at = revertedApproval(current, approved)
+	assert.Equal(t, model.AssetStatusApproved, restored)
+	assert.Equal(t, text("reviewer-2"), approvedBy)
+	assert.Equal(t, &reapprovedAt, at)
+}
@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/audit"
+)
+
+// maxAuditLogLimit is the most audit entries returned by one auditLog query
+const maxAuditLogLimit = 200
+
+// audit records that the user of ctx performed operation on the entity entityID
+// of entityType, changing it from old to new. Failing to record it does not fail
+// the mutation, which has already been committed.
+func (r *Resolver) audit(ctx context.Context, operation, entityType, entityID string, old, new interface{}) {
+	if r.Audit == nil {
+		return
+	}
+
+	if err := r.Audit.Log(ctx, operation, entityType, entityID, old, new); err != nil {
+		log.Printf("Failed to audit %s of %s %s: %v", operation, entityType, entityID, err)
+	}
+}
+
+// auditEntry converts a stored audit entry to its GraphQL type
+func auditEntry(entry *audit.Entry) *model.AuditEntry {
+	var impersonatedBy *string
+	if entry.ImpersonatedBy != "" {
+		impersonatedBy = &entry.ImpersonatedBy
+	}
+
+	return &model.AuditEntry{
+		ID:         entry.ID,
+		UserID:     entry.UserID,
+		Operation:  entry.Operation,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		OldValue:   auditValue(entry.OldValue),
+		NewValue:   auditValue(entry.NewValue),
+		IPAddress:  entry.IPAddress,
+		UserAgent:  entry.UserAgent,
+		CreatedAt:  entry.CreatedAt,
+
+		ImpersonatedBy: impersonatedBy,
+	}
+}
+
+// auditValue decodes a recorded value, which is nil when the value was absent
+func auditValue(data json.RawMessage) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+
+	var value map[string]interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return map[string]interface{}{"value": string(data)}
+	}
+	return value
+}
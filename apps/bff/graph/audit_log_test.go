@@ -0,0 +1,83 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/audit"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+)
+
+type memoryAuditStore struct {
+	mu      sync.Mutex
+	entries []*audit.Entry
+}
+
+func (s *memoryAuditStore) Insert(ctx context.Context, entry *audit.Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *memoryAuditStore) List(ctx context.Context, entityType, entityID string, limit int) ([]*audit.Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []*audit.Entry
+	for i := len(s.entries) - 1; i >= 0 && len(entries) < limit; i-- {
+		if s.entries[i].EntityType == entityType && s.entries[i].EntityID == entityID {
+			entries = append(entries, s.entries[i])
+		}
+	}
+	return entries, nil
+}
+
+func TestQueryResolver_AuditLog(t *testing.T) {
+	store := &memoryAuditStore{}
+	logger := audit.NewAuditLogger(store, 10)
+	resolver := &queryResolver{&Resolver{Audit: logger}}
+
+	user := context.WithValue(context.Background(), "user", &auth.User{ID: "user-1", Role: "user"})
+	resolver.audit(user, "createProject", "project", "project-1", nil, map[string]string{"name": "Launch"})
+	resolver.audit(user, "deleteProject", "project", "project-1", nil, map[string]string{"deletedAt": "2024-03-01T10:00:00Z"})
+	logger.Close()
+
+	_, err := resolver.AuditLog(context.Background(), "project", "project-1", 10)
+	assert.EqualError(t, err, "unauthorized")
+	_, err = resolver.AuditLog(user, "project", "project-1", 10)
+	assert.EqualError(t, err, "admin access required")
+
+	admin := context.WithValue(context.Background(), "user", &auth.User{ID: "admin-1", Role: "admin"})
+	_, err = resolver.AuditLog(admin, "project", "project-1", maxAuditLogLimit+1)
+	assert.Error(t, err)
+
+	entries, err := resolver.AuditLog(admin, "project", "project-1", 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "deleteProject", entries[0].Operation)
+	assert.Equal(t, "createProject", entries[1].Operation)
+	assert.Equal(t, "user-1", entries[1].UserID)
+	assert.Nil(t, entries[1].OldValue)
+	assert.Equal(t, map[string]interface{}{"name": "Launch"}, entries[1].NewValue)
+}
+
+func TestAuditEntry_DecodesValues(t *testing.T) {
+	entry := auditEntry(&audit.Entry{
+		ID:        "entry-1",
+		OldValue:  json.RawMessage(`{"status": "PENDING"}`),
+		NewValue:  json.RawMessage(`true`),
+		CreatedAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+	})
+
+	assert.Equal(t, map[string]interface{}{"status": "PENDING"}, entry.OldValue)
+	// Values that are not objects are wrapped, as Map only holds objects
+	assert.Equal(t, map[string]interface{}{"value": "true"}, entry.NewValue)
+}
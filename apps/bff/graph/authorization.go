@@ -0,0 +1,312 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+)
+
+const errForbidden = "FORBIDDEN"
+
+// ErrProjectNotFound is returned by authorization checks on a project that does
+// not exist
+var ErrProjectNotFound = errors.New("project not found")
+
+// ErrBoardNotFound is returned by authorization checks on a board that does not
+// exist
+var ErrBoardNotFound = errors.New("board not found")
+
+// Action is an operation on a project that requires authorization
+type Action string
+
+const (
+	// ActionView reads a project and its boards and assets
+	ActionView Action = "view"
+
+	// ActionApprove approves an asset of a project
+	ActionApprove Action = "approve"
+
+	// ActionEdit deletes, restores or reverts boards and assets of a project
+	ActionEdit Action = "edit"
+
+	// ActionDelete deletes a project, which only its owner and admins may do
+	ActionDelete Action = "delete"
+)
+
+// actionRoles are the lowest project roles allowed to perform each action, as
+// reported by myPermissions
+var actionRoles = map[Action]model.Role{
+	ActionView:    model.RoleViewer,
+	ActionEdit:    model.RoleEditor,
+	ActionApprove: model.RoleAdmin,
+	ActionDelete:  model.RoleAdmin,
+}
+
+// reviewerRoles are the user roles allowed to approve assets
+var reviewerRoles = map[string]bool{
+	"admin":    true,
+	"reviewer": true,
+}
+
+type permissionKey struct {
+	userID    string
+	projectID string
+}
+
+type permissionEntry struct {
+	role      model.Role
+	expiresAt time.Time
+}
+
+// Permissions caches the roles of users in projects so that resolving many
+// boards of the same project queries membership once. Entries expire after ttl so
+// that removed members and changed roles take effect shortly after.
+type Permissions struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[permissionKey]permissionEntry
+}
+
+// NewPermissions creates a permission cache whose entries expire after ttl
+func NewPermissions(ttl time.Duration) *Permissions {
+	return &Permissions{
+		ttl:     ttl,
+		entries: make(map[permissionKey]permissionEntry),
+	}
+}
+
+// Get returns the cached role of userID in projectID, empty when the user has no
+// access to the project
+func (p *Permissions) Get(userID, projectID string) (model.Role, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := permissionKey{userID: userID, projectID: projectID}
+	entry, ok := p.entries[key]
+	if !ok {
+		return "", false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(p.entries, key)
+		return "", false
+	}
+	return entry.role, true
+}
+
+// Set caches the role of userID in projectID
+func (p *Permissions) Set(userID, projectID string, role model.Role) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.entries[permissionKey{userID: userID, projectID: projectID}] = permissionEntry{
+		role:      role,
+		expiresAt: time.Now().Add(p.ttl),
+	}
+}
+
+// AuthorizationMiddleware checks that the user may access the project of a field
+// before its resolver runs. Row-level security already hides other tenants' rows;
+// the middleware lets clients tell a project they cannot access, reported with a
+// FORBIDDEN error, apart from one that does not exist.
+type AuthorizationMiddleware struct {
+	Resolver *Resolver
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.FieldInterceptor
+} = &AuthorizationMiddleware{}
+
+// NewAuthorizationMiddleware creates an authorization middleware checking
+// permissions with resolver
+func NewAuthorizationMiddleware(resolver *Resolver) *AuthorizationMiddleware {
+	return &AuthorizationMiddleware{Resolver: resolver}
+}
+
+// ExtensionName returns the name of the extension
+func (m *AuthorizationMiddleware) ExtensionName() string {
+	return "AuthorizationMiddleware"
+}
+
+// Validate checks the middleware when it is added to the server
+func (m *AuthorizationMiddleware) Validate(schema graphql.ExecutableSchema) error {
+	if m.Resolver == nil {
+		return fmt.Errorf("authorization middleware requires a resolver")
+	}
+	return nil
+}
+
+// InterceptField authorizes the fields that read or change a project before
+// resolving them
+func (m *AuthorizationMiddleware) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil {
+		return next(ctx)
+	}
+
+	var err error
+	switch fc.Object + "." + fc.Field.Name {
+	case "Query.project":
+		err = m.Resolver.authorize(ctx, fc.Args["id"].(string), ActionView)
+	case "Query.assetHistory", "Query.assetVersionDiff":
+		err = m.Resolver.authorizeAsset(ctx, fc.Args["assetId"].(string), ActionView)
+	case "Query.scheduledDeployments", "Query.projectSpendReport":
+		err = m.Resolver.authorize(ctx, fc.Args["projectId"].(string), ActionView)
+	case "Subscription.campaignMetricsUpdated":
+		err = m.Resolver.authorize(ctx, fc.Args["projectId"].(string), ActionView)
+	case "Subscription.assetStatusChanged":
+		err = m.Resolver.authorizeBoard(ctx, fc.Args["boardId"].(string), ActionView)
+	case "Board.assets":
+		board, ok := fc.Parent.Result.(*model.Board)
+		if !ok {
+			return nil, fmt.Errorf("board of assets not resolved")
+		}
+		err = m.Resolver.authorize(ctx, board.ProjectID, ActionView)
+	case "Mutation.approveAsset", "Mutation.rejectAsset", "Mutation.recallAsset", "Mutation.scheduleDeployment":
+		err = m.Resolver.authorizeAsset(ctx, fc.Args["assetId"].(string), ActionApprove)
+	case "Mutation.approveAssets":
+		// Each asset is authorized by the resolver, which reports the ones the
+		// user may not approve
+		_, err = authorizeRole(ctx, ActionApprove)
+	case "Mutation.deleteProject":
+		err = m.Resolver.authorize(ctx, fc.Args["id"].(string), ActionDelete)
+	case "Mutation.setProjectBudgetLimit":
+		err = m.Resolver.authorize(ctx, fc.Args["projectId"].(string), ActionEdit)
+	case "Mutation.deleteBoard":
+		err = m.Resolver.authorizeBoard(ctx, fc.Args["id"].(string), ActionEdit)
+	case "Mutation.deleteAsset", "Mutation.restoreAsset":
+		err = m.Resolver.authorizeAsset(ctx, fc.Args["id"].(string), ActionEdit)
+	case "Mutation.revertAsset", "Mutation.submitAssetForReview":
+		err = m.Resolver.authorizeAsset(ctx, fc.Args["assetId"].(string), ActionEdit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return next(ctx)
+}
+
+// authorize checks that the authenticated user's role in the project projectID
+// allows action. It returns an error with code FORBIDDEN when it does not, and
+// ErrProjectNotFound when the project does not exist.
+func (r *Resolver) authorize(ctx context.Context, projectID string, action Action) error {
+	user, err := authorizeRole(ctx, action)
+	if err != nil {
+		return err
+	}
+	if user.ProjectScope != nil && *user.ProjectScope != projectID {
+		return forbidden("token is scoped to project %s", *user.ProjectScope)
+	}
+
+	role, err := r.projectRole(ctx, user, projectID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return forbidden("access to project %s denied", projectID)
+	}
+	if required := actionRoles[action]; roleRanks[role] < roleRanks[required] {
+		return forbidden("%s in project %s requires the %s role", action, projectID, required)
+	}
+
+	return nil
+}
+
+// authorizeBoard checks that the authenticated user may perform action on the
+// project of the board boardID
+func (r *Resolver) authorizeBoard(ctx context.Context, boardID string, action Action) error {
+	if _, err := authorizeRole(ctx, action); err != nil {
+		return err
+	}
+
+	projectID, err := r.boardProject(ctx, boardID)
+	if err != nil {
+		return err
+	}
+
+	return r.authorize(ctx, projectID, action)
+}
+
+// boardProject returns the project of the board boardID, or ErrBoardNotFound
+func (r *Resolver) boardProject(ctx context.Context, boardID string) (string, error) {
+	var projectID string
+	err := r.reader(ctx).QueryRowContext(ctx, `
+		SELECT project_id FROM boards WHERE id = $1 AND deleted_at IS NULL
+	`, boardID).Scan(&projectID)
+	if err == sql.ErrNoRows {
+		return "", ErrBoardNotFound
+	} else if err != nil {
+		return "", fmt.Errorf("failed to query board: %w", err)
+	}
+	return projectID, nil
+}
+
+// authorizeAsset checks that the authenticated user may perform action on the
+// project of the asset assetID. Deleted assets are found, so that they can be
+// restored, as long as their board is not deleted.
+func (r *Resolver) authorizeAsset(ctx context.Context, assetID string, action Action) error {
+	if _, err := authorizeRole(ctx, action); err != nil {
+		return err
+	}
+
+	projectID, err := r.assetProject(ctx, assetID)
+	if err != nil {
+		return err
+	}
+
+	return r.authorize(ctx, projectID, action)
+}
+
+// assetProject returns the project of the asset assetID, deleted or not, as long
+// as its board is not deleted
+func (r *Resolver) assetProject(ctx context.Context, assetID string) (string, error) {
+	var projectID string
+	err := r.reader(ctx).QueryRowContext(ctx, `
+		SELECT b.project_id FROM assets a JOIN boards b ON b.id = a.board_id
+		WHERE a.id = $1 AND b.deleted_at IS NULL
+	`, assetID).Scan(&projectID)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("asset not found")
+	} else if err != nil {
+		return "", fmt.Errorf("failed to query asset: %w", err)
+	}
+	return projectID, nil
+}
+
+// authorizeRole returns the authenticated user when their role allows action in
+// any project
+func authorizeRole(ctx context.Context, action Action) (*auth.User, error) {
+	user, ok := ctx.Value("user").(*auth.User)
+	if !ok {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	if action == ActionApprove && !reviewerRoles[user.Role] {
+		return nil, forbidden("approving assets requires the admin or reviewer role")
+	}
+
+	return user, nil
+}
+
+// IsForbidden reports whether err denies the authenticated user access
+func IsForbidden(err error) bool {
+	var gqlErr *gqlerror.Error
+	return errors.As(err, &gqlErr) && gqlErr.Extensions["code"] == errForbidden
+}
+
+// forbidden returns an error with code FORBIDDEN
+func forbidden(format string, args ...interface{}) error {
+	err := gqlerror.Errorf(format, args...)
+	errcode.Set(err, errForbidden)
+	return err
+}
@@ -0,0 +1,200 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/generated"
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/middleware"
+)
+
+type authorizationResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Path       []interface{}          `json:"path"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+// postAuthorized sends query, as user when user is not nil, to a server whose
+// fields are authorized against resolver
+func postAuthorized(t *testing.T, resolver *Resolver, user *auth.User, query string) authorizationResponse {
+	t.Helper()
+
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{Resolvers: resolver, Directives: resolver.Directives()}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(NewAuthorizationMiddleware(resolver))
+
+	body, err := json.Marshal(map[string]string{"query": query})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	if user != nil {
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+	}
+	rec := httptest.NewRecorder()
+	resolver.LoaderMiddleware(srv).ServeHTTP(rec, req)
+
+	var resp authorizationResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp), rec.Body.String())
+	return resp
+}
+
+func TestAuthorizationMiddleware_ApproveRequiresReviewerRole(t *testing.T) {
+	// The resolvers would fail without a database; the mutation never reaches them
+	resp := postAuthorized(t, &Resolver{}, &auth.User{ID: "user-1", Role: "user"}, `mutation { approveAsset(assetId: "asset-1") { id } }`)
+
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "approving assets requires the admin or reviewer role", resp.Errors[0].Message)
+	assert.Equal(t, errForbidden, resp.Errors[0].Extensions["code"])
+	assert.Equal(t, []interface{}{"approveAsset"}, resp.Errors[0].Path)
+}
+
+func TestAuthorizationMiddleware_RequiresUser(t *testing.T) {
+	resp := postAuthorized(t, &Resolver{}, nil, `{ project(id: "project-1") { id } }`)
+
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "unauthorized", resp.Errors[0].Message)
+	assert.Nil(t, resp.Errors[0].Extensions["code"])
+}
+
+func TestAuthorizationMiddleware_Validate(t *testing.T) {
+	assert.Error(t, NewAuthorizationMiddleware(nil).Validate(nil))
+	assert.NoError(t, NewAuthorizationMiddleware(&Resolver{}).Validate(nil))
+}
+
+func TestPermissions_Expire(t *testing.T) {
+	permissions := NewPermissions(50 * time.Millisecond)
+
+	_, ok := permissions.Get("user-1", "project-1")
+	assert.False(t, ok)
+
+	permissions.Set("user-1", "project-1", model.RoleEditor)
+	role, ok := permissions.Get("user-1", "project-1")
+	assert.True(t, ok)
+	assert.Equal(t, model.RoleEditor, role)
+
+	// Permissions are cached per user
+	_, ok = permissions.Get("user-2", "project-1")
+	assert.False(t, ok)
+
+	time.Sleep(60 * time.Millisecond)
+	_, ok = permissions.Get("user-1", "project-1")
+	assert.False(t, ok)
+}
+
+func TestAuthorize_EnforcesProjectRole(t *testing.T) {
+	tests := []struct {
+		role    model.Role
+		allowed map[Action]bool
+	}{
+		{"", map[Action]bool{}},
+		{model.RoleViewer, map[Action]bool{ActionView: true}},
+		{model.RoleEditor, map[Action]bool{ActionView: true, ActionEdit: true}},
+		{model.RoleAdmin, map[Action]bool{ActionView: true, ActionEdit: true, ActionApprove: true, ActionDelete: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			// The cached role spares the database
+			permissions := NewPermissions(time.Minute)
+			permissions.Set("user-1", "project-1", tt.role)
+			resolver := &Resolver{Permissions: permissions}
+			ctx := context.WithValue(context.Background(), "user", &auth.User{ID: "user-1", Role: "reviewer"})
+
+			for _, action := range []Action{ActionView, ActionEdit, ActionApprove, ActionDelete} {
+				err := resolver.authorize(ctx, "project-1", action)
+				if tt.allowed[action] {
+					assert.NoError(t, err, action)
+				} else {
+					assert.True(t, IsForbidden(err), "%s: %v", action, err)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorizationMiddleware_ViewerCannotEdit(t *testing.T) {
+	permissions := NewPermissions(time.Minute)
+	permissions.Set("user-1", "project-1", model.RoleViewer)
+
+	resp := postAuthorized(t, &Resolver{Permissions: permissions}, &auth.User{ID: "user-1", Role: "user"}, `mutation { setProjectBudgetLimit(projectId: "project-1", budgetLimit: 100) }`)
+
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "edit in project project-1 requires the EDITOR role", resp.Errors[0].Message)
+	assert.Equal(t, errForbidden, resp.Errors[0].Extensions["code"])
+}
+
+func TestAuthorizationMiddleware_ApproveAssetsRequiresReviewerRole(t *testing.T) {
+	resp := postAuthorized(t, &Resolver{}, &auth.User{ID: "user-1", Role: "user"}, `mutation { approveAssets(ids: ["asset-1", "asset-2"]) { ... on Asset { id } } }`)
+
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "approving assets requires the admin or reviewer role", resp.Errors[0].Message)
+	assert.Equal(t, errForbidden, resp.Errors[0].Extensions["code"])
+}
+
+func TestAuthorize_ProjectScopedTokenDeniedOtherProjects(t *testing.T) {
+	scope := "project-1"
+	user := &auth.User{ID: "user-1", Role: "user", ProjectScope: &scope}
+
+	// The scope is checked before the permission, which would need a database
+	resp := postAuthorized(t, &Resolver{}, user, `{ project(id: "project-2") { id } }`)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "token is scoped to project project-1", resp.Errors[0].Message)
+	assert.Equal(t, errForbidden, resp.Errors[0].Extensions["code"])
+
+	resp = postAuthorized(t, &Resolver{}, user, `mutation { createBoard(input: {name: "Launch", projectId: "project-2"}) { id } }`)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "token is scoped to project project-1", resp.Errors[0].Message)
+}
+
+func TestHasRole_RequiresAuthentication(t *testing.T) {
+	// createBoard is authorized by @hasRole only
+	resp := postAuthorized(t, &Resolver{}, nil, `mutation { createBoard(input: {name: "Launch", projectId: "project-1"}) { id } }`)
+
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "unauthorized", resp.Errors[0].Message)
+}
+
+func TestBatchRequest_AuthorizesEachOperation(t *testing.T) {
+	resolver := &Resolver{}
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{Resolvers: resolver, Directives: resolver.Directives()}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(NewAuthorizationMiddleware(resolver))
+	batch := middleware.BatchRequestMiddleware(middleware.DefaultMaxBatchSize)(resolver.LoaderMiddleware(srv))
+
+	body := `[
+		{"query": "{ __typename }"},
+		{"query": "mutation { approveAsset(assetId: \"asset-1\") { id } }"}
+	]`
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(context.WithValue(req.Context(), "user", &auth.User{ID: "user-1", Role: "user"}))
+	rec := httptest.NewRecorder()
+	batch.ServeHTTP(rec, req)
+
+	var resps []authorizationResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resps), rec.Body.String())
+	require.Len(t, resps, 2)
+
+	assert.Empty(t, resps[0].Errors)
+	assert.JSONEq(t, `{"__typename": "Query"}`, string(resps[0].Data))
+
+	require.Len(t, resps[1].Errors, 1)
+	assert.Equal(t, "approving assets requires the admin or reviewer role", resps[1].Errors[0].Message)
+	assert.Equal(t, errForbidden, resps[1].Errors[0].Extensions["code"])
+}
@@ -0,0 +1,127 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+// maxApprovalBatch is the most assets a single approveAssets call approves
+const maxApprovalBatch = 100
+
+// uniqueIDs returns ids without duplicates, in the order they first appear
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// approveAssets approves with a single statement those of the assets ids whose
+// status allows it, and returns the outcome for each asset keyed by its ID.
+// Assets left unchanged are reported with an ApprovalError giving the reason.
+func approveAssets(ctx context.Context, tx *sql.Tx, ids []string, userID string, now time.Time) (map[string]model.AssetApprovalResult, error) {
+	results := make(map[string]model.AssetApprovalResult, len(ids))
+	if len(ids) == 0 {
+		return results, nil
+	}
+
+	var approvable []string
+	for _, status := range assetStatusMachine.AllowedFrom(model.AssetStatusApproved) {
+		approvable = append(approvable, string(status))
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE assets
+		SET status = $1, approved_by = $2, approved_at = $3, updated_at = $3
+		WHERE id = ANY($4::uuid[]) AND deleted_at IS NULL AND status = ANY($5::asset_status[])
+		RETURNING id, name, type, url, status, board_id, approved_by, approved_at, platform_rejection_reason, rejection_reason, duplicate_of, scheduled_at, thumbnail_url, created_at, updated_at
+	`, model.AssetStatusApproved, userID, now, pq.Array(ids), pq.Array(approvable))
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve assets: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var asset model.Asset
+		var approvedBy sql.NullString
+		if err := rows.Scan(
+			&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
+			&asset.BoardID, &approvedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+			&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan approved asset: %w", err)
+		}
+		if approvedBy.Valid {
+			asset.ApprovedBy = &model.User{ID: approvedBy.String}
+		}
+		results[asset.ID] = &asset
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to approve assets: %w", err)
+	}
+
+	if len(results) == len(ids) {
+		return results, nil
+	}
+
+	// The assets that were not updated either do not exist or are in a status
+	// that cannot be approved
+	var unchanged []string
+	for _, id := range ids {
+		if _, approved := results[id]; !approved {
+			unchanged = append(unchanged, id)
+		}
+	}
+
+	statuses := make(map[string]model.AssetStatus, len(unchanged))
+	rows, err = tx.QueryContext(ctx, `
+		SELECT id, status FROM assets WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
+	`, pq.Array(unchanged))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unapproved assets: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var status model.AssetStatus
+		if err := rows.Scan(&id, &status); err != nil {
+			return nil, fmt.Errorf("failed to scan unapproved asset: %w", err)
+		}
+		statuses[id] = status
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query unapproved assets: %w", err)
+	}
+
+	for _, id := range unchanged {
+		results[id] = approvalFailure(id, statuses[id])
+	}
+
+	return results, nil
+}
+
+// approvalFailure explains why the asset assetID in status was not approved. An
+// empty status means the asset was not found.
+func approvalFailure(assetID string, status model.AssetStatus) *model.ApprovalError {
+	if status == "" {
+		return &model.ApprovalError{AssetID: assetID, Reason: "asset not found"}
+	}
+
+	reason := "asset status changed concurrently, please retry"
+	if err := assetStatusMachine.ValidateTransition(status, model.AssetStatusApproved); err != nil {
+		reason = err.Error()
+	}
+	return &model.ApprovalError{AssetID: assetID, Reason: reason}
+}
@@ -0,0 +1,28 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+func TestUniqueIDs(t *testing.T) {
+	assert.Equal(t, []string{"asset-2", "asset-1", "asset-3"}, uniqueIDs([]string{"asset-2", "asset-1", "asset-2", "asset-3", "asset-1"}))
+	assert.Empty(t, uniqueIDs(nil))
+}
+
+func TestApprovalFailure(t *testing.T) {
+	assert.Equal(t, &model.ApprovalError{AssetID: "asset-1", Reason: "asset not found"}, approvalFailure("asset-1", ""))
+	assert.Equal(t,
+		&model.ApprovalError{AssetID: "asset-1", Reason: "invalid asset status transition from APPROVED to APPROVED"},
+		approvalFailure("asset-1", model.AssetStatusApproved),
+	)
+
+	// An approvable asset was left unchanged because its status changed meanwhile
+	assert.Equal(t,
+		&model.ApprovalError{AssetID: "asset-1", Reason: "asset status changed concurrently, please retry"},
+		approvalFailure("asset-1", model.AssetStatusReview),
+	)
+}
@@ -0,0 +1,164 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/export"
+)
+
+// BoardDataExport is a board with its assets and chat messages, read for an
+// export within one read transaction of the user exporting it. The transaction
+// ends before the export is written, so that a slow download does not hold a
+// connection of the pool. It implements export.BoardSource.
+type BoardDataExport struct {
+	user     *auth.User
+	board    export.BoardRecord
+	assets   []export.AssetRow
+	messages []export.MessageRow
+
+	assetsWritten   int
+	messagesWritten int
+}
+
+// OpenBoardDataExport checks that the authenticated user may view the board
+// boardID and reads it with its assets and chat messages. It returns
+// ErrBoardNotFound when the board does not exist and an error with code
+// FORBIDDEN when the user may not view it.
+func (r *Resolver) OpenBoardDataExport(ctx context.Context, boardID string) (*BoardDataExport, error) {
+	if err := r.authorizeBoard(ctx, boardID, ActionView); err != nil {
+		return nil, err
+	}
+
+	tx, authUser, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	source := &BoardDataExport{user: authUser}
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, name, description, project_id, created_at, updated_at
+		FROM boards WHERE id = $1 AND deleted_at IS NULL
+	`, boardID).Scan(
+		&source.board.ID, &source.board.Name, &source.board.Description,
+		&source.board.ProjectID, &source.board.CreatedAt, &source.board.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrBoardNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query board: %w", err)
+	}
+
+	if source.assets, err = readExportedAssets(ctx, tx, boardID); err != nil {
+		return nil, err
+	}
+	if source.messages, err = readExportedMessages(ctx, tx, boardID); err != nil {
+		return nil, err
+	}
+
+	return source, nil
+}
+
+// readExportedAssets returns the assets of the board boardID, oldest first
+func readExportedAssets(ctx context.Context, tx *sql.Tx, boardID string) ([]export.AssetRow, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, name, type, url, status, approved_by, approved_at, scheduled_at, thumbnail_url, created_at, updated_at
+		FROM assets WHERE board_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at, id
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query assets: %w", err)
+	}
+	defer rows.Close()
+
+	assets := []export.AssetRow{}
+	for rows.Next() {
+		var asset export.AssetRow
+		err := rows.Scan(
+			&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
+			&asset.ApprovedBy, &asset.ApprovedAt, &asset.ScheduledAt, &asset.ThumbnailURL,
+			&asset.CreatedAt, &asset.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan asset: %w", err)
+		}
+		assets = append(assets, asset)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query assets: %w", err)
+	}
+
+	return assets, nil
+}
+
+// readExportedMessages returns the chat messages of the board boardID, oldest
+// first
+func readExportedMessages(ctx context.Context, tx *sql.Tx, boardID string) ([]export.MessageRow, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, content, user_id, parent_id, created_at
+		FROM chat_messages WHERE board_id = $1
+		ORDER BY created_at, id
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chat messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []export.MessageRow{}
+	for rows.Next() {
+		var message export.MessageRow
+		err := rows.Scan(&message.ID, &message.Content, &message.UserID, &message.ParentID, &message.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan chat message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query chat messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// Board returns the metadata of the board
+func (e *BoardDataExport) Board() export.BoardRecord {
+	return e.board
+}
+
+// EachAsset calls fn with every asset of the board, oldest first
+func (e *BoardDataExport) EachAsset(fn func(export.AssetRow) error) error {
+	for _, asset := range e.assets {
+		if err := fn(asset); err != nil {
+			return err
+		}
+		e.assetsWritten++
+	}
+	return nil
+}
+
+// EachMessage calls fn with every chat message of the board, oldest first
+func (e *BoardDataExport) EachMessage(fn func(export.MessageRow) error) error {
+	for _, message := range e.messages {
+		if err := fn(message); err != nil {
+			return err
+		}
+		e.messagesWritten++
+	}
+	return nil
+}
+
+// RecordBoardDataExport adds an export of the board in format, once it has been
+// written, to the export audit log
+func (r *Resolver) RecordBoardDataExport(ctx context.Context, e *BoardDataExport, format string) error {
+	_, err := r.DB.Writer().ExecContext(ctx, `
+		INSERT INTO export_audit_log (user_id, board_id, format, asset_count, message_count)
+		VALUES ($1, $2, $3, $4, $5)
+	`, e.user.ID, e.board.ID, format, e.assetsWritten, e.messagesWritten)
+	if err != nil {
+		return fmt.Errorf("failed to record board export: %w", err)
+	}
+	return nil
+}
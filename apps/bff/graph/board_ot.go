@@ -0,0 +1,222 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+// maxBoardOperations is the number of applied operations kept per board. Clients
+// whose version falls behind this window must reload the board.
+const maxBoardOperations = 500
+
+// BoardOperationLog records the operations applied to each board description in
+// Redis so that operations submitted against an older version can be transformed
+// against everything applied since.
+type BoardOperationLog struct {
+	client *redis.Client
+}
+
+// NewBoardOperationLog creates an operation log backed by client
+func NewBoardOperationLog(client *redis.Client) *BoardOperationLog {
+	return &BoardOperationLog{client: client}
+}
+
+func boardOperationsKey(boardID string) string {
+	return "board_ops:" + boardID
+}
+
+func boardVersionKey(boardID string) string {
+	return "board_ops:" + boardID + ":version"
+}
+
+// Since returns the current version of a board and the operations applied after
+// version, oldest first.
+func (l *BoardOperationLog) Since(ctx context.Context, boardID string, version int) (int, []model.BoardOperation, error) {
+	current, err := l.client.Get(ctx, boardVersionKey(boardID)).Int()
+	if err != nil && err != redis.Nil {
+		return 0, nil, fmt.Errorf("failed to get board version: %w", err)
+	}
+
+	if version > current {
+		return 0, nil, fmt.Errorf("operation version %d is ahead of board version %d", version, current)
+	}
+	if version == current {
+		return current, nil, nil
+	}
+
+	entries, err := l.client.LRange(ctx, boardOperationsKey(boardID), 0, -1).Result()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get board operations: %w", err)
+	}
+
+	var pending []model.BoardOperation
+	for _, entry := range entries {
+		var op model.BoardOperation
+		if err := json.Unmarshal([]byte(entry), &op); err != nil {
+			return 0, nil, fmt.Errorf("failed to decode board operation: %w", err)
+		}
+		if op.Version > version {
+			pending = append(pending, op)
+		}
+	}
+
+	// Each applied operation advances the version by one, so a gap means the
+	// operations the client has not seen were trimmed
+	if len(pending) != current-version {
+		return 0, nil, fmt.Errorf("operation version %d is too old, reload the board", version)
+	}
+
+	return current, pending, nil
+}
+
+// Append records op as applied, advancing the board to op.Version
+func (l *BoardOperationLog) Append(ctx context.Context, op model.BoardOperation) error {
+	data, err := json.Marshal(op)
+	if err != nil {
+		return fmt.Errorf("failed to marshal board operation: %w", err)
+	}
+
+	_, err = l.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, boardOperationsKey(op.BoardID), data)
+		pipe.LTrim(ctx, boardOperationsKey(op.BoardID), -maxBoardOperations, -1)
+		pipe.Set(ctx, boardVersionKey(op.BoardID), op.Version, 0)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record board operation: %w", err)
+	}
+
+	return nil
+}
+
+// undoBoardOperationScript removes the operation ARGV[1] from the end of the
+// list in KEYS[1] and moves the version in KEYS[2] from ARGV[2] back to ARGV[3],
+// if the operation is still the last one applied
+const undoBoardOperationScript = `
+if redis.call("GET", KEYS[2]) ~= ARGV[2] or redis.call("LINDEX", KEYS[1], -1) ~= ARGV[1] then
+	return 0
+end
+redis.call("RPOP", KEYS[1])
+redis.call("SET", KEYS[2], ARGV[3])
+return 1
+`
+
+// Undo removes op, recorded by Append, when the change it made could not be
+// saved. Operations applied after op are left in place.
+func (l *BoardOperationLog) Undo(ctx context.Context, op model.BoardOperation) error {
+	data, err := json.Marshal(op)
+	if err != nil {
+		return fmt.Errorf("failed to marshal board operation: %w", err)
+	}
+
+	keys := []string{boardOperationsKey(op.BoardID), boardVersionKey(op.BoardID)}
+	err = l.client.Eval(ctx, undoBoardOperationScript, keys, data, op.Version, op.Version-1).Err()
+	if err != nil {
+		return fmt.Errorf("failed to undo board operation: %w", err)
+	}
+
+	return nil
+}
+
+// validateBoardOperation checks that an operation is well formed before it is
+// transformed or applied
+func validateBoardOperation(op model.BoardOperation) error {
+	if !op.Type.IsValid() {
+		return fmt.Errorf("invalid operation type: %s", op.Type)
+	}
+	if op.Offset < 0 {
+		return fmt.Errorf("offset must not be negative")
+	}
+	if op.Version < 0 {
+		return fmt.Errorf("version must not be negative")
+	}
+	if op.Chars == "" {
+		return fmt.Errorf("chars must not be empty")
+	}
+	return nil
+}
+
+// transformOperation rewrites op, which was created without knowledge of applied,
+// so that it has the same intent once applied has been applied. Both operations
+// must be based on the same version. When two inserts land at the same offset
+// the one applied first stays in front.
+func transformOperation(op, applied model.BoardOperation) model.BoardOperation {
+	chars := []rune(op.Chars)
+	appliedChars := []rune(applied.Chars)
+	appliedEnd := applied.Offset + len(appliedChars)
+
+	switch {
+	case op.Type == model.BoardOperationTypeInsert && applied.Type == model.BoardOperationTypeInsert:
+		if applied.Offset <= op.Offset {
+			op.Offset += len(appliedChars)
+		}
+
+	case op.Type == model.BoardOperationTypeInsert && applied.Type == model.BoardOperationTypeDelete:
+		if op.Offset >= appliedEnd {
+			op.Offset -= len(appliedChars)
+		} else if op.Offset > applied.Offset {
+			// The insert point was deleted; insert where the deleted text was
+			op.Offset = applied.Offset
+		}
+
+	case op.Type == model.BoardOperationTypeDelete && applied.Type == model.BoardOperationTypeInsert:
+		opEnd := op.Offset + len(chars)
+		if applied.Offset <= op.Offset {
+			op.Offset += len(appliedChars)
+		} else if applied.Offset < opEnd {
+			// Text inserted inside the deleted range is deleted with it
+			split := applied.Offset - op.Offset
+			op.Chars = string(chars[:split]) + applied.Chars + string(chars[split:])
+		}
+
+	case op.Type == model.BoardOperationTypeDelete && applied.Type == model.BoardOperationTypeDelete:
+		opEnd := op.Offset + len(chars)
+		overlapStart := max(op.Offset, applied.Offset)
+		overlapEnd := min(opEnd, appliedEnd)
+		deletedBefore := max(min(appliedEnd, op.Offset)-applied.Offset, 0)
+
+		if overlapStart < overlapEnd {
+			// Characters already deleted by applied are not deleted again
+			op.Chars = string(chars[:overlapStart-op.Offset]) + string(chars[overlapEnd-op.Offset:])
+		}
+		op.Offset -= deletedBefore
+	}
+
+	return op
+}
+
+// transformOperations transforms op against each of applied in order
+func transformOperations(op model.BoardOperation, applied []model.BoardOperation) model.BoardOperation {
+	for _, a := range applied {
+		op = transformOperation(op, a)
+	}
+	return op
+}
+
+// applyOperation applies op to text. Deletes must match the text they remove.
+func applyOperation(text string, op model.BoardOperation) (string, error) {
+	runes := []rune(text)
+	chars := []rune(op.Chars)
+
+	if op.Offset > len(runes) {
+		return "", fmt.Errorf("offset %d is beyond the end of the board description", op.Offset)
+	}
+
+	switch op.Type {
+	case model.BoardOperationTypeInsert:
+		return string(runes[:op.Offset]) + op.Chars + string(runes[op.Offset:]), nil
+	case model.BoardOperationTypeDelete:
+		end := op.Offset + len(chars)
+		if end > len(runes) || string(runes[op.Offset:end]) != op.Chars {
+			return "", fmt.Errorf("deleted characters do not match the board description")
+		}
+		return string(runes[:op.Offset]) + string(runes[end:]), nil
+	default:
+		return "", fmt.Errorf("invalid operation type: %s", op.Type)
+	}
+}
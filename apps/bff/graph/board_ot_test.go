@@ -0,0 +1,174 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+func insertOp(offset int, chars string) model.BoardOperation {
+	return model.BoardOperation{Type: model.BoardOperationTypeInsert, Offset: offset, Chars: chars}
+}
+
+func deleteOp(offset int, chars string) model.BoardOperation {
+	return model.BoardOperation{Type: model.BoardOperationTypeDelete, Offset: offset, Chars: chars}
+}
+
+// converge applies first then second, transformed against first, the way the
+// server orders two operations made against the same version
+func converge(t *testing.T, text string, first, second model.BoardOperation) string {
+	t.Helper()
+
+	result, err := applyOperation(text, first)
+	require.NoError(t, err)
+
+	result, err = applyOperation(result, transformOperation(second, first))
+	require.NoError(t, err)
+
+	return result
+}
+
+func TestTransformOperation_ConcurrentInserts(t *testing.T) {
+	t.Run("Different Offsets", func(t *testing.T) {
+		a := insertOp(0, "Big ")
+		b := insertOp(6, " launch")
+
+		// Both orders yield the same text
+		assert.Equal(t, "Big summer launch plan", converge(t, "summer plan", a, b))
+		assert.Equal(t, "Big summer launch plan", converge(t, "summer plan", b, a))
+	})
+
+	t.Run("Same Offset", func(t *testing.T) {
+		a := insertOp(3, "AAA")
+		b := insertOp(3, "BBB")
+
+		// The operation applied first stays in front
+		assert.Equal(t, "abcAAABBBdef", converge(t, "abcdef", a, b))
+		assert.Equal(t, "abcBBBAAAdef", converge(t, "abcdef", b, a))
+	})
+
+	t.Run("Earlier Insert Shifts Later", func(t *testing.T) {
+		transformed := transformOperation(insertOp(5, "x"), insertOp(2, "abc"))
+		assert.Equal(t, 8, transformed.Offset)
+
+		transformed = transformOperation(insertOp(1, "x"), insertOp(2, "abc"))
+		assert.Equal(t, 1, transformed.Offset)
+	})
+
+	t.Run("Multibyte Characters", func(t *testing.T) {
+		a := insertOp(0, "¡")
+		b := insertOp(3, "!")
+
+		// Offsets count characters, not bytes
+		assert.Equal(t, "¡Olé!", converge(t, "Olé", a, b))
+		assert.Equal(t, "¡Olé!", converge(t, "Olé", b, a))
+	})
+
+	t.Run("Against Several Pending", func(t *testing.T) {
+		// Pending operations were applied one after another, as recorded in the log
+		text := "hello"
+		pending := []model.BoardOperation{insertOp(0, "> "), insertOp(7, " world")}
+
+		for _, op := range pending {
+			var err error
+			text, err = applyOperation(text, op)
+			require.NoError(t, err)
+		}
+
+		// Made against "hello", before either pending operation
+		op := transformOperations(insertOp(5, "!"), pending)
+		text, err := applyOperation(text, op)
+		require.NoError(t, err)
+		assert.Equal(t, "> hello world!", text)
+	})
+}
+
+func TestTransformOperation_Deletes(t *testing.T) {
+	t.Run("Insert Inside Deleted Range", func(t *testing.T) {
+		del := deleteOp(2, "cde")
+		ins := insertOp(4, "XY")
+
+		assert.Equal(t, "abXYf", converge(t, "abcdef", del, ins))
+		assert.Equal(t, "abf", converge(t, "abcdef", ins, del))
+	})
+
+	t.Run("Insert After Delete", func(t *testing.T) {
+		assert.Equal(t, "abfX", converge(t, "abcdef", deleteOp(2, "cde"), insertOp(6, "X")))
+		assert.Equal(t, "abfX", converge(t, "abcdef", insertOp(6, "X"), deleteOp(2, "cde")))
+	})
+
+	t.Run("Overlapping Deletes", func(t *testing.T) {
+		a := deleteOp(1, "bcd")
+		b := deleteOp(2, "cdef")
+
+		assert.Equal(t, "ag", converge(t, "abcdefg", a, b))
+		assert.Equal(t, "ag", converge(t, "abcdefg", b, a))
+	})
+
+	t.Run("Identical Deletes", func(t *testing.T) {
+		transformed := transformOperation(deleteOp(1, "bc"), deleteOp(1, "bc"))
+		assert.Equal(t, "", transformed.Chars)
+		assert.Equal(t, "ad", converge(t, "abcd", deleteOp(1, "bc"), deleteOp(1, "bc")))
+	})
+}
+
+func TestApplyOperation(t *testing.T) {
+	t.Run("Offset Beyond End", func(t *testing.T) {
+		_, err := applyOperation("abc", insertOp(4, "x"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Delete Mismatch", func(t *testing.T) {
+		_, err := applyOperation("abcdef", deleteOp(1, "xyz"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "do not match")
+	})
+
+	t.Run("Delete Beyond End", func(t *testing.T) {
+		_, err := applyOperation("abc", deleteOp(2, "cd"))
+		assert.Error(t, err)
+	})
+}
+
+func TestValidateBoardOperation(t *testing.T) {
+	assert.NoError(t, validateBoardOperation(insertOp(0, "a")))
+	assert.Error(t, validateBoardOperation(insertOp(-1, "a")))
+	assert.Error(t, validateBoardOperation(insertOp(0, "")))
+	assert.Error(t, validateBoardOperation(model.BoardOperation{Type: "REPLACE", Chars: "a"}))
+}
+
+func TestBoardOperationLog_Undo(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	operations := NewBoardOperationLog(client)
+
+	first := insertOp(0, "a")
+	first.BoardID, first.Version = "board-1", 1
+	second := insertOp(1, "b")
+	second.BoardID, second.Version = "board-1", 2
+
+	require.NoError(t, operations.Append(ctx, first))
+	require.NoError(t, operations.Append(ctx, second))
+
+	// An operation that is no longer the last one is left in place
+	require.NoError(t, operations.Undo(ctx, first))
+	version, pending, err := operations.Since(ctx, "board-1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+	assert.Len(t, pending, 2)
+
+	require.NoError(t, operations.Undo(ctx, second))
+	version, pending, err = operations.Since(ctx, "board-1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+	assert.Equal(t, []model.BoardOperation{first}, pending)
+}
@@ -0,0 +1,116 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+)
+
+// scanBoardTemplate scans a board_templates row selected as id, name,
+// description, default_boards, created_by, is_public, created_at
+func scanBoardTemplate(row rowScanner) (*model.BoardTemplate, error) {
+	var template model.BoardTemplate
+	var raw []byte
+	var createdBy sql.NullString
+	if err := row.Scan(&template.ID, &template.Name, &template.Description, &raw,
+		&createdBy, &template.IsPublic, &template.CreatedAt); err != nil {
+		return nil, err
+	}
+	if createdBy.Valid {
+		template.CreatedBy = &createdBy.String
+	}
+
+	template.Preview = []*model.TemplateBoard{}
+	if err := json.Unmarshal(raw, &template.Preview); err != nil {
+		return nil, fmt.Errorf("failed to decode template boards: %w", err)
+	}
+
+	return &template, nil
+}
+
+// insertProject creates a project owned by authUser in tx. Projects belong to
+// the organization the user acts for unless another is given.
+func insertProject(ctx context.Context, tx *sql.Tx, authUser *auth.User, input model.CreateProjectInput) (*model.Project, error) {
+	var orgID sql.NullString
+	if input.OrgID != nil {
+		orgID = sql.NullString{String: *input.OrgID, Valid: true}
+	} else if authUser.OrgID != "" {
+		orgID = sql.NullString{String: authUser.OrgID, Valid: true}
+	}
+	if orgID.Valid {
+		var member bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM organization_members WHERE org_id = $1 AND user_id = $2)
+		`, orgID.String, authUser.ID).Scan(&member)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query organization: %w", err)
+		}
+		if !member {
+			return nil, fmt.Errorf("organization not found")
+		}
+	}
+
+	project := model.Project{
+		ID:          uuid.New().String(),
+		Name:        input.Name,
+		Description: input.Description,
+		Status:      model.ProjectStatusActive,
+		OwnerID:     authUser.ID,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO projects (id, name, description, status, owner_id, org_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, project.ID, project.Name, project.Description, project.Status,
+		project.OwnerID, orgID, project.CreatedAt, project.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return &project, nil
+}
+
+// templateBoards returns the boards of the project projectID visible in tx, in
+// the order they were created, as the boards of a template
+func templateBoards(ctx context.Context, tx *sql.Tx, projectID string) ([]*model.TemplateBoard, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT name, description
+		FROM boards
+		WHERE project_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at, id
+	`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query boards: %w", err)
+	}
+	defer rows.Close()
+
+	boards := []*model.TemplateBoard{}
+	for rows.Next() {
+		var board model.TemplateBoard
+		var description sql.NullString
+		if err := rows.Scan(&board.Name, &description); err != nil {
+			return nil, fmt.Errorf("failed to scan board: %w", err)
+		}
+		if description.Valid && strings.TrimSpace(description.String) != "" {
+			board.Description = &description.String
+		}
+		boards = append(boards, &board)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate boards: %w", err)
+	}
+
+	return boards, nil
+}
@@ -0,0 +1,63 @@
+package graph
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// templateRow is a board_templates row as selected by the resolvers
+type templateRow []interface{}
+
+func (r templateRow) Scan(dest ...interface{}) error {
+	if len(dest) != len(r) {
+		return fmt.Errorf("expected %d columns, got %d", len(r), len(dest))
+	}
+	for i, value := range r {
+		switch d := dest[i].(type) {
+		case *string:
+			*d = value.(string)
+		case *[]byte:
+			*d = []byte(value.(string))
+		case *bool:
+			*d = value.(bool)
+		case *time.Time:
+			*d = value.(time.Time)
+		default:
+			if err := d.(interface{ Scan(interface{}) error }).Scan(value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func TestScanBoardTemplate(t *testing.T) {
+	createdAt := time.Now()
+	system, err := scanBoardTemplate(templateRow{
+		"template-1", "Product Launch", "Launch assets",
+		`[{"name": "Research", "description": "Market and positioning"}, {"name": "Launch Assets"}]`,
+		nil, true, createdAt,
+	})
+	require.NoError(t, err)
+	assert.Nil(t, system.CreatedBy)
+	assert.True(t, system.IsPublic)
+	require.Len(t, system.Preview, 2)
+	assert.Equal(t, "Research", system.Preview[0].Name)
+	require.NotNil(t, system.Preview[0].Description)
+	assert.Equal(t, "Market and positioning", *system.Preview[0].Description)
+	assert.Nil(t, system.Preview[1].Description)
+
+	saved, err := scanBoardTemplate(templateRow{"template-2", "Empty", "", `[]`, "user-1", false, createdAt})
+	require.NoError(t, err)
+	require.NotNil(t, saved.CreatedBy)
+	assert.Equal(t, "user-1", *saved.CreatedBy)
+	assert.NotNil(t, saved.Preview)
+	assert.Empty(t, saved.Preview)
+
+	_, err = scanBoardTemplate(templateRow{"template-3", "Broken", "", `{`, nil, false, createdAt})
+	assert.Error(t, err)
+}
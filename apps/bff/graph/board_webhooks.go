@@ -0,0 +1,214 @@
+package graph
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/webhook"
+)
+
+const (
+	// MaxBoardWebhookDeliveries is the number of deliveries
+	// BoardWebhookDeliveries returns at most
+	MaxBoardWebhookDeliveries = 100
+
+	// boardWebhookLookupTimeout bounds the query of the webhooks to notify of
+	// an event
+	boardWebhookLookupTimeout = 5 * time.Second
+)
+
+// ErrBoardWebhookNotFound is returned for board webhooks that do not exist or
+// whose board the user cannot see
+var ErrBoardWebhookNotFound = errors.New("board webhook not found")
+
+// boardEvents maps GraphQL board events to the names stored with board webhooks
+var boardEvents = map[model.BoardEventType]string{
+	model.BoardEventTypeAssetUploaded: webhook.EventAssetUploaded,
+	model.BoardEventTypeAssetApproved: webhook.EventAssetApproved,
+	model.BoardEventTypeAssetDeployed: webhook.EventAssetDeployed,
+	model.BoardEventTypeMessageSent:   webhook.EventMessageSent,
+}
+
+// boardWebhookColumns are the columns read by scanBoardWebhook
+const boardWebhookColumns = `id, board_id, url, events, active, created_at`
+
+// boardEventNames returns the stored names of events, of which there must be
+// at least one
+func boardEventNames(events []model.BoardEventType) ([]string, error) {
+	if len(events) == 0 {
+		return nil, fmt.Errorf("webhook must subscribe to at least one event")
+	}
+
+	names := []string{}
+	seen := map[string]bool{}
+	for _, event := range events {
+		name, ok := boardEvents[event]
+		if !ok {
+			return nil, fmt.Errorf("unsupported board event: %s", event)
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// generateWebhookSecret returns a random secret for a webhook created without one
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// scanBoardWebhook scans a board_webhooks row selected as boardWebhookColumns
+func scanBoardWebhook(row rowScanner) (*model.BoardWebhook, error) {
+	var hook model.BoardWebhook
+	var events []string
+	if err := row.Scan(&hook.ID, &hook.BoardID, &hook.URL, pq.Array(&events), &hook.Active, &hook.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	hook.Events = []model.BoardEventType{}
+	for _, name := range events {
+		for event, eventName := range boardEvents {
+			if eventName == name {
+				hook.Events = append(hook.Events, event)
+			}
+		}
+	}
+
+	return &hook, nil
+}
+
+// createBoardWebhook registers url for the events of a board the user may edit
+func (r *Resolver) createBoardWebhook(ctx context.Context, boardID, url string, events []model.BoardEventType, secret *string) (*model.BoardWebhook, error) {
+	if err := validateWebhookURL(url); err != nil {
+		return nil, err
+	}
+	names, err := boardEventNames(events)
+	if err != nil {
+		return nil, err
+	}
+
+	var webhookSecret string
+	if secret != nil {
+		webhookSecret = strings.TrimSpace(*secret)
+		if webhookSecret == "" {
+			return nil, fmt.Errorf("webhook secret is required")
+		}
+	} else if webhookSecret, err = generateWebhookSecret(); err != nil {
+		return nil, err
+	}
+
+	if err := r.authorizeBoard(ctx, boardID, ActionEdit); err != nil {
+		return nil, err
+	}
+
+	tx, _, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	hook, err := scanBoardWebhook(tx.QueryRowContext(ctx, `
+		INSERT INTO board_webhooks (board_id, url, secret, events)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+boardWebhookColumns,
+		boardID, url, webhookSecret, pq.Array(names)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create board webhook: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit board webhook: %w", err)
+	}
+
+	r.audit(ctx, "createBoardWebhook", "board_webhook", hook.ID, nil, hook)
+
+	hook.Secret = &webhookSecret
+	return hook, nil
+}
+
+// BoardWebhookDeliveries returns the latest limit attempts to deliver events to
+// a webhook of a board the user may edit, newest first
+func (r *Resolver) BoardWebhookDeliveries(ctx context.Context, webhookID string, limit int) ([]*webhook.BoardDelivery, error) {
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var boardID string
+	err = tx.QueryRowContext(ctx, `SELECT board_id FROM board_webhooks WHERE id = $1`, webhookID).Scan(&boardID)
+	if err == sql.ErrNoRows {
+		return nil, ErrBoardWebhookNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query board webhook: %w", err)
+	}
+
+	if err := r.authorizeBoard(ctx, boardID, ActionEdit); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT webhook_id, delivery_id, event, attempt, status_code, response_body, error, delivered_at
+		FROM board_webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY delivered_at DESC, attempt DESC
+		LIMIT $2
+	`, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query board webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []*webhook.BoardDelivery{}
+	for rows.Next() {
+		var delivery webhook.BoardDelivery
+		var statusCode sql.NullInt64
+		var responseBody, deliveryError sql.NullString
+		err := rows.Scan(&delivery.WebhookID, &delivery.DeliveryID, &delivery.Event, &delivery.Attempt,
+			&statusCode, &responseBody, &deliveryError, &delivery.DeliveredAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan board webhook delivery: %w", err)
+		}
+		delivery.StatusCode = int(statusCode.Int64)
+		delivery.ResponseBody = responseBody.String
+		delivery.Error = deliveryError.String
+		deliveries = append(deliveries, &delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read board webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// dispatchBoardEvent posts payload to the webhooks of its board subscribed to
+// it. Failing to do so does not fail the mutation that caused the event.
+func (r *Resolver) dispatchBoardEvent(payload *webhook.BoardPayload) {
+	if r.BoardWebhooks == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), boardWebhookLookupTimeout)
+	defer cancel()
+
+	if err := r.BoardWebhooks.Dispatch(ctx, payload); err != nil {
+		log.Printf("Failed to dispatch %s of board %s to webhooks: %v", payload.Event, payload.BoardID, err)
+	}
+}
@@ -0,0 +1,86 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+func TestBoardEventNames(t *testing.T) {
+	names, err := boardEventNames([]model.BoardEventType{
+		model.BoardEventTypeMessageSent, model.BoardEventTypeAssetUploaded,
+		model.BoardEventTypeAssetApproved, model.BoardEventTypeAssetDeployed, model.BoardEventTypeMessageSent,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"message.sent", "asset.uploaded", "asset.approved", "asset.deployed"}, names)
+
+	_, err = boardEventNames(nil)
+	assert.EqualError(t, err, "webhook must subscribe to at least one event")
+
+	_, err = boardEventNames([]model.BoardEventType{"ASSET_FAILED"})
+	assert.EqualError(t, err, "unsupported board event: ASSET_FAILED")
+}
+
+func TestGenerateWebhookSecret(t *testing.T) {
+	first, err := generateWebhookSecret()
+	require.NoError(t, err)
+	second, err := generateWebhookSecret()
+	require.NoError(t, err)
+
+	assert.Len(t, first, 64)
+	assert.NotEqual(t, first, second)
+}
+
+func TestMutationResolver_CreateBoardWebhook(t *testing.T) {
+	events := []model.BoardEventType{model.BoardEventTypeAssetUploaded}
+
+	t.Run("Error - Invalid URL", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+		ctx := createTestContext(uuid.New().String())
+
+		result, err := mutationResolver.CreateBoardWebhook(ctx, uuid.New().String(), "http://10.1.2.3/hook", events, nil)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("Error - No Events", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+		ctx := createTestContext(uuid.New().String())
+
+		result, err := mutationResolver.CreateBoardWebhook(ctx, uuid.New().String(), "https://example.com/hook", nil, nil)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "at least one event")
+	})
+
+	t.Run("Error - Blank Secret", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+		ctx := createTestContext(uuid.New().String())
+
+		result, err := mutationResolver.CreateBoardWebhook(ctx, uuid.New().String(), "https://example.com/hook", events, stringPtr(" "))
+
+		assert.EqualError(t, err, "webhook secret is required")
+		assert.Nil(t, result)
+	})
+
+	t.Run("Error - Unauthorized", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+
+		result, err := mutationResolver.CreateBoardWebhook(context.Background(), uuid.New().String(), "https://example.com/hook", events, nil)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "unauthorized")
+	})
+}
@@ -0,0 +1,92 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+// SaveCampaignMetrics stores the latest metrics of a deployed campaign and pushes
+// them to the campaignMetricsUpdated subscribers of its project, and the new ROI
+// of the project to its projectROIUpdated subscribers. It is called for
+// events from the connectors service rather than by a user, so row-level security
+// does not apply.
+func (r *Resolver) SaveCampaignMetrics(ctx context.Context, event *nats.CampaignMetricsUpdatedEvent) (*model.CampaignMetricsUpdate, error) {
+	metrics := event.Metrics
+
+	var campaignName string
+	err := r.DB.Writer().QueryRowContext(ctx, `
+		INSERT INTO campaign_metrics (platform, campaign_id, asset_id, project_id, impressions, clicks, cost_micros, conversions, ctr, revenue, start_date, end_date, fetched_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, '')::date, NULLIF($12, '')::date, $13)
+		ON CONFLICT (platform, campaign_id) DO UPDATE
+		SET asset_id = EXCLUDED.asset_id, project_id = EXCLUDED.project_id,
+			impressions = EXCLUDED.impressions, clicks = EXCLUDED.clicks, cost_micros = EXCLUDED.cost_micros,
+			conversions = EXCLUDED.conversions, ctr = EXCLUDED.ctr, revenue = EXCLUDED.revenue,
+			start_date = EXCLUDED.start_date, end_date = EXCLUDED.end_date, fetched_at = EXCLUDED.fetched_at
+		RETURNING (SELECT name FROM assets WHERE id = asset_id)
+	`, event.Platform, event.CampaignID, event.AssetID, event.ProjectID,
+		metrics.Impressions, metrics.Clicks, metrics.CostMicros, metrics.Conversions, metrics.CTR, metrics.Revenue,
+		metrics.StartDate, metrics.EndDate, metrics.FetchedAt).Scan(&campaignName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save campaign metrics: %w", err)
+	}
+
+	update := campaignMetricsUpdate(event, campaignName)
+
+	if r.Cache != nil {
+		r.Cache.InvalidateProjectROI(event.ProjectID)
+	}
+	r.checkSpendBudget(ctx, event.ProjectID, metrics.FetchedAt)
+
+	if r.NatsConn != nil {
+		if err := r.NatsConn.PublishCampaignMetricsUpdate(event.ProjectID, update); err != nil {
+			log.Printf("Failed to publish campaign metrics update: %v", err)
+		}
+		r.publishProjectROI(ctx, event.ProjectID)
+	}
+
+	return update, nil
+}
+
+// campaignMetricsUpdate converts a metrics event of the connectors service into
+// the update sent to subscribers. The campaign is named after its asset. Google
+// Ads and Meta events are told apart by their platform.
+func campaignMetricsUpdate(event *nats.CampaignMetricsUpdatedEvent, campaignName string) *model.CampaignMetricsUpdate {
+	metrics := event.Metrics
+	spend := float64(metrics.CostMicros) / 1e6
+
+	var cpc, cpm float64
+	if metrics.Clicks > 0 {
+		cpc = spend / float64(metrics.Clicks)
+	}
+	if metrics.Impressions > 0 {
+		cpm = spend / float64(metrics.Impressions) * 1000
+	}
+
+	return &model.CampaignMetricsUpdate{
+		ProjectID:  event.ProjectID,
+		CampaignID: event.CampaignID,
+		Metrics: &model.CampaignMetrics{
+			CampaignID:   event.CampaignID,
+			CampaignName: campaignName,
+			Platform:     model.CampaignPlatform(strings.ToUpper(event.Platform)),
+			Impressions:  int(metrics.Impressions),
+			Clicks:       int(metrics.Clicks),
+			Spend:        spend,
+			Conversions:  int(math.Round(metrics.Conversions)),
+			Revenue:      metrics.Revenue,
+			CTR:          metrics.CTR,
+			CPC:          cpc,
+			CPM:          cpm,
+			ROAS:         metrics.ROAS,
+			Timestamp:    metrics.FetchedAt,
+			Date:         metrics.EndDate,
+		},
+		Timestamp: event.Timestamp,
+	}
+}
@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+func TestCampaignMetricsUpdate(t *testing.T) {
+	fetchedAt := time.Date(2024, 3, 14, 18, 0, 0, 0, time.UTC)
+	event := &nats.CampaignMetricsUpdatedEvent{
+		ProjectID:  "project-1",
+		AssetID:    "asset-1",
+		Platform:   "google_ads",
+		CampaignID: "555",
+		Metrics: nats.CampaignMetrics{
+			Impressions: 12000,
+			Clicks:      480,
+			CostMicros:  96500000,
+			Conversions: 12.6,
+			CTR:         0.04,
+			StartDate:   "2024-03-01",
+			EndDate:     "2024-03-14",
+			FetchedAt:   fetchedAt,
+		},
+		Timestamp: fetchedAt,
+	}
+
+	update := campaignMetricsUpdate(event, "Spring trail headlines")
+	assert.Equal(t, "project-1", update.ProjectID)
+	assert.Equal(t, "555", update.CampaignID)
+
+	metrics := update.Metrics
+	assert.Equal(t, "Spring trail headlines", metrics.CampaignName)
+	assert.Equal(t, model.CampaignPlatformGoogleAds, metrics.Platform)
+	assert.Equal(t, 12000, metrics.Impressions)
+	assert.Equal(t, 480, metrics.Clicks)
+	assert.Equal(t, 96.5, metrics.Spend)
+	assert.Equal(t, 13, metrics.Conversions)
+	assert.InDelta(t, 0.2010, metrics.CPC, 0.0001)
+	assert.InDelta(t, 8.0417, metrics.CPM, 0.0001)
+	assert.Equal(t, "2024-03-14", metrics.Date)
+	assert.Equal(t, fetchedAt, metrics.Timestamp)
+
+	event.Platform = "meta"
+	event.Metrics.Revenue = 386
+	event.Metrics.ROAS = 4
+	metrics = campaignMetricsUpdate(event, "Spring trail headlines").Metrics
+	assert.Equal(t, model.CampaignPlatformMeta, metrics.Platform)
+	assert.Equal(t, 386.0, metrics.Revenue)
+	assert.Equal(t, 4.0, metrics.ROAS)
+
+	// Campaigns without traffic have no cost per click or impression
+	event.Metrics = nats.CampaignMetrics{}
+	metrics = campaignMetricsUpdate(event, "").Metrics
+	assert.Zero(t, metrics.CPC)
+	assert.Zero(t, metrics.CPM)
+}
@@ -0,0 +1,27 @@
+package graph
+
+import (
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+// fromNatsCampaignSchedule converts a schedule returned by the connectors service,
+// whose platform is one of the connectorPlatforms identifiers.
+func fromNatsCampaignSchedule(schedule *nats.CampaignSchedule, platform model.CampaignPlatform) *model.CampaignSchedule {
+	for graphPlatform, connectorPlatform := range connectorPlatforms {
+		if connectorPlatform == schedule.Platform {
+			platform = graphPlatform
+			break
+		}
+	}
+
+	return &model.CampaignSchedule{
+		ID:                 schedule.ID,
+		Platform:           platform,
+		PlatformCampaignID: schedule.PlatformCampaignID,
+		PauseCron:          schedule.PauseCron,
+		ResumeCron:         schedule.ResumeCron,
+		Timezone:           schedule.Timezone,
+		CreatedAt:          schedule.CreatedAt,
+	}
+}
@@ -0,0 +1,40 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+func TestFromNatsCampaignSchedule(t *testing.T) {
+	createdAt := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+
+	schedule := fromNatsCampaignSchedule(&nats.CampaignSchedule{
+		ID:                 "schedule-1",
+		Platform:           "meta",
+		PlatformCampaignID: "120200000000001",
+		PauseCron:          "0 18 * * 1-5",
+		ResumeCron:         "0 9 * * 1-5",
+		Timezone:           "America/New_York",
+		CreatedAt:          createdAt,
+	}, model.CampaignPlatformGoogleAds)
+
+	// The platform reported by the connectors service wins over the requested one
+	assert.Equal(t, &model.CampaignSchedule{
+		ID:                 "schedule-1",
+		Platform:           model.CampaignPlatformMeta,
+		PlatformCampaignID: "120200000000001",
+		PauseCron:          "0 18 * * 1-5",
+		ResumeCron:         "0 9 * * 1-5",
+		Timezone:           "America/New_York",
+		CreatedAt:          createdAt,
+	}, schedule)
+
+	// Unknown identifiers fall back to the requested platform
+	schedule = fromNatsCampaignSchedule(&nats.CampaignSchedule{Platform: "tiktok"}, model.CampaignPlatformGoogleAds)
+	assert.Equal(t, model.CampaignPlatformGoogleAds, schedule.Platform)
+}
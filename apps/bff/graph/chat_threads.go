@@ -0,0 +1,96 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+// MessageReplies loads the direct replies to a chat message, oldest first
+func (l *Loaders) MessageReplies(ctx context.Context, messageID string) ([]*model.ChatMessage, error) {
+	replies, err := l.replies.Load(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if replies == nil {
+		return []*model.ChatMessage{}, nil
+	}
+	return replies, nil
+}
+
+// ReactionCount loads the number of reactions to a chat message
+func (l *Loaders) ReactionCount(ctx context.Context, messageID string) (int, error) {
+	return l.reactions.Load(ctx, messageID)
+}
+
+func (r *Resolver) fetchMessageReplies(ctx context.Context, ids []string) (map[string][]*model.ChatMessage, error) {
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, content, user_id, board_id, parent_id, created_at
+		FROM chat_messages WHERE parent_id = ANY($1::uuid[])
+		ORDER BY created_at, id
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chat message replies: %w", err)
+	}
+	defer rows.Close()
+
+	replies := make(map[string][]*model.ChatMessage, len(ids))
+	for rows.Next() {
+		var message model.ChatMessage
+		err := rows.Scan(
+			&message.ID, &message.Content, &message.UserID,
+			&message.BoardID, &message.ParentID, &message.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan chat message: %w", err)
+		}
+		replies[*message.ParentID] = append(replies[*message.ParentID], &message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query chat message replies: %w", err)
+	}
+
+	return replies, nil
+}
+
+func (r *Resolver) fetchReactionCounts(ctx context.Context, ids []string) (map[string]int, error) {
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT message_id, COUNT(*)
+		FROM message_reactions WHERE message_id = ANY($1::uuid[])
+		GROUP BY message_id
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query message reactions: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int, len(ids))
+	for rows.Next() {
+		var messageID string
+		var count int
+		if err := rows.Scan(&messageID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan message reactions: %w", err)
+		}
+		counts[messageID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query message reactions: %w", err)
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,214 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+)
+
+const (
+	complexityExtension = "complexity"
+
+	errComplexityLimit = "COMPLEXITY_LIMIT_EXCEEDED"
+
+	// DefaultListSize is the estimated size of lists whose type has no configured size
+	DefaultListSize = 10
+
+	// maxComplexity bounds computed costs so that deeply nested lists cannot overflow
+	maxComplexity = math.MaxInt32
+)
+
+// databaseFields are the fields whose resolvers query the database, besides the
+// root Query, Mutation and Subscription fields other than introspection. They are the fields gqlgen.yml
+// gives a resolver.
+var databaseFields = map[string]bool{
+	"Project.owner":     true,
+	"Project.boards":    true,
+	"Board.project":     true,
+	"Board.assets":      true,
+	"Asset.board":       true,
+	"Asset.approvedBy":  true,
+	"ChatMessage.user":  true,
+	"ChatMessage.board": true,
+}
+
+// ComplexityStats is the cost of an operation and the budget it was checked against
+type ComplexityStats struct {
+	Cost   int `json:"cost"`
+	Budget int `json:"budget"`
+}
+
+// ComplexityLimiter rejects operations whose estimated cost exceeds the budget of
+// the user before any resolver runs, and reports the cost of the others in the
+// complexity response extension.
+//
+// Scalar fields cost 1. The selections of a list field are multiplied by the
+// estimated size of the list, which is configured per element type in ListSizes
+// and defaults to DefaultListSize. Fields whose resolvers query the database add
+// DatabasePenalty. Fragments and @skip/@include directives are counted as if every
+// selection was resolved.
+type ComplexityLimiter struct {
+	// Budget is the highest cost allowed per operation
+	Budget int
+
+	// AdminBudget is the highest cost allowed per operation of admin users
+	AdminBudget int
+
+	// DatabasePenalty is added for each resolver call that queries the database
+	DatabasePenalty int
+
+	// ListSizes are the estimated list sizes by element type name
+	ListSizes map[string]int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+	graphql.ResponseInterceptor
+} = &ComplexityLimiter{}
+
+// NewComplexityLimiter creates a complexity limiter
+func NewComplexityLimiter(budget, adminBudget, databasePenalty int, listSizes map[string]int) *ComplexityLimiter {
+	return &ComplexityLimiter{
+		Budget:          budget,
+		AdminBudget:     adminBudget,
+		DatabasePenalty: databasePenalty,
+		ListSizes:       listSizes,
+	}
+}
+
+// ExtensionName returns the name of the extension
+func (c *ComplexityLimiter) ExtensionName() string {
+	return "ComplexityLimiter"
+}
+
+// Validate checks the budgets when the extension is added to the server
+func (c *ComplexityLimiter) Validate(schema graphql.ExecutableSchema) error {
+	if c.Budget <= 0 || c.AdminBudget <= 0 {
+		return fmt.Errorf("complexity budgets must be positive")
+	}
+	return nil
+}
+
+// MutateOperationContext computes the cost of the operation and rejects it when
+// it exceeds the budget
+func (c *ComplexityLimiter) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	op := rc.Doc.Operations.ForName(rc.OperationName)
+	if op == nil {
+		return nil
+	}
+
+	stats := &ComplexityStats{
+		Cost:   c.Cost(op),
+		Budget: c.budget(ctx),
+	}
+	rc.Stats.SetExtension(complexityExtension, stats)
+
+	if stats.Cost > stats.Budget {
+		err := gqlerror.Errorf("operation has complexity %d, which exceeds the budget of %d", stats.Cost, stats.Budget)
+		errcode.Set(err, errComplexityLimit)
+		err.Extensions[complexityExtension] = stats
+		return err
+	}
+
+	return nil
+}
+
+// InterceptResponse adds the cost of the operation to the response extensions
+func (c *ComplexityLimiter) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+	if resp == nil || !graphql.HasOperationContext(ctx) {
+		return resp
+	}
+
+	stats, ok := graphql.GetOperationContext(ctx).Stats.GetExtension(complexityExtension).(*ComplexityStats)
+	if !ok {
+		return resp
+	}
+
+	if resp.Extensions == nil {
+		resp.Extensions = make(map[string]interface{})
+	}
+	resp.Extensions[complexityExtension] = stats
+
+	return resp
+}
+
+// budget returns the budget of the user making the request
+func (c *ComplexityLimiter) budget(ctx context.Context) int {
+	if user, ok := ctx.Value("user").(*auth.User); ok && user.Role == "admin" {
+		return c.AdminBudget
+	}
+	return c.Budget
+}
+
+// Cost returns the estimated cost of op
+func (c *ComplexityLimiter) Cost(op *ast.OperationDefinition) int {
+	return c.selectionSetCost(op.SelectionSet, true)
+}
+
+func (c *ComplexityLimiter) selectionSetCost(set ast.SelectionSet, root bool) int {
+	cost := 0
+	for _, selection := range set {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			cost = addCost(cost, c.fieldCost(selection, root))
+		case *ast.FragmentSpread:
+			if selection.Definition != nil {
+				cost = addCost(cost, c.selectionSetCost(selection.Definition.SelectionSet, root))
+			}
+		case *ast.InlineFragment:
+			cost = addCost(cost, c.selectionSetCost(selection.SelectionSet, root))
+		}
+	}
+	return cost
+}
+
+func (c *ComplexityLimiter) fieldCost(field *ast.Field, root bool) int {
+	cost := 1
+	if len(field.SelectionSet) > 0 {
+		cost = c.selectionSetCost(field.SelectionSet, false)
+	}
+
+	if (root && !strings.HasPrefix(field.Name, "__")) || (field.ObjectDefinition != nil && databaseFields[field.ObjectDefinition.Name+"."+field.Name]) {
+		cost = addCost(cost, c.DatabasePenalty)
+	}
+
+	if field.Definition != nil {
+		for typ := field.Definition.Type; typ.Elem != nil; typ = typ.Elem {
+			cost = multiplyCost(cost, c.listSize(typ.Elem.Name()))
+		}
+	}
+
+	return cost
+}
+
+// listSize returns the estimated size of a list of typeName
+func (c *ComplexityLimiter) listSize(typeName string) int {
+	if size, ok := c.ListSizes[typeName]; ok {
+		return size
+	}
+	return DefaultListSize
+}
+
+func addCost(a, b int) int {
+	if a > maxComplexity-b {
+		return maxComplexity
+	}
+	return a + b
+}
+
+func multiplyCost(cost, size int) int {
+	if size > 0 && cost > maxComplexity/size {
+		return maxComplexity
+	}
+	return cost * size
+}
@@ -0,0 +1,166 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/generated"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+)
+
+const nestedProjectsQuery = `{
+	projects {
+		edges {
+			node {
+				id
+				name
+				boards {
+					edges {
+						node {
+							id
+							assets {
+								edges {
+									node {
+										id
+										approvedBy { name }
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}`
+
+// operationCost parses query against the schema and returns its cost
+func operationCost(t *testing.T, limiter *ComplexityLimiter, query string) int {
+	t.Helper()
+
+	schema := generated.NewExecutableSchema(generated.Config{Resolvers: &Resolver{}}).Schema()
+	doc, err := gqlparser.LoadQuery(schema, query)
+	require.Nil(t, err)
+
+	return limiter.Cost(doc.Operations[0])
+}
+
+type complexityResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+	Extensions map[string]map[string]int `json:"extensions"`
+}
+
+// postQuery sends query, as user when user is not nil, to a server limited by limiter
+func postQuery(t *testing.T, limiter *ComplexityLimiter, user *auth.User, query string) complexityResponse {
+	t.Helper()
+
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{Resolvers: &Resolver{}}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(limiter)
+
+	body, err := json.Marshal(map[string]string{"query": query})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	if user != nil {
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	var resp complexityResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp), rec.Body.String())
+	return resp
+}
+
+func TestComplexityLimiter_Cost(t *testing.T) {
+	limiter := NewComplexityLimiter(1000, 1000, 5, nil)
+
+	// Scalars cost 1 and root fields add the database penalty
+	assert.Equal(t, 2+5, operationCost(t, limiter, `{ me { id name } }`))
+	assert.Equal(t, 1, operationCost(t, limiter, `{ __typename }`))
+
+	// List selections are multiplied by the default list size
+	assert.Equal(t, 5+10*2, operationCost(t, limiter, `{ projects { edges { node { id name } } } }`))
+
+	// Resolver fields add the penalty for each item of the list holding them
+	assets := 5 + 10*(1+(1+5))
+	boards := 5 + 10*(1+assets)
+	projects := 5 + 10*(2+boards)
+	assert.Equal(t, projects, operationCost(t, limiter, nestedProjectsQuery))
+
+	// Fragments count like inline selections
+	assert.Equal(t, 5+10*2, operationCost(t, limiter, `
+		query { projects { edges { node { ...projectFields } } } }
+		fragment projectFields on Project { id name }
+	`))
+}
+
+func TestComplexityLimiter_ListSizes(t *testing.T) {
+	limiter := NewComplexityLimiter(1000, 1000, 0, map[string]int{"ProjectEdge": 3, "BoardEdge": 50})
+
+	assert.Equal(t, 3*(1+50*1), operationCost(t, limiter, `{ projects { edges { node { id boards { edges { node { id } } } } } } }`))
+}
+
+func TestComplexityLimiter_Saturates(t *testing.T) {
+	limiter := NewComplexityLimiter(1000, 1000, 5, map[string]int{"ProjectEdge": 1 << 20, "BoardEdge": 1 << 20, "AssetEdge": 1 << 20})
+
+	assert.Equal(t, maxComplexity, operationCost(t, limiter, nestedProjectsQuery))
+}
+
+func TestComplexityLimiter_RejectsBeforeResolving(t *testing.T) {
+	limiter := NewComplexityLimiter(1000, 100000, 5, nil)
+
+	// The resolvers would fail without a database; the operation never reaches them
+	resp := postQuery(t, limiter, &auth.User{ID: "user-1", Role: "user"}, nestedProjectsQuery)
+
+	require.Len(t, resp.Errors, 1)
+	assert.Contains(t, resp.Errors[0].Message, "exceeds the budget of 1000")
+	assert.Equal(t, errComplexityLimit, resp.Errors[0].Extensions["code"])
+	assert.Equal(t, map[string]interface{}{"cost": float64(operationCost(t, limiter, nestedProjectsQuery)), "budget": float64(1000)},
+		resp.Errors[0].Extensions[complexityExtension])
+	assert.True(t, len(resp.Data) == 0 || string(resp.Data) == "null")
+}
+
+func TestComplexityLimiter_AdminBudget(t *testing.T) {
+	limiter := NewComplexityLimiter(5, 100, 5, nil)
+	query := `{ a: __typename b: __typename c: __typename d: __typename e: __typename f: __typename }`
+
+	resp := postQuery(t, limiter, nil, query)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, errComplexityLimit, resp.Errors[0].Extensions["code"])
+
+	resp = postQuery(t, limiter, &auth.User{ID: "admin-1", Role: "admin"}, query)
+	assert.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"a": "Query", "b": "Query", "c": "Query", "d": "Query", "e": "Query", "f": "Query"}`, string(resp.Data))
+	assert.Equal(t, map[string]int{"cost": 6, "budget": 100}, resp.Extensions[complexityExtension])
+}
+
+func TestComplexityLimiter_ReportsCost(t *testing.T) {
+	limiter := NewComplexityLimiter(1000, 1000, 5, nil)
+
+	// Resolver errors do not hide the cost
+	resp := postQuery(t, limiter, nil, `{ me { id } }`)
+	require.NotEmpty(t, resp.Errors)
+	assert.Equal(t, map[string]int{"cost": 6, "budget": 1000}, resp.Extensions[complexityExtension])
+}
+
+func TestComplexityLimiter_Validate(t *testing.T) {
+	assert.Error(t, NewComplexityLimiter(0, 100, 5, nil).Validate(nil))
+	assert.NoError(t, NewComplexityLimiter(100, 100, 5, nil).Validate(nil))
+}
@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+// hashCustomAudienceEmails replaces the custom audience emails of demographics
+// with their hex-encoded SHA-256 hashes, so that emails are neither stored nor
+// published in the clear. Emails are trimmed and lowercased first, as Meta
+// does before matching them, and blank ones are left out.
+func hashCustomAudienceEmails(demographics *model.Demographics) {
+	if demographics == nil || len(demographics.CustomAudienceEmails) == 0 {
+		return
+	}
+
+	for _, email := range demographics.CustomAudienceEmails {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if email == "" {
+			continue
+		}
+		sum := sha256.Sum256([]byte(email))
+		demographics.CustomAudienceEmailHashes = append(demographics.CustomAudienceEmailHashes, hex.EncodeToString(sum[:]))
+	}
+	demographics.CustomAudienceEmails = nil
+}
+
+// deleteCustomAudience has the connectors service delete the Meta custom
+// audiences the deployments of an asset target, and reports whether the asset
+// had any. Admins delete audiences of any tenant, so row-level security does
+// not apply.
+func (r *Resolver) deleteCustomAudience(ctx context.Context, userID, assetID string) (bool, error) {
+	// Audiences outlive the assets they were built for, so deleted assets count too
+	var exists bool
+	err := r.DB.Writer().QueryRowContext(ctx, `SELECT TRUE FROM assets WHERE id = $1`, assetID).Scan(&exists)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, fmt.Errorf("asset not found")
+		}
+		return false, fmt.Errorf("failed to query asset: %w", err)
+	}
+
+	deleted, err := r.NatsConn.RequestCustomAudienceDeletion(ctx, nats.CustomAudienceDeletionRequest{
+		AssetID:     assetID,
+		RequestedBy: userID,
+	}, 30*time.Second)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete custom audience: %w", err)
+	}
+
+	r.audit(ctx, "deleteCustomAudience", "asset", assetID, nil, map[string]interface{}{"deleted": deleted})
+
+	return deleted, nil
+}
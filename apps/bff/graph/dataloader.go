@@ -0,0 +1,403 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+const (
+	// loaderWait is how long a loader collects keys before querying them
+	loaderWait = 2 * time.Millisecond
+
+	// loaderMaxBatch bounds the keys queried at once
+	loaderMaxBatch = 100
+)
+
+// batchLoader collects the keys loaded concurrently, fetches them with a single
+// call and hands each caller its result. Results are not kept once a batch has
+// been delivered.
+type batchLoader[K comparable, V any] struct {
+	fetch    func(ctx context.Context, keys []K) (map[K]V, error)
+	wait     time.Duration
+	maxBatch int
+
+	mu    sync.Mutex
+	batch *loaderBatch[K, V]
+}
+
+type loaderBatch[K comparable, V any] struct {
+	keys    []K
+	queued  map[K]bool
+	results map[K]V
+	err     error
+	done    chan struct{}
+}
+
+func newBatchLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *batchLoader[K, V] {
+	return &batchLoader[K, V]{fetch: fetch, wait: loaderWait, maxBatch: loaderMaxBatch}
+}
+
+// Load waits for the batch key joins to be fetched and returns its value, the
+// zero value when the fetch found nothing for key
+func (l *batchLoader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	b := l.batch
+	if b == nil {
+		b = &loaderBatch[K, V]{queued: make(map[K]bool), done: make(chan struct{})}
+		l.batch = b
+		time.AfterFunc(l.wait, func() { l.dispatch(ctx, b) })
+	}
+	if !b.queued[key] {
+		b.queued[key] = true
+		b.keys = append(b.keys, key)
+	}
+	full := len(b.keys) >= l.maxBatch
+	l.mu.Unlock()
+
+	if full {
+		l.dispatch(ctx, b)
+	}
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+
+	return b.results[key], b.err
+}
+
+// dispatch fetches b unless it has already been fetched
+func (l *batchLoader[K, V]) dispatch(ctx context.Context, b *loaderBatch[K, V]) {
+	l.mu.Lock()
+	if l.batch != b {
+		l.mu.Unlock()
+		return
+	}
+	l.batch = nil
+	l.mu.Unlock()
+
+	b.results, b.err = l.fetch(ctx, b.keys)
+	close(b.done)
+}
+
+// Loaders batch the lookups field resolvers make for every item of a list, so
+// that resolving a field on N items costs one query instead of N. Loaders are
+// created per request by Resolver.LoaderMiddleware.
+type Loaders struct {
+	users         *batchLoader[string, *model.User]
+	projects      *batchLoader[string, *model.Project]
+	boards        *batchLoader[string, *model.Board]
+	projectBoards *batchLoader[pageKey, []*model.Board]
+	boardAssets   *batchLoader[pageKey, []*model.Asset]
+	replies       *batchLoader[string, []*model.ChatMessage]
+	reactions     *batchLoader[string, int]
+	successRates  *batchLoader[string, float64]
+}
+
+type loadersKey struct{}
+
+// pageKey identifies a page of the children of a parent entity
+type pageKey struct {
+	parentID string
+	page     connectionPage
+}
+
+// NewLoaders creates the loaders of one request
+func (r *Resolver) NewLoaders() *Loaders {
+	return &Loaders{
+		users:         newBatchLoader(r.fetchUsers),
+		projects:      newBatchLoader(r.fetchProjects),
+		boards:        newBatchLoader(r.fetchBoards),
+		projectBoards: newBatchLoader(r.fetchProjectBoards),
+		boardAssets:   newBatchLoader(r.fetchBoardAssets),
+		replies:       newBatchLoader(r.fetchMessageReplies),
+		reactions:     newBatchLoader(r.fetchReactionCounts),
+		successRates:  newBatchLoader(r.fetchSuccessRates),
+	}
+}
+
+// LoaderMiddleware gives each request its own loaders so that batched results are
+// never shared between requests
+func (r *Resolver) LoaderMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := context.WithValue(req.Context(), loadersKey{}, r.NewLoaders())
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// loaders returns the loaders of the request, or new ones when ctx has none
+func (r *Resolver) loaders(ctx context.Context) *Loaders {
+	if loaders, ok := ctx.Value(loadersKey{}).(*Loaders); ok {
+		return loaders
+	}
+	return r.NewLoaders()
+}
+
+// User loads a user by ID
+func (l *Loaders) User(ctx context.Context, id string) (*model.User, error) {
+	user, err := l.users.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("failed to query user: %w", sql.ErrNoRows)
+	}
+	return user, nil
+}
+
+// Project loads a project by ID
+func (l *Loaders) Project(ctx context.Context, id string) (*model.Project, error) {
+	project, err := l.projects.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, fmt.Errorf("failed to query project: %w", sql.ErrNoRows)
+	}
+	return project, nil
+}
+
+// Board loads a board by ID
+func (l *Loaders) Board(ctx context.Context, id string) (*model.Board, error) {
+	board, err := l.boards.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil {
+		return nil, fmt.Errorf("failed to query board: %w", sql.ErrNoRows)
+	}
+	return board, nil
+}
+
+// ProjectBoards loads a page of the boards of a project in the order of the
+// page, with one board past the page when another page follows
+func (l *Loaders) ProjectBoards(ctx context.Context, projectID string, page connectionPage) ([]*model.Board, error) {
+	return l.projectBoards.Load(ctx, pageKey{parentID: projectID, page: page})
+}
+
+// BoardAssets loads a page of the assets of a board in the order of the page,
+// with one asset past the page when another page follows
+func (l *Loaders) BoardAssets(ctx context.Context, boardID string, page connectionPage) ([]*model.Asset, error) {
+	return l.boardAssets.Load(ctx, pageKey{parentID: boardID, page: page})
+}
+
+func (r *Resolver) fetchUsers(ctx context.Context, ids []string) (map[string]*model.User, error) {
+	rows, err := r.reader(ctx).QueryContext(ctx, `
+		SELECT id, email, name, avatar, created_at, updated_at
+		FROM users WHERE id = ANY($1::uuid[])
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make(map[string]*model.User, len(ids))
+	for rows.Next() {
+		var user model.User
+		err := rows.Scan(
+			&user.ID, &user.Email, &user.Name, &user.Avatar,
+			&user.CreatedAt, &user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users[user.ID] = &user
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *Resolver) fetchProjects(ctx context.Context, ids []string) (map[string]*model.Project, error) {
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, name, description, status, owner_id, created_at, updated_at
+		FROM projects WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := make(map[string]*model.Project, len(ids))
+	for rows.Next() {
+		var project model.Project
+		err := rows.Scan(
+			&project.ID, &project.Name, &project.Description, &project.Status,
+			&project.OwnerID, &project.CreatedAt, &project.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects[project.ID] = &project
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+func (r *Resolver) fetchBoards(ctx context.Context, ids []string) (map[string]*model.Board, error) {
+	boards, err := r.queryBoards(ctx, `
+		SELECT id, name, description, project_id, created_at, updated_at
+		FROM boards WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*model.Board, len(boards))
+	for _, board := range boards {
+		byID[board.ID] = board
+	}
+	return byID, nil
+}
+
+func (r *Resolver) fetchProjectBoards(ctx context.Context, keys []pageKey) (map[pageKey][]*model.Board, error) {
+	byPage := make(map[pageKey][]*model.Board, len(keys))
+	for page, projectIDs := range groupPages(keys) {
+		query, args := pageQuery("boards", "id, name, description, project_id, created_at, updated_at", "project_id", projectIDs, page)
+		boards, err := r.queryBoards(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, board := range boards {
+			key := pageKey{parentID: board.ProjectID, page: page}
+			byPage[key] = append(byPage[key], board)
+		}
+	}
+	return byPage, nil
+}
+
+// queryBoards runs query, which selects the columns of boards
+func (r *Resolver) queryBoards(ctx context.Context, query string, args ...interface{}) ([]*model.Board, error) {
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query boards: %w", err)
+	}
+	defer rows.Close()
+
+	var boards []*model.Board
+	for rows.Next() {
+		var board model.Board
+		err := rows.Scan(
+			&board.ID, &board.Name, &board.Description, &board.ProjectID,
+			&board.CreatedAt, &board.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan board: %w", err)
+		}
+		boards = append(boards, &board)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query boards: %w", err)
+	}
+
+	return boards, nil
+}
+
+func (r *Resolver) fetchBoardAssets(ctx context.Context, keys []pageKey) (map[pageKey][]*model.Asset, error) {
+	byPage := make(map[pageKey][]*model.Asset, len(keys))
+	for page, boardIDs := range groupPages(keys) {
+		if err := r.queryBoardAssets(ctx, page, boardIDs, byPage); err != nil {
+			return nil, err
+		}
+	}
+	return byPage, nil
+}
+
+// queryBoardAssets adds page of the assets of each of boardIDs to byPage
+func (r *Resolver) queryBoardAssets(ctx context.Context, page connectionPage, boardIDs []string, byPage map[pageKey][]*model.Asset) error {
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query, args := pageQuery("assets", "id, name, type, url, status, board_id, approved_by, approved_at, platform_rejection_reason, rejection_reason, duplicate_of, scheduled_at, thumbnail_url, created_at, updated_at", "board_id", boardIDs, page)
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query assets: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var asset model.Asset
+		var approvedBy sql.NullString
+		err := rows.Scan(
+			&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
+			&asset.BoardID, &approvedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+			&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan asset: %w", err)
+		}
+		if approvedBy.Valid {
+			asset.ApprovedBy = &model.User{ID: approvedBy.String}
+		}
+		key := pageKey{parentID: asset.BoardID, page: page}
+		byPage[key] = append(byPage[key], &asset)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query assets: %w", err)
+	}
+
+	return nil
+}
+
+// groupPages groups the parent IDs of keys by page, so that each page is
+// fetched for all its parents with one query
+func groupPages(keys []pageKey) map[connectionPage][]string {
+	groups := make(map[connectionPage][]string)
+	for _, key := range keys {
+		groups[key.page] = append(groups[key.page], key.parentID)
+	}
+	return groups
+}
+
+// pageQuery returns the query selecting columns of the rows of table on page
+// for each parent whose parentColumn is in parentIDs, in the order of the page,
+// leaving out deleted rows. Each parent gets one row past the page when another
+// page follows.
+func pageQuery(table, columns, parentColumn string, parentIDs []string, page connectionPage) (string, []interface{}) {
+	where := parentColumn + " = ANY($1::uuid[]) AND deleted_at IS NULL"
+	filter, args := page.filter([]interface{}{pq.Array(parentIDs)})
+	if filter != "" {
+		where += " AND " + filter
+	}
+	args = append(args, page.fetchLimit())
+
+	return fmt.Sprintf(`
+		SELECT %[1]s FROM (
+			SELECT %[1]s, ROW_NUMBER() OVER (PARTITION BY %[2]s ORDER BY %[3]s) AS position
+			FROM %[4]s WHERE %[5]s
+		) page
+		WHERE position <= $%[6]d
+		ORDER BY %[3]s
+	`, columns, parentColumn, page.order(), table, where, len(args)), args
+}
@@ -0,0 +1,156 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingFetch returns a fetch that doubles its keys and records each batch it is called with
+func countingFetch() (func(ctx context.Context, keys []int) (map[int]string, error), func() [][]int) {
+	var mu sync.Mutex
+	var batches [][]int
+
+	fetch := func(ctx context.Context, keys []int) (map[int]string, error) {
+		mu.Lock()
+		batches = append(batches, append([]int(nil), keys...))
+		mu.Unlock()
+
+		results := make(map[int]string, len(keys))
+		for _, key := range keys {
+			if key >= 0 {
+				results[key] = fmt.Sprint(key * 2)
+			}
+		}
+		return results, nil
+	}
+
+	return fetch, func() [][]int {
+		mu.Lock()
+		defer mu.Unlock()
+		return batches
+	}
+}
+
+// loadAll loads keys concurrently and returns the results in key order
+func loadAll(t *testing.T, loader *batchLoader[int, string], keys []int) []string {
+	results := make([]string, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i, key int) {
+			defer wg.Done()
+			value, err := loader.Load(context.Background(), key)
+			assert.NoError(t, err)
+			results[i] = value
+		}(i, key)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func TestBatchLoader_BatchesConcurrentLoads(t *testing.T) {
+	fetch, batches := countingFetch()
+	loader := newBatchLoader(fetch)
+
+	results := loadAll(t, loader, []int{1, 2, 3, 2, 1, -1})
+
+	assert.Equal(t, []string{"2", "4", "6", "4", "2", ""}, results)
+	require.Len(t, batches(), 1)
+
+	// Each key is fetched once
+	keys := batches()[0]
+	sort.Ints(keys)
+	assert.Equal(t, []int{-1, 1, 2, 3}, keys)
+}
+
+func TestBatchLoader_SplitsLargeBatches(t *testing.T) {
+	fetch, batches := countingFetch()
+	loader := newBatchLoader(fetch)
+	loader.maxBatch = 10
+
+	keys := make([]int, 25)
+	for i := range keys {
+		keys[i] = i
+	}
+	results := loadAll(t, loader, keys)
+
+	for i, result := range results {
+		assert.Equal(t, fmt.Sprint(i*2), result)
+	}
+	total := 0
+	for _, batch := range batches() {
+		assert.LessOrEqual(t, len(batch), 10)
+		total += len(batch)
+	}
+	assert.Equal(t, 25, total)
+}
+
+func TestBatchLoader_LaterLoadsStartNewBatch(t *testing.T) {
+	fetch, batches := countingFetch()
+	loader := newBatchLoader(fetch)
+
+	loadAll(t, loader, []int{1, 2})
+	loadAll(t, loader, []int{1, 2})
+
+	assert.Len(t, batches(), 2)
+}
+
+func TestBatchLoader_Error(t *testing.T) {
+	loader := newBatchLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+		return nil, errors.New("connection reset")
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(key int) {
+			defer wg.Done()
+			_, err := loader.Load(context.Background(), key)
+			assert.EqualError(t, err, "connection reset")
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestBatchLoader_CancelledContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	loader := newBatchLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+		<-release
+		return nil, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := loader.Load(ctx, 1)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestLoaderMiddleware_LoadersPerRequest(t *testing.T) {
+	resolver := &Resolver{}
+
+	var seen []*Loaders
+	handler := resolver.LoaderMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		loaders := resolver.loaders(r.Context())
+		assert.Same(t, loaders, resolver.loaders(r.Context()))
+		seen = append(seen, loaders)
+	}))
+
+	for i := 0; i < 2; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/query", nil))
+	}
+
+	require.Len(t, seen, 2)
+	assert.NotSame(t, seen[0], seen[1])
+}
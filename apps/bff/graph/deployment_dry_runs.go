@@ -0,0 +1,196 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+// dryRunApproveAsset checks what approving an asset would deploy without
+// approving it, and returns the asset with the outcome as its dryRunReport
+func (r *Resolver) dryRunApproveAsset(ctx context.Context, assetID string) (*model.Asset, error) {
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The project owner is the tenant whose platform credentials are checked
+	var asset model.Asset
+	var projectID, tenantID string
+	var variantGroup sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		SELECT a.id, a.name, a.type, a.url, a.status, a.board_id, a.approved_by, a.approved_at, a.platform_rejection_reason, a.rejection_reason, a.duplicate_of,
+		       a.scheduled_at, a.thumbnail_url, a.created_at, a.updated_at, a.variant_group, p.id, p.owner_id
+		FROM assets a
+		JOIN boards b ON a.board_id = b.id
+		JOIN projects p ON b.project_id = p.id
+		WHERE a.id = $1 AND a.deleted_at IS NULL
+	`, assetID).Scan(
+		&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
+		&asset.BoardID, &asset.ApprovedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+		&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt, &variantGroup, &projectID, &tenantID,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("asset not found")
+		}
+		return nil, fmt.Errorf("failed to query asset: %w", err)
+	}
+
+	if err := assetStatusMachine.ValidateTransition(asset.Status, model.AssetStatusApproved); err != nil {
+		return nil, err
+	}
+
+	// Nothing is written, so release the connection before waiting on the checks
+	tx.Rollback()
+
+	platforms, err := r.credentialPlatforms(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(platforms) == 0 {
+		return nil, fmt.Errorf("the project owner has no platform credentials to deploy with")
+	}
+
+	metadata, err := json.Marshal(newApprovalMetadata(asset.Type, derefString(asset.URL), platforms))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode asset metadata: %w", err)
+	}
+
+	report, err := r.NatsConn.RequestDeploymentDryRun(ctx, nats.DeploymentDryRunRequest{
+		AssetID:      asset.ID,
+		ProjectID:    projectID,
+		TenantID:     tenantID,
+		Status:       strings.ToLower(string(model.AssetStatusApproved)),
+		PrevStatus:   strings.ToLower(string(asset.Status)),
+		ContentType:  assetContentType(asset.Type),
+		Title:        asset.Name,
+		Metadata:     metadata,
+		ScheduledAt:  asset.ScheduledAt,
+		VariantGroup: variantGroup.String,
+	}, 60*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check asset deployment: %w", err)
+	}
+
+	asset.DryRunReport = fromNatsValidationReport(report)
+	return &asset, nil
+}
+
+// approvalMetadata is the deployment metadata of an approved asset: it is
+// deployed to the platforms its project owner has credentials for
+type approvalMetadata struct {
+	Platforms []string `json:"platforms"`
+	model.DeploymentMetadata
+}
+
+// newApprovalMetadata returns the deployment metadata of an asset of assetType
+// at url deployed to the connectors platforms
+func newApprovalMetadata(assetType model.AssetType, url string, platforms []string) approvalMetadata {
+	return approvalMetadata{
+		Platforms:          platforms,
+		DeploymentMetadata: assetDeploymentMetadata(assetType, url),
+	}
+}
+
+// credentialPlatforms returns, sorted, the connectors platforms supported by the
+// GraphQL API that the tenant tenantID has stored credentials for: those its
+// approved assets are deployed to. Credentials are not visible to the
+// application role, so they are read outside of a user transaction.
+func (r *Resolver) credentialPlatforms(ctx context.Context, tenantID string) ([]string, error) {
+	rows, err := r.DB.Writer().QueryContext(ctx, `SELECT platform FROM platform_credentials WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query platform credentials: %w", err)
+	}
+	defer rows.Close()
+
+	supported := make(map[string]bool, len(connectorPlatforms))
+	for _, platform := range connectorPlatforms {
+		supported[platform] = true
+	}
+
+	var platforms []string
+	for rows.Next() {
+		var platform string
+		if err := rows.Scan(&platform); err != nil {
+			return nil, fmt.Errorf("failed to scan platform credentials: %w", err)
+		}
+		if supported[platform] {
+			platforms = append(platforms, platform)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query platform credentials: %w", err)
+	}
+
+	sort.Strings(platforms)
+	return platforms, nil
+}
+
+// assetContentType returns the connectors content type of assets of assetType
+func assetContentType(assetType model.AssetType) string {
+	if assetType == model.AssetTypeVideo {
+		return "video_script"
+	}
+	return "social_media"
+}
+
+// fromNatsValidationReport converts a validation report returned by the
+// connectors service. Reports of platforms without a GraphQL counterpart are
+// left out.
+func fromNatsValidationReport(report *nats.DeploymentValidationReport) *model.DeploymentValidationReport {
+	platformsByConnector := make(map[string]model.CampaignPlatform, len(connectorPlatforms))
+	for graphPlatform, connectorPlatform := range connectorPlatforms {
+		platformsByConnector[connectorPlatform] = graphPlatform
+	}
+
+	result := &model.DeploymentValidationReport{
+		Valid:     report.Valid,
+		Platforms: []*model.PlatformValidationReport{},
+	}
+	for _, platformReport := range report.Platforms {
+		platform, ok := platformsByConnector[platformReport.Platform]
+		if !ok {
+			continue
+		}
+
+		converted := &model.PlatformValidationReport{
+			Platform:          platform,
+			Valid:             platformReport.Valid,
+			CredentialsValid:  platformReport.CredentialsValid,
+			CreativeComplete:  platformReport.CreativeComplete,
+			BudgetWithinRange: platformReport.BudgetWithinRange,
+			TargetingValid:    platformReport.TargetingValid,
+			Errors:            make([]string, len(platformReport.Errors)),
+		}
+		for i, err := range platformReport.Errors {
+			converted.Errors[i] = err.Field + " " + err.Message
+		}
+		if platformReport.EstimatedAudienceSize > 0 {
+			size := int(platformReport.EstimatedAudienceSize)
+			converted.EstimatedAudienceSize = &size
+		}
+		if estimate := platformReport.CostEstimate; estimate != nil {
+			impressions, clicks := int(estimate.EstimatedImpressions), int(estimate.EstimatedClicks)
+			converted.EstimatedImpressions = &impressions
+			converted.EstimatedClicks = &clicks
+			converted.EstimatedSpend = &estimate.EstimatedSpend
+			converted.Currency = &estimate.Currency
+		}
+		if platformReport.EstimateError != "" {
+			converted.EstimateError = &platformReport.EstimateError
+		}
+
+		result.Platforms = append(result.Platforms, converted)
+	}
+
+	return result
+}
@@ -0,0 +1,73 @@
+package graph
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+func TestNewApprovalMetadata(t *testing.T) {
+	metadata, err := json.Marshal(newApprovalMetadata(model.AssetTypeVideo, "https://cdn.example.com/ad.mp4", []string{"meta"}))
+	require.NoError(t, err)
+
+	// The platforms the asset would be deployed to are checked, with the asset as
+	// the creative
+	assert.JSONEq(t, `{
+		"platforms": ["meta"],
+		"creative_specs": {"video_url": "https://cdn.example.com/ad.mp4"}
+	}`, string(metadata))
+	assert.Equal(t, "video_script", assetContentType(model.AssetTypeVideo))
+	assert.Equal(t, "social_media", assetContentType(model.AssetTypeImage))
+}
+
+func TestFromNatsValidationReport(t *testing.T) {
+	report := fromNatsValidationReport(&nats.DeploymentValidationReport{
+		AssetID: "asset-1",
+		Valid:   false,
+		Platforms: []nats.PlatformValidationReport{
+			{
+				Platform:              "meta",
+				CredentialsValid:      true,
+				CreativeComplete:      true,
+				TargetingValid:        true,
+				EstimatedAudienceSize: 700000,
+				CostEstimate:          &nats.CostEstimate{EstimatedImpressions: 49000, EstimatedClicks: 420, EstimatedSpend: 280, Currency: "USD"},
+				Errors:                []nats.ValidationError{{Field: "budget", Message: "must be positive"}},
+			},
+			{
+				Platform:      "google_ads",
+				Valid:         true,
+				EstimateError: "keywords are required to estimate Google Ads cost",
+			},
+			// Platforms without a GraphQL counterpart are left out
+			{Platform: "tiktok"},
+		},
+	})
+
+	assert.False(t, report.Valid)
+	require.Len(t, report.Platforms, 2)
+
+	meta := report.Platforms[0]
+	assert.Equal(t, model.CampaignPlatformMeta, meta.Platform)
+	assert.False(t, meta.BudgetWithinRange)
+	assert.Equal(t, []string{"budget must be positive"}, meta.Errors)
+	require.NotNil(t, meta.EstimatedAudienceSize)
+	assert.Equal(t, 700000, *meta.EstimatedAudienceSize)
+	require.NotNil(t, meta.EstimatedSpend)
+	assert.Equal(t, 280.0, *meta.EstimatedSpend)
+	assert.Equal(t, "USD", *meta.Currency)
+	assert.Nil(t, meta.EstimateError)
+
+	googleAds := report.Platforms[1]
+	assert.Equal(t, model.CampaignPlatformGoogleAds, googleAds.Platform)
+	assert.True(t, googleAds.Valid)
+	assert.Empty(t, googleAds.Errors)
+	assert.Nil(t, googleAds.EstimatedAudienceSize)
+	assert.Nil(t, googleAds.EstimatedSpend)
+	require.NotNil(t, googleAds.EstimateError)
+}
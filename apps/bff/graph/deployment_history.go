@@ -0,0 +1,196 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+// RecordDeployment adds the deployment of an asset to a platform reported by the
+// connectors service to the deployment history. Dry runs, whose status is not a
+// DeploymentStatus, are left out. It is called for events from the connectors
+// service rather than by a user, so row-level security does not apply.
+func (r *Resolver) RecordDeployment(ctx context.Context, event *nats.DeploymentStatusChangedEvent) error {
+	result := event.DeploymentResult
+	if !model.DeploymentStatus(strings.ToUpper(result.Status)).IsValid() {
+		return nil
+	}
+
+	deployedAt := result.DeployedAt
+	if deployedAt.IsZero() {
+		deployedAt = event.Timestamp
+	}
+	if deployedAt.IsZero() {
+		deployedAt = time.Now()
+	}
+
+	_, err := r.DB.Writer().ExecContext(ctx, `
+		INSERT INTO deployment_history (asset_id, project_id, platform, status, platform_id, error, deployed_at, duration_ms, retry_count)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8, $9)
+	`, event.AssetID, event.ProjectID, strings.ToLower(event.Platform), strings.ToLower(result.Status),
+		result.PlatformID, result.Error, deployedAt, result.Metrics.Duration.Milliseconds(), result.Metrics.RetryCount)
+	if err != nil {
+		return fmt.Errorf("failed to record deployment: %w", err)
+	}
+
+	return nil
+}
+
+// deploymentHistory returns the page of at most first deployments after the
+// cursor after, newest first, that match the filters given
+func (r *Resolver) deploymentHistory(ctx context.Context, assetID *string, projectID *string, platform *model.CampaignPlatform, status *model.DeploymentStatus, first int, after *string, since *time.Time, until *time.Time) (*model.DeploymentConnection, error) {
+	if first < 1 || first > maxPageSize {
+		return nil, fmt.Errorf("first must be between 1 and %d", maxPageSize)
+	}
+
+	query := `
+		SELECT h.id, h.platform, h.status, h.platform_id, h.error, h.deployed_at, h.duration_ms, h.retry_count,
+			a.id, a.name, a.type, a.url, a.status, a.board_id, a.approved_by, a.approved_at, a.platform_rejection_reason, a.rejection_reason, a.duplicate_of, a.scheduled_at, a.thumbnail_url, a.created_at, a.updated_at
+		FROM deployment_history h
+		JOIN assets a ON a.id = h.asset_id AND a.deleted_at IS NULL
+		WHERE TRUE`
+	var args []interface{}
+
+	if assetID != nil {
+		args = append(args, *assetID)
+		query += fmt.Sprintf(" AND h.asset_id = $%d", len(args))
+	}
+	if projectID != nil {
+		args = append(args, *projectID)
+		query += fmt.Sprintf(" AND h.project_id = $%d", len(args))
+	}
+	if platform != nil {
+		args = append(args, strings.ToLower(string(*platform)))
+		query += fmt.Sprintf(" AND h.platform = $%d", len(args))
+	}
+	if status != nil {
+		args = append(args, strings.ToLower(string(*status)))
+		query += fmt.Sprintf(" AND h.status = $%d", len(args))
+	}
+	if since != nil {
+		args = append(args, *since)
+		query += fmt.Sprintf(" AND h.deployed_at >= $%d", len(args))
+	}
+	if until != nil {
+		args = append(args, *until)
+		query += fmt.Sprintf(" AND h.deployed_at < $%d", len(args))
+	}
+
+	if after != nil {
+		deployedAt, id, err := decodeCursor(*after)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, deployedAt, id)
+		query += fmt.Sprintf(" AND (h.deployed_at, h.id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	args = append(args, first+1)
+	query += fmt.Sprintf(" ORDER BY h.deployed_at DESC, h.id DESC LIMIT $%d", len(args))
+
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deployment history: %w", err)
+	}
+	defer rows.Close()
+
+	connection := &model.DeploymentConnection{
+		Edges:    []*model.DeploymentEdge{},
+		PageInfo: &model.PageInfo{HasPreviousPage: after != nil},
+	}
+	for rows.Next() {
+		if len(connection.Edges) == first {
+			connection.PageInfo.HasNextPage = true
+			break
+		}
+
+		var deployment model.Deployment
+		var asset model.Asset
+		var deploymentPlatform, deploymentStatus string
+		var approvedBy sql.NullString
+		err := rows.Scan(
+			&deployment.ID, &deploymentPlatform, &deploymentStatus, &deployment.PlatformID, &deployment.Error,
+			&deployment.DeployedAt, &deployment.DurationMs, &deployment.RetryCount,
+			&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
+			&asset.BoardID, &approvedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+			&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan deployment: %w", err)
+		}
+		if approvedBy.Valid {
+			asset.ApprovedBy = &model.User{ID: approvedBy.String}
+		}
+		deployment.Platform = model.CampaignPlatform(strings.ToUpper(deploymentPlatform))
+		deployment.Status = model.DeploymentStatus(strings.ToUpper(deploymentStatus))
+		deployment.AssetID = asset.ID
+		deployment.Asset = &asset
+
+		cursor := encodeCursor(deployment.DeployedAt, deployment.ID)
+		connection.Edges = append(connection.Edges, &model.DeploymentEdge{Cursor: cursor, Node: &deployment})
+		if connection.PageInfo.StartCursor == nil {
+			connection.PageInfo.StartCursor = &cursor
+		}
+		connection.PageInfo.EndCursor = &cursor
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read deployment history: %w", err)
+	}
+
+	return connection, nil
+}
+
+// SuccessRate loads the share of the finished deployments of a project that
+// succeeded, 0 for projects without any
+func (l *Loaders) SuccessRate(ctx context.Context, projectID string) (float64, error) {
+	return l.successRates.Load(ctx, projectID)
+}
+
+func (r *Resolver) fetchSuccessRates(ctx context.Context, ids []string) (map[string]float64, error) {
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT project_id, COUNT(*) FILTER (WHERE status = 'success')::double precision / COUNT(*)
+		FROM deployment_history
+		WHERE project_id = ANY($1::uuid[]) AND status IN ('success', 'failed')
+		GROUP BY project_id
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deployment success rates: %w", err)
+	}
+	defer rows.Close()
+
+	rates := make(map[string]float64, len(ids))
+	for rows.Next() {
+		var projectID string
+		var rate float64
+		if err := rows.Scan(&projectID, &rate); err != nil {
+			return nil, fmt.Errorf("failed to scan deployment success rate: %w", err)
+		}
+		rates[projectID] = rate
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query deployment success rates: %w", err)
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,120 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+// validateRollback checks that an asset in status may be rolled back. An asset
+// already rolled back on one platform may still be rolled back on another.
+func validateRollback(status model.AssetStatus) error {
+	if status == model.AssetStatusRolledBack {
+		return nil
+	}
+	return assetStatusMachine.ValidateTransition(status, model.AssetStatusRolledBack)
+}
+
+// rollbackDeployment has the connectors service pause the ad of an asset on a
+// platform, then records the rollback and marks the asset as rolled back. Admins
+// roll back assets of any tenant, so row-level security does not apply.
+func (r *Resolver) rollbackDeployment(ctx context.Context, userID, assetID string, platform model.CampaignPlatform, reason *string) (*model.DeploymentRollbackResult, error) {
+	connectorPlatform, ok := connectorPlatforms[platform]
+	if !ok {
+		return nil, fmt.Errorf("unsupported platform: %s", platform)
+	}
+
+	var currentStatus model.AssetStatus
+	err := r.DB.Writer().QueryRowContext(ctx, `SELECT status FROM assets WHERE id = $1 AND deleted_at IS NULL`, assetID).Scan(&currentStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("asset not found")
+		}
+		return nil, fmt.Errorf("failed to query asset: %w", err)
+	}
+
+	if err := validateRollback(currentStatus); err != nil {
+		return nil, err
+	}
+
+	request := nats.DeploymentRollbackRequest{
+		AssetID:     assetID,
+		Platform:    connectorPlatform,
+		RequestedBy: userID,
+	}
+	if reason != nil {
+		request.Reason = *reason
+	}
+
+	rollback, err := r.NatsConn.RequestDeploymentRollback(ctx, request, 30*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll back deployment: %w", err)
+	}
+
+	tx, err := r.DB.Writer().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO deployment_rollbacks (asset_id, platform, platform_campaign_id, platform_ad_id, rolled_back_by, rolled_back_at, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, assetID, connectorPlatform, rollback.PlatformCampaignID, rollback.PlatformAdID, userID, rollback.RolledBackAt, reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record deployment rollback: %w", err)
+	}
+
+	// The ad is paused already, so the asset is marked as rolled back even if its
+	// status changed since it was read
+	var asset model.Asset
+	var approvedBy sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		UPDATE assets
+		SET status = $1, updated_at = $2
+		WHERE id = $3
+		RETURNING id, name, type, url, status, board_id, approved_by, approved_at, platform_rejection_reason, rejection_reason, duplicate_of, scheduled_at, thumbnail_url, created_at, updated_at
+	`, model.AssetStatusRolledBack, time.Now(), assetID).Scan(
+		&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
+		&asset.BoardID, &approvedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+		&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll back asset: %w", err)
+	}
+	if approvedBy.Valid {
+		asset.ApprovedBy = &model.User{ID: approvedBy.String}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit deployment rollback: %w", err)
+	}
+
+	result := &model.DeploymentRollbackResult{
+		AssetID:            asset.ID,
+		Platform:           platform,
+		PlatformCampaignID: rollback.PlatformCampaignID,
+		PlatformAdID:       rollback.PlatformAdID,
+		RolledBackAt:       rollback.RolledBackAt,
+		Reason:             reason,
+		Asset:              &asset,
+	}
+
+	r.audit(ctx, "rollbackDeployment", "asset", asset.ID, map[string]interface{}{"status": currentStatus}, result)
+
+	if r.NatsConn != nil {
+		if err := r.NatsConn.PublishBoardUpdate(asset.BoardID, &asset); err != nil {
+			log.Printf("Failed to publish board update: %v", err)
+		}
+		if err := r.NatsConn.PublishAssetUpdate(asset.BoardID, &asset); err != nil {
+			log.Printf("Failed to publish asset update: %v", err)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,41 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+)
+
+func TestMutationResolver_RollbackDeploymentRequiresAdmin(t *testing.T) {
+	resolver := &mutationResolver{&Resolver{}}
+
+	_, err := resolver.RollbackDeployment(context.Background(), "asset-1", model.CampaignPlatformMeta, nil)
+	assert.EqualError(t, err, "unauthorized")
+
+	user := context.WithValue(context.Background(), "user", &auth.User{ID: "user-1", Role: "user"})
+	_, err = resolver.RollbackDeployment(user, "asset-1", model.CampaignPlatformMeta, nil)
+	assert.EqualError(t, err, "admin access required")
+}
+
+func TestMutationResolver_RollbackDeploymentRejectsUnsupportedPlatform(t *testing.T) {
+	resolver := &mutationResolver{&Resolver{}}
+
+	admin := context.WithValue(context.Background(), "user", &auth.User{ID: "admin-1", Role: "admin"})
+	_, err := resolver.RollbackDeployment(admin, "asset-1", model.CampaignPlatformTwitter, nil)
+	assert.EqualError(t, err, "unsupported platform: TWITTER")
+}
+
+func TestValidateRollback(t *testing.T) {
+	assert.NoError(t, validateRollback(model.AssetStatusDeployed))
+	// Rolled back on one platform, the asset may be rolled back on another
+	assert.NoError(t, validateRollback(model.AssetStatusRolledBack))
+
+	for _, status := range []model.AssetStatus{model.AssetStatusApproved, model.AssetStatusReview, model.AssetStatusFailed} {
+		var transitionErr *InvalidTransitionError
+		assert.ErrorAs(t, validateRollback(status), &transitionErr)
+	}
+}
@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+// validateDeploymentMetadata checks template settings before they are stored
+func validateDeploymentMetadata(metadata model.DeploymentMetadata) error {
+	if _, ok := connectorPlatforms[metadata.Platform]; !ok {
+		return fmt.Errorf("unsupported platform: %s", metadata.Platform)
+	}
+	if metadata.Budget < 0 {
+		return fmt.Errorf("budget must not be negative")
+	}
+	if d := metadata.Demographics; d != nil {
+		if d.AgeMin < 0 || d.AgeMax < 0 {
+			return fmt.Errorf("ages must not be negative")
+		}
+		if d.AgeMax != 0 && d.AgeMin > d.AgeMax {
+			return fmt.Errorf("minimum age must not be above maximum age")
+		}
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanDeploymentTemplate scans a deployment_templates row selected as id, name,
+// owner_id, platform, content_type, template_metadata, created_at
+func scanDeploymentTemplate(row rowScanner) (*model.DeploymentTemplate, error) {
+	var template model.DeploymentTemplate
+	var raw []byte
+	if err := row.Scan(&template.ID, &template.Name, &template.OwnerID, &template.Platform,
+		&template.ContentType, &raw, &template.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	template.Metadata = &model.DeploymentMetadata{}
+	if err := json.Unmarshal(raw, template.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode template metadata: %w", err)
+	}
+	template.Metadata.Platform = template.Platform
+	template.Metadata.ContentType = template.ContentType
+
+	return &template, nil
+}
+
+// assetDeploymentMetadata returns the settings an asset contributes to a
+// deployment: its URL as the creative of matching type
+func assetDeploymentMetadata(assetType model.AssetType, url string) model.DeploymentMetadata {
+	var metadata model.DeploymentMetadata
+	switch assetType {
+	case model.AssetTypeImage:
+		metadata.CreativeSpecs = &model.CreativeSpecs{ImageURL: url}
+	case model.AssetTypeVideo:
+		metadata.CreativeSpecs = &model.CreativeSpecs{VideoURL: url}
+	}
+	return metadata
+}
+
+func toNatsDeploymentTemplate(template *model.DeploymentTemplate) (nats.DeploymentTemplate, error) {
+	metadata, err := json.Marshal(template.Metadata)
+	if err != nil {
+		return nats.DeploymentTemplate{}, fmt.Errorf("failed to encode template metadata: %w", err)
+	}
+
+	return nats.DeploymentTemplate{
+		ID:          template.ID,
+		Name:        template.Name,
+		OwnerID:     template.OwnerID,
+		Platform:    connectorPlatforms[template.Platform],
+		ContentType: strings.ToLower(string(template.ContentType)),
+		Metadata:    metadata,
+		CreatedAt:   template.CreatedAt,
+	}, nil
+}
+
+func fromNatsDeploymentResult(result *nats.DeploymentResult, assetID string, platform model.CampaignPlatform) *model.DeploymentResult {
+	deployment := &model.DeploymentResult{
+		AssetID:    assetID,
+		Platform:   platform,
+		Status:     result.Status,
+		DeployedAt: result.DeployedAt,
+	}
+	if result.PlatformID != "" {
+		deployment.PlatformID = &result.PlatformID
+	}
+	if result.PlatformURL != "" {
+		deployment.PlatformURL = &result.PlatformURL
+	}
+	if result.Error != "" {
+		deployment.Error = &result.Error
+	}
+	return deployment
+}
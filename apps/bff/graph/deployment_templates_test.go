@@ -0,0 +1,87 @@
+package graph
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+func TestToNatsDeploymentTemplate(t *testing.T) {
+	template := &model.DeploymentTemplate{
+		ID:          "template-1",
+		Name:        "Search always-on",
+		OwnerID:     "user-1",
+		Platform:    model.CampaignPlatformGoogleAds,
+		ContentType: model.DeploymentContentTypeBlogPost,
+		Metadata: &model.DeploymentMetadata{
+			Platform:    model.CampaignPlatformGoogleAds,
+			ContentType: model.DeploymentContentTypeBlogPost,
+			Budget:      25,
+			Keywords:    []string{"trail shoes"},
+		},
+		CreatedAt: time.Now(),
+	}
+
+	natsTemplate, err := toNatsDeploymentTemplate(template)
+	require.NoError(t, err)
+
+	// Platform and content type use the connectors service's identifiers
+	assert.Equal(t, "google_ads", natsTemplate.Platform)
+	assert.Equal(t, "blog_post", natsTemplate.ContentType)
+	assert.JSONEq(t, `{"budget": 25, "keywords": ["trail shoes"]}`, string(natsTemplate.Metadata))
+}
+
+func TestAssetDeploymentMetadata(t *testing.T) {
+	image, err := json.Marshal(assetDeploymentMetadata(model.AssetTypeImage, "https://cdn.example.com/banner.png"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"creative_specs": {"image_url": "https://cdn.example.com/banner.png"}}`, string(image))
+
+	video, err := json.Marshal(assetDeploymentMetadata(model.AssetTypeVideo, "https://cdn.example.com/spot.mp4"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"creative_specs": {"video_url": "https://cdn.example.com/spot.mp4"}}`, string(video))
+
+	// Other assets leave the creative to the template
+	document, err := json.Marshal(assetDeploymentMetadata(model.AssetTypeDocument, "https://cdn.example.com/brief.pdf"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(document))
+}
+
+func TestFromNatsDeploymentResult(t *testing.T) {
+	result := fromNatsDeploymentResult(&nats.DeploymentResult{
+		Status: "failed",
+		Error:  "unsupported platform: linkedin",
+	}, "asset-1", model.CampaignPlatformMeta)
+
+	assert.Equal(t, "asset-1", result.AssetID)
+	assert.Equal(t, model.CampaignPlatformMeta, result.Platform)
+	assert.Equal(t, "failed", result.Status)
+	assert.Nil(t, result.PlatformID)
+	require.NotNil(t, result.Error)
+	assert.Equal(t, "unsupported platform: linkedin", *result.Error)
+}
+
+func TestHashCustomAudienceEmails(t *testing.T) {
+	demographics := &model.Demographics{
+		Locations:            []string{"US"},
+		CustomAudienceEmails: []string{" Jane@Example.com ", "", "john@example.com"},
+	}
+
+	hashCustomAudienceEmails(demographics)
+
+	// Emails are normalized before hashing and never encoded in the clear
+	metadata, err := json.Marshal(model.DeploymentMetadata{Demographics: demographics})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"demographics": {"locations": ["US"], "custom_audience_email_hashes": [
+		"8c87b489ce35cf2e2f39f80e282cb2e804932a56a213983eeeb428407d43b52d",
+		"855f96e983f1f8e8be944692b6f719fd54329826cb62e98015efee8e2e071dd4"
+	]}}`, string(metadata))
+	assert.Nil(t, demographics.CustomAudienceEmails)
+
+	hashCustomAudienceEmails(nil)
+}
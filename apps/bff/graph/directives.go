@@ -0,0 +1,162 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/generated"
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+)
+
+// roleRanks orders the project roles, each allowing what the lower ones do
+var roleRanks = map[model.Role]int{
+	model.RoleViewer: 1,
+	model.RoleEditor: 2,
+	model.RoleAdmin:  3,
+}
+
+// memberRoles are the project roles of the roles stored in project_members
+var memberRoles = map[string]model.Role{
+	"viewer": model.RoleViewer,
+	"editor": model.RoleEditor,
+	"admin":  model.RoleAdmin,
+}
+
+// organizationMemberRoles are the project roles the members of a project's
+// organization have in it
+var organizationMemberRoles = map[string]model.Role{
+	"owner":  model.RoleAdmin,
+	"admin":  model.RoleAdmin,
+	"member": model.RoleEditor,
+}
+
+// Directives returns the implementations of the schema directives
+func (r *Resolver) Directives() generated.DirectiveRoot {
+	return generated.DirectiveRoot{
+		HasRole: r.HasRole,
+	}
+}
+
+// HasRole implements @hasRole: the field resolves only for users with at least
+// role in the project of its arguments, and fails with code FORBIDDEN otherwise
+func (r *Resolver) HasRole(ctx context.Context, obj interface{}, next graphql.Resolver, role model.Role) (interface{}, error) {
+	user, ok := ctx.Value("user").(*auth.User)
+	if !ok {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	fc := graphql.GetFieldContext(ctx)
+	projectID, err := r.fieldProject(ctx, fc)
+	if err != nil {
+		return nil, err
+	}
+	if user.ProjectScope != nil && *user.ProjectScope != projectID {
+		return nil, forbidden("token is scoped to project %s", *user.ProjectScope)
+	}
+
+	userRole, err := r.projectRole(ctx, user, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if roleRanks[userRole] < roleRanks[role] {
+		return nil, forbidden("%s requires the %s role in project %s", fc.Field.Name, role, projectID)
+	}
+
+	return next(ctx)
+}
+
+// fieldProject returns the project of the arguments of a field with @hasRole
+func (r *Resolver) fieldProject(ctx context.Context, fc *graphql.FieldContext) (string, error) {
+	switch fc.Object + "." + fc.Field.Name {
+	case "Mutation.deleteProject":
+		return fc.Args["id"].(string), nil
+	case "Mutation.createBoard":
+		return fc.Args["input"].(model.CreateBoardInput).ProjectID, nil
+	case "Mutation.uploadAsset":
+		return r.boardProject(ctx, fc.Args["input"].(model.UploadAssetInput).BoardID)
+	case "Mutation.approveAsset":
+		return r.assetProject(ctx, fc.Args["assetId"].(string))
+	}
+	return "", fmt.Errorf("no project for @hasRole on %s.%s", fc.Object, fc.Field.Name)
+}
+
+// projectRole returns the role of user in the project projectID, the highest of
+// the roles of its owner, members and organization members. Projects of an
+// organization are only accessible to its members. Admins of the service are
+// admins of every project. It returns an empty role for users without access
+// to the project and ErrProjectNotFound when it does not exist.
+func (r *Resolver) projectRole(ctx context.Context, user *auth.User, projectID string) (model.Role, error) {
+	if r.Permissions != nil {
+		if role, ok := r.Permissions.Get(user.ID, projectID); ok {
+			return role, nil
+		}
+	}
+
+	var owner, organizationProject bool
+	var memberRole, organizationRole sql.NullString
+	err := r.reader(ctx).QueryRowContext(ctx, `
+		SELECT p.owner_id = $2, p.org_id IS NOT NULL,
+			(SELECT m.role FROM project_members m WHERE m.project_id = p.id AND m.user_id = $2),
+			(SELECT m.role FROM organization_members m WHERE m.org_id = p.org_id AND m.user_id = $2)
+		FROM projects p WHERE p.id = $1 AND p.deleted_at IS NULL
+	`, projectID, user.ID).Scan(&owner, &organizationProject, &memberRole, &organizationRole)
+	if err == sql.ErrNoRows {
+		return "", ErrProjectNotFound
+	} else if err != nil {
+		return "", fmt.Errorf("failed to query project role: %w", err)
+	}
+
+	var role model.Role
+	switch {
+	case user.Role == "admin":
+		role = model.RoleAdmin
+	case organizationProject && !organizationRole.Valid:
+		// Owning or being shared a project of an organization gives no access
+		// to it after leaving the organization
+	case owner:
+		role = model.RoleAdmin
+	default:
+		for _, candidate := range []model.Role{memberRoles[memberRole.String], organizationMemberRoles[organizationRole.String]} {
+			if roleRanks[candidate] > roleRanks[role] {
+				role = candidate
+			}
+		}
+	}
+
+	if r.Permissions != nil {
+		r.Permissions.Set(user.ID, projectID, role)
+	}
+	return role, nil
+}
+
+// myPermissions returns what the authenticated user may do in the project
+// projectID. Approving assets also requires the admin or reviewer role of the
+// service.
+func (r *Resolver) myPermissions(ctx context.Context, projectID string) (*model.Permissions, error) {
+	user, ok := ctx.Value("user").(*auth.User)
+	if !ok {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	role, err := r.projectRole(ctx, user, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	rank := roleRanks[role]
+	permissions := &model.Permissions{
+		ProjectID:  projectID,
+		CanView:    rank >= roleRanks[model.RoleViewer],
+		CanUpload:  rank >= roleRanks[model.RoleEditor],
+		CanApprove: rank >= roleRanks[model.RoleAdmin] && reviewerRoles[user.Role],
+		CanDelete:  rank >= roleRanks[model.RoleAdmin],
+	}
+	if role != "" {
+		permissions.Role = &role
+	}
+	return permissions, nil
+}
@@ -0,0 +1,94 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/middleware"
+)
+
+// superAdminRole is the role of the support agents allowed to impersonate users
+const superAdminRole = "super_admin"
+
+// impersonateUser issues the super admin admin a token pair acting as the user
+// userID, and logs the start of the impersonation session
+func (r *Resolver) impersonateUser(ctx context.Context, admin *auth.User, userID string) (*model.TokenPair, error) {
+	if admin.ImpersonatedBy != nil {
+		return nil, fmt.Errorf("already impersonating a user")
+	}
+	if userID == admin.ID {
+		return nil, fmt.Errorf("cannot impersonate yourself")
+	}
+
+	email, role, err := r.userIdentity(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == superAdminRole {
+		return nil, fmt.Errorf("cannot impersonate a super admin")
+	}
+
+	pair, err := r.AuthService.GenerateImpersonationTokenPair(r.DB.Writer(), userID, email, role, admin.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+
+	middleware.LogImpersonation(ctx, admin.ID, userID, middleware.ImpersonationStart)
+	r.audit(ctx, "impersonateUser", "user", userID, nil, nil)
+
+	return tokenPairToModel(pair), nil
+}
+
+// endImpersonation revokes the impersonation token of user, issues the super
+// admin impersonating them a token pair of their own, and logs the end of the
+// impersonation session
+func (r *Resolver) endImpersonation(ctx context.Context, user *auth.User) (*model.TokenPair, error) {
+	if user.ImpersonatedBy == nil {
+		return nil, fmt.Errorf("not impersonating a user")
+	}
+	adminID := *user.ImpersonatedBy
+
+	email, role, err := r.userIdentity(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.AuthService.RevokeToken(user.Token); err != nil {
+		return nil, fmt.Errorf("failed to revoke impersonation token: %w", err)
+	}
+
+	pair, err := r.AuthService.GenerateTokenPair(r.DB.Writer(), adminID, email, role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+
+	middleware.LogImpersonation(ctx, adminID, user.ID, middleware.ImpersonationEnd)
+	r.audit(ctx, "endImpersonation", "user", user.ID, nil, nil)
+
+	return tokenPairToModel(pair), nil
+}
+
+// userIdentity returns the email and role of the user userID. Users are not
+// covered by row-level security, so they are read outside of a user transaction.
+func (r *Resolver) userIdentity(ctx context.Context, userID string) (string, string, error) {
+	var email, role string
+	err := r.DB.Writer().QueryRowContext(ctx, `SELECT email, role FROM users WHERE id = $1`, userID).Scan(&email, &role)
+	if err == sql.ErrNoRows {
+		return "", "", fmt.Errorf("user not found")
+	} else if err != nil {
+		return "", "", fmt.Errorf("failed to query user: %w", err)
+	}
+	return email, role, nil
+}
+
+func tokenPairToModel(pair *auth.TokenPair) *model.TokenPair {
+	return &model.TokenPair{
+		AccessToken:  pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    pair.ExpiresIn,
+		TokenType:    pair.TokenType,
+	}
+}
@@ -0,0 +1,53 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/audit"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+)
+
+func TestMutationResolver_ImpersonateUser_Authorization(t *testing.T) {
+	resolver := &mutationResolver{&Resolver{}}
+
+	_, err := resolver.ImpersonateUser(context.Background(), "user-1")
+	assert.EqualError(t, err, "unauthorized")
+
+	// Admins are not support agents
+	admin := context.WithValue(context.Background(), "user", &auth.User{ID: "admin-1", Role: "admin"})
+	_, err = resolver.ImpersonateUser(admin, "user-1")
+	assert.EqualError(t, err, "super admin access required")
+
+	superAdmin := context.WithValue(context.Background(), "user", &auth.User{ID: "admin-1", Role: superAdminRole})
+	_, err = resolver.ImpersonateUser(superAdmin, "admin-1")
+	assert.EqualError(t, err, "cannot impersonate yourself")
+
+	// Impersonation sessions do not nest
+	adminID := "admin-1"
+	impersonated := context.WithValue(context.Background(), "user", &auth.User{ID: "user-1", Role: superAdminRole, ImpersonatedBy: &adminID})
+	_, err = resolver.ImpersonateUser(impersonated, "user-2")
+	assert.EqualError(t, err, "already impersonating a user")
+}
+
+func TestMutationResolver_EndImpersonation_RequiresImpersonation(t *testing.T) {
+	resolver := &mutationResolver{&Resolver{}}
+
+	_, err := resolver.EndImpersonation(context.Background())
+	assert.EqualError(t, err, "unauthorized")
+
+	user := context.WithValue(context.Background(), "user", &auth.User{ID: "user-1", Role: "user"})
+	_, err = resolver.EndImpersonation(user)
+	assert.EqualError(t, err, "not impersonating a user")
+}
+
+func TestAuditEntry_ImpersonatedBy(t *testing.T) {
+	entry := auditEntry(&audit.Entry{ID: "entry-1", UserID: "user-1", ImpersonatedBy: "admin-1"})
+	require.NotNil(t, entry.ImpersonatedBy)
+	assert.Equal(t, "admin-1", *entry.ImpersonatedBy)
+
+	assert.Nil(t, auditEntry(&audit.Entry{ID: "entry-2", UserID: "user-1"}).ImpersonatedBy)
+}
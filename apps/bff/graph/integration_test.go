@@ -1,21 +1,36 @@
 package graph
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	natsgo "github.com/nats-io/nats.go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/assethash"
 	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/autocomplete"
 	"github.com/zerionstudio/zamc-v2/apps/bff/internal/database"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/export"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
 )
 
 // IntegrationTestSuite provides a test suite for integration tests
@@ -47,17 +62,22 @@ func (suite *IntegrationTestSuite) SetupSuite() {
 	err = db.Ping()
 	require.NoError(suite.T(), err)
 
+	// Apply migrations so row-level security policies are in place
+	err = database.Migrate(db, "../migrations")
+	require.NoError(suite.T(), err)
+
 	suite.db = db
 
 	// Setup test resolver with real dependencies
-	dbWrapper := &database.DB{DB: db}
+	dbPool := database.NewPool(db, nil)
 	
 	// For integration tests, we'll use nil for NATS and Auth since we're testing database operations
 	// In a real scenario, you'd set up test instances of these services
 	suite.resolver = &Resolver{
-		DB:          dbWrapper,
+		DB:          dbPool,
 		NatsConn:    nil, // Will be mocked in individual tests if needed
 		AuthService: nil, // Will be mocked in individual tests if needed
+		Cache:       NewResolverCache(),
 	}
 
 	// Create test user context
@@ -97,10 +117,20 @@ func (suite *IntegrationTestSuite) TearDownTest() {
 
 func (suite *IntegrationTestSuite) cleanupTestData() {
 	// Clean up in reverse dependency order
+	suite.db.Exec("DELETE FROM user_preferences WHERE user_id = $1", suite.userID)
+	suite.db.Exec("DELETE FROM deployment_templates WHERE owner_id = $1", suite.userID)
+	suite.db.Exec("DELETE FROM board_templates WHERE created_by = $1", suite.userID)
+	suite.resolver.Cache.InvalidatePreferences(suite.userID)
 	suite.db.Exec("DELETE FROM assets WHERE board_id IN (SELECT id FROM boards WHERE project_id IN (SELECT id FROM projects WHERE owner_id = $1))", suite.userID)
+	suite.db.Exec("DELETE FROM chat_message_reads WHERE user_id = $1", suite.userID)
 	suite.db.Exec("DELETE FROM chat_messages WHERE board_id IN (SELECT id FROM boards WHERE project_id IN (SELECT id FROM projects WHERE owner_id = $1))", suite.userID)
 	suite.db.Exec("DELETE FROM boards WHERE project_id IN (SELECT id FROM projects WHERE owner_id = $1)", suite.userID)
 	suite.db.Exec("DELETE FROM projects WHERE owner_id = $1", suite.userID)
+	// Organizations are only deleted once they have no members
+	var orgIDs pq.StringArray
+	suite.db.QueryRow("SELECT COALESCE(array_agg(org_id), '{}') FROM organization_members WHERE user_id = $1", suite.userID).Scan(&orgIDs)
+	suite.db.Exec("DELETE FROM organization_members WHERE org_id = ANY($1::uuid[])", orgIDs)
+	suite.db.Exec("DELETE FROM organizations WHERE id = ANY($1::uuid[])", orgIDs)
 }
 
 	// Test implementations
@@ -132,10 +162,10 @@ func (suite *IntegrationTestSuite) TestProjectLifecycle() {
 	assert.Equal(suite.T(), suite.userID, project.OwnerID)
 
 	// Query projects
-	projects, err := queryResolver.Projects(suite.ctx)
+	projects, err := queryResolver.Projects(suite.ctx, 0, nil, 0, nil)
 	require.NoError(suite.T(), err)
-	require.Len(suite.T(), projects, 1)
-	assert.Equal(suite.T(), project.ID, projects[0].ID)
+	require.Len(suite.T(), projects.Edges, 1)
+	assert.Equal(suite.T(), project.ID, projects.Edges[0].Node.ID)
 
 	// Query specific project
 	queriedProject, err := queryResolver.Project(suite.ctx, project.ID)
@@ -175,10 +205,10 @@ func (suite *IntegrationTestSuite) TestBoardLifecycle() {
 
 	// Test project resolver - boards
 	projectResolver := &projectResolver{suite.resolver}
-	boards, err := projectResolver.Boards(suite.ctx, project)
+	boards, err := projectResolver.Boards(suite.ctx, project, 0, nil, 0, nil)
 	require.NoError(suite.T(), err)
-	require.Len(suite.T(), boards, 1)
-	assert.Equal(suite.T(), board.ID, boards[0].ID)
+	require.Len(suite.T(), boards.Edges, 1)
+	assert.Equal(suite.T(), board.ID, boards.Edges[0].Node.ID)
 }
 
 func (suite *IntegrationTestSuite) TestAssetLifecycle() {
@@ -214,10 +244,10 @@ func (suite *IntegrationTestSuite) TestAssetLifecycle() {
 
 	// Test board resolver - assets
 	boardResolver := &boardResolver{suite.resolver}
-	assets, err := boardResolver.Assets(suite.ctx, board)
+	assets, err := boardResolver.Assets(suite.ctx, board, 0, nil, 0, nil)
 	require.NoError(suite.T(), err)
-	require.Len(suite.T(), assets, 1)
-	assert.Equal(suite.T(), asset.ID, assets[0].ID)
+	require.Len(suite.T(), assets.Edges, 1)
+	assert.Equal(suite.T(), asset.ID, assets.Edges[0].Node.ID)
 
 	// Test asset resolver - board
 	assetResolver := &assetResolver{suite.resolver}
@@ -226,8 +256,17 @@ func (suite *IntegrationTestSuite) TestAssetLifecycle() {
 	require.NotNil(suite.T(), assetBoard)
 	assert.Equal(suite.T(), board.ID, assetBoard.ID)
 
+	// Uploaded assets are approved once in review
+	_, err = mutationResolver.ApproveAsset(suite.ctx, asset.ID, nil)
+	var invalid *InvalidTransitionError
+	assert.ErrorAs(suite.T(), err, &invalid)
+
+	inReview, err := mutationResolver.SubmitAssetForReview(suite.ctx, asset.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.AssetStatusReview, inReview.Status)
+
 	// Approve asset
-	approvedAsset, err := mutationResolver.ApproveAsset(suite.ctx, asset.ID)
+	approvedAsset, err := mutationResolver.ApproveAsset(suite.ctx, asset.ID, nil)
 	require.NoError(suite.T(), err)
 	require.NotNil(suite.T(), approvedAsset)
 	assert.Equal(suite.T(), model.AssetStatusApproved, approvedAsset.Status)
@@ -266,10 +305,10 @@ func (suite *IntegrationTestSuite) TestChatLifecycle() {
 	assert.Equal(suite.T(), board.ID, message.BoardID)
 
 	// Query chat messages
-	messages, err := queryResolver.ChatMessages(suite.ctx, board.ID, nil, nil)
+	messages, err := queryResolver.ChatMessages(suite.ctx, board.ID, 50, nil, nil, nil)
 	require.NoError(suite.T(), err)
-	require.Len(suite.T(), messages, 1)
-	assert.Equal(suite.T(), message.ID, messages[0].ID)
+	require.Len(suite.T(), messages.Edges, 1)
+	assert.Equal(suite.T(), message.ID, messages.Edges[0].Node.ID)
 
 	// Test chat message resolvers
 	chatMessageResolver := &chatMessageResolver{suite.resolver}
@@ -331,23 +370,23 @@ func (suite *IntegrationTestSuite) TestComplexScenario() {
 	}
 
 	// Verify data integrity
-	allProjects, err := queryResolver.Projects(suite.ctx)
+	allProjects, err := queryResolver.Projects(suite.ctx, 0, nil, 0, nil)
 	require.NoError(suite.T(), err)
-	assert.Len(suite.T(), allProjects, 3)
+	assert.Len(suite.T(), allProjects.Edges, 3)
 
 	// Test performance with multiple queries
 	start := time.Now()
-	for _, project := range allProjects {
+	for _, projectEdge := range allProjects.Edges {
 		projectResolver := &projectResolver{suite.resolver}
-		projectBoards, err := projectResolver.Boards(suite.ctx, project)
+		projectBoards, err := projectResolver.Boards(suite.ctx, projectEdge.Node, 0, nil, 0, nil)
 		require.NoError(suite.T(), err)
-		assert.Len(suite.T(), projectBoards, 2)
+		assert.Len(suite.T(), projectBoards.Edges, 2)
 
-		for _, board := range projectBoards {
+		for _, boardEdge := range projectBoards.Edges {
 			boardResolver := &boardResolver{suite.resolver}
-			boardAssets, err := boardResolver.Assets(suite.ctx, board)
+			boardAssets, err := boardResolver.Assets(suite.ctx, boardEdge.Node, 0, nil, 0, nil)
 			require.NoError(suite.T(), err)
-			assert.Len(suite.T(), boardAssets, 3)
+			assert.Len(suite.T(), boardAssets.Edges, 3)
 		}
 	}
 	duration := time.Since(start)
@@ -356,6 +395,194 @@ func (suite *IntegrationTestSuite) TestComplexScenario() {
 	assert.Less(suite.T(), duration, time.Second*5, "Complex query should complete within 5 seconds")
 }
 
+func (suite *IntegrationTestSuite) TestLoadersResolveListFields() {
+	mutationResolver := &mutationResolver{suite.resolver}
+	ctx := context.WithValue(suite.ctx, loadersKey{}, suite.resolver.NewLoaders())
+
+	var boards []*model.Board
+	for i := 0; i < 2; i++ {
+		project, err := mutationResolver.CreateProject(suite.ctx, model.CreateProjectInput{
+			Name: fmt.Sprintf("Loader Project %d", i+1),
+		})
+		require.NoError(suite.T(), err)
+
+		board, err := mutationResolver.CreateBoard(suite.ctx, model.CreateBoardInput{
+			Name:      fmt.Sprintf("Loader Board %d", i+1),
+			ProjectID: project.ID,
+		})
+		require.NoError(suite.T(), err)
+		boards = append(boards, board)
+
+		for j := 0; j <= i; j++ {
+			_, err := mutationResolver.UploadAsset(suite.ctx, model.UploadAssetInput{
+				Name:    fmt.Sprintf("loader-asset-%d-%d.jpg", i, j),
+				Type:    model.AssetTypeImage,
+				URL:     fmt.Sprintf("https://example.com/loader-%d-%d.jpg", i, j),
+				BoardID: board.ID,
+			})
+			require.NoError(suite.T(), err)
+		}
+	}
+
+	// Resolve the fields of both boards concurrently, as gqlgen does for a list
+	boardResolver := &boardResolver{suite.resolver}
+	projectResolver := &projectResolver{suite.resolver}
+	assetCounts := make([]int, len(boards))
+	var wg sync.WaitGroup
+	for i, board := range boards {
+		wg.Add(1)
+		go func(i int, board *model.Board) {
+			defer wg.Done()
+
+			assets, err := boardResolver.Assets(ctx, board, 0, nil, 0, nil)
+			assert.NoError(suite.T(), err)
+			for _, edge := range assets.Edges {
+				assert.Equal(suite.T(), board.ID, edge.Node.BoardID)
+			}
+			assetCounts[i] = len(assets.Edges)
+
+			project, err := boardResolver.Project(ctx, board)
+			assert.NoError(suite.T(), err)
+			assert.Equal(suite.T(), board.ProjectID, project.ID)
+
+			owner, err := projectResolver.Owner(ctx, project)
+			assert.NoError(suite.T(), err)
+			assert.Equal(suite.T(), suite.userID, owner.ID)
+
+			projectBoards, err := projectResolver.Boards(ctx, project, 0, nil, 0, nil)
+			assert.NoError(suite.T(), err)
+			assert.Len(suite.T(), projectBoards.Edges, 1)
+		}(i, board)
+	}
+	wg.Wait()
+
+	assert.Equal(suite.T(), []int{1, 2}, assetCounts)
+
+	// Boards hidden by row-level security are not found
+	_, err := (&assetResolver{suite.resolver}).Board(ctx, &model.Asset{BoardID: uuid.New().String()})
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+}
+
+func (suite *IntegrationTestSuite) TestProjectsPagination() {
+	mutationResolver := &mutationResolver{suite.resolver}
+	queryResolver := &queryResolver{suite.resolver}
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		project, err := mutationResolver.CreateProject(suite.ctx, model.CreateProjectInput{
+			Name: fmt.Sprintf("Paginated Project %d", i+1),
+		})
+		require.NoError(suite.T(), err)
+		ids = append(ids, project.ID)
+	}
+
+	// Oldest first, two at a time
+	page, err := queryResolver.Projects(suite.ctx, 2, nil, 0, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), page.Edges, 2)
+	assert.Equal(suite.T(), ids[0], page.Edges[0].Node.ID)
+	assert.Equal(suite.T(), ids[1], page.Edges[1].Node.ID)
+	assert.True(suite.T(), page.PageInfo.HasNextPage)
+	assert.False(suite.T(), page.PageInfo.HasPreviousPage)
+	assert.Equal(suite.T(), page.Edges[0].Cursor, *page.PageInfo.StartCursor)
+	assert.Equal(suite.T(), page.Edges[1].Cursor, *page.PageInfo.EndCursor)
+
+	page, err = queryResolver.Projects(suite.ctx, 2, page.PageInfo.EndCursor, 0, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), page.Edges, 2)
+	assert.Equal(suite.T(), ids[2], page.Edges[0].Node.ID)
+	assert.True(suite.T(), page.PageInfo.HasPreviousPage)
+
+	page, err = queryResolver.Projects(suite.ctx, 2, page.PageInfo.EndCursor, 0, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), page.Edges, 1)
+	assert.Equal(suite.T(), ids[4], page.Edges[0].Node.ID)
+	assert.False(suite.T(), page.PageInfo.HasNextPage)
+
+	// Backward from the end, then from the start of that page
+	page, err = queryResolver.Projects(suite.ctx, 0, nil, 2, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), page.Edges, 2)
+	assert.Equal(suite.T(), ids[3], page.Edges[0].Node.ID)
+	assert.Equal(suite.T(), ids[4], page.Edges[1].Node.ID)
+	assert.True(suite.T(), page.PageInfo.HasPreviousPage)
+	assert.False(suite.T(), page.PageInfo.HasNextPage)
+
+	page, err = queryResolver.Projects(suite.ctx, 0, nil, 3, page.PageInfo.StartCursor)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), page.Edges, 3)
+	assert.Equal(suite.T(), ids[0], page.Edges[0].Node.ID)
+	assert.Equal(suite.T(), ids[2], page.Edges[2].Node.ID)
+	assert.False(suite.T(), page.PageInfo.HasPreviousPage)
+	assert.True(suite.T(), page.PageInfo.HasNextPage)
+}
+
+func (suite *IntegrationTestSuite) TestLoadersPaginateListFields() {
+	mutationResolver := &mutationResolver{suite.resolver}
+	ctx := context.WithValue(suite.ctx, loadersKey{}, suite.resolver.NewLoaders())
+
+	project, err := mutationResolver.CreateProject(suite.ctx, model.CreateProjectInput{
+		Name: "Test Project for Asset Pagination",
+	})
+	require.NoError(suite.T(), err)
+
+	boards := make([]*model.Board, 2)
+	assetIDs := make([][]string, 2)
+	for i := range boards {
+		boards[i], err = mutationResolver.CreateBoard(suite.ctx, model.CreateBoardInput{
+			Name:      fmt.Sprintf("Paginated Board %d", i+1),
+			ProjectID: project.ID,
+		})
+		require.NoError(suite.T(), err)
+
+		for j := 0; j < 3; j++ {
+			asset, err := mutationResolver.UploadAsset(suite.ctx, model.UploadAssetInput{
+				Name:    fmt.Sprintf("paginated-asset-%d-%d.jpg", i, j),
+				Type:    model.AssetTypeImage,
+				URL:     fmt.Sprintf("https://example.com/paginated-%d-%d.jpg", i, j),
+				BoardID: boards[i].ID,
+			})
+			require.NoError(suite.T(), err)
+			assetIDs[i] = append(assetIDs[i], asset.ID)
+		}
+	}
+
+	// Both boards get their own first page from the same batch
+	boardResolver := &boardResolver{suite.resolver}
+	pages := make([]*model.AssetConnection, len(boards))
+	var wg sync.WaitGroup
+	for i, board := range boards {
+		wg.Add(1)
+		go func(i int, board *model.Board) {
+			defer wg.Done()
+
+			page, err := boardResolver.Assets(ctx, board, 2, nil, 0, nil)
+			assert.NoError(suite.T(), err)
+			pages[i] = page
+		}(i, board)
+	}
+	wg.Wait()
+
+	for i, page := range pages {
+		require.Len(suite.T(), page.Edges, 2)
+		assert.Equal(suite.T(), assetIDs[i][0], page.Edges[0].Node.ID)
+		assert.Equal(suite.T(), assetIDs[i][1], page.Edges[1].Node.ID)
+		assert.True(suite.T(), page.PageInfo.HasNextPage)
+	}
+
+	rest, err := boardResolver.Assets(ctx, boards[0], 2, pages[0].PageInfo.EndCursor, 0, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), rest.Edges, 1)
+	assert.Equal(suite.T(), assetIDs[0][2], rest.Edges[0].Node.ID)
+	assert.False(suite.T(), rest.PageInfo.HasNextPage)
+
+	boardsPage, err := (&projectResolver{suite.resolver}).Boards(ctx, project, 0, nil, 1, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), boardsPage.Edges, 1)
+	assert.Equal(suite.T(), boards[1].ID, boardsPage.Edges[0].Node.ID)
+	assert.True(suite.T(), boardsPage.PageInfo.HasPreviousPage)
+}
+
 func (suite *IntegrationTestSuite) TestConcurrentOperations() {
 	// Test concurrent asset uploads
 	mutationResolver := &mutationResolver{suite.resolver}
@@ -410,21 +637,1559 @@ func (suite *IntegrationTestSuite) TestConcurrentOperations() {
 
 	// Verify all assets were created
 	boardResolver := &boardResolver{suite.resolver}
-	boardAssets, err := boardResolver.Assets(suite.ctx, board)
+	boardAssets, err := boardResolver.Assets(suite.ctx, board, 0, nil, 0, nil)
 	require.NoError(suite.T(), err)
-	assert.Len(suite.T(), boardAssets, numConcurrent)
+	assert.Len(suite.T(), boardAssets.Edges, numConcurrent)
 }
 
-// Test helper implementations - these are kept for reference but not used in integration tests
-// In integration tests, we focus on database operations and use nil for external services
+func (suite *IntegrationTestSuite) TestCrossTenantIsolation() {
+	mutationResolver := &mutationResolver{suite.resolver}
+	queryResolver := &queryResolver{suite.resolver}
 
-// Run the integration test suite
-func TestIntegrationSuite(t *testing.T) {
-	// Skip integration tests if not in integration test mode
-	if testing.Short() {
-		t.Skip("Skipping integration tests in short mode")
-		return
+	// Seed another tenant directly, bypassing the resolvers
+	otherUserID := uuid.New().String()
+	otherProjectID := uuid.New().String()
+	otherBoardID := uuid.New().String()
+
+	_, err := suite.db.Exec(`
+		INSERT INTO users (id, email, name) VALUES ($1, $2, $3)
+	`, otherUserID, "other-tenant@test.com", "Other Tenant")
+	require.NoError(suite.T(), err)
+	defer suite.db.Exec("DELETE FROM users WHERE id = $1", otherUserID)
+
+	_, err = suite.db.Exec(`
+		INSERT INTO projects (id, name, owner_id) VALUES ($1, $2, $3)
+	`, otherProjectID, "Other Tenant Project", otherUserID)
+	require.NoError(suite.T(), err)
+
+	_, err = suite.db.Exec(`
+		INSERT INTO boards (id, name, project_id) VALUES ($1, $2, $3)
+	`, otherBoardID, "Other Tenant Board", otherProjectID)
+	require.NoError(suite.T(), err)
+
+	_, err = suite.db.Exec(`
+		INSERT INTO chat_messages (content, user_id, board_id) VALUES ($1, $2, $3)
+	`, "private message", otherUserID, otherBoardID)
+	require.NoError(suite.T(), err)
+
+	// None of the other tenant's rows are visible
+	projects, err := queryResolver.Projects(suite.ctx, 0, nil, 0, nil)
+	require.NoError(suite.T(), err)
+	for _, edge := range projects.Edges {
+		assert.NotEqual(suite.T(), otherProjectID, edge.Node.ID)
 	}
 
-	suite.Run(t, new(IntegrationTestSuite))
-} 
\ No newline at end of file
+	_, err = queryResolver.Project(suite.ctx, otherProjectID)
+	assert.EqualError(suite.T(), err, "project not found")
+
+	_, err = queryResolver.Board(suite.ctx, otherBoardID)
+	assert.EqualError(suite.T(), err, "board not found")
+
+	messages, err := queryResolver.ChatMessages(suite.ctx, otherBoardID, 50, nil, nil, nil)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), messages.Edges)
+
+	// Writes into the other tenant's project are rejected by the policy
+	_, err = mutationResolver.CreateBoard(suite.ctx, model.CreateBoardInput{
+		Name:      "Intruder Board",
+		ProjectID: otherProjectID,
+	})
+	assert.Error(suite.T(), err)
+
+	// Membership grants access to the shared project
+	_, err = suite.db.Exec(`
+		INSERT INTO project_members (project_id, user_id) VALUES ($1, $2)
+	`, otherProjectID, suite.userID)
+	require.NoError(suite.T(), err)
+
+	shared, err := queryResolver.Project(suite.ctx, otherProjectID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), otherProjectID, shared.ID)
+}
+
+func (suite *IntegrationTestSuite) TestOrganizationMembership() {
+	mutationResolver := &mutationResolver{suite.resolver}
+	queryResolver := &queryResolver{suite.resolver}
+
+	otherUserID := uuid.New().String()
+	_, err := suite.db.Exec(`
+		INSERT INTO users (id, email, name) VALUES ($1, $2, $3)
+	`, otherUserID, "teammate@test.com", "Teammate")
+	require.NoError(suite.T(), err)
+	defer suite.db.Exec("DELETE FROM users WHERE id = $1", otherUserID)
+	otherCtx := context.WithValue(context.Background(), "user", &auth.User{ID: otherUserID, Email: "teammate@test.com"})
+
+	organization, err := mutationResolver.CreateOrganization(suite.ctx, model.CreateOrganizationInput{Name: "Acme"})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "free", organization.Plan)
+
+	project, err := mutationResolver.CreateProject(suite.ctx, model.CreateProjectInput{
+		Name:  "Acme Launch",
+		OrgID: &organization.ID,
+	})
+	require.NoError(suite.T(), err)
+
+	// Projects of the organization are hidden from non-members
+	_, err = queryResolver.Project(otherCtx, project.ID)
+	assert.EqualError(suite.T(), err, "project not found")
+
+	_, err = mutationResolver.CreateProject(otherCtx, model.CreateProjectInput{
+		Name:  "Intruder Project",
+		OrgID: &organization.ID,
+	})
+	assert.EqualError(suite.T(), err, "organization not found")
+
+	// Invited members have access to every project of the organization
+	member, err := mutationResolver.InviteMember(suite.ctx, organization.ID, "teammate@test.com", model.OrganizationRoleMember)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), otherUserID, member.UserID)
+	assert.Equal(suite.T(), model.OrganizationRoleMember, member.Role)
+
+	_, err = mutationResolver.InviteMember(suite.ctx, organization.ID, "teammate@test.com", model.OrganizationRoleMember)
+	assert.EqualError(suite.T(), err, "user is already a member of the organization")
+
+	projects, err := queryResolver.Projects(otherCtx, 0, nil, 0, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), projects.Edges, 1)
+	assert.Equal(suite.T(), project.ID, projects.Edges[0].Node.ID)
+
+	organizations, err := queryResolver.Organizations(otherCtx)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), organizations, 1)
+
+	members, err := (&organizationResolver{suite.resolver}).Members(otherCtx, organizations[0])
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), members, 2)
+	assert.Equal(suite.T(), model.OrganizationRoleOwner, members[0].Role)
+
+	// Members cannot manage members, and admins cannot make owners
+	_, err = mutationResolver.RemoveMember(otherCtx, organization.ID, suite.userID)
+	assert.Error(suite.T(), err)
+
+	member, err = mutationResolver.UpdateMemberRole(suite.ctx, organization.ID, otherUserID, model.OrganizationRoleAdmin)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.OrganizationRoleAdmin, member.Role)
+
+	_, err = mutationResolver.UpdateMemberRole(otherCtx, organization.ID, otherUserID, model.OrganizationRoleOwner)
+	assert.Error(suite.T(), err)
+
+	// The last owner stays
+	_, err = mutationResolver.RemoveMember(suite.ctx, organization.ID, suite.userID)
+	assert.EqualError(suite.T(), err, "organization must keep an owner")
+
+	// Removed members lose access
+	removed, err := mutationResolver.RemoveMember(suite.ctx, organization.ID, otherUserID)
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), removed)
+
+	_, err = queryResolver.Project(otherCtx, project.ID)
+	assert.EqualError(suite.T(), err, "project not found")
+
+	// Sharing a project of the organization with a non-member does not give
+	// access to it
+	_, err = suite.db.Exec(`
+		INSERT INTO project_members (project_id, user_id) VALUES ($1, $2)
+	`, project.ID, otherUserID)
+	require.NoError(suite.T(), err)
+
+	_, err = queryResolver.Project(otherCtx, project.ID)
+	assert.EqualError(suite.T(), err, "project not found")
+
+	_, err = mutationResolver.CreateBoard(otherCtx, model.CreateBoardInput{Name: "Intruder Board", ProjectID: project.ID})
+	assert.Error(suite.T(), err)
+
+	// Organizations keep their projects and members
+	_, err = suite.db.Exec("DELETE FROM organizations WHERE id = $1", organization.ID)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *IntegrationTestSuite) TestPreferencesLifecycle() {
+	mutationResolver := &mutationResolver{suite.resolver}
+	queryResolver := &queryResolver{suite.resolver}
+
+	// Users start without preferences
+	preferences, err := queryResolver.MyPreferences(suite.ctx)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), preferences)
+
+	updated, err := mutationResolver.UpdatePreferences(suite.ctx, map[string]interface{}{
+		"language":              "es",
+		"notificationFrequency": "daily",
+		"dashboardLayout":       map[string]interface{}{"columns": 3},
+	})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "es", updated["language"])
+
+	// Updates are merged into the stored preferences
+	updated, err = mutationResolver.UpdatePreferences(suite.ctx, map[string]interface{}{
+		"language": "pt",
+		"theme":    "dark",
+	})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "pt", updated["language"])
+	assert.Equal(suite.T(), "dark", updated["theme"])
+	assert.Equal(suite.T(), "daily", updated["notificationFrequency"])
+	assert.Equal(suite.T(), map[string]interface{}{"columns": float64(3)}, updated["dashboardLayout"])
+
+	// Reads are cached and the cache is invalidated by updates
+	preferences, err = queryResolver.MyPreferences(suite.ctx)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), updated, preferences)
+	cached, ok := suite.resolver.Cache.GetPreferences(suite.userID)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), preferences, cached)
+
+	// Null clears a key
+	updated, err = mutationResolver.UpdatePreferences(suite.ctx, map[string]interface{}{"theme": nil})
+	require.NoError(suite.T(), err)
+	assert.NotContains(suite.T(), updated, "theme")
+
+	_, ok = suite.resolver.Cache.GetPreferences(suite.userID)
+	assert.False(suite.T(), ok)
+
+	preferences, err = queryResolver.MyPreferences(suite.ctx)
+	require.NoError(suite.T(), err)
+	assert.NotContains(suite.T(), preferences, "theme")
+	assert.Equal(suite.T(), "pt", preferences["language"])
+
+	// Unknown keys and invalid values are rejected without changing anything
+	_, err = mutationResolver.UpdatePreferences(suite.ctx, map[string]interface{}{"favoriteColor": "blue"})
+	assert.EqualError(suite.T(), err, "unknown preference: favoriteColor")
+
+	_, err = mutationResolver.UpdatePreferences(suite.ctx, map[string]interface{}{"language": "klingon"})
+	assert.Error(suite.T(), err)
+
+	preferences, err = queryResolver.MyPreferences(suite.ctx)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "pt", preferences["language"])
+}
+
+func (suite *IntegrationTestSuite) TestPreferencesIsolation() {
+	mutationResolver := &mutationResolver{suite.resolver}
+	queryResolver := &queryResolver{suite.resolver}
+
+	otherUserID := uuid.New().String()
+	_, err := suite.db.Exec(`
+		INSERT INTO users (id, email, name) VALUES ($1, $2, $3)
+	`, otherUserID, "other-preferences@test.com", "Other User")
+	require.NoError(suite.T(), err)
+	defer suite.db.Exec("DELETE FROM users WHERE id = $1", otherUserID)
+
+	otherCtx := context.WithValue(context.Background(), "user", &auth.User{ID: otherUserID})
+
+	_, err = mutationResolver.UpdatePreferences(otherCtx, map[string]interface{}{"language": "fr"})
+	require.NoError(suite.T(), err)
+
+	preferences, err := queryResolver.MyPreferences(suite.ctx)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), preferences)
+
+	_, err = mutationResolver.UpdatePreferences(suite.ctx, map[string]interface{}{"language": "de"})
+	require.NoError(suite.T(), err)
+
+	preferences, err = queryResolver.MyPreferences(otherCtx)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "fr", preferences["language"])
+}
+
+// createChatBoard creates a board holding the given messages, each a minute newer
+// than the one before, and returns the board ID and message IDs
+func (suite *IntegrationTestSuite) createChatBoard(contents ...string) (string, []string) {
+	mutationResolver := &mutationResolver{suite.resolver}
+
+	project, err := mutationResolver.CreateProject(suite.ctx, model.CreateProjectInput{
+		Name: "Test Project for Chat Pagination",
+	})
+	require.NoError(suite.T(), err)
+
+	board, err := mutationResolver.CreateBoard(suite.ctx, model.CreateBoardInput{
+		Name:      "Test Board for Chat Pagination",
+		ProjectID: project.ID,
+	})
+	require.NoError(suite.T(), err)
+
+	start := time.Now().Add(-time.Hour)
+	var ids []string
+	for i, content := range contents {
+		id := uuid.New().String()
+		_, err := suite.db.Exec(`
+			INSERT INTO chat_messages (id, content, user_id, board_id, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, id, content, suite.userID, board.ID, start.Add(time.Duration(i)*time.Minute))
+		require.NoError(suite.T(), err)
+		ids = append(ids, id)
+	}
+
+	return board.ID, ids
+}
+
+func (suite *IntegrationTestSuite) TestChatMessagesPagination() {
+	queryResolver := &queryResolver{suite.resolver}
+
+	boardID, ids := suite.createChatBoard("one", "two", "three", "four", "five")
+
+	// Newest first, two at a time
+	page, err := queryResolver.ChatMessages(suite.ctx, boardID, 2, nil, nil, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), page.Edges, 2)
+	assert.Equal(suite.T(), ids[4], page.Edges[0].Node.ID)
+	assert.Equal(suite.T(), ids[3], page.Edges[1].Node.ID)
+	assert.True(suite.T(), page.PageInfo.HasNextPage)
+	require.NotNil(suite.T(), page.PageInfo.EndCursor)
+	assert.Equal(suite.T(), page.Edges[1].Cursor, *page.PageInfo.EndCursor)
+
+	page, err = queryResolver.ChatMessages(suite.ctx, boardID, 2, page.PageInfo.EndCursor, nil, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), page.Edges, 2)
+	assert.Equal(suite.T(), ids[2], page.Edges[0].Node.ID)
+	assert.Equal(suite.T(), ids[1], page.Edges[1].Node.ID)
+	assert.True(suite.T(), page.PageInfo.HasNextPage)
+
+	page, err = queryResolver.ChatMessages(suite.ctx, boardID, 2, page.PageInfo.EndCursor, nil, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), page.Edges, 1)
+	assert.Equal(suite.T(), ids[0], page.Edges[0].Node.ID)
+	assert.False(suite.T(), page.PageInfo.HasNextPage)
+
+	// Messages posted after a page was read do not shift later pages
+	first, err := queryResolver.ChatMessages(suite.ctx, boardID, 3, nil, nil, nil)
+	require.NoError(suite.T(), err)
+	_, err = suite.db.Exec(`
+		INSERT INTO chat_messages (id, content, user_id, board_id, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+	`, uuid.New().String(), "six", suite.userID, boardID)
+	require.NoError(suite.T(), err)
+
+	rest, err := queryResolver.ChatMessages(suite.ctx, boardID, 3, first.PageInfo.EndCursor, nil, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), rest.Edges, 2)
+	assert.Equal(suite.T(), ids[1], rest.Edges[0].Node.ID)
+
+	invalid := "not-a-cursor"
+	_, err = queryResolver.ChatMessages(suite.ctx, boardID, 2, &invalid, nil, nil)
+	assert.EqualError(suite.T(), err, "invalid cursor")
+
+	_, err = queryResolver.ChatMessages(suite.ctx, boardID, 0, nil, nil, nil)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *IntegrationTestSuite) TestChatMessagesSearch() {
+	queryResolver := &queryResolver{suite.resolver}
+
+	boardID, ids := suite.createChatBoard(
+		"Launch plan for Q3",
+		"Budget review",
+		"Final LAUNCH checklist",
+	)
+
+	search := "launch"
+	page, err := queryResolver.ChatMessages(suite.ctx, boardID, 50, nil, &search, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), page.Edges, 2)
+	assert.Equal(suite.T(), ids[2], page.Edges[0].Node.ID)
+	assert.Equal(suite.T(), ids[0], page.Edges[1].Node.ID)
+
+	// Search filters combine with pagination
+	page, err = queryResolver.ChatMessages(suite.ctx, boardID, 1, nil, &search, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), page.Edges, 1)
+	assert.True(suite.T(), page.PageInfo.HasNextPage)
+
+	page, err = queryResolver.ChatMessages(suite.ctx, boardID, 1, page.PageInfo.EndCursor, &search, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), page.Edges, 1)
+	assert.Equal(suite.T(), ids[0], page.Edges[0].Node.ID)
+	assert.False(suite.T(), page.PageInfo.HasNextPage)
+
+	search = "nothing matches"
+	page, err = queryResolver.ChatMessages(suite.ctx, boardID, 50, nil, &search, nil)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), page.Edges)
+}
+
+func (suite *IntegrationTestSuite) TestChatMessagesSearchIsSanitized() {
+	queryResolver := &queryResolver{suite.resolver}
+
+	boardID, ids := suite.createChatBoard(
+		"Tom &amp; Jerry",
+		"Tom & Jerry",
+		"100% done",
+		"1000 done",
+	)
+
+	// The search term is HTML escaped the same way as sanitized input
+	search := "Tom & Jerry"
+	page, err := queryResolver.ChatMessages(suite.ctx, boardID, 50, nil, &search, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), page.Edges, 1)
+	assert.Equal(suite.T(), ids[0], page.Edges[0].Node.ID)
+
+	// Control characters are dropped
+	search = "Tom &\x00 Jerry\x07"
+	page, err = queryResolver.ChatMessages(suite.ctx, boardID, 50, nil, &search, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), page.Edges, 1)
+	assert.Equal(suite.T(), ids[0], page.Edges[0].Node.ID)
+
+	// Pattern characters match literally
+	search = "100%"
+	page, err = queryResolver.ChatMessages(suite.ctx, boardID, 50, nil, &search, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), page.Edges, 1)
+	assert.Equal(suite.T(), ids[2], page.Edges[0].Node.ID)
+
+	search = "10_0"
+	page, err = queryResolver.ChatMessages(suite.ctx, boardID, 50, nil, &search, nil)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), page.Edges)
+}
+
+func (suite *IntegrationTestSuite) TestChatThreads() {
+	// Replies are published to the board subscribers
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	natsServer := natsserver.RunServer(&opts)
+	defer natsServer.Shutdown()
+
+	natsConn, err := nats.Connect(natsServer.ClientURL())
+	require.NoError(suite.T(), err)
+	defer natsConn.Close()
+
+	resolver := *suite.resolver
+	resolver.NatsConn = natsConn
+	mutationResolver := &mutationResolver{&resolver}
+	queryResolver := &queryResolver{&resolver}
+	chatMessageResolver := &chatMessageResolver{&resolver}
+
+	boardID, ids := suite.createChatBoard("Is the banner final?", "Unrelated")
+
+	updates, err := natsConn.SubscribeSync(fmt.Sprintf("board.%s.updated", boardID))
+	require.NoError(suite.T(), err)
+
+	reply, err := mutationResolver.ReplyToMessage(suite.ctx, ids[0], "Not yet")
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), boardID, reply.BoardID)
+	require.NotNil(suite.T(), reply.ParentID)
+	assert.Equal(suite.T(), ids[0], *reply.ParentID)
+
+	update, err := updates.NextMsg(time.Second)
+	require.NoError(suite.T(), err)
+	var published model.ChatMessage
+	require.NoError(suite.T(), json.Unmarshal(update.Data, &published))
+	assert.Equal(suite.T(), reply.ID, published.ID)
+
+	nested, err := mutationResolver.ReplyToMessage(suite.ctx, reply.ID, "Waiting on legal")
+	require.NoError(suite.T(), err)
+
+	// Replies only hold the direct children
+	replies, err := chatMessageResolver.Replies(suite.ctx, &model.ChatMessage{ID: ids[0]})
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), replies, 1)
+	assert.Equal(suite.T(), reply.ID, replies[0].ID)
+
+	replies, err = chatMessageResolver.Replies(suite.ctx, &model.ChatMessage{ID: ids[1]})
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), replies)
+
+	// A thread holds its root and the replies at any depth
+	page, err := queryResolver.ChatMessages(suite.ctx, boardID, 50, nil, nil, &ids[0])
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), page.Edges, 3)
+	assert.Equal(suite.T(), nested.ID, page.Edges[0].Node.ID)
+	assert.Equal(suite.T(), reply.ID, page.Edges[1].Node.ID)
+	assert.Equal(suite.T(), ids[0], page.Edges[2].Node.ID)
+
+	_, err = mutationResolver.ReplyToMessage(suite.ctx, uuid.New().String(), "Lost")
+	assert.Error(suite.T(), err)
+
+	_, err = suite.db.Exec(`
+		INSERT INTO message_reactions (message_id, user_id, emoji)
+		VALUES ($1, $2, '👍'), ($1, $2, '🎉')
+	`, ids[0], suite.userID)
+	require.NoError(suite.T(), err)
+
+	count, err := chatMessageResolver.ReactionCount(suite.ctx, &model.ChatMessage{ID: ids[0]})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, count)
+
+	count, err = chatMessageResolver.ReactionCount(suite.ctx, &model.ChatMessage{ID: reply.ID})
+	require.NoError(suite.T(), err)
+	assert.Zero(suite.T(), count)
+}
+
+func (suite *IntegrationTestSuite) TestBoardDataExport() {
+	boardID, ids := suite.createChatBoard("Ship it", "Wait")
+
+	source, err := suite.resolver.OpenBoardDataExport(suite.ctx, boardID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), boardID, source.Board().ID)
+
+	var buf bytes.Buffer
+	require.NoError(suite.T(), export.WriteBoardJSON(&buf, source, time.Now()))
+
+	var document struct {
+		Messages []export.MessageRow `json:"messages"`
+	}
+	require.NoError(suite.T(), json.Unmarshal(buf.Bytes(), &document))
+	require.Len(suite.T(), document.Messages, 2)
+	assert.Equal(suite.T(), ids[0], document.Messages[0].ID)
+
+	require.NoError(suite.T(), suite.resolver.RecordBoardDataExport(suite.ctx, source, export.FormatJSON))
+	var messageCount int
+	err = suite.db.QueryRow(`
+		SELECT message_count FROM export_audit_log WHERE board_id = $1 AND user_id = $2
+	`, boardID, suite.userID).Scan(&messageCount)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, messageCount)
+
+	// Boards of other users are not found
+	_, err = suite.resolver.OpenBoardDataExport(suite.ctx, uuid.New().String())
+	assert.ErrorIs(suite.T(), err, ErrBoardNotFound)
+}
+
+func (suite *IntegrationTestSuite) TestReadAt() {
+	mutationResolver := &mutationResolver{suite.resolver}
+
+	_, ids := suite.createChatBoard("first", "second")
+
+	ok, err := mutationResolver.ReadAt(suite.ctx, ids)
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), ok)
+
+	// Marking a message again keeps the first read
+	var firstRead time.Time
+	err = suite.db.QueryRow(`
+		SELECT read_at FROM chat_message_reads WHERE message_id = $1 AND user_id = $2
+	`, ids[0], suite.userID).Scan(&firstRead)
+	require.NoError(suite.T(), err)
+
+	_, err = mutationResolver.ReadAt(suite.ctx, ids[:1])
+	require.NoError(suite.T(), err)
+
+	var reads int
+	var readAt time.Time
+	err = suite.db.QueryRow(`
+		SELECT COUNT(*), MIN(read_at) FROM chat_message_reads WHERE user_id = $1
+	`, suite.userID).Scan(&reads, &readAt)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, reads)
+	assert.True(suite.T(), firstRead.Equal(readAt))
+
+	// Messages the user cannot see are not marked
+	otherUserID := uuid.New().String()
+	_, err = suite.db.Exec(`
+		INSERT INTO users (id, email, name) VALUES ($1, $2, $3)
+	`, otherUserID, "other-reader@test.com", "Other User")
+	require.NoError(suite.T(), err)
+	defer suite.db.Exec("DELETE FROM users WHERE id = $1", otherUserID)
+
+	otherCtx := context.WithValue(context.Background(), "user", &auth.User{ID: otherUserID})
+	_, err = mutationResolver.ReadAt(otherCtx, ids)
+	require.NoError(suite.T(), err)
+
+	err = suite.db.QueryRow(`
+		SELECT COUNT(*) FROM chat_message_reads WHERE user_id = $1
+	`, otherUserID).Scan(&reads)
+	require.NoError(suite.T(), err)
+	assert.Zero(suite.T(), reads)
+}
+
+func (suite *IntegrationTestSuite) TestDeploymentTemplates() {
+	mutationResolver := &mutationResolver{suite.resolver}
+	queryResolver := &queryResolver{suite.resolver}
+
+	metaTemplate, err := mutationResolver.CreateDeploymentTemplate(suite.ctx, "Spring retargeting", model.DeploymentMetadata{
+		Platform:       model.CampaignPlatformMeta,
+		ContentType:    model.DeploymentContentTypeSocialMedia,
+		TargetAudience: "Returning visitors",
+		Budget:         40,
+		Demographics:   &model.Demographics{AgeMin: 25, AgeMax: 54, Locations: []string{"US"}},
+		CreativeSpecs:  &model.CreativeSpecs{CallToAction: "Shop now", LandingURL: "https://example.com/spring"},
+	})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), suite.userID, metaTemplate.OwnerID)
+	assert.Equal(suite.T(), model.CampaignPlatformMeta, metaTemplate.Platform)
+	assert.Equal(suite.T(), 40.0, metaTemplate.Metadata.Budget)
+	assert.Equal(suite.T(), "Shop now", metaTemplate.Metadata.CreativeSpecs.CallToAction)
+
+	// Metadata is stored in the connectors service's format
+	var raw []byte
+	err = suite.db.QueryRow(`SELECT template_metadata FROM deployment_templates WHERE id = $1`, metaTemplate.ID).Scan(&raw)
+	require.NoError(suite.T(), err)
+	assert.JSONEq(suite.T(), `{
+		"target_audience": "Returning visitors",
+		"budget": 40,
+		"demographics": {"age_min": 25, "age_max": 54, "locations": ["US"]},
+		"creative_specs": {"call_to_action": "Shop now", "landing_url": "https://example.com/spring"}
+	}`, string(raw))
+
+	_, err = mutationResolver.CreateDeploymentTemplate(suite.ctx, "Search always-on", model.DeploymentMetadata{
+		Platform:    model.CampaignPlatformGoogleAds,
+		ContentType: model.DeploymentContentTypeBlogPost,
+		Keywords:    []string{"trail shoes"},
+	})
+	require.NoError(suite.T(), err)
+
+	templates, err := queryResolver.DeploymentTemplates(suite.ctx, nil)
+	require.NoError(suite.T(), err)
+	assert.Len(suite.T(), templates, 2)
+
+	platform := model.CampaignPlatformMeta
+	templates, err = queryResolver.DeploymentTemplates(suite.ctx, &platform)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), templates, 1)
+	assert.Equal(suite.T(), metaTemplate.ID, templates[0].ID)
+
+	// Templates belong to the user who created them
+	otherUserID := uuid.New().String()
+	_, err = suite.db.Exec(`
+		INSERT INTO users (id, email, name) VALUES ($1, $2, $3)
+	`, otherUserID, "other-advertiser@test.com", "Other User")
+	require.NoError(suite.T(), err)
+	defer suite.db.Exec("DELETE FROM users WHERE id = $1", otherUserID)
+
+	otherCtx := context.WithValue(context.Background(), "user", &auth.User{ID: otherUserID})
+	templates, err = queryResolver.DeploymentTemplates(otherCtx, nil)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), templates)
+
+	_, err = mutationResolver.DeployAssetFromTemplate(otherCtx, uuid.New().String(), metaTemplate.ID)
+	assert.EqualError(suite.T(), err, "deployment template not found")
+}
+
+func (suite *IntegrationTestSuite) TestKeywordQualityScores() {
+	mutationResolver := &mutationResolver{suite.resolver}
+	queryResolver := &queryResolver{suite.resolver}
+
+	project, err := mutationResolver.CreateProject(suite.ctx, model.CreateProjectInput{Name: "Quality Score Project"})
+	require.NoError(suite.T(), err)
+
+	board, err := mutationResolver.CreateBoard(suite.ctx, model.CreateBoardInput{Name: "Quality Score Board", ProjectID: project.ID})
+	require.NoError(suite.T(), err)
+
+	asset, err := mutationResolver.UploadAsset(suite.ctx, model.UploadAssetInput{
+		Name:    "Search ad",
+		Type:    model.AssetTypeDocument,
+		URL:     "https://example.com/search-ad.txt",
+		BoardID: board.ID,
+	})
+	require.NoError(suite.T(), err)
+
+	scores, err := queryResolver.KeywordQualityScores(suite.ctx, asset.ID)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), scores)
+
+	// Scores are written by the connectors service
+	_, err = suite.db.Exec(`
+		INSERT INTO keyword_quality_scores (asset_id, keyword_id, ad_group_id, keyword, score)
+		VALUES ($1, '101', '777', 'trail running shoes', 7), ($1, '103', '777', 'ultralight running shoes', 4)
+	`, asset.ID)
+	require.NoError(suite.T(), err)
+
+	scores, err = queryResolver.KeywordQualityScores(suite.ctx, asset.ID)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), scores, 2)
+	assert.Equal(suite.T(), "ultralight running shoes", scores[0].Keyword)
+	assert.Equal(suite.T(), 4, scores[0].Score)
+	assert.Equal(suite.T(), "103", scores[0].KeywordID)
+	assert.Equal(suite.T(), "777", scores[0].AdGroupID)
+	assert.Equal(suite.T(), 7, scores[1].Score)
+
+	// Scores are only visible to the members of the asset's project
+	otherUserID := uuid.New().String()
+	_, err = suite.db.Exec(`
+		INSERT INTO users (id, email, name) VALUES ($1, $2, $3)
+	`, otherUserID, "other-scores@test.com", "Other User")
+	require.NoError(suite.T(), err)
+	defer suite.db.Exec("DELETE FROM users WHERE id = $1", otherUserID)
+
+	otherCtx := context.WithValue(context.Background(), "user", &auth.User{ID: otherUserID})
+	_, err = queryResolver.KeywordQualityScores(otherCtx, asset.ID)
+	assert.EqualError(suite.T(), err, "asset not found")
+}
+
+func (suite *IntegrationTestSuite) TestRejectAssetAfterPlatformReview() {
+	mutationResolver := &mutationResolver{suite.resolver}
+
+	project, err := mutationResolver.CreateProject(suite.ctx, model.CreateProjectInput{Name: "Platform Review Project"})
+	require.NoError(suite.T(), err)
+
+	board, err := mutationResolver.CreateBoard(suite.ctx, model.CreateBoardInput{Name: "Platform Review Board", ProjectID: project.ID})
+	require.NoError(suite.T(), err)
+
+	asset, err := mutationResolver.UploadAsset(suite.ctx, model.UploadAssetInput{
+		Name:    "Before and after",
+		Type:    model.AssetTypeImage,
+		URL:     "https://example.com/before-after.jpg",
+		BoardID: board.ID,
+	})
+	require.NoError(suite.T(), err)
+
+	// Only assets that passed internal review can be rejected by a platform
+	_, err = suite.resolver.RejectAssetOnPlatform(context.Background(), asset.ID, "Ads can't show before-and-after images.")
+	assert.Error(suite.T(), err)
+
+	_, err = mutationResolver.SubmitAssetForReview(suite.ctx, asset.ID)
+	require.NoError(suite.T(), err)
+	_, err = mutationResolver.ApproveAsset(suite.ctx, asset.ID, nil)
+	require.NoError(suite.T(), err)
+
+	rejected, err := suite.resolver.RejectAssetOnPlatform(context.Background(), asset.ID, "Ads can't show before-and-after images.")
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.AssetStatusRejected, rejected.Status)
+	require.NotNil(suite.T(), rejected.PlatformRejectionReason)
+	assert.Equal(suite.T(), "Ads can't show before-and-after images.", *rejected.PlatformRejectionReason)
+
+	boardResolver := &boardResolver{suite.resolver}
+	assets, err := boardResolver.Assets(suite.ctx, board, 0, nil, 0, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), assets.Edges, 1)
+	assert.Equal(suite.T(), model.AssetStatusRejected, assets.Edges[0].Node.Status)
+	assert.Equal(suite.T(), rejected.PlatformRejectionReason, assets.Edges[0].Node.PlatformRejectionReason)
+
+	_, err = suite.resolver.RejectAssetOnPlatform(context.Background(), uuid.New().String(), "Misleading claims")
+	assert.EqualError(suite.T(), err, "asset not found")
+}
+
+func (suite *IntegrationTestSuite) TestRejectAndRecallAsset() {
+	mutationResolver := &mutationResolver{suite.resolver}
+	assetResolver := &assetResolver{suite.resolver}
+
+	project, err := mutationResolver.CreateProject(suite.ctx, model.CreateProjectInput{Name: "Review Workflow Project"})
+	require.NoError(suite.T(), err)
+
+	board, err := mutationResolver.CreateBoard(suite.ctx, model.CreateBoardInput{Name: "Review Workflow Board", ProjectID: project.ID})
+	require.NoError(suite.T(), err)
+
+	upload := func(name string) *model.Asset {
+		asset, err := mutationResolver.UploadAsset(suite.ctx, model.UploadAssetInput{
+			Name:    name,
+			Type:    model.AssetTypeImage,
+			URL:     "https://example.com/" + name + ".jpg",
+			BoardID: board.ID,
+		})
+		require.NoError(suite.T(), err)
+		return asset
+	}
+
+	// Rejecting requires a reason and an asset in review
+	rejectable := upload("rejectable")
+	_, err = mutationResolver.RejectAsset(suite.ctx, rejectable.ID, "  ")
+	assert.EqualError(suite.T(), err, "reason is required")
+
+	var invalid *InvalidTransitionError
+	_, err = mutationResolver.RejectAsset(suite.ctx, rejectable.ID, "Logo is off-brand")
+	assert.ErrorAs(suite.T(), err, &invalid)
+
+	_, err = mutationResolver.SubmitAssetForReview(suite.ctx, rejectable.ID)
+	require.NoError(suite.T(), err)
+
+	rejected, err := mutationResolver.RejectAsset(suite.ctx, rejectable.ID, "Logo is off-brand")
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.AssetStatusRejected, rejected.Status)
+	require.NotNil(suite.T(), rejected.RejectionReason)
+	assert.Equal(suite.T(), "Logo is off-brand", *rejected.RejectionReason)
+
+	// The reason is kept with the asset
+	var reason string
+	require.NoError(suite.T(), suite.db.QueryRow(`SELECT rejection_reason FROM assets WHERE id = $1`, rejectable.ID).Scan(&reason))
+	assert.Equal(suite.T(), "Logo is off-brand", reason)
+
+	transitions, err := assetResolver.AvailableTransitions(suite.ctx, rejected)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), transitions)
+
+	_, err = mutationResolver.RejectAsset(suite.ctx, rejectable.ID, "Logo is off-brand")
+	assert.ErrorAs(suite.T(), err, &invalid)
+
+	// Recalling moves approved assets back to pending and clears the approval
+	recallable := upload("recallable")
+	_, err = mutationResolver.RecallAsset(suite.ctx, recallable.ID)
+	assert.ErrorAs(suite.T(), err, &invalid)
+
+	_, err = mutationResolver.SubmitAssetForReview(suite.ctx, recallable.ID)
+	require.NoError(suite.T(), err)
+	approved, err := mutationResolver.ApproveAsset(suite.ctx, recallable.ID, nil)
+	require.NoError(suite.T(), err)
+
+	transitions, err = assetResolver.AvailableTransitions(suite.ctx, approved)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []model.AssetStatus{model.AssetStatusPending, model.AssetStatusRejected, model.AssetStatusDeployed}, transitions)
+
+	recalled, err := mutationResolver.RecallAsset(suite.ctx, recallable.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.AssetStatusPending, recalled.Status)
+	assert.Nil(suite.T(), recalled.ApprovedBy)
+	assert.Nil(suite.T(), recalled.ApprovedAt)
+
+	_, err = mutationResolver.RecallAsset(suite.ctx, uuid.New().String())
+	assert.EqualError(suite.T(), err, "asset not found")
+}
+
+func (suite *IntegrationTestSuite) TestScheduleDeployment() {
+	mutationResolver := &mutationResolver{suite.resolver}
+	queryResolver := &queryResolver{suite.resolver}
+
+	project, err := mutationResolver.CreateProject(suite.ctx, model.CreateProjectInput{Name: "Scheduled Project"})
+	require.NoError(suite.T(), err)
+
+	board, err := mutationResolver.CreateBoard(suite.ctx, model.CreateBoardInput{Name: "Scheduled Board", ProjectID: project.ID})
+	require.NoError(suite.T(), err)
+
+	var assets []*model.Asset
+	for _, name := range []string{"Monday launch", "Tuesday launch", "Unscheduled"} {
+		asset, err := mutationResolver.UploadAsset(suite.ctx, model.UploadAssetInput{
+			Name:    name,
+			Type:    model.AssetTypeImage,
+			URL:     "https://example.com/" + name + ".jpg",
+			BoardID: board.ID,
+		})
+		require.NoError(suite.T(), err)
+		assets = append(assets, asset)
+	}
+
+	monday := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	tuesday := monday.Add(24 * time.Hour)
+
+	scheduled, err := mutationResolver.ScheduleDeployment(suite.ctx, assets[1].ID, tuesday)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), scheduled.ScheduledAt)
+	assert.True(suite.T(), tuesday.Equal(*scheduled.ScheduledAt))
+
+	_, err = mutationResolver.SubmitAssetForReview(suite.ctx, assets[0].ID)
+	require.NoError(suite.T(), err)
+	_, err = mutationResolver.ApproveAsset(suite.ctx, assets[0].ID, nil)
+	require.NoError(suite.T(), err)
+	_, err = mutationResolver.ScheduleDeployment(suite.ctx, assets[0].ID, monday)
+	require.NoError(suite.T(), err)
+
+	// Soonest first, without unscheduled assets
+	upcoming, err := queryResolver.ScheduledDeployments(suite.ctx, project.ID)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), upcoming, 2)
+	assert.Equal(suite.T(), assets[0].ID, upcoming[0].ID)
+	assert.Equal(suite.T(), model.AssetStatusApproved, upcoming[0].Status)
+	assert.Equal(suite.T(), assets[1].ID, upcoming[1].ID)
+
+	_, err = mutationResolver.ScheduleDeployment(suite.ctx, assets[2].ID, time.Now().Add(-time.Hour))
+	assert.EqualError(suite.T(), err, "scheduledAt must be in the future")
+
+	_, err = mutationResolver.ScheduleDeployment(suite.ctx, assets[2].ID, time.Now().Add(8*24*time.Hour))
+	assert.EqualError(suite.T(), err, "scheduledAt must be within 7 days")
+
+	_, err = mutationResolver.ScheduleDeployment(suite.ctx, uuid.New().String(), monday)
+	assert.EqualError(suite.T(), err, "asset not found")
+}
+
+func (suite *IntegrationTestSuite) TestFieldAuthorization() {
+	// Seed another tenant directly, bypassing the resolvers
+	otherUserID := uuid.New().String()
+	otherProjectID := uuid.New().String()
+	otherBoardID := uuid.New().String()
+	otherAssetID := uuid.New().String()
+
+	_, err := suite.db.Exec(`
+		INSERT INTO users (id, email, name) VALUES ($1, $2, $3)
+	`, otherUserID, "other-reviewer@test.com", "Other Tenant")
+	require.NoError(suite.T(), err)
+	defer suite.db.Exec("DELETE FROM users WHERE id = $1", otherUserID)
+	defer suite.db.Exec("DELETE FROM projects WHERE id = $1", otherProjectID)
+
+	_, err = suite.db.Exec(`
+		INSERT INTO projects (id, name, owner_id) VALUES ($1, $2, $3)
+	`, otherProjectID, "Other Tenant Project", otherUserID)
+	require.NoError(suite.T(), err)
+
+	_, err = suite.db.Exec(`
+		INSERT INTO boards (id, name, project_id) VALUES ($1, $2, $3)
+	`, otherBoardID, "Other Tenant Board", otherProjectID)
+	require.NoError(suite.T(), err)
+
+	_, err = suite.db.Exec(`
+		INSERT INTO assets (id, name, type, url, board_id) VALUES ($1, $2, $3, $4, $5)
+	`, otherAssetID, "Other Tenant Asset", model.AssetTypeImage, "https://example.com/other.jpg", otherBoardID)
+	require.NoError(suite.T(), err)
+
+	reviewer := &auth.User{ID: suite.userID, Email: "integration@test.com", Role: "reviewer"}
+	projectQuery := fmt.Sprintf(`{ project(id: %q) { id boards { edges { node { assets { edges { node { id } } } } } } } }`, otherProjectID)
+	approveMutation := fmt.Sprintf(`mutation { approveAsset(assetId: %q) { id status } }`, otherAssetID)
+
+	// Projects the user cannot access are forbidden rather than missing
+	resp := postAuthorized(suite.T(), suite.resolver, reviewer, projectQuery)
+	require.Len(suite.T(), resp.Errors, 1)
+	assert.Equal(suite.T(), errForbidden, resp.Errors[0].Extensions["code"])
+
+	resp = postAuthorized(suite.T(), suite.resolver, reviewer, fmt.Sprintf(`{ project(id: %q) { id } }`, uuid.New().String()))
+	require.Len(suite.T(), resp.Errors, 1)
+	assert.Equal(suite.T(), "project not found", resp.Errors[0].Message)
+	assert.Nil(suite.T(), resp.Errors[0].Extensions["code"])
+
+	resp = postAuthorized(suite.T(), suite.resolver, reviewer, approveMutation)
+	require.Len(suite.T(), resp.Errors, 1)
+	assert.Equal(suite.T(), errForbidden, resp.Errors[0].Extensions["code"])
+
+	// Membership grants access to the project, its boards' assets and their review
+	_, err = suite.db.Exec(`
+		INSERT INTO project_members (project_id, user_id) VALUES ($1, $2)
+	`, otherProjectID, suite.userID)
+	require.NoError(suite.T(), err)
+
+	resp = postAuthorized(suite.T(), suite.resolver, reviewer, projectQuery)
+	require.Empty(suite.T(), resp.Errors)
+	assert.Contains(suite.T(), string(resp.Data), otherAssetID)
+
+	// Members without a reviewer role still cannot approve
+	member := &auth.User{ID: suite.userID, Email: "integration@test.com", Role: "user"}
+	resp = postAuthorized(suite.T(), suite.resolver, member, approveMutation)
+	require.Len(suite.T(), resp.Errors, 1)
+	assert.Equal(suite.T(), errForbidden, resp.Errors[0].Extensions["code"])
+
+	// Nor can reviewers who are editors rather than admins of the project
+	resp = postAuthorized(suite.T(), suite.resolver, reviewer, approveMutation)
+	require.Len(suite.T(), resp.Errors, 1)
+	assert.Equal(suite.T(), errForbidden, resp.Errors[0].Extensions["code"])
+
+	_, err = suite.db.Exec(`
+		UPDATE project_members SET role = 'admin' WHERE project_id = $1 AND user_id = $2
+	`, otherProjectID, suite.userID)
+	require.NoError(suite.T(), err)
+
+	resp = postAuthorized(suite.T(), suite.resolver, reviewer, approveMutation)
+	require.Empty(suite.T(), resp.Errors)
+	assert.JSONEq(suite.T(), fmt.Sprintf(`{"approveAsset": {"id": %q, "status": "APPROVED"}}`, otherAssetID), string(resp.Data))
+}
+
+func (suite *IntegrationTestSuite) TestProjectRoles() {
+	mutationResolver := &mutationResolver{suite.resolver}
+	queryResolver := &queryResolver{suite.resolver}
+
+	project, err := mutationResolver.CreateProject(suite.ctx, model.CreateProjectInput{Name: "Roles Project"})
+	require.NoError(suite.T(), err)
+
+	members := map[string]*auth.User{}
+	for _, role := range []string{"viewer", "editor", "admin"} {
+		user := &auth.User{ID: uuid.New().String(), Email: role + "@test.com", Role: "user"}
+		_, err := suite.db.Exec(`INSERT INTO users (id, email, name) VALUES ($1, $2, $3)`, user.ID, user.Email, role)
+		require.NoError(suite.T(), err)
+		defer suite.db.Exec("DELETE FROM users WHERE id = $1", user.ID)
+
+		_, err = suite.db.Exec(`
+			INSERT INTO project_members (project_id, user_id, role) VALUES ($1, $2, $3)
+		`, project.ID, user.ID, role)
+		require.NoError(suite.T(), err)
+		members[role] = user
+	}
+
+	_, err = suite.db.Exec(`
+		INSERT INTO project_members (project_id, user_id, role) VALUES ($1, $2, 'owner')
+	`, project.ID, members["viewer"].ID)
+	assert.Error(suite.T(), err, "only viewer, editor and admin roles are stored")
+
+	// Permissions grow with the role; approving also takes a reviewer role
+	permissions, err := queryResolver.MyPermissions(context.WithValue(context.Background(), "user", members["viewer"]), project.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.RoleViewer, *permissions.Role)
+	assert.True(suite.T(), permissions.CanView)
+	assert.False(suite.T(), permissions.CanUpload)
+
+	permissions, err = queryResolver.MyPermissions(context.WithValue(context.Background(), "user", members["editor"]), project.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.RoleEditor, *permissions.Role)
+	assert.True(suite.T(), permissions.CanUpload)
+	assert.False(suite.T(), permissions.CanApprove)
+	assert.False(suite.T(), permissions.CanDelete)
+
+	permissions, err = queryResolver.MyPermissions(context.WithValue(context.Background(), "user", members["admin"]), project.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.RoleAdmin, *permissions.Role)
+	assert.False(suite.T(), permissions.CanApprove)
+	assert.True(suite.T(), permissions.CanDelete)
+
+	permissions, err = queryResolver.MyPermissions(suite.ctx, project.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.RoleAdmin, *permissions.Role, "owners are admins")
+
+	outsider := &auth.User{ID: uuid.New().String(), Role: "user"}
+	permissions, err = queryResolver.MyPermissions(context.WithValue(context.Background(), "user", outsider), project.ID)
+	require.NoError(suite.T(), err)
+	assert.Nil(suite.T(), permissions.Role)
+	assert.False(suite.T(), permissions.CanView)
+
+	// @hasRole(role: EDITOR) keeps viewers from creating boards
+	createBoard := fmt.Sprintf(`mutation { createBoard(input: {name: "Launch", projectId: %q}) { id } }`, project.ID)
+	resp := postAuthorized(suite.T(), suite.resolver, members["viewer"], createBoard)
+	require.Len(suite.T(), resp.Errors, 1)
+	assert.Equal(suite.T(), errForbidden, resp.Errors[0].Extensions["code"])
+	assert.Equal(suite.T(), fmt.Sprintf("createBoard requires the EDITOR role in project %s", project.ID), resp.Errors[0].Message)
+
+	resp = postAuthorized(suite.T(), suite.resolver, members["editor"], createBoard)
+	require.Empty(suite.T(), resp.Errors)
+
+	// Editors cannot delete the project
+	deleteProject := fmt.Sprintf(`mutation { deleteProject(id: %q) }`, project.ID)
+	resp = postAuthorized(suite.T(), suite.resolver, members["editor"], deleteProject)
+	require.Len(suite.T(), resp.Errors, 1)
+	assert.Equal(suite.T(), errForbidden, resp.Errors[0].Extensions["code"])
+}
+
+// Test helper implementations - these are kept for reference but not used in integration tests
+// In integration tests, we focus on database operations and use nil for external services
+
+// Run the integration test suite
+func TestIntegrationSuite(t *testing.T) {
+	// Skip integration tests if not in integration test mode
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+		return
+	}
+
+	suite.Run(t, new(IntegrationTestSuite))
+} 
+func (suite *IntegrationTestSuite) TestUploadAssetDeduplicatesContent() {
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("contents of " + path.Base(r.URL.Path)))
+	}))
+	defer files.Close()
+
+	// Content hashes come from a worker on an in-process NATS server
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	natsServer := natsserver.RunServer(&opts)
+	defer natsServer.Shutdown()
+
+	natsConn, err := nats.Connect(natsServer.ClientURL())
+	require.NoError(suite.T(), err)
+	defer natsConn.Close()
+
+	subscription, err := assethash.NewWorker(assethash.NewHasher(files.Client()), assethash.NewDBStore(suite.db), natsConn).Start()
+	require.NoError(suite.T(), err)
+	defer subscription.Unsubscribe()
+
+	resolver := *suite.resolver
+	resolver.NatsConn = natsConn
+	mutationResolver := &mutationResolver{&resolver}
+	ctx := operationContext()
+	ctx = context.WithValue(ctx, "user", suite.ctx.Value("user"))
+
+	project, err := mutationResolver.CreateProject(ctx, model.CreateProjectInput{Name: "Dedup Project"})
+	require.NoError(suite.T(), err)
+	board, err := mutationResolver.CreateBoard(ctx, model.CreateBoardInput{Name: "Dedup Board", ProjectID: project.ID})
+	require.NoError(suite.T(), err)
+	otherBoard, err := mutationResolver.CreateBoard(ctx, model.CreateBoardInput{Name: "Other Dedup Board", ProjectID: project.ID})
+	require.NoError(suite.T(), err)
+
+	upload := func(name, url, boardID string) *model.Asset {
+		asset, err := mutationResolver.UploadAsset(ctx, model.UploadAssetInput{
+			Name:    name,
+			Type:    model.AssetTypeImage,
+			URL:     files.URL + url,
+			BoardID: boardID,
+		})
+		require.NoError(suite.T(), err)
+		return asset
+	}
+	hashed := func(assetID string) (contentHash, duplicateOf sql.NullString) {
+		require.Eventually(suite.T(), func() bool {
+			err := suite.db.QueryRow(`SELECT content_hash, duplicate_of FROM assets WHERE id = $1`, assetID).Scan(&contentHash, &duplicateOf)
+			return err == nil && contentHash.Valid
+		}, 5*time.Second, 10*time.Millisecond)
+		return contentHash, duplicateOf
+	}
+
+	// Uploads are hashed in the background
+	original := upload("Banner", "/cdn-a/banner.png", board.ID)
+	assert.Nil(suite.T(), graphql.GetExtension(ctx, "duplicateWarning"))
+	contentHash, duplicateOf := hashed(original.ID)
+	assert.Len(suite.T(), contentHash.String, 64)
+	assert.False(suite.T(), duplicateOf.Valid)
+
+	// A hashed file uploaded again to another board of the project is a duplicate
+	duplicate := upload("Banner copy", "/cdn-a/banner.png", otherBoard.ID)
+	assert.Equal(suite.T(), original.ID, duplicate.ID)
+	assert.Equal(suite.T(), "Banner", duplicate.Name)
+
+	warnings, ok := graphql.GetExtension(ctx, "duplicateWarning").(*[]DuplicateWarning)
+	require.True(suite.T(), ok)
+	require.Len(suite.T(), *warnings, 1)
+	assert.Equal(suite.T(), original.ID, (*warnings)[0].AssetID)
+	assert.Equal(suite.T(), contentHash.String, (*warnings)[0].ContentHash)
+
+	// The same file at another URL is created and marked once hashed
+	mirrored := upload("Banner mirror", "/cdn-b/banner.png", otherBoard.ID)
+	assert.NotEqual(suite.T(), original.ID, mirrored.ID)
+	_, duplicateOf = hashed(mirrored.ID)
+	assert.Equal(suite.T(), original.ID, duplicateOf.String)
+
+	// Other files and other projects are not duplicates
+	different := upload("Logo", "/cdn-a/logo.png", board.ID)
+	assert.NotEqual(suite.T(), original.ID, different.ID)
+	_, duplicateOf = hashed(different.ID)
+	assert.False(suite.T(), duplicateOf.Valid)
+
+	otherProject, err := mutationResolver.CreateProject(ctx, model.CreateProjectInput{Name: "Other Dedup Project"})
+	require.NoError(suite.T(), err)
+	otherProjectBoard, err := mutationResolver.CreateBoard(ctx, model.CreateBoardInput{Name: "Dedup Board", ProjectID: otherProject.ID})
+	require.NoError(suite.T(), err)
+
+	elsewhere := upload("Banner", "/cdn-a/banner.png", otherProjectBoard.ID)
+	assert.NotEqual(suite.T(), original.ID, elsewhere.ID)
+	_, duplicateOf = hashed(elsewhere.ID)
+	assert.False(suite.T(), duplicateOf.Valid)
+	assert.Len(suite.T(), *warnings, 1)
+}
+
+func (suite *IntegrationTestSuite) TestSoftDelete() {
+	// Restored assets are published to the board subscribers
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	natsServer := natsserver.RunServer(&opts)
+	defer natsServer.Shutdown()
+
+	natsConn, err := nats.Connect(natsServer.ClientURL())
+	require.NoError(suite.T(), err)
+	defer natsConn.Close()
+
+	resolver := *suite.resolver
+	resolver.NatsConn = natsConn
+	mutationResolver := &mutationResolver{&resolver}
+	queryResolver := &queryResolver{&resolver}
+	boardResolver := &boardResolver{&resolver}
+
+	project, err := mutationResolver.CreateProject(suite.ctx, model.CreateProjectInput{Name: "Soft Delete Project"})
+	require.NoError(suite.T(), err)
+	board, err := mutationResolver.CreateBoard(suite.ctx, model.CreateBoardInput{Name: "Soft Delete Board", ProjectID: project.ID})
+	require.NoError(suite.T(), err)
+	asset, err := mutationResolver.UploadAsset(suite.ctx, model.UploadAssetInput{
+		Name:    "Launch banner",
+		Type:    model.AssetTypeImage,
+		URL:     "https://example.com/launch-banner.jpg",
+		BoardID: board.ID,
+	})
+	require.NoError(suite.T(), err)
+
+	deleted, err := mutationResolver.DeleteAsset(suite.ctx, asset.ID)
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), deleted)
+
+	assets, err := boardResolver.Assets(suite.ctx, board, 0, nil, 0, nil)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), assets.Edges)
+
+	_, err = mutationResolver.DeleteAsset(suite.ctx, asset.ID)
+	assert.EqualError(suite.T(), err, "asset not found")
+
+	restored, err := mutationResolver.RestoreAsset(suite.ctx, asset.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), asset.ID, restored.ID)
+
+	_, err = mutationResolver.RestoreAsset(suite.ctx, asset.ID)
+	assert.EqualError(suite.T(), err, "asset is not deleted")
+
+	// Deleting the project hides it with its boards, whose assets cannot be
+	// restored on their own
+	deleted, err = mutationResolver.DeleteProject(suite.ctx, project.ID)
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), deleted)
+
+	_, err = queryResolver.Project(suite.ctx, project.ID)
+	assert.Error(suite.T(), err)
+	_, err = queryResolver.Board(suite.ctx, board.ID)
+	assert.Error(suite.T(), err)
+
+	_, err = mutationResolver.RestoreAsset(suite.ctx, asset.ID)
+	assert.EqualError(suite.T(), err, "the board of the asset is deleted")
+
+	// Only admins purge, and only what was deleted before olderThan
+	_, err = mutationResolver.PurgeDeleted(suite.ctx, time.Now())
+	assert.EqualError(suite.T(), err, "admin access required")
+
+	admin := context.WithValue(suite.ctx, "user", &auth.User{ID: uuid.New().String(), Role: "admin"})
+	purged, err := mutationResolver.PurgeDeleted(admin, time.Now().Add(-time.Hour))
+	require.NoError(suite.T(), err)
+	assert.Zero(suite.T(), purged)
+
+	purged, err = mutationResolver.PurgeDeleted(admin, time.Now().Add(time.Minute))
+	require.NoError(suite.T(), err)
+	assert.GreaterOrEqual(suite.T(), purged, 3)
+
+	var remaining int
+	err = suite.db.QueryRow(`SELECT COUNT(*) FROM assets WHERE id = $1`, asset.ID).Scan(&remaining)
+	require.NoError(suite.T(), err)
+	assert.Zero(suite.T(), remaining)
+}
+
+func (suite *IntegrationTestSuite) TestAssetAutocomplete() {
+	server := miniredis.RunT(suite.T())
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	resolver := *suite.resolver
+	resolver.Autocomplete = autocomplete.NewIndex(client)
+	mutationResolver := &mutationResolver{&resolver}
+
+	project, err := mutationResolver.CreateProject(suite.ctx, model.CreateProjectInput{Name: "Autocomplete Project"})
+	require.NoError(suite.T(), err)
+	board, err := mutationResolver.CreateBoard(suite.ctx, model.CreateBoardInput{Name: "Autocomplete Board", ProjectID: project.ID})
+	require.NoError(suite.T(), err)
+
+	upload := func(name string) *model.Asset {
+		asset, err := mutationResolver.UploadAsset(suite.ctx, model.UploadAssetInput{
+			Name:    name,
+			Type:    model.AssetTypeVideo,
+			URL:     "https://example.com/" + uuid.New().String() + ".mp4",
+			BoardID: board.ID,
+		})
+		require.NoError(suite.T(), err)
+		return asset
+	}
+	suggest := func(prefix string) []AssetSuggestion {
+		suggestions, err := resolver.AutocompleteAssets(suite.ctx, project.ID, prefix, 10)
+		require.NoError(suite.T(), err)
+		return suggestions
+	}
+
+	sale := upload("Summer Sale")
+	banner := upload("summer banner")
+	upload("Winter teaser")
+
+	// Assets are indexed in the background once uploaded
+	require.Eventually(suite.T(), func() bool { return len(suggest("sum")) == 2 }, time.Second, 10*time.Millisecond)
+	assert.Equal(suite.T(), []AssetSuggestion{
+		{ID: banner.ID, Name: "summer banner", Status: model.AssetStatusPending},
+		{ID: sale.ID, Name: "Summer Sale", Status: model.AssetStatusPending},
+	}, suggest("SUM"))
+
+	// Statuses are current without reindexing
+	_, err = mutationResolver.SubmitAssetForReview(suite.ctx, sale.ID)
+	require.NoError(suite.T(), err)
+	_, err = mutationResolver.ApproveAsset(suite.ctx, sale.ID, nil)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.AssetStatusApproved, suggest("summer s")[0].Status)
+
+	// Deleted assets are no longer suggested, nor indexed
+	_, err = mutationResolver.DeleteAsset(suite.ctx, banner.ID)
+	require.NoError(suite.T(), err)
+	assert.Len(suite.T(), suggest("summer"), 1)
+	require.Eventually(suite.T(), func() bool { return !server.Exists("autocomplete:asset:" + banner.ID) }, time.Second, 10*time.Millisecond)
+
+	// The assets are indexed again once Redis loses its data
+	server.FlushAll()
+	assert.Empty(suite.T(), suggest("summer"))
+	require.NoError(suite.T(), resolver.BackfillAutocomplete(suite.ctx))
+	assert.Len(suite.T(), suggest("summer"), 1)
+	assert.Len(suite.T(), suggest("winter"), 1)
+
+	// Other users' projects are not searched
+	other := context.WithValue(context.Background(), "user", &auth.User{ID: uuid.New().String()})
+	_, err = resolver.AutocompleteAssets(other, project.ID, "summer", 10)
+	assert.True(suite.T(), IsForbidden(err))
+
+	_, err = resolver.AutocompleteAssets(suite.ctx, uuid.New().String(), "summer", 10)
+	assert.ErrorIs(suite.T(), err, ErrProjectNotFound)
+}
+
+func (suite *IntegrationTestSuite) TestApproveAssets() {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	natsServer := natsserver.RunServer(&opts)
+	defer natsServer.Shutdown()
+
+	natsConn, err := nats.Connect(natsServer.ClientURL())
+	require.NoError(suite.T(), err)
+	defer natsConn.Close()
+
+	batches := make(chan []byte, 1)
+	subscription, err := natsConn.Subscribe("zamc.events.asset.approved_batch", func(msg *natsgo.Msg) {
+		batches <- msg.Data
+	})
+	require.NoError(suite.T(), err)
+	defer subscription.Unsubscribe()
+
+	resolver := *suite.resolver
+	resolver.NatsConn = natsConn
+	mutationResolver := &mutationResolver{&resolver}
+
+	project, err := mutationResolver.CreateProject(suite.ctx, model.CreateProjectInput{Name: "Batch Approval Project"})
+	require.NoError(suite.T(), err)
+	board, err := mutationResolver.CreateBoard(suite.ctx, model.CreateBoardInput{Name: "Batch Approval Board", ProjectID: project.ID})
+	require.NoError(suite.T(), err)
+
+	var assets []*model.Asset
+	for _, name := range []string{"First", "Second", "Already approved"} {
+		asset, err := mutationResolver.UploadAsset(suite.ctx, model.UploadAssetInput{
+			Name:    name,
+			Type:    model.AssetTypeImage,
+			URL:     "https://example.com/" + name + ".jpg",
+			BoardID: board.ID,
+		})
+		require.NoError(suite.T(), err)
+		_, err = mutationResolver.SubmitAssetForReview(suite.ctx, asset.ID)
+		require.NoError(suite.T(), err)
+		assets = append(assets, asset)
+	}
+	_, err = mutationResolver.ApproveAsset(suite.ctx, assets[2].ID, nil)
+	require.NoError(suite.T(), err)
+
+	missingID := uuid.New().String()
+	results, err := mutationResolver.ApproveAssets(suite.ctx, []string{assets[0].ID, assets[2].ID, missingID, assets[1].ID, assets[0].ID})
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), results, 4)
+
+	// Results follow the requested order, without duplicates
+	approved, ok := results[0].(*model.Asset)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), assets[0].ID, approved.ID)
+	assert.Equal(suite.T(), model.AssetStatusApproved, approved.Status)
+	assert.Equal(suite.T(), &model.ApprovalError{
+		AssetID: assets[2].ID,
+		Reason:  "invalid asset status transition from APPROVED to APPROVED",
+	}, results[1])
+	assert.Equal(suite.T(), &model.ApprovalError{AssetID: missingID, Reason: "asset not found"}, results[2])
+	approved, ok = results[3].(*model.Asset)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), assets[1].ID, approved.ID)
+
+	// The connectors service gets one event for the whole batch
+	select {
+	case data := <-batches:
+		var event nats.AssetsApprovedEvent
+		require.NoError(suite.T(), json.Unmarshal(data, &event))
+		assert.Equal(suite.T(), []string{assets[0].ID, assets[1].ID}, event.AssetIDs)
+	case <-time.After(5 * time.Second):
+		suite.T().Fatal("approved assets were not published")
+	}
+}
+
+func (suite *IntegrationTestSuite) TestAssetVariants() {
+	mutationResolver := &mutationResolver{suite.resolver}
+	queryResolver := &queryResolver{suite.resolver}
+
+	project, err := mutationResolver.CreateProject(suite.ctx, model.CreateProjectInput{Name: "Variant Project"})
+	require.NoError(suite.T(), err)
+	board, err := mutationResolver.CreateBoard(suite.ctx, model.CreateBoardInput{Name: "Variant Board", ProjectID: project.ID})
+	require.NoError(suite.T(), err)
+
+	variantGroup := "spring-trail-headlines"
+	var variants []string
+	for _, name := range []string{"Headline A", "Headline B", "Unrelated"} {
+		input := model.UploadAssetInput{
+			Name:    name,
+			Type:    model.AssetTypeImage,
+			URL:     "https://example.com/" + name + ".jpg",
+			BoardID: board.ID,
+		}
+		if name != "Unrelated" {
+			input.VariantGroup = &variantGroup
+		}
+		asset, err := mutationResolver.UploadAsset(suite.ctx, input)
+		require.NoError(suite.T(), err)
+		if input.VariantGroup != nil {
+			variants = append(variants, asset.ID)
+		}
+	}
+
+	assets, err := queryResolver.AssetVariants(suite.ctx, variantGroup)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), assets, 2)
+	assert.Equal(suite.T(), variants, []string{assets[0].ID, assets[1].ID})
+
+	assets, err = queryResolver.AssetVariants(suite.ctx, "autumn-trail-headlines")
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), assets)
+}
+
+func (suite *IntegrationTestSuite) TestAssetStatusChanged() {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	natsServer := natsserver.RunServer(&opts)
+	defer natsServer.Shutdown()
+
+	natsConn, err := nats.Connect(natsServer.ClientURL())
+	require.NoError(suite.T(), err)
+	defer natsConn.Close()
+
+	resolver := *suite.resolver
+	resolver.NatsConn = natsConn
+	mutationResolver := &mutationResolver{&resolver}
+	subscriptionResolver := &subscriptionResolver{&resolver}
+
+	project, err := mutationResolver.CreateProject(suite.ctx, model.CreateProjectInput{Name: "Live Status Project"})
+	require.NoError(suite.T(), err)
+	board, err := mutationResolver.CreateBoard(suite.ctx, model.CreateBoardInput{Name: "Live Status Board", ProjectID: project.ID})
+	require.NoError(suite.T(), err)
+
+	ctx, cancel := context.WithCancel(suite.ctx)
+	defer cancel()
+	updates, err := subscriptionResolver.AssetStatusChanged(ctx, board.ID, nil)
+	require.NoError(suite.T(), err)
+	approvals, err := subscriptionResolver.AssetStatusChanged(ctx, board.ID, []model.AssetStatus{model.AssetStatusApproved})
+	require.NoError(suite.T(), err)
+	require.NoError(suite.T(), natsConn.Flush())
+
+	receive := func() *model.Asset {
+		select {
+		case asset := <-updates:
+			return asset
+		case <-time.After(5 * time.Second):
+			suite.T().Fatal("asset update was not received")
+			return nil
+		}
+	}
+
+	asset, err := mutationResolver.UploadAsset(suite.ctx, model.UploadAssetInput{
+		Name:    "Live hero",
+		Type:    model.AssetTypeImage,
+		URL:     "https://example.com/live-hero.jpg",
+		BoardID: board.ID,
+	})
+	require.NoError(suite.T(), err)
+
+	uploaded := receive()
+	assert.Equal(suite.T(), asset.ID, uploaded.ID)
+	assert.Equal(suite.T(), model.AssetStatusPending, uploaded.Status)
+
+	_, err = mutationResolver.SubmitAssetForReview(suite.ctx, asset.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.AssetStatusReview, receive().Status)
+
+	_, err = mutationResolver.ApproveAsset(suite.ctx, asset.ID, nil)
+	require.NoError(suite.T(), err)
+
+	approved := receive()
+	assert.Equal(suite.T(), asset.ID, approved.ID)
+	assert.Equal(suite.T(), model.AssetStatusApproved, approved.Status)
+	assert.NotNil(suite.T(), approved.ApprovedAt)
+
+	// The subscriber filtering on status only receives the approval
+	select {
+	case filtered := <-approvals:
+		assert.Equal(suite.T(), model.AssetStatusApproved, filtered.Status)
+	case <-time.After(5 * time.Second):
+		suite.T().Fatal("approval was not received")
+	}
+	assert.Empty(suite.T(), approvals)
+
+	// Closing the WebSocket unsubscribes from NATS
+	cancel()
+	assert.Eventually(suite.T(), func() bool { return natsConn.NumSubscriptions() == 0 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func (suite *IntegrationTestSuite) TestDeploymentHistory() {
+	mutationResolver := &mutationResolver{suite.resolver}
+	queryResolver := &queryResolver{suite.resolver}
+	projectResolver := &projectResolver{suite.resolver}
+
+	project, err := mutationResolver.CreateProject(suite.ctx, model.CreateProjectInput{Name: "Deployment History Project"})
+	require.NoError(suite.T(), err)
+	board, err := mutationResolver.CreateBoard(suite.ctx, model.CreateBoardInput{Name: "Deployment History Board", ProjectID: project.ID})
+	require.NoError(suite.T(), err)
+	asset, err := mutationResolver.UploadAsset(suite.ctx, model.UploadAssetInput{
+		Name:    "Deployed hero",
+		Type:    model.AssetTypeImage,
+		URL:     "https://example.com/deployed-hero.jpg",
+		BoardID: board.ID,
+	})
+	require.NoError(suite.T(), err)
+
+	rate, err := projectResolver.SuccessRate(suite.ctx, project)
+	require.NoError(suite.T(), err)
+	assert.Zero(suite.T(), rate)
+
+	deployedAt := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	for i, result := range []nats.DeploymentResult{
+		{Platform: "google_ads", Status: "success", PlatformID: "ad-1"},
+		{Platform: "meta", Status: "failed", Error: "quota exceeded", Metrics: nats.DeploymentMetrics{Duration: 1500 * time.Millisecond, RetryCount: 2}},
+		{Platform: "meta", Status: "success", PlatformID: "ad-2"},
+		{Platform: "meta", Status: "estimated"},
+	} {
+		result.DeployedAt = deployedAt.Add(time.Duration(i) * time.Hour)
+		err := suite.resolver.RecordDeployment(suite.ctx, &nats.DeploymentStatusChangedEvent{
+			EventType:        "asset.deployment_status_changed",
+			AssetID:          asset.ID,
+			ProjectID:        project.ID,
+			Platform:         result.Platform,
+			DeploymentResult: result,
+		})
+		require.NoError(suite.T(), err)
+	}
+
+	// Dry runs are left out and pages are newest first
+	history, err := queryResolver.DeploymentHistory(suite.ctx, nil, &project.ID, nil, nil, 2, nil, nil, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), history.Edges, 2)
+	assert.True(suite.T(), history.PageInfo.HasNextPage)
+	assert.Equal(suite.T(), "ad-2", *history.Edges[0].Node.PlatformID)
+	assert.Equal(suite.T(), model.DeploymentStatusFailed, history.Edges[1].Node.Status)
+	assert.Equal(suite.T(), "quota exceeded", *history.Edges[1].Node.Error)
+	assert.Equal(suite.T(), 1500, history.Edges[1].Node.DurationMs)
+	assert.Equal(suite.T(), 2, history.Edges[1].Node.RetryCount)
+	assert.Equal(suite.T(), asset.ID, history.Edges[1].Node.Asset.ID)
+
+	history, err = queryResolver.DeploymentHistory(suite.ctx, nil, &project.ID, nil, nil, 2, history.PageInfo.EndCursor, nil, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), history.Edges, 1)
+	assert.False(suite.T(), history.PageInfo.HasNextPage)
+	assert.Equal(suite.T(), model.CampaignPlatformGoogleAds, history.Edges[0].Node.Platform)
+
+	// Filters combine
+	meta := model.CampaignPlatformMeta
+	success := model.DeploymentStatusSuccess
+	history, err = queryResolver.DeploymentHistory(suite.ctx, &asset.ID, nil, &meta, &success, 20, nil, nil, nil)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), history.Edges, 1)
+	assert.Equal(suite.T(), "ad-2", *history.Edges[0].Node.PlatformID)
+
+	since, until := deployedAt.Add(30*time.Minute), deployedAt.Add(90*time.Minute)
+	history, err = queryResolver.DeploymentHistory(suite.ctx, &asset.ID, nil, nil, nil, 20, nil, &since, &until)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), history.Edges, 1)
+	assert.Equal(suite.T(), model.DeploymentStatusFailed, history.Edges[0].Node.Status)
+
+	rate, err = projectResolver.SuccessRate(suite.ctx, project)
+	require.NoError(suite.T(), err)
+	assert.InDelta(suite.T(), 2.0/3, rate, 0.0001)
+
+	// Other users see no history
+	outsider := context.WithValue(context.Background(), "user", &auth.User{ID: uuid.New().String(), Role: "user"})
+	history, err = queryResolver.DeploymentHistory(outsider, nil, &project.ID, nil, nil, 20, nil, nil, nil)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), history.Edges)
+}
+
+func (suite *IntegrationTestSuite) TestBoardTemplates() {
+	mutationResolver := &mutationResolver{suite.resolver}
+	queryResolver := &queryResolver{suite.resolver}
+
+	// The system templates are seeded by the migrations
+	templates, err := queryResolver.BoardTemplates(suite.ctx)
+	require.NoError(suite.T(), err)
+	var launch *model.BoardTemplate
+	for _, template := range templates {
+		if template.Name == "Product Launch" {
+			launch = template
+		}
+	}
+	require.NotNil(suite.T(), launch)
+	assert.True(suite.T(), launch.IsPublic)
+	assert.Nil(suite.T(), launch.CreatedBy)
+	require.NotEmpty(suite.T(), launch.Preview)
+
+	project, err := mutationResolver.CreateProjectFromTemplate(suite.ctx, launch.ID, "Spring Launch")
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Spring Launch", project.Name)
+
+	template, err := mutationResolver.SaveAsTemplate(suite.ctx, project.ID, "My Launch")
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), template.IsPublic)
+	require.NotNil(suite.T(), template.CreatedBy)
+	assert.Equal(suite.T(), suite.userID, *template.CreatedBy)
+	assert.Equal(suite.T(), launch.Preview, template.Preview)
+
+	// Saved templates are private to their creator
+	outsider := context.WithValue(context.Background(), "user", &auth.User{ID: uuid.New().String(), Role: "user"})
+	_, err = mutationResolver.CreateProjectFromTemplate(outsider, template.ID, "Copied Launch")
+	assert.EqualError(suite.T(), err, "board template not found")
+
+	_, err = mutationResolver.SaveAsTemplate(outsider, project.ID, "Stolen Launch")
+	assert.EqualError(suite.T(), err, "project not found")
+}
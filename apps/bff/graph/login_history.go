@@ -0,0 +1,27 @@
+package graph
+
+import (
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/audit"
+)
+
+// maxLoginHistoryLimit is the most login events returned by one loginHistory query
+const maxLoginHistoryLimit = 100
+
+// loginEvent converts a stored login event to its GraphQL type
+func loginEvent(event *audit.LoginEvent) *model.LoginEvent {
+	loginEvent := &model.LoginEvent{
+		ID:        event.ID,
+		EventType: event.EventType,
+		IPAddress: event.IPAddress,
+		UserAgent: event.UserAgent,
+		Country:   event.Country,
+		City:      event.City,
+		Success:   event.Success,
+		CreatedAt: event.CreatedAt,
+	}
+	if event.FailureReason != "" {
+		loginEvent.FailureReason = &event.FailureReason
+	}
+	return loginEvent
+}
@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/audit"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+)
+
+type memoryLoginStore struct {
+	mu     sync.Mutex
+	events []*audit.LoginEvent
+}
+
+func (s *memoryLoginStore) InsertLogin(ctx context.Context, event *audit.LoginEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *memoryLoginStore) ListLogins(ctx context.Context, userID string, limit, offset int) ([]*audit.LoginEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []*audit.LoginEvent
+	for i := len(s.events) - 1; i >= 0 && len(events) < limit; i-- {
+		if s.events[i].UserID != userID {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		events = append(events, s.events[i])
+	}
+	return events, nil
+}
+
+func TestQueryResolver_LoginHistory(t *testing.T) {
+	store := &memoryLoginStore{}
+	recorder := audit.NewLoginRecorder(store, 10)
+	resolver := &queryResolver{&Resolver{Logins: recorder}}
+
+	require.NoError(t, recorder.Record(&audit.LoginEvent{UserID: "user-1", EventType: audit.LoginEventTokenVerification, IPAddress: "203.0.113.9", Country: "DE", City: "Berlin", Success: true}))
+	require.NoError(t, recorder.Record(&audit.LoginEvent{UserID: "user-2", EventType: audit.LoginEventTokenVerification, Success: true}))
+	require.NoError(t, recorder.Record(&audit.LoginEvent{UserID: "user-1", EventType: audit.LoginEventTokenVerification, FailureReason: "token is expired"}))
+	recorder.Close()
+
+	_, err := resolver.LoginHistory(context.Background(), 10, 0)
+	assert.EqualError(t, err, "unauthorized")
+
+	ctx := context.WithValue(context.Background(), "user", &auth.User{ID: "user-1", Role: "user"})
+	_, err = resolver.LoginHistory(ctx, maxLoginHistoryLimit+1, 0)
+	assert.Error(t, err)
+	_, err = resolver.LoginHistory(ctx, 10, -1)
+	assert.Error(t, err)
+
+	// Users only see their own events, newest first
+	history, err := resolver.LoginHistory(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.False(t, history[0].Success)
+	require.NotNil(t, history[0].FailureReason)
+	assert.Equal(t, "token is expired", *history[0].FailureReason)
+	assert.True(t, history[1].Success)
+	assert.Nil(t, history[1].FailureReason)
+	assert.Equal(t, "Berlin", history[1].City)
+
+	history, err = resolver.LoginHistory(ctx, 10, 1)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "DE", history[0].Country)
+}
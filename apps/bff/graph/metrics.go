@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/metrics"
+)
+
+// RequestMetrics records the count and duration of GraphQL operations by
+// operation name. Operations failing validation are recorded under the
+// operationName of the request, and subscriptions are not recorded, as they
+// last as long as the client stays subscribed.
+type RequestMetrics struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = RequestMetrics{}
+
+// ExtensionName returns the name of the extension
+func (RequestMetrics) ExtensionName() string {
+	return "RequestMetrics"
+}
+
+// Validate accepts every schema
+func (RequestMetrics) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse records the operation once its response is ready
+func (RequestMetrics) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+	if !graphql.HasOperationContext(ctx) {
+		return resp
+	}
+
+	rc := graphql.GetOperationContext(ctx)
+	if rc.Operation != nil && rc.Operation.Operation == ast.Subscription {
+		return resp
+	}
+
+	operation := rc.OperationName
+	if rc.Operation != nil {
+		operation = rc.Operation.Name
+	}
+	if operation == "" {
+		operation = "anonymous"
+	}
+
+	status := "success"
+	if resp == nil || len(resp.Errors) > 0 {
+		status = "error"
+	}
+
+	metrics.GraphQLRequests.WithLabelValues(operation, status).Inc()
+	metrics.GraphQLRequestDuration.WithLabelValues(operation).Observe(time.Since(rc.Stats.OperationStart).Seconds())
+
+	return resp
+}
+
+// recordCacheLookup counts a resolver cache lookup for the cache hit ratio
+func recordCacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	metrics.CacheRequests.WithLabelValues(cache, result).Inc()
+}
@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/generated"
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/metrics"
+)
+
+// postOperation sends query, naming operationName when not empty, to a server
+// recording request metrics
+func postOperation(t *testing.T, query, operationName string) {
+	t.Helper()
+
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{Resolvers: &Resolver{}}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(RequestMetrics{})
+
+	body, err := json.Marshal(map[string]string{"query": query, "operationName": operationName})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestRequestMetrics(t *testing.T) {
+	succeeded := metrics.GraphQLRequests.WithLabelValues("MetricsTypename", "success")
+	failed := metrics.GraphQLRequests.WithLabelValues("MetricsMissingField", "error")
+	duration := metrics.GraphQLRequestDuration.WithLabelValues("MetricsTypename").(prometheus.Metric)
+	anonymous := metrics.GraphQLRequests.WithLabelValues("anonymous", "success")
+	before := []float64{testutil.ToFloat64(succeeded), testutil.ToFloat64(failed), testutil.ToFloat64(anonymous)}
+	observed := func() uint64 {
+		var m dto.Metric
+		require.NoError(t, duration.Write(&m))
+		return m.GetHistogram().GetSampleCount()
+	}
+	observedBefore := observed()
+
+	postOperation(t, `query MetricsTypename { __typename }`, "")
+	postOperation(t, `query MetricsMissingField { missingField }`, "MetricsMissingField")
+	postOperation(t, `{ __typename }`, "")
+
+	assert.Equal(t, before[0]+1, testutil.ToFloat64(succeeded))
+	assert.Equal(t, before[1]+1, testutil.ToFloat64(failed))
+	assert.Equal(t, before[2]+1, testutil.ToFloat64(anonymous))
+
+	assert.Equal(t, observedBefore+1, observed())
+}
+
+func TestResolverCache_RecordsLookups(t *testing.T) {
+	cache := &ResolverCache{users: make(map[string]*model.User)}
+	hits := metrics.CacheRequests.WithLabelValues("users", "hit")
+	misses := metrics.CacheRequests.WithLabelValues("users", "miss")
+	hitsBefore, missesBefore := testutil.ToFloat64(hits), testutil.ToFloat64(misses)
+
+	cache.GetUser("user-1")
+	cache.SetUser("user-1", &model.User{ID: "user-1"})
+	cache.GetUser("user-1")
+	cache.GetUser("user-1")
+
+	assert.Equal(t, hitsBefore+2, testutil.ToFloat64(hits))
+	assert.Equal(t, missesBefore+1, testutil.ToFloat64(misses))
+}
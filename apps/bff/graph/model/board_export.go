@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+// ExportResult holds the download link of an exported board archive
+type ExportResult struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// BoardOperationType is the kind of edit a board operation makes
+type BoardOperationType string
+
+const (
+	BoardOperationTypeInsert BoardOperationType = "INSERT"
+	BoardOperationTypeDelete BoardOperationType = "DELETE"
+)
+
+func (e BoardOperationType) IsValid() bool {
+	switch e {
+	case BoardOperationTypeInsert, BoardOperationTypeDelete:
+		return true
+	}
+	return false
+}
+
+func (e BoardOperationType) String() string {
+	return string(e)
+}
+
+func (e *BoardOperationType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = BoardOperationType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid BoardOperationType", str)
+	}
+	return nil
+}
+
+func (e BoardOperationType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// BoardOperation is a text edit of a board description. Offset counts characters,
+// Chars holds the inserted or deleted text and Version is the description version
+// the operation applies to.
+type BoardOperation struct {
+	BoardID string             `json:"boardId"`
+	Type    BoardOperationType `json:"type"`
+	Offset  int                `json:"offset"`
+	Chars   string             `json:"chars"`
+	Version int                `json:"version"`
+}
+
+func (BoardOperation) IsBoardUpdate() {}
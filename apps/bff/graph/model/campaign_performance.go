@@ -48,6 +48,29 @@ type CampaignMetricsUpdate struct {
 	Timestamp  time.Time        `json:"timestamp"`
 }
 
+// ProjectROI represents the return on ad spend of the campaigns of a project
+type ProjectROI struct {
+	ProjectID        string             `json:"projectId"`
+	TotalSpend       float64            `json:"totalSpend"`
+	TotalRevenue     float64            `json:"totalRevenue"`
+	TotalConversions int                `json:"totalConversions"`
+	ROAS             float64            `json:"roas"`
+	ROASByPlatform   map[string]float64 `json:"roasByPlatform"`
+	TopCampaigns     []*CampaignMetrics `json:"topCampaigns"`
+}
+
+// SpendReport represents the ad spend of the campaigns of a project in a month
+type SpendReport struct {
+	ProjectID         string             `json:"projectId"`
+	Month             string             `json:"month"`
+	TotalSpend        float64            `json:"totalSpend"`
+	ByPlatform        map[string]float64 `json:"byPlatform"`
+	ByContentType     map[string]float64 `json:"byContentType"`
+	DailySpend        []*DailySpend      `json:"dailySpend"`
+	BudgetLimit       *float64           `json:"budgetLimit,omitempty"`
+	BudgetUtilization float64            `json:"budgetUtilization"`
+}
+
 // CampaignPerformanceAlert represents a campaign performance alert
 type CampaignPerformanceAlert struct {
 	AlertID      string        `json:"alertId"`
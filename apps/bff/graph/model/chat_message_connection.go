@@ -0,0 +1,21 @@
+package model
+
+// ChatMessageConnection is a page of chat messages, newest first
+type ChatMessageConnection struct {
+	Edges    []*ChatMessageEdge `json:"edges"`
+	PageInfo *PageInfo          `json:"pageInfo"`
+}
+
+// ChatMessageEdge pairs a chat message with the cursor that resumes after it
+type ChatMessageEdge struct {
+	Cursor string       `json:"cursor"`
+	Node   *ChatMessage `json:"node"`
+}
+
+// PageInfo describes whether more results surround a page
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor,omitempty"`
+	EndCursor       *string `json:"endCursor,omitempty"`
+}
@@ -0,0 +1,37 @@
+package model
+
+// ProjectConnection is a page of projects, oldest first
+type ProjectConnection struct {
+	Edges    []*ProjectEdge `json:"edges"`
+	PageInfo *PageInfo      `json:"pageInfo"`
+}
+
+// ProjectEdge pairs a project with its cursor
+type ProjectEdge struct {
+	Cursor string   `json:"cursor"`
+	Node   *Project `json:"node"`
+}
+
+// BoardConnection is a page of the boards of a project, oldest first
+type BoardConnection struct {
+	Edges    []*BoardEdge `json:"edges"`
+	PageInfo *PageInfo    `json:"pageInfo"`
+}
+
+// BoardEdge pairs a board with its cursor
+type BoardEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Board `json:"node"`
+}
+
+// AssetConnection is a page of the assets of a board, oldest first
+type AssetConnection struct {
+	Edges    []*AssetEdge `json:"edges"`
+	PageInfo *PageInfo    `json:"pageInfo"`
+}
+
+// AssetEdge pairs an asset with its cursor
+type AssetEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Asset `json:"node"`
+}
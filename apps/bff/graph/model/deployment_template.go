@@ -0,0 +1,75 @@
+package model
+
+import "time"
+
+// DeploymentContentType represents the kind of content an asset is deployed as
+type DeploymentContentType string
+
+const (
+	DeploymentContentTypeBlogPost      DeploymentContentType = "BLOG_POST"
+	DeploymentContentTypeSocialMedia   DeploymentContentType = "SOCIAL_MEDIA"
+	DeploymentContentTypeEmailCampaign DeploymentContentType = "EMAIL_CAMPAIGN"
+	DeploymentContentTypeVideoScript   DeploymentContentType = "VIDEO_SCRIPT"
+	DeploymentContentTypeInfographic   DeploymentContentType = "INFOGRAPHIC"
+)
+
+// DeploymentTemplate represents reusable deployment settings for one platform
+type DeploymentTemplate struct {
+	ID          string                `json:"id"`
+	Name        string                `json:"name"`
+	OwnerID     string                `json:"ownerId"`
+	Platform    CampaignPlatform      `json:"platform"`
+	ContentType DeploymentContentType `json:"contentType"`
+	Metadata    *DeploymentMetadata   `json:"metadata"`
+	CreatedAt   time.Time             `json:"createdAt"`
+}
+
+// DeploymentMetadata holds the targeting, budget and creative settings of a deployment.
+// Its JSON encoding is the metadata format of the connectors service; Platform and
+// ContentType are stored alongside it rather than in it.
+type DeploymentMetadata struct {
+	Platform       CampaignPlatform      `json:"-"`
+	ContentType    DeploymentContentType `json:"-"`
+	TargetAudience string                `json:"target_audience,omitempty"`
+	Budget         float64               `json:"budget,omitempty"`
+	CampaignType   string                `json:"campaign_type,omitempty"`
+	Keywords       []string              `json:"keywords,omitempty"`
+	Demographics   *Demographics         `json:"demographics,omitempty"`
+	CreativeSpecs  *CreativeSpecs        `json:"creative_specs,omitempty"`
+}
+
+// Demographics holds targeting demographics. CustomAudienceEmails are only
+// input: they are stored and published as CustomAudienceEmailHashes.
+type Demographics struct {
+	AgeMin                    int      `json:"age_min,omitempty"`
+	AgeMax                    int      `json:"age_max,omitempty"`
+	Genders                   []string `json:"genders,omitempty"`
+	Locations                 []string `json:"locations,omitempty"`
+	Interests                 []string `json:"interests,omitempty"`
+	Behaviors                 []string `json:"behaviors,omitempty"`
+	CustomAudienceEmails      []string `json:"-"`
+	CustomAudienceEmailHashes []string `json:"custom_audience_email_hashes,omitempty"`
+}
+
+// CreativeSpecs holds creative specifications
+type CreativeSpecs struct {
+	ImageURL     string `json:"image_url,omitempty"`
+	LogoURL      string `json:"logo_url,omitempty"`
+	VideoURL     string `json:"video_url,omitempty"`
+	Headline     string `json:"headline,omitempty"`
+	Description  string `json:"description,omitempty"`
+	CallToAction string `json:"call_to_action,omitempty"`
+	LandingURL   string `json:"landing_url,omitempty"`
+	BusinessName string `json:"business_name,omitempty"`
+}
+
+// DeploymentResult represents the outcome of deploying an asset to a platform
+type DeploymentResult struct {
+	AssetID     string           `json:"assetId"`
+	Platform    CampaignPlatform `json:"platform"`
+	Status      string           `json:"status"`
+	PlatformID  *string          `json:"platformId,omitempty"`
+	PlatformURL *string          `json:"platformUrl,omitempty"`
+	Error       *string          `json:"error,omitempty"`
+	DeployedAt  time.Time        `json:"deployedAt"`
+}
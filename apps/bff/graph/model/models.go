@@ -70,6 +70,7 @@ type ChatMessageDB struct {
 	Content   string    `json:"content" db:"content"`
 	UserID    string    `json:"userId" db:"user_id"`
 	BoardID   string    `json:"boardId" db:"board_id"`
+	ParentID  *string   `json:"parentId" db:"parent_id"`
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 }
 
@@ -128,6 +129,7 @@ func (c *ChatMessageDB) ToGraphQL() *ChatMessage {
 		Content:   c.Content,
 		UserID:    c.UserID,
 		BoardID:   c.BoardID,
+		ParentID:  c.ParentID,
 		CreatedAt: c.CreatedAt,
 	}
 } 
\ No newline at end of file
@@ -9,45 +9,158 @@ import (
 	"time"
 )
 
+type AssetApprovalResult interface {
+	IsAssetApprovalResult()
+}
+
 type BoardUpdate interface {
 	IsBoardUpdate()
 }
 
+type ApprovalError struct {
+	AssetID string `json:"assetId"`
+	Reason  string `json:"reason"`
+}
+
+func (ApprovalError) IsAssetApprovalResult() {}
+
 type Asset struct {
-	ID         string      `json:"id"`
-	Name       string      `json:"name"`
-	Type       AssetType   `json:"type"`
-	URL        *string     `json:"url,omitempty"`
-	Status     AssetStatus `json:"status"`
-	BoardID    string      `json:"boardId"`
-	Board      *Board      `json:"board"`
-	ApprovedBy *User       `json:"approvedBy,omitempty"`
-	ApprovedAt *time.Time  `json:"approvedAt,omitempty"`
-	CreatedAt  time.Time   `json:"createdAt"`
-	UpdatedAt  time.Time   `json:"updatedAt"`
+	ID                      string                      `json:"id"`
+	Name                    string                      `json:"name"`
+	Type                    AssetType                   `json:"type"`
+	URL                     *string                     `json:"url,omitempty"`
+	Status                  AssetStatus                 `json:"status"`
+	BoardID                 string                      `json:"boardId"`
+	Board                   *Board                      `json:"board"`
+	ApprovedBy              *User                       `json:"approvedBy,omitempty"`
+	ApprovedAt              *time.Time                  `json:"approvedAt,omitempty"`
+	PlatformRejectionReason *string                     `json:"platformRejectionReason,omitempty"`
+	RejectionReason         *string                     `json:"rejectionReason,omitempty"`
+	DuplicateOfID           *string                     `json:"duplicateOfId,omitempty"`
+	ScheduledAt             *time.Time                  `json:"scheduledAt,omitempty"`
+	ThumbnailURL            *string                     `json:"thumbnailURL,omitempty"`
+	ThumbnailGenerated      bool                        `json:"thumbnailGenerated"`
+	AvailableTransitions    []AssetStatus               `json:"availableTransitions"`
+	DryRunReport            *DeploymentValidationReport `json:"dryRunReport,omitempty"`
+	QualityScore            *QualityScore               `json:"qualityScore,omitempty"`
+	CreatedAt               time.Time                   `json:"createdAt"`
+	UpdatedAt               time.Time                   `json:"updatedAt"`
 }
 
 func (Asset) IsBoardUpdate() {}
 
+func (Asset) IsAssetApprovalResult() {}
+
+type AssetDiff struct {
+	AssetID     string              `json:"assetId"`
+	FromVersion int                 `json:"fromVersion"`
+	ToVersion   int                 `json:"toVersion"`
+	Added       []*AssetFieldChange `json:"added"`
+	Removed     []*AssetFieldChange `json:"removed"`
+	Changed     []*AssetFieldChange `json:"changed"`
+}
+
+type AssetFieldChange struct {
+	Field    string  `json:"field"`
+	OldValue *string `json:"oldValue,omitempty"`
+	NewValue *string `json:"newValue,omitempty"`
+}
+
+type AssetQualityScore struct {
+	AssetID string        `json:"assetId"`
+	BoardID string        `json:"boardId"`
+	Score   *QualityScore `json:"score"`
+}
+
+type AssetVersion struct {
+	ID            string       `json:"id"`
+	AssetID       string       `json:"assetId"`
+	VersionNumber int          `json:"versionNumber"`
+	Name          string       `json:"name"`
+	URL           *string      `json:"url,omitempty"`
+	Status        *AssetStatus `json:"status,omitempty"`
+	ApprovedBy    *string      `json:"approvedBy,omitempty"`
+	ApprovedAt    *time.Time   `json:"approvedAt,omitempty"`
+	ChangedBy     *string      `json:"changedBy,omitempty"`
+	ChangedAt     time.Time    `json:"changedAt"`
+	ChangeReason  *string      `json:"changeReason,omitempty"`
+}
+
+type AuditEntry struct {
+	ID             string                 `json:"id"`
+	UserID         string                 `json:"userId"`
+	Operation      string                 `json:"operation"`
+	EntityType     string                 `json:"entityType"`
+	EntityID       string                 `json:"entityId"`
+	OldValue       map[string]interface{} `json:"oldValue,omitempty"`
+	NewValue       map[string]interface{} `json:"newValue,omitempty"`
+	IPAddress      string                 `json:"ipAddress"`
+	UserAgent      string                 `json:"userAgent"`
+	CreatedAt      time.Time              `json:"createdAt"`
+	ImpersonatedBy *string                `json:"impersonatedBy,omitempty"`
+}
+
 type Board struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description *string   `json:"description,omitempty"`
-	ProjectID   string    `json:"projectId"`
-	Project     *Project  `json:"project"`
-	Assets      []*Asset  `json:"assets"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description *string          `json:"description,omitempty"`
+	ProjectID   string           `json:"projectId"`
+	Project     *Project         `json:"project"`
+	Assets      *AssetConnection `json:"assets"`
+	CreatedAt   time.Time        `json:"createdAt"`
+	UpdatedAt   time.Time        `json:"updatedAt"`
+}
+
+type BoardTemplate struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	CreatedBy   *string          `json:"createdBy,omitempty"`
+	IsPublic    bool             `json:"isPublic"`
+	Preview     []*TemplateBoard `json:"preview"`
+	CreatedAt   time.Time        `json:"createdAt"`
+}
+
+type BoardWebhook struct {
+	ID        string           `json:"id"`
+	BoardID   string           `json:"boardId"`
+	URL       string           `json:"url"`
+	Events    []BoardEventType `json:"events"`
+	Active    bool             `json:"active"`
+	Secret    *string          `json:"secret,omitempty"`
+	CreatedAt time.Time        `json:"createdAt"`
+}
+
+type CampaignBudgetExceeded struct {
+	AssetID   string           `json:"assetId"`
+	ProjectID string           `json:"projectId"`
+	Platform  CampaignPlatform `json:"platform"`
+	Budget    float64          `json:"budget"`
+	Cap       float64          `json:"cap"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+type CampaignSchedule struct {
+	ID                 string           `json:"id"`
+	Platform           CampaignPlatform `json:"platform"`
+	PlatformCampaignID string           `json:"platformCampaignId"`
+	PauseCron          string           `json:"pauseCron"`
+	ResumeCron         string           `json:"resumeCron"`
+	Timezone           string           `json:"timezone"`
+	CreatedAt          time.Time        `json:"createdAt"`
 }
 
 type ChatMessage struct {
-	ID        string    `json:"id"`
-	Content   string    `json:"content"`
-	UserID    string    `json:"userId"`
-	User      *User     `json:"user"`
-	BoardID   string    `json:"boardId"`
-	Board     *Board    `json:"board"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID            string         `json:"id"`
+	Content       string         `json:"content"`
+	UserID        string         `json:"userId"`
+	User          *User          `json:"user"`
+	BoardID       string         `json:"boardId"`
+	Board         *Board         `json:"board"`
+	ParentID      *string        `json:"parentId,omitempty"`
+	Replies       []*ChatMessage `json:"replies"`
+	ReactionCount int            `json:"reactionCount"`
+	CreatedAt     time.Time      `json:"createdAt"`
 }
 
 func (ChatMessage) IsBoardUpdate() {}
@@ -58,24 +171,162 @@ type CreateBoardInput struct {
 	ProjectID   string  `json:"projectId"`
 }
 
+type CreateCampaignScheduleInput struct {
+	Platform           CampaignPlatform `json:"platform"`
+	PlatformCampaignID string           `json:"platformCampaignId"`
+	PauseCron          string           `json:"pauseCron"`
+	ResumeCron         string           `json:"resumeCron"`
+	Timezone           string           `json:"timezone"`
+}
+
+type CreateOrganizationInput struct {
+	Name string  `json:"name"`
+	Plan *string `json:"plan,omitempty"`
+}
+
 type CreateProjectInput struct {
 	Name        string  `json:"name"`
 	Description *string `json:"description,omitempty"`
+	OrgID       *string `json:"orgId,omitempty"`
+}
+
+type CreateWebhookInput struct {
+	ProjectID string         `json:"projectId"`
+	URL       string         `json:"url"`
+	Secret    string         `json:"secret"`
+	Events    []WebhookEvent `json:"events"`
+}
+
+type DailySpend struct {
+	Date  string  `json:"date"`
+	Spend float64 `json:"spend"`
+}
+
+type DateRange struct {
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+}
+
+type Deployment struct {
+	ID         string           `json:"id"`
+	AssetID    string           `json:"assetId"`
+	Asset      *Asset           `json:"asset"`
+	Platform   CampaignPlatform `json:"platform"`
+	Status     DeploymentStatus `json:"status"`
+	PlatformID *string          `json:"platformId,omitempty"`
+	Error      *string          `json:"error,omitempty"`
+	DeployedAt time.Time        `json:"deployedAt"`
+	DurationMs int              `json:"durationMs"`
+	RetryCount int              `json:"retryCount"`
+}
+
+type DeploymentConnection struct {
+	Edges    []*DeploymentEdge `json:"edges"`
+	PageInfo *PageInfo         `json:"pageInfo"`
+}
+
+type DeploymentEdge struct {
+	Cursor string      `json:"cursor"`
+	Node   *Deployment `json:"node"`
+}
+
+type DeploymentRollbackResult struct {
+	AssetID            string           `json:"assetId"`
+	Platform           CampaignPlatform `json:"platform"`
+	PlatformCampaignID string           `json:"platformCampaignId"`
+	PlatformAdID       string           `json:"platformAdId"`
+	RolledBackAt       time.Time        `json:"rolledBackAt"`
+	Reason             *string          `json:"reason,omitempty"`
+	Asset              *Asset           `json:"asset"`
+}
+
+type DeploymentValidationReport struct {
+	Valid     bool                        `json:"valid"`
+	Platforms []*PlatformValidationReport `json:"platforms"`
+}
+
+type KeywordQualityScore struct {
+	KeywordID string    `json:"keywordId"`
+	AdGroupID string    `json:"adGroupId"`
+	Keyword   string    `json:"keyword"`
+	Score     int       `json:"score"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+type LoginEvent struct {
+	ID            string    `json:"id"`
+	EventType     string    `json:"eventType"`
+	IPAddress     string    `json:"ipAddress"`
+	UserAgent     string    `json:"userAgent"`
+	Country       string    `json:"country"`
+	City          string    `json:"city"`
+	Success       bool      `json:"success"`
+	FailureReason *string   `json:"failureReason,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
 }
 
 type Mutation struct {
 }
 
+type Organization struct {
+	ID        string                `json:"id"`
+	Name      string                `json:"name"`
+	Plan      string                `json:"plan"`
+	Members   []*OrganizationMember `json:"members"`
+	CreatedAt time.Time             `json:"createdAt"`
+}
+
+type OrganizationMember struct {
+	OrgID     string           `json:"orgId"`
+	UserID    string           `json:"userId"`
+	Email     string           `json:"email"`
+	Role      OrganizationRole `json:"role"`
+	CreatedAt time.Time        `json:"createdAt"`
+}
+
+type Permissions struct {
+	ProjectID  string `json:"projectId"`
+	Role       *Role  `json:"role,omitempty"`
+	CanView    bool   `json:"canView"`
+	CanUpload  bool   `json:"canUpload"`
+	CanApprove bool   `json:"canApprove"`
+	CanDelete  bool   `json:"canDelete"`
+}
+
+type PlatformValidationReport struct {
+	Platform              CampaignPlatform `json:"platform"`
+	Valid                 bool             `json:"valid"`
+	CredentialsValid      bool             `json:"credentialsValid"`
+	CreativeComplete      bool             `json:"creativeComplete"`
+	BudgetWithinRange     bool             `json:"budgetWithinRange"`
+	TargetingValid        bool             `json:"targetingValid"`
+	EstimatedAudienceSize *int             `json:"estimatedAudienceSize,omitempty"`
+	EstimatedImpressions  *int             `json:"estimatedImpressions,omitempty"`
+	EstimatedClicks       *int             `json:"estimatedClicks,omitempty"`
+	EstimatedSpend        *float64         `json:"estimatedSpend,omitempty"`
+	Currency              *string          `json:"currency,omitempty"`
+	Errors                []string         `json:"errors"`
+	EstimateError         *string          `json:"estimateError,omitempty"`
+}
+
 type Project struct {
-	ID          string        `json:"id"`
-	Name        string        `json:"name"`
-	Description *string       `json:"description,omitempty"`
-	Status      ProjectStatus `json:"status"`
-	OwnerID     string        `json:"ownerId"`
-	Owner       *User         `json:"owner"`
-	Boards      []*Board      `json:"boards"`
-	CreatedAt   time.Time     `json:"createdAt"`
-	UpdatedAt   time.Time     `json:"updatedAt"`
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description *string          `json:"description,omitempty"`
+	Status      ProjectStatus    `json:"status"`
+	OwnerID     string           `json:"ownerId"`
+	Owner       *User            `json:"owner"`
+	Boards      *BoardConnection `json:"boards"`
+	SuccessRate float64          `json:"successRate"`
+	CreatedAt   time.Time        `json:"createdAt"`
+	UpdatedAt   time.Time        `json:"updatedAt"`
+}
+
+type QualityScore struct {
+	Readability    float64 `json:"readability"`
+	Sentiment      string  `json:"sentiment"`
+	KeywordDensity float64 `json:"keywordDensity"`
+	OverallScore   float64 `json:"overallScore"`
 }
 
 type Query struct {
@@ -84,11 +335,31 @@ type Query struct {
 type Subscription struct {
 }
 
+type TemplateBoard struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"`
+	TokenType    string `json:"tokenType"`
+}
+
+type UpdateWebhookInput struct {
+	URL    *string        `json:"url,omitempty"`
+	Secret *string        `json:"secret,omitempty"`
+	Events []WebhookEvent `json:"events,omitempty"`
+	Active *bool          `json:"active,omitempty"`
+}
+
 type UploadAssetInput struct {
-	Name    string    `json:"name"`
-	Type    AssetType `json:"type"`
-	URL     string    `json:"url"`
-	BoardID string    `json:"boardId"`
+	Name         string    `json:"name"`
+	Type         AssetType `json:"type"`
+	URL          string    `json:"url"`
+	BoardID      string    `json:"boardId"`
+	VariantGroup *string   `json:"variantGroup,omitempty"`
 }
 
 type User struct {
@@ -100,6 +371,15 @@ type User struct {
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+type Webhook struct {
+	ID        string         `json:"id"`
+	ProjectID string         `json:"projectId"`
+	URL       string         `json:"url"`
+	Events    []WebhookEvent `json:"events"`
+	Active    bool           `json:"active"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
 type AssetStatus string
 
 const (
@@ -107,6 +387,11 @@ const (
 	AssetStatusApproved         AssetStatus = "APPROVED"
 	AssetStatusRejected         AssetStatus = "REJECTED"
 	AssetStatusRevisionRequired AssetStatus = "REVISION_REQUIRED"
+	AssetStatusDraft            AssetStatus = "DRAFT"
+	AssetStatusReview           AssetStatus = "REVIEW"
+	AssetStatusDeployed         AssetStatus = "DEPLOYED"
+	AssetStatusFailed           AssetStatus = "FAILED"
+	AssetStatusRolledBack       AssetStatus = "ROLLED_BACK"
 )
 
 var AllAssetStatus = []AssetStatus{
@@ -114,11 +399,16 @@ var AllAssetStatus = []AssetStatus{
 	AssetStatusApproved,
 	AssetStatusRejected,
 	AssetStatusRevisionRequired,
+	AssetStatusDraft,
+	AssetStatusReview,
+	AssetStatusDeployed,
+	AssetStatusFailed,
+	AssetStatusRolledBack,
 }
 
 func (e AssetStatus) IsValid() bool {
 	switch e {
-	case AssetStatusPending, AssetStatusApproved, AssetStatusRejected, AssetStatusRevisionRequired:
+	case AssetStatusPending, AssetStatusApproved, AssetStatusRejected, AssetStatusRevisionRequired, AssetStatusDraft, AssetStatusReview, AssetStatusDeployed, AssetStatusFailed, AssetStatusRolledBack:
 		return true
 	}
 	return false
@@ -192,6 +482,141 @@ func (e AssetType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type BoardEventType string
+
+const (
+	BoardEventTypeAssetUploaded BoardEventType = "ASSET_UPLOADED"
+	BoardEventTypeAssetApproved BoardEventType = "ASSET_APPROVED"
+	BoardEventTypeAssetDeployed BoardEventType = "ASSET_DEPLOYED"
+	BoardEventTypeMessageSent   BoardEventType = "MESSAGE_SENT"
+)
+
+var AllBoardEventType = []BoardEventType{
+	BoardEventTypeAssetUploaded,
+	BoardEventTypeAssetApproved,
+	BoardEventTypeAssetDeployed,
+	BoardEventTypeMessageSent,
+}
+
+func (e BoardEventType) IsValid() bool {
+	switch e {
+	case BoardEventTypeAssetUploaded, BoardEventTypeAssetApproved, BoardEventTypeAssetDeployed, BoardEventTypeMessageSent:
+		return true
+	}
+	return false
+}
+
+func (e BoardEventType) String() string {
+	return string(e)
+}
+
+func (e *BoardEventType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = BoardEventType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid BoardEventType", str)
+	}
+	return nil
+}
+
+func (e BoardEventType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type DeploymentStatus string
+
+const (
+	DeploymentStatusPending   DeploymentStatus = "PENDING"
+	DeploymentStatusRunning   DeploymentStatus = "RUNNING"
+	DeploymentStatusSuccess   DeploymentStatus = "SUCCESS"
+	DeploymentStatusFailed    DeploymentStatus = "FAILED"
+	DeploymentStatusCancelled DeploymentStatus = "CANCELLED"
+)
+
+var AllDeploymentStatus = []DeploymentStatus{
+	DeploymentStatusPending,
+	DeploymentStatusRunning,
+	DeploymentStatusSuccess,
+	DeploymentStatusFailed,
+	DeploymentStatusCancelled,
+}
+
+func (e DeploymentStatus) IsValid() bool {
+	switch e {
+	case DeploymentStatusPending, DeploymentStatusRunning, DeploymentStatusSuccess, DeploymentStatusFailed, DeploymentStatusCancelled:
+		return true
+	}
+	return false
+}
+
+func (e DeploymentStatus) String() string {
+	return string(e)
+}
+
+func (e *DeploymentStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DeploymentStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DeploymentStatus", str)
+	}
+	return nil
+}
+
+func (e DeploymentStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type OrganizationRole string
+
+const (
+	OrganizationRoleOwner  OrganizationRole = "OWNER"
+	OrganizationRoleAdmin  OrganizationRole = "ADMIN"
+	OrganizationRoleMember OrganizationRole = "MEMBER"
+)
+
+var AllOrganizationRole = []OrganizationRole{
+	OrganizationRoleOwner,
+	OrganizationRoleAdmin,
+	OrganizationRoleMember,
+}
+
+func (e OrganizationRole) IsValid() bool {
+	switch e {
+	case OrganizationRoleOwner, OrganizationRoleAdmin, OrganizationRoleMember:
+		return true
+	}
+	return false
+}
+
+func (e OrganizationRole) String() string {
+	return string(e)
+}
+
+func (e *OrganizationRole) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OrganizationRole(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OrganizationRole", str)
+	}
+	return nil
+}
+
+func (e OrganizationRole) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type ProjectStatus string
 
 const (
@@ -234,3 +659,87 @@ func (e *ProjectStatus) UnmarshalGQL(v interface{}) error {
 func (e ProjectStatus) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
+
+type Role string
+
+const (
+	RoleViewer Role = "VIEWER"
+	RoleEditor Role = "EDITOR"
+	RoleAdmin  Role = "ADMIN"
+)
+
+var AllRole = []Role{
+	RoleViewer,
+	RoleEditor,
+	RoleAdmin,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleViewer, RoleEditor, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type WebhookEvent string
+
+const (
+	WebhookEventAssetDeployed WebhookEvent = "ASSET_DEPLOYED"
+	WebhookEventAssetFailed   WebhookEvent = "ASSET_FAILED"
+)
+
+var AllWebhookEvent = []WebhookEvent{
+	WebhookEventAssetDeployed,
+	WebhookEventAssetFailed,
+}
+
+func (e WebhookEvent) IsValid() bool {
+	switch e {
+	case WebhookEventAssetDeployed, WebhookEventAssetFailed:
+		return true
+	}
+	return false
+}
+
+func (e WebhookEvent) String() string {
+	return string(e)
+}
+
+func (e *WebhookEvent) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebhookEvent(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebhookEvent", str)
+	}
+	return nil
+}
+
+func (e WebhookEvent) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
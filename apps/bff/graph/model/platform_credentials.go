@@ -0,0 +1,16 @@
+package model
+
+// PlatformCredentialsInput holds the credentials a tenant uses for one advertising
+// platform. Only the fields relevant to the platform are required.
+type PlatformCredentialsInput struct {
+	DeveloperToken  *string `json:"developerToken,omitempty"`
+	ClientID        *string `json:"clientId,omitempty"`
+	ClientSecret    *string `json:"clientSecret,omitempty"`
+	RefreshToken    *string `json:"refreshToken,omitempty"`
+	CustomerID      *string `json:"customerId,omitempty"`
+	LoginCustomerID *string `json:"loginCustomerId,omitempty"`
+	AppID           *string `json:"appId,omitempty"`
+	AppSecret       *string `json:"appSecret,omitempty"`
+	AccessToken     *string `json:"accessToken,omitempty"`
+	AdAccountID     *string `json:"adAccountId,omitempty"`
+}
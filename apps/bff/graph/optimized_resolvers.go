@@ -4,26 +4,26 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
 )
 
-// OptimizedResolver provides performance-optimized resolver implementations
+// OptimizedResolver provides performance-optimized resolver implementations.
+// Batched loading of related entities lives in Loaders.
 type OptimizedResolver struct {
 	*Resolver
 	cache      *ResolverCache
-	batcher    *DataBatcher
 	metrics    *PerformanceMetrics
 }
 
-// NewOptimizedResolver creates a new optimized resolver with caching and batching
+// NewOptimizedResolver creates a new optimized resolver with caching
 func NewOptimizedResolver(base *Resolver) *OptimizedResolver {
 	return &OptimizedResolver{
 		Resolver: base,
 		cache:    NewResolverCache(),
-		batcher:  NewDataBatcher(base.DB),
 		metrics:  NewPerformanceMetrics(),
 	}
 }
@@ -34,7 +34,10 @@ type ResolverCache struct {
 	projects  map[string]*model.Project
 	boards    map[string]*model.Board
 	assets    map[string]*model.Asset
-	boardAssets map[string][]*model.Asset
+	boardAssets map[string]map[connectionPage][]*model.Asset
+	preferences map[string]map[string]interface{}
+	searches  map[string]searchResult
+	rois      map[string]roiResult
 	mutex     sync.RWMutex
 	ttl       time.Duration
 	lastClean time.Time
@@ -47,7 +50,10 @@ func NewResolverCache() *ResolverCache {
 		projects:    make(map[string]*model.Project),
 		boards:      make(map[string]*model.Board),
 		assets:      make(map[string]*model.Asset),
-		boardAssets: make(map[string][]*model.Asset),
+		boardAssets: make(map[string]map[connectionPage][]*model.Asset),
+		preferences: make(map[string]map[string]interface{}),
+		searches:    make(map[string]searchResult),
+		rois:        make(map[string]roiResult),
 		ttl:         time.Minute * 5, // 5 minute TTL
 		lastClean:   time.Now(),
 	}
@@ -80,7 +86,10 @@ func (c *ResolverCache) cleanup() {
 	c.projects = make(map[string]*model.Project)
 	c.boards = make(map[string]*model.Board)
 	c.assets = make(map[string]*model.Asset)
-	c.boardAssets = make(map[string][]*model.Asset)
+	c.boardAssets = make(map[string]map[connectionPage][]*model.Asset)
+	c.preferences = make(map[string]map[string]interface{})
+	c.searches = make(map[string]searchResult)
+	c.rois = make(map[string]roiResult)
 	c.lastClean = time.Now()
 }
 
@@ -88,6 +97,7 @@ func (c *ResolverCache) GetUser(id string) (*model.User, bool) {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 	user, exists := c.users[id]
+	recordCacheLookup("users", exists)
 	return user, exists
 }
 
@@ -101,6 +111,7 @@ func (c *ResolverCache) GetProject(id string) (*model.Project, bool) {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 	project, exists := c.projects[id]
+	recordCacheLookup("projects", exists)
 	return project, exists
 }
 
@@ -114,6 +125,7 @@ func (c *ResolverCache) GetBoard(id string) (*model.Board, bool) {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 	board, exists := c.boards[id]
+	recordCacheLookup("boards", exists)
 	return board, exists
 }
 
@@ -127,6 +139,7 @@ func (c *ResolverCache) GetAsset(id string) (*model.Asset, bool) {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 	asset, exists := c.assets[id]
+	recordCacheLookup("assets", exists)
 	return asset, exists
 }
 
@@ -136,88 +149,194 @@ func (c *ResolverCache) SetAsset(id string, asset *model.Asset) {
 	c.assets[id] = asset
 }
 
-func (c *ResolverCache) GetBoardAssets(boardID string) ([]*model.Asset, bool) {
+// GetBoardAssets returns the cached rows of a page of the assets of a board
+func (c *ResolverCache) GetBoardAssets(boardID string, page connectionPage) ([]*model.Asset, bool) {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
-	assets, exists := c.boardAssets[boardID]
+	assets, exists := c.boardAssets[boardID][page]
+	recordCacheLookup("board_assets", exists)
 	return assets, exists
 }
 
-func (c *ResolverCache) SetBoardAssets(boardID string, assets []*model.Asset) {
+// SetBoardAssets caches the rows of a page of the assets of a board. The pages
+// of a board are invalidated together.
+func (c *ResolverCache) SetBoardAssets(boardID string, page connectionPage, assets []*model.Asset) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	c.boardAssets[boardID] = assets
+	if c.boardAssets[boardID] == nil {
+		c.boardAssets[boardID] = make(map[connectionPage][]*model.Asset)
+	}
+	c.boardAssets[boardID][page] = assets
 }
 
-func (c *ResolverCache) InvalidateBoard(boardID string) {
+func (c *ResolverCache) GetPreferences(userID string) (map[string]interface{}, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	preferences, exists := c.preferences[preferencesKey(userID)]
+	recordCacheLookup("preferences", exists)
+	return preferences, exists
+}
+
+func (c *ResolverCache) SetPreferences(userID string, preferences map[string]interface{}) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	delete(c.boards, boardID)
-	delete(c.boardAssets, boardID)
+	c.preferences[preferencesKey(userID)] = preferences
 }
 
-func (c *ResolverCache) InvalidateAsset(assetID string) {
+func (c *ResolverCache) InvalidatePreferences(userID string) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	delete(c.assets, assetID)
-	// Also invalidate board assets cache for the asset's board
-	// This is a simplified approach - in production you'd want more granular invalidation
-	c.boardAssets = make(map[string][]*model.Asset)
+	delete(c.preferences, preferencesKey(userID))
 }
 
-// DataBatcher provides batching for database queries to reduce N+1 problems
-type DataBatcher struct {
-	db           interface{} // Database interface
-	userBatch    map[string]chan *model.User
-	projectBatch map[string]chan *model.Project
-	boardBatch   map[string]chan *model.Board
-	assetBatch   map[string]chan *model.Asset
-	mutex        sync.Mutex
+func preferencesKey(userID string) string {
+	return "preferences:" + userID
 }
 
-// NewDataBatcher creates a new data batcher
-func NewDataBatcher(db interface{}) *DataBatcher {
-	return &DataBatcher{
-		db:           db,
-		userBatch:    make(map[string]chan *model.User),
-		projectBatch: make(map[string]chan *model.Project),
-		boardBatch:   make(map[string]chan *model.Board),
-		assetBatch:   make(map[string]chan *model.Asset),
+// searchTTL is how long search results are cached. Results are not invalidated
+// by mutations, so they may be stale for that long.
+const searchTTL = 30 * time.Second
+
+// searchResult holds the assets or boards found by a search
+type searchResult struct {
+	assets    []*model.Asset
+	boards    []*model.Board
+	expiresAt time.Time
+}
+
+// GetSearchAssets returns the cached assets found by a search
+func (c *ResolverCache) GetSearchAssets(key string) ([]*model.Asset, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	result, exists := c.searches[key]
+	exists = exists && result.assets != nil && time.Now().Before(result.expiresAt)
+	recordCacheLookup("searches", exists)
+	return result.assets, exists
+}
+
+// SetSearchAssets caches the assets found by a search for searchTTL
+func (c *ResolverCache) SetSearchAssets(key string, assets []*model.Asset) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.searches == nil {
+		c.searches = make(map[string]searchResult)
 	}
+	c.searches[key] = searchResult{assets: assets, expiresAt: time.Now().Add(searchTTL)}
 }
 
-// BatchLoadUsers loads multiple users in a single query
-func (b *DataBatcher) BatchLoadUsers(ctx context.Context, userIDs []string) (map[string]*model.User, error) {
-	// Implementation would batch load users from database
-	// This is a simplified version for demonstration
-	users := make(map[string]*model.User)
-	
-	// Simulate batched database query
-	for _, id := range userIDs {
-		users[id] = &model.User{
-			ID:    id,
-			Email: fmt.Sprintf("user-%s@example.com", id),
+// GetSearchBoards returns the cached boards found by a search
+func (c *ResolverCache) GetSearchBoards(key string) ([]*model.Board, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	result, exists := c.searches[key]
+	exists = exists && result.boards != nil && time.Now().Before(result.expiresAt)
+	recordCacheLookup("searches", exists)
+	return result.boards, exists
+}
+
+// SetSearchBoards caches the boards found by a search for searchTTL
+func (c *ResolverCache) SetSearchBoards(key string, boards []*model.Board) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.searches == nil {
+		c.searches = make(map[string]searchResult)
+	}
+	c.searches[key] = searchResult{boards: boards, expiresAt: time.Now().Add(searchTTL)}
+}
+
+// roiTTL is how long the return on ad spend of a project is cached unless new
+// campaign metrics invalidate it
+const roiTTL = 5 * time.Minute
+
+// roiResult holds the return on ad spend of a project over a date range
+type roiResult struct {
+	roi       *model.ProjectROI
+	expiresAt time.Time
+}
+
+// GetProjectROI returns the cached return on ad spend of a project over a date
+// range
+func (c *ResolverCache) GetProjectROI(projectID string, dateRange model.DateRange) (*model.ProjectROI, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	result, exists := c.rois[roiCacheKey(projectID, dateRange)]
+	exists = exists && time.Now().Before(result.expiresAt)
+	recordCacheLookup("rois", exists)
+	return result.roi, exists
+}
+
+// SetProjectROI caches the return on ad spend of a project over a date range for
+// roiTTL
+func (c *ResolverCache) SetProjectROI(projectID string, dateRange model.DateRange, roi *model.ProjectROI) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.rois == nil {
+		c.rois = make(map[string]roiResult)
+	}
+	c.rois[roiCacheKey(projectID, dateRange)] = roiResult{roi: roi, expiresAt: time.Now().Add(roiTTL)}
+}
+
+// InvalidateProjectROI drops the return on ad spend of a project over every date
+// range
+func (c *ResolverCache) InvalidateProjectROI(projectID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	prefix := "roi:" + projectID + ":"
+	for key := range c.rois {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.rois, key)
 		}
 	}
-	
-	return users, nil
 }
 
-// BatchLoadAssets loads multiple assets in a single query
-func (b *DataBatcher) BatchLoadAssets(ctx context.Context, assetIDs []string) (map[string]*model.Asset, error) {
-	assets := make(map[string]*model.Asset)
-	
-	// Simulate batched database query
-	for _, id := range assetIDs {
-		assets[id] = &model.Asset{
-			ID:     id,
-			Name:   fmt.Sprintf("Asset %s", id),
-			Type:   model.AssetTypeImage,
-			Status: model.AssetStatusPending,
+func roiCacheKey(projectID string, dateRange model.DateRange) string {
+	return fmt.Sprintf("roi:%s:%s/%s", projectID, dateRange.StartDate, dateRange.EndDate)
+}
+
+// InvalidateBoard drops a board with its assets, which are deleted with it
+func (c *ResolverCache) InvalidateBoard(boardID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.invalidateBoard(boardID)
+}
+
+func (c *ResolverCache) invalidateBoard(boardID string) {
+	delete(c.boards, boardID)
+	delete(c.boardAssets, boardID)
+	for id, asset := range c.assets {
+		if asset.BoardID == boardID {
+			delete(c.assets, id)
 		}
 	}
-	
-	return assets, nil
+}
+
+// InvalidateProject drops a project with its boards and their assets, which are
+// deleted with it. Boards of the project that are not cached are unknown, so the
+// assets of all boards are dropped.
+func (c *ResolverCache) InvalidateProject(projectID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.projects, projectID)
+	for id, board := range c.boards {
+		if board.ProjectID == projectID {
+			delete(c.boards, id)
+		}
+	}
+	c.assets = make(map[string]*model.Asset)
+	c.boardAssets = make(map[string]map[connectionPage][]*model.Asset)
+}
+
+func (c *ResolverCache) InvalidateAsset(assetID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	// Every page of the asset's board may hold it. When the asset is not cached
+	// its board is unknown, so the pages of all boards are dropped.
+	if asset, exists := c.assets[assetID]; exists {
+		delete(c.boardAssets, asset.BoardID)
+	} else {
+		c.boardAssets = make(map[string]map[connectionPage][]*model.Asset)
+	}
+	delete(c.assets, assetID)
 }
 
 // PerformanceMetrics tracks resolver performance
@@ -292,25 +411,31 @@ func (m *PerformanceMetrics) GetStats() map[string]interface{} {
 
 // Optimized resolver implementations
 
-// OptimizedBoardAssets provides optimized asset loading for boards
-func (r *OptimizedResolver) OptimizedBoardAssets(ctx context.Context, obj *model.Board) ([]*model.Asset, error) {
+// OptimizedBoardAssets provides optimized asset loading for a page of the
+// assets of a board
+func (r *OptimizedResolver) OptimizedBoardAssets(ctx context.Context, obj *model.Board, page connectionPage) (*model.AssetConnection, error) {
 	start := time.Now()
 	defer func() {
 		r.metrics.RecordQuery("board_assets", time.Since(start))
 	}()
 
 	// Check cache first
-	if assets, exists := r.cache.GetBoardAssets(obj.ID); exists {
-		return assets, nil
+	if assets, exists := r.cache.GetBoardAssets(obj.ID, page); exists {
+		return assetConnection(page, assets), nil
 	}
 
 	// Load from database
-	rows, err := r.DB.Query(`
-		SELECT id, name, type, url, status, board_id, approved_by, approved_at, created_at, updated_at
-		FROM assets WHERE board_id = $1
-		ORDER BY created_at DESC
-	`, obj.ID)
+	query := `
+		SELECT id, name, type, url, status, board_id, approved_by, approved_at, platform_rejection_reason, rejection_reason, duplicate_of, scheduled_at, thumbnail_url, created_at, updated_at
+		FROM assets WHERE board_id = $1 AND deleted_at IS NULL`
+	filter, args := page.filter([]interface{}{obj.ID})
+	if filter != "" {
+		query += " AND " + filter
+	}
+	args = append(args, page.fetchLimit())
+	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d", page.order(), len(args))
 
+	rows, err := r.reader(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		r.metrics.RecordError("board_assets")
 		return nil, fmt.Errorf("failed to query assets: %w", err)
@@ -322,8 +447,8 @@ func (r *OptimizedResolver) OptimizedBoardAssets(ctx context.Context, obj *model
 		var asset model.Asset
 		err := rows.Scan(
 			&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
-			&asset.BoardID, &asset.ApprovedBy, &asset.ApprovedAt,
-			&asset.CreatedAt, &asset.UpdatedAt,
+			&asset.BoardID, &asset.ApprovedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+			&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt,
 		)
 		if err != nil {
 			r.metrics.RecordError("board_assets")
@@ -336,9 +461,9 @@ func (r *OptimizedResolver) OptimizedBoardAssets(ctx context.Context, obj *model
 	}
 
 	// Cache the result
-	r.cache.SetBoardAssets(obj.ID, assets)
+	r.cache.SetBoardAssets(obj.ID, page, assets)
 
-	return assets, nil
+	return assetConnection(page, assets), nil
 }
 
 // OptimizedAssetBoard provides optimized board loading for assets
@@ -354,9 +479,9 @@ func (r *OptimizedResolver) OptimizedAssetBoard(ctx context.Context, obj *model.
 	}
 
 	var board model.Board
-	err := r.DB.QueryRow(`
+	err := r.reader(ctx).QueryRowContext(ctx, `
 		SELECT id, name, description, project_id, created_at, updated_at
-		FROM boards WHERE id = $1
+		FROM boards WHERE id = $1 AND deleted_at IS NULL
 	`, obj.BoardID).Scan(
 		&board.ID, &board.Name, &board.Description, &board.ProjectID,
 		&board.CreatedAt, &board.UpdatedAt,
@@ -386,7 +511,7 @@ func (r *OptimizedResolver) OptimizedUserLoader(ctx context.Context, userID stri
 	}
 
 	var user model.User
-	err := r.DB.QueryRow(`
+	err := r.reader(ctx).QueryRowContext(ctx, `
 		SELECT id, email, name, avatar, created_at, updated_at
 		FROM users WHERE id = $1
 	`, userID).Scan(
@@ -405,19 +530,25 @@ func (r *OptimizedResolver) OptimizedUserLoader(ctx context.Context, userID stri
 	return &user, nil
 }
 
-// OptimizedProjectBoards provides optimized board loading for projects
-func (r *OptimizedResolver) OptimizedProjectBoards(ctx context.Context, obj *model.Project) ([]*model.Board, error) {
+// OptimizedProjectBoards provides optimized board loading for a page of the
+// boards of a project
+func (r *OptimizedResolver) OptimizedProjectBoards(ctx context.Context, obj *model.Project, page connectionPage) (*model.BoardConnection, error) {
 	start := time.Now()
 	defer func() {
 		r.metrics.RecordQuery("project_boards", time.Since(start))
 	}()
 
-	rows, err := r.DB.Query(`
+	query := `
 		SELECT id, name, description, project_id, created_at, updated_at
-		FROM boards WHERE project_id = $1
-		ORDER BY created_at DESC
-	`, obj.ID)
+		FROM boards WHERE project_id = $1 AND deleted_at IS NULL`
+	filter, args := page.filter([]interface{}{obj.ID})
+	if filter != "" {
+		query += " AND " + filter
+	}
+	args = append(args, page.fetchLimit())
+	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d", page.order(), len(args))
 
+	rows, err := r.reader(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		r.metrics.RecordError("project_boards")
 		return nil, fmt.Errorf("failed to query boards: %w", err)
@@ -441,7 +572,7 @@ func (r *OptimizedResolver) OptimizedProjectBoards(ctx context.Context, obj *mod
 		r.cache.SetBoard(board.ID, &board)
 	}
 
-	return boards, nil
+	return boardConnection(page, boards), nil
 }
 
 // LiveAnalyticsResolver provides optimized live analytics data
@@ -477,21 +608,22 @@ type AnalyticsData struct {
 	LastUpdated   time.Time `json:"lastUpdated"`
 }
 
-// InvalidateCache provides cache invalidation for mutations
-func (r *OptimizedResolver) InvalidateCache(ctx context.Context, entityType string, entityID string) {
-	switch entityType {
-	case "board":
-		r.cache.InvalidateBoard(entityID)
-	case "asset":
-		r.cache.InvalidateAsset(entityID)
-	case "user":
-		r.cache.mutex.Lock()
-		delete(r.cache.users, entityID)
-		r.cache.mutex.Unlock()
-	case "project":
-		r.cache.mutex.Lock()
-		delete(r.cache.projects, entityID)
-		r.cache.mutex.Unlock()
+// InvalidateCache provides cache invalidation for mutations, dropping the cached
+// entities of entityType with the given IDs
+func (r *OptimizedResolver) InvalidateCache(ctx context.Context, entityType string, entityIDs []string) {
+	for _, entityID := range entityIDs {
+		switch entityType {
+		case "board":
+			r.cache.InvalidateBoard(entityID)
+		case "asset":
+			r.cache.InvalidateAsset(entityID)
+		case "user":
+			r.cache.mutex.Lock()
+			delete(r.cache.users, entityID)
+			r.cache.mutex.Unlock()
+		case "project":
+			r.cache.InvalidateProject(entityID)
+		}
 	}
 }
 
@@ -0,0 +1,143 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+func TestResolverCache_BoardAssetPages(t *testing.T) {
+	cache := &ResolverCache{
+		assets:      make(map[string]*model.Asset),
+		boardAssets: make(map[string]map[connectionPage][]*model.Asset),
+	}
+
+	first, err := newConnectionPage(2, nil, 0, nil)
+	require.NoError(t, err)
+	last, err := newConnectionPage(0, nil, 2, nil)
+	require.NoError(t, err)
+
+	asset := &model.Asset{ID: "asset-1", BoardID: "board-1"}
+	cache.SetAsset(asset.ID, asset)
+	cache.SetBoardAssets("board-1", first, []*model.Asset{asset})
+	cache.SetBoardAssets("board-1", last, []*model.Asset{asset})
+	cache.SetBoardAssets("board-2", first, []*model.Asset{})
+
+	// Pages are cached separately
+	_, exists := cache.GetBoardAssets("board-1", first)
+	assert.True(t, exists)
+	other, err := newConnectionPage(3, nil, 0, nil)
+	require.NoError(t, err)
+	_, exists = cache.GetBoardAssets("board-1", other)
+	assert.False(t, exists)
+
+	// Invalidating a cached asset drops every page of its board only
+	cache.InvalidateAsset(asset.ID)
+	_, exists = cache.GetBoardAssets("board-1", first)
+	assert.False(t, exists)
+	_, exists = cache.GetBoardAssets("board-1", last)
+	assert.False(t, exists)
+	_, exists = cache.GetBoardAssets("board-2", first)
+	assert.True(t, exists)
+
+	// The board of an uncached asset is unknown, so every page is dropped
+	cache.InvalidateAsset("asset-2")
+	_, exists = cache.GetBoardAssets("board-2", first)
+	assert.False(t, exists)
+
+	cache.SetBoardAssets("board-2", first, []*model.Asset{})
+	cache.InvalidateBoard("board-2")
+	_, exists = cache.GetBoardAssets("board-2", first)
+	assert.False(t, exists)
+}
+
+func TestResolverCache_InvalidateProject(t *testing.T) {
+	cache := &ResolverCache{
+		projects:    make(map[string]*model.Project),
+		boards:      make(map[string]*model.Board),
+		assets:      make(map[string]*model.Asset),
+		boardAssets: make(map[string]map[connectionPage][]*model.Asset),
+	}
+
+	first, err := newConnectionPage(2, nil, 0, nil)
+	require.NoError(t, err)
+
+	cache.SetProject("project-1", &model.Project{ID: "project-1"})
+	cache.SetProject("project-2", &model.Project{ID: "project-2"})
+	cache.SetBoard("board-1", &model.Board{ID: "board-1", ProjectID: "project-1"})
+	cache.SetBoard("board-2", &model.Board{ID: "board-2", ProjectID: "project-2"})
+	cache.SetAsset("asset-1", &model.Asset{ID: "asset-1", BoardID: "board-1"})
+	cache.SetBoardAssets("board-1", first, []*model.Asset{})
+
+	// Deleting a project removes its boards and the assets cached for them
+	cache.InvalidateProject("project-1")
+	_, exists := cache.GetProject("project-1")
+	assert.False(t, exists)
+	_, exists = cache.GetBoard("board-1")
+	assert.False(t, exists)
+	_, exists = cache.GetAsset("asset-1")
+	assert.False(t, exists)
+	_, exists = cache.GetBoardAssets("board-1", first)
+	assert.False(t, exists)
+
+	_, exists = cache.GetProject("project-2")
+	assert.True(t, exists)
+	_, exists = cache.GetBoard("board-2")
+	assert.True(t, exists)
+}
+
+func TestResolverCache_Searches(t *testing.T) {
+	cache := &ResolverCache{}
+
+	_, exists := cache.GetSearchAssets("search:banner:0")
+	assert.False(t, exists)
+
+	cache.SetSearchAssets("search:banner:0", []*model.Asset{})
+	assets, exists := cache.GetSearchAssets("search:banner:0")
+	assert.True(t, exists)
+	assert.Empty(t, assets)
+
+	// Assets and boards found by searches are told apart
+	_, exists = cache.GetSearchBoards("search:banner:0")
+	assert.False(t, exists)
+
+	// Results expire after searchTTL
+	cache.searches["search:banner:0"] = searchResult{assets: []*model.Asset{}, expiresAt: time.Now().Add(-time.Second)}
+	_, exists = cache.GetSearchAssets("search:banner:0")
+	assert.False(t, exists)
+}
+
+func TestResolverCache_ProjectROI(t *testing.T) {
+	cache := &ResolverCache{}
+	march := model.DateRange{StartDate: "2024-03-01", EndDate: "2024-03-31"}
+	april := model.DateRange{StartDate: "2024-04-01", EndDate: "2024-04-30"}
+
+	_, exists := cache.GetProjectROI("project-1", march)
+	assert.False(t, exists)
+
+	cache.SetProjectROI("project-1", march, &model.ProjectROI{ProjectID: "project-1", ROAS: 2})
+	cache.SetProjectROI("project-1", april, &model.ProjectROI{ProjectID: "project-1", ROAS: 3})
+	cache.SetProjectROI("project-2", march, &model.ProjectROI{ProjectID: "project-2", ROAS: 4})
+
+	roi, exists := cache.GetProjectROI("project-1", march)
+	require.True(t, exists)
+	assert.Equal(t, 2.0, roi.ROAS)
+
+	// New metrics drop every date range of their project only
+	cache.InvalidateProjectROI("project-1")
+	_, exists = cache.GetProjectROI("project-1", march)
+	assert.False(t, exists)
+	_, exists = cache.GetProjectROI("project-1", april)
+	assert.False(t, exists)
+	_, exists = cache.GetProjectROI("project-2", march)
+	assert.True(t, exists)
+
+	// ROIs expire after roiTTL
+	cache.rois[roiCacheKey("project-2", march)] = roiResult{roi: roi, expiresAt: time.Now().Add(-time.Second)}
+	_, exists = cache.GetProjectROI("project-2", march)
+	assert.False(t, exists)
+}
@@ -0,0 +1,105 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+// defaultOrganizationPlan is the plan of organizations created without one
+const defaultOrganizationPlan = "free"
+
+// organizationMemberColumns are the columns read by scanOrganizationMember, from
+// organization_members m joined with users u
+const organizationMemberColumns = `m.org_id, m.user_id, u.email, m.role, m.created_at`
+
+// storedOrganizationRole returns the role stored with organization members for
+// a GraphQL organization role
+func storedOrganizationRole(role model.OrganizationRole) string {
+	return strings.ToLower(string(role))
+}
+
+// scanOrganizationMember scans a member selected as organizationMemberColumns
+func scanOrganizationMember(row rowScanner) (*model.OrganizationMember, error) {
+	var member model.OrganizationMember
+	var role string
+	if err := row.Scan(&member.OrgID, &member.UserID, &member.Email, &role, &member.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	member.Role = model.OrganizationRole(strings.ToUpper(role))
+	return &member, nil
+}
+
+// lockOrganizationRole locks the organization orgID for the rest of tx, so that
+// changes to its members are made one at a time, and returns the role of userID
+// in it. The organization is not found by users who are not members.
+func lockOrganizationRole(ctx context.Context, tx *sql.Tx, orgID, userID string) (model.OrganizationRole, error) {
+	var role string
+	err := tx.QueryRowContext(ctx, `
+		SELECT m.role
+		FROM organizations o
+		JOIN organization_members m ON m.org_id = o.id AND m.user_id = $2
+		WHERE o.id = $1
+		FOR UPDATE OF o
+	`, orgID, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("organization not found")
+	} else if err != nil {
+		return "", fmt.Errorf("failed to query organization: %w", err)
+	}
+
+	return model.OrganizationRole(strings.ToUpper(role)), nil
+}
+
+// authorizeMemberChange checks that a member with callerRole may change the role
+// of a member from one role to another. from is empty for users being added and
+// to is empty for members being removed.
+func authorizeMemberChange(callerRole, from, to model.OrganizationRole) error {
+	if callerRole != model.OrganizationRoleOwner && callerRole != model.OrganizationRoleAdmin {
+		return forbidden("managing members requires the owner or admin role")
+	}
+	if callerRole != model.OrganizationRoleOwner && (from == model.OrganizationRoleOwner || to == model.OrganizationRoleOwner) {
+		return forbidden("only owners may add, remove or change owners")
+	}
+	return nil
+}
+
+// checkOwnerRemains fails when a change of role from one role to another, or the
+// removal of a member when to is empty, would leave orgID without an owner
+func checkOwnerRemains(ctx context.Context, tx *sql.Tx, orgID string, from, to model.OrganizationRole) error {
+	if from != model.OrganizationRoleOwner || to == model.OrganizationRoleOwner {
+		return nil
+	}
+
+	var owners int
+	err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM organization_members WHERE org_id = $1 AND role = $2
+	`, orgID, storedOrganizationRole(model.OrganizationRoleOwner)).Scan(&owners)
+	if err != nil {
+		return fmt.Errorf("failed to count organization owners: %w", err)
+	}
+	if owners <= 1 {
+		return fmt.Errorf("organization must keep an owner")
+	}
+	return nil
+}
+
+// memberRole returns the role of userID in orgID, or an error when they are not
+// a member
+func memberRole(ctx context.Context, tx *sql.Tx, orgID, userID string) (model.OrganizationRole, error) {
+	var role string
+	err := tx.QueryRowContext(ctx, `
+		SELECT role FROM organization_members WHERE org_id = $1 AND user_id = $2
+	`, orgID, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("member not found")
+	} else if err != nil {
+		return "", fmt.Errorf("failed to query member: %w", err)
+	}
+
+	return model.OrganizationRole(strings.ToUpper(role)), nil
+}
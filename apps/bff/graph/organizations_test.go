@@ -0,0 +1,57 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+func TestAuthorizeMemberChange(t *testing.T) {
+	const (
+		owner  = model.OrganizationRoleOwner
+		admin  = model.OrganizationRoleAdmin
+		member = model.OrganizationRoleMember
+	)
+
+	tests := []struct {
+		name    string
+		caller  model.OrganizationRole
+		from    model.OrganizationRole
+		to      model.OrganizationRole
+		allowed bool
+	}{
+		{name: "owner adds an owner", caller: owner, to: owner, allowed: true},
+		{name: "owner demotes an owner", caller: owner, from: owner, to: member, allowed: true},
+		{name: "admin adds a member", caller: admin, to: member, allowed: true},
+		{name: "admin promotes a member to admin", caller: admin, from: member, to: admin, allowed: true},
+		{name: "admin removes an admin", caller: admin, from: admin, allowed: true},
+		{name: "admin adds an owner", caller: admin, to: owner},
+		{name: "admin removes an owner", caller: admin, from: owner},
+		{name: "admin demotes an owner", caller: admin, from: owner, to: admin},
+		{name: "member adds a member", caller: member, to: member},
+		{name: "member removes a member", caller: member, from: member},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := authorizeMemberChange(tt.caller, tt.from, tt.to)
+			if tt.allowed {
+				assert.NoError(t, err)
+				return
+			}
+
+			var gqlErr *gqlerror.Error
+			if assert.ErrorAs(t, err, &gqlErr) {
+				assert.Equal(t, errForbidden, gqlErr.Extensions["code"])
+			}
+		})
+	}
+}
+
+func TestStoredOrganizationRole(t *testing.T) {
+	assert.Equal(t, "owner", storedOrganizationRole(model.OrganizationRoleOwner))
+	assert.Equal(t, "member", storedOrganizationRole(model.OrganizationRoleMember))
+}
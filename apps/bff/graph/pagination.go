@@ -0,0 +1,204 @@
+package graph
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+const (
+	defaultChatPageSize = 50
+	maxChatPageSize     = 100
+
+	// defaultPageSize is the size of connection pages requested without first or last
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// likeEscaper escapes the pattern characters of ILIKE so that search text is
+// matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// encodeCursor returns an opaque cursor for the item with the given creation
+// time and ID. Items are ordered by both so that cursors stay unambiguous when
+// timestamps collide.
+func encodeCursor(createdAt time.Time, id string) string {
+	return base64.URLEncoding.EncodeToString([]byte(createdAt.UTC().Format(time.RFC3339Nano) + "|" + id))
+}
+
+// decodeCursor reverses encodeCursor
+func decodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.URLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid cursor")
+	}
+
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found || id == "" {
+		return time.Time{}, "", fmt.Errorf("invalid cursor")
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid cursor")
+	}
+
+	return t, id, nil
+}
+
+// connectionPage is the page of a connection selected by its first/after or
+// last/before arguments. Connections are ordered oldest first by creation time
+// and ID. Pages are comparable so that they can key loaders and caches.
+type connectionPage struct {
+	limit int
+
+	// backward pages end before the cursor rather than start after it
+	backward bool
+
+	// cursorCreatedAt and cursorID are decoded from the after or before cursor;
+	// cursorID is empty for pages starting at either end of the connection
+	cursorCreatedAt time.Time
+	cursorID        string
+}
+
+// newConnectionPage validates the pagination arguments of a connection field.
+// first and last are 0 when not given; pages hold defaultPageSize items unless
+// one of them is set.
+func newConnectionPage(first int, after *string, last int, before *string) (connectionPage, error) {
+	page := connectionPage{
+		limit:    defaultPageSize,
+		backward: last > 0 || before != nil,
+	}
+
+	if first < 0 || last < 0 {
+		return page, fmt.Errorf("first and last must not be negative")
+	}
+	if page.backward && (first > 0 || after != nil) {
+		return page, fmt.Errorf("first and after cannot be combined with last and before")
+	}
+
+	if size := first + last; size > 0 {
+		if size > maxPageSize {
+			return page, fmt.Errorf("first and last must be at most %d", maxPageSize)
+		}
+		page.limit = size
+	}
+
+	cursor := after
+	if page.backward {
+		cursor = before
+	}
+	if cursor != nil {
+		createdAt, id, err := decodeCursor(*cursor)
+		if err != nil {
+			return page, err
+		}
+		page.cursorCreatedAt, page.cursorID = createdAt, id
+	}
+
+	return page, nil
+}
+
+// filter returns the condition selecting the items past the cursor of the page,
+// with its arguments appended to args, or an empty condition when the page has
+// no cursor
+func (p connectionPage) filter(args []interface{}) (string, []interface{}) {
+	if p.cursorID == "" {
+		return "", args
+	}
+
+	operator := ">"
+	if p.backward {
+		operator = "<"
+	}
+	args = append(args, p.cursorCreatedAt, p.cursorID)
+	return fmt.Sprintf("(created_at, id) %s ($%d, $%d)", operator, len(args)-1, len(args)), args
+}
+
+// order returns the ORDER BY expression walking the connection from the cursor
+func (p connectionPage) order() string {
+	if p.backward {
+		return "created_at DESC, id DESC"
+	}
+	return "created_at, id"
+}
+
+// fetchLimit is the number of rows to fetch for the page. The extra row tells
+// whether another page follows.
+func (p connectionPage) fetchLimit() int {
+	return p.limit + 1
+}
+
+// paginate trims rows fetched in the order of p to the page, puts them oldest
+// first and returns them with their cursors and the page info
+func paginate[T any](p connectionPage, rows []T, cursor func(T) string) ([]T, []string, *model.PageInfo) {
+	info := &model.PageInfo{}
+
+	more := len(rows) > p.limit
+	if more {
+		rows = rows[:p.limit]
+	}
+
+	items := make([]T, len(rows))
+	copy(items, rows)
+	if p.backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+		info.HasPreviousPage = more
+		info.HasNextPage = p.cursorID != ""
+	} else {
+		info.HasNextPage = more
+		info.HasPreviousPage = p.cursorID != ""
+	}
+
+	cursors := make([]string, len(items))
+	for i, item := range items {
+		cursors[i] = cursor(item)
+	}
+	if len(cursors) > 0 {
+		info.StartCursor = &cursors[0]
+		info.EndCursor = &cursors[len(cursors)-1]
+	}
+
+	return items, cursors, info
+}
+
+func projectConnection(page connectionPage, rows []*model.Project) *model.ProjectConnection {
+	projects, cursors, info := paginate(page, rows, func(project *model.Project) string {
+		return encodeCursor(project.CreatedAt, project.ID)
+	})
+
+	connection := &model.ProjectConnection{Edges: make([]*model.ProjectEdge, len(projects)), PageInfo: info}
+	for i, project := range projects {
+		connection.Edges[i] = &model.ProjectEdge{Cursor: cursors[i], Node: project}
+	}
+	return connection
+}
+
+func boardConnection(page connectionPage, rows []*model.Board) *model.BoardConnection {
+	boards, cursors, info := paginate(page, rows, func(board *model.Board) string {
+		return encodeCursor(board.CreatedAt, board.ID)
+	})
+
+	connection := &model.BoardConnection{Edges: make([]*model.BoardEdge, len(boards)), PageInfo: info}
+	for i, board := range boards {
+		connection.Edges[i] = &model.BoardEdge{Cursor: cursors[i], Node: board}
+	}
+	return connection
+}
+
+func assetConnection(page connectionPage, rows []*model.Asset) *model.AssetConnection {
+	assets, cursors, info := paginate(page, rows, func(asset *model.Asset) string {
+		return encodeCursor(asset.CreatedAt, asset.ID)
+	})
+
+	connection := &model.AssetConnection{Edges: make([]*model.AssetEdge, len(assets)), PageInfo: info}
+	for i, asset := range assets {
+		connection.Edges[i] = &model.AssetEdge{Cursor: cursors[i], Node: asset}
+	}
+	return connection
+}
@@ -0,0 +1,176 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+func testProjects(n int) []*model.Project {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	projects := make([]*model.Project, n)
+	for i := range projects {
+		projects[i] = &model.Project{ID: string(rune('a' + i)), CreatedAt: start.Add(time.Duration(i) * time.Minute)}
+	}
+	return projects
+}
+
+func reversed(projects []*model.Project) []*model.Project {
+	out := make([]*model.Project, len(projects))
+	for i, project := range projects {
+		out[len(projects)-1-i] = project
+	}
+	return out
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 12, 30, 0, 123456789, time.FixedZone("CET", 3600))
+
+	gotTime, gotID, err := decodeCursor(encodeCursor(createdAt, "asset-1"))
+	require.NoError(t, err)
+	assert.True(t, createdAt.Equal(gotTime))
+	assert.Equal(t, "asset-1", gotID)
+
+	for _, cursor := range []string{"not-a-cursor", encodeCursor(createdAt, "")} {
+		_, _, err := decodeCursor(cursor)
+		assert.EqualError(t, err, "invalid cursor")
+	}
+}
+
+func TestNewConnectionPage(t *testing.T) {
+	cursor := encodeCursor(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "b")
+	invalid := "not-a-cursor"
+
+	page, err := newConnectionPage(0, nil, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, connectionPage{limit: defaultPageSize}, page)
+
+	page, err = newConnectionPage(5, &cursor, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 5, page.limit)
+	assert.False(t, page.backward)
+	assert.Equal(t, "b", page.cursorID)
+
+	page, err = newConnectionPage(0, nil, 0, &cursor)
+	require.NoError(t, err)
+	assert.Equal(t, defaultPageSize, page.limit)
+	assert.True(t, page.backward)
+	assert.Equal(t, "b", page.cursorID)
+
+	// Pages decoded from the same cursor are equal, so they share loader batches
+	again, err := newConnectionPage(0, nil, 0, &cursor)
+	require.NoError(t, err)
+	assert.True(t, page == again)
+
+	tests := []struct {
+		first, last   int
+		after, before *string
+		err           string
+	}{
+		{first: -1, err: "first and last must not be negative"},
+		{first: maxPageSize + 1, err: "first and last must be at most 100"},
+		{first: 2, last: 2, err: "first and after cannot be combined with last and before"},
+		{after: &cursor, before: &cursor, err: "first and after cannot be combined with last and before"},
+		{first: 2, after: &invalid, err: "invalid cursor"},
+	}
+	for _, tt := range tests {
+		_, err := newConnectionPage(tt.first, tt.after, tt.last, tt.before)
+		assert.EqualError(t, err, tt.err)
+	}
+}
+
+func TestConnectionPage_Filter(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cursor := encodeCursor(createdAt, "b")
+
+	page, err := newConnectionPage(2, nil, 0, nil)
+	require.NoError(t, err)
+	filter, args := page.filter([]interface{}{"board-1"})
+	assert.Empty(t, filter)
+	assert.Equal(t, []interface{}{"board-1"}, args)
+	assert.Equal(t, "created_at, id", page.order())
+	assert.Equal(t, 3, page.fetchLimit())
+
+	page, err = newConnectionPage(2, &cursor, 0, nil)
+	require.NoError(t, err)
+	filter, args = page.filter([]interface{}{"board-1"})
+	assert.Equal(t, "(created_at, id) > ($2, $3)", filter)
+	assert.Equal(t, []interface{}{"board-1", createdAt, "b"}, args)
+
+	page, err = newConnectionPage(0, nil, 2, &cursor)
+	require.NoError(t, err)
+	filter, _ = page.filter(nil)
+	assert.Equal(t, "(created_at, id) < ($1, $2)", filter)
+	assert.Equal(t, "created_at DESC, id DESC", page.order())
+}
+
+func TestPageQuery(t *testing.T) {
+	cursor := encodeCursor(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "b")
+	page, err := newConnectionPage(0, nil, 2, &cursor)
+	require.NoError(t, err)
+
+	query, args := pageQuery("boards", "id, name", "project_id", []string{"p1", "p2"}, page)
+
+	assert.Contains(t, query, "ROW_NUMBER() OVER (PARTITION BY project_id ORDER BY created_at DESC, id DESC)")
+	assert.Contains(t, query, "WHERE project_id = ANY($1::uuid[]) AND deleted_at IS NULL AND (created_at, id) < ($2, $3)")
+	assert.Contains(t, query, "WHERE position <= $4")
+	require.Len(t, args, 4)
+	assert.Equal(t, 3, args[3])
+}
+
+func TestProjectConnection(t *testing.T) {
+	projects := testProjects(5)
+	cursorOf := func(project *model.Project) string { return encodeCursor(project.CreatedAt, project.ID) }
+	ids := func(connection *model.ProjectConnection) []string {
+		var ids []string
+		for _, edge := range connection.Edges {
+			assert.Equal(t, cursorOf(edge.Node), edge.Cursor)
+			ids = append(ids, edge.Node.ID)
+		}
+		return ids
+	}
+
+	// The first page holds the extra row telling that another page follows
+	first, err := newConnectionPage(2, nil, 0, nil)
+	require.NoError(t, err)
+	connection := projectConnection(first, projects[:3])
+	assert.Equal(t, []string{"a", "b"}, ids(connection))
+	assert.Equal(t, &model.PageInfo{
+		HasNextPage: true,
+		StartCursor: stringPtr(cursorOf(projects[0])),
+		EndCursor:   stringPtr(cursorOf(projects[1])),
+	}, connection.PageInfo)
+
+	after := cursorOf(projects[1])
+	next, err := newConnectionPage(2, &after, 0, nil)
+	require.NoError(t, err)
+	connection = projectConnection(next, projects[2:4])
+	assert.Equal(t, []string{"c", "d"}, ids(connection))
+	assert.False(t, connection.PageInfo.HasNextPage)
+	assert.True(t, connection.PageInfo.HasPreviousPage)
+
+	// Backward pages are fetched newest first and returned oldest first
+	last, err := newConnectionPage(0, nil, 2, nil)
+	require.NoError(t, err)
+	connection = projectConnection(last, reversed(projects)[:3])
+	assert.Equal(t, []string{"d", "e"}, ids(connection))
+	assert.True(t, connection.PageInfo.HasPreviousPage)
+	assert.False(t, connection.PageInfo.HasNextPage)
+
+	before := cursorOf(projects[3])
+	previous, err := newConnectionPage(0, nil, 3, &before)
+	require.NoError(t, err)
+	connection = projectConnection(previous, reversed(projects[:3]))
+	assert.Equal(t, []string{"a", "b", "c"}, ids(connection))
+	assert.False(t, connection.PageInfo.HasPreviousPage)
+	assert.True(t, connection.PageInfo.HasNextPage)
+
+	connection = projectConnection(first, nil)
+	assert.NotNil(t, connection.Edges)
+	assert.Empty(t, connection.Edges)
+	assert.Equal(t, &model.PageInfo{}, connection.PageInfo)
+}
@@ -0,0 +1,35 @@
+package graph
+
+import (
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+// connectorPlatforms maps GraphQL platforms to the identifiers used by the
+// connectors service. Only platforms with a connector are listed.
+var connectorPlatforms = map[model.CampaignPlatform]string{
+	model.CampaignPlatformGoogleAds: "google_ads",
+	model.CampaignPlatformMeta:      "meta",
+}
+
+func toNatsCredentials(input model.PlatformCredentialsInput) nats.PlatformCredentials {
+	return nats.PlatformCredentials{
+		DeveloperToken:  derefString(input.DeveloperToken),
+		ClientID:        derefString(input.ClientID),
+		ClientSecret:    derefString(input.ClientSecret),
+		RefreshToken:    derefString(input.RefreshToken),
+		CustomerID:      derefString(input.CustomerID),
+		LoginCustomerID: derefString(input.LoginCustomerID),
+		AppID:           derefString(input.AppID),
+		AppSecret:       derefString(input.AppSecret),
+		AccessToken:     derefString(input.AccessToken),
+		AdAccountID:     derefString(input.AdAccountID),
+	}
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
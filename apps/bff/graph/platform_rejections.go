@@ -0,0 +1,70 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+// RejectAssetOnPlatform marks an approved or deployed asset as rejected by an ad
+// platform's review and records the platform's reason. It is called for events
+// from the connectors service rather than by a user, so row-level security does
+// not apply.
+func (r *Resolver) RejectAssetOnPlatform(ctx context.Context, assetID string, reason string) (*model.Asset, error) {
+	tx, err := r.DB.Writer().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var currentStatus model.AssetStatus
+	err = tx.QueryRowContext(ctx, `SELECT status FROM assets WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, assetID).Scan(&currentStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("asset not found")
+		}
+		return nil, fmt.Errorf("failed to query asset: %w", err)
+	}
+
+	if err := assetStatusMachine.ValidateTransition(currentStatus, model.AssetStatusRejected); err != nil {
+		return nil, err
+	}
+
+	var asset model.Asset
+	var approvedBy sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		UPDATE assets
+		SET status = $1, platform_rejection_reason = $2, updated_at = $3
+		WHERE id = $4
+		RETURNING id, name, type, url, status, board_id, approved_by, approved_at, platform_rejection_reason, rejection_reason, duplicate_of, scheduled_at, thumbnail_url, created_at, updated_at
+	`, model.AssetStatusRejected, reason, time.Now(), assetID).Scan(
+		&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
+		&asset.BoardID, &approvedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+		&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reject asset: %w", err)
+	}
+	if approvedBy.Valid {
+		asset.ApprovedBy = &model.User{ID: approvedBy.String}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit asset rejection: %w", err)
+	}
+
+	if r.NatsConn != nil {
+		if err := r.NatsConn.PublishBoardUpdate(asset.BoardID, &asset); err != nil {
+			log.Printf("Failed to publish board update: %v", err)
+		}
+		if err := r.NatsConn.PublishAssetUpdate(asset.BoardID, &asset); err != nil {
+			log.Printf("Failed to publish asset update: %v", err)
+		}
+	}
+
+	return &asset, nil
+}
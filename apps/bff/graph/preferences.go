@@ -0,0 +1,107 @@
+package graph
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+//go:embed preferences_schema.json
+var preferencesSchemaJSON []byte
+
+// preferenceProperty describes the allowed values of one preference key
+type preferenceProperty struct {
+	Type string        `json:"type"`
+	Enum []interface{} `json:"enum,omitempty"`
+}
+
+// preferencesSchema lists the known preference keys
+type preferencesSchema struct {
+	Properties map[string]preferenceProperty `json:"properties"`
+}
+
+var knownPreferences = mustLoadPreferencesSchema(preferencesSchemaJSON)
+
+func mustLoadPreferencesSchema(data []byte) preferencesSchema {
+	var schema preferencesSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		panic(fmt.Sprintf("invalid preferences schema: %v", err))
+	}
+	return schema
+}
+
+// validatePreferences checks that every key is known and its value has the type
+// and, where listed, one of the values of the schema. A null value clears the key.
+func validatePreferences(preferences map[string]interface{}) error {
+	keys := make([]string, 0, len(preferences))
+	for key := range preferences {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		property, ok := knownPreferences.Properties[key]
+		if !ok {
+			return fmt.Errorf("unknown preference: %s", key)
+		}
+
+		value := preferences[key]
+		if value == nil {
+			continue
+		}
+
+		if valueType := jsonType(value); valueType != property.Type {
+			return fmt.Errorf("preference %s must be of type %s, got %s", key, property.Type, valueType)
+		}
+
+		if len(property.Enum) > 0 && !containsValue(property.Enum, value) {
+			return fmt.Errorf("invalid value for preference %s: %v", key, value)
+		}
+	}
+
+	return nil
+}
+
+// splitPreferenceUpdate separates the keys to set from the keys cleared with null
+func splitPreferenceUpdate(preferences map[string]interface{}) (map[string]interface{}, []string) {
+	set := make(map[string]interface{}, len(preferences))
+	cleared := []string{}
+	for key, value := range preferences {
+		if value == nil {
+			cleared = append(cleared, key)
+			continue
+		}
+		set[key] = value
+	}
+	sort.Strings(cleared)
+
+	return set, cleared
+}
+
+// jsonType returns the JSON schema type name of a decoded GraphQL value
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int32, int64, float32, float64, json.Number:
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+// topCampaignsLimit is the number of campaigns listed in the topCampaigns of a
+// ProjectROI
+const topCampaignsLimit = 5
+
+// sqlQuerier runs queries within a user's transaction or directly on the
+// database
+type sqlQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// platformTotals are the summed metrics of the campaigns of a project on one
+// platform
+type platformTotals struct {
+	platform    string
+	costMicros  int64
+	revenue     float64
+	conversions float64
+}
+
+// validateDateRange checks that dateRange holds two YYYY-MM-DD dates in order
+func validateDateRange(dateRange model.DateRange) error {
+	start, err := time.Parse("2006-01-02", dateRange.StartDate)
+	if err != nil {
+		return fmt.Errorf("startDate must be a date in the format YYYY-MM-DD")
+	}
+	end, err := time.Parse("2006-01-02", dateRange.EndDate)
+	if err != nil {
+		return fmt.Errorf("endDate must be a date in the format YYYY-MM-DD")
+	}
+	if end.Before(start) {
+		return fmt.Errorf("endDate must not be before startDate")
+	}
+	return nil
+}
+
+// projectROI computes the return on ad spend of the campaigns of a project
+// whose reporting period overlaps dateRange, or of all of them when dateRange is
+// nil. Campaigns without dates count in every date range.
+func (r *Resolver) projectROI(ctx context.Context, db sqlQuerier, projectID string, dateRange *model.DateRange) (*model.ProjectROI, error) {
+	var startDate, endDate sql.NullString
+	if dateRange != nil {
+		startDate = sql.NullString{String: dateRange.StartDate, Valid: true}
+		endDate = sql.NullString{String: dateRange.EndDate, Valid: true}
+	}
+
+	const inRange = `
+		project_id = $1
+		AND ($2::date IS NULL OR end_date IS NULL OR end_date >= $2::date)
+		AND ($3::date IS NULL OR start_date IS NULL OR start_date <= $3::date)`
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT platform, SUM(cost_micros), SUM(revenue), SUM(conversions)
+		FROM campaign_metrics
+		WHERE`+inRange+`
+		GROUP BY platform
+	`, projectID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query campaign metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []platformTotals
+	for rows.Next() {
+		var t platformTotals
+		if err := rows.Scan(&t.platform, &t.costMicros, &t.revenue, &t.conversions); err != nil {
+			return nil, fmt.Errorf("failed to scan campaign metrics: %w", err)
+		}
+		totals = append(totals, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate campaign metrics: %w", err)
+	}
+
+	rows, err = db.QueryContext(ctx, `
+		SELECT m.platform, m.campaign_id, COALESCE(a.name, ''), m.impressions, m.clicks, m.cost_micros,
+			m.conversions, m.ctr, m.revenue, COALESCE(m.start_date::text, ''), COALESCE(m.end_date::text, ''), m.fetched_at
+		FROM (SELECT * FROM campaign_metrics WHERE`+inRange+`) m
+		LEFT JOIN assets a ON a.id = m.asset_id
+		ORDER BY CASE WHEN m.cost_micros > 0 THEN m.revenue / m.cost_micros ELSE 0 END DESC, m.revenue DESC, m.campaign_id
+		LIMIT $4
+	`, projectID, startDate, endDate, topCampaignsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top campaigns: %w", err)
+	}
+	defer rows.Close()
+
+	topCampaigns := []*model.CampaignMetrics{}
+	for rows.Next() {
+		event := nats.CampaignMetricsUpdatedEvent{ProjectID: projectID}
+		var campaignName string
+		metrics := &event.Metrics
+		err := rows.Scan(&event.Platform, &event.CampaignID, &campaignName, &metrics.Impressions, &metrics.Clicks, &metrics.CostMicros,
+			&metrics.Conversions, &metrics.CTR, &metrics.Revenue, &metrics.StartDate, &metrics.EndDate, &metrics.FetchedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan campaign metrics: %w", err)
+		}
+		metrics.ROAS = roas(metrics.CostMicros, metrics.Revenue)
+		topCampaigns = append(topCampaigns, campaignMetricsUpdate(&event, campaignName).Metrics)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate top campaigns: %w", err)
+	}
+
+	return projectROIFromTotals(projectID, totals, topCampaigns), nil
+}
+
+// projectROIFromTotals sums the metrics of each platform into the return on ad
+// spend of a project
+func projectROIFromTotals(projectID string, totals []platformTotals, topCampaigns []*model.CampaignMetrics) *model.ProjectROI {
+	roi := &model.ProjectROI{
+		ProjectID:      projectID,
+		ROASByPlatform: make(map[string]float64, len(totals)),
+		TopCampaigns:   topCampaigns,
+	}
+
+	var costMicros int64
+	var conversions float64
+	for _, t := range totals {
+		costMicros += t.costMicros
+		conversions += t.conversions
+		roi.TotalRevenue += t.revenue
+		roi.ROASByPlatform[strings.ToUpper(t.platform)] = roas(t.costMicros, t.revenue)
+	}
+
+	roi.TotalSpend = float64(costMicros) / 1e6
+	roi.TotalConversions = int(math.Round(conversions))
+	roi.ROAS = roas(costMicros, roi.TotalRevenue)
+
+	return roi
+}
+
+// roas is the return on ad spend of revenue for a cost in micros, or 0 without
+// cost
+func roas(costMicros int64, revenue float64) float64 {
+	if costMicros <= 0 {
+		return 0
+	}
+	return revenue / (float64(costMicros) / 1e6)
+}
+
+// publishProjectROI sends the all-time return on ad spend of a project to its
+// projectROIUpdated subscribers after its campaign metrics have changed
+func (r *Resolver) publishProjectROI(ctx context.Context, projectID string) {
+	roi, err := r.projectROI(ctx, r.DB.Writer(), projectID, nil)
+	if err != nil {
+		log.Printf("Failed to compute ROI of project %s: %v", projectID, err)
+		return
+	}
+
+	if err := r.NatsConn.PublishProjectROIUpdate(projectID, roi); err != nil {
+		log.Printf("Failed to publish project ROI update: %v", err)
+	}
+}
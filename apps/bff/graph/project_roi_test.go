@@ -0,0 +1,44 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+func TestValidateDateRange(t *testing.T) {
+	assert.NoError(t, validateDateRange(model.DateRange{StartDate: "2024-03-01", EndDate: "2024-03-31"}))
+	assert.NoError(t, validateDateRange(model.DateRange{StartDate: "2024-03-01", EndDate: "2024-03-01"}))
+
+	assert.EqualError(t, validateDateRange(model.DateRange{StartDate: "03/01/2024", EndDate: "2024-03-31"}),
+		"startDate must be a date in the format YYYY-MM-DD")
+	assert.EqualError(t, validateDateRange(model.DateRange{StartDate: "2024-03-01", EndDate: "2024-02-30"}),
+		"endDate must be a date in the format YYYY-MM-DD")
+	assert.EqualError(t, validateDateRange(model.DateRange{StartDate: "2024-03-31", EndDate: "2024-03-01"}),
+		"endDate must not be before startDate")
+}
+
+func TestProjectROIFromTotals(t *testing.T) {
+	top := []*model.CampaignMetrics{{CampaignID: "555"}}
+	roi := projectROIFromTotals("project-1", []platformTotals{
+		{platform: "google_ads", costMicros: 100000000, revenue: 250, conversions: 10.4},
+		{platform: "meta", costMicros: 50000000, revenue: 50, conversions: 4.3},
+		{platform: "linkedin", revenue: 20},
+	}, top)
+
+	assert.Equal(t, "project-1", roi.ProjectID)
+	assert.Equal(t, 150.0, roi.TotalSpend)
+	assert.Equal(t, 320.0, roi.TotalRevenue)
+	assert.Equal(t, 15, roi.TotalConversions)
+	assert.InDelta(t, 2.1333, roi.ROAS, 0.0001)
+	assert.Equal(t, map[string]float64{"GOOGLE_ADS": 2.5, "META": 1, "LINKEDIN": 0}, roi.ROASByPlatform)
+	assert.Equal(t, top, roi.TopCampaigns)
+
+	// Projects without campaigns have no return
+	roi = projectROIFromTotals("project-2", nil, []*model.CampaignMetrics{})
+	assert.Zero(t, roi.TotalSpend)
+	assert.Zero(t, roi.ROAS)
+	assert.Empty(t, roi.ROASByPlatform)
+}
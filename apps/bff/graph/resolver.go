@@ -1,9 +1,23 @@
 package graph
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/assets"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/audit"
 	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/autocomplete"
 	"github.com/zerionstudio/zamc-v2/apps/bff/internal/database"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/export"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/middleware"
 	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/quality"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/webhook"
 
 
 )
@@ -13,7 +27,149 @@ import (
 // It serves as dependency injection for your app, add any dependencies you require here.
 
 type Resolver struct {
-	DB          *database.DB
+	// DB sends SELECT-only resolvers to the read replica and writes to the primary
+	DB          *database.Pool
 	NatsConn    *nats.Conn
 	AuthService *auth.Service
-} 
\ No newline at end of file
+
+	// SLAHours is the number of business hours an asset may wait for review
+	SLAHours int
+
+	// BoardExports stores exported board archives; nil when Redis is unavailable
+	BoardExports *export.Store
+
+	// BoardOperations records collaborative edits to board descriptions; nil when
+	// Redis is unavailable
+	BoardOperations *BoardOperationLog
+
+	// Validator sanitizes free-text input such as search terms
+	Validator *middleware.InputValidator
+
+	// Cache holds per-user data such as preferences; nil disables caching
+	Cache *ResolverCache
+
+	// Permissions caches the permissions of users in projects; nil disables caching
+	Permissions *Permissions
+
+	// Audit records who changed what with each mutation; nil disables auditing
+	Audit *audit.AuditLogger
+
+	// Logins records the sign-in activity of users; nil disables login history
+	Logins *audit.LoginRecorder
+
+	// Thumbnails generates previews of uploaded images; nil disables thumbnails
+	Thumbnails *assets.ImageProcessor
+
+	// Autocomplete indexes asset names for search suggestions; nil when Redis is
+	// unavailable
+	Autocomplete *autocomplete.Index
+
+	// Quality scores the copy of assets; nil when no scoring API is configured
+	Quality *quality.Scorer
+
+	// BoardWebhooks posts the events of boards to their webhooks; nil disables
+	// board webhooks
+	BoardWebhooks *webhook.BoardWebhookDispatcher
+
+	// SpendReports caches the monthly ad spend of projects; nil when Redis is
+	// unavailable
+	SpendReports *SpendReportCache
+}
+
+// validator returns the configured input validator, or a default one
+func (r *Resolver) validator() *middleware.InputValidator {
+	if r.Validator == nil {
+		return middleware.NewInputValidator()
+	}
+	return r.Validator
+}
+
+// userTx begins a transaction scoped to the authenticated user so that row-level
+// security policies apply to every statement run on it. Callers must roll back
+// or commit the returned transaction.
+func (r *Resolver) userTx(ctx context.Context) (*sql.Tx, *auth.User, error) {
+	authUser, err := currentUser(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := r.DB.WithUserContext(ctx, authUser.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := scopeTx(ctx, tx, authUser); err != nil {
+		return nil, nil, err
+	}
+
+	return tx, authUser, nil
+}
+
+// userReadTx is userTx for resolvers that only run SELECT statements. The
+// transaction is read-only and runs on the reader, except within mutations,
+// whose results must reflect their writes.
+func (r *Resolver) userReadTx(ctx context.Context) (*sql.Tx, *auth.User, error) {
+	if isMutation(ctx) {
+		return r.userTx(ctx)
+	}
+
+	authUser, err := currentUser(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := r.DB.WithUserReadContext(ctx, authUser.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := scopeTx(ctx, tx, authUser); err != nil {
+		return nil, nil, err
+	}
+
+	return tx, authUser, nil
+}
+
+// scopeTx hides the projects other than the one of a project-scoped token in tx
+func scopeTx(ctx context.Context, tx *sql.Tx, user *auth.User) error {
+	if user.ProjectScope == nil {
+		return nil
+	}
+
+	if err := database.ScopeToProject(ctx, tx, *user.ProjectScope); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return nil
+}
+
+// reader returns the database for SELECT statements run outside a user
+// transaction, which is the writer within mutations as in userReadTx
+func (r *Resolver) reader(ctx context.Context) *sql.DB {
+	if isMutation(ctx) {
+		return r.DB.Writer()
+	}
+	return r.DB.Reader()
+}
+
+// isMutation reports whether ctx is the context of a mutation operation
+func isMutation(ctx context.Context) bool {
+	return graphql.HasOperationContext(ctx) &&
+		graphql.GetOperationContext(ctx).Operation != nil &&
+		graphql.GetOperationContext(ctx).Operation.Operation == ast.Mutation
+}
+
+// currentUser returns the authenticated user of a request
+func currentUser(ctx context.Context) (*auth.User, error) {
+	user := ctx.Value("user")
+	if user == nil {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	authUser, ok := user.(*auth.User)
+	if !ok {
+		return nil, fmt.Errorf("invalid user context")
+	}
+
+	return authUser, nil
+}
@@ -27,7 +27,7 @@ func setupTestResolver() (*Resolver, *MockDB) {
 	mockDB := &MockDB{DB: &sql.DB{}}
 
 	resolver := &Resolver{
-		DB:          &database.DB{DB: mockDB.DB},
+		DB:          database.NewPool(mockDB.DB, nil),
 		NatsConn:    nil, // Unit tests focus on resolver logic, not external services
 		AuthService: nil, // Unit tests focus on resolver logic, not external services
 	}
@@ -131,7 +131,59 @@ func TestQueryResolver_Projects(t *testing.T) {
 		queryResolver := &queryResolver{resolver}
 		ctx := context.Background()
 
-		result, err := queryResolver.Projects(ctx)
+		result, err := queryResolver.Projects(ctx, 0, nil, 0, nil)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "unauthorized")
+	})
+}
+
+func TestQueryResolver_ChatMessages(t *testing.T) {
+	t.Run("Error - Unauthorized", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		queryResolver := &queryResolver{resolver}
+
+		result, err := queryResolver.ChatMessages(context.Background(), uuid.New().String(), 50, nil, nil, nil)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "unauthorized")
+	})
+
+	t.Run("Error - Page Size Out Of Range", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		queryResolver := &queryResolver{resolver}
+		ctx := createTestContext(uuid.New().String())
+
+		result, err := queryResolver.ChatMessages(ctx, uuid.New().String(), maxChatPageSize+1, nil, nil, nil)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "first must be between")
+	})
+
+	t.Run("Error - Invalid Cursor", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		queryResolver := &queryResolver{resolver}
+		ctx := createTestContext(uuid.New().String())
+		cursor := "not-a-cursor"
+
+		result, err := queryResolver.ChatMessages(ctx, uuid.New().String(), 10, &cursor, nil, nil)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "invalid cursor")
+	})
+}
+
+func TestQueryResolver_DeploymentTemplates(t *testing.T) {
+	t.Run("Error - Unauthorized", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		queryResolver := &queryResolver{resolver}
+		platform := model.CampaignPlatformMeta
+
+		result, err := queryResolver.DeploymentTemplates(context.Background(), &platform)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -172,6 +224,275 @@ func TestMutationResolver_ApproveAsset(t *testing.T) {
 	})
 }
 
+func TestMutationResolver_ApproveAssets(t *testing.T) {
+	t.Run("Error - Unauthorized", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+
+		result, err := mutationResolver.ApproveAssets(context.Background(), []string{uuid.New().String()})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "unauthorized")
+	})
+
+	t.Run("Error - Too Many Assets", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+		ctx := createTestContext(uuid.New().String())
+
+		ids := make([]string, maxApprovalBatch+1)
+		for i := range ids {
+			ids[i] = uuid.New().String()
+		}
+
+		result, err := mutationResolver.ApproveAssets(ctx, ids)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "at most")
+	})
+}
+
+func TestMutationResolver_DuplicateMetaCampaign(t *testing.T) {
+	t.Run("Error - Unauthorized", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+
+		result, err := mutationResolver.DuplicateMetaCampaign(context.Background(), uuid.New().String(), "Copy", 100)
+
+		assert.Error(t, err)
+		assert.Empty(t, result)
+		assert.Contains(t, err.Error(), "unauthorized")
+	})
+
+	t.Run("Error - Invalid Budget", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+		ctx := createTestContext(uuid.New().String())
+
+		result, err := mutationResolver.DuplicateMetaCampaign(ctx, uuid.New().String(), "Copy", 0)
+
+		assert.Error(t, err)
+		assert.Empty(t, result)
+		assert.Contains(t, err.Error(), "budget")
+	})
+}
+
+func TestMutationResolver_ExportBoard(t *testing.T) {
+	t.Run("Error - Unauthorized", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+
+		result, err := mutationResolver.ExportBoard(context.Background(), uuid.New().String())
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "unauthorized")
+	})
+}
+
+func TestMutationResolver_SubmitBoardOperation(t *testing.T) {
+	op := model.BoardOperation{Type: model.BoardOperationTypeInsert, Offset: 0, Chars: "Hello"}
+
+	t.Run("Error - Unauthorized", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+
+		result, err := mutationResolver.SubmitBoardOperation(context.Background(), uuid.New().String(), op)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "unauthorized")
+	})
+
+	t.Run("Error - Invalid Operation", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+		ctx := createTestContext(uuid.New().String())
+
+		result, err := mutationResolver.SubmitBoardOperation(ctx, uuid.New().String(), model.BoardOperation{Type: model.BoardOperationTypeDelete, Offset: -1, Chars: "x"})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "offset")
+	})
+}
+
+func TestMutationResolver_ReadAt(t *testing.T) {
+	t.Run("Error - Unauthorized", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+
+		result, err := mutationResolver.ReadAt(context.Background(), []string{uuid.New().String()})
+
+		assert.Error(t, err)
+		assert.False(t, result)
+		assert.Contains(t, err.Error(), "unauthorized")
+	})
+
+	t.Run("Error - No Messages", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+		ctx := createTestContext(uuid.New().String())
+
+		result, err := mutationResolver.ReadAt(ctx, nil)
+
+		assert.Error(t, err)
+		assert.False(t, result)
+	})
+}
+
+func TestMutationResolver_StorePlatformCredentials(t *testing.T) {
+	creds := model.PlatformCredentialsInput{AccessToken: stringPtr("token")}
+
+	t.Run("Error - Unauthorized", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+
+		result, err := mutationResolver.StorePlatformCredentials(context.Background(), uuid.New().String(), model.CampaignPlatformMeta, creds)
+
+		assert.Error(t, err)
+		assert.False(t, result)
+		assert.Contains(t, err.Error(), "unauthorized")
+	})
+
+	t.Run("Error - Not Admin", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+		ctx := createTestContext(uuid.New().String())
+
+		result, err := mutationResolver.StorePlatformCredentials(ctx, uuid.New().String(), model.CampaignPlatformMeta, creds)
+
+		assert.Error(t, err)
+		assert.False(t, result)
+		assert.Contains(t, err.Error(), "admin")
+	})
+
+	t.Run("Error - Unsupported Platform", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+		ctx := context.WithValue(context.Background(), "user", &auth.User{ID: uuid.New().String(), Role: "admin"})
+
+		result, err := mutationResolver.StorePlatformCredentials(ctx, uuid.New().String(), model.CampaignPlatformLinkedin, creds)
+
+		assert.Error(t, err)
+		assert.False(t, result)
+		assert.Contains(t, err.Error(), "unsupported platform")
+	})
+}
+
+func TestMutationResolver_UpdatePreferences(t *testing.T) {
+	t.Run("Error - Unauthorized", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+
+		result, err := mutationResolver.UpdatePreferences(context.Background(), map[string]interface{}{"language": "en"})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "unauthorized")
+	})
+
+	t.Run("Error - Unknown Key", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+		ctx := createTestContext(uuid.New().String())
+
+		result, err := mutationResolver.UpdatePreferences(ctx, map[string]interface{}{"favoriteColor": "blue"})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "unknown preference")
+	})
+
+	t.Run("Error - Invalid Value", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+		ctx := createTestContext(uuid.New().String())
+
+		_, err := mutationResolver.UpdatePreferences(ctx, map[string]interface{}{"emailNotifications": "yes"})
+		assert.Contains(t, err.Error(), "must be of type boolean")
+
+		_, err = mutationResolver.UpdatePreferences(ctx, map[string]interface{}{"notificationFrequency": "monthly"})
+		assert.Contains(t, err.Error(), "invalid value")
+	})
+}
+
+func TestMutationResolver_CreateDeploymentTemplate(t *testing.T) {
+	metadata := model.DeploymentMetadata{
+		Platform:    model.CampaignPlatformMeta,
+		ContentType: model.DeploymentContentTypeSocialMedia,
+		Budget:      40,
+	}
+
+	t.Run("Error - Unauthorized", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+
+		result, err := mutationResolver.CreateDeploymentTemplate(context.Background(), "Spring retargeting", metadata)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "unauthorized")
+	})
+
+	t.Run("Error - Missing Name", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+		ctx := createTestContext(uuid.New().String())
+
+		result, err := mutationResolver.CreateDeploymentTemplate(ctx, "  ", metadata)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "name is required")
+	})
+
+	t.Run("Error - Unsupported Platform", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+		ctx := createTestContext(uuid.New().String())
+		linkedin := metadata
+		linkedin.Platform = model.CampaignPlatformLinkedin
+
+		result, err := mutationResolver.CreateDeploymentTemplate(ctx, "Spring retargeting", linkedin)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "unsupported platform")
+	})
+
+	t.Run("Error - Invalid Targeting", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+		ctx := createTestContext(uuid.New().String())
+
+		negative := metadata
+		negative.Budget = -1
+		_, err := mutationResolver.CreateDeploymentTemplate(ctx, "Spring retargeting", negative)
+		assert.Contains(t, err.Error(), "budget must not be negative")
+
+		ages := metadata
+		ages.Demographics = &model.Demographics{AgeMin: 50, AgeMax: 30}
+		_, err = mutationResolver.CreateDeploymentTemplate(ctx, "Spring retargeting", ages)
+		assert.Contains(t, err.Error(), "minimum age")
+	})
+}
+
+func TestMutationResolver_DeployAssetFromTemplate(t *testing.T) {
+	t.Run("Error - Unauthorized", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+
+		result, err := mutationResolver.DeployAssetFromTemplate(context.Background(), uuid.New().String(), uuid.New().String())
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "unauthorized")
+	})
+}
+
 // Asset Resolver Tests
 func TestAssetResolver_Board(t *testing.T) {
 	_, _ = setupTestResolver() // Unused in skipped tests
@@ -0,0 +1,90 @@
+package graph
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/middleware"
+)
+
+// ResponseEnvelopeExtension adds the ID of the request, the time taken to answer
+// it and the version of the server to the extensions of every response, failed
+// ones included, and logs each completed operation
+type ResponseEnvelopeExtension struct {
+	// ServerVersion is the version the server was built as
+	ServerVersion string
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = ResponseEnvelopeExtension{}
+
+// NewResponseEnvelopeExtension creates the extension for a server built as
+// serverVersion
+func NewResponseEnvelopeExtension(serverVersion string) ResponseEnvelopeExtension {
+	return ResponseEnvelopeExtension{ServerVersion: serverVersion}
+}
+
+// ExtensionName returns the name of the extension
+func (ResponseEnvelopeExtension) ExtensionName() string {
+	return "ResponseEnvelope"
+}
+
+// Validate accepts every schema
+func (ResponseEnvelopeExtension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse adds the envelope to the response once it is ready. The
+// duration is counted from the receipt of the request, or from the start of the
+// operation when the request has no ID.
+func (e ResponseEnvelopeExtension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+	if resp == nil {
+		return resp
+	}
+
+	start, ok := middleware.RequestReceivedAt(ctx)
+	if !ok && graphql.HasOperationContext(ctx) {
+		start = graphql.GetOperationContext(ctx).Stats.OperationStart
+	}
+	duration := time.Since(start)
+	requestID := middleware.RequestIDFromContext(ctx)
+
+	if resp.Extensions == nil {
+		resp.Extensions = make(map[string]interface{})
+	}
+	resp.Extensions["requestId"] = requestID
+	resp.Extensions["durationMs"] = duration.Milliseconds()
+	resp.Extensions["serverVersion"] = e.ServerVersion
+
+	// Subscriptions are not logged, as they last as long as the client stays
+	// subscribed
+	operation := "anonymous"
+	if graphql.HasOperationContext(ctx) {
+		rc := graphql.GetOperationContext(ctx)
+		if rc.Operation != nil && rc.Operation.Operation == ast.Subscription {
+			return resp
+		}
+		if rc.Operation != nil && rc.Operation.Name != "" {
+			operation = rc.Operation.Name
+		} else if rc.OperationName != "" {
+			operation = rc.OperationName
+		}
+	}
+
+	userID := ""
+	if user, ok := ctx.Value("user").(*auth.User); ok {
+		userID = user.ID
+	}
+	log.Printf("INFO graphql operation=%s request_id=%s user_id=%s duration_ms=%d errors=%d",
+		operation, requestID, userID, duration.Milliseconds(), len(resp.Errors))
+
+	return resp
+}
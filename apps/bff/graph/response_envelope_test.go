@@ -0,0 +1,74 @@
+package graph
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/generated"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/middleware"
+)
+
+type envelopeResponse struct {
+	Data       json.RawMessage          `json:"data"`
+	Errors     []map[string]interface{} `json:"errors"`
+	Extensions struct {
+		RequestID     string  `json:"requestId"`
+		DurationMs    float64 `json:"durationMs"`
+		ServerVersion string  `json:"serverVersion"`
+	} `json:"extensions"`
+}
+
+// postEnveloped sends query, in a request received receivedAgo ago, to a server
+// adding the response envelope
+func postEnveloped(t *testing.T, query string, receivedAgo time.Duration) envelopeResponse {
+	t.Helper()
+
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{Resolvers: &Resolver{}}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(NewResponseEnvelopeExtension("1.2.3"))
+
+	body, err := json.Marshal(map[string]string{"query": query})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(middleware.WithRequestID(req.Context(), "request-1", time.Now().Add(-receivedAgo)))
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	var resp envelopeResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp), rec.Body.String())
+	return resp
+}
+
+func TestResponseEnvelope_Success(t *testing.T) {
+	resp := postEnveloped(t, `{ __typename }`, 25*time.Millisecond)
+
+	assert.Empty(t, resp.Errors)
+	assert.Equal(t, "request-1", resp.Extensions.RequestID)
+	assert.GreaterOrEqual(t, resp.Extensions.DurationMs, float64(25))
+	assert.Equal(t, "1.2.3", resp.Extensions.ServerVersion)
+}
+
+func TestResponseEnvelope_Failure(t *testing.T) {
+	// Operations failing validation are answered without being executed
+	resp := postEnveloped(t, `{ missingField }`, 0)
+	require.NotEmpty(t, resp.Errors)
+	assert.Equal(t, "request-1", resp.Extensions.RequestID)
+	assert.Equal(t, "1.2.3", resp.Extensions.ServerVersion)
+
+	// Resolver errors, here for a missing user
+	resp = postEnveloped(t, `{ projects { id } }`, 0)
+	require.NotEmpty(t, resp.Errors)
+	assert.Equal(t, "request-1", resp.Extensions.RequestID)
+	assert.Equal(t, "1.2.3", resp.Extensions.ServerVersion)
+}
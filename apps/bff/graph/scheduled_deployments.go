@@ -0,0 +1,182 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+// maxScheduleAhead is how far ahead deployments can be scheduled. The connectors
+// service holds scheduled deployments in its event stream, which keeps events
+// for a week.
+const maxScheduleAhead = 7 * 24 * time.Hour
+
+// validateDeploymentSchedule checks that scheduledAt is in the future and within
+// maxScheduleAhead of now
+func validateDeploymentSchedule(scheduledAt, now time.Time) error {
+	if !scheduledAt.After(now) {
+		return fmt.Errorf("scheduledAt must be in the future")
+	}
+	if scheduledAt.Sub(now) > maxScheduleAhead {
+		return fmt.Errorf("scheduledAt must be within %d days", int(maxScheduleAhead.Hours()/24))
+	}
+	return nil
+}
+
+// scheduledDeploymentBatch is how many due deployments are published per check
+const scheduledDeploymentBatch = 100
+
+// validateSchedulableStatus checks that an asset in status has not been approved
+// yet: the deployment of an asset is scheduled before its approval, which makes
+// it go live at the scheduled time instead of right away. Deployed, failed,
+// rejected and rolled back assets are never deployed again.
+func validateSchedulableStatus(status model.AssetStatus) error {
+	switch status {
+	case model.AssetStatusDraft, model.AssetStatusPending, model.AssetStatusReview, model.AssetStatusRevisionRequired:
+		return nil
+	case model.AssetStatusApproved:
+		return fmt.Errorf("approved assets cannot be scheduled")
+	case model.AssetStatusDeployed:
+		return fmt.Errorf("asset is already deployed")
+	default:
+		return fmt.Errorf("%s assets cannot be scheduled", status)
+	}
+}
+
+// RunScheduledDeployments publishes due scheduled deployments immediately and
+// then on every tick until ctx is done
+func (r *Resolver) RunScheduledDeployments(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if published, err := r.PublishDueDeployments(ctx); err != nil {
+			log.Printf("Scheduled deployment check failed: %v", err)
+		} else if published > 0 {
+			log.Printf("Published %d scheduled deployments", published)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dueDeployment is an approved asset to hand to the connectors service, whose
+// scheduled deployment time has come or whose approval a revert restored
+type dueDeployment struct {
+	asset        model.Asset
+	variantGroup sql.NullString
+	projectID    string
+	tenantID     string
+}
+
+// PublishDueDeployments hands the approved assets whose scheduled time has come
+// to the connectors service and returns how many were published. The assets are
+// claimed with row locks that other instances skip, and marked as published in
+// the same transaction, so each deployment is published by one instance only.
+// Assets whose event cannot be published are left for the next check.
+func (r *Resolver) PublishDueDeployments(ctx context.Context) (int, error) {
+	// Credentials and assets of every tenant are read, so outside of a user transaction
+	tx, err := r.DB.Writer().BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	rows, err := tx.QueryContext(ctx, `
+		SELECT a.id, a.name, a.type, a.url, a.board_id, a.scheduled_at, a.variant_group, p.id, p.owner_id
+		FROM assets a
+		JOIN boards b ON a.board_id = b.id
+		JOIN projects p ON b.project_id = p.id
+		WHERE a.status = $1
+			AND a.scheduled_at <= $2
+			AND a.schedule_published_at IS NULL
+			AND a.deleted_at IS NULL
+		ORDER BY a.scheduled_at
+		LIMIT $3
+		FOR UPDATE OF a SKIP LOCKED
+	`, model.AssetStatusApproved, now, scheduledDeploymentBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query due deployments: %w", err)
+	}
+
+	var due []dueDeployment
+	for rows.Next() {
+		var d dueDeployment
+		if err := rows.Scan(
+			&d.asset.ID, &d.asset.Name, &d.asset.Type, &d.asset.URL, &d.asset.BoardID,
+			&d.asset.ScheduledAt, &d.variantGroup, &d.projectID, &d.tenantID,
+		); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan due deployment: %w", err)
+		}
+		due = append(due, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to iterate due deployments: %w", err)
+	}
+
+	published := 0
+	for _, d := range due {
+		if err := r.publishApproval(ctx, &d, model.AssetStatusApproved, now); err != nil {
+			log.Printf("Failed to publish scheduled deployment of asset %s: %v", d.asset.ID, err)
+			continue
+		}
+
+		_, err := tx.ExecContext(ctx, `UPDATE assets SET schedule_published_at = $1 WHERE id = $2`, now, d.asset.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to mark scheduled deployment as published: %w", err)
+		}
+		published++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit scheduled deployments: %w", err)
+	}
+
+	return published, nil
+}
+
+// publishApproval publishes the status changed event of an approved asset, from
+// prevStatus, to the platforms its tenant has credentials for
+func (r *Resolver) publishApproval(ctx context.Context, d *dueDeployment, prevStatus model.AssetStatus, now time.Time) error {
+	platforms, err := r.credentialPlatforms(ctx, d.tenantID)
+	if err != nil {
+		return err
+	}
+	if len(platforms) == 0 {
+		return fmt.Errorf("the project owner has no platform credentials to deploy with")
+	}
+
+	metadata, err := json.Marshal(newApprovalMetadata(d.asset.Type, derefString(d.asset.URL), platforms))
+	if err != nil {
+		return fmt.Errorf("failed to encode asset metadata: %w", err)
+	}
+
+	return r.NatsConn.PublishScheduledDeployment(&nats.ScheduledDeploymentEvent{
+		EventType:    "asset.status_changed",
+		AssetID:      d.asset.ID,
+		ProjectID:    d.projectID,
+		TenantID:     d.tenantID,
+		Status:       strings.ToLower(string(model.AssetStatusApproved)),
+		PrevStatus:   strings.ToLower(string(prevStatus)),
+		ContentType:  assetContentType(d.asset.Type),
+		Title:        d.asset.Name,
+		Metadata:     metadata,
+		ScheduledAt:  d.asset.ScheduledAt,
+		VariantGroup: d.variantGroup.String,
+		Timestamp:    now,
+	})
+}
@@ -0,0 +1,38 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+func TestValidateDeploymentSchedule(t *testing.T) {
+	now := time.Date(2024, 1, 12, 17, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, validateDeploymentSchedule(now.Add(64*time.Hour), now))
+	assert.NoError(t, validateDeploymentSchedule(now.Add(maxScheduleAhead), now))
+
+	assert.EqualError(t, validateDeploymentSchedule(now, now), "scheduledAt must be in the future")
+	assert.EqualError(t, validateDeploymentSchedule(now.Add(-time.Minute), now), "scheduledAt must be in the future")
+	assert.EqualError(t, validateDeploymentSchedule(now.Add(maxScheduleAhead+time.Second), now), "scheduledAt must be within 7 days")
+}
+
+func TestValidateSchedulableStatus(t *testing.T) {
+	for _, status := range []model.AssetStatus{
+		model.AssetStatusDraft,
+		model.AssetStatusPending,
+		model.AssetStatusReview,
+		model.AssetStatusRevisionRequired,
+	} {
+		assert.NoError(t, validateSchedulableStatus(status), status)
+	}
+
+	assert.EqualError(t, validateSchedulableStatus(model.AssetStatusApproved), "approved assets cannot be scheduled")
+	assert.EqualError(t, validateSchedulableStatus(model.AssetStatusDeployed), "asset is already deployed")
+	assert.EqualError(t, validateSchedulableStatus(model.AssetStatusFailed), "FAILED assets cannot be scheduled")
+	assert.EqualError(t, validateSchedulableStatus(model.AssetStatusRejected), "REJECTED assets cannot be scheduled")
+	assert.EqualError(t, validateSchedulableStatus(model.AssetStatusRolledBack), "ROLLED_BACK assets cannot be scheduled")
+}
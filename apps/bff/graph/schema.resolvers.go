@@ -10,13 +10,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/zerionstudio/zamc-v2/apps/bff/graph/generated"
 	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
 	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
-
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/export"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/sla"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/webhook"
 )
 
 // Me is the resolver for the me field.
@@ -33,7 +38,7 @@ func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
 
 	// Query user from database
 	var dbUser model.User
-	err := r.DB.QueryRow(`
+	err := r.DB.Writer().QueryRowContext(ctx, `
 		SELECT id, email, name, avatar, created_at, updated_at 
 		FROM users WHERE id = $1
 	`, authUser.ID).Scan(
@@ -50,7 +55,7 @@ func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
 			UpdatedAt: time.Now(),
 		}
 
-		_, err = r.DB.Exec(`
+		_, err = r.DB.Writer().ExecContext(ctx, `
 			INSERT INTO users (id, email, created_at, updated_at)
 			VALUES ($1, $2, $3, $4)
 		`, dbUser.ID, dbUser.Email, dbUser.CreatedAt, dbUser.UpdatedAt)
@@ -66,23 +71,34 @@ func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
 }
 
 // Projects is the resolver for the projects field.
-func (r *queryResolver) Projects(ctx context.Context) ([]*model.Project, error) {
-	user := ctx.Value("user")
-	if user == nil {
-		return nil, fmt.Errorf("unauthorized")
+func (r *queryResolver) Projects(ctx context.Context, first int, after *string, last int, before *string) (*model.ProjectConnection, error) {
+	page, err := newConnectionPage(first, after, last, before)
+	if err != nil {
+		return nil, err
 	}
 
-	authUser, ok := user.(*auth.User)
-	if !ok {
-		return nil, fmt.Errorf("invalid user context")
+	query := `
+		SELECT id, name, description, status, owner_id, created_at, updated_at
+		FROM projects
+		WHERE deleted_at IS NULL`
+	filter, args := page.filter(nil)
+	if filter != "" {
+		query += " AND " + filter
 	}
 
-	rows, err := r.DB.Query(`
-		SELECT id, name, description, status, owner_id, created_at, updated_at
-		FROM projects WHERE owner_id = $1
-		ORDER BY created_at DESC
-	`, authUser.ID)
+	// Fetch one extra row to learn whether another page follows
+	args = append(args, page.fetchLimit())
+	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d", page.order(), len(args))
 
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Row-level security limits the result to owned and shared projects, and
+	// to the projects of the user's organizations
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query projects: %w", err)
 	}
@@ -101,26 +117,26 @@ func (r *queryResolver) Projects(ctx context.Context) ([]*model.Project, error)
 		projects = append(projects, &project)
 	}
 
-	return projects, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read projects: %w", err)
+	}
+
+	return projectConnection(page, projects), nil
 }
 
 // Project is the resolver for the project field.
 func (r *queryResolver) Project(ctx context.Context, id string) (*model.Project, error) {
-	user := ctx.Value("user")
-	if user == nil {
-		return nil, fmt.Errorf("unauthorized")
-	}
-
-	authUser, ok := user.(*auth.User)
-	if !ok {
-		return nil, fmt.Errorf("invalid user context")
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer tx.Rollback()
 
 	var project model.Project
-	err := r.DB.QueryRow(`
+	err = tx.QueryRowContext(ctx, `
 		SELECT id, name, description, status, owner_id, created_at, updated_at
-		FROM projects WHERE id = $1 AND owner_id = $2
-	`, id, authUser.ID).Scan(
+		FROM projects WHERE id = $1 AND deleted_at IS NULL
+	`, id).Scan(
 		&project.ID, &project.Name, &project.Description, &project.Status,
 		&project.OwnerID, &project.CreatedAt, &project.UpdatedAt,
 	)
@@ -136,17 +152,18 @@ func (r *queryResolver) Project(ctx context.Context, id string) (*model.Project,
 
 // Board is the resolver for the board field.
 func (r *queryResolver) Board(ctx context.Context, id string) (*model.Board, error) {
-	user := ctx.Value("user")
-	if user == nil {
-		return nil, fmt.Errorf("unauthorized")
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer tx.Rollback()
 
 	var board model.Board
-	err := r.DB.QueryRow(`
+	err = tx.QueryRowContext(ctx, `
 		SELECT b.id, b.name, b.description, b.project_id, b.created_at, b.updated_at
 		FROM boards b
 		JOIN projects p ON b.project_id = p.id
-		WHERE b.id = $1
+		WHERE b.id = $1 AND b.deleted_at IS NULL
 	`, id).Scan(
 		&board.ID, &board.Name, &board.Description, &board.ProjectID,
 		&board.CreatedAt, &board.UpdatedAt,
@@ -162,100 +179,141 @@ func (r *queryResolver) Board(ctx context.Context, id string) (*model.Board, err
 }
 
 // ChatMessages is the resolver for the chatMessages field.
-func (r *queryResolver) ChatMessages(ctx context.Context, boardID string, limit *int, offset *int) ([]*model.ChatMessage, error) {
-	user := ctx.Value("user")
-	if user == nil {
-		return nil, fmt.Errorf("unauthorized")
+func (r *queryResolver) ChatMessages(ctx context.Context, boardID string, first int, after *string, search *string, threadID *string) (*model.ChatMessageConnection, error) {
+	if first < 1 || first > maxChatPageSize {
+		return nil, fmt.Errorf("first must be between 1 and %d", maxChatPageSize)
+	}
+
+	query := `
+		SELECT id, content, user_id, board_id, parent_id, created_at
+		FROM chat_messages
+		WHERE board_id = $1`
+	args := []interface{}{boardID}
+
+	if threadID != nil {
+		args = append(args, *threadID)
+		query += fmt.Sprintf(` AND id IN (
+			WITH RECURSIVE thread AS (
+				SELECT id FROM chat_messages WHERE id = $%d
+				UNION ALL
+				SELECT m.id FROM chat_messages m JOIN thread t ON m.parent_id = t.id
+			)
+			SELECT id FROM thread
+		)`, len(args))
 	}
 
-	limitVal := 50
-	if limit != nil {
-		limitVal = *limit
+	if after != nil {
+		createdAt, id, err := decodeCursor(*after)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, createdAt, id)
+		query += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", len(args)-1, len(args))
 	}
 
-	offsetVal := 0
-	if offset != nil {
-		offsetVal = *offset
+	if search != nil {
+		args = append(args, likeEscaper.Replace(r.validator().SanitizeString(*search)))
+		query += fmt.Sprintf(" AND content ILIKE '%%' || $%d || '%%'", len(args))
 	}
 
-	rows, err := r.DB.Query(`
-		SELECT id, content, user_id, board_id, created_at
-		FROM chat_messages 
-		WHERE board_id = $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
-	`, boardID, limitVal, offsetVal)
+	// Fetch one extra row to learn whether another page follows
+	args = append(args, first+1)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d", len(args))
+
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query chat messages: %w", err)
 	}
 	defer rows.Close()
 
-	var messages []*model.ChatMessage
+	connection := &model.ChatMessageConnection{
+		Edges:    []*model.ChatMessageEdge{},
+		PageInfo: &model.PageInfo{HasPreviousPage: after != nil},
+	}
 	for rows.Next() {
+		if len(connection.Edges) == first {
+			connection.PageInfo.HasNextPage = true
+			break
+		}
+
 		var message model.ChatMessage
 		err := rows.Scan(
 			&message.ID, &message.Content, &message.UserID,
-			&message.BoardID, &message.CreatedAt,
+			&message.BoardID, &message.ParentID, &message.CreatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan chat message: %w", err)
 		}
-		messages = append(messages, &message)
-	}
-
-	return messages, nil
-}
 
-// ApproveAsset is the resolver for the approveAsset field.
-func (r *mutationResolver) ApproveAsset(ctx context.Context, assetID string) (*model.Asset, error) {
-	user := ctx.Value("user")
-	if user == nil {
-		return nil, fmt.Errorf("unauthorized")
+		cursor := encodeCursor(message.CreatedAt, message.ID)
+		connection.Edges = append(connection.Edges, &model.ChatMessageEdge{Cursor: cursor, Node: &message})
+		if connection.PageInfo.StartCursor == nil {
+			connection.PageInfo.StartCursor = &cursor
+		}
+		connection.PageInfo.EndCursor = &cursor
 	}
 
-	authUser, ok := user.(*auth.User)
-	if !ok {
-		return nil, fmt.Errorf("invalid user context")
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read chat messages: %w", err)
 	}
 
-	now := time.Now()
-	_, err := r.DB.Exec(`
-		UPDATE assets 
-		SET status = $1, approved_by = $2, approved_at = $3, updated_at = $4
-		WHERE id = $5
-	`, model.AssetStatusApproved, authUser.ID, now, now, assetID)
+	return connection, nil
+}
 
+// OverdueAssets is the resolver for the overdueAssets field.
+func (r *queryResolver) OverdueAssets(ctx context.Context, projectID string) ([]*model.Asset, error) {
+	tx, _, err := r.userReadTx(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to approve asset: %w", err)
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	// Get updated asset
-	var asset model.Asset
-	err = r.DB.QueryRow(`
-		SELECT id, name, type, url, status, board_id, approved_by, approved_at, created_at, updated_at
-		FROM assets WHERE id = $1
-	`, assetID).Scan(
-		&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
-		&asset.BoardID, &asset.ApprovedBy, &asset.ApprovedAt,
-		&asset.CreatedAt, &asset.UpdatedAt,
-	)
+	now := time.Now()
+	rows, err := tx.QueryContext(ctx, `
+		SELECT a.id, a.name, a.type, a.url, a.status, a.board_id, a.approved_by, a.approved_at, a.platform_rejection_reason, a.rejection_reason, a.duplicate_of, a.scheduled_at, a.thumbnail_url, a.created_at, a.updated_at
+		FROM assets a
+		JOIN boards b ON a.board_id = b.id
+		WHERE b.project_id = $1
+			AND a.status IN ($2, $3)
+			AND a.updated_at < $4
+			AND a.deleted_at IS NULL
+		ORDER BY a.updated_at ASC
+	`, projectID, model.AssetStatusReview, model.AssetStatusPending, now.Add(-time.Duration(r.SLAHours)*time.Hour))
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to query updated asset: %w", err)
+		return nil, fmt.Errorf("failed to query overdue assets: %w", err)
 	}
+	defer rows.Close()
 
-	// Publish board update
-	err = r.NatsConn.PublishBoardUpdate(asset.BoardID, &asset)
-	if err != nil {
-		log.Printf("Failed to publish board update: %v", err)
+	assets := []*model.Asset{}
+	for rows.Next() {
+		var asset model.Asset
+		err := rows.Scan(
+			&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
+			&asset.BoardID, &asset.ApprovedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+			&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan asset: %w", err)
+		}
+
+		// Weekend hours do not count towards the SLA
+		if sla.IsOverdue(asset.UpdatedAt, now, r.SLAHours) {
+			assets = append(assets, &asset)
+		}
 	}
 
-	return &asset, nil
+	return assets, nil
 }
 
-// Chat is the resolver for the chat field.
-func (r *mutationResolver) Chat(ctx context.Context, boardID string, content string) (*model.ChatMessage, error) {
+// MyPreferences is the resolver for the myPreferences field.
+func (r *queryResolver) MyPreferences(ctx context.Context) (map[string]interface{}, error) {
 	user := ctx.Value("user")
 	if user == nil {
 		return nil, fmt.Errorf("unauthorized")
@@ -266,293 +324,2435 @@ func (r *mutationResolver) Chat(ctx context.Context, boardID string, content str
 		return nil, fmt.Errorf("invalid user context")
 	}
 
-	message := model.ChatMessage{
-		ID:        uuid.New().String(),
-		Content:   content,
-		UserID:    authUser.ID,
-		BoardID:   boardID,
-		CreatedAt: time.Now(),
+	if r.Cache != nil {
+		if preferences, ok := r.Cache.GetPreferences(authUser.ID); ok {
+			return preferences, nil
+		}
 	}
 
-	_, err := r.DB.Exec(`
-		INSERT INTO chat_messages (id, content, user_id, board_id, created_at)
-		VALUES ($1, $2, $3, $4, $5)
-	`, message.ID, message.Content, message.UserID, message.BoardID, message.CreatedAt)
-
+	tx, _, err := r.userReadTx(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create chat message: %w", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var raw []byte
+	err = tx.QueryRowContext(ctx, `
+		SELECT preferences FROM user_preferences WHERE user_id = $1
+	`, authUser.ID).Scan(&raw)
+
+	// Users without stored preferences get an empty map
+	preferences := map[string]interface{}{}
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to query preferences: %w", err)
+	} else if err == nil {
+		if err := json.Unmarshal(raw, &preferences); err != nil {
+			return nil, fmt.Errorf("failed to decode preferences: %w", err)
+		}
 	}
 
-	// Publish board update
-	err = r.NatsConn.PublishBoardUpdate(boardID, &message)
-	if err != nil {
-		log.Printf("Failed to publish board update: %v", err)
+	if r.Cache != nil {
+		r.Cache.SetPreferences(authUser.ID, preferences)
 	}
 
-	return &message, nil
+	return preferences, nil
 }
 
-// CreateProject is the resolver for the createProject field.
-func (r *mutationResolver) CreateProject(ctx context.Context, input model.CreateProjectInput) (*model.Project, error) {
-	user := ctx.Value("user")
-	if user == nil {
-		return nil, fmt.Errorf("unauthorized")
+// BoardTemplates is the resolver for the boardTemplates field.
+func (r *queryResolver) BoardTemplates(ctx context.Context) ([]*model.BoardTemplate, error) {
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	authUser, ok := user.(*auth.User)
-	if !ok {
-		return nil, fmt.Errorf("invalid user context")
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, name, description, default_boards, created_by, is_public, created_at
+		FROM board_templates
+		ORDER BY is_public DESC, name, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query board templates: %w", err)
 	}
+	defer rows.Close()
 
-	project := model.Project{
-		ID:          uuid.New().String(),
-		Name:        input.Name,
-		Description: input.Description,
-		Status:      model.ProjectStatusActive,
-		OwnerID:     authUser.ID,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	templates := []*model.BoardTemplate{}
+	for rows.Next() {
+		template, err := scanBoardTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan board template: %w", err)
+		}
+		templates = append(templates, template)
 	}
 
-	_, err := r.DB.Exec(`
-		INSERT INTO projects (id, name, description, status, owner_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-	`, project.ID, project.Name, project.Description, project.Status,
-		project.OwnerID, project.CreatedAt, project.UpdatedAt)
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to create project: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate board templates: %w", err)
 	}
 
-	return &project, nil
+	return templates, nil
 }
 
-// CreateBoard is the resolver for the createBoard field.
-func (r *mutationResolver) CreateBoard(ctx context.Context, input model.CreateBoardInput) (*model.Board, error) {
-	user := ctx.Value("user")
-	if user == nil {
-		return nil, fmt.Errorf("unauthorized")
+// DeploymentTemplates is the resolver for the deploymentTemplates field.
+func (r *queryResolver) DeploymentTemplates(ctx context.Context, platform *model.CampaignPlatform) ([]*model.DeploymentTemplate, error) {
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	board := model.Board{
-		ID:          uuid.New().String(),
-		Name:        input.Name,
-		Description: input.Description,
-		ProjectID:   input.ProjectID,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	var platformFilter sql.NullString
+	if platform != nil {
+		platformFilter = sql.NullString{String: string(*platform), Valid: true}
 	}
 
-	_, err := r.DB.Exec(`
-		INSERT INTO boards (id, name, description, project_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`, board.ID, board.Name, board.Description, board.ProjectID,
-		board.CreatedAt, board.UpdatedAt)
-
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, name, owner_id, platform, content_type, template_metadata, created_at
+		FROM deployment_templates
+		WHERE $1::text IS NULL OR platform = $1
+		ORDER BY created_at DESC
+	`, platformFilter)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create board: %w", err)
+		return nil, fmt.Errorf("failed to query deployment templates: %w", err)
 	}
+	defer rows.Close()
 
-	return &board, nil
-}
-
-// UploadAsset is the resolver for the uploadAsset field.
-func (r *mutationResolver) UploadAsset(ctx context.Context, input model.UploadAssetInput) (*model.Asset, error) {
-	user := ctx.Value("user")
-	if user == nil {
-		return nil, fmt.Errorf("unauthorized")
+	templates := []*model.DeploymentTemplate{}
+	for rows.Next() {
+		template, err := scanDeploymentTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan deployment template: %w", err)
+		}
+		templates = append(templates, template)
 	}
 
-	asset := model.Asset{
-		ID:        uuid.New().String(),
-		Name:      input.Name,
-		Type:      input.Type,
-		URL:       &input.URL,
-		Status:    model.AssetStatusPending,
-		BoardID:   input.BoardID,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate deployment templates: %w", err)
 	}
 
-	_, err := r.DB.Exec(`
-		INSERT INTO assets (id, name, type, url, status, board_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`, asset.ID, asset.Name, asset.Type, asset.URL, asset.Status,
-		asset.BoardID, asset.CreatedAt, asset.UpdatedAt)
+	return templates, nil
+}
 
+// KeywordQualityScores is the resolver for the keywordQualityScores field.
+func (r *queryResolver) KeywordQualityScores(ctx context.Context, assetID string) ([]*model.KeywordQualityScore, error) {
+	tx, _, err := r.userReadTx(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create asset: %w", err)
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	// Publish board update
-	err = r.NatsConn.PublishBoardUpdate(input.BoardID, &asset)
-	if err != nil {
-		log.Printf("Failed to publish board update: %v", err)
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM assets WHERE id = $1 AND deleted_at IS NULL)`, assetID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to query asset: %w", err)
 	}
-
-	return &asset, nil
-}
-
-// BoardUpdated is the resolver for the boardUpdated field.
-func (r *subscriptionResolver) BoardUpdated(ctx context.Context, boardID string) (<-chan model.BoardUpdate, error) {
-	user := ctx.Value("user")
-	if user == nil {
-		return nil, fmt.Errorf("unauthorized")
+	if !exists {
+		return nil, fmt.Errorf("asset not found")
 	}
 
-	ch := make(chan model.BoardUpdate, 1)
-
-	// Subscribe to NATS updates
-	sub, err := r.NatsConn.SubscribeBoardUpdates(boardID, func(data []byte) {
-		var update model.BoardUpdate
-
-		// Try to unmarshal as Asset first
-		var asset model.Asset
-		if err := json.Unmarshal(data, &asset); err == nil {
-			update = asset
-		} else {
-			// Try to unmarshal as ChatMessage
-			var message model.ChatMessage
-			if err := json.Unmarshal(data, &message); err == nil {
-				update = message
-			} else {
-				log.Printf("Failed to unmarshal board update: %v", err)
-				return
-			}
-		}
+	rows, err := tx.QueryContext(ctx, `
+		SELECT keyword_id, ad_group_id, keyword, score, fetched_at
+		FROM keyword_quality_scores
+		WHERE asset_id = $1
+		ORDER BY score ASC, keyword ASC
+	`, assetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query keyword quality scores: %w", err)
+	}
+	defer rows.Close()
 
-		select {
-		case ch <- update:
-		case <-ctx.Done():
-			return
+	scores := []*model.KeywordQualityScore{}
+	for rows.Next() {
+		var score model.KeywordQualityScore
+		if err := rows.Scan(&score.KeywordID, &score.AdGroupID, &score.Keyword, &score.Score, &score.FetchedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan keyword quality score: %w", err)
 		}
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to subscribe to board updates: %w", err)
+		scores = append(scores, &score)
 	}
 
-	// Clean up subscription when context is done
-	go func() {
-		<-ctx.Done()
-		sub.Unsubscribe()
-		close(ch)
-	}()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate keyword quality scores: %w", err)
+	}
 
-	return ch, nil
+	return scores, nil
 }
 
-// Owner is the resolver for the owner field.
-func (r *projectResolver) Owner(ctx context.Context, obj *model.Project) (*model.User, error) {
-	var user model.User
-	err := r.DB.QueryRow(`
-		SELECT id, email, name, avatar, created_at, updated_at
-		FROM users WHERE id = $1
-	`, obj.OwnerID).Scan(
-		&user.ID, &user.Email, &user.Name, &user.Avatar,
-		&user.CreatedAt, &user.UpdatedAt,
-	)
-
+// ScheduledDeployments is the resolver for the scheduledDeployments field.
+func (r *queryResolver) ScheduledDeployments(ctx context.Context, projectID string) ([]*model.Asset, error) {
+	tx, _, err := r.userReadTx(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query user: %w", err)
+		return nil, err
 	}
-
-	return &user, nil
-}
-
-// Boards is the resolver for the boards field.
-func (r *projectResolver) Boards(ctx context.Context, obj *model.Project) ([]*model.Board, error) {
-	rows, err := r.DB.Query(`
-		SELECT id, name, description, project_id, created_at, updated_at
-		FROM boards WHERE project_id = $1
-		ORDER BY created_at DESC
-	`, obj.ID)
-
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT a.id, a.name, a.type, a.url, a.status, a.board_id, a.approved_by, a.approved_at, a.platform_rejection_reason, a.rejection_reason, a.duplicate_of, a.scheduled_at, a.thumbnail_url, a.created_at, a.updated_at
+		FROM assets a
+		JOIN boards b ON a.board_id = b.id
+		WHERE b.project_id = $1
+			AND a.scheduled_at > $2
+			AND a.deleted_at IS NULL
+		ORDER BY a.scheduled_at ASC
+	`, projectID, time.Now())
 	if err != nil {
-		return nil, fmt.Errorf("failed to query boards: %w", err)
+		return nil, fmt.Errorf("failed to query scheduled deployments: %w", err)
 	}
 	defer rows.Close()
 
-	var boards []*model.Board
+	assets := []*model.Asset{}
 	for rows.Next() {
-		var board model.Board
+		var asset model.Asset
+		var approvedBy sql.NullString
 		err := rows.Scan(
-			&board.ID, &board.Name, &board.Description, &board.ProjectID,
-			&board.CreatedAt, &board.UpdatedAt,
+			&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
+			&asset.BoardID, &approvedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+			&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan board: %w", err)
+			return nil, fmt.Errorf("failed to scan asset: %w", err)
 		}
-		boards = append(boards, &board)
+		if approvedBy.Valid {
+			asset.ApprovedBy = &model.User{ID: approvedBy.String}
+		}
+		assets = append(assets, &asset)
 	}
 
-	return boards, nil
-}
-
-// Project is the resolver for the project field.
-func (r *boardResolver) Project(ctx context.Context, obj *model.Board) (*model.Project, error) {
-	var project model.Project
-	err := r.DB.QueryRow(`
-		SELECT id, name, description, status, owner_id, created_at, updated_at
-		FROM projects WHERE id = $1
-	`, obj.ProjectID).Scan(
-		&project.ID, &project.Name, &project.Description, &project.Status,
-		&project.OwnerID, &project.CreatedAt, &project.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to query project: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate scheduled deployments: %w", err)
 	}
 
-	return &project, nil
+	return assets, nil
 }
 
-// Assets is the resolver for the assets field.
-func (r *boardResolver) Assets(ctx context.Context, obj *model.Board) ([]*model.Asset, error) {
-	rows, err := r.DB.Query(`
-		SELECT id, name, type, url, status, board_id, approved_by, approved_at, created_at, updated_at
-		FROM assets WHERE board_id = $1
-		ORDER BY created_at DESC
-	`, obj.ID)
+// AssetVariants is the resolver for the assetVariants field.
+func (r *queryResolver) AssetVariants(ctx context.Context, variantGroup string) ([]*model.Asset, error) {
+	if err := validateVariantGroup(variantGroup); err != nil {
+		return nil, err
+	}
 
+	tx, _, err := r.userReadTx(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query assets: %w", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Row-level security limits the variants to the user's projects
+	rows, err := tx.QueryContext(ctx, `
+		SELECT a.id, a.name, a.type, a.url, a.status, a.board_id, a.approved_by, a.approved_at, a.platform_rejection_reason, a.rejection_reason, a.duplicate_of, a.scheduled_at, a.thumbnail_url, a.created_at, a.updated_at
+		FROM assets a
+		JOIN boards b ON a.board_id = b.id
+		WHERE a.variant_group = $1
+			AND a.deleted_at IS NULL
+			AND b.deleted_at IS NULL
+		ORDER BY a.created_at ASC, a.id ASC
+	`, variantGroup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query asset variants: %w", err)
 	}
 	defer rows.Close()
 
-	var assets []*model.Asset
+	assets := []*model.Asset{}
 	for rows.Next() {
 		var asset model.Asset
+		var approvedBy sql.NullString
 		err := rows.Scan(
 			&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
-			&asset.BoardID, &asset.ApprovedBy, &asset.ApprovedAt,
-			&asset.CreatedAt, &asset.UpdatedAt,
+			&asset.BoardID, &approvedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+			&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan asset: %w", err)
+		}
+		if approvedBy.Valid {
+			asset.ApprovedBy = &model.User{ID: approvedBy.String}
+		}
+		assets = append(assets, &asset)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate asset variants: %w", err)
+	}
+
+	return assets, nil
+}
+
+// AssetHistory is the resolver for the assetHistory field.
+func (r *queryResolver) AssetHistory(ctx context.Context, assetID string) ([]*model.AssetVersion, error) {
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return assetHistory(ctx, tx, assetID)
+}
+
+// AssetVersionDiff is the resolver for the assetVersionDiff field.
+func (r *queryResolver) AssetVersionDiff(ctx context.Context, assetID string, fromVersion int, toVersion int) (*model.AssetDiff, error) {
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	from, err := assetVersion(ctx, tx, assetID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := assetVersion(ctx, tx, assetID, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffAssetVersions(from, to), nil
+}
+
+// SearchAssets is the resolver for the searchAssets field.
+func (r *queryResolver) SearchAssets(ctx context.Context, query string, projectID *string, status *model.AssetStatus) ([]*model.Asset, error) {
+	return r.searchAssets(ctx, query, projectID, status)
+}
+
+// SearchBoards is the resolver for the searchBoards field.
+func (r *queryResolver) SearchBoards(ctx context.Context, query string, projectID string) ([]*model.Board, error) {
+	return r.searchBoards(ctx, query, projectID)
+}
+
+// ListWebhooks is the resolver for the listWebhooks field.
+func (r *queryResolver) ListWebhooks(ctx context.Context, projectID string) ([]*model.Webhook, error) {
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+webhookColumns+`
+		FROM webhooks
+		WHERE project_id = $1
+		ORDER BY created_at, id
+	`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []*model.Webhook{}
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, hook)
+	}
+
+	return webhooks, nil
+}
+
+// AuditLog is the resolver for the auditLog field.
+func (r *queryResolver) AuditLog(ctx context.Context, entityType string, entityID string, limit int) ([]*model.AuditEntry, error) {
+	authUser, ok := ctx.Value("user").(*auth.User)
+	if !ok {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	if authUser.Role != "admin" {
+		return nil, fmt.Errorf("admin access required")
+	}
+
+	if limit < 1 || limit > maxAuditLogLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxAuditLogLimit)
+	}
+
+	if r.Audit == nil {
+		return nil, fmt.Errorf("audit log not available")
+	}
+
+	entries, err := r.Audit.Entries(ctx, entityType, entityID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	auditLog := make([]*model.AuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		auditLog = append(auditLog, auditEntry(entry))
+	}
+
+	return auditLog, nil
+}
+
+// LoginHistory is the resolver for the loginHistory field.
+func (r *queryResolver) LoginHistory(ctx context.Context, limit int, offset int) ([]*model.LoginEvent, error) {
+	authUser, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit < 1 || limit > maxLoginHistoryLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxLoginHistoryLimit)
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("offset must not be negative")
+	}
+
+	if r.Logins == nil {
+		return nil, fmt.Errorf("login history not available")
+	}
+
+	events, err := r.Logins.History(ctx, authUser.ID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]*model.LoginEvent, 0, len(events))
+	for _, event := range events {
+		history = append(history, loginEvent(event))
+	}
+
+	return history, nil
+}
+
+// ProjectRoi is the resolver for the projectROI field.
+func (r *queryResolver) ProjectRoi(ctx context.Context, projectID string, dateRange model.DateRange) (*model.ProjectROI, error) {
+	if err := validateDateRange(dateRange); err != nil {
+		return nil, err
+	}
+
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The cache is shared by the members of the project, so access is checked
+	// before it is read
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM projects WHERE id = $1 AND deleted_at IS NULL)`, projectID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to query project: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("project not found")
+	}
+
+	if r.Cache != nil {
+		if roi, ok := r.Cache.GetProjectROI(projectID, dateRange); ok {
+			return roi, nil
+		}
+	}
+
+	roi, err := r.projectROI(ctx, tx, projectID, &dateRange)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Cache != nil {
+		r.Cache.SetProjectROI(projectID, dateRange, roi)
+	}
+
+	return roi, nil
+}
+
+// ProjectSpendReport is the resolver for the projectSpendReport field.
+func (r *queryResolver) ProjectSpendReport(ctx context.Context, projectID string, month string) (*model.SpendReport, error) {
+	return r.projectSpendReport(ctx, projectID, month)
+}
+
+// Organizations is the resolver for the organizations field.
+func (r *queryResolver) Organizations(ctx context.Context) ([]*model.Organization, error) {
+	tx, authUser, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT o.id, o.name, o.plan, o.created_at
+		FROM organizations o
+		JOIN organization_members m ON m.org_id = o.id
+		WHERE m.user_id = $1
+		ORDER BY o.created_at, o.id
+	`, authUser.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query organizations: %w", err)
+	}
+	defer rows.Close()
+
+	organizations := []*model.Organization{}
+	for rows.Next() {
+		var organization model.Organization
+		if err := rows.Scan(&organization.ID, &organization.Name, &organization.Plan, &organization.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		organizations = append(organizations, &organization)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read organizations: %w", err)
+	}
+
+	return organizations, nil
+}
+
+// MyPermissions is the resolver for the myPermissions field.
+func (r *queryResolver) MyPermissions(ctx context.Context, projectID string) (*model.Permissions, error) {
+	return r.myPermissions(ctx, projectID)
+}
+
+// DeploymentHistory is the resolver for the deploymentHistory field.
+func (r *queryResolver) DeploymentHistory(ctx context.Context, assetID *string, projectID *string, platform *model.CampaignPlatform, status *model.DeploymentStatus, first int, after *string, since *time.Time, until *time.Time) (*model.DeploymentConnection, error) {
+	return r.deploymentHistory(ctx, assetID, projectID, platform, status, first, after, since, until)
+}
+
+// ApproveAsset is the resolver for the approveAsset field.
+func (r *mutationResolver) ApproveAsset(ctx context.Context, assetID string, dryRun *bool) (*model.Asset, error) {
+	if dryRun != nil && *dryRun {
+		return r.dryRunApproveAsset(ctx, assetID)
+	}
+
+	tx, authUser, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var currentStatus model.AssetStatus
+	err = tx.QueryRowContext(ctx, `SELECT status FROM assets WHERE id = $1 AND deleted_at IS NULL`, assetID).Scan(&currentStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("asset not found")
+		}
+		return nil, fmt.Errorf("failed to query asset: %w", err)
+	}
+
+	if err := assetStatusMachine.ValidateTransition(currentStatus, model.AssetStatusApproved); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE assets 
+		SET status = $1, approved_by = $2, approved_at = $3, updated_at = $4
+		WHERE id = $5 AND status = $6
+	`, model.AssetStatusApproved, authUser.ID, now, now, assetID, currentStatus)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve asset: %w", err)
+	}
+
+	// Guard against another request changing the status since it was read
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		return nil, fmt.Errorf("asset status changed concurrently, please retry")
+	}
+
+	// Get updated asset
+	var asset model.Asset
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, name, type, url, status, board_id, approved_by, approved_at, platform_rejection_reason, rejection_reason, duplicate_of, scheduled_at, thumbnail_url, created_at, updated_at
+		FROM assets WHERE id = $1
+	`, assetID).Scan(
+		&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
+		&asset.BoardID, &asset.ApprovedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+		&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query updated asset: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit asset approval: %w", err)
+	}
+
+	r.audit(ctx, "approveAsset", "asset", asset.ID, map[string]interface{}{"status": currentStatus}, &asset)
+	r.dispatchBoardEvent(&webhook.BoardPayload{
+		Event:     webhook.EventAssetApproved,
+		BoardID:   asset.BoardID,
+		AssetID:   asset.ID,
+		Status:    string(asset.Status),
+		Timestamp: now,
+	})
+
+	// Publish board update
+	err = r.NatsConn.PublishBoardUpdate(asset.BoardID, &asset)
+	if err != nil {
+		log.Printf("Failed to publish board update: %v", err)
+	}
+	if err := r.NatsConn.PublishAssetUpdate(asset.BoardID, &asset); err != nil {
+		log.Printf("Failed to publish asset update: %v", err)
+	}
+
+	return &asset, nil
+}
+
+// ApproveAssets is the resolver for the approveAssets field.
+func (r *mutationResolver) ApproveAssets(ctx context.Context, ids []string) ([]model.AssetApprovalResult, error) {
+	ids = uniqueIDs(ids)
+	if len(ids) > maxApprovalBatch {
+		return nil, fmt.Errorf("at most %d assets can be approved at once", maxApprovalBatch)
+	}
+
+	tx, authUser, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Assets of projects the user may not approve in are reported, not approved
+	failures := make(map[string]model.AssetApprovalResult)
+	var approvable []string
+	for _, id := range ids {
+		if err := r.authorizeAsset(ctx, id, ActionApprove); err != nil {
+			failures[id] = &model.ApprovalError{AssetID: id, Reason: err.Error()}
+			continue
+		}
+		approvable = append(approvable, id)
+	}
+
+	outcomes, err := approveAssets(ctx, tx, approvable, authUser.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit asset approvals: %w", err)
+	}
+
+	results := make([]model.AssetApprovalResult, 0, len(ids))
+	var approvedIDs []string
+	for _, id := range ids {
+		result, failed := failures[id]
+		if !failed {
+			result = outcomes[id]
+		}
+		results = append(results, result)
+
+		asset, approved := result.(*model.Asset)
+		if !approved {
+			continue
+		}
+		approvedIDs = append(approvedIDs, asset.ID)
+		r.audit(ctx, "approveAssets", "asset", asset.ID, nil, asset)
+		r.dispatchBoardEvent(&webhook.BoardPayload{
+			Event:     webhook.EventAssetApproved,
+			BoardID:   asset.BoardID,
+			AssetID:   asset.ID,
+			Status:    string(asset.Status),
+			Timestamp: *asset.ApprovedAt,
+		})
+
+		if err := r.NatsConn.PublishBoardUpdate(asset.BoardID, asset); err != nil {
+			log.Printf("Failed to publish board update: %v", err)
+		}
+		if err := r.NatsConn.PublishAssetUpdate(asset.BoardID, asset); err != nil {
+			log.Printf("Failed to publish asset update: %v", err)
+		}
+	}
+
+	if r.Cache != nil {
+		for _, id := range approvedIDs {
+			r.Cache.InvalidateAsset(id)
+		}
+	}
+
+	if len(approvedIDs) > 0 {
+		err = r.NatsConn.PublishAssetsApproved(&nats.AssetsApprovedEvent{
+			EventType:  "asset.approved_batch",
+			AssetIDs:   approvedIDs,
+			ApprovedBy: authUser.ID,
+			Timestamp:  time.Now(),
+		})
+		if err != nil {
+			log.Printf("Failed to publish approved assets: %v", err)
+		}
+	}
+
+	return results, nil
+}
+
+// SubmitAssetForReview is the resolver for the submitAssetForReview field.
+func (r *mutationResolver) SubmitAssetForReview(ctx context.Context, assetID string) (*model.Asset, error) {
+	asset, previous, err := r.transitionAsset(ctx, assetID, model.AssetStatusReview, "")
+	if err != nil {
+		return nil, err
+	}
+
+	r.audit(ctx, "submitAssetForReview", "asset", asset.ID, map[string]interface{}{"status": previous}, map[string]interface{}{"status": asset.Status})
+
+	return asset, nil
+}
+
+// RejectAsset is the resolver for the rejectAsset field.
+func (r *mutationResolver) RejectAsset(ctx context.Context, assetID string, reason string) (*model.Asset, error) {
+	reason = strings.TrimSpace(reason)
+	if err := validateRejectionReason(reason); err != nil {
+		return nil, err
+	}
+
+	asset, previous, err := r.transitionAsset(ctx, assetID, model.AssetStatusRejected, "rejection_reason = $4", reason)
+	if err != nil {
+		return nil, err
+	}
+
+	r.audit(ctx, "rejectAsset", "asset", asset.ID,
+		map[string]interface{}{"status": previous},
+		map[string]interface{}{"status": asset.Status, "reason": reason})
+
+	return asset, nil
+}
+
+// RecallAsset is the resolver for the recallAsset field.
+func (r *mutationResolver) RecallAsset(ctx context.Context, assetID string) (*model.Asset, error) {
+	asset, previous, err := r.transitionAsset(ctx, assetID, model.AssetStatusPending, "approved_by = NULL, approved_at = NULL")
+	if err != nil {
+		return nil, err
+	}
+
+	r.audit(ctx, "recallAsset", "asset", asset.ID, map[string]interface{}{"status": previous}, asset)
+
+	return asset, nil
+}
+
+// Chat is the resolver for the chat field.
+func (r *mutationResolver) Chat(ctx context.Context, boardID string, content string) (*model.ChatMessage, error) {
+	tx, authUser, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	message := model.ChatMessage{
+		ID:        uuid.New().String(),
+		Content:   content,
+		UserID:    authUser.ID,
+		BoardID:   boardID,
+		CreatedAt: time.Now(),
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO chat_messages (id, content, user_id, board_id, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, message.ID, message.Content, message.UserID, message.BoardID, message.CreatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chat message: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit chat message: %w", err)
+	}
+
+	r.dispatchBoardEvent(&webhook.BoardPayload{
+		Event:     webhook.EventMessageSent,
+		BoardID:   message.BoardID,
+		MessageID: message.ID,
+		Timestamp: message.CreatedAt,
+	})
+
+	// Publish board update
+	err = r.NatsConn.PublishBoardUpdate(boardID, &message)
+	if err != nil {
+		log.Printf("Failed to publish board update: %v", err)
+	}
+
+	return &message, nil
+}
+
+// ReplyToMessage is the resolver for the replyToMessage field.
+func (r *mutationResolver) ReplyToMessage(ctx context.Context, parentMessageID string, content string) (*model.ChatMessage, error) {
+	tx, authUser, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	message := model.ChatMessage{
+		ID:        uuid.New().String(),
+		Content:   content,
+		UserID:    authUser.ID,
+		ParentID:  &parentMessageID,
+		CreatedAt: time.Now(),
+	}
+
+	// The reply is posted on the board of its parent, which must be visible to
+	// the user
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO chat_messages (id, content, user_id, board_id, parent_id, created_at)
+		SELECT $1, $2, $3, board_id, id, $5
+		FROM chat_messages WHERE id = $4
+		RETURNING board_id
+	`, message.ID, message.Content, message.UserID, parentMessageID, message.CreatedAt).Scan(&message.BoardID)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("parent message not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to create chat message: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit chat message: %w", err)
+	}
+
+	r.dispatchBoardEvent(&webhook.BoardPayload{
+		Event:     webhook.EventMessageSent,
+		BoardID:   message.BoardID,
+		MessageID: message.ID,
+		Timestamp: message.CreatedAt,
+	})
+
+	// Publish board update
+	err = r.NatsConn.PublishBoardUpdate(message.BoardID, &message)
+	if err != nil {
+		log.Printf("Failed to publish board update: %v", err)
+	}
+
+	return &message, nil
+}
+
+// ReadAt is the resolver for the readAt field.
+func (r *mutationResolver) ReadAt(ctx context.Context, messageIds []string) (bool, error) {
+	if len(messageIds) == 0 {
+		return false, fmt.Errorf("at least one message ID is required")
+	}
+
+	tx, authUser, err := r.userTx(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Only messages visible to the user can be marked; the first read is kept
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO chat_message_reads (message_id, user_id, read_at)
+		SELECT id, $2, NOW() FROM chat_messages WHERE id = ANY($1::uuid[])
+		ON CONFLICT (message_id, user_id) DO NOTHING
+	`, pq.Array(messageIds), authUser.ID)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to mark chat messages read: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit chat message reads: %w", err)
+	}
+
+	return true, nil
+}
+
+// CreateProject is the resolver for the createProject field.
+func (r *mutationResolver) CreateProject(ctx context.Context, input model.CreateProjectInput) (*model.Project, error) {
+	tx, authUser, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	project, err := insertProject(ctx, tx, authUser, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit project: %w", err)
+	}
+
+	r.audit(ctx, "createProject", "project", project.ID, nil, project)
+
+	return project, nil
+}
+
+// CreateProjectFromTemplate is the resolver for the createProjectFromTemplate field.
+func (r *mutationResolver) CreateProjectFromTemplate(ctx context.Context, templateID string, projectName string) (*model.Project, error) {
+	projectName = strings.TrimSpace(projectName)
+	if projectName == "" {
+		return nil, fmt.Errorf("project name is required")
+	}
+
+	tx, authUser, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	template, err := scanBoardTemplate(tx.QueryRowContext(ctx, `
+		SELECT id, name, description, default_boards, created_by, is_public, created_at
+		FROM board_templates
+		WHERE id = $1
+	`, templateID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("board template not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query board template: %w", err)
+	}
+
+	project, err := insertProject(ctx, tx, authUser, model.CreateProjectInput{Name: projectName})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, board := range template.Preview {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO boards (id, name, description, project_id, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $5)
+		`, uuid.New().String(), board.Name, board.Description, project.ID, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create board: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit project: %w", err)
+	}
+
+	r.audit(ctx, "createProjectFromTemplate", "project", project.ID, nil, project)
+
+	return project, nil
+}
+
+// SaveAsTemplate is the resolver for the saveAsTemplate field.
+func (r *mutationResolver) SaveAsTemplate(ctx context.Context, projectID string, templateName string) (*model.BoardTemplate, error) {
+	templateName = strings.TrimSpace(templateName)
+	if templateName == "" {
+		return nil, fmt.Errorf("template name is required")
+	}
+
+	tx, authUser, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var description sql.NullString
+	err = tx.QueryRowContext(ctx, `SELECT description FROM projects WHERE id = $1`, projectID).Scan(&description)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("project not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query project: %w", err)
+	}
+
+	boards, err := templateBoards(ctx, tx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	boardsJSON, err := json.Marshal(boards)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode template boards: %w", err)
+	}
+
+	template, err := scanBoardTemplate(tx.QueryRowContext(ctx, `
+		INSERT INTO board_templates (name, description, default_boards, created_by, is_public)
+		VALUES ($1, $2, $3::jsonb, $4, FALSE)
+		RETURNING id, name, description, default_boards, created_by, is_public, created_at
+	`, templateName, description.String, string(boardsJSON), authUser.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create board template: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit board template: %w", err)
+	}
+
+	r.audit(ctx, "saveAsTemplate", "board_template", template.ID, nil, template)
+
+	return template, nil
+}
+
+// CreateBoard is the resolver for the createBoard field.
+func (r *mutationResolver) CreateBoard(ctx context.Context, input model.CreateBoardInput) (*model.Board, error) {
+	tx, _, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	board := model.Board{
+		ID:          uuid.New().String(),
+		Name:        input.Name,
+		Description: input.Description,
+		ProjectID:   input.ProjectID,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO boards (id, name, description, project_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, board.ID, board.Name, board.Description, board.ProjectID,
+		board.CreatedAt, board.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create board: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit board: %w", err)
+	}
+
+	r.audit(ctx, "createBoard", "board", board.ID, nil, &board)
+
+	return &board, nil
+}
+
+// UploadAsset is the resolver for the uploadAsset field.
+func (r *mutationResolver) UploadAsset(ctx context.Context, input model.UploadAssetInput) (*model.Asset, error) {
+	if ctx.Value("user") == nil {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	if input.VariantGroup != nil {
+		if err := validateVariantGroup(*input.VariantGroup); err != nil {
+			return nil, err
+		}
+	}
+
+	asset := model.Asset{
+		ID:        uuid.New().String(),
+		Name:      input.Name,
+		Type:      input.Type,
+		URL:       &input.URL,
+		Status:    model.AssetStatusPending,
+		BoardID:   input.BoardID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	tx, _, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// A file already hashed in the project returns the existing asset
+	existing, contentHash, err := findDuplicateAsset(ctx, tx, input.BoardID, input.URL)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		addDuplicateWarning(ctx, DuplicateWarning{
+			Message:     "an asset with the same content already exists in this project",
+			AssetID:     existing.ID,
+			BoardID:     existing.BoardID,
+			ContentHash: contentHash,
+		})
+		return existing, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO assets (id, name, type, url, status, board_id, variant_group, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, asset.ID, asset.Name, asset.Type, asset.URL, asset.Status,
+		asset.BoardID, input.VariantGroup, asset.CreatedAt, asset.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create asset: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit asset: %w", err)
+	}
+
+	r.audit(ctx, "uploadAsset", "asset", asset.ID, nil, &asset)
+	r.dispatchBoardEvent(&webhook.BoardPayload{
+		Event:     webhook.EventAssetUploaded,
+		BoardID:   asset.BoardID,
+		AssetID:   asset.ID,
+		Status:    string(asset.Status),
+		Timestamp: asset.CreatedAt,
+	})
+
+	r.queueContentHash(nats.AssetHashJob{AssetID: asset.ID, URL: input.URL})
+	if asset.Type == model.AssetTypeImage {
+		r.generateThumbnail(asset)
+	}
+	r.scoreQuality(asset)
+	r.ReindexAsset(asset.ID)
+
+	// Publish board update
+	err = r.NatsConn.PublishBoardUpdate(input.BoardID, &asset)
+	if err != nil {
+		log.Printf("Failed to publish board update: %v", err)
+	}
+	if err := r.NatsConn.PublishAssetUpdate(input.BoardID, &asset); err != nil {
+		log.Printf("Failed to publish asset update: %v", err)
+	}
+
+	return &asset, nil
+}
+
+// DuplicateMetaCampaign is the resolver for the duplicateMetaCampaign field.
+func (r *mutationResolver) DuplicateMetaCampaign(ctx context.Context, assetID string, newName string, newBudget float64) (string, error) {
+	if ctx.Value("user") == nil {
+		return "", fmt.Errorf("unauthorized")
+	}
+
+	if newBudget <= 0 {
+		return "", fmt.Errorf("new budget must be positive")
+	}
+
+	tx, _, err := r.userTx(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	// The project owner is the tenant whose platform credentials are used. The
+	// source is the latest Meta campaign the connectors service deployed the
+	// asset to.
+	var sourceCampaignID sql.NullString
+	var tenantID string
+	err = tx.QueryRowContext(ctx, `
+		SELECT (
+			SELECT cd.platform_campaign_id FROM campaign_deployments cd
+			WHERE cd.asset_id = a.id AND cd.platform = 'meta'
+			ORDER BY cd.deployed_at DESC
+			LIMIT 1
+		), p.owner_id
+		FROM assets a
+		JOIN boards b ON a.board_id = b.id
+		JOIN projects p ON b.project_id = p.id
+		WHERE a.id = $1 AND a.deleted_at IS NULL
+	`, assetID).Scan(&sourceCampaignID, &tenantID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("asset not found")
+		}
+		return "", fmt.Errorf("failed to query asset: %w", err)
+	}
+
+	if !sourceCampaignID.Valid || sourceCampaignID.String == "" {
+		return "", fmt.Errorf("asset has no deployed Meta campaign")
+	}
+
+	campaignID, err := r.NatsConn.RequestCampaignDuplication(ctx, nats.CampaignDuplicationRequest{
+		AssetID:          assetID,
+		TenantID:         tenantID,
+		Platform:         "meta",
+		SourceCampaignID: sourceCampaignID.String,
+		NewName:          newName,
+		NewBudget:        newBudget,
+	}, 60*time.Second)
+	if err != nil {
+		return "", fmt.Errorf("failed to duplicate campaign: %w", err)
+	}
+
+	return campaignID, nil
+}
+
+// SubmitBoardOperation is the resolver for the submitBoardOperation field.
+func (r *mutationResolver) SubmitBoardOperation(ctx context.Context, boardID string, op model.BoardOperation) (*model.BoardOperation, error) {
+	if err := validateBoardOperation(op); err != nil {
+		return nil, err
+	}
+
+	tx, _, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if r.BoardOperations == nil {
+		return nil, fmt.Errorf("board collaboration is not available")
+	}
+
+	// Locking the board row serializes operations on the same board
+	var description sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		SELECT description FROM boards WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+	`, boardID).Scan(&description)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("board not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query board: %w", err)
+	}
+
+	version, pending, err := r.BoardOperations.Since(ctx, boardID, op.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	op.BoardID = boardID
+	op = transformOperations(op, pending)
+
+	updated, err := applyOperation(description.String, op)
+	if err != nil {
+		return nil, err
+	}
+	op.Version = version + 1
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE boards SET description = $1 WHERE id = $2
+	`, updated, boardID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to update board: %w", err)
+	}
+
+	// The operation is recorded while the board row is locked, so the next
+	// operation on the board is transformed against it
+	if err := r.BoardOperations.Append(ctx, op); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		if undoErr := r.BoardOperations.Undo(ctx, op); undoErr != nil {
+			log.Printf("Failed to undo board operation: %v", undoErr)
+		}
+		return nil, fmt.Errorf("failed to commit board operation: %w", err)
+	}
+
+	// Publish board update
+	err = r.NatsConn.PublishBoardUpdate(boardID, &op)
+	if err != nil {
+		log.Printf("Failed to publish board update: %v", err)
+	}
+
+	return &op, nil
+}
+
+// ExportBoard is the resolver for the exportBoard field.
+func (r *mutationResolver) ExportBoard(ctx context.Context, boardID string) (*model.ExportResult, error) {
+	tx, _, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if r.BoardExports == nil {
+		return nil, fmt.Errorf("board export is not available")
+	}
+
+	var board model.Board
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, name, description, project_id, created_at, updated_at
+		FROM boards WHERE id = $1 AND deleted_at IS NULL
+	`, boardID).Scan(
+		&board.ID, &board.Name, &board.Description, &board.ProjectID,
+		&board.CreatedAt, &board.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("board not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query board: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, name, type, url, status, board_id, approved_by, approved_at, platform_rejection_reason, rejection_reason, duplicate_of, scheduled_at, thumbnail_url, created_at, updated_at
+		FROM assets WHERE board_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query assets: %w", err)
+	}
+	defer rows.Close()
+
+	var assets []*model.Asset
+	for rows.Next() {
+		var asset model.Asset
+		var approvedBy sql.NullString
+		err := rows.Scan(
+			&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
+			&asset.BoardID, &approvedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+			&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan asset: %w", err)
+		}
+		if approvedBy.Valid {
+			asset.ApprovedBy = &model.User{ID: approvedBy.String}
+		}
+		assets = append(assets, &asset)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query assets: %w", err)
+	}
+
+	archive, err := export.BuildBoardArchive(&board, assets, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build board archive: %w", err)
+	}
+
+	url, expiresAt, err := r.BoardExports.Save(ctx, board.ID, archive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save board export: %w", err)
+	}
+
+	return &model.ExportResult{
+		URL:       url,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// StorePlatformCredentials is the resolver for the storePlatformCredentials field.
+func (r *mutationResolver) StorePlatformCredentials(ctx context.Context, tenantID string, platform model.CampaignPlatform, credentials model.PlatformCredentialsInput) (bool, error) {
+	user := ctx.Value("user")
+	if user == nil {
+		return false, fmt.Errorf("unauthorized")
+	}
+
+	authUser, ok := user.(*auth.User)
+	if !ok {
+		return false, fmt.Errorf("invalid user context")
+	}
+
+	if authUser.Role != "admin" {
+		return false, fmt.Errorf("admin access required")
+	}
+
+	connectorPlatform, ok := connectorPlatforms[platform]
+	if !ok {
+		return false, fmt.Errorf("unsupported platform: %s", platform)
+	}
+
+	err := r.NatsConn.RequestStorePlatformCredentials(ctx, nats.PlatformCredentialsRequest{
+		TenantID:    tenantID,
+		Platform:    connectorPlatform,
+		Credentials: toNatsCredentials(credentials),
+	}, 10*time.Second)
+	if err != nil {
+		return false, fmt.Errorf("failed to store platform credentials: %w", err)
+	}
+
+	return true, nil
+}
+
+// UpdatePreferences is the resolver for the updatePreferences field.
+func (r *mutationResolver) UpdatePreferences(ctx context.Context, preferences map[string]interface{}) (map[string]interface{}, error) {
+	if ctx.Value("user") == nil {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	if err := validatePreferences(preferences); err != nil {
+		return nil, err
+	}
+
+	tx, authUser, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	set, cleared := splitPreferenceUpdate(preferences)
+	setJSON, err := json.Marshal(set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode preferences: %w", err)
+	}
+
+	var raw []byte
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO user_preferences (user_id, preferences)
+		VALUES ($1, $2::jsonb)
+		ON CONFLICT (user_id) DO UPDATE
+		SET preferences = (user_preferences.preferences || EXCLUDED.preferences) - $3::text[]
+		RETURNING preferences
+	`, authUser.ID, string(setJSON), pq.Array(cleared)).Scan(&raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update preferences: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit preferences: %w", err)
+	}
+
+	if r.Cache != nil {
+		r.Cache.InvalidatePreferences(authUser.ID)
+	}
+
+	merged := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &merged); err != nil {
+		return nil, fmt.Errorf("failed to decode preferences: %w", err)
+	}
+
+	return merged, nil
+}
+
+// CreateDeploymentTemplate is the resolver for the createDeploymentTemplate field.
+func (r *mutationResolver) CreateDeploymentTemplate(ctx context.Context, name string, metadata model.DeploymentMetadata) (*model.DeploymentTemplate, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("template name is required")
+	}
+
+	if err := validateDeploymentMetadata(metadata); err != nil {
+		return nil, err
+	}
+	hashCustomAudienceEmails(metadata.Demographics)
+
+	tx, authUser, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode template metadata: %w", err)
+	}
+
+	template, err := scanDeploymentTemplate(tx.QueryRowContext(ctx, `
+		INSERT INTO deployment_templates (name, owner_id, platform, content_type, template_metadata)
+		VALUES ($1, $2, $3, $4, $5::jsonb)
+		RETURNING id, name, owner_id, platform, content_type, template_metadata, created_at
+	`, name, authUser.ID, metadata.Platform, metadata.ContentType, string(metadataJSON)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create deployment template: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit deployment template: %w", err)
+	}
+
+	return template, nil
+}
+
+// DeployAssetFromTemplate is the resolver for the deployAssetFromTemplate field.
+func (r *mutationResolver) DeployAssetFromTemplate(ctx context.Context, assetID string, templateID string) (*model.DeploymentResult, error) {
+	tx, _, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	template, err := scanDeploymentTemplate(tx.QueryRowContext(ctx, `
+		SELECT id, name, owner_id, platform, content_type, template_metadata, created_at
+		FROM deployment_templates
+		WHERE id = $1
+	`, templateID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("deployment template not found")
+		}
+		return nil, fmt.Errorf("failed to query deployment template: %w", err)
+	}
+
+	// The project owner is the tenant whose platform credentials are used
+	var asset model.Asset
+	var url sql.NullString
+	var projectID, tenantID string
+	err = tx.QueryRowContext(ctx, `
+		SELECT a.name, a.type, a.url, a.status, p.id, p.owner_id
+		FROM assets a
+		JOIN boards b ON a.board_id = b.id
+		JOIN projects p ON b.project_id = p.id
+		WHERE a.id = $1 AND a.deleted_at IS NULL
+	`, assetID).Scan(&asset.Name, &asset.Type, &url, &asset.Status, &projectID, &tenantID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("asset not found")
+		}
+		return nil, fmt.Errorf("failed to query asset: %w", err)
+	}
+
+	if err := assetStatusMachine.ValidateTransition(asset.Status, model.AssetStatusDeployed); err != nil {
+		return nil, fmt.Errorf("asset must be approved before it is deployed: %w", err)
+	}
+
+	// Nothing is written, so release the connection before waiting on the deployment
+	tx.Rollback()
+
+	assetMetadata, err := json.Marshal(assetDeploymentMetadata(asset.Type, url.String))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode asset metadata: %w", err)
+	}
+
+	natsTemplate, err := toNatsDeploymentTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.NatsConn.RequestTemplateDeployment(ctx, nats.TemplateDeploymentRequest{
+		Asset: nats.DeploymentAsset{
+			AssetID:   assetID,
+			ProjectID: projectID,
+			TenantID:  tenantID,
+			Status:    strings.ToLower(string(asset.Status)),
+			Title:     asset.Name,
+			Metadata:  assetMetadata,
+		},
+		Template: natsTemplate,
+	}, 60*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deploy asset: %w", err)
+	}
+
+	return fromNatsDeploymentResult(result, assetID, template.Platform), nil
+}
+
+// CreateCampaignSchedule is the resolver for the createCampaignSchedule field.
+func (r *mutationResolver) CreateCampaignSchedule(ctx context.Context, input model.CreateCampaignScheduleInput) (*model.CampaignSchedule, error) {
+	user := ctx.Value("user")
+	if user == nil {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	authUser, ok := user.(*auth.User)
+	if !ok {
+		return nil, fmt.Errorf("invalid user context")
+	}
+
+	connectorPlatform, ok := connectorPlatforms[input.Platform]
+	if !ok {
+		return nil, fmt.Errorf("unsupported platform: %s", input.Platform)
+	}
+
+	// The campaign runs on the user's own platform credentials
+	schedule, err := r.NatsConn.RequestCreateCampaignSchedule(ctx, nats.CampaignScheduleRequest{
+		TenantID:           authUser.ID,
+		Platform:           connectorPlatform,
+		PlatformCampaignID: input.PlatformCampaignID,
+		PauseCron:          input.PauseCron,
+		ResumeCron:         input.ResumeCron,
+		Timezone:           input.Timezone,
+	}, 30*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create campaign schedule: %w", err)
+	}
+
+	return fromNatsCampaignSchedule(schedule, input.Platform), nil
+}
+
+// DeleteCampaignSchedule is the resolver for the deleteCampaignSchedule field.
+func (r *mutationResolver) DeleteCampaignSchedule(ctx context.Context, id string) (bool, error) {
+	user := ctx.Value("user")
+	if user == nil {
+		return false, fmt.Errorf("unauthorized")
+	}
+
+	authUser, ok := user.(*auth.User)
+	if !ok {
+		return false, fmt.Errorf("invalid user context")
+	}
+
+	err := r.NatsConn.RequestDeleteCampaignSchedule(ctx, nats.CampaignScheduleDeletionRequest{
+		ID:       id,
+		TenantID: authUser.ID,
+	}, 10*time.Second)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete campaign schedule: %w", err)
+	}
+
+	return true, nil
+}
+
+// ScheduleDeployment is the resolver for the scheduleDeployment field.
+func (r *mutationResolver) ScheduleDeployment(ctx context.Context, assetID string, scheduledAt time.Time) (*model.Asset, error) {
+	if err := validateDeploymentSchedule(scheduledAt, time.Now()); err != nil {
+		return nil, err
+	}
+
+	tx, _, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var currentStatus model.AssetStatus
+	err = tx.QueryRowContext(ctx, `SELECT status FROM assets WHERE id = $1 AND deleted_at IS NULL`, assetID).Scan(&currentStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("asset not found")
+		}
+		return nil, fmt.Errorf("failed to query asset: %w", err)
+	}
+
+	if err := validateSchedulableStatus(currentStatus); err != nil {
+		return nil, err
+	}
+
+	var asset model.Asset
+	var approvedBy sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		UPDATE assets
+		SET scheduled_at = $1, schedule_published_at = NULL, updated_at = $2
+		WHERE id = $3 AND status = $4
+		RETURNING id, name, type, url, status, board_id, approved_by, approved_at, platform_rejection_reason, rejection_reason, duplicate_of, scheduled_at, thumbnail_url, created_at, updated_at
+	`, scheduledAt.UTC(), time.Now(), assetID, currentStatus).Scan(
+		&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
+		&asset.BoardID, &approvedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+		&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		// Guard against another request changing the status since it was read
+		return nil, fmt.Errorf("asset status changed concurrently, please retry")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to schedule deployment: %w", err)
+	}
+	if approvedBy.Valid {
+		asset.ApprovedBy = &model.User{ID: approvedBy.String}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit deployment schedule: %w", err)
+	}
+
+	if err := r.NatsConn.PublishBoardUpdate(asset.BoardID, &asset); err != nil {
+		log.Printf("Failed to publish board update: %v", err)
+	}
+
+	return &asset, nil
+}
+
+// DeleteProject is the resolver for the deleteProject field.
+func (r *mutationResolver) DeleteProject(ctx context.Context, id string) (bool, error) {
+	tx, _, err := r.userTx(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if err := softDeleteProject(ctx, tx, id, now); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit project deletion: %w", err)
+	}
+
+	r.audit(ctx, "deleteProject", "project", id, nil, map[string]interface{}{"deletedAt": now})
+
+	if r.Cache != nil {
+		r.Cache.InvalidateProject(id)
+	}
+
+	return true, nil
+}
+
+// DeleteBoard is the resolver for the deleteBoard field.
+func (r *mutationResolver) DeleteBoard(ctx context.Context, id string) (bool, error) {
+	tx, _, err := r.userTx(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if err := softDeleteBoard(ctx, tx, id, now); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit board deletion: %w", err)
+	}
+
+	r.audit(ctx, "deleteBoard", "board", id, nil, map[string]interface{}{"deletedAt": now})
+
+	if r.Cache != nil {
+		r.Cache.InvalidateBoard(id)
+	}
+
+	return true, nil
+}
+
+// DeleteAsset is the resolver for the deleteAsset field.
+func (r *mutationResolver) DeleteAsset(ctx context.Context, id string) (bool, error) {
+	tx, _, err := r.userTx(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if err := softDeleteAsset(ctx, tx, id, now); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit asset deletion: %w", err)
+	}
+
+	r.audit(ctx, "deleteAsset", "asset", id, nil, map[string]interface{}{"deletedAt": now})
+
+	if r.Cache != nil {
+		r.Cache.InvalidateAsset(id)
+	}
+	r.ReindexAsset(id)
+
+	return true, nil
+}
+
+// RestoreAsset is the resolver for the restoreAsset field.
+func (r *mutationResolver) RestoreAsset(ctx context.Context, id string) (*model.Asset, error) {
+	tx, _, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	asset, err := restoreDeletedAsset(ctx, tx, id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit asset restore: %w", err)
+	}
+
+	r.audit(ctx, "restoreAsset", "asset", id, nil, asset)
+
+	if r.Cache != nil {
+		r.Cache.InvalidateAsset(id)
+	}
+	r.ReindexAsset(id)
+
+	if err := r.NatsConn.PublishBoardUpdate(asset.BoardID, asset); err != nil {
+		log.Printf("Failed to publish board update: %v", err)
+	}
+
+	return asset, nil
+}
+
+// RevertAsset is the resolver for the revertAsset field.
+func (r *mutationResolver) RevertAsset(ctx context.Context, assetID string, toVersion int) (*model.Asset, error) {
+	tx, _, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, asset, err := revertAsset(ctx, tx, assetID, toVersion, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// Restoring an approval is approving the asset
+	approved := asset.Status == model.AssetStatusApproved && before.Status != model.AssetStatusApproved
+	if approved {
+		if _, err := authorizeRole(ctx, ActionApprove); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit asset revert: %w", err)
+	}
+
+	r.audit(ctx, "revertAsset", "asset", assetID, before, asset)
+
+	if r.Cache != nil {
+		r.Cache.InvalidateAsset(assetID)
+	}
+	r.ReindexAsset(assetID)
+
+	if err := r.NatsConn.PublishBoardUpdate(asset.BoardID, asset); err != nil {
+		log.Printf("Failed to publish board update: %v", err)
+	}
+
+	if approved {
+		r.dispatchBoardEvent(&webhook.BoardPayload{
+			Event:     webhook.EventAssetApproved,
+			BoardID:   asset.BoardID,
+			AssetID:   asset.ID,
+			Status:    string(asset.Status),
+			Timestamp: asset.UpdatedAt,
+		})
+		if err := r.NatsConn.PublishAssetUpdate(asset.BoardID, asset); err != nil {
+			log.Printf("Failed to publish asset update: %v", err)
+		}
+		if err := r.publishRestoredApproval(ctx, asset, before.Status); err != nil {
+			log.Printf("Failed to publish approval of reverted asset %s: %v", asset.ID, err)
+		}
+	}
+
+	return asset, nil
+}
+
+// PurgeDeleted is the resolver for the purgeDeleted field.
+func (r *mutationResolver) PurgeDeleted(ctx context.Context, olderThan time.Time) (int, error) {
+	authUser, ok := ctx.Value("user").(*auth.User)
+	if !ok {
+		return 0, fmt.Errorf("unauthorized")
+	}
+
+	if authUser.Role != "admin" {
+		return 0, fmt.Errorf("admin access required")
+	}
+
+	return r.purgeDeleted(ctx, olderThan)
+}
+
+// RollbackDeployment is the resolver for the rollbackDeployment field.
+func (r *mutationResolver) RollbackDeployment(ctx context.Context, assetID string, platform model.CampaignPlatform, reason *string) (*model.DeploymentRollbackResult, error) {
+	authUser, ok := ctx.Value("user").(*auth.User)
+	if !ok {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	if authUser.Role != "admin" {
+		return nil, fmt.Errorf("admin access required")
+	}
+
+	return r.rollbackDeployment(ctx, authUser.ID, assetID, platform, reason)
+}
+
+// DeleteCustomAudience is the resolver for the deleteCustomAudience field.
+func (r *mutationResolver) DeleteCustomAudience(ctx context.Context, assetID string) (bool, error) {
+	authUser, ok := ctx.Value("user").(*auth.User)
+	if !ok {
+		return false, fmt.Errorf("unauthorized")
+	}
+
+	if authUser.Role != "admin" {
+		return false, fmt.Errorf("admin access required")
+	}
+
+	return r.deleteCustomAudience(ctx, authUser.ID, assetID)
+}
+
+// ImpersonateUser is the resolver for the impersonateUser field.
+func (r *mutationResolver) ImpersonateUser(ctx context.Context, userID string) (*model.TokenPair, error) {
+	authUser, ok := ctx.Value("user").(*auth.User)
+	if !ok {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	if authUser.Role != superAdminRole {
+		return nil, fmt.Errorf("super admin access required")
+	}
+
+	return r.impersonateUser(ctx, authUser, userID)
+}
+
+// EndImpersonation is the resolver for the endImpersonation field.
+func (r *mutationResolver) EndImpersonation(ctx context.Context) (*model.TokenPair, error) {
+	authUser, ok := ctx.Value("user").(*auth.User)
+	if !ok {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	return r.endImpersonation(ctx, authUser)
+}
+
+// CreateWebhook is the resolver for the createWebhook field.
+func (r *mutationResolver) CreateWebhook(ctx context.Context, input model.CreateWebhookInput) (*model.Webhook, error) {
+	events, err := validateWebhookInput(&input.URL, &input.Secret, input.Events)
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		return nil, fmt.Errorf("webhook must subscribe to at least one event")
+	}
+
+	tx, _, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1 AND deleted_at IS NULL)`, input.ProjectID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query project: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("project not found")
+	}
+
+	hook, err := scanWebhook(tx.QueryRowContext(ctx, `
+		INSERT INTO webhooks (project_id, url, secret, events)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+webhookColumns,
+		input.ProjectID, input.URL, input.Secret, pq.Array(events)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit webhook: %w", err)
+	}
+
+	return hook, nil
+}
+
+// UpdateWebhook is the resolver for the updateWebhook field.
+func (r *mutationResolver) UpdateWebhook(ctx context.Context, id string, input model.UpdateWebhookInput) (*model.Webhook, error) {
+	events, err := validateWebhookInput(input.URL, input.Secret, input.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, _, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Fields that are not given are NULL and keep their value
+	hook, err := scanWebhook(tx.QueryRowContext(ctx, `
+		UPDATE webhooks
+		SET url = COALESCE($2::text, url),
+			secret = COALESCE($3::text, secret),
+			events = COALESCE($4::text[], events),
+			active = COALESCE($5::boolean, active)
+		WHERE id = $1
+		RETURNING `+webhookColumns,
+		id, input.URL, input.Secret, pq.Array(events), input.Active))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("webhook not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit webhook: %w", err)
+	}
+
+	return hook, nil
+}
+
+// DeleteWebhook is the resolver for the deleteWebhook field.
+func (r *mutationResolver) DeleteWebhook(ctx context.Context, id string) (bool, error) {
+	tx, _, err := r.userTx(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if deleted == 0 {
+		return false, fmt.Errorf("webhook not found")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit webhook deletion: %w", err)
+	}
+
+	return true, nil
+}
+
+// CreateBoardWebhook is the resolver for the createBoardWebhook field.
+func (r *mutationResolver) CreateBoardWebhook(ctx context.Context, boardID string, url string, events []model.BoardEventType, secret *string) (*model.BoardWebhook, error) {
+	return r.createBoardWebhook(ctx, boardID, url, events, secret)
+}
+
+// SetProjectBudgetLimit is the resolver for the setProjectBudgetLimit field.
+func (r *mutationResolver) SetProjectBudgetLimit(ctx context.Context, projectID string, budgetLimit *float64) (bool, error) {
+	return r.setProjectBudgetLimit(ctx, projectID, budgetLimit)
+}
+
+// CreateOrganization is the resolver for the createOrganization field.
+func (r *mutationResolver) CreateOrganization(ctx context.Context, input model.CreateOrganizationInput) (*model.Organization, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("organization name is required")
+	}
+	plan := defaultOrganizationPlan
+	if input.Plan != nil {
+		plan = *input.Plan
+	}
+
+	tx, authUser, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	organization := model.Organization{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Plan:      plan,
+		CreatedAt: time.Now(),
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO organizations (id, name, plan, created_at) VALUES ($1, $2, $3, $4)
+	`, organization.ID, organization.Name, organization.Plan, organization.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO organization_members (org_id, user_id, role) VALUES ($1, $2, $3)
+	`, organization.ID, authUser.ID, storedOrganizationRole(model.OrganizationRoleOwner))
+	if err != nil {
+		return nil, fmt.Errorf("failed to add organization owner: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit organization: %w", err)
+	}
+
+	r.audit(ctx, "createOrganization", "organization", organization.ID, nil, &organization)
+
+	return &organization, nil
+}
+
+// InviteMember is the resolver for the inviteMember field.
+func (r *mutationResolver) InviteMember(ctx context.Context, orgID string, email string, role model.OrganizationRole) (*model.OrganizationMember, error) {
+	tx, authUser, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	callerRole, err := lockOrganizationRole(ctx, tx, orgID, authUser.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeMemberChange(callerRole, "", role); err != nil {
+		return nil, err
+	}
+
+	var userID string
+	err = tx.QueryRowContext(ctx, `SELECT id FROM users WHERE email = $1`, strings.TrimSpace(email)).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query user: %w", err)
+	}
+
+	member, err := scanOrganizationMember(tx.QueryRowContext(ctx, `
+		WITH m AS (
+			INSERT INTO organization_members (org_id, user_id, role) VALUES ($1, $2, $3)
+			ON CONFLICT (org_id, user_id) DO NOTHING
+			RETURNING org_id, user_id, role, created_at
+		)
+		SELECT `+organizationMemberColumns+`
+		FROM m JOIN users u ON u.id = m.user_id
+	`, orgID, userID, storedOrganizationRole(role)))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user is already a member of the organization")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to add organization member: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit organization member: %w", err)
+	}
+
+	r.audit(ctx, "inviteMember", "organization", orgID, nil, member)
+
+	return member, nil
+}
+
+// RemoveMember is the resolver for the removeMember field.
+func (r *mutationResolver) RemoveMember(ctx context.Context, orgID string, userID string) (bool, error) {
+	tx, authUser, err := r.userTx(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	callerRole, err := lockOrganizationRole(ctx, tx, orgID, authUser.ID)
+	if err != nil {
+		return false, err
+	}
+	from, err := memberRole(ctx, tx, orgID, userID)
+	if err != nil {
+		return false, err
+	}
+	if err := authorizeMemberChange(callerRole, from, ""); err != nil {
+		return false, err
+	}
+	if err := checkOwnerRemains(ctx, tx, orgID, from, ""); err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM organization_members WHERE org_id = $1 AND user_id = $2`, orgID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove organization member: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit organization member removal: %w", err)
+	}
+
+	r.audit(ctx, "removeMember", "organization", orgID, map[string]interface{}{"userId": userID, "role": from}, nil)
+
+	return true, nil
+}
+
+// UpdateMemberRole is the resolver for the updateMemberRole field.
+func (r *mutationResolver) UpdateMemberRole(ctx context.Context, orgID string, userID string, role model.OrganizationRole) (*model.OrganizationMember, error) {
+	tx, authUser, err := r.userTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	callerRole, err := lockOrganizationRole(ctx, tx, orgID, authUser.ID)
+	if err != nil {
+		return nil, err
+	}
+	from, err := memberRole(ctx, tx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeMemberChange(callerRole, from, role); err != nil {
+		return nil, err
+	}
+	if err := checkOwnerRemains(ctx, tx, orgID, from, role); err != nil {
+		return nil, err
+	}
+
+	member, err := scanOrganizationMember(tx.QueryRowContext(ctx, `
+		WITH m AS (
+			UPDATE organization_members SET role = $3
+			WHERE org_id = $1 AND user_id = $2
+			RETURNING org_id, user_id, role, created_at
 		)
+		SELECT `+organizationMemberColumns+`
+		FROM m JOIN users u ON u.id = m.user_id
+	`, orgID, userID, storedOrganizationRole(role)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update member role: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit member role: %w", err)
+	}
+
+	r.audit(ctx, "updateMemberRole", "organization", orgID, map[string]interface{}{"userId": userID, "role": from}, member)
+
+	return member, nil
+}
+
+// BoardUpdated is the resolver for the boardUpdated field.
+func (r *subscriptionResolver) BoardUpdated(ctx context.Context, boardID string) (<-chan model.BoardUpdate, error) {
+	user := ctx.Value("user")
+	if user == nil {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	ch := make(chan model.BoardUpdate, 1)
+
+	// Subscribe to NATS updates
+	sub, err := r.NatsConn.SubscribeBoardUpdates(boardID, func(data []byte) {
+		var update model.BoardUpdate
+
+		// Every payload decodes as any of the update types, so tell them apart by
+		// their fields
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			log.Printf("Failed to unmarshal board update: %v", err)
+			return
+		}
+
+		switch {
+		case fields["offset"] != nil:
+			var op model.BoardOperation
+			if err := json.Unmarshal(data, &op); err != nil {
+				log.Printf("Failed to unmarshal board operation: %v", err)
+				return
+			}
+			update = op
+		case fields["content"] != nil:
+			var message model.ChatMessage
+			if err := json.Unmarshal(data, &message); err != nil {
+				log.Printf("Failed to unmarshal chat message: %v", err)
+				return
+			}
+			update = message
+		default:
+			var asset model.Asset
+			if err := json.Unmarshal(data, &asset); err != nil {
+				log.Printf("Failed to unmarshal asset: %v", err)
+				return
+			}
+			update = asset
+		}
+
+		select {
+		case ch <- update:
+		case <-ctx.Done():
+			return
+		}
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to board updates: %w", err)
+	}
+
+	// Clean up subscription when context is done
+	go func() {
+		<-ctx.Done()
+		sub.Unsubscribe()
+		close(ch)
+	}()
+
+	return ch, nil
+}
+
+// AssetStatusChanged is the resolver for the assetStatusChanged field.
+func (r *subscriptionResolver) AssetStatusChanged(ctx context.Context, boardID string, status []model.AssetStatus) (<-chan *model.Asset, error) {
+	if ctx.Value("user") == nil {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	ch := make(chan *model.Asset, 1)
+
+	sub, err := r.NatsConn.SubscribeAssetUpdates(boardID, subscriptionHandler(ctx, ch, "asset update", assetStatusFilter(status)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to asset updates: %w", err)
+	}
+
+	// Unsubscribe when the WebSocket closes. The channel is left open, as an update
+	// being handled may still be sent to it; gqlgen stops reading once ctx is done.
+	go func() {
+		<-ctx.Done()
+		if err := sub.Unsubscribe(); err != nil {
+			log.Printf("Failed to unsubscribe from asset updates: %v", err)
+		}
+	}()
+
+	return ch, nil
+}
+
+// AssetQualityScoreUpdated is the resolver for the assetQualityScoreUpdated field.
+func (r *subscriptionResolver) AssetQualityScoreUpdated(ctx context.Context, boardID string) (<-chan *model.AssetQualityScore, error) {
+	if ctx.Value("user") == nil {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	ch := make(chan *model.AssetQualityScore, 1)
+
+	sub, err := r.NatsConn.SubscribeAssetQualityScores(boardID, subscriptionHandler(ctx, ch, "asset quality score"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to asset quality scores: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := sub.Unsubscribe(); err != nil {
+			log.Printf("Failed to unsubscribe from asset quality scores: %v", err)
+		}
+	}()
+
+	return ch, nil
+}
+
+// CampaignMetricsUpdated is the resolver for the campaignMetricsUpdated field.
+func (r *subscriptionResolver) CampaignMetricsUpdated(ctx context.Context, projectID string, platforms []model.CampaignPlatform) (<-chan *model.CampaignMetricsUpdate, error) {
+	if ctx.Value("user") == nil {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	ch := make(chan *model.CampaignMetricsUpdate, 1)
+
+	sub, err := r.NatsConn.SubscribeCampaignMetricsUpdates(projectID, subscriptionHandler(ctx, ch, "campaign metrics update", campaignPlatformFilter(platforms)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to campaign metrics updates: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := sub.Unsubscribe(); err != nil {
+			log.Printf("Failed to unsubscribe from campaign metrics updates: %v", err)
+		}
+	}()
+
+	return ch, nil
+}
+
+// CampaignPerformanceAlert is the resolver for the campaignPerformanceAlert field.
+func (r *subscriptionResolver) CampaignPerformanceAlert(ctx context.Context, projectID string) (<-chan *model.CampaignPerformanceAlert, error) {
+	if err := r.authorize(ctx, projectID, ActionView); err != nil {
+		return nil, err
+	}
+
+	ch := make(chan *model.CampaignPerformanceAlert, 1)
+
+	sub, err := r.NatsConn.SubscribeCampaignPerformanceAlert(projectID, performanceAlertHandler(ctx, projectID, ch))
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to campaign performance alerts: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := sub.Unsubscribe(); err != nil {
+			log.Printf("Failed to unsubscribe from campaign performance alerts: %v", err)
+		}
+	}()
+
+	return ch, nil
+}
+
+// CampaignBudgetExceeded is the resolver for the campaignBudgetExceeded field.
+func (r *subscriptionResolver) CampaignBudgetExceeded(ctx context.Context, projectID string) (<-chan *model.CampaignBudgetExceeded, error) {
+	if err := r.authorize(ctx, projectID, ActionView); err != nil {
+		return nil, err
+	}
+
+	ch := make(chan *model.CampaignBudgetExceeded, 1)
+
+	sub, err := r.NatsConn.SubscribeCampaignBudgetExceeded(projectID, budgetExceededHandler(ctx, projectID, ch))
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to campaign budget exceeded events: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := sub.Unsubscribe(); err != nil {
+			log.Printf("Failed to unsubscribe from campaign budget exceeded events: %v", err)
+		}
+	}()
+
+	return ch, nil
+}
+
+// ProjectROIUpdated is the resolver for the projectROIUpdated field.
+func (r *subscriptionResolver) ProjectROIUpdated(ctx context.Context, projectID string) (<-chan *model.ProjectROI, error) {
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM projects WHERE id = $1 AND deleted_at IS NULL)`, projectID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to query project: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("project not found")
+	}
+
+	ch := make(chan *model.ProjectROI, 1)
+
+	sub, err := r.NatsConn.SubscribeProjectROIUpdates(projectID, func(data []byte) {
+		var roi model.ProjectROI
+		if err := json.Unmarshal(data, &roi); err != nil {
+			log.Printf("Failed to unmarshal project ROI update: %v", err)
+			return
+		}
+
+		select {
+		case ch <- &roi:
+		case <-ctx.Done():
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to project ROI updates: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := sub.Unsubscribe(); err != nil {
+			log.Printf("Failed to unsubscribe from project ROI updates: %v", err)
+		}
+	}()
+
+	return ch, nil
+}
+
+// Owner is the resolver for the owner field.
+func (r *projectResolver) Owner(ctx context.Context, obj *model.Project) (*model.User, error) {
+	return r.loaders(ctx).User(ctx, obj.OwnerID)
+}
+
+// Boards is the resolver for the boards field.
+func (r *projectResolver) Boards(ctx context.Context, obj *model.Project, first int, after *string, last int, before *string) (*model.BoardConnection, error) {
+	page, err := newConnectionPage(first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+
+	boards, err := r.loaders(ctx).ProjectBoards(ctx, obj.ID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return boardConnection(page, boards), nil
+}
+
+// SuccessRate is the resolver for the successRate field.
+func (r *projectResolver) SuccessRate(ctx context.Context, obj *model.Project) (float64, error) {
+	return r.loaders(ctx).SuccessRate(ctx, obj.ID)
+}
+
+// Members is the resolver for the members field.
+func (r *organizationResolver) Members(ctx context.Context, obj *model.Organization) ([]*model.OrganizationMember, error) {
+	tx, authUser, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Only members see the members of an organization
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+organizationMemberColumns+`
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.org_id = $1
+			AND EXISTS (SELECT 1 FROM organization_members WHERE org_id = $1 AND user_id = $2)
+		ORDER BY m.created_at, m.user_id
+	`, obj.ID, authUser.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query organization members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []*model.OrganizationMember{}
+	for rows.Next() {
+		member, err := scanOrganizationMember(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan asset: %w", err)
+			return nil, fmt.Errorf("failed to scan organization member: %w", err)
 		}
-		assets = append(assets, &asset)
+		members = append(members, member)
 	}
 
-	return assets, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read organization members: %w", err)
+	}
+
+	return members, nil
 }
 
-// Board is the resolver for the board field.
-func (r *assetResolver) Board(ctx context.Context, obj *model.Asset) (*model.Board, error) {
-	var board model.Board
-	err := r.DB.QueryRow(`
-		SELECT id, name, description, project_id, created_at, updated_at
-		FROM boards WHERE id = $1
-	`, obj.BoardID).Scan(
-		&board.ID, &board.Name, &board.Description, &board.ProjectID,
-		&board.CreatedAt, &board.UpdatedAt,
-	)
+// RoasByPlatform is the resolver for the roasByPlatform field.
+func (r *projectROIResolver) RoasByPlatform(ctx context.Context, obj *model.ProjectROI) (map[string]interface{}, error) {
+	roasByPlatform := make(map[string]interface{}, len(obj.ROASByPlatform))
+	for platform, roas := range obj.ROASByPlatform {
+		roasByPlatform[platform] = roas
+	}
+	return roasByPlatform, nil
+}
+
+// ByPlatform is the resolver for the byPlatform field.
+func (r *spendReportResolver) ByPlatform(ctx context.Context, obj *model.SpendReport) (map[string]interface{}, error) {
+	byPlatform := make(map[string]interface{}, len(obj.ByPlatform))
+	for platform, spend := range obj.ByPlatform {
+		byPlatform[platform] = spend
+	}
+	return byPlatform, nil
+}
+
+// ByContentType is the resolver for the byContentType field.
+func (r *spendReportResolver) ByContentType(ctx context.Context, obj *model.SpendReport) (map[string]interface{}, error) {
+	byContentType := make(map[string]interface{}, len(obj.ByContentType))
+	for contentType, spend := range obj.ByContentType {
+		byContentType[contentType] = spend
+	}
+	return byContentType, nil
+}
 
+// Project is the resolver for the project field.
+func (r *boardResolver) Project(ctx context.Context, obj *model.Board) (*model.Project, error) {
+	return r.loaders(ctx).Project(ctx, obj.ProjectID)
+}
+
+// Assets is the resolver for the assets field.
+func (r *boardResolver) Assets(ctx context.Context, obj *model.Board, first int, after *string, last int, before *string) (*model.AssetConnection, error) {
+	page, err := newConnectionPage(first, after, last, before)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query board: %w", err)
+		return nil, err
 	}
 
-	return &board, nil
+	assets, err := r.loaders(ctx).BoardAssets(ctx, obj.ID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return assetConnection(page, assets), nil
+}
+
+// Board is the resolver for the board field.
+func (r *assetResolver) Board(ctx context.Context, obj *model.Asset) (*model.Board, error) {
+	return r.loaders(ctx).Board(ctx, obj.BoardID)
 }
 
 // ApprovedBy is the resolver for the approvedBy field.
@@ -561,56 +2761,55 @@ func (r *assetResolver) ApprovedBy(ctx context.Context, obj *model.Asset) (*mode
 		return nil, nil
 	}
 
-	var user model.User
-	err := r.DB.QueryRow(`
-		SELECT id, email, name, avatar, created_at, updated_at
-		FROM users WHERE id = $1
-	`, *obj.ApprovedBy).Scan(
-		&user.ID, &user.Email, &user.Name, &user.Avatar,
-		&user.CreatedAt, &user.UpdatedAt,
-	)
+	return r.loaders(ctx).User(ctx, obj.ApprovedBy.ID)
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to query user: %w", err)
-	}
+// ThumbnailGenerated is the resolver for the thumbnailGenerated field.
+func (r *assetResolver) ThumbnailGenerated(ctx context.Context, obj *model.Asset) (bool, error) {
+	return obj.ThumbnailURL != nil, nil
+}
 
-	return &user, nil
+// AvailableTransitions is the resolver for the availableTransitions field.
+func (r *assetResolver) AvailableTransitions(ctx context.Context, obj *model.Asset) ([]model.AssetStatus, error) {
+	return assetStatusMachine.Next(obj.Status), nil
 }
 
-// User is the resolver for the user field.
-func (r *chatMessageResolver) User(ctx context.Context, obj *model.ChatMessage) (*model.User, error) {
-	var user model.User
-	err := r.DB.QueryRow(`
-		SELECT id, email, name, avatar, created_at, updated_at
-		FROM users WHERE id = $1
-	`, obj.UserID).Scan(
-		&user.ID, &user.Email, &user.Name, &user.Avatar,
-		&user.CreatedAt, &user.UpdatedAt,
-	)
+// QualityScore is the resolver for the qualityScore field.
+func (r *assetResolver) QualityScore(ctx context.Context, obj *model.Asset) (*model.QualityScore, error) {
+	if r.Quality == nil {
+		return nil, nil
+	}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to query user: %w", err)
+	if score := r.Quality.Cached(ctx, obj.ID, obj.UpdatedAt); score != nil {
+		return qualityScoreToModel(score), nil
+	}
+
+	// Unscored assets are scored in the background and their scores sent by
+	// assetQualityScoreUpdated, so loading a board never waits for the API
+	if r.Quality.Claim(ctx, obj.ID, obj.UpdatedAt, qualityScoreTimeout) {
+		r.scoreQuality(*obj)
 	}
+	return nil, nil
+}
 
-	return &user, nil
+// User is the resolver for the user field.
+func (r *chatMessageResolver) User(ctx context.Context, obj *model.ChatMessage) (*model.User, error) {
+	return r.loaders(ctx).User(ctx, obj.UserID)
 }
 
 // Board is the resolver for the board field.
 func (r *chatMessageResolver) Board(ctx context.Context, obj *model.ChatMessage) (*model.Board, error) {
-	var board model.Board
-	err := r.DB.QueryRow(`
-		SELECT id, name, description, project_id, created_at, updated_at
-		FROM boards WHERE id = $1
-	`, obj.BoardID).Scan(
-		&board.ID, &board.Name, &board.Description, &board.ProjectID,
-		&board.CreatedAt, &board.UpdatedAt,
-	)
+	return r.loaders(ctx).Board(ctx, obj.BoardID)
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to query board: %w", err)
-	}
+// Replies is the resolver for the replies field.
+func (r *chatMessageResolver) Replies(ctx context.Context, obj *model.ChatMessage) ([]*model.ChatMessage, error) {
+	return r.loaders(ctx).MessageReplies(ctx, obj.ID)
+}
 
-	return &board, nil
+// ReactionCount is the resolver for the reactionCount field.
+func (r *chatMessageResolver) ReactionCount(ctx context.Context, obj *model.ChatMessage) (int, error) {
+	return r.loaders(ctx).ReactionCount(ctx, obj.ID)
 }
 
 // Query returns generated.QueryResolver implementation.
@@ -634,10 +2833,22 @@ func (r *Resolver) Asset() generated.AssetResolver { return &assetResolver{r} }
 // ChatMessage returns generated.ChatMessageResolver implementation.
 func (r *Resolver) ChatMessage() generated.ChatMessageResolver { return &chatMessageResolver{r} }
 
+// ProjectROI returns generated.ProjectROIResolver implementation.
+func (r *Resolver) ProjectROI() generated.ProjectROIResolver { return &projectROIResolver{r} }
+
+// Organization returns generated.OrganizationResolver implementation.
+func (r *Resolver) Organization() generated.OrganizationResolver { return &organizationResolver{r} }
+
+// SpendReport returns generated.SpendReportResolver implementation.
+func (r *Resolver) SpendReport() generated.SpendReportResolver { return &spendReportResolver{r} }
+
 type queryResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type subscriptionResolver struct{ *Resolver }
 type projectResolver struct{ *Resolver }
 type boardResolver struct{ *Resolver }
 type assetResolver struct{ *Resolver }
-type chatMessageResolver struct{ *Resolver } 
\ No newline at end of file
+type chatMessageResolver struct{ *Resolver } 
+type projectROIResolver struct{ *Resolver }
+type organizationResolver struct{ *Resolver }
+type spendReportResolver struct{ *Resolver }
@@ -0,0 +1,178 @@
+package graph
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+// maxSearchResults is the number of best matches returned by a search
+const maxSearchResults = 50
+
+// searchQuery sanitizes the terms of a search, which must not be blank
+func (r *Resolver) searchQuery(query string) (string, error) {
+	query = strings.TrimSpace(r.validator().SanitizeString(query))
+	if query == "" {
+		return "", fmt.Errorf("search query must not be empty")
+	}
+	return query, nil
+}
+
+// searchCacheKey identifies the results of a search as search:{query}:{filters_hash}.
+// Row-level security makes results depend on the user, who is hashed with the
+// filters.
+func searchCacheKey(query string, filters ...string) string {
+	hash := sha256.Sum256([]byte(strings.Join(filters, "\x00")))
+	return "search:" + query + ":" + hex.EncodeToString(hash[:8])
+}
+
+// searchAssets returns the assets whose name matches query, best matches first
+func (r *Resolver) searchAssets(ctx context.Context, query string, projectID *string, status *model.AssetStatus) ([]*model.Asset, error) {
+	query, err := r.searchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	authUser, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := []string{"assets", authUser.ID, "", ""}
+	if projectID != nil {
+		filters[2] = *projectID
+	}
+	if status != nil {
+		filters[3] = status.String()
+	}
+	key := searchCacheKey(query, filters...)
+	if r.Cache != nil {
+		if assets, ok := r.Cache.GetSearchAssets(key); ok {
+			return assets, nil
+		}
+	}
+
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	sqlQuery := `
+		SELECT a.id, a.name, a.type, a.url, a.status, a.board_id, a.approved_by, a.approved_at, a.platform_rejection_reason, a.rejection_reason, a.duplicate_of, a.scheduled_at, a.thumbnail_url, a.created_at, a.updated_at
+		FROM assets a
+		JOIN boards b ON a.board_id = b.id
+		WHERE to_tsvector('english', a.name) @@ plainto_tsquery('english', $1)
+			AND a.deleted_at IS NULL`
+	args := []interface{}{query}
+
+	if projectID != nil {
+		args = append(args, *projectID)
+		sqlQuery += fmt.Sprintf(" AND b.project_id = $%d", len(args))
+	}
+	if status != nil {
+		args = append(args, *status)
+		sqlQuery += fmt.Sprintf(" AND a.status = $%d", len(args))
+	}
+
+	args = append(args, maxSearchResults)
+	sqlQuery += fmt.Sprintf(`
+		ORDER BY ts_rank(to_tsvector('english', a.name), plainto_tsquery('english', $1)) DESC, a.created_at DESC
+		LIMIT $%d`, len(args))
+
+	rows, err := tx.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search assets: %w", err)
+	}
+	defer rows.Close()
+
+	assets := []*model.Asset{}
+	for rows.Next() {
+		var asset model.Asset
+		err := rows.Scan(
+			&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
+			&asset.BoardID, &asset.ApprovedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+			&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan asset: %w", err)
+		}
+		assets = append(assets, &asset)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search assets: %w", err)
+	}
+
+	if r.Cache != nil {
+		r.Cache.SetSearchAssets(key, assets)
+	}
+
+	return assets, nil
+}
+
+// searchBoards returns the boards of a project whose name or description
+// matches query, best matches first
+func (r *Resolver) searchBoards(ctx context.Context, query string, projectID string) ([]*model.Board, error) {
+	query, err := r.searchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	authUser, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key := searchCacheKey(query, "boards", authUser.ID, projectID)
+	if r.Cache != nil {
+		if boards, ok := r.Cache.GetSearchBoards(key); ok {
+			return boards, nil
+		}
+	}
+
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, name, description, project_id, created_at, updated_at
+		FROM boards
+		WHERE to_tsvector('english', name || ' ' || coalesce(description, '')) @@ plainto_tsquery('english', $1)
+			AND project_id = $2
+			AND deleted_at IS NULL
+		ORDER BY ts_rank(to_tsvector('english', name || ' ' || coalesce(description, '')), plainto_tsquery('english', $1)) DESC, created_at DESC
+		LIMIT $3
+	`, query, projectID, maxSearchResults)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search boards: %w", err)
+	}
+	defer rows.Close()
+
+	boards := []*model.Board{}
+	for rows.Next() {
+		var board model.Board
+		err := rows.Scan(
+			&board.ID, &board.Name, &board.Description, &board.ProjectID,
+			&board.CreatedAt, &board.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan board: %w", err)
+		}
+		boards = append(boards, &board)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search boards: %w", err)
+	}
+
+	if r.Cache != nil {
+		r.Cache.SetSearchBoards(key, boards)
+	}
+
+	return boards, nil
+}
@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+func TestSearchQuery(t *testing.T) {
+	resolver := &Resolver{}
+
+	query, err := resolver.searchQuery("  summer <b>banner</b>\x00 ")
+	require.NoError(t, err)
+	assert.Equal(t, "summer &lt;b&gt;banner&lt;/b&gt;", query)
+
+	_, err = resolver.searchQuery(" \x00\t ")
+	assert.EqualError(t, err, "search query must not be empty")
+}
+
+func TestSearchCacheKey(t *testing.T) {
+	key := searchCacheKey("banner", "assets", "user-1", "project-1", "")
+	assert.Regexp(t, `^search:banner:[0-9a-f]{16}$`, key)
+	assert.Equal(t, key, searchCacheKey("banner", "assets", "user-1", "project-1", ""))
+
+	// Other users, filters and queries have their own results
+	assert.NotEqual(t, key, searchCacheKey("banner", "assets", "user-2", "project-1", ""))
+	assert.NotEqual(t, key, searchCacheKey("banner", "assets", "user-1", "project-1", "APPROVED"))
+	assert.NotEqual(t, key, searchCacheKey("banner", "assets", "user-1", "", "project-1"))
+	assert.NotEqual(t, key, searchCacheKey("poster", "assets", "user-1", "project-1", ""))
+}
+
+func TestQueryResolver_SearchAssets(t *testing.T) {
+	t.Run("Error - Unauthorized", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		queryResolver := &queryResolver{resolver}
+
+		result, err := queryResolver.SearchAssets(context.Background(), "banner", nil, nil)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "unauthorized")
+	})
+
+	t.Run("Error - Empty Query", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		queryResolver := &queryResolver{resolver}
+		ctx := createTestContext(uuid.New().String())
+
+		result, err := queryResolver.SearchAssets(ctx, "  ", nil, nil)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "must not be empty")
+	})
+
+	t.Run("Success - Cached", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		resolver.Cache = &ResolverCache{}
+		queryResolver := &queryResolver{resolver}
+		userID := uuid.New().String()
+		ctx := createTestContext(userID)
+		projectID := uuid.New().String()
+		status := model.AssetStatusApproved
+
+		asset := createTestAsset(uuid.New().String())
+		key := searchCacheKey("banner", "assets", userID, projectID, status.String())
+		resolver.Cache.SetSearchAssets(key, []*model.Asset{asset})
+
+		result, err := queryResolver.SearchAssets(ctx, " banner ", &projectID, &status)
+
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Asset{asset}, result)
+	})
+}
+
+func TestQueryResolver_SearchBoards(t *testing.T) {
+	t.Run("Error - Unauthorized", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		queryResolver := &queryResolver{resolver}
+
+		result, err := queryResolver.SearchBoards(context.Background(), "launch", uuid.New().String())
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "unauthorized")
+	})
+
+	t.Run("Success - Cached", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		resolver.Cache = &ResolverCache{}
+		queryResolver := &queryResolver{resolver}
+		userID := uuid.New().String()
+		ctx := createTestContext(userID)
+		projectID := uuid.New().String()
+
+		board := createTestBoard(projectID)
+		resolver.Cache.SetSearchBoards(searchCacheKey("launch", "boards", userID, projectID), []*model.Board{board})
+
+		result, err := queryResolver.SearchBoards(ctx, "launch", projectID)
+
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Board{board}, result)
+	})
+}
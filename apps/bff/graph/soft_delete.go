@@ -0,0 +1,157 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+// softDeleteProject marks the project projectID, its boards and their assets as
+// deleted at now
+func softDeleteProject(ctx context.Context, tx *sql.Tx, projectID string, now time.Time) error {
+	result, err := tx.ExecContext(ctx, `
+		UPDATE projects SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL
+	`, now, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		return fmt.Errorf("project not found")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE assets SET deleted_at = $1
+		WHERE deleted_at IS NULL
+			AND board_id IN (SELECT id FROM boards WHERE project_id = $2 AND deleted_at IS NULL)
+	`, now, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to delete project assets: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE boards SET deleted_at = $1 WHERE project_id = $2 AND deleted_at IS NULL
+	`, now, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to delete project boards: %w", err)
+	}
+
+	return nil
+}
+
+// softDeleteBoard marks the board boardID and its assets as deleted at now
+func softDeleteBoard(ctx context.Context, tx *sql.Tx, boardID string, now time.Time) error {
+	result, err := tx.ExecContext(ctx, `
+		UPDATE boards SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL
+	`, now, boardID)
+	if err != nil {
+		return fmt.Errorf("failed to delete board: %w", err)
+	}
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		return fmt.Errorf("board not found")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE assets SET deleted_at = $1 WHERE board_id = $2 AND deleted_at IS NULL
+	`, now, boardID)
+	if err != nil {
+		return fmt.Errorf("failed to delete board assets: %w", err)
+	}
+
+	return nil
+}
+
+// softDeleteAsset marks the asset assetID as deleted at now
+func softDeleteAsset(ctx context.Context, tx *sql.Tx, assetID string, now time.Time) error {
+	result, err := tx.ExecContext(ctx, `
+		UPDATE assets SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL
+	`, now, assetID)
+	if err != nil {
+		return fmt.Errorf("failed to delete asset: %w", err)
+	}
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		return fmt.Errorf("asset not found")
+	}
+
+	return nil
+}
+
+// restoreDeletedAsset clears the deletion of the asset assetID and returns it.
+// Assets of deleted boards stay deleted with their board.
+func restoreDeletedAsset(ctx context.Context, tx *sql.Tx, assetID string, now time.Time) (*model.Asset, error) {
+	var assetDeleted, boardDeleted bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT a.deleted_at IS NOT NULL, b.deleted_at IS NOT NULL
+		FROM assets a
+		JOIN boards b ON a.board_id = b.id
+		WHERE a.id = $1
+		FOR UPDATE OF a
+	`, assetID).Scan(&assetDeleted, &boardDeleted)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("asset not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query asset: %w", err)
+	}
+
+	if !assetDeleted {
+		return nil, fmt.Errorf("asset is not deleted")
+	}
+	if boardDeleted {
+		return nil, fmt.Errorf("the board of the asset is deleted")
+	}
+
+	var asset model.Asset
+	var approvedBy sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		UPDATE assets
+		SET deleted_at = NULL, updated_at = $1
+		WHERE id = $2
+		RETURNING id, name, type, url, status, board_id, approved_by, approved_at, platform_rejection_reason, rejection_reason, duplicate_of, scheduled_at, thumbnail_url, created_at, updated_at
+	`, now, assetID).Scan(
+		&asset.ID, &asset.Name, &asset.Type, &asset.URL, &asset.Status,
+		&asset.BoardID, &approvedBy, &asset.ApprovedAt, &asset.PlatformRejectionReason, &asset.RejectionReason, &asset.DuplicateOfID,
+		&asset.ScheduledAt, &asset.ThumbnailURL, &asset.CreatedAt, &asset.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore asset: %w", err)
+	}
+	if approvedBy.Valid {
+		asset.ApprovedBy = &model.User{ID: approvedBy.String}
+	}
+
+	return &asset, nil
+}
+
+// purgeDeleted permanently removes the assets, boards and projects of every
+// tenant deleted before olderThan and returns the number of rows removed. It
+// runs outside of a user transaction so that row-level security does not hide
+// other tenants' rows.
+func (r *Resolver) purgeDeleted(ctx context.Context, olderThan time.Time) (int, error) {
+	tx, err := r.DB.Writer().BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Children go first, as removing their parent would cascade to them uncounted
+	purged := 0
+	for _, table := range []string{"assets", "boards", "projects"} {
+		result, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE deleted_at < $1`, table), olderThan)
+		if err != nil {
+			return 0, fmt.Errorf("failed to purge deleted %s: %w", table, err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to count purged %s: %w", table, err)
+		}
+		purged += int(rowsAffected)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit purge: %w", err)
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,356 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+const (
+	// budgetAlertThreshold is the share of its monthly budget a project may
+	// spend before a budget exceeded event is published
+	budgetAlertThreshold = 0.8
+
+	// spendReportTTL is how long a spend report is cached. New campaign metrics
+	// invalidate it.
+	spendReportTTL = time.Hour
+
+	// budgetAlertTTL is how long a project is not alerted again about the
+	// budget of a month, longer than any month
+	budgetAlertTTL = 32 * 24 * time.Hour
+)
+
+// dailySpend is the spend of the campaigns of a project on one platform for
+// assets of one type on a day
+type dailySpend struct {
+	date        string
+	platform    string
+	contentType string
+	costMicros  int64
+}
+
+// parseMonth returns the first day of month, given as YYYY-MM
+func parseMonth(month string) (time.Time, error) {
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("month must be in the format YYYY-MM")
+	}
+	return start, nil
+}
+
+// projectSpendReport returns the ad spend of a project the user can see in
+// month, compared with its budget
+func (r *Resolver) projectSpendReport(ctx context.Context, projectID, month string) (*model.SpendReport, error) {
+	start, err := parseMonth(month)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, _, err := r.userReadTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The cache is shared by the members of the project, so access is checked
+	// before it is read
+	var budgetLimit sql.NullFloat64
+	err = tx.QueryRowContext(ctx, `
+		SELECT budget_limit FROM projects WHERE id = $1 AND deleted_at IS NULL
+	`, projectID).Scan(&budgetLimit)
+	if err == sql.ErrNoRows {
+		return nil, ErrProjectNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query project: %w", err)
+	}
+
+	report, ok := r.SpendReports.Get(ctx, projectID, month)
+	if !ok {
+		report, err = r.projectSpend(ctx, tx, projectID, start)
+		if err != nil {
+			return nil, err
+		}
+		r.SpendReports.Set(ctx, report)
+	}
+
+	// The budget is not cached, so changing it takes effect immediately
+	if budgetLimit.Valid {
+		report.BudgetLimit = &budgetLimit.Float64
+	}
+	report.BudgetUtilization = budgetUtilization(report.TotalSpend, report.BudgetLimit)
+
+	return report, nil
+}
+
+// projectSpend sums the spend of the campaigns of a project in the month
+// starting at start. Snapshots hold the spend of a campaign since it started,
+// so the spend of a day is the growth since the previous snapshot; a snapshot
+// with less spend than the previous one, as when the reporting period of a
+// campaign changes, counts as no spend.
+func (r *Resolver) projectSpend(ctx context.Context, db sqlQuerier, projectID string, start time.Time) (*model.SpendReport, error) {
+	end := start.AddDate(0, 1, 0)
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT s.fetched_on::text, s.platform, COALESCE(a.type::text, ''), SUM(GREATEST(s.cost_micros, 0))
+		FROM (
+			SELECT fetched_on, platform, asset_id,
+				cost_micros - COALESCE(LAG(cost_micros) OVER (PARTITION BY platform, campaign_id ORDER BY fetched_on), 0) AS cost_micros
+			FROM campaign_metrics_history
+			WHERE project_id = $1 AND fetched_on < $3
+		) s
+		LEFT JOIN assets a ON a.id = s.asset_id
+		WHERE s.fetched_on >= $2
+		GROUP BY 1, 2, 3
+	`, projectID, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query campaign spend: %w", err)
+	}
+	defer rows.Close()
+
+	var spends []dailySpend
+	for rows.Next() {
+		var s dailySpend
+		if err := rows.Scan(&s.date, &s.platform, &s.contentType, &s.costMicros); err != nil {
+			return nil, fmt.Errorf("failed to scan campaign spend: %w", err)
+		}
+		spends = append(spends, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate campaign spend: %w", err)
+	}
+
+	return spendReportFromDays(projectID, start, spends), nil
+}
+
+// spendReportFromDays sums the daily spend of a project into its report for
+// the month starting at start. Every day of the month is listed.
+func spendReportFromDays(projectID string, start time.Time, spends []dailySpend) *model.SpendReport {
+	byPlatform := map[string]int64{}
+	byContentType := map[string]int64{}
+	byDate := map[string]int64{}
+	var total int64
+	for _, s := range spends {
+		total += s.costMicros
+		byPlatform[strings.ToUpper(s.platform)] += s.costMicros
+		contentType := s.contentType
+		if contentType == "" {
+			contentType = string(model.AssetTypeOther)
+		}
+		byContentType[contentType] += s.costMicros
+		byDate[s.date] += s.costMicros
+	}
+
+	report := &model.SpendReport{
+		ProjectID:     projectID,
+		Month:         start.Format("2006-01"),
+		TotalSpend:    float64(total) / 1e6,
+		ByPlatform:    make(map[string]float64, len(byPlatform)),
+		ByContentType: make(map[string]float64, len(byContentType)),
+		DailySpend:    []*model.DailySpend{},
+	}
+	for platform, costMicros := range byPlatform {
+		report.ByPlatform[platform] = float64(costMicros) / 1e6
+	}
+	for contentType, costMicros := range byContentType {
+		report.ByContentType[contentType] = float64(costMicros) / 1e6
+	}
+	for day := start; day.Before(start.AddDate(0, 1, 0)); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		report.DailySpend = append(report.DailySpend, &model.DailySpend{
+			Date:  date,
+			Spend: float64(byDate[date]) / 1e6,
+		})
+	}
+
+	return report
+}
+
+// budgetUtilization is totalSpend divided by budgetLimit, or 0 without a
+// budget
+func budgetUtilization(totalSpend float64, budgetLimit *float64) float64 {
+	if budgetLimit == nil || *budgetLimit <= 0 {
+		return 0
+	}
+	return totalSpend / *budgetLimit
+}
+
+// setProjectBudgetLimit sets or removes the monthly budget of a project
+func (r *Resolver) setProjectBudgetLimit(ctx context.Context, projectID string, budgetLimit *float64) (bool, error) {
+	if budgetLimit != nil && *budgetLimit < 0 {
+		return false, fmt.Errorf("budget limit must not be negative")
+	}
+
+	tx, _, err := r.userTx(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var previous sql.NullFloat64
+	err = tx.QueryRowContext(ctx, `
+		SELECT budget_limit FROM projects WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+	`, projectID).Scan(&previous)
+	if err == sql.ErrNoRows {
+		return false, ErrProjectNotFound
+	} else if err != nil {
+		return false, fmt.Errorf("failed to query project: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE projects SET budget_limit = $2 WHERE id = $1`, projectID, budgetLimit); err != nil {
+		return false, fmt.Errorf("failed to set project budget: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit project budget: %w", err)
+	}
+
+	var old interface{}
+	if previous.Valid {
+		old = previous.Float64
+	}
+	r.audit(ctx, "setProjectBudgetLimit", "project", projectID,
+		map[string]interface{}{"budgetLimit": old}, map[string]interface{}{"budgetLimit": budgetLimit})
+
+	return true, nil
+}
+
+// checkSpendBudget drops the cached spend report of a project for the month
+// of metrics fetched at fetchedAt, and publishes a budget exceeded event the
+// first time the project spends more than budgetAlertThreshold of its budget
+// in that month. It is called for events from the connectors service, so
+// row-level security does not apply.
+func (r *Resolver) checkSpendBudget(ctx context.Context, projectID string, fetchedAt time.Time) {
+	year, monthOfYear, _ := fetchedAt.UTC().Date()
+	start := time.Date(year, monthOfYear, 1, 0, 0, 0, 0, time.UTC)
+	month := start.Format("2006-01")
+	r.SpendReports.Invalidate(ctx, projectID, month)
+
+	if r.NatsConn == nil {
+		return
+	}
+
+	var budgetLimit sql.NullFloat64
+	err := r.DB.Writer().QueryRowContext(ctx, `SELECT budget_limit FROM projects WHERE id = $1`, projectID).Scan(&budgetLimit)
+	if err != nil {
+		log.Printf("Failed to query budget of project %s: %v", projectID, err)
+		return
+	}
+	if !budgetLimit.Valid || budgetLimit.Float64 <= 0 {
+		return
+	}
+
+	report, err := r.projectSpend(ctx, r.DB.Writer(), projectID, start)
+	if err != nil {
+		log.Printf("Failed to compute spend of project %s: %v", projectID, err)
+		return
+	}
+	r.SpendReports.Set(ctx, report)
+
+	utilization := budgetUtilization(report.TotalSpend, &budgetLimit.Float64)
+	if utilization <= budgetAlertThreshold || !r.SpendReports.claimBudgetAlert(ctx, projectID, month) {
+		return
+	}
+
+	err = r.NatsConn.PublishSpendBudgetExceeded(&nats.SpendBudgetExceededEvent{
+		EventType:         "project.spend_budget_exceeded",
+		ProjectID:         projectID,
+		Month:             month,
+		TotalSpend:        report.TotalSpend,
+		BudgetLimit:       budgetLimit.Float64,
+		BudgetUtilization: utilization,
+		Timestamp:         time.Now(),
+	})
+	if err != nil {
+		log.Printf("Failed to publish budget exceeded event: %v", err)
+	}
+}
+
+// SpendReportCache caches spend reports in Redis under
+// spend_report:{projectID}:{month}, without the budget of the project. A nil
+// cache caches nothing.
+type SpendReportCache struct {
+	client redis.UniversalClient
+}
+
+// NewSpendReportCache creates a cache of spend reports stored in client
+func NewSpendReportCache(client redis.UniversalClient) *SpendReportCache {
+	return &SpendReportCache{client: client}
+}
+
+func spendReportKey(projectID, month string) string {
+	return fmt.Sprintf("spend_report:%s:%s", projectID, month)
+}
+
+// Get returns the cached spend report of a project for month
+func (c *SpendReportCache) Get(ctx context.Context, projectID, month string) (*model.SpendReport, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	data, err := c.client.Get(ctx, spendReportKey(projectID, month)).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			log.Printf("Failed to read spend report of project %s: %v", projectID, err)
+		}
+		recordCacheLookup("spend_reports", false)
+		return nil, false
+	}
+
+	var report model.SpendReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		recordCacheLookup("spend_reports", false)
+		return nil, false
+	}
+	recordCacheLookup("spend_reports", true)
+	return &report, true
+}
+
+// Set caches report for spendReportTTL
+func (c *SpendReportCache) Set(ctx context.Context, report *model.SpendReport) {
+	if c == nil {
+		return
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return
+	}
+	if err := c.client.Set(ctx, spendReportKey(report.ProjectID, report.Month), data, spendReportTTL).Err(); err != nil {
+		log.Printf("Failed to cache spend report of project %s: %v", report.ProjectID, err)
+	}
+}
+
+// Invalidate drops the cached spend report of a project for month
+func (c *SpendReportCache) Invalidate(ctx context.Context, projectID, month string) {
+	if c == nil {
+		return
+	}
+
+	if err := c.client.Del(ctx, spendReportKey(projectID, month)).Err(); err != nil {
+		log.Printf("Failed to invalidate spend report of project %s: %v", projectID, err)
+	}
+}
+
+// claimBudgetAlert reports whether the project has not been alerted about its
+// budget for month yet. Without a cache, every check alerts.
+func (c *SpendReportCache) claimBudgetAlert(ctx context.Context, projectID, month string) bool {
+	if c == nil {
+		return true
+	}
+
+	claimed, err := c.client.SetNX(ctx, fmt.Sprintf("budget_alert:%s:%s", projectID, month), 1, budgetAlertTTL).Result()
+	if err != nil {
+		log.Printf("Failed to record budget alert of project %s: %v", projectID, err)
+		return true
+	}
+	return claimed
+}
@@ -0,0 +1,110 @@
+package graph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+func TestParseMonth(t *testing.T) {
+	start, err := parseMonth("2024-02")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), start)
+
+	for _, month := range []string{"", "2024-13", "2024-02-01", "02/2024"} {
+		_, err := parseMonth(month)
+		assert.EqualError(t, err, "month must be in the format YYYY-MM", month)
+	}
+}
+
+func TestSpendReportFromDays(t *testing.T) {
+	start := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	report := spendReportFromDays("project-1", start, []dailySpend{
+		{date: "2024-02-01", platform: "google_ads", contentType: "IMAGE", costMicros: 10000000},
+		{date: "2024-02-01", platform: "meta", contentType: "VIDEO", costMicros: 5500000},
+		{date: "2024-02-29", platform: "meta", contentType: "IMAGE", costMicros: 2000000},
+		{date: "2024-02-29", platform: "meta", costMicros: 500000},
+	})
+
+	assert.Equal(t, "project-1", report.ProjectID)
+	assert.Equal(t, "2024-02", report.Month)
+	assert.Equal(t, 18.0, report.TotalSpend)
+	assert.Equal(t, map[string]float64{"GOOGLE_ADS": 10, "META": 8}, report.ByPlatform)
+	assert.Equal(t, map[string]float64{"IMAGE": 12, "VIDEO": 5.5, "OTHER": 0.5}, report.ByContentType)
+
+	// Every day of the month is listed, with or without spend
+	require.Len(t, report.DailySpend, 29)
+	assert.Equal(t, &model.DailySpend{Date: "2024-02-01", Spend: 15.5}, report.DailySpend[0])
+	assert.Equal(t, &model.DailySpend{Date: "2024-02-02", Spend: 0}, report.DailySpend[1])
+	assert.Equal(t, &model.DailySpend{Date: "2024-02-29", Spend: 2.5}, report.DailySpend[28])
+
+	// Months without campaigns have no spend
+	report = spendReportFromDays("project-2", start, nil)
+	assert.Zero(t, report.TotalSpend)
+	assert.Empty(t, report.ByPlatform)
+	assert.Len(t, report.DailySpend, 29)
+}
+
+func TestBudgetUtilization(t *testing.T) {
+	budget := 1000.0
+	assert.Equal(t, 0.85, budgetUtilization(850, &budget))
+
+	zero := 0.0
+	assert.Zero(t, budgetUtilization(850, &zero))
+	assert.Zero(t, budgetUtilization(850, nil))
+}
+
+func TestSpendReportCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cache := NewSpendReportCache(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	ctx := context.Background()
+
+	_, ok := cache.Get(ctx, "project-1", "2024-02")
+	assert.False(t, ok)
+
+	report := spendReportFromDays("project-1", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), []dailySpend{
+		{date: "2024-02-03", platform: "meta", contentType: "IMAGE", costMicros: 1000000},
+	})
+	cache.Set(ctx, report)
+
+	cached, ok := cache.Get(ctx, "project-1", "2024-02")
+	require.True(t, ok)
+	assert.Equal(t, report, cached)
+	assert.Equal(t, spendReportTTL, mr.TTL("spend_report:project-1:2024-02"))
+
+	cache.Invalidate(ctx, "project-1", "2024-02")
+	_, ok = cache.Get(ctx, "project-1", "2024-02")
+	assert.False(t, ok)
+
+	// A project is alerted once per month
+	assert.True(t, cache.claimBudgetAlert(ctx, "project-1", "2024-02"))
+	assert.False(t, cache.claimBudgetAlert(ctx, "project-1", "2024-02"))
+	assert.True(t, cache.claimBudgetAlert(ctx, "project-1", "2024-03"))
+}
+
+func TestSpendReportCache_Nil(t *testing.T) {
+	var cache *SpendReportCache
+	ctx := context.Background()
+
+	cache.Set(ctx, &model.SpendReport{ProjectID: "project-1", Month: "2024-02"})
+	_, ok := cache.Get(ctx, "project-1", "2024-02")
+	assert.False(t, ok)
+	cache.Invalidate(ctx, "project-1", "2024-02")
+	assert.True(t, cache.claimBudgetAlert(ctx, "project-1", "2024-02"))
+}
+
+func TestMutationResolver_SetProjectBudgetLimit(t *testing.T) {
+	resolver, _ := setupTestResolver()
+	mutationResolver := &mutationResolver{resolver}
+
+	negative := -1.0
+	_, err := mutationResolver.SetProjectBudgetLimit(createTestContext("user-1"), "project-1", &negative)
+	assert.EqualError(t, err, "budget limit must not be negative")
+}
@@ -0,0 +1,81 @@
+package graph
+
+import (
+	"fmt"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+// InvalidTransitionError is returned when an asset status change is not allowed
+type InvalidTransitionError struct {
+	From model.AssetStatus
+	To   model.AssetStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("invalid asset status transition from %s to %s", e.From, e.To)
+}
+
+// StatusMachine enforces the allowed asset status transitions. Every resolver
+// that writes assets.status must validate the change before hitting the database.
+type StatusMachine struct {
+	transitions map[model.AssetStatus][]model.AssetStatus
+}
+
+// NewStatusMachine creates a status machine with the asset review workflow
+func NewStatusMachine() *StatusMachine {
+	return &StatusMachine{
+		transitions: map[model.AssetStatus][]model.AssetStatus{
+			model.AssetStatusDraft:   {model.AssetStatusReview},
+			model.AssetStatusPending: {model.AssetStatusReview},
+			model.AssetStatusReview:  {model.AssetStatusApproved, model.AssetStatusRejected},
+			// Ad platforms review deployed ads and may still reject them, and
+			// reviewers recall approved assets back to pending before they deploy
+			model.AssetStatusApproved: {model.AssetStatusDeployed, model.AssetStatusRejected, model.AssetStatusPending},
+			// Admins roll back deployments by pausing their ads
+			model.AssetStatusDeployed: {model.AssetStatusFailed, model.AssetStatusRejected, model.AssetStatusRolledBack},
+		},
+	}
+}
+
+// ValidateTransition returns an InvalidTransitionError if an asset may not move from one status to the other
+func (sm *StatusMachine) ValidateTransition(from, to model.AssetStatus) error {
+	for _, allowed := range sm.Next(from) {
+		if allowed == to {
+			return nil
+		}
+	}
+
+	return &InvalidTransitionError{From: from, To: to}
+}
+
+// Next returns the statuses an asset may move to from the status from, in the
+// order of model.AllAssetStatus
+func (sm *StatusMachine) Next(from model.AssetStatus) []model.AssetStatus {
+	allowed := make(map[model.AssetStatus]bool)
+	for _, to := range sm.transitions[from] {
+		allowed[to] = true
+	}
+
+	next := []model.AssetStatus{}
+	for _, to := range model.AllAssetStatus {
+		if allowed[to] {
+			next = append(next, to)
+		}
+	}
+	return next
+}
+
+// AllowedFrom returns the statuses an asset may move to the status to from
+func (sm *StatusMachine) AllowedFrom(to model.AssetStatus) []model.AssetStatus {
+	var allowed []model.AssetStatus
+	for _, from := range model.AllAssetStatus {
+		if sm.ValidateTransition(from, to) == nil {
+			allowed = append(allowed, from)
+		}
+	}
+	return allowed
+}
+
+// assetStatusMachine is shared by all status-mutation resolvers
+var assetStatusMachine = NewStatusMachine()
@@ -0,0 +1,76 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+func TestStatusMachine_TransitionMatrix(t *testing.T) {
+	sm := NewStatusMachine()
+
+	allowed := map[model.AssetStatus]map[model.AssetStatus]bool{
+		model.AssetStatusDraft:    {model.AssetStatusReview: true},
+		model.AssetStatusReview:   {model.AssetStatusApproved: true, model.AssetStatusRejected: true},
+		model.AssetStatusPending:  {model.AssetStatusReview: true},
+		model.AssetStatusApproved: {model.AssetStatusDeployed: true, model.AssetStatusRejected: true, model.AssetStatusPending: true},
+		model.AssetStatusDeployed: {model.AssetStatusFailed: true, model.AssetStatusRejected: true, model.AssetStatusRolledBack: true},
+	}
+
+	for _, from := range model.AllAssetStatus {
+		for _, to := range model.AllAssetStatus {
+			from, to := from, to
+			t.Run(string(from)+"->"+string(to), func(t *testing.T) {
+				err := sm.ValidateTransition(from, to)
+
+				if allowed[from][to] {
+					assert.NoError(t, err)
+					return
+				}
+
+				require.Error(t, err)
+				var transitionErr *InvalidTransitionError
+				require.True(t, errors.As(err, &transitionErr))
+				assert.Equal(t, from, transitionErr.From)
+				assert.Equal(t, to, transitionErr.To)
+				assert.Contains(t, err.Error(), string(from))
+				assert.Contains(t, err.Error(), string(to))
+			})
+		}
+	}
+}
+
+func TestStatusMachine_TerminalStates(t *testing.T) {
+	sm := NewStatusMachine()
+
+	for _, terminal := range []model.AssetStatus{
+		model.AssetStatusRejected,
+		model.AssetStatusFailed,
+		model.AssetStatusRevisionRequired,
+		model.AssetStatusRolledBack,
+	} {
+		for _, to := range model.AllAssetStatus {
+			assert.Error(t, sm.ValidateTransition(terminal, to), "%s should be terminal", terminal)
+		}
+	}
+}
+
+func TestStatusMachine_AllowedFrom(t *testing.T) {
+	sm := NewStatusMachine()
+
+	assert.ElementsMatch(t, []model.AssetStatus{model.AssetStatusReview}, sm.AllowedFrom(model.AssetStatusApproved))
+	assert.ElementsMatch(t, []model.AssetStatus{model.AssetStatusDraft, model.AssetStatusPending}, sm.AllowedFrom(model.AssetStatusReview))
+	assert.ElementsMatch(t, []model.AssetStatus{model.AssetStatusApproved}, sm.AllowedFrom(model.AssetStatusPending))
+	assert.Empty(t, sm.AllowedFrom(model.AssetStatusDraft))
+}
+
+func TestStatusMachine_Next(t *testing.T) {
+	sm := NewStatusMachine()
+
+	assert.Equal(t, []model.AssetStatus{model.AssetStatusReview}, sm.Next(model.AssetStatusPending))
+	assert.Equal(t, []model.AssetStatus{model.AssetStatusPending, model.AssetStatusRejected, model.AssetStatusDeployed}, sm.Next(model.AssetStatusApproved))
+	assert.Equal(t, []model.AssetStatus{}, sm.Next(model.AssetStatusRolledBack))
+}
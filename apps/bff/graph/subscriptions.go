@@ -0,0 +1,146 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+// FilterFunc reports whether a subscription event is sent to the subscriber.
+// Events are filtered on the server so that WebSocket clients only receive those
+// they asked for.
+type FilterFunc[T any] func(event T) bool
+
+// subscriptionHandler returns a NATS message handler decoding the messages of a
+// subscription as T and sending those passing every filter to ch, until ctx is
+// done. Messages that cannot be decoded are logged with name and dropped.
+func subscriptionHandler[T any](ctx context.Context, ch chan<- *T, name string, filters ...FilterFunc[*T]) func(data []byte) {
+	return func(data []byte) {
+		event := new(T)
+		if err := json.Unmarshal(data, event); err != nil {
+			log.Printf("Failed to unmarshal %s: %v", name, err)
+			return
+		}
+
+		for _, filter := range filters {
+			if !filter(event) {
+				return
+			}
+		}
+
+		select {
+		case ch <- event:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// oneOf returns a filter passing the events whose value is one of values, or
+// every event when values is empty
+func oneOf[T any, V comparable](values []V, value func(event T) V) FilterFunc[T] {
+	if len(values) == 0 {
+		return func(T) bool { return true }
+	}
+
+	allowed := make(map[V]bool, len(values))
+	for _, v := range values {
+		allowed[v] = true
+	}
+	return func(event T) bool {
+		return allowed[value(event)]
+	}
+}
+
+// assetStatusFilter passes the assets with one of statuses
+func assetStatusFilter(statuses []model.AssetStatus) FilterFunc[*model.Asset] {
+	return oneOf(statuses, func(asset *model.Asset) model.AssetStatus { return asset.Status })
+}
+
+// performanceAlertHandler returns a NATS message handler sending the campaign
+// performance alerts of projectID to ch, until ctx is done. Alerts of other
+// projects are dropped.
+func performanceAlertHandler(ctx context.Context, projectID string, ch chan<- *model.CampaignPerformanceAlert) func(data []byte) {
+	return func(data []byte) {
+		var event nats.CampaignPerformanceAlertEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal campaign performance alert: %v", err)
+			return
+		}
+		if event.Anomaly.ProjectID != projectID {
+			return
+		}
+
+		select {
+		case ch <- campaignPerformanceAlert(&event):
+		case <-ctx.Done():
+		}
+	}
+}
+
+// campaignPerformanceAlert converts an anomaly alert of the connectors service.
+// The threshold is the rolling average the metric moved away from.
+func campaignPerformanceAlert(event *nats.CampaignPerformanceAlertEvent) *model.CampaignPerformanceAlert {
+	anomaly := event.Anomaly
+
+	severity := model.AlertSeverityMedium
+	if anomaly.Severity == "critical" {
+		severity = model.AlertSeverityCritical
+	}
+
+	return &model.CampaignPerformanceAlert{
+		AlertID:    fmt.Sprintf("%s:%s:%s:%d", anomaly.Platform, anomaly.CampaignID, anomaly.Metric, anomaly.DetectedAt.Unix()),
+		ProjectID:  anomaly.ProjectID,
+		CampaignID: anomaly.CampaignID,
+		AlertType:  anomaly.Metric + "_anomaly",
+		Severity:   severity,
+		Message: fmt.Sprintf("%s of campaign %s is %.2f, %.1f standard deviations from its average of %.2f",
+			anomaly.Metric, anomaly.CampaignID, anomaly.Value, anomaly.ZScore, anomaly.Mean),
+		Threshold:    &anomaly.Mean,
+		CurrentValue: &anomaly.Value,
+		Timestamp:    event.Timestamp,
+	}
+}
+
+// budgetExceededHandler returns a NATS message handler sending the deployments of
+// projectID refused for exceeding a platform's budget cap to ch, until ctx is
+// done. The subject also carries the monthly spend alerts published by the BFF,
+// which are told apart by their event type and dropped.
+func budgetExceededHandler(ctx context.Context, projectID string, ch chan<- *model.CampaignBudgetExceeded) func(data []byte) {
+	return func(data []byte) {
+		var event nats.CampaignBudgetExceededEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal campaign budget exceeded event: %v", err)
+			return
+		}
+		if event.EventType != "campaign.budget_exceeded" || event.ProjectID != projectID {
+			return
+		}
+
+		select {
+		case ch <- &model.CampaignBudgetExceeded{
+			AssetID:   event.AssetID,
+			ProjectID: event.ProjectID,
+			Platform:  model.CampaignPlatform(strings.ToUpper(event.Platform)),
+			Budget:    event.Budget,
+			Cap:       event.Cap,
+			Timestamp: event.Timestamp,
+		}:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// campaignPlatformFilter passes the metrics updates of the campaigns of one of platforms
+func campaignPlatformFilter(platforms []model.CampaignPlatform) FilterFunc[*model.CampaignMetricsUpdate] {
+	return oneOf(platforms, func(update *model.CampaignMetricsUpdate) model.CampaignPlatform {
+		if update.Metrics == nil {
+			return ""
+		}
+		return update.Metrics.Platform
+	})
+}
@@ -0,0 +1,123 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+func TestSubscriptionHandler_FiltersEvents(t *testing.T) {
+	ch := make(chan *model.CampaignMetricsUpdate, 3)
+	handle := subscriptionHandler(context.Background(), ch, "campaign metrics update",
+		campaignPlatformFilter([]model.CampaignPlatform{model.CampaignPlatformMeta, model.CampaignPlatformLinkedin}))
+
+	for _, platform := range []model.CampaignPlatform{model.CampaignPlatformGoogleAds, model.CampaignPlatformMeta, model.CampaignPlatformTwitter} {
+		data, err := json.Marshal(&model.CampaignMetricsUpdate{CampaignID: "campaign-1", Metrics: &model.CampaignMetrics{Platform: platform}})
+		require.NoError(t, err)
+		handle(data)
+	}
+	handle([]byte(`not json`))
+
+	require.Len(t, ch, 1)
+	assert.Equal(t, model.CampaignPlatformMeta, (<-ch).Metrics.Platform)
+}
+
+func TestAssetStatusFilter(t *testing.T) {
+	pending := &model.Asset{Status: model.AssetStatusPending}
+	approved := &model.Asset{Status: model.AssetStatusApproved}
+
+	filter := assetStatusFilter([]model.AssetStatus{model.AssetStatusApproved})
+	assert.False(t, filter(pending))
+	assert.True(t, filter(approved))
+
+	// Without statuses every asset is sent
+	filter = assetStatusFilter(nil)
+	assert.True(t, filter(pending))
+	assert.True(t, filter(approved))
+}
+
+func TestCampaignPlatformFilter_WithoutMetrics(t *testing.T) {
+	assert.False(t, campaignPlatformFilter([]model.CampaignPlatform{model.CampaignPlatformMeta})(&model.CampaignMetricsUpdate{}))
+	assert.True(t, campaignPlatformFilter(nil)(&model.CampaignMetricsUpdate{}))
+}
+
+func TestPerformanceAlertHandler(t *testing.T) {
+	ch := make(chan *model.CampaignPerformanceAlert, 2)
+	handle := performanceAlertHandler(context.Background(), "project-1", ch)
+
+	detectedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	for _, projectID := range []string{"project-2", "project-1"} {
+		data, err := json.Marshal(&nats.CampaignPerformanceAlertEvent{
+			EventType: "campaign.performance_alert",
+			Anomaly: nats.CampaignAnomaly{
+				CampaignID: "campaign-1",
+				Platform:   "meta",
+				ProjectID:  projectID,
+				Metric:     "ctr",
+				Value:      0.5,
+				Mean:       2,
+				ZScore:     -3.2,
+				Severity:   "critical",
+				DetectedAt: detectedAt,
+			},
+			Timestamp: detectedAt,
+		})
+		require.NoError(t, err)
+		handle(data)
+	}
+	handle([]byte(`not json`))
+
+	// Only the alert of the subscribed project is sent
+	require.Len(t, ch, 1)
+	alert := <-ch
+	assert.Equal(t, "project-1", alert.ProjectID)
+	assert.Equal(t, "campaign-1", alert.CampaignID)
+	assert.Equal(t, "ctr_anomaly", alert.AlertType)
+	assert.Equal(t, model.AlertSeverityCritical, alert.Severity)
+	assert.Equal(t, 2.0, *alert.Threshold)
+	assert.Equal(t, 0.5, *alert.CurrentValue)
+	assert.Equal(t, detectedAt, alert.Timestamp)
+}
+
+func TestBudgetExceededHandler(t *testing.T) {
+	ch := make(chan *model.CampaignBudgetExceeded, 3)
+	handle := budgetExceededHandler(context.Background(), "project-1", ch)
+
+	refusedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	for _, projectID := range []string{"project-2", "project-1"} {
+		data, err := json.Marshal(&nats.CampaignBudgetExceededEvent{
+			EventType: "campaign.budget_exceeded",
+			AssetID:   "asset-1",
+			ProjectID: projectID,
+			Platform:  "google_ads",
+			Budget:    999999,
+			Cap:       1000,
+			Timestamp: refusedAt,
+		})
+		require.NoError(t, err)
+		handle(data)
+	}
+
+	// Monthly spend alerts share the subject
+	data, err := json.Marshal(&nats.SpendBudgetExceededEvent{EventType: "project.spend_budget_exceeded", ProjectID: "project-1"})
+	require.NoError(t, err)
+	handle(data)
+	handle([]byte(`not json`))
+
+	// Only the refused deployment of the subscribed project is sent
+	require.Len(t, ch, 1)
+	exceeded := <-ch
+	assert.Equal(t, "asset-1", exceeded.AssetID)
+	assert.Equal(t, "project-1", exceeded.ProjectID)
+	assert.Equal(t, model.CampaignPlatformGoogleAds, exceeded.Platform)
+	assert.Equal(t, 999999.0, exceeded.Budget)
+	assert.Equal(t, 1000.0, exceeded.Cap)
+	assert.Equal(t, refusedAt, exceeded.Timestamp)
+}
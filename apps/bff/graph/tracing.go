@@ -0,0 +1,46 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/tracing"
+)
+
+// ResolverTracing traces each resolver call with a span named after its field,
+// such as Query.project, as a child of the span of the request. Fields read
+// from their parent object are not traced.
+type ResolverTracing struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.FieldInterceptor
+} = ResolverTracing{}
+
+// ExtensionName returns the name of the extension
+func (ResolverTracing) ExtensionName() string {
+	return "ResolverTracing"
+}
+
+// Validate accepts every schema
+func (ResolverTracing) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptField runs the resolver of the field within its span
+func (ResolverTracing) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !fc.IsResolver {
+		return next(ctx)
+	}
+
+	ctx, span := tracing.Tracer.Start(ctx, fc.Object+"."+fc.Field.Name)
+	span.SetAttributes(attribute.String("graphql.field.path", fc.Path().String()))
+
+	res, err := next(ctx)
+	tracing.End(span, err)
+
+	return res, err
+}
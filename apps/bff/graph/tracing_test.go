@@ -0,0 +1,51 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/generated"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/tracing"
+)
+
+func TestResolverTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{Resolvers: &Resolver{}}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(ResolverTracing{})
+
+	body, err := json.Marshal(map[string]string{"query": `{ __typename me { id name } }`})
+	require.NoError(t, err)
+
+	ctx, request := tracing.Tracer.Start(context.Background(), "graphql.request")
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(string(body))).WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+	request.End()
+
+	// Only me has a resolver, and it fails without a user
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	me := spans[0]
+	assert.Equal(t, "Query.me", me.Name())
+	assert.Equal(t, request.SpanContext().SpanID(), me.Parent().SpanID())
+	assert.Equal(t, codes.Error, me.Status().Code)
+	assert.Equal(t, "unauthorized", me.Status().Description)
+	assert.Contains(t, me.Attributes(), attribute.String("graphql.field.path", "me"))
+}
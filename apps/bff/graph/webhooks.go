@@ -0,0 +1,88 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/safehttp"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/webhook"
+)
+
+// webhookEvents maps GraphQL webhook events to the names stored with webhooks
+var webhookEvents = map[model.WebhookEvent]string{
+	model.WebhookEventAssetDeployed: webhook.EventAssetDeployed,
+	model.WebhookEventAssetFailed:   webhook.EventAssetFailed,
+}
+
+// webhookColumns are the columns read by scanWebhook
+const webhookColumns = `id, project_id, url, events, active, created_at`
+
+// validateWebhookURL checks that webhooks are posted to an absolute http or
+// https URL that is not on a private network
+func validateWebhookURL(rawURL string) error {
+	if err := safehttp.ValidateURL(rawURL); err != nil {
+		return fmt.Errorf("webhook URL %w", err)
+	}
+	return nil
+}
+
+// webhookEventNames returns the stored names of events, of which there must be
+// at least one
+func webhookEventNames(events []model.WebhookEvent) ([]string, error) {
+	if len(events) == 0 {
+		return nil, fmt.Errorf("webhook must subscribe to at least one event")
+	}
+
+	names := []string{}
+	seen := map[string]bool{}
+	for _, event := range events {
+		name, ok := webhookEvents[event]
+		if !ok {
+			return nil, fmt.Errorf("unsupported webhook event: %s", event)
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// validateWebhookInput checks the fields of a webhook that are given
+func validateWebhookInput(rawURL, secret *string, events []model.WebhookEvent) ([]string, error) {
+	if rawURL != nil {
+		if err := validateWebhookURL(*rawURL); err != nil {
+			return nil, err
+		}
+	}
+	if secret != nil && strings.TrimSpace(*secret) == "" {
+		return nil, fmt.Errorf("webhook secret is required")
+	}
+	if events == nil {
+		return nil, nil
+	}
+	return webhookEventNames(events)
+}
+
+// scanWebhook scans a webhooks row selected as webhookColumns
+func scanWebhook(row rowScanner) (*model.Webhook, error) {
+	var hook model.Webhook
+	var events []string
+	if err := row.Scan(&hook.ID, &hook.ProjectID, &hook.URL, pq.Array(&events), &hook.Active, &hook.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	hook.Events = []model.WebhookEvent{}
+	for _, name := range events {
+		for event, eventName := range webhookEvents {
+			if eventName == name {
+				hook.Events = append(hook.Events, event)
+			}
+		}
+	}
+
+	return &hook, nil
+}
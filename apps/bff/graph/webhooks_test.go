@@ -0,0 +1,107 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+func TestValidateWebhookURL(t *testing.T) {
+	assert.NoError(t, validateWebhookURL("https://example.com/hooks/zamc"))
+	assert.NoError(t, validateWebhookURL("http://203.0.113.10:8080/hook"))
+
+	for _, rawURL := range []string{
+		"", "/hooks/zamc", "ftp://example.com/hook", "https://", "example.com",
+		"http://localhost:8080/hook", "http://127.0.0.1/hook", "http://[::1]/hook", "http://10.1.2.3/hook",
+		"http://169.254.169.254/latest/meta-data", "http://redis:6379/", "http://api.localhost/hook",
+	} {
+		assert.Error(t, validateWebhookURL(rawURL), rawURL)
+	}
+}
+
+func TestWebhookEventNames(t *testing.T) {
+	names, err := webhookEventNames([]model.WebhookEvent{
+		model.WebhookEventAssetFailed, model.WebhookEventAssetDeployed, model.WebhookEventAssetFailed,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"asset.failed", "asset.deployed"}, names)
+
+	_, err = webhookEventNames([]model.WebhookEvent{})
+	assert.EqualError(t, err, "webhook must subscribe to at least one event")
+
+	_, err = webhookEventNames([]model.WebhookEvent{"ASSET_UPLOADED"})
+	assert.EqualError(t, err, "unsupported webhook event: ASSET_UPLOADED")
+}
+
+func TestValidateWebhookInput(t *testing.T) {
+	// Fields that are not given are not checked
+	events, err := validateWebhookInput(nil, nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, events)
+
+	_, err = validateWebhookInput(stringPtr("not a url"), nil, nil)
+	assert.Error(t, err)
+
+	_, err = validateWebhookInput(nil, stringPtr("  "), nil)
+	assert.EqualError(t, err, "webhook secret is required")
+
+	events, err = validateWebhookInput(nil, nil, []model.WebhookEvent{model.WebhookEventAssetDeployed})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"asset.deployed"}, events)
+}
+
+func TestMutationResolver_CreateWebhook(t *testing.T) {
+	t.Run("Error - Invalid URL", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+		ctx := createTestContext(uuid.New().String())
+
+		result, err := mutationResolver.CreateWebhook(ctx, model.CreateWebhookInput{
+			ProjectID: uuid.New().String(),
+			URL:       "ftp://example.com/hook",
+			Secret:    "s3cret",
+			Events:    []model.WebhookEvent{model.WebhookEventAssetDeployed},
+		})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "http or https")
+	})
+
+	t.Run("Error - No Events", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+		ctx := createTestContext(uuid.New().String())
+
+		result, err := mutationResolver.CreateWebhook(ctx, model.CreateWebhookInput{
+			ProjectID: uuid.New().String(),
+			URL:       "https://example.com/hook",
+			Secret:    "s3cret",
+		})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "at least one event")
+	})
+
+	t.Run("Error - Unauthorized", func(t *testing.T) {
+		resolver, _ := setupTestResolver()
+		mutationResolver := &mutationResolver{resolver}
+
+		result, err := mutationResolver.CreateWebhook(context.Background(), model.CreateWebhookInput{
+			ProjectID: uuid.New().String(),
+			URL:       "https://example.com/hook",
+			Secret:    "s3cret",
+			Events:    []model.WebhookEvent{model.WebhookEventAssetDeployed},
+		})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "unauthorized")
+	})
+}
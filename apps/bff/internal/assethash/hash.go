@@ -0,0 +1,82 @@
+package assethash
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// MaxHashedBytes is how much of an asset's file is hashed. Files that differ only
+// past this point get the same hash.
+const MaxHashedBytes = 1 << 20
+
+// Hasher computes the content hashes of asset files
+type Hasher struct {
+	client *http.Client
+}
+
+// NewHasher creates a hasher that downloads asset files with client
+func NewHasher(client *http.Client) *Hasher {
+	return &Hasher{client: client}
+}
+
+// Hash returns the hex encoded SHA-256 of the first MaxHashedBytes of the file at
+// assetURL. A HEAD request checks that the file exists before it is downloaded.
+func (h *Hasher) Hash(ctx context.Context, assetURL string) (string, error) {
+	parsed, err := url.Parse(assetURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("invalid asset url: %s", assetURL)
+	}
+
+	if _, err := h.do(ctx, http.MethodHead, assetURL, nil); err != nil {
+		return "", err
+	}
+
+	// Servers that ignore the range send the whole file, only the start is read
+	resp, err := h.do(ctx, http.MethodGet, assetURL, http.Header{
+		"Range": {fmt.Sprintf("bytes=0-%d", MaxHashedBytes-1)},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, io.LimitReader(resp.Body, MaxHashedBytes)); err != nil {
+		return "", fmt.Errorf("failed to read asset file: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// do sends a request for the asset file and fails on non-2xx responses. The body
+// of a HEAD response is closed.
+func (h *Hasher) do(ctx context.Context, method, assetURL string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, assetURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch asset file: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to fetch asset file: %s %s returned %d", method, assetURL, resp.StatusCode)
+	}
+
+	if method == http.MethodHead {
+		resp.Body.Close()
+	}
+
+	return resp, nil
+}
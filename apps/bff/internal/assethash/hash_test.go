@@ -0,0 +1,73 @@
+package assethash
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// newFileServer serves content at /asset.jpg and ignores range requests, like
+// servers that always send the whole file
+func newFileServer(t *testing.T, content []byte) (*httptest.Server, *[]string) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.Header.Get("Range"))
+		if r.URL.Path != "/asset.jpg" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		if r.Method == http.MethodGet {
+			w.Write(content)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestHasher_Hash(t *testing.T) {
+	content := []byte("same file contents")
+	server, requests := newFileServer(t, content)
+
+	hash, err := NewHasher(server.Client()).Hash(context.Background(), server.URL+"/asset.jpg")
+	require.NoError(t, err)
+
+	assert.Equal(t, sha256Hex(content), hash)
+	assert.Equal(t, []string{"HEAD ", "GET bytes=0-1048575"}, *requests)
+}
+
+func TestHasher_Hash_OnlyFirstMegabyte(t *testing.T) {
+	content := bytes.Repeat([]byte{'a'}, MaxHashedBytes)
+	longer := append(append([]byte{}, content...), []byte("trailing bytes")...)
+	server, _ := newFileServer(t, longer)
+
+	hash, err := NewHasher(server.Client()).Hash(context.Background(), server.URL+"/asset.jpg")
+	require.NoError(t, err)
+
+	assert.Equal(t, sha256Hex(content), hash)
+}
+
+func TestHasher_Hash_Errors(t *testing.T) {
+	server, requests := newFileServer(t, []byte("content"))
+	hasher := NewHasher(server.Client())
+
+	_, err := hasher.Hash(context.Background(), server.URL+"/missing.jpg")
+	assert.ErrorContains(t, err, "returned 404")
+	assert.Equal(t, []string{"HEAD "}, *requests, "a missing file must not be downloaded")
+
+	_, err = hasher.Hash(context.Background(), "file:///etc/passwd")
+	assert.ErrorContains(t, err, "invalid asset url")
+}
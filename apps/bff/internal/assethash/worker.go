@@ -0,0 +1,109 @@
+package assethash
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+// jobTimeout bounds the download and storage of one asset's content hash
+const jobTimeout = time.Minute
+
+// Store saves the content hashes computed by the worker
+type Store interface {
+	// SaveContentHash saves the content hash of an asset and returns the older
+	// asset of its project with the same content, or ""
+	SaveContentHash(ctx context.Context, assetID, contentHash string) (string, error)
+}
+
+// DBStore saves content hashes in the assets table
+type DBStore struct {
+	db *sql.DB
+}
+
+// NewDBStore creates a store writing to the assets table of db
+func NewDBStore(db *sql.DB) *DBStore {
+	return &DBStore{db: db}
+}
+
+// SaveContentHash sets the content hash of an asset that has none yet, and
+// marks it as a duplicate of the oldest other asset of its project with the
+// same content
+func (s *DBStore) SaveContentHash(ctx context.Context, assetID, contentHash string) (string, error) {
+	var duplicateOf sql.NullString
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE assets a SET content_hash = $2, duplicate_of = (
+			SELECT d.id FROM assets d
+			JOIN boards db ON d.board_id = db.id
+			WHERE d.content_hash = $2
+				AND d.id <> a.id
+				AND d.deleted_at IS NULL
+				AND db.project_id = (SELECT project_id FROM boards WHERE id = a.board_id)
+			ORDER BY d.created_at
+			LIMIT 1
+		)
+		WHERE a.id = $1 AND a.content_hash IS NULL
+		RETURNING a.duplicate_of
+	`, assetID, contentHash).Scan(&duplicateOf)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to save content hash: %w", err)
+	}
+
+	return duplicateOf.String, nil
+}
+
+// Worker handles the asset content hash jobs queued on NATS
+type Worker struct {
+	hasher *Hasher
+	store  Store
+	nats   *nats.Conn
+}
+
+// NewWorker creates a worker that hashes asset files with hasher and saves the
+// hashes in store
+func NewWorker(hasher *Hasher, store Store, natsConn *nats.Conn) *Worker {
+	return &Worker{
+		hasher: hasher,
+		store:  store,
+		nats:   natsConn,
+	}
+}
+
+// Start subscribes the worker to the queue of content hash jobs
+func (w *Worker) Start() (*natsgo.Subscription, error) {
+	return w.nats.SubscribeAssetHashJobs(func(job *nats.AssetHashJob) {
+		ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+		defer cancel()
+
+		w.Handle(ctx, job)
+	})
+}
+
+// Handle computes and saves the content hash of a job's asset
+func (w *Worker) Handle(ctx context.Context, job *nats.AssetHashJob) (string, error) {
+	contentHash, err := w.hasher.Hash(ctx, job.URL)
+	if err != nil {
+		log.Printf("Failed to hash asset %s: %v", job.AssetID, err)
+		return "", err
+	}
+
+	duplicateOf, err := w.store.SaveContentHash(ctx, job.AssetID, contentHash)
+	if err != nil {
+		log.Printf("Failed to save content hash of asset %s: %v", job.AssetID, err)
+		return "", err
+	}
+	if duplicateOf != "" {
+		log.Printf("Asset %s has the same content as asset %s", job.AssetID, duplicateOf)
+	}
+
+	return contentHash, nil
+}
@@ -0,0 +1,113 @@
+package assethash
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+type memoryStore struct {
+	mu     sync.Mutex
+	hashes map[string]string
+	err    error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{hashes: map[string]string{}}
+}
+
+func (s *memoryStore) SaveContentHash(ctx context.Context, assetID, contentHash string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return "", s.err
+	}
+
+	var duplicateOf string
+	for otherID, otherHash := range s.hashes {
+		if otherHash == contentHash {
+			duplicateOf = otherID
+		}
+	}
+	s.hashes[assetID] = contentHash
+	return duplicateOf, nil
+}
+
+func (s *memoryStore) hash(assetID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.hashes[assetID]
+}
+
+// startWorker runs a worker on an in-process NATS server and returns a
+// connection for queueing jobs
+func startWorker(t *testing.T, client *http.Client, store Store) *nats.Conn {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	server := natsserver.RunServer(&opts)
+	t.Cleanup(server.Shutdown)
+
+	conn, err := nats.Connect(server.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	subscription, err := NewWorker(NewHasher(client), store, conn).Start()
+	require.NoError(t, err)
+	t.Cleanup(func() { subscription.Unsubscribe() })
+
+	return conn
+}
+
+func TestWorker_Handle(t *testing.T) {
+	content := []byte("same file contents")
+	server, _ := newFileServer(t, content)
+	store := newMemoryStore()
+	worker := NewWorker(NewHasher(server.Client()), store, nil)
+
+	hash, err := worker.Handle(context.Background(), &nats.AssetHashJob{AssetID: "asset-1", URL: server.URL + "/asset.jpg"})
+	require.NoError(t, err)
+
+	assert.Equal(t, sha256Hex(content), hash)
+	assert.Equal(t, hash, store.hash("asset-1"))
+}
+
+func TestWorker_Handle_Errors(t *testing.T) {
+	server, _ := newFileServer(t, []byte("content"))
+
+	store := newMemoryStore()
+	worker := NewWorker(NewHasher(server.Client()), store, nil)
+	_, err := worker.Handle(context.Background(), &nats.AssetHashJob{AssetID: "asset-1", URL: server.URL + "/missing.jpg"})
+	assert.Error(t, err)
+	assert.Empty(t, store.hash("asset-1"))
+
+	worker = NewWorker(NewHasher(server.Client()), &memoryStore{err: errors.New("database unavailable")}, nil)
+	_, err = worker.Handle(context.Background(), &nats.AssetHashJob{AssetID: "asset-1", URL: server.URL + "/asset.jpg"})
+	assert.ErrorContains(t, err, "database unavailable")
+}
+
+func TestWorker_PublishedJob(t *testing.T) {
+	content := []byte("same file contents")
+	server, _ := newFileServer(t, content)
+	store := newMemoryStore()
+	conn := startWorker(t, server.Client(), store)
+
+	require.NoError(t, conn.PublishAssetHashJob(nats.AssetHashJob{AssetID: "asset-1", URL: server.URL + "/asset.jpg"}))
+	require.NoError(t, conn.PublishAssetHashJob(nats.AssetHashJob{AssetID: "asset-2", URL: server.URL + "/missing.jpg"}))
+	require.NoError(t, conn.PublishAssetHashJob(nats.AssetHashJob{AssetID: "asset-3", URL: server.URL + "/asset.jpg"}))
+
+	assert.Eventually(t, func() bool {
+		return store.hash("asset-1") == sha256Hex(content) && store.hash("asset-3") == sha256Hex(content)
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Empty(t, store.hash("asset-2"))
+}
@@ -0,0 +1,157 @@
+package assets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif" // decoded in addition to JPEG
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// ThumbnailWidth and ThumbnailHeight are the size of generated thumbnails
+	ThumbnailWidth  = 400
+	ThumbnailHeight = 300
+
+	// thumbnailQuality is the JPEG quality of thumbnails
+	thumbnailQuality = 80
+
+	// fetchTimeout bounds the download of an image
+	fetchTimeout = 10 * time.Second
+
+	// MaxImageBytes is the largest image a thumbnail is generated for
+	MaxImageBytes = 20 << 20
+
+	// MaxImagePixels is the most pixels of an image a thumbnail is generated for,
+	// so that a small, highly compressed file cannot decode to gigabytes
+	MaxImagePixels = 50_000_000
+)
+
+// Uploader stores generated thumbnails
+type Uploader interface {
+	// Upload stores data under key and returns the URL it is served from
+	Upload(ctx context.Context, key string, data []byte, contentType string) (string, error)
+}
+
+// ImageProcessor generates the thumbnails of image assets
+type ImageProcessor struct {
+	client   *http.Client
+	uploader Uploader
+}
+
+// NewImageProcessor creates a processor that downloads images with client and
+// stores their thumbnails with uploader
+func NewImageProcessor(client *http.Client, uploader Uploader) *ImageProcessor {
+	return &ImageProcessor{
+		client:   client,
+		uploader: uploader,
+	}
+}
+
+// GenerateThumbnail downloads the image at imageURL, scales and crops it to
+// ThumbnailWidth by ThumbnailHeight and uploads it as a JPEG. It returns the URL
+// of the thumbnail.
+func (p *ImageProcessor) GenerateThumbnail(ctx context.Context, assetID, imageURL string) (string, error) {
+	img, err := p.fetch(ctx, imageURL)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, Thumbnail(img), &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return "", fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+
+	thumbnailURL, err := p.uploader.Upload(ctx, thumbnailKey(assetID), buf.Bytes(), "image/jpeg")
+	if err != nil {
+		return "", fmt.Errorf("failed to upload thumbnail: %w", err)
+	}
+
+	return thumbnailURL, nil
+}
+
+// fetch downloads and decodes the image at imageURL
+func (p *ImageProcessor) fetch(ctx context.Context, imageURL string) (image.Image, error) {
+	parsed, err := url.Parse(imageURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid image url: %s", imageURL)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to fetch image: GET %s returned %d", imageURL, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxImageBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) > MaxImageBytes {
+		return nil, fmt.Errorf("image is larger than %d bytes", MaxImageBytes)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	if int64(config.Width)*int64(config.Height) > MaxImagePixels {
+		return nil, fmt.Errorf("image of %dx%d pixels is larger than %d pixels", config.Width, config.Height, MaxImagePixels)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	return img, nil
+}
+
+// Thumbnail scales img to cover ThumbnailWidth by ThumbnailHeight and crops the
+// overflow evenly from both sides
+func Thumbnail(img image.Image) *image.RGBA {
+	src := img.Bounds()
+	crop := src
+
+	// Compare the aspect ratios without dividing
+	if src.Dx()*ThumbnailHeight > src.Dy()*ThumbnailWidth {
+		width := src.Dy() * ThumbnailWidth / ThumbnailHeight
+		crop.Min.X += (src.Dx() - width) / 2
+		crop.Max.X = crop.Min.X + width
+	} else {
+		height := src.Dx() * ThumbnailHeight / ThumbnailWidth
+		crop.Min.Y += (src.Dy() - height) / 2
+		crop.Max.Y = crop.Min.Y + height
+	}
+
+	thumbnail := image.NewRGBA(image.Rect(0, 0, ThumbnailWidth, ThumbnailHeight))
+	draw.CatmullRom.Scale(thumbnail, thumbnail.Bounds(), img, crop, draw.Src, nil)
+
+	return thumbnail
+}
+
+// thumbnailKey is the object key of an asset's thumbnail
+func thumbnailKey(assetID string) string {
+	return "thumbnails/" + assetID + ".jpg"
+}
@@ -0,0 +1,138 @@
+package assets
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryUploader keeps uploaded thumbnails in memory
+type memoryUploader struct {
+	objects      map[string][]byte
+	contentTypes map[string]string
+}
+
+func newMemoryUploader() *memoryUploader {
+	return &memoryUploader{objects: map[string][]byte{}, contentTypes: map[string]string{}}
+}
+
+func (u *memoryUploader) Upload(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	u.objects[key] = data
+	u.contentTypes[key] = contentType
+	return "https://cdn.example.com/" + key, nil
+}
+
+// wideImage is a 1600x600 PNG whose left and right quarters are red and whose
+// middle is blue
+func wideImage(t *testing.T) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 1600, 600))
+	for x := 0; x < 1600; x++ {
+		c := color.RGBA{B: 255, A: 255}
+		if x < 400 || x >= 1200 {
+			c = color.RGBA{R: 255, A: 255}
+		}
+		for y := 0; y < 600; y++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestImageProcessor_GenerateThumbnail(t *testing.T) {
+	content := wideImage(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/asset.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	uploader := newMemoryUploader()
+	processor := NewImageProcessor(server.Client(), uploader)
+
+	thumbnailURL, err := processor.GenerateThumbnail(context.Background(), "asset-1", server.URL+"/asset.png")
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/thumbnails/asset-1.jpg", thumbnailURL)
+	assert.Equal(t, "image/jpeg", uploader.contentTypes["thumbnails/asset-1.jpg"])
+
+	thumbnail, err := jpeg.Decode(bytes.NewReader(uploader.objects["thumbnails/asset-1.jpg"]))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, ThumbnailWidth, ThumbnailHeight), thumbnail.Bounds())
+
+	// The 800 pixels in the middle are kept, so the red sides are cropped away
+	for _, x := range []int{5, ThumbnailWidth / 2, ThumbnailWidth - 5} {
+		r, _, b, _ := thumbnail.At(x, ThumbnailHeight/2).RGBA()
+		assert.Less(t, r, uint32(0x2000), "x=%d", x)
+		assert.Greater(t, b, uint32(0xe000), "x=%d", x)
+	}
+
+	_, err = processor.GenerateThumbnail(context.Background(), "asset-2", server.URL+"/missing.png")
+	assert.Error(t, err)
+}
+
+func TestImageProcessor_RejectsNonImages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("%PDF-1.4"))
+	}))
+	defer server.Close()
+
+	uploader := newMemoryUploader()
+	_, err := NewImageProcessor(server.Client(), uploader).GenerateThumbnail(context.Background(), "asset-1", server.URL+"/asset.pdf")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode image")
+	assert.Empty(t, uploader.objects)
+
+	_, err = NewImageProcessor(server.Client(), uploader).GenerateThumbnail(context.Background(), "asset-1", "ftp://example.com/asset.png")
+	assert.Error(t, err)
+}
+
+// hugeImage is a 1x1 PNG whose header claims it is 100000x100000 pixels
+func hugeImage(t *testing.T) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))))
+	data := buf.Bytes()
+
+	// The IHDR chunk follows the 8 byte signature: length, type, width, height,
+	// five more bytes of data and the CRC of the type and data
+	binary.BigEndian.PutUint32(data[16:20], 100000)
+	binary.BigEndian.PutUint32(data[20:24], 100000)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestImageProcessor_RejectsHugeImages(t *testing.T) {
+	content := hugeImage(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	uploader := newMemoryUploader()
+	_, err := NewImageProcessor(server.Client(), uploader).GenerateThumbnail(context.Background(), "asset-1", server.URL+"/asset.png")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "image of 100000x100000 pixels is larger than")
+	assert.Empty(t, uploader.objects)
+}
+
+func TestThumbnail_TallImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 300, 900))
+	thumbnail := Thumbnail(img)
+	assert.Equal(t, image.Rect(0, 0, ThumbnailWidth, ThumbnailHeight), thumbnail.Bounds())
+}
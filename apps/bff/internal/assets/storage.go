@@ -0,0 +1,56 @@
+package assets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/config"
+)
+
+// S3Uploader stores thumbnails in a bucket of an S3-compatible object store
+type S3Uploader struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+}
+
+// NewS3Uploader creates an uploader for the bucket configured in cfg
+func NewS3Uploader(cfg config.ThumbnailStorageConfig) (*S3Uploader, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create object storage client: %w", err)
+	}
+
+	publicURL := cfg.PublicURL
+	if publicURL == "" {
+		publicURL = client.EndpointURL().String() + "/" + cfg.Bucket
+	}
+
+	return &S3Uploader{
+		client:    client,
+		bucket:    cfg.Bucket,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+	}, nil
+}
+
+// Upload stores data under key and returns its URL below the public URL of the
+// bucket
+func (u *S3Uploader) Upload(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	_, err := u.client.PutObject(ctx, u.bucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store %s: %w", key, err)
+	}
+
+	return u.publicURL + "/" + key, nil
+}
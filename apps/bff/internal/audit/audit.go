@@ -0,0 +1,255 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+)
+
+// insertTimeout bounds the storage of one audit entry
+const insertTimeout = 10 * time.Second
+
+// ErrBufferFull is returned by Log when entries are logged faster than they
+// can be stored
+var ErrBufferFull = errors.New("audit log buffer is full")
+
+// Entry records a mutation: who changed which entity, from what to what and when
+type Entry struct {
+	ID         string
+	UserID     string
+	Operation  string
+	EntityType string
+	EntityID   string
+	OldValue   json.RawMessage
+	NewValue   json.RawMessage
+	IPAddress  string
+	UserAgent  string
+	CreatedAt  time.Time
+
+	// ImpersonatedBy is the super admin who acted as UserID, empty when the user
+	// acted themselves
+	ImpersonatedBy string
+}
+
+// Store saves and lists audit entries
+type Store interface {
+	Insert(ctx context.Context, entry *Entry) error
+	List(ctx context.Context, entityType, entityID string, limit int) ([]*Entry, error)
+}
+
+// DBStore keeps audit entries in the mutation_audit_log table
+type DBStore struct {
+	db *sql.DB
+}
+
+// NewDBStore creates a store writing to the mutation_audit_log table of db
+func NewDBStore(db *sql.DB) *DBStore {
+	return &DBStore{db: db}
+}
+
+// Insert saves entry
+func (s *DBStore) Insert(ctx context.Context, entry *Entry) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO mutation_audit_log (id, user_id, operation, entity_type, entity_id, old_value, new_value, ip_address, user_agent, created_at, impersonated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, entry.ID, entry.UserID, entry.Operation, entry.EntityType, entry.EntityID,
+		nullJSON(entry.OldValue), nullJSON(entry.NewValue), entry.IPAddress, entry.UserAgent, entry.CreatedAt,
+		entry.ImpersonatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// List returns the latest limit entries of the entity entityID of entityType,
+// newest first
+func (s *DBStore) List(ctx context.Context, entityType, entityID string, limit int) ([]*Entry, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, operation, entity_type, entity_id, old_value, new_value, ip_address, user_agent, created_at, impersonated_by
+		FROM mutation_audit_log
+		WHERE entity_type = $1 AND entity_id = $2
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`, entityType, entityID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*Entry{}
+	for rows.Next() {
+		var entry Entry
+		var oldValue, newValue []byte
+		err := rows.Scan(
+			&entry.ID, &entry.UserID, &entry.Operation, &entry.EntityType, &entry.EntityID,
+			&oldValue, &newValue, &entry.IPAddress, &entry.UserAgent, &entry.CreatedAt,
+			&entry.ImpersonatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entry.OldValue = oldValue
+		entry.NewValue = newValue
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// nullJSON stores an absent value as NULL
+func nullJSON(value json.RawMessage) interface{} {
+	if value == nil {
+		return nil
+	}
+	return []byte(value)
+}
+
+// AuditLogger records mutations in a store without blocking the resolvers that
+// log them: entries are queued on a buffered channel and stored in the background.
+type AuditLogger struct {
+	store   Store
+	entries chan *Entry
+	closing sync.Once
+	done    chan struct{}
+}
+
+// NewAuditLogger creates an audit logger queueing up to bufferSize entries and
+// starts storing them in store
+func NewAuditLogger(store Store, bufferSize int) *AuditLogger {
+	l := &AuditLogger{
+		store:   store,
+		entries: make(chan *Entry, bufferSize),
+		done:    make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+func (l *AuditLogger) run() {
+	defer close(l.done)
+
+	for entry := range l.entries {
+		ctx, cancel := context.WithTimeout(context.Background(), insertTimeout)
+		if err := l.store.Insert(ctx, entry); err != nil {
+			log.Printf("Failed to store audit entry for %s %s %s: %v", entry.Operation, entry.EntityType, entry.EntityID, err)
+		}
+		cancel()
+	}
+}
+
+// Log queues an entry recording that the authenticated user of ctx performed
+// operation on the entity entityID of entityType, changing it from old to new.
+// Either value may be nil. When a super admin impersonates the user, the entry
+// names both. The client address and user agent are taken from the
+// request stored in ctx by WithRequest.
+func (l *AuditLogger) Log(ctx context.Context, operation, entityType, entityID string, old, new interface{}) error {
+	entry := &Entry{
+		ID:         uuid.New().String(),
+		Operation:  operation,
+		EntityType: entityType,
+		EntityID:   entityID,
+		CreatedAt:  time.Now(),
+	}
+
+	if user, ok := ctx.Value("user").(*auth.User); ok {
+		entry.UserID = user.ID
+		if user.ImpersonatedBy != nil {
+			entry.ImpersonatedBy = *user.ImpersonatedBy
+		}
+	}
+	if client, ok := ctx.Value(clientKey{}).(clientInfo); ok {
+		entry.IPAddress = client.ipAddress
+		entry.UserAgent = client.userAgent
+	}
+
+	var err error
+	if entry.OldValue, err = marshalValue(old); err != nil {
+		return err
+	}
+	if entry.NewValue, err = marshalValue(new); err != nil {
+		return err
+	}
+
+	select {
+	case l.entries <- entry:
+		return nil
+	default:
+		return ErrBufferFull
+	}
+}
+
+// Entries returns the latest limit audit entries of the entity entityID of
+// entityType, newest first
+func (l *AuditLogger) Entries(ctx context.Context, entityType, entityID string, limit int) ([]*Entry, error) {
+	return l.store.List(ctx, entityType, entityID, limit)
+}
+
+// Close stops accepting entries and waits until the queued ones are stored
+func (l *AuditLogger) Close() {
+	l.closing.Do(func() { close(l.entries) })
+	<-l.done
+}
+
+func marshalValue(value interface{}) (json.RawMessage, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit value: %w", err)
+	}
+	return data, nil
+}
+
+type clientKey struct{}
+
+type clientInfo struct {
+	ipAddress string
+	userAgent string
+}
+
+// WithRequest returns a copy of ctx carrying the client address and user agent
+// of r, to be recorded with the mutations made while handling it
+func WithRequest(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, clientKey{}, clientInfo{
+		ipAddress: ClientIP(r),
+		userAgent: r.UserAgent(),
+	})
+}
+
+// ClientIP returns the address of the client of r, preferring the first address
+// forwarded by a proxy
+func ClientIP(r *http.Request) string {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		if idx := strings.Index(xff, ","); idx > 0 {
+			return strings.TrimSpace(xff[:idx])
+		}
+		return strings.TrimSpace(xff)
+	}
+
+	if xri := r.Header.Get("X-Real-IP"); xri != "" {
+		return xri
+	}
+
+	if idx := strings.LastIndex(r.RemoteAddr, ":"); idx > 0 {
+		return r.RemoteAddr[:idx]
+	}
+	return r.RemoteAddr
+}
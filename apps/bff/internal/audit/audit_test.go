@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+)
+
+type memoryStore struct {
+	mu      sync.Mutex
+	entries []*Entry
+	block   chan struct{}
+}
+
+func (s *memoryStore) Insert(ctx context.Context, entry *Entry) error {
+	if s.block != nil {
+		<-s.block
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *memoryStore) List(ctx context.Context, entityType, entityID string, limit int) ([]*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []*Entry
+	for i := len(s.entries) - 1; i >= 0 && len(entries) < limit; i-- {
+		if s.entries[i].EntityType == entityType && s.entries[i].EntityID == entityID {
+			entries = append(entries, s.entries[i])
+		}
+	}
+	return entries, nil
+}
+
+func TestAuditLogger_RecordsUserAndClient(t *testing.T) {
+	store := &memoryStore{}
+	logger := NewAuditLogger(store, 10)
+
+	r := httptest.NewRequest("POST", "/query", nil)
+	r.RemoteAddr = "10.0.0.7:52100"
+	r.Header.Set("X-Forwarded-For", "203.0.113.9, 10.0.0.1")
+	r.Header.Set("User-Agent", "zamc-web/1.4")
+
+	ctx := WithRequest(context.Background(), r)
+	ctx = context.WithValue(ctx, "user", &auth.User{ID: "user-1"})
+
+	old := map[string]string{"status": "PENDING"}
+	new := map[string]string{"status": "APPROVED"}
+	require.NoError(t, logger.Log(ctx, "approveAsset", "asset", "asset-1", old, new))
+	require.NoError(t, logger.Log(ctx, "createProject", "project", "project-1", nil, map[string]string{"name": "Launch"}))
+	logger.Close()
+
+	entries, err := logger.Entries(context.Background(), "asset", "asset-1", 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	entry := entries[0]
+	assert.NotEmpty(t, entry.ID)
+	assert.Equal(t, "user-1", entry.UserID)
+	assert.Equal(t, "approveAsset", entry.Operation)
+	assert.JSONEq(t, `{"status": "PENDING"}`, string(entry.OldValue))
+	assert.JSONEq(t, `{"status": "APPROVED"}`, string(entry.NewValue))
+	assert.Equal(t, "203.0.113.9", entry.IPAddress)
+	assert.Equal(t, "zamc-web/1.4", entry.UserAgent)
+	assert.False(t, entry.CreatedAt.IsZero())
+
+	entries, err = logger.Entries(context.Background(), "project", "project-1", 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Nil(t, entries[0].OldValue)
+	assert.Empty(t, entries[0].ImpersonatedBy)
+}
+
+func TestAuditLogger_RecordsImpersonator(t *testing.T) {
+	store := &memoryStore{}
+	logger := NewAuditLogger(store, 10)
+
+	admin := "admin-1"
+	ctx := context.WithValue(context.Background(), "user", &auth.User{ID: "user-1", ImpersonatedBy: &admin})
+	require.NoError(t, logger.Log(ctx, "createBoard", "board", "board-1", nil, nil))
+	logger.Close()
+
+	require.Len(t, store.entries, 1)
+	assert.Equal(t, "user-1", store.entries[0].UserID)
+	assert.Equal(t, "admin-1", store.entries[0].ImpersonatedBy)
+}
+
+func TestAuditLogger_DoesNotBlockWhenFull(t *testing.T) {
+	store := &memoryStore{block: make(chan struct{})}
+	logger := NewAuditLogger(store, 1)
+
+	// One entry is being stored and one is queued, so the next one is dropped
+	ctx := context.Background()
+	require.NoError(t, logger.Log(ctx, "createBoard", "board", "board-1", nil, nil))
+	require.Eventually(t, func() bool { return len(logger.entries) == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, logger.Log(ctx, "createBoard", "board", "board-2", nil, nil))
+	assert.ErrorIs(t, logger.Log(ctx, "createBoard", "board", "board-3", nil, nil), ErrBufferFull)
+
+	close(store.block)
+	logger.Close()
+	assert.Len(t, store.entries, 2)
+}
+
+func TestClientIP(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "192.0.2.4:41000"
+	assert.Equal(t, "192.0.2.4", ClientIP(r))
+
+	r.Header.Set("X-Real-IP", "198.51.100.2")
+	assert.Equal(t, "198.51.100.2", ClientIP(r))
+
+	r.Header.Set("X-Forwarded-For", "203.0.113.9")
+	assert.Equal(t, "203.0.113.9", ClientIP(r))
+}
@@ -0,0 +1,164 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of login events
+const (
+	// LoginEventTokenVerification is an access token presented to the BFF
+	LoginEventTokenVerification = "token_verification"
+
+	// LoginEventSuspiciousLogin is a login the client reported from a device it
+	// does not recognize
+	LoginEventSuspiciousLogin = "suspicious_login"
+)
+
+// LoginEvent records an attempt to authenticate: who, from which client and
+// where, and whether it succeeded
+type LoginEvent struct {
+	ID            string
+	UserID        string
+	EventType     string
+	IPAddress     string
+	UserAgent     string
+	Country       string
+	City          string
+	Success       bool
+	FailureReason string
+	CreatedAt     time.Time
+}
+
+// LoginStore saves and lists login events
+type LoginStore interface {
+	InsertLogin(ctx context.Context, event *LoginEvent) error
+	ListLogins(ctx context.Context, userID string, limit, offset int) ([]*LoginEvent, error)
+}
+
+// DBLoginStore keeps login events in the login_events table
+type DBLoginStore struct {
+	db *sql.DB
+}
+
+// NewDBLoginStore creates a store writing to the login_events table of db
+func NewDBLoginStore(db *sql.DB) *DBLoginStore {
+	return &DBLoginStore{db: db}
+}
+
+// InsertLogin saves event
+func (s *DBLoginStore) InsertLogin(ctx context.Context, event *LoginEvent) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO login_events (id, user_id, event_type, ip_address, user_agent, country, city, success, failure_reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, event.ID, event.UserID, event.EventType, event.IPAddress, event.UserAgent,
+		event.Country, event.City, event.Success, event.FailureReason, event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert login event: %w", err)
+	}
+
+	return nil
+}
+
+// ListLogins returns limit login events of userID, newest first, skipping the
+// first offset
+func (s *DBLoginStore) ListLogins(ctx context.Context, userID string, limit, offset int) ([]*LoginEvent, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, event_type, ip_address, user_agent, country, city, success, failure_reason, created_at
+		FROM login_events
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query login events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*LoginEvent{}
+	for rows.Next() {
+		var event LoginEvent
+		err := rows.Scan(
+			&event.ID, &event.UserID, &event.EventType, &event.IPAddress, &event.UserAgent,
+			&event.Country, &event.City, &event.Success, &event.FailureReason, &event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan login event: %w", err)
+		}
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read login events: %w", err)
+	}
+
+	return events, nil
+}
+
+// LoginRecorder records login events in a store without delaying the requests
+// they belong to, the same way AuditLogger records mutations
+type LoginRecorder struct {
+	store   LoginStore
+	events  chan *LoginEvent
+	closing sync.Once
+	done    chan struct{}
+}
+
+// NewLoginRecorder creates a login recorder queueing up to bufferSize events and
+// starts storing them in store
+func NewLoginRecorder(store LoginStore, bufferSize int) *LoginRecorder {
+	l := &LoginRecorder{
+		store:  store,
+		events: make(chan *LoginEvent, bufferSize),
+		done:   make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+func (l *LoginRecorder) run() {
+	defer close(l.done)
+
+	for event := range l.events {
+		ctx, cancel := context.WithTimeout(context.Background(), insertTimeout)
+		if err := l.store.InsertLogin(ctx, event); err != nil {
+			log.Printf("Failed to store %s login event of user %q: %v", event.EventType, event.UserID, err)
+		}
+		cancel()
+	}
+}
+
+// Record queues event, filling in its ID and time when they are not set
+func (l *LoginRecorder) Record(event *LoginEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	select {
+	case l.events <- event:
+		return nil
+	default:
+		return ErrBufferFull
+	}
+}
+
+// History returns limit login events of userID, newest first, skipping the
+// first offset
+func (l *LoginRecorder) History(ctx context.Context, userID string, limit, offset int) ([]*LoginEvent, error) {
+	return l.store.ListLogins(ctx, userID, limit, offset)
+}
+
+// Close stops accepting events and waits until the queued ones are stored
+func (l *LoginRecorder) Close() {
+	l.closing.Do(func() { close(l.events) })
+	<-l.done
+}
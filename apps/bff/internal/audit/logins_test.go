@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryLoginStore struct {
+	mu     sync.Mutex
+	events []*LoginEvent
+	block  chan struct{}
+}
+
+func (s *memoryLoginStore) InsertLogin(ctx context.Context, event *LoginEvent) error {
+	if s.block != nil {
+		<-s.block
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *memoryLoginStore) ListLogins(ctx context.Context, userID string, limit, offset int) ([]*LoginEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []*LoginEvent
+	for i := len(s.events) - 1; i >= 0 && len(events) < limit; i-- {
+		if s.events[i].UserID != userID {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		events = append(events, s.events[i])
+	}
+	return events, nil
+}
+
+func TestLoginRecorder_RecordsEvents(t *testing.T) {
+	store := &memoryLoginStore{}
+	recorder := NewLoginRecorder(store, 10)
+
+	require.NoError(t, recorder.Record(&LoginEvent{UserID: "user-1", EventType: LoginEventTokenVerification, Success: true}))
+	require.NoError(t, recorder.Record(&LoginEvent{UserID: "user-1", EventType: LoginEventSuspiciousLogin, Success: true}))
+	recorder.Close()
+
+	events, err := recorder.History(context.Background(), "user-1", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, LoginEventSuspiciousLogin, events[0].EventType)
+	assert.NotEmpty(t, events[0].ID)
+	assert.NotEqual(t, events[0].ID, events[1].ID)
+	assert.False(t, events[0].CreatedAt.IsZero())
+}
+
+func TestLoginRecorder_DoesNotBlockWhenFull(t *testing.T) {
+	store := &memoryLoginStore{block: make(chan struct{})}
+	recorder := NewLoginRecorder(store, 1)
+
+	// One event is being stored and one is queued, so the next one is dropped
+	require.NoError(t, recorder.Record(&LoginEvent{UserID: "user-1"}))
+	require.Eventually(t, func() bool { return len(recorder.events) == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, recorder.Record(&LoginEvent{UserID: "user-1"}))
+	assert.ErrorIs(t, recorder.Record(&LoginEvent{UserID: "user-1"}), ErrBufferFull)
+
+	close(store.block)
+	recorder.Close()
+	assert.Len(t, store.events, 2)
+}
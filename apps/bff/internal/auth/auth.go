@@ -2,22 +2,57 @@ package auth
 
 import (
 	"context"
+	"crypto/cipher"
 	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/metrics"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/redisclient"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/tracing"
 )
 
 type User struct {
 	ID    string `json:"sub"`
 	Email string `json:"email"`
 	Role  string `json:"role"`
+
+	// Projects lists the projects the user owned or belonged to when the token was issued
+	Projects []string `json:"projects,omitempty"`
+
+	// ProjectScope restricts a token to a single project; nil for user tokens
+	ProjectScope *string `json:"project_scope,omitempty"`
+
+	// OrgID is the organization the user acts for, empty for users without one
+	OrgID string `json:"org_id,omitempty"`
+
+	// TOTPVerified is true when the token was issued after a TOTP code was checked
+	TOTPVerified bool `json:"totp_verified,omitempty"`
+
+	// ImpersonatedBy is the super admin acting as the user, nil for the user's
+	// own tokens
+	ImpersonatedBy *string `json:"impersonated_by,omitempty"`
+
+	// Token is the access token the user was authenticated with
+	Token string `json:"-"`
 }
 
+// ImpersonationTTL is the lifetime of impersonation access tokens, which cannot
+// be refreshed
+const ImpersonationTTL = 30 * time.Minute
+
 type TokenPair struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
@@ -26,19 +61,135 @@ type TokenPair struct {
 }
 
 type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
-	Type   string `json:"type"` // "access" or "refresh"
+	UserID       string   `json:"user_id"`
+	Email        string   `json:"email"`
+	Role         string   `json:"role"`
+	Projects     []string `json:"projects"`
+	ProjectScope *string  `json:"project_scope,omitempty"`
+	OrgID        string   `json:"org_id,omitempty"`
+	TOTPVerified bool     `json:"totp_verified,omitempty"`
+	DeviceID     string   `json:"device_id,omitempty"`
+	FamilyID     string   `json:"family_id,omitempty"`
+	Type         string   `json:"type"` // "access" or "refresh"
+
+	// ImpersonatedBy is the ID of the super admin a token was issued to in the
+	// name of the user
+	ImpersonatedBy *string `json:"impersonated_by,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// tokenSubject is the identity and project access encoded into a token pair
+type tokenSubject struct {
+	userID       string
+	email        string
+	role         string
+	projects     []string
+	projectScope *string
+	orgID        string
+	totpVerified bool
+	deviceID     string
+
+	// impersonatedBy is the super admin the pair is issued to, nil when it is
+	// issued to the user
+	impersonatedBy *string
+
+	// familyID is the token family of the refresh token; a new family is started
+	// when it is empty
+	familyID string
+
+	// refreshJTI is the JTI of the refresh token, generated when empty
+	refreshJTI string
+}
+
+// Session is the access token of a user on a device
+type Session struct {
+	DeviceID  string `json:"device_id"`
+	TokenID   string `json:"jti"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+// DeviceID fingerprints the device of a login request from the headers its
+// browser sends with every request
+func DeviceID(r *http.Request) string {
+	fingerprint := strings.Join([]string{
+		r.Header.Get("User-Agent"),
+		r.Header.Get("Accept-Language"),
+		r.Header.Get("Accept-Encoding"),
+	}, "\n")
+	sum := sha256.Sum256([]byte(fingerprint))
+	return hex.EncodeToString(sum[:])
+}
+
+// sessionKey is the Redis key of the session of a user on a device
+func sessionKey(userID, deviceID string) string {
+	return fmt.Sprintf("session:%s:%s", userID, deviceID)
+}
+
+// userSessionsKey is the sorted set of the devices a user has a session on,
+// scored by the Unix time their session expires at, so that they are listed
+// without scanning the keyspace
+func userSessionsKey(userID string) string {
+	return "user_sessions:" + userID
+}
+
+// activeSessionsKey is the sorted set of the sessions of every user, as
+// "{userID}:{deviceID}" scored by the Unix time they expire at
+const activeSessionsKey = "active_sessions"
+
+// TokenReuseError is returned by RefreshTokens for a refresh token that was
+// already exchanged for a newer one. The token family is revoked when it occurs.
+type TokenReuseError struct {
+	UserID   string
+	FamilyID string
+}
+
+func (e *TokenReuseError) Error() string {
+	return "refresh token reuse detected"
+}
+
+// tokenFamilyKey is the Redis key holding the JTI of the latest refresh token of
+// a token family
+func tokenFamilyKey(familyID string) string {
+	return fmt.Sprintf("token_family:%s", familyID)
+}
+
+// userTokenFamilyKey is the Redis key listing a token family among the families
+// of a user
+func userTokenFamilyKey(userID, familyID string) string {
+	return fmt.Sprintf("user_token_family:%s:%s", userID, familyID)
+}
+
+// rotateFamilyScript moves the token family in KEYS[1] from the refresh token
+// ARGV[1] to ARGV[2] for ARGV[3] milliseconds, if it is still at ARGV[1]. Only
+// one of several concurrent refreshes with the same token can move the family.
+const rotateFamilyScript = `
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+return 1
+`
+
+// minRSAKeyBits is the smallest RSA key accepted for signing tokens
+const minRSAKeyBits = 2048
+
 type Service struct {
+	// SigningMethod signs and verifies tokens: HS256 with the JWT secret, or
+	// RS256/RS384 with the RSA key pair
+	SigningMethod jwt.SigningMethod
+
 	jwtSecret     []byte
 	refreshSecret []byte
+	publicKey     *rsa.PublicKey
+	privateKey    *rsa.PrivateKey
 	accessTTL     time.Duration
 	refreshTTL    time.Duration
-	redisClient   *redis.Client
+	redisClient   redisclient.RedisClientInterface
+
+	// userStore holds the TOTP settings of users; tokens are not checked for a
+	// TOTP code when it is nil
+	userStore  UserStore
+	totpCipher cipher.AEAD
 }
 
 func NewService(jwtSecret string) *Service {
@@ -46,6 +197,7 @@ func NewService(jwtSecret string) *Service {
 	refreshSecret := jwtSecret + "_refresh"
 	
 	return &Service{
+		SigningMethod: jwt.SigningMethodHS256,
 		jwtSecret:     []byte(jwtSecret),
 		refreshSecret: []byte(refreshSecret),
 		accessTTL:     15 * time.Minute,  // Short-lived access tokens
@@ -53,26 +205,259 @@ func NewService(jwtSecret string) *Service {
 	}
 }
 
-func NewServiceWithRedis(jwtSecret string, redisClient *redis.Client) *Service {
-	service := NewService(jwtSecret)
-	service.redisClient = redisClient
-	return service
+// NewServiceWithRSAKey creates a service for tokens signed with RS256 using an RSA
+// key pair. Set SigningMethod to jwt.SigningMethodRS384 to use RS384 instead.
+// privateKeyPEM may be nil when tokens are only verified, such as when Supabase
+// issues them; generating tokens then fails.
+func NewServiceWithRSAKey(publicKeyPEM, privateKeyPEM []byte) (*Service, error) {
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse RSA public key: %w", err)
+	}
+
+	var privateKey *rsa.PrivateKey
+	if len(privateKeyPEM) > 0 {
+		privateKey, err = jwt.ParseRSAPrivateKeyFromPEM(privateKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RSA private key: %w", err)
+		}
+		if !privateKey.PublicKey.Equal(publicKey) {
+			return nil, errors.New("RSA private key does not match the public key")
+		}
+	}
+
+	return &Service{
+		SigningMethod: jwt.SigningMethodRS256,
+		publicKey:     publicKey,
+		privateKey:    privateKey,
+		accessTTL:     15 * time.Minute,
+		refreshTTL:    7 * 24 * time.Hour,
+	}, nil
 }
 
-// GenerateTokenPair creates a new access and refresh token pair
-func (s *Service) GenerateTokenPair(userID, email, role string) (*TokenPair, error) {
+// UseRedis enables token revocation and refresh token tracking in Redis. Wrap
+// cluster clients with redisclient.NewClusterClient.
+func (s *Service) UseRedis(redisClient redisclient.RedisClientInterface) {
+	s.redisClient = redisClient
+}
+
+// usesRSA returns true if tokens are signed with the RSA key pair
+func (s *Service) usesRSA() bool {
+	_, ok := s.SigningMethod.(*jwt.SigningMethodRSA)
+	return ok
+}
+
+// signingKey returns the key that signs access tokens, or refresh tokens when refresh is set
+func (s *Service) signingKey(refresh bool) (interface{}, error) {
+	if s.usesRSA() {
+		if s.privateKey == nil {
+			return nil, errors.New("RSA private key not configured")
+		}
+		return s.privateKey, nil
+	}
+
 	if len(s.jwtSecret) < 32 {
 		return nil, errors.New("JWT secret must be at least 32 bytes for security")
 	}
+	if refresh {
+		return s.refreshSecret, nil
+	}
+	return s.jwtSecret, nil
+}
 
+// keyFunc returns the function that checks a token was signed with the kind of
+// key the service uses and returns the key verifying it. RSA services accept both
+// RS256 and RS384 tokens.
+func (s *Service) keyFunc(refresh bool) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if s.usesRSA() {
+			if alg := token.Method.Alg(); alg != jwt.SigningMethodRS256.Alg() && alg != jwt.SigningMethodRS384.Alg() {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return s.publicKey, nil
+		}
+
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		if refresh {
+			return s.refreshSecret, nil
+		}
+		return s.jwtSecret, nil
+	}
+}
+
+func NewServiceWithRedis(jwtSecret string, redisClient redisclient.RedisClientInterface) *Service {
+	service := NewService(jwtSecret)
+	service.UseRedis(redisClient)
+	return service
+}
+
+// GenerateTokenPair creates a new access and refresh token pair. The tokens carry
+// the IDs of the projects the user owns or is a member of, looked up in db.
+func (s *Service) GenerateTokenPair(db *sql.DB, userID, email, role string) (*TokenPair, error) {
+	subject, err := userSubject(db, userID, email, role)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.generateTokenPair(subject)
+}
+
+// GenerateImpersonationTokenPair creates an access token for the super admin
+// adminUserID to act as the user userID, carrying the admin in its
+// impersonated_by claim. It expires after ImpersonationTTL and comes without a
+// refresh token, so the session cannot outlive it.
+func (s *Service) GenerateImpersonationTokenPair(db *sql.DB, userID, email, role, adminUserID string) (*TokenPair, error) {
+	subject, err := userSubject(db, userID, email, role)
+	if err != nil {
+		return nil, err
+	}
+
+	subject.impersonatedBy = &adminUserID
+	return s.generateTokenPair(subject)
+}
+
+// GenerateDeviceTokenPair creates a token pair like GenerateTokenPair for a login
+// from the device identified by deviceID, usually computed with DeviceID. With
+// Redis the login is tracked as the session of the device, and the tokens are
+// rejected once the session is revoked.
+func (s *Service) GenerateDeviceTokenPair(db *sql.DB, userID, email, role, deviceID string) (*TokenPair, error) {
+	subject, err := userSubject(db, userID, email, role)
+	if err != nil {
+		return nil, err
+	}
+
+	subject.deviceID = deviceID
+	return s.generateTokenPair(subject)
+}
+
+// GenerateProjectTokenPair creates a token pair scoped to a single project, such as
+// an API key. The user must own or be a member of the project.
+func (s *Service) GenerateProjectTokenPair(db *sql.DB, userID, email, role, projectID string) (*TokenPair, error) {
+	subject, err := projectSubject(db, userID, email, role, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.generateTokenPair(subject)
+}
+
+// userSubject returns the subject of a token pair giving the user access to the
+// projects they own or are a member of, acting for the organization they joined
+// first
+func userSubject(db *sql.DB, userID, email, role string) (tokenSubject, error) {
+	projects, err := userProjects(db, userID)
+	if err != nil {
+		return tokenSubject{}, err
+	}
+
+	var orgID sql.NullString
+	err = db.QueryRow(`
+		SELECT org_id FROM organization_members WHERE user_id = $1
+		ORDER BY created_at, org_id LIMIT 1
+	`, userID).Scan(&orgID)
+	if err != nil && err != sql.ErrNoRows {
+		return tokenSubject{}, fmt.Errorf("failed to query user organization: %w", err)
+	}
+
+	return tokenSubject{
+		userID:   userID,
+		email:    email,
+		role:     role,
+		projects: projects,
+		orgID:    orgID.String,
+	}, nil
+}
+
+// projectSubject returns the subject of a token pair scoped to a project the user
+// owns or is a member of
+func projectSubject(db *sql.DB, userID, email, role, projectID string) (tokenSubject, error) {
+	projects, err := userProjects(db, userID)
+	if err != nil {
+		return tokenSubject{}, err
+	}
+
+	member := false
+	for _, id := range projects {
+		if id == projectID {
+			member = true
+			break
+		}
+	}
+	if !member {
+		return tokenSubject{}, errors.New("user is not a member of the project")
+	}
+
+	var orgID sql.NullString
+	err = db.QueryRow(`SELECT org_id FROM projects WHERE id = $1`, projectID).Scan(&orgID)
+	if err != nil {
+		return tokenSubject{}, fmt.Errorf("failed to query project organization: %w", err)
+	}
+
+	return tokenSubject{
+		userID:       userID,
+		email:        email,
+		role:         role,
+		projects:     []string{projectID},
+		projectScope: &projectID,
+		orgID:        orgID.String,
+	}, nil
+}
+
+// userProjects returns the IDs of the projects a user owns or is a member of,
+// directly or through their organizations. Projects of an organization are
+// only returned to its members.
+func userProjects(db *sql.DB, userID string) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT p.id FROM projects p
+		WHERE p.org_id IN (SELECT org_id FROM organization_members WHERE user_id = $1)
+			OR (
+				p.org_id IS NULL
+				AND (p.owner_id = $1 OR p.id IN (SELECT project_id FROM project_members WHERE user_id = $1))
+			)
+		ORDER BY 1
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user project: %w", err)
+		}
+		projects = append(projects, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query user projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+// generateTokenPair mints an access and refresh token pair for subject. The
+// pairs of impersonation sessions have no refresh token.
+func (s *Service) generateTokenPair(subject tokenSubject) (*TokenPair, error) {
 	// Generate access token
-	accessToken, err := s.generateAccessToken(userID, email, role)
+	accessToken, err := s.generateAccessToken(subject)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
+	if subject.impersonatedBy != nil {
+		return &TokenPair{
+			AccessToken: accessToken,
+			ExpiresIn:   int(ImpersonationTTL.Seconds()),
+			TokenType:   "Bearer",
+		}, nil
+	}
+
 	// Generate refresh token
-	refreshToken, err := s.generateRefreshToken(userID, email, role)
+	refreshToken, err := s.generateRefreshToken(subject)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
@@ -86,45 +471,101 @@ func (s *Service) GenerateTokenPair(userID, email, role string) (*TokenPair, err
 }
 
 // generateAccessToken creates a short-lived access token
-func (s *Service) generateAccessToken(userID, email, role string) (string, error) {
+func (s *Service) generateAccessToken(subject tokenSubject) (string, error) {
 	now := time.Now()
+
+	jti, err := s.generateJTI()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate JTI: %w", err)
+	}
+
+	ttl := s.accessTTL
+	if subject.impersonatedBy != nil {
+		ttl = ImpersonationTTL
+	}
+
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
-		Type:   "access",
+		UserID:         subject.userID,
+		Email:          subject.email,
+		Role:           subject.role,
+		Projects:       subject.projects,
+		ProjectScope:   subject.projectScope,
+		OrgID:          subject.orgID,
+		TOTPVerified:   subject.totpVerified,
+		DeviceID:       subject.deviceID,
+		ImpersonatedBy: subject.impersonatedBy,
+		Type:           "access",
 		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   userID,
+			ID:        jti,
+			Subject:   subject.userID,
 			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTTL)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "zamc-bff",
 			Audience:  []string{"zamc-web"},
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.jwtSecret)
+	key, err := s.signingKey(false)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(s.SigningMethod, claims)
+	tokenString, err := token.SignedString(key)
+	if err != nil {
+		return "", err
+	}
+
+	// The device's session points at its latest access token
+	if s.redisClient != nil && subject.deviceID != "" {
+		if err := s.storeSession(context.Background(), subject.userID, subject.deviceID, jti); err != nil {
+			return "", err
+		}
+	}
+
+	return tokenString, nil
 }
 
 // generateRefreshToken creates a long-lived refresh token
-func (s *Service) generateRefreshToken(userID, email, role string) (string, error) {
+func (s *Service) generateRefreshToken(subject tokenSubject) (string, error) {
 	now := time.Now()
+
+	key, err := s.signingKey(true)
+	if err != nil {
+		return "", err
+	}
 	
 	// Generate a unique JTI for the refresh token
-	jti, err := s.generateJTI()
-	if err != nil {
-		return "", fmt.Errorf("failed to generate JTI: %w", err)
+	jti := subject.refreshJTI
+	if jti == "" {
+		jti, err = s.generateJTI()
+		if err != nil {
+			return "", fmt.Errorf("failed to generate JTI: %w", err)
+		}
+	}
+
+	// A login starts a token family, which every refresh carries on
+	familyID := subject.familyID
+	if familyID == "" {
+		familyID = uuid.New().String()
 	}
 
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
-		Type:   "refresh",
+		UserID:         subject.userID,
+		Email:          subject.email,
+		Role:           subject.role,
+		Projects:       subject.projects,
+		ProjectScope:   subject.projectScope,
+		OrgID:          subject.orgID,
+		TOTPVerified:   subject.totpVerified,
+		DeviceID:       subject.deviceID,
+		ImpersonatedBy: subject.impersonatedBy,
+		FamilyID:       familyID,
+		Type:           "refresh",
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        jti,
-			Subject:   userID,
+			Subject:   subject.userID,
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(now.Add(s.refreshTTL)),
 			NotBefore: jwt.NewNumericDate(now),
@@ -133,29 +574,100 @@ func (s *Service) generateRefreshToken(userID, email, role string) (string, erro
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(s.refreshSecret)
+	token := jwt.NewWithClaims(s.SigningMethod, claims)
+	tokenString, err := token.SignedString(key)
 	if err != nil {
 		return "", err
 	}
 
-	// Store refresh token in Redis if available
+	// Store refresh token in Redis if available, with the device it was issued
+	// to so that revoking the device's session revokes it too
 	if s.redisClient != nil {
 		ctx := context.Background()
-		key := fmt.Sprintf("refresh_token:%s:%s", userID, jti)
-		err = s.redisClient.Set(ctx, key, "valid", s.refreshTTL).Err()
+		key := fmt.Sprintf("refresh_token:%s:%s", subject.userID, jti)
+		value := "valid"
+		if subject.deviceID != "" {
+			value = subject.deviceID
+		}
+		err = s.redisClient.Set(ctx, key, value, s.refreshTTL).Err()
 		if err != nil {
 			// Log error but don't fail - token blacklisting is optional
 			fmt.Printf("Warning: Failed to store refresh token in Redis: %v\n", err)
 		}
+
+		// The family moves on to this token, so that presenting an earlier one
+		// is detected as reuse
+		err = s.redisClient.Set(ctx, tokenFamilyKey(familyID), jti, s.refreshTTL).Err()
+		if err != nil {
+			return "", fmt.Errorf("failed to store token family: %w", err)
+		}
+		err = s.redisClient.Set(ctx, userTokenFamilyKey(subject.userID, familyID), jti, s.refreshTTL).Err()
+		if err != nil {
+			return "", fmt.Errorf("failed to store token family: %w", err)
+		}
 	}
 
 	return tokenString, nil
 }
 
-// VerifyToken validates and parses a JWT token
+// VerifyToken validates and parses a JWT token. When a user store is configured
+// and the user has enabled two-factor authentication, ErrTOTPRequired is returned
+// for tokens issued before a TOTP code was checked. Tokens issued to a device are
+// rejected with "session terminated" once the device's session is revoked.
 func (s *Service) VerifyToken(tokenString string) (*User, error) {
-	if len(s.jwtSecret) == 0 {
+	return s.VerifyTokenContext(context.Background(), tokenString)
+}
+
+// VerifyTokenContext is VerifyToken traced as a child of the span in ctx
+func (s *Service) VerifyTokenContext(ctx context.Context, tokenString string) (user *User, err error) {
+	_, span := tracing.Tracer.Start(ctx, "auth.VerifyToken")
+	defer func() { tracing.End(span, err) }()
+
+	start := time.Now()
+	user, err = s.verifyToken(ctx, tokenString)
+
+	result := "valid"
+	if err != nil {
+		result = "invalid"
+	}
+	metrics.TokenVerificationDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+
+	return user, err
+}
+
+func (s *Service) verifyToken(ctx context.Context, tokenString string) (*User, error) {
+	claims, err := s.parseAccessToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.redisClient != nil && claims.DeviceID != "" {
+		err := s.redisClient.Get(ctx, sessionKey(claims.UserID, claims.DeviceID)).Err()
+		if err == redis.Nil {
+			return nil, errors.New("session terminated")
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to check session: %w", err)
+		}
+	}
+
+	if !claims.TOTPVerified && s.userStore != nil {
+		required, err := s.userStore.TOTPRequired(ctx, claims.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if required {
+			return nil, ErrTOTPRequired
+		}
+	}
+
+	user := claimsUser(claims)
+	user.Token = tokenString
+	return user, nil
+}
+
+// parseAccessToken validates an access token and returns its claims
+func (s *Service) parseAccessToken(tokenString string) (*Claims, error) {
+	if len(s.jwtSecret) == 0 && s.publicKey == nil {
 		return nil, errors.New("JWT secret not configured")
 	}
 
@@ -164,13 +676,7 @@ func (s *Service) VerifyToken(tokenString string) (*User, error) {
 		return nil, errors.New("token has been revoked")
 	}
 
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return s.jwtSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, s.keyFunc(false))
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -190,28 +696,56 @@ func (s *Service) VerifyToken(tokenString string) (*User, error) {
 		return nil, errors.New("invalid token type")
 	}
 
-	user := &User{
-		ID:    claims.UserID,
-		Email: claims.Email,
-		Role:  claims.Role,
+	return claims, nil
+}
+
+// TokenSubject returns the ID of the user an access token signed by the
+// service is issued to, even if the token has expired or been revoked, or an
+// empty string when its signature is not valid. It attributes failed
+// verifications to a user, so that forged tokens cannot pin failures on
+// someone else, and must never authenticate one.
+func (s *Service) TokenSubject(tokenString string) string {
+	if len(s.jwtSecret) == 0 && s.publicKey == nil {
+		return ""
 	}
 
-	return user, nil
+	var claims Claims
+	if _, err := jwt.ParseWithClaims(tokenString, &claims, s.keyFunc(false), jwt.WithoutClaimsValidation()); err != nil {
+		return ""
+	}
+	return claims.UserID
+}
+
+// claimsUser returns the user identified by claims
+func claimsUser(claims *Claims) *User {
+	return &User{
+		ID:             claims.UserID,
+		Email:          claims.Email,
+		Role:           claims.Role,
+		Projects:       claims.Projects,
+		ProjectScope:   claims.ProjectScope,
+		OrgID:          claims.OrgID,
+		TOTPVerified:   claims.TOTPVerified,
+		ImpersonatedBy: claims.ImpersonatedBy,
+	}
 }
 
-// RefreshTokens validates a refresh token and generates a new token pair
-func (s *Service) RefreshTokens(refreshTokenString string) (*TokenPair, error) {
-	if len(s.refreshSecret) == 0 {
+// RefreshTokens validates a refresh token and generates a new token pair. Project
+// memberships are looked up again in db, so membership changes take effect on
+// refresh; a project-scoped pair stays scoped and fails once access is lost. A
+// pair issued after a TOTP code was checked stays TOTP verified.
+//
+// With Redis, each refresh token belongs to the token family started by its
+// login. A refresh token older than the latest one of its family has been used
+// before, possibly by someone who stole it: the family and all refresh tokens
+// of the user are revoked and a *TokenReuseError is returned.
+func (s *Service) RefreshTokens(db *sql.DB, refreshTokenString string) (*TokenPair, error) {
+	if len(s.refreshSecret) == 0 && s.publicKey == nil {
 		return nil, errors.New("refresh secret not configured")
 	}
 
 	// Parse refresh token
-	token, err := jwt.ParseWithClaims(refreshTokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return s.refreshSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(refreshTokenString, &Claims{}, s.keyFunc(true))
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse refresh token: %w", err)
@@ -231,6 +765,29 @@ func (s *Service) RefreshTokens(refreshTokenString string) (*TokenPair, error) {
 		return nil, errors.New("invalid token type")
 	}
 
+	// Impersonation sessions end when their access token expires
+	if claims.ImpersonatedBy != nil {
+		return nil, errors.New("impersonation tokens cannot be refreshed")
+	}
+
+	// Check that the token is the latest of its family
+	if s.redisClient != nil && claims.FamilyID != "" {
+		ctx := context.Background()
+		current, err := s.redisClient.Get(ctx, tokenFamilyKey(claims.FamilyID)).Result()
+		if err == redis.Nil {
+			return nil, errors.New("refresh token has been revoked")
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read token family: %w", err)
+		}
+
+		if current != claims.ID {
+			if err := s.revokeTokenFamily(ctx, claims.UserID, claims.FamilyID); err != nil {
+				return nil, err
+			}
+			return nil, &TokenReuseError{UserID: claims.UserID, FamilyID: claims.FamilyID}
+		}
+	}
+
 	// Check if refresh token is still valid in Redis
 	if s.redisClient != nil && claims.ID != "" {
 		ctx := context.Background()
@@ -241,6 +798,38 @@ func (s *Service) RefreshTokens(refreshTokenString string) (*TokenPair, error) {
 		}
 	}
 
+	// Generate new token pair
+	subject, err := claimsSubject(db, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// Move the family on to the new refresh token, unless a concurrent refresh
+	// with the same token got there first
+	if s.redisClient != nil && claims.FamilyID != "" {
+		subject.refreshJTI, err = s.generateJTI()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate JTI: %w", err)
+		}
+
+		ctx := context.Background()
+		rotated, err := s.rotateTokenFamily(ctx, claims.FamilyID, claims.ID, subject.refreshJTI)
+		if err != nil {
+			return nil, err
+		}
+		if !rotated {
+			if err := s.revokeTokenFamily(ctx, claims.UserID, claims.FamilyID); err != nil {
+				return nil, err
+			}
+			return nil, &TokenReuseError{UserID: claims.UserID, FamilyID: claims.FamilyID}
+		}
+	}
+
+	pair, err := s.generateTokenPair(subject)
+	if err != nil {
+		return nil, err
+	}
+
 	// Invalidate the old refresh token
 	if s.redisClient != nil && claims.ID != "" {
 		ctx := context.Background()
@@ -248,8 +837,72 @@ func (s *Service) RefreshTokens(refreshTokenString string) (*TokenPair, error) {
 		s.redisClient.Del(ctx, key)
 	}
 
-	// Generate new token pair
-	return s.GenerateTokenPair(claims.UserID, claims.Email, claims.Role)
+	return pair, nil
+}
+
+// claimsSubject returns the subject of a new token pair for the user of claims,
+// with the project memberships looked up again in db
+func claimsSubject(db *sql.DB, claims *Claims) (tokenSubject, error) {
+	var subject tokenSubject
+	var err error
+	if claims.ProjectScope != nil {
+		subject, err = projectSubject(db, claims.UserID, claims.Email, claims.Role, *claims.ProjectScope)
+	} else {
+		subject, err = userSubject(db, claims.UserID, claims.Email, claims.Role)
+	}
+	if err != nil {
+		return tokenSubject{}, err
+	}
+
+	subject.totpVerified = claims.TOTPVerified
+	subject.deviceID = claims.DeviceID
+	subject.familyID = claims.FamilyID
+	return subject, nil
+}
+
+// rotateTokenFamily atomically moves a token family from the refresh token
+// fromJTI to toJTI. It returns false when the family is no longer at fromJTI.
+func (s *Service) rotateTokenFamily(ctx context.Context, familyID, fromJTI, toJTI string) (bool, error) {
+	rotated, err := s.redisClient.Eval(ctx, rotateFamilyScript, []string{tokenFamilyKey(familyID)},
+		fromJTI, toJTI, s.refreshTTL.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to rotate token family: %w", err)
+	}
+	return rotated == 1, nil
+}
+
+// revokeTokenFamily deletes a token family of the user along with all of the
+// user's refresh tokens
+func (s *Service) revokeTokenFamily(ctx context.Context, userID, familyID string) error {
+	keys, err := s.redisClient.Keys(ctx, fmt.Sprintf("refresh_token:%s:*", userID)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to find user tokens: %w", err)
+	}
+	keys = append(keys, tokenFamilyKey(familyID), userTokenFamilyKey(userID, familyID))
+
+	if err := s.redisClient.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to revoke token family: %w", err)
+	}
+	return nil
+}
+
+// GetActiveFamilies returns the IDs of the token families of the user whose
+// refresh tokens have not expired or been revoked
+func (s *Service) GetActiveFamilies(userID string) ([]string, error) {
+	if s.redisClient == nil {
+		return nil, errors.New("token families require Redis")
+	}
+
+	keys, err := s.redisClient.Keys(context.Background(), userTokenFamilyKey(userID, "*")).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find token families: %w", err)
+	}
+
+	families := make([]string, 0, len(keys))
+	for _, key := range keys {
+		families = append(families, strings.TrimPrefix(key, userTokenFamilyKey(userID, "")))
+	}
+	return families, nil
 }
 
 // RevokeToken adds a token to the blacklist
@@ -259,9 +912,7 @@ func (s *Service) RevokeToken(tokenString string) error {
 	}
 
 	// Parse token to get expiration
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return s.jwtSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, s.keyFunc(false))
 
 	if err != nil {
 		// Even if parsing fails, we should blacklist the token
@@ -282,7 +933,8 @@ func (s *Service) RevokeToken(tokenString string) error {
 	return s.blacklistToken(tokenString, ttl)
 }
 
-// RevokeAllUserTokens revokes all tokens for a specific user
+// RevokeAllUserTokens revokes all tokens for a specific user, terminating the
+// sessions of all their devices and their token families
 func (s *Service) RevokeAllUserTokens(userID string) error {
 	if s.redisClient == nil {
 		return errors.New("token revocation requires Redis")
@@ -290,23 +942,172 @@ func (s *Service) RevokeAllUserTokens(userID string) error {
 
 	ctx := context.Background()
 	
-	// Remove all refresh tokens for the user
-	pattern := fmt.Sprintf("refresh_token:%s:*", userID)
-	keys, err := s.redisClient.Keys(ctx, pattern).Result()
+	families, err := s.GetActiveFamilies(userID)
 	if err != nil {
-		return fmt.Errorf("failed to find user tokens: %w", err)
+		return err
+	}
+
+	devices, err := s.redisClient.ZRange(ctx, userSessionsKey(userID), 0, -1).Result()
+	if err != nil {
+		return fmt.Errorf("failed to find sessions: %w", err)
+	}
+
+	// Remove all refresh tokens, sessions and token families for the user
+	keys := []string{userSessionsKey(userID)}
+	for _, familyID := range families {
+		keys = append(keys, tokenFamilyKey(familyID))
+	}
+	for _, deviceID := range devices {
+		keys = append(keys, sessionKey(userID, deviceID))
+	}
+	for _, pattern := range []string{fmt.Sprintf("refresh_token:%s:*", userID), userTokenFamilyKey(userID, "*")} {
+		matched, err := s.redisClient.Keys(ctx, pattern).Result()
+		if err != nil {
+			return fmt.Errorf("failed to find user tokens: %w", err)
+		}
+		keys = append(keys, matched...)
 	}
 
 	if len(keys) > 0 {
-		err = s.redisClient.Del(ctx, keys...).Err()
+		err := s.redisClient.Del(ctx, keys...).Err()
 		if err != nil {
 			return fmt.Errorf("failed to revoke user tokens: %w", err)
 		}
 	}
 
+	if len(devices) > 0 {
+		members := make([]interface{}, len(devices))
+		for i, deviceID := range devices {
+			members[i] = userID + ":" + deviceID
+		}
+		if err := s.redisClient.ZRem(ctx, activeSessionsKey, members...).Err(); err != nil {
+			return fmt.Errorf("failed to revoke user sessions: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// storeSession points the session of the user on a device at the access token
+// jti, and records the device among the sessions of the user
+func (s *Service) storeSession(ctx context.Context, userID, deviceID, jti string) error {
+	if err := s.redisClient.Set(ctx, sessionKey(userID, deviceID), jti, s.accessTTL).Err(); err != nil {
+		return fmt.Errorf("failed to store session: %w", err)
+	}
+
+	expiresAt := float64(time.Now().Add(s.accessTTL).Unix())
+	if err := s.redisClient.ZAdd(ctx, userSessionsKey(userID), &redis.Z{Score: expiresAt, Member: deviceID}).Err(); err != nil {
+		return fmt.Errorf("failed to store session: %w", err)
+	}
+	if err := s.redisClient.Expire(ctx, userSessionsKey(userID), s.accessTTL).Err(); err != nil {
+		return fmt.Errorf("failed to store session: %w", err)
+	}
+	if err := s.redisClient.ZAdd(ctx, activeSessionsKey, &redis.Z{Score: expiresAt, Member: userID + ":" + deviceID}).Err(); err != nil {
+		return fmt.Errorf("failed to store session: %w", err)
+	}
+
+	return nil
+}
+
+// pruneSessions removes the sessions that expired from the sorted set key
+func (s *Service) pruneSessions(ctx context.Context, key string) error {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	if err := s.redisClient.ZRemRangeByScore(ctx, key, "-inf", "("+now).Err(); err != nil {
+		return fmt.Errorf("failed to prune expired sessions: %w", err)
+	}
 	return nil
 }
 
+// Sessions returns the sessions of the devices the user is logged in on
+func (s *Service) Sessions(ctx context.Context, userID string) ([]Session, error) {
+	if s.redisClient == nil {
+		return nil, errors.New("session tracking requires Redis")
+	}
+
+	if err := s.pruneSessions(ctx, userSessionsKey(userID)); err != nil {
+		return nil, err
+	}
+	devices, err := s.redisClient.ZRange(ctx, userSessionsKey(userID), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sessions: %w", err)
+	}
+
+	sessions := []Session{}
+	for _, deviceID := range devices {
+		key := sessionKey(userID, deviceID)
+		jti, err := s.redisClient.Get(ctx, key).Result()
+		if err == redis.Nil {
+			continue // Expired since it was listed
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read session: %w", err)
+		}
+
+		ttl, err := s.redisClient.TTL(ctx, key).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session: %w", err)
+		}
+
+		sessions = append(sessions, Session{
+			DeviceID:  deviceID,
+			TokenID:   jti,
+			ExpiresIn: int(ttl.Seconds()),
+		})
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession terminates the session of the user on a device, rejecting its
+// access tokens and revoking its refresh tokens. It returns false when the
+// device has no session.
+func (s *Service) RevokeSession(ctx context.Context, userID, deviceID string) (bool, error) {
+	if s.redisClient == nil {
+		return false, errors.New("session tracking requires Redis")
+	}
+
+	deleted, err := s.redisClient.Del(ctx, sessionKey(userID, deviceID)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if err := s.redisClient.ZRem(ctx, userSessionsKey(userID), deviceID).Err(); err != nil {
+		return false, fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if err := s.redisClient.ZRem(ctx, activeSessionsKey, userID+":"+deviceID).Err(); err != nil {
+		return false, fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	keys, err := s.redisClient.Keys(ctx, fmt.Sprintf("refresh_token:%s:*", userID)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to find user tokens: %w", err)
+	}
+	for _, key := range keys {
+		if s.redisClient.Get(ctx, key).Val() != deviceID {
+			continue
+		}
+		if err := s.redisClient.Del(ctx, key).Err(); err != nil {
+			return false, fmt.Errorf("failed to revoke session: %w", err)
+		}
+	}
+
+	return deleted > 0, nil
+}
+
+// SessionCount returns the number of active sessions of all users
+func (s *Service) SessionCount(ctx context.Context) (int, error) {
+	if s.redisClient == nil {
+		return 0, errors.New("session tracking requires Redis")
+	}
+
+	if err := s.pruneSessions(ctx, activeSessionsKey); err != nil {
+		return 0, err
+	}
+	count, err := s.redisClient.ZCard(ctx, activeSessionsKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count sessions: %w", err)
+	}
+	return int(count), nil
+}
+
 // blacklistToken adds a token to the blacklist with TTL
 func (s *Service) blacklistToken(tokenString string, ttl time.Duration) error {
 	ctx := context.Background()
@@ -341,8 +1142,18 @@ func (s *Service) GetUserFromContext(ctx interface{}) (*User, bool) {
 	return user, ok
 }
 
-// ValidateTokenStrength ensures JWT secret meets security requirements
+// ValidateTokenStrength ensures the JWT secret, or the RSA keys, meet security requirements
 func (s *Service) ValidateTokenStrength() error {
+	if s.usesRSA() {
+		if s.publicKey == nil {
+			return errors.New("RSA public key not configured")
+		}
+		if bits := s.publicKey.N.BitLen(); bits < minRSAKeyBits {
+			return fmt.Errorf("RSA key must be at least %d bits for security, got %d", minRSAKeyBits, bits)
+		}
+		return nil
+	}
+
 	if len(s.jwtSecret) < 32 {
 		return errors.New("JWT secret must be at least 32 bytes (256 bits) for security")
 	}
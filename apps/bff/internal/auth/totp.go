@@ -0,0 +1,432 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image/png"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// totpIssuer names the BFF in authenticator apps
+const totpIssuer = "ZAMC"
+
+// totpQRCodeSize is the width and height of the enrollment QR code in pixels
+const totpQRCodeSize = 256
+
+const (
+	// totpPeriod is how long each code is valid for; the codes of the previous
+	// and next periods are accepted too, for clock drift
+	totpPeriod = 30 * time.Second
+
+	// maxTOTPFailures is the number of invalid codes after which a user's codes
+	// are refused until totpLockout has passed since the first of them
+	maxTOTPFailures = 5
+	totpLockout     = 15 * time.Minute
+)
+
+// useTOTPStepScript records the time step ARGV[1] as the last one a code was
+// accepted for in KEYS[1], for ARGV[2] milliseconds, unless it is not later than
+// the one recorded. A code can therefore only be used once.
+const useTOTPStepScript = `
+local last = tonumber(redis.call("GET", KEYS[1]) or "-1")
+if last >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return 1
+`
+
+var (
+	// ErrTOTPRequired is returned by VerifyToken when the user has enabled
+	// two-factor authentication and the token was issued without a TOTP code
+	ErrTOTPRequired = errors.New("TOTP verification required")
+
+	// ErrInvalidTOTPCode is returned when a TOTP code does not match the user's
+	// secret, or was already used
+	ErrInvalidTOTPCode = errors.New("invalid TOTP code")
+
+	// ErrTOTPLocked is returned while a user is locked out after too many invalid
+	// TOTP codes
+	ErrTOTPLocked = errors.New("too many invalid TOTP codes")
+
+	// ErrTOTPNotEnrolled is returned when the user has no TOTP secret
+	ErrTOTPNotEnrolled = errors.New("TOTP is not enrolled")
+
+	// ErrTOTPEnabled is returned when enrolling a user who already requires TOTP
+	ErrTOTPEnabled = errors.New("TOTP is already enabled")
+
+	// ErrTOTPNotConfigured is returned when no user store or encryption key is set
+	ErrTOTPNotConfigured = errors.New("TOTP is not configured")
+)
+
+// UserStore keeps the two-factor authentication settings of users. Secrets are
+// stored encrypted by the service.
+type UserStore interface {
+	// TOTPRequired returns true if the user's tokens must be confirmed with a TOTP code
+	TOTPRequired(ctx context.Context, userID string) (bool, error)
+
+	// TOTPSecret returns the encrypted TOTP secret of the user, or ErrTOTPNotEnrolled
+	TOTPSecret(ctx context.Context, userID string) ([]byte, error)
+
+	// SaveTOTPSecret stores the encrypted TOTP secret of the user, replacing any
+	// previous one
+	SaveTOTPSecret(ctx context.Context, userID string, secret []byte) error
+
+	// RequireTOTP requires the user's tokens to be confirmed with a TOTP code
+	RequireTOTP(ctx context.Context, userID string) error
+
+	// DeleteTOTPSecret removes the TOTP secret of the user and stops requiring TOTP
+	DeleteTOTPSecret(ctx context.Context, userID string) error
+}
+
+// DBUserStore keeps TOTP secrets in the user_totp_secrets table and whether TOTP
+// is required in the users table
+type DBUserStore struct {
+	db *sql.DB
+}
+
+// NewDBUserStore creates a user store for db
+func NewDBUserStore(db *sql.DB) *DBUserStore {
+	return &DBUserStore{db: db}
+}
+
+// TOTPRequired returns true if the user's tokens must be confirmed with a TOTP code
+func (s *DBUserStore) TOTPRequired(ctx context.Context, userID string) (bool, error) {
+	var required bool
+	err := s.db.QueryRowContext(ctx, `SELECT totp_required FROM users WHERE id = $1`, userID).Scan(&required)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to check TOTP requirement: %w", err)
+	}
+
+	return required, nil
+}
+
+// TOTPSecret returns the encrypted TOTP secret of the user, or ErrTOTPNotEnrolled
+func (s *DBUserStore) TOTPSecret(ctx context.Context, userID string) ([]byte, error) {
+	var secret []byte
+	err := s.db.QueryRowContext(ctx, `SELECT secret_encrypted FROM user_totp_secrets WHERE user_id = $1`, userID).Scan(&secret)
+	if err == sql.ErrNoRows {
+		return nil, ErrTOTPNotEnrolled
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get TOTP secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// SaveTOTPSecret stores the encrypted TOTP secret of the user, replacing any
+// previous one
+func (s *DBUserStore) SaveTOTPSecret(ctx context.Context, userID string, secret []byte) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO user_totp_secrets (user_id, secret_encrypted) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET secret_encrypted = EXCLUDED.secret_encrypted, created_at = NOW()
+	`, userID, secret)
+	if err != nil {
+		return fmt.Errorf("failed to save TOTP secret: %w", err)
+	}
+
+	return nil
+}
+
+// RequireTOTP requires the user's tokens to be confirmed with a TOTP code
+func (s *DBUserStore) RequireTOTP(ctx context.Context, userID string) error {
+	if _, err := s.db.ExecContext(ctx, `UPDATE users SET totp_required = TRUE WHERE id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to require TOTP: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteTOTPSecret removes the TOTP secret of the user and stops requiring TOTP
+func (s *DBUserStore) DeleteTOTPSecret(ctx context.Context, userID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_totp_secrets WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete TOTP secret: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET totp_required = FALSE WHERE id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to stop requiring TOTP: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// TOTPEnrollment is what a user needs to add their TOTP secret to an
+// authenticator app
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+
+	// QRCode is a PNG data URL of the provisioning URI
+	QRCode string `json:"qr_code"`
+}
+
+// UseUserStore enables two-factor authentication: VerifyToken rejects tokens of
+// users requiring TOTP unless they were issued after a TOTP code was checked.
+// totpKey is the base64 encoded 32 byte AES key encrypting the TOTP secrets.
+func (s *Service) UseUserStore(store UserStore, totpKey string) error {
+	key, err := base64.StdEncoding.DecodeString(totpKey)
+	if err != nil {
+		return fmt.Errorf("failed to decode TOTP encryption key: %w", err)
+	}
+	if len(key) != 32 {
+		return fmt.Errorf("TOTP encryption key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return fmt.Errorf("failed to create block cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return fmt.Errorf("failed to create GCM cipher: %w", err)
+	}
+
+	s.userStore = store
+	s.totpCipher = aead
+	return nil
+}
+
+// EnrollTOTP generates a TOTP secret for user, replacing the one of an enrollment
+// that was not confirmed. TOTP is only required once a code is checked with
+// VerifyTOTP.
+func (s *Service) EnrollTOTP(ctx context.Context, user *User) (*TOTPEnrollment, error) {
+	if s.userStore == nil {
+		return nil, ErrTOTPNotConfigured
+	}
+
+	required, err := s.userStore.TOTPRequired(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if required {
+		return nil, ErrTOTPEnabled
+	}
+
+	accountName := user.Email
+	if accountName == "" {
+		accountName = user.ID
+	}
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: totpIssuer, AccountName: accountName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+
+	sealed, err := s.encryptTOTPSecret(user.ID, key.Secret())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userStore.SaveTOTPSecret(ctx, user.ID, sealed); err != nil {
+		return nil, err
+	}
+
+	image, err := key.Image(totpQRCodeSize, totpQRCodeSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render TOTP QR code: %w", err)
+	}
+	var qrCode bytes.Buffer
+	if err := png.Encode(&qrCode, image); err != nil {
+		return nil, fmt.Errorf("failed to encode TOTP QR code: %w", err)
+	}
+
+	return &TOTPEnrollment{
+		Secret:          key.Secret(),
+		ProvisioningURI: key.URL(),
+		QRCode:          "data:image/png;base64," + base64.StdEncoding.EncodeToString(qrCode.Bytes()),
+	}, nil
+}
+
+// VerifyTOTP checks code against the TOTP secret of the user of the access token
+// and issues a TOTP verified token pair. The first code checked after enrolling
+// turns on two-factor authentication for the user.
+func (s *Service) VerifyTOTP(ctx context.Context, db *sql.DB, tokenString, code string) (*TokenPair, error) {
+	if s.userStore == nil {
+		return nil, ErrTOTPNotConfigured
+	}
+
+	claims, err := s.parseAccessToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkTOTPCode(ctx, claims.UserID, code); err != nil {
+		return nil, err
+	}
+
+	required, err := s.userStore.TOTPRequired(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !required {
+		if err := s.userStore.RequireTOTP(ctx, claims.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	subject, err := claimsSubject(db, claims)
+	if err != nil {
+		return nil, err
+	}
+	subject.totpVerified = true
+
+	return s.generateTokenPair(subject)
+}
+
+// DisableTOTP removes the TOTP secret of user after checking code against it
+func (s *Service) DisableTOTP(ctx context.Context, user *User, code string) error {
+	if s.userStore == nil {
+		return ErrTOTPNotConfigured
+	}
+
+	if err := s.checkTOTPCode(ctx, user.ID, code); err != nil {
+		return err
+	}
+
+	return s.userStore.DeleteTOTPSecret(ctx, user.ID)
+}
+
+// checkTOTPCode returns ErrInvalidTOTPCode unless code is the current code of the
+// user's TOTP secret. With Redis, each code is only accepted once, and users are
+// locked out with ErrTOTPLocked after maxTOTPFailures invalid codes.
+func (s *Service) checkTOTPCode(ctx context.Context, userID, code string) error {
+	if s.redisClient != nil {
+		failures, err := s.redisClient.Get(ctx, totpFailuresKey(userID)).Int()
+		if err != nil && err != redis.Nil {
+			return fmt.Errorf("failed to read TOTP failures: %w", err)
+		}
+		if failures >= maxTOTPFailures {
+			return ErrTOTPLocked
+		}
+	}
+
+	sealed, err := s.userStore.TOTPSecret(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	secret, err := s.decryptTOTPSecret(userID, sealed)
+	if err != nil {
+		return err
+	}
+
+	step, ok := totpStep(secret, code, time.Now())
+	if ok && s.redisClient != nil {
+		ok, err = s.useTOTPStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+	}
+	if !ok {
+		if err := s.recordTOTPFailure(ctx, userID); err != nil {
+			return err
+		}
+		return ErrInvalidTOTPCode
+	}
+
+	if s.redisClient != nil {
+		s.redisClient.Del(ctx, totpFailuresKey(userID))
+	}
+	return nil
+}
+
+// totpStep returns the time step of the code of secret that code is, among the
+// codes accepted at now
+func totpStep(secret, code string, now time.Time) (int64, bool) {
+	period := int64(totpPeriod / time.Second)
+	opts := totp.ValidateOpts{Period: uint(period), Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1}
+
+	for _, skew := range []int64{-1, 0, 1} {
+		at := now.Add(time.Duration(skew) * totpPeriod)
+		expected, err := totp.GenerateCodeCustom(secret, at, opts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return at.Unix() / period, true
+		}
+	}
+	return 0, false
+}
+
+// useTOTPStep records step as the last time step a code of the user was accepted
+// for. It returns false if a code of the step, or a later one, was accepted
+// before.
+func (s *Service) useTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	// Codes are accepted up to a period after their own
+	ttl := 3 * totpPeriod
+	used, err := s.redisClient.Eval(ctx, useTOTPStepScript, []string{totpLastStepKey(userID)}, step, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to record TOTP code: %w", err)
+	}
+	return used == 1, nil
+}
+
+// recordTOTPFailure counts an invalid code of the user. The count expires
+// totpLockout after the first invalid code.
+func (s *Service) recordTOTPFailure(ctx context.Context, userID string) error {
+	if s.redisClient == nil {
+		return nil
+	}
+
+	key := totpFailuresKey(userID)
+	failures, err := s.redisClient.Incr(ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf("failed to count TOTP failure: %w", err)
+	}
+	if failures == 1 {
+		if err := s.redisClient.Expire(ctx, key, totpLockout).Err(); err != nil {
+			return fmt.Errorf("failed to count TOTP failure: %w", err)
+		}
+	}
+	return nil
+}
+
+func totpFailuresKey(userID string) string {
+	return "totp_failures:" + userID
+}
+
+func totpLastStepKey(userID string) string {
+	return "totp_last_step:" + userID
+}
+
+// encryptTOTPSecret seals secret and returns the nonce followed by the ciphertext.
+// The user ID is authenticated with it, so a secret cannot be moved to another user.
+func (s *Service) encryptTOTPSecret(userID, secret string) ([]byte, error) {
+	nonce := make([]byte, s.totpCipher.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return s.totpCipher.Seal(nonce, nonce, []byte(secret), []byte(userID)), nil
+}
+
+func (s *Service) decryptTOTPSecret(userID string, sealed []byte) (string, error) {
+	nonceSize := s.totpCipher.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", errors.New("TOTP secret ciphertext too short")
+	}
+
+	secret, err := s.totpCipher.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(userID))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt TOTP secret: %w", err)
+	}
+	return string(secret), nil
+}
@@ -0,0 +1,296 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryUserStore struct {
+	mu       sync.Mutex
+	secrets  map[string][]byte
+	required map[string]bool
+}
+
+func newMemoryUserStore() *memoryUserStore {
+	return &memoryUserStore{secrets: map[string][]byte{}, required: map[string]bool{}}
+}
+
+func (s *memoryUserStore) TOTPRequired(ctx context.Context, userID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.required[userID], nil
+}
+
+func (s *memoryUserStore) TOTPSecret(ctx context.Context, userID string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secret, ok := s.secrets[userID]
+	if !ok {
+		return nil, ErrTOTPNotEnrolled
+	}
+	return secret, nil
+}
+
+func (s *memoryUserStore) SaveTOTPSecret(ctx context.Context, userID string, secret []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secrets[userID] = secret
+	return nil
+}
+
+func (s *memoryUserStore) RequireTOTP(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.required[userID] = true
+	return nil
+}
+
+func (s *memoryUserStore) DeleteTOTPSecret(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.secrets, userID)
+	s.required[userID] = false
+	return nil
+}
+
+func testTOTPKey(t *testing.T) string {
+	t.Helper()
+
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func newTOTPService(t *testing.T) (*Service, *memoryUserStore) {
+	t.Helper()
+
+	store := newMemoryUserStore()
+	service := NewService(testSecret)
+	require.NoError(t, service.UseUserStore(store, testTOTPKey(t)))
+	return service, store
+}
+
+// newRedisTOTPService creates a TOTP service that keeps failures and used codes in
+// miniredis
+func newRedisTOTPService(t *testing.T) (*Service, *miniredis.Miniredis) {
+	t.Helper()
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	t.Cleanup(func() { redisClient.Close() })
+
+	service := NewServiceWithRedis(testSecret, redisClient)
+	require.NoError(t, service.UseUserStore(newMemoryUserStore(), testTOTPKey(t)))
+	return service, redisServer
+}
+
+// enrolledCode enrolls user and returns a valid code of the new secret
+func enrolledCode(t *testing.T, service *Service, user *User) string {
+	t.Helper()
+
+	enrollment, err := service.EnrollTOTP(context.Background(), user)
+	require.NoError(t, err)
+
+	code, err := totp.GenerateCode(enrollment.Secret, time.Now())
+	require.NoError(t, err)
+	return code
+}
+
+func TestVerifyToken_TOTPRequired(t *testing.T) {
+	service, store := newTOTPService(t)
+
+	pair, err := service.generateTokenPair(testSubject())
+	require.NoError(t, err)
+
+	_, err = service.VerifyToken(pair.AccessToken)
+	require.NoError(t, err)
+
+	store.required["user-1"] = true
+	_, err = service.VerifyToken(pair.AccessToken)
+	assert.ErrorIs(t, err, ErrTOTPRequired)
+
+	subject := testSubject()
+	subject.totpVerified = true
+	pair, err = service.generateTokenPair(subject)
+	require.NoError(t, err)
+
+	user, err := service.VerifyToken(pair.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, user.TOTPVerified)
+}
+
+func TestEnrollTOTP(t *testing.T) {
+	service, store := newTOTPService(t)
+	user := &User{ID: "user-1", Email: "user@example.com"}
+
+	enrollment, err := service.EnrollTOTP(context.Background(), user)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(enrollment.ProvisioningURI, "otpauth://totp/ZAMC:user@example.com?"))
+	assert.Contains(t, enrollment.ProvisioningURI, "secret="+enrollment.Secret)
+	assert.True(t, strings.HasPrefix(enrollment.QRCode, "data:image/png;base64,"))
+
+	// The secret is stored encrypted and TOTP is only required once a code is checked
+	assert.NotContains(t, string(store.secrets["user-1"]), enrollment.Secret)
+	assert.False(t, store.required["user-1"])
+
+	code, err := totp.GenerateCode(enrollment.Secret, time.Now())
+	require.NoError(t, err)
+	assert.NoError(t, service.checkTOTPCode(context.Background(), "user-1", code))
+
+	// A secret cannot be moved to another user
+	store.secrets["user-2"] = store.secrets["user-1"]
+	assert.Error(t, service.checkTOTPCode(context.Background(), "user-2", code))
+}
+
+func TestEnrollTOTP_AlreadyEnabled(t *testing.T) {
+	service, store := newTOTPService(t)
+	store.required["user-1"] = true
+
+	_, err := service.EnrollTOTP(context.Background(), &User{ID: "user-1"})
+	assert.ErrorIs(t, err, ErrTOTPEnabled)
+}
+
+func TestDisableTOTP(t *testing.T) {
+	service, store := newTOTPService(t)
+	user := &User{ID: "user-1", Email: "user@example.com"}
+
+	err := service.DisableTOTP(context.Background(), user, "123456")
+	assert.ErrorIs(t, err, ErrTOTPNotEnrolled)
+
+	code := enrolledCode(t, service, user)
+	store.required["user-1"] = true
+
+	wrongCode := "000000"
+	if code == wrongCode {
+		wrongCode = "111111"
+	}
+	err = service.DisableTOTP(context.Background(), user, wrongCode)
+	assert.ErrorIs(t, err, ErrInvalidTOTPCode)
+
+	require.NoError(t, service.DisableTOTP(context.Background(), user, code))
+	assert.NotContains(t, store.secrets, "user-1")
+	assert.False(t, store.required["user-1"])
+}
+
+func TestTOTP_NotConfigured(t *testing.T) {
+	service := NewService(testSecret)
+
+	_, err := service.EnrollTOTP(context.Background(), &User{ID: "user-1"})
+	assert.ErrorIs(t, err, ErrTOTPNotConfigured)
+
+	_, err = service.VerifyTOTP(context.Background(), nil, "token", "123456")
+	assert.ErrorIs(t, err, ErrTOTPNotConfigured)
+}
+
+func TestUseUserStore_InvalidKey(t *testing.T) {
+	service := NewService(testSecret)
+
+	assert.Error(t, service.UseUserStore(newMemoryUserStore(), "not base64!"))
+	assert.Error(t, service.UseUserStore(newMemoryUserStore(), base64.StdEncoding.EncodeToString([]byte("too short"))))
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	db := openTestDB(t)
+	userID, projectID := seedUser(t, db)
+
+	service := NewService(testSecret)
+	require.NoError(t, service.UseUserStore(NewDBUserStore(db), testTOTPKey(t)))
+
+	pair, err := service.GenerateTokenPair(db, userID, userID+"@auth.test", "user")
+	require.NoError(t, err)
+	user, err := service.VerifyToken(pair.AccessToken)
+	require.NoError(t, err)
+
+	code := enrolledCode(t, service, user)
+
+	verified, err := service.VerifyTOTP(context.Background(), db, pair.AccessToken, code)
+	require.NoError(t, err)
+
+	// The first code turns on TOTP, so only the verified pair is accepted
+	_, err = service.VerifyToken(pair.AccessToken)
+	assert.ErrorIs(t, err, ErrTOTPRequired)
+
+	user, err = service.VerifyToken(verified.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, user.TOTPVerified)
+	assert.Equal(t, []string{projectID}, user.Projects)
+
+	// Refreshing keeps the claim
+	refreshed, err := service.RefreshTokens(db, verified.RefreshToken)
+	require.NoError(t, err)
+	user, err = service.VerifyToken(refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, user.TOTPVerified)
+
+	require.NoError(t, service.DisableTOTP(context.Background(), user, code))
+	_, err = service.VerifyToken(pair.AccessToken)
+	assert.NoError(t, err)
+}
+
+func TestCheckTOTPCode_RejectsReuse(t *testing.T) {
+	service, _ := newRedisTOTPService(t)
+	ctx := context.Background()
+
+	enrollment, err := service.EnrollTOTP(ctx, &User{ID: "user-1"})
+	require.NoError(t, err)
+
+	now := time.Now()
+	code, err := totp.GenerateCode(enrollment.Secret, now)
+	require.NoError(t, err)
+	require.NoError(t, service.checkTOTPCode(ctx, "user-1", code))
+	assert.ErrorIs(t, service.checkTOTPCode(ctx, "user-1", code), ErrInvalidTOTPCode)
+
+	// Nor are codes older than the last one used accepted
+	previous, err := totp.GenerateCode(enrollment.Secret, now.Add(-totpPeriod))
+	require.NoError(t, err)
+	if previous != code {
+		assert.ErrorIs(t, service.checkTOTPCode(ctx, "user-1", previous), ErrInvalidTOTPCode)
+	}
+
+	next, err := totp.GenerateCode(enrollment.Secret, now.Add(totpPeriod))
+	require.NoError(t, err)
+	assert.NoError(t, service.checkTOTPCode(ctx, "user-1", next))
+}
+
+func TestCheckTOTPCode_LocksOut(t *testing.T) {
+	service, redisServer := newRedisTOTPService(t)
+	ctx := context.Background()
+
+	enrollment, err := service.EnrollTOTP(ctx, &User{ID: "user-1"})
+	require.NoError(t, err)
+	code, err := totp.GenerateCode(enrollment.Secret, time.Now())
+	require.NoError(t, err)
+
+	wrongCode := "000000"
+	if code == wrongCode {
+		wrongCode = "111111"
+	}
+	for i := 0; i < maxTOTPFailures; i++ {
+		assert.ErrorIs(t, service.checkTOTPCode(ctx, "user-1", wrongCode), ErrInvalidTOTPCode)
+	}
+
+	// Even the right code is refused during the lockout
+	assert.ErrorIs(t, service.checkTOTPCode(ctx, "user-1", code), ErrTOTPLocked)
+	assert.Equal(t, totpLockout, redisServer.TTL(totpFailuresKey("user-1")))
+
+	// Other users are not locked out
+	_, err = service.EnrollTOTP(ctx, &User{ID: "user-2"})
+	require.NoError(t, err)
+	assert.ErrorIs(t, service.checkTOTPCode(ctx, "user-2", wrongCode), ErrInvalidTOTPCode)
+
+	redisServer.FastForward(totpLockout)
+	require.NoError(t, service.checkTOTPCode(ctx, "user-1", code))
+	assert.False(t, redisServer.Exists(totpFailuresKey("user-1")))
+}
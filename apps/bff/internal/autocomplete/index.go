@@ -0,0 +1,185 @@
+// Package autocomplete suggests the assets whose name starts with what a user is
+// typing, from an index of asset names kept in Redis.
+package autocomplete
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// maxPrefixLength is the number of leading characters of a name that are
+	// indexed. Longer prefixes match on them, their callers check the full name.
+	maxPrefixLength = 32
+
+	// MaxLimit is the largest number of suggestions returned at once
+	MaxLimit = 50
+)
+
+// projectKey is the sorted set indexing the asset names of a project. Its
+// members all have score 0, so that Redis orders them lexicographically and a
+// prefix is looked up with ZRANGEBYLEX as in a trie.
+func projectKey(projectID string) string {
+	return "autocomplete:assets:" + projectID
+}
+
+// assetKey holds the project and name an asset is indexed under, so that its
+// entries can be removed once it is renamed or deleted
+func assetKey(assetID string) string {
+	return "autocomplete:asset:" + assetID
+}
+
+// backfillKey marks the index as holding the assets created before it was kept
+// up to date. It goes along with the index when Redis loses its data, so that
+// the index is then backfilled again.
+const backfillKey = "autocomplete:backfilled"
+
+// Normalize returns the form of a name or prefix that is indexed and looked up,
+// so that suggestions ignore case and surrounding spaces
+func Normalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// indexedPrefix truncates a normalized prefix to the indexed characters
+func indexedPrefix(prefix string) string {
+	if runes := []rune(prefix); len(runes) > maxPrefixLength {
+		return string(runes[:maxPrefixLength])
+	}
+	return prefix
+}
+
+// members returns the entries indexing name for assetID, "{prefix}:{assetID}"
+// for every prefix of the normalized name up to maxPrefixLength characters
+func members(name, assetID string) []string {
+	runes := []rune(Normalize(name))
+	if len(runes) > maxPrefixLength {
+		runes = runes[:maxPrefixLength]
+	}
+
+	entries := make([]string, 0, len(runes))
+	for i := 1; i <= len(runes); i++ {
+		entries = append(entries, string(runes[:i])+":"+assetID)
+	}
+	return entries
+}
+
+// Index is the autocomplete index of the asset names of every project
+type Index struct {
+	client redis.UniversalClient
+}
+
+// NewIndex creates an index stored in client
+func NewIndex(client redis.UniversalClient) *Index {
+	return &Index{client: client}
+}
+
+// Add indexes the asset assetID of projectID under name, replacing the entries
+// of its previous name
+func (i *Index) Add(ctx context.Context, projectID, assetID, name string) error {
+	if err := i.Remove(ctx, assetID); err != nil {
+		return err
+	}
+
+	entries := members(name, assetID)
+	if len(entries) == 0 {
+		return nil
+	}
+	scored := make([]*redis.Z, len(entries))
+	for j, entry := range entries {
+		scored[j] = &redis.Z{Score: 0, Member: entry}
+	}
+
+	// The keys of a project and an asset may be in different slots of a Redis
+	// Cluster, so they are not written in a transaction
+	pipe := i.client.Pipeline()
+	pipe.ZAdd(ctx, projectKey(projectID), scored...)
+	pipe.HSet(ctx, assetKey(assetID), "project_id", projectID, "name", name)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to index asset %s: %w", assetID, err)
+	}
+
+	return nil
+}
+
+// Remove removes the entries of the asset assetID, if it is indexed
+func (i *Index) Remove(ctx context.Context, assetID string) error {
+	indexed, err := i.client.HGetAll(ctx, assetKey(assetID)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to read index entry of asset %s: %w", assetID, err)
+	}
+	if len(indexed) == 0 {
+		return nil
+	}
+
+	entries := members(indexed["name"], assetID)
+	stale := make([]interface{}, len(entries))
+	for j, entry := range entries {
+		stale[j] = entry
+	}
+
+	pipe := i.client.Pipeline()
+	if len(stale) > 0 {
+		pipe.ZRem(ctx, projectKey(indexed["project_id"]), stale...)
+	}
+	pipe.Del(ctx, assetKey(assetID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to remove index entry of asset %s: %w", assetID, err)
+	}
+
+	return nil
+}
+
+// Search returns the IDs of up to limit assets of projectID whose name starts
+// with prefix, ignoring case. Prefixes longer than the indexed characters match
+// on those only.
+func (i *Index) Search(ctx context.Context, projectID, prefix string, limit int) ([]string, error) {
+	prefix = indexedPrefix(Normalize(prefix))
+	if prefix == "" || limit <= 0 {
+		return []string{}, nil
+	}
+
+	// The entries of the prefix itself sort between "{prefix}:" and the byte
+	// above any asset ID
+	entries, err := i.client.ZRangeByLex(ctx, projectKey(projectID), &redis.ZRangeBy{
+		Min:   "[" + prefix + ":",
+		Max:   "[" + prefix + ":\xff",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to search asset names: %w", err)
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		// Names containing ":" also index longer prefixes within the range,
+		// such as "a:b:{assetID}" for "a"
+		separator := strings.LastIndex(entry, ":")
+		if entry[:separator] != prefix {
+			continue
+		}
+		ids = append(ids, entry[separator+1:])
+	}
+
+	return ids, nil
+}
+
+// Backfilled returns whether every existing asset was indexed since Redis last
+// lost its data
+func (i *Index) Backfilled(ctx context.Context) (bool, error) {
+	n, err := i.client.Exists(ctx, backfillKey).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to read autocomplete backfill: %w", err)
+	}
+	return n > 0, nil
+}
+
+// MarkBackfilled records that every existing asset was indexed
+func (i *Index) MarkBackfilled(ctx context.Context) error {
+	if err := i.client.Set(ctx, backfillKey, 1, 0).Err(); err != nil {
+		return fmt.Errorf("failed to record autocomplete backfill: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package autocomplete
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIndex(t *testing.T) (*Index, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewIndex(client), server
+}
+
+func TestIndex_Search(t *testing.T) {
+	index, _ := newTestIndex(t)
+	ctx := context.Background()
+	projectID := uuid.New().String()
+
+	summer, winter, launch := uuid.New().String(), uuid.New().String(), uuid.New().String()
+	require.NoError(t, index.Add(ctx, projectID, summer, "Summer Banner"))
+	require.NoError(t, index.Add(ctx, projectID, winter, "Summer Sale"))
+	require.NoError(t, index.Add(ctx, projectID, launch, "Launch video"))
+	// Assets of other projects are not suggested
+	require.NoError(t, index.Add(ctx, uuid.New().String(), uuid.New().String(), "Summer Banner"))
+
+	ids, err := index.Search(ctx, projectID, "summer", 10)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{summer, winter}, ids)
+
+	// Case and surrounding spaces are ignored
+	ids, err = index.Search(ctx, projectID, "  SUMMER b", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{summer}, ids)
+
+	ids, err = index.Search(ctx, projectID, "s", 1)
+	require.NoError(t, err)
+	assert.Len(t, ids, 1)
+
+	ids, err = index.Search(ctx, projectID, "banner", 10)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+
+	ids, err = index.Search(ctx, projectID, " ", 10)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestIndex_SearchNamesWithSeparator(t *testing.T) {
+	index, _ := newTestIndex(t)
+	ctx := context.Background()
+	projectID := uuid.New().String()
+
+	assetID := uuid.New().String()
+	require.NoError(t, index.Add(ctx, projectID, assetID, "Q3: launch"))
+
+	for _, prefix := range []string{"q", "q3", "q3:", "q3: l"} {
+		ids, err := index.Search(ctx, projectID, prefix, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{assetID}, ids, prefix)
+	}
+}
+
+func TestIndex_SearchLongPrefix(t *testing.T) {
+	index, _ := newTestIndex(t)
+	ctx := context.Background()
+	projectID := uuid.New().String()
+
+	name := "Holiday campaign banner for the northern hemisphere"
+	assetID := uuid.New().String()
+	require.NoError(t, index.Add(ctx, projectID, assetID, name))
+
+	// Prefixes longer than the indexed characters match on those
+	ids, err := index.Search(ctx, projectID, name, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{assetID}, ids)
+}
+
+func TestIndex_AddReplacesPreviousName(t *testing.T) {
+	index, server := newTestIndex(t)
+	ctx := context.Background()
+	projectID := uuid.New().String()
+
+	assetID := uuid.New().String()
+	require.NoError(t, index.Add(ctx, projectID, assetID, "Draft"))
+	require.NoError(t, index.Add(ctx, projectID, assetID, "Final"))
+
+	ids, err := index.Search(ctx, projectID, "draft", 10)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+
+	ids, err = index.Search(ctx, projectID, "fin", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{assetID}, ids)
+
+	entries, err := server.ZMembers(projectKey(projectID))
+	require.NoError(t, err)
+	assert.Len(t, entries, len("final"))
+}
+
+func TestIndex_Remove(t *testing.T) {
+	index, server := newTestIndex(t)
+	ctx := context.Background()
+	projectID := uuid.New().String()
+
+	assetID := uuid.New().String()
+	require.NoError(t, index.Add(ctx, projectID, assetID, "Banner"))
+	require.NoError(t, index.Remove(ctx, assetID))
+
+	ids, err := index.Search(ctx, projectID, "b", 10)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+	assert.False(t, server.Exists(projectKey(projectID)))
+	assert.False(t, server.Exists(assetKey(assetID)))
+
+	// Removing an asset that is not indexed does nothing
+	assert.NoError(t, index.Remove(ctx, uuid.New().String()))
+}
+
+// autocompleteBenchmarkAssets is the number of assets indexed for the benchmark
+const autocompleteBenchmarkAssets = 50000
+
+// BenchmarkIndex_Search looks up prefixes among autocompleteBenchmarkAssets
+// asset names in the Redis server at TEST_REDIS_ADDR, failing when the 99th
+// percentile of the lookups takes 10ms or more. The benchmark is skipped when
+// Redis is unavailable.
+func BenchmarkIndex_Search(b *testing.B) {
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	b.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	if err := client.Ping(ctx).Err(); err != nil {
+		b.Skipf("test redis unavailable: %v", err)
+	}
+
+	index := NewIndex(client)
+	projectID := uuid.New().String()
+	assetIDs := make([]string, autocompleteBenchmarkAssets)
+	b.Cleanup(func() {
+		for _, assetID := range assetIDs {
+			client.Del(ctx, assetKey(assetID))
+		}
+		client.Del(ctx, projectKey(projectID))
+	})
+
+	words := []string{"Summer", "Winter", "Launch", "Holiday"}
+	for i := range assetIDs {
+		assetIDs[i] = uuid.New().String()
+		name := fmt.Sprintf("%s campaign banner %d", words[i%len(words)], i)
+		if err := index.Add(ctx, projectID, assetIDs[i], name); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	prefixes := []string{"s", "wint", "launch camp", "holiday campaign banner 4"}
+	durations := make([]time.Duration, 0, b.N)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		start := time.Now()
+		ids, err := index.Search(ctx, projectID, prefixes[i%len(prefixes)], 10)
+		durations = append(durations, time.Since(start))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(ids) != 10 {
+			b.Fatalf("found %d assets, want 10", len(ids))
+		}
+	}
+
+	b.StopTimer()
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	p99 := durations[len(durations)*99/100]
+	b.ReportMetric(float64(p99.Microseconds())/1000, "p99-ms")
+	if p99 >= 10*time.Millisecond {
+		b.Fatalf("p99 latency %v, want under 10ms", p99)
+	}
+}
+
+func TestIndex_Backfilled(t *testing.T) {
+	index, server := newTestIndex(t)
+	ctx := context.Background()
+
+	backfilled, err := index.Backfilled(ctx)
+	require.NoError(t, err)
+	assert.False(t, backfilled)
+
+	require.NoError(t, index.MarkBackfilled(ctx))
+	backfilled, err = index.Backfilled(ctx)
+	require.NoError(t, err)
+	assert.True(t, backfilled)
+
+	// The index is backfilled again once Redis loses its data
+	server.FlushAll()
+	backfilled, err = index.Backfilled(ctx)
+	require.NoError(t, err)
+	assert.False(t, backfilled)
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// DefaultWatchInterval is how often a Watcher reads the configuration
+const DefaultWatchInterval = 30 * time.Second
+
+// reloadableKey is a setting that takes effect without restarting the server,
+// named after the environment variable it is read from
+type reloadableKey struct {
+	name  string
+	value func(*Config) string
+	apply func(dst, src *Config)
+}
+
+var reloadableKeys = []reloadableKey{
+	{
+		name:  "CORS_ORIGINS",
+		value: func(c *Config) string { return c.CorsOrigins },
+		apply: func(dst, src *Config) { dst.CorsOrigins = src.CorsOrigins },
+	},
+	{
+		name:  "RATE_LIMIT_REQUESTS_PER_MINUTE",
+		value: func(c *Config) string { return strconv.Itoa(c.RateLimits.GraphQLRequestsPerMinute) },
+		apply: func(dst, src *Config) {
+			dst.RateLimits.GraphQLRequestsPerMinute = src.RateLimits.GraphQLRequestsPerMinute
+		},
+	},
+	{
+		name:  "GRAPHQL_MAX_DEPTH",
+		value: func(c *Config) string { return strconv.Itoa(c.MaxQueryDepth) },
+		apply: func(dst, src *Config) { dst.MaxQueryDepth = src.MaxQueryDepth },
+	},
+	{
+		name:  "GRAPHQL_COMPLEXITY_BUDGET",
+		value: func(c *Config) string { return strconv.Itoa(c.ComplexityBudget) },
+		apply: func(dst, src *Config) { dst.ComplexityBudget = src.ComplexityBudget },
+	},
+}
+
+// Diff describes the hot-reloadable settings that differ between old and new,
+// such as `GRAPHQL_MAX_DEPTH: "10" -> "12"`
+func Diff(old, new *Config) []string {
+	var changes []string
+	for _, key := range reloadableKeys {
+		if before, after := key.value(old), key.value(new); before != after {
+			changes = append(changes, fmt.Sprintf("%s: %q -> %q", key.name, before, after))
+		}
+	}
+	return changes
+}
+
+// LoadFile loads the configuration like Load, with the variables set in the
+// dotenv file at path taking priority over the environment. An empty path only
+// reads the environment. The environment of the process is left as is, so that
+// variables removed from the file fall back to it on the next load.
+func LoadFile(path string) (*Config, error) {
+	if path == "" {
+		return Load(), nil
+	}
+
+	overrides, err := godotenv.Read(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	return load(func(key string) string {
+		if value, ok := overrides[key]; ok {
+			return value
+		}
+		return os.Getenv(key)
+	}), nil
+}
+
+// Watcher reads the configuration periodically and on demand, and emits it when
+// a hot-reloadable setting changed. Other settings keep the value they had
+// when the watcher was created, as they only take effect on restart.
+type Watcher struct {
+	path     string
+	interval time.Duration
+	current  Config
+	changes  chan Config
+	reloads  chan chan reloadResult
+	done     chan struct{}
+}
+
+type reloadResult struct {
+	changes []string
+	err     error
+}
+
+// NewWatcher creates a watcher of the configuration read from the environment
+// and the dotenv file at path, if any, starting from current
+func NewWatcher(current *Config, path string, interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	return &Watcher{
+		path:     path,
+		interval: interval,
+		current:  *current,
+		changes:  make(chan Config),
+		reloads:  make(chan chan reloadResult),
+		done:     make(chan struct{}),
+	}
+}
+
+// Changes receives the configuration each time a hot-reloadable setting
+// changes. It is closed when Run returns.
+func (w *Watcher) Changes() <-chan Config {
+	return w.changes
+}
+
+// Run reads the configuration every interval until ctx is cancelled
+func (w *Watcher) Run(ctx context.Context) {
+	defer close(w.changes)
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := w.check(ctx); err != nil {
+				log.Printf("Failed to reload configuration: %v", err)
+			}
+		case result := <-w.reloads:
+			changes, err := w.check(ctx)
+			result <- reloadResult{changes: changes, err: err}
+		}
+	}
+}
+
+// Reload reads the configuration immediately and returns the changes of the
+// hot-reloadable settings, once they were emitted
+func (w *Watcher) Reload(ctx context.Context) ([]string, error) {
+	result := make(chan reloadResult, 1)
+
+	select {
+	case w.reloads <- result:
+	case <-w.done:
+		return nil, fmt.Errorf("configuration watcher is stopped")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case r := <-result:
+		return r.changes, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// check reads the configuration and emits it when it changed
+func (w *Watcher) check(ctx context.Context) ([]string, error) {
+	loaded, err := LoadFile(w.path)
+	if err != nil {
+		return nil, err
+	}
+
+	next := w.current
+	for _, key := range reloadableKeys {
+		key.apply(&next, loaded)
+	}
+
+	changes := Diff(&w.current, &next)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	select {
+	case w.changes <- next:
+		w.current = next
+		return changes, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
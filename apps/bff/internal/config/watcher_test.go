@@ -0,0 +1,142 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setReloadableEnv sets the hot-reloadable variables for the duration of the
+// test
+func setReloadableEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("CORS_ORIGINS", "http://localhost:5173")
+	t.Setenv("RATE_LIMIT_REQUESTS_PER_MINUTE", "60")
+	t.Setenv("GRAPHQL_MAX_DEPTH", "10")
+	t.Setenv("GRAPHQL_COMPLEXITY_BUDGET", "5000")
+	t.Setenv("PORT", "8080")
+}
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestDiff(t *testing.T) {
+	old := &Config{CorsOrigins: "http://a.test", MaxQueryDepth: 10, ComplexityBudget: 5000, Port: "8080"}
+	new := *old
+	assert.Empty(t, Diff(old, &new))
+
+	new.CorsOrigins = "http://a.test,http://b.test"
+	new.MaxQueryDepth = 12
+	new.Port = "9090"
+	assert.Equal(t, []string{
+		`CORS_ORIGINS: "http://a.test" -> "http://a.test,http://b.test"`,
+		`GRAPHQL_MAX_DEPTH: "10" -> "12"`,
+	}, Diff(old, &new))
+}
+
+func TestLoadFile(t *testing.T) {
+	setReloadableEnv(t)
+	path := filepath.Join(t.TempDir(), "bff.env")
+	writeConfigFile(t, path, "GRAPHQL_MAX_DEPTH=15\n")
+
+	cfg, err := LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 15, cfg.MaxQueryDepth)
+	assert.Equal(t, "http://localhost:5173", cfg.CorsOrigins)
+	assert.Equal(t, "10", os.Getenv("GRAPHQL_MAX_DEPTH"))
+
+	_, err = LoadFile(filepath.Join(t.TempDir(), "missing.env"))
+	assert.Error(t, err)
+}
+
+func TestWatcher_EmitsReloadableChanges(t *testing.T) {
+	setReloadableEnv(t)
+	path := filepath.Join(t.TempDir(), "bff.env")
+	writeConfigFile(t, path, "")
+
+	current, err := LoadFile(path)
+	require.NoError(t, err)
+	watcher := NewWatcher(current, path, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watcher.Run(ctx)
+
+	// Settings that need a restart are not reloaded
+	writeConfigFile(t, path, "CORS_ORIGINS=https://app.example.com\nRATE_LIMIT_REQUESTS_PER_MINUTE=120\nPORT=9090\n")
+
+	select {
+	case cfg := <-watcher.Changes():
+		assert.Equal(t, "https://app.example.com", cfg.CorsOrigins)
+		assert.Equal(t, 120, cfg.RateLimits.GraphQLRequestsPerMinute)
+		assert.Equal(t, "8080", cfg.Port)
+	case <-time.After(time.Second):
+		t.Fatal("configuration change was not emitted")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-watcher.Changes():
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("watcher did not stop")
+	}
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	setReloadableEnv(t)
+	path := filepath.Join(t.TempDir(), "bff.env")
+	writeConfigFile(t, path, "")
+
+	current, err := LoadFile(path)
+	require.NoError(t, err)
+	watcher := NewWatcher(current, path, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watcher.Run(ctx)
+
+	received := make(chan Config, 1)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for cfg := range watcher.Changes() {
+			received <- cfg
+		}
+	}()
+
+	changes, err := watcher.Reload(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	writeConfigFile(t, path, "GRAPHQL_COMPLEXITY_BUDGET=8000\n")
+	changes, err = watcher.Reload(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{`GRAPHQL_COMPLEXITY_BUDGET: "5000" -> "8000"`}, changes)
+	assert.Equal(t, 8000, (<-received).ComplexityBudget)
+
+	// Settings removed from the file fall back to the environment
+	writeConfigFile(t, path, "")
+	changes, err = watcher.Reload(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{`GRAPHQL_COMPLEXITY_BUDGET: "8000" -> "5000"`}, changes)
+	assert.Equal(t, 5000, (<-received).ComplexityBudget)
+	assert.Equal(t, "5000", os.Getenv("GRAPHQL_COMPLEXITY_BUDGET"))
+
+	// Unreadable files are reported and the configuration is kept
+	require.NoError(t, os.Remove(path))
+	_, err = watcher.Reload(context.Background())
+	assert.Error(t, err)
+
+	cancel()
+	<-stopped
+	_, err = watcher.Reload(context.Background())
+	assert.EqualError(t, err, "configuration watcher is stopped")
+}
@@ -1,12 +1,17 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
 	_ "github.com/lib/pq"
 )
 
+// AppRole is the role request transactions run as so that row-level security
+// policies apply regardless of the role the service connects with
+const AppRole = "zamc_app"
+
 type DB struct {
 	*sql.DB
 }
@@ -26,4 +31,41 @@ func Connect(databaseURL string) (*DB, error) {
 
 func (db *DB) Close() error {
 	return db.DB.Close()
-} 
\ No newline at end of file
+} 
+
+// WithUserContext begins a transaction in which row-level security policies are
+// evaluated for userID. The caller must commit or roll back the transaction.
+func (db *DB) WithUserContext(ctx context.Context, userID string) (*sql.Tx, error) {
+	return withUserContext(ctx, db.DB, userID, nil)
+}
+
+// withUserContext begins a transaction on db with opts in which row-level
+// security policies are evaluated for userID
+func withUserContext(ctx context.Context, db *sql.DB, userID string, opts *sql.TxOptions) (*sql.Tx, error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "SET LOCAL ROLE "+AppRole); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to assume application role: %w", err)
+	}
+
+	// Equivalent to SET LOCAL app.current_user_id, which cannot take bind parameters
+	if _, err := tx.ExecContext(ctx, `SELECT set_config('app.current_user_id', $1, true)`, userID); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to set user context: %w", err)
+	}
+
+	return tx, nil
+}
+
+// ScopeToProject restricts the projects visible in tx, a transaction begun by
+// WithUserContext or WithUserReadContext, to projectID and its boards and assets
+func ScopeToProject(ctx context.Context, tx *sql.Tx, projectID string) error {
+	if _, err := tx.ExecContext(ctx, `SELECT set_config('app.current_project_scope', $1, true)`, projectID); err != nil {
+		return fmt.Errorf("failed to set project scope: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+)
+
+// MigrationState is the version of the schema and the migrations left to apply
+type MigrationState struct {
+	// Version is the last applied migration, 0 when none was applied
+	Version uint `json:"version"`
+
+	// Dirty is true when the last migration failed halfway and needs fixing by hand
+	Dirty bool `json:"dirty"`
+
+	// Pending names the up migrations newer than Version, such as "000023_webhooks"
+	Pending []string `json:"pending"`
+}
+
+// Migrate applies all pending up migrations found in migrationsDir
+func Migrate(db *sql.DB, migrationsDir string) error {
+	m, err := newMigrator(db, migrationsDir)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	return nil
+}
+
+// Rollback reverts the given number of applied migrations
+func Rollback(db *sql.DB, migrationsDir string, steps int) error {
+	m, err := newMigrator(db, migrationsDir)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to roll back migrations: %w", err)
+	}
+
+	return nil
+}
+
+// MigrationStatus returns the migration version of the schema and the
+// migrations in migrationsDir that are not applied yet
+func MigrationStatus(db *sql.DB, migrationsDir string) (*MigrationState, error) {
+	m, err := newMigrator(db, migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+	defer m.Close()
+
+	version, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return nil, fmt.Errorf("failed to read migration version: %w", err)
+	}
+
+	pending, err := pendingMigrations(migrationsDir, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MigrationState{Version: version, Dirty: dirty, Pending: pending}, nil
+}
+
+// pendingMigrations returns the names of the up migrations in migrationsDir
+// newer than version, in the order they are applied
+func pendingMigrations(migrationsDir string, version uint) ([]string, error) {
+	entries, err := os.ReadDir(migrationsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	var migrations []*source.Migration
+	for _, entry := range entries {
+		migration, err := source.Parse(entry.Name())
+		if err != nil || migration.Direction != source.Up || migration.Version <= version {
+			continue
+		}
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	pending := []string{}
+	for _, migration := range migrations {
+		pending = append(pending, fmt.Sprintf("%06d_%s", migration.Version, migration.Identifier))
+	}
+	return pending, nil
+}
+
+// newMigrator runs migrations on a dedicated connection so that closing the
+// migrator returns it to the pool instead of closing the shared *sql.DB
+func newMigrator(db *sql.DB, migrationsDir string) (*migrate.Migrate, error) {
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire migration connection: %w", err)
+	}
+
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create migration driver: %w", err)
+	}
+
+	absDir, err := filepath.Abs(migrationsDir)
+	if err != nil {
+		driver.Close()
+		return nil, fmt.Errorf("failed to resolve migrations directory: %w", err)
+	}
+
+	m, err := migrate.NewWithDatabaseInstance("file://"+filepath.ToSlash(absDir), "postgres", driver)
+	if err != nil {
+		driver.Close()
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Pool routes statements to the primary database or to a read replica, so that
+// heavy read traffic does not compete with writes. Without a replica, reads go
+// to the primary.
+type Pool struct {
+	primary *sql.DB
+	replica *sql.DB
+}
+
+// NewPool creates a pool writing to primary and reading from replica, or from
+// primary when replica is nil
+func NewPool(primary, replica *sql.DB) *Pool {
+	if replica == nil {
+		replica = primary
+	}
+	return &Pool{primary: primary, replica: replica}
+}
+
+// Writer returns the primary database, which runs INSERT, UPDATE and DELETE
+// statements and reads that must see them
+func (p *Pool) Writer() *sql.DB {
+	return p.primary
+}
+
+// Reader returns the database running SELECT-only statements. A replica may lag
+// behind the primary.
+func (p *Pool) Reader() *sql.DB {
+	return p.replica
+}
+
+// HasReplica reports whether reads go to a replica rather than the primary
+func (p *Pool) HasReplica() bool {
+	return p.replica != p.primary
+}
+
+// WithUserContext begins a transaction on the primary in which row-level
+// security policies are evaluated for userID. The caller must commit or roll
+// back the transaction.
+func (p *Pool) WithUserContext(ctx context.Context, userID string) (*sql.Tx, error) {
+	return withUserContext(ctx, p.primary, userID, nil)
+}
+
+// WithUserReadContext begins a read-only transaction on the reader in which
+// row-level security policies are evaluated for userID. The caller must commit
+// or roll back the transaction.
+func (p *Pool) WithUserReadContext(ctx context.Context, userID string) (*sql.Tx, error) {
+	return withUserContext(ctx, p.replica, userID, &sql.TxOptions{ReadOnly: true})
+}
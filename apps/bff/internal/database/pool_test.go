@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPool_ReadsFromPrimaryWithoutReplica(t *testing.T) {
+	primary, err := sql.Open("postgres", "postgres://localhost/primary")
+	require.NoError(t, err)
+	defer primary.Close()
+
+	pool := NewPool(primary, nil)
+	assert.Same(t, primary, pool.Writer())
+	assert.Same(t, primary, pool.Reader())
+	assert.False(t, pool.HasReplica())
+
+	replica, err := sql.Open("postgres", "postgres://localhost/replica")
+	require.NoError(t, err)
+	defer replica.Close()
+
+	pool = NewPool(primary, replica)
+	assert.Same(t, primary, pool.Writer())
+	assert.Same(t, replica, pool.Reader())
+	assert.True(t, pool.HasReplica())
+}
+
+func TestPool_WithUserReadContext(t *testing.T) {
+	sqlDB := openTestDB(t)
+	require.NoError(t, Migrate(sqlDB, testMigrationsDir))
+	pool := NewPool(sqlDB, nil)
+
+	alice := seedTenant(t, sqlDB)
+	bob := seedTenant(t, sqlDB)
+
+	tx, err := pool.WithUserReadContext(context.Background(), alice.userID)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	// Row-level security applies to reads
+	assert.Equal(t, 1, countVisible(t, tx, `SELECT COUNT(*) FROM projects WHERE id = $1`, alice.projectID))
+	assert.Zero(t, countVisible(t, tx, `SELECT COUNT(*) FROM projects WHERE id = $1`, bob.projectID))
+
+	// Writes are rejected, as they would be on a replica
+	_, err = tx.Exec(`UPDATE projects SET name = 'Renamed' WHERE id = $1`, alice.projectID)
+	assert.Error(t, err)
+}
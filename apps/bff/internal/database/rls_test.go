@@ -0,0 +1,175 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenant struct {
+	userID    string
+	projectID string
+	boardID   string
+	assetID   string
+}
+
+func seedTenant(t *testing.T, db *sql.DB) tenant {
+	t.Helper()
+
+	tn := tenant{
+		userID:    uuid.New().String(),
+		projectID: uuid.New().String(),
+		boardID:   uuid.New().String(),
+		assetID:   uuid.New().String(),
+	}
+
+	_, err := db.Exec(`INSERT INTO users (id, email, name) VALUES ($1, $2, $3)`,
+		tn.userID, tn.userID+"@rls.test", "RLS Tenant")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Exec(`DELETE FROM users WHERE id = $1`, tn.userID) })
+
+	_, err = db.Exec(`INSERT INTO projects (id, name, owner_id) VALUES ($1, $2, $3)`,
+		tn.projectID, "RLS Project", tn.userID)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`INSERT INTO boards (id, name, project_id) VALUES ($1, $2, $3)`,
+		tn.boardID, "RLS Board", tn.projectID)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`INSERT INTO assets (id, name, type, board_id) VALUES ($1, $2, 'IMAGE', $3)`,
+		tn.assetID, "rls.png", tn.boardID)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`INSERT INTO chat_messages (content, user_id, board_id) VALUES ($1, $2, $3)`,
+		"hello", tn.userID, tn.boardID)
+	require.NoError(t, err)
+
+	return tn
+}
+
+func countVisible(t *testing.T, tx *sql.Tx, query string, args ...interface{}) int {
+	t.Helper()
+
+	var count int
+	require.NoError(t, tx.QueryRow(query, args...).Scan(&count))
+
+	return count
+}
+
+func TestWithUserContext_BlocksCrossTenantReads(t *testing.T) {
+	sqlDB := openTestDB(t)
+	require.NoError(t, Migrate(sqlDB, testMigrationsDir))
+	db := &DB{DB: sqlDB}
+
+	alice := seedTenant(t, sqlDB)
+	bob := seedTenant(t, sqlDB)
+
+	tx, err := db.WithUserContext(context.Background(), alice.userID)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	// Alice sees her own rows
+	assert.Equal(t, 1, countVisible(t, tx, `SELECT COUNT(*) FROM projects WHERE id = $1`, alice.projectID))
+	assert.Equal(t, 1, countVisible(t, tx, `SELECT COUNT(*) FROM boards WHERE id = $1`, alice.boardID))
+	assert.Equal(t, 1, countVisible(t, tx, `SELECT COUNT(*) FROM assets WHERE id = $1`, alice.assetID))
+	assert.Equal(t, 1, countVisible(t, tx, `SELECT COUNT(*) FROM chat_messages WHERE board_id = $1`, alice.boardID))
+
+	// Bob's rows are filtered out even when addressed directly
+	assert.Zero(t, countVisible(t, tx, `SELECT COUNT(*) FROM projects WHERE id = $1`, bob.projectID))
+	assert.Zero(t, countVisible(t, tx, `SELECT COUNT(*) FROM boards WHERE id = $1`, bob.boardID))
+	assert.Zero(t, countVisible(t, tx, `SELECT COUNT(*) FROM assets WHERE id = $1`, bob.assetID))
+	assert.Zero(t, countVisible(t, tx, `SELECT COUNT(*) FROM chat_messages WHERE board_id = $1`, bob.boardID))
+}
+
+func TestWithUserContext_BlocksCrossTenantWrites(t *testing.T) {
+	sqlDB := openTestDB(t)
+	require.NoError(t, Migrate(sqlDB, testMigrationsDir))
+	db := &DB{DB: sqlDB}
+
+	alice := seedTenant(t, sqlDB)
+	bob := seedTenant(t, sqlDB)
+
+	tx, err := db.WithUserContext(context.Background(), alice.userID)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	// Updates against invisible rows silently match nothing
+	result, err := tx.Exec(`UPDATE assets SET name = 'hijacked' WHERE id = $1`, bob.assetID)
+	require.NoError(t, err)
+	affected, err := result.RowsAffected()
+	require.NoError(t, err)
+	assert.Zero(t, affected)
+
+	// Inserts into another tenant's project violate the policy
+	_, err = tx.Exec(`INSERT INTO boards (name, project_id) VALUES ($1, $2)`, "intruder", bob.projectID)
+	assert.Error(t, err)
+}
+
+func TestWithUserContext_ProjectMembership(t *testing.T) {
+	sqlDB := openTestDB(t)
+	require.NoError(t, Migrate(sqlDB, testMigrationsDir))
+	db := &DB{DB: sqlDB}
+
+	alice := seedTenant(t, sqlDB)
+	bob := seedTenant(t, sqlDB)
+
+	_, err := sqlDB.Exec(`INSERT INTO project_members (project_id, user_id) VALUES ($1, $2)`,
+		bob.projectID, alice.userID)
+	require.NoError(t, err)
+
+	tx, err := db.WithUserContext(context.Background(), alice.userID)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	assert.Equal(t, 1, countVisible(t, tx, `SELECT COUNT(*) FROM projects WHERE id = $1`, bob.projectID))
+	assert.Equal(t, 1, countVisible(t, tx, `SELECT COUNT(*) FROM assets WHERE id = $1`, bob.assetID))
+}
+
+func TestScopeToProject_HidesOtherProjects(t *testing.T) {
+	sqlDB := openTestDB(t)
+	require.NoError(t, Migrate(sqlDB, testMigrationsDir))
+	db := &DB{DB: sqlDB}
+
+	alice := seedTenant(t, sqlDB)
+	bob := seedTenant(t, sqlDB)
+
+	_, err := sqlDB.Exec(`INSERT INTO project_members (project_id, user_id) VALUES ($1, $2)`,
+		bob.projectID, alice.userID)
+	require.NoError(t, err)
+
+	tx, err := db.WithUserContext(context.Background(), alice.userID)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	require.NoError(t, ScopeToProject(context.Background(), tx, alice.projectID))
+
+	// Bob's project is hidden despite the membership
+	assert.Equal(t, 1, countVisible(t, tx, `SELECT COUNT(*) FROM projects WHERE id = $1`, alice.projectID))
+	assert.Zero(t, countVisible(t, tx, `SELECT COUNT(*) FROM projects WHERE id = $1`, bob.projectID))
+	assert.Zero(t, countVisible(t, tx, `SELECT COUNT(*) FROM assets WHERE id = $1`, bob.assetID))
+}
+
+func TestWithUserContext_SettingIsTransactionScoped(t *testing.T) {
+	sqlDB := openTestDB(t)
+	require.NoError(t, Migrate(sqlDB, testMigrationsDir))
+	db := &DB{DB: sqlDB}
+
+	alice := seedTenant(t, sqlDB)
+
+	tx, err := db.WithUserContext(context.Background(), alice.userID)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	// The pooled connection must not keep the user or the restricted role
+	var currentUserID sql.NullString
+	err = sqlDB.QueryRow(`SELECT NULLIF(current_setting('app.current_user_id', true), '')`).Scan(&currentUserID)
+	require.NoError(t, err)
+	assert.False(t, currentUserID.Valid)
+
+	var role string
+	require.NoError(t, sqlDB.QueryRow(`SELECT current_user`).Scan(&role))
+	assert.NotEqual(t, AppRole, role)
+}
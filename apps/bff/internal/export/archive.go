@@ -0,0 +1,153 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+// FormatVersion is the version of the board archive layout
+const FormatVersion = 1
+
+// Manifest is written to manifest.json at the root of a board archive
+type Manifest struct {
+	FormatVersion int             `json:"formatVersion"`
+	ExportedAt    time.Time       `json:"exportedAt"`
+	Board         BoardRecord     `json:"board"`
+	Assets        []ManifestEntry `json:"assets"`
+}
+
+// BoardRecord holds the board metadata included in the manifest
+type BoardRecord struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	ProjectID   string    `json:"projectId"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// ManifestEntry points at the JSON file of one asset
+type ManifestEntry struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// AssetRecord is written to assets/<id>.json for every asset on the board
+type AssetRecord struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Type       model.AssetType   `json:"type"`
+	URL        *string           `json:"url,omitempty"`
+	Status     model.AssetStatus `json:"status"`
+	BoardID    string            `json:"boardId"`
+	ApprovedBy *string           `json:"approvedBy,omitempty"`
+	ApprovedAt *time.Time        `json:"approvedAt,omitempty"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	UpdatedAt  time.Time         `json:"updatedAt"`
+	File       FileReference     `json:"file"`
+}
+
+// FileReference describes where an asset's file would be stored in the archive.
+// Files are not included until assets are served through a CDN the BFF can read.
+type FileReference struct {
+	Path      string  `json:"path"`
+	SourceURL *string `json:"sourceUrl,omitempty"`
+	Included  bool    `json:"included"`
+}
+
+// BuildBoardArchive packs the board's metadata and assets into a ZIP archive
+func BuildBoardArchive(board *model.Board, assets []*model.Asset, exportedAt time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	manifest := Manifest{
+		FormatVersion: FormatVersion,
+		ExportedAt:    exportedAt.UTC(),
+		Board: BoardRecord{
+			ID:          board.ID,
+			Name:        board.Name,
+			Description: board.Description,
+			ProjectID:   board.ProjectID,
+			CreatedAt:   board.CreatedAt,
+			UpdatedAt:   board.UpdatedAt,
+		},
+		Assets: make([]ManifestEntry, 0, len(assets)),
+	}
+
+	for _, asset := range assets {
+		record := AssetRecord{
+			ID:         asset.ID,
+			Name:       asset.Name,
+			Type:       asset.Type,
+			URL:        asset.URL,
+			Status:     asset.Status,
+			BoardID:    asset.BoardID,
+			ApprovedAt: asset.ApprovedAt,
+			CreatedAt:  asset.CreatedAt,
+			UpdatedAt:  asset.UpdatedAt,
+			File: FileReference{
+				Path:      fileReferencePath(asset),
+				SourceURL: asset.URL,
+			},
+		}
+		if asset.ApprovedBy != nil {
+			record.ApprovedBy = &asset.ApprovedBy.ID
+		}
+
+		name := path.Join("assets", asset.ID+".json")
+		if err := writeJSON(zw, name, record); err != nil {
+			return nil, err
+		}
+
+		manifest.Assets = append(manifest.Assets, ManifestEntry{
+			ID:   asset.ID,
+			Name: asset.Name,
+			Path: name,
+		})
+	}
+
+	if err := writeJSON(zw, "manifest.json", manifest); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// fileReferencePath returns the archive path reserved for an asset's file,
+// keeping the extension of its source URL
+func fileReferencePath(asset *model.Asset) string {
+	ext := ""
+	if asset.URL != nil {
+		if u, err := url.Parse(*asset.URL); err == nil {
+			ext = path.Ext(u.Path)
+		}
+	}
+	return path.Join("files", asset.ID+ext)
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func readArchive(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	files := make(map[string][]byte, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		files[f.Name] = content
+	}
+
+	return files
+}
+
+func TestBuildBoardArchive(t *testing.T) {
+	created := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	approvedAt := created.Add(2 * time.Hour)
+
+	board := &model.Board{
+		ID:          "board-1",
+		Name:        "Launch",
+		Description: stringPtr("Spring launch assets"),
+		ProjectID:   "project-1",
+		CreatedAt:   created,
+		UpdatedAt:   created,
+	}
+	assets := []*model.Asset{
+		{
+			ID:         "asset-1",
+			Name:       "Hero banner",
+			Type:       model.AssetTypeImage,
+			URL:        stringPtr("https://cdn.example.com/hero.png?v=2"),
+			Status:     model.AssetStatusApproved,
+			BoardID:    "board-1",
+			ApprovedBy: &model.User{ID: "user-1"},
+			ApprovedAt: &approvedAt,
+			CreatedAt:  created,
+			UpdatedAt:  approvedAt,
+		},
+		{
+			ID:        "asset-2",
+			Name:      "Brief",
+			Type:      model.AssetTypeDocument,
+			Status:    model.AssetStatusDraft,
+			BoardID:   "board-1",
+			CreatedAt: created,
+			UpdatedAt: created,
+		},
+	}
+
+	data, err := BuildBoardArchive(board, assets, created.Add(24*time.Hour))
+	require.NoError(t, err)
+
+	files := readArchive(t, data)
+	assert.Len(t, files, 3)
+	require.Contains(t, files, "manifest.json")
+	require.Contains(t, files, "assets/asset-1.json")
+	require.Contains(t, files, "assets/asset-2.json")
+
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+	assert.Equal(t, FormatVersion, manifest.FormatVersion)
+	assert.Equal(t, "board-1", manifest.Board.ID)
+	assert.Equal(t, "Launch", manifest.Board.Name)
+	assert.Equal(t, "project-1", manifest.Board.ProjectID)
+	assert.Equal(t, []ManifestEntry{
+		{ID: "asset-1", Name: "Hero banner", Path: "assets/asset-1.json"},
+		{ID: "asset-2", Name: "Brief", Path: "assets/asset-2.json"},
+	}, manifest.Assets)
+
+	var hero AssetRecord
+	require.NoError(t, json.Unmarshal(files["assets/asset-1.json"], &hero))
+	assert.Equal(t, "Hero banner", hero.Name)
+	assert.Equal(t, model.AssetStatusApproved, hero.Status)
+	assert.Equal(t, "user-1", *hero.ApprovedBy)
+	assert.True(t, approvedAt.Equal(*hero.ApprovedAt))
+	assert.Equal(t, "files/asset-1.png", hero.File.Path)
+	assert.Equal(t, "https://cdn.example.com/hero.png?v=2", *hero.File.SourceURL)
+	assert.False(t, hero.File.Included)
+
+	var brief AssetRecord
+	require.NoError(t, json.Unmarshal(files["assets/asset-2.json"], &brief))
+	assert.Nil(t, brief.ApprovedBy)
+	assert.Equal(t, "files/asset-2", brief.File.Path)
+	assert.Nil(t, brief.File.SourceURL)
+}
+
+func TestBuildBoardArchive_EmptyBoard(t *testing.T) {
+	data, err := BuildBoardArchive(&model.Board{ID: "board-1", Name: "Empty"}, nil, time.Now())
+	require.NoError(t, err)
+
+	files := readArchive(t, data)
+	assert.Len(t, files, 1)
+
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+	assert.Empty(t, manifest.Assets)
+	assert.Contains(t, string(files["manifest.json"]), `"assets": []`)
+}
+
+func TestStore_SignedURL(t *testing.T) {
+	store := NewStore(nil, "signing-key", "https://api.example.com/")
+	now := time.Now()
+
+	signed := store.SignedURL("abc123", now.Add(TTL))
+	assert.True(t, strings.HasPrefix(signed, "https://api.example.com/board-export/abc123?"))
+
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	expires := u.Query().Get("expires")
+	signature := u.Query().Get("signature")
+
+	assert.NoError(t, store.Verify("abc123", expires, signature, now))
+
+	// Expired links are rejected
+	assert.ErrorIs(t, store.Verify("abc123", expires, signature, now.Add(TTL+time.Minute)), ErrInvalidSignature)
+
+	// Signatures cover the token and the expiry
+	assert.ErrorIs(t, store.Verify("abc124", expires, signature, now), ErrInvalidSignature)
+	assert.ErrorIs(t, store.Verify("abc123", expires+"0", signature, now), ErrInvalidSignature)
+
+	// Links signed with another key are rejected
+	other := NewStore(nil, "other-key", "https://api.example.com")
+	assert.ErrorIs(t, other.Verify("abc123", expires, signature, now), ErrInvalidSignature)
+}
@@ -0,0 +1,246 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+const (
+	// FormatJSON exports a board as one JSON document
+	FormatJSON = "json"
+
+	// FormatCSV exports a board as a ZIP archive of assets.csv and messages.csv
+	FormatCSV = "csv"
+)
+
+// ChunkSize is the number of bytes of an export sent to the client at once
+const ChunkSize = 32 * 1024
+
+// AssetRow is an asset in a board data export
+type AssetRow struct {
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Type         model.AssetType   `json:"type"`
+	URL          *string           `json:"url,omitempty"`
+	Status       model.AssetStatus `json:"status"`
+	ApprovedBy   *string           `json:"approvedBy,omitempty"`
+	ApprovedAt   *time.Time        `json:"approvedAt,omitempty"`
+	ScheduledAt  *time.Time        `json:"scheduledAt,omitempty"`
+	ThumbnailURL *string           `json:"thumbnailUrl,omitempty"`
+	CreatedAt    time.Time         `json:"createdAt"`
+	UpdatedAt    time.Time         `json:"updatedAt"`
+}
+
+// MessageRow is a chat message in a board data export
+type MessageRow struct {
+	ID        string    `json:"id"`
+	Content   string    `json:"content"`
+	UserID    string    `json:"userId"`
+	ParentID  *string   `json:"parentId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// BoardSource supplies the records of a board data export one at a time, so
+// that the export of a large board is never held in memory
+type BoardSource interface {
+	Board() BoardRecord
+	EachAsset(fn func(AssetRow) error) error
+	EachMessage(fn func(MessageRow) error) error
+}
+
+var (
+	assetColumns   = []string{"id", "name", "type", "url", "status", "approved_by", "approved_at", "scheduled_at", "thumbnail_url", "created_at", "updated_at"}
+	messageColumns = []string{"id", "content", "user_id", "parent_id", "created_at"}
+)
+
+// DataFileName returns the name of the file a board is exported to in format
+func DataFileName(boardID, format string, exportedAt time.Time) string {
+	ext := "json"
+	if format == FormatCSV {
+		ext = "zip"
+	}
+	return fmt.Sprintf("board-%s-%s.%s", boardID, exportedAt.UTC().Format("2006-01-02"), ext)
+}
+
+// WriteBoardJSON writes the board of source with its assets and messages as one
+// JSON document, encoding each record as it is read
+func WriteBoardJSON(w io.Writer, source BoardSource, exportedAt time.Time) error {
+	board, err := json.Marshal(source.Board())
+	if err != nil {
+		return fmt.Errorf("failed to encode board: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, `{"formatVersion":%d,"exportedAt":%q,"board":%s,"assets":[`,
+		FormatVersion, exportedAt.UTC().Format(time.RFC3339), board); err != nil {
+		return err
+	}
+
+	first := true
+	err = source.EachAsset(func(asset AssetRow) error {
+		return writeElement(w, asset, &first)
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, `],"messages":[`); err != nil {
+		return err
+	}
+
+	first = true
+	err = source.EachMessage(func(message MessageRow) error {
+		return writeElement(w, message, &first)
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "]}\n")
+	return err
+}
+
+// writeElement writes v as an element of a JSON array, after a comma unless it
+// is the first
+func writeElement(w io.Writer, v interface{}, first *bool) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+	if !*first {
+		if _, err := io.WriteString(w, ","); err != nil {
+			return err
+		}
+	}
+	*first = false
+
+	_, err = w.Write(data)
+	return err
+}
+
+// WriteBoardCSV writes the assets and messages of source as assets.csv and
+// messages.csv in a ZIP archive. The archive is written as it is built, so
+// that it never needs to be seeked.
+func WriteBoardCSV(w io.Writer, source BoardSource) error {
+	zw := zip.NewWriter(w)
+
+	file, err := zw.Create("assets.csv")
+	if err != nil {
+		return fmt.Errorf("failed to add assets.csv to archive: %w", err)
+	}
+	cw := csv.NewWriter(file)
+	if err := cw.Write(assetColumns); err != nil {
+		return err
+	}
+	err = source.EachAsset(func(asset AssetRow) error {
+		return cw.Write([]string{
+			asset.ID, asset.Name, string(asset.Type), optional(asset.URL), string(asset.Status),
+			optional(asset.ApprovedBy), optionalTime(asset.ApprovedAt), optionalTime(asset.ScheduledAt),
+			optional(asset.ThumbnailURL), formatTime(asset.CreatedAt), formatTime(asset.UpdatedAt),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write assets.csv: %w", err)
+	}
+
+	file, err = zw.Create("messages.csv")
+	if err != nil {
+		return fmt.Errorf("failed to add messages.csv to archive: %w", err)
+	}
+	cw = csv.NewWriter(file)
+	if err := cw.Write(messageColumns); err != nil {
+		return err
+	}
+	err = source.EachMessage(func(message MessageRow) error {
+		return cw.Write([]string{
+			message.ID, message.Content, message.UserID, optional(message.ParentID), formatTime(message.CreatedAt),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write messages.csv: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize archive: %w", err)
+	}
+	return nil
+}
+
+func optional(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func optionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return formatTime(*t)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// ChunkedWriter sends what is written to an HTTP response in chunks of
+// ChunkSize bytes, flushing each to the client instead of buffering the
+// response
+type ChunkedWriter struct {
+	buf  *bufio.Writer
+	sent *flushWriter
+}
+
+// NewChunkedWriter creates a writer of the response w. Flush sends what is left.
+func NewChunkedWriter(w http.ResponseWriter) *ChunkedWriter {
+	flusher, _ := w.(http.Flusher)
+	sent := &flushWriter{w: w, flusher: flusher}
+	return &ChunkedWriter{buf: bufio.NewWriterSize(sent, ChunkSize), sent: sent}
+}
+
+// Write buffers p, sending the buffer once it holds ChunkSize bytes
+func (c *ChunkedWriter) Write(p []byte) (int, error) {
+	return c.buf.Write(p)
+}
+
+// Flush sends what has been buffered
+func (c *ChunkedWriter) Flush() error {
+	return c.buf.Flush()
+}
+
+// Started reports whether a chunk has been sent, and with it the response
+// status and headers
+func (c *ChunkedWriter) Started() bool {
+	return c.sent.chunks > 0
+}
+
+// flushWriter flushes every write to the client
+type flushWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+	chunks  int
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	f.chunks++
+	n, err := f.w.Write(p)
+	if err == nil && f.flusher != nil {
+		f.flusher.Flush()
+	}
+	return n, err
+}
@@ -0,0 +1,238 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/graph/model"
+)
+
+// generatedBoard is a board source producing its assets as they are read
+type generatedBoard struct {
+	assets   int
+	messages []MessageRow
+
+	// produced is the number of assets handed out so far
+	produced int
+}
+
+func (b *generatedBoard) Board() BoardRecord {
+	return BoardRecord{
+		ID:        "board-1",
+		Name:      "Launch",
+		ProjectID: "project-1",
+		CreatedAt: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func (b *generatedBoard) EachAsset(fn func(AssetRow) error) error {
+	created := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < b.assets; i++ {
+		b.produced++
+		err := fn(AssetRow{
+			ID:        fmt.Sprintf("asset-%d", i),
+			Name:      fmt.Sprintf("Banner, variant \"%d\"", i),
+			Type:      model.AssetTypeImage,
+			URL:       stringPtr(fmt.Sprintf("https://cdn.example.com/%d.png", i)),
+			Status:    model.AssetStatusApproved,
+			CreatedAt: created,
+			UpdatedAt: created,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *generatedBoard) EachMessage(fn func(MessageRow) error) error {
+	for _, message := range b.messages {
+		if err := fn(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestWriteBoardJSON(t *testing.T) {
+	source := &generatedBoard{
+		assets: 2,
+		messages: []MessageRow{
+			{ID: "message-1", Content: "Looks good", UserID: "user-1", CreatedAt: time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)},
+			{ID: "message-2", Content: "Agreed", UserID: "user-2", ParentID: stringPtr("message-1"), CreatedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteBoardJSON(&buf, source, time.Date(2024, 3, 5, 8, 0, 0, 0, time.UTC)))
+
+	var document struct {
+		FormatVersion int          `json:"formatVersion"`
+		ExportedAt    time.Time    `json:"exportedAt"`
+		Board         BoardRecord  `json:"board"`
+		Assets        []AssetRow   `json:"assets"`
+		Messages      []MessageRow `json:"messages"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &document))
+	assert.Equal(t, FormatVersion, document.FormatVersion)
+	assert.Equal(t, "board-1", document.Board.ID)
+	require.Len(t, document.Assets, 2)
+	assert.Equal(t, `Banner, variant "1"`, document.Assets[1].Name)
+	require.Len(t, document.Messages, 2)
+	assert.Equal(t, "message-1", *document.Messages[1].ParentID)
+}
+
+func TestWriteBoardJSON_EmptyBoard(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteBoardJSON(&buf, &generatedBoard{}, time.Now()))
+
+	var document map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &document))
+	assert.JSONEq(t, "[]", string(document["assets"]))
+	assert.JSONEq(t, "[]", string(document["messages"]))
+}
+
+func TestWriteBoardCSV(t *testing.T) {
+	source := &generatedBoard{
+		assets: 3,
+		messages: []MessageRow{
+			{ID: "message-1", Content: "Line one\nline two", UserID: "user-1", CreatedAt: time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteBoardCSV(&buf, source))
+
+	files := readArchive(t, buf.Bytes())
+	require.Len(t, files, 2)
+
+	assets, err := csv.NewReader(bytes.NewReader(files["assets.csv"])).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, assets, 4)
+	assert.Equal(t, assetColumns, assets[0])
+	assert.Equal(t, []string{
+		"asset-0", `Banner, variant "0"`, "IMAGE", "https://cdn.example.com/0.png", "APPROVED",
+		"", "", "", "", "2024-03-01T10:00:00Z", "2024-03-01T10:00:00Z",
+	}, assets[1])
+
+	messages, err := csv.NewReader(bytes.NewReader(files["messages.csv"])).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, messageColumns, messages[0])
+	assert.Equal(t, []string{"message-1", "Line one\nline two", "user-1", "", "2024-03-01T11:00:00Z"}, messages[1])
+}
+
+func TestDataFileName(t *testing.T) {
+	exportedAt := time.Date(2024, 3, 5, 23, 30, 0, 0, time.FixedZone("PST", -8*3600))
+
+	assert.Equal(t, "board-b1-2024-03-06.json", DataFileName("b1", FormatJSON, exportedAt))
+	assert.Equal(t, "board-b1-2024-03-06.zip", DataFileName("b1", FormatCSV, exportedAt))
+}
+
+// progressRecorder records how many assets had been read whenever a chunk of
+// the export reached the client
+type progressRecorder struct {
+	*httptest.ResponseRecorder
+	source  *generatedBoard
+	flushes []int
+}
+
+func (p *progressRecorder) Flush() {
+	p.flushes = append(p.flushes, p.source.produced)
+	p.ResponseRecorder.Flush()
+}
+
+func TestChunkedWriter_StreamsLargeBoard(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			source := &generatedBoard{assets: 10000}
+			recorder := &progressRecorder{ResponseRecorder: httptest.NewRecorder(), source: source}
+
+			out := NewChunkedWriter(recorder)
+			var err error
+			if format == FormatCSV {
+				err = WriteBoardCSV(out, source)
+			} else {
+				err = WriteBoardJSON(out, source, time.Now())
+			}
+			require.NoError(t, err)
+			assert.True(t, out.Started())
+			require.NoError(t, out.Flush())
+
+			// Chunks are sent while the assets are still being read
+			require.Greater(t, len(recorder.flushes), 2)
+			assert.Less(t, recorder.flushes[0], source.assets/2)
+			assert.Equal(t, source.assets, source.produced)
+		})
+	}
+}
+
+// heapSampler records the largest heap seen while an export is written
+type heapSampler struct {
+	written int
+	writes  int
+	maxHeap uint64
+}
+
+func (h *heapSampler) Write(p []byte) (int, error) {
+	h.written += len(p)
+	h.writes++
+	if h.writes%16 == 0 {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		if stats.HeapAlloc > h.maxHeap {
+			h.maxHeap = stats.HeapAlloc
+		}
+	}
+	return len(p), nil
+}
+
+func TestWriteBoardJSON_MemoryOfLargeBoard(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping memory measurement in short mode")
+	}
+
+	// Long names make the export much larger than the heap it may use
+	source := &generatedBoard{assets: 10000}
+	var names strings.Builder
+	for names.Len() < 2048 {
+		names.WriteString("campaign ")
+	}
+	padded := &paddedBoard{generatedBoard: source, padding: names.String()}
+
+	runtime.GC()
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	sampler := &heapSampler{}
+	require.NoError(t, WriteBoardJSON(io.Writer(sampler), padded, time.Now()))
+
+	require.Greater(t, sampler.written, 20<<20)
+	assert.Less(t, int64(sampler.maxHeap)-int64(before.HeapAlloc), int64(16<<20),
+		"export of %d bytes grew the heap by %d bytes", sampler.written, int64(sampler.maxHeap)-int64(before.HeapAlloc))
+}
+
+// paddedBoard lengthens the names of the assets of a generated board
+type paddedBoard struct {
+	*generatedBoard
+	padding string
+}
+
+func (b *paddedBoard) EachAsset(fn func(AssetRow) error) error {
+	return b.generatedBoard.EachAsset(func(asset AssetRow) error {
+		asset.Name += b.padding
+		return fn(asset)
+	})
+}
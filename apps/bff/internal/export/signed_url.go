@@ -0,0 +1,73 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+const signedURLKeyPrefix = "signed_url:"
+
+// ErrTokenNotFound is returned when a download token has expired, has already
+// been redeemed or never existed
+var ErrTokenNotFound = errors.New("download token not found")
+
+// SignedURLStore keeps exported files in Redis behind single-use download tokens
+type SignedURLStore struct {
+	client redis.UniversalClient
+}
+
+// NewSignedURLStore creates a download token store
+func NewSignedURLStore(client redis.UniversalClient) *SignedURLStore {
+	return &SignedURLStore{client: client}
+}
+
+// GenerateSignedURL stores payload for ttl and returns the token that downloads it
+func (s *SignedURLStore) GenerateSignedURL(ctx context.Context, payload []byte, contentType, filename string, ttl time.Duration) (string, error) {
+	token := uuid.New().String()
+
+	key := signedURLKeyPrefix + token
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "payload", payload, "content_type", contentType, "filename", filename)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store download: %w", err)
+	}
+
+	return token, nil
+}
+
+// RedeemSignedURL returns the file stored for token and deletes it, so each token
+// can be redeemed only once
+func (s *SignedURLStore) RedeemSignedURL(ctx context.Context, token string) ([]byte, string, string, error) {
+	if _, err := uuid.Parse(token); err != nil {
+		return nil, "", "", ErrTokenNotFound
+	}
+
+	key := signedURLKeyPrefix + token
+	var values *redis.SliceCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		values = pipe.HMGet(ctx, key, "payload", "content_type", "filename")
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to redeem download: %w", err)
+	}
+
+	fields := values.Val()
+	payload, ok := fields[0].(string)
+	if !ok {
+		return nil, "", "", ErrTokenNotFound
+	}
+	contentType, _ := fields[1].(string)
+	filename, _ := fields[2].(string)
+
+	return []byte(payload), contentType, filename, nil
+}
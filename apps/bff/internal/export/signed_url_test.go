@@ -0,0 +1,126 @@
+package export
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSignedURLStore(t *testing.T) (*SignedURLStore, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewSignedURLStore(client), server
+}
+
+func TestSignedURLStore_Redeem(t *testing.T) {
+	store, _ := newTestSignedURLStore(t)
+	ctx := context.Background()
+
+	token, err := store.GenerateSignedURL(ctx, []byte("report contents"), "text/csv", "report.csv", time.Minute)
+	require.NoError(t, err)
+
+	payload, contentType, filename, err := store.RedeemSignedURL(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("report contents"), payload)
+	assert.Equal(t, "text/csv", contentType)
+	assert.Equal(t, "report.csv", filename)
+
+	// Tokens are single-use
+	_, _, _, err = store.RedeemSignedURL(ctx, token)
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+}
+
+func TestSignedURLStore_Expiry(t *testing.T) {
+	store, server := newTestSignedURLStore(t)
+	ctx := context.Background()
+
+	token, err := store.GenerateSignedURL(ctx, []byte("report contents"), "text/csv", "report.csv", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, server.TTL(signedURLKeyPrefix+token))
+
+	server.FastForward(time.Minute + time.Second)
+
+	_, _, _, err = store.RedeemSignedURL(ctx, token)
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+}
+
+func TestSignedURLStore_UnknownToken(t *testing.T) {
+	store, _ := newTestSignedURLStore(t)
+	ctx := context.Background()
+
+	for _, token := range []string{"", "not-a-token", "0b6f2a7e-4a4c-4f49-9a59-6a1f5e0c3d21"} {
+		_, _, _, err := store.RedeemSignedURL(ctx, token)
+		assert.ErrorIs(t, err, ErrTokenNotFound, token)
+	}
+}
+
+func TestSignedURLStore_ConcurrentRedemption(t *testing.T) {
+	store, _ := newTestSignedURLStore(t)
+	ctx := context.Background()
+
+	token, err := store.GenerateSignedURL(ctx, []byte("archive"), "application/zip", "board.zip", time.Minute)
+	require.NoError(t, err)
+
+	const redeemers = 20
+	var wg sync.WaitGroup
+	results := make(chan error, redeemers)
+	for i := 0; i < redeemers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, _, err := store.RedeemSignedURL(ctx, token)
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	redeemed := 0
+	for err := range results {
+		if err == nil {
+			redeemed++
+		} else {
+			assert.ErrorIs(t, err, ErrTokenNotFound)
+		}
+	}
+	assert.Equal(t, 1, redeemed)
+}
+
+func TestStore_Load(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	store := NewStore(client, "signing-key", "https://api.example.com")
+	ctx := context.Background()
+
+	downloadURL, _, err := store.Save(ctx, "board-1", []byte("archive"))
+	require.NoError(t, err)
+	u, err := url.Parse(downloadURL)
+	require.NoError(t, err)
+	token := strings.TrimPrefix(u.Path, DownloadPath)
+	expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+
+	_, _, _, err = store.Load(ctx, token, expires, "bad-signature")
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	archive, contentType, filename, err := store.Load(ctx, token, expires, signature)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("archive"), archive)
+	assert.Equal(t, "application/zip", contentType)
+	assert.Equal(t, "board-board-1.zip", filename)
+
+	_, _, _, err = store.Load(ctx, token, expires, signature)
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+}
@@ -0,0 +1,96 @@
+package export
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// TTL is how long an exported archive can be downloaded
+const TTL = 30 * time.Minute
+
+// DownloadPath is the HTTP path prefix that serves exported archives
+const DownloadPath = "/board-export/"
+
+// ErrInvalidSignature is returned when a download URL has been tampered with or has expired
+var ErrInvalidSignature = errors.New("invalid or expired download link")
+
+// Store keeps exported archives behind single-use download tokens and issues
+// signed download URLs for them
+type Store struct {
+	urls       *SignedURLStore
+	signingKey []byte
+	baseURL    string
+}
+
+// NewStore creates an export store. Download URLs are signed with signingKey and
+// point at baseURL, the public address of the BFF.
+func NewStore(client redis.UniversalClient, signingKey, baseURL string) *Store {
+	return &Store{
+		urls:       NewSignedURLStore(client),
+		signingKey: []byte(signingKey),
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Save stores the archive of a board for TTL and returns its signed download URL
+func (s *Store) Save(ctx context.Context, boardID string, archive []byte) (string, time.Time, error) {
+	token, err := s.urls.GenerateSignedURL(ctx, archive, "application/zip", fmt.Sprintf("board-%s.zip", boardID), TTL)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to store export: %w", err)
+	}
+
+	expiresAt := time.Now().Add(TTL).Truncate(time.Second)
+	return s.SignedURL(token, expiresAt), expiresAt, nil
+}
+
+// Load verifies a download link and returns the archive it refers to with its
+// content type and file name. The link cannot be used again afterwards.
+func (s *Store) Load(ctx context.Context, token, expires, signature string) ([]byte, string, string, error) {
+	if err := s.Verify(token, expires, signature, time.Now()); err != nil {
+		return nil, "", "", err
+	}
+
+	return s.urls.RedeemSignedURL(ctx, token)
+}
+
+// SignedURL returns the download URL of token, valid until expiresAt
+func (s *Store) SignedURL(token string, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(token, expires))
+
+	return s.baseURL + DownloadPath + token + "?" + query.Encode()
+}
+
+// Verify checks the signature and expiry of a download link
+func (s *Store) Verify(token, expires, signature string, now time.Time) error {
+	expected := s.sign(token, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > expiresAt {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func (s *Store) sign(token, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(token + ":" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
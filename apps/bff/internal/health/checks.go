@@ -0,0 +1,111 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultCheckTimeout bounds each dependency check when no timeout is configured
+const DefaultCheckTimeout = 5 * time.Second
+
+// StatusDegraded is the status of a dependency the BFF serves requests without,
+// with fewer features
+const StatusDegraded = "degraded"
+
+// degradedError is returned by the Check of a dependency the BFF runs without
+type degradedError struct {
+	reason string
+}
+
+func (e *degradedError) Error() string {
+	return e.reason
+}
+
+// Degraded returns the error a Check reports for a dependency the BFF is
+// running without, such as Redis when it is not configured. The dependency is
+// reported as degraded rather than unhealthy.
+func Degraded(reason string) error {
+	return &degradedError{reason: reason}
+}
+
+// Dependency is a service the BFF needs to serve requests
+type Dependency struct {
+	Name string
+
+	// Check returns an error when the service cannot be reached
+	Check func(ctx context.Context) error
+}
+
+// DependencyStatus is the result of checking a dependency
+type DependencyStatus struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Healthy returns true if the dependency could be reached
+func (s DependencyStatus) Healthy() bool {
+	return s.Status == StatusHealthy
+}
+
+// Unhealthy returns true if the BFF cannot serve requests without the
+// dependency, which could not be reached
+func (s DependencyStatus) Unhealthy() bool {
+	return s.Status == StatusUnhealthy
+}
+
+// CheckDependencies checks the dependencies concurrently, giving each at most
+// timeout, and returns their statuses by name
+func CheckDependencies(ctx context.Context, timeout time.Duration, dependencies []Dependency) map[string]DependencyStatus {
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	statuses := make(map[string]DependencyStatus, len(dependencies))
+	for _, dependency := range dependencies {
+		wg.Add(1)
+		go func(dependency Dependency) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := dependency.Check(checkCtx)
+			status := DependencyStatus{Status: StatusHealthy, LatencyMs: time.Since(start).Milliseconds()}
+			var degraded *degradedError
+			if errors.As(err, &degraded) {
+				status.Status = StatusDegraded
+				status.Error = err.Error()
+			} else if err != nil {
+				status.Status = StatusUnhealthy
+				status.Error = err.Error()
+			}
+
+			mu.Lock()
+			statuses[dependency.Name] = status
+			mu.Unlock()
+		}(dependency)
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+// Summarize returns the health of each dependency as recorded in snapshots,
+// healthy, or degraded or unhealthy followed by the error
+func Summarize(statuses map[string]DependencyStatus) map[string]string {
+	services := make(map[string]string, len(statuses))
+	for name, status := range statuses {
+		services[name] = status.Status
+		if !status.Healthy() {
+			services[name] = fmt.Sprintf("%s: %s", status.Status, status.Error)
+		}
+	}
+	return services
+}
@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDependencies(t *testing.T) {
+	start := time.Now()
+	statuses := CheckDependencies(context.Background(), 50*time.Millisecond, []Dependency{
+		{Name: "database", Check: func(ctx context.Context) error {
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		}},
+		{Name: "redis", Check: func(ctx context.Context) error {
+			return errors.New("connection refused")
+		}},
+		{Name: "nats", Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+	})
+
+	// The checks run concurrently, each within the timeout
+	assert.Less(t, time.Since(start), 200*time.Millisecond)
+
+	require.Len(t, statuses, 3)
+	assert.True(t, statuses["database"].Healthy())
+	assert.GreaterOrEqual(t, statuses["database"].LatencyMs, int64(20))
+	assert.Equal(t, StatusUnhealthy, statuses["redis"].Status)
+	assert.Equal(t, "connection refused", statuses["redis"].Error)
+	assert.Equal(t, "context deadline exceeded", statuses["nats"].Error)
+	assert.GreaterOrEqual(t, statuses["nats"].LatencyMs, int64(50))
+
+	assert.Equal(t, map[string]string{
+		"database": StatusHealthy,
+		"redis":    "unhealthy: connection refused",
+		"nats":     "unhealthy: context deadline exceeded",
+	}, Summarize(statuses))
+}
+
+func TestCheckDependencies_Degraded(t *testing.T) {
+	statuses := CheckDependencies(context.Background(), time.Second, []Dependency{
+		{Name: "redis", Check: func(ctx context.Context) error {
+			return Degraded("not configured")
+		}},
+	})
+
+	// Dependencies the BFF runs without are degraded, not unhealthy
+	assert.Equal(t, StatusDegraded, statuses["redis"].Status)
+	assert.False(t, statuses["redis"].Healthy())
+	assert.False(t, statuses["redis"].Unhealthy())
+	assert.Equal(t, map[string]string{"redis": "degraded: not configured"}, Summarize(statuses))
+}
@@ -0,0 +1,56 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectIncidents(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	at := func(i int) time.Time { return start.Add(time.Duration(i) * RecordInterval) }
+	snapshot := func(instance string, i int, database string) Snapshot {
+		status := StatusHealthy
+		if database != StatusHealthy {
+			status = StatusUnhealthy
+		}
+		return Snapshot{
+			Timestamp: at(i),
+			Instance:  instance,
+			Status:    status,
+			Services:  map[string]string{"database": database, "nats": StatusHealthy},
+		}
+	}
+
+	incidents := DetectIncidents([]Snapshot{
+		snapshot("bff-1", 0, StatusHealthy),
+		snapshot("bff-2", 0, "unhealthy: connection refused"),
+		snapshot("bff-1", 1, "unhealthy: connection refused"),
+		snapshot("bff-2", 1, StatusHealthy),
+		snapshot("bff-1", 2, StatusHealthy),
+		snapshot("bff-2", 2, "unhealthy: connection refused"),
+	})
+
+	// bff-2 was already unhealthy in its first snapshot, so only its second failure counts
+	require.Len(t, incidents, 2)
+
+	assert.Equal(t, "bff-1", incidents[0].Instance)
+	assert.Equal(t, at(1), incidents[0].StartedAt)
+	require.NotNil(t, incidents[0].ResolvedAt)
+	assert.Equal(t, at(2), *incidents[0].ResolvedAt)
+	assert.Equal(t, map[string]string{"database": "unhealthy: connection refused"}, incidents[0].Services)
+
+	assert.Equal(t, "bff-2", incidents[1].Instance)
+	assert.Equal(t, at(2), incidents[1].StartedAt)
+	assert.Nil(t, incidents[1].ResolvedAt)
+}
+
+func TestDetectIncidents_NoTransitions(t *testing.T) {
+	assert.Empty(t, DetectIncidents(nil))
+	assert.Empty(t, DetectIncidents([]Snapshot{
+		{Timestamp: time.Now(), Instance: "bff-1", Status: StatusHealthy},
+		{Timestamp: time.Now().Add(RecordInterval), Instance: "bff-1", Status: StatusHealthy},
+	}))
+}
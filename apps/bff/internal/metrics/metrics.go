@@ -0,0 +1,115 @@
+// Package metrics defines the Prometheus metrics of the BFF. The metrics are
+// registered with the default registry, which Handler serves.
+package metrics
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "zamc_bff"
+
+var (
+	// GraphQLRequests counts GraphQL operations by operation name and status,
+	// which is "success" or "error"
+	GraphQLRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "graphql_requests_total",
+		Help:      "Number of GraphQL operations by operation name and status.",
+	}, []string{"operation", "status"})
+
+	// GraphQLRequestDuration observes the time from parsing a GraphQL operation to
+	// its response
+	GraphQLRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "graphql_request_duration_seconds",
+		Help:      "Duration of GraphQL operations by operation name.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	// CacheRequests counts resolver cache lookups by cache and result, which is
+	// "hit" or "miss"
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Number of resolver cache lookups by cache and result.",
+	}, []string{"cache", "result"})
+
+	// NATSPublishDuration observes the time to publish a NATS message
+	NATSPublishDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "nats_publish_duration_seconds",
+		Help:      "Duration of NATS publishes by subject.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"subject"})
+
+	// NATSRequestDuration observes the time from sending a NATS request to its reply
+	NATSRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "nats_request_duration_seconds",
+		Help:      "Duration of NATS requests until their reply by subject.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"subject"})
+
+	// NATSHandleDuration observes the time subscribers take to handle a NATS message
+	NATSHandleDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "nats_handle_duration_seconds",
+		Help:      "Duration of NATS message handling by subject.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"subject"})
+
+	// RateLimitRejections counts requests rejected by the rate limiter by endpoint
+	RateLimitRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Number of requests rejected by the rate limiter by endpoint.",
+	}, []string{"endpoint"})
+
+	// TokenVerificationDuration observes the time to verify an access token by
+	// result, which is "valid" or "invalid"
+	TokenVerificationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "auth_token_verification_duration_seconds",
+		Help:      "Duration of access token verifications by result.",
+		Buckets:   []float64{.0001, .0005, .001, .0025, .005, .01, .025, .05, .1, .25},
+	}, []string{"result"})
+
+	// SecurityEvents counts the events recorded by the security monitor by type
+	SecurityEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "security_events_total",
+		Help:      "Number of security events by event type.",
+	}, []string{"event_type"})
+)
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Subject returns subject with its UUID tokens replaced by "*", so that the
+// subjects of different boards or projects share their metrics
+func Subject(subject string) string {
+	tokens := strings.Split(subject, ".")
+	for i, token := range tokens {
+		if _, err := uuid.Parse(token); err == nil {
+			tokens[i] = "*"
+		}
+	}
+	return strings.Join(tokens, ".")
+}
+
+// Route returns the pattern of the route r matched, such as "/auth/sessions/",
+// so that requests for different IDs share their metrics, or "unmatched"
+func Route(r *http.Request) string {
+	if r.Pattern == "" {
+		return "unmatched"
+	}
+	return r.Pattern
+}
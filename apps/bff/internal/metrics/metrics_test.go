@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubject(t *testing.T) {
+	assert.Equal(t, "board.*.ot", Subject("board.6f1c2d3e-8b9a-4c5d-9e0f-1a2b3c4d5e6f.ot"))
+	assert.Equal(t, "zamc.events.asset.status_changed", Subject("zamc.events.asset.status_changed"))
+
+	// Tokens that merely contain a UUID are kept
+	assert.Equal(t, "board.x6f1c2d3e-8b9a-4c5d-9e0f-1a2b3c4d5e6f", Subject("board.x6f1c2d3e-8b9a-4c5d-9e0f-1a2b3c4d5e6f"))
+}
+
+func TestRoute(t *testing.T) {
+	var route string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth/sessions/", func(w http.ResponseWriter, r *http.Request) {
+		route = Route(r)
+	})
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/auth/sessions/6f1c2d3e", nil))
+	assert.Equal(t, "/auth/sessions/", route)
+
+	assert.Equal(t, "unmatched", Route(httptest.NewRequest(http.MethodGet, "/query", nil)))
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxBatchSize is the batch size limit used when none is configured
+const DefaultMaxBatchSize = 10
+
+// MaxRequestBodySize is the largest JSON body read into memory to look for a
+// batch
+const MaxRequestBodySize = 1 << 20
+
+// BatchRequestMiddleware lets clients send several GraphQL operations in one
+// POST request, as a JSON array of the usual request bodies. Each operation is
+// run through next on its own with the context and headers of the request, so
+// that it is authorized and counted against the rate limit individually, and
+// the responses are written as a JSON array in the order of the operations. An
+// operation failing outside GraphQL, e.g. because it was rate limited, gets a
+// response with the error and its HTTP status in place of its result.
+//
+// Batches of more than maxSize operations are rejected with 400, and bodies of
+// more than MaxRequestBodySize bytes with 413. Requests whose body is not an
+// array are passed to next unchanged.
+func BatchRequestMiddleware(maxSize int) func(http.Handler) http.Handler {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxBatchSize
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxRequestBodySize))
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+					return
+				}
+				http.Error(w, "Failed to read request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			if !isBatch(body) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var operations []json.RawMessage
+			if err := json.Unmarshal(body, &operations); err != nil {
+				http.Error(w, "Invalid batch request", http.StatusBadRequest)
+				return
+			}
+			for _, operation := range operations {
+				if !bytes.HasPrefix(bytes.TrimLeft(operation, " \t\r\n"), []byte("{")) {
+					http.Error(w, "Invalid batch request", http.StatusBadRequest)
+					return
+				}
+			}
+			if len(operations) == 0 {
+				http.Error(w, "Batch must contain at least one operation", http.StatusBadRequest)
+				return
+			}
+			if len(operations) > maxSize {
+				http.Error(w, "Batch exceeds the maximum of "+strconv.Itoa(maxSize)+" operations", http.StatusBadRequest)
+				return
+			}
+
+			responses := make([]json.RawMessage, len(operations))
+			for i, operation := range operations {
+				rec := httptest.NewRecorder()
+				next.ServeHTTP(rec, batchOperationRequest(r, operation))
+
+				// Headers such as X-RateLimit-Remaining are those of the last operation
+				for key, values := range rec.Header() {
+					if key != "Content-Type" && key != "Content-Length" {
+						w.Header()[key] = values
+					}
+				}
+				responses[i] = batchOperationResponse(rec)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(responses)
+		})
+	}
+}
+
+// isBatch returns true if body is a JSON array
+func isBatch(body []byte) bool {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// batchOperationRequest returns the request of one operation of the batch r
+func batchOperationRequest(r *http.Request, operation json.RawMessage) *http.Request {
+	req := r.Clone(r.Context())
+	req.Body = io.NopCloser(bytes.NewReader(operation))
+	req.ContentLength = int64(len(operation))
+	req.Header.Set("Content-Length", strconv.Itoa(len(operation)))
+	return req
+}
+
+// batchOperationResponse returns the GraphQL response recorded for an operation
+// of a batch, or a response with the error when next did not write one
+func batchOperationResponse(rec *httptest.ResponseRecorder) json.RawMessage {
+	body := bytes.TrimSpace(rec.Body.Bytes())
+	var response map[string]json.RawMessage
+	if json.Unmarshal(body, &response) == nil {
+		return body
+	}
+
+	message := string(body)
+	if message == "" {
+		message = http.StatusText(rec.Code)
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"errors": []map[string]interface{}{{
+			"message":    message,
+			"extensions": map[string]interface{}{"status": rec.Code},
+		}},
+	})
+	return data
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoOperationHandler responds to each operation with its query as data,
+// refusing requests past the limit with 429 like the rate limiter
+func echoOperationHandler(limit int) (http.Handler, *int) {
+	served := 0
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(limit-served))
+		if served > limit {
+			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+
+		var params struct {
+			Query string `json:"query"`
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &params)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": params.Query})
+	}), &served
+}
+
+func postBatch(handler http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestBatchRequestMiddleware_RunsEachOperation(t *testing.T) {
+	next, served := echoOperationHandler(10)
+	rec := postBatch(BatchRequestMiddleware(10)(next), `[{"query":"{ a }"}, {"query":"{ b }"}]`)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 2, *served)
+	assert.JSONEq(t, `[{"data":"{ a }"},{"data":"{ b }"}]`, rec.Body.String())
+	assert.Equal(t, "8", rec.Header().Get("X-RateLimit-Remaining"))
+}
+
+func TestBatchRequestMiddleware_RateLimitsOperations(t *testing.T) {
+	next, served := echoOperationHandler(1)
+	rec := postBatch(BatchRequestMiddleware(10)(next), `[{"query":"{ a }"}, {"query":"{ b }"}]`)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 2, *served)
+	assert.JSONEq(t, `[
+		{"data":"{ a }"},
+		{"errors":[{"message":"Rate limit exceeded","extensions":{"status":429}}]}
+	]`, rec.Body.String())
+}
+
+func TestBatchRequestMiddleware_RejectsLargeBatches(t *testing.T) {
+	next, served := echoOperationHandler(10)
+	rec := postBatch(BatchRequestMiddleware(2)(next), `[{"query":"{ a }"}, {"query":"{ b }"}, {"query":"{ c }"}]`)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "maximum of 2 operations")
+	assert.Zero(t, *served)
+}
+
+func TestBatchRequestMiddleware_RejectsInvalidBatches(t *testing.T) {
+	next, served := echoOperationHandler(10)
+
+	for _, body := range []string{`[]`, `[{"query":`, `["{ a }"]`} {
+		rec := postBatch(BatchRequestMiddleware(10)(next), body)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+	assert.Zero(t, *served)
+}
+
+func TestBatchRequestMiddleware_PassesSingleOperations(t *testing.T) {
+	next, served := echoOperationHandler(10)
+	rec := postBatch(BatchRequestMiddleware(10)(next), ` {"query":"{ a }"}`)
+
+	assert.Equal(t, 1, *served)
+	assert.JSONEq(t, `{"data":"{ a }"}`, rec.Body.String())
+}
+
+func TestBatchRequestMiddleware_RejectsLargeBodies(t *testing.T) {
+	handler, served := echoOperationHandler(10)
+	batch := BatchRequestMiddleware(10)(handler)
+
+	body := `[{"query": "` + strings.Repeat("a", MaxRequestBodySize) + `"}]`
+	rec := postBatch(batch, body)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Equal(t, 0, *served)
+}
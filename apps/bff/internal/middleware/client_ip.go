@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// TrustedProxies are the networks of the reverse proxies in front of the BFF.
+// The X-Forwarded-For and X-Real-IP headers are only believed on requests from
+// them, as any other client can set the headers to an address of its choice.
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies parses IP addresses and CIDR ranges
+func ParseTrustedProxies(values []string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", value)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+// Contains returns true if ip is the address of a trusted proxy
+func (p TrustedProxies) Contains(ip string) bool {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return false
+	}
+	for _, network := range p {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address of the client of a request. It is the peer
+// address unless the peer is a trusted proxy, in which case it is the last
+// X-Forwarded-For entry not added by a trusted proxy, or X-Real-IP.
+func (p TrustedProxies) ClientIP(r *http.Request) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	if !p.Contains(remote) {
+		return remote
+	}
+
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		// Each proxy appends the address it received the request from, so the
+		// entries before the last untrusted one may be forged by the client
+		entries := strings.Split(xff, ",")
+		for i := len(entries) - 1; i >= 0; i-- {
+			entry := strings.TrimSpace(entries[i])
+			if net.ParseIP(entry) == nil {
+				break
+			}
+			if !p.Contains(entry) || i == 0 {
+				return entry
+			}
+		}
+	}
+
+	if xri := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(xri) != nil {
+		return xri
+	}
+
+	return remote
+}
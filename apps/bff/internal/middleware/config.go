@@ -0,0 +1,229 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// securityConfigKey holds the security configuration last set at runtime,
+	// which replicas started afterwards load
+	securityConfigKey = "security_config"
+
+	// securityConfigChannel announces runtime changes of the security
+	// configuration to every replica
+	securityConfigChannel = "security_config_updates"
+)
+
+// SecurityConfig sets the number of security events of a client that raise an
+// alert. SQL injection and XSS attempts always alert on the first one.
+type SecurityConfig struct {
+	// FailedAuthThreshold failed authentications within 10 minutes alert
+	FailedAuthThreshold int `json:"failed_auth_threshold"`
+	// RateLimitHitThreshold rate limit hits within 10 minutes alert
+	RateLimitHitThreshold int `json:"rate_limit_hit_threshold"`
+	// SuspiciousActivityThreshold suspicious activities within 10 minutes alert
+	SuspiciousActivityThreshold int `json:"suspicious_activity_threshold"`
+
+	// CustomRules alert on further events
+	CustomRules []CustomAlertRule `json:"custom_rules"`
+}
+
+// CustomAlertRule alerts when a client causes Threshold events whose type
+// matches Pattern within WindowMinutes
+type CustomAlertRule struct {
+	// Pattern is matched against event types as with path.Match, such as
+	// "country_blocked" or "*token*"
+	Pattern       string `json:"pattern"`
+	Threshold     int    `json:"threshold"`
+	WindowMinutes int    `json:"window_minutes"`
+	// Severity is info, warning, high or critical
+	Severity string `json:"severity"`
+}
+
+// alertSeverities are the severities of custom alert rules
+var alertSeverities = map[string]bool{"info": true, "warning": true, "high": true, "critical": true}
+
+// DefaultSecurityConfig returns the alert thresholds used when none are
+// configured
+func DefaultSecurityConfig() SecurityConfig {
+	return SecurityConfig{
+		FailedAuthThreshold:         5,
+		RateLimitHitThreshold:       10,
+		SuspiciousActivityThreshold: 3,
+	}
+}
+
+// Validate checks that the thresholds are positive and the custom rules are well
+// formed
+func (c SecurityConfig) Validate() error {
+	if c.FailedAuthThreshold <= 0 || c.RateLimitHitThreshold <= 0 || c.SuspiciousActivityThreshold <= 0 {
+		return fmt.Errorf("alert thresholds must be positive")
+	}
+	for i, rule := range c.CustomRules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("custom rule %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (r CustomAlertRule) validate() error {
+	if r.Pattern == "" {
+		return fmt.Errorf("pattern is required")
+	}
+	if _, err := path.Match(r.Pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", r.Pattern, err)
+	}
+	if r.Threshold <= 0 {
+		return fmt.Errorf("threshold must be positive")
+	}
+	if r.WindowMinutes <= 0 {
+		return fmt.Errorf("window_minutes must be positive")
+	}
+	if !alertSeverities[r.Severity] {
+		return fmt.Errorf("severity must be info, warning, high or critical")
+	}
+	return nil
+}
+
+// matches reports whether events of eventType count towards the rule
+func (r CustomAlertRule) matches(eventType string) bool {
+	matched, _ := path.Match(r.Pattern, eventType)
+	return matched
+}
+
+// ParseCustomAlertRules parses a JSON array of custom alert rules, such as
+// [{"pattern": "country_blocked", "threshold": 20, "window_minutes": 5, "severity": "high"}]
+func ParseCustomAlertRules(value string) ([]CustomAlertRule, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var rules []CustomAlertRule
+	if err := json.Unmarshal([]byte(value), &rules); err != nil {
+		return nil, fmt.Errorf("invalid custom alert rules: %w", err)
+	}
+	for i, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("custom rule %d: %w", i, err)
+		}
+	}
+	return rules, nil
+}
+
+// SecurityConfig returns the alert thresholds in use
+func (sm *SecurityMonitor) SecurityConfig() SecurityConfig {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	return SecurityConfig{
+		FailedAuthThreshold:         sm.alertThresholds["failed_auth"],
+		RateLimitHitThreshold:       sm.alertThresholds["rate_limit_hit"],
+		SuspiciousActivityThreshold: sm.alertThresholds["suspicious_activity"],
+		CustomRules:                 append([]CustomAlertRule{}, sm.customRules...),
+	}
+}
+
+// applyConfig replaces the alert thresholds in use with those of cfg
+func (sm *SecurityMonitor) applyConfig(cfg SecurityConfig) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.alertThresholds = map[string]int{
+		"failed_auth":         cfg.FailedAuthThreshold,
+		"sql_injection":       1,
+		"xss_attempt":         1,
+		"rate_limit_hit":      cfg.RateLimitHitThreshold,
+		"suspicious_activity": cfg.SuspiciousActivityThreshold,
+	}
+	sm.customRules = append([]CustomAlertRule{}, cfg.CustomRules...)
+}
+
+// UpdateConfig replaces the alert thresholds of every replica with cfg. The
+// configuration is stored in Redis, for replicas started later, and announced to
+// the running ones.
+func (sm *SecurityMonitor) UpdateConfig(ctx context.Context, cfg SecurityConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if sm.redisClient == nil {
+		return fmt.Errorf("redis not available")
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to encode security config: %w", err)
+	}
+	if err := sm.redisClient.Set(ctx, securityConfigKey, data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to store security config: %w", err)
+	}
+
+	sm.applyConfig(cfg)
+	if err := sm.redisClient.Publish(ctx, securityConfigChannel, data).Err(); err != nil {
+		return fmt.Errorf("failed to announce security config: %w", err)
+	}
+
+	log.Printf("Security alert thresholds updated: %s", data)
+	return nil
+}
+
+// WatchConfig applies the security configuration stored in Redis, if any, and
+// then the changes announced by other replicas until ctx is done
+func (sm *SecurityMonitor) WatchConfig(ctx context.Context) {
+	if sm.redisClient == nil {
+		return
+	}
+
+	// Subscribe first so that no change is missed between the load and the
+	// subscription
+	pubsub := sm.redisClient.Subscribe(ctx, securityConfigChannel)
+	defer pubsub.Close()
+	if _, err := pubsub.Receive(ctx); err != nil {
+		log.Printf("Failed to subscribe to security config updates: %v", err)
+		return
+	}
+
+	data, err := sm.redisClient.Get(ctx, securityConfigKey).Bytes()
+	switch {
+	case err == redis.Nil:
+	case err != nil:
+		log.Printf("Failed to load security config: %v", err)
+	default:
+		sm.applyStoredConfig(data)
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			sm.applyStoredConfig([]byte(msg.Payload))
+		}
+	}
+}
+
+// applyStoredConfig applies a security configuration set at runtime, ignoring
+// malformed ones
+func (sm *SecurityMonitor) applyStoredConfig(data []byte) {
+	var cfg SecurityConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		log.Printf("Ignoring malformed security config: %v", err)
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Printf("Ignoring invalid security config: %v", err)
+		return
+	}
+	sm.applyConfig(cfg)
+}
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// alertKeys returns the keys of the threshold alerts raised by monitor
+func alertKeys(t *testing.T, monitor *SecurityMonitor) []string {
+	t.Helper()
+
+	keys, err := monitor.redisClient.Keys(context.Background(), "security_alert:*").Result()
+	require.NoError(t, err)
+	return keys
+}
+
+func TestNewSecurityMonitorWithConfig(t *testing.T) {
+	monitor := NewSecurityMonitorWithConfig(nil, SecurityConfig{FailedAuthThreshold: 2})
+
+	// Missing thresholds keep their default
+	assert.Equal(t, SecurityConfig{
+		FailedAuthThreshold:         2,
+		RateLimitHitThreshold:       10,
+		SuspiciousActivityThreshold: 3,
+		CustomRules:                 []CustomAlertRule{},
+	}, monitor.SecurityConfig())
+}
+
+func TestSecurityMonitor_AlertsAtConfiguredThreshold(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	monitor := NewSecurityMonitorWithConfig(client, SecurityConfig{FailedAuthThreshold: 2})
+
+	request := httptest.NewRequest(http.MethodPost, "/query", nil)
+	monitor.LogFailedAuthentication(request, "invalid token")
+	assert.Empty(t, alertKeys(t, monitor))
+
+	monitor.LogFailedAuthentication(request, "invalid token")
+	assert.Len(t, alertKeys(t, monitor), 1)
+}
+
+func TestSecurityMonitor_CustomRules(t *testing.T) {
+	monitor, server := newTestSecurityMonitor(t)
+	require.NoError(t, monitor.UpdateConfig(context.Background(), SecurityConfig{
+		FailedAuthThreshold:         5,
+		RateLimitHitThreshold:       10,
+		SuspiciousActivityThreshold: 3,
+		CustomRules: []CustomAlertRule{
+			{Pattern: "country_*", Threshold: 2, WindowMinutes: 5, Severity: "critical"},
+		},
+	}))
+
+	request := httptest.NewRequest(http.MethodPost, "/query", nil)
+	monitor.LogCountryBlocked(request, "KP")
+	assert.Empty(t, alertKeys(t, monitor))
+	assert.Equal(t, 5*time.Minute, server.TTL("security_rule_counter:country_*:192.0.2.1"))
+
+	// Other events do not count
+	monitor.LogTokenRevocation("user-1", "logout", request)
+	assert.Empty(t, alertKeys(t, monitor))
+
+	monitor.LogCountryBlocked(request, "KP")
+	keys := alertKeys(t, monitor)
+	require.Len(t, keys, 1)
+	alert, err := server.Get(keys[0])
+	require.NoError(t, err)
+	assert.Contains(t, alert, `"severity":"critical"`)
+	assert.Contains(t, alert, `"event_type":"country_blocked"`)
+
+	// The window starts over once it has passed
+	server.FastForward(5 * time.Minute)
+	server.Del(keys[0])
+	monitor.LogCountryBlocked(request, "KP")
+	assert.Empty(t, alertKeys(t, monitor))
+}
+
+func TestSecurityMonitor_UpdateConfigReachesReplicas(t *testing.T) {
+	monitor, server := newTestSecurityMonitor(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newReplica := func() *SecurityMonitor {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+		replica := NewSecurityMonitor(client)
+		go replica.WatchConfig(ctx)
+		return replica
+	}
+
+	running := newReplica()
+	// The replica is watching once it subscribed
+	require.Eventually(t, func() bool { return len(server.PubSubChannels("")) == 1 }, time.Second, 10*time.Millisecond)
+
+	updated := SecurityConfig{
+		FailedAuthThreshold:         3,
+		RateLimitHitThreshold:       50,
+		SuspiciousActivityThreshold: 5,
+		CustomRules: []CustomAlertRule{
+			{Pattern: "refresh_token_reuse", Threshold: 1, WindowMinutes: 60, Severity: "critical"},
+		},
+	}
+	require.NoError(t, monitor.UpdateConfig(ctx, updated))
+	assert.Equal(t, updated, monitor.SecurityConfig())
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(updated, running.SecurityConfig())
+	}, time.Second, 10*time.Millisecond)
+
+	// Replicas started later load the stored configuration
+	started := newReplica()
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(updated, started.SecurityConfig())
+	}, time.Second, 10*time.Millisecond)
+
+	// Invalid configurations are refused
+	assert.Error(t, monitor.UpdateConfig(ctx, SecurityConfig{FailedAuthThreshold: 3}))
+	assert.Equal(t, updated, monitor.SecurityConfig())
+}
+
+func TestSecurityConfig_Validate(t *testing.T) {
+	valid := DefaultSecurityConfig()
+	assert.NoError(t, valid.Validate())
+
+	invalid := valid
+	invalid.RateLimitHitThreshold = 0
+	assert.Error(t, invalid.Validate())
+
+	for _, rule := range []CustomAlertRule{
+		{Pattern: "", Threshold: 1, WindowMinutes: 1, Severity: "high"},
+		{Pattern: "[", Threshold: 1, WindowMinutes: 1, Severity: "high"},
+		{Pattern: "failed_auth", Threshold: 0, WindowMinutes: 1, Severity: "high"},
+		{Pattern: "failed_auth", Threshold: 1, WindowMinutes: 0, Severity: "high"},
+		{Pattern: "failed_auth", Threshold: 1, WindowMinutes: 1, Severity: "urgent"},
+	} {
+		invalid := valid
+		invalid.CustomRules = []CustomAlertRule{rule}
+		assert.Error(t, invalid.Validate(), rule.Pattern)
+	}
+}
+
+func TestParseCustomAlertRules(t *testing.T) {
+	rules, err := ParseCustomAlertRules("")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	rules, err = ParseCustomAlertRules(`[{"pattern": "country_blocked", "threshold": 20, "window_minutes": 5, "severity": "high"}]`)
+	require.NoError(t, err)
+	assert.Equal(t, []CustomAlertRule{{Pattern: "country_blocked", Threshold: 20, WindowMinutes: 5, Severity: "high"}}, rules)
+
+	_, err = ParseCustomAlertRules(`{"pattern": "country_blocked"}`)
+	assert.Error(t, err)
+	_, err = ParseCustomAlertRules(`[{"pattern": "country_blocked"}]`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	errDepthLimit = "DEPTH_LIMIT_EXCEEDED"
+
+	// DefaultMaxQueryDepth is the depth limit used when none is configured
+	DefaultMaxQueryDepth = 10
+)
+
+// DepthLimitExtension rejects operations whose selection sets are nested deeper
+// than MaxDepth before any resolver runs, so that recursive relations such as
+// Board.assets and Asset.board cannot be followed indefinitely.
+//
+// Top-level fields are at depth 0 and every selection set below a field adds one.
+// Fragments count as if their selections were written in place. Introspection
+// fields are not counted, as the introspection query nests types deeply.
+type DepthLimitExtension struct {
+	// MaxDepth is the deepest nesting allowed per operation
+	MaxDepth int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+} = &DepthLimitExtension{}
+
+// NewDepthLimitExtension creates a depth limit extension, using
+// DefaultMaxQueryDepth when maxDepth is not positive
+func NewDepthLimitExtension(maxDepth int) *DepthLimitExtension {
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxQueryDepth
+	}
+	return &DepthLimitExtension{MaxDepth: maxDepth}
+}
+
+// ExtensionName returns the name of the extension
+func (e *DepthLimitExtension) ExtensionName() string {
+	return "DepthLimit"
+}
+
+// Validate is called when the extension is added to the server
+func (e *DepthLimitExtension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation rejects the operation when it is nested deeper than MaxDepth
+func (e *DepthLimitExtension) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	rc := graphql.GetOperationContext(ctx)
+	op := rc.Doc.Operations.ForName(rc.OperationName)
+	if op == nil {
+		return next(ctx)
+	}
+
+	depth := Depth(op)
+	if depth <= e.MaxDepth {
+		return next(ctx)
+	}
+
+	logSuspiciousActivity(ctx, "query_depth_exceeded", map[string]string{
+		"depth": strconv.Itoa(depth),
+	})
+
+	err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, e.MaxDepth)
+	errcode.Set(err, errDepthLimit)
+	return graphql.OneShot(&graphql.Response{Errors: gqlerror.List{err}})
+}
+
+// Depth returns the deepest nesting of selection sets in op
+func Depth(op *ast.OperationDefinition) int {
+	return selectionSetDepth(op.SelectionSet, 0)
+}
+
+func selectionSetDepth(set ast.SelectionSet, depth int) int {
+	deepest := depth
+	for _, selection := range set {
+		var d int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name, "__") || len(selection.SelectionSet) == 0 {
+				continue
+			}
+			d = selectionSetDepth(selection.SelectionSet, depth+1)
+		case *ast.FragmentSpread:
+			if selection.Definition == nil {
+				continue
+			}
+			d = selectionSetDepth(selection.Definition.SelectionSet, depth)
+		case *ast.InlineFragment:
+			d = selectionSetDepth(selection.SelectionSet, depth)
+		}
+		if d > deepest {
+			deepest = d
+		}
+	}
+	return deepest
+}
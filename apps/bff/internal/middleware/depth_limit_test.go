@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/metrics"
+)
+
+const depthTestSchema = `
+type Query {
+	board(id: ID!): Board
+}
+
+type Board {
+	id: ID!
+	name: String!
+	assets: [Asset!]!
+}
+
+type Asset {
+	id: ID!
+	board: Board!
+}
+`
+
+// nestedBoardQuery returns a query following board and assets until depth
+func nestedBoardQuery(depth int) string {
+	var query strings.Builder
+	query.WriteString(`{ board(id: "1") { `)
+	for d := 1; d < depth; d++ {
+		if d%2 == 1 {
+			query.WriteString("assets { ")
+		} else {
+			query.WriteString("board { ")
+		}
+	}
+	query.WriteString("id")
+	query.WriteString(strings.Repeat(" }", depth+1))
+	return query.String()
+}
+
+// parseOperation parses query against the test schema
+func parseOperation(t *testing.T, query string) *ast.QueryDocument {
+	t.Helper()
+
+	schema := gqlparser.MustLoadSchema(&ast.Source{Input: depthTestSchema})
+	doc, err := gqlparser.LoadQuery(schema, query)
+	require.Nil(t, err)
+	return doc
+}
+
+// interceptOperation runs query through the extension and reports whether it
+// reached the next handler
+func interceptOperation(t *testing.T, ctx context.Context, extension *DepthLimitExtension, query string) (*graphql.Response, bool) {
+	t.Helper()
+
+	ctx = graphql.WithOperationContext(ctx, &graphql.OperationContext{Doc: parseOperation(t, query)})
+
+	executed := false
+	responses := extension.InterceptOperation(ctx, func(ctx context.Context) graphql.ResponseHandler {
+		executed = true
+		return graphql.OneShot(&graphql.Response{Data: []byte(`{}`)})
+	})
+	return responses(ctx), executed
+}
+
+func TestDepth(t *testing.T) {
+	assert.Equal(t, 0, Depth(parseOperation(t, `{ __typename }`).Operations[0]))
+	assert.Equal(t, 1, Depth(parseOperation(t, `{ board(id: "1") { id name } }`).Operations[0]))
+	assert.Equal(t, 10, Depth(parseOperation(t, nestedBoardQuery(10)).Operations[0]))
+
+	// The deepest branch counts
+	assert.Equal(t, 3, Depth(parseOperation(t, `{
+		board(id: "1") {
+			id
+			assets { board { id } }
+			name
+		}
+	}`).Operations[0]))
+
+	// Introspection is not counted
+	assert.Equal(t, 0, Depth(parseOperation(t, `{ __schema { types { fields { type { ofType { name } } } } } }`).Operations[0]))
+}
+
+func TestDepth_Fragments(t *testing.T) {
+	query := `
+		query {
+			board(id: "1") { ...BoardAssets }
+		}
+
+		fragment BoardAssets on Board {
+			assets { ...AssetBoard }
+		}
+
+		fragment AssetBoard on Asset {
+			board {
+				... on Board {
+					assets { ...AssetBoardID }
+				}
+			}
+		}
+
+		fragment AssetBoardID on Asset {
+			board { assets { board { assets { board { assets { board { id } } } } } } }
+		}
+	`
+	assert.Equal(t, 11, Depth(parseOperation(t, query).Operations[0]))
+
+	response, executed := interceptOperation(t, context.Background(), NewDepthLimitExtension(10), query)
+	assert.False(t, executed)
+	require.Len(t, response.Errors, 1)
+}
+
+func TestDepthLimitExtension(t *testing.T) {
+	extension := NewDepthLimitExtension(10)
+
+	response, executed := interceptOperation(t, context.Background(), extension, nestedBoardQuery(10))
+	assert.True(t, executed)
+	assert.Empty(t, response.Errors)
+
+	response, executed = interceptOperation(t, context.Background(), extension, nestedBoardQuery(11))
+	assert.False(t, executed)
+	assert.Nil(t, response.Data)
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "operation has depth 11, which exceeds the limit of 10", response.Errors[0].Message)
+	assert.Equal(t, errDepthLimit, response.Errors[0].Extensions["code"])
+}
+
+func TestDepthLimitExtension_DefaultsMaxDepth(t *testing.T) {
+	assert.Equal(t, DefaultMaxQueryDepth, NewDepthLimitExtension(0).MaxDepth)
+	assert.Equal(t, 4, NewDepthLimitExtension(4).MaxDepth)
+}
+
+func TestDepthLimitExtension_LogsRejectedOperations(t *testing.T) {
+	monitor := NewSecurityMonitor(nil)
+	suspicious := metrics.SecurityEvents.WithLabelValues("suspicious_activity")
+	before := testutil.ToFloat64(suspicious)
+
+	var ctx context.Context
+	monitor.SecurityMonitoringMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/query", nil))
+
+	_, executed := interceptOperation(t, ctx, NewDepthLimitExtension(10), nestedBoardQuery(10))
+	assert.True(t, executed)
+	assert.Equal(t, before, testutil.ToFloat64(suspicious))
+
+	_, executed = interceptOperation(t, ctx, NewDepthLimitExtension(10), nestedBoardQuery(12))
+	assert.False(t, executed)
+	assert.Equal(t, before+1, testutil.ToFloat64(suspicious))
+}
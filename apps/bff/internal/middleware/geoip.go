@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+// CountryResolver finds the country of IP addresses
+type CountryResolver interface {
+	// CountryCode returns the ISO 3166-1 alpha-2 code of the country of ip, or an
+	// empty string when it is not known
+	CountryCode(ip net.IP) (string, error)
+}
+
+// CityResolver is a CountryResolver that also finds the city of IP addresses
+type CityResolver interface {
+	CountryResolver
+
+	// City returns the ISO code of the country and the English name of the city
+	// of ip; either is empty when it is not known
+	City(ip net.IP) (country, city string, err error)
+}
+
+// GeoIPDatabase resolves countries with a MaxMind GeoLite2 or GeoIP2 database
+type GeoIPDatabase struct {
+	reader *geoip2.Reader
+}
+
+// OpenGeoIPDatabase opens the MaxMind database file at path
+func OpenGeoIPDatabase(path string) (*GeoIPDatabase, error) {
+	reader, err := geoip2.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database: %w", err)
+	}
+	return &GeoIPDatabase{reader: reader}, nil
+}
+
+// CountryCode returns the ISO code of the country of ip
+func (db *GeoIPDatabase) CountryCode(ip net.IP) (string, error) {
+	record, err := db.reader.Country(ip)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up country of %s: %w", ip, err)
+	}
+	return record.Country.IsoCode, nil
+}
+
+// City returns the ISO code of the country and the name of the city of ip.
+// Country databases have no cities, so only the country is found with them.
+func (db *GeoIPDatabase) City(ip net.IP) (string, string, error) {
+	record, err := db.reader.City(ip)
+	if errors.As(err, new(geoip2.InvalidMethodError)) {
+		country, err := db.CountryCode(ip)
+		return country, "", err
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to look up city of %s: %w", ip, err)
+	}
+	return record.Country.IsoCode, record.City.Names["en"], nil
+}
+
+// Close closes the database file
+func (db *GeoIPDatabase) Close() error {
+	return db.reader.Close()
+}
+
+// SetCountryBlocking refuses the requests of clients whose country, as found by
+// resolver, is one of the ISO codes in blocked
+func (sm *SecurityMonitor) SetCountryBlocking(resolver CountryResolver, blocked []string) {
+	sm.countries = resolver
+	sm.blockedCountries = make(map[string]bool, len(blocked))
+	for _, code := range blocked {
+		sm.blockedCountries[strings.ToUpper(strings.TrimSpace(code))] = true
+	}
+}
+
+// IsCountryBlocked returns whether the requests of ip are refused for its
+// country, and the ISO code of the country. Private and loopback addresses, such
+// as those of internal load balancers, are never looked up nor blocked.
+func (sm *SecurityMonitor) IsCountryBlocked(ctx context.Context, ip string) (bool, string, error) {
+	if sm.countries == nil {
+		return false, "", nil
+	}
+
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return false, "", fmt.Errorf("invalid IP address %q", ip)
+	}
+	if parsed.IsPrivate() || parsed.IsLoopback() {
+		return false, "", nil
+	}
+
+	country, err := sm.countries.CountryCode(parsed)
+	if err != nil {
+		return false, "", err
+	}
+	return sm.blockedCountries[country], country, nil
+}
+
+// LocateClient returns the address of the client of r and, when the GeoIP
+// database knows it, its country and city. Private and loopback addresses are
+// not looked up.
+func (sm *SecurityMonitor) LocateClient(r *http.Request) (ip, country, city string) {
+	ip = sm.getClientIP(r)
+	if sm.countries == nil {
+		return ip, "", ""
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil || parsed.IsPrivate() || parsed.IsLoopback() {
+		return ip, "", ""
+	}
+
+	var err error
+	if cities, ok := sm.countries.(CityResolver); ok {
+		country, city, err = cities.City(parsed)
+	} else {
+		country, err = sm.countries.CountryCode(parsed)
+	}
+	if err != nil {
+		log.Printf("Failed to locate client %s: %v", ip, err)
+	}
+	return ip, country, city
+}
+
+// LogCountryBlocked logs a request refused for the country of the client
+func (sm *SecurityMonitor) LogCountryBlocked(r *http.Request, country string) {
+	event := SecurityEvent{
+		Type:      "country_blocked",
+		Severity:  "warning",
+		Timestamp: time.Now(),
+		ClientIP:  sm.getClientIP(r),
+		UserAgent: r.UserAgent(),
+		RequestID: RequestIDFromContext(r.Context()),
+		Endpoint:  r.URL.Path,
+		Method:    r.Method,
+		Details: map[string]string{
+			"country": country,
+		},
+		RiskScore: 4,
+	}
+
+	sm.recordEvent(event)
+}
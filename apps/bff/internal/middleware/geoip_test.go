@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCountries resolves the countries of a fixed set of addresses
+type fakeCountries map[string]string
+
+func (f fakeCountries) CountryCode(ip net.IP) (string, error) {
+	return f[ip.String()], nil
+}
+
+func TestSecurityMonitor_IsCountryBlocked(t *testing.T) {
+	monitor := NewSecurityMonitor(nil)
+	ctx := context.Background()
+
+	// Countries are not checked without a resolver
+	blocked, country, err := monitor.IsCountryBlocked(ctx, "203.0.113.7")
+	require.NoError(t, err)
+	assert.False(t, blocked)
+	assert.Empty(t, country)
+
+	monitor.SetCountryBlocking(fakeCountries{
+		"203.0.113.7":  "KP",
+		"198.51.100.1": "DE",
+		"10.0.0.1":     "KP",
+	}, []string{"kp", " IR"})
+
+	blocked, country, err = monitor.IsCountryBlocked(ctx, "203.0.113.7")
+	require.NoError(t, err)
+	assert.True(t, blocked)
+	assert.Equal(t, "KP", country)
+
+	blocked, country, err = monitor.IsCountryBlocked(ctx, "198.51.100.1")
+	require.NoError(t, err)
+	assert.False(t, blocked)
+	assert.Equal(t, "DE", country)
+
+	// Internal addresses are never blocked
+	for _, ip := range []string{"10.0.0.1", "172.16.5.4", "192.168.1.1", "127.0.0.1"} {
+		blocked, country, err = monitor.IsCountryBlocked(ctx, ip)
+		require.NoError(t, err)
+		assert.False(t, blocked, ip)
+		assert.Empty(t, country, ip)
+	}
+
+	_, _, err = monitor.IsCountryBlocked(ctx, "not-an-ip")
+	assert.Error(t, err)
+}
+
+// fakeCities resolves the countries and cities of a fixed set of addresses
+type fakeCities map[string][2]string
+
+func (f fakeCities) CountryCode(ip net.IP) (string, error) {
+	return f[ip.String()][0], nil
+}
+
+func (f fakeCities) City(ip net.IP) (string, string, error) {
+	location := f[ip.String()]
+	return location[0], location[1], nil
+}
+
+func TestSecurityMonitor_LocateClient(t *testing.T) {
+	monitor := NewSecurityMonitor(nil)
+	request := httptest.NewRequest(http.MethodPost, "/query", nil)
+	request.RemoteAddr = "203.0.113.7:41000"
+
+	// Clients are not located without a GeoIP database
+	ip, country, city := monitor.LocateClient(request)
+	assert.Equal(t, "203.0.113.7", ip)
+	assert.Empty(t, country)
+	assert.Empty(t, city)
+
+	monitor.SetCountryBlocking(fakeCountries{"203.0.113.7": "DE"}, nil)
+	_, country, city = monitor.LocateClient(request)
+	assert.Equal(t, "DE", country)
+	assert.Empty(t, city)
+
+	monitor.SetCountryBlocking(fakeCities{"203.0.113.7": {"DE", "Berlin"}, "10.0.0.1": {"DE", "Berlin"}}, nil)
+	_, country, city = monitor.LocateClient(request)
+	assert.Equal(t, "DE", country)
+	assert.Equal(t, "Berlin", city)
+
+	// Internal addresses are not looked up
+	request.RemoteAddr = "10.0.0.1:41000"
+	ip, country, city = monitor.LocateClient(request)
+	assert.Equal(t, "10.0.0.1", ip)
+	assert.Empty(t, country)
+	assert.Empty(t, city)
+}
+
+func TestSecurityMonitoringMiddleware_RefusesBlockedCountries(t *testing.T) {
+	monitor, server := newTestSecurityMonitor(t)
+	monitor.SetCountryBlocking(fakeCountries{"203.0.113.7": "KP", "198.51.100.1": "DE"}, []string{"KP"})
+
+	handler := monitor.SecurityMonitoringMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := httptest.NewRequest(http.MethodPost, "/query", nil)
+	request.RemoteAddr = "203.0.113.7:41000"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, request)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, "country", rec.Header().Get("X-Blocked-Reason"))
+
+	members, err := server.ZMembers("security_timeseries:country_blocked")
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.Contains(t, members[0], `"country":"KP"`)
+
+	request = httptest.NewRequest(http.MethodPost, "/query", nil)
+	request.RemoteAddr = "198.51.100.1:41000"
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, request)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("X-Blocked-Reason"))
+}
@@ -0,0 +1,214 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/go-redis/redis/v8"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	errOperationNameRequired = "OPERATION_NAME_REQUIRED"
+	errInvalidOperationName  = "INVALID_OPERATION_NAME"
+	errOperationBlocked      = "OPERATION_BLOCKED"
+
+	// MaxOperationNameLength is the longest operation name accepted
+	MaxOperationNameLength = 100
+
+	// operationBlocklistKey is the Redis set of the blocked operation names
+	operationBlocklistKey = "graphql_operation_blocklist"
+)
+
+// operationNamePattern matches the operation names accepted
+var operationNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// ValidateOperationName checks that name is a valid operation name, of at most
+// MaxOperationNameLength letters, digits and underscores starting with a letter
+func ValidateOperationName(name string) error {
+	if len(name) > MaxOperationNameLength {
+		return fmt.Errorf("operation name must be at most %d characters", MaxOperationNameLength)
+	}
+	if !operationNamePattern.MatchString(name) {
+		return fmt.Errorf("operation name must start with a letter and contain only letters, digits and underscores")
+	}
+	return nil
+}
+
+// OperationName returns the name of the operation ctx runs, from its
+// definition or else the operationName of the request, or an empty string for
+// anonymous operations
+func OperationName(ctx context.Context) string {
+	if !graphql.HasOperationContext(ctx) {
+		return ""
+	}
+	rc := graphql.GetOperationContext(ctx)
+	if rc.Operation != nil && rc.Operation.Name != "" {
+		return rc.Operation.Name
+	}
+	return rc.OperationName
+}
+
+// OperationBlocklist holds the names of the operations refused in an
+// emergency, such as abusive queries. They are kept in Redis so that every
+// replica refuses them at once, or in memory when Redis is unavailable.
+type OperationBlocklist struct {
+	redisClient redis.UniversalClient
+
+	// mu guards names, used without Redis
+	mu    sync.RWMutex
+	names map[string]bool
+}
+
+// NewOperationBlocklist creates a blocklist kept in redisClient, or in memory
+// when it is nil
+func NewOperationBlocklist(redisClient redis.UniversalClient) *OperationBlocklist {
+	return &OperationBlocklist{redisClient: redisClient, names: map[string]bool{}}
+}
+
+// Block refuses the operations named name from now on
+func (b *OperationBlocklist) Block(ctx context.Context, name string) error {
+	if b.redisClient == nil {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.names[name] = true
+		return nil
+	}
+
+	if err := b.redisClient.SAdd(ctx, operationBlocklistKey, name).Err(); err != nil {
+		return fmt.Errorf("failed to block operation: %w", err)
+	}
+	return nil
+}
+
+// Unblock lets the operations named name run again and returns whether they
+// were blocked
+func (b *OperationBlocklist) Unblock(ctx context.Context, name string) (bool, error) {
+	if b.redisClient == nil {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		blocked := b.names[name]
+		delete(b.names, name)
+		return blocked, nil
+	}
+
+	removed, err := b.redisClient.SRem(ctx, operationBlocklistKey, name).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to unblock operation: %w", err)
+	}
+	return removed > 0, nil
+}
+
+// Blocked returns whether the operations named name are refused
+func (b *OperationBlocklist) Blocked(ctx context.Context, name string) (bool, error) {
+	if b.redisClient == nil {
+		b.mu.RLock()
+		defer b.mu.RUnlock()
+		return b.names[name], nil
+	}
+
+	blocked, err := b.redisClient.SIsMember(ctx, operationBlocklistKey, name).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check operation blocklist: %w", err)
+	}
+	return blocked, nil
+}
+
+// List returns the blocked operation names in alphabetical order
+func (b *OperationBlocklist) List(ctx context.Context) ([]string, error) {
+	names := []string{}
+	if b.redisClient == nil {
+		b.mu.RLock()
+		for name := range b.names {
+			names = append(names, name)
+		}
+		b.mu.RUnlock()
+	} else {
+		members, err := b.redisClient.SMembers(ctx, operationBlocklistKey).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list blocked operations: %w", err)
+		}
+		names = append(names, members...)
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// OperationNameEnforcementExtension requires operations to be named, so that
+// tracing, metrics and rate limits can tell them apart. Names must pass
+// ValidateOperationName, and operations whose name is blocked are refused.
+// Anonymous operations are accepted when AllowAnonymous is set, as in
+// development.
+type OperationNameEnforcementExtension struct {
+	AllowAnonymous bool
+
+	// Blocklist holds the names of the operations refused. No operation is
+	// refused by name when it is nil.
+	Blocklist *OperationBlocklist
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+} = &OperationNameEnforcementExtension{}
+
+// NewOperationNameEnforcementExtension creates the extension, refusing the
+// operations named in blocklist
+func NewOperationNameEnforcementExtension(allowAnonymous bool, blocklist *OperationBlocklist) *OperationNameEnforcementExtension {
+	return &OperationNameEnforcementExtension{AllowAnonymous: allowAnonymous, Blocklist: blocklist}
+}
+
+// ExtensionName returns the name of the extension
+func (e *OperationNameEnforcementExtension) ExtensionName() string {
+	return "OperationNameEnforcement"
+}
+
+// Validate is called when the extension is added to the server
+func (e *OperationNameEnforcementExtension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation refuses anonymous operations, operations with an invalid
+// name and blocked operations. The blocklist failing to answer lets the
+// operation run.
+func (e *OperationNameEnforcementExtension) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	name := OperationName(ctx)
+	if name == "" {
+		if e.AllowAnonymous {
+			return next(ctx)
+		}
+		logSuspiciousActivity(ctx, "anonymous_operation", nil)
+		return operationError(errOperationNameRequired, "operation name is required")
+	}
+
+	if err := ValidateOperationName(name); err != nil {
+		logSuspiciousActivity(ctx, "invalid_operation_name", nil)
+		return operationError(errInvalidOperationName, err.Error())
+	}
+
+	if e.Blocklist != nil {
+		blocked, err := e.Blocklist.Blocked(ctx, name)
+		if err != nil {
+			log.Printf("Failed to check whether operation %s is blocked: %v", name, err)
+		} else if blocked {
+			logSuspiciousActivity(ctx, "blocked_operation", nil)
+			return operationError(errOperationBlocked, fmt.Sprintf("operation %s is blocked", name))
+		}
+	}
+
+	return next(ctx)
+}
+
+// operationError responds to an operation with a single error of code
+func operationError(code, message string) graphql.ResponseHandler {
+	err := gqlerror.Errorf("%s", message)
+	errcode.Set(err, code)
+	return graphql.OneShot(&graphql.Response{Errors: gqlerror.List{err}})
+}
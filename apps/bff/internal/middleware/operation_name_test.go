@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/metrics"
+)
+
+// runNamedOperation runs query, requested as operationName, through the
+// extension and reports whether it reached the next handler
+func runNamedOperation(t *testing.T, ctx context.Context, extension *OperationNameEnforcementExtension, query, operationName string) (*graphql.Response, bool) {
+	t.Helper()
+
+	doc := parseOperation(t, query)
+	ctx = graphql.WithOperationContext(ctx, &graphql.OperationContext{
+		Doc:           doc,
+		OperationName: operationName,
+		Operation:     doc.Operations.ForName(operationName),
+	})
+
+	executed := false
+	responses := extension.InterceptOperation(ctx, func(ctx context.Context) graphql.ResponseHandler {
+		executed = true
+		return graphql.OneShot(&graphql.Response{Data: []byte(`{}`)})
+	})
+	return responses(ctx), executed
+}
+
+func TestValidateOperationName(t *testing.T) {
+	for _, name := range []string{"GetBoard", "board_assets2", "Q", strings.Repeat("a", MaxOperationNameLength)} {
+		assert.NoError(t, ValidateOperationName(name), name)
+	}
+
+	for _, name := range []string{"", "2Boards", "_private", "get-board", "Get Board", "Ünicode"} {
+		assert.EqualError(t, ValidateOperationName(name),
+			"operation name must start with a letter and contain only letters, digits and underscores", name)
+	}
+
+	assert.EqualError(t, ValidateOperationName(strings.Repeat("a", MaxOperationNameLength+1)),
+		"operation name must be at most 100 characters")
+}
+
+func TestOperationNameEnforcementExtension_RequiresName(t *testing.T) {
+	extension := NewOperationNameEnforcementExtension(false, nil)
+
+	response, executed := runNamedOperation(t, context.Background(), extension, `query GetBoard { board(id: "1") { id } }`, "")
+	assert.True(t, executed, "the name of the definition counts")
+	assert.Empty(t, response.Errors)
+
+	response, executed = runNamedOperation(t, context.Background(), extension, `{ board(id: "1") { id } }`, "")
+	assert.False(t, executed)
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "operation name is required", response.Errors[0].Message)
+	assert.Equal(t, errOperationNameRequired, response.Errors[0].Extensions["code"])
+
+	// Development accepts anonymous operations, but not invalid names
+	extension = NewOperationNameEnforcementExtension(true, nil)
+	_, executed = runNamedOperation(t, context.Background(), extension, `{ board(id: "1") { id } }`, "")
+	assert.True(t, executed)
+
+	longName := strings.Repeat("a", MaxOperationNameLength+1)
+	response, executed = runNamedOperation(t, context.Background(), extension, `query `+longName+` { board(id: "1") { id } }`, longName)
+	assert.False(t, executed)
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, errInvalidOperationName, response.Errors[0].Extensions["code"])
+}
+
+func TestOperationNameEnforcementExtension_Blocklist(t *testing.T) {
+	mr := miniredis.RunT(t)
+	blocklist := NewOperationBlocklist(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	extension := NewOperationNameEnforcementExtension(false, blocklist)
+	ctx := context.Background()
+	query := `query GetBoard { board(id: "1") { id } }`
+
+	require.NoError(t, blocklist.Block(ctx, "GetBoard"))
+	require.NoError(t, blocklist.Block(ctx, "DumpAssets"))
+	names, err := blocklist.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DumpAssets", "GetBoard"}, names)
+
+	response, executed := runNamedOperation(t, ctx, extension, query, "GetBoard")
+	assert.False(t, executed)
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "operation GetBoard is blocked", response.Errors[0].Message)
+	assert.Equal(t, errOperationBlocked, response.Errors[0].Extensions["code"])
+
+	unblocked, err := blocklist.Unblock(ctx, "GetBoard")
+	require.NoError(t, err)
+	assert.True(t, unblocked)
+	unblocked, err = blocklist.Unblock(ctx, "GetBoard")
+	require.NoError(t, err)
+	assert.False(t, unblocked)
+
+	_, executed = runNamedOperation(t, ctx, extension, query, "GetBoard")
+	assert.True(t, executed)
+
+	// Operations run while the blocklist is unavailable
+	require.NoError(t, blocklist.Block(ctx, "GetBoard"))
+	mr.Close()
+	_, executed = runNamedOperation(t, ctx, extension, query, "GetBoard")
+	assert.True(t, executed)
+}
+
+func TestOperationBlocklist_InMemory(t *testing.T) {
+	blocklist := NewOperationBlocklist(nil)
+	ctx := context.Background()
+
+	require.NoError(t, blocklist.Block(ctx, "GetBoard"))
+	blocked, err := blocklist.Blocked(ctx, "GetBoard")
+	require.NoError(t, err)
+	assert.True(t, blocked)
+
+	unblocked, err := blocklist.Unblock(ctx, "GetBoard")
+	require.NoError(t, err)
+	assert.True(t, unblocked)
+
+	names, err := blocklist.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestOperationNameEnforcementExtension_LogsOperationName(t *testing.T) {
+	monitor := NewSecurityMonitor(nil)
+	suspicious := metrics.SecurityEvents.WithLabelValues("suspicious_activity")
+	before := testutil.ToFloat64(suspicious)
+
+	var ctx context.Context
+	monitor.SecurityMonitoringMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/query", nil))
+
+	_, executed := runNamedOperation(t, ctx, NewOperationNameEnforcementExtension(false, nil), `{ board(id: "1") { id } }`, "")
+	assert.False(t, executed)
+	assert.Equal(t, before+1, testutil.ToFloat64(suspicious))
+
+	doc := parseOperation(t, `query GetBoard { board(id: "1") { id } }`)
+	operationCtx := graphql.WithOperationContext(ctx, &graphql.OperationContext{Doc: doc, Operation: doc.Operations[0]})
+	assert.Equal(t, map[string]string{"depth": "11", "operation_name": "GetBoard"},
+		withOperationName(operationCtx, map[string]string{"depth": "11"}))
+	assert.Nil(t, withOperationName(ctx, nil))
+}
@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	// PatternCategorySQLInjection patterns detect SQL injection attempts
+	PatternCategorySQLInjection = "sql_injection"
+
+	// PatternCategoryXSS patterns detect XSS attempts
+	PatternCategoryXSS = "xss"
+
+	// DefaultPatternPollInterval is how often the patterns are reloaded, so that
+	// patterns changed through another replica take effect
+	DefaultPatternPollInterval = 60 * time.Second
+)
+
+// ErrPatternNotFound is returned when deactivating a pattern that does not
+// exist or is already inactive
+var ErrPatternNotFound = errors.New("security pattern not found")
+
+// SecurityPattern is a regular expression that input validation rejects input
+// matching, stored in the security_patterns table
+type SecurityPattern struct {
+	ID string `json:"id"`
+	// Category is sql_injection or xss
+	Category string `json:"category"`
+	// Pattern is an RE2 regular expression, such as (?i)union\s+select
+	Pattern string `json:"pattern"`
+	// Severity is info, warning, high or critical
+	Severity string `json:"severity"`
+	Active   bool   `json:"active"`
+}
+
+// Validate checks that a pattern can be added
+func (p SecurityPattern) Validate() error {
+	if p.Category != PatternCategorySQLInjection && p.Category != PatternCategoryXSS {
+		return fmt.Errorf("category must be %s or %s", PatternCategorySQLInjection, PatternCategoryXSS)
+	}
+	re, err := regexp.Compile(p.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+	// Such a pattern would reject every input
+	if re.MatchString("") {
+		return fmt.Errorf("pattern must not match empty input")
+	}
+	if !alertSeverities[p.Severity] {
+		return fmt.Errorf("severity must be info, warning, high or critical")
+	}
+	return nil
+}
+
+// PatternRegistry holds the compiled active patterns of the security_patterns
+// table, which are reloaded periodically so that patterns are added and
+// deactivated without a restart
+type PatternRegistry struct {
+	db *sql.DB
+
+	mu       sync.RWMutex
+	compiled map[string][]*regexp.Regexp
+}
+
+// NewPatternRegistry creates a registry of the patterns of db, loading them
+// before returning
+func NewPatternRegistry(ctx context.Context, db *sql.DB) (*PatternRegistry, error) {
+	registry := &PatternRegistry{db: db}
+	if err := registry.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// Patterns returns the compiled active patterns of category
+func (r *PatternRegistry) Patterns(category string) []*regexp.Regexp {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.compiled[category]
+}
+
+// Reload replaces the compiled patterns with the active patterns of the table
+func (r *PatternRegistry) Reload(ctx context.Context) error {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, category, pattern, severity, active
+		FROM security_patterns
+		WHERE active
+		ORDER BY created_at, id
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to load security patterns: %w", err)
+	}
+	defer rows.Close()
+
+	var patterns []SecurityPattern
+	for rows.Next() {
+		var pattern SecurityPattern
+		if err := rows.Scan(&pattern.ID, &pattern.Category, &pattern.Pattern, &pattern.Severity, &pattern.Active); err != nil {
+			return fmt.Errorf("failed to scan security pattern: %w", err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to load security patterns: %w", err)
+	}
+
+	r.setPatterns(patterns)
+	return nil
+}
+
+// setPatterns compiles patterns and replaces the compiled patterns with them at
+// once. Patterns that do not compile, such as ones inserted by hand, are skipped.
+func (r *PatternRegistry) setPatterns(patterns []SecurityPattern) {
+	compiled := make(map[string][]*regexp.Regexp)
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern.Pattern)
+		if err != nil {
+			log.Printf("Skipping invalid security pattern %s: %v", pattern.ID, err)
+			continue
+		}
+		compiled[pattern.Category] = append(compiled[pattern.Category], re)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.compiled = compiled
+}
+
+// Watch reloads the patterns every interval until ctx is done. The patterns in
+// use are kept when reloading fails.
+func (r *PatternRegistry) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Reload(ctx); err != nil {
+				log.Printf("Failed to reload security patterns: %v", err)
+			}
+		}
+	}
+}
+
+// AddPattern stores a new active pattern and applies it at once
+func (r *PatternRegistry) AddPattern(ctx context.Context, pattern SecurityPattern) (*SecurityPattern, error) {
+	if err := pattern.Validate(); err != nil {
+		return nil, err
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO security_patterns (category, pattern, severity)
+		VALUES ($1, $2, $3)
+		RETURNING id, active
+	`, pattern.Category, pattern.Pattern, pattern.Severity).Scan(&pattern.ID, &pattern.Active)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store security pattern: %w", err)
+	}
+
+	if err := r.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Security pattern %s added: %s %s", pattern.ID, pattern.Category, pattern.Pattern)
+	return &pattern, nil
+}
+
+// DeactivatePattern stops applying the pattern id, which is kept in the table
+func (r *PatternRegistry) DeactivatePattern(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE security_patterns SET active = FALSE
+		WHERE id = $1 AND active
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate security pattern: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrPatternNotFound
+	}
+
+	if err := r.Reload(ctx); err != nil {
+		return err
+	}
+
+	log.Printf("Security pattern %s deactivated", id)
+	return nil
+}
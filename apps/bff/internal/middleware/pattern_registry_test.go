@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityPattern_Validate(t *testing.T) {
+	valid := SecurityPattern{Category: PatternCategoryXSS, Pattern: `(?i)onpointerdown\s*=`, Severity: "high"}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name    string
+		pattern SecurityPattern
+	}{
+		{"unknown category", SecurityPattern{Category: "csrf", Pattern: `token`, Severity: "high"}},
+		{"invalid regexp", SecurityPattern{Category: PatternCategoryXSS, Pattern: `(?i)<script(`, Severity: "high"}},
+		{"matches empty input", SecurityPattern{Category: PatternCategorySQLInjection, Pattern: `.*`, Severity: "high"}},
+		{"unknown severity", SecurityPattern{Category: PatternCategoryXSS, Pattern: `javascript:`, Severity: "urgent"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, tt.pattern.Validate())
+		})
+	}
+}
+
+func TestInputValidator_DefaultPatterns(t *testing.T) {
+	validator := NewInputValidator()
+
+	assert.True(t, validator.DetectSQLInjection("1 UNION SELECT password FROM users"))
+	assert.True(t, validator.DetectSQLInjection("' or 1=1"))
+	assert.False(t, validator.DetectSQLInjection("Summer banner"))
+
+	assert.True(t, validator.DetectXSS(`<img src=x onerror=alert(1)>`))
+	assert.True(t, validator.DetectXSS("javascript:alert(1)"))
+	assert.False(t, validator.DetectXSS("Summer banner"))
+}
+
+func TestInputValidator_RegistryPatterns(t *testing.T) {
+	registry := &PatternRegistry{}
+	registry.setPatterns([]SecurityPattern{
+		{ID: "1", Category: PatternCategoryXSS, Pattern: `(?i)onpointerdown\s*=`, Severity: "high", Active: true},
+		{ID: "2", Category: PatternCategorySQLInjection, Pattern: `(?i)waitfor\s+delay`, Severity: "high", Active: true},
+		// Invalid patterns are skipped
+		{ID: "3", Category: PatternCategoryXSS, Pattern: `(`, Severity: "high", Active: true},
+	})
+	validator := NewInputValidatorWithRegistry(registry)
+
+	assert.True(t, validator.DetectXSS(`<div onpointerdown="steal()">`))
+	assert.True(t, validator.DetectSQLInjection("1; WAITFOR DELAY '0:0:5'"))
+	assert.Len(t, registry.Patterns(PatternCategoryXSS), 1)
+
+	// Only the registry's patterns apply
+	assert.False(t, validator.DetectXSS("javascript:alert(1)"))
+
+	registry.setPatterns(nil)
+	assert.False(t, validator.DetectXSS(`<div onpointerdown="steal()">`))
+}
+
+func TestInputValidator_RegistryReplacedConcurrently(t *testing.T) {
+	registry := &PatternRegistry{}
+	validator := NewInputValidatorWithRegistry(registry)
+	patterns := []SecurityPattern{{Category: PatternCategoryXSS, Pattern: `javascript:`, Severity: "high"}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			registry.setPatterns(patterns)
+		}()
+		go func() {
+			defer wg.Done()
+			validator.DetectXSS("javascript:alert(1)")
+		}()
+	}
+	wg.Wait()
+
+	assert.True(t, validator.DetectXSS("javascript:alert(1)"))
+}
+
+func TestSecurityValidationMiddleware_RegistryPatterns(t *testing.T) {
+	registry := &PatternRegistry{}
+	registry.setPatterns([]SecurityPattern{
+		{Category: PatternCategorySQLInjection, Pattern: `(?i)waitfor\s+delay`, Severity: "high"},
+	})
+	handler := NewInputValidatorWithRegistry(registry).SecurityValidationMiddleware()(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	)
+
+	form := url.Values{"name": {"1; WAITFOR DELAY '0:0:5'"}}
+	request := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
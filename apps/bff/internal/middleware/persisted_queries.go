@@ -0,0 +1,271 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/go-redis/redis/v8"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+)
+
+const (
+	// maxPersistedQuerySize is the longest query persisted; longer ones are run
+	// but clients have to send them in full every time
+	maxPersistedQuerySize = 32 * 1024
+
+	// persistedQueryRetention is how long a query is kept after its last use
+	persistedQueryRetention = 30 * 24 * time.Hour
+
+	// maxPersistedQueries is the most queries kept; the least recently used
+	// beyond it are deleted
+	maxPersistedQueries = 10000
+
+	// persistedQueryLocalTTL is how long a replica serves a query from memory
+	// before reading it from the store again, which records its use there
+	persistedQueryLocalTTL = time.Hour
+
+	// persistedQueryInvalidationChannel is the Redis channel on which deleted
+	// hashes are announced to every replica
+	persistedQueryInvalidationChannel = "persisted_query_invalidations"
+)
+
+// persistedQueryHash matches the SHA-256 hashes automatic persisted queries are
+// identified by
+var persistedQueryHash = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ValidPersistedQueryHash returns whether hash is a hex-encoded SHA-256 hash
+func ValidPersistedQueryHash(hash string) bool {
+	return persistedQueryHash.MatchString(hash)
+}
+
+// PersistedQueryStore keeps the queries of automatic persisted queries by hash
+type PersistedQueryStore interface {
+	// Get returns the query of hash and whether it is known
+	Get(ctx context.Context, hash string) (string, bool, error)
+	Add(ctx context.Context, hash, query string) error
+	// Delete removes the query of hash and returns whether it was known
+	Delete(ctx context.Context, hash string) (bool, error)
+}
+
+// DBPersistedQueryStore keeps persisted queries in the persisted_queries table
+type DBPersistedQueryStore struct {
+	db *sql.DB
+}
+
+// NewDBPersistedQueryStore creates a store of the persisted_queries table of db
+func NewDBPersistedQueryStore(db *sql.DB) *DBPersistedQueryStore {
+	return &DBPersistedQueryStore{db: db}
+}
+
+// Get returns the query of hash, recording that it was used
+func (s *DBPersistedQueryStore) Get(ctx context.Context, hash string) (string, bool, error) {
+	var query string
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE persisted_queries SET last_used_at = NOW()
+		WHERE hash = $1
+		RETURNING query
+	`, hash).Scan(&query)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get persisted query: %w", err)
+	}
+	return query, true, nil
+}
+
+// Add saves query under hash
+func (s *DBPersistedQueryStore) Add(ctx context.Context, hash, query string) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO persisted_queries (hash, query, created_at, last_used_at)
+		VALUES ($1, $2, NOW(), NOW())
+		ON CONFLICT (hash) DO UPDATE SET last_used_at = NOW()
+	`, hash, query)
+	if err != nil {
+		return fmt.Errorf("failed to save persisted query: %w", err)
+	}
+	return nil
+}
+
+// Purge deletes the queries unused for persistedQueryRetention and the least
+// recently used beyond maxPersistedQueries, and returns how many it deleted
+func (s *DBPersistedQueryStore) Purge(ctx context.Context, now time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM persisted_queries
+		WHERE last_used_at < $1
+			OR hash IN (SELECT hash FROM persisted_queries ORDER BY last_used_at DESC OFFSET $2)
+	`, now.Add(-persistedQueryRetention), maxPersistedQueries)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge persisted queries: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// RunRetention purges the store immediately and then on every tick until ctx
+// is done
+func (s *DBPersistedQueryStore) RunRetention(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := s.Purge(ctx, time.Now()); err != nil {
+			log.Printf("Persisted query retention failed: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d persisted queries", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Delete removes the query of hash
+func (s *DBPersistedQueryStore) Delete(ctx context.Context, hash string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM persisted_queries WHERE hash = $1`, hash)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete persisted query: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete persisted query: %w", err)
+	}
+	return deleted > 0, nil
+}
+
+// PersistedQueryCache is the cache of the AutomaticPersistedQuery extension. It
+// keeps the hot queries in memory in front of a store shared by every replica,
+// so clients do not have to send their queries again after a restart or when
+// they reach another replica. Deletions are announced to every replica through
+// Redis; without Redis, queries are not kept in memory.
+type PersistedQueryCache struct {
+	store       PersistedQueryStore
+	redisClient redis.UniversalClient
+	local       *expirable.LRU[string, string]
+
+	// misses holds the hashes recently not found, which clients following the
+	// protocol send next with their query
+	misses *lru.Cache[string, struct{}]
+}
+
+var _ graphql.Cache = &PersistedQueryCache{}
+
+// NewPersistedQueryCache creates a cache of store keeping size queries in memory
+func NewPersistedQueryCache(store PersistedQueryStore, redisClient redis.UniversalClient, size int) *PersistedQueryCache {
+	misses, err := lru.New[string, struct{}](size)
+	if err != nil {
+		panic(fmt.Sprintf("invalid persisted query cache size %d: %v", size, err))
+	}
+
+	c := &PersistedQueryCache{store: store, redisClient: redisClient, misses: misses}
+	if redisClient != nil {
+		c.local = expirable.NewLRU[string, string](size, nil, persistedQueryLocalTTL)
+	}
+	return c
+}
+
+// Get returns the query of hash. The first miss of a hash is how clients learn
+// to send a new query; a hash missed again before its query was sent is logged
+// as suspicious activity of the request, as clients probing for queries do.
+func (c *PersistedQueryCache) Get(ctx context.Context, hash string) (interface{}, bool) {
+	if c.local != nil {
+		if query, ok := c.local.Get(hash); ok {
+			return query, true
+		}
+	}
+
+	query, ok, err := c.store.Get(ctx, hash)
+	if err != nil {
+		log.Printf("Failed to look up persisted query %s: %v", hash, err)
+		return nil, false
+	}
+	if !ok {
+		if previous, _ := c.misses.ContainsOrAdd(hash, struct{}{}); previous {
+			logSuspiciousActivity(ctx, "unknown_persisted_query", map[string]string{
+				"hash": hash,
+			})
+		}
+		return nil, false
+	}
+
+	if c.local != nil {
+		c.local.Add(hash, query)
+	}
+	return query, true
+}
+
+// Add saves the query of hash, which the extension has checked against it.
+// Queries longer than maxPersistedQuerySize are not saved.
+func (c *PersistedQueryCache) Add(ctx context.Context, hash string, value interface{}) {
+	query, ok := value.(string)
+	if !ok {
+		return
+	}
+	c.misses.Remove(hash)
+
+	if len(query) > maxPersistedQuerySize {
+		log.Printf("Not persisting query %s of %d bytes", hash, len(query))
+		return
+	}
+
+	if c.local != nil {
+		c.local.Add(hash, query)
+	}
+	if err := c.store.Add(ctx, hash, query); err != nil {
+		log.Printf("Failed to persist query %s: %v", hash, err)
+	}
+}
+
+// Delete removes the query of hash from the store and the memory of every
+// replica, and returns whether the store knew it
+func (c *PersistedQueryCache) Delete(ctx context.Context, hash string) (bool, error) {
+	deleted, err := c.store.Delete(ctx, hash)
+	if err != nil {
+		return false, err
+	}
+
+	if c.local != nil {
+		c.local.Remove(hash)
+		if err := c.redisClient.Publish(ctx, persistedQueryInvalidationChannel, hash).Err(); err != nil {
+			log.Printf("Failed to announce deletion of persisted query %s: %v", hash, err)
+		}
+	}
+	return deleted, nil
+}
+
+// WatchInvalidations removes from memory the queries other replicas delete,
+// until ctx is done
+func (c *PersistedQueryCache) WatchInvalidations(ctx context.Context) {
+	if c.local == nil {
+		return
+	}
+
+	pubsub := c.redisClient.Subscribe(ctx, persistedQueryInvalidationChannel)
+	defer pubsub.Close()
+	if _, err := pubsub.Receive(ctx); err != nil {
+		log.Printf("Failed to subscribe to persisted query deletions: %v", err)
+		return
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			c.local.Remove(msg.Payload)
+		}
+	}
+}
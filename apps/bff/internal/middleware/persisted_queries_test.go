@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/metrics"
+)
+
+// memoryPersistedQueries is a persisted query store counting its lookups
+type memoryPersistedQueries struct {
+	mu      sync.Mutex
+	queries map[string]string
+	gets    int
+}
+
+func (s *memoryPersistedQueries) Get(ctx context.Context, hash string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gets++
+	query, ok := s.queries[hash]
+	return query, ok, nil
+}
+
+func (s *memoryPersistedQueries) Add(ctx context.Context, hash, query string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.queries[hash] = query
+	return nil
+}
+
+func (s *memoryPersistedQueries) Delete(ctx context.Context, hash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.queries[hash]
+	delete(s.queries, hash)
+	return ok, nil
+}
+
+func TestPersistedQueryCache_FallsBackToStore(t *testing.T) {
+	store := &memoryPersistedQueries{queries: map[string]string{"hash-1": "{ me { id } }"}}
+	server := miniredis.RunT(t)
+	cache := NewPersistedQueryCache(store, redis.NewClient(&redis.Options{Addr: server.Addr()}), 10)
+	ctx := context.Background()
+
+	// Queries persisted by another replica are found in the store, then in memory
+	query, ok := cache.Get(ctx, "hash-1")
+	require.True(t, ok)
+	assert.Equal(t, "{ me { id } }", query)
+	_, ok = cache.Get(ctx, "hash-1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, store.gets)
+
+	cache.Add(ctx, "hash-2", "{ projects { id } }")
+	assert.Equal(t, "{ projects { id } }", store.queries["hash-2"])
+	_, ok = cache.Get(ctx, "hash-2")
+	assert.True(t, ok)
+	assert.Equal(t, 1, store.gets)
+
+	deleted, err := cache.Delete(ctx, "hash-2")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	_, ok = cache.Get(ctx, "hash-2")
+	assert.False(t, ok)
+
+	deleted, err = cache.Delete(ctx, "hash-2")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
+
+func TestPersistedQueryCache_DeletesOnEveryReplica(t *testing.T) {
+	server := miniredis.RunT(t)
+	store := &memoryPersistedQueries{queries: map[string]string{"hash-1": "{ me { id } }"}}
+	replicas := []*PersistedQueryCache{
+		NewPersistedQueryCache(store, redis.NewClient(&redis.Options{Addr: server.Addr()}), 10),
+		NewPersistedQueryCache(store, redis.NewClient(&redis.Options{Addr: server.Addr()}), 10),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go replicas[1].WatchInvalidations(ctx)
+	require.Eventually(t, func() bool {
+		return len(server.PubSubChannels("")) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	_, ok := replicas[1].Get(ctx, "hash-1")
+	require.True(t, ok)
+
+	deleted, err := replicas[0].Delete(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	assert.Eventually(t, func() bool {
+		_, ok := replicas[1].Get(ctx, "hash-1")
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestPersistedQueryCache_SkipsLongQueries(t *testing.T) {
+	store := &memoryPersistedQueries{queries: map[string]string{}}
+	cache := NewPersistedQueryCache(store, nil, 10)
+	ctx := context.Background()
+
+	cache.Add(ctx, "hash-1", strings.Repeat("a", maxPersistedQuerySize+1))
+	assert.Empty(t, store.queries)
+
+	// Without Redis, every lookup reads the store
+	cache.Add(ctx, "hash-2", "{ me { id } }")
+	_, ok := cache.Get(ctx, "hash-2")
+	assert.True(t, ok)
+	assert.Equal(t, 1, store.gets)
+}
+
+func TestPersistedQueryCache_LogsRepeatedMisses(t *testing.T) {
+	monitor := NewSecurityMonitor(nil)
+	suspicious := metrics.SecurityEvents.WithLabelValues("suspicious_activity")
+	before := testutil.ToFloat64(suspicious)
+
+	var ctx context.Context
+	monitor.SecurityMonitoringMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/query", nil))
+
+	cache := NewPersistedQueryCache(&memoryPersistedQueries{queries: map[string]string{}}, nil, 10)
+
+	// A client sending a new query misses once, then sends it in full
+	_, ok := cache.Get(ctx, strings.Repeat("a", 64))
+	assert.False(t, ok)
+	cache.Add(ctx, strings.Repeat("a", 64), "{ me { id } }")
+	assert.Equal(t, before, testutil.ToFloat64(suspicious))
+
+	// Sending an unknown hash again instead of its query is suspicious
+	_, ok = cache.Get(ctx, strings.Repeat("b", 64))
+	assert.False(t, ok)
+	assert.Equal(t, before, testutil.ToFloat64(suspicious))
+	_, ok = cache.Get(ctx, strings.Repeat("b", 64))
+	assert.False(t, ok)
+	assert.Equal(t, before+1, testutil.ToFloat64(suspicious))
+}
+
+func TestValidPersistedQueryHash(t *testing.T) {
+	assert.True(t, ValidPersistedQueryHash(strings.Repeat("0f", 32)))
+	assert.False(t, ValidPersistedQueryHash(strings.Repeat("0F", 32)))
+	assert.False(t, ValidPersistedQueryHash("0f0f"))
+	assert.False(t, ValidPersistedQueryHash(strings.Repeat("zz", 32)))
+}
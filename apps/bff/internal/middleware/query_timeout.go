@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	errQueryTimeout = "TIMEOUT"
+
+	// Kinds of operations, each with its own timeout
+	QueryTimeoutList         = "list"
+	QueryTimeoutSingle       = "single"
+	QueryTimeoutMutation     = "mutation"
+	QueryTimeoutSubscription = "subscription"
+
+	// queryTimeoutWindow and queryTimeoutThreshold: a client whose operation
+	// times out more than queryTimeoutThreshold times within queryTimeoutWindow
+	// is reported as suspicious
+	queryTimeoutWindow    = time.Minute
+	queryTimeoutThreshold = 3
+)
+
+// DefaultQueryTimeouts are the timeouts of the kinds of operations left out of
+// the configuration
+var DefaultQueryTimeouts = map[string]time.Duration{
+	QueryTimeoutList:         5 * time.Second,
+	QueryTimeoutSingle:       2 * time.Second,
+	QueryTimeoutMutation:     10 * time.Second,
+	QueryTimeoutSubscription: 30 * time.Second,
+}
+
+// QueryTimeoutMiddleware bounds the time the resolvers of an operation have to
+// run, so that slow SQL queries give their connection back to the pool. Queries
+// with a list or connection root field get the list timeout, other queries the
+// single timeout. Subscriptions get the subscription timeout to subscribe; once
+// subscribed, they last as long as the client stays subscribed.
+//
+// Operations running out of time fail with a TIMEOUT error. A client whose
+// operation times out more than 3 times in a minute is recorded by the security
+// monitor as query_timeouts suspicious activity.
+type QueryTimeoutMiddleware struct {
+	timeouts map[string]time.Duration
+
+	mu       sync.Mutex
+	timedOut map[string][]time.Time
+	now      func() time.Time
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+} = &QueryTimeoutMiddleware{}
+
+// NewQueryTimeoutMiddleware creates a query timeout middleware with timeouts by
+// kind of operation, using DefaultQueryTimeouts for missing kinds
+func NewQueryTimeoutMiddleware(timeouts map[string]time.Duration) *QueryTimeoutMiddleware {
+	m := &QueryTimeoutMiddleware{
+		timeouts: make(map[string]time.Duration, len(DefaultQueryTimeouts)),
+		timedOut: make(map[string][]time.Time),
+		now:      time.Now,
+	}
+	for kind, timeout := range DefaultQueryTimeouts {
+		m.timeouts[kind] = timeout
+	}
+	for kind, timeout := range timeouts {
+		if timeout > 0 {
+			m.timeouts[kind] = timeout
+		}
+	}
+	return m
+}
+
+// ExtensionName returns the name of the extension
+func (m *QueryTimeoutMiddleware) ExtensionName() string {
+	return "QueryTimeout"
+}
+
+// Validate is called when the extension is added to the server
+func (m *QueryTimeoutMiddleware) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// Timeout returns the timeout of op
+func (m *QueryTimeoutMiddleware) Timeout(op *ast.OperationDefinition) time.Duration {
+	switch {
+	case op.Operation == ast.Mutation:
+		return m.timeouts[QueryTimeoutMutation]
+	case op.Operation == ast.Subscription:
+		return m.timeouts[QueryTimeoutSubscription]
+	case selectsList(op.SelectionSet):
+		return m.timeouts[QueryTimeoutList]
+	default:
+		return m.timeouts[QueryTimeoutSingle]
+	}
+}
+
+// InterceptOperation runs the operation with its timeout
+func (m *QueryTimeoutMiddleware) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	rc := graphql.GetOperationContext(ctx)
+	op := rc.Operation
+	if op == nil {
+		op = rc.Doc.Operations.ForName(rc.OperationName)
+	}
+	if op == nil {
+		return next(ctx)
+	}
+	timeout := m.Timeout(op)
+
+	// Subscription resolvers subscribe within next, and their context must stay
+	// alive afterwards
+	if op.Operation == ast.Subscription {
+		subscribeCtx, cancel := context.WithCancel(ctx)
+		timer := time.AfterFunc(timeout, cancel)
+		responses := next(subscribeCtx)
+		if !timer.Stop() {
+			return graphql.OneShot(m.timeoutResponse(ctx, op, nil))
+		}
+		return responses
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	responses := next(timeoutCtx)
+	return func(ctx context.Context) *graphql.Response {
+		defer cancel()
+
+		resp := responses(ctx)
+		if errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+			return m.timeoutResponse(ctx, op, resp)
+		}
+		return resp
+	}
+}
+
+// timeoutResponse replaces the response of an operation that timed out, keeping
+// its extensions, and reports clients whose operation keeps timing out
+func (m *QueryTimeoutMiddleware) timeoutResponse(ctx context.Context, op *ast.OperationDefinition, resp *graphql.Response) *graphql.Response {
+	name := op.Name
+	if name == "" {
+		name = "anonymous"
+	}
+	if count := m.recordTimeout(monitoredClientIP(ctx), name); count > queryTimeoutThreshold {
+		logSuspiciousActivity(ctx, "query_timeouts", map[string]string{
+			"operation": name,
+			"timeouts":  strconv.Itoa(count),
+		})
+	}
+
+	err := gqlerror.Errorf("query timeout")
+	errcode.Set(err, errQueryTimeout)
+	timedOut := &graphql.Response{Errors: gqlerror.List{err}}
+	if resp != nil {
+		timedOut.Extensions = resp.Extensions
+	}
+	return timedOut
+}
+
+// recordTimeout records a timeout of operation for client and returns the number
+// of its timeouts within the window. Timeouts are not counted for unknown
+// clients.
+func (m *QueryTimeoutMiddleware) recordTimeout(client, operation string) int {
+	if client == "" {
+		return 0
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	key := client + "|" + operation
+	recent := m.timedOut[key][:0]
+	for _, at := range m.timedOut[key] {
+		if now.Sub(at) < queryTimeoutWindow {
+			recent = append(recent, at)
+		}
+	}
+	recent = append(recent, now)
+	m.timedOut[key] = recent
+
+	// Forget clients whose timeouts have all left the window
+	for k, times := range m.timedOut {
+		if now.Sub(times[len(times)-1]) >= queryTimeoutWindow {
+			delete(m.timedOut, k)
+		}
+	}
+
+	return len(recent)
+}
+
+// selectsList reports whether a root field of set returns a list or a connection
+func selectsList(set ast.SelectionSet) bool {
+	for _, selection := range set {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if selection.Definition == nil || selection.Definition.Type == nil {
+				continue
+			}
+			t := selection.Definition.Type
+			if t.Elem != nil || strings.HasSuffix(t.NamedType, "Connection") {
+				return true
+			}
+		case *ast.FragmentSpread:
+			if selection.Definition != nil && selectsList(selection.Definition.SelectionSet) {
+				return true
+			}
+		case *ast.InlineFragment:
+			if selectsList(selection.SelectionSet) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/metrics"
+)
+
+const timeoutTestSchema = `
+type Query {
+	board(id: ID!): Board
+	boards: [Board!]!
+	assets: AssetConnection!
+}
+
+type Mutation {
+	renameBoard(id: ID!, name: String!): Board!
+}
+
+type Subscription {
+	boardUpdated(id: ID!): Board!
+}
+
+type Board {
+	id: ID!
+	name: String!
+}
+
+type AssetConnection {
+	totalCount: Int!
+}
+`
+
+// timeoutOperation parses query against the timeout test schema
+func timeoutOperation(t *testing.T, query string) *ast.QueryDocument {
+	t.Helper()
+
+	schema := gqlparser.MustLoadSchema(&ast.Source{Input: timeoutTestSchema})
+	doc, err := gqlparser.LoadQuery(schema, query)
+	require.Nil(t, err)
+	return doc
+}
+
+// runWithTimeout runs query through middleware with a resolver taking delay, or
+// until its context is done
+func runWithTimeout(t *testing.T, ctx context.Context, middleware *QueryTimeoutMiddleware, query string, delay time.Duration) *graphql.Response {
+	t.Helper()
+
+	doc := timeoutOperation(t, query)
+	ctx = graphql.WithOperationContext(ctx, &graphql.OperationContext{Doc: doc, Operation: doc.Operations[0]})
+
+	// Resolvers run with the context of the operation, as in the executor
+	return middleware.InterceptOperation(ctx, func(operationCtx context.Context) graphql.ResponseHandler {
+		return func(ctx context.Context) *graphql.Response {
+			select {
+			case <-time.After(delay):
+				return &graphql.Response{Data: []byte(`{}`)}
+			case <-operationCtx.Done():
+				return &graphql.Response{Errors: gqlerror.List{gqlerror.Wrap(operationCtx.Err())}}
+			}
+		}
+	})(ctx)
+}
+
+func TestQueryTimeoutMiddleware_Timeout(t *testing.T) {
+	middleware := NewQueryTimeoutMiddleware(map[string]time.Duration{QueryTimeoutSingle: time.Second})
+	timeout := func(query string) time.Duration {
+		return middleware.Timeout(timeoutOperation(t, query).Operations[0])
+	}
+
+	assert.Equal(t, time.Second, timeout(`{ board(id: "1") { id } }`))
+	assert.Equal(t, 5*time.Second, timeout(`{ boards { id } }`))
+	assert.Equal(t, 5*time.Second, timeout(`{ board(id: "1") { id } assets { totalCount } }`))
+	assert.Equal(t, 5*time.Second, timeout(`{ ... on Query { boards { id } } }`))
+	assert.Equal(t, 10*time.Second, timeout(`mutation { renameBoard(id: "1", name: "Q3") { id } }`))
+	assert.Equal(t, 30*time.Second, timeout(`subscription { boardUpdated(id: "1") { id } }`))
+}
+
+func TestQueryTimeoutMiddleware_TimesOut(t *testing.T) {
+	middleware := NewQueryTimeoutMiddleware(map[string]time.Duration{QueryTimeoutSingle: 20 * time.Millisecond})
+
+	resp := runWithTimeout(t, context.Background(), middleware, `{ board(id: "1") { id } }`, time.Millisecond)
+	assert.Empty(t, resp.Errors)
+
+	resp = runWithTimeout(t, context.Background(), middleware, `{ board(id: "1") { id } }`, time.Second)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "query timeout", resp.Errors[0].Message)
+	assert.Equal(t, errQueryTimeout, resp.Errors[0].Extensions["code"])
+	assert.Nil(t, resp.Data)
+}
+
+func TestQueryTimeoutMiddleware_Subscriptions(t *testing.T) {
+	middleware := NewQueryTimeoutMiddleware(map[string]time.Duration{QueryTimeoutSubscription: 20 * time.Millisecond})
+	doc := timeoutOperation(t, `subscription { boardUpdated(id: "1") { id } }`)
+	ctx := graphql.WithOperationContext(context.Background(), &graphql.OperationContext{Doc: doc, Operation: doc.Operations[0]})
+
+	// A subscription stays alive after subscribing in time
+	var subscribed context.Context
+	middleware.InterceptOperation(ctx, func(ctx context.Context) graphql.ResponseHandler {
+		subscribed = ctx
+		return graphql.OneShot(&graphql.Response{Data: []byte(`{}`)})
+	})
+	time.Sleep(40 * time.Millisecond)
+	assert.NoError(t, subscribed.Err())
+
+	// Subscribing too slowly fails
+	responses := middleware.InterceptOperation(ctx, func(ctx context.Context) graphql.ResponseHandler {
+		<-ctx.Done()
+		return graphql.OneShot(&graphql.Response{Data: []byte(`{}`)})
+	})
+	resp := responses(ctx)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, errQueryTimeout, resp.Errors[0].Extensions["code"])
+}
+
+func TestQueryTimeoutMiddleware_LogsRepeatedTimeouts(t *testing.T) {
+	monitor := NewSecurityMonitor(nil)
+	suspicious := metrics.SecurityEvents.WithLabelValues("suspicious_activity")
+	before := testutil.ToFloat64(suspicious)
+
+	var ctx context.Context
+	monitor.SecurityMonitoringMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/query", nil))
+
+	middleware := NewQueryTimeoutMiddleware(map[string]time.Duration{QueryTimeoutSingle: time.Millisecond})
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	middleware.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		runWithTimeout(t, ctx, middleware, `query Slow { board(id: "1") { id } }`, time.Second)
+	}
+	assert.Equal(t, before, testutil.ToFloat64(suspicious))
+
+	// Timeouts of other operations count separately
+	runWithTimeout(t, ctx, middleware, `query Other { board(id: "1") { id } }`, time.Second)
+	assert.Equal(t, before, testutil.ToFloat64(suspicious))
+
+	runWithTimeout(t, ctx, middleware, `query Slow { board(id: "1") { id } }`, time.Second)
+	assert.Equal(t, before+1, testutil.ToFloat64(suspicious))
+
+	// Timeouts older than a minute no longer count
+	now = now.Add(time.Minute)
+	runWithTimeout(t, ctx, middleware, `query Slow { board(id: "1") { id } }`, time.Second)
+	assert.Equal(t, before+1, testutil.ToFloat64(suspicious))
+}
@@ -8,37 +8,72 @@ import (
 	"time"
 
 	"github.com/go-redis/redis/v8"
-	"github.com/go-redis/redis_rate/v10"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/config"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/metrics"
 )
 
 type RateLimiter struct {
-	limiter *redis_rate.Limiter
+	client redis.Scripter
+
+	policy          config.RateLimitPolicy
+	securityMonitor *SecurityMonitor
 }
 
 type RateLimitConfig struct {
 	RequestsPerMinute int
 	BurstSize         int
 	WindowSize        time.Duration
+
+	// WarningThresholdPercent is the percentage of the limit left below which
+	// responses carry the X-RateLimit-Warning headers. Zero disables them.
+	WarningThresholdPercent float64
 }
 
+// ExportRateLimitPerHour is the number of board exports a user may download
+// per hour
+const ExportRateLimitPerHour = 10
+
+// rateLimitCriticalPercent is the percentage of the limit left below which
+// clients are reported to the security monitor
+const rateLimitCriticalPercent = 10
+
 func NewRateLimiter(redisClient *redis.Client) *RateLimiter {
 	return &RateLimiter{
-		limiter: redis_rate.NewLimiter(redisClient),
+		client: redisClient,
+	}
+}
+
+// NewRateLimiterWithCluster creates a rate limiter keeping its counters in a Redis
+// Cluster. Each client key is a single cluster key, so limits hold across nodes.
+func NewRateLimiterWithCluster(clusterClient *redis.ClusterClient) *RateLimiter {
+	return &RateLimiter{
+		client: clusterClient,
 	}
 }
 
+// SetPolicy sets the warning thresholds of the GraphQL, auth and API rate limits
+func (rl *RateLimiter) SetPolicy(policy config.RateLimitPolicy) {
+	rl.policy = policy
+}
+
+// SetSecurityMonitor reports the clients close to their rate limit to monitor
+func (rl *RateLimiter) SetSecurityMonitor(monitor *SecurityMonitor) {
+	rl.securityMonitor = monitor
+}
+
 // RateLimitMiddleware creates a rate limiting middleware
 func (rl *RateLimiter) RateLimitMiddleware(config RateLimitConfig) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ctx := r.Context()
-			
+
 			// Get client identifier (IP + User ID if available)
 			key := rl.getClientKey(r)
-			
+
 			// Apply rate limit
-			limit := redis_rate.PerMinute(config.RequestsPerMinute)
-			res, err := rl.limiter.Allow(ctx, key, limit)
+			res, err := rl.allow(ctx, key, config.RequestsPerMinute, time.Minute)
 			if err != nil {
 				http.Error(w, "Rate limiting error", http.StatusInternalServerError)
 				return
@@ -47,62 +82,156 @@ func (rl *RateLimiter) RateLimitMiddleware(config RateLimitConfig) func(http.Han
 			// Set rate limit headers
 			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(config.RequestsPerMinute))
 			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
-			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(res.ResetAfter.Unix(), 10))
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(int64(res.ResetAfter.Seconds()), 10))
 
 			// Check if rate limit exceeded
-			if res.Allowed == 0 {
-				w.Header().Set("Retry-After", strconv.FormatInt(int64(res.RetryAfter.Seconds()), 10))
+			if !res.Allowed {
+				metrics.RateLimitRejections.WithLabelValues(metrics.Route(r)).Inc()
+				w.Header().Set("Retry-After", strconv.FormatInt(int64(res.ResetAfter.Seconds()), 10))
 				http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
 				return
 			}
 
+			rl.warnApproachingLimit(w, r, config, res.Remaining)
+
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// warnApproachingLimit sets the warning headers of a request allowed with
+// remaining requests left when they are below the warning threshold of limit,
+// and reports the client to the security monitor below rateLimitCriticalPercent
+func (rl *RateLimiter) warnApproachingLimit(w http.ResponseWriter, r *http.Request, limit RateLimitConfig, remaining int) {
+	if limit.WarningThresholdPercent <= 0 || limit.RequestsPerMinute <= 0 {
+		return
+	}
+
+	left := float64(remaining) / float64(limit.RequestsPerMinute) * 100
+	if left >= limit.WarningThresholdPercent {
+		return
+	}
+
+	w.Header().Set("X-RateLimit-Warning", "true")
+	w.Header().Set("X-RateLimit-Warning-Threshold", strconv.FormatFloat(100-limit.WarningThresholdPercent, 'f', -1, 64))
+
+	if left < rateLimitCriticalPercent && rl.securityMonitor != nil {
+		rl.securityMonitor.LogSuspiciousActivity(r, "rate_limit_approaching", map[string]string{
+			"approaching_limit": "true",
+			"remaining":         strconv.Itoa(remaining),
+			"limit":             strconv.Itoa(limit.RequestsPerMinute),
+		})
+	}
+}
+
 // AuthRateLimitMiddleware applies stricter rate limiting for authentication endpoints
 func (rl *RateLimiter) AuthRateLimitMiddleware() func(http.Handler) http.Handler {
-	config := RateLimitConfig{
-		RequestsPerMinute: 5,  // Very strict for auth
-		BurstSize:         3,
-		WindowSize:        time.Minute,
-	}
-	return rl.RateLimitMiddleware(config)
+	return rl.RateLimitMiddleware(AuthRateLimit(rl.policy))
 }
 
 // APIRateLimitMiddleware applies standard rate limiting for API endpoints
 func (rl *RateLimiter) APIRateLimitMiddleware() func(http.Handler) http.Handler {
-	config := RateLimitConfig{
-		RequestsPerMinute: 100,
-		BurstSize:         20,
-		WindowSize:        time.Minute,
-	}
-	return rl.RateLimitMiddleware(config)
+	return rl.RateLimitMiddleware(APIRateLimit(rl.policy))
 }
 
 // GraphQLRateLimitMiddleware applies rate limiting for GraphQL queries
 func (rl *RateLimiter) GraphQLRateLimitMiddleware() func(http.Handler) http.Handler {
-	config := RateLimitConfig{
-		RequestsPerMinute: 60,
-		BurstSize:         10,
-		WindowSize:        time.Minute,
+	return rl.RateLimitMiddleware(GraphQLRateLimit(rl.policy))
+}
+
+// AuthRateLimit returns the rate limit of authentication endpoints
+func AuthRateLimit(policy config.RateLimitPolicy) RateLimitConfig {
+	return RateLimitConfig{
+		RequestsPerMinute:       5, // Very strict for auth
+		BurstSize:               3,
+		WindowSize:              time.Minute,
+		WarningThresholdPercent: policy.AuthWarningThresholdPercent,
+	}
+}
+
+// APIRateLimit returns the rate limit of API endpoints
+func APIRateLimit(policy config.RateLimitPolicy) RateLimitConfig {
+	return RateLimitConfig{
+		RequestsPerMinute:       100,
+		BurstSize:               20,
+		WindowSize:              time.Minute,
+		WarningThresholdPercent: policy.APIWarningThresholdPercent,
+	}
+}
+
+// defaultGraphQLRequestsPerMinute is the rate limit of GraphQL queries when the
+// policy does not set one
+const defaultGraphQLRequestsPerMinute = 60
+
+// GraphQLRateLimit returns the rate limit of GraphQL queries
+func GraphQLRateLimit(policy config.RateLimitPolicy) RateLimitConfig {
+	requestsPerMinute := policy.GraphQLRequestsPerMinute
+	if requestsPerMinute <= 0 {
+		requestsPerMinute = defaultGraphQLRequestsPerMinute
+	}
+
+	return RateLimitConfig{
+		RequestsPerMinute:       requestsPerMinute,
+		BurstSize:               10,
+		WindowSize:              time.Minute,
+		WarningThresholdPercent: policy.GraphQLWarningThresholdPercent,
+	}
+}
+
+// RateLimitPolicyDescription describes the rate limit of a group of endpoints
+type RateLimitPolicyDescription struct {
+	Endpoints               string  `json:"endpoints"`
+	RequestsPerMinute       int     `json:"requests_per_minute"`
+	BurstSize               int     `json:"burst_size"`
+	WarningThresholdPercent float64 `json:"warning_threshold_percent"`
+}
+
+// rateLimitHeaders documents the headers of rate limited responses
+var rateLimitHeaders = map[string]string{
+	"X-RateLimit-Limit":             "Requests allowed per minute",
+	"X-RateLimit-Remaining":         "Requests left in the current minute",
+	"X-RateLimit-Reset":             "Seconds until the limit resets",
+	"X-RateLimit-Warning":           "Set to true once fewer requests than the warning threshold are left",
+	"X-RateLimit-Warning-Threshold": "Percentage of the limit used after which the warning is set",
+	"Retry-After":                   "Seconds to wait before retrying a request rejected with 429",
+}
+
+// DescribeRateLimits returns a description of the rate limits of policy and of
+// the headers of rate limited responses
+func DescribeRateLimits(policy config.RateLimitPolicy) map[string]interface{} {
+	describe := func(endpoints string, limit RateLimitConfig) RateLimitPolicyDescription {
+		return RateLimitPolicyDescription{
+			Endpoints:               endpoints,
+			RequestsPerMinute:       limit.RequestsPerMinute,
+			BurstSize:               limit.BurstSize,
+			WarningThresholdPercent: limit.WarningThresholdPercent,
+		}
+	}
+
+	return map[string]interface{}{
+		"policies": map[string]RateLimitPolicyDescription{
+			"graphql": describe("/query", GraphQLRateLimit(policy)),
+			"auth":    describe("authentication endpoints", AuthRateLimit(policy)),
+			"api":     describe("REST API endpoints", APIRateLimit(policy)),
+		},
+		"headers": rateLimitHeaders,
 	}
-	return rl.RateLimitMiddleware(config)
 }
 
-// getClientKey generates a unique key for rate limiting based on IP and user
+// getClientKey generates a unique key for rate limiting based on IP and user.
+// Members of an organization share the quota of the organization.
 func (rl *RateLimiter) getClientKey(r *http.Request) string {
 	// Get client IP
 	ip := rl.getClientIP(r)
-	
-	// Try to get user ID from context
-	if user := r.Context().Value("user"); user != nil {
-		if userID, ok := user.(string); ok {
-			return fmt.Sprintf("rate_limit:user:%s", userID)
+
+	// Try to get the organization or user ID from context
+	if user, ok := r.Context().Value("user").(*auth.User); ok {
+		if user.OrgID != "" {
+			return fmt.Sprintf("rate_limit:org:%s", user.OrgID)
 		}
+		return fmt.Sprintf("rate_limit:user:%s", user.ID)
 	}
-	
+
 	// Fall back to IP-based rate limiting
 	return fmt.Sprintf("rate_limit:ip:%s", ip)
 }
@@ -127,22 +256,78 @@ func (rl *RateLimiter) getClientIP(r *http.Request) string {
 			return xff
 		}
 	}
-	
+
 	// Check X-Real-IP header
 	if xri := r.Header.Get("X-Real-IP"); xri != "" {
 		return xri
 	}
-	
+
 	// Fall back to RemoteAddr
 	return r.RemoteAddr
 }
 
 // IsRateLimited checks if a client is currently rate limited
 func (rl *RateLimiter) IsRateLimited(ctx context.Context, key string, requestsPerMinute int) (bool, error) {
-	limit := redis_rate.PerMinute(requestsPerMinute)
-	res, err := rl.limiter.Allow(ctx, key, limit)
+	res, err := rl.allow(ctx, key, requestsPerMinute, time.Minute)
 	if err != nil {
 		return false, err
 	}
-	return res.Allowed == 0, nil
-} 
\ No newline at end of file
+	return !res.Allowed, nil
+}
+
+// AllowExport counts a board export by userID against ExportRateLimitPerHour,
+// under a key of its own so that exports do not use up the API limit. It
+// returns whether the export is allowed and, when it is not, how long until
+// the next one is.
+func (rl *RateLimiter) AllowExport(ctx context.Context, userID string) (bool, time.Duration, error) {
+	res, err := rl.allow(ctx, fmt.Sprintf("rate_limit:export:user:%s", userID), ExportRateLimitPerHour, time.Hour)
+	if err != nil {
+		return false, 0, err
+	}
+	if res.Allowed {
+		return true, 0, nil
+	}
+	return false, res.ResetAfter, nil
+}
+
+// rateLimitScript counts a request in the window of KEYS[1], which lasts
+// ARGV[1] milliseconds from the first request, and returns the number of
+// requests in the window and the milliseconds left in it
+var rateLimitScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {count, redis.call("PTTL", KEYS[1])}
+`)
+
+// rateLimitResult is the outcome of counting a request against a rate limit
+type rateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	ResetAfter time.Duration
+}
+
+// allow counts a request of key against a limit of requests per period
+func (rl *RateLimiter) allow(ctx context.Context, key string, requests int, period time.Duration) (*rateLimitResult, error) {
+	values, err := rateLimitScript.Run(ctx, rl.client, []string{key}, period.Milliseconds()).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count request of %s: %w", key, err)
+	}
+
+	count, ttl := int(values[0]), time.Duration(values[1])*time.Millisecond
+	if ttl < 0 {
+		ttl = period
+	}
+
+	remaining := requests - count
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return &rateLimitResult{
+		Allowed:    count <= requests,
+		Remaining:  remaining,
+		ResetAfter: ttl,
+	}, nil
+}
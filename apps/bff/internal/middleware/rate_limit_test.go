@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/config"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/metrics"
+)
+
+func TestRateLimiter_WarnApproachingLimit(t *testing.T) {
+	limit := RateLimitConfig{RequestsPerMinute: 60, WarningThresholdPercent: 20}
+
+	tests := []struct {
+		name      string
+		limit     RateLimitConfig
+		remaining int
+		warning   bool
+	}{
+		{name: "plenty left", limit: limit, remaining: 30},
+		{name: "at the threshold", limit: limit, remaining: 12},
+		{name: "below the threshold", limit: limit, remaining: 11, warning: true},
+		{name: "nearly exhausted", limit: limit, remaining: 1, warning: true},
+		{name: "warnings disabled", limit: RateLimitConfig{RequestsPerMinute: 60}, remaining: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			(&RateLimiter{}).warnApproachingLimit(recorder, httptest.NewRequest(http.MethodPost, "/query", nil), tt.limit, tt.remaining)
+
+			if tt.warning {
+				assert.Equal(t, "true", recorder.Header().Get("X-RateLimit-Warning"))
+				assert.Equal(t, "80", recorder.Header().Get("X-RateLimit-Warning-Threshold"))
+			} else {
+				assert.Empty(t, recorder.Header().Get("X-RateLimit-Warning"))
+				assert.Empty(t, recorder.Header().Get("X-RateLimit-Warning-Threshold"))
+			}
+		})
+	}
+}
+
+func TestRateLimiter_ReportsClientsNearTheLimit(t *testing.T) {
+	limiter := &RateLimiter{}
+	limiter.SetSecurityMonitor(NewSecurityMonitor(nil))
+	limit := RateLimitConfig{RequestsPerMinute: 60, WarningThresholdPercent: 20}
+
+	suspicious := metrics.SecurityEvents.WithLabelValues("suspicious_activity")
+	before := testutil.ToFloat64(suspicious)
+
+	// 10% and more left only warns the client
+	limiter.warnApproachingLimit(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/query", nil), limit, 6)
+	assert.Equal(t, before, testutil.ToFloat64(suspicious))
+
+	limiter.warnApproachingLimit(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/query", nil), limit, 5)
+	assert.Equal(t, before+1, testutil.ToFloat64(suspicious))
+}
+
+func TestRateLimiter_ClientKey(t *testing.T) {
+	limiter := &RateLimiter{}
+	request := func(user *auth.User) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/query", nil)
+		r.RemoteAddr = "203.0.113.7"
+		if user != nil {
+			r = r.WithContext(context.WithValue(r.Context(), "user", user))
+		}
+		return r
+	}
+
+	// Members of an organization share its quota
+	assert.Equal(t, "rate_limit:org:org-1", limiter.getClientKey(request(&auth.User{ID: "user-1", OrgID: "org-1"})))
+	assert.Equal(t, "rate_limit:org:org-1", limiter.getClientKey(request(&auth.User{ID: "user-2", OrgID: "org-1"})))
+
+	assert.Equal(t, "rate_limit:user:user-3", limiter.getClientKey(request(&auth.User{ID: "user-3"})))
+	assert.Equal(t, "rate_limit:ip:203.0.113.7", limiter.getClientKey(request(nil)))
+}
+
+func TestDescribeRateLimits(t *testing.T) {
+	description := DescribeRateLimits(config.RateLimitPolicy{
+		GraphQLWarningThresholdPercent: 20,
+		AuthWarningThresholdPercent:    40,
+		APIWarningThresholdPercent:     10,
+	})
+
+	policies := description["policies"].(map[string]RateLimitPolicyDescription)
+	assert.Equal(t, RateLimitPolicyDescription{
+		Endpoints:               "/query",
+		RequestsPerMinute:       60,
+		BurstSize:               10,
+		WarningThresholdPercent: 20,
+	}, policies["graphql"])
+	assert.Equal(t, 40.0, policies["auth"].WarningThresholdPercent)
+	assert.Equal(t, 10.0, policies["api"].WarningThresholdPercent)
+
+	headers := description["headers"].(map[string]string)
+	assert.Contains(t, headers, "X-RateLimit-Warning")
+	assert.Contains(t, headers, "X-RateLimit-Warning-Threshold")
+}
+
+func TestGraphQLRateLimit_RequestsPerMinute(t *testing.T) {
+	assert.Equal(t, 120, GraphQLRateLimit(config.RateLimitPolicy{GraphQLRequestsPerMinute: 120}).RequestsPerMinute)
+
+	// Unset limits fall back to the default
+	assert.Equal(t, 60, GraphQLRateLimit(config.RateLimitPolicy{}).RequestsPerMinute)
+}
+
+func TestRateLimiter_RateLimitMiddleware(t *testing.T) {
+	mr := miniredis.RunT(t)
+	limiter := NewRateLimiter(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	handler := limiter.RateLimitMiddleware(RateLimitConfig{RequestsPerMinute: 2})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/query", nil)
+		request.RemoteAddr = "203.0.113.7:1234"
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	first := serve()
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "1", first.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "60", first.Header().Get("X-RateLimit-Reset"))
+
+	assert.Equal(t, http.StatusOK, serve().Code)
+
+	rejected := serve()
+	assert.Equal(t, http.StatusTooManyRequests, rejected.Code)
+	assert.Equal(t, "0", rejected.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, rejected.Header().Get("Retry-After"))
+
+	// The window resets after a minute
+	mr.FastForward(time.Minute)
+	assert.Equal(t, http.StatusOK, serve().Code)
+}
+
+func TestRateLimiter_AllowExport(t *testing.T) {
+	mr := miniredis.RunT(t)
+	limiter := NewRateLimiter(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+
+	for i := 0; i < ExportRateLimitPerHour; i++ {
+		allowed, _, err := limiter.AllowExport(context.Background(), "user-1")
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	allowed, retryAfter, err := limiter.AllowExport(context.Background(), "user-1")
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, time.Hour, retryAfter)
+
+	// Exports are counted per user
+	allowed, _, err = limiter.AllowExport(context.Background(), "user-2")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"time"
+)
+
+// RequestIDHeader is the response header carrying the ID of a request
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the context key of the ID of a request
+type requestIDKey struct{}
+
+type requestInfo struct {
+	id         string
+	receivedAt time.Time
+}
+
+// WithRequestID returns ctx carrying the ID of the request and the time it was
+// received
+func WithRequestID(ctx context.Context, id string, receivedAt time.Time) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestInfo{id: id, receivedAt: receivedAt})
+}
+
+// RequestIDFromContext returns the ID of the request ctx belongs to, or an empty
+// string when it has none
+func RequestIDFromContext(ctx context.Context) string {
+	info, _ := ctx.Value(requestIDKey{}).(requestInfo)
+	return info.id
+}
+
+// RequestReceivedAt returns the time the request ctx belongs to was received
+func RequestReceivedAt(ctx context.Context) (time.Time, bool) {
+	info, ok := ctx.Value(requestIDKey{}).(requestInfo)
+	return info.receivedAt, ok
+}
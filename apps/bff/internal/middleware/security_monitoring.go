@@ -5,11 +5,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis/v8"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/metrics"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/redisclient"
 )
 
 type SecurityEvent struct {
@@ -19,6 +24,7 @@ type SecurityEvent struct {
 	ClientIP    string            `json:"client_ip"`
 	UserAgent   string            `json:"user_agent"`
 	UserID      string            `json:"user_id,omitempty"`
+	RequestID   string            `json:"request_id"`
 	Endpoint    string            `json:"endpoint"`
 	Method      string            `json:"method"`
 	Details     map[string]string `json:"details"`
@@ -26,20 +32,90 @@ type SecurityEvent struct {
 }
 
 type SecurityMonitor struct {
-	redisClient *redis.Client
+	redisClient redisclient.RedisClientInterface
+
+	// mu guards the alert thresholds, which change at runtime
+	mu              sync.RWMutex
 	alertThresholds map[string]int
+	customRules     []CustomAlertRule
+
+	// autoBlockEvents are the event types whose IPs are blocked for
+	// autoBlockDuration once over their threshold
+	autoBlockEvents   map[string]bool
+	autoBlockDuration time.Duration
+
+	// trustedProxies may report the client IP of the requests they forward
+	trustedProxies TrustedProxies
+
+	// countries finds the country of clients, whose requests are refused when it
+	// is in blockedCountries. Countries are not checked when it is nil.
+	countries        CountryResolver
+	blockedCountries map[string]bool
 }
 
-func NewSecurityMonitor(redisClient *redis.Client) *SecurityMonitor {
-	return &SecurityMonitor{
+// BlockedIP is an IP address whose requests are refused until its block expires
+type BlockedIP struct {
+	IP         string `json:"ip"`
+	TTLSeconds int64  `json:"ttl_seconds"`
+}
+
+// blockedIPKeyPrefix prefixes the Redis keys of blocked IPs
+const blockedIPKeyPrefix = "blocked_ip:"
+
+func NewSecurityMonitor(redisClient redisclient.RedisClientInterface) *SecurityMonitor {
+	return NewSecurityMonitorWithConfig(redisClient, DefaultSecurityConfig())
+}
+
+// NewSecurityMonitorWithConfig creates a security monitor alerting at the
+// thresholds of cfg. Thresholds that are not positive keep their default.
+func NewSecurityMonitorWithConfig(redisClient redisclient.RedisClientInterface, cfg SecurityConfig) *SecurityMonitor {
+	defaults := DefaultSecurityConfig()
+	if cfg.FailedAuthThreshold <= 0 {
+		cfg.FailedAuthThreshold = defaults.FailedAuthThreshold
+	}
+	if cfg.RateLimitHitThreshold <= 0 {
+		cfg.RateLimitHitThreshold = defaults.RateLimitHitThreshold
+	}
+	if cfg.SuspiciousActivityThreshold <= 0 {
+		cfg.SuspiciousActivityThreshold = defaults.SuspiciousActivityThreshold
+	}
+
+	sm := &SecurityMonitor{
 		redisClient: redisClient,
-		alertThresholds: map[string]int{
-			"failed_auth":        5,  // 5 failed attempts in 5 minutes
-			"sql_injection":      1,  // Any SQL injection attempt
-			"xss_attempt":        1,  // Any XSS attempt
-			"rate_limit_hit":     10, // 10 rate limit hits in 5 minutes
-			"suspicious_activity": 3,  // 3 suspicious activities in 10 minutes
+		autoBlockEvents: map[string]bool{
+			"sql_injection": true,
+			"xss_attempt":   true,
 		},
+		autoBlockDuration: time.Hour,
+	}
+	sm.applyConfig(cfg)
+
+	// Export a counter for every alerting event type from the start, so that
+	// alerts on their rate work before the first event
+	for eventType := range sm.alertThresholds {
+		metrics.SecurityEvents.WithLabelValues(eventType)
+	}
+
+	return sm
+}
+
+// SetTrustedProxies sets the reverse proxies whose forwarding headers are
+// believed. Without any, the client IP is the peer address of the request.
+func (sm *SecurityMonitor) SetTrustedProxies(proxies TrustedProxies) {
+	sm.trustedProxies = proxies
+}
+
+// BlockMiddleware refuses the requests of blocked IPs. It wraps the whole server,
+// so that blocked clients do not reach any other middleware.
+func (sm *SecurityMonitor) BlockMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if sm.IsBlocked(r.Context(), sm.getClientIP(r)) {
+				http.Error(w, "Too many requests", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
 	}
 }
 
@@ -48,12 +124,27 @@ func (sm *SecurityMonitor) SecurityMonitoringMiddleware() func(http.Handler) htt
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
+
+			// Refuse clients from countries the deployment must not serve
+			blocked, country, err := sm.IsCountryBlocked(r.Context(), sm.getClientIP(r))
+			if err != nil {
+				log.Printf("Failed to check the country of the client: %v", err)
+			}
+			if blocked {
+				sm.LogCountryBlocked(r, country)
+				w.Header().Set("X-Blocked-Reason", "country")
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
 			
 			// Create a response writer wrapper to capture status code
 			wrapper := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 			
+			// Let handlers further down report suspicious activity
+			ctx := context.WithValue(r.Context(), monitoredRequestKey{}, monitoredRequest{monitor: sm, request: r})
+			
 			// Process request
-			next.ServeHTTP(wrapper, r)
+			next.ServeHTTP(wrapper, r.WithContext(ctx))
 			
 			// Log security events based on response
 			sm.logSecurityEvent(r, wrapper, time.Since(start))
@@ -69,6 +160,7 @@ func (sm *SecurityMonitor) LogFailedAuthentication(r *http.Request, reason strin
 		Timestamp: time.Now(),
 		ClientIP:  sm.getClientIP(r),
 		UserAgent: r.UserAgent(),
+		RequestID: RequestIDFromContext(r.Context()),
 		Endpoint:  r.URL.Path,
 		Method:    r.Method,
 		Details: map[string]string{
@@ -89,6 +181,7 @@ func (sm *SecurityMonitor) LogSQLInjectionAttempt(r *http.Request, payload strin
 		Timestamp: time.Now(),
 		ClientIP:  sm.getClientIP(r),
 		UserAgent: r.UserAgent(),
+		RequestID: RequestIDFromContext(r.Context()),
 		Endpoint:  r.URL.Path,
 		Method:    r.Method,
 		Details: map[string]string{
@@ -99,6 +192,7 @@ func (sm *SecurityMonitor) LogSQLInjectionAttempt(r *http.Request, payload strin
 	
 	sm.recordEvent(event)
 	sm.triggerImmediateAlert(event)
+	sm.checkAlertThresholds("sql_injection", event.ClientIP)
 }
 
 // LogXSSAttempt logs XSS attempts
@@ -109,6 +203,7 @@ func (sm *SecurityMonitor) LogXSSAttempt(r *http.Request, payload string) {
 		Timestamp: time.Now(),
 		ClientIP:  sm.getClientIP(r),
 		UserAgent: r.UserAgent(),
+		RequestID: RequestIDFromContext(r.Context()),
 		Endpoint:  r.URL.Path,
 		Method:    r.Method,
 		Details: map[string]string{
@@ -119,6 +214,7 @@ func (sm *SecurityMonitor) LogXSSAttempt(r *http.Request, payload string) {
 	
 	sm.recordEvent(event)
 	sm.triggerImmediateAlert(event)
+	sm.checkAlertThresholds("xss_attempt", event.ClientIP)
 }
 
 // LogRateLimitHit logs rate limit violations
@@ -129,6 +225,7 @@ func (sm *SecurityMonitor) LogRateLimitHit(r *http.Request, limit string) {
 		Timestamp: time.Now(),
 		ClientIP:  sm.getClientIP(r),
 		UserAgent: r.UserAgent(),
+		RequestID: RequestIDFromContext(r.Context()),
 		Endpoint:  r.URL.Path,
 		Method:    r.Method,
 		Details: map[string]string{
@@ -149,6 +246,7 @@ func (sm *SecurityMonitor) LogSuspiciousActivity(r *http.Request, activity strin
 		Timestamp: time.Now(),
 		ClientIP:  sm.getClientIP(r),
 		UserAgent: r.UserAgent(),
+		RequestID: RequestIDFromContext(r.Context()),
 		Endpoint:  r.URL.Path,
 		Method:    r.Method,
 		Details:   details,
@@ -164,6 +262,51 @@ func (sm *SecurityMonitor) LogSuspiciousActivity(r *http.Request, activity strin
 	sm.checkAlertThresholds("suspicious_activity", event.ClientIP)
 }
 
+// monitoredRequestKey is the context key of the request being monitored
+type monitoredRequestKey struct{}
+
+type monitoredRequest struct {
+	monitor *SecurityMonitor
+	request *http.Request
+}
+
+// monitoredClientIP returns the client IP of the request ctx belongs to, or an
+// empty string if it is not monitored
+func monitoredClientIP(ctx context.Context) string {
+	if monitored, ok := ctx.Value(monitoredRequestKey{}).(monitoredRequest); ok {
+		return monitored.monitor.getClientIP(monitored.request)
+	}
+	return ""
+}
+
+// logSuspiciousActivity logs a suspicious activity of the request ctx belongs to,
+// if it is monitored, along with the name of the GraphQL operation it runs
+func logSuspiciousActivity(ctx context.Context, activity string, details map[string]string) {
+	if monitored, ok := ctx.Value(monitoredRequestKey{}).(monitoredRequest); ok {
+		monitored.monitor.LogSuspiciousActivity(monitored.request, activity, withOperationName(ctx, details))
+	}
+}
+
+// withOperationName returns a copy of details with the name of the GraphQL
+// operation ctx runs, cut to MaxOperationNameLength, so that the SIEM can filter
+// events by operation. details is returned as is outside of operations.
+func withOperationName(ctx context.Context, details map[string]string) map[string]string {
+	name := OperationName(ctx)
+	if name == "" {
+		return details
+	}
+	if len(name) > MaxOperationNameLength {
+		name = name[:MaxOperationNameLength]
+	}
+
+	withName := make(map[string]string, len(details)+1)
+	for key, value := range details {
+		withName[key] = value
+	}
+	withName["operation_name"] = name
+	return withName
+}
+
 // LogTokenRevocation logs token revocation events
 func (sm *SecurityMonitor) LogTokenRevocation(userID, reason string, r *http.Request) {
 	event := SecurityEvent{
@@ -172,6 +315,7 @@ func (sm *SecurityMonitor) LogTokenRevocation(userID, reason string, r *http.Req
 		Timestamp: time.Now(),
 		ClientIP:  sm.getClientIP(r),
 		UserAgent: r.UserAgent(),
+		RequestID: RequestIDFromContext(r.Context()),
 		UserID:    userID,
 		Endpoint:  r.URL.Path,
 		Method:    r.Method,
@@ -184,8 +328,80 @@ func (sm *SecurityMonitor) LogTokenRevocation(userID, reason string, r *http.Req
 	sm.recordEvent(event)
 }
 
+// Actions of an impersonation session
+const (
+	ImpersonationStart = "start"
+	ImpersonationEnd   = "end"
+)
+
+// LogImpersonation logs the start or end of a session in which the super admin
+// adminID acts as userID. They are token_revocation events, as the admin trades
+// their tokens for the user's and back.
+func (sm *SecurityMonitor) LogImpersonation(adminID, userID, action string, r *http.Request) {
+	sm.logImpersonation(adminID, userID, action, r, nil)
+}
+
+// logImpersonation logs an impersonation event with details added to its own
+func (sm *SecurityMonitor) logImpersonation(adminID, userID, action string, r *http.Request, details map[string]string) {
+	event := SecurityEvent{
+		Type:      "token_revocation",
+		Severity:  "warning",
+		Timestamp: time.Now(),
+		ClientIP:  sm.getClientIP(r),
+		UserAgent: r.UserAgent(),
+		RequestID: RequestIDFromContext(r.Context()),
+		UserID:    userID,
+		Endpoint:  r.URL.Path,
+		Method:    r.Method,
+		Details: map[string]string{
+			"reason":          "impersonation_" + action,
+			"impersonated_by": adminID,
+		},
+		RiskScore: 3,
+	}
+	for key, value := range details {
+		event.Details[key] = value
+	}
+
+	sm.recordEvent(event)
+}
+
+// LogImpersonation logs the start or end of an impersonation session of the
+// request ctx belongs to, if it is monitored, along with the name of the
+// GraphQL operation it runs
+func LogImpersonation(ctx context.Context, adminID, userID, action string) {
+	if monitored, ok := ctx.Value(monitoredRequestKey{}).(monitoredRequest); ok {
+		monitored.monitor.logImpersonation(adminID, userID, action, monitored.request, withOperationName(ctx, nil))
+	}
+}
+
+// LogRefreshTokenReuse logs a refresh token presented after its token family had
+// moved on to a newer one, which means the token was stolen or replayed
+func (sm *SecurityMonitor) LogRefreshTokenReuse(userID, familyID string, r *http.Request) {
+	event := SecurityEvent{
+		Type:      "refresh_token_reuse",
+		Severity:  "critical",
+		Timestamp: time.Now(),
+		ClientIP:  sm.getClientIP(r),
+		UserAgent: r.UserAgent(),
+		RequestID: RequestIDFromContext(r.Context()),
+		UserID:    userID,
+		Endpoint:  r.URL.Path,
+		Method:    r.Method,
+		Details: map[string]string{
+			"family_id": familyID,
+		},
+		RiskScore: 9,
+	}
+	
+	sm.recordEvent(event)
+	sm.triggerImmediateAlert(event)
+}
+
 // recordEvent stores the security event
 func (sm *SecurityMonitor) recordEvent(event SecurityEvent) {
+	metrics.SecurityEvents.WithLabelValues(event.Type).Inc()
+
 	if sm.redisClient == nil {
 		// Fallback to standard logging
 		eventJSON, _ := json.Marshal(event)
@@ -221,6 +437,37 @@ func (sm *SecurityMonitor) recordEvent(event SecurityEvent) {
 		Member: eventJSON,
 	})
 	sm.redisClient.Expire(ctx, timeSeriesKey, 7*24*time.Hour) // Keep for 7 days
+
+	sm.checkCustomRules(ctx, event)
+}
+
+// checkCustomRules counts event towards the custom alert rules matching its type
+// and alerts on those the client went over
+func (sm *SecurityMonitor) checkCustomRules(ctx context.Context, event SecurityEvent) {
+	sm.mu.RLock()
+	rules := sm.customRules
+	sm.mu.RUnlock()
+
+	for _, rule := range rules {
+		if !rule.matches(event.Type) {
+			continue
+		}
+
+		// The window starts with the first matching event
+		counterKey := fmt.Sprintf("security_rule_counter:%s:%s", rule.Pattern, event.ClientIP)
+		count, err := sm.redisClient.Incr(ctx, counterKey).Result()
+		if err != nil {
+			log.Printf("Failed to count security event for rule %s: %v", rule.Pattern, err)
+			continue
+		}
+		if count == 1 {
+			sm.redisClient.Expire(ctx, counterKey, time.Duration(rule.WindowMinutes)*time.Minute)
+		}
+
+		if int(count) >= rule.Threshold {
+			sm.triggerAlert(event.Type, event.ClientIP, int(count), rule.Threshold, rule.Severity)
+		}
+	}
 }
 
 // checkAlertThresholds checks if alert thresholds are exceeded
@@ -229,7 +476,9 @@ func (sm *SecurityMonitor) checkAlertThresholds(eventType, clientIP string) {
 		return
 	}
 	
+	sm.mu.RLock()
 	threshold, exists := sm.alertThresholds[eventType]
+	sm.mu.RUnlock()
 	if !exists {
 		return
 	}
@@ -243,12 +492,88 @@ func (sm *SecurityMonitor) checkAlertThresholds(eventType, clientIP string) {
 	}
 	
 	if count >= threshold {
-		sm.triggerAlert(eventType, clientIP, count, threshold)
+		sm.triggerAlert(eventType, clientIP, count, threshold, "high")
+
+		if sm.autoBlockEvents[eventType] {
+			if err := sm.BlockIP(ctx, clientIP, sm.autoBlockDuration); err != nil {
+				log.Printf("Failed to block IP %s after %s: %v", clientIP, eventType, err)
+			}
+		}
+	}
+}
+
+// BlockIP refuses the requests of ip for duration
+func (sm *SecurityMonitor) BlockIP(ctx context.Context, ip string, duration time.Duration) error {
+	if sm.redisClient == nil {
+		return fmt.Errorf("redis not available")
+	}
+	if net.ParseIP(ip) == nil {
+		return fmt.Errorf("invalid IP address: %q", ip)
+	}
+	if duration <= 0 {
+		return fmt.Errorf("block duration must be positive")
+	}
+
+	if err := sm.redisClient.Set(ctx, blockedIPKeyPrefix+ip, time.Now().Unix(), duration).Err(); err != nil {
+		return fmt.Errorf("failed to block IP: %w", err)
+	}
+
+	log.Printf("SECURITY_BLOCK: blocked %s for %s", ip, duration)
+	return nil
+}
+
+// IsBlocked reports whether the requests of ip are refused. IPs are let through
+// when Redis cannot be read.
+func (sm *SecurityMonitor) IsBlocked(ctx context.Context, ip string) bool {
+	if sm.redisClient == nil {
+		return false
+	}
+
+	err := sm.redisClient.Get(ctx, blockedIPKeyPrefix+ip).Err()
+	if err != nil && err != redis.Nil {
+		log.Printf("Failed to check blocked IP %s: %v", ip, err)
+	}
+	return err == nil
+}
+
+// BlockedIPs returns the blocked IPs with the time left until they are unblocked
+func (sm *SecurityMonitor) BlockedIPs(ctx context.Context) ([]BlockedIP, error) {
+	if sm.redisClient == nil {
+		return nil, fmt.Errorf("redis not available")
+	}
+
+	blocked := []BlockedIP{}
+	var cursor uint64
+	for {
+		keys, next, err := sm.redisClient.Scan(ctx, cursor, blockedIPKeyPrefix+"*", 100).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan blocked IPs: %w", err)
+		}
+
+		for _, key := range keys {
+			ttl, err := sm.redisClient.TTL(ctx, key).Result()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read blocked IP TTL: %w", err)
+			}
+			// The block expired since the scan
+			if ttl < 0 {
+				continue
+			}
+			blocked = append(blocked, BlockedIP{
+				IP:         strings.TrimPrefix(key, blockedIPKeyPrefix),
+				TTLSeconds: int64(ttl.Seconds()),
+			})
+		}
+
+		if next == 0 {
+			return blocked, nil
+		}
+		cursor = next
 	}
 }
 
 // triggerAlert sends an alert for threshold violations
-func (sm *SecurityMonitor) triggerAlert(eventType, clientIP string, count, threshold int) {
+func (sm *SecurityMonitor) triggerAlert(eventType, clientIP string, count, threshold int, severity string) {
 	alert := map[string]interface{}{
 		"type":        "security_threshold_exceeded",
 		"event_type":  eventType,
@@ -256,7 +581,7 @@ func (sm *SecurityMonitor) triggerAlert(eventType, clientIP string, count, thres
 		"count":       count,
 		"threshold":   threshold,
 		"timestamp":   time.Now(),
-		"severity":    "high",
+		"severity":    severity,
 	}
 	
 	alertJSON, _ := json.Marshal(alert)
@@ -325,27 +650,10 @@ func (sm *SecurityMonitor) logSecurityEvent(r *http.Request, w *responseWriter,
 	}
 }
 
-// getClientIP extracts the real client IP
+// getClientIP returns the client IP, from the forwarding headers only when the
+// request comes from a trusted proxy
 func (sm *SecurityMonitor) getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		// Take the first IP in the chain
-		if idx := strings.Index(xff, ","); idx > 0 {
-			return strings.TrimSpace(xff[:idx])
-		}
-		return strings.TrimSpace(xff)
-	}
-	
-	// Check X-Real-IP header
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return xri
-	}
-	
-	// Fall back to RemoteAddr
-	if idx := strings.LastIndex(r.RemoteAddr, ":"); idx > 0 {
-		return r.RemoteAddr[:idx]
-	}
-	return r.RemoteAddr
+	return sm.trustedProxies.ClientIP(r)
 }
 
 // GetSecurityMetrics returns security metrics for monitoring
@@ -358,7 +666,13 @@ func (sm *SecurityMonitor) GetSecurityMetrics() map[string]interface{} {
 	metrics := make(map[string]interface{})
 	
 	// Get event counts by type
+	sm.mu.RLock()
+	eventTypes := make([]string, 0, len(sm.alertThresholds))
 	for eventType := range sm.alertThresholds {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sm.mu.RUnlock()
+	for _, eventType := range eventTypes {
 		pattern := fmt.Sprintf("security_counter:%s:*", eventType)
 		keys, err := sm.redisClient.Keys(ctx, pattern).Result()
 		if err == nil {
@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSecurityMonitor(t *testing.T) (*SecurityMonitor, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewSecurityMonitor(client), server
+}
+
+func TestSecurityMonitor_BlockIP(t *testing.T) {
+	monitor, server := newTestSecurityMonitor(t)
+	ctx := context.Background()
+
+	require.NoError(t, monitor.BlockIP(ctx, "1.2.3.4", time.Hour))
+	assert.True(t, monitor.IsBlocked(ctx, "1.2.3.4"))
+	assert.False(t, monitor.IsBlocked(ctx, "5.6.7.8"))
+	assert.Equal(t, time.Hour, server.TTL("blocked_ip:1.2.3.4"))
+
+	server.FastForward(time.Hour)
+	assert.False(t, monitor.IsBlocked(ctx, "1.2.3.4"))
+
+	assert.Error(t, monitor.BlockIP(ctx, "not-an-ip", time.Hour))
+	assert.Error(t, monitor.BlockIP(ctx, "1.2.3.4", 0))
+}
+
+func TestSecurityMonitor_BlockedIPs(t *testing.T) {
+	monitor, server := newTestSecurityMonitor(t)
+	ctx := context.Background()
+
+	require.NoError(t, monitor.BlockIP(ctx, "1.2.3.4", time.Hour))
+	require.NoError(t, monitor.BlockIP(ctx, "2001:db8::1", 10*time.Minute))
+	server.Set("security_counter:xss_attempt:1.2.3.4", "1")
+
+	blocked, err := monitor.BlockedIPs(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []BlockedIP{
+		{IP: "1.2.3.4", TTLSeconds: 3600},
+		{IP: "2001:db8::1", TTLSeconds: 600},
+	}, blocked)
+}
+
+func TestSecurityMonitor_WithoutRedis(t *testing.T) {
+	monitor := NewSecurityMonitor(nil)
+	ctx := context.Background()
+
+	assert.Error(t, monitor.BlockIP(ctx, "1.2.3.4", time.Hour))
+	assert.False(t, monitor.IsBlocked(ctx, "1.2.3.4"))
+	_, err := monitor.BlockedIPs(ctx)
+	assert.Error(t, err)
+}
+
+func TestBlockMiddleware_RefusesBlockedIPs(t *testing.T) {
+	monitor, _ := newTestSecurityMonitor(t)
+	require.NoError(t, monitor.BlockIP(context.Background(), "1.2.3.4", time.Hour))
+
+	var served int
+	handler := monitor.BlockMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+	}))
+
+	blocked := httptest.NewRequest(http.MethodPost, "/query", nil)
+	blocked.RemoteAddr = "1.2.3.4:41000"
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, blocked)
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Zero(t, served)
+
+	// Forwarding headers of untrusted clients do not get them through
+	blocked.Header.Set("X-Forwarded-For", "5.6.7.8")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, blocked)
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Zero(t, served)
+
+	allowed := httptest.NewRequest(http.MethodPost, "/query", nil)
+	allowed.RemoteAddr = "5.6.7.8:41000"
+	allowed.Header.Set("X-Forwarded-For", "1.2.3.4")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, allowed)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, 1, served)
+}
+
+func TestTrustedProxies_ClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", " 192.0.2.7", ""})
+	require.NoError(t, err)
+
+	request := func(remoteAddr, xff, xri string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remoteAddr
+		if xff != "" {
+			r.Header.Set("X-Forwarded-For", xff)
+		}
+		if xri != "" {
+			r.Header.Set("X-Real-IP", xri)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		request  *http.Request
+		expected string
+	}{
+		{"untrusted peer", request("203.0.113.5:1234", "1.2.3.4", "1.2.3.4"), "203.0.113.5"},
+		{"trusted peer", request("10.0.0.1:1234", "203.0.113.9", ""), "203.0.113.9"},
+		{"forged first entry", request("10.0.0.1:1234", "1.2.3.4, 203.0.113.9, 10.0.0.2", ""), "203.0.113.9"},
+		{"only proxies", request("192.0.2.7:1234", "10.0.0.3, 10.0.0.2", ""), "10.0.0.3"},
+		{"invalid entry", request("10.0.0.1:1234", "not-an-ip", "203.0.113.9"), "203.0.113.9"},
+		{"no headers", request("10.0.0.1:1234", "", ""), "10.0.0.1"},
+		{"ipv6 peer", request("[2001:db8::1]:1234", "1.2.3.4", ""), "2001:db8::1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, proxies.ClientIP(test.request))
+		})
+	}
+
+	_, err = ParseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+	_, err = ParseTrustedProxies([]string{"proxy.internal"})
+	assert.Error(t, err)
+}
+
+func TestSecurityMonitor_BlocksInjectionAttempts(t *testing.T) {
+	monitor, server := newTestSecurityMonitor(t)
+	ctx := context.Background()
+
+	request := httptest.NewRequest(http.MethodPost, "/query", nil)
+	request.RemoteAddr = "1.2.3.4:41000"
+	monitor.LogSQLInjectionAttempt(request, "' OR 1=1 --")
+	assert.True(t, monitor.IsBlocked(ctx, "1.2.3.4"))
+	assert.Equal(t, time.Hour, server.TTL("blocked_ip:1.2.3.4"))
+
+	request = httptest.NewRequest(http.MethodPost, "/query", nil)
+	request.RemoteAddr = "5.6.7.8:41000"
+	monitor.LogXSSAttempt(request, "<script>alert(1)</script>")
+	assert.True(t, monitor.IsBlocked(ctx, "5.6.7.8"))
+
+	// Failed logins alert but do not block
+	request = httptest.NewRequest(http.MethodPost, "/query", nil)
+	request.RemoteAddr = "9.9.9.9:41000"
+	for i := 0; i < 5; i++ {
+		monitor.LogFailedAuthentication(request, "invalid password")
+	}
+	assert.False(t, monitor.IsBlocked(ctx, "9.9.9.9"))
+}
+
+func TestSecurityMonitor_RecordsRequestID(t *testing.T) {
+	monitor, server := newTestSecurityMonitor(t)
+
+	request := httptest.NewRequest(http.MethodPost, "/query", nil)
+	request = request.WithContext(WithRequestID(request.Context(), "request-1", time.Now()))
+	monitor.LogFailedAuthentication(request, "invalid password")
+
+	members, err := server.ZMembers("security_timeseries:failed_auth")
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+
+	var event SecurityEvent
+	require.NoError(t, json.Unmarshal([]byte(members[0]), &event))
+	assert.Equal(t, "request-1", event.RequestID)
+}
+
+func TestLogImpersonation(t *testing.T) {
+	monitor, server := newTestSecurityMonitor(t)
+
+	var ctx context.Context
+	monitor.SecurityMonitoringMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/query", nil))
+
+	LogImpersonation(ctx, "admin-1", "user-1", ImpersonationStart)
+	LogImpersonation(ctx, "admin-1", "user-1", ImpersonationEnd)
+	// Requests that are not monitored are not logged
+	LogImpersonation(context.Background(), "admin-1", "user-1", ImpersonationStart)
+
+	members, err := server.ZMembers("security_timeseries:token_revocation")
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+
+	reasons := []string{}
+	for _, member := range members {
+		var event SecurityEvent
+		require.NoError(t, json.Unmarshal([]byte(member), &event))
+		assert.Equal(t, "user-1", event.UserID)
+		assert.Equal(t, "admin-1", event.Details["impersonated_by"])
+		reasons = append(reasons, event.Details["reason"])
+	}
+	assert.ElementsMatch(t, []string{"impersonation_start", "impersonation_end"}, reasons)
+}
@@ -0,0 +1,278 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// siemChannels are the Redis channels the security monitor publishes alerts on
+var siemChannels = []string{"security_alerts", "critical_security_alerts"}
+
+const (
+	// siemFailedEventsKey is the Redis list of the alerts the SIEM could not be
+	// sent, oldest first, kept for manual review
+	siemFailedEventsKey = "siem_failed_events"
+
+	// siemBacklogLimit is the most alerts kept in the backlog; older ones are dropped
+	siemBacklogLimit = 10000
+
+	// siemAttempts is the number of times an alert is posted before it is backlogged
+	siemAttempts = 3
+
+	// siemClaimTTL is how long the claim of an alert by a replica is kept. Every
+	// replica receives each alert, and only the one claiming it forwards it.
+	siemClaimTTL = time.Hour
+)
+
+// SIEMEvent is the payload posted to the SIEM webhook: a security alert and the
+// channel it was published on
+type SIEMEvent struct {
+	Channel string          `json:"channel"`
+	Alert   json.RawMessage `json:"alert"`
+}
+
+// SIEMBacklog is the number of alerts the SIEM could not be sent and the oldest
+// of them, and whether this replica is flushing them
+type SIEMBacklog struct {
+	Count    int64       `json:"count"`
+	Oldest   []SIEMEvent `json:"oldest"`
+	Flushing bool        `json:"flushing"`
+}
+
+// SIEMForwarder forwards the security alerts published on Redis to the webhook
+// of an external SIEM, authenticated with an API key. Alerts the SIEM does not
+// accept are kept in a Redis list until they are flushed.
+type SIEMForwarder struct {
+	redisClient redis.UniversalClient
+	httpClient  *http.Client
+	webhookURL  string
+	apiKey      string
+
+	// retryDelay is the wait between attempts to post an alert
+	retryDelay time.Duration
+
+	mu       sync.Mutex
+	flushing bool
+}
+
+// NewSIEMForwarder creates a forwarder posting the alerts to webhookURL
+func NewSIEMForwarder(redisClient redis.UniversalClient, webhookURL, apiKey string) *SIEMForwarder {
+	return &SIEMForwarder{
+		redisClient: redisClient,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		webhookURL:  webhookURL,
+		apiKey:      apiKey,
+		retryDelay:  2 * time.Second,
+	}
+}
+
+// Run forwards the alerts published on the security alert channels until ctx is done
+func (f *SIEMForwarder) Run(ctx context.Context) {
+	pubsub := f.redisClient.Subscribe(ctx, siemChannels...)
+	defer pubsub.Close()
+	if _, err := pubsub.Receive(ctx); err != nil {
+		log.Printf("Failed to subscribe to security alerts: %v", err)
+		return
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			if !f.claim(ctx, msg.Channel, msg.Payload) {
+				continue
+			}
+			f.forward(ctx, SIEMEvent{Channel: msg.Channel, Alert: json.RawMessage(msg.Payload)})
+		}
+	}
+}
+
+// claim reports whether no replica has received the alert payload on channel
+// before. Alerts carry the time they were raised, so their contents identify
+// them. When Redis cannot be reached, the alert is forwarded rather than dropped.
+func (f *SIEMForwarder) claim(ctx context.Context, channel, payload string) bool {
+	sum := sha256.Sum256([]byte(channel + "\n" + payload))
+	claimed, err := f.redisClient.SetNX(ctx, "siem_forwarded:"+hex.EncodeToString(sum[:]), 1, siemClaimTTL).Result()
+	if err != nil {
+		log.Printf("Failed to deduplicate security alert: %v", err)
+		return true
+	}
+	return claimed
+}
+
+// forward posts event to the SIEM, backlogging it if every attempt fails
+func (f *SIEMForwarder) forward(ctx context.Context, event SIEMEvent) {
+	err := f.deliver(ctx, event)
+	if err == nil {
+		return
+	}
+	log.Printf("Failed to forward security alert to SIEM: %v", err)
+
+	// Alerts interrupted by a shutdown are backlogged too
+	if err := f.backlog(context.Background(), event); err != nil {
+		log.Printf("Failed to backlog security alert: %v", err)
+	}
+}
+
+// deliver posts event to the SIEM, up to siemAttempts times
+func (f *SIEMForwarder) deliver(ctx context.Context, event SIEMEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal security alert: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = f.post(ctx, payload)
+		if err == nil || attempt == siemAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(f.retryDelay):
+		}
+	}
+}
+
+// post sends payload to the SIEM webhook once
+func (f *SIEMForwarder) post(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create SIEM request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", f.apiKey)
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("SIEM request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("SIEM responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// backlog appends event to the failed events list, dropping the oldest events
+// beyond siemBacklogLimit
+func (f *SIEMForwarder) backlog(ctx context.Context, event SIEMEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal security alert: %w", err)
+	}
+
+	_, err = f.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, siemFailedEventsKey, payload)
+		pipe.LTrim(ctx, siemFailedEventsKey, -siemBacklogLimit, -1)
+		return nil
+	})
+	return err
+}
+
+// Backlog returns the number of backlogged alerts and the oldest 10 of them
+func (f *SIEMForwarder) Backlog(ctx context.Context) (*SIEMBacklog, error) {
+	count, err := f.redisClient.LLen(ctx, siemFailedEventsKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count backlogged security alerts: %w", err)
+	}
+
+	entries, err := f.redisClient.LRange(ctx, siemFailedEventsKey, 0, 9).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backlogged security alerts: %w", err)
+	}
+
+	backlog := &SIEMBacklog{Count: count, Oldest: make([]SIEMEvent, 0, len(entries)), Flushing: f.Flushing()}
+	for _, entry := range entries {
+		var event SIEMEvent
+		if err := json.Unmarshal([]byte(entry), &event); err != nil {
+			log.Printf("Skipping malformed backlogged security alert: %v", err)
+			continue
+		}
+		backlog.Oldest = append(backlog.Oldest, event)
+	}
+	return backlog, nil
+}
+
+// StartFlush flushes the backlog in the background, unless this replica is
+// flushing it already, and reports whether it started
+func (f *SIEMForwarder) StartFlush() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.flushing {
+		return false
+	}
+	f.flushing = true
+
+	go func() {
+		defer func() {
+			f.mu.Lock()
+			f.flushing = false
+			f.mu.Unlock()
+		}()
+
+		flushed, err := f.Flush(context.Background())
+		if err != nil {
+			log.Printf("Failed to flush SIEM backlog after %d alerts: %v", flushed, err)
+			return
+		}
+		log.Printf("Flushed %d backlogged security alerts to SIEM", flushed)
+	}()
+	return true
+}
+
+// Flushing reports whether a flush started by StartFlush is running
+func (f *SIEMForwarder) Flushing() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.flushing
+}
+
+// Flush posts the backlogged alerts to the SIEM, oldest first, and returns how
+// many were sent. It stops at the first alert the SIEM still does not accept,
+// which stays at the head of the backlog.
+func (f *SIEMForwarder) Flush(ctx context.Context) (int, error) {
+	flushed := 0
+	for {
+		entry, err := f.redisClient.LPop(ctx, siemFailedEventsKey).Result()
+		if err == redis.Nil {
+			return flushed, nil
+		}
+		if err != nil {
+			return flushed, fmt.Errorf("failed to read backlogged security alert: %w", err)
+		}
+
+		var event SIEMEvent
+		if err := json.Unmarshal([]byte(entry), &event); err != nil {
+			log.Printf("Dropping malformed backlogged security alert: %v", err)
+			continue
+		}
+
+		if err := f.deliver(ctx, event); err != nil {
+			if pushErr := f.redisClient.LPush(context.Background(), siemFailedEventsKey, entry).Err(); pushErr != nil {
+				log.Printf("Failed to return security alert to the backlog: %v", pushErr)
+			}
+			return flushed, err
+		}
+		flushed++
+	}
+}
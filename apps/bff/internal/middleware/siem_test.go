@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSIEM is a SIEM webhook recording the alerts it accepts
+type fakeSIEM struct {
+	mu       sync.Mutex
+	failing  bool
+	attempts int
+	received []SIEMEvent
+	apiKeys  []string
+}
+
+func (s *fakeSIEM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts++
+	s.apiKeys = append(s.apiKeys, r.Header.Get("X-API-Key"))
+	if s.failing {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+
+	var event SIEMEvent
+	json.NewDecoder(r.Body).Decode(&event)
+	s.received = append(s.received, event)
+}
+
+func (s *fakeSIEM) setFailing(failing bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failing = failing
+}
+
+func (s *fakeSIEM) events() []SIEMEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SIEMEvent(nil), s.received...)
+}
+
+func newTestSIEMForwarder(t *testing.T) (*SIEMForwarder, *fakeSIEM, *redis.Client) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	siem := &fakeSIEM{}
+	webhook := httptest.NewServer(siem)
+	t.Cleanup(webhook.Close)
+
+	forwarder := NewSIEMForwarder(client, webhook.URL, "siem-key")
+	forwarder.retryDelay = time.Millisecond
+	return forwarder, siem, client
+}
+
+func TestSIEMForwarder_ForwardsPublishedAlerts(t *testing.T) {
+	forwarder, siem, client := newTestSIEMForwarder(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go forwarder.Run(ctx)
+
+	// Publish until the forwarder has subscribed
+	require.Eventually(t, func() bool {
+		client.Publish(ctx, "critical_security_alerts", `{"type":"immediate_security_alert"}`)
+		return len(siem.events()) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	event := siem.events()[0]
+	assert.Equal(t, "critical_security_alerts", event.Channel)
+	assert.JSONEq(t, `{"type":"immediate_security_alert"}`, string(event.Alert))
+	assert.Equal(t, "siem-key", siem.apiKeys[0])
+}
+
+func TestSIEMForwarder_ForwardsEachAlertOnce(t *testing.T) {
+	forwarder, siem, client := newTestSIEMForwarder(t)
+	other := NewSIEMForwarder(client, forwarder.webhookURL, "siem-key")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go forwarder.Run(ctx)
+	go other.Run(ctx)
+
+	// Wait until both replicas have subscribed
+	require.Eventually(t, func() bool {
+		return client.PubSubNumSub(ctx, "security_alerts").Val()["security_alerts"] == 2
+	}, time.Second, 10*time.Millisecond)
+
+	client.Publish(ctx, "security_alerts", `{"type":"security_alert","n":1}`)
+	client.Publish(ctx, "security_alerts", `{"type":"security_alert","n":2}`)
+
+	require.Eventually(t, func() bool { return len(siem.events()) >= 2 }, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, siem.events(), 2)
+}
+
+func TestSIEMForwarder_BacklogsUndeliveredAlerts(t *testing.T) {
+	forwarder, siem, _ := newTestSIEMForwarder(t)
+	siem.setFailing(true)
+	ctx := context.Background()
+
+	forwarder.forward(ctx, SIEMEvent{Channel: "security_alerts", Alert: json.RawMessage(`{"n":1}`)})
+	forwarder.forward(ctx, SIEMEvent{Channel: "security_alerts", Alert: json.RawMessage(`{"n":2}`)})
+	assert.Equal(t, 2*siemAttempts, siem.attempts)
+
+	backlog, err := forwarder.Backlog(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), backlog.Count)
+	require.Len(t, backlog.Oldest, 2)
+	assert.JSONEq(t, `{"n":1}`, string(backlog.Oldest[0].Alert))
+
+	// Flushing stops at the first alert still refused
+	flushed, err := forwarder.Flush(ctx)
+	assert.Error(t, err)
+	assert.Zero(t, flushed)
+	backlog, err = forwarder.Backlog(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), backlog.Count)
+	assert.JSONEq(t, `{"n":1}`, string(backlog.Oldest[0].Alert))
+
+	// Flushes run in the background, one at a time
+	siem.setFailing(false)
+	require.True(t, forwarder.StartFlush())
+	require.Eventually(t, func() bool { return !forwarder.Flushing() }, time.Second, time.Millisecond)
+
+	events := siem.events()
+	require.Len(t, events, 2)
+	assert.JSONEq(t, `{"n":1}`, string(events[0].Alert))
+	assert.JSONEq(t, `{"n":2}`, string(events[1].Alert))
+
+	backlog, err = forwarder.Backlog(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, backlog.Count)
+	assert.Empty(t, backlog.Oldest)
+}
+
+func TestSIEMForwarder_BacklogIsCapped(t *testing.T) {
+	forwarder, _, client := newTestSIEMForwarder(t)
+	ctx := context.Background()
+
+	for i := 0; i < siemBacklogLimit; i++ {
+		require.NoError(t, client.RPush(ctx, siemFailedEventsKey, `{"channel":"security_alerts","alert":{"n":0}}`).Err())
+	}
+	require.NoError(t, forwarder.backlog(ctx, SIEMEvent{Channel: "security_alerts", Alert: json.RawMessage(`{"n":1}`)}))
+
+	// The oldest alert made room for the newest
+	assert.Equal(t, int64(siemBacklogLimit), client.LLen(ctx, siemFailedEventsKey).Val())
+	newest, err := client.LIndex(ctx, siemFailedEventsKey, -1).Result()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"channel":"security_alerts","alert":{"n":1}}`, newest)
+}
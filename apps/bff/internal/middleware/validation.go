@@ -13,6 +13,59 @@ import (
 
 type InputValidator struct {
 	policy *bluemonday.Policy
+	// registry holds the SQL injection and XSS patterns; the default patterns
+	// are used without one
+	registry *PatternRegistry
+}
+
+// Common SQL injection patterns, used when no pattern registry is configured.
+// The security_patterns table is seeded with the same patterns.
+var defaultSQLPatterns = compilePatterns(
+	`(?i)(union\s+select)`,
+	`(?i)(select\s+.*\s+from)`,
+	`(?i)(insert\s+into)`,
+	`(?i)(delete\s+from)`,
+	`(?i)(update\s+.*\s+set)`,
+	`(?i)(drop\s+table)`,
+	`(?i)(create\s+table)`,
+	`(?i)(alter\s+table)`,
+	`(?i)(\'\s*or\s*\'\s*=\s*\')`,
+	`(?i)(\'\s*or\s*1\s*=\s*1)`,
+	`(?i)(--\s*$)`,
+	`(?i)(/\*.*\*/)`,
+	`(?i)(xp_cmdshell)`,
+	`(?i)(sp_executesql)`,
+)
+
+// Common XSS patterns, used when no pattern registry is configured
+var defaultXSSPatterns = compilePatterns(
+	`(?i)<script[^>]*>.*?</script>`,
+	`(?i)<iframe[^>]*>.*?</iframe>`,
+	`(?i)<object[^>]*>.*?</object>`,
+	`(?i)<embed[^>]*>`,
+	`(?i)<link[^>]*>`,
+	`(?i)<meta[^>]*>`,
+	`(?i)javascript:`,
+	`(?i)vbscript:`,
+	`(?i)onload\s*=`,
+	`(?i)onerror\s*=`,
+	`(?i)onclick\s*=`,
+	`(?i)onmouseover\s*=`,
+	`(?i)onfocus\s*=`,
+	`(?i)onblur\s*=`,
+	`(?i)onchange\s*=`,
+	`(?i)onsubmit\s*=`,
+	`(?i)expression\s*\(`,
+	`(?i)@import`,
+	`(?i)behavior\s*:`,
+)
+
+func compilePatterns(patterns ...string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		compiled[i] = regexp.MustCompile(pattern)
+	}
+	return compiled
 }
 
 type ValidationRule struct {
@@ -33,6 +86,14 @@ func NewInputValidator() *InputValidator {
 	}
 }
 
+// NewInputValidatorWithRegistry creates a validator that checks input against the
+// active patterns of registry, following their updates
+func NewInputValidatorWithRegistry(registry *PatternRegistry) *InputValidator {
+	validator := NewInputValidator()
+	validator.registry = registry
+	return validator
+}
+
 // ValidateAndSanitizeInput validates and sanitizes user input
 func (iv *InputValidator) ValidateAndSanitizeInput(input string, rules ValidationRule) (string, error) {
 	// Check if required field is empty
@@ -136,64 +197,31 @@ func (iv *InputValidator) ValidateUUID(uuid string) error {
 
 // DetectSQLInjection detects potential SQL injection attempts
 func (iv *InputValidator) DetectSQLInjection(input string) bool {
-	// Common SQL injection patterns
-	sqlPatterns := []string{
-		`(?i)(union\s+select)`,
-		`(?i)(select\s+.*\s+from)`,
-		`(?i)(insert\s+into)`,
-		`(?i)(delete\s+from)`,
-		`(?i)(update\s+.*\s+set)`,
-		`(?i)(drop\s+table)`,
-		`(?i)(create\s+table)`,
-		`(?i)(alter\s+table)`,
-		`(?i)(\'\s*or\s*\'\s*=\s*\')`,
-		`(?i)(\'\s*or\s*1\s*=\s*1)`,
-		`(?i)(--\s*$)`,
-		`(?i)(/\*.*\*/)`,
-		`(?i)(xp_cmdshell)`,
-		`(?i)(sp_executesql)`,
-	}
-	
-	for _, pattern := range sqlPatterns {
-		if matched, _ := regexp.MatchString(pattern, input); matched {
-			return true
-		}
-	}
-	
-	return false
+	return matchesAny(iv.patterns(PatternCategorySQLInjection), input)
 }
 
 // DetectXSS detects potential XSS attempts
 func (iv *InputValidator) DetectXSS(input string) bool {
-	// Common XSS patterns
-	xssPatterns := []string{
-		`(?i)<script[^>]*>.*?</script>`,
-		`(?i)<iframe[^>]*>.*?</iframe>`,
-		`(?i)<object[^>]*>.*?</object>`,
-		`(?i)<embed[^>]*>`,
-		`(?i)<link[^>]*>`,
-		`(?i)<meta[^>]*>`,
-		`(?i)javascript:`,
-		`(?i)vbscript:`,
-		`(?i)onload\s*=`,
-		`(?i)onerror\s*=`,
-		`(?i)onclick\s*=`,
-		`(?i)onmouseover\s*=`,
-		`(?i)onfocus\s*=`,
-		`(?i)onblur\s*=`,
-		`(?i)onchange\s*=`,
-		`(?i)onsubmit\s*=`,
-		`(?i)expression\s*\(`,
-		`(?i)@import`,
-		`(?i)behavior\s*:`,
+	return matchesAny(iv.patterns(PatternCategoryXSS), input)
+}
+
+// patterns returns the patterns of category that input is checked against
+func (iv *InputValidator) patterns(category string) []*regexp.Regexp {
+	if iv.registry != nil {
+		return iv.registry.Patterns(category)
 	}
-	
-	for _, pattern := range xssPatterns {
-		if matched, _ := regexp.MatchString(pattern, input); matched {
+	if category == PatternCategorySQLInjection {
+		return defaultSQLPatterns
+	}
+	return defaultXSSPatterns
+}
+
+func matchesAny(patterns []*regexp.Regexp, input string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(input) {
 			return true
 		}
 	}
-	
 	return false
 }
 
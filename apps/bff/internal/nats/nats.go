@@ -1,14 +1,36 @@
 package nats
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
+	"time"
 
 	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/metrics"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/tracing"
 )
 
 type Conn struct {
 	*nats.Conn
+
+	// credentialsCipher seals the platform credentials sent to the connectors service
+	credentialsCipher cipher.AEAD
 }
 
 func Connect(natsURL string) (*Conn, error) {
@@ -24,6 +46,85 @@ func (c *Conn) Close() {
 	c.Conn.Close()
 }
 
+// Publish publishes data to subject and records the publish latency
+func (c *Conn) Publish(subject string, data []byte) error {
+	timer := prometheus.NewTimer(metrics.NATSPublishDuration.WithLabelValues(metrics.Subject(subject)))
+	defer timer.ObserveDuration()
+
+	return c.Conn.Publish(subject, data)
+}
+
+// Request sends data to subject and waits for the reply, recording the latency
+// until the reply arrives. The request is traced as a child of the span in ctx,
+// whose trace context is sent in the message headers.
+func (c *Conn) Request(ctx context.Context, subject string, data []byte, timeout time.Duration) (_ *nats.Msg, err error) {
+	timer := prometheus.NewTimer(metrics.NATSRequestDuration.WithLabelValues(metrics.Subject(subject)))
+	defer timer.ObserveDuration()
+
+	ctx, span := tracing.Tracer.Start(ctx, subject+" request", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("messaging.system", "nats"), attribute.String("messaging.destination.name", subject)))
+	defer func() { tracing.End(span, err) }()
+
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(msg.Header))
+
+	return c.Conn.RequestMsg(msg, timeout)
+}
+
+// maxMessageSize is the largest size of the data of a compressed message once
+// decompressed, so that a small message cannot inflate to exhaust memory
+const maxMessageSize = 8 << 20
+
+// messageData returns the data of a message published by the connectors service,
+// which compresses it with gzip when its Content-Encoding header says so
+func messageData(msg *nats.Msg) ([]byte, error) {
+	switch encoding := msg.Header.Get("Content-Encoding"); encoding {
+	case "":
+		return msg.Data, nil
+	case "gzip":
+		reader, err := gzip.NewReader(bytes.NewReader(msg.Data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress message: %w", err)
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(io.LimitReader(reader, maxMessageSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress message: %w", err)
+		}
+		if len(data) > maxMessageSize {
+			return nil, fmt.Errorf("decompressed message exceeds %d bytes", maxMessageSize)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unsupported message encoding %q", encoding)
+	}
+}
+
+// Subscribe subscribes handler to subject and records how long it takes to
+// handle each message
+func (c *Conn) Subscribe(subject string, handler nats.MsgHandler) (*nats.Subscription, error) {
+	return c.Conn.Subscribe(subject, observeHandler(subject, handler))
+}
+
+// QueueSubscribe subscribes handler to subject within queue and records how long
+// it takes to handle each message
+func (c *Conn) QueueSubscribe(subject, queue string, handler nats.MsgHandler) (*nats.Subscription, error) {
+	return c.Conn.QueueSubscribe(subject, queue, observeHandler(subject, handler))
+}
+
+func observeHandler(subject string, handler nats.MsgHandler) nats.MsgHandler {
+	duration := metrics.NATSHandleDuration.WithLabelValues(metrics.Subject(subject))
+
+	return func(msg *nats.Msg) {
+		timer := prometheus.NewTimer(duration)
+		defer timer.ObserveDuration()
+
+		handler(msg)
+	}
+}
+
 func (c *Conn) PublishBoardUpdate(boardID string, data interface{}) error {
 	subject := fmt.Sprintf("board.%s.updated", boardID)
 	
@@ -43,34 +144,960 @@ func (c *Conn) SubscribeBoardUpdates(boardID string, handler func([]byte)) (*nat
 	})
 }
 
-func (c *Conn) SubscribeCampaignMetricsUpdated(projectID string, handler func([]byte)) (*nats.Subscription, error) {
+// PublishAssetUpdate publishes an asset whose status changed to the subscribers of
+// its board's asset updates
+func (c *Conn) PublishAssetUpdate(boardID string, asset interface{}) error {
+	payload, err := json.Marshal(asset)
+	if err != nil {
+		return fmt.Errorf("failed to marshal asset: %w", err)
+	}
+
+	return c.Publish(assetUpdateSubject(boardID), payload)
+}
+
+// SubscribeAssetUpdates calls handler with each asset published by
+// PublishAssetUpdate for the board boardID
+func (c *Conn) SubscribeAssetUpdates(boardID string, handler func([]byte)) (*nats.Subscription, error) {
+	return c.Subscribe(assetUpdateSubject(boardID), func(msg *nats.Msg) {
+		handler(msg.Data)
+	})
+}
+
+func assetUpdateSubject(boardID string) string {
+	return fmt.Sprintf("board.%s.asset_updated", boardID)
+}
+
+// PublishAssetQualityScore publishes the quality score of an asset of the board
+// boardID to its subscribers on every instance of the BFF
+func (c *Conn) PublishAssetQualityScore(boardID string, score interface{}) error {
+	payload, err := json.Marshal(score)
+	if err != nil {
+		return fmt.Errorf("failed to marshal asset quality score: %w", err)
+	}
+
+	return c.Publish(assetQualityScoreSubject(boardID), payload)
+}
+
+// SubscribeAssetQualityScores calls handler with each score published by
+// PublishAssetQualityScore for the board boardID
+func (c *Conn) SubscribeAssetQualityScores(boardID string, handler func([]byte)) (*nats.Subscription, error) {
+	return c.Subscribe(assetQualityScoreSubject(boardID), func(msg *nats.Msg) {
+		handler(msg.Data)
+	})
+}
+
+func assetQualityScoreSubject(boardID string) string {
+	return fmt.Sprintf("board.%s.asset_quality_scored", boardID)
+}
+
+// CampaignMetrics is the performance of a deployed campaign as fetched by the
+// connectors service. Cost is in micros and revenue in units of the account
+// currency; revenue is only reported by Meta.
+type CampaignMetrics struct {
+	Impressions int64     `json:"impressions"`
+	Clicks      int64     `json:"clicks"`
+	CostMicros  int64     `json:"cost_micros"`
+	Conversions float64   `json:"conversions"`
+	CTR         float64   `json:"ctr"`
+	Revenue     float64   `json:"revenue"`
+	ROAS        float64   `json:"roas"`
+	StartDate   string    `json:"start_date"`
+	EndDate     string    `json:"end_date"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+type CampaignMetricsUpdatedEvent struct {
+	EventType  string          `json:"event_type"`
+	ProjectID  string          `json:"project_id"`
+	AssetID    string          `json:"asset_id"`
+	Platform   string          `json:"platform"`
+	CampaignID string          `json:"campaign_id"`
+	Metrics    CampaignMetrics `json:"metrics"`
+	Timestamp  time.Time       `json:"timestamp"`
+}
+
+// SubscribeCampaignMetricsUpdated calls handler for the metrics of every deployed
+// campaign polled by the connectors service. Instances of the BFF share the
+// events through a queue group so that each is handled once.
+func (c *Conn) SubscribeCampaignMetricsUpdated(handler func(*CampaignMetricsUpdatedEvent)) (*nats.Subscription, error) {
 	subject := "zamc.events.campaign.metrics_updated"
-	
-	return c.Subscribe(subject, func(msg *nats.Msg) {
+
+	return c.QueueSubscribe(subject, "bff", func(msg *nats.Msg) {
+		var event CampaignMetricsUpdatedEvent
+		data, err := messageData(msg)
+		if err != nil {
+			return
+		}
+		if err := json.Unmarshal(data, &event); err != nil {
+			return
+		}
+		handler(&event)
+	})
+}
+
+// PublishCampaignMetricsUpdate publishes saved campaign metrics to the
+// subscribers of the project projectID on every instance of the BFF
+func (c *Conn) PublishCampaignMetricsUpdate(projectID string, update interface{}) error {
+	payload, err := json.Marshal(update)
+	if err != nil {
+		return fmt.Errorf("failed to marshal campaign metrics update: %w", err)
+	}
+
+	return c.Publish(campaignMetricsUpdateSubject(projectID), payload)
+}
+
+// SubscribeCampaignMetricsUpdates calls handler with each update published by
+// PublishCampaignMetricsUpdate for the project projectID
+func (c *Conn) SubscribeCampaignMetricsUpdates(projectID string, handler func([]byte)) (*nats.Subscription, error) {
+	return c.Subscribe(campaignMetricsUpdateSubject(projectID), func(msg *nats.Msg) {
 		handler(msg.Data)
 	})
 }
 
+func campaignMetricsUpdateSubject(projectID string) string {
+	return fmt.Sprintf("project.%s.campaign_metrics_updated", projectID)
+}
+
+// PublishProjectROIUpdate publishes the return on ad spend of the project
+// projectID to its subscribers on every instance of the BFF
+func (c *Conn) PublishProjectROIUpdate(projectID string, update interface{}) error {
+	payload, err := json.Marshal(update)
+	if err != nil {
+		return fmt.Errorf("failed to marshal project ROI update: %w", err)
+	}
+
+	return c.Publish(projectROIUpdateSubject(projectID), payload)
+}
+
+// SubscribeProjectROIUpdates calls handler with each update published by
+// PublishProjectROIUpdate for the project projectID
+func (c *Conn) SubscribeProjectROIUpdates(projectID string, handler func([]byte)) (*nats.Subscription, error) {
+	return c.Subscribe(projectROIUpdateSubject(projectID), func(msg *nats.Msg) {
+		handler(msg.Data)
+	})
+}
+
+// SubscribeAllProjectROIUpdates calls handler with the ID of the project of each
+// update published by PublishProjectROIUpdate
+func (c *Conn) SubscribeAllProjectROIUpdates(handler func(projectID string)) (*nats.Subscription, error) {
+	return c.Subscribe(projectROIUpdateSubject("*"), func(msg *nats.Msg) {
+		tokens := strings.Split(msg.Subject, ".")
+		if len(tokens) == 3 {
+			handler(tokens[1])
+		}
+	})
+}
+
+func projectROIUpdateSubject(projectID string) string {
+	return fmt.Sprintf("project.%s.roi_updated", projectID)
+}
+
+// CampaignAnomaly is a campaign metric the connectors service found too far from
+// its rolling average
+type CampaignAnomaly struct {
+	CampaignID string    `json:"campaign_id"`
+	Platform   string    `json:"platform"`
+	AssetID    string    `json:"asset_id"`
+	ProjectID  string    `json:"project_id"`
+	Metric     string    `json:"metric"`
+	Value      float64   `json:"value"`
+	Mean       float64   `json:"mean"`
+	StdDev     float64   `json:"std_dev"`
+	ZScore     float64   `json:"z_score"`
+	Severity   string    `json:"severity"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// CampaignPerformanceAlertEvent is published by the connectors service when a
+// campaign metric becomes anomalous
+type CampaignPerformanceAlertEvent struct {
+	EventType string          `json:"event_type"`
+	Anomaly   CampaignAnomaly `json:"anomaly"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
 func (c *Conn) SubscribeCampaignPerformanceAlert(projectID string, handler func([]byte)) (*nats.Subscription, error) {
 	subject := "zamc.events.campaign.performance_alert"
 	
 	return c.Subscribe(subject, func(msg *nats.Msg) {
-		handler(msg.Data)
+		data, err := messageData(msg)
+		if err != nil {
+			return
+		}
+		handler(data)
 	})
 }
 
+// CampaignBudgetExceededEvent is published by the connectors service when it
+// refuses a deployment whose daily budget is above the platform's cap
+type CampaignBudgetExceededEvent struct {
+	EventType string    `json:"event_type"`
+	AssetID   string    `json:"asset_id"`
+	ProjectID string    `json:"project_id"`
+	Platform  string    `json:"platform"`
+	Budget    float64   `json:"budget"`
+	Cap       float64   `json:"cap"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
 func (c *Conn) SubscribeCampaignBudgetExceeded(projectID string, handler func([]byte)) (*nats.Subscription, error) {
 	subject := "zamc.events.campaign.budget_exceeded"
 	
 	return c.Subscribe(subject, func(msg *nats.Msg) {
-		handler(msg.Data)
+		data, err := messageData(msg)
+		if err != nil {
+			return
+		}
+		handler(data)
 	})
 }
 
+// SpendBudgetExceededEvent reports that the ad spend of a project in a month is
+// above the share of its budget that warrants an alert. It shares its subject
+// with the deployments refused by the connectors service for exceeding a
+// platform's cap, from which its event type tells it apart.
+type SpendBudgetExceededEvent struct {
+	EventType         string    `json:"event_type"`
+	ProjectID         string    `json:"project_id"`
+	Month             string    `json:"month"`
+	TotalSpend        float64   `json:"total_spend"`
+	BudgetLimit       float64   `json:"budget_limit"`
+	BudgetUtilization float64   `json:"budget_utilization"`
+	Timestamp         time.Time `json:"timestamp"`
+}
+
+// PublishSpendBudgetExceeded publishes the spend of a project nearing or above
+// its monthly budget
+func (c *Conn) PublishSpendBudgetExceeded(event *SpendBudgetExceededEvent) error {
+	subject := "zamc.events.campaign.budget_exceeded"
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	return c.Publish(subject, payload)
+}
+
 func (c *Conn) SubscribeCampaignPerformanceThreshold(projectID string, handler func([]byte)) (*nats.Subscription, error) {
 	subject := "zamc.events.campaign.performance_threshold"
 	
 	return c.Subscribe(subject, func(msg *nats.Msg) {
-		handler(msg.Data)
+		data, err := messageData(msg)
+		if err != nil {
+			return
+		}
+		handler(data)
+	})
+} 
+
+type AssetPlatformRejectedEvent struct {
+	EventType          string    `json:"event_type"`
+	AssetID            string    `json:"asset_id"`
+	ProjectID          string    `json:"project_id"`
+	Platform           string    `json:"platform"`
+	PlatformAdID       string    `json:"platform_ad_id"`
+	Reason             string    `json:"reason"`
+	DisapprovalReasons []string  `json:"disapproval_reasons"`
+	PolicyViolations   []string  `json:"policy_violations"`
+	Timestamp          time.Time `json:"timestamp"`
+}
+
+// SubscribeAssetPlatformRejected calls handler for every ad the connectors service
+// reports as disapproved by a platform's review. Instances of the BFF share the
+// events through a queue group so that each is handled once.
+func (c *Conn) SubscribeAssetPlatformRejected(handler func(*AssetPlatformRejectedEvent)) (*nats.Subscription, error) {
+	subject := "zamc.events.asset.platform_rejected"
+
+	return c.QueueSubscribe(subject, "bff", func(msg *nats.Msg) {
+		var event AssetPlatformRejectedEvent
+		data, err := messageData(msg)
+		if err != nil {
+			return
+		}
+		if err := json.Unmarshal(data, &event); err != nil {
+			return
+		}
+		handler(&event)
+	})
+}
+
+// AssetStatusChangedEvent is published by the connectors service when the
+// deployment of an asset to all of its platforms has finished
+type AssetStatusChangedEvent struct {
+	EventType  string    `json:"event_type"`
+	AssetID    string    `json:"asset_id"`
+	ProjectID  string    `json:"project_id"`
+	Status     string    `json:"status"`
+	PrevStatus string    `json:"prev_status"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// SubscribeAssetStatusChanged calls handler for every asset status change
+// published by the connectors service. Per-platform deployment updates shared
+// with the subject are skipped. Instances of the BFF share the events through a
+// queue group so that each is handled once.
+func (c *Conn) SubscribeAssetStatusChanged(handler func(*AssetStatusChangedEvent)) (*nats.Subscription, error) {
+	subject := "zamc.events.asset.status_changed"
+
+	return c.QueueSubscribe(subject, "bff", func(msg *nats.Msg) {
+		var event AssetStatusChangedEvent
+		data, err := messageData(msg)
+		if err != nil {
+			return
+		}
+		if err := json.Unmarshal(data, &event); err != nil {
+			return
+		}
+		if event.EventType != "asset.status_changed" {
+			return
+		}
+		handler(&event)
+	})
+}
+
+// DeploymentStatusChangedEvent is published by the connectors service when the
+// deployment of an asset to one of its platforms has finished
+type DeploymentStatusChangedEvent struct {
+	EventType        string           `json:"event_type"`
+	AssetID          string           `json:"asset_id"`
+	ProjectID        string           `json:"project_id"`
+	Platform         string           `json:"platform"`
+	Status           string           `json:"status"`
+	DeploymentResult DeploymentResult `json:"deployment_result"`
+	Timestamp        time.Time        `json:"timestamp"`
+}
+
+// SubscribeDeploymentStatusChanged calls handler for the deployment of an asset
+// to each of its platforms. The events share their subject with asset status
+// changes, so instances of the BFF share them through a queue group of their
+// own: within the group of SubscribeAssetStatusChanged, each event would reach
+// only one of the two subscriptions.
+func (c *Conn) SubscribeDeploymentStatusChanged(handler func(*DeploymentStatusChangedEvent)) (*nats.Subscription, error) {
+	subject := "zamc.events.asset.status_changed"
+
+	return c.QueueSubscribe(subject, "bff-deployments", func(msg *nats.Msg) {
+		var event DeploymentStatusChangedEvent
+		data, err := messageData(msg)
+		if err != nil {
+			return
+		}
+		if err := json.Unmarshal(data, &event); err != nil {
+			return
+		}
+		if event.EventType != "asset.deployment_status_changed" {
+			return
+		}
+		handler(&event)
+	})
+}
+
+type CampaignDuplicationRequest struct {
+	AssetID          string  `json:"asset_id"`
+	TenantID         string  `json:"tenant_id,omitempty"`
+	Platform         string  `json:"platform"`
+	SourceCampaignID string  `json:"source_campaign_id"`
+	NewName          string  `json:"new_name"`
+	NewBudget        float64 `json:"new_budget"`
+}
+
+type campaignDuplicationReply struct {
+	SourceCampaignID string `json:"source_campaign_id"`
+	CampaignID       string `json:"campaign_id"`
+	Error            string `json:"error"`
+}
+
+// RequestCampaignDuplication asks the connectors service to clone a deployed
+// campaign and waits for the new platform campaign ID.
+func (c *Conn) RequestCampaignDuplication(ctx context.Context, request CampaignDuplicationRequest, timeout time.Duration) (string, error) {
+	subject := "zamc.commands.campaign.duplicate"
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	msg, err := c.Request(ctx, subject, payload, timeout)
+	if err != nil {
+		return "", fmt.Errorf("campaign duplication request failed: %w", err)
+	}
+
+	var reply campaignDuplicationReply
+	if err := json.Unmarshal(msg.Data, &reply); err != nil {
+		return "", fmt.Errorf("failed to unmarshal campaign duplication reply: %w", err)
+	}
+
+	if reply.Error != "" {
+		return "", errors.New(reply.Error)
+	}
+
+	return reply.CampaignID, nil
+}
+
+type PlatformCredentials struct {
+	DeveloperToken  string `json:"developer_token,omitempty"`
+	ClientID        string `json:"client_id,omitempty"`
+	ClientSecret    string `json:"client_secret,omitempty"`
+	RefreshToken    string `json:"refresh_token,omitempty"`
+	CustomerID      string `json:"customer_id,omitempty"`
+	LoginCustomerID string `json:"login_customer_id,omitempty"`
+	AppID           string `json:"app_id,omitempty"`
+	AppSecret       string `json:"app_secret,omitempty"`
+	AccessToken     string `json:"access_token,omitempty"`
+	AdAccountID     string `json:"ad_account_id,omitempty"`
+}
+
+type PlatformCredentialsRequest struct {
+	TenantID    string
+	Platform    string
+	Credentials PlatformCredentials
+}
+
+// platformCredentialsMessage is the message of a PlatformCredentialsRequest,
+// whose credentials are sealed with the transport key
+type platformCredentialsMessage struct {
+	TenantID             string `json:"tenant_id"`
+	Platform             string `json:"platform"`
+	EncryptedCredentials string `json:"encrypted_credentials"`
+}
+
+type platformCredentialsReply struct {
+	Error string `json:"error"`
+}
+
+// UseCredentialsTransportKey seals the platform credentials sent to the
+// connectors service with transportKey, the base64 encoded 32 byte AES key
+// shared with it only
+func (c *Conn) UseCredentialsTransportKey(transportKey string) error {
+	key, err := base64.StdEncoding.DecodeString(transportKey)
+	if err != nil {
+		return fmt.Errorf("failed to decode credentials transport key: %w", err)
+	}
+	if len(key) != 32 {
+		return fmt.Errorf("credentials transport key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return fmt.Errorf("failed to create block cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return fmt.Errorf("failed to create GCM cipher: %w", err)
+	}
+
+	c.credentialsCipher = aead
+	return nil
+}
+
+// sealPlatformCredentials encrypts the credentials of request, binding them to
+// its tenant and platform. The result is the base64 encoded nonce followed by
+// the ciphertext.
+func (c *Conn) sealPlatformCredentials(request PlatformCredentialsRequest) (string, error) {
+	if c.credentialsCipher == nil {
+		return "", errors.New("credentials transport key is not configured")
+	}
+
+	plaintext, err := json.Marshal(request.Credentials)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	nonce := make([]byte, c.credentialsCipher.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := c.credentialsCipher.Seal(nonce, nonce, plaintext, []byte(request.TenantID+":"+request.Platform))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// RequestStorePlatformCredentials hands tenant credentials to the connectors
+// service, which encrypts and stores them. The credentials are sealed with the
+// transport key so that they never cross NATS in plain text.
+func (c *Conn) RequestStorePlatformCredentials(ctx context.Context, request PlatformCredentialsRequest, timeout time.Duration) error {
+	subject := "zamc.commands.credentials.store"
+
+	encrypted, err := c.sealPlatformCredentials(request)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(platformCredentialsMessage{
+		TenantID:             request.TenantID,
+		Platform:             request.Platform,
+		EncryptedCredentials: encrypted,
 	})
-} 
\ No newline at end of file
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	msg, err := c.Request(ctx, subject, payload, timeout)
+	if err != nil {
+		return fmt.Errorf("platform credentials request failed: %w", err)
+	}
+
+	var reply platformCredentialsReply
+	if err := json.Unmarshal(msg.Data, &reply); err != nil {
+		return fmt.Errorf("failed to unmarshal platform credentials reply: %w", err)
+	}
+
+	if reply.Error != "" {
+		return errors.New(reply.Error)
+	}
+
+	return nil
+}
+
+type DeploymentRollbackRequest struct {
+	AssetID     string `json:"asset_id"`
+	Platform    string `json:"platform"`
+	RequestedBy string `json:"requested_by"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+type DeploymentRollback struct {
+	AssetID            string    `json:"asset_id"`
+	Platform           string    `json:"platform"`
+	PlatformCampaignID string    `json:"platform_campaign_id"`
+	PlatformAdID       string    `json:"platform_ad_id"`
+	RolledBackAt       time.Time `json:"rolled_back_at"`
+}
+
+type deploymentRollbackReply struct {
+	DeploymentRollback
+	Error string `json:"error"`
+}
+
+// RequestDeploymentRollback asks the connectors service to pause the ad of an
+// asset's deployment to a platform and waits for the paused campaign and ad.
+func (c *Conn) RequestDeploymentRollback(ctx context.Context, request DeploymentRollbackRequest, timeout time.Duration) (*DeploymentRollback, error) {
+	subject := "zamc.commands.deployment.rollback"
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	msg, err := c.Request(ctx, subject, payload, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("deployment rollback request failed: %w", err)
+	}
+
+	var reply deploymentRollbackReply
+	if err := json.Unmarshal(msg.Data, &reply); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal deployment rollback reply: %w", err)
+	}
+
+	if reply.Error != "" {
+		return nil, errors.New(reply.Error)
+	}
+
+	return &reply.DeploymentRollback, nil
+}
+
+type CustomAudienceDeletionRequest struct {
+	AssetID     string `json:"asset_id"`
+	RequestedBy string `json:"requested_by"`
+}
+
+type customAudienceDeletionReply struct {
+	Deleted bool   `json:"deleted"`
+	Error   string `json:"error"`
+}
+
+// RequestCustomAudienceDeletion asks the connectors service to delete the Meta
+// custom audiences an asset's deployments target, and reports whether the asset
+// had any.
+func (c *Conn) RequestCustomAudienceDeletion(ctx context.Context, request CustomAudienceDeletionRequest, timeout time.Duration) (bool, error) {
+	subject := "zamc.commands.audience.delete"
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	msg, err := c.Request(ctx, subject, payload, timeout)
+	if err != nil {
+		return false, fmt.Errorf("custom audience deletion request failed: %w", err)
+	}
+
+	var reply customAudienceDeletionReply
+	if err := json.Unmarshal(msg.Data, &reply); err != nil {
+		return false, fmt.Errorf("failed to unmarshal custom audience deletion reply: %w", err)
+	}
+
+	if reply.Error != "" {
+		return false, errors.New(reply.Error)
+	}
+
+	return reply.Deleted, nil
+}
+
+type AssetSLABreachEvent struct {
+	EventType       string    `json:"event_type"`
+	AssetID         string    `json:"asset_id"`
+	AssetName       string    `json:"asset_name"`
+	BoardID         string    `json:"board_id"`
+	ProjectID       string    `json:"project_id"`
+	ReviewStartedAt time.Time `json:"review_started_at"`
+	SLAHours        int       `json:"sla_hours"`
+	OverdueHours    float64   `json:"overdue_hours"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+func (c *Conn) PublishAssetSLABreach(event *AssetSLABreachEvent) error {
+	subject := "zamc.events.asset.sla_breach"
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	return c.Publish(subject, payload)
+}
+
+// SuspiciousLoginEvent reports a login a client detected from a device it does
+// not recognize, for the user to be warned by email
+type SuspiciousLoginEvent struct {
+	EventType string    `json:"event_type"`
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	Device    string    `json:"device,omitempty"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	Country   string    `json:"country,omitempty"`
+	City      string    `json:"city,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// PublishSuspiciousLogin publishes a suspicious login, which the connectors
+// service emails to the user
+func (c *Conn) PublishSuspiciousLogin(event *SuspiciousLoginEvent) error {
+	subject := "zamc.events.auth.suspicious_login"
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	return c.Publish(subject, payload)
+}
+
+// AssetsApprovedEvent lists the assets approved together by one reviewer
+type AssetsApprovedEvent struct {
+	EventType  string    `json:"event_type"`
+	AssetIDs   []string  `json:"asset_ids"`
+	ApprovedBy string    `json:"approved_by"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// PublishAssetsApproved publishes a single event for a batch of approved assets,
+// instead of one per asset
+func (c *Conn) PublishAssetsApproved(event *AssetsApprovedEvent) error {
+	subject := "zamc.events.asset.approved_batch"
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	return c.Publish(subject, payload)
+}
+
+type DeploymentAsset struct {
+	AssetID   string          `json:"asset_id"`
+	ProjectID string          `json:"project_id"`
+	TenantID  string          `json:"tenant_id,omitempty"`
+	Status    string          `json:"status"`
+	Title     string          `json:"title"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
+}
+
+type DeploymentTemplate struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	OwnerID     string          `json:"owner_id"`
+	Platform    string          `json:"platform"`
+	ContentType string          `json:"content_type"`
+	Metadata    json.RawMessage `json:"metadata"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type TemplateDeploymentRequest struct {
+	Asset    DeploymentAsset    `json:"asset"`
+	Template DeploymentTemplate `json:"template"`
+}
+
+type DeploymentResult struct {
+	AssetID     string    `json:"asset_id"`
+	Platform    string    `json:"platform"`
+	Status      string    `json:"status"`
+	PlatformID  string    `json:"platform_id"`
+	PlatformURL string    `json:"platform_url"`
+	Error       string    `json:"error"`
+	DeployedAt  time.Time `json:"deployed_at"`
+
+	Metrics DeploymentMetrics `json:"metrics"`
+}
+
+// DeploymentMetrics tell how long the deployment of an asset to a platform took
+type DeploymentMetrics struct {
+	Duration   time.Duration `json:"duration"`
+	RetryCount int           `json:"retry_count"`
+}
+
+// RequestTemplateDeployment asks the connectors service to deploy an asset with a
+// deployment template and waits for the result. A deployment that fails on the
+// platform is reported through the result's status and error.
+func (c *Conn) RequestTemplateDeployment(ctx context.Context, request TemplateDeploymentRequest, timeout time.Duration) (*DeploymentResult, error) {
+	subject := "zamc.commands.deployment.template"
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	msg, err := c.Request(ctx, subject, payload, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("template deployment request failed: %w", err)
+	}
+
+	var result DeploymentResult
+	if err := json.Unmarshal(msg.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal template deployment result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// DeploymentDryRunRequest is an asset, shaped like an asset status event, whose
+// deployment the connectors service checks without making it
+type DeploymentDryRunRequest struct {
+	AssetID     string          `json:"asset_id"`
+	ProjectID   string          `json:"project_id"`
+	TenantID    string          `json:"tenant_id,omitempty"`
+	Status      string          `json:"status"`
+	PrevStatus  string          `json:"prev_status"`
+	ContentType string          `json:"content_type"`
+	Title       string          `json:"title"`
+	Metadata    json.RawMessage `json:"metadata"`
+	Mode        string          `json:"mode"`
+
+	// ScheduledAt and VariantGroup are those of the asset, as in the event of a
+	// live approval
+	ScheduledAt  *time.Time `json:"scheduled_at,omitempty"`
+	VariantGroup string     `json:"variant_group,omitempty"`
+}
+
+// ScheduledDeploymentEvent is the status changed event of an approved asset
+// whose scheduled deployment time has come, which the connectors service deploys
+type ScheduledDeploymentEvent struct {
+	EventType    string          `json:"event_type"`
+	AssetID      string          `json:"asset_id"`
+	ProjectID    string          `json:"project_id"`
+	TenantID     string          `json:"tenant_id,omitempty"`
+	Status       string          `json:"status"`
+	PrevStatus   string          `json:"prev_status"`
+	ContentType  string          `json:"content_type"`
+	Title        string          `json:"title"`
+	Metadata     json.RawMessage `json:"metadata"`
+	ScheduledAt  *time.Time      `json:"scheduled_at,omitempty"`
+	VariantGroup string          `json:"variant_group,omitempty"`
+	Timestamp    time.Time       `json:"timestamp"`
+}
+
+// PublishScheduledDeployment hands an asset due to go live to the connectors
+// service
+func (c *Conn) PublishScheduledDeployment(event *ScheduledDeploymentEvent) error {
+	subject := "zamc.events.asset.status_changed"
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	return c.Publish(subject, payload)
+}
+
+// ValidationError is a problem found by a dry-run deployment
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// CostEstimate is the projected weekly delivery of a deployment
+type CostEstimate struct {
+	EstimatedImpressions int64   `json:"estimated_impressions"`
+	EstimatedClicks      int64   `json:"estimated_clicks"`
+	EstimatedSpend       float64 `json:"estimated_spend"`
+	Currency             string  `json:"currency"`
+	Confidence           float64 `json:"confidence"`
+}
+
+// PlatformValidationReport is the outcome of a dry-run deployment to a platform
+type PlatformValidationReport struct {
+	Platform              string            `json:"platform"`
+	Valid                 bool              `json:"valid"`
+	CredentialsValid      bool              `json:"credentials_valid"`
+	CreativeComplete      bool              `json:"creative_complete"`
+	BudgetWithinRange     bool              `json:"budget_within_range"`
+	TargetingValid        bool              `json:"targeting_valid"`
+	EstimatedAudienceSize int64             `json:"estimated_audience_size"`
+	CostEstimate          *CostEstimate     `json:"cost_estimate"`
+	Errors                []ValidationError `json:"errors"`
+	EstimateError         string            `json:"estimate_error"`
+}
+
+// DeploymentValidationReport holds the outcome of a dry-run deployment to each
+// platform of an asset
+type DeploymentValidationReport struct {
+	AssetID   string                     `json:"asset_id"`
+	Valid     bool                       `json:"valid"`
+	Platforms []PlatformValidationReport `json:"platforms"`
+}
+
+type deploymentDryRunReply struct {
+	Report *DeploymentValidationReport `json:"report"`
+	Error  string                      `json:"error"`
+}
+
+// RequestDeploymentDryRun asks the connectors service to check the deployment of
+// an asset to its platforms without creating anything or publishing any event,
+// and waits for the validation report.
+func (c *Conn) RequestDeploymentDryRun(ctx context.Context, request DeploymentDryRunRequest, timeout time.Duration) (*DeploymentValidationReport, error) {
+	subject := "zamc.commands.deployment.dry_run"
+
+	request.Mode = "dry_run"
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	msg, err := c.Request(ctx, subject, payload, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("deployment dry-run request failed: %w", err)
+	}
+
+	var reply deploymentDryRunReply
+	if err := json.Unmarshal(msg.Data, &reply); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal deployment dry-run reply: %w", err)
+	}
+
+	if reply.Error != "" {
+		return nil, errors.New(reply.Error)
+	}
+	if reply.Report == nil {
+		return nil, errors.New("deployment dry-run reply has no report")
+	}
+
+	return reply.Report, nil
+}
+
+type CampaignScheduleRequest struct {
+	TenantID           string `json:"tenant_id"`
+	Platform           string `json:"platform"`
+	PlatformCampaignID string `json:"platform_campaign_id"`
+	PauseCron          string `json:"pause_cron"`
+	ResumeCron         string `json:"resume_cron"`
+	Timezone           string `json:"timezone"`
+}
+
+type CampaignScheduleDeletionRequest struct {
+	ID       string `json:"id"`
+	TenantID string `json:"tenant_id"`
+}
+
+type CampaignSchedule struct {
+	ID                 string    `json:"id"`
+	Platform           string    `json:"platform"`
+	PlatformCampaignID string    `json:"platform_campaign_id"`
+	PauseCron          string    `json:"pause_cron"`
+	ResumeCron         string    `json:"resume_cron"`
+	Timezone           string    `json:"timezone"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+type campaignScheduleReply struct {
+	Schedule *CampaignSchedule `json:"schedule"`
+	Error    string            `json:"error"`
+}
+
+// RequestCreateCampaignSchedule asks the connectors service to pause and resume a
+// platform campaign on cron schedules. An existing schedule of the campaign is
+// replaced.
+func (c *Conn) RequestCreateCampaignSchedule(ctx context.Context, request CampaignScheduleRequest, timeout time.Duration) (*CampaignSchedule, error) {
+	reply, err := c.requestCampaignSchedule(ctx, "zamc.commands.campaign.schedule.create", request, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if reply.Schedule == nil {
+		return nil, errors.New("campaign schedule reply has no schedule")
+	}
+
+	return reply.Schedule, nil
+}
+
+// RequestDeleteCampaignSchedule asks the connectors service to stop and delete a
+// campaign schedule of the tenant.
+func (c *Conn) RequestDeleteCampaignSchedule(ctx context.Context, request CampaignScheduleDeletionRequest, timeout time.Duration) error {
+	_, err := c.requestCampaignSchedule(ctx, "zamc.commands.campaign.schedule.delete", request, timeout)
+	return err
+}
+
+func (c *Conn) requestCampaignSchedule(ctx context.Context, subject string, request interface{}, timeout time.Duration) (*campaignScheduleReply, error) {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	msg, err := c.Request(ctx, subject, payload, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("campaign schedule request failed: %w", err)
+	}
+
+	var reply campaignScheduleReply
+	if err := json.Unmarshal(msg.Data, &reply); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal campaign schedule reply: %w", err)
+	}
+
+	if reply.Error != "" {
+		return nil, errors.New(reply.Error)
+	}
+
+	return &reply, nil
+}
+
+// assetHashSubject is the work queue of asset content hash jobs
+const assetHashSubject = "zamc.work.asset.hash"
+
+// AssetHashJob asks for the content hash of an asset's file
+type AssetHashJob struct {
+	AssetID string `json:"asset_id"`
+	URL     string `json:"url"`
+}
+
+// PublishAssetHashJob queues a content hash job
+func (c *Conn) PublishAssetHashJob(job AssetHashJob) error {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	return c.Publish(assetHashSubject, payload)
+}
+
+// SubscribeAssetHashJobs calls handler for every queued content hash job.
+// Instances of the BFF share the jobs through a queue group so that each is
+// handled once.
+func (c *Conn) SubscribeAssetHashJobs(handler func(*AssetHashJob)) (*nats.Subscription, error) {
+	return c.QueueSubscribe(assetHashSubject, "bff", func(msg *nats.Msg) {
+		var job AssetHashJob
+		if err := json.Unmarshal(msg.Data, &job); err != nil {
+			return
+		}
+
+		handler(&job)
+	})
+}
@@ -0,0 +1,480 @@
+package nats
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRequest_PropagatesTraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	server := natsserver.RunServer(&opts)
+	t.Cleanup(server.Shutdown)
+
+	conn, err := Connect(server.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	received := make(chan trace.SpanContext, 1)
+	_, err = conn.Subscribe("zamc.test.trace", func(msg *nats.Msg) {
+		ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(msg.Header))
+		received <- trace.SpanContextFromContext(ctx)
+		msg.Respond([]byte("ok"))
+	})
+	require.NoError(t, err)
+
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), parent)
+
+	reply, err := conn.Request(ctx, "zamc.test.trace", []byte("{}"), time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(reply.Data))
+
+	handled := <-received
+	assert.True(t, handled.IsRemote())
+	assert.Equal(t, parent.TraceID(), handled.TraceID())
+}
+
+func TestAssetUpdates_PerBoard(t *testing.T) {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	server := natsserver.RunServer(&opts)
+	t.Cleanup(server.Shutdown)
+
+	conn, err := Connect(server.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	received := make(chan string, 2)
+	_, err = conn.SubscribeAssetUpdates("board-1", func(data []byte) {
+		received <- string(data)
+	})
+	require.NoError(t, err)
+
+	// Board updates and assets of other boards are not delivered
+	require.NoError(t, conn.PublishBoardUpdate("board-1", map[string]string{"id": "asset-0"}))
+	require.NoError(t, conn.PublishAssetUpdate("board-2", map[string]string{"id": "asset-2"}))
+	require.NoError(t, conn.PublishAssetUpdate("board-1", map[string]string{"id": "asset-1"}))
+
+	select {
+	case data := <-received:
+		assert.JSONEq(t, `{"id": "asset-1"}`, data)
+	case <-time.After(time.Second):
+		t.Fatal("asset update was not received")
+	}
+	assert.Empty(t, received)
+}
+
+func TestSubscribeCampaignMetricsUpdated(t *testing.T) {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	server := natsserver.RunServer(&opts)
+	t.Cleanup(server.Shutdown)
+
+	conn, err := Connect(server.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	received := make(chan *CampaignMetricsUpdatedEvent, 1)
+	_, err = conn.SubscribeCampaignMetricsUpdated(func(event *CampaignMetricsUpdatedEvent) {
+		received <- event
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, conn.Publish("zamc.events.campaign.metrics_updated", []byte(`{
+		"event_type": "campaign.metrics_updated",
+		"project_id": "project-1",
+		"asset_id": "asset-1",
+		"platform": "google_ads",
+		"campaign_id": "555",
+		"metrics": {"campaign_id": "555", "impressions": 12000, "clicks": 480, "cost_micros": 96500000, "conversions": 12.5, "ctr": 0.04, "start_date": "2024-03-01", "end_date": "2024-03-14"}
+	}`)))
+
+	select {
+	case event := <-received:
+		assert.Equal(t, "project-1", event.ProjectID)
+		assert.Equal(t, "555", event.CampaignID)
+		assert.Equal(t, int64(12000), event.Metrics.Impressions)
+		assert.Equal(t, int64(96500000), event.Metrics.CostMicros)
+		assert.Equal(t, 12.5, event.Metrics.Conversions)
+		assert.Equal(t, "2024-03-14", event.Metrics.EndDate)
+	case <-time.After(time.Second):
+		t.Fatal("campaign metrics event was not received")
+	}
+}
+
+func TestProjectROIUpdates(t *testing.T) {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	server := natsserver.RunServer(&opts)
+	t.Cleanup(server.Shutdown)
+
+	conn, err := Connect(server.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	updates := make(chan []byte, 1)
+	_, err = conn.SubscribeProjectROIUpdates("project-1", func(data []byte) {
+		updates <- data
+	})
+	require.NoError(t, err)
+
+	projects := make(chan string, 2)
+	_, err = conn.SubscribeAllProjectROIUpdates(func(projectID string) {
+		projects <- projectID
+	})
+	require.NoError(t, err)
+	require.NoError(t, conn.Flush())
+
+	require.NoError(t, conn.PublishProjectROIUpdate("project-2", map[string]float64{"roas": 1}))
+	require.NoError(t, conn.PublishProjectROIUpdate("project-1", map[string]float64{"roas": 2.5}))
+
+	select {
+	case data := <-updates:
+		assert.JSONEq(t, `{"roas": 2.5}`, string(data))
+	case <-time.After(time.Second):
+		t.Fatal("project ROI update was not received")
+	}
+
+	for _, want := range []string{"project-2", "project-1"} {
+		select {
+		case projectID := <-projects:
+			assert.Equal(t, want, projectID)
+		case <-time.After(time.Second):
+			t.Fatal("project ROI update was not received by the wildcard subscriber")
+		}
+	}
+}
+
+func TestSubscribeDeploymentStatusChanged(t *testing.T) {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	server := natsserver.RunServer(&opts)
+	t.Cleanup(server.Shutdown)
+
+	conn, err := Connect(server.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	deployments := make(chan *DeploymentStatusChangedEvent, 2)
+	_, err = conn.SubscribeDeploymentStatusChanged(func(event *DeploymentStatusChangedEvent) {
+		deployments <- event
+	})
+	require.NoError(t, err)
+
+	// Asset status changes on the same subject still reach their subscribers
+	statuses := make(chan *AssetStatusChangedEvent, 2)
+	_, err = conn.SubscribeAssetStatusChanged(func(event *AssetStatusChangedEvent) {
+		statuses <- event
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, conn.Publish("zamc.events.asset.status_changed", []byte(`{
+		"event_type": "asset.deployment_status_changed",
+		"asset_id": "asset-1",
+		"project_id": "project-1",
+		"platform": "meta",
+		"status": "failed",
+		"deployment_result": {
+			"status": "failed",
+			"platform_id": "ad-9",
+			"error": "quota exceeded",
+			"deployed_at": "2024-01-15T10:31:00Z",
+			"metrics": {"duration": 1500000000, "retry_count": 2}
+		}
+	}`)))
+	require.NoError(t, conn.Publish("zamc.events.asset.status_changed", []byte(`{
+		"event_type": "asset.status_changed",
+		"asset_id": "asset-1",
+		"project_id": "project-1",
+		"status": "failed"
+	}`)))
+
+	select {
+	case event := <-deployments:
+		assert.Equal(t, "asset-1", event.AssetID)
+		assert.Equal(t, "meta", event.Platform)
+		assert.Equal(t, "failed", event.DeploymentResult.Status)
+		assert.Equal(t, "ad-9", event.DeploymentResult.PlatformID)
+		assert.Equal(t, "quota exceeded", event.DeploymentResult.Error)
+		assert.Equal(t, 1500*time.Millisecond, event.DeploymentResult.Metrics.Duration)
+		assert.Equal(t, 2, event.DeploymentResult.Metrics.RetryCount)
+	case <-time.After(time.Second):
+		t.Fatal("deployment status change was not received")
+	}
+
+	select {
+	case event := <-statuses:
+		assert.Equal(t, "failed", event.Status)
+	case <-time.After(time.Second):
+		t.Fatal("asset status change was not received")
+	}
+
+	select {
+	case event := <-deployments:
+		t.Fatalf("unexpected event: %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSubscribeAssetStatusChanged_DecompressesEvents(t *testing.T) {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	server := natsserver.RunServer(&opts)
+	t.Cleanup(server.Shutdown)
+
+	conn, err := Connect(server.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	received := make(chan *AssetStatusChangedEvent, 1)
+	_, err = conn.SubscribeAssetStatusChanged(func(event *AssetStatusChangedEvent) {
+		received <- event
+	})
+	require.NoError(t, err)
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err = writer.Write([]byte(`{"event_type": "asset.status_changed", "asset_id": "asset-1", "status": "deployed"}`))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	msg := nats.NewMsg("zamc.events.asset.status_changed")
+	msg.Header.Set("Content-Encoding", "gzip")
+	msg.Data = compressed.Bytes()
+	require.NoError(t, conn.PublishMsg(msg))
+
+	select {
+	case event := <-received:
+		assert.Equal(t, "asset-1", event.AssetID)
+		assert.Equal(t, "deployed", event.Status)
+	case <-time.After(time.Second):
+		t.Fatal("compressed asset status change was not received")
+	}
+}
+
+func TestMessageData_RejectsOversizedData(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write(make([]byte, maxMessageSize+1))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	msg := nats.NewMsg("zamc.events.asset.status_changed")
+	msg.Header.Set("Content-Encoding", "gzip")
+	msg.Data = compressed.Bytes()
+
+	_, err = messageData(msg)
+	assert.ErrorContains(t, err, "decompressed message exceeds")
+}
+
+func TestSubscribeAssetStatusChanged(t *testing.T) {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	server := natsserver.RunServer(&opts)
+	t.Cleanup(server.Shutdown)
+
+	conn, err := Connect(server.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	received := make(chan *AssetStatusChangedEvent, 2)
+	_, err = conn.SubscribeAssetStatusChanged(func(event *AssetStatusChangedEvent) {
+		received <- event
+	})
+	require.NoError(t, err)
+
+	// Per-platform updates are skipped
+	require.NoError(t, conn.Publish("zamc.events.asset.status_changed", []byte(`{
+		"event_type": "asset.deployment_status_changed",
+		"asset_id": "asset-1",
+		"project_id": "project-1",
+		"platform": "google_ads",
+		"status": "deployed"
+	}`)))
+	require.NoError(t, conn.Publish("zamc.events.asset.status_changed", []byte(`{
+		"event_type": "asset.status_changed",
+		"asset_id": "asset-1",
+		"project_id": "project-1",
+		"status": "deployed",
+		"prev_status": "approved",
+		"timestamp": "2024-01-15T10:31:30Z"
+	}`)))
+
+	select {
+	case event := <-received:
+		assert.Equal(t, "asset-1", event.AssetID)
+		assert.Equal(t, "project-1", event.ProjectID)
+		assert.Equal(t, "deployed", event.Status)
+		assert.Equal(t, "approved", event.PrevStatus)
+		assert.Equal(t, time.Date(2024, 1, 15, 10, 31, 30, 0, time.UTC), event.Timestamp)
+	case <-time.After(time.Second):
+		t.Fatal("asset status change was not received")
+	}
+
+	select {
+	case event := <-received:
+		t.Fatalf("unexpected event: %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRequestDeploymentRollback(t *testing.T) {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	server := natsserver.RunServer(&opts)
+	t.Cleanup(server.Shutdown)
+
+	conn, err := Connect(server.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	_, err = conn.Subscribe("zamc.commands.deployment.rollback", func(msg *nats.Msg) {
+		var request DeploymentRollbackRequest
+		require.NoError(t, json.Unmarshal(msg.Data, &request))
+
+		if request.Platform != "meta" {
+			msg.Respond([]byte(`{"error": "campaign deployment not found"}`))
+			return
+		}
+		msg.Respond([]byte(`{
+			"asset_id": "` + request.AssetID + `",
+			"platform": "meta",
+			"platform_campaign_id": "120200000000001",
+			"platform_ad_id": "120200000000001",
+			"rolled_back_at": "2024-03-01T10:00:00Z"
+		}`))
+	})
+	require.NoError(t, err)
+
+	rollback, err := conn.RequestDeploymentRollback(context.Background(), DeploymentRollbackRequest{
+		AssetID:     "asset-1",
+		Platform:    "meta",
+		RequestedBy: "admin-1",
+	}, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "asset-1", rollback.AssetID)
+	assert.Equal(t, "120200000000001", rollback.PlatformAdID)
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), rollback.RolledBackAt)
+
+	_, err = conn.RequestDeploymentRollback(context.Background(), DeploymentRollbackRequest{
+		AssetID:  "asset-1",
+		Platform: "google_ads",
+	}, time.Second)
+	assert.EqualError(t, err, "campaign deployment not found")
+}
+
+func TestRequestCustomAudienceDeletion(t *testing.T) {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	server := natsserver.RunServer(&opts)
+	t.Cleanup(server.Shutdown)
+
+	conn, err := Connect(server.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	_, err = conn.Subscribe("zamc.commands.audience.delete", func(msg *nats.Msg) {
+		var request CustomAudienceDeletionRequest
+		require.NoError(t, json.Unmarshal(msg.Data, &request))
+
+		switch request.AssetID {
+		case "asset-1":
+			msg.Respond([]byte(`{"deleted": true}`))
+		case "asset-2":
+			msg.Respond([]byte(`{"deleted": false}`))
+		default:
+			msg.Respond([]byte(`{"error": "custom audience deletions are not configured"}`))
+		}
+	})
+	require.NoError(t, err)
+
+	deleted, err := conn.RequestCustomAudienceDeletion(context.Background(), CustomAudienceDeletionRequest{AssetID: "asset-1", RequestedBy: "admin-1"}, time.Second)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = conn.RequestCustomAudienceDeletion(context.Background(), CustomAudienceDeletionRequest{AssetID: "asset-2"}, time.Second)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	_, err = conn.RequestCustomAudienceDeletion(context.Background(), CustomAudienceDeletionRequest{AssetID: "asset-3"}, time.Second)
+	assert.EqualError(t, err, "custom audience deletions are not configured")
+}
+
+func TestRequestStorePlatformCredentials_SealsCredentials(t *testing.T) {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	server := natsserver.RunServer(&opts)
+	t.Cleanup(server.Shutdown)
+
+	conn, err := Connect(server.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	request := PlatformCredentialsRequest{
+		TenantID:    "tenant-1",
+		Platform:    "meta",
+		Credentials: PlatformCredentials{AppSecret: "app-secret", AccessToken: "access-token"},
+	}
+
+	// Credentials are never sent without a transport key
+	assert.Error(t, conn.RequestStorePlatformCredentials(context.Background(), request, time.Second))
+
+	key := make([]byte, 32)
+	_, err = rand.Read(key)
+	require.NoError(t, err)
+	require.NoError(t, conn.UseCredentialsTransportKey(base64.StdEncoding.EncodeToString(key)))
+
+	received := make(chan []byte, 1)
+	_, err = conn.Subscribe("zamc.commands.credentials.store", func(msg *nats.Msg) {
+		received <- msg.Data
+		msg.Respond([]byte(`{}`))
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, conn.RequestStorePlatformCredentials(context.Background(), request, time.Second))
+
+	data := <-received
+	assert.NotContains(t, string(data), "app-secret")
+	assert.NotContains(t, string(data), "access-token")
+
+	var message platformCredentialsMessage
+	require.NoError(t, json.Unmarshal(data, &message))
+	assert.Equal(t, "tenant-1", message.TenantID)
+
+	// The connectors service opens them with the same key, for the same tenant and platform
+	sealed, err := base64.StdEncoding.DecodeString(message.EncryptedCredentials)
+	require.NoError(t, err)
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte("tenant-1:meta"))
+	require.NoError(t, err)
+
+	var credentials PlatformCredentials
+	require.NoError(t, json.Unmarshal(plaintext, &credentials))
+	assert.Equal(t, request.Credentials, credentials)
+}
@@ -0,0 +1,204 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/config"
+)
+
+// FlowTTL is how long a user has to log in with the provider once a flow started
+const FlowTTL = 5 * time.Minute
+
+const keyPrefix = "oauth:pkce:"
+
+// ErrInvalidState is returned when the state of a callback does not belong to a
+// flow started by the BFF, or the flow expired or was already completed
+var ErrInvalidState = errors.New("invalid or expired OAuth state")
+
+// Identity is the user the provider authenticated
+type Identity struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified *bool  `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+// PKCEFlow logs users in with an OAuth2 provider using the authorization code flow
+// with PKCE. The code verifier of each flow is kept in Redis under its state, a
+// random nonce that the provider echoes back to the callback, so that callbacks
+// the BFF did not initiate are rejected.
+//
+// The provider's endpoints are /authorize, /token and /userinfo under its URL.
+type PKCEFlow struct {
+	cfg        config.PKCEConfig
+	client     redis.UniversalClient
+	httpClient *http.Client
+}
+
+// NewPKCEFlow creates a PKCE flow for the provider configured in cfg
+func NewPKCEFlow(cfg config.PKCEConfig, client redis.UniversalClient, httpClient *http.Client) *PKCEFlow {
+	cfg.ProviderURL = strings.TrimSuffix(cfg.ProviderURL, "/")
+	return &PKCEFlow{
+		cfg:        cfg,
+		client:     client,
+		httpClient: httpClient,
+	}
+}
+
+// Start begins a flow and returns the provider URL to redirect the user to
+func (f *PKCEFlow) Start(ctx context.Context) (string, error) {
+	verifier, err := randomToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate code verifier: %w", err)
+	}
+	state, err := randomToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate state: %w", err)
+	}
+
+	if err := f.client.Set(ctx, keyPrefix+state, verifier, FlowTTL).Err(); err != nil {
+		return "", fmt.Errorf("failed to store code verifier: %w", err)
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", f.cfg.ClientID)
+	query.Set("redirect_uri", f.cfg.RedirectURI)
+	query.Set("scope", strings.Join(f.cfg.Scopes, " "))
+	query.Set("state", state)
+	query.Set("code_challenge", CodeChallenge(verifier))
+	query.Set("code_challenge_method", "S256")
+
+	return f.cfg.ProviderURL + "/authorize?" + query.Encode(), nil
+}
+
+// Exchange completes the flow of state by exchanging code for the provider's
+// tokens and returns the identity of the user. A state can only be used once.
+func (f *PKCEFlow) Exchange(ctx context.Context, state, code string) (*Identity, error) {
+	if state == "" {
+		return nil, ErrInvalidState
+	}
+
+	verifier, err := f.client.GetDel(ctx, keyPrefix+state).Result()
+	if err == redis.Nil {
+		return nil, ErrInvalidState
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load code verifier: %w", err)
+	}
+
+	accessToken, err := f.exchangeCode(ctx, code, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := f.userInfo(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if identity.Email == "" {
+		return nil, fmt.Errorf("provider did not return an email address")
+	}
+	// The user is found by email, so an address the provider did not verify
+	// could take over the account of its owner
+	if identity.EmailVerified == nil || !*identity.EmailVerified {
+		return nil, fmt.Errorf("email address %s is not verified", identity.Email)
+	}
+
+	return identity, nil
+}
+
+// exchangeCode redeems the authorization code with its verifier and returns the
+// provider's access token
+func (f *PKCEFlow) exchangeCode(ctx context.Context, code, verifier string) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", f.cfg.RedirectURI)
+	form.Set("client_id", f.cfg.ClientID)
+	form.Set("code_verifier", verifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.cfg.ProviderURL+"/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+	}
+	if err := f.do(req, &tokens); err != nil {
+		return "", fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	if tokens.AccessToken == "" {
+		return "", fmt.Errorf("failed to exchange authorization code: no access token returned")
+	}
+
+	return tokens.AccessToken, nil
+}
+
+// userInfo returns the identity of the user the access token was issued to
+func (f *PKCEFlow) userInfo(ctx context.Context, accessToken string) (*Identity, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.cfg.ProviderURL+"/userinfo", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user info request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	var identity Identity
+	if err := f.do(req, &identity); err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	return &identity, nil
+}
+
+// do sends req and decodes its JSON response into v
+func (f *PKCEFlow) do(req *http.Request, v interface{}) error {
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("provider returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid provider response: %w", err)
+	}
+	return nil
+}
+
+// CodeChallenge returns the S256 code challenge of verifier
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// randomToken returns 32 random bytes encoded for use in URLs. As a code verifier
+// it is 43 characters long, the minimum RFC 7636 allows.
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
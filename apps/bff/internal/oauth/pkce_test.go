@@ -0,0 +1,155 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/config"
+)
+
+// fakeProvider is an OAuth2 provider that issues tokens for the code "auth-code"
+// when the verifier matches the challenge of the authorization request
+type fakeProvider struct {
+	challenge string
+	userInfo  map[string]interface{}
+}
+
+func newTestFlow(t *testing.T, provider *fakeProvider) (*PKCEFlow, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			require.NoError(t, r.ParseForm())
+			if r.PostForm.Get("code") != "auth-code" || CodeChallenge(r.PostForm.Get("code_verifier")) != provider.challenge {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error": "invalid_grant"}`))
+				return
+			}
+			assert.Equal(t, "authorization_code", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "bff-client", r.PostForm.Get("client_id"))
+			assert.Equal(t, "http://localhost:8080/auth/callback", r.PostForm.Get("redirect_uri"))
+			w.Write([]byte(`{"access_token": "provider-token", "token_type": "Bearer"}`))
+		case "/userinfo":
+			assert.Equal(t, "Bearer provider-token", r.Header.Get("Authorization"))
+			json.NewEncoder(w).Encode(provider.userInfo)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	redisServer := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewPKCEFlow(config.PKCEConfig{
+		ProviderURL: server.URL + "/",
+		ClientID:    "bff-client",
+		RedirectURI: "http://localhost:8080/auth/callback",
+		Scopes:      []string{"openid", "email"},
+	}, client, server.Client()), redisServer
+}
+
+// start begins a flow and records its challenge with the provider, returning the
+// query of the authorization URL
+func start(t *testing.T, flow *PKCEFlow, provider *fakeProvider) url.Values {
+	t.Helper()
+
+	authorizeURL, err := flow.Start(context.Background())
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(authorizeURL)
+	require.NoError(t, err)
+	assert.Equal(t, "/authorize", parsed.Path)
+
+	query := parsed.Query()
+	provider.challenge = query.Get("code_challenge")
+	return query
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// Example from RFC 7636 appendix B
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
+
+func TestPKCEFlow_Login(t *testing.T) {
+	provider := &fakeProvider{userInfo: map[string]interface{}{
+		"sub": "provider-user-1", "email": "jane@example.com", "email_verified": true, "name": "Jane",
+	}}
+	flow, redisServer := newTestFlow(t, provider)
+
+	query := start(t, flow, provider)
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "bff-client", query.Get("client_id"))
+	assert.Equal(t, "http://localhost:8080/auth/callback", query.Get("redirect_uri"))
+	assert.Equal(t, "openid email", query.Get("scope"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Len(t, query.Get("state"), 43)
+
+	// The verifier is kept for five minutes under the state
+	assert.Equal(t, FlowTTL, redisServer.TTL(keyPrefix+query.Get("state")))
+
+	identity, err := flow.Exchange(context.Background(), query.Get("state"), "auth-code")
+	require.NoError(t, err)
+	assert.Equal(t, "provider-user-1", identity.Subject)
+	assert.Equal(t, "jane@example.com", identity.Email)
+	assert.Equal(t, "Jane", identity.Name)
+
+	// States are single-use
+	_, err = flow.Exchange(context.Background(), query.Get("state"), "auth-code")
+	assert.ErrorIs(t, err, ErrInvalidState)
+}
+
+func TestPKCEFlow_RejectsUnknownState(t *testing.T) {
+	provider := &fakeProvider{}
+	flow, redisServer := newTestFlow(t, provider)
+
+	_, err := flow.Exchange(context.Background(), "forged-state", "auth-code")
+	assert.ErrorIs(t, err, ErrInvalidState)
+
+	_, err = flow.Exchange(context.Background(), "", "auth-code")
+	assert.ErrorIs(t, err, ErrInvalidState)
+
+	// Flows expire
+	query := start(t, flow, provider)
+	redisServer.FastForward(FlowTTL)
+	_, err = flow.Exchange(context.Background(), query.Get("state"), "auth-code")
+	assert.ErrorIs(t, err, ErrInvalidState)
+}
+
+func TestPKCEFlow_ProviderErrors(t *testing.T) {
+	provider := &fakeProvider{userInfo: map[string]interface{}{
+		"sub": "provider-user-1", "email": "jane@example.com", "email_verified": false,
+	}}
+	flow, _ := newTestFlow(t, provider)
+
+	// Codes are only redeemed with the verifier of the flow
+	query := start(t, flow, provider)
+	provider.challenge = CodeChallenge("another-verifier")
+	_, err := flow.Exchange(context.Background(), query.Get("state"), "auth-code")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid_grant")
+
+	// Unverified emails cannot be used to log in
+	query = start(t, flow, provider)
+	_, err = flow.Exchange(context.Background(), query.Get("state"), "auth-code")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not verified")
+
+	// Nor can emails the provider does not say are verified
+	delete(provider.userInfo, "email_verified")
+	query = start(t, flow, provider)
+	_, err = flow.Exchange(context.Background(), query.Get("state"), "auth-code")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not verified")
+}
@@ -0,0 +1,70 @@
+package quality
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FleschKincaidGrade returns the Flesch-Kincaid grade level of text: the years
+// of schooling needed to understand it, from its words per sentence and
+// syllables per word. Text without words has a grade of 0.
+func FleschKincaidGrade(text string) float64 {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	if len(words) == 0 {
+		return 0
+	}
+
+	syllables := 0
+	for _, word := range words {
+		syllables += countSyllables(word)
+	}
+
+	return 0.39*float64(len(words))/float64(countSentences(text)) +
+		11.8*float64(syllables)/float64(len(words)) - 15.59
+}
+
+// countSentences counts the runs of sentence-ending punctuation in text, and the
+// last sentence when it is not terminated
+func countSentences(text string) int {
+	sentences := 0
+	inSentence := false
+	for _, r := range text {
+		switch {
+		case r == '.' || r == '!' || r == '?':
+			if inSentence {
+				sentences++
+			}
+			inSentence = false
+		case unicode.IsLetter(r):
+			inSentence = true
+		}
+	}
+	if inSentence {
+		sentences++
+	}
+	return max(sentences, 1)
+}
+
+// countSyllables estimates the syllables of an English word as its groups of
+// vowels, not counting a silent final e
+func countSyllables(word string) int {
+	word = strings.ToLower(strings.Trim(word, "'"))
+
+	syllables := 0
+	previousVowel := false
+	for _, r := range word {
+		vowel := strings.ContainsRune("aeiouy", r)
+		if vowel && !previousVowel {
+			syllables++
+		}
+		previousVowel = vowel
+	}
+
+	// "make" has one syllable, but "table" has two
+	if syllables > 1 && strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") {
+		syllables--
+	}
+	return max(syllables, 1)
+}
@@ -0,0 +1,39 @@
+package quality
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFleschKincaidGrade(t *testing.T) {
+	// 6 words, 1 sentence, 6 syllables
+	assert.InDelta(t, -1.45, FleschKincaidGrade("The cat sat on the mat."), 0.01)
+	// Longer sentences of longer words need more schooling
+	assert.Greater(t,
+		FleschKincaidGrade("Unprecedented organizational transformation necessitates comprehensive stakeholder collaboration."),
+		FleschKincaidGrade("Buy now. Save big. Ships today."),
+	)
+	assert.Equal(t, 0.0, FleschKincaidGrade(""))
+	assert.Equal(t, 0.0, FleschKincaidGrade("!!! 123"))
+}
+
+func TestCountSentences(t *testing.T) {
+	assert.Equal(t, 3, countSentences("Buy now. Save big! Really?!"))
+	assert.Equal(t, 2, countSentences("Summer sale. Ends Sunday"))
+	assert.Equal(t, 1, countSentences("..."))
+}
+
+func TestCountSyllables(t *testing.T) {
+	for word, syllables := range map[string]int{
+		"cat":       1,
+		"make":      1,
+		"table":     2,
+		"the":       1,
+		"beautiful": 3,
+		"rhythm":    1,
+		"don't":     1,
+	} {
+		assert.Equal(t, syllables, countSyllables(word), word)
+	}
+}
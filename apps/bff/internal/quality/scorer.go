@@ -0,0 +1,210 @@
+// Package quality scores how compelling the copy of an asset is: its readability
+// is computed locally and its sentiment, keyword density and overall score come
+// from an external text analysis API. Scores are cached in Redis.
+package quality
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// CacheTTL is how long a score is cached
+const CacheTTL = time.Hour
+
+// Sentiments of the copy of an asset
+const (
+	SentimentPositive = "positive"
+	SentimentNeutral  = "neutral"
+	SentimentNegative = "negative"
+)
+
+// Score estimates how compelling the copy of an asset is
+type Score struct {
+	// Readability is the Flesch-Kincaid grade level of the copy
+	Readability float64 `json:"readability"`
+
+	Sentiment      string  `json:"sentiment"`
+	KeywordDensity float64 `json:"keyword_density"`
+	OverallScore   float64 `json:"overall_score"`
+}
+
+// Analysis is the response of the text analysis API
+type Analysis struct {
+	Sentiment      string  `json:"sentiment"`
+	KeywordDensity float64 `json:"keyword_density"`
+	OverallScore   float64 `json:"overall_score"`
+}
+
+// Analyzer analyzes the copy of assets
+type Analyzer interface {
+	Analyze(ctx context.Context, text string) (*Analysis, error)
+}
+
+// Client calls the text analysis API at its endpoint, authenticated with an API key
+type Client struct {
+	httpClient *http.Client
+	endpoint   string
+	apiKey     string
+}
+
+// NewClient creates a client posting texts to endpoint with apiKey
+func NewClient(httpClient *http.Client, endpoint, apiKey string) *Client {
+	return &Client{httpClient: httpClient, endpoint: endpoint, apiKey: apiKey}
+}
+
+// Analyze posts text to the API and returns its analysis
+func (c *Client) Analyze(ctx context.Context, text string) (*Analysis, error) {
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal text: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("quality score request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("quality score API responded with status %d", resp.StatusCode)
+	}
+
+	var analysis Analysis
+	if err := json.NewDecoder(resp.Body).Decode(&analysis); err != nil {
+		return nil, fmt.Errorf("failed to decode quality score: %w", err)
+	}
+
+	switch analysis.Sentiment {
+	case SentimentPositive, SentimentNeutral, SentimentNegative:
+	default:
+		return nil, fmt.Errorf("quality score API returned unknown sentiment %q", analysis.Sentiment)
+	}
+
+	return &analysis, nil
+}
+
+// cachedScore is the score of an asset as it was when last updated at UpdatedAt
+type cachedScore struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	Score     *Score    `json:"score"`
+}
+
+// Scorer scores the copy of assets, caching the scores in Redis under
+// quality:{assetID}. A cached score is only used for the version of the asset it
+// was computed for, so updating an asset invalidates its score.
+type Scorer struct {
+	analyzer    Analyzer
+	redisClient redis.UniversalClient
+}
+
+// NewScorer creates a scorer analyzing copy with analyzer. Scores are not cached
+// when redisClient is nil.
+func NewScorer(analyzer Analyzer, redisClient redis.UniversalClient) *Scorer {
+	return &Scorer{analyzer: analyzer, redisClient: redisClient}
+}
+
+func cacheKey(assetID string) string {
+	return "quality:" + assetID
+}
+
+// Score returns the score of text, the copy of the asset assetID as last
+// updated at updatedAt
+func (s *Scorer) Score(ctx context.Context, assetID string, updatedAt time.Time, text string) (*Score, error) {
+	if score := s.cached(ctx, assetID, updatedAt); score != nil {
+		return score, nil
+	}
+
+	analysis, err := s.analyzer.Analyze(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	score := &Score{
+		Readability:    FleschKincaidGrade(text),
+		Sentiment:      analysis.Sentiment,
+		KeywordDensity: analysis.KeywordDensity,
+		OverallScore:   analysis.OverallScore,
+	}
+	s.cache(ctx, assetID, updatedAt, score)
+
+	return score, nil
+}
+
+// Cached returns the cached score of the asset assetID as last updated at
+// updatedAt, or nil when it has not been scored since
+func (s *Scorer) Cached(ctx context.Context, assetID string, updatedAt time.Time) *Score {
+	return s.cached(ctx, assetID, updatedAt)
+}
+
+// Claim reserves the scoring of the asset assetID as last updated at updatedAt
+// for ttl and reports whether no replica had reserved it. Without Redis, scores
+// cannot be read back and it reports false.
+func (s *Scorer) Claim(ctx context.Context, assetID string, updatedAt time.Time, ttl time.Duration) bool {
+	if s.redisClient == nil {
+		return false
+	}
+
+	key := fmt.Sprintf("quality_scoring:%s:%d", assetID, updatedAt.UnixNano())
+	claimed, err := s.redisClient.SetNX(ctx, key, 1, ttl).Result()
+	if err != nil {
+		log.Printf("Failed to claim quality scoring of asset %s: %v", assetID, err)
+		return false
+	}
+	return claimed
+}
+
+// cached returns the cached score of the asset assetID as last updated at
+// updatedAt, or nil
+func (s *Scorer) cached(ctx context.Context, assetID string, updatedAt time.Time) *Score {
+	if s.redisClient == nil {
+		return nil
+	}
+
+	data, err := s.redisClient.Get(ctx, cacheKey(assetID)).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			log.Printf("Failed to read quality score of asset %s: %v", assetID, err)
+		}
+		return nil
+	}
+
+	var cached cachedScore
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Score == nil {
+		return nil
+	}
+	if !cached.UpdatedAt.Equal(updatedAt) {
+		return nil
+	}
+	return cached.Score
+}
+
+// cache stores the score of the asset assetID as last updated at updatedAt
+func (s *Scorer) cache(ctx context.Context, assetID string, updatedAt time.Time, score *Score) {
+	if s.redisClient == nil {
+		return
+	}
+
+	data, err := json.Marshal(cachedScore{UpdatedAt: updatedAt, Score: score})
+	if err != nil {
+		return
+	}
+	if err := s.redisClient.Set(ctx, cacheKey(assetID), data, CacheTTL).Err(); err != nil {
+		log.Printf("Failed to cache quality score of asset %s: %v", assetID, err)
+	}
+}
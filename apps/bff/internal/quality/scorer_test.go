@@ -0,0 +1,129 @@
+package quality
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAPI starts a text analysis API answering with analysis and counting its
+// requests
+func newTestAPI(t *testing.T, analysis Analysis) (*Client, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("X-API-Key") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["text"] == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(analysis)
+	}))
+	t.Cleanup(server.Close)
+
+	return NewClient(server.Client(), server.URL, "test-key"), &requests
+}
+
+func TestClient_Analyze(t *testing.T) {
+	client, _ := newTestAPI(t, Analysis{Sentiment: SentimentPositive, KeywordDensity: 0.12, OverallScore: 81})
+
+	analysis, err := client.Analyze(context.Background(), "Summer sale starts today.")
+	require.NoError(t, err)
+	assert.Equal(t, SentimentPositive, analysis.Sentiment)
+	assert.Equal(t, 0.12, analysis.KeywordDensity)
+	assert.Equal(t, 81.0, analysis.OverallScore)
+
+	client.apiKey = "wrong-key"
+	_, err = client.Analyze(context.Background(), "Summer sale starts today.")
+	assert.Error(t, err)
+}
+
+func TestClient_AnalyzeRejectsUnknownSentiment(t *testing.T) {
+	client, _ := newTestAPI(t, Analysis{Sentiment: "ecstatic"})
+
+	_, err := client.Analyze(context.Background(), "Summer sale starts today.")
+	assert.Error(t, err)
+}
+
+func TestScorer_Score(t *testing.T) {
+	client, requests := newTestAPI(t, Analysis{Sentiment: SentimentNeutral, KeywordDensity: 0.05, OverallScore: 64})
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { redisClient.Close() })
+
+	scorer := NewScorer(client, redisClient)
+	ctx := context.Background()
+	updatedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	score, err := scorer.Score(ctx, "asset-1", updatedAt, "The cat sat on the mat.")
+	require.NoError(t, err)
+	assert.InDelta(t, -1.45, score.Readability, 0.01)
+	assert.Equal(t, SentimentNeutral, score.Sentiment)
+	assert.Equal(t, 64.0, score.OverallScore)
+	assert.True(t, server.Exists("quality:asset-1"))
+	assert.Equal(t, CacheTTL, server.TTL("quality:asset-1"))
+
+	// The cached score is used until the asset is updated
+	cached, err := scorer.Score(ctx, "asset-1", updatedAt, "The cat sat on the mat.")
+	require.NoError(t, err)
+	assert.Equal(t, score, cached)
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+
+	_, err = scorer.Score(ctx, "asset-1", updatedAt.Add(time.Minute), "The dog sat on the mat.")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+}
+
+func TestScorer_CachedAndClaim(t *testing.T) {
+	client, requests := newTestAPI(t, Analysis{Sentiment: SentimentPositive})
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { redisClient.Close() })
+
+	scorer := NewScorer(client, redisClient)
+	ctx := context.Background()
+	updatedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Reading the cache never calls the API
+	assert.Nil(t, scorer.Cached(ctx, "asset-1", updatedAt))
+	assert.Equal(t, int32(0), atomic.LoadInt32(requests))
+
+	// One scoring of each version of an asset is claimed at a time
+	assert.True(t, scorer.Claim(ctx, "asset-1", updatedAt, time.Minute))
+	assert.False(t, scorer.Claim(ctx, "asset-1", updatedAt, time.Minute))
+	assert.True(t, scorer.Claim(ctx, "asset-1", updatedAt.Add(time.Minute), time.Minute))
+
+	_, err := scorer.Score(ctx, "asset-1", updatedAt, "Summer sale starts today.")
+	require.NoError(t, err)
+	cached := scorer.Cached(ctx, "asset-1", updatedAt)
+	require.NotNil(t, cached)
+	assert.Equal(t, SentimentPositive, cached.Sentiment)
+
+	assert.False(t, NewScorer(client, nil).Claim(ctx, "asset-1", updatedAt, time.Minute))
+}
+
+func TestScorer_ScoreWithoutRedis(t *testing.T) {
+	client, requests := newTestAPI(t, Analysis{Sentiment: SentimentNegative})
+	scorer := NewScorer(client, nil)
+
+	for i := 0; i < 2; i++ {
+		score, err := scorer.Score(context.Background(), "asset-1", time.Now(), "Sold out.")
+		require.NoError(t, err)
+		assert.Equal(t, SentimentNegative, score.Sentiment)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+}
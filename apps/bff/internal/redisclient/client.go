@@ -0,0 +1,153 @@
+package redisclient
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisClientInterface is the set of Redis commands the auth service and the
+// security middleware use. *redis.Client implements it for a single node or
+// Sentinel, and ClusterClient for a Redis Cluster.
+type RedisClientInterface interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+	Incr(ctx context.Context, key string) *redis.IntCmd
+	Expire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd
+	Publish(ctx context.Context, channel string, message interface{}) *redis.IntCmd
+	Subscribe(ctx context.Context, channels ...string) *redis.PubSub
+	ZAdd(ctx context.Context, key string, members ...*redis.Z) *redis.IntCmd
+	ZRem(ctx context.Context, key string, members ...interface{}) *redis.IntCmd
+	ZRange(ctx context.Context, key string, start, stop int64) *redis.StringSliceCmd
+	ZRemRangeByScore(ctx context.Context, key, min, max string) *redis.IntCmd
+	ZCard(ctx context.Context, key string) *redis.IntCmd
+	Keys(ctx context.Context, pattern string) *redis.StringSliceCmd
+	Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd
+	TTL(ctx context.Context, key string) *redis.DurationCmd
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd
+}
+
+var (
+	_ RedisClientInterface = (*redis.Client)(nil)
+	_ RedisClientInterface = (*ClusterClient)(nil)
+)
+
+// ClusterClient runs the commands of RedisClientInterface on a Redis Cluster. A
+// cluster rejects commands on keys of several hash slots and only matches the keys
+// of one node, so Keys and Scan match the keys of every master and Del deletes
+// keys one by one.
+type ClusterClient struct {
+	*redis.ClusterClient
+}
+
+// NewClusterClient adapts client to RedisClientInterface
+func NewClusterClient(client *redis.ClusterClient) *ClusterClient {
+	return &ClusterClient{ClusterClient: client}
+}
+
+// New returns the RedisClientInterface of client, adapting cluster clients, or
+// nil when client is nil
+func New(client redis.UniversalClient) RedisClientInterface {
+	switch client := client.(type) {
+	case nil:
+		return nil
+	case *redis.ClusterClient:
+		return NewClusterClient(client)
+	default:
+		return client
+	}
+}
+
+// Keys returns the keys matching pattern on every master of the cluster
+func (c *ClusterClient) Keys(ctx context.Context, pattern string) *redis.StringSliceCmd {
+	cmd := redis.NewStringSliceCmd(ctx, "keys", pattern)
+
+	var mu sync.Mutex
+	keys := []string{}
+	err := c.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+		masterKeys, err := master.Keys(ctx, pattern).Result()
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, masterKeys...)
+		return nil
+	})
+	if err != nil {
+		cmd.SetErr(err)
+		return cmd
+	}
+
+	cmd.SetVal(keys)
+	return cmd
+}
+
+// Scan scans every master of the cluster to the end and returns all the keys
+// matching match with a zero cursor. Cursors of one node mean nothing to the
+// others, so only a zero cursor is accepted.
+func (c *ClusterClient) Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd {
+	cmd := redis.NewScanCmd(ctx, nil, "scan", cursor, "match", match, "count", count)
+	if cursor != 0 {
+		cmd.SetErr(fmt.Errorf("cluster scans do not support cursor %d", cursor))
+		return cmd
+	}
+
+	var mu sync.Mutex
+	keys := []string{}
+	err := c.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+		iter := master.Scan(ctx, 0, match, count).Iterator()
+		var masterKeys []string
+		for iter.Next(ctx) {
+			masterKeys = append(masterKeys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, masterKeys...)
+		return nil
+	})
+	if err != nil {
+		cmd.SetErr(err)
+		return cmd
+	}
+
+	cmd.SetVal(keys, 0)
+	return cmd
+}
+
+// Del deletes keys with one command per key, so that keys of different slots can
+// be deleted together, and returns the number of keys deleted
+func (c *ClusterClient) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	args := make([]interface{}, 0, len(keys)+1)
+	args = append(args, "del")
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	cmd := redis.NewIntCmd(ctx, args...)
+
+	pipe := c.Pipeline()
+	deletes := make([]*redis.IntCmd, len(keys))
+	for i, key := range keys {
+		deletes[i] = pipe.Del(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		cmd.SetErr(err)
+		return cmd
+	}
+
+	var deleted int64
+	for _, del := range deletes {
+		deleted += del.Val()
+	}
+	cmd.SetVal(deleted)
+	return cmd
+}
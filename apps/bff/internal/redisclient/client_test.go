@@ -0,0 +1,94 @@
+package redisclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCluster returns a cluster client whose slots are split between two nodes
+func newTestCluster(t *testing.T) (*redis.ClusterClient, *miniredis.Miniredis, *miniredis.Miniredis) {
+	t.Helper()
+
+	first := miniredis.RunT(t)
+	second := miniredis.RunT(t)
+
+	client := redis.NewClusterClient(&redis.ClusterOptions{
+		ClusterSlots: func(ctx context.Context) ([]redis.ClusterSlot, error) {
+			return []redis.ClusterSlot{
+				{Start: 0, End: 8191, Nodes: []redis.ClusterNode{{Addr: first.Addr()}}},
+				{Start: 8192, End: 16383, Nodes: []redis.ClusterNode{{Addr: second.Addr()}}},
+			}, nil
+		},
+	})
+	t.Cleanup(func() { client.Close() })
+
+	return client, first, second
+}
+
+func TestNew(t *testing.T) {
+	assert.Nil(t, New(nil))
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	defer client.Close()
+	assert.Same(t, client, New(client))
+
+	cluster, _, _ := newTestCluster(t)
+	assert.IsType(t, &ClusterClient{}, New(cluster))
+}
+
+func TestClusterClient_KeysMatchesEveryMaster(t *testing.T) {
+	cluster, first, second := newTestCluster(t)
+	client := NewClusterClient(cluster)
+	ctx := context.Background()
+
+	// refresh_token:user-1:a hashes to slot 125 and refresh_token:user-1:c to 8255
+	for _, key := range []string{"refresh_token:user-1:a", "refresh_token:user-1:c", "refresh_token:user-2:a"} {
+		require.NoError(t, client.Set(ctx, key, "valid", 0).Err())
+	}
+	require.True(t, first.Exists("refresh_token:user-1:a"))
+	require.True(t, second.Exists("refresh_token:user-1:c"))
+
+	keys, err := client.Keys(ctx, "refresh_token:user-1:*").Result()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"refresh_token:user-1:a", "refresh_token:user-1:c"}, keys)
+}
+
+func TestClusterClient_DelAcrossSlots(t *testing.T) {
+	cluster, first, second := newTestCluster(t)
+	client := NewClusterClient(cluster)
+	ctx := context.Background()
+
+	require.NoError(t, client.Set(ctx, "refresh_token:user-1:a", "valid", 0).Err())
+	require.NoError(t, client.Set(ctx, "refresh_token:user-1:c", "valid", 0).Err())
+
+	deleted, err := client.Del(ctx, "refresh_token:user-1:a", "refresh_token:user-1:c", "refresh_token:user-1:missing").Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	assert.False(t, first.Exists("refresh_token:user-1:a"))
+	assert.False(t, second.Exists("refresh_token:user-1:c"))
+}
+
+func TestClusterClient_ScanMatchesEveryMaster(t *testing.T) {
+	cluster, first, second := newTestCluster(t)
+	client := NewClusterClient(cluster)
+	ctx := context.Background()
+
+	for _, key := range []string{"refresh_token:user-1:a", "refresh_token:user-1:c", "refresh_token:user-2:a"} {
+		require.NoError(t, client.Set(ctx, key, "valid", 0).Err())
+	}
+	require.True(t, first.Exists("refresh_token:user-1:a"))
+	require.True(t, second.Exists("refresh_token:user-1:c"))
+
+	keys, cursor, err := client.Scan(ctx, 0, "refresh_token:user-1:*", 10).Result()
+	require.NoError(t, err)
+	assert.Zero(t, cursor)
+	assert.ElementsMatch(t, []string{"refresh_token:user-1:a", "refresh_token:user-1:c"}, keys)
+
+	_, _, err = client.Scan(ctx, 42, "refresh_token:user-1:*", 10).Result()
+	assert.Error(t, err)
+}
@@ -0,0 +1,118 @@
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrNonPublicAddress is returned for requests to loopback, private, link-local
+// and other addresses that are not reachable from the internet
+var ErrNonPublicAddress = errors.New("address is not public")
+
+// reservedNetworks are the special-purpose ranges not covered by the net.IP
+// predicates that must not be reached either
+var reservedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",      // "this" network
+	"100.64.0.0/10",  // carrier-grade NAT
+	"192.0.0.0/24",   // IETF protocol assignments
+	"198.18.0.0/15",  // benchmarking
+	"240.0.0.0/4",    // reserved, including broadcast
+	"64:ff9b::/96",   // NAT64, which may map to private IPv4 addresses
+	"64:ff9b:1::/48", // local-use NAT64
+	"2002::/16",      // 6to4, which embeds IPv4 addresses
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// IsPublicIP returns true if ip is a globally routable unicast address
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateURL checks that rawURL is an absolute http or https URL whose host is
+// not a non-public IP address or a local name. Hostnames are not resolved, as
+// they may resolve differently later; clients from NewClient check the
+// addresses they connect to.
+func ValidateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("must be an absolute http or https URL")
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if ip := net.ParseIP(host); ip != nil {
+		if !IsPublicIP(ip) {
+			return fmt.Errorf("%s: %w", host, ErrNonPublicAddress)
+		}
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || !strings.Contains(host, ".") {
+		return fmt.Errorf("%s: %w", host, ErrNonPublicAddress)
+	}
+	return nil
+}
+
+// checkAddress refuses connections to non-public addresses. The dialer calls it
+// with each address a host resolves to, right before connecting, so a host
+// cannot resolve to a public address when validated and a private one after.
+func checkAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if !IsPublicIP(net.ParseIP(host)) {
+		return fmt.Errorf("%s: %w", host, ErrNonPublicAddress)
+	}
+	return nil
+}
+
+// NewClient creates an HTTP client for URLs given by users, such as webhooks and
+// asset files. It only connects to public addresses, ignores proxy settings and
+// does not follow redirects, which could otherwise lead to internal services.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   checkAddress,
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,70 @@
+package safehttp
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	for _, ip := range []string{"93.184.216.34", "8.8.8.8", "2606:4700::1111"} {
+		assert.True(t, IsPublicIP(net.ParseIP(ip)), ip)
+	}
+
+	for _, ip := range []string{
+		"127.0.0.1", "10.0.0.1", "172.16.5.4", "192.168.1.1", "169.254.169.254", "100.64.0.1",
+		"0.0.0.0", "255.255.255.255", "224.0.0.1", "::1", "fe80::1", "fd00::1", "::ffff:127.0.0.1", "64:ff9b::a00:1",
+	} {
+		assert.False(t, IsPublicIP(net.ParseIP(ip)), ip)
+	}
+	assert.False(t, IsPublicIP(nil))
+}
+
+func TestValidateURL(t *testing.T) {
+	assert.NoError(t, ValidateURL("https://hooks.example.com/zamc"))
+	assert.NoError(t, ValidateURL("http://203.0.113.10:8080/hook"))
+
+	for _, rawURL := range []string{"", "ftp://example.com", "https://", "/hook"} {
+		assert.Error(t, ValidateURL(rawURL), rawURL)
+	}
+	for _, rawURL := range []string{
+		"http://localhost/hook", "http://LOCALHOST./hook", "http://app.localhost/hook", "http://redis:6379",
+		"http://127.0.0.1/hook", "http://[::1]:8080/hook", "http://169.254.169.254/latest/meta-data",
+	} {
+		assert.True(t, errors.Is(ValidateURL(rawURL), ErrNonPublicAddress), rawURL)
+	}
+}
+
+func TestNewClient_RefusesLoopback(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer server.Close()
+
+	_, err := NewClient(time.Second).Get(server.URL)
+	assert.True(t, errors.Is(err, ErrNonPublicAddress), err)
+	assert.Zero(t, calls)
+}
+
+func TestNewClient_DoesNotFollowRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+	}))
+	defer server.Close()
+
+	// The redirect policy is tested apart from the dialer, which refuses loopback
+	client := NewClient(time.Second)
+	client.Transport = http.DefaultTransport
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+}
@@ -0,0 +1,118 @@
+package sla
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+)
+
+// Monitor periodically escalates assets that have been waiting for review
+// longer than the SLA
+type Monitor struct {
+	db       *sql.DB
+	nats     *nats.Conn
+	slaHours int
+	interval time.Duration
+}
+
+// NewMonitor creates an SLA monitor that checks for overdue assets every interval
+func NewMonitor(db *sql.DB, natsConn *nats.Conn, slaHours int, interval time.Duration) *Monitor {
+	return &Monitor{
+		db:       db,
+		nats:     natsConn,
+		slaHours: slaHours,
+		interval: interval,
+	}
+}
+
+// Run checks for overdue assets immediately and then on every tick until ctx is done
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		if escalated, err := m.CheckOnce(ctx); err != nil {
+			log.Printf("Asset SLA check failed: %v", err)
+		} else if escalated > 0 {
+			log.Printf("Escalated %d assets past their review SLA", escalated)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckOnce publishes an SLA breach event for every overdue asset that has not
+// been escalated yet during its current review and returns how many were escalated
+func (m *Monitor) CheckOnce(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	// Wall-clock time is an upper bound for business hours, so this narrows the
+	// candidates without missing any overdue asset
+	rows, err := m.db.QueryContext(ctx, `
+		SELECT a.id, a.name, a.board_id, b.project_id, a.updated_at
+		FROM assets a
+		JOIN boards b ON a.board_id = b.id
+		LEFT JOIN asset_sla_escalations e
+			ON e.asset_id = a.id AND e.review_started_at = a.updated_at
+		WHERE a.status IN ('REVIEW', 'PENDING')
+			AND a.deleted_at IS NULL
+			AND a.updated_at < $1
+			AND e.asset_id IS NULL
+	`, now.Add(-time.Duration(m.slaHours)*time.Hour))
+	if err != nil {
+		return 0, fmt.Errorf("failed to query assets in review: %w", err)
+	}
+
+	var overdue []nats.AssetSLABreachEvent
+	for rows.Next() {
+		var event nats.AssetSLABreachEvent
+		if err := rows.Scan(&event.AssetID, &event.AssetName, &event.BoardID, &event.ProjectID, &event.ReviewStartedAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan asset: %w", err)
+		}
+
+		if IsOverdue(event.ReviewStartedAt, now, m.slaHours) {
+			overdue = append(overdue, event)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to iterate assets: %w", err)
+	}
+
+	escalated := 0
+	for _, event := range overdue {
+		event.EventType = "asset.sla_breach"
+		event.SLAHours = m.slaHours
+		event.OverdueHours = (BusinessHoursBetween(event.ReviewStartedAt, now) - time.Duration(m.slaHours)*time.Hour).Hours()
+		event.Timestamp = now
+
+		if err := m.nats.PublishAssetSLABreach(&event); err != nil {
+			log.Printf("Failed to publish SLA breach for asset %s: %v", event.AssetID, err)
+			continue
+		}
+
+		// Remember the escalation so the asset is not escalated again until it re-enters review
+		_, err := m.db.ExecContext(ctx, `
+			INSERT INTO asset_sla_escalations (asset_id, review_started_at, escalated_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (asset_id) DO UPDATE
+			SET review_started_at = EXCLUDED.review_started_at, escalated_at = EXCLUDED.escalated_at
+		`, event.AssetID, event.ReviewStartedAt, now)
+		if err != nil {
+			return escalated, fmt.Errorf("failed to record SLA escalation: %w", err)
+		}
+
+		escalated++
+	}
+
+	return escalated, nil
+}
@@ -0,0 +1,37 @@
+package sla
+
+import "time"
+
+// BusinessHoursBetween returns how much of the interval from start to end falls
+// on weekdays. Saturdays and Sundays (in UTC) do not count towards the SLA.
+func BusinessHoursBetween(start, end time.Time) time.Duration {
+	start, end = start.UTC(), end.UTC()
+
+	var total time.Duration
+	for cursor := start; cursor.Before(end); {
+		nextDay := time.Date(cursor.Year(), cursor.Month(), cursor.Day()+1, 0, 0, 0, 0, time.UTC)
+		segmentEnd := nextDay
+		if end.Before(segmentEnd) {
+			segmentEnd = end
+		}
+
+		if !isWeekend(cursor) {
+			total += segmentEnd.Sub(cursor)
+		}
+
+		cursor = segmentEnd
+	}
+
+	return total
+}
+
+// IsOverdue reports whether an asset that entered review at reviewStarted has
+// exceeded an SLA of slaHours business hours by now
+func IsOverdue(reviewStarted, now time.Time, slaHours int) bool {
+	return BusinessHoursBetween(reviewStarted, now) > time.Duration(slaHours)*time.Hour
+}
+
+func isWeekend(t time.Time) bool {
+	day := t.Weekday()
+	return day == time.Saturday || day == time.Sunday
+}
@@ -0,0 +1,68 @@
+package sla
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func utc(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+func TestBusinessHoursBetween(t *testing.T) {
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		want  time.Duration
+	}{
+		// 2024-01-15 is a Monday
+		{"same weekday", utc(2024, 1, 15, 9), utc(2024, 1, 15, 17), 8 * time.Hour},
+		{"across weekday midnight", utc(2024, 1, 15, 20), utc(2024, 1, 16, 4), 8 * time.Hour},
+		{"friday evening to monday morning", utc(2024, 1, 19, 18), utc(2024, 1, 22, 6), 12 * time.Hour},
+		{"entirely on weekend", utc(2024, 1, 20, 1), utc(2024, 1, 21, 23), 0},
+		{"saturday to monday", utc(2024, 1, 20, 12), utc(2024, 1, 22, 10), 10 * time.Hour},
+		{"full week", utc(2024, 1, 15, 0), utc(2024, 1, 22, 0), 5 * 24 * time.Hour},
+		{"end before start", utc(2024, 1, 16, 0), utc(2024, 1, 15, 0), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, BusinessHoursBetween(tt.start, tt.end))
+		})
+	}
+}
+
+func TestBusinessHoursBetween_NormalizesTimezone(t *testing.T) {
+	// Friday 23:00 in UTC-5 is already Saturday 04:00 UTC
+	est := time.FixedZone("EST", -5*60*60)
+	start := time.Date(2024, 1, 19, 23, 0, 0, 0, est)
+
+	assert.Equal(t, time.Duration(0), BusinessHoursBetween(start, utc(2024, 1, 21, 12)))
+}
+
+func TestIsOverdue(t *testing.T) {
+	tests := []struct {
+		name          string
+		reviewStarted time.Time
+		now           time.Time
+		want          bool
+	}{
+		{"within SLA on weekdays", utc(2024, 1, 15, 9), utc(2024, 1, 17, 8), false},
+		{"past SLA on weekdays", utc(2024, 1, 15, 9), utc(2024, 1, 17, 10), true},
+		// 48 wall-clock hours later but only 6 business hours elapsed
+		{"weekend does not count", utc(2024, 1, 19, 18), utc(2024, 1, 21, 18), false},
+		// Friday 18:00 plus 48 business hours is Tuesday 18:00
+		{"just before deadline after weekend", utc(2024, 1, 19, 18), utc(2024, 1, 23, 17), false},
+		{"just after deadline after weekend", utc(2024, 1, 19, 18), utc(2024, 1, 23, 19), true},
+		{"submitted on saturday", utc(2024, 1, 20, 10), utc(2024, 1, 24, 1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsOverdue(tt.reviewStarted, tt.now, 48))
+		})
+	}
+}
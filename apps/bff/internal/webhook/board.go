@@ -0,0 +1,251 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/safehttp"
+)
+
+// Events board webhooks subscribe to, besides EventAssetDeployed
+const (
+	EventAssetUploaded = "asset.uploaded"
+	EventAssetApproved = "asset.approved"
+	EventMessageSent   = "message.sent"
+)
+
+// DeliveryIDHeader identifies the delivery of an event to a board webhook. It
+// is the same for every retry, so receivers can ignore deliveries they have
+// already processed.
+const DeliveryIDHeader = "X-ZAMC-Delivery-ID"
+
+const (
+	// DeliveryIDTTL is how long delivery IDs are remembered to deduplicate
+	// events dispatched more than once
+	DeliveryIDTTL = 7 * 24 * time.Hour
+
+	// maxResponseBody is the length of the response bodies that are recorded
+	maxResponseBody = 4 << 10
+)
+
+// boardDeliveryNamespace derives the delivery IDs of board webhooks
+var boardDeliveryNamespace = uuid.MustParse("5c0b8f0e-2d3a-4f6b-9a47-0e6c1d2b3a45")
+
+// BoardWebhook is a URL of a board that receives the events it subscribes to
+type BoardWebhook struct {
+	ID        string
+	BoardID   string
+	URL       string
+	Secret    string
+	Events    []string
+	Active    bool
+	CreatedAt time.Time
+}
+
+// BoardDelivery is an attempt to deliver an event to a board webhook.
+// StatusCode is zero when no response was received.
+type BoardDelivery struct {
+	WebhookID    string    `json:"webhook_id"`
+	DeliveryID   string    `json:"delivery_id"`
+	Event        string    `json:"event"`
+	Attempt      int       `json:"attempt"`
+	StatusCode   int       `json:"status_code,omitempty"`
+	ResponseBody string    `json:"response_body,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	DeliveredAt  time.Time `json:"delivered_at"`
+}
+
+// BoardPayload is the JSON body posted to board webhooks. Only the ID of the
+// asset or message the event is about is set.
+type BoardPayload struct {
+	Event     string    `json:"event"`
+	BoardID   string    `json:"board_id"`
+	AssetID   string    `json:"asset_id,omitempty"`
+	MessageID string    `json:"message_id,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// deliveryID returns the ID of the delivery of payload to the webhook
+// webhookID. The same event dispatched twice gets the same ID.
+func (p *BoardPayload) deliveryID(webhookID string) string {
+	name := fmt.Sprintf("%s|%s|%s|%s|%s", webhookID, p.Event, p.AssetID, p.MessageID,
+		p.Timestamp.UTC().Format(time.RFC3339Nano))
+	return uuid.NewSHA1(boardDeliveryNamespace, []byte(name)).String()
+}
+
+// BoardStore lists the board webhooks to notify of events and records their
+// deliveries
+type BoardStore interface {
+	BoardSubscribers(ctx context.Context, boardID, event string) ([]*BoardWebhook, error)
+	AssetBoard(ctx context.Context, assetID string) (string, error)
+	RecordBoardDelivery(ctx context.Context, delivery *BoardDelivery) error
+}
+
+// BoardWebhookDispatcher posts the events of boards to their webhooks. Like
+// Dispatcher, deliveries run in the background and are retried with exponential
+// backoff. Delivery IDs are remembered in Redis so that an event dispatched
+// again, such as a NATS message redelivered, is not posted twice.
+type BoardWebhookDispatcher struct {
+	store       BoardStore
+	redisClient redis.UniversalClient
+	client      *http.Client
+	backoff     time.Duration
+	wg          sync.WaitGroup
+}
+
+// NewBoardWebhookDispatcher creates a dispatcher reading board webhooks from
+// store and recording their deliveries in it. Events are not deduplicated when
+// redisClient is nil.
+func NewBoardWebhookDispatcher(store BoardStore, redisClient redis.UniversalClient) *BoardWebhookDispatcher {
+	return &BoardWebhookDispatcher{
+		store:       store,
+		redisClient: redisClient,
+		client:      safehttp.NewClient(deliveryTimeout),
+		backoff:     time.Second,
+	}
+}
+
+// Dispatch starts delivering payload to the webhooks of its board subscribed to
+// its event
+func (d *BoardWebhookDispatcher) Dispatch(ctx context.Context, payload *BoardPayload) error {
+	webhooks, err := d.store.BoardSubscribers(ctx, payload.BoardID, payload.Event)
+	if err != nil {
+		return err
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	for _, webhook := range webhooks {
+		deliveryID := payload.deliveryID(webhook.ID)
+		if !d.claim(ctx, deliveryID) {
+			continue
+		}
+
+		d.wg.Add(1)
+		go func(webhook *BoardWebhook) {
+			defer d.wg.Done()
+			d.deliver(webhook, payload.Event, deliveryID, body)
+		}(webhook)
+	}
+
+	return nil
+}
+
+// DispatchAsset dispatches payload to the webhooks of the board of its asset
+func (d *BoardWebhookDispatcher) DispatchAsset(ctx context.Context, payload *BoardPayload) error {
+	boardID, err := d.store.AssetBoard(ctx, payload.AssetID)
+	if err != nil {
+		return err
+	}
+	payload.BoardID = boardID
+	return d.Dispatch(ctx, payload)
+}
+
+// Close waits until the started deliveries succeed or run out of retries
+func (d *BoardWebhookDispatcher) Close() {
+	d.wg.Wait()
+}
+
+// claim reports whether the delivery deliveryID has not been started before.
+// When Redis cannot be reached, the event is delivered rather than dropped.
+func (d *BoardWebhookDispatcher) claim(ctx context.Context, deliveryID string) bool {
+	if d.redisClient == nil {
+		return true
+	}
+
+	claimed, err := d.redisClient.SetNX(ctx, "board_webhook_delivery:"+deliveryID, 1, DeliveryIDTTL).Result()
+	if err != nil {
+		log.Printf("Failed to deduplicate webhook delivery %s: %v", deliveryID, err)
+		return true
+	}
+	return claimed
+}
+
+func (d *BoardWebhookDispatcher) deliver(webhook *BoardWebhook, event, deliveryID string, body []byte) {
+	signature := Sign(webhook.Secret, body)
+	backoff := d.backoff
+
+	for attempt := 1; ; attempt++ {
+		statusCode, responseBody, err := d.post(webhook.URL, event, deliveryID, signature, body)
+
+		delivery := &BoardDelivery{
+			WebhookID:    webhook.ID,
+			DeliveryID:   deliveryID,
+			Event:        event,
+			Attempt:      attempt,
+			StatusCode:   statusCode,
+			ResponseBody: responseBody,
+			DeliveredAt:  time.Now(),
+		}
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		d.record(delivery)
+
+		if err == nil {
+			return
+		}
+		if attempt > maxRetries {
+			log.Printf("Failed to deliver %s to board webhook %s after %d attempts: %v", event, webhook.ID, attempt, err)
+			return
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (d *BoardWebhookDispatcher) post(url, event, deliveryID, signature string, body []byte) (int, string, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ZAMC-Webhook/1.0")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryIDHeader, deliveryID)
+	req.Header.Set(SignatureHeader, signature)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	// Postgres text cannot hold NUL bytes or invalid UTF-8
+	responseBody := strings.ReplaceAll(strings.ToValidUTF8(string(data), "\uFFFD"), "\x00", "")
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, responseBody, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, responseBody, nil
+}
+
+func (d *BoardWebhookDispatcher) record(delivery *BoardDelivery) {
+	ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+
+	if err := d.store.RecordBoardDelivery(ctx, delivery); err != nil {
+		log.Printf("Failed to record delivery of %s to board webhook %s: %v", delivery.Event, delivery.WebhookID, err)
+	}
+}
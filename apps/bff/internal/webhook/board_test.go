@@ -0,0 +1,183 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryBoardStore struct {
+	mu         sync.Mutex
+	webhooks   []*BoardWebhook
+	assets     map[string]string
+	deliveries []*BoardDelivery
+}
+
+func (s *memoryBoardStore) BoardSubscribers(ctx context.Context, boardID, event string) ([]*BoardWebhook, error) {
+	var webhooks []*BoardWebhook
+	for _, webhook := range s.webhooks {
+		if webhook.BoardID != boardID || !webhook.Active {
+			continue
+		}
+		for _, e := range webhook.Events {
+			if e == event {
+				webhooks = append(webhooks, webhook)
+			}
+		}
+	}
+	return webhooks, nil
+}
+
+func (s *memoryBoardStore) AssetBoard(ctx context.Context, assetID string) (string, error) {
+	return s.assets[assetID], nil
+}
+
+func (s *memoryBoardStore) RecordBoardDelivery(ctx context.Context, delivery *BoardDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+func newTestBoardDispatcher(t *testing.T, store BoardStore) *BoardWebhookDispatcher {
+	mr := miniredis.RunT(t)
+	dispatcher := NewBoardWebhookDispatcher(store, redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	dispatcher.backoff = time.Millisecond
+	// The test servers listen on loopback, which the dispatcher refuses
+	dispatcher.client = &http.Client{Timeout: deliveryTimeout}
+	return dispatcher
+}
+
+func TestBoardWebhookDispatcher_DeliversSignedPayload(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []*http.Request
+		bodies   [][]byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+		mu.Unlock()
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	store := &memoryBoardStore{webhooks: []*BoardWebhook{
+		{ID: "webhook-1", BoardID: "board-1", URL: server.URL, Secret: "s3cret", Events: []string{EventMessageSent}, Active: true},
+		{ID: "webhook-2", BoardID: "board-1", URL: server.URL, Secret: "other", Events: []string{EventAssetUploaded}, Active: true},
+		{ID: "webhook-3", BoardID: "board-2", URL: server.URL, Secret: "other", Events: []string{EventMessageSent}, Active: true},
+	}}
+	dispatcher := newTestBoardDispatcher(t, store)
+
+	payload := &BoardPayload{
+		Event:     EventMessageSent,
+		BoardID:   "board-1",
+		MessageID: "message-1",
+		Timestamp: time.Date(2024, 1, 15, 10, 31, 0, 0, time.UTC),
+	}
+	require.NoError(t, dispatcher.Dispatch(context.Background(), payload))
+	dispatcher.Close()
+
+	require.Len(t, requests, 1)
+	assert.Equal(t, EventMessageSent, requests[0].Header.Get(EventHeader))
+	assert.Equal(t, Sign("s3cret", bodies[0]), requests[0].Header.Get(SignatureHeader))
+	deliveryID := requests[0].Header.Get(DeliveryIDHeader)
+	_, err := uuid.Parse(deliveryID)
+	assert.NoError(t, err)
+
+	var received BoardPayload
+	require.NoError(t, json.Unmarshal(bodies[0], &received))
+	assert.Equal(t, *payload, received)
+
+	require.Len(t, store.deliveries, 1)
+	assert.Equal(t, "webhook-1", store.deliveries[0].WebhookID)
+	assert.Equal(t, deliveryID, store.deliveries[0].DeliveryID)
+	assert.Equal(t, http.StatusOK, store.deliveries[0].StatusCode)
+	assert.Equal(t, `{"ok":true}`, store.deliveries[0].ResponseBody)
+}
+
+func TestBoardWebhookDispatcher_DeduplicatesEvents(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer server.Close()
+
+	store := &memoryBoardStore{webhooks: []*BoardWebhook{
+		{ID: "webhook-1", BoardID: "board-1", URL: server.URL, Secret: "s3cret", Events: []string{EventAssetApproved}, Active: true},
+	}}
+	dispatcher := newTestBoardDispatcher(t, store)
+
+	approvedAt := time.Date(2024, 1, 15, 10, 31, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		err := dispatcher.Dispatch(context.Background(), &BoardPayload{
+			Event: EventAssetApproved, BoardID: "board-1", AssetID: "asset-1", Timestamp: approvedAt,
+		})
+		require.NoError(t, err)
+		dispatcher.Close()
+	}
+	assert.Equal(t, 1, calls)
+
+	// The asset approved again is a new event
+	err := dispatcher.Dispatch(context.Background(), &BoardPayload{
+		Event: EventAssetApproved, BoardID: "board-1", AssetID: "asset-1", Timestamp: approvedAt.Add(time.Hour),
+	})
+	require.NoError(t, err)
+	dispatcher.Close()
+	assert.Equal(t, 2, calls)
+}
+
+func TestBoardWebhookDispatcher_RetriesWithSameDeliveryID(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		deliveryIDs []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		deliveryIDs = append(deliveryIDs, r.Header.Get(DeliveryIDHeader))
+		if len(deliveryIDs) < 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(strings.Repeat("x", maxResponseBody+100)))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	store := &memoryBoardStore{
+		webhooks: []*BoardWebhook{
+			{ID: "webhook-1", BoardID: "board-1", URL: server.URL, Secret: "s3cret", Events: []string{EventAssetDeployed}, Active: true},
+		},
+		assets: map[string]string{"asset-1": "board-1"},
+	}
+	dispatcher := newTestBoardDispatcher(t, store)
+
+	payload := &BoardPayload{Event: EventAssetDeployed, AssetID: "asset-1", Status: "deployed", Timestamp: time.Now()}
+	require.NoError(t, dispatcher.DispatchAsset(context.Background(), payload))
+	dispatcher.Close()
+
+	require.Len(t, deliveryIDs, 2)
+	assert.Equal(t, deliveryIDs[0], deliveryIDs[1])
+
+	require.Len(t, store.deliveries, 2)
+	assert.Equal(t, http.StatusBadGateway, store.deliveries[0].StatusCode)
+	assert.Len(t, store.deliveries[0].ResponseBody, maxResponseBody)
+	assert.Equal(t, "webhook responded with status 502", store.deliveries[0].Error)
+	assert.Equal(t, 2, store.deliveries[1].Attempt)
+	assert.Empty(t, store.deliveries[1].Error)
+}
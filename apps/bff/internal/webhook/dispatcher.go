@@ -0,0 +1,218 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/safehttp"
+)
+
+// Events webhooks subscribe to
+const (
+	EventAssetDeployed = "asset.deployed"
+	EventAssetFailed   = "asset.failed"
+)
+
+// Headers of every delivery. The signature is the hex HMAC-SHA256 of the body
+// keyed with the webhook's secret, prefixed with "sha256=".
+const (
+	SignatureHeader = "X-ZAMC-Signature-256"
+	EventHeader     = "X-ZAMC-Event"
+	DeliveryHeader  = "X-ZAMC-Delivery"
+)
+
+const (
+	// deliveryTimeout bounds each attempt to deliver an event
+	deliveryTimeout = 10 * time.Second
+
+	// maxRetries is the number of times a failed delivery is retried
+	maxRetries = 3
+
+	// recordTimeout bounds the storage of a delivery attempt
+	recordTimeout = 5 * time.Second
+)
+
+// Webhook is a URL of a project that receives the events it subscribes to
+type Webhook struct {
+	ID        string
+	ProjectID string
+	URL       string
+	Secret    string
+	Events    []string
+	Active    bool
+	CreatedAt time.Time
+}
+
+// Delivery is an attempt to deliver an event to a webhook. StatusCode is zero
+// when no response was received.
+type Delivery struct {
+	WebhookID   string
+	Event       string
+	Attempt     int
+	StatusCode  int
+	Error       string
+	DeliveredAt time.Time
+}
+
+// Payload is the JSON body posted to webhooks when the deployment of an asset
+// finishes
+type Payload struct {
+	Event      string    `json:"event"`
+	ProjectID  string    `json:"project_id"`
+	AssetID    string    `json:"asset_id"`
+	Status     string    `json:"status"`
+	PrevStatus string    `json:"prev_status,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// StatusEvent returns the event webhooks subscribe to for an asset reaching
+// status, as reported by the connectors service
+func StatusEvent(status string) (string, bool) {
+	switch status {
+	case "deployed":
+		return EventAssetDeployed, true
+	case "failed":
+		return EventAssetFailed, true
+	default:
+		return "", false
+	}
+}
+
+// Sign returns the value of the SignatureHeader of body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher posts events to the webhooks subscribed to them. Deliveries run in
+// the background and are retried with exponential backoff when the webhook
+// cannot be reached or does not answer with a 2xx status.
+type Dispatcher struct {
+	store   Store
+	client  *http.Client
+	backoff time.Duration
+	wg      sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher reading webhooks from store and recording
+// their deliveries in it. Deliveries are only made to public addresses and do
+// not follow redirects.
+func NewDispatcher(store Store) *Dispatcher {
+	return &Dispatcher{
+		store:   store,
+		client:  safehttp.NewClient(deliveryTimeout),
+		backoff: time.Second,
+	}
+}
+
+// Dispatch starts delivering payload to the webhooks of its project subscribed
+// to its event
+func (d *Dispatcher) Dispatch(ctx context.Context, payload *Payload) error {
+	webhooks, err := d.store.Subscribers(ctx, payload.ProjectID, payload.Event)
+	if err != nil {
+		return err
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	for _, webhook := range webhooks {
+		d.wg.Add(1)
+		go func(webhook *Webhook) {
+			defer d.wg.Done()
+			d.deliver(webhook, payload.Event, body)
+		}(webhook)
+	}
+
+	return nil
+}
+
+// Close waits until the started deliveries succeed or run out of retries
+func (d *Dispatcher) Close() {
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) deliver(webhook *Webhook, event string, body []byte) {
+	deliveryID := uuid.New().String()
+	signature := Sign(webhook.Secret, body)
+	backoff := d.backoff
+
+	for attempt := 1; ; attempt++ {
+		statusCode, err := d.post(webhook.URL, event, deliveryID, signature, body)
+
+		delivery := &Delivery{
+			WebhookID:   webhook.ID,
+			Event:       event,
+			Attempt:     attempt,
+			StatusCode:  statusCode,
+			DeliveredAt: time.Now(),
+		}
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		d.record(delivery)
+
+		if err == nil {
+			return
+		}
+		if attempt > maxRetries {
+			log.Printf("Failed to deliver %s to webhook %s after %d attempts: %v", event, webhook.ID, attempt, err)
+			return
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (d *Dispatcher) post(url, event, deliveryID, signature string, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ZAMC-Webhook/1.0")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, deliveryID)
+	req.Header.Set(SignatureHeader, signature)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) record(delivery *Delivery) {
+	ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+
+	if err := d.store.RecordDelivery(ctx, delivery); err != nil {
+		log.Printf("Failed to record delivery of %s to webhook %s: %v", delivery.Event, delivery.WebhookID, err)
+	}
+}
@@ -0,0 +1,208 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	mu         sync.Mutex
+	webhooks   []*Webhook
+	deliveries []*Delivery
+}
+
+func (s *memoryStore) Subscribers(ctx context.Context, projectID, event string) ([]*Webhook, error) {
+	var webhooks []*Webhook
+	for _, webhook := range s.webhooks {
+		if webhook.ProjectID != projectID || !webhook.Active {
+			continue
+		}
+		for _, e := range webhook.Events {
+			if e == event {
+				webhooks = append(webhooks, webhook)
+			}
+		}
+	}
+	return webhooks, nil
+}
+
+func (s *memoryStore) RecordDelivery(ctx context.Context, delivery *Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+func newTestDispatcher(store Store) *Dispatcher {
+	dispatcher := NewDispatcher(store)
+	dispatcher.backoff = time.Millisecond
+	// The test servers listen on loopback, which the dispatcher refuses
+	dispatcher.client = &http.Client{Timeout: deliveryTimeout}
+	return dispatcher
+}
+
+func TestSign(t *testing.T) {
+	// Example from GitHub's webhook documentation
+	assert.Equal(t,
+		"sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17",
+		Sign("It's a Secret to Everybody", []byte("Hello, World!")))
+}
+
+func TestStatusEvent(t *testing.T) {
+	event, ok := StatusEvent("deployed")
+	assert.True(t, ok)
+	assert.Equal(t, EventAssetDeployed, event)
+
+	event, ok = StatusEvent("failed")
+	assert.True(t, ok)
+	assert.Equal(t, EventAssetFailed, event)
+
+	_, ok = StatusEvent("approved")
+	assert.False(t, ok)
+}
+
+func TestDispatcher_DeliversSignedPayload(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []*http.Request
+		bodies   [][]byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	store := &memoryStore{webhooks: []*Webhook{
+		{ID: "webhook-1", ProjectID: "project-1", URL: server.URL, Secret: "s3cret", Events: []string{EventAssetDeployed}, Active: true},
+		{ID: "webhook-2", ProjectID: "project-1", URL: server.URL, Secret: "other", Events: []string{EventAssetFailed}, Active: true},
+		{ID: "webhook-3", ProjectID: "project-1", URL: server.URL, Secret: "other", Events: []string{EventAssetDeployed}, Active: false},
+		{ID: "webhook-4", ProjectID: "project-2", URL: server.URL, Secret: "other", Events: []string{EventAssetDeployed}, Active: true},
+	}}
+	dispatcher := newTestDispatcher(store)
+
+	payload := &Payload{
+		Event:      EventAssetDeployed,
+		ProjectID:  "project-1",
+		AssetID:    "asset-1",
+		Status:     "deployed",
+		PrevStatus: "approved",
+		Timestamp:  time.Date(2024, 1, 15, 10, 31, 0, 0, time.UTC),
+	}
+	require.NoError(t, dispatcher.Dispatch(context.Background(), payload))
+	dispatcher.Close()
+
+	// Only the active webhook of the project subscribed to the event is called
+	require.Len(t, requests, 1)
+	assert.Equal(t, http.MethodPost, requests[0].Method)
+	assert.Equal(t, "application/json", requests[0].Header.Get("Content-Type"))
+	assert.Equal(t, EventAssetDeployed, requests[0].Header.Get(EventHeader))
+	assert.NotEmpty(t, requests[0].Header.Get(DeliveryHeader))
+	assert.Equal(t, Sign("s3cret", bodies[0]), requests[0].Header.Get(SignatureHeader))
+
+	var received Payload
+	require.NoError(t, json.Unmarshal(bodies[0], &received))
+	assert.Equal(t, *payload, received)
+
+	require.Len(t, store.deliveries, 1)
+	assert.Equal(t, "webhook-1", store.deliveries[0].WebhookID)
+	assert.Equal(t, 1, store.deliveries[0].Attempt)
+	assert.Equal(t, http.StatusNoContent, store.deliveries[0].StatusCode)
+	assert.Empty(t, store.deliveries[0].Error)
+}
+
+func TestDispatcher_RetriesFailedDeliveries(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		calls       int
+		deliveryIDs []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		deliveryIDs = append(deliveryIDs, r.Header.Get(DeliveryHeader))
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	store := &memoryStore{webhooks: []*Webhook{
+		{ID: "webhook-1", ProjectID: "project-1", URL: server.URL, Secret: "s3cret", Events: []string{EventAssetFailed}, Active: true},
+	}}
+	dispatcher := newTestDispatcher(store)
+
+	require.NoError(t, dispatcher.Dispatch(context.Background(), &Payload{Event: EventAssetFailed, ProjectID: "project-1"}))
+	dispatcher.Close()
+
+	assert.Equal(t, 3, calls)
+	// Retries are the same delivery
+	assert.Equal(t, deliveryIDs[0], deliveryIDs[2])
+
+	require.Len(t, store.deliveries, 3)
+	for i, delivery := range store.deliveries {
+		assert.Equal(t, i+1, delivery.Attempt)
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, store.deliveries[0].StatusCode)
+	assert.Equal(t, "webhook responded with status 503", store.deliveries[0].Error)
+	assert.Equal(t, http.StatusOK, store.deliveries[2].StatusCode)
+	assert.Empty(t, store.deliveries[2].Error)
+}
+
+func TestDispatcher_GivesUpAfterRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	store := &memoryStore{webhooks: []*Webhook{
+		{ID: "webhook-1", ProjectID: "project-1", URL: url, Secret: "s3cret", Events: []string{EventAssetDeployed}, Active: true},
+	}}
+	dispatcher := newTestDispatcher(store)
+
+	require.NoError(t, dispatcher.Dispatch(context.Background(), &Payload{Event: EventAssetDeployed, ProjectID: "project-1"}))
+	dispatcher.Close()
+
+	// The first attempt and 3 retries, none of which got a response
+	require.Len(t, store.deliveries, maxRetries+1)
+	for _, delivery := range store.deliveries {
+		assert.Zero(t, delivery.StatusCode)
+		assert.Contains(t, delivery.Error, "failed to post webhook")
+	}
+}
+
+func TestDispatcher_RefusesPrivateAddresses(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer server.Close()
+
+	store := &memoryStore{webhooks: []*Webhook{
+		{ID: "webhook-1", ProjectID: "project-1", URL: server.URL, Secret: "s3cret", Events: []string{EventAssetDeployed}, Active: true},
+	}}
+	dispatcher := NewDispatcher(store)
+	dispatcher.backoff = time.Millisecond
+
+	require.NoError(t, dispatcher.Dispatch(context.Background(), &Payload{Event: EventAssetDeployed, ProjectID: "project-1"}))
+	dispatcher.Close()
+
+	assert.Zero(t, calls)
+	require.Len(t, store.deliveries, maxRetries+1)
+	assert.Contains(t, store.deliveries[0].Error, "address is not public")
+}
@@ -0,0 +1,145 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Store lists the webhooks to notify of events and records their deliveries
+type Store interface {
+	Subscribers(ctx context.Context, projectID, event string) ([]*Webhook, error)
+	RecordDelivery(ctx context.Context, delivery *Delivery) error
+}
+
+// DBStore reads the webhooks table and writes the webhook_deliveries table.
+// Events are dispatched outside of any request, so row-level security does not
+// apply.
+type DBStore struct {
+	db *sql.DB
+}
+
+// NewDBStore creates a store backed by db
+func NewDBStore(db *sql.DB) *DBStore {
+	return &DBStore{db: db}
+}
+
+// Subscribers returns the active webhooks of a project subscribed to event
+func (s *DBStore) Subscribers(ctx context.Context, projectID, event string) ([]*Webhook, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, project_id, url, secret, events, active, created_at
+		FROM webhooks
+		WHERE project_id = $1 AND active AND $2 = ANY(events)
+		ORDER BY created_at
+	`, projectID, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []*Webhook
+	for rows.Next() {
+		var webhook Webhook
+		err := rows.Scan(&webhook.ID, &webhook.ProjectID, &webhook.URL, &webhook.Secret,
+			pq.Array(&webhook.Events), &webhook.Active, &webhook.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, &webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// RecordDelivery saves an attempt to deliver an event
+func (s *DBStore) RecordDelivery(ctx context.Context, delivery *Delivery) error {
+	var statusCode, deliveryError interface{}
+	if delivery.StatusCode != 0 {
+		statusCode = delivery.StatusCode
+	}
+	if delivery.Error != "" {
+		deliveryError = delivery.Error
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, event, attempt, status_code, error, delivered_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, delivery.WebhookID, delivery.Event, delivery.Attempt, statusCode, deliveryError, delivery.DeliveredAt)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// BoardSubscribers returns the active webhooks of a board subscribed to event
+func (s *DBStore) BoardSubscribers(ctx context.Context, boardID, event string) ([]*BoardWebhook, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, board_id, url, secret, events, active, created_at
+		FROM board_webhooks
+		WHERE board_id = $1 AND active AND $2 = ANY(events)
+		ORDER BY created_at
+	`, boardID, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query board webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []*BoardWebhook
+	for rows.Next() {
+		var webhook BoardWebhook
+		err := rows.Scan(&webhook.ID, &webhook.BoardID, &webhook.URL, &webhook.Secret,
+			pq.Array(&webhook.Events), &webhook.Active, &webhook.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan board webhook: %w", err)
+		}
+		webhooks = append(webhooks, &webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read board webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// AssetBoard returns the ID of the board of an asset
+func (s *DBStore) AssetBoard(ctx context.Context, assetID string) (string, error) {
+	var boardID string
+	err := s.db.QueryRowContext(ctx, `SELECT board_id FROM assets WHERE id = $1`, assetID).Scan(&boardID)
+	if err != nil {
+		return "", fmt.Errorf("failed to query board of asset %s: %w", assetID, err)
+	}
+	return boardID, nil
+}
+
+// RecordBoardDelivery saves an attempt to deliver an event to a board webhook
+func (s *DBStore) RecordBoardDelivery(ctx context.Context, delivery *BoardDelivery) error {
+	var statusCode, responseBody, deliveryError interface{}
+	if delivery.StatusCode != 0 {
+		statusCode = delivery.StatusCode
+	}
+	if delivery.ResponseBody != "" {
+		responseBody = delivery.ResponseBody
+	}
+	if delivery.Error != "" {
+		deliveryError = delivery.Error
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO board_webhook_deliveries (webhook_id, delivery_id, event, attempt, status_code, response_body, error, delivered_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, delivery.WebhookID, delivery.DeliveryID, delivery.Event, delivery.Attempt,
+		statusCode, responseBody, deliveryError, delivery.DeliveredAt)
+	if err != nil {
+		return fmt.Errorf("failed to record board webhook delivery: %w", err)
+	}
+
+	return nil
+}
@@ -1,12 +1,23 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/99designs/gqlgen/graphql/handler"
@@ -15,29 +26,74 @@ import (
 	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/joho/godotenv"
 	"github.com/rs/cors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/zerionstudio/zamc-v2/apps/bff/graph"
 "github.com/zerionstudio/zamc-v2/apps/bff/graph/generated"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/assethash"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/assets"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/audit"
 "github.com/zerionstudio/zamc-v2/apps/bff/internal/auth"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/autocomplete"
 "github.com/zerionstudio/zamc-v2/apps/bff/internal/config"
 "github.com/zerionstudio/zamc-v2/apps/bff/internal/database"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/export"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/health"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/metrics"
 	"github.com/zerionstudio/zamc-v2/apps/bff/internal/middleware"
 "github.com/zerionstudio/zamc-v2/apps/bff/internal/nats"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/oauth"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/quality"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/redisclient"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/safehttp"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/sla"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/tracing"
+	"github.com/zerionstudio/zamc-v2/apps/bff/internal/webhook"
 )
 
 var startTime = time.Now()
 
+// Version and BuildTime are set at build time with -ldflags
+var (
+	Version   = "dev"
+	BuildTime = "unknown"
+)
+
 func main() {
+	migrateOnly := flag.Bool("migrate-only", false, "Run database migrations and exit")
+	flag.Parse()
+
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
 		log.Printf("Warning: .env file not found: %v", err)
 	}
 
-	// Initialize configuration
-	cfg := config.Load()
+	// Initialize configuration, with the settings of CONFIG_FILE taking priority
+	configFile := os.Getenv("CONFIG_FILE")
+	cfg, err := config.LoadFile(configFile)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Cancelled on SIGINT or SIGTERM to stop the server
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Export traces to the OpenTelemetry collector
+	shutdownTracing, err := tracing.Setup(context.Background(), "bff", cfg.OTLPEndpoint)
+	if err != nil {
+		log.Printf("Warning: tracing disabled: %v", err)
+	} else {
+		defer shutdownTracing(context.Background())
+	}
 
 	// Initialize database connection
 	db, err := database.Connect(cfg.DatabaseURL)
@@ -46,10 +102,36 @@ func main() {
 	}
 	defer db.Close()
 
-	// Initialize Redis connection for rate limiting
-	redisClient := redis.NewClient(&redis.Options{
-		Addr: getRedisAddr(cfg.DatabaseURL), // Extract Redis URL from config
-	})
+	// Apply database migrations before anything else touches the schema
+	if err := database.Migrate(db.DB, cfg.MigrationsPath); err != nil {
+		log.Fatalf("Failed to run database migrations: %v", err)
+	}
+	if *migrateOnly {
+		log.Println("Database migrations applied")
+		return
+	}
+
+	// Send SELECT-only resolvers to the read replica, if any
+	var replicaDB *sql.DB
+	if cfg.ReplicaDatabaseURL != "" {
+		replica, err := database.Connect(cfg.ReplicaDatabaseURL)
+		if err != nil {
+			log.Fatalf("Failed to connect to replica database: %v", err)
+		}
+		defer replica.Close()
+		replicaDB = replica.DB
+		log.Println("Reading from the replica database")
+	}
+	dbPool := database.NewPool(db.DB, replicaDB)
+
+	// Initialize Redis connection for rate limiting. A comma-separated REDIS_URL
+	// lists the nodes of a Redis Cluster.
+	var redisClient redis.UniversalClient
+	if redisAddrs := getRedisAddrs(); len(redisAddrs) > 1 {
+		redisClient = redis.NewClusterClient(&redis.ClusterOptions{Addrs: redisAddrs})
+	} else {
+		redisClient = redis.NewClient(&redis.Options{Addr: redisAddrs[0]})
+	}
 	defer redisClient.Close()
 
 	// Test Redis connection
@@ -65,15 +147,43 @@ func main() {
 	}
 	defer natsConn.Close()
 
+	if cfg.CredentialsTransportKey != "" {
+		if err := natsConn.UseCredentialsTransportKey(cfg.CredentialsTransportKey); err != nil {
+			log.Fatalf("CREDENTIALS_TRANSPORT_KEY: %v", err)
+		}
+	} else {
+		log.Println("Warning: platform credentials cannot be stored (CREDENTIALS_TRANSPORT_KEY not set)")
+	}
+
+	// Escalate assets that wait in review longer than the SLA
+	go sla.NewMonitor(db.DB, natsConn, cfg.SLAHours, time.Hour).Run(context.Background())
+
+	// Hash uploaded asset files so that re-uploads of the same file are detected
+	hashWorker := assethash.NewWorker(assethash.NewHasher(safehttp.NewClient(30 * time.Second)), assethash.NewDBStore(db.DB), natsConn)
+	if _, err := hashWorker.Start(); err != nil {
+		log.Printf("Warning: asset content hashing disabled: %v", err)
+	}
+
 	// Initialize auth service with Redis support
-	var authService *auth.Service
+	authService, err := newAuthService(cfg)
+	if err != nil {
+		log.Fatalf("JWT configuration error: %v", err)
+	}
 	if redisClient != nil {
-		authService = auth.NewServiceWithRedis(cfg.SupabaseJWTSecret, redisClient)
+		authService.UseRedis(redisclient.New(redisClient))
 	} else {
-		authService = auth.NewService(cfg.SupabaseJWTSecret)
 		log.Println("Warning: JWT token blacklisting disabled (Redis unavailable)")
 	}
 
+	// Require a TOTP code from users who turned on two-factor authentication
+	if cfg.TOTPEncryptionKey != "" {
+		if err := authService.UseUserStore(auth.NewDBUserStore(db.DB), cfg.TOTPEncryptionKey); err != nil {
+			log.Fatalf("TOTP configuration error: %v", err)
+		}
+	} else {
+		log.Println("Warning: two-factor authentication disabled (TOTP_ENCRYPTION_KEY not set)")
+	}
+
 	// Validate JWT secret strength
 	if err := authService.ValidateTokenStrength(); err != nil {
 		log.Fatalf("JWT configuration error: %v", err)
@@ -82,83 +192,402 @@ func main() {
 	// Initialize security middleware
 	var rateLimiter *middleware.RateLimiter
 	var securityMonitor *middleware.SecurityMonitor
+	switch client := redisClient.(type) {
+	case *redis.ClusterClient:
+		rateLimiter = middleware.NewRateLimiterWithCluster(client)
+	case *redis.Client:
+		rateLimiter = middleware.NewRateLimiter(client)
+	}
 	if redisClient != nil {
-		rateLimiter = middleware.NewRateLimiter(redisClient)
-		securityMonitor = middleware.NewSecurityMonitor(redisClient)
-	}
-	inputValidator := middleware.NewInputValidator()
-
-	// Create GraphQL server
-	srv := handler.New(generated.NewExecutableSchema(generated.Config{
-		Resolvers: &graph.Resolver{
-			DB:          db,
-			NatsConn:    natsConn,
-			AuthService: authService,
-		},
-	}))
+		customAlertRules, err := middleware.ParseCustomAlertRules(cfg.Security.CustomAlertRules)
+		if err != nil {
+			log.Fatalf("SECURITY_CUSTOM_ALERT_RULES: %v", err)
+		}
+		securityMonitor = middleware.NewSecurityMonitorWithConfig(redisclient.New(redisClient), middleware.SecurityConfig{
+			FailedAuthThreshold:         cfg.Security.FailedAuthThreshold,
+			RateLimitHitThreshold:       cfg.Security.RateLimitHitThreshold,
+			SuspiciousActivityThreshold: cfg.Security.SuspiciousActivityThreshold,
+			CustomRules:                 customAlertRules,
+		})
+		// Thresholds changed at runtime replace the configured ones
+		go securityMonitor.WatchConfig(ctx)
 
-	// Add transports
-	srv.AddTransport(transport.Websocket{
-		KeepAlivePingInterval: 10,
-		Upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool {
-				return checkCORSOrigin(r, cfg.CorsOrigins)
-			},
-		},
+		trustedProxies, err := middleware.ParseTrustedProxies(cfg.TrustedProxies)
+		if err != nil {
+			log.Fatalf("TRUSTED_PROXIES: %v", err)
+		}
+		securityMonitor.SetTrustedProxies(trustedProxies)
+
+		if cfg.Security.GeoIPDatabasePath != "" {
+			geoIP, err := middleware.OpenGeoIPDatabase(cfg.Security.GeoIPDatabasePath)
+			if err != nil {
+				log.Fatalf("GEOIP_DB_PATH: %v", err)
+			}
+			defer geoIP.Close()
+			securityMonitor.SetCountryBlocking(geoIP, cfg.Security.BlockedCountries)
+			log.Printf("Blocking requests from countries: %s", strings.Join(cfg.Security.BlockedCountries, ", "))
+		}
+	}
+	var siemForwarder *middleware.SIEMForwarder
+	if redisClient != nil && cfg.Security.SIEMWebhookURL != "" {
+		siemForwarder = middleware.NewSIEMForwarder(redisClient, cfg.Security.SIEMWebhookURL, cfg.Security.SIEMAPIKey)
+		go siemForwarder.Run(ctx)
+		log.Printf("Forwarding security alerts to SIEM")
+	}
+	if rateLimiter != nil {
+		rateLimiter.SetPolicy(cfg.RateLimits)
+		if securityMonitor != nil {
+			rateLimiter.SetSecurityMonitor(securityMonitor)
+		}
+	}
+	patternRegistry, err := middleware.NewPatternRegistry(ctx, db.DB)
+	if err != nil {
+		log.Fatalf("Failed to load security patterns: %v", err)
+	}
+	go patternRegistry.Watch(ctx, middleware.DefaultPatternPollInterval)
+	inputValidator := middleware.NewInputValidatorWithRegistry(patternRegistry)
+
+	// Initialize board export storage
+	var boardExports *export.Store
+	if redisClient != nil {
+		signingKey := cfg.ExportSigningKey
+		if signingKey == "" {
+			signingKey = cfg.SupabaseJWTSecret
+		}
+		boardExports = export.NewStore(redisClient, signingKey, cfg.PublicURL)
+	} else {
+		log.Println("Warning: board export disabled (Redis unavailable)")
+	}
+
+	// Initialize login through the OAuth2 provider
+	var pkceFlow *oauth.PKCEFlow
+	if cfg.PKCE.Enabled() {
+		if redisClient != nil {
+			pkceFlow = oauth.NewPKCEFlow(cfg.PKCE, redisClient, &http.Client{Timeout: 10 * time.Second})
+		} else {
+			log.Println("Warning: OAuth login disabled (Redis unavailable)")
+		}
+	}
+
+	// Initialize the collaborative editing operation log
+	// The operations and version of a board are updated in one transaction, which a
+	// Redis Cluster rejects when their keys are in different slots
+	var boardOperations *graph.BoardOperationLog
+	switch client := redisClient.(type) {
+	case *redis.Client:
+		boardOperations = graph.NewBoardOperationLog(client)
+	case nil:
+		log.Println("Warning: collaborative board editing disabled (Redis unavailable)")
+	default:
+		log.Println("Warning: collaborative board editing disabled (not supported on a Redis Cluster)")
+	}
+
+	// Record the health timeline for post-mortems
+	dependencies := healthDependencies(dbPool, redisClient, natsConn)
+	checkServices := func(ctx context.Context) map[string]string {
+		return health.Summarize(health.CheckDependencies(ctx, cfg.HealthCheckTimeout, dependencies))
+	}
+	var healthRecorder *health.HealthRecorder
+	if redisClient != nil {
+		healthRecorder = health.NewHealthRecorder(redisClient, "bff", checkServices)
+		go healthRecorder.Run(context.Background())
+	} else {
+		log.Println("Warning: health history disabled (Redis unavailable)")
+	}
+
+	// Record who changed what with each mutation
+	auditLogger := audit.NewAuditLogger(audit.NewDBStore(db.DB), 1000)
+	defer auditLogger.Close()
+
+	// Record the sign-in activity of users
+	loginRecorder := audit.NewLoginRecorder(audit.NewDBLoginStore(db.DB), 1000)
+	defer loginRecorder.Close()
+
+	// Generate previews of uploaded images
+	var thumbnails *assets.ImageProcessor
+	if cfg.Thumbnails.Enabled() {
+		uploader, err := assets.NewS3Uploader(cfg.Thumbnails)
+		if err != nil {
+			log.Fatalf("Thumbnail storage configuration error: %v", err)
+		}
+		thumbnails = assets.NewImageProcessor(safehttp.NewClient(30*time.Second), uploader)
+	} else {
+		log.Println("Warning: asset thumbnails disabled (THUMBNAIL_S3_ENDPOINT or THUMBNAIL_S3_BUCKET not set)")
+	}
+
+	resolver := &graph.Resolver{
+		DB:              dbPool,
+		NatsConn:        natsConn,
+		AuthService:     authService,
+		SLAHours:        cfg.SLAHours,
+		BoardExports:    boardExports,
+		BoardOperations: boardOperations,
+		Validator:       inputValidator,
+		Cache:           graph.NewResolverCache(),
+		Permissions:     graph.NewPermissions(30 * time.Second),
+		Audit:           auditLogger,
+		Logins:          loginRecorder,
+		Thumbnails:      thumbnails,
+	}
+	if redisClient != nil {
+		resolver.Autocomplete = autocomplete.NewIndex(redisClient)
+		go func() {
+			if err := resolver.BackfillAutocomplete(ctx); err != nil {
+				log.Printf("Failed to backfill the autocomplete index: %v", err)
+			}
+		}()
+	} else {
+		log.Println("Warning: asset autocomplete disabled (Redis unavailable)")
+	}
+
+	// Score the copy of assets, caching the scores in Redis when it is available
+	if cfg.QualityScoring.Endpoint != "" {
+		client := quality.NewClient(&http.Client{Timeout: 10 * time.Second}, cfg.QualityScoring.Endpoint, cfg.QualityScoring.APIKey)
+		resolver.Quality = quality.NewScorer(client, redisClient)
+	} else {
+		log.Println("Warning: asset quality scoring disabled (QUALITY_SCORE_API_URL not set)")
+	}
+
+	// Post the events of boards to their webhooks, deduplicated in Redis when it
+	// is available
+	boardWebhooks := webhook.NewBoardWebhookDispatcher(webhook.NewDBStore(db.DB), redisClient)
+	defer boardWebhooks.Close()
+	resolver.BoardWebhooks = boardWebhooks
+
+	// Cache monthly spend reports in Redis, shared by the replicas
+	if redisClient != nil {
+		resolver.SpendReports = graph.NewSpendReportCache(redisClient)
+	}
+
+	// Hand approved assets to the connectors service once their scheduled time has come
+	go resolver.RunScheduledDeployments(context.Background(), time.Minute)
+
+	// Reject assets whose ads were disapproved by an ad platform's review
+	_, err = natsConn.SubscribeAssetPlatformRejected(func(event *nats.AssetPlatformRejectedEvent) {
+		if _, err := resolver.RejectAssetOnPlatform(context.Background(), event.AssetID, event.Reason); err != nil {
+			log.Printf("Failed to reject asset %s after %s review: %v", event.AssetID, event.Platform, err)
+		}
+	})
+	if err != nil {
+		log.Printf("Warning: platform ad rejections will not be recorded: %v", err)
+	}
+
+	// Save the campaign metrics polled by the connectors service and push them to subscribers
+	_, err = natsConn.SubscribeCampaignMetricsUpdated(func(event *nats.CampaignMetricsUpdatedEvent) {
+		if _, err := resolver.SaveCampaignMetrics(context.Background(), event); err != nil {
+			log.Printf("Failed to save metrics of %s campaign %s: %v", event.Platform, event.CampaignID, err)
+		}
+	})
+	if err != nil {
+		log.Printf("Warning: campaign metrics will not be recorded: %v", err)
+	}
+
+	// Drop cached project ROIs when any instance saves new campaign metrics
+	_, err = natsConn.SubscribeAllProjectROIUpdates(func(projectID string) {
+		resolver.Cache.InvalidateProjectROI(projectID)
+	})
+	if err != nil {
+		log.Printf("Warning: cached project ROIs will not be invalidated: %v", err)
+	}
+
+	// Post the outcome of deployments to the webhooks of their projects
+	webhookDispatcher := webhook.NewDispatcher(webhook.NewDBStore(db.DB))
+	defer webhookDispatcher.Close()
+	_, err = natsConn.SubscribeAssetStatusChanged(func(event *nats.AssetStatusChangedEvent) {
+		// The subscription is shared by the instances, so the autocomplete index
+		// is refreshed here rather than by a subscription of its own
+		resolver.ReindexAsset(event.AssetID)
+
+		webhookEvent, ok := webhook.StatusEvent(event.Status)
+		if !ok {
+			return
+		}
+		err := webhookDispatcher.Dispatch(context.Background(), &webhook.Payload{
+			Event:      webhookEvent,
+			ProjectID:  event.ProjectID,
+			AssetID:    event.AssetID,
+			Status:     event.Status,
+			PrevStatus: event.PrevStatus,
+			Timestamp:  event.Timestamp,
+		})
+		if err != nil {
+			log.Printf("Failed to dispatch %s of asset %s to webhooks: %v", webhookEvent, event.AssetID, err)
+		}
+
+		if webhookEvent == webhook.EventAssetDeployed {
+			err := boardWebhooks.DispatchAsset(context.Background(), &webhook.BoardPayload{
+				Event:     webhook.EventAssetDeployed,
+				AssetID:   event.AssetID,
+				Status:    event.Status,
+				Timestamp: event.Timestamp,
+			})
+			if err != nil {
+				log.Printf("Failed to dispatch deployment of asset %s to board webhooks: %v", event.AssetID, err)
+			}
+		}
 	})
-	srv.AddTransport(transport.Options{})
-	srv.AddTransport(transport.GET{})
-	srv.AddTransport(transport.POST{})
-	srv.AddTransport(transport.MultipartForm{})
+	if err != nil {
+		log.Printf("Warning: webhooks will not be notified of deployments: %v", err)
+	}
+
+	// Record the deployment of each asset to each of its platforms
+	_, err = natsConn.SubscribeDeploymentStatusChanged(func(event *nats.DeploymentStatusChangedEvent) {
+		if err := resolver.RecordDeployment(context.Background(), event); err != nil {
+			log.Printf("Failed to record deployment of asset %s to %s: %v", event.AssetID, event.Platform, err)
+		}
+	})
+	if err != nil {
+		log.Printf("Warning: deployment history will not be recorded: %v", err)
+	}
+
+	// Persisted queries are shared by the replicas and survive restarts, so the
+	// cache outlives the GraphQL servers rebuilt on reload
+	persistedQueryStore := middleware.NewDBPersistedQueryStore(db.DB)
+	go persistedQueryStore.RunRetention(ctx, time.Hour)
+	persistedQueries := middleware.NewPersistedQueryCache(persistedQueryStore, redisClient, 1000)
+	go persistedQueries.WatchInvalidations(ctx)
+
+	// Blocked operations are shared by the replicas through Redis, when available
+	operationBlocklist := middleware.NewOperationBlocklist(redisClient)
+
+	// buildGraphQLHandler creates the GraphQL server and its middleware stack for
+	// the settings of cfg, and is called again when they are reloaded
+	buildGraphQLHandler := func(cfg *config.Config) http.Handler {
+		// Create GraphQL server
+		srv := handler.New(generated.NewExecutableSchema(generated.Config{
+			Resolvers:  resolver,
+			Directives: resolver.Directives(),
+		}))
+
+		// Add transports
+		srv.AddTransport(transport.Websocket{
+			KeepAlivePingInterval: 10 * time.Second,
+			Upgrader: websocket.Upgrader{
+				CheckOrigin: func(r *http.Request) bool {
+					return checkCORSOrigin(r, cfg.CorsOrigins)
+				},
+			},
+			InitFunc: websocketInit(authService),
+		})
+		srv.AddTransport(transport.Options{})
+		srv.AddTransport(transport.GET{})
+		srv.AddTransport(transport.POST{})
+		srv.AddTransport(transport.MultipartForm{})
+
+		// Add extensions based on environment
+		srv.SetQueryCache(lru.New(1000))
+	
+		// SECURITY: Only enable introspection in development
+		if cfg.Environment == "development" {
+		srv.Use(extension.Introspection{})
+		}
+	
+		srv.Use(extension.AutomaticPersistedQuery{
+			Cache: persistedQueries,
+		})
+
+		// Record operation counts and durations for Prometheus
+		srv.Use(graph.RequestMetrics{})
+
+		// Add the request ID, duration and server version to every response
+		srv.Use(graph.NewResponseEnvelopeExtension(Version))
+
+		// Trace resolver calls within the span of the request
+		srv.Use(graph.ResolverTracing{})
+
+		// Require named operations, anonymous ones only in development, and
+		// reject the operations blocked by admins
+		srv.Use(middleware.NewOperationNameEnforcementExtension(cfg.Environment == "development", operationBlocklist))
+
+		// Reject operations nested too deeply, such as recursive board and asset selections
+		srv.Use(middleware.NewDepthLimitExtension(cfg.MaxQueryDepth))
+
+		// Bound the time resolvers have to run each operation
+		srv.Use(middleware.NewQueryTimeoutMiddleware(cfg.QueryTimeouts))
 
-	// Add extensions based on environment
-	srv.SetQueryCache(lru.New(1000))
+		// Reject operations that would cost more than the user's budget
+		srv.Use(graph.NewComplexityLimiter(
+			cfg.ComplexityBudget,
+			cfg.AdminComplexityBudget,
+			cfg.ComplexityDatabasePenalty,
+			cfg.ComplexityListSizes,
+		))
+
+		// Check project permissions before resolving the fields that need them
+		srv.Use(graph.NewAuthorizationMiddleware(resolver))
+
+		// Setup CORS
+		c := cors.New(cors.Options{
+			AllowedOrigins:   strings.Split(cfg.CorsOrigins, ","),
+			AllowedMethods:   []string{"GET", "POST", "OPTIONS"},
+			AllowedHeaders:   []string{"Content-Type", "Authorization", "X-Requested-With"},
+			ExposedHeaders:   []string{middleware.RequestIDHeader},
+			AllowCredentials: true,
+			MaxAge:           300, // 5 minutes
+		})
+
+		var graphqlHandler http.Handler = srv
 	
-	// SECURITY: Only enable introspection in development
+		// Apply security middleware in order
+		if securityMonitor != nil {
+			graphqlHandler = securityMonitor.SecurityMonitoringMiddleware()(graphqlHandler)
+			// Use validation middleware with security monitoring
+			graphqlHandler = inputValidator.SecurityValidationMiddlewareWithMonitor(securityMonitor)(graphqlHandler)
+		} else {
+			// Use standard validation middleware
+			graphqlHandler = inputValidator.SecurityValidationMiddleware()(graphqlHandler)
+		}
+		if rateLimiter != nil {
+			graphqlHandler = rateLimiter.RateLimitMiddleware(middleware.GraphQLRateLimit(cfg.RateLimits))(graphqlHandler)
+		}
+		graphqlHandler = resolver.LoaderMiddleware(graphqlHandler)
+		// Run the operations of batch requests one by one through the stack above,
+		// so that each is rate limited and gets its own loaders
+		graphqlHandler = middleware.BatchRequestMiddleware(cfg.MaxBatchSize)(graphqlHandler)
+		graphqlHandler = authMiddleware(authService, securityMonitor, loginRecorder, graphqlHandler)
+		graphqlHandler = tracingMiddleware(graphqlHandler)
+		graphqlHandler = c.Handler(graphqlHandler)
+
+		return graphqlHandler
+	}
+
 	if cfg.Environment == "development" {
-	srv.Use(extension.Introspection{})
 		log.Println("GraphQL introspection enabled (development mode)")
 	} else {
 		log.Println("GraphQL introspection disabled (production mode)")
 	}
-	
-	srv.Use(extension.AutomaticPersistedQuery{
-		Cache: lru.New(100),
-	})
 
-	// Setup CORS
-	c := cors.New(cors.Options{
-		AllowedOrigins:   strings.Split(cfg.CorsOrigins, ","),
-		AllowedMethods:   []string{"GET", "POST", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-Requested-With"},
-		AllowCredentials: true,
-		MaxAge:           300, // 5 minutes
-	})
+	// The CORS origins, rate limit and query limits are reloaded without a restart
+	graphqlHandler := newReloadableHandler(cfg, buildGraphQLHandler(cfg))
+	configWatcher := config.NewWatcher(cfg, configFile, config.DefaultWatchInterval)
+	go configWatcher.Run(ctx)
+	go func() {
+		for next := range configWatcher.Changes() {
+			previous := graphqlHandler.Config()
+			for _, change := range config.Diff(&previous, &next) {
+				log.Printf("Configuration reloaded: %s", change)
+			}
+			graphqlHandler.Swap(&next, buildGraphQLHandler(&next))
+		}
+	}()
 
 	// Setup routes with security middleware
 	mux := http.NewServeMux()
 
-	// Health check endpoint (no security middleware)
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		healthStatus := map[string]interface{}{
-			"status":    "healthy",
-			"timestamp": time.Now().Format(time.RFC3339),
-			"version":   "1.0.0",
-			"service":   "ZAMC BFF GraphQL API",
-			"services": map[string]string{
-				"database": "healthy", // TODO: Add actual database health check
-				"redis":    "healthy", // TODO: Add actual Redis health check
-				"nats":     "healthy", // TODO: Add actual NATS health check
-				"graphql":  "healthy",
-			},
-			"uptime": time.Since(startTime).String(),
-		}
-
+	// Health check endpoints (no security middleware). Kubernetes restarts pods
+	// failing /health/live and stops routing to those failing /health/ready.
+	readinessHandler := healthReadinessHandler(dependencies, cfg.HealthCheckTimeout)
+	mux.HandleFunc("/health", readinessHandler)
+	mux.HandleFunc("/health/ready", readinessHandler)
+	mux.HandleFunc("/health/live", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(healthStatus)
+		json.NewEncoder(w).Encode(map[string]string{"status": "alive"})
 	})
 
+	// Health timeline for post-mortems (no security middleware)
+	mux.HandleFunc("/health/history", healthHistoryHandler(healthRecorder))
+	mux.HandleFunc("/health/incidents", healthIncidentsHandler(healthRecorder))
+
 	// GraphQL playground (development only)
 	if cfg.Environment == "development" {
 		mux.Handle("/", playground.Handler("GraphQL playground", "/query"))
@@ -169,24 +598,23 @@ func main() {
 		})
 	}
 
-	// Security metrics endpoint (protected)
-	mux.HandleFunc("/security/metrics", func(w http.ResponseWriter, r *http.Request) {
+	// Rate limit policies and the headers of rate limited responses
+	mux.HandleFunc("/docs/rate-limits", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 
-		// Verify admin access
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-			http.Error(w, "Missing authorization header", http.StatusUnauthorized)
-			return
-		}
+		description := middleware.DescribeRateLimits(graphqlHandler.Config().RateLimits)
+		description["enabled"] = rateLimiter != nil
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(description)
+	})
 
-		token := strings.TrimPrefix(authHeader, "Bearer ")
-		user, err := authService.VerifyToken(token)
-		if err != nil || user.Role != "admin" {
-			http.Error(w, "Unauthorized", http.StatusForbidden)
+	// Security endpoints (admin only)
+	mux.HandleFunc("/security/metrics", adminOnly(authService, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 
@@ -196,30 +624,66 @@ func main() {
 		}
 
 		metrics := securityMonitor.GetSecurityMetrics()
+		if sessions, err := authService.SessionCount(r.Context()); err == nil {
+			metrics["active_sessions"] = sessions
+		} else {
+			log.Printf("Failed to count sessions: %v", err)
+		}
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(metrics)
-	})
+	}))
+	mux.HandleFunc("/security/block-ip", adminOnly(authService, blockIPHandler(securityMonitor)))
+	mux.HandleFunc("/security/blocked-ips", adminOnly(authService, blockedIPsHandler(securityMonitor)))
+	mux.HandleFunc("/security/geoip/", adminOnly(authService, geoIPHandler(securityMonitor)))
+	mux.HandleFunc("/security/config", adminOnly(authService, securityConfigHandler(securityMonitor)))
+	mux.HandleFunc("/security/patterns", adminOnly(authService, addSecurityPatternHandler(patternRegistry)))
+	mux.HandleFunc("/security/patterns/", adminOnly(authService, deactivateSecurityPatternHandler(patternRegistry)))
+	mux.HandleFunc("/security/siem/backlog", adminOnly(authService, siemBacklogHandler(siemForwarder)))
+	mux.HandleFunc("/security/siem/flush", adminOnly(authService, siemFlushHandler(siemForwarder)))
 
-	// GraphQL endpoint with full security middleware stack
-	graphqlHandler := srv
-	
-	// Apply security middleware in order
-	if securityMonitor != nil {
-		graphqlHandler = securityMonitor.SecurityMonitoringMiddleware()(graphqlHandler)
-		// Use validation middleware with security monitoring
-		graphqlHandler = inputValidator.SecurityValidationMiddlewareWithMonitor(securityMonitor)(graphqlHandler)
-	} else {
-		// Use standard validation middleware
-		graphqlHandler = inputValidator.SecurityValidationMiddleware()(graphqlHandler)
-	}
-	if rateLimiter != nil {
-		graphqlHandler = rateLimiter.GraphQLRateLimitMiddleware()(graphqlHandler)
-	}
-	graphqlHandler = authMiddleware(authService, securityMonitor, graphqlHandler)
-	graphqlHandler = c.Handler(graphqlHandler)
+	// Configuration reload endpoint (admin only)
+	mux.HandleFunc("/config/reload", adminOnly(authService, configReloadHandler(configWatcher)))
+
+	// Migration status endpoint (admin only)
+	mux.HandleFunc("/admin/migrations", adminOnly(authService, migrationsHandler(db.DB, cfg.MigrationsPath)))
 
+	// Persisted query removal endpoint (admin only)
+	mux.HandleFunc("/admin/persisted-queries/", adminOnly(authService, deletePersistedQueryHandler(persistedQueries)))
+
+	// Emergency operation blocklist endpoints (admin only)
+	mux.HandleFunc("/admin/operations/blocklist", adminOnly(authService, operationBlocklistHandler(operationBlocklist)))
+	mux.HandleFunc("/admin/operations/blocklist/", adminOnly(authService, unblockOperationHandler(operationBlocklist)))
+
+	// GraphQL endpoint with full security middleware stack
 	mux.Handle("/query", graphqlHandler)
 
+	// Asset name suggestions, outside GraphQL so that browsers can cache them
+	autocompleteCORS := cors.New(cors.Options{
+		AllowedOrigins:   strings.Split(cfg.CorsOrigins, ","),
+		AllowedMethods:   []string{"GET"},
+		AllowedHeaders:   []string{"Authorization"},
+		AllowCredentials: true,
+		MaxAge:           300,
+	})
+	mux.Handle("/api/assets/autocomplete", autocompleteCORS.Handler(assetAutocompleteHandler(authService, resolver)))
+
+	// Board data exports for analysts, streamed as they are read
+	exportCORS := cors.New(cors.Options{
+		AllowedOrigins:   strings.Split(cfg.CorsOrigins, ","),
+		AllowedMethods:   []string{"GET"},
+		AllowedHeaders:   []string{"Authorization"},
+		ExposedHeaders:   []string{"Content-Disposition"},
+		AllowCredentials: true,
+		MaxAge:           300,
+	})
+	mux.Handle("/export/board/", exportCORS.Handler(boardDataExportHandler(authService, resolver, rateLimiter)))
+
+	// Board export downloads; the signed URL authorizes the request
+	mux.HandleFunc(export.DownloadPath, boardExportHandler(boardExports))
+
+	// Delivery history of board webhooks
+	mux.HandleFunc("/webhooks/board/", boardWebhookDeliveriesHandler(authService, resolver))
+
 	// Add authentication endpoints
 	mux.HandleFunc("/auth/refresh", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -237,9 +701,13 @@ func main() {
 		}
 
 		// Validate refresh token and generate new token pair
-		tokenPair, err := authService.RefreshTokens(request.RefreshToken)
+		tokenPair, err := authService.RefreshTokens(db.DB, request.RefreshToken)
 		if err != nil {
 			log.Printf("Token refresh failed: %v", err)
+			var reuse *auth.TokenReuseError
+			if errors.As(err, &reuse) && securityMonitor != nil {
+				securityMonitor.LogRefreshTokenReuse(reuse.UserID, reuse.FamilyID, r)
+			}
 			http.Error(w, "Invalid refresh token", http.StatusUnauthorized)
 			return
 		}
@@ -248,6 +716,19 @@ func main() {
 		json.NewEncoder(w).Encode(tokenPair)
 	})
 
+	// OAuth2 login with PKCE
+	mux.HandleFunc("/auth/authorize", oauthAuthorizeHandler(pkceFlow))
+	mux.HandleFunc("/auth/callback", oauthCallbackHandler(pkceFlow, authService, db.DB))
+
+	// TOTP two-factor authentication
+	mux.HandleFunc("/auth/totp/enroll", totpEnrollHandler(authService))
+	mux.HandleFunc("/auth/totp/verify", totpVerifyHandler(authService, db.DB))
+	mux.HandleFunc("/auth/totp/disable", totpDisableHandler(authService))
+
+	mux.HandleFunc("/auth/sessions", sessionsHandler(authService))
+	mux.HandleFunc("/auth/sessions/", revokeSessionHandler(authService))
+	mux.HandleFunc("/auth/suspicious-login", suspiciousLoginHandler(authService, securityMonitor, loginRecorder, natsConn))
+
 	mux.HandleFunc("/auth/logout", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -311,6 +792,7 @@ func main() {
 	}
 
 	log.Printf("Starting server on port %s", port)
+	log.Printf("Version: %s (built %s)", Version, BuildTime)
 	log.Printf("Environment: %s", cfg.Environment)
 	log.Printf("CORS origins: %s", cfg.CorsOrigins)
 	
@@ -320,11 +802,77 @@ func main() {
 		log.Println("Rate limiting disabled (Redis unavailable)")
 	}
 
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
+	// Serve Prometheus metrics on their own port, away from the public API
+	go func() {
+		log.Printf("Serving metrics on port %s", cfg.MetricsPort)
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/metrics", metrics.Handler())
+		if err := http.ListenAndServe(":"+cfg.MetricsPort, metricsMux); err != nil {
+			log.Printf("Metrics server failed: %v", err)
+		}
+	}()
+
+	// Blocked IPs are refused before any other middleware runs, and every
+	// request is given an ID first
+	var handler http.Handler = mux
+	if securityMonitor != nil {
+		handler = securityMonitor.BlockMiddleware()(handler)
+	}
+	handler = requestIDMiddleware(handler)
+
+	server := &http.Server{Addr: ":" + port, Handler: handler}
+	go func() {
+		<-ctx.Done()
+		log.Println("Shutting down server")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Failed to shut down server: %v", err)
+		}
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("Server failed to start: %v", err)
 	}
 }
 
+// newAuthService creates the auth service for the RSA key pair when a public key
+// file is configured, or for the Supabase JWT secret otherwise
+func newAuthService(cfg *config.Config) (*auth.Service, error) {
+	if cfg.JWTPublicKeyFile == "" {
+		return auth.NewService(cfg.SupabaseJWTSecret), nil
+	}
+
+	publicKey, err := os.ReadFile(cfg.JWTPublicKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT public key: %w", err)
+	}
+
+	var privateKey []byte
+	if cfg.JWTPrivateKeyFile != "" {
+		privateKey, err = os.ReadFile(cfg.JWTPrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT private key: %w", err)
+		}
+	}
+
+	service, err := auth.NewServiceWithRSAKey(publicKey, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.JWTAlgorithm {
+	case "RS256":
+		service.SigningMethod = jwt.SigningMethodRS256
+	case "RS384":
+		service.SigningMethod = jwt.SigningMethodRS384
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q, use RS256 or RS384", cfg.JWTAlgorithm)
+	}
+
+	return service, nil
+}
+
 // checkCORSOrigin validates WebSocket origin against allowed origins
 func checkCORSOrigin(r *http.Request, allowedOrigins string) bool {
 	origin := r.Header.Get("Origin")
@@ -344,9 +892,10 @@ func checkCORSOrigin(r *http.Request, allowedOrigins string) bool {
 }
 
 // authMiddleware handles JWT authentication with security monitoring
-func authMiddleware(authService *auth.Service, securityMonitor *middleware.SecurityMonitor, next http.Handler) http.Handler {
+func authMiddleware(authService *auth.Service, securityMonitor *middleware.SecurityMonitor, loginRecorder *audit.LoginRecorder, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		// The client is recorded with the mutations of the request
+		ctx := audit.WithRequest(r.Context(), r)
 
 		// Extract token from Authorization header
 		authHeader := r.Header.Get("Authorization")
@@ -354,9 +903,14 @@ func authMiddleware(authService *auth.Service, securityMonitor *middleware.Secur
 			token := strings.TrimPrefix(authHeader, "Bearer ")
 			
 			// Verify token and extract user
-			user, err := authService.VerifyToken(token)
+			user, err := authService.VerifyTokenContext(ctx, token)
+			recordTokenVerification(authService, loginRecorder, securityMonitor, r, token, err)
 			if err == nil && user != nil {
 				ctx = context.WithValue(ctx, "user", user)
+			} else if errors.Is(err, auth.ErrTOTPRequired) {
+				// Tell the client to exchange the token at /auth/totp/verify
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
 			} else {
 				// Log failed authentication attempt
 				if securityMonitor != nil {
@@ -370,22 +924,1365 @@ func authMiddleware(authService *auth.Service, securityMonitor *middleware.Secur
 	})
 }
 
-// getRedisAddr extracts Redis address from configuration
-func getRedisAddr(databaseURL string) string {
-	// This is a simple implementation - in production, you'd have a separate Redis URL
-	// For now, assume Redis is on localhost:6379
-	redisURL := os.Getenv("REDIS_URL")
-	if redisURL == "" {
-		return "localhost:6379"
-	}
-	
-	// Parse Redis URL to extract address
-	if strings.HasPrefix(redisURL, "redis://") {
-		redisURL = strings.TrimPrefix(redisURL, "redis://")
-		if idx := strings.Index(redisURL, "@"); idx != -1 {
-			redisURL = redisURL[idx+1:]
+// websocketInit authenticates subscriptions with the token of the connection_init
+// payload, as browsers cannot set the Authorization header of a WebSocket
+func websocketInit(authService *auth.Service) transport.WebsocketInitFunc {
+	return func(ctx context.Context, initPayload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+		// The upgrade request was already authenticated by authMiddleware
+		if ctx.Value("user") != nil {
+			return ctx, nil, nil
+		}
+
+		token := strings.TrimPrefix(initPayload.Authorization(), "Bearer ")
+		if token == "" {
+			return ctx, nil, nil
+		}
+
+		user, err := authService.VerifyTokenContext(ctx, token)
+		if err != nil {
+			log.Printf("Auth: WebSocket token verification failed: %v", err)
+			return nil, nil, errors.New("invalid token")
 		}
+
+		return context.WithValue(ctx, "user", user), nil, nil
 	}
-	
-	return redisURL
+}
+
+// requestIDMiddleware gives each request a new ID, returned in the X-Request-ID
+// header and recorded in its context along with the time it was received
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedAt := time.Now()
+		requestID := uuid.New().String()
+		w.Header().Set(middleware.RequestIDHeader, requestID)
+
+		next.ServeHTTP(w, r.WithContext(middleware.WithRequestID(r.Context(), requestID, receivedAt)))
+	})
+}
+
+// tracingMiddleware starts the root span of each GraphQL request, continuing the
+// trace of the caller when the request carries W3C trace context headers
+func tracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Tracer.Start(ctx, "graphql.request", trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attribute.String("http.request.method", r.Method), attribute.String("url.path", r.URL.Path)))
+		defer span.End()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// boardExportHandler streams an exported board archive
+func boardExportHandler(store *export.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if store == nil {
+			http.Error(w, "Board export not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		token := strings.TrimPrefix(r.URL.Path, export.DownloadPath)
+		query := r.URL.Query()
+
+		archive, contentType, filename, err := store.Load(r.Context(), token, query.Get("expires"), query.Get("signature"))
+		switch {
+		case errors.Is(err, export.ErrInvalidSignature):
+			http.Error(w, "Invalid or expired download link", http.StatusForbidden)
+			return
+		case errors.Is(err, export.ErrTokenNotFound):
+			http.Error(w, "Export not found or already downloaded", http.StatusNotFound)
+			return
+		case err != nil:
+			log.Printf("Board export download failed: %v", err)
+			http.Error(w, "Failed to load export", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		w.Header().Set("Content-Length", strconv.Itoa(len(archive)))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if _, err := io.Copy(w, bytes.NewReader(archive)); err != nil {
+			log.Printf("Failed to stream board export: %v", err)
+		}
+	}
+}
+
+// oauthAuthorizeHandler starts a login with the OAuth2 provider and redirects
+// the user to it
+func oauthAuthorizeHandler(flow *oauth.PKCEFlow) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if flow == nil {
+			http.Error(w, "OAuth login not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		authorizeURL, err := flow.Start(r.Context())
+		if err != nil {
+			log.Printf("OAuth login failed to start: %v", err)
+			http.Error(w, "Failed to start login", http.StatusInternalServerError)
+			return
+		}
+
+		http.Redirect(w, r, authorizeURL, http.StatusFound)
+	}
+}
+
+// oauthCallbackHandler completes a login with the OAuth2 provider and returns a
+// BFF token pair for the user, who is created on their first login
+func oauthCallbackHandler(flow *oauth.PKCEFlow, authService *auth.Service, db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if flow == nil {
+			http.Error(w, "OAuth login not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		query := r.URL.Query()
+		if providerErr := query.Get("error"); providerErr != "" {
+			http.Error(w, "Login denied by provider: "+providerErr, http.StatusBadRequest)
+			return
+		}
+		if query.Get("code") == "" {
+			http.Error(w, "Missing authorization code", http.StatusBadRequest)
+			return
+		}
+
+		identity, err := flow.Exchange(r.Context(), query.Get("state"), query.Get("code"))
+		if errors.Is(err, oauth.ErrInvalidState) {
+			http.Error(w, "Invalid or expired login attempt", http.StatusBadRequest)
+			return
+		} else if err != nil {
+			log.Printf("OAuth login failed: %v", err)
+			http.Error(w, "Login failed", http.StatusUnauthorized)
+			return
+		}
+
+		userID, role, err := oauthUser(r.Context(), db, identity)
+		if err != nil {
+			log.Printf("OAuth login failed: %v", err)
+			http.Error(w, "Login failed", http.StatusInternalServerError)
+			return
+		}
+
+		tokenPair, err := authService.GenerateDeviceTokenPair(db, userID, identity.Email, role, auth.DeviceID(r))
+		if err != nil {
+			log.Printf("OAuth login failed to issue tokens: %v", err)
+			http.Error(w, "Login failed", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(tokenPair)
+	}
+}
+
+// bearerToken returns the token of the Authorization header of r
+func bearerToken(r *http.Request) (string, bool) {
+	authHeader := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return "", false
+	}
+	return strings.TrimPrefix(authHeader, "Bearer "), true
+}
+
+// adminOnly serves the requests of next whose bearer token belongs to an admin
+func adminOnly(authService *auth.Service, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			http.Error(w, "Missing authorization header", http.StatusUnauthorized)
+			return
+		}
+
+		user, err := authService.VerifyToken(token)
+		if err != nil || user.Role != "admin" {
+			http.Error(w, "Unauthorized", http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// blockIPRequest is the body of the block IP endpoint
+type blockIPRequest struct {
+	IP              string `json:"ip"`
+	DurationMinutes int    `json:"duration_minutes"`
+}
+
+// blockIPHandler refuses the requests of an IP address for a number of minutes
+func blockIPHandler(securityMonitor *middleware.SecurityMonitor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if securityMonitor == nil {
+			http.Error(w, "Security monitoring not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		var request blockIPRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if net.ParseIP(request.IP) == nil {
+			http.Error(w, "Invalid IP address", http.StatusBadRequest)
+			return
+		}
+		if request.DurationMinutes <= 0 {
+			http.Error(w, "duration_minutes must be positive", http.StatusBadRequest)
+			return
+		}
+
+		duration := time.Duration(request.DurationMinutes) * time.Minute
+		if err := securityMonitor.BlockIP(r.Context(), request.IP, duration); err != nil {
+			log.Printf("Failed to block IP %s: %v", request.IP, err)
+			http.Error(w, "Failed to block IP", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(middleware.BlockedIP{
+			IP:         request.IP,
+			TTLSeconds: int64(duration.Seconds()),
+		})
+	}
+}
+
+// blockedIPsHandler serves the blocked IP addresses and the seconds left until
+// they are unblocked
+func blockedIPsHandler(securityMonitor *middleware.SecurityMonitor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if securityMonitor == nil {
+			http.Error(w, "Security monitoring not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		blocked, err := securityMonitor.BlockedIPs(r.Context())
+		if err != nil {
+			log.Printf("Failed to list blocked IPs: %v", err)
+			http.Error(w, "Blocked IPs unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"blocked_ips": blocked})
+	}
+}
+
+// siemBacklogHandler serves the number of security alerts the SIEM could not be
+// sent and the oldest of them
+func siemBacklogHandler(siemForwarder *middleware.SIEMForwarder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if siemForwarder == nil {
+			http.Error(w, "SIEM forwarding not configured", http.StatusServiceUnavailable)
+			return
+		}
+
+		backlog, err := siemForwarder.Backlog(r.Context())
+		if err != nil {
+			log.Printf("Failed to read SIEM backlog: %v", err)
+			http.Error(w, "SIEM backlog unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(backlog)
+	}
+}
+
+// siemFlushHandler starts posting the backlogged security alerts to the SIEM
+// again, in the background
+func siemFlushHandler(siemForwarder *middleware.SIEMForwarder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if siemForwarder == nil {
+			http.Error(w, "SIEM forwarding not configured", http.StatusServiceUnavailable)
+			return
+		}
+
+		if !siemForwarder.StartFlush() {
+			http.Error(w, "SIEM backlog flush already running", http.StatusConflict)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{"flushing": true})
+	}
+}
+
+// geoIPHandler returns the country detected for the IP address at the end of the
+// path and whether its requests are blocked
+func geoIPHandler(securityMonitor *middleware.SecurityMonitor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if securityMonitor == nil {
+			http.Error(w, "Security monitoring not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		ip := strings.TrimPrefix(r.URL.Path, "/security/geoip/")
+		if net.ParseIP(ip) == nil {
+			http.Error(w, "Invalid IP address", http.StatusBadRequest)
+			return
+		}
+
+		blocked, country, err := securityMonitor.IsCountryBlocked(r.Context(), ip)
+		if err != nil {
+			log.Printf("Failed to look up country of %s: %v", ip, err)
+			http.Error(w, "GeoIP lookup unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"ip":      ip,
+			"country": country,
+			"blocked": blocked,
+		})
+	}
+}
+
+// securityConfigHandler serves the security alert thresholds and replaces them
+// on every replica
+func securityConfigHandler(securityMonitor *middleware.SecurityMonitor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPut {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if securityMonitor == nil {
+			http.Error(w, "Security monitoring not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		if r.Method == http.MethodPut {
+			var securityConfig middleware.SecurityConfig
+			if err := json.NewDecoder(r.Body).Decode(&securityConfig); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+			if err := securityConfig.Validate(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := securityMonitor.UpdateConfig(r.Context(), securityConfig); err != nil {
+				log.Printf("Failed to update security config: %v", err)
+				http.Error(w, "Failed to update security config", http.StatusServiceUnavailable)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(securityMonitor.SecurityConfig())
+	}
+}
+
+// addSecurityPatternHandler adds a SQL injection or XSS pattern that input is
+// rejected for
+func addSecurityPatternHandler(registry *middleware.PatternRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var pattern middleware.SecurityPattern
+		if err := json.NewDecoder(r.Body).Decode(&pattern); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if pattern.Severity == "" {
+			pattern.Severity = "high"
+		}
+		if err := pattern.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		added, err := registry.AddPattern(r.Context(), pattern)
+		if err != nil {
+			log.Printf("Failed to add security pattern: %v", err)
+			http.Error(w, "Failed to add security pattern", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(added)
+	}
+}
+
+// deactivateSecurityPatternHandler stops rejecting input for the pattern whose
+// ID ends the path
+func deactivateSecurityPatternHandler(registry *middleware.PatternRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, "/security/patterns/")
+		if _, err := uuid.Parse(id); err != nil {
+			http.Error(w, "Invalid pattern ID", http.StatusBadRequest)
+			return
+		}
+
+		err := registry.DeactivatePattern(r.Context(), id)
+		if errors.Is(err, middleware.ErrPatternNotFound) {
+			http.Error(w, "Security pattern not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Printf("Failed to deactivate security pattern %s: %v", id, err)
+			http.Error(w, "Failed to deactivate security pattern", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// configReloadHandler reads the configuration immediately and returns the
+// hot-reloadable settings that changed
+func configReloadHandler(watcher *config.Watcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		changed, err := watcher.Reload(r.Context())
+		if err != nil {
+			log.Printf("Failed to reload configuration: %v", err)
+			http.Error(w, "Failed to reload configuration", http.StatusInternalServerError)
+			return
+		}
+		if changed == nil {
+			changed = []string{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"changed": changed})
+	}
+}
+
+// migrationsHandler serves the migration version of the schema and the names
+// of the migrations not applied yet
+func migrationsHandler(db *sql.DB, migrationsDir string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		state, err := database.MigrationStatus(db, migrationsDir)
+		if err != nil {
+			log.Printf("Failed to read migration status: %v", err)
+			http.Error(w, "Migration status unavailable", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(state)
+	}
+}
+
+// deletePersistedQueryHandler removes the persisted query whose hash ends the
+// path, such as a malicious query, so that clients have to send it in full again
+func deletePersistedQueryHandler(persistedQueries *middleware.PersistedQueryCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		hash := strings.TrimPrefix(r.URL.Path, "/admin/persisted-queries/")
+		if !middleware.ValidPersistedQueryHash(hash) {
+			http.Error(w, "Invalid query hash", http.StatusBadRequest)
+			return
+		}
+
+		deleted, err := persistedQueries.Delete(r.Context(), hash)
+		if err != nil {
+			log.Printf("Failed to delete persisted query %s: %v", hash, err)
+			http.Error(w, "Failed to delete persisted query", http.StatusServiceUnavailable)
+			return
+		}
+		if !deleted {
+			http.Error(w, "Persisted query not found", http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// blockOperationRequest is the body of the block operation endpoint
+type blockOperationRequest struct {
+	OperationName string `json:"operation_name"`
+}
+
+// operationBlocklistHandler serves the names of the blocked GraphQL operations
+// on GET, and blocks the operation named in the body on POST
+func operationBlocklistHandler(blocklist *middleware.OperationBlocklist) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			names, err := blocklist.List(r.Context())
+			if err != nil {
+				log.Printf("Failed to list blocked operations: %v", err)
+				http.Error(w, "Blocked operations unavailable", http.StatusServiceUnavailable)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"blocked_operations": names})
+		case http.MethodPost:
+			var request blockOperationRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+			if err := middleware.ValidateOperationName(request.OperationName); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if err := blocklist.Block(r.Context(), request.OperationName); err != nil {
+				log.Printf("Failed to block operation %s: %v", request.OperationName, err)
+				http.Error(w, "Failed to block operation", http.StatusServiceUnavailable)
+				return
+			}
+			log.Printf("Blocked GraphQL operation %s", request.OperationName)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(request)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// unblockOperationHandler lets the operation whose name ends the path run again
+func unblockOperationHandler(blocklist *middleware.OperationBlocklist) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := strings.TrimPrefix(r.URL.Path, "/admin/operations/blocklist/")
+		unblocked, err := blocklist.Unblock(r.Context(), name)
+		if err != nil {
+			log.Printf("Failed to unblock operation %s: %v", name, err)
+			http.Error(w, "Failed to unblock operation", http.StatusServiceUnavailable)
+			return
+		}
+		if !unblocked {
+			http.Error(w, "Operation not blocked", http.StatusNotFound)
+			return
+		}
+		log.Printf("Unblocked GraphQL operation %s", name)
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// reloadableHandler serves requests with the handler built for the current
+// configuration, which is swapped when the configuration is reloaded
+type reloadableHandler struct {
+	mu      sync.RWMutex
+	config  config.Config
+	handler http.Handler
+}
+
+func newReloadableHandler(cfg *config.Config, handler http.Handler) *reloadableHandler {
+	return &reloadableHandler{config: *cfg, handler: handler}
+}
+
+func (h *reloadableHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	handler := h.handler
+	h.mu.RUnlock()
+
+	handler.ServeHTTP(w, r)
+}
+
+// Swap replaces the configuration and the handler serving new requests
+func (h *reloadableHandler) Swap(cfg *config.Config, handler http.Handler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.config = *cfg
+	h.handler = handler
+}
+
+// Config returns the configuration of the current handler
+func (h *reloadableHandler) Config() config.Config {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.config
+}
+
+// totpCodeRequest is the body of the TOTP verify and disable endpoints
+type totpCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// totpEnrollHandler generates a TOTP secret for the authenticated user and returns
+// its provisioning URI and QR code
+func totpEnrollHandler(authService *auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			http.Error(w, "Missing authorization header", http.StatusUnauthorized)
+			return
+		}
+		user, err := authService.VerifyTokenContext(r.Context(), token)
+		if err != nil {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		enrollment, err := authService.EnrollTOTP(r.Context(), user)
+		if errors.Is(err, auth.ErrTOTPNotConfigured) {
+			http.Error(w, "Two-factor authentication not available", http.StatusServiceUnavailable)
+			return
+		} else if errors.Is(err, auth.ErrTOTPEnabled) {
+			http.Error(w, "TOTP is already enabled", http.StatusConflict)
+			return
+		} else if err != nil {
+			log.Printf("TOTP enrollment failed: %v", err)
+			http.Error(w, "TOTP enrollment failed", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(enrollment)
+	}
+}
+
+// totpVerifyHandler checks a TOTP code of the user of the token and returns a
+// TOTP verified token pair. The token is accepted without the totp_verified claim.
+func totpVerifyHandler(authService *auth.Service, db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			http.Error(w, "Missing authorization header", http.StatusUnauthorized)
+			return
+		}
+
+		var request totpCodeRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		tokenPair, err := authService.VerifyTOTP(r.Context(), db, token, request.Code)
+		if errors.Is(err, auth.ErrTOTPNotConfigured) {
+			http.Error(w, "Two-factor authentication not available", http.StatusServiceUnavailable)
+			return
+		} else if errors.Is(err, auth.ErrTOTPLocked) {
+			http.Error(w, "Too many invalid TOTP codes, try again later", http.StatusTooManyRequests)
+			return
+		} else if errors.Is(err, auth.ErrTOTPNotEnrolled) {
+			http.Error(w, "TOTP is not enrolled", http.StatusBadRequest)
+			return
+		} else if err != nil {
+			log.Printf("TOTP verification failed: %v", err)
+			http.Error(w, "TOTP verification failed", http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(tokenPair)
+	}
+}
+
+// totpDisableHandler turns off two-factor authentication for the authenticated
+// user after checking their current TOTP code
+func totpDisableHandler(authService *auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			http.Error(w, "Missing authorization header", http.StatusUnauthorized)
+			return
+		}
+		user, err := authService.VerifyTokenContext(r.Context(), token)
+		if err != nil {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		var request totpCodeRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		err = authService.DisableTOTP(r.Context(), user, request.Code)
+		if errors.Is(err, auth.ErrTOTPNotConfigured) {
+			http.Error(w, "Two-factor authentication not available", http.StatusServiceUnavailable)
+			return
+		} else if errors.Is(err, auth.ErrTOTPNotEnrolled) {
+			http.Error(w, "TOTP is not enrolled", http.StatusBadRequest)
+			return
+		} else if errors.Is(err, auth.ErrInvalidTOTPCode) {
+			http.Error(w, "Invalid TOTP code", http.StatusUnauthorized)
+			return
+		} else if errors.Is(err, auth.ErrTOTPLocked) {
+			http.Error(w, "Too many invalid TOTP codes, try again later", http.StatusTooManyRequests)
+			return
+		} else if err != nil {
+			log.Printf("Disabling TOTP failed: %v", err)
+			http.Error(w, "Disabling TOTP failed", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "totp disabled"})
+	}
+}
+
+// sessionsHandler lists the devices the authenticated user is logged in on
+func sessionsHandler(authService *auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			http.Error(w, "Missing authorization header", http.StatusUnauthorized)
+			return
+		}
+		user, err := authService.VerifyTokenContext(r.Context(), token)
+		if err != nil {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		sessions, err := authService.Sessions(r.Context(), user.ID)
+		if err != nil {
+			log.Printf("Failed to list sessions: %v", err)
+			http.Error(w, "Sessions unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"sessions": sessions})
+	}
+}
+
+// revokeSessionHandler terminates the session of the authenticated user on the
+// device whose ID ends the path
+func revokeSessionHandler(authService *auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		deviceID := strings.TrimPrefix(r.URL.Path, "/auth/sessions/")
+		if deviceID == "" || strings.Contains(deviceID, "/") {
+			http.Error(w, "Invalid device ID", http.StatusBadRequest)
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			http.Error(w, "Missing authorization header", http.StatusUnauthorized)
+			return
+		}
+		user, err := authService.VerifyTokenContext(r.Context(), token)
+		if err != nil {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		revoked, err := authService.RevokeSession(r.Context(), user.ID, deviceID)
+		if err != nil {
+			log.Printf("Failed to revoke session: %v", err)
+			http.Error(w, "Failed to revoke session", http.StatusServiceUnavailable)
+			return
+		}
+		if !revoked {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "session revoked"})
+	}
+}
+
+// suspiciousLoginHandler records a login the client detected from a device it
+// does not recognize and has the user warned by email
+func suspiciousLoginHandler(authService *auth.Service, securityMonitor *middleware.SecurityMonitor, loginRecorder *audit.LoginRecorder, natsConn *nats.Conn) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			http.Error(w, "Missing authorization header", http.StatusUnauthorized)
+			return
+		}
+		user, err := authService.VerifyTokenContext(r.Context(), token)
+		if err != nil {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		// The device is optional, as described by the client
+		var request struct {
+			Device string `json:"device"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+		}
+
+		ip, country, city := locateClient(securityMonitor, r)
+		if err := loginRecorder.Record(&audit.LoginEvent{
+			UserID:    user.ID,
+			EventType: audit.LoginEventSuspiciousLogin,
+			IPAddress: ip,
+			UserAgent: r.UserAgent(),
+			Country:   country,
+			City:      city,
+			Success:   true,
+		}); err != nil {
+			log.Printf("Failed to record suspicious login of user %s: %v", user.ID, err)
+		}
+
+		err = natsConn.PublishSuspiciousLogin(&nats.SuspiciousLoginEvent{
+			EventType: "suspicious_login",
+			UserID:    user.ID,
+			Email:     user.Email,
+			Device:    request.Device,
+			IPAddress: ip,
+			UserAgent: r.UserAgent(),
+			Country:   country,
+			City:      city,
+			Timestamp: time.Now(),
+		})
+		if err != nil {
+			log.Printf("Failed to publish suspicious login of user %s: %v", user.ID, err)
+			http.Error(w, "Failed to send notification", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{"status": "notification sent"})
+	}
+}
+
+// recordTokenVerification records the verification of the access token of r in
+// the login history of the user it names. Failed verifications are attributed
+// to the user the token is issued to only when its signature is valid.
+func recordTokenVerification(authService *auth.Service, loginRecorder *audit.LoginRecorder, securityMonitor *middleware.SecurityMonitor, r *http.Request, token string, verifyErr error) {
+	ip, country, city := locateClient(securityMonitor, r)
+	event := &audit.LoginEvent{
+		UserID:    authService.TokenSubject(token),
+		EventType: audit.LoginEventTokenVerification,
+		IPAddress: ip,
+		UserAgent: r.UserAgent(),
+		Country:   country,
+		City:      city,
+		Success:   verifyErr == nil,
+	}
+	if verifyErr != nil {
+		event.FailureReason = verifyErr.Error()
+	}
+
+	if err := loginRecorder.Record(event); err != nil {
+		log.Printf("Failed to record token verification: %v", err)
+	}
+}
+
+// locateClient returns the address of the client of r with its country and
+// city, which are only known with the GeoIP database of the security monitor
+func locateClient(securityMonitor *middleware.SecurityMonitor, r *http.Request) (ip, country, city string) {
+	if securityMonitor == nil {
+		return audit.ClientIP(r), "", ""
+	}
+	return securityMonitor.LocateClient(r)
+}
+
+// oauthUser returns the ID and role of the user with the provider's verified email,
+// creating the user if needed
+func oauthUser(ctx context.Context, db *sql.DB, identity *oauth.Identity) (string, string, error) {
+	var name *string
+	if identity.Name != "" {
+		name = &identity.Name
+	}
+
+	var userID, role string
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO users (email, name) VALUES ($1, $2)
+		ON CONFLICT (email) DO UPDATE SET name = COALESCE(users.name, EXCLUDED.name), updated_at = NOW()
+		RETURNING id, role
+	`, identity.Email, name).Scan(&userID, &role)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to save user %s: %w", identity.Email, err)
+	}
+
+	return userID, role, nil
+}
+
+// healthDependencies returns the services the BFF depends on, the read replica
+// included when one is configured
+func healthDependencies(db *database.Pool, redisClient redis.UniversalClient, natsConn *nats.Conn) []health.Dependency {
+	dependencies := []health.Dependency{
+		{Name: "database", Check: db.Writer().PingContext},
+		{Name: "redis", Check: func(ctx context.Context) error {
+			// The BFF runs without Redis, with fewer features, when it is not configured
+			if redisClient == nil {
+				return health.Degraded("not configured")
+			}
+			return redisClient.Ping(ctx).Err()
+		}},
+		{Name: "nats", Check: func(ctx context.Context) error {
+			if !natsConn.Conn.IsConnected() {
+				return fmt.Errorf("%s", strings.ToLower(natsConn.Status().String()))
+			}
+			return nil
+		}},
+	}
+
+	// The replica is checked separately, as reads keep failing while it is down
+	if db.HasReplica() {
+		dependencies = append(dependencies, health.Dependency{Name: "database_replica", Check: db.Reader().PingContext})
+	}
+
+	return dependencies
+}
+
+// healthReadinessHandler checks the dependencies of the BFF and responds with
+// 503 Service Unavailable when any of them cannot be reached. Degraded
+// dependencies make the BFF degraded but still ready.
+func healthReadinessHandler(dependencies []health.Dependency, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		services := health.CheckDependencies(r.Context(), timeout, dependencies)
+		status, code := health.StatusHealthy, http.StatusOK
+		for _, service := range services {
+			if service.Unhealthy() {
+				status, code = health.StatusUnhealthy, http.StatusServiceUnavailable
+			} else if !service.Healthy() && code == http.StatusOK {
+				status = health.StatusDegraded
+			}
+		}
+
+		healthStatus := map[string]interface{}{
+			"status":    status,
+			"timestamp": time.Now().Format(time.RFC3339),
+			"version":   Version,
+			"service":   "ZAMC BFF GraphQL API",
+			"services":  services,
+			"uptime":    time.Since(startTime).String(),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(healthStatus)
+	}
+}
+
+// assetAutocompleteHandler suggests the assets of the project ?projectID= whose
+// name starts with ?q=, up to ?limit= of them
+func assetAutocompleteHandler(authService *auth.Service, resolver *graph.Resolver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			http.Error(w, "Missing authorization header", http.StatusUnauthorized)
+			return
+		}
+		user, err := authService.VerifyTokenContext(r.Context(), token)
+		if err != nil {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		query := r.URL.Query()
+		projectID := query.Get("projectID")
+		if _, err := uuid.Parse(projectID); err != nil {
+			http.Error(w, "Invalid projectID", http.StatusBadRequest)
+			return
+		}
+		limit := 10
+		if value := query.Get("limit"); value != "" {
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit < 1 || limit > autocomplete.MaxLimit {
+				http.Error(w, fmt.Sprintf("limit must be between 1 and %d", autocomplete.MaxLimit), http.StatusBadRequest)
+				return
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), "user", user)
+		suggestions, err := resolver.AutocompleteAssets(ctx, projectID, query.Get("q"), limit)
+		switch {
+		case errors.Is(err, graph.ErrAutocompleteUnavailable):
+			http.Error(w, "Asset autocomplete unavailable", http.StatusServiceUnavailable)
+			return
+		case errors.Is(err, graph.ErrProjectNotFound):
+			http.Error(w, "Project not found", http.StatusNotFound)
+			return
+		case graph.IsForbidden(err):
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		case err != nil:
+			log.Printf("Failed to suggest assets: %v", err)
+			http.Error(w, "Asset autocomplete unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		// Suggestions depend on the user, and may be reused while they type
+		w.Header().Set("Cache-Control", "private, max-age=10")
+		w.Header().Set("Vary", "Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(suggestions)
+	}
+}
+
+// boardDataExportHandler streams the board at the end of the path with its
+// assets and chat messages, as JSON or as CSV files in a ZIP archive
+func boardDataExportHandler(authService *auth.Service, resolver *graph.Resolver, rateLimiter *middleware.RateLimiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			http.Error(w, "Missing authorization header", http.StatusUnauthorized)
+			return
+		}
+		user, err := authService.VerifyTokenContext(r.Context(), token)
+		if err != nil {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		boardID := strings.TrimPrefix(r.URL.Path, "/export/board/")
+		if _, err := uuid.Parse(boardID); err != nil {
+			http.Error(w, "Invalid board ID", http.StatusBadRequest)
+			return
+		}
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = export.FormatJSON
+		}
+		if format != export.FormatJSON && format != export.FormatCSV {
+			http.Error(w, "format must be json or csv", http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), "user", user)
+		source, err := resolver.OpenBoardDataExport(ctx, boardID)
+		switch {
+		case errors.Is(err, graph.ErrBoardNotFound), errors.Is(err, graph.ErrProjectNotFound):
+			http.Error(w, "Board not found", http.StatusNotFound)
+			return
+		case graph.IsForbidden(err):
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		case err != nil:
+			log.Printf("Failed to open export of board %s: %v", boardID, err)
+			http.Error(w, "Failed to export board", http.StatusInternalServerError)
+			return
+		}
+
+		// Only exports the user may download count against their limit
+		if rateLimiter != nil {
+			allowed, retryAfter, err := rateLimiter.AllowExport(ctx, user.ID)
+			if err != nil {
+				log.Printf("Failed to rate limit board export: %v", err)
+				http.Error(w, "Rate limiting error", http.StatusInternalServerError)
+				return
+			}
+			if !allowed {
+				w.Header().Set("Retry-After", strconv.FormatInt(int64(retryAfter.Seconds()), 10))
+				http.Error(w, "Export rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+		}
+
+		exportedAt := time.Now()
+		if format == export.FormatCSV {
+			w.Header().Set("Content-Type", "application/zip")
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.DataFileName(boardID, format, exportedAt)))
+
+		// Without a Content-Length, the response is sent with chunked transfer
+		// encoding as the chunks are flushed
+		out := export.NewChunkedWriter(w)
+		if format == export.FormatCSV {
+			err = export.WriteBoardCSV(out, source)
+		} else {
+			err = export.WriteBoardJSON(out, source, exportedAt)
+		}
+		if err == nil {
+			err = out.Flush()
+		}
+		if err != nil {
+			log.Printf("Failed to stream export of board %s: %v", boardID, err)
+			// Once a chunk has been sent, the client only sees the download cut short
+			if !out.Started() {
+				w.Header().Del("Content-Disposition")
+				http.Error(w, "Failed to export board", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		if err := resolver.RecordBoardDataExport(r.Context(), source, format); err != nil {
+			log.Printf("Failed to audit export of board %s: %v", boardID, err)
+		}
+	}
+}
+
+// boardWebhookDeliveriesHandler lists the latest attempts to deliver events to
+// the board webhook at /webhooks/board/{webhookID}/deliveries, up to ?limit= of
+// them, with the status codes and bodies of the responses
+func boardWebhookDeliveriesHandler(authService *auth.Service, resolver *graph.Resolver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		webhookID, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/webhooks/board/"), "/deliveries")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if _, err := uuid.Parse(webhookID); err != nil {
+			http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			http.Error(w, "Missing authorization header", http.StatusUnauthorized)
+			return
+		}
+		user, err := authService.VerifyTokenContext(r.Context(), token)
+		if err != nil {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		limit := 50
+		if value := r.URL.Query().Get("limit"); value != "" {
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit < 1 || limit > graph.MaxBoardWebhookDeliveries {
+				http.Error(w, fmt.Sprintf("limit must be between 1 and %d", graph.MaxBoardWebhookDeliveries), http.StatusBadRequest)
+				return
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), "user", user)
+		deliveries, err := resolver.BoardWebhookDeliveries(ctx, webhookID, limit)
+		switch {
+		case errors.Is(err, graph.ErrBoardWebhookNotFound), errors.Is(err, graph.ErrBoardNotFound), errors.Is(err, graph.ErrProjectNotFound):
+			http.Error(w, "Webhook not found", http.StatusNotFound)
+			return
+		case graph.IsForbidden(err):
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		case err != nil:
+			log.Printf("Failed to list deliveries of board webhook %s: %v", webhookID, err)
+			http.Error(w, "Failed to list webhook deliveries", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(deliveries)
+	}
+}
+
+// healthHistoryHandler serves the health snapshots recorded between the start
+// and end query parameters
+func healthHistoryHandler(recorder *health.HealthRecorder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if recorder == nil {
+			http.Error(w, "Health history unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		start, end, err := healthTimeRange(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		snapshots, err := recorder.History(r.Context(), start, end)
+		if err != nil {
+			log.Printf("Failed to read health history: %v", err)
+			http.Error(w, "Health history unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"snapshots": snapshots})
+	}
+}
+
+// healthIncidentsHandler serves the transitions from healthy to unhealthy in
+// the health timeline
+func healthIncidentsHandler(recorder *health.HealthRecorder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if recorder == nil {
+			http.Error(w, "Health history unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		start, end, err := healthTimeRange(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		incidents, err := recorder.Incidents(r.Context(), start, end)
+		if err != nil {
+			log.Printf("Failed to read health incidents: %v", err)
+			http.Error(w, "Health history unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"incidents": incidents})
+	}
+}
+
+// healthTimeRange returns the period given by the start and end query parameters,
+// in Unix seconds, of a health history request. It defaults to the retention period.
+func healthTimeRange(r *http.Request) (time.Time, time.Time, error) {
+	end := time.Now()
+	if value := r.URL.Query().Get("end"); value != "" {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end: %s", value)
+		}
+		end = time.Unix(seconds, 0)
+	}
+
+	start := end.Add(-health.Retention)
+	if value := r.URL.Query().Get("start"); value != "" {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start: %s", value)
+		}
+		start = time.Unix(seconds, 0)
+	}
+
+	if start.After(end) {
+		return time.Time{}, time.Time{}, errors.New("start is after end")
+	}
+
+	return start, end, nil
+}
+
+// getRedisAddrs returns the addresses in REDIS_URL, which lists the nodes of a
+// Redis Cluster when it holds several comma-separated URLs
+func getRedisAddrs() []string {
+	// Assume Redis is on localhost:6379 unless configured
+	redisURL := os.Getenv("REDIS_URL")
+	if redisURL == "" {
+		return []string{"localhost:6379"}
+	}
+
+	var addrs []string
+	for _, addr := range strings.Split(redisURL, ",") {
+		addr = strings.TrimSpace(addr)
+
+		// Parse Redis URL to extract address
+		if strings.HasPrefix(addr, "redis://") {
+			addr = strings.TrimPrefix(addr, "redis://")
+			if idx := strings.Index(addr, "@"); idx != -1 {
+				addr = addr[idx+1:]
+			}
+		}
+
+		if addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	if len(addrs) == 0 {
+		return []string{"localhost:6379"}
+	}
+
+	return addrs
 } 
\ No newline at end of file
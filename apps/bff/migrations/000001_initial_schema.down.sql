@@ -0,0 +1,15 @@
+DROP TRIGGER IF EXISTS update_assets_updated_at ON assets;
+DROP TRIGGER IF EXISTS update_boards_updated_at ON boards;
+DROP TRIGGER IF EXISTS update_projects_updated_at ON projects;
+DROP TRIGGER IF EXISTS update_users_updated_at ON users;
+DROP FUNCTION IF EXISTS update_updated_at_column();
+
+DROP TABLE IF EXISTS chat_messages;
+DROP TABLE IF EXISTS assets;
+DROP TABLE IF EXISTS boards;
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS users;
+
+DROP TYPE IF EXISTS asset_status;
+DROP TYPE IF EXISTS asset_type;
+DROP TYPE IF EXISTS project_status;
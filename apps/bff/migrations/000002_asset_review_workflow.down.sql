@@ -0,0 +1,12 @@
+ALTER TABLE assets DROP COLUMN IF EXISTS meta_campaign_id;
+
+-- PostgreSQL cannot drop enum values, so the asset_status type is rebuilt
+-- after moving rows in the new statuses back to their closest legacy value
+ALTER TABLE assets ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE assets ALTER COLUMN status TYPE TEXT;
+UPDATE assets SET status = 'PENDING' WHERE status IN ('DRAFT', 'REVIEW');
+UPDATE assets SET status = 'APPROVED' WHERE status IN ('DEPLOYED', 'FAILED');
+DROP TYPE asset_status;
+CREATE TYPE asset_status AS ENUM ('PENDING', 'APPROVED', 'REJECTED', 'REVISION_REQUIRED');
+ALTER TABLE assets ALTER COLUMN status TYPE asset_status USING status::asset_status;
+ALTER TABLE assets ALTER COLUMN status SET DEFAULT 'PENDING';
@@ -0,0 +1,8 @@
+-- Asset review workflow statuses
+ALTER TYPE asset_status ADD VALUE IF NOT EXISTS 'DRAFT';
+ALTER TYPE asset_status ADD VALUE IF NOT EXISTS 'REVIEW';
+ALTER TYPE asset_status ADD VALUE IF NOT EXISTS 'DEPLOYED';
+ALTER TYPE asset_status ADD VALUE IF NOT EXISTS 'FAILED';
+
+-- Meta campaign an asset was deployed to
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS meta_campaign_id VARCHAR(255);
@@ -0,0 +1,18 @@
+DROP POLICY IF EXISTS chat_message_isolation ON chat_messages;
+DROP POLICY IF EXISTS asset_isolation ON assets;
+DROP POLICY IF EXISTS board_isolation ON boards;
+DROP POLICY IF EXISTS project_isolation ON projects;
+
+ALTER TABLE chat_messages DISABLE ROW LEVEL SECURITY;
+ALTER TABLE assets DISABLE ROW LEVEL SECURITY;
+ALTER TABLE boards DISABLE ROW LEVEL SECURITY;
+ALTER TABLE projects DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION IF EXISTS app_current_user_id();
+
+DROP TABLE IF EXISTS project_members;
+
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE SELECT, INSERT, UPDATE, DELETE ON TABLES FROM zamc_app;
+REVOKE ALL ON ALL TABLES IN SCHEMA public FROM zamc_app;
+REVOKE USAGE ON SCHEMA public FROM zamc_app;
+DROP ROLE IF EXISTS zamc_app;
@@ -0,0 +1,54 @@
+-- Project membership grants access to projects the user does not own
+CREATE TABLE IF NOT EXISTS project_members (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL DEFAULT 'member',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_members_user_id ON project_members(user_id);
+
+-- Role assumed by request transactions. It does not own the tables and has no
+-- BYPASSRLS, so policies apply even when the service connects as the owner.
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'zamc_app') THEN
+        CREATE ROLE zamc_app NOLOGIN;
+    END IF;
+END $$;
+
+GRANT zamc_app TO CURRENT_USER;
+GRANT USAGE ON SCHEMA public TO zamc_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO zamc_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO zamc_app;
+
+-- Current user of the request transaction, NULL outside of one
+CREATE OR REPLACE FUNCTION app_current_user_id()
+RETURNS UUID AS $$
+    SELECT NULLIF(current_setting('app.current_user_id', true), '')::uuid
+$$ LANGUAGE sql STABLE;
+
+ALTER TABLE projects ENABLE ROW LEVEL SECURITY;
+ALTER TABLE boards ENABLE ROW LEVEL SECURITY;
+ALTER TABLE assets ENABLE ROW LEVEL SECURITY;
+ALTER TABLE chat_messages ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS project_isolation ON projects;
+CREATE POLICY project_isolation ON projects
+    USING (
+        owner_id = app_current_user_id()
+        OR id IN (SELECT project_id FROM project_members WHERE user_id = app_current_user_id())
+    );
+
+-- Child tables inherit visibility from the project policy through their subqueries
+DROP POLICY IF EXISTS board_isolation ON boards;
+CREATE POLICY board_isolation ON boards
+    USING (project_id IN (SELECT id FROM projects));
+
+DROP POLICY IF EXISTS asset_isolation ON assets;
+CREATE POLICY asset_isolation ON assets
+    USING (board_id IN (SELECT id FROM boards));
+
+DROP POLICY IF EXISTS chat_message_isolation ON chat_messages;
+CREATE POLICY chat_message_isolation ON chat_messages
+    USING (board_id IN (SELECT id FROM boards));
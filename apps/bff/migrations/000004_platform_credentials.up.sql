@@ -0,0 +1,17 @@
+-- Per-tenant advertising platform credentials. A tenant is the user owning the
+-- projects being deployed. Values are AES-256-GCM encrypted by the connectors
+-- service, which is the only holder of the master key.
+CREATE TABLE IF NOT EXISTS platform_credentials (
+    tenant_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform VARCHAR(50) NOT NULL,
+    credentials_json_encrypted TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, platform)
+);
+
+DROP TRIGGER IF EXISTS update_platform_credentials_updated_at ON platform_credentials;
+CREATE TRIGGER update_platform_credentials_updated_at BEFORE UPDATE ON platform_credentials FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Request transactions never need credentials, even encrypted
+REVOKE ALL ON platform_credentials FROM zamc_app;
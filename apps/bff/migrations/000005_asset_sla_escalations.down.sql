@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_assets_status_updated_at;
+DROP TABLE IF EXISTS asset_sla_escalations;
@@ -0,0 +1,12 @@
+-- Assets escalated for breaching the review SLA. review_started_at identifies
+-- the review round so an asset is escalated at most once per round.
+CREATE TABLE IF NOT EXISTS asset_sla_escalations (
+    asset_id UUID PRIMARY KEY REFERENCES assets(id) ON DELETE CASCADE,
+    review_started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    escalated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_assets_status_updated_at ON assets(status, updated_at);
+
+-- Only the SLA monitor writes escalations
+REVOKE ALL ON asset_sla_escalations FROM zamc_app;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS chat_message_reads;
+DROP INDEX IF EXISTS idx_chat_messages_board_created_at;
@@ -0,0 +1,19 @@
+-- Read receipts for chat messages, one row per message and reader
+CREATE TABLE IF NOT EXISTS chat_message_reads (
+    message_id UUID NOT NULL REFERENCES chat_messages(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    read_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (message_id, user_id)
+);
+
+-- Keyset pagination walks a board's messages newest first
+CREATE INDEX IF NOT EXISTS idx_chat_messages_board_created_at ON chat_messages(board_id, created_at DESC, id DESC);
+
+ALTER TABLE chat_message_reads ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS chat_message_read_isolation ON chat_message_reads;
+CREATE POLICY chat_message_read_isolation ON chat_message_reads
+    USING (
+        user_id = app_current_user_id()
+        AND message_id IN (SELECT id FROM chat_messages)
+    );
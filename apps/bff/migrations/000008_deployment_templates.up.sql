@@ -0,0 +1,18 @@
+-- Reusable targeting, budget and creative settings for deploying assets to a platform
+CREATE TABLE IF NOT EXISTS deployment_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform VARCHAR(50) NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    template_metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_deployment_templates_owner_id ON deployment_templates(owner_id);
+
+ALTER TABLE deployment_templates ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS deployment_template_isolation ON deployment_templates;
+CREATE POLICY deployment_template_isolation ON deployment_templates
+    USING (owner_id = app_current_user_id());
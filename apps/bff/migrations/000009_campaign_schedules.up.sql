@@ -0,0 +1,21 @@
+-- Cron schedules pausing and resuming platform campaigns. Written and run by the
+-- connectors service; a campaign has at most one schedule.
+CREATE TABLE IF NOT EXISTS campaign_schedules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform VARCHAR(50) NOT NULL,
+    platform_campaign_id VARCHAR(255) NOT NULL,
+    pause_cron VARCHAR(100) NOT NULL,
+    resume_cron VARCHAR(100) NOT NULL,
+    timezone VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (platform, platform_campaign_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_schedules_tenant_id ON campaign_schedules(tenant_id);
+
+ALTER TABLE campaign_schedules ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS campaign_schedule_isolation ON campaign_schedules;
+CREATE POLICY campaign_schedule_isolation ON campaign_schedules
+    USING (tenant_id = app_current_user_id());
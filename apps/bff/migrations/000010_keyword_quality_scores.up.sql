@@ -0,0 +1,17 @@
+-- Google Ads quality scores of the keywords of deployed assets. Written by the
+-- connectors service a day after deployment; one row per keyword, latest score.
+CREATE TABLE IF NOT EXISTS keyword_quality_scores (
+    asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    keyword_id VARCHAR(255) NOT NULL,
+    ad_group_id VARCHAR(255) NOT NULL,
+    keyword VARCHAR(255) NOT NULL,
+    score SMALLINT NOT NULL CHECK (score BETWEEN 1 AND 10),
+    fetched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (asset_id, ad_group_id, keyword_id)
+);
+
+ALTER TABLE keyword_quality_scores ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS keyword_quality_score_isolation ON keyword_quality_scores;
+CREATE POLICY keyword_quality_score_isolation ON keyword_quality_scores
+    USING (asset_id IN (SELECT id FROM assets));
@@ -0,0 +1 @@
+ALTER TABLE assets DROP COLUMN IF EXISTS platform_rejection_reason;
@@ -0,0 +1,2 @@
+-- Why an advertising platform's ad review rejected a deployed asset
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS platform_rejection_reason TEXT;
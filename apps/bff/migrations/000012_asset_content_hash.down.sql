@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_assets_content_hash;
+
+ALTER TABLE assets DROP COLUMN IF EXISTS content_hash;
@@ -0,0 +1,5 @@
+-- SHA-256 of the first megabyte of an asset's file, used to detect re-uploads of
+-- the same file within a project
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_assets_content_hash ON assets(content_hash);
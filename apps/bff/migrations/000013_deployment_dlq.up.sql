@@ -0,0 +1,13 @@
+-- Asset status changed events whose deployment failed on every attempt. Written
+-- by the connectors service's dead letter processor; rows are removed once their
+-- event is replayed, so every row is waiting to be processed.
+CREATE TABLE IF NOT EXISTS deployment_dlq (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    asset_id UUID NOT NULL,
+    event_json JSONB NOT NULL,
+    failure_reason TEXT NOT NULL,
+    retry_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_deployment_dlq_created_at ON deployment_dlq(created_at);
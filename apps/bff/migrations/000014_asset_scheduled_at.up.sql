@@ -0,0 +1,5 @@
+-- When an approved asset goes live on its platforms, if it was scheduled rather
+-- than deployed on approval
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_assets_scheduled_at ON assets(scheduled_at);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_assets_deleted_at;
+DROP INDEX IF EXISTS idx_boards_deleted_at;
+DROP INDEX IF EXISTS idx_projects_deleted_at;
+
+ALTER TABLE assets DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE boards DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE projects DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_assets_variant_group;
+
+ALTER TABLE assets DROP COLUMN IF EXISTS variant_group;
@@ -0,0 +1,4 @@
+-- The A/B test an asset is a variant in, shared by the assets compared with it
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS variant_group TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_assets_variant_group ON assets(variant_group) WHERE variant_group IS NOT NULL;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS mutation_audit_log;
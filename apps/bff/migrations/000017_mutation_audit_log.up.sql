@@ -0,0 +1,20 @@
+-- Who changed what and when, for every audited GraphQL mutation. Entries are
+-- kept after the entity is purged, so nothing references the entity tables.
+CREATE TABLE IF NOT EXISTS mutation_audit_log (
+    id UUID PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    operation TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    old_value JSONB,
+    new_value JSONB,
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_mutation_audit_log_entity ON mutation_audit_log(entity_type, entity_id, created_at DESC);
+
+-- Written and read by the BFF outside of user transactions only; without a
+-- policy, users cannot read or rewrite the log
+ALTER TABLE mutation_audit_log ENABLE ROW LEVEL SECURITY;
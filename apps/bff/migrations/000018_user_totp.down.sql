@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_totp_secrets;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_required;
@@ -0,0 +1,13 @@
+-- TOTP two-factor authentication. Once a user confirms their secret with a code,
+-- their tokens are only accepted after a TOTP code is checked.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_required BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Secrets are AES-256-GCM encrypted by the BFF, bound to their user
+CREATE TABLE IF NOT EXISTS user_totp_secrets (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret_encrypted BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Request transactions never need TOTP secrets, even encrypted
+REVOKE ALL ON user_totp_secrets FROM zamc_app;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS campaign_metrics;
+DROP TABLE IF EXISTS campaign_deployments;
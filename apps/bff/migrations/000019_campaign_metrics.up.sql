@@ -0,0 +1,46 @@
+-- Platform campaigns of deployed assets whose performance metrics are polled.
+-- Written by the connectors service after each successful deployment.
+CREATE TABLE IF NOT EXISTS campaign_deployments (
+    platform VARCHAR(50) NOT NULL,
+    platform_campaign_id VARCHAR(255) NOT NULL,
+    asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    tenant_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    deployed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (platform, platform_campaign_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_deployments_active ON campaign_deployments(active) WHERE active;
+
+ALTER TABLE campaign_deployments ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS campaign_deployment_isolation ON campaign_deployments;
+CREATE POLICY campaign_deployment_isolation ON campaign_deployments
+    USING (asset_id IN (SELECT id FROM assets));
+
+-- Latest performance metrics of deployed campaigns, since the day they were
+-- deployed. Written by the BFF from the connectors service's metrics events.
+CREATE TABLE IF NOT EXISTS campaign_metrics (
+    platform VARCHAR(50) NOT NULL,
+    campaign_id VARCHAR(255) NOT NULL,
+    asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    impressions BIGINT NOT NULL DEFAULT 0,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    cost_micros BIGINT NOT NULL DEFAULT 0,
+    conversions DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ctr DOUBLE PRECISION NOT NULL DEFAULT 0,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    fetched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (platform, campaign_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_metrics_project_id ON campaign_metrics(project_id);
+
+ALTER TABLE campaign_metrics ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS campaign_metrics_isolation ON campaign_metrics;
+CREATE POLICY campaign_metrics_isolation ON campaign_metrics
+    USING (asset_id IN (SELECT id FROM assets));
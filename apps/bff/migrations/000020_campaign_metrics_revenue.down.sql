@@ -0,0 +1,4 @@
+DELETE FROM campaign_metrics WHERE start_date IS NULL OR end_date IS NULL;
+ALTER TABLE campaign_metrics ALTER COLUMN end_date SET NOT NULL;
+ALTER TABLE campaign_metrics ALTER COLUMN start_date SET NOT NULL;
+ALTER TABLE campaign_metrics DROP COLUMN IF EXISTS revenue;
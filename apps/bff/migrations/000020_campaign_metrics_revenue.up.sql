@@ -0,0 +1,5 @@
+-- Revenue of the conversions of a campaign, reported by Meta with its purchases.
+-- Meta reports no dates for ads that have not delivered yet.
+ALTER TABLE campaign_metrics ADD COLUMN IF NOT EXISTS revenue DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE campaign_metrics ALTER COLUMN start_date DROP NOT NULL;
+ALTER TABLE campaign_metrics ALTER COLUMN end_date DROP NOT NULL;
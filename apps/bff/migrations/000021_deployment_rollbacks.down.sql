@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS deployment_rollbacks;
+
+-- PostgreSQL cannot drop enum values, so the asset_status type is rebuilt
+-- after moving rolled back assets back to DEPLOYED
+ALTER TABLE assets ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE assets ALTER COLUMN status TYPE TEXT;
+UPDATE assets SET status = 'DEPLOYED' WHERE status = 'ROLLED_BACK';
+DROP TYPE asset_status;
+CREATE TYPE asset_status AS ENUM ('PENDING', 'APPROVED', 'REJECTED', 'REVISION_REQUIRED', 'DRAFT', 'REVIEW', 'DEPLOYED', 'FAILED');
+ALTER TABLE assets ALTER COLUMN status TYPE asset_status USING status::asset_status;
+ALTER TABLE assets ALTER COLUMN status SET DEFAULT 'PENDING';
+
+ALTER TABLE campaign_deployments DROP COLUMN IF EXISTS platform_ad_id;
+ALTER TABLE campaign_deployments DROP COLUMN IF EXISTS ad_group_id;
@@ -0,0 +1,26 @@
+-- Ad group and ad of a campaign deployment, paused when the deployment is
+-- rolled back
+ALTER TABLE campaign_deployments ADD COLUMN IF NOT EXISTS ad_group_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE campaign_deployments ADD COLUMN IF NOT EXISTS platform_ad_id VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Deployed assets whose ads were paused on a platform by an admin
+ALTER TYPE asset_status ADD VALUE IF NOT EXISTS 'ROLLED_BACK';
+
+CREATE TABLE IF NOT EXISTS deployment_rollbacks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    platform VARCHAR(50) NOT NULL,
+    platform_campaign_id VARCHAR(255) NOT NULL,
+    platform_ad_id VARCHAR(255) NOT NULL,
+    rolled_back_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    rolled_back_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    reason TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_deployment_rollbacks_asset_id ON deployment_rollbacks(asset_id);
+
+ALTER TABLE deployment_rollbacks ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS deployment_rollback_isolation ON deployment_rollbacks;
+CREATE POLICY deployment_rollback_isolation ON deployment_rollbacks
+    USING (asset_id IN (SELECT id FROM assets));
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_boards_search;
+DROP INDEX IF EXISTS idx_boards_name_search;
+DROP INDEX IF EXISTS idx_assets_name_search;
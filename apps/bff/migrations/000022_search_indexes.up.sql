@@ -0,0 +1,5 @@
+-- Full-text search over the names of assets and boards. Boards are also matched
+-- on their description.
+CREATE INDEX IF NOT EXISTS idx_assets_name_search ON assets USING GIN (to_tsvector('english', name));
+CREATE INDEX IF NOT EXISTS idx_boards_name_search ON boards USING GIN (to_tsvector('english', name));
+CREATE INDEX IF NOT EXISTS idx_boards_search ON boards USING GIN (to_tsvector('english', name || ' ' || coalesce(description, '')));
@@ -0,0 +1,39 @@
+-- URLs that receive a signed POST when an asset of a project is deployed or
+-- fails to deploy. The secret signs the payloads, so it is kept in plain text.
+CREATE TABLE IF NOT EXISTS webhooks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_project_id ON webhooks(project_id);
+
+ALTER TABLE webhooks ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS webhook_isolation ON webhooks;
+CREATE POLICY webhook_isolation ON webhooks
+    USING (project_id IN (SELECT id FROM projects));
+
+-- Every attempt to deliver an event to a webhook, with the HTTP status code of
+-- the response or the error when there was none
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event VARCHAR(50) NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    delivered_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, delivered_at);
+
+ALTER TABLE webhook_deliveries ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS webhook_delivery_isolation ON webhook_deliveries;
+CREATE POLICY webhook_delivery_isolation ON webhook_deliveries
+    USING (webhook_id IN (SELECT id FROM webhooks));
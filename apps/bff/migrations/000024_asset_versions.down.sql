@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS record_asset_version ON assets;
+DROP FUNCTION IF EXISTS record_asset_version();
+DROP TABLE IF EXISTS asset_versions;
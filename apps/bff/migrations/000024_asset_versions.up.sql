@@ -0,0 +1,48 @@
+-- Previous contents of assets, recorded by a trigger each time the name, URL,
+-- status or approval of an asset changes. changed_by and changed_at are who
+-- replaced the version and when; change_reason is read from the
+-- app.asset_change_reason setting of the transaction, if set.
+CREATE TABLE IF NOT EXISTS asset_versions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    version_number INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    url TEXT,
+    status asset_status,
+    approved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    approved_at TIMESTAMP WITH TIME ZONE,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    change_reason TEXT,
+    UNIQUE (asset_id, version_number)
+);
+
+-- The update holds the lock of the asset row, so version numbers of an asset
+-- are assigned one at a time
+CREATE OR REPLACE FUNCTION record_asset_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO asset_versions (asset_id, version_number, name, url, status, approved_by, approved_at, changed_by, changed_at, change_reason)
+    VALUES (
+        OLD.id,
+        COALESCE((SELECT MAX(version_number) FROM asset_versions WHERE asset_id = OLD.id), 0) + 1,
+        OLD.name, OLD.url, OLD.status, OLD.approved_by, OLD.approved_at,
+        app_current_user_id(), NOW(),
+        NULLIF(current_setting('app.asset_change_reason', true), '')
+    );
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Deleting, restoring or touching updated_at alone does not create a version
+DROP TRIGGER IF EXISTS record_asset_version ON assets;
+CREATE TRIGGER record_asset_version BEFORE UPDATE ON assets FOR EACH ROW
+    WHEN ((OLD.name, OLD.url, OLD.status, OLD.approved_by, OLD.approved_at)
+        IS DISTINCT FROM (NEW.name, NEW.url, NEW.status, NEW.approved_by, NEW.approved_at))
+    EXECUTE FUNCTION record_asset_version();
+
+ALTER TABLE asset_versions ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS asset_version_isolation ON asset_versions;
+CREATE POLICY asset_version_isolation ON asset_versions
+    USING (asset_id IN (SELECT id FROM assets));
@@ -0,0 +1,3 @@
+-- Role of each user in tokens issued by the BFF, such as after an OAuth2 login.
+-- Admins and reviewers are promoted by setting it.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(50) NOT NULL DEFAULT 'user';
@@ -0,0 +1 @@
+ALTER TABLE assets DROP COLUMN IF EXISTS thumbnail_url;
@@ -0,0 +1,2 @@
+-- Preview of image assets, 400x300 JPEG, generated in the background after upload
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS thumbnail_url TEXT;
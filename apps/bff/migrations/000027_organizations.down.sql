@@ -0,0 +1,12 @@
+DROP POLICY IF EXISTS project_isolation ON projects;
+CREATE POLICY project_isolation ON projects
+    USING (
+        owner_id = app_current_user_id()
+        OR id IN (SELECT project_id FROM project_members WHERE user_id = app_current_user_id())
+    );
+
+DROP INDEX IF EXISTS idx_projects_org_id;
+ALTER TABLE projects DROP COLUMN IF EXISTS org_id;
+
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,53 @@
+-- Organizations are the tenants projects belong to. Every member of an
+-- organization has access to its projects, and its members share rate limits.
+CREATE TABLE IF NOT EXISTS organizations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL,
+    plan TEXT NOT NULL DEFAULT 'free',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- role is owner, admin or member. Owners and admins manage the members.
+CREATE TABLE IF NOT EXISTS organization_members (
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL DEFAULT 'member',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (org_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members(user_id);
+
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS org_id UUID REFERENCES organizations(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_projects_org_id ON projects(org_id);
+
+-- Existing projects move to a personal organization of their owner
+CREATE TEMPORARY TABLE personal_organizations AS
+SELECT owners.owner_id AS user_id, uuid_generate_v4() AS org_id
+FROM (SELECT DISTINCT owner_id FROM projects WHERE org_id IS NULL) owners;
+
+INSERT INTO organizations (id, name)
+SELECT p.org_id, COALESCE(NULLIF(u.name, ''), u.email)
+FROM personal_organizations p
+JOIN users u ON u.id = p.user_id;
+
+INSERT INTO organization_members (org_id, user_id, role)
+SELECT org_id, user_id, 'owner' FROM personal_organizations;
+
+UPDATE projects p
+SET org_id = o.org_id
+FROM personal_organizations o
+WHERE p.owner_id = o.user_id AND p.org_id IS NULL;
+
+DROP TABLE personal_organizations;
+
+-- Members of the organization of a project have access to it, as well as its
+-- owner and members
+DROP POLICY IF EXISTS project_isolation ON projects;
+CREATE POLICY project_isolation ON projects
+    USING (
+        owner_id = app_current_user_id()
+        OR id IN (SELECT project_id FROM project_members WHERE user_id = app_current_user_id())
+        OR org_id IN (SELECT org_id FROM organization_members WHERE user_id = app_current_user_id())
+    );
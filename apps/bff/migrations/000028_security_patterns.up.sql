@@ -0,0 +1,48 @@
+-- Patterns of SQL injection and XSS attempts that input validation rejects.
+-- category is sql_injection or xss; deactivated patterns are kept for reference.
+CREATE TABLE IF NOT EXISTS security_patterns (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    category TEXT NOT NULL CHECK (category IN ('sql_injection', 'xss')),
+    pattern TEXT NOT NULL,
+    severity TEXT NOT NULL DEFAULT 'high',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_patterns_active ON security_patterns(active);
+
+-- The patterns previously built into the validator
+INSERT INTO security_patterns (category, pattern, severity) VALUES
+    ('sql_injection', '(?i)(union\s+select)', 'high'),
+    ('sql_injection', '(?i)(select\s+.*\s+from)', 'high'),
+    ('sql_injection', '(?i)(insert\s+into)', 'high'),
+    ('sql_injection', '(?i)(delete\s+from)', 'high'),
+    ('sql_injection', '(?i)(update\s+.*\s+set)', 'high'),
+    ('sql_injection', '(?i)(drop\s+table)', 'critical'),
+    ('sql_injection', '(?i)(create\s+table)', 'high'),
+    ('sql_injection', '(?i)(alter\s+table)', 'high'),
+    ('sql_injection', '(?i)(\''\s*or\s*\''\s*=\s*\'')', 'high'),
+    ('sql_injection', '(?i)(\''\s*or\s*1\s*=\s*1)', 'high'),
+    ('sql_injection', '(?i)(--\s*$)', 'warning'),
+    ('sql_injection', '(?i)(/\*.*\*/)', 'warning'),
+    ('sql_injection', '(?i)(xp_cmdshell)', 'critical'),
+    ('sql_injection', '(?i)(sp_executesql)', 'critical'),
+    ('xss', '(?i)<script[^>]*>.*?</script>', 'high'),
+    ('xss', '(?i)<iframe[^>]*>.*?</iframe>', 'high'),
+    ('xss', '(?i)<object[^>]*>.*?</object>', 'high'),
+    ('xss', '(?i)<embed[^>]*>', 'high'),
+    ('xss', '(?i)<link[^>]*>', 'high'),
+    ('xss', '(?i)<meta[^>]*>', 'high'),
+    ('xss', '(?i)javascript:', 'high'),
+    ('xss', '(?i)vbscript:', 'high'),
+    ('xss', '(?i)onload\s*=', 'high'),
+    ('xss', '(?i)onerror\s*=', 'high'),
+    ('xss', '(?i)onclick\s*=', 'high'),
+    ('xss', '(?i)onmouseover\s*=', 'high'),
+    ('xss', '(?i)onfocus\s*=', 'high'),
+    ('xss', '(?i)onblur\s*=', 'high'),
+    ('xss', '(?i)onchange\s*=', 'high'),
+    ('xss', '(?i)onsubmit\s*=', 'high'),
+    ('xss', '(?i)expression\s*\(', 'high'),
+    ('xss', '(?i)@import', 'high'),
+    ('xss', '(?i)behavior\s*:', 'high');
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS message_reactions;
+
+DROP INDEX IF EXISTS idx_chat_messages_parent_id;
+
+ALTER TABLE chat_messages DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,19 @@
+-- Replies reference the message they answer; deleting a message deletes its thread
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES chat_messages(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_chat_messages_parent_id ON chat_messages(parent_id, created_at);
+
+-- Reactions to chat messages, one row per message, user and emoji
+CREATE TABLE IF NOT EXISTS message_reactions (
+    message_id UUID NOT NULL REFERENCES chat_messages(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (message_id, user_id, emoji)
+);
+
+ALTER TABLE message_reactions ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS message_reaction_isolation ON message_reactions;
+CREATE POLICY message_reaction_isolation ON message_reactions
+    USING (message_id IN (SELECT id FROM chat_messages));
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS record_campaign_metrics_history ON campaign_metrics;
+DROP FUNCTION IF EXISTS record_campaign_metrics_history();
+DROP TABLE IF EXISTS campaign_anomalies;
+DROP TABLE IF EXISTS campaign_metrics_history;
@@ -0,0 +1,68 @@
+-- Daily snapshots of the metrics of deployed campaigns, the last one of each day
+-- kept, which the connectors service averages to detect anomalies
+CREATE TABLE IF NOT EXISTS campaign_metrics_history (
+    platform VARCHAR(50) NOT NULL,
+    campaign_id VARCHAR(255) NOT NULL,
+    asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    fetched_on DATE NOT NULL,
+    impressions BIGINT NOT NULL DEFAULT 0,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    cost_micros BIGINT NOT NULL DEFAULT 0,
+    conversions DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ctr DOUBLE PRECISION NOT NULL DEFAULT 0,
+    revenue DOUBLE PRECISION NOT NULL DEFAULT 0,
+    PRIMARY KEY (platform, campaign_id, fetched_on)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_metrics_history_campaign_id ON campaign_metrics_history(campaign_id, fetched_on);
+
+-- Campaign metrics far from their rolling average, until they are back to normal
+CREATE TABLE IF NOT EXISTS campaign_anomalies (
+    campaign_id VARCHAR(255) NOT NULL,
+    metric VARCHAR(50) NOT NULL,
+    platform VARCHAR(50) NOT NULL,
+    asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    value DOUBLE PRECISION NOT NULL,
+    mean DOUBLE PRECISION NOT NULL,
+    std_dev DOUBLE PRECISION NOT NULL,
+    z_score DOUBLE PRECISION NOT NULL,
+    severity VARCHAR(20) NOT NULL,
+    detected_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (campaign_id, metric)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_anomalies_project_id ON campaign_anomalies(project_id);
+
+-- Records the metrics of a campaign as those of the day they were fetched
+CREATE OR REPLACE FUNCTION record_campaign_metrics_history()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO campaign_metrics_history (platform, campaign_id, asset_id, project_id, fetched_on, impressions, clicks, cost_micros, conversions, ctr, revenue)
+    VALUES (
+        NEW.platform, NEW.campaign_id, NEW.asset_id, NEW.project_id, (NEW.fetched_at AT TIME ZONE 'UTC')::date,
+        NEW.impressions, NEW.clicks, NEW.cost_micros, NEW.conversions, NEW.ctr, NEW.revenue
+    )
+    ON CONFLICT (platform, campaign_id, fetched_on) DO UPDATE
+    SET asset_id = EXCLUDED.asset_id, project_id = EXCLUDED.project_id,
+        impressions = EXCLUDED.impressions, clicks = EXCLUDED.clicks, cost_micros = EXCLUDED.cost_micros,
+        conversions = EXCLUDED.conversions, ctr = EXCLUDED.ctr, revenue = EXCLUDED.revenue;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS record_campaign_metrics_history ON campaign_metrics;
+CREATE TRIGGER record_campaign_metrics_history AFTER INSERT OR UPDATE ON campaign_metrics FOR EACH ROW
+    EXECUTE FUNCTION record_campaign_metrics_history();
+
+ALTER TABLE campaign_metrics_history ENABLE ROW LEVEL SECURITY;
+ALTER TABLE campaign_anomalies ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS campaign_metrics_history_isolation ON campaign_metrics_history;
+CREATE POLICY campaign_metrics_history_isolation ON campaign_metrics_history
+    USING (asset_id IN (SELECT id FROM assets));
+
+DROP POLICY IF EXISTS campaign_anomaly_isolation ON campaign_anomalies;
+CREATE POLICY campaign_anomaly_isolation ON campaign_anomalies
+    USING (asset_id IN (SELECT id FROM assets));
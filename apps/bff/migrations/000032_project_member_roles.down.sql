@@ -0,0 +1,5 @@
+ALTER TABLE project_members DROP CONSTRAINT IF EXISTS project_members_role_check;
+
+ALTER TABLE project_members ALTER COLUMN role SET DEFAULT 'member';
+
+UPDATE project_members SET role = 'member';
@@ -0,0 +1,8 @@
+-- Members of a project are viewers, editors or admins of it. Existing members
+-- keep uploading assets as editors.
+UPDATE project_members SET role = 'editor' WHERE role NOT IN ('viewer', 'editor', 'admin');
+
+ALTER TABLE project_members ALTER COLUMN role SET DEFAULT 'editor';
+
+ALTER TABLE project_members DROP CONSTRAINT IF EXISTS project_members_role_check;
+ALTER TABLE project_members ADD CONSTRAINT project_members_role_check CHECK (role IN ('viewer', 'editor', 'admin'));
@@ -0,0 +1,23 @@
+-- Finished deployments of assets to ad platforms, one row per asset and platform
+-- each time the connectors service deploys it
+CREATE TABLE IF NOT EXISTS deployment_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    platform VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    platform_id VARCHAR(255),
+    error TEXT,
+    deployed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    retry_count INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_deployment_history_asset_id ON deployment_history(asset_id, deployed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_deployment_history_project_id ON deployment_history(project_id, deployed_at DESC);
+
+ALTER TABLE deployment_history ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS deployment_history_isolation ON deployment_history;
+CREATE POLICY deployment_history_isolation ON deployment_history
+    USING (asset_id IN (SELECT id FROM assets));
@@ -0,0 +1 @@
+ALTER TABLE campaign_deployments DROP COLUMN IF EXISTS audience_id;
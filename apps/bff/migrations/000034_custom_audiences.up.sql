@@ -0,0 +1,3 @@
+-- Meta custom audience targeted by a campaign deployment, deleted on request
+-- when the people in it withdraw their consent
+ALTER TABLE campaign_deployments ADD COLUMN IF NOT EXISTS audience_id VARCHAR(255) NOT NULL DEFAULT '';
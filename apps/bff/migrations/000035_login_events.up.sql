@@ -0,0 +1,20 @@
+-- Every verification of an access token, successful or not, with the client and
+-- where it was located, so users can review the activity on their account.
+-- Failed attempts whose token does not name a user have an empty user_id.
+CREATE TABLE IF NOT EXISTS login_events (
+    id UUID PRIMARY KEY,
+    user_id TEXT NOT NULL DEFAULT '',
+    event_type TEXT NOT NULL,
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    country TEXT NOT NULL DEFAULT '',
+    city TEXT NOT NULL DEFAULT '',
+    success BOOLEAN NOT NULL,
+    failure_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_events_user_id ON login_events(user_id, created_at DESC);
+
+-- Written and read by the BFF outside of user transactions only
+ALTER TABLE login_events ENABLE ROW LEVEL SECURITY;
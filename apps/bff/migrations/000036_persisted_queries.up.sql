@@ -0,0 +1,11 @@
+-- Queries of automatic persisted queries by SHA-256 hash, shared by the BFF
+-- replicas so that clients do not send them again after a restart
+CREATE TABLE IF NOT EXISTS persisted_queries (
+    hash TEXT PRIMARY KEY,
+    query TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Written and read by the BFF outside of user transactions only
+ALTER TABLE persisted_queries ENABLE ROW LEVEL SECURITY;
@@ -0,0 +1,50 @@
+-- Board structures new projects are created from. default_boards is an array
+-- of {"name", "description"} objects. System templates have no creator and are
+-- public; templates saved by users are private to them.
+CREATE TABLE IF NOT EXISTS board_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    default_boards JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_by TEXT,
+    is_public BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_board_templates_created_by ON board_templates(created_by);
+
+ALTER TABLE board_templates ENABLE ROW LEVEL SECURITY;
+
+-- Everyone sees the public templates, but users only change their own
+DROP POLICY IF EXISTS board_template_visibility ON board_templates;
+CREATE POLICY board_template_visibility ON board_templates FOR SELECT
+    USING (is_public OR created_by = app_current_user_id()::text);
+
+DROP POLICY IF EXISTS board_template_isolation ON board_templates;
+CREATE POLICY board_template_isolation ON board_templates
+    USING (created_by = app_current_user_id()::text);
+
+INSERT INTO board_templates (id, name, description, default_boards, is_public) VALUES
+    ('6a1f4c1e-0c7e-4f3b-9d51-5b8f0e6f1a01', 'Social Media Campaign',
+     'Plan, produce and schedule posts across social platforms',
+     '[{"name": "Strategy", "description": "Goals, audiences and key messages"},
+       {"name": "Content Calendar", "description": "Posts planned per platform and date"},
+       {"name": "Creative", "description": "Images and videos in production"},
+       {"name": "Approval", "description": "Posts waiting for review"},
+       {"name": "Published", "description": "Live posts and their performance"}]'::jsonb,
+     TRUE),
+    ('6a1f4c1e-0c7e-4f3b-9d51-5b8f0e6f1a02', 'Product Launch',
+     'Coordinate the assets of a product launch from teaser to follow-up',
+     '[{"name": "Research", "description": "Market, competitors and positioning"},
+       {"name": "Teasers", "description": "Pre-launch announcements"},
+       {"name": "Launch Assets", "description": "Landing pages, ads and press material"},
+       {"name": "Follow-up", "description": "Post-launch campaigns and retargeting"}]'::jsonb,
+     TRUE),
+    ('6a1f4c1e-0c7e-4f3b-9d51-5b8f0e6f1a03', 'Email Campaign',
+     'Write, design and test a sequence of emails',
+     '[{"name": "Copy", "description": "Subject lines and email bodies"},
+       {"name": "Design", "description": "Templates and imagery"},
+       {"name": "Testing", "description": "A/B variants and rendering checks"},
+       {"name": "Sent", "description": "Delivered emails and their results"}]'::jsonb,
+     TRUE)
+ON CONFLICT (id) DO NOTHING;
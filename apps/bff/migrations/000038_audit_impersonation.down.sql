@@ -0,0 +1 @@
+ALTER TABLE mutation_audit_log DROP COLUMN IF EXISTS impersonated_by;
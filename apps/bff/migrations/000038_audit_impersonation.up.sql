@@ -0,0 +1,3 @@
+-- The super admin who impersonated the user of an audited mutation, empty when
+-- the user acted themselves
+ALTER TABLE mutation_audit_log ADD COLUMN IF NOT EXISTS impersonated_by TEXT NOT NULL DEFAULT '';
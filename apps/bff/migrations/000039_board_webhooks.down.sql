@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS board_webhook_deliveries;
+DROP TABLE IF EXISTS board_webhooks;
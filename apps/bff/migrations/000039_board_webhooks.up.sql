@@ -0,0 +1,42 @@
+-- URLs that receive a signed POST when an asset of a board is uploaded,
+-- approved or deployed, or when a message is sent on it. The secret signs the
+-- payloads, so it is kept in plain text.
+CREATE TABLE IF NOT EXISTS board_webhooks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    board_id UUID NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_board_webhooks_board_id ON board_webhooks(board_id);
+
+ALTER TABLE board_webhooks ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS board_webhook_isolation ON board_webhooks;
+CREATE POLICY board_webhook_isolation ON board_webhooks
+    USING (board_id IN (SELECT id FROM boards));
+
+-- Every attempt to deliver an event to a board webhook. Retries of an event
+-- share its delivery ID; the response body is truncated.
+CREATE TABLE IF NOT EXISTS board_webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    webhook_id UUID NOT NULL REFERENCES board_webhooks(id) ON DELETE CASCADE,
+    delivery_id UUID NOT NULL,
+    event VARCHAR(50) NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    response_body TEXT,
+    error TEXT,
+    delivered_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_board_webhook_deliveries_webhook_id ON board_webhook_deliveries(webhook_id, delivered_at);
+
+ALTER TABLE board_webhook_deliveries ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS board_webhook_delivery_isolation ON board_webhook_deliveries;
+CREATE POLICY board_webhook_delivery_isolation ON board_webhook_deliveries
+    USING (webhook_id IN (SELECT id FROM board_webhooks));
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_campaign_metrics_history_project_id;
+ALTER TABLE projects DROP COLUMN IF EXISTS budget_limit;
@@ -0,0 +1,6 @@
+-- Monthly ad spend budget of a project, in units of the account currency. Spend
+-- reports compare the spend of a month with it; NULL means no budget.
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS budget_limit DOUBLE PRECISION CHECK (budget_limit >= 0);
+
+-- Spend reports read the daily snapshots of the campaigns of a project
+CREATE INDEX IF NOT EXISTS idx_campaign_metrics_history_project_id ON campaign_metrics_history(project_id, fetched_on);
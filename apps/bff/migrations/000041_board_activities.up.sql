@@ -0,0 +1,20 @@
+-- Activity feed of a board: who uploaded, commented on, reviewed or deployed
+-- which of its assets. entity_type and entity_id name the object acted on.
+CREATE TABLE IF NOT EXISTS board_activities (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    board_id UUID NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(50) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id UUID,
+    details JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_board_activities_board_id ON board_activities(board_id, created_at DESC);
+
+ALTER TABLE board_activities ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS board_activity_isolation ON board_activities;
+CREATE POLICY board_activity_isolation ON board_activities
+    USING (board_id IN (SELECT id FROM boards));
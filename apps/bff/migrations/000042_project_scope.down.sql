@@ -0,0 +1,9 @@
+DROP POLICY IF EXISTS project_isolation ON projects;
+CREATE POLICY project_isolation ON projects
+    USING (
+        owner_id = app_current_user_id()
+        OR id IN (SELECT project_id FROM project_members WHERE user_id = app_current_user_id())
+        OR org_id IN (SELECT org_id FROM organization_members WHERE user_id = app_current_user_id())
+    );
+
+DROP FUNCTION IF EXISTS app_current_project_scope();
@@ -0,0 +1,19 @@
+-- Project the token of the request transaction is scoped to, NULL for user
+-- tokens and outside of a transaction
+CREATE OR REPLACE FUNCTION app_current_project_scope()
+RETURNS UUID AS $$
+    SELECT NULLIF(current_setting('app.current_project_scope', true), '')::uuid
+$$ LANGUAGE sql STABLE;
+
+-- Project-scoped tokens only see their project, and through it its boards and
+-- assets
+DROP POLICY IF EXISTS project_isolation ON projects;
+CREATE POLICY project_isolation ON projects
+    USING (
+        (
+            owner_id = app_current_user_id()
+            OR id IN (SELECT project_id FROM project_members WHERE user_id = app_current_user_id())
+            OR org_id IN (SELECT org_id FROM organization_members WHERE user_id = app_current_user_id())
+        )
+        AND (app_current_project_scope() IS NULL OR id = app_current_project_scope())
+    );
@@ -0,0 +1,3 @@
+-- When the scheduled deployment of an approved asset was handed to the
+-- connectors service, so that it is published once
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS schedule_published_at TIMESTAMP WITH TIME ZONE;
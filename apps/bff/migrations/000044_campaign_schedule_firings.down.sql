@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_campaign_schedules_next_resume_at;
+DROP INDEX IF EXISTS idx_campaign_schedules_next_pause_at;
+ALTER TABLE campaign_schedules DROP COLUMN IF EXISTS next_resume_at;
+ALTER TABLE campaign_schedules DROP COLUMN IF EXISTS next_pause_at;
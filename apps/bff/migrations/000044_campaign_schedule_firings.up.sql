@@ -0,0 +1,8 @@
+-- The next pause and resume of each campaign schedule. Connectors instances claim
+-- a due firing by locking the schedule and moving these on, so that each firing
+-- is applied once. NULL until an instance first runs the schedule.
+ALTER TABLE campaign_schedules ADD COLUMN IF NOT EXISTS next_pause_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE campaign_schedules ADD COLUMN IF NOT EXISTS next_resume_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_campaign_schedules_next_pause_at ON campaign_schedules(next_pause_at);
+CREATE INDEX IF NOT EXISTS idx_campaign_schedules_next_resume_at ON campaign_schedules(next_resume_at);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_persisted_queries_last_used_at;
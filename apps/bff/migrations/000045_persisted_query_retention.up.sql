@@ -0,0 +1,2 @@
+-- Persisted queries unused for a while are purged by the BFF, oldest first
+CREATE INDEX IF NOT EXISTS idx_persisted_queries_last_used_at ON persisted_queries(last_used_at);
@@ -0,0 +1,2 @@
+-- Why a reviewer rejected an asset, given to rejectAsset
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS rejection_reason TEXT;
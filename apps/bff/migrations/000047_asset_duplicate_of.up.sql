@@ -0,0 +1,6 @@
+-- Assets are hashed in the background after upload. An asset whose file turns
+-- out to be already in the project points to the older asset; uploads of a URL
+-- already hashed are matched at once through the URL index.
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS duplicate_of UUID REFERENCES assets(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_assets_url ON assets USING HASH (url);
@@ -0,0 +1,29 @@
+DROP POLICY IF EXISTS project_isolation ON projects;
+CREATE POLICY project_isolation ON projects
+    USING (
+        (
+            owner_id = app_current_user_id()
+            OR id IN (SELECT project_id FROM project_members WHERE user_id = app_current_user_id())
+            OR org_id IN (SELECT org_id FROM organization_members WHERE user_id = app_current_user_id())
+        )
+        AND (app_current_project_scope() IS NULL OR id = app_current_project_scope())
+    );
+
+DROP POLICY IF EXISTS organization_member_creation ON organization_members;
+DROP POLICY IF EXISTS organization_member_isolation ON organization_members;
+DROP POLICY IF EXISTS organization_creation ON organizations;
+DROP POLICY IF EXISTS organization_isolation ON organizations;
+
+ALTER TABLE organization_members DISABLE ROW LEVEL SECURITY;
+ALTER TABLE organizations DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION IF EXISTS app_organization_has_members(UUID);
+DROP FUNCTION IF EXISTS app_current_organizations();
+
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_org_id_fkey;
+ALTER TABLE projects ADD CONSTRAINT projects_org_id_fkey
+    FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE organization_members DROP CONSTRAINT IF EXISTS organization_members_org_id_fkey;
+ALTER TABLE organization_members ADD CONSTRAINT organization_members_org_id_fkey
+    FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE;
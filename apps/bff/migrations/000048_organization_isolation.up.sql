@@ -0,0 +1,62 @@
+-- Deleting an organization must not take its projects and members with it:
+-- they are moved or removed first
+ALTER TABLE organization_members DROP CONSTRAINT IF EXISTS organization_members_org_id_fkey;
+ALTER TABLE organization_members ADD CONSTRAINT organization_members_org_id_fkey
+    FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE RESTRICT;
+
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_org_id_fkey;
+ALTER TABLE projects ADD CONSTRAINT projects_org_id_fkey
+    FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE RESTRICT;
+
+-- Organizations of the user of the request transaction. It bypasses the
+-- policies of organization_members, which would otherwise query themselves.
+CREATE OR REPLACE FUNCTION app_current_organizations()
+RETURNS SETOF UUID AS $$
+    SELECT org_id FROM organization_members WHERE user_id = app_current_user_id()
+$$ LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public;
+
+-- Whether an organization has members, so that its creator can join it
+CREATE OR REPLACE FUNCTION app_organization_has_members(UUID)
+RETURNS BOOLEAN AS $$
+    SELECT EXISTS (SELECT 1 FROM organization_members WHERE org_id = $1)
+$$ LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public;
+
+ALTER TABLE organizations ENABLE ROW LEVEL SECURITY;
+ALTER TABLE organization_members ENABLE ROW LEVEL SECURITY;
+
+-- Organizations are visible to their members. Any user may create one.
+DROP POLICY IF EXISTS organization_isolation ON organizations;
+CREATE POLICY organization_isolation ON organizations
+    USING (id IN (SELECT app_current_organizations()));
+
+DROP POLICY IF EXISTS organization_creation ON organizations;
+CREATE POLICY organization_creation ON organizations FOR INSERT
+    WITH CHECK (true);
+
+-- Members see the other members of their organizations. The creator of an
+-- organization joins it as its first member.
+DROP POLICY IF EXISTS organization_member_isolation ON organization_members;
+CREATE POLICY organization_member_isolation ON organization_members
+    USING (org_id IN (SELECT app_current_organizations()));
+
+DROP POLICY IF EXISTS organization_member_creation ON organization_members;
+CREATE POLICY organization_member_creation ON organization_members FOR INSERT
+    WITH CHECK (user_id = app_current_user_id() AND NOT app_organization_has_members(org_id));
+
+-- Projects of organizations are visible to the members of the organization
+-- only, even when shared with other users
+DROP POLICY IF EXISTS project_isolation ON projects;
+CREATE POLICY project_isolation ON projects
+    USING (
+        (
+            org_id IN (SELECT app_current_organizations())
+            OR (
+                org_id IS NULL
+                AND (
+                    owner_id = app_current_user_id()
+                    OR id IN (SELECT project_id FROM project_members WHERE user_id = app_current_user_id())
+                )
+            )
+        )
+        AND (app_current_project_scope() IS NULL OR id = app_current_project_scope())
+    );
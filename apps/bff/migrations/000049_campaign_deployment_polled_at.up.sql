@@ -0,0 +1,3 @@
+-- When the metrics of a campaign were last polled, so that connector
+-- instances polling together claim different campaigns
+ALTER TABLE campaign_deployments ADD COLUMN IF NOT EXISTS polled_at TIMESTAMP WITH TIME ZONE;
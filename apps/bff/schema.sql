@@ -1,4 +1,5 @@
 -- Database schema for ZAMC BFF
+-- Reference snapshot only: schema changes are applied through migrations/
 
 -- Enable UUID extension
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
@@ -9,10 +10,29 @@ CREATE TABLE IF NOT EXISTS users (
     email VARCHAR(255) UNIQUE NOT NULL,
     name VARCHAR(255),
     avatar TEXT,
+    role VARCHAR(50) NOT NULL DEFAULT 'user',
+    totp_required BOOLEAN NOT NULL DEFAULT FALSE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
+-- Organizations table. Organizations are the tenants projects belong to.
+CREATE TABLE IF NOT EXISTS organizations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL,
+    plan TEXT NOT NULL DEFAULT 'free',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Organization members table. role is owner, admin or member.
+CREATE TABLE IF NOT EXISTS organization_members (
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE RESTRICT,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL DEFAULT 'member',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (org_id, user_id)
+);
+
 -- Project status enum
 CREATE TYPE project_status AS ENUM ('ACTIVE', 'ARCHIVED', 'DRAFT');
 
@@ -23,8 +43,11 @@ CREATE TABLE IF NOT EXISTS projects (
     description TEXT,
     status project_status DEFAULT 'ACTIVE',
     owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    org_id UUID REFERENCES organizations(id) ON DELETE RESTRICT,
+    budget_limit DOUBLE PRECISION CHECK (budget_limit >= 0),
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE
 );
 
 -- Boards table
@@ -34,14 +57,15 @@ CREATE TABLE IF NOT EXISTS boards (
     description TEXT,
     project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE
 );
 
 -- Asset type enum
 CREATE TYPE asset_type AS ENUM ('IMAGE', 'VIDEO', 'DOCUMENT', 'AUDIO', 'OTHER');
 
 -- Asset status enum
-CREATE TYPE asset_status AS ENUM ('PENDING', 'APPROVED', 'REJECTED', 'REVISION_REQUIRED');
+CREATE TYPE asset_status AS ENUM ('PENDING', 'APPROVED', 'REJECTED', 'REVISION_REQUIRED', 'DRAFT', 'REVIEW', 'DEPLOYED', 'FAILED', 'ROLLED_BACK');
 
 -- Assets table
 CREATE TABLE IF NOT EXISTS assets (
@@ -53,8 +77,18 @@ CREATE TABLE IF NOT EXISTS assets (
     board_id UUID NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
     approved_by UUID REFERENCES users(id),
     approved_at TIMESTAMP WITH TIME ZONE,
+    meta_campaign_id VARCHAR(255),
+    platform_rejection_reason TEXT,
+    rejection_reason TEXT,
+    duplicate_of UUID REFERENCES assets(id) ON DELETE SET NULL,
+    content_hash VARCHAR(64),
+    scheduled_at TIMESTAMP WITH TIME ZONE,
+    schedule_published_at TIMESTAMP WITH TIME ZONE,
+    variant_group TEXT,
+    thumbnail_url TEXT,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE
 );
 
 -- Chat messages table
@@ -63,15 +97,397 @@ CREATE TABLE IF NOT EXISTS chat_messages (
     content TEXT NOT NULL,
     user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     board_id UUID NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES chat_messages(id) ON DELETE CASCADE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
+-- Project members table. Membership grants access to projects the user does not own,
+-- as a viewer, editor or admin of the project
+CREATE TABLE IF NOT EXISTS project_members (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL DEFAULT 'editor' CONSTRAINT project_members_role_check CHECK (role IN ('viewer', 'editor', 'admin')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_members_user_id ON project_members(user_id);
+
+-- Platform credentials table. Values are encrypted by the connectors service.
+CREATE TABLE IF NOT EXISTS platform_credentials (
+    tenant_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform VARCHAR(50) NOT NULL,
+    credentials_json_encrypted TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, platform)
+);
+
+-- Asset SLA escalations table
+CREATE TABLE IF NOT EXISTS asset_sla_escalations (
+    asset_id UUID PRIMARY KEY REFERENCES assets(id) ON DELETE CASCADE,
+    review_started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    escalated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- User preferences table
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    preferences JSONB NOT NULL DEFAULT '{}'::jsonb,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Chat message read receipts table
+CREATE TABLE IF NOT EXISTS chat_message_reads (
+    message_id UUID NOT NULL REFERENCES chat_messages(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    read_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (message_id, user_id)
+);
+
+-- Chat message reactions table
+CREATE TABLE IF NOT EXISTS message_reactions (
+    message_id UUID NOT NULL REFERENCES chat_messages(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (message_id, user_id, emoji)
+);
+
+-- Deployment templates table
+CREATE TABLE IF NOT EXISTS deployment_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform VARCHAR(50) NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    template_metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Campaign schedules table
+CREATE TABLE IF NOT EXISTS campaign_schedules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform VARCHAR(50) NOT NULL,
+    platform_campaign_id VARCHAR(255) NOT NULL,
+    pause_cron VARCHAR(100) NOT NULL,
+    resume_cron VARCHAR(100) NOT NULL,
+    timezone VARCHAR(100) NOT NULL,
+    next_pause_at TIMESTAMP WITH TIME ZONE,
+    next_resume_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (platform, platform_campaign_id)
+);
+
+-- Keyword quality scores table
+CREATE TABLE IF NOT EXISTS keyword_quality_scores (
+    asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    keyword_id VARCHAR(255) NOT NULL,
+    ad_group_id VARCHAR(255) NOT NULL,
+    keyword VARCHAR(255) NOT NULL,
+    score SMALLINT NOT NULL CHECK (score BETWEEN 1 AND 10),
+    fetched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (asset_id, ad_group_id, keyword_id)
+);
+
+-- Dead letters of failed deployments table
+CREATE TABLE IF NOT EXISTS deployment_dlq (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    asset_id UUID NOT NULL,
+    event_json JSONB NOT NULL,
+    failure_reason TEXT NOT NULL,
+    retry_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Mutation audit log table. Entries are kept after the entity is purged.
+CREATE TABLE IF NOT EXISTS mutation_audit_log (
+    id UUID PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    operation TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    old_value JSONB,
+    new_value JSONB,
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    impersonated_by TEXT NOT NULL DEFAULT ''
+);
+
+-- Login events table. Every access token verification, successful or not.
+CREATE TABLE IF NOT EXISTS login_events (
+    id UUID PRIMARY KEY,
+    user_id TEXT NOT NULL DEFAULT '',
+    event_type TEXT NOT NULL,
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    country TEXT NOT NULL DEFAULT '',
+    city TEXT NOT NULL DEFAULT '',
+    success BOOLEAN NOT NULL,
+    failure_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Persisted queries table. Automatic persisted queries by SHA-256 hash.
+CREATE TABLE IF NOT EXISTS persisted_queries (
+    hash TEXT PRIMARY KEY,
+    query TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Board templates table. Public system templates and the private ones of users.
+CREATE TABLE IF NOT EXISTS board_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    default_boards JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_by TEXT,
+    is_public BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Board webhooks table
+CREATE TABLE IF NOT EXISTS board_webhooks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    board_id UUID NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Board webhook delivery attempts table. Retries share their delivery ID.
+CREATE TABLE IF NOT EXISTS board_webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    webhook_id UUID NOT NULL REFERENCES board_webhooks(id) ON DELETE CASCADE,
+    delivery_id UUID NOT NULL,
+    event VARCHAR(50) NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    response_body TEXT,
+    error TEXT,
+    delivered_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Board activities table
+CREATE TABLE IF NOT EXISTS board_activities (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    board_id UUID NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(50) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id UUID,
+    details JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- TOTP secrets table. Secrets are encrypted by the BFF.
+CREATE TABLE IF NOT EXISTS user_totp_secrets (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret_encrypted BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Campaign deployments table. Written by the connectors service.
+CREATE TABLE IF NOT EXISTS campaign_deployments (
+    platform VARCHAR(50) NOT NULL,
+    platform_campaign_id VARCHAR(255) NOT NULL,
+    asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    tenant_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    ad_group_id VARCHAR(255) NOT NULL DEFAULT '',
+    platform_ad_id VARCHAR(255) NOT NULL DEFAULT '',
+    audience_id VARCHAR(255) NOT NULL DEFAULT '',
+    deployed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    polled_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (platform, platform_campaign_id)
+);
+
+-- Campaign metrics table
+CREATE TABLE IF NOT EXISTS campaign_metrics (
+    platform VARCHAR(50) NOT NULL,
+    campaign_id VARCHAR(255) NOT NULL,
+    asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    impressions BIGINT NOT NULL DEFAULT 0,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    cost_micros BIGINT NOT NULL DEFAULT 0,
+    conversions DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ctr DOUBLE PRECISION NOT NULL DEFAULT 0,
+    revenue DOUBLE PRECISION NOT NULL DEFAULT 0,
+    start_date DATE,
+    end_date DATE,
+    fetched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (platform, campaign_id)
+);
+
+-- Campaign metrics history table
+CREATE TABLE IF NOT EXISTS campaign_metrics_history (
+    platform VARCHAR(50) NOT NULL,
+    campaign_id VARCHAR(255) NOT NULL,
+    asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    fetched_on DATE NOT NULL,
+    impressions BIGINT NOT NULL DEFAULT 0,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    cost_micros BIGINT NOT NULL DEFAULT 0,
+    conversions DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ctr DOUBLE PRECISION NOT NULL DEFAULT 0,
+    revenue DOUBLE PRECISION NOT NULL DEFAULT 0,
+    PRIMARY KEY (platform, campaign_id, fetched_on)
+);
+
+-- Campaign anomalies table
+CREATE TABLE IF NOT EXISTS campaign_anomalies (
+    campaign_id VARCHAR(255) NOT NULL,
+    metric VARCHAR(50) NOT NULL,
+    platform VARCHAR(50) NOT NULL,
+    asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    value DOUBLE PRECISION NOT NULL,
+    mean DOUBLE PRECISION NOT NULL,
+    std_dev DOUBLE PRECISION NOT NULL,
+    z_score DOUBLE PRECISION NOT NULL,
+    severity VARCHAR(20) NOT NULL,
+    detected_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (campaign_id, metric)
+);
+
+-- Deployment history table
+CREATE TABLE IF NOT EXISTS deployment_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    platform VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    platform_id VARCHAR(255),
+    error TEXT,
+    deployed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    retry_count INTEGER NOT NULL DEFAULT 0
+);
+
+-- Deployment rollbacks table
+CREATE TABLE IF NOT EXISTS deployment_rollbacks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    platform VARCHAR(50) NOT NULL,
+    platform_campaign_id VARCHAR(255) NOT NULL,
+    platform_ad_id VARCHAR(255) NOT NULL,
+    rolled_back_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    rolled_back_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    reason TEXT
+);
+
+-- Webhooks table
+CREATE TABLE IF NOT EXISTS webhooks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Webhook delivery attempts table
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event VARCHAR(50) NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    delivered_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Asset versions table. Rows are recorded by the record_asset_version trigger.
+CREATE TABLE IF NOT EXISTS asset_versions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    asset_id UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    version_number INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    url TEXT,
+    status asset_status,
+    approved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    approved_at TIMESTAMP WITH TIME ZONE,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    change_reason TEXT,
+    UNIQUE (asset_id, version_number)
+);
+
+-- Security patterns table. Input matching an active pattern is rejected.
+CREATE TABLE IF NOT EXISTS security_patterns (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    category TEXT NOT NULL CHECK (category IN ('sql_injection', 'xss')),
+    pattern TEXT NOT NULL,
+    severity TEXT NOT NULL DEFAULT 'high',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Export audit log table. Board data exports, one row per download.
+CREATE TABLE IF NOT EXISTS export_audit_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    board_id UUID NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    format TEXT NOT NULL,
+    asset_count INTEGER NOT NULL DEFAULT 0,
+    message_count INTEGER NOT NULL DEFAULT 0,
+    exported_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
 -- Indexes for better performance
 CREATE INDEX IF NOT EXISTS idx_projects_owner_id ON projects(owner_id);
+CREATE INDEX IF NOT EXISTS idx_projects_org_id ON projects(org_id);
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members(user_id);
 CREATE INDEX IF NOT EXISTS idx_boards_project_id ON boards(project_id);
 CREATE INDEX IF NOT EXISTS idx_assets_board_id ON assets(board_id);
+CREATE INDEX IF NOT EXISTS idx_assets_status_updated_at ON assets(status, updated_at);
+CREATE INDEX IF NOT EXISTS idx_assets_content_hash ON assets(content_hash);
+CREATE INDEX IF NOT EXISTS idx_assets_url ON assets USING HASH (url);
+CREATE INDEX IF NOT EXISTS idx_assets_scheduled_at ON assets(scheduled_at);
 CREATE INDEX IF NOT EXISTS idx_chat_messages_board_id ON chat_messages(board_id);
 CREATE INDEX IF NOT EXISTS idx_chat_messages_created_at ON chat_messages(created_at);
+CREATE INDEX IF NOT EXISTS idx_security_patterns_active ON security_patterns(active);
+CREATE INDEX IF NOT EXISTS idx_chat_messages_board_created_at ON chat_messages(board_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_chat_messages_parent_id ON chat_messages(parent_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_deployment_templates_owner_id ON deployment_templates(owner_id);
+CREATE INDEX IF NOT EXISTS idx_campaign_schedules_tenant_id ON campaign_schedules(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_campaign_schedules_next_pause_at ON campaign_schedules(next_pause_at);
+CREATE INDEX IF NOT EXISTS idx_campaign_schedules_next_resume_at ON campaign_schedules(next_resume_at);
+CREATE INDEX IF NOT EXISTS idx_deployment_dlq_created_at ON deployment_dlq(created_at);
+CREATE INDEX IF NOT EXISTS idx_projects_deleted_at ON projects(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_boards_deleted_at ON boards(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_assets_deleted_at ON assets(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_assets_variant_group ON assets(variant_group) WHERE variant_group IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_assets_name_search ON assets USING GIN (to_tsvector('english', name));
+CREATE INDEX IF NOT EXISTS idx_boards_name_search ON boards USING GIN (to_tsvector('english', name));
+CREATE INDEX IF NOT EXISTS idx_boards_search ON boards USING GIN (to_tsvector('english', name || ' ' || coalesce(description, '')));
+CREATE INDEX IF NOT EXISTS idx_mutation_audit_log_entity ON mutation_audit_log(entity_type, entity_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_login_events_user_id ON login_events(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_board_templates_created_by ON board_templates(created_by);
+CREATE INDEX IF NOT EXISTS idx_board_webhooks_board_id ON board_webhooks(board_id);
+CREATE INDEX IF NOT EXISTS idx_board_webhook_deliveries_webhook_id ON board_webhook_deliveries(webhook_id, delivered_at);
+CREATE INDEX IF NOT EXISTS idx_board_activities_board_id ON board_activities(board_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_campaign_deployments_active ON campaign_deployments(active) WHERE active;
+CREATE INDEX IF NOT EXISTS idx_campaign_metrics_project_id ON campaign_metrics(project_id);
+CREATE INDEX IF NOT EXISTS idx_campaign_metrics_history_campaign_id ON campaign_metrics_history(campaign_id, fetched_on);
+CREATE INDEX IF NOT EXISTS idx_campaign_metrics_history_project_id ON campaign_metrics_history(project_id, fetched_on);
+CREATE INDEX IF NOT EXISTS idx_campaign_anomalies_project_id ON campaign_anomalies(project_id);
+CREATE INDEX IF NOT EXISTS idx_deployment_history_asset_id ON deployment_history(asset_id, deployed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_deployment_history_project_id ON deployment_history(project_id, deployed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_deployment_rollbacks_asset_id ON deployment_rollbacks(asset_id);
+CREATE INDEX IF NOT EXISTS idx_webhooks_project_id ON webhooks(project_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, delivered_at);
+CREATE INDEX IF NOT EXISTS idx_export_audit_log_board_id ON export_audit_log(board_id, exported_at);
+CREATE INDEX IF NOT EXISTS idx_persisted_queries_last_used_at ON persisted_queries(last_used_at);
 
 -- Updated at trigger function
 CREATE OR REPLACE FUNCTION update_updated_at_column()
@@ -86,4 +502,321 @@ $$ language 'plpgsql';
 CREATE TRIGGER update_users_updated_at BEFORE UPDATE ON users FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_projects_updated_at BEFORE UPDATE ON projects FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_boards_updated_at BEFORE UPDATE ON boards FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
-CREATE TRIGGER update_assets_updated_at BEFORE UPDATE ON assets FOR EACH ROW EXECUTE FUNCTION update_updated_at_column(); 
\ No newline at end of file
+CREATE TRIGGER update_assets_updated_at BEFORE UPDATE ON assets FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_platform_credentials_updated_at BEFORE UPDATE ON platform_credentials FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_user_preferences_updated_at BEFORE UPDATE ON user_preferences FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Row-level security
+-- Role assumed by request transactions. It does not own the tables and has no
+-- BYPASSRLS, so policies apply even when the service connects as the owner.
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'zamc_app') THEN
+        CREATE ROLE zamc_app NOLOGIN;
+    END IF;
+END $$;
+
+GRANT zamc_app TO CURRENT_USER;
+GRANT USAGE ON SCHEMA public TO zamc_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO zamc_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO zamc_app;
+REVOKE ALL ON platform_credentials FROM zamc_app;
+REVOKE ALL ON asset_sla_escalations FROM zamc_app;
+REVOKE ALL ON user_totp_secrets FROM zamc_app;
+
+-- Current user of the request transaction, NULL outside of one
+CREATE OR REPLACE FUNCTION app_current_user_id()
+RETURNS UUID AS $$
+    SELECT NULLIF(current_setting('app.current_user_id', true), '')::uuid
+$$ LANGUAGE sql STABLE;
+
+-- Project the token of the request transaction is scoped to, NULL for user tokens
+CREATE OR REPLACE FUNCTION app_current_project_scope()
+RETURNS UUID AS $$
+    SELECT NULLIF(current_setting('app.current_project_scope', true), '')::uuid
+$$ LANGUAGE sql STABLE;
+
+-- Organizations of the user of the request transaction. It bypasses the
+-- policies of organization_members, which would otherwise query themselves.
+CREATE OR REPLACE FUNCTION app_current_organizations()
+RETURNS SETOF UUID AS $$
+    SELECT org_id FROM organization_members WHERE user_id = app_current_user_id()
+$$ LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public;
+
+-- Whether an organization has members, so that its creator can join it
+CREATE OR REPLACE FUNCTION app_organization_has_members(UUID)
+RETURNS BOOLEAN AS $$
+    SELECT EXISTS (SELECT 1 FROM organization_members WHERE org_id = $1)
+$$ LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public;
+
+-- Records the previous contents of an asset when its name, URL, status or
+-- approval changes
+CREATE OR REPLACE FUNCTION record_asset_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO asset_versions (asset_id, version_number, name, url, status, approved_by, approved_at, changed_by, changed_at, change_reason)
+    VALUES (
+        OLD.id,
+        COALESCE((SELECT MAX(version_number) FROM asset_versions WHERE asset_id = OLD.id), 0) + 1,
+        OLD.name, OLD.url, OLD.status, OLD.approved_by, OLD.approved_at,
+        app_current_user_id(), NOW(),
+        NULLIF(current_setting('app.asset_change_reason', true), '')
+    );
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS record_asset_version ON assets;
+CREATE TRIGGER record_asset_version BEFORE UPDATE ON assets FOR EACH ROW
+    WHEN ((OLD.name, OLD.url, OLD.status, OLD.approved_by, OLD.approved_at)
+        IS DISTINCT FROM (NEW.name, NEW.url, NEW.status, NEW.approved_by, NEW.approved_at))
+    EXECUTE FUNCTION record_asset_version();
+
+-- Records the metrics of a campaign as those of the day they were fetched
+CREATE OR REPLACE FUNCTION record_campaign_metrics_history()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO campaign_metrics_history (platform, campaign_id, asset_id, project_id, fetched_on, impressions, clicks, cost_micros, conversions, ctr, revenue)
+    VALUES (
+        NEW.platform, NEW.campaign_id, NEW.asset_id, NEW.project_id, (NEW.fetched_at AT TIME ZONE 'UTC')::date,
+        NEW.impressions, NEW.clicks, NEW.cost_micros, NEW.conversions, NEW.ctr, NEW.revenue
+    )
+    ON CONFLICT (platform, campaign_id, fetched_on) DO UPDATE
+    SET asset_id = EXCLUDED.asset_id, project_id = EXCLUDED.project_id,
+        impressions = EXCLUDED.impressions, clicks = EXCLUDED.clicks, cost_micros = EXCLUDED.cost_micros,
+        conversions = EXCLUDED.conversions, ctr = EXCLUDED.ctr, revenue = EXCLUDED.revenue;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS record_campaign_metrics_history ON campaign_metrics;
+CREATE TRIGGER record_campaign_metrics_history AFTER INSERT OR UPDATE ON campaign_metrics FOR EACH ROW
+    EXECUTE FUNCTION record_campaign_metrics_history();
+
+ALTER TABLE organizations ENABLE ROW LEVEL SECURITY;
+ALTER TABLE organization_members ENABLE ROW LEVEL SECURITY;
+ALTER TABLE projects ENABLE ROW LEVEL SECURITY;
+ALTER TABLE boards ENABLE ROW LEVEL SECURITY;
+ALTER TABLE assets ENABLE ROW LEVEL SECURITY;
+ALTER TABLE chat_messages ENABLE ROW LEVEL SECURITY;
+ALTER TABLE user_preferences ENABLE ROW LEVEL SECURITY;
+ALTER TABLE chat_message_reads ENABLE ROW LEVEL SECURITY;
+ALTER TABLE message_reactions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE deployment_templates ENABLE ROW LEVEL SECURITY;
+ALTER TABLE campaign_schedules ENABLE ROW LEVEL SECURITY;
+ALTER TABLE keyword_quality_scores ENABLE ROW LEVEL SECURITY;
+-- Written and read outside of user transactions only, so it has no policy
+ALTER TABLE mutation_audit_log ENABLE ROW LEVEL SECURITY;
+ALTER TABLE login_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE persisted_queries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE board_templates ENABLE ROW LEVEL SECURITY;
+ALTER TABLE board_webhooks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE board_webhook_deliveries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE board_activities ENABLE ROW LEVEL SECURITY;
+ALTER TABLE campaign_deployments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE campaign_metrics ENABLE ROW LEVEL SECURITY;
+ALTER TABLE campaign_metrics_history ENABLE ROW LEVEL SECURITY;
+ALTER TABLE campaign_anomalies ENABLE ROW LEVEL SECURITY;
+ALTER TABLE deployment_history ENABLE ROW LEVEL SECURITY;
+ALTER TABLE deployment_rollbacks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhooks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_deliveries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE asset_versions ENABLE ROW LEVEL SECURITY;
+
+-- Organizations are visible to their members. Any user may create one.
+DROP POLICY IF EXISTS organization_isolation ON organizations;
+CREATE POLICY organization_isolation ON organizations
+    USING (id IN (SELECT app_current_organizations()));
+
+DROP POLICY IF EXISTS organization_creation ON organizations;
+CREATE POLICY organization_creation ON organizations FOR INSERT
+    WITH CHECK (true);
+
+-- Members see the other members of their organizations. The creator of an
+-- organization joins it as its first member.
+DROP POLICY IF EXISTS organization_member_isolation ON organization_members;
+CREATE POLICY organization_member_isolation ON organization_members
+    USING (org_id IN (SELECT app_current_organizations()));
+
+DROP POLICY IF EXISTS organization_member_creation ON organization_members;
+CREATE POLICY organization_member_creation ON organization_members FOR INSERT
+    WITH CHECK (user_id = app_current_user_id() AND NOT app_organization_has_members(org_id));
+
+-- Projects of organizations are visible to the members of the organization
+-- only, even when shared with other users
+DROP POLICY IF EXISTS project_isolation ON projects;
+CREATE POLICY project_isolation ON projects
+    USING (
+        (
+            org_id IN (SELECT app_current_organizations())
+            OR (
+                org_id IS NULL
+                AND (
+                    owner_id = app_current_user_id()
+                    OR id IN (SELECT project_id FROM project_members WHERE user_id = app_current_user_id())
+                )
+            )
+        )
+        AND (app_current_project_scope() IS NULL OR id = app_current_project_scope())
+    );
+
+-- Child tables inherit visibility from the project policy through their subqueries
+DROP POLICY IF EXISTS board_isolation ON boards;
+CREATE POLICY board_isolation ON boards
+    USING (project_id IN (SELECT id FROM projects));
+
+DROP POLICY IF EXISTS asset_isolation ON assets;
+CREATE POLICY asset_isolation ON assets
+    USING (board_id IN (SELECT id FROM boards));
+
+DROP POLICY IF EXISTS chat_message_isolation ON chat_messages;
+CREATE POLICY chat_message_isolation ON chat_messages
+    USING (board_id IN (SELECT id FROM boards));
+
+DROP POLICY IF EXISTS user_preferences_isolation ON user_preferences;
+CREATE POLICY user_preferences_isolation ON user_preferences
+    USING (user_id = app_current_user_id());
+
+DROP POLICY IF EXISTS chat_message_read_isolation ON chat_message_reads;
+CREATE POLICY chat_message_read_isolation ON chat_message_reads
+    USING (
+        user_id = app_current_user_id()
+        AND message_id IN (SELECT id FROM chat_messages)
+    );
+
+DROP POLICY IF EXISTS message_reaction_isolation ON message_reactions;
+CREATE POLICY message_reaction_isolation ON message_reactions
+    USING (message_id IN (SELECT id FROM chat_messages));
+
+DROP POLICY IF EXISTS deployment_template_isolation ON deployment_templates;
+CREATE POLICY deployment_template_isolation ON deployment_templates
+    USING (owner_id = app_current_user_id());
+
+DROP POLICY IF EXISTS board_template_visibility ON board_templates;
+CREATE POLICY board_template_visibility ON board_templates FOR SELECT
+    USING (is_public OR created_by = app_current_user_id()::text);
+
+DROP POLICY IF EXISTS board_template_isolation ON board_templates;
+CREATE POLICY board_template_isolation ON board_templates
+    USING (created_by = app_current_user_id()::text);
+
+DROP POLICY IF EXISTS board_webhook_isolation ON board_webhooks;
+CREATE POLICY board_webhook_isolation ON board_webhooks
+    USING (board_id IN (SELECT id FROM boards));
+
+DROP POLICY IF EXISTS board_webhook_delivery_isolation ON board_webhook_deliveries;
+CREATE POLICY board_webhook_delivery_isolation ON board_webhook_deliveries
+    USING (webhook_id IN (SELECT id FROM board_webhooks));
+
+DROP POLICY IF EXISTS board_activity_isolation ON board_activities;
+CREATE POLICY board_activity_isolation ON board_activities
+    USING (board_id IN (SELECT id FROM boards));
+
+DROP POLICY IF EXISTS campaign_schedule_isolation ON campaign_schedules;
+CREATE POLICY campaign_schedule_isolation ON campaign_schedules
+    USING (tenant_id = app_current_user_id());
+
+DROP POLICY IF EXISTS keyword_quality_score_isolation ON keyword_quality_scores;
+CREATE POLICY keyword_quality_score_isolation ON keyword_quality_scores
+    USING (asset_id IN (SELECT id FROM assets));
+
+DROP POLICY IF EXISTS campaign_deployment_isolation ON campaign_deployments;
+CREATE POLICY campaign_deployment_isolation ON campaign_deployments
+    USING (asset_id IN (SELECT id FROM assets));
+
+DROP POLICY IF EXISTS campaign_metrics_isolation ON campaign_metrics;
+CREATE POLICY campaign_metrics_isolation ON campaign_metrics
+    USING (asset_id IN (SELECT id FROM assets));
+
+DROP POLICY IF EXISTS campaign_metrics_history_isolation ON campaign_metrics_history;
+CREATE POLICY campaign_metrics_history_isolation ON campaign_metrics_history
+    USING (asset_id IN (SELECT id FROM assets));
+
+DROP POLICY IF EXISTS campaign_anomaly_isolation ON campaign_anomalies;
+CREATE POLICY campaign_anomaly_isolation ON campaign_anomalies
+    USING (asset_id IN (SELECT id FROM assets));
+
+DROP POLICY IF EXISTS deployment_history_isolation ON deployment_history;
+CREATE POLICY deployment_history_isolation ON deployment_history
+    USING (asset_id IN (SELECT id FROM assets));
+
+DROP POLICY IF EXISTS deployment_rollback_isolation ON deployment_rollbacks;
+CREATE POLICY deployment_rollback_isolation ON deployment_rollbacks
+    USING (asset_id IN (SELECT id FROM assets));
+
+DROP POLICY IF EXISTS webhook_isolation ON webhooks;
+CREATE POLICY webhook_isolation ON webhooks
+    USING (project_id IN (SELECT id FROM projects));
+
+DROP POLICY IF EXISTS webhook_delivery_isolation ON webhook_deliveries;
+CREATE POLICY webhook_delivery_isolation ON webhook_deliveries
+    USING (webhook_id IN (SELECT id FROM webhooks));
+
+DROP POLICY IF EXISTS asset_version_isolation ON asset_versions;
+CREATE POLICY asset_version_isolation ON asset_versions
+    USING (asset_id IN (SELECT id FROM assets));
+
+-- The patterns built into the validator, seeded once so that patterns added or
+-- deactivated since are kept
+INSERT INTO security_patterns (category, pattern, severity)
+SELECT category, pattern, severity FROM (VALUES
+    ('sql_injection', '(?i)(union\s+select)', 'high'),
+    ('sql_injection', '(?i)(select\s+.*\s+from)', 'high'),
+    ('sql_injection', '(?i)(insert\s+into)', 'high'),
+    ('sql_injection', '(?i)(delete\s+from)', 'high'),
+    ('sql_injection', '(?i)(update\s+.*\s+set)', 'high'),
+    ('sql_injection', '(?i)(drop\s+table)', 'critical'),
+    ('sql_injection', '(?i)(create\s+table)', 'high'),
+    ('sql_injection', '(?i)(alter\s+table)', 'high'),
+    ('sql_injection', '(?i)(\''\s*or\s*\''\s*=\s*\'')', 'high'),
+    ('sql_injection', '(?i)(\''\s*or\s*1\s*=\s*1)', 'high'),
+    ('sql_injection', '(?i)(--\s*$)', 'warning'),
+    ('sql_injection', '(?i)(/\*.*\*/)', 'warning'),
+    ('sql_injection', '(?i)(xp_cmdshell)', 'critical'),
+    ('sql_injection', '(?i)(sp_executesql)', 'critical'),
+    ('xss', '(?i)<script[^>]*>.*?</script>', 'high'),
+    ('xss', '(?i)<iframe[^>]*>.*?</iframe>', 'high'),
+    ('xss', '(?i)<object[^>]*>.*?</object>', 'high'),
+    ('xss', '(?i)<embed[^>]*>', 'high'),
+    ('xss', '(?i)<link[^>]*>', 'high'),
+    ('xss', '(?i)<meta[^>]*>', 'high'),
+    ('xss', '(?i)javascript:', 'high'),
+    ('xss', '(?i)vbscript:', 'high'),
+    ('xss', '(?i)onload\s*=', 'high'),
+    ('xss', '(?i)onerror\s*=', 'high'),
+    ('xss', '(?i)onclick\s*=', 'high'),
+    ('xss', '(?i)onmouseover\s*=', 'high'),
+    ('xss', '(?i)onfocus\s*=', 'high'),
+    ('xss', '(?i)onblur\s*=', 'high'),
+    ('xss', '(?i)onchange\s*=', 'high'),
+    ('xss', '(?i)onsubmit\s*=', 'high'),
+    ('xss', '(?i)expression\s*\(', 'high'),
+    ('xss', '(?i)@import', 'high'),
+    ('xss', '(?i)behavior\s*:', 'high')
+) AS patterns (category, pattern, severity)
+WHERE NOT EXISTS (SELECT 1 FROM security_patterns);
+
+-- The public system board templates
+INSERT INTO board_templates (id, name, description, default_boards, is_public) VALUES
+    ('6a1f4c1e-0c7e-4f3b-9d51-5b8f0e6f1a01', 'Social Media Campaign',
+     'Plan, produce and schedule posts across social platforms',
+     '[{"name": "Strategy", "description": "Goals, audiences and key messages"},
+       {"name": "Content Calendar", "description": "Posts planned per platform and date"},
+       {"name": "Creative", "description": "Images and videos in production"},
+       {"name": "Approval", "description": "Posts waiting for review"},
+       {"name": "Published", "description": "Live posts and their performance"}]'::jsonb,
+     TRUE),
+    ('6a1f4c1e-0c7e-4f3b-9d51-5b8f0e6f1a02', 'Product Launch',
+     'Coordinate the assets of a product launch from teaser to follow-up',
+     '[{"name": "Research", "description": "Market, competitors and positioning"},
+       {"name": "Teasers", "description": "Pre-launch announcements"},
+       {"name": "Launch Assets", "description": "Landing pages, ads and press material"},
+       {"name": "Follow-up", "description": "Post-launch campaigns and retargeting"}]'::jsonb,
+     TRUE),
+    ('6a1f4c1e-0c7e-4f3b-9d51-5b8f0e6f1a03', 'Email Campaign',
+     'Write, design and test a sequence of emails',
+     '[{"name": "Copy", "description": "Subject lines and email bodies"},
+       {"name": "Design", "description": "Templates and imagery"},
+       {"name": "Testing", "description": "A/B variants and rendering checks"},
+       {"name": "Sent", "description": "Delivered emails and their results"}]'::jsonb,
+     TRUE)
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/service"
+)
+
+// runDryRun validates the deployment of the asset status changed event in the
+// JSON file at path, or stdin for "-", and prints its validation report. It
+// returns the exit code: 0 when the deployment would go through, 1 when it
+// would not and 2 when it could not be validated.
+func runDryRun(deploymentService *service.DeploymentService, path string, logger *logrus.Logger) int {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		logger.WithError(err).Error("Failed to read dry-run event")
+		return 2
+	}
+
+	var event models.AssetStatusChangedEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		logger.WithError(err).Error("Failed to unmarshal dry-run event")
+		return 2
+	}
+	event.Mode = models.DeploymentModeDryRun
+
+	report, err := deploymentService.DryRunDeployment(context.Background(), &event)
+	if err != nil {
+		logger.WithError(err).Error("Deployment dry run failed")
+		return 2
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		logger.WithError(err).Error("Failed to write validation report")
+		return 2
+	}
+
+	if !report.Valid {
+		return 1
+	}
+	return 0
+}
@@ -2,24 +2,52 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 
+	"github.com/zamc/connectors/internal/analytics"
+	"github.com/zamc/connectors/internal/cache"
+	"github.com/zamc/connectors/internal/campaigns"
 	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/credentials"
+	"github.com/zamc/connectors/internal/dlq"
+	"github.com/zamc/connectors/internal/health"
+	"github.com/zamc/connectors/internal/models"
 	"github.com/zamc/connectors/internal/nats"
+	"github.com/zamc/connectors/internal/notifications"
 	"github.com/zamc/connectors/internal/platforms/googleads"
+	"github.com/zamc/connectors/internal/platforms/linkedin"
 	"github.com/zamc/connectors/internal/platforms/meta"
+	"github.com/zamc/connectors/internal/platforms/tiktok"
+	"github.com/zamc/connectors/internal/platforms/twitter"
+	"github.com/zamc/connectors/internal/qualityscores"
+	"github.com/zamc/connectors/internal/ratelimit"
+	"github.com/zamc/connectors/internal/scheduler"
 	"github.com/zamc/connectors/internal/service"
+	"github.com/zamc/connectors/internal/stats"
+	"github.com/zamc/connectors/internal/tracing"
 )
 
 func main() {
+	dryRunEvent := flag.String("dry-run", "", "validate the deployment of the asset status changed event in this JSON file (- for stdin), print the report and exit")
+	flag.Parse()
+
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
 		logrus.Warn("No .env file found, using environment variables")
@@ -39,6 +67,15 @@ func main() {
 		"port":        cfg.Port,
 	}).Info("Starting ZAMC Ad Deployment Connectors service")
 
+	// Initialize tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), "connectors", &cfg.Tracing)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize tracing")
+	}
+	if cfg.Tracing.OTLPEndpoint == "" {
+		logger.Warn("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT not set, traces will not be exported")
+	}
+
 	// Initialize clients
 	googleAdsClient, err := googleads.NewClient(&cfg.GoogleAds, logger)
 	if err != nil {
@@ -50,9 +87,40 @@ func main() {
 		logger.WithError(err).Fatal("Failed to initialize Meta client")
 	}
 
-	natsClient, err := nats.NewClient(&cfg.NATS, logger)
+	// Dry runs publish nothing, so they do not need NATS
+	var natsClient *nats.Client
+	if *dryRunEvent == "" {
+		natsClient, err = nats.NewClient(&cfg.NATS, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize NATS client")
+		}
+	}
+
+	// Initialize per-tenant credential store
+	var credentialStore *credentials.CredentialStore
+	if cfg.Credentials.Enabled() {
+		credentialStore, err = credentials.Open(&cfg.Credentials)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize credential store")
+		}
+	} else {
+		logger.Warn("DATABASE_URL not set, all tenants will use the default platform credentials")
+	}
+
+	// Rotate the default platform credentials without a restart
+	credentialBackend, err := credentials.NewBackend(&cfg.Credentials)
 	if err != nil {
-		logger.WithError(err).Fatal("Failed to initialize NATS client")
+		logger.WithError(err).Fatal("Failed to initialize credential backend")
+	}
+	credentialRotator := credentials.NewRotator(credentialBackend, googleAdsClient, metaClient, logger)
+
+	// Initialize deployment statistics
+	var statsCollector *stats.StatsCollector
+	redisClient, err := connectRedis(&cfg.Redis)
+	if err != nil {
+		logger.WithError(err).Warn("Redis unavailable, deployment statistics disabled")
+	} else {
+		statsCollector = stats.NewStatsCollector(redisClient)
 	}
 
 	// Initialize deployment service
@@ -60,16 +128,177 @@ func main() {
 		googleAdsClient,
 		metaClient,
 		natsClient,
+		credentialStore,
+		statsCollector,
 		&cfg.Deployment,
 		logger,
 	)
 
+	// Back off from Google Ads and Meta accounts running out of API quota
+	deploymentService.SetRateLimiter(ratelimit.NewPlatformRateLimiter(cfg.Deployment.QuotaBackoff, cfg.Deployment.MaxQuotaBackoff))
+
+	// Serve repeated Meta reads and retried campaign creations from memory
+	responseCache := cache.NewPlatformResponseCache()
+	deploymentService.SetResponseCache(responseCache)
+
+	// Initialize TikTok deployments
+	if cfg.TikTok.Enabled() {
+		tiktokClient, err := tiktok.NewClient(&cfg.TikTok, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize TikTok client")
+		}
+		deploymentService.SetTikTokClient(tiktokClient)
+	} else {
+		logger.Warn("TIKTOK_ACCESS_TOKEN not set, TikTok deployments are disabled")
+	}
+
+	// Initialize LinkedIn deployments
+	if cfg.LinkedIn.Enabled() {
+		linkedinClient, err := linkedin.NewClient(&cfg.LinkedIn, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize LinkedIn client")
+		}
+		deploymentService.SetLinkedInClient(linkedinClient)
+	} else {
+		logger.Warn("LINKEDIN_ACCESS_TOKEN not set, LinkedIn deployments are disabled")
+	}
+
+	// Initialize Twitter deployments
+	if cfg.Twitter.Enabled() {
+		twitterClient, err := twitter.NewClient(&cfg.Twitter, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize Twitter client")
+		}
+		deploymentService.SetTwitterClient(twitterClient)
+	} else {
+		logger.Warn("TWITTER_ACCESS_TOKEN not set, Twitter deployments are disabled")
+	}
+
+	// Simulate the deployment pipeline of an asset instead of serving
+	if *dryRunEvent != "" {
+		os.Exit(runDryRun(deploymentService, *dryRunEvent, logger))
+	}
+
+	// Initialize campaign pause/resume schedules, stored next to the credentials
+	var scheduleStore *scheduler.Store
+	var schedulerWorker *scheduler.SchedulerWorker
+	if cfg.Credentials.Enabled() {
+		scheduleStore, err = scheduler.Open(cfg.Credentials.DatabaseURL)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize campaign schedule store")
+		}
+		schedulerWorker = scheduler.NewSchedulerWorker(scheduleStore, deploymentService.CampaignController, logger)
+		deploymentService.SetScheduler(schedulerWorker)
+	} else {
+		logger.Warn("DATABASE_URL not set, campaign schedules are disabled")
+	}
+
+	// Initialize keyword quality score storage
+	var qualityScoreStore *qualityscores.Store
+	if cfg.Credentials.Enabled() {
+		qualityScoreStore, err = qualityscores.Open(cfg.Credentials.DatabaseURL)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize keyword quality score store")
+		}
+		deploymentService.SetQualityScoreStore(qualityScoreStore)
+	} else {
+		logger.Warn("DATABASE_URL not set, keyword quality scores are disabled")
+	}
+
+	// Initialize campaign metrics polling and rollbacks of Google Ads and Meta deployments
+	var campaignStore *campaigns.Store
+	var metricsPoller *campaigns.MetricsPoller
+	if cfg.Credentials.Enabled() {
+		campaignStore, err = campaigns.Open(cfg.Credentials.DatabaseURL)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize campaign deployment store")
+		}
+		deploymentService.SetCampaignStore(campaignStore)
+		metricsPoller = campaigns.NewMetricsPoller(campaignStore, deploymentService, natsClient, cfg.Deployment.CampaignMetricsInterval, logger)
+	} else {
+		logger.Warn("DATABASE_URL not set, campaign metrics polling and deployment rollbacks are disabled")
+	}
+
+	// Initialize detection of campaign click-through rates far from their rolling average
+	var anomalyStore *analytics.Store
+	var anomalyDetector *analytics.AnomalyDetector
+	if metricsPoller != nil {
+		anomalyStore, err = analytics.Open(cfg.Credentials.DatabaseURL)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize campaign anomaly store")
+		}
+		anomalyDetector = analytics.NewAnomalyDetector(anomalyStore, natsClient, &cfg.Anomaly, logger)
+		metricsPoller.SetAnomalyDetector(anomalyDetector)
+	}
+
+	// Initialize the dead letter queue of deployments that failed on every attempt
+	var dlqStore *dlq.Store
+	var dlqProcessor *dlq.DLQProcessor
+	if cfg.Credentials.Enabled() {
+		dlqStore, err = dlq.Open(cfg.Credentials.DatabaseURL)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize dead letter store")
+		}
+		dlqProcessor = dlq.NewDLQProcessor(dlqStore, natsClient, deploymentService, logger)
+	} else {
+		logger.Warn("DATABASE_URL not set, dead letters will stay in the NATS stream")
+	}
+
+	// Notify of deployment results
+	var notificationChannels []notifications.Channel
+	if cfg.Notification.SlackEnabled {
+		if cfg.Slack.WebhookURL == "" {
+			logger.Warn("SLACK_WEBHOOK_URL not set, deployment results will only be posted for projects with their own webhook")
+		}
+		slackNotifier := notifications.NewSlackNotifier(&cfg.Slack, logger)
+		slackNotifier.SetProjectWebhooks(cfg.Notification.SlackProjectWebhooks)
+		notificationChannels = append(notificationChannels, slackNotifier)
+	}
+	if cfg.Notification.EmailEnabled {
+		if cfg.Notification.SMTPHost == "" || len(cfg.Notification.EmailTo) == 0 {
+			logger.Fatal("NOTIFY_EMAIL_ENABLED requires SMTP_HOST and NOTIFY_EMAIL_TO")
+		}
+		notificationChannels = append(notificationChannels, notifications.NewEmailNotifier(&cfg.Notification, logger))
+	}
+	var notificationService *notifications.NotificationService
+	if len(notificationChannels) > 0 {
+		notificationService = notifications.NewNotificationService(logger, notificationChannels...)
+		deploymentService.SetNotificationService(notificationService)
+		logger.WithField("channels", len(notificationChannels)).Info("Deployment notifications enabled")
+	}
+
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Record the health timeline for post-mortems
+	var healthRecorder *health.HealthRecorder
+	if redisClient != nil {
+		healthRecorder = health.NewHealthRecorder(redisClient, "connectors", deploymentService.HealthCheck, logger)
+		go healthRecorder.Run(ctx)
+	} else {
+		logger.Warn("Redis unavailable, health history disabled")
+	}
+
+	// Pick up rotated platform credentials
+	go credentialRotator.Run(ctx, cfg.Credentials.CredentialRefreshInterval)
+
+	// Drop the expired platform responses
+	go responseCache.Run(ctx, cache.DefaultSweepInterval)
+
+	// Send the deployment results in the background
+	if notificationService != nil {
+		go notificationService.Run(ctx)
+	}
+
 	// Start HTTP server for health checks
-	httpServer := startHTTPServer(cfg.Port, deploymentService, logger)
+	httpServer := startHTTPServer(cfg.Port, cfg.Monitoring.AdminToken, deploymentService, natsClient, healthRecorder, dlqProcessor, anomalyDetector, credentialRotator, logger)
+
+	// Start Prometheus metrics server
+	var metricsServer *http.Server
+	if cfg.Monitoring.EnableMetrics {
+		metricsServer = startMetricsServer(cfg.Monitoring.MetricsPort, deploymentService, natsClient, logger)
+	}
 
 	// Start NATS event listener
 	go func() {
@@ -79,6 +308,135 @@ func main() {
 		}
 	}()
 
+	// Start scheduled deployment listener
+	go func() {
+		if err := natsClient.SubscribeToScheduledDeployments(ctx, deploymentService); err != nil {
+			logger.WithError(err).Error("Scheduled deployment subscription failed")
+		}
+	}()
+
+	// Start campaign duplication request listener
+	go func() {
+		if err := natsClient.SubscribeToCampaignDuplicationRequests(ctx, deploymentService); err != nil {
+			logger.WithError(err).Error("Campaign duplication subscription failed")
+		}
+	}()
+
+	// Start deployment cost estimate request listener
+	go func() {
+		if err := natsClient.SubscribeToDeploymentEstimateRequests(ctx, deploymentService); err != nil {
+			logger.WithError(err).Error("Deployment estimate subscription failed")
+		}
+	}()
+
+	// Start dry-run deployment request listener
+	go func() {
+		if err := natsClient.SubscribeToDeploymentDryRunRequests(ctx, deploymentService); err != nil {
+			logger.WithError(err).Error("Deployment dry-run subscription failed")
+		}
+	}()
+
+	// Start template deployment request listener
+	go func() {
+		if err := natsClient.SubscribeToTemplateDeploymentRequests(ctx, deploymentService); err != nil {
+			logger.WithError(err).Error("Template deployment subscription failed")
+		}
+	}()
+
+	// Start platform credentials request listener
+	if credentialStore != nil {
+		go func() {
+			if err := natsClient.SubscribeToPlatformCredentialsRequests(ctx, deploymentService); err != nil {
+				logger.WithError(err).Error("Platform credentials subscription failed")
+			}
+		}()
+	}
+
+	// Start campaign scheduler and schedule request listener
+	if schedulerWorker != nil {
+		go func() {
+			if err := schedulerWorker.Run(ctx); err != nil {
+				logger.WithError(err).Error("Campaign scheduler failed")
+			}
+		}()
+
+		go func() {
+			if err := natsClient.SubscribeToCampaignScheduleRequests(ctx, deploymentService); err != nil {
+				logger.WithError(err).Error("Campaign schedule subscription failed")
+			}
+		}()
+	}
+
+	// Start keyword quality score fetcher
+	if qualityScoreStore != nil {
+		go func() {
+			if err := natsClient.SubscribeToKeywordQualityScoreFetches(ctx, deploymentService); err != nil {
+				logger.WithError(err).Error("Keyword quality score subscription failed")
+			}
+		}()
+	}
+
+	// Start campaign metrics poller and deployment rollback request listener
+	if metricsPoller != nil {
+		go func() {
+			if err := metricsPoller.Run(ctx); err != nil {
+				logger.WithError(err).Error("Campaign metrics poller failed")
+			}
+		}()
+
+		go func() {
+			if err := natsClient.SubscribeToDeploymentRollbackRequests(ctx, deploymentService); err != nil {
+				logger.WithError(err).Error("Deployment rollback subscription failed")
+			}
+		}()
+
+		go func() {
+			if err := natsClient.SubscribeToCustomAudienceDeletionRequests(ctx, deploymentService); err != nil {
+				logger.WithError(err).Error("Custom audience deletion subscription failed")
+			}
+		}()
+	}
+
+	// Start ad review checker
+	go func() {
+		if err := natsClient.SubscribeToAdReviewChecks(ctx, deploymentService); err != nil {
+			logger.WithError(err).Error("Ad review subscription failed")
+		}
+	}()
+
+	// Start dead letter processor
+	if dlqProcessor != nil {
+		go func() {
+			if err := dlqProcessor.Run(ctx); err != nil {
+				logger.WithError(err).Error("Dead letter subscription failed")
+			}
+		}()
+	}
+
+	// Start asset SLA breach listener
+	if cfg.Slack.WebhookURL != "" {
+		slackNotifier := notifications.NewSlackNotifier(&cfg.Slack, logger)
+		go func() {
+			if err := natsClient.SubscribeToAssetSLABreaches(ctx, slackNotifier); err != nil {
+				logger.WithError(err).Error("Asset SLA breach subscription failed")
+			}
+		}()
+	} else {
+		logger.Warn("SLACK_WEBHOOK_URL not set, asset SLA breaches will not be escalated")
+	}
+
+	// Start suspicious login listener
+	if cfg.Notification.SMTPHost != "" {
+		emailNotifier := notifications.NewEmailNotifier(&cfg.Notification, logger)
+		go func() {
+			if err := natsClient.SubscribeToSuspiciousLogins(ctx, emailNotifier); err != nil {
+				logger.WithError(err).Error("Suspicious login subscription failed")
+			}
+		}()
+	} else {
+		logger.Warn("SMTP_HOST not set, users will not be warned of suspicious logins")
+	}
+
 	// Wait for shutdown signal
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -99,11 +457,74 @@ func main() {
 		logger.WithError(err).Error("HTTP server shutdown failed")
 	}
 
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			logger.WithError(err).Error("Metrics server shutdown failed")
+		}
+	}
+
+	// Let the deployment workers finish their current deployments
+	deploymentService.Stop()
+
 	// Close NATS connection
 	if err := natsClient.Close(); err != nil {
 		logger.WithError(err).Error("Failed to close NATS connection")
 	}
 
+	// Close Redis connection
+	if redisClient != nil {
+		if err := redisClient.Close(); err != nil {
+			logger.WithError(err).Error("Failed to close Redis connection")
+		}
+	}
+
+	// Close campaign schedule store
+	if scheduleStore != nil {
+		if err := scheduleStore.Close(); err != nil {
+			logger.WithError(err).Error("Failed to close campaign schedule store")
+		}
+	}
+
+	// Close keyword quality score store
+	if qualityScoreStore != nil {
+		if err := qualityScoreStore.Close(); err != nil {
+			logger.WithError(err).Error("Failed to close keyword quality score store")
+		}
+	}
+
+	// Close campaign deployment store
+	if campaignStore != nil {
+		if err := campaignStore.Close(); err != nil {
+			logger.WithError(err).Error("Failed to close campaign deployment store")
+		}
+	}
+
+	// Close campaign anomaly store
+	if anomalyStore != nil {
+		if err := anomalyStore.Close(); err != nil {
+			logger.WithError(err).Error("Failed to close campaign anomaly store")
+		}
+	}
+
+	// Close dead letter store
+	if dlqStore != nil {
+		if err := dlqStore.Close(); err != nil {
+			logger.WithError(err).Error("Failed to close dead letter store")
+		}
+	}
+
+	// Close credential store
+	if credentialStore != nil {
+		if err := credentialStore.Close(); err != nil {
+			logger.WithError(err).Error("Failed to close credential store")
+		}
+	}
+
+	// Flush pending spans
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.WithError(err).Error("Failed to flush traces")
+	}
+
 	logger.Info("Service shutdown completed")
 }
 
@@ -126,8 +547,28 @@ func setupLogging(level string) *logrus.Logger {
 	return logger
 }
 
+// connectRedis connects to the Redis server used for deployment statistics
+func connectRedis(cfg *config.RedisConfig) (*redis.Client, error) {
+	opts, err := redis.ParseURL(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to ping Redis: %w", err)
+	}
+
+	return client, nil
+}
+
 // startHTTPServer starts the HTTP server for health checks and metrics
-func startHTTPServer(port int, deploymentService *service.DeploymentService, logger *logrus.Logger) *http.Server {
+func startHTTPServer(port int, adminToken string, deploymentService *service.DeploymentService, natsClient *nats.Client, healthRecorder *health.HealthRecorder, dlqProcessor *dlq.DLQProcessor, anomalyDetector *analytics.AnomalyDetector, credentialRotator *credentials.Rotator, logger *logrus.Logger) *http.Server {
 	mux := http.NewServeMux()
 
 	// Health check endpoint
@@ -151,11 +592,48 @@ func startHTTPServer(port int, deploymentService *service.DeploymentService, log
 			w.WriteHeader(http.StatusServiceUnavailable)
 		}
 
+		// Accounts running out of API quota degrade the service without failing the
+		// probe, deployments to them wait for their backoff to end
+		status := getOverallStatus(allHealthy)
+		quotas := deploymentService.PlatformQuotas()
+		for _, quota := range quotas {
+			if allHealthy && quota.Low() {
+				status = "degraded"
+				break
+			}
+		}
+
+		// Platforms whose circuit is open degrade the service the same way, their
+		// deployments fail until a trial deployment succeeds
+		circuits := deploymentService.CircuitStates()
+		for _, state := range circuits {
+			if allHealthy && state != "closed" {
+				status = "degraded"
+				break
+			}
+		}
+
 		response := map[string]interface{}{
-			"status":    getOverallStatus(allHealthy),
+			"status":    status,
 			"timestamp": time.Now().Format(time.RFC3339),
 			"version":   "1.0.0",
 			"services":  health,
+			"quotas":    quotas,
+			"circuits":  circuits,
+		}
+
+		// Billing does not affect health, deployments that it cannot pay for fail on their own
+		if billing, err := deploymentService.GoogleAdsBillingStatus(ctx); err != nil {
+			response["billing"] = map[string]interface{}{"google_ads": map[string]string{"error": err.Error()}}
+		} else {
+			response["billing"] = map[string]interface{}{"google_ads": billing}
+		}
+
+		// Events waiting to be handled; a growing backlog means subscribers are falling behind
+		if lag, err := natsClient.EventStreamLag(ctx); err != nil {
+			response["stream_lag"] = map[string]string{"error": err.Error()}
+		} else {
+			response["stream_lag"] = lag
 		}
 
 		if err := writeJSONResponse(w, response); err != nil {
@@ -163,9 +641,64 @@ func startHTTPServer(port int, deploymentService *service.DeploymentService, log
 		}
 	})
 
+	// Health snapshots recorded between ?start= and ?end= (Unix times)
+	mux.HandleFunc("/health/history", func(w http.ResponseWriter, r *http.Request) {
+		if healthRecorder == nil {
+			http.Error(w, "Health history unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		start, end, err := healthTimeRange(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		snapshots, err := healthRecorder.History(r.Context(), start, end)
+		if err != nil {
+			logger.WithError(err).Error("Failed to read health history")
+			http.Error(w, "Health history unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		if err := writeJSONResponse(w, map[string]interface{}{"snapshots": snapshots}); err != nil {
+			logger.WithError(err).Error("Failed to write health history response")
+		}
+	})
+
+	// Transitions from healthy to unhealthy in the health timeline
+	mux.HandleFunc("/health/incidents", func(w http.ResponseWriter, r *http.Request) {
+		if healthRecorder == nil {
+			http.Error(w, "Health history unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		start, end, err := healthTimeRange(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		incidents, err := healthRecorder.Incidents(r.Context(), start, end)
+		if err != nil {
+			logger.WithError(err).Error("Failed to read health incidents")
+			http.Error(w, "Health history unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		if err := writeJSONResponse(w, map[string]interface{}{"incidents": incidents}); err != nil {
+			logger.WithError(err).Error("Failed to write health incidents response")
+		}
+	})
+
 	// Metrics endpoint
 	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
-		stats := deploymentService.GetDeploymentStats()
+		stats, err := deploymentService.GetDeploymentStats(r.Context())
+		if err != nil {
+			logger.WithError(err).Error("Failed to read deployment stats")
+			http.Error(w, "Deployment statistics unavailable", http.StatusServiceUnavailable)
+			return
+		}
 		
 		w.Header().Set("Content-Type", "application/json")
 		if err := writeJSONResponse(w, stats); err != nil {
@@ -173,6 +706,187 @@ func startHTTPServer(port int, deploymentService *service.DeploymentService, log
 		}
 	})
 
+	// Stats reset endpoint (admin only)
+	mux.HandleFunc("/admin/stats/reset", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if !isAdminRequest(r, adminToken) {
+			http.Error(w, "Unauthorized", http.StatusForbidden)
+			return
+		}
+
+		if err := deploymentService.ResetStats(r.Context()); err != nil {
+			logger.WithError(err).Error("Failed to reset deployment stats")
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		response := map[string]interface{}{
+			"status":    "reset",
+			"timestamp": time.Now().Format(time.RFC3339),
+		}
+
+		if err := writeJSONResponse(w, response); err != nil {
+			logger.WithError(err).Error("Failed to write stats reset response")
+		}
+	})
+
+	// JetStream consumers, of the stream given by ?stream= or the delayed message stream
+	mux.HandleFunc("/admin/consumers", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if !isAdminRequest(r, adminToken) {
+			http.Error(w, "Unauthorized", http.StatusForbidden)
+			return
+		}
+
+		streamName := consumerStream(r)
+		consumers, err := natsClient.ListConsumers(r.Context(), streamName)
+		if err != nil {
+			writeConsumerError(w, err, logger)
+			return
+		}
+
+		response := map[string]interface{}{
+			"stream":    streamName,
+			"consumers": consumers,
+		}
+
+		if err := writeJSONResponse(w, response); err != nil {
+			logger.WithError(err).Error("Failed to write consumers response")
+		}
+	})
+
+	mux.HandleFunc("/admin/consumers/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if !isAdminRequest(r, adminToken) {
+			http.Error(w, "Unauthorized", http.StatusForbidden)
+			return
+		}
+
+		consumerName := strings.TrimPrefix(r.URL.Path, "/admin/consumers/")
+		if consumerName == "" || strings.Contains(consumerName, "/") {
+			http.Error(w, "Invalid consumer name", http.StatusBadRequest)
+			return
+		}
+
+		streamName := consumerStream(r)
+		if err := natsClient.DeleteConsumer(r.Context(), streamName, consumerName); err != nil {
+			writeConsumerError(w, err, logger)
+			return
+		}
+
+		response := map[string]interface{}{
+			"status":   "deleted",
+			"stream":   streamName,
+			"consumer": consumerName,
+		}
+
+		if err := writeJSONResponse(w, response); err != nil {
+			logger.WithError(err).Error("Failed to write consumer deletion response")
+		}
+	})
+
+	// Immediate rotation of the default credentials of a platform (admin only)
+	mux.HandleFunc("/admin/credentials/rotate/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if !isAdminRequest(r, adminToken) {
+			http.Error(w, "Unauthorized", http.StatusForbidden)
+			return
+		}
+
+		platform := models.Platform(strings.TrimPrefix(r.URL.Path, "/admin/credentials/rotate/"))
+		err := credentialRotator.Rotate(r.Context(), platform)
+		if errors.Is(err, credentials.ErrUnsupportedPlatform) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		response := map[string]interface{}{
+			"status":    "rotated",
+			"platform":  platform,
+			"timestamp": time.Now().Format(time.RFC3339),
+		}
+
+		if err := writeJSONResponse(w, response); err != nil {
+			logger.WithError(err).Error("Failed to write credential rotation response")
+		}
+	})
+
+	// Dead letters of failed deployments and their replay (admin only)
+	dlqHandler := func(w http.ResponseWriter, r *http.Request) {
+		if !isAdminRequest(r, adminToken) {
+			http.Error(w, "Unauthorized", http.StatusForbidden)
+			return
+		}
+
+		if dlqProcessor == nil {
+			http.Error(w, "Dead letter queue unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		dlqProcessor.ServeHTTP(w, r)
+	}
+	mux.HandleFunc("/dlq", dlqHandler)
+	mux.HandleFunc("/dlq/", dlqHandler)
+
+	// Current campaign metric anomalies of the project given by ?projectID= (admin only)
+	mux.HandleFunc("/analytics/anomalies", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if !isAdminRequest(r, adminToken) {
+			http.Error(w, "Unauthorized", http.StatusForbidden)
+			return
+		}
+
+		if anomalyDetector == nil {
+			http.Error(w, "Campaign anomalies unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		projectID, err := uuid.Parse(r.URL.Query().Get("projectID"))
+		if err != nil {
+			http.Error(w, "Invalid projectID", http.StatusBadRequest)
+			return
+		}
+
+		anomalies, err := anomalyDetector.Anomalies(r.Context(), projectID)
+		if err != nil {
+			logger.WithError(err).Error("Failed to list campaign anomalies")
+			http.Error(w, "Campaign anomalies unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		response := map[string]interface{}{
+			"project_id": projectID,
+			"anomalies":  anomalies,
+		}
+
+		if err := writeJSONResponse(w, response); err != nil {
+			logger.WithError(err).Error("Failed to write campaign anomalies response")
+		}
+	})
+
 	// Ready endpoint
 	mux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -192,11 +906,15 @@ func startHTTPServer(port int, deploymentService *service.DeploymentService, log
 		response := map[string]interface{}{
 			"service":     "ZAMC Ad Deployment Connectors",
 			"version":     "1.0.0",
-			"description": "Deploys approved assets to Google Ads and Meta Marketing platforms",
+			"description": "Deploys approved assets to Google Ads, Meta, TikTok, LinkedIn and Twitter advertising platforms",
 			"endpoints": map[string]string{
-				"health":  "/health",
-				"metrics": "/metrics",
-				"ready":   "/ready",
+				"health":           "/health",
+				"health_history":   "/health/history",
+				"health_incidents": "/health/incidents",
+				"metrics":          "/metrics",
+				"dlq":              "/dlq",
+				"anomalies":        "/analytics/anomalies",
+				"ready":            "/ready",
 			},
 		}
 		
@@ -223,8 +941,100 @@ func startHTTPServer(port int, deploymentService *service.DeploymentService, log
 	return server
 }
 
+// startMetricsServer serves the Prometheus metrics, including the deployment
+// statistics and the backlog of the event consumers, at /metrics
+func startMetricsServer(port int, deploymentService *service.DeploymentService, natsClient *nats.Client, logger *logrus.Logger) *http.Server {
+	prometheus.MustRegister(stats.NewExporter(deploymentService.DeploymentStats))
+	prometheus.MustRegister(nats.NewConsumerExporter(natsClient))
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+		ErrorLog: logger.WithField("component", "metrics"),
+	}))
+
+	server := &http.Server{
+		Addr:         fmt.Sprintf(":%d", port),
+		Handler:      mux,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+	}
+
+	go func() {
+		logger.WithField("port", port).Info("Starting Prometheus metrics server")
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.WithError(err).Error("Metrics server failed")
+		}
+	}()
+
+	return server
+}
+
 // Helper functions
 
+// isAdminRequest reports whether r carries the admin bearer token. Admin
+// endpoints are disabled when no token is configured.
+func isAdminRequest(r *http.Request, adminToken string) bool {
+	if adminToken == "" {
+		return false
+	}
+
+	authHeader := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return false
+	}
+
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
+
+// healthTimeRange returns the period given by the start and end query parameters,
+// in Unix seconds, of a health history request. It defaults to the retention period.
+func healthTimeRange(r *http.Request) (time.Time, time.Time, error) {
+	end := time.Now()
+	if value := r.URL.Query().Get("end"); value != "" {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end: %s", value)
+		}
+		end = time.Unix(seconds, 0)
+	}
+
+	start := end.Add(-health.Retention)
+	if value := r.URL.Query().Get("start"); value != "" {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start: %s", value)
+		}
+		start = time.Unix(seconds, 0)
+	}
+
+	if start.After(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("start is after end")
+	}
+
+	return start, end, nil
+}
+
+// consumerStream returns the stream named by the stream query parameter of an
+// admin consumers request, defaulting to the delayed message stream
+func consumerStream(r *http.Request) string {
+	if stream := r.URL.Query().Get("stream"); stream != "" {
+		return stream
+	}
+	return nats.DelayedStream
+}
+
+// writeConsumerError responds to an admin consumers request that failed
+func writeConsumerError(w http.ResponseWriter, err error, logger *logrus.Logger) {
+	if errors.Is(err, nats.ErrStreamNotFound) || errors.Is(err, nats.ErrConsumerNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	logger.WithError(err).Error("Failed to manage JetStream consumers")
+	http.Error(w, err.Error(), http.StatusServiceUnavailable)
+}
+
 func getOverallStatus(allHealthy bool) string {
 	if allHealthy {
 		return "healthy"
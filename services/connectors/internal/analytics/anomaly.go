@@ -0,0 +1,199 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+)
+
+// MetricCTR is the click-through rate of a campaign
+const MetricCTR = "ctr"
+
+// ErrUnknownMetric is returned for metrics that are not recorded in the campaign
+// metrics history
+var ErrUnknownMetric = errors.New("unknown campaign metric")
+
+// minSamples is the number of days of history below which no anomaly is reported
+const minSamples = 3
+
+// metricColumns are the columns of campaign_metrics_history of the metrics that
+// can be checked for anomalies
+var metricColumns = map[string]string{
+	MetricCTR:     "ctr",
+	"impressions": "impressions",
+	"clicks":      "clicks",
+	"cost_micros": "cost_micros",
+	"conversions": "conversions",
+	"revenue":     "revenue",
+}
+
+// MetricHistory is the daily values of a campaign metric
+type MetricHistory struct {
+	Platform  models.Platform
+	AssetID   uuid.UUID
+	ProjectID uuid.UUID
+	Values    []float64
+}
+
+// AnomalyStore reads the metrics history of campaigns and keeps their current
+// anomalies
+type AnomalyStore interface {
+	// MetricHistory returns the daily values of metric of the campaign since
+	// since, not including today. It returns nil for campaigns without history.
+	MetricHistory(ctx context.Context, campaignID, metric string, since time.Time) (*MetricHistory, error)
+
+	// SaveAnomaly saves the current anomaly of a campaign metric, reporting
+	// whether the metric was not anomalous before
+	SaveAnomaly(ctx context.Context, anomaly *models.CampaignAnomaly) (bool, error)
+
+	// ClearAnomaly removes the anomaly of a campaign metric that is back to normal
+	ClearAnomaly(ctx context.Context, campaignID, metric string) error
+
+	// ProjectAnomalies returns the current anomalies of the campaigns of a project
+	ProjectAnomalies(ctx context.Context, projectID uuid.UUID) ([]models.CampaignAnomaly, error)
+}
+
+// AlertPublisher publishes alerts of campaign metrics becoming anomalous
+type AlertPublisher interface {
+	PublishCampaignPerformanceAlert(ctx context.Context, event *models.CampaignPerformanceAlertEvent) error
+}
+
+// AnomalyDetector compares the latest value of a campaign metric with its rolling
+// average, alerting when it is too many standard deviations away
+type AnomalyDetector struct {
+	store     AnomalyStore
+	publisher AlertPublisher
+	threshold float64
+	window    int
+	logger    *logrus.Logger
+}
+
+// NewAnomalyDetector creates a detector of the campaign anomalies of store
+func NewAnomalyDetector(store AnomalyStore, publisher AlertPublisher, cfg *config.AnomalyConfig, logger *logrus.Logger) *AnomalyDetector {
+	return &AnomalyDetector{
+		store:     store,
+		publisher: publisher,
+		threshold: cfg.ZScoreThreshold,
+		window:    cfg.WindowDays,
+		logger:    logger,
+	}
+}
+
+// Detect reports whether currentValue of metric is anomalous for the campaign
+// campaignID, along with its z-score against the values of the metric over the
+// window. A metric becoming anomalous is saved and alerted; one back to normal
+// is cleared. Campaigns with fewer than 3 days of history, or whose metric never
+// varied, have no anomalies.
+func (d *AnomalyDetector) Detect(ctx context.Context, campaignID string, metric string, currentValue float64) (bool, float64, error) {
+	if _, ok := metricColumns[metric]; !ok {
+		return false, 0, fmt.Errorf("%w: %s", ErrUnknownMetric, metric)
+	}
+
+	now := time.Now()
+	history, err := d.store.MetricHistory(ctx, campaignID, metric, now.AddDate(0, 0, -d.window))
+	if err != nil {
+		return false, 0, err
+	}
+	if history == nil {
+		return false, 0, nil
+	}
+
+	mean, stdDev, ok := meanStdDev(history.Values)
+	if !ok {
+		return false, 0, nil
+	}
+	z := (currentValue - mean) / stdDev
+
+	if math.Abs(z) <= d.threshold {
+		if err := d.store.ClearAnomaly(ctx, campaignID, metric); err != nil {
+			return false, z, err
+		}
+		return false, z, nil
+	}
+
+	anomaly := &models.CampaignAnomaly{
+		CampaignID: campaignID,
+		Platform:   history.Platform,
+		AssetID:    history.AssetID,
+		ProjectID:  history.ProjectID,
+		Metric:     metric,
+		Value:      currentValue,
+		Mean:       mean,
+		StdDev:     stdDev,
+		ZScore:     z,
+		Severity:   d.severity(z),
+		DetectedAt: now,
+	}
+	created, err := d.store.SaveAnomaly(ctx, anomaly)
+	if err != nil {
+		return true, z, err
+	}
+
+	// Alert once, when the metric becomes anomalous, rather than on every poll
+	if created {
+		err := d.publisher.PublishCampaignPerformanceAlert(ctx, &models.CampaignPerformanceAlertEvent{
+			EventType: "campaign.performance_alert",
+			Anomaly:   *anomaly,
+			Timestamp: now,
+		})
+		if err != nil {
+			return true, z, err
+		}
+
+		d.logger.WithFields(logrus.Fields{
+			"campaign_id": campaignID,
+			"metric":      metric,
+			"z_score":     z,
+			"severity":    anomaly.Severity,
+		}).Info("Campaign metric anomaly detected")
+	}
+
+	return true, z, nil
+}
+
+// Anomalies returns the current anomalies of the campaigns of the project projectID
+func (d *AnomalyDetector) Anomalies(ctx context.Context, projectID uuid.UUID) ([]models.CampaignAnomaly, error) {
+	return d.store.ProjectAnomalies(ctx, projectID)
+}
+
+// severity is critical for z-scores more than one standard deviation beyond the
+// threshold
+func (d *AnomalyDetector) severity(z float64) models.AnomalySeverity {
+	if math.Abs(z) > d.threshold+1 {
+		return models.AnomalySeverityCritical
+	}
+	return models.AnomalySeverityWarning
+}
+
+// meanStdDev returns the mean and sample standard deviation of values. It
+// returns false when there are too few values or they are all the same.
+func meanStdDev(values []float64) (float64, float64, bool) {
+	if len(values) < minSamples {
+		return 0, 0, false
+	}
+
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+
+	var squares float64
+	for _, value := range values {
+		squares += (value - mean) * (value - mean)
+	}
+	stdDev := math.Sqrt(squares / float64(len(values)-1))
+	if stdDev == 0 {
+		return mean, 0, false
+	}
+
+	return mean, stdDev, true
+}
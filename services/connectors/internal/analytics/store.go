@@ -0,0 +1,146 @@
+package analytics
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+// Store reads the daily snapshots of campaign metrics the BFF records in the
+// campaign_metrics_history table, and keeps anomalies in campaign_anomalies
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates an anomaly store backed by db
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Open connects to the database and creates an anomaly store
+func Open(databaseURL string) (*Store, error) {
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open campaign anomalies database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping campaign anomalies database: %w", err)
+	}
+
+	return NewStore(db), nil
+}
+
+// MetricHistory returns the daily values of metric of the campaign campaignID
+// from since until yesterday, oldest first
+func (s *Store) MetricHistory(ctx context.Context, campaignID, metric string, since time.Time) (*MetricHistory, error) {
+	column, ok := metricColumns[metric]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMetric, metric)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT platform, asset_id, project_id, `+column+`::double precision
+		FROM campaign_metrics_history
+		WHERE campaign_id = $1 AND fetched_on >= $2::date AND fetched_on < (NOW() AT TIME ZONE 'UTC')::date
+		ORDER BY fetched_on
+	`, campaignID, since.UTC().Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query campaign metrics history: %w", err)
+	}
+	defer rows.Close()
+
+	var history *MetricHistory
+	for rows.Next() {
+		if history == nil {
+			history = &MetricHistory{}
+		}
+		var value float64
+		if err := rows.Scan(&history.Platform, &history.AssetID, &history.ProjectID, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan campaign metrics history: %w", err)
+		}
+		history.Values = append(history.Values, value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query campaign metrics history: %w", err)
+	}
+
+	return history, nil
+}
+
+// SaveAnomaly saves the current anomaly of a campaign metric, keeping the time it
+// was first detected. It reports whether the metric was not anomalous before.
+func (s *Store) SaveAnomaly(ctx context.Context, anomaly *models.CampaignAnomaly) (bool, error) {
+	var created bool
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO campaign_anomalies (campaign_id, metric, platform, asset_id, project_id, value, mean, std_dev, z_score, severity, detected_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (campaign_id, metric) DO UPDATE
+		SET value = EXCLUDED.value, mean = EXCLUDED.mean, std_dev = EXCLUDED.std_dev,
+			z_score = EXCLUDED.z_score, severity = EXCLUDED.severity
+		RETURNING xmax = 0
+	`, anomaly.CampaignID, anomaly.Metric, anomaly.Platform, anomaly.AssetID, anomaly.ProjectID,
+		anomaly.Value, anomaly.Mean, anomaly.StdDev, anomaly.ZScore, anomaly.Severity, anomaly.DetectedAt).Scan(&created)
+	if err != nil {
+		return false, fmt.Errorf("failed to save campaign anomaly: %w", err)
+	}
+
+	return created, nil
+}
+
+// ClearAnomaly removes the anomaly of metric of the campaign campaignID, if any
+func (s *Store) ClearAnomaly(ctx context.Context, campaignID, metric string) error {
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM campaign_anomalies WHERE campaign_id = $1 AND metric = $2
+	`, campaignID, metric)
+	if err != nil {
+		return fmt.Errorf("failed to clear campaign anomaly: %w", err)
+	}
+
+	return nil
+}
+
+// ProjectAnomalies returns the current anomalies of the campaigns of the project
+// projectID, furthest from their average first
+func (s *Store) ProjectAnomalies(ctx context.Context, projectID uuid.UUID) ([]models.CampaignAnomaly, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT campaign_id, metric, platform, asset_id, project_id, value, mean, std_dev, z_score, severity, detected_at
+		FROM campaign_anomalies
+		WHERE project_id = $1
+		ORDER BY ABS(z_score) DESC
+	`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list campaign anomalies: %w", err)
+	}
+	defer rows.Close()
+
+	anomalies := []models.CampaignAnomaly{}
+	for rows.Next() {
+		var anomaly models.CampaignAnomaly
+		err := rows.Scan(
+			&anomaly.CampaignID, &anomaly.Metric, &anomaly.Platform, &anomaly.AssetID, &anomaly.ProjectID,
+			&anomaly.Value, &anomaly.Mean, &anomaly.StdDev, &anomaly.ZScore, &anomaly.Severity, &anomaly.DetectedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan campaign anomaly: %w", err)
+		}
+		anomalies = append(anomalies, anomaly)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list campaign anomalies: %w", err)
+	}
+
+	return anomalies, nil
+}
+
+// Close closes the database connection
+func (s *Store) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,62 @@
+// Package backoff computes the delays between retries of a failed operation
+package backoff
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff gives the delay before retrying an operation
+type Backoff interface {
+	// Next returns the delay after the given attempt, counted from 1, has failed
+	Next(attempt int) time.Duration
+}
+
+// Exponential is a truncated binary exponential backoff with jitter. The delay
+// after attempt n is Base * 2^(n-1) plus a random jitter below Jitter, capped at
+// Max. A zero Max does not cap the delay.
+type Exponential struct {
+	Base   time.Duration
+	Max    time.Duration
+	Jitter time.Duration
+
+	// random returns a number in [0, n), for tests
+	random func(n int64) int64
+}
+
+// NewExponential creates an exponential backoff
+func NewExponential(base, max, jitter time.Duration) *Exponential {
+	return &Exponential{
+		Base:   base,
+		Max:    max,
+		Jitter: jitter,
+		random: rand.Int63n,
+	}
+}
+
+// Next returns the delay after attempt
+func (e *Exponential) Next(attempt int) time.Duration {
+	// Double the base delay, stopping once it reaches the cap or would overflow
+	delay := e.Base
+	for i := 1; i < attempt && delay > 0 && delay <= math.MaxInt64/2; i++ {
+		if e.Max > 0 && delay >= e.Max {
+			break
+		}
+		delay *= 2
+	}
+
+	if e.Jitter > 0 && delay <= math.MaxInt64-e.Jitter {
+		delay += time.Duration(e.random(int64(e.Jitter)))
+	}
+
+	if e.Max > 0 && delay > e.Max {
+		delay = e.Max
+	}
+	return delay
+}
+
+// SetRandom replaces the source of the jitter, for tests
+func (e *Exponential) SetRandom(random func(n int64) int64) {
+	e.random = random
+}
@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultSweepInterval is how often Run removes the expired entries
+const DefaultSweepInterval = time.Minute
+
+// entry is a cached response, served until expiresAt
+type entry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// Stats are the lookups of a cache since it was created
+type Stats struct {
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+// PlatformResponseCache keeps the responses of platform APIs in memory, each
+// for its own TTL. Expired entries are dropped when looked up, invalidated or
+// swept by Run. It is safe for concurrent use; a nil cache caches nothing.
+type PlatformResponseCache struct {
+	entries sync.Map
+	hits    atomic.Int64
+	misses  atomic.Int64
+	now     func() time.Time
+}
+
+// NewPlatformResponseCache creates an empty response cache
+func NewPlatformResponseCache() *PlatformResponseCache {
+	return &PlatformResponseCache{now: time.Now}
+}
+
+// Get returns the value cached under key, if it has not expired
+func (c *PlatformResponseCache) Get(key string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	value, ok := c.entries.Load(key)
+	if ok && c.now().Before(value.(*entry).expiresAt) {
+		c.hits.Add(1)
+		return value.(*entry).value, true
+	}
+	if ok {
+		c.entries.CompareAndDelete(key, value)
+	}
+
+	c.misses.Add(1)
+	return "", false
+}
+
+// Set caches value under key for ttl. Values with no ttl are not cached.
+func (c *PlatformResponseCache) Set(key, value string, ttl time.Duration) {
+	if c == nil || ttl <= 0 {
+		return
+	}
+	c.entries.Store(key, &entry{value: value, expiresAt: c.now().Add(ttl)})
+}
+
+// Invalidate removes the entries whose key matches keyPattern, in which *
+// matches any sequence of characters, along with every expired entry
+func (c *PlatformResponseCache) Invalidate(keyPattern string) {
+	if c == nil {
+		return
+	}
+
+	pattern := regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(keyPattern), `\*`, ".*") + "$")
+	now := c.now()
+	c.entries.Range(func(key, value interface{}) bool {
+		if pattern.MatchString(key.(string)) || !now.Before(value.(*entry).expiresAt) {
+			c.entries.Delete(key)
+		}
+		return true
+	})
+}
+
+// Sweep removes every expired entry, so that responses that are not looked up
+// again do not stay in memory
+func (c *PlatformResponseCache) Sweep() {
+	if c == nil {
+		return
+	}
+
+	now := c.now()
+	c.entries.Range(func(key, value interface{}) bool {
+		if !now.Before(value.(*entry).expiresAt) {
+			c.entries.CompareAndDelete(key, value)
+		}
+		return true
+	})
+}
+
+// Run sweeps the cache every interval until ctx is cancelled
+func (c *PlatformResponseCache) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Sweep()
+		}
+	}
+}
+
+// Len returns the number of entries in the cache, including expired entries
+// not removed yet
+func (c *PlatformResponseCache) Len() int {
+	if c == nil {
+		return 0
+	}
+
+	n := 0
+	c.entries.Range(func(key, value interface{}) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// Stats returns the hits and misses of the cache. The hit ratio is 0 before
+// the first lookup.
+func (c *PlatformResponseCache) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+
+	stats := Stats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
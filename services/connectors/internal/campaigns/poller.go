@@ -0,0 +1,166 @@
+package campaigns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+// DeploymentStore lists the campaigns whose metrics are polled
+type DeploymentStore interface {
+	// ClaimDueDeployments returns the active deployments not polled since
+	// before, and records them as polled at now, so that instances polling
+	// together do not poll the same deployments
+	ClaimDueDeployments(ctx context.Context, now, before time.Time) ([]models.CampaignDeployment, error)
+	Deactivate(ctx context.Context, platform models.Platform, platformCampaignID string) error
+}
+
+// MetricsFetcher fetches the performance metrics of a deployed campaign. It
+// returns models.ErrCampaignNotFound for campaigns removed from the platform.
+type MetricsFetcher interface {
+	FetchCampaignMetrics(ctx context.Context, deployment *models.CampaignDeployment) (*models.CampaignMetrics, error)
+}
+
+// MetricsPublisher publishes the metrics fetched by the poller
+type MetricsPublisher interface {
+	PublishCampaignMetricsUpdated(ctx context.Context, event *models.CampaignMetricsUpdatedEvent) error
+}
+
+// AnomalyDetector checks the metrics fetched by the poller against their history
+type AnomalyDetector interface {
+	Detect(ctx context.Context, campaignID string, metric string, currentValue float64) (bool, float64, error)
+}
+
+// MetricsPoller periodically fetches the metrics of every active campaign
+// deployment and publishes them
+type MetricsPoller struct {
+	store     DeploymentStore
+	fetcher   MetricsFetcher
+	publisher MetricsPublisher
+	detector  AnomalyDetector
+	interval  time.Duration
+	logger    *logrus.Logger
+}
+
+// NewMetricsPoller creates a poller fetching the metrics of the deployments of
+// store every interval
+func NewMetricsPoller(store DeploymentStore, fetcher MetricsFetcher, publisher MetricsPublisher, interval time.Duration, logger *logrus.Logger) *MetricsPoller {
+	return &MetricsPoller{
+		store:     store,
+		fetcher:   fetcher,
+		publisher: publisher,
+		interval:  interval,
+		logger:    logger,
+	}
+}
+
+// SetAnomalyDetector checks the click-through rate of every campaign polled for
+// anomalies with detector
+func (p *MetricsPoller) SetAnomalyDetector(detector AnomalyDetector) {
+	p.detector = detector
+}
+
+// Run polls the campaign metrics right away and then every interval until ctx is
+// cancelled
+func (p *MetricsPoller) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.logger.WithField("interval", p.interval).Info("Campaign metrics poller started")
+
+	for {
+		if err := p.Poll(ctx); err != nil {
+			p.logger.WithError(err).Error("Failed to poll campaign metrics")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches and publishes the metrics of every active campaign deployment
+// that no instance polled during the last half interval. Deployments whose
+// campaign was removed are deactivated; a failure to fetch or publish the
+// metrics of one campaign does not stop the others.
+func (p *MetricsPoller) Poll(ctx context.Context) error {
+	now := time.Now()
+	deployments, err := p.store.ClaimDueDeployments(ctx, now, now.Add(-p.interval/2))
+	if err != nil {
+		return err
+	}
+
+	published, failed := 0, 0
+	fetched := make(map[string]float64, len(deployments))
+	for i := range deployments {
+		deployment := &deployments[i]
+		logger := p.logger.WithFields(logrus.Fields{
+			"asset_id":    deployment.AssetID,
+			"platform":    deployment.Platform,
+			"campaign_id": deployment.PlatformCampaignID,
+		})
+
+		metrics, err := p.fetcher.FetchCampaignMetrics(ctx, deployment)
+		if errors.Is(err, models.ErrCampaignNotFound) {
+			if err := p.store.Deactivate(ctx, deployment.Platform, deployment.PlatformCampaignID); err != nil {
+				logger.WithError(err).Error("Failed to deactivate removed campaign")
+			} else {
+				logger.Info("Stopped polling metrics of removed campaign")
+			}
+			continue
+		} else if err != nil {
+			logger.WithError(err).Warn("Failed to fetch campaign metrics")
+			continue
+		}
+
+		err = p.publisher.PublishCampaignMetricsUpdated(ctx, &models.CampaignMetricsUpdatedEvent{
+			EventType:  "campaign.metrics_updated",
+			ProjectID:  deployment.ProjectID,
+			AssetID:    deployment.AssetID,
+			Platform:   deployment.Platform,
+			CampaignID: deployment.PlatformCampaignID,
+			Metrics:    *metrics,
+			Timestamp:  time.Now(),
+		})
+		if err != nil {
+			logger.WithError(err).Error("Failed to publish campaign metrics")
+			failed++
+			continue
+		}
+		published++
+		fetched[deployment.PlatformCampaignID] = metrics.CTR
+	}
+
+	p.detectAnomalies(ctx, fetched)
+
+	p.logger.WithFields(logrus.Fields{
+		"deployments": len(deployments),
+		"published":   published,
+	}).Debug("Polled campaign metrics")
+
+	if failed > 0 {
+		return fmt.Errorf("failed to publish metrics of %d campaigns", failed)
+	}
+	return nil
+}
+
+// detectAnomalies checks the click-through rates of the campaigns of a poll for
+// anomalies, if an anomaly detector is set
+func (p *MetricsPoller) detectAnomalies(ctx context.Context, ctrs map[string]float64) {
+	if p.detector == nil {
+		return
+	}
+
+	for campaignID, ctr := range ctrs {
+		if _, _, err := p.detector.Detect(ctx, campaignID, "ctr", ctr); err != nil {
+			p.logger.WithError(err).WithField("campaign_id", campaignID).Warn("Failed to detect campaign metric anomalies")
+		}
+	}
+}
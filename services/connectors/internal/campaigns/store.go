@@ -0,0 +1,196 @@
+package campaigns
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+// ErrNotFound is returned when an asset has no active deployment on a platform
+var ErrNotFound = errors.New("campaign deployment not found")
+
+// Store persists the campaigns assets were deployed to in the campaign_deployments table
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates a campaign deployment store backed by db
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Open connects to the database and creates a campaign deployment store
+func Open(databaseURL string) (*Store, error) {
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open campaign deployments database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping campaign deployments database: %w", err)
+	}
+
+	return NewStore(db), nil
+}
+
+// RecordDeployment saves a campaign an asset was deployed to and marks it active.
+// A campaign deployed to again keeps its original deployment time.
+func (s *Store) RecordDeployment(ctx context.Context, deployment *models.CampaignDeployment) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO campaign_deployments (platform, platform_campaign_id, ad_group_id, platform_ad_id, audience_id, asset_id, project_id, tenant_id, deployed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, '')::uuid, $9)
+		ON CONFLICT (platform, platform_campaign_id) DO UPDATE
+		SET ad_group_id = EXCLUDED.ad_group_id, platform_ad_id = EXCLUDED.platform_ad_id, audience_id = EXCLUDED.audience_id,
+			asset_id = EXCLUDED.asset_id, project_id = EXCLUDED.project_id, tenant_id = EXCLUDED.tenant_id, active = TRUE
+	`, deployment.Platform, deployment.PlatformCampaignID, deployment.AdGroupID, deployment.PlatformAdID, deployment.AudienceID,
+		deployment.AssetID, deployment.ProjectID, deployment.TenantID, deployment.DeployedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record campaign deployment: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimDueDeployments returns the active campaign deployments not polled since
+// before, oldest first, and records them as polled at now. Deployments locked
+// by another instance claiming them are skipped, so each is polled by one
+// instance at a time.
+func (s *Store) ClaimDueDeployments(ctx context.Context, now, before time.Time) ([]models.CampaignDeployment, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH due AS (
+			SELECT platform, platform_campaign_id
+			FROM campaign_deployments
+			WHERE active AND (polled_at IS NULL OR polled_at < $2)
+			ORDER BY deployed_at
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE campaign_deployments d SET polled_at = $1
+		FROM due
+		WHERE d.platform = due.platform AND d.platform_campaign_id = due.platform_campaign_id
+		RETURNING `+qualifiedDeploymentColumns+`
+	`, now, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim campaign deployments: %w", err)
+	}
+	defer rows.Close()
+
+	var deployments []models.CampaignDeployment
+	for rows.Next() {
+		deployment, err := scanDeployment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan campaign deployment: %w", err)
+		}
+		deployments = append(deployments, *deployment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read claimed campaign deployments: %w", err)
+	}
+
+	sort.Slice(deployments, func(i, j int) bool {
+		return deployments[i].DeployedAt.Before(deployments[j].DeployedAt)
+	})
+	return deployments, nil
+}
+
+// AssetDeployment returns the latest active deployment of an asset on a platform
+func (s *Store) AssetDeployment(ctx context.Context, assetID uuid.UUID, platform models.Platform) (*models.CampaignDeployment, error) {
+	deployment, err := scanDeployment(s.db.QueryRowContext(ctx, `
+		SELECT `+deploymentColumns+`
+		FROM campaign_deployments
+		WHERE asset_id = $1 AND platform = $2 AND active
+		ORDER BY deployed_at DESC
+		LIMIT 1
+	`, assetID, platform))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get campaign deployment: %w", err)
+	}
+
+	return deployment, nil
+}
+
+// Deactivate stops polling the metrics of a platform campaign
+func (s *Store) Deactivate(ctx context.Context, platform models.Platform, platformCampaignID string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE campaign_deployments SET active = FALSE WHERE platform = $1 AND platform_campaign_id = $2
+	`, platform, platformCampaignID)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate campaign deployment: %w", err)
+	}
+
+	return nil
+}
+
+// AudienceDeployments returns the deployments of an asset that target a custom
+// audience, active or not
+func (s *Store) AudienceDeployments(ctx context.Context, assetID uuid.UUID) ([]models.CampaignDeployment, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+deploymentColumns+`
+		FROM campaign_deployments
+		WHERE asset_id = $1 AND audience_id <> ''
+		ORDER BY deployed_at
+	`, assetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom audience deployments: %w", err)
+	}
+	defer rows.Close()
+
+	var deployments []models.CampaignDeployment
+	for rows.Next() {
+		deployment, err := scanDeployment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan campaign deployment: %w", err)
+		}
+		deployments = append(deployments, *deployment)
+	}
+
+	return deployments, rows.Err()
+}
+
+// ClearAudience forgets the custom audience of a platform campaign once it is deleted
+func (s *Store) ClearAudience(ctx context.Context, platform models.Platform, platformCampaignID string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE campaign_deployments SET audience_id = '' WHERE platform = $1 AND platform_campaign_id = $2
+	`, platform, platformCampaignID)
+	if err != nil {
+		return fmt.Errorf("failed to clear custom audience of campaign deployment: %w", err)
+	}
+
+	return nil
+}
+
+// deploymentColumns are the columns read by scanDeployment
+const deploymentColumns = `platform, platform_campaign_id, ad_group_id, platform_ad_id, audience_id, asset_id, project_id, COALESCE(tenant_id::text, ''), deployed_at`
+
+// qualifiedDeploymentColumns are deploymentColumns of campaign_deployments d
+const qualifiedDeploymentColumns = `d.platform, d.platform_campaign_id, d.ad_group_id, d.platform_ad_id, d.audience_id, d.asset_id, d.project_id, COALESCE(d.tenant_id::text, ''), d.deployed_at`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDeployment(row rowScanner) (*models.CampaignDeployment, error) {
+	var deployment models.CampaignDeployment
+	err := row.Scan(&deployment.Platform, &deployment.PlatformCampaignID, &deployment.AdGroupID, &deployment.PlatformAdID, &deployment.AudienceID,
+		&deployment.AssetID, &deployment.ProjectID, &deployment.TenantID, &deployment.DeployedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &deployment, nil
+}
+
+// Close closes the underlying database connection
+func (s *Store) Close() error {
+	return s.db.Close()
+}
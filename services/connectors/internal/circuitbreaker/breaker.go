@@ -0,0 +1,200 @@
+// Package circuitbreaker stops calls to a failing platform API for a while, so
+// that an outage fails deployments at once instead of retrying each of them
+package circuitbreaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned for calls refused while the circuit is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// State is the state of a circuit
+type State int
+
+const (
+	// StateClosed lets every call through
+	StateClosed State = iota
+	// StateOpen refuses every call until the recovery timeout has passed
+	StateOpen
+	// StateHalfOpen lets a single trial call through, which closes the circuit
+	// when it succeeds and opens it again when it fails
+	StateHalfOpen
+)
+
+// String returns closed, open or half-open
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// States lists every state of a circuit
+var States = []State{StateClosed, StateOpen, StateHalfOpen}
+
+// Config configures a Breaker
+type Config struct {
+	// FailureThreshold consecutive failures within WindowDuration open the
+	// circuit. Zero never opens it.
+	FailureThreshold int
+	WindowDuration   time.Duration
+
+	// RecoveryTimeout is how long the circuit stays open before a trial call is
+	// let through
+	RecoveryTimeout time.Duration
+
+	// IsFailure tells whether an error counts as a failure of the API. All errors
+	// do when it is nil. A trial call failing with any other error closes the
+	// circuit, as the API answered.
+	IsFailure func(err error) bool
+
+	// OnStateChange is called, with the breaker locked, when the circuit changes
+	// state
+	OnStateChange func(from, to State)
+}
+
+// Breaker is a circuit breaker. It is safe for concurrent use.
+type Breaker struct {
+	mu           sync.Mutex
+	cfg          Config
+	state        State
+	failures     int
+	firstFailure time.Time
+	openedAt     time.Time
+	trialRunning bool
+	now          func() time.Time
+}
+
+// New creates a closed breaker
+func New(cfg Config) *Breaker {
+	return &Breaker{
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+// SetClock replaces the clock of the breaker, for tests
+func (b *Breaker) SetClock(now func() time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.now = now
+}
+
+// State returns the current state of the circuit. An open circuit whose recovery
+// timeout has passed is reported as half-open.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.recover()
+	return b.state
+}
+
+// Execute calls fn unless the circuit is open, in which case it returns
+// ErrCircuitOpen, and records its outcome
+func (b *Breaker) Execute(fn func() error) error {
+	trial, err := b.allow()
+	if err != nil {
+		return err
+	}
+
+	err = fn()
+	b.record(trial, err)
+	return err
+}
+
+// allow reserves the trial call of a half-open circuit, or fails while the
+// circuit is open or the trial call is running. It returns true for the trial
+// call.
+func (b *Breaker) allow() (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.recover()
+	switch b.state {
+	case StateOpen:
+		return false, ErrCircuitOpen
+	case StateHalfOpen:
+		if b.trialRunning {
+			return false, ErrCircuitOpen
+		}
+		b.trialRunning = true
+		return true, nil
+	}
+	return false, nil
+}
+
+// record counts the outcome of a call let through by allow. Calls that started
+// before the circuit opened do not count once it has.
+func (b *Breaker) record(trial bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	failed := err != nil && (b.cfg.IsFailure == nil || b.cfg.IsFailure(err))
+
+	if trial {
+		b.trialRunning = false
+		if failed {
+			b.open()
+		} else {
+			b.failures = 0
+			b.setState(StateClosed)
+		}
+		return
+	}
+
+	if b.state != StateClosed {
+		return
+	}
+
+	// Errors that are not failures neither count nor end a run of failures
+	if !failed {
+		if err == nil {
+			b.failures = 0
+		}
+		return
+	}
+
+	// Failures older than the window no longer count
+	now := b.now()
+	if b.failures == 0 || now.Sub(b.firstFailure) > b.cfg.WindowDuration {
+		b.failures = 0
+		b.firstFailure = now
+	}
+	b.failures++
+
+	if b.cfg.FailureThreshold > 0 && b.failures >= b.cfg.FailureThreshold {
+		b.open()
+	}
+}
+
+// recover moves an open circuit whose recovery timeout has passed to half-open
+func (b *Breaker) recover() {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.cfg.RecoveryTimeout {
+		b.setState(StateHalfOpen)
+	}
+}
+
+func (b *Breaker) open() {
+	b.failures = 0
+	b.openedAt = b.now()
+	b.setState(StateOpen)
+}
+
+func (b *Breaker) setState(state State) {
+	if b.state == state {
+		return
+	}
+
+	from := b.state
+	b.state = state
+	if b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(from, state)
+	}
+}
@@ -1,9 +1,13 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
+
+	"github.com/zamc/connectors/internal/models"
 )
 
 // Config holds all configuration for the connectors service
@@ -16,20 +20,47 @@ type Config struct {
 	// NATS Configuration
 	NATS NATSConfig
 
+	// Redis Configuration
+	Redis RedisConfig
+
 	// Google Ads Configuration
 	GoogleAds GoogleAdsConfig
 
 	// Meta Marketing API Configuration
 	Meta MetaConfig
 
+	// TikTok Marketing API Configuration
+	TikTok TikTokConfig
+
+	// LinkedIn Marketing API Configuration
+	LinkedIn LinkedInConfig
+
+	// Twitter Ads API Configuration
+	Twitter TwitterConfig
+
 	// Deployment Configuration
 	Deployment DeploymentConfig
 
+	// Per-tenant Credentials Configuration
+	Credentials CredentialsConfig
+
+	// Slack Configuration
+	Slack SlackConfig
+
+	// Deployment Notification Configuration
+	Notification NotificationConfig
+
+	// Campaign Anomaly Detection Configuration
+	Anomaly AnomalyConfig
+
 	// Health Check Configuration
 	HealthCheck HealthCheckConfig
 
 	// Monitoring Configuration
 	Monitoring MonitoringConfig
+
+	// Tracing Configuration
+	Tracing TracingConfig
 }
 
 // NATSConfig holds NATS-specific configuration
@@ -37,6 +68,20 @@ type NATSConfig struct {
 	URL           string `envconfig:"NATS_URL" default:"nats://localhost:4222"`
 	SubjectPrefix string `envconfig:"NATS_SUBJECT_PREFIX" default:"zamc"`
 	QueueGroup    string `envconfig:"NATS_QUEUE_GROUP" default:"connectors"`
+
+	// EnableCompression compresses the events the service publishes with gzip
+	// at CompressionLevel, from 1 (fastest) to 9 (smallest), or -1 for the default
+	EnableCompression bool `envconfig:"NATS_ENABLE_COMPRESSION" default:"false"`
+	CompressionLevel  int  `envconfig:"NATS_COMPRESSION_LEVEL" default:"-1"`
+
+	// MaxConcurrentDeployments bounds the approved assets and scheduled
+	// deployments an instance handles at once; 0 uses the default of 32
+	MaxConcurrentDeployments int `envconfig:"NATS_MAX_CONCURRENT_DEPLOYMENTS" default:"32"`
+}
+
+// RedisConfig holds Redis configuration used for deployment statistics
+type RedisConfig struct {
+	URL string `envconfig:"REDIS_URL" default:"redis://localhost:6379/0"`
 }
 
 // GoogleAdsConfig holds Google Ads API configuration
@@ -47,6 +92,27 @@ type GoogleAdsConfig struct {
 	RefreshToken      string `envconfig:"GOOGLE_ADS_REFRESH_TOKEN" required:"true"`
 	CustomerID        string `envconfig:"GOOGLE_ADS_CUSTOMER_ID" required:"true"`
 	LoginCustomerID   string `envconfig:"GOOGLE_ADS_LOGIN_CUSTOMER_ID"`
+	BaseURL           string `envconfig:"GOOGLE_ADS_API_BASE_URL" default:"https://googleads.googleapis.com/v16"`
+	Currency          string `envconfig:"GOOGLE_ADS_CURRENCY" default:"USD"`
+
+	// BiddingStrategyMap is the bidding strategy of the campaigns of each content
+	// type; content types without one use the campaign default
+	BiddingStrategyMap BiddingStrategies `envconfig:"GOOGLE_ADS_BIDDING_STRATEGIES"`
+}
+
+// BiddingStrategies maps content types to the Google Ads bidding strategy of
+// their campaigns. It is configured as a JSON object, such as
+// {"blog_post": "MAXIMIZE_CLICKS", "social_media": "TARGET_CPA"}.
+type BiddingStrategies map[models.ContentType]string
+
+// Decode parses the JSON object of an environment variable
+func (b *BiddingStrategies) Decode(value string) error {
+	strategies := map[models.ContentType]string{}
+	if err := json.Unmarshal([]byte(value), &strategies); err != nil {
+		return fmt.Errorf("invalid bidding strategies: %w", err)
+	}
+	*b = strategies
+	return nil
 }
 
 // MetaConfig holds Meta Marketing API configuration
@@ -56,6 +122,65 @@ type MetaConfig struct {
 	AccessToken string `envconfig:"META_ACCESS_TOKEN" required:"true"`
 	AdAccountID string `envconfig:"META_AD_ACCOUNT_ID" required:"true"`
 	APIVersion  string `envconfig:"META_API_VERSION" default:"v18.0"`
+	BaseURL     string `envconfig:"META_API_BASE_URL" default:"https://graph.facebook.com"`
+	Currency    string `envconfig:"META_CURRENCY" default:"USD"`
+
+	// CacheGETResponsesTTL is how long the responses of GET calls are served
+	// from cache, 0 to always call the API
+	CacheGETResponsesTTL time.Duration `envconfig:"META_CACHE_GET_RESPONSES_TTL" default:"1m"`
+}
+
+// TikTokConfig holds TikTok Marketing API configuration. Ads are created in the
+// ad group AdGroupID of the advertiser AdvertiserID.
+type TikTokConfig struct {
+	AccessToken  string `envconfig:"TIKTOK_ACCESS_TOKEN"`
+	AdvertiserID string `envconfig:"TIKTOK_ADVERTISER_ID"`
+	AdGroupID    string `envconfig:"TIKTOK_AD_GROUP_ID"`
+	BaseURL      string `envconfig:"TIKTOK_API_BASE_URL" default:"https://business-api.tiktok.com/open_api/v1.3"`
+}
+
+// Enabled returns true if TikTok deployments are configured
+func (c *TikTokConfig) Enabled() bool {
+	return c.AccessToken != ""
+}
+
+// LinkedInConfig holds LinkedIn Marketing API configuration. Each deployment
+// creates a campaign in the campaign group CampaignGroupID of the ad account
+// AdAccountID, sponsoring a post by the organization OrganizationID.
+type LinkedInConfig struct {
+	AccessToken     string `envconfig:"LINKEDIN_ACCESS_TOKEN"`
+	AdAccountID     string `envconfig:"LINKEDIN_AD_ACCOUNT_ID"`
+	CampaignGroupID string `envconfig:"LINKEDIN_CAMPAIGN_GROUP_ID"`
+	OrganizationID  string `envconfig:"LINKEDIN_ORGANIZATION_ID"`
+	// DefaultLocations are targeted when an asset names no locations
+	DefaultLocations []string `envconfig:"LINKEDIN_DEFAULT_LOCATIONS" default:"US"`
+	Currency         string   `envconfig:"LINKEDIN_CURRENCY" default:"USD"`
+	BaseURL          string   `envconfig:"LINKEDIN_API_BASE_URL" default:"https://api.linkedin.com/rest"`
+}
+
+// Enabled returns true if LinkedIn deployments are configured
+func (c *LinkedInConfig) Enabled() bool {
+	return c.AccessToken != ""
+}
+
+// TwitterConfig holds Twitter Ads API configuration. Requests are signed with
+// OAuth 1.0a for the app ConsumerKey and the user of AccessToken, who must have
+// access to the ads account AccountID. Campaigns are funded by
+// FundingInstrumentID.
+type TwitterConfig struct {
+	ConsumerKey         string `envconfig:"TWITTER_CONSUMER_KEY"`
+	ConsumerSecret      string `envconfig:"TWITTER_CONSUMER_SECRET"`
+	AccessToken         string `envconfig:"TWITTER_ACCESS_TOKEN"`
+	AccessTokenSecret   string `envconfig:"TWITTER_ACCESS_TOKEN_SECRET"`
+	AccountID           string `envconfig:"TWITTER_ADS_ACCOUNT_ID"`
+	FundingInstrumentID string `envconfig:"TWITTER_FUNDING_INSTRUMENT_ID"`
+	BaseURL             string `envconfig:"TWITTER_ADS_API_BASE_URL" default:"https://ads-api.twitter.com/12"`
+	APIBaseURL          string `envconfig:"TWITTER_API_BASE_URL" default:"https://api.twitter.com"`
+}
+
+// Enabled returns true if Twitter deployments are configured
+func (c *TwitterConfig) Enabled() bool {
+	return c.AccessToken != ""
 }
 
 // DeploymentConfig holds deployment-specific configuration
@@ -63,6 +188,138 @@ type DeploymentConfig struct {
 	MaxRetryAttempts int           `envconfig:"MAX_RETRY_ATTEMPTS" default:"3"`
 	RetryDelay       time.Duration `envconfig:"RETRY_DELAY_SECONDS" default:"5s"`
 	Timeout          time.Duration `envconfig:"DEPLOYMENT_TIMEOUT_SECONDS" default:"300s"`
+
+	// WorkerCount deployments run at once to each platform, each platform
+	// queueing up to QueueSize more, highest campaign priority first
+	WorkerCount int `envconfig:"DEPLOYMENT_WORKER_COUNT" default:"4"`
+	QueueSize   int `envconfig:"DEPLOYMENT_QUEUE_SIZE" default:"100"`
+
+	// RetryDelay doubles after every failed attempt, plus a random jitter below
+	// RetryJitter so that deployments failing together do not retry together, up
+	// to MaxRetryDelay. Zero MaxRetryDelay does not cap the delay.
+	MaxRetryDelay time.Duration `envconfig:"MAX_RETRY_DELAY" default:"1m"`
+	RetryJitter   time.Duration `envconfig:"RETRY_JITTER" default:"1s"`
+
+	// QualityScoreDelay is how long after a Google Ads deployment keyword quality
+	// scores are fetched
+	QualityScoreDelay time.Duration `envconfig:"QUALITY_SCORE_DELAY" default:"24h"`
+
+	// AdReviewDelay is how long after a deployment, and between checks while the
+	// ad is in review, the platform's ad review status is checked
+	AdReviewDelay     time.Duration `envconfig:"AD_REVIEW_DELAY" default:"30m"`
+	AdReviewMaxChecks int           `envconfig:"AD_REVIEW_MAX_CHECKS" default:"12"`
+
+	// CampaignMetricsInterval is how often the performance metrics of active
+	// campaign deployments are fetched
+	CampaignMetricsInterval time.Duration `envconfig:"CAMPAIGN_METRICS_INTERVAL" default:"15m"`
+
+	// MaxDailyBudgetGoogleAds and MaxDailyBudgetMeta cap the daily budget of a
+	// deployment, in the ad account currency. Zero disables the cap.
+	MaxDailyBudgetGoogleAds float64 `envconfig:"MAX_DAILY_BUDGET_GOOGLE_ADS" default:"1000"`
+	MaxDailyBudgetMeta      float64 `envconfig:"MAX_DAILY_BUDGET_META" default:"1000"`
+
+	// QuotaBackoff is how long calls to a Meta or Google Ads account are held back
+	// once it has less than 10% of its API quota left. The backoff doubles with
+	// every further low response, up to MaxQuotaBackoff.
+	QuotaBackoff    time.Duration `envconfig:"QUOTA_BACKOFF" default:"30s"`
+	MaxQuotaBackoff time.Duration `envconfig:"MAX_QUOTA_BACKOFF" default:"5m"`
+
+	// CircuitFailureThreshold consecutive transient failures of the deployments
+	// of a tenant to a platform within CircuitWindow open their circuit. They then
+	// fail at once until CircuitRecoveryTimeout has passed and a trial deployment
+	// succeeds. Zero disables the circuit breakers.
+	CircuitFailureThreshold int           `envconfig:"CIRCUIT_FAILURE_THRESHOLD" default:"5"`
+	CircuitWindow           time.Duration `envconfig:"CIRCUIT_WINDOW" default:"1m"`
+	CircuitRecoveryTimeout  time.Duration `envconfig:"CIRCUIT_RECOVERY_TIMEOUT" default:"30s"`
+}
+
+// CredentialsConfig holds per-tenant platform credential storage configuration.
+// Tenants without stored credentials fall back to the platform credentials above.
+type CredentialsConfig struct {
+	DatabaseURL string `envconfig:"DATABASE_URL"`
+	MasterKey   string `envconfig:"CREDENTIALS_MASTER_KEY"`
+
+	// TransportKey is the base64 encoded 32 byte key, shared with the BFF only,
+	// that encrypts the credentials the BFF sends to be stored
+	TransportKey string `envconfig:"CREDENTIALS_TRANSPORT_KEY"`
+
+	// CredentialBackend is where the platform credentials above are read again
+	// every CredentialRefreshInterval, so that they can be rotated without a
+	// restart: env (the environment and the dotenv file EnvFile), aws (AWS
+	// Secrets Manager) or vault (HashiCorp Vault). Zero CredentialRefreshInterval
+	// only rotates them on request.
+	CredentialBackend         string        `envconfig:"CREDENTIAL_BACKEND" default:"env"`
+	CredentialRefreshInterval time.Duration `envconfig:"CREDENTIAL_REFRESH_INTERVAL" default:"5m"`
+	EnvFile                   string        `envconfig:"CREDENTIAL_ENV_FILE" default:".env"`
+
+	// SecretPrefix names the secret of each platform in AWS Secrets Manager or
+	// Vault, followed by the platform, such as zamc/connectors/meta
+	SecretPrefix string `envconfig:"CREDENTIAL_SECRET_PREFIX" default:"zamc/connectors/"`
+
+	// AWS Secrets Manager
+	AWSRegion          string `envconfig:"AWS_REGION"`
+	AWSAccessKeyID     string `envconfig:"AWS_ACCESS_KEY_ID"`
+	AWSSecretAccessKey string `envconfig:"AWS_SECRET_ACCESS_KEY"`
+	AWSSessionToken    string `envconfig:"AWS_SESSION_TOKEN"`
+	AWSEndpoint        string `envconfig:"CREDENTIAL_AWS_ENDPOINT"`
+
+	// HashiCorp Vault, whose KV version 2 secrets engine is mounted at VaultMount
+	VaultAddress string `envconfig:"VAULT_ADDR"`
+	VaultToken   string `envconfig:"VAULT_TOKEN"`
+	VaultMount   string `envconfig:"CREDENTIAL_VAULT_MOUNT" default:"secret"`
+}
+
+// Enabled returns true if per-tenant credential storage is configured
+func (c *CredentialsConfig) Enabled() bool {
+	return c.DatabaseURL != ""
+}
+
+// SlackConfig holds Slack notification configuration
+type SlackConfig struct {
+	WebhookURL string `envconfig:"SLACK_WEBHOOK_URL"`
+}
+
+// NotificationConfig holds the configuration of the notifications sent for the
+// result of every deployment of an asset to a platform
+type NotificationConfig struct {
+	// SlackEnabled posts the results to SLACK_WEBHOOK_URL, or to the webhook of
+	// their project in SlackProjectWebhooks
+	SlackEnabled         bool            `envconfig:"NOTIFY_SLACK_ENABLED" default:"false"`
+	SlackProjectWebhooks ProjectWebhooks `envconfig:"NOTIFY_SLACK_PROJECT_WEBHOOKS"`
+
+	// EmailEnabled emails the results from EmailFrom to EmailTo through the SMTP
+	// server at SMTPHost:SMTPPort, authenticating when SMTPUsername is set
+	EmailEnabled bool     `envconfig:"NOTIFY_EMAIL_ENABLED" default:"false"`
+	SMTPHost     string   `envconfig:"SMTP_HOST"`
+	SMTPPort     int      `envconfig:"SMTP_PORT" default:"587"`
+	SMTPUsername string   `envconfig:"SMTP_USERNAME"`
+	SMTPPassword string   `envconfig:"SMTP_PASSWORD"`
+	EmailFrom    string   `envconfig:"NOTIFY_EMAIL_FROM"`
+	EmailTo      []string `envconfig:"NOTIFY_EMAIL_TO"`
+}
+
+// ProjectWebhooks maps project IDs to the Slack webhook URL their notifications
+// are posted to. It is configured as a JSON object, such as
+// {"3f0c...": "https://hooks.slack.com/services/..."}.
+type ProjectWebhooks map[string]string
+
+// Decode parses the JSON object of an environment variable
+func (w *ProjectWebhooks) Decode(value string) error {
+	webhooks := map[string]string{}
+	if err := json.Unmarshal([]byte(value), &webhooks); err != nil {
+		return fmt.Errorf("invalid project webhooks: %w", err)
+	}
+	*w = webhooks
+	return nil
+}
+
+// AnomalyConfig holds the configuration of the detection of campaign metrics
+// far from their rolling average
+type AnomalyConfig struct {
+	// ZScoreThreshold is the number of standard deviations from the average of
+	// the last WindowDays days beyond which a metric is anomalous
+	ZScoreThreshold float64 `envconfig:"ANOMALY_ZSCORE_THRESHOLD" default:"2"`
+	WindowDays      int     `envconfig:"ANOMALY_WINDOW_DAYS" default:"14"`
 }
 
 // HealthCheckConfig holds health check configuration
@@ -73,8 +330,15 @@ type HealthCheckConfig struct {
 
 // MonitoringConfig holds monitoring configuration
 type MonitoringConfig struct {
-	EnableMetrics bool `envconfig:"ENABLE_METRICS" default:"true"`
-	MetricsPort   int  `envconfig:"METRICS_PORT" default:"8003"`
+	EnableMetrics bool   `envconfig:"ENABLE_METRICS" default:"true"`
+	MetricsPort   int    `envconfig:"METRICS_PORT" default:"8003"`
+	AdminToken    string `envconfig:"ADMIN_API_TOKEN"`
+}
+
+// TracingConfig holds OpenTelemetry tracing configuration. Spans are exported
+// to the OTLP/HTTP traces endpoint OTLPEndpoint, or not recorded when it is empty.
+type TracingConfig struct {
+	OTLPEndpoint string `envconfig:"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"`
 }
 
 // Load loads configuration from environment variables
@@ -0,0 +1,293 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+)
+
+// Backend reads the current default credentials of a platform from where they
+// are kept, so that they can be rotated without restarting the service
+type Backend interface {
+	Fetch(ctx context.Context, platform models.Platform) (PlatformCreds, error)
+}
+
+// NewBackend creates the backend chosen by cfg.CredentialBackend
+func NewBackend(cfg *config.CredentialsConfig) (Backend, error) {
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+
+	switch cfg.CredentialBackend {
+	case "", "env":
+		return &EnvBackend{Path: cfg.EnvFile}, nil
+	case "aws":
+		if cfg.AWSRegion == "" || cfg.AWSAccessKeyID == "" || cfg.AWSSecretAccessKey == "" {
+			return nil, errors.New("the aws credential backend requires AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+		}
+		return &AWSSecretsManagerBackend{
+			Region:          cfg.AWSRegion,
+			AccessKeyID:     cfg.AWSAccessKeyID,
+			SecretAccessKey: cfg.AWSSecretAccessKey,
+			SessionToken:    cfg.AWSSessionToken,
+			Endpoint:        cfg.AWSEndpoint,
+			SecretPrefix:    cfg.SecretPrefix,
+			HTTPClient:      httpClient,
+		}, nil
+	case "vault":
+		if cfg.VaultAddress == "" || cfg.VaultToken == "" {
+			return nil, errors.New("the vault credential backend requires VAULT_ADDR and VAULT_TOKEN")
+		}
+		return &VaultBackend{
+			Address:      cfg.VaultAddress,
+			Token:        cfg.VaultToken,
+			Mount:        cfg.VaultMount,
+			SecretPrefix: cfg.SecretPrefix,
+			HTTPClient:   httpClient,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported credential backend: %s", cfg.CredentialBackend)
+	}
+}
+
+// envVariables are the environment variables of the credentials of each
+// platform, by the JSON field of PlatformCreds they set
+var envVariables = map[models.Platform]map[string]string{
+	models.PlatformGoogleAds: {
+		"developer_token":   "GOOGLE_ADS_DEVELOPER_TOKEN",
+		"client_id":         "GOOGLE_ADS_CLIENT_ID",
+		"client_secret":     "GOOGLE_ADS_CLIENT_SECRET",
+		"refresh_token":     "GOOGLE_ADS_REFRESH_TOKEN",
+		"customer_id":       "GOOGLE_ADS_CUSTOMER_ID",
+		"login_customer_id": "GOOGLE_ADS_LOGIN_CUSTOMER_ID",
+		"expires_at":        "GOOGLE_ADS_CREDENTIALS_EXPIRES_AT",
+	},
+	models.PlatformMeta: {
+		"app_id":        "META_APP_ID",
+		"app_secret":    "META_APP_SECRET",
+		"access_token":  "META_ACCESS_TOKEN",
+		"ad_account_id": "META_AD_ACCOUNT_ID",
+		"expires_at":    "META_ACCESS_TOKEN_EXPIRES_AT",
+	},
+}
+
+// EnvBackend reads credentials from the variables the service is configured
+// with. As the environment of a running process cannot change, the variables
+// set in the dotenv file at Path take priority over the environment.
+type EnvBackend struct {
+	Path string
+}
+
+// Fetch returns the credentials of platform from the dotenv file and environment
+func (b *EnvBackend) Fetch(ctx context.Context, platform models.Platform) (PlatformCreds, error) {
+	variables, ok := envVariables[platform]
+	if !ok {
+		return PlatformCreds{}, fmt.Errorf("unsupported platform: %s", platform)
+	}
+
+	file := map[string]string{}
+	if b.Path != "" {
+		var err error
+		file, err = godotenv.Read(b.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			file = map[string]string{}
+		} else if err != nil {
+			return PlatformCreds{}, fmt.Errorf("failed to read %s: %w", b.Path, err)
+		}
+	}
+
+	secret := map[string]string{}
+	for field, name := range variables {
+		value, ok := file[name]
+		if !ok {
+			value = os.Getenv(name)
+		}
+		if value != "" {
+			secret[field] = value
+		}
+	}
+
+	data, err := json.Marshal(secret)
+	if err != nil {
+		return PlatformCreds{}, fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+	return decodeSecret(data)
+}
+
+// AWSSecretsManagerBackend reads the credentials of each platform from the
+// JSON secret SecretPrefix followed by the platform in AWS Secrets Manager. Its
+// fields are those of PlatformCreds.
+type AWSSecretsManagerBackend struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	SecretPrefix    string
+
+	// Endpoint overrides the regional endpoint of Secrets Manager
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+// Fetch returns the credentials of platform from its secret
+func (b *AWSSecretsManagerBackend) Fetch(ctx context.Context, platform models.Platform) (PlatformCreds, error) {
+	body, err := json.Marshal(map[string]string{"SecretId": b.SecretPrefix + string(platform)})
+	if err != nil {
+		return PlatformCreds{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := b.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", b.Region)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return PlatformCreds{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	b.sign(req, body, time.Now())
+
+	var response struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := doSecretRequest(b.HTTPClient, req, &response); err != nil {
+		return PlatformCreds{}, err
+	}
+	return decodeSecret([]byte(response.SecretString))
+}
+
+// sign adds the AWS Signature Version 4 of the request to Secrets Manager to req
+func (b *AWSSecretsManagerBackend) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if b.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", b.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(req.Header.Get(name))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method, "/", "", canonicalHeaders.String(), signedHeaders, sha256Hex(body),
+	}, "\n")
+	scope := date + "/" + b.Region + "/secretsmanager/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+b.SecretAccessKey), date)
+	for _, part := range []string{b.Region, "secretsmanager", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// VaultBackend reads the credentials of each platform from the secret
+// SecretPrefix followed by the platform in the KV version 2 secrets engine
+// mounted at Mount in HashiCorp Vault. Its keys are the fields of PlatformCreds.
+type VaultBackend struct {
+	Address      string
+	Token        string
+	Mount        string
+	SecretPrefix string
+	HTTPClient   *http.Client
+}
+
+// Fetch returns the credentials of platform from the latest version of its secret
+func (b *VaultBackend) Fetch(ctx context.Context, platform models.Platform) (PlatformCreds, error) {
+	url := fmt.Sprintf("%s/v1/%s/data/%s%s", strings.TrimSuffix(b.Address, "/"), strings.Trim(b.Mount, "/"), b.SecretPrefix, platform)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return PlatformCreds{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", b.Token)
+
+	var response struct {
+		Data struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+	if err := doSecretRequest(b.HTTPClient, req, &response); err != nil {
+		return PlatformCreds{}, err
+	}
+	return decodeSecret(response.Data.Data)
+}
+
+// doSecretRequest sends req and decodes its JSON response into v
+func doSecretRequest(client *http.Client, req *http.Request, v interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request secret: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read secret: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("secret request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to unmarshal secret response: %w", err)
+	}
+	return nil
+}
+
+// decodeSecret returns the credentials of a JSON secret
+func decodeSecret(data []byte) (PlatformCreds, error) {
+	var creds PlatformCreds
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return creds, fmt.Errorf("failed to unmarshal credentials: %w", err)
+	}
+	return creds, nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
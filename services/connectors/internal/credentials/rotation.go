@@ -0,0 +1,174 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+)
+
+// ErrUnsupportedPlatform is returned when rotating the credentials of a platform
+// without a client to rotate them in
+var ErrUnsupportedPlatform = errors.New("credential rotation is not supported for this platform")
+
+// ExpiryWarning is how long before they expire rotated credentials are reported
+// as expiring
+const ExpiryWarning = 72 * time.Hour
+
+// GoogleAdsClient is the Google Ads client whose credentials are rotated,
+// implemented by *googleads.Client
+type GoogleAdsClient interface {
+	Config() *config.GoogleAdsConfig
+	UpdateCredentials(cfg *config.GoogleAdsConfig) error
+}
+
+// MetaClient is the Meta client whose credentials are rotated, implemented by
+// *meta.Client
+type MetaClient interface {
+	Config() *config.MetaConfig
+	UpdateCredentials(cfg *config.MetaConfig)
+}
+
+// Rotator keeps the default credentials of the Google Ads and Meta clients up to
+// date with those of a backend, so that expired tokens can be replaced without
+// restarting the service. Tenants with their own credentials in the
+// CredentialStore are not affected.
+//
+// Every rotation, failed rotation and expiring credentials are written to the
+// audit log, the entries of the logger with the audit field.
+type Rotator struct {
+	backend   Backend
+	googleAds GoogleAdsClient
+	meta      MetaClient
+	audit     *logrus.Entry
+	now       func() time.Time
+
+	// mu serializes rotations
+	mu sync.Mutex
+}
+
+// NewRotator creates a rotator updating the clients given with the credentials
+// of backend
+func NewRotator(backend Backend, googleAds GoogleAdsClient, meta MetaClient, logger *logrus.Logger) *Rotator {
+	return &Rotator{
+		backend:   backend,
+		googleAds: googleAds,
+		meta:      meta,
+		audit:     logger.WithField("audit", true),
+		now:       time.Now,
+	}
+}
+
+// Run checks the backend for new credentials every interval until ctx is done.
+// Zero interval disables the checks.
+func (r *Rotator) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, platform := range []models.Platform{models.PlatformGoogleAds, models.PlatformMeta} {
+				// Failures are in the audit log
+				r.rotate(ctx, platform, "scheduled", false)
+			}
+		}
+	}
+}
+
+// Rotate fetches the credentials of platform from the backend and updates its
+// client with them at once
+func (r *Rotator) Rotate(ctx context.Context, platform models.Platform) error {
+	return r.rotate(ctx, platform, "manual", true)
+}
+
+// rotate updates the client of platform with the credentials of the backend,
+// unless they are those it already uses and force is false
+func (r *Rotator) rotate(ctx context.Context, platform models.Platform, trigger string, force bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	logger := r.audit.WithFields(logrus.Fields{
+		"platform": platform,
+		"trigger":  trigger,
+		"backend":  fmt.Sprintf("%T", r.backend),
+	})
+
+	switch {
+	case platform == models.PlatformGoogleAds && r.googleAds != nil:
+	case platform == models.PlatformMeta && r.meta != nil:
+	default:
+		return ErrUnsupportedPlatform
+	}
+
+	creds, err := r.backend.Fetch(ctx, platform)
+	if err == nil {
+		err = creds.Validate(platform)
+	}
+	if err != nil {
+		logger.WithError(err).WithField("event", "credential_rotation_failed").Error("Failed to fetch platform credentials")
+		return fmt.Errorf("failed to fetch %s credentials: %w", platform, err)
+	}
+
+	r.checkExpiry(logger, creds)
+
+	// The clients keep their accounts, only their credentials change
+	switch platform {
+	case models.PlatformGoogleAds:
+		current := r.googleAds.Config()
+		cfg := creds.GoogleAdsConfig(*current)
+		cfg.CustomerID, cfg.LoginCustomerID = current.CustomerID, current.LoginCustomerID
+		if !force && sameGoogleAdsCredentials(&cfg, current) {
+			return nil
+		}
+		if err := r.googleAds.UpdateCredentials(&cfg); err != nil {
+			logger.WithError(err).WithField("event", "credential_rotation_failed").Error("Failed to update platform credentials")
+			return fmt.Errorf("failed to update %s credentials: %w", platform, err)
+		}
+	case models.PlatformMeta:
+		current := r.meta.Config()
+		cfg := creds.MetaConfig(*current)
+		cfg.AdAccountID = current.AdAccountID
+		if !force && cfg == *current {
+			return nil
+		}
+		r.meta.UpdateCredentials(&cfg)
+	}
+
+	logger.WithField("event", "credentials_rotated").Info("Platform credentials rotated")
+	return nil
+}
+
+// sameGoogleAdsCredentials tells whether a and b hold the same credentials, the
+// only fields a rotation changes
+func sameGoogleAdsCredentials(a, b *config.GoogleAdsConfig) bool {
+	return a.DeveloperToken == b.DeveloperToken && a.ClientID == b.ClientID &&
+		a.ClientSecret == b.ClientSecret && a.RefreshToken == b.RefreshToken
+}
+
+// checkExpiry reports credentials that have expired or expire within ExpiryWarning
+func (r *Rotator) checkExpiry(logger *logrus.Entry, creds PlatformCreds) {
+	if creds.ExpiresAt == nil {
+		return
+	}
+
+	logger = logger.WithField("expires_at", creds.ExpiresAt.Format(time.RFC3339))
+	switch left := creds.ExpiresAt.Sub(r.now()); {
+	case left <= 0:
+		logger.WithField("event", "credentials_expired").Error("Platform credentials have expired")
+	case left <= ExpiryWarning:
+		logger.WithField("event", "credentials_expiring").Warn("Platform credentials expire soon")
+	}
+}
@@ -0,0 +1,314 @@
+package credentials
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+)
+
+// ErrNotFound is returned when a tenant has no stored credentials for a platform
+var ErrNotFound = errors.New("platform credentials not found")
+
+// PlatformCreds holds the credentials a tenant uses for one advertising platform.
+// Only the fields relevant to the platform are set.
+type PlatformCreds struct {
+	// Google Ads
+	DeveloperToken  string `json:"developer_token,omitempty"`
+	ClientID        string `json:"client_id,omitempty"`
+	ClientSecret    string `json:"client_secret,omitempty"`
+	RefreshToken    string `json:"refresh_token,omitempty"`
+	CustomerID      string `json:"customer_id,omitempty"`
+	LoginCustomerID string `json:"login_customer_id,omitempty"`
+
+	// Meta
+	AppID       string `json:"app_id,omitempty"`
+	AppSecret   string `json:"app_secret,omitempty"`
+	AccessToken string `json:"access_token,omitempty"`
+	AdAccountID string `json:"ad_account_id,omitempty"`
+
+	// ExpiresAt is when the credentials expire, if known
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// GoogleAdsConfig returns base with the tenant's Google Ads credentials applied
+func (c PlatformCreds) GoogleAdsConfig(base config.GoogleAdsConfig) config.GoogleAdsConfig {
+	base.DeveloperToken = c.DeveloperToken
+	base.ClientID = c.ClientID
+	base.ClientSecret = c.ClientSecret
+	base.RefreshToken = c.RefreshToken
+	base.CustomerID = c.CustomerID
+	base.LoginCustomerID = c.LoginCustomerID
+	return base
+}
+
+// MetaConfig returns base with the tenant's Meta credentials applied
+func (c PlatformCreds) MetaConfig(base config.MetaConfig) config.MetaConfig {
+	base.AppID = c.AppID
+	base.AppSecret = c.AppSecret
+	base.AccessToken = c.AccessToken
+	base.AdAccountID = c.AdAccountID
+	return base
+}
+
+// Validate checks that the credentials required by platform are present
+func (c PlatformCreds) Validate(platform models.Platform) error {
+	var required map[string]string
+	switch platform {
+	case models.PlatformGoogleAds:
+		required = map[string]string{
+			"developer_token": c.DeveloperToken,
+			"client_id":       c.ClientID,
+			"client_secret":   c.ClientSecret,
+			"refresh_token":   c.RefreshToken,
+			"customer_id":     c.CustomerID,
+		}
+	case models.PlatformMeta:
+		required = map[string]string{
+			"app_id":        c.AppID,
+			"app_secret":    c.AppSecret,
+			"access_token":  c.AccessToken,
+			"ad_account_id": c.AdAccountID,
+		}
+	default:
+		return fmt.Errorf("unsupported platform: %s", platform)
+	}
+
+	for field, value := range required {
+		if value == "" {
+			return fmt.Errorf("missing %s for platform %s", field, platform)
+		}
+	}
+
+	return nil
+}
+
+// Cipher encrypts credentials with AES-256-GCM
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a cipher from a base64 encoded 32 byte master key
+func NewCipher(masterKey string) (*Cipher, error) {
+	key, err := base64.StdEncoding.DecodeString(masterKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode master key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("master key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create block cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM cipher: %w", err)
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+// Encrypt seals plaintext and returns the nonce followed by the ciphertext.
+// The additional data binds the ciphertext to its tenant and platform.
+func (c *Cipher) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Decrypt opens a value produced by Encrypt
+func (c *Cipher) Decrypt(sealed, additionalData []byte) ([]byte, error) {
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	plaintext, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt credentials: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// CredentialStore persists encrypted per-tenant platform credentials
+type CredentialStore struct {
+	db     *sql.DB
+	cipher *Cipher
+
+	// transport opens the credentials sent by the BFF
+	transport *Cipher
+}
+
+// NewCredentialStore creates a credential store backed by the platform_credentials table
+func NewCredentialStore(db *sql.DB, masterKey string) (*CredentialStore, error) {
+	c, err := NewCipher(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CredentialStore{db: db, cipher: c}, nil
+}
+
+// Open connects to the database and creates a credential store from cfg
+func Open(cfg *config.CredentialsConfig) (*CredentialStore, error) {
+	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open credentials database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping credentials database: %w", err)
+	}
+
+	store, err := NewCredentialStore(db, cfg.MasterKey)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if cfg.TransportKey != "" {
+		if err := store.UseTransportKey(cfg.TransportKey); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	return store, nil
+}
+
+// UseTransportKey opens the credentials sent by the BFF with transportKey, the
+// base64 encoded 32 byte key it seals them with
+func (s *CredentialStore) UseTransportKey(transportKey string) error {
+	c, err := NewCipher(transportKey)
+	if err != nil {
+		return fmt.Errorf("invalid transport key: %w", err)
+	}
+
+	s.transport = c
+	return nil
+}
+
+// OpenTransported decrypts the credentials of tenantID for platform sealed by
+// the BFF with the transport key
+func (s *CredentialStore) OpenTransported(tenantID, platform, sealed string) (PlatformCreds, error) {
+	var creds PlatformCreds
+	if s.transport == nil {
+		return creds, errors.New("credentials transport key is not configured")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return creds, fmt.Errorf("failed to decode transported credentials: %w", err)
+	}
+
+	plaintext, err := s.transport.Decrypt(data, additionalData(tenantID, platform))
+	if err != nil {
+		return creds, err
+	}
+
+	if err := json.Unmarshal(plaintext, &creds); err != nil {
+		return creds, fmt.Errorf("invalid credentials payload: %w", err)
+	}
+
+	return creds, nil
+}
+
+// GetCredentials returns the decrypted credentials of tenantID for platform
+func (s *CredentialStore) GetCredentials(ctx context.Context, tenantID, platform string) (PlatformCreds, error) {
+	var creds PlatformCreds
+	var encrypted string
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT credentials_json_encrypted FROM platform_credentials
+		WHERE tenant_id = $1 AND platform = $2
+	`, tenantID, platform).Scan(&encrypted)
+	if err == sql.ErrNoRows {
+		return creds, ErrNotFound
+	} else if err != nil {
+		return creds, fmt.Errorf("failed to query platform credentials: %w", err)
+	}
+
+	plaintext, err := s.decrypt(tenantID, platform, encrypted)
+	if err != nil {
+		return creds, err
+	}
+
+	if err := json.Unmarshal(plaintext, &creds); err != nil {
+		return creds, fmt.Errorf("failed to unmarshal platform credentials: %w", err)
+	}
+
+	return creds, nil
+}
+
+// StoreCredentials encrypts and saves the credentials of tenantID for platform,
+// replacing any existing ones
+func (s *CredentialStore) StoreCredentials(ctx context.Context, tenantID, platform string, creds PlatformCreds) error {
+	encrypted, err := s.encrypt(tenantID, platform, creds)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO platform_credentials (tenant_id, platform, credentials_json_encrypted, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		ON CONFLICT (tenant_id, platform) DO UPDATE
+		SET credentials_json_encrypted = EXCLUDED.credentials_json_encrypted, updated_at = NOW()
+	`, tenantID, platform, encrypted)
+	if err != nil {
+		return fmt.Errorf("failed to store platform credentials: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the underlying database connection
+func (s *CredentialStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *CredentialStore) encrypt(tenantID, platform string, creds PlatformCreds) (string, error) {
+	plaintext, err := json.Marshal(creds)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal platform credentials: %w", err)
+	}
+
+	sealed, err := s.cipher.Encrypt(plaintext, additionalData(tenantID, platform))
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *CredentialStore) decrypt(tenantID, platform, encrypted string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode platform credentials: %w", err)
+	}
+
+	return s.cipher.Decrypt(sealed, additionalData(tenantID, platform))
+}
+
+func additionalData(tenantID, platform string) []byte {
+	return []byte(tenantID + ":" + platform)
+}
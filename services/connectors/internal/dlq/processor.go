@@ -0,0 +1,182 @@
+package dlq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/nats"
+)
+
+// DLQProcessor keeps the asset status changed events of deployments that failed on
+// every attempt and replays them on request. Dead letters are stored as they
+// arrive on the dead letter stream and removed once replayed, so the store only
+// holds unprocessed entries.
+//
+// It serves the stored entries over HTTP:
+//
+//	GET  /dlq              lists the entries waiting to be replayed
+//	POST /dlq/{id}/retry   replays an entry's event through the event handler
+type DLQProcessor struct {
+	store      EntryStore
+	natsClient *nats.Client
+	handler    nats.EventHandler
+	logger     *logrus.Logger
+}
+
+// NewDLQProcessor creates a processor storing the dead letters received through
+// natsClient in store and replaying them through handler
+func NewDLQProcessor(store EntryStore, natsClient *nats.Client, handler nats.EventHandler, logger *logrus.Logger) *DLQProcessor {
+	return &DLQProcessor{
+		store:      store,
+		natsClient: natsClient,
+		handler:    handler,
+		logger:     logger,
+	}
+}
+
+// Run stores dead letters until ctx is cancelled
+func (p *DLQProcessor) Run(ctx context.Context) error {
+	return p.natsClient.SubscribeToDeadLetters(ctx, p)
+}
+
+// HandleDeadLetter stores a dead letter for replay
+func (p *DLQProcessor) HandleDeadLetter(ctx context.Context, letter *models.DeadLetter) error {
+	if err := p.store.SaveEntry(ctx, letter); err != nil {
+		return err
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"id":             letter.ID,
+		"asset_id":       letter.AssetID,
+		"failure_reason": letter.FailureReason,
+		"retry_count":    letter.RetryCount,
+	}).Warn("Stored dead letter")
+
+	return nil
+}
+
+// Entries returns the dead letters waiting to be replayed
+func (p *DLQProcessor) Entries(ctx context.Context) ([]models.DeadLetter, error) {
+	return p.store.ListEntries(ctx)
+}
+
+// Retry removes a dead letter from the store and replays its event in the
+// background, returning the entry. Entries whose event the handler fails to
+// handle are stored again; deployments that fail again are dead-lettered anew.
+func (p *DLQProcessor) Retry(ctx context.Context, id uuid.UUID) (*models.DeadLetter, error) {
+	letter, err := p.store.DeleteEntry(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var event models.AssetStatusChangedEvent
+	if err := json.Unmarshal(letter.Event, &event); err != nil {
+		p.restore(ctx, letter)
+		return nil, fmt.Errorf("invalid dead letter event: %w", err)
+	}
+
+	go p.replay(context.WithoutCancel(ctx), letter, &event)
+
+	return letter, nil
+}
+
+// replay hands the event of a dead letter to the handler
+func (p *DLQProcessor) replay(ctx context.Context, letter *models.DeadLetter, event *models.AssetStatusChangedEvent) {
+	logger := p.logger.WithFields(logrus.Fields{
+		"id":       letter.ID,
+		"asset_id": letter.AssetID,
+	})
+	logger.Info("Replaying dead letter")
+
+	if err := p.handler.HandleAssetStatusChanged(ctx, event); err != nil {
+		logger.WithError(err).Error("Failed to replay dead letter")
+		p.restore(ctx, letter)
+	}
+}
+
+// restore stores a dead letter again after a failed replay
+func (p *DLQProcessor) restore(ctx context.Context, letter *models.DeadLetter) {
+	if err := p.store.SaveEntry(ctx, letter); err != nil {
+		p.logger.WithError(err).WithField("asset_id", letter.AssetID).Error("Failed to restore dead letter")
+	}
+}
+
+// ServeHTTP serves the dead letter endpoints
+func (p *DLQProcessor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/dlq" {
+		p.serveEntries(w, r)
+		return
+	}
+
+	rest := strings.TrimPrefix(r.URL.Path, "/dlq/")
+	id, action, ok := strings.Cut(rest, "/")
+	if rest == r.URL.Path || !ok || action != "retry" {
+		http.NotFound(w, r)
+		return
+	}
+
+	p.serveRetry(w, r, id)
+}
+
+// serveEntries lists the dead letters waiting to be replayed
+func (p *DLQProcessor) serveEntries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	entries, err := p.Entries(r.Context())
+	if err != nil {
+		p.logger.WithError(err).Error("Failed to list dead letters")
+		http.Error(w, "Dead letters unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	p.writeJSON(w, http.StatusOK, map[string]interface{}{"entries": entries})
+}
+
+// serveRetry replays the dead letter with the given ID
+func (p *DLQProcessor) serveRetry(w http.ResponseWriter, r *http.Request, rawID string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		http.Error(w, "Invalid dead letter ID", http.StatusBadRequest)
+		return
+	}
+
+	letter, err := p.Retry(r.Context(), id)
+	if errors.Is(err, ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		p.logger.WithError(err).WithField("id", id).Error("Failed to retry dead letter")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	p.writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"status":   "retrying",
+		"id":       letter.ID,
+		"asset_id": letter.AssetID,
+	})
+}
+
+func (p *DLQProcessor) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		p.logger.WithError(err).Error("Failed to write dead letter response")
+	}
+}
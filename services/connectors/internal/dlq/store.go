@@ -0,0 +1,135 @@
+package dlq
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+// ErrNotFound is returned when a dead letter does not exist or was already replayed
+var ErrNotFound = errors.New("dead letter not found")
+
+// EntryStore persists dead letters until they are replayed
+type EntryStore interface {
+	SaveEntry(ctx context.Context, letter *models.DeadLetter) error
+	ListEntries(ctx context.Context) ([]models.DeadLetter, error)
+	DeleteEntry(ctx context.Context, id uuid.UUID) (*models.DeadLetter, error)
+}
+
+// Store persists dead letters in the deployment_dlq table
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates a dead letter store backed by db
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Open connects to the database and creates a dead letter store
+func Open(databaseURL string) (*Store, error) {
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead letter database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping dead letter database: %w", err)
+	}
+
+	return NewStore(db), nil
+}
+
+// SaveEntry saves a dead letter. The ID and creation time of the saved entry are
+// set on letter.
+func (s *Store) SaveEntry(ctx context.Context, letter *models.DeadLetter) error {
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO deployment_dlq (asset_id, event_json, failure_reason, retry_count)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, letter.AssetID, []byte(letter.Event), letter.FailureReason, letter.RetryCount).Scan(&letter.ID, &letter.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save dead letter: %w", err)
+	}
+
+	return nil
+}
+
+// ListEntries returns the dead letters waiting to be replayed, oldest first
+func (s *Store) ListEntries(ctx context.Context) ([]models.DeadLetter, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, asset_id, event_json, failure_reason, retry_count, created_at
+		FROM deployment_dlq
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	letters := []models.DeadLetter{}
+	for rows.Next() {
+		letter, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dead letter: %w", err)
+		}
+		letters = append(letters, *letter)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate dead letters: %w", err)
+	}
+
+	return letters, nil
+}
+
+// DeleteEntry removes a dead letter and returns it. Concurrent deletions of the
+// same entry return it only once.
+func (s *Store) DeleteEntry(ctx context.Context, id uuid.UUID) (*models.DeadLetter, error) {
+	letter, err := scanEntry(s.db.QueryRowContext(ctx, `
+		DELETE FROM deployment_dlq WHERE id = $1
+		RETURNING id, asset_id, event_json, failure_reason, retry_count, created_at
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to delete dead letter: %w", err)
+	}
+
+	return letter, nil
+}
+
+// Close closes the underlying database connection
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEntry(row rowScanner) (*models.DeadLetter, error) {
+	var letter models.DeadLetter
+	var event []byte
+	err := row.Scan(
+		&letter.ID,
+		&letter.AssetID,
+		&event,
+		&letter.FailureReason,
+		&letter.RetryCount,
+		&letter.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	letter.Event = event
+	return &letter, nil
+}
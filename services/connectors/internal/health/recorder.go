@@ -0,0 +1,238 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// timelineKeyPrefix prefixes the sorted set of a service's health snapshots,
+	// scored by their Unix time
+	timelineKeyPrefix = "health_timeline:"
+
+	// RecordInterval is the time between two snapshots
+	RecordInterval = 30 * time.Second
+
+	// Retention is how long snapshots are kept
+	Retention = 48 * time.Hour
+
+	// MaxHistory bounds the snapshots returned by History
+	MaxHistory = 200
+)
+
+// Overall health statuses of a Snapshot
+const (
+	StatusHealthy   = "healthy"
+	StatusUnhealthy = "unhealthy"
+)
+
+// Snapshot is the health of one service instance at a point in time
+type Snapshot struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Instance  string            `json:"instance"`
+	Status    string            `json:"status"`
+	Services  map[string]string `json:"services"`
+}
+
+// Healthy returns true if every service was healthy
+func (s Snapshot) Healthy() bool {
+	return s.Status == StatusHealthy
+}
+
+// Incident is a period during which an instance was unhealthy
+type Incident struct {
+	Instance   string            `json:"instance"`
+	StartedAt  time.Time         `json:"started_at"`
+	ResolvedAt *time.Time        `json:"resolved_at,omitempty"`
+	Services   map[string]string `json:"services"`
+}
+
+// CheckFunc returns the health of each service a snapshot covers
+type CheckFunc func(ctx context.Context) map[string]string
+
+// HealthRecorder keeps a timeline of health snapshots in Redis so that past
+// outages can be looked at after the fact
+type HealthRecorder struct {
+	client   *redis.Client
+	key      string
+	instance string
+	check    CheckFunc
+	logger   *logrus.Logger
+}
+
+// NewHealthRecorder creates a recorder storing the snapshots of check in the
+// timeline of service
+func NewHealthRecorder(client *redis.Client, service string, check CheckFunc, logger *logrus.Logger) *HealthRecorder {
+	instance, err := os.Hostname()
+	if err != nil {
+		instance = "unknown"
+	}
+
+	return &HealthRecorder{
+		client:   client,
+		key:      timelineKeyPrefix + service,
+		instance: instance,
+		check:    check,
+		logger:   logger,
+	}
+}
+
+// Run records a snapshot immediately and then every RecordInterval until ctx is done
+func (r *HealthRecorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(RecordInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Record(ctx); err != nil {
+			r.logger.WithError(err).Warn("Failed to record health snapshot")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Record checks the health of the services and saves it as a snapshot
+func (r *HealthRecorder) Record(ctx context.Context) (*Snapshot, error) {
+	checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	services := r.check(checkCtx)
+	cancel()
+
+	snapshot := Snapshot{
+		Timestamp: time.Now().UTC(),
+		Instance:  r.instance,
+		Status:    StatusHealthy,
+		Services:  services,
+	}
+	for _, status := range services {
+		if status != StatusHealthy {
+			snapshot.Status = StatusUnhealthy
+			break
+		}
+	}
+
+	if err := r.Save(ctx, snapshot); err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// Save adds a snapshot to the timeline and drops the snapshots older than Retention
+func (r *HealthRecorder) Save(ctx context.Context, snapshot Snapshot) error {
+	member, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal health snapshot: %w", err)
+	}
+
+	cutoff := snapshot.Timestamp.Add(-Retention).Unix()
+
+	pipe := r.client.TxPipeline()
+	pipe.ZAdd(ctx, r.key, &redis.Z{Score: float64(snapshot.Timestamp.Unix()), Member: member})
+	pipe.ZRemRangeByScore(ctx, r.key, "-inf", "("+strconv.FormatInt(cutoff, 10))
+	pipe.Expire(ctx, r.key, Retention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save health snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// History returns up to MaxHistory snapshots taken between start and end, oldest first
+func (r *HealthRecorder) History(ctx context.Context, start, end time.Time) ([]Snapshot, error) {
+	return r.snapshots(ctx, start, end, MaxHistory)
+}
+
+// Incidents returns the incidents that started between start and end
+func (r *HealthRecorder) Incidents(ctx context.Context, start, end time.Time) ([]Incident, error) {
+	snapshots, err := r.snapshots(ctx, start, end, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return DetectIncidents(snapshots), nil
+}
+
+// snapshots reads the snapshots taken between start and end, all of them when limit is 0
+func (r *HealthRecorder) snapshots(ctx context.Context, start, end time.Time, limit int64) ([]Snapshot, error) {
+	members, err := r.client.ZRangeByScore(ctx, r.key, &redis.ZRangeBy{
+		Min:   strconv.FormatInt(start.Unix(), 10),
+		Max:   strconv.FormatInt(end.Unix(), 10),
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read health timeline: %w", err)
+	}
+
+	snapshots := make([]Snapshot, 0, len(members))
+	for _, member := range members {
+		var snapshot Snapshot
+		if err := json.Unmarshal([]byte(member), &snapshot); err != nil {
+			r.logger.WithError(err).Warn("Skipping invalid health snapshot")
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// DetectIncidents finds the transitions from healthy to unhealthy in snapshots,
+// separately for each instance. An incident is resolved by the instance's next
+// healthy snapshot. An instance that is unhealthy in its first snapshot has no
+// known transition and starts no incident until it has recovered.
+func DetectIncidents(snapshots []Snapshot) []Incident {
+	sorted := make([]Snapshot, len(snapshots))
+	copy(sorted, snapshots)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	incidents := []Incident{}
+	lastHealthy := make(map[string]bool)
+	open := make(map[string]int)
+
+	for _, snapshot := range sorted {
+		wasHealthy, seen := lastHealthy[snapshot.Instance]
+		lastHealthy[snapshot.Instance] = snapshot.Healthy()
+
+		if !seen {
+			continue
+		}
+
+		switch {
+		case wasHealthy && !snapshot.Healthy():
+			unhealthy := make(map[string]string)
+			for service, status := range snapshot.Services {
+				if status != StatusHealthy {
+					unhealthy[service] = status
+				}
+			}
+			open[snapshot.Instance] = len(incidents)
+			incidents = append(incidents, Incident{
+				Instance:  snapshot.Instance,
+				StartedAt: snapshot.Timestamp,
+				Services:  unhealthy,
+			})
+		case !wasHealthy && snapshot.Healthy():
+			if i, ok := open[snapshot.Instance]; ok {
+				resolvedAt := snapshot.Timestamp
+				incidents[i].ResolvedAt = &resolvedAt
+				delete(open, snapshot.Instance)
+			}
+		}
+	}
+
+	return incidents
+}
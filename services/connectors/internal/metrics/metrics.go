@@ -0,0 +1,45 @@
+// Package metrics defines the Prometheus metrics of the connectors service that
+// are measured in process. They are registered with the default registry.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "zamc_connectors"
+
+var (
+	// NATSPublishDuration observes the time to publish a NATS message until the
+	// server acknowledges it
+	NATSPublishDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "nats_publish_duration_seconds",
+		Help:      "Duration of NATS publishes by subject.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"subject"})
+
+	// NATSHandleDuration observes the time subscribers take to handle a NATS message
+	NATSHandleDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "nats_handle_duration_seconds",
+		Help:      "Duration of NATS message handling by subject.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"subject"})
+
+	// CircuitBreakers is the number of circuit breakers of the deployments to each
+	// platform in each state. Each tenant has its own circuit per platform.
+	CircuitBreakers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "circuit_breakers",
+		Help:      "Number of circuit breakers of platform deployments, one per platform and tenant, in each state.",
+	}, []string{"platform", "state"})
+
+	// DeploymentQueueDepth is the number of deployments waiting for a worker of
+	// each platform
+	DeploymentQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "deployment_queue_depth",
+		Help:      "Number of deployments waiting for a worker by platform.",
+	}, []string{"platform"})
+)
@@ -3,6 +3,7 @@ package mocks
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/zamc/connectors/internal/models"
 	"github.com/zamc/connectors/internal/nats"
@@ -29,9 +30,8 @@ func NewMockNATSClient() *MockNATSClient {
 // SubscribeToAssetStatusChanged mocks the subscription to asset status changed events
 func (m *MockNATSClient) SubscribeToAssetStatusChanged(ctx context.Context, handler nats.EventHandler) error {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-	
 	m.subscriptionHandler = handler
+	m.mu.Unlock()
 	
 	// Wait for context cancellation
 	<-ctx.Done()
@@ -64,6 +64,59 @@ func (m *MockNATSClient) PublishAssetStatusChanged(ctx context.Context, event *m
 	return nil
 }
 
+// PublishAssetPlatformRejected mocks publishing asset platform rejected events
+func (m *MockNATSClient) PublishAssetPlatformRejected(ctx context.Context, event *models.AssetPlatformRejectedEvent) error {
+	return m.publish(event)
+}
+
+// PublishCampaignBudgetExceeded mocks publishing campaign budget exceeded events
+func (m *MockNATSClient) PublishCampaignBudgetExceeded(ctx context.Context, event *models.CampaignBudgetExceededEvent) error {
+	return m.publish(event)
+}
+
+// PublishAssetRolledBack mocks publishing asset rolled back events
+func (m *MockNATSClient) PublishAssetRolledBack(ctx context.Context, event *models.AssetRolledBackEvent) error {
+	return m.publish(event)
+}
+
+// PublishCampaignPerformanceAlert mocks publishing campaign performance alerts
+func (m *MockNATSClient) PublishCampaignPerformanceAlert(ctx context.Context, event *models.CampaignPerformanceAlertEvent) error {
+	return m.publish(event)
+}
+
+// PublishScheduledDeployment mocks publishing deployments scheduled for later
+func (m *MockNATSClient) PublishScheduledDeployment(ctx context.Context, event *models.AssetStatusChangedEvent) error {
+	return m.publish(event)
+}
+
+// PublishDeadLetter mocks publishing failed events to the dead letter queue
+func (m *MockNATSClient) PublishDeadLetter(ctx context.Context, event *models.AssetStatusChangedEvent, failureReason string, retryCount int) error {
+	return m.publish(event)
+}
+
+// PublishKeywordQualityScoreFetch mocks publishing delayed quality score fetches
+func (m *MockNATSClient) PublishKeywordQualityScoreFetch(ctx context.Context, fetch *models.KeywordQualityScoreFetch, delay time.Duration) error {
+	return m.publish(fetch)
+}
+
+// PublishAdReviewCheck mocks publishing delayed ad review checks
+func (m *MockNATSClient) PublishAdReviewCheck(ctx context.Context, check *models.AdReviewCheck, delay time.Duration) error {
+	return m.publish(check)
+}
+
+// publish records an event unless publishing should fail
+func (m *MockNATSClient) publish(event interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFailPublish {
+		return &MockError{Message: "mock publish error"}
+	}
+
+	m.publishedEvents = append(m.publishedEvents, event)
+	return nil
+}
+
 // HealthCheck mocks the health check
 func (m *MockNATSClient) HealthCheck() error {
 	m.mu.RLock()
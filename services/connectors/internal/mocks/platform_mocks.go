@@ -6,7 +6,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/zamc/connectors/internal/cache"
+	"github.com/zamc/connectors/internal/config"
 	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/googleads"
+	"github.com/zamc/connectors/internal/ratelimit"
+	"github.com/zamc/connectors/internal/scheduler"
 )
 
 // MockGoogleAdsClient is a mock implementation of the Google Ads client
@@ -16,6 +21,8 @@ type MockGoogleAdsClient struct {
 	shouldFailDeployment  bool
 	shouldFailHealthCheck bool
 	deploymentDelay       time.Duration
+	biddingStrategies     config.BiddingStrategies
+	campaignBiddings      []*googleads.CampaignBidding
 }
 
 // NewMockGoogleAdsClient creates a new mock Google Ads client
@@ -52,7 +59,19 @@ func (m *MockGoogleAdsClient) DeployAsset(ctx context.Context, request *models.D
 		}, &MockError{Message: "mock deployment failure"}
 	}
 
+	bidding, err := googleads.BuildCampaignBidding(m.biddingStrategies, request)
+	if err != nil {
+		return &models.DeploymentResult{
+			AssetID:    request.AssetID,
+			Platform:   models.PlatformGoogleAds,
+			Status:     models.DeploymentStatusFailed,
+			Error:      err.Error(),
+			DeployedAt: time.Now(),
+		}, err
+	}
+
 	m.deployments = append(m.deployments, *request)
+	m.campaignBiddings = append(m.campaignBiddings, bidding)
 
 	return &models.DeploymentResult{
 		AssetID:     request.AssetID,
@@ -81,6 +100,64 @@ func (m *MockGoogleAdsClient) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
+// Config returns an empty Google Ads configuration
+func (m *MockGoogleAdsClient) Config() *config.GoogleAdsConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return &config.GoogleAdsConfig{BiddingStrategyMap: m.biddingStrategies}
+}
+
+// SetScheduler does nothing
+func (m *MockGoogleAdsClient) SetScheduler(worker *scheduler.SchedulerWorker) {}
+
+// SetRateLimiter does nothing
+func (m *MockGoogleAdsClient) SetRateLimiter(limiter *ratelimit.PlatformRateLimiter) {}
+
+// GetBillingStatus mocks an approved account without a credit limit
+func (m *MockGoogleAdsClient) GetBillingStatus(ctx context.Context) (*models.BillingStatus, error) {
+	return &models.BillingStatus{Approved: true, Unlimited: true, Currency: "USD"}, nil
+}
+
+// EstimateCampaignCost mocks the cost estimate of a campaign
+func (m *MockGoogleAdsClient) EstimateCampaignCost(ctx context.Context, request *models.DeploymentRequest) (*models.CostEstimate, error) {
+	return &models.CostEstimate{EstimatedSpend: request.Metadata.Budget, Currency: "USD"}, nil
+}
+
+// FetchCampaignMetrics mocks fetching the metrics of a campaign
+func (m *MockGoogleAdsClient) FetchCampaignMetrics(ctx context.Context, campaignID string, dateRange googleads.DateRange) (*models.CampaignMetrics, error) {
+	return &models.CampaignMetrics{CampaignID: campaignID, Platform: models.PlatformGoogleAds, FetchedAt: time.Now()}, nil
+}
+
+// FetchKeywordQualityScores mocks fetching the keyword quality scores of an ad group
+func (m *MockGoogleAdsClient) FetchKeywordQualityScores(ctx context.Context, adGroupID string) ([]models.KeywordQualityScore, error) {
+	return []models.KeywordQualityScore{}, nil
+}
+
+// GetAdReviewStatus mocks an approved ad
+func (m *MockGoogleAdsClient) GetAdReviewStatus(ctx context.Context, platformAdID string) (*models.AdReviewStatus, error) {
+	return &models.AdReviewStatus{Status: models.AdReviewStatusApproved}, nil
+}
+
+// PauseAd does nothing
+func (m *MockGoogleAdsClient) PauseAd(ctx context.Context, campaignID, adGroupID, adID string) error {
+	return nil
+}
+
+// PauseCampaign does nothing
+func (m *MockGoogleAdsClient) PauseCampaign(ctx context.Context, platformCampaignID string) error {
+	return nil
+}
+
+// ResumeCampaign does nothing
+func (m *MockGoogleAdsClient) ResumeCampaign(ctx context.Context, platformCampaignID string) error {
+	return nil
+}
+
+// ScheduleCampaignPauseResume does nothing
+func (m *MockGoogleAdsClient) ScheduleCampaignPauseResume(ctx context.Context, tenantID, platformCampaignID, pauseCron, resumeCron string, timezone string) error {
+	return nil
+}
+
 // Test helper methods
 
 // GetDeployments returns all deployments
@@ -93,6 +170,25 @@ func (m *MockGoogleAdsClient) GetDeployments() []models.DeploymentRequest {
 	return deployments
 }
 
+// GetCampaignBiddings returns the campaign bidding of each deployment, nil for
+// the campaign default
+func (m *MockGoogleAdsClient) GetCampaignBiddings() []*googleads.CampaignBidding {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	biddings := make([]*googleads.CampaignBidding, len(m.campaignBiddings))
+	copy(biddings, m.campaignBiddings)
+	return biddings
+}
+
+// SetBiddingStrategies sets the bidding strategy of the campaigns of each content type
+func (m *MockGoogleAdsClient) SetBiddingStrategies(strategies config.BiddingStrategies) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.biddingStrategies = strategies
+}
+
 // SetShouldFailDeployment sets whether deployments should fail
 func (m *MockGoogleAdsClient) SetShouldFailDeployment(shouldFail bool) {
 	m.mu.Lock()
@@ -123,6 +219,7 @@ func (m *MockGoogleAdsClient) ClearDeployments() {
 	defer m.mu.Unlock()
 
 	m.deployments = make([]models.DeploymentRequest, 0)
+	m.campaignBiddings = nil
 }
 
 // MockMetaClient is a mock implementation of the Meta client
@@ -199,6 +296,65 @@ func (m *MockMetaClient) HealthCheck(ctx context.Context) error {
 
 // Test helper methods
 
+// Config returns an empty Meta configuration
+func (m *MockMetaClient) Config() *config.MetaConfig {
+	return &config.MetaConfig{}
+}
+
+// SetScheduler does nothing
+func (m *MockMetaClient) SetScheduler(worker *scheduler.SchedulerWorker) {}
+
+// SetRateLimiter does nothing
+func (m *MockMetaClient) SetRateLimiter(limiter *ratelimit.PlatformRateLimiter) {}
+
+// SetResponseCache does nothing
+func (m *MockMetaClient) SetResponseCache(responseCache *cache.PlatformResponseCache) {}
+
+// EstimateAdCost mocks the cost estimate of an ad
+func (m *MockMetaClient) EstimateAdCost(ctx context.Context, request *models.DeploymentRequest) (*models.CostEstimate, error) {
+	return &models.CostEstimate{EstimatedSpend: request.Metadata.Budget, Currency: "USD"}, nil
+}
+
+// FetchCampaignMetrics mocks fetching the metrics of an ad
+func (m *MockMetaClient) FetchCampaignMetrics(ctx context.Context, adID string, datePreset string) (*models.CampaignMetrics, error) {
+	return &models.CampaignMetrics{CampaignID: adID, Platform: models.PlatformMeta, FetchedAt: time.Now()}, nil
+}
+
+// GetAdReviewStatus mocks an approved ad
+func (m *MockMetaClient) GetAdReviewStatus(ctx context.Context, platformAdID string) (*models.AdReviewStatus, error) {
+	return &models.AdReviewStatus{Status: models.AdReviewStatusApproved}, nil
+}
+
+// DuplicateCampaign mocks duplicating a campaign
+func (m *MockMetaClient) DuplicateCampaign(ctx context.Context, sourceCampaignID string, newName string, newBudget float64) (string, error) {
+	return fmt.Sprintf("meta_campaign_%d", time.Now().UnixNano()), nil
+}
+
+// PauseAd does nothing
+func (m *MockMetaClient) PauseAd(ctx context.Context, adID string) error {
+	return nil
+}
+
+// RevokeCustomAudience does nothing
+func (m *MockMetaClient) RevokeCustomAudience(ctx context.Context, audienceID string) error {
+	return nil
+}
+
+// PauseCampaign does nothing
+func (m *MockMetaClient) PauseCampaign(ctx context.Context, platformCampaignID string) error {
+	return nil
+}
+
+// ResumeCampaign does nothing
+func (m *MockMetaClient) ResumeCampaign(ctx context.Context, platformCampaignID string) error {
+	return nil
+}
+
+// ScheduleCampaignPauseResume does nothing
+func (m *MockMetaClient) ScheduleCampaignPauseResume(ctx context.Context, tenantID, platformCampaignID, pauseCron, resumeCron string, timezone string) error {
+	return nil
+}
+
 // GetDeployments returns all deployments
 func (m *MockMetaClient) GetDeployments() []models.DeploymentRequest {
 	m.mu.RLock()
@@ -239,4 +395,352 @@ func (m *MockMetaClient) ClearDeployments() {
 	defer m.mu.Unlock()
 
 	m.deployments = make([]models.DeploymentRequest, 0)
-} 
\ No newline at end of file
+}
+
+// MockTikTokClient is a mock implementation of the TikTok client
+type MockTikTokClient struct {
+	mu                    sync.RWMutex
+	deployments           []models.DeploymentRequest
+	shouldFailDeployment  bool
+	shouldFailHealthCheck bool
+	deploymentDelay       time.Duration
+}
+
+// NewMockTikTokClient creates a new mock TikTok client
+func NewMockTikTokClient() *MockTikTokClient {
+	return &MockTikTokClient{
+		deployments: make([]models.DeploymentRequest, 0),
+	}
+}
+
+// DeployAsset mocks deploying an asset to TikTok
+func (m *MockTikTokClient) DeployAsset(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Simulate deployment delay
+	if m.deploymentDelay > 0 {
+		select {
+		case <-time.After(m.deploymentDelay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if m.shouldFailDeployment {
+		return &models.DeploymentResult{
+			AssetID:    request.AssetID,
+			Platform:   models.PlatformTikTok,
+			Status:     models.DeploymentStatusFailed,
+			Error:      "mock deployment failure",
+			DeployedAt: time.Now(),
+			Metrics: models.DeploymentMetrics{
+				Duration: m.deploymentDelay,
+			},
+		}, &MockError{Message: "mock deployment failure"}
+	}
+
+	m.deployments = append(m.deployments, *request)
+
+	return &models.DeploymentResult{
+		AssetID:     request.AssetID,
+		Platform:    models.PlatformTikTok,
+		Status:      models.DeploymentStatusSuccess,
+		PlatformID:  fmt.Sprintf("tiktok_%d", time.Now().Unix()),
+		PlatformURL: "https://ads.tiktok.com/i18n/perf/creative?aadvid=mock_advertiser",
+		DeployedAt:  time.Now(),
+		Metrics: models.DeploymentMetrics{
+			Duration:     m.deploymentDelay,
+			RetryCount:   0,
+			DataSent:     2048,
+			DataReceived: 1024,
+		},
+	}, nil
+}
+
+// HealthCheck mocks the health check
+func (m *MockTikTokClient) HealthCheck(ctx context.Context) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.shouldFailHealthCheck {
+		return &MockError{Message: "mock TikTok health check failed"}
+	}
+	return nil
+}
+
+// Test helper methods
+
+// GetDeployments returns all deployments
+func (m *MockTikTokClient) GetDeployments() []models.DeploymentRequest {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	deployments := make([]models.DeploymentRequest, len(m.deployments))
+	copy(deployments, m.deployments)
+	return deployments
+}
+
+// SetShouldFailDeployment sets whether deployments should fail
+func (m *MockTikTokClient) SetShouldFailDeployment(shouldFail bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.shouldFailDeployment = shouldFail
+}
+
+// SetShouldFailHealthCheck sets whether health checks should fail
+func (m *MockTikTokClient) SetShouldFailHealthCheck(shouldFail bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.shouldFailHealthCheck = shouldFail
+}
+
+// SetDeploymentDelay sets the deployment delay
+func (m *MockTikTokClient) SetDeploymentDelay(delay time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.deploymentDelay = delay
+}
+
+// ClearDeployments clears all deployments
+func (m *MockTikTokClient) ClearDeployments() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.deployments = make([]models.DeploymentRequest, 0)
+}
+
+// MockLinkedInClient is a mock implementation of the LinkedIn client
+type MockLinkedInClient struct {
+	mu                    sync.RWMutex
+	deployments           []models.DeploymentRequest
+	shouldFailDeployment  bool
+	shouldFailHealthCheck bool
+	deploymentDelay       time.Duration
+}
+
+// NewMockLinkedInClient creates a new mock LinkedIn client
+func NewMockLinkedInClient() *MockLinkedInClient {
+	return &MockLinkedInClient{
+		deployments: make([]models.DeploymentRequest, 0),
+	}
+}
+
+// DeployAsset mocks deploying an asset to LinkedIn
+func (m *MockLinkedInClient) DeployAsset(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Simulate deployment delay
+	if m.deploymentDelay > 0 {
+		select {
+		case <-time.After(m.deploymentDelay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if m.shouldFailDeployment {
+		return &models.DeploymentResult{
+			AssetID:    request.AssetID,
+			Platform:   models.PlatformLinkedIn,
+			Status:     models.DeploymentStatusFailed,
+			Error:      "mock deployment failure",
+			DeployedAt: time.Now(),
+			Metrics: models.DeploymentMetrics{
+				Duration: m.deploymentDelay,
+			},
+		}, &MockError{Message: "mock deployment failure"}
+	}
+
+	m.deployments = append(m.deployments, *request)
+
+	return &models.DeploymentResult{
+		AssetID:     request.AssetID,
+		Platform:    models.PlatformLinkedIn,
+		Status:      models.DeploymentStatusSuccess,
+		PlatformID:  fmt.Sprintf("urn:li:sponsoredCreative:%d", time.Now().Unix()),
+		PlatformURL: "https://www.linkedin.com/campaignmanager/accounts/mock_account/campaigns/mock_campaign/creatives",
+		DeployedAt:  time.Now(),
+		Metrics: models.DeploymentMetrics{
+			Duration:     m.deploymentDelay,
+			RetryCount:   0,
+			DataSent:     2048,
+			DataReceived: 1024,
+		},
+	}, nil
+}
+
+// HealthCheck mocks the health check
+func (m *MockLinkedInClient) HealthCheck(ctx context.Context) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.shouldFailHealthCheck {
+		return &MockError{Message: "mock LinkedIn health check failed"}
+	}
+	return nil
+}
+
+// Test helper methods
+
+// GetDeployments returns all deployments
+func (m *MockLinkedInClient) GetDeployments() []models.DeploymentRequest {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	deployments := make([]models.DeploymentRequest, len(m.deployments))
+	copy(deployments, m.deployments)
+	return deployments
+}
+
+// SetShouldFailDeployment sets whether deployments should fail
+func (m *MockLinkedInClient) SetShouldFailDeployment(shouldFail bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.shouldFailDeployment = shouldFail
+}
+
+// SetShouldFailHealthCheck sets whether health checks should fail
+func (m *MockLinkedInClient) SetShouldFailHealthCheck(shouldFail bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.shouldFailHealthCheck = shouldFail
+}
+
+// SetDeploymentDelay sets the deployment delay
+func (m *MockLinkedInClient) SetDeploymentDelay(delay time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.deploymentDelay = delay
+}
+
+// ClearDeployments clears all deployments
+func (m *MockLinkedInClient) ClearDeployments() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.deployments = make([]models.DeploymentRequest, 0)
+}
+
+// MockTwitterClient is a mock implementation of the Twitter client
+type MockTwitterClient struct {
+	mu                    sync.RWMutex
+	deployments           []models.DeploymentRequest
+	shouldFailDeployment  bool
+	shouldFailHealthCheck bool
+	deploymentDelay       time.Duration
+}
+
+// NewMockTwitterClient creates a new mock Twitter client
+func NewMockTwitterClient() *MockTwitterClient {
+	return &MockTwitterClient{
+		deployments: make([]models.DeploymentRequest, 0),
+	}
+}
+
+// DeployAsset mocks deploying an asset to Twitter
+func (m *MockTwitterClient) DeployAsset(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Simulate deployment delay
+	if m.deploymentDelay > 0 {
+		select {
+		case <-time.After(m.deploymentDelay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if m.shouldFailDeployment {
+		return &models.DeploymentResult{
+			AssetID:    request.AssetID,
+			Platform:   models.PlatformTwitter,
+			Status:     models.DeploymentStatusFailed,
+			Error:      "mock deployment failure",
+			DeployedAt: time.Now(),
+			Metrics: models.DeploymentMetrics{
+				Duration: m.deploymentDelay,
+			},
+		}, &MockError{Message: "mock deployment failure"}
+	}
+
+	m.deployments = append(m.deployments, *request)
+
+	return &models.DeploymentResult{
+		AssetID:     request.AssetID,
+		Platform:    models.PlatformTwitter,
+		Status:      models.DeploymentStatusSuccess,
+		PlatformID:  fmt.Sprintf("%d", time.Now().Unix()),
+		PlatformURL: "https://ads.twitter.com/ads_manager/mock_account/campaigns/mock_campaign",
+		DeployedAt:  time.Now(),
+		Metrics: models.DeploymentMetrics{
+			Duration:     m.deploymentDelay,
+			RetryCount:   0,
+			DataSent:     2048,
+			DataReceived: 1024,
+		},
+	}, nil
+}
+
+// HealthCheck mocks the health check
+func (m *MockTwitterClient) HealthCheck(ctx context.Context) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.shouldFailHealthCheck {
+		return &MockError{Message: "mock Twitter health check failed"}
+	}
+	return nil
+}
+
+// Test helper methods
+
+// GetDeployments returns all deployments
+func (m *MockTwitterClient) GetDeployments() []models.DeploymentRequest {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	deployments := make([]models.DeploymentRequest, len(m.deployments))
+	copy(deployments, m.deployments)
+	return deployments
+}
+
+// SetShouldFailDeployment sets whether deployments should fail
+func (m *MockTwitterClient) SetShouldFailDeployment(shouldFail bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.shouldFailDeployment = shouldFail
+}
+
+// SetShouldFailHealthCheck sets whether health checks should fail
+func (m *MockTwitterClient) SetShouldFailHealthCheck(shouldFail bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.shouldFailHealthCheck = shouldFail
+}
+
+// SetDeploymentDelay sets the deployment delay
+func (m *MockTwitterClient) SetDeploymentDelay(delay time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.deploymentDelay = delay
+}
+
+// ClearDeployments clears all deployments
+func (m *MockTwitterClient) ClearDeployments() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.deployments = make([]models.DeploymentRequest, 0)
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// CustomAudienceDeletionRequest asks to delete the custom audiences the
+// deployments of an asset target, for people who withdrew their consent
+type CustomAudienceDeletionRequest struct {
+	AssetID     uuid.UUID `json:"asset_id"`
+	RequestedBy string    `json:"requested_by"`
+}
+
+// CustomAudienceDeletionResult represents the reply to a custom audience deletion request
+type CustomAudienceDeletionResult struct {
+	Deleted bool   `json:"deleted"`
+	Error   string `json:"error,omitempty"`
+}
@@ -0,0 +1,17 @@
+package models
+
+// BillingStatus is the billing state of an advertising account. CurrentBalance is
+// the credit left under the account's spending limit. Accounts without a spending
+// limit are Unlimited and report no credit limit or balance.
+type BillingStatus struct {
+	Approved       bool    `json:"approved"`
+	Unlimited      bool    `json:"unlimited"`
+	CreditLimit    float64 `json:"credit_limit"`
+	CurrentBalance float64 `json:"current_balance"`
+	Currency       string  `json:"currency"`
+}
+
+// Covers returns true if the account can serve ads spending budget
+func (s *BillingStatus) Covers(budget float64) bool {
+	return s.Approved && (s.Unlimited || s.CurrentBalance >= budget)
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CampaignBudgetExceededEvent reports that a deployment was refused because its
+// daily budget is above the platform's cap
+type CampaignBudgetExceededEvent struct {
+	EventType string    `json:"event_type"`
+	AssetID   uuid.UUID `json:"asset_id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	Platform  Platform  `json:"platform"`
+	Budget    float64   `json:"budget"`
+	Cap       float64   `json:"cap"`
+	Timestamp time.Time `json:"timestamp"`
+}
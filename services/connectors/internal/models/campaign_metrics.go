@@ -0,0 +1,88 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrCampaignNotFound is returned when a platform campaign no longer exists
+var ErrCampaignNotFound = errors.New("campaign not found")
+
+// CampaignDeployment is a platform campaign an asset was deployed to, whose
+// performance metrics are polled while it is active. On Meta, whose metrics are
+// read per ad, the campaign ID is the ad's.
+type CampaignDeployment struct {
+	Platform           Platform  `json:"platform"`
+	PlatformCampaignID string    `json:"platform_campaign_id"`
+	AdGroupID          string    `json:"ad_group_id,omitempty"`
+	PlatformAdID       string    `json:"platform_ad_id"`
+	AudienceID         string    `json:"audience_id,omitempty"` // Meta custom audience the ad set targets
+	AssetID            uuid.UUID `json:"asset_id"`
+	ProjectID          uuid.UUID `json:"project_id"`
+	TenantID           string    `json:"tenant_id,omitempty"`
+	DeployedAt         time.Time `json:"deployed_at"`
+}
+
+// CampaignMetrics is the performance of a platform campaign over a date range.
+// Cost is in micros and revenue in units of the account currency; CTR is a
+// fraction of impressions.
+type CampaignMetrics struct {
+	CampaignID  string    `json:"campaign_id"`
+	Platform    Platform  `json:"platform"`
+	Impressions int64     `json:"impressions"`
+	Clicks      int64     `json:"clicks"`
+	CostMicros  int64     `json:"cost_micros"`
+	Conversions float64   `json:"conversions"`
+	CTR         float64   `json:"ctr"`
+	Revenue     float64   `json:"revenue"`
+	ROAS        float64   `json:"roas"`
+	StartDate   string    `json:"start_date"`
+	EndDate     string    `json:"end_date"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+// CampaignMetricsUpdatedEvent is published after the metrics of a deployed
+// campaign are fetched
+type CampaignMetricsUpdatedEvent struct {
+	EventType  string          `json:"event_type"`
+	ProjectID  uuid.UUID       `json:"project_id"`
+	AssetID    uuid.UUID       `json:"asset_id"`
+	Platform   Platform        `json:"platform"`
+	CampaignID string          `json:"campaign_id"`
+	Metrics    CampaignMetrics `json:"metrics"`
+	Timestamp  time.Time       `json:"timestamp"`
+}
+
+// AnomalySeverity is how far a campaign metric is from its rolling average
+type AnomalySeverity string
+
+const (
+	AnomalySeverityWarning  AnomalySeverity = "warning"
+	AnomalySeverityCritical AnomalySeverity = "critical"
+)
+
+// CampaignAnomaly is a campaign metric whose latest value is more standard
+// deviations away from its rolling average than the anomaly threshold
+type CampaignAnomaly struct {
+	CampaignID string          `json:"campaign_id"`
+	Platform   Platform        `json:"platform"`
+	AssetID    uuid.UUID       `json:"asset_id"`
+	ProjectID  uuid.UUID       `json:"project_id"`
+	Metric     string          `json:"metric"`
+	Value      float64         `json:"value"`
+	Mean       float64         `json:"mean"`
+	StdDev     float64         `json:"std_dev"`
+	ZScore     float64         `json:"z_score"`
+	Severity   AnomalySeverity `json:"severity"`
+	DetectedAt time.Time       `json:"detected_at"`
+}
+
+// CampaignPerformanceAlertEvent is published when a campaign metric becomes
+// anomalous
+type CampaignPerformanceAlertEvent struct {
+	EventType string          `json:"event_type"`
+	Anomaly   CampaignAnomaly `json:"anomaly"`
+	Timestamp time.Time       `json:"timestamp"`
+}
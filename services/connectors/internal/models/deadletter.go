@@ -0,0 +1,19 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeadLetter is an asset status changed event whose deployment failed on every
+// attempt, kept until it is replayed
+type DeadLetter struct {
+	ID            uuid.UUID       `json:"id"`
+	AssetID       uuid.UUID       `json:"asset_id"`
+	Event         json.RawMessage `json:"event"`
+	FailureReason string          `json:"failure_reason"`
+	RetryCount    int             `json:"retry_count"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,6 +26,9 @@ type Platform string
 const (
 	PlatformGoogleAds Platform = "google_ads"
 	PlatformMeta      Platform = "meta"
+	PlatformTikTok    Platform = "tiktok"
+	PlatformLinkedIn  Platform = "linkedin"
+	PlatformTwitter   Platform = "twitter"
 )
 
 // ContentType represents the type of content
@@ -42,6 +47,7 @@ type AssetStatusChangedEvent struct {
 	EventType   string      `json:"event_type"`
 	AssetID     uuid.UUID   `json:"asset_id"`
 	ProjectID   uuid.UUID   `json:"project_id"`
+	TenantID    string      `json:"tenant_id,omitempty"`
 	StrategyID  uuid.UUID   `json:"strategy_id"`
 	Status      AssetStatus `json:"status"`
 	PrevStatus  AssetStatus `json:"prev_status"`
@@ -50,6 +56,16 @@ type AssetStatusChangedEvent struct {
 	Content     string      `json:"content"`
 	Metadata    Metadata    `json:"metadata"`
 	Timestamp   time.Time   `json:"timestamp"`
+
+	// ScheduledAt is when an approved asset should go live, if not on approval
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+
+	// VariantGroup names the A/B test the asset is a variant in, if any
+	VariantGroup string `json:"variant_group,omitempty"`
+
+	// Mode is dry_run for approvals that only check what the deployment would
+	// create; empty means live
+	Mode DeploymentMode `json:"mode,omitempty"`
 }
 
 // Metadata holds additional asset information
@@ -61,6 +77,12 @@ type Metadata struct {
 	Keywords        []string   `json:"keywords"`
 	Demographics    Demographics `json:"demographics"`
 	CreativeSpecs   CreativeSpecs `json:"creative_specs"`
+
+	// TargetCPA, in units of the account currency, and TargetROAS, as a ratio of
+	// conversion value to spend, override the targets of the Google Ads bidding
+	// strategy of the deployment
+	TargetCPA  *float64 `json:"target_cpa,omitempty"`
+	TargetROAS *float64 `json:"target_roas,omitempty"`
 }
 
 // Demographics holds targeting demographics
@@ -71,30 +93,70 @@ type Demographics struct {
 	Locations   []string `json:"locations"`
 	Interests   []string `json:"interests"`
 	Behaviors   []string `json:"behaviors"`
+	// UseAdvantagePlus lets Meta find the audience with Advantage+ instead of
+	// the interests and behaviors above
+	UseAdvantagePlus bool `json:"use_advantage_plus"`
+	// CustomAudienceEmailHashes are the hex-encoded SHA-256 hashes of the
+	// trimmed, lowercased emails of the customers Meta ads target as a custom
+	// audience instead of by interests. Emails are hashed before they are published.
+	CustomAudienceEmailHashes []string `json:"custom_audience_email_hashes,omitempty"`
 }
 
 // CreativeSpecs holds creative specifications
 type CreativeSpecs struct {
 	ImageURL     string            `json:"image_url"`
+	LogoURL      string            `json:"logo_url"`
 	VideoURL     string            `json:"video_url"`
 	Headline     string            `json:"headline"`
 	Description  string            `json:"description"`
 	CallToAction string            `json:"call_to_action"`
 	LandingURL   string            `json:"landing_url"`
+	BusinessName string            `json:"business_name"`
 	Dimensions   map[string]string `json:"dimensions"`
+
+	// TweetID is the tweet promoted by Twitter deployments
+	TweetID string `json:"tweet_id,omitempty"`
+
+	// VariantID and VariantLabel identify the creative among the variants of
+	// an A/B test, such as "b" and "Headline B"
+	VariantID    string `json:"variant_id,omitempty"`
+	VariantLabel string `json:"variant_label,omitempty"`
+}
+
+// VariantName returns name followed by the variant label, so that the variants
+// of an A/B test can be told apart in the ad platforms
+func (s CreativeSpecs) VariantName(name string) string {
+	if s.VariantLabel == "" {
+		return name
+	}
+	return fmt.Sprintf("%s [%s]", name, s.VariantLabel)
 }
 
 // DeploymentRequest represents a deployment request
 type DeploymentRequest struct {
 	AssetID     uuid.UUID   `json:"asset_id"`
 	ProjectID   uuid.UUID   `json:"project_id"`
+	TenantID    string      `json:"tenant_id,omitempty"`
 	StrategyID  uuid.UUID   `json:"strategy_id"`
 	Platform    Platform    `json:"platform"`
 	ContentType ContentType `json:"content_type"`
 	Title       string      `json:"title"`
 	Content     string      `json:"content"`
 	Metadata    Metadata    `json:"metadata"`
+	Mode        DeploymentMode `json:"mode,omitempty"`
 	CreatedAt   time.Time   `json:"created_at"`
+
+	// ScheduledAt is the start time of the campaign, if the deployment was scheduled
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+
+	// VariantGroup names the A/B test the asset is a variant in, if any
+	VariantGroup string `json:"variant_group,omitempty"`
+}
+
+// IsDryRun reports whether the request only checks the deployment, without
+// creating anything on the platform
+func (r *DeploymentRequest) IsDryRun() bool {
+	return r.Mode == DeploymentModeDryRun
 }
 
 // DeploymentResult represents the result of a deployment
@@ -104,9 +166,43 @@ type DeploymentResult struct {
 	Status        DeploymentStatus `json:"status"`
 	PlatformID    string          `json:"platform_id"`
 	PlatformURL   string          `json:"platform_url"`
+	AdGroupID     string          `json:"ad_group_id,omitempty"` // Google Ads search ad group holding the ad's keywords
+	CampaignID    string          `json:"campaign_id,omitempty"` // Google Ads campaign holding the ad
+	AudienceID    string          `json:"audience_id,omitempty"` // Meta custom audience the ad set targets
 	Error         string          `json:"error,omitempty"`
 	DeployedAt    time.Time       `json:"deployed_at"`
 	Metrics       DeploymentMetrics `json:"metrics"`
+	CostEstimate  *CostEstimate   `json:"cost_estimate,omitempty"`
+
+	// ValidationErrors are the problems with the creative of a deployment that
+	// failed validation before reaching the platform
+	ValidationErrors []ValidationError `json:"validation_errors,omitempty"`
+}
+
+// ValidationError is a problem with a field of the creative of a deployment
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Message)
+}
+
+// APIError is an error response of a platform API
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API call failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// Transient tells whether the platform, rather than the call, is at fault, so
+// that the same call may succeed later: server errors and throttling
+func (e *APIError) Transient() bool {
+	return e.StatusCode >= http.StatusInternalServerError || e.StatusCode == http.StatusTooManyRequests
 }
 
 // DeploymentStatus represents the status of a deployment
@@ -118,8 +214,22 @@ const (
 	DeploymentStatusSuccess   DeploymentStatus = "success"
 	DeploymentStatusFailed    DeploymentStatus = "failed"
 	DeploymentStatusCancelled DeploymentStatus = "cancelled"
+	DeploymentStatusEstimated DeploymentStatus = "estimated"
 )
 
+// CostEstimate holds the projected weekly delivery of a deployment
+type CostEstimate struct {
+	EstimatedImpressions int64   `json:"estimated_impressions"`
+	EstimatedClicks      int64   `json:"estimated_clicks"`
+	EstimatedSpend       float64 `json:"estimated_spend"`
+	Currency             string  `json:"currency"`
+	Confidence           float64 `json:"confidence"`
+
+	// EstimatedAudienceSize is the monthly active audience of the targeting,
+	// where the platform estimates it
+	EstimatedAudienceSize int64 `json:"estimated_audience_size,omitempty"`
+}
+
 // DeploymentMetrics holds deployment metrics
 type DeploymentMetrics struct {
 	Duration    time.Duration `json:"duration"`
@@ -174,4 +284,65 @@ type DeploymentStatusChangedEvent struct {
 	PrevStatus       AssetStatus      `json:"prev_status"`
 	DeploymentResult DeploymentResult `json:"deployment_result"`
 	Timestamp        time.Time        `json:"timestamp"`
-} 
\ No newline at end of file
+
+	// VariantGroup and VariantID identify the deployed variant of an A/B test
+	VariantGroup string `json:"variant_group,omitempty"`
+	VariantID    string `json:"variant_id,omitempty"`
+} 
+// CampaignDuplicationRequest represents a request to clone a deployed campaign
+type CampaignDuplicationRequest struct {
+	AssetID          uuid.UUID `json:"asset_id"`
+	TenantID         string    `json:"tenant_id,omitempty"`
+	Platform         Platform  `json:"platform"`
+	SourceCampaignID string    `json:"source_campaign_id"`
+	NewName          string    `json:"new_name"`
+	NewBudget        float64   `json:"new_budget"`
+}
+
+// CampaignDuplicationResult represents the reply to a campaign duplication request
+type CampaignDuplicationResult struct {
+	SourceCampaignID string `json:"source_campaign_id"`
+	CampaignID       string `json:"campaign_id,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// PlatformCredentialsRequest represents a request to store a tenant's platform
+// credentials. The credentials are sealed with the transport key shared by the
+// BFF and the connectors service, so they never cross NATS in plain text.
+type PlatformCredentialsRequest struct {
+	TenantID             string   `json:"tenant_id"`
+	Platform             Platform `json:"platform"`
+	EncryptedCredentials string   `json:"encrypted_credentials"`
+}
+
+// PlatformCredentialsResult represents the reply to a platform credentials request
+type PlatformCredentialsResult struct {
+	Error string `json:"error,omitempty"`
+}
+
+// SuspiciousLoginEvent represents the NATS event for a login a client detected
+// from a device it does not recognize
+type SuspiciousLoginEvent struct {
+	EventType string    `json:"event_type"`
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	Device    string    `json:"device,omitempty"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	Country   string    `json:"country,omitempty"`
+	City      string    `json:"city,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// AssetSLABreachEvent represents the NATS event for assets past their review SLA
+type AssetSLABreachEvent struct {
+	EventType       string    `json:"event_type"`
+	AssetID         uuid.UUID `json:"asset_id"`
+	AssetName       string    `json:"asset_name"`
+	BoardID         uuid.UUID `json:"board_id"`
+	ProjectID       uuid.UUID `json:"project_id"`
+	ReviewStartedAt time.Time `json:"review_started_at"`
+	SLAHours        int       `json:"sla_hours"`
+	OverdueHours    float64   `json:"overdue_hours"`
+	Timestamp       time.Time `json:"timestamp"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KeywordQualityScore is the Google Ads quality score of a keyword, from 1 to 10
+type KeywordQualityScore struct {
+	KeywordID string    `json:"keyword_id"`
+	AdGroupID string    `json:"ad_group_id"`
+	Keyword   string    `json:"keyword"`
+	Score     int       `json:"score"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// KeywordQualityScoreFetch asks for the quality scores of the keywords of a
+// deployed asset's ad group
+type KeywordQualityScoreFetch struct {
+	AssetID   uuid.UUID `json:"asset_id"`
+	TenantID  string    `json:"tenant_id,omitempty"`
+	AdGroupID string    `json:"ad_group_id"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ad review outcomes reported in AdReviewStatus.Status
+const (
+	AdReviewStatusPending  = "pending"
+	AdReviewStatusApproved = "approved"
+	AdReviewStatusRejected = "rejected"
+)
+
+// AdReviewStatus is the outcome of a platform's policy review of an ad
+type AdReviewStatus struct {
+	Status             string   `json:"status"`
+	DisapprovalReasons []string `json:"disapproval_reasons,omitempty"`
+	PolicyViolations   []string `json:"policy_violations,omitempty"`
+}
+
+// Rejected returns true if the platform disapproved the ad
+func (s *AdReviewStatus) Rejected() bool {
+	return s.Status == AdReviewStatusRejected
+}
+
+// Reason summarizes why the ad was rejected
+func (s *AdReviewStatus) Reason() string {
+	if len(s.DisapprovalReasons) > 0 {
+		return strings.Join(s.DisapprovalReasons, "; ")
+	}
+	if len(s.PolicyViolations) > 0 {
+		return "Policy violations: " + strings.Join(s.PolicyViolations, ", ")
+	}
+	return "Disapproved by the platform's ad review"
+}
+
+// AdReviewCheck asks for the review status of a deployed ad
+type AdReviewCheck struct {
+	AssetID      uuid.UUID `json:"asset_id"`
+	ProjectID    uuid.UUID `json:"project_id"`
+	TenantID     string    `json:"tenant_id,omitempty"`
+	Platform     Platform  `json:"platform"`
+	PlatformAdID string    `json:"platform_ad_id"`
+	Attempt      int       `json:"attempt"`
+}
+
+// AssetPlatformRejectedEvent reports that a platform disapproved a deployed asset's ad
+type AssetPlatformRejectedEvent struct {
+	EventType          string    `json:"event_type"`
+	AssetID            uuid.UUID `json:"asset_id"`
+	ProjectID          uuid.UUID `json:"project_id"`
+	Platform           Platform  `json:"platform"`
+	PlatformAdID       string    `json:"platform_ad_id"`
+	Reason             string    `json:"reason"`
+	DisapprovalReasons []string  `json:"disapproval_reasons,omitempty"`
+	PolicyViolations   []string  `json:"policy_violations,omitempty"`
+	Timestamp          time.Time `json:"timestamp"`
+}
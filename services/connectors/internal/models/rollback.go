@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeploymentRollbackRequest asks to stop the ad of a deployed asset on a platform
+type DeploymentRollbackRequest struct {
+	AssetID     uuid.UUID `json:"asset_id"`
+	Platform    Platform  `json:"platform"`
+	RequestedBy string    `json:"requested_by"`
+	Reason      string    `json:"reason,omitempty"`
+}
+
+// DeploymentRollbackResult represents the reply to a deployment rollback request
+type DeploymentRollbackResult struct {
+	AssetID            uuid.UUID `json:"asset_id"`
+	Platform           Platform  `json:"platform"`
+	PlatformCampaignID string    `json:"platform_campaign_id,omitempty"`
+	PlatformAdID       string    `json:"platform_ad_id,omitempty"`
+	RolledBackAt       time.Time `json:"rolled_back_at,omitempty"`
+	Error              string    `json:"error,omitempty"`
+}
+
+// AssetRolledBackEvent reports that the ad of a deployed asset was paused on a
+// platform at a user's request
+type AssetRolledBackEvent struct {
+	EventType          string    `json:"event_type"`
+	AssetID            uuid.UUID `json:"asset_id"`
+	ProjectID          uuid.UUID `json:"project_id"`
+	Platform           Platform  `json:"platform"`
+	PlatformCampaignID string    `json:"platform_campaign_id"`
+	PlatformAdID       string    `json:"platform_ad_id"`
+	RolledBackBy       string    `json:"rolled_back_by"`
+	Reason             string    `json:"reason,omitempty"`
+	Timestamp          time.Time `json:"timestamp"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CampaignSchedule pauses and resumes a platform campaign on cron expressions
+// evaluated in the schedule's timezone
+type CampaignSchedule struct {
+	ID                 uuid.UUID `json:"id"`
+	TenantID           string    `json:"tenant_id,omitempty"`
+	Platform           Platform  `json:"platform"`
+	PlatformCampaignID string    `json:"platform_campaign_id"`
+	PauseCron          string    `json:"pause_cron"`
+	ResumeCron         string    `json:"resume_cron"`
+	Timezone           string    `json:"timezone"`
+	CreatedAt          time.Time `json:"created_at"`
+
+	// NextPauseAt and NextResumeAt are the next times the schedule fires, zero
+	// until a scheduler first runs it
+	NextPauseAt  time.Time `json:"next_pause_at,omitempty"`
+	NextResumeAt time.Time `json:"next_resume_at,omitempty"`
+}
+
+// CampaignScheduleRequest represents a request to schedule a campaign's pauses and resumes
+type CampaignScheduleRequest struct {
+	TenantID           string   `json:"tenant_id,omitempty"`
+	Platform           Platform `json:"platform"`
+	PlatformCampaignID string   `json:"platform_campaign_id"`
+	PauseCron          string   `json:"pause_cron"`
+	ResumeCron         string   `json:"resume_cron"`
+	Timezone           string   `json:"timezone"`
+}
+
+// CampaignScheduleDeletionRequest represents a request to delete a campaign schedule
+type CampaignScheduleDeletionRequest struct {
+	ID       uuid.UUID `json:"id"`
+	TenantID string    `json:"tenant_id,omitempty"`
+}
+
+// CampaignScheduleResult represents the reply to a campaign schedule request
+type CampaignScheduleResult struct {
+	Schedule *CampaignSchedule `json:"schedule,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
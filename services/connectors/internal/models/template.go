@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeploymentTemplate represents a reusable deployment configuration for a platform
+type DeploymentTemplate struct {
+	ID          uuid.UUID   `json:"id"`
+	Name        string      `json:"name"`
+	OwnerID     uuid.UUID   `json:"owner_id"`
+	Platform    Platform    `json:"platform"`
+	ContentType ContentType `json:"content_type"`
+	Metadata    Metadata    `json:"metadata"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// TemplateDeploymentRequest represents a request to deploy an asset with a deployment template
+type TemplateDeploymentRequest struct {
+	Asset    AssetStatusChangedEvent `json:"asset"`
+	Template DeploymentTemplate      `json:"template"`
+}
+
+// Apply merges the template into an asset's event. Fields set on the asset take
+// priority and the template fills in the rest, except for the platform and content
+// type, which always come from the template.
+func (t *DeploymentTemplate) Apply(asset AssetStatusChangedEvent) AssetStatusChangedEvent {
+	merged := asset
+	merged.ContentType = t.ContentType
+	merged.Metadata = mergeMetadata(t.Metadata, asset.Metadata)
+	merged.Metadata.Platforms = []Platform{t.Platform}
+	return merged
+}
+
+// mergeMetadata returns base with every field set in override replaced
+func mergeMetadata(base, override Metadata) Metadata {
+	merged := base
+
+	merged.TargetAudience = mergeString(base.TargetAudience, override.TargetAudience)
+	merged.CampaignType = mergeString(base.CampaignType, override.CampaignType)
+	if override.Budget != 0 {
+		merged.Budget = override.Budget
+	}
+	merged.Keywords = mergeStrings(base.Keywords, override.Keywords)
+
+	merged.Demographics = Demographics{
+		AgeMin:    mergeInt(base.Demographics.AgeMin, override.Demographics.AgeMin),
+		AgeMax:    mergeInt(base.Demographics.AgeMax, override.Demographics.AgeMax),
+		Genders:   mergeStrings(base.Demographics.Genders, override.Demographics.Genders),
+		Locations: mergeStrings(base.Demographics.Locations, override.Demographics.Locations),
+		Interests: mergeStrings(base.Demographics.Interests, override.Demographics.Interests),
+		Behaviors: mergeStrings(base.Demographics.Behaviors, override.Demographics.Behaviors),
+
+		UseAdvantagePlus: base.Demographics.UseAdvantagePlus || override.Demographics.UseAdvantagePlus,
+	}
+
+	specs := base.CreativeSpecs
+	specs.ImageURL = mergeString(specs.ImageURL, override.CreativeSpecs.ImageURL)
+	specs.LogoURL = mergeString(specs.LogoURL, override.CreativeSpecs.LogoURL)
+	specs.VideoURL = mergeString(specs.VideoURL, override.CreativeSpecs.VideoURL)
+	specs.Headline = mergeString(specs.Headline, override.CreativeSpecs.Headline)
+	specs.Description = mergeString(specs.Description, override.CreativeSpecs.Description)
+	specs.CallToAction = mergeString(specs.CallToAction, override.CreativeSpecs.CallToAction)
+	specs.LandingURL = mergeString(specs.LandingURL, override.CreativeSpecs.LandingURL)
+	specs.BusinessName = mergeString(specs.BusinessName, override.CreativeSpecs.BusinessName)
+	specs.VariantID = mergeString(specs.VariantID, override.CreativeSpecs.VariantID)
+	specs.VariantLabel = mergeString(specs.VariantLabel, override.CreativeSpecs.VariantLabel)
+	if len(override.CreativeSpecs.Dimensions) > 0 {
+		specs.Dimensions = make(map[string]string, len(base.CreativeSpecs.Dimensions)+len(override.CreativeSpecs.Dimensions))
+		for key, value := range base.CreativeSpecs.Dimensions {
+			specs.Dimensions[key] = value
+		}
+		for key, value := range override.CreativeSpecs.Dimensions {
+			specs.Dimensions[key] = value
+		}
+	}
+	merged.CreativeSpecs = specs
+
+	return merged
+}
+
+func mergeString(base, override string) string {
+	if override != "" {
+		return override
+	}
+	return base
+}
+
+func mergeInt(base, override int) int {
+	if override != 0 {
+		return override
+	}
+	return base
+}
+
+func mergeStrings(base, override []string) []string {
+	if len(override) > 0 {
+		return override
+	}
+	return base
+}
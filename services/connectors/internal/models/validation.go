@@ -0,0 +1,52 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// DeploymentMode tells whether a deployment creates campaigns on the platforms
+// or only checks what would be created
+type DeploymentMode string
+
+const (
+	DeploymentModeLive   DeploymentMode = "live"
+	DeploymentModeDryRun DeploymentMode = "dry_run"
+)
+
+// ValidationReport is the outcome of a dry-run deployment of an asset to a
+// platform: whether a live deployment would get past each check, and what it
+// would reach
+type ValidationReport struct {
+	AssetID  uuid.UUID `json:"asset_id"`
+	Platform Platform  `json:"platform"`
+	Valid    bool      `json:"valid"`
+
+	CredentialsValid  bool `json:"credentials_valid"`
+	CreativeComplete  bool `json:"creative_complete"`
+	BudgetWithinRange bool `json:"budget_within_range"`
+	TargetingValid    bool `json:"targeting_valid"`
+
+	// EstimatedAudienceSize is the number of people the targeting reaches,
+	// where the platform estimates it
+	EstimatedAudienceSize int64         `json:"estimated_audience_size,omitempty"`
+	CostEstimate          *CostEstimate `json:"cost_estimate,omitempty"`
+
+	// Errors are the problems found by the checks; EstimateError is why the
+	// platform could not estimate the deployment, which does not make it invalid
+	Errors        []ValidationError `json:"errors,omitempty"`
+	EstimateError string            `json:"estimate_error,omitempty"`
+}
+
+// DeploymentValidationReport holds the validation report of every platform of a
+// dry-run deployment of an asset
+type DeploymentValidationReport struct {
+	AssetID   uuid.UUID          `json:"asset_id"`
+	Valid     bool               `json:"valid"`
+	Platforms []ValidationReport `json:"platforms"`
+}
+
+// DeploymentDryRunResult represents the reply to a dry-run deployment request
+type DeploymentDryRunResult struct {
+	Report *DeploymentValidationReport `json:"report,omitempty"`
+	Error  string                      `json:"error,omitempty"`
+}
@@ -7,16 +7,20 @@ import (
 	"time"
 
 	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/metrics"
 	"github.com/zamc/connectors/internal/models"
 )
 
 // Client represents a NATS client
 type Client struct {
-	conn   *nats.Conn
-	config *config.NATSConfig
-	logger *logrus.Logger
+	conn      *nats.Conn
+	js        nats.JetStreamContext
+	transport *CompressionTransport
+	config    *config.NATSConfig
+	logger    *logrus.Logger
 }
 
 // EventHandler defines the interface for handling events
@@ -24,6 +28,57 @@ type EventHandler interface {
 	HandleAssetStatusChanged(ctx context.Context, event *models.AssetStatusChangedEvent) error
 }
 
+// CampaignDuplicationHandler defines the interface for handling campaign duplication requests
+type CampaignDuplicationHandler interface {
+	DuplicateCampaign(ctx context.Context, request *models.CampaignDuplicationRequest) (*models.CampaignDuplicationResult, error)
+}
+
+// DeploymentEstimateHandler defines the interface for handling deployment cost estimate requests
+type DeploymentEstimateHandler interface {
+	EstimateDeployment(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error)
+}
+
+// DeploymentDryRunHandler defines the interface for handling dry-run deployment requests
+type DeploymentDryRunHandler interface {
+	DryRunDeployment(ctx context.Context, event *models.AssetStatusChangedEvent) (*models.DeploymentValidationReport, error)
+}
+
+// TemplateDeploymentHandler defines the interface for handling template deployment requests
+type TemplateDeploymentHandler interface {
+	DeployAssetFromTemplate(ctx context.Context, request *models.TemplateDeploymentRequest) (*models.DeploymentResult, error)
+}
+
+// PlatformCredentialsHandler defines the interface for handling platform credentials requests
+type PlatformCredentialsHandler interface {
+	StorePlatformCredentials(ctx context.Context, request *models.PlatformCredentialsRequest) error
+}
+
+// CampaignScheduleHandler defines the interface for handling campaign schedule requests
+type CampaignScheduleHandler interface {
+	CreateCampaignSchedule(ctx context.Context, request *models.CampaignScheduleRequest) (*models.CampaignSchedule, error)
+	DeleteCampaignSchedule(ctx context.Context, request *models.CampaignScheduleDeletionRequest) error
+}
+
+// DeploymentRollbackHandler defines the interface for handling deployment rollback requests
+type DeploymentRollbackHandler interface {
+	RollbackDeployment(ctx context.Context, request *models.DeploymentRollbackRequest) (*models.DeploymentRollbackResult, error)
+}
+
+// CustomAudienceDeletionHandler defines the interface for handling custom audience deletion requests
+type CustomAudienceDeletionHandler interface {
+	DeleteCustomAudience(ctx context.Context, request *models.CustomAudienceDeletionRequest) (bool, error)
+}
+
+// SLABreachHandler defines the interface for handling asset SLA breach events
+type SLABreachHandler interface {
+	HandleAssetSLABreach(ctx context.Context, event *models.AssetSLABreachEvent) error
+}
+
+// SuspiciousLoginHandler defines the interface for handling suspicious login events
+type SuspiciousLoginHandler interface {
+	HandleSuspiciousLogin(ctx context.Context, event *models.SuspiciousLoginEvent) error
+}
+
 // NewClient creates a new NATS client
 func NewClient(cfg *config.NATSConfig, logger *logrus.Logger) (*Client, error) {
 	conn, err := nats.Connect(cfg.URL,
@@ -45,37 +100,58 @@ func NewClient(cfg *config.NATSConfig, logger *logrus.Logger) (*Client, error) {
 
 	logger.WithField("url", cfg.URL).Info("Connected to NATS")
 
-	return &Client{
-		conn:   conn,
-		config: cfg,
-		logger: logger,
-	}, nil
-}
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to get JetStream context: %w", err)
+	}
 
-// SubscribeToAssetStatusChanged subscribes to asset status changed events
-func (c *Client) SubscribeToAssetStatusChanged(ctx context.Context, handler EventHandler) error {
-	subject := fmt.Sprintf("%s.events.asset.status_changed", c.config.SubjectPrefix)
-	
-	subscription, err := c.conn.QueueSubscribe(subject, c.config.QueueGroup, func(msg *nats.Msg) {
-		c.handleAssetStatusChangedMessage(ctx, msg, handler)
-	})
+	transport, err := NewCompressionTransport(conn, cfg)
 	if err != nil {
-		return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+		conn.Close()
+		return nil, err
 	}
 
-	c.logger.WithFields(logrus.Fields{
-		"subject":     subject,
-		"queue_group": c.config.QueueGroup,
-	}).Info("Subscribed to asset status changed events")
+	client := &Client{
+		conn:      conn,
+		js:        js,
+		transport: transport,
+		config:    cfg,
+		logger:    logger,
+	}
 
-	// Wait for context cancellation
-	<-ctx.Done()
-	
-	if err := subscription.Unsubscribe(); err != nil {
-		c.logger.WithError(err).Error("Failed to unsubscribe from asset status changed events")
+	if err := client.setUpEventStream(); err != nil {
+		conn.Close()
+		return nil, err
 	}
 
-	return nil
+	if err := client.setUpDeadLetterStream(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// Asset status changed events are kept from the start, even before an
+	// instance subscribes to them
+	subject := client.assetStatusChangedSubject()
+	if err := client.EnsureDurableConsumer(EventsStream, client.durableName(subject), subject); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// SubscribeToAssetStatusChanged subscribes to asset status changed events. Events
+// are delivered until they have been handled, including those published while the
+// service was stopped. Up to NATSConfig.MaxConcurrentDeployments events are
+// handled at once, so that their deployments wait in the deployment queue by
+// priority rather than on the subscription in the order they were published.
+func (c *Client) SubscribeToAssetStatusChanged(ctx context.Context, handler EventHandler) error {
+	subject := c.assetStatusChangedSubject()
+
+	return c.subscribeEventsConcurrently(ctx, subject, c.maxConcurrentDeployments(), func(ctx context.Context, msg *nats.Msg) {
+		c.handleAssetStatusChangedMessage(ctx, msg, handler)
+	})
 }
 
 // handleAssetStatusChangedMessage handles incoming asset status changed messages
@@ -83,8 +159,16 @@ func (c *Client) handleAssetStatusChangedMessage(ctx context.Context, msg *nats.
 	logger := c.logger.WithField("subject", msg.Subject)
 
 	var event models.AssetStatusChangedEvent
-	if err := json.Unmarshal(msg.Data, &event); err != nil {
+	data, err := DecodeData(msg)
+	if err == nil {
+		err = json.Unmarshal(data, &event)
+	}
+	if err != nil {
 		logger.WithError(err).Error("Failed to unmarshal asset status changed event")
+		// Delivering the event again would not make it readable
+		if err := msg.Term(); err != nil {
+			logger.WithError(err).Error("Failed to terminate message")
+		}
 		return
 	}
 
@@ -98,14 +182,24 @@ func (c *Client) handleAssetStatusChangedMessage(ctx context.Context, msg *nats.
 	// Only process approved assets
 	if event.Status != models.AssetStatusApproved {
 		logger.Debug("Ignoring non-approved asset status change")
+		if err := msg.Ack(); err != nil {
+			logger.WithError(err).Error("Failed to acknowledge message")
+		}
 		return
 	}
 
 	logger.Info("Processing approved asset for deployment")
 
-	if err := handler.HandleAssetStatusChanged(ctx, &event); err != nil {
+	stopProgress := c.reportProgress(msg)
+	err = handler.HandleAssetStatusChanged(ctx, &event)
+	stopProgress()
+
+	if err != nil {
 		logger.WithError(err).Error("Failed to handle asset status changed event")
-		// Don't acknowledge the message so it can be retried
+		// Have the server deliver the event again
+		if err := msg.Nak(); err != nil {
+			logger.WithError(err).Error("Failed to negatively acknowledge message")
+		}
 		return
 	}
 
@@ -115,6 +209,527 @@ func (c *Client) handleAssetStatusChangedMessage(ctx context.Context, msg *nats.
 	}
 }
 
+// SubscribeToCampaignDuplicationRequests serves campaign duplication requests sent by the BFF
+func (c *Client) SubscribeToCampaignDuplicationRequests(ctx context.Context, handler CampaignDuplicationHandler) error {
+	subject := fmt.Sprintf("%s.commands.campaign.duplicate", c.config.SubjectPrefix)
+
+	subscription, err := c.queueSubscribe(ctx, subject, func(ctx context.Context, msg *nats.Msg) {
+		c.handleCampaignDuplicationMessage(ctx, msg, handler)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":     subject,
+		"queue_group": c.config.QueueGroup,
+	}).Info("Subscribed to campaign duplication requests")
+
+	// Wait for context cancellation
+	<-ctx.Done()
+
+	if err := subscription.Unsubscribe(); err != nil {
+		c.logger.WithError(err).Error("Failed to unsubscribe from campaign duplication requests")
+	}
+
+	return nil
+}
+
+// handleCampaignDuplicationMessage handles a campaign duplication request and replies with the result
+func (c *Client) handleCampaignDuplicationMessage(ctx context.Context, msg *nats.Msg, handler CampaignDuplicationHandler) {
+	logger := c.logger.WithField("subject", msg.Subject)
+
+	var request models.CampaignDuplicationRequest
+	result := &models.CampaignDuplicationResult{}
+
+	if err := json.Unmarshal(msg.Data, &request); err != nil {
+		logger.WithError(err).Error("Failed to unmarshal campaign duplication request")
+		result.Error = "invalid campaign duplication request"
+	} else {
+		result.SourceCampaignID = request.SourceCampaignID
+		duplicated, err := handler.DuplicateCampaign(ctx, &request)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result = duplicated
+		}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		logger.WithError(err).Error("Failed to marshal campaign duplication result")
+		return
+	}
+
+	if err := msg.Respond(data); err != nil {
+		logger.WithError(err).Error("Failed to respond to campaign duplication request")
+	}
+}
+
+// SubscribeToDeploymentRollbackRequests serves the deployment rollback requests
+// sent by the BFF, replying with the paused ad or the error
+func (c *Client) SubscribeToDeploymentRollbackRequests(ctx context.Context, handler DeploymentRollbackHandler) error {
+	subject := fmt.Sprintf("%s.commands.deployment.rollback", c.config.SubjectPrefix)
+
+	subscription, err := c.queueSubscribe(ctx, subject, func(ctx context.Context, msg *nats.Msg) {
+		c.handleDeploymentRollbackMessage(ctx, msg, handler)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":     subject,
+		"queue_group": c.config.QueueGroup,
+	}).Info("Subscribed to deployment rollback requests")
+
+	// Wait for context cancellation
+	<-ctx.Done()
+
+	if err := subscription.Unsubscribe(); err != nil {
+		c.logger.WithError(err).Error("Failed to unsubscribe from deployment rollback requests")
+	}
+
+	return nil
+}
+
+// handleDeploymentRollbackMessage handles a deployment rollback request and replies with the result
+func (c *Client) handleDeploymentRollbackMessage(ctx context.Context, msg *nats.Msg, handler DeploymentRollbackHandler) {
+	logger := c.logger.WithField("subject", msg.Subject)
+
+	var request models.DeploymentRollbackRequest
+	result := &models.DeploymentRollbackResult{}
+
+	if err := json.Unmarshal(msg.Data, &request); err != nil {
+		logger.WithError(err).Error("Failed to unmarshal deployment rollback request")
+		result.Error = "invalid deployment rollback request"
+	} else {
+		result.AssetID = request.AssetID
+		result.Platform = request.Platform
+		rolledBack, err := handler.RollbackDeployment(ctx, &request)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result = rolledBack
+		}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		logger.WithError(err).Error("Failed to marshal deployment rollback result")
+		return
+	}
+
+	if err := msg.Respond(data); err != nil {
+		logger.WithError(err).Error("Failed to respond to deployment rollback request")
+	}
+}
+
+// SubscribeToCustomAudienceDeletionRequests serves the custom audience deletion
+// requests sent by the BFF, replying with whether any audience was deleted
+func (c *Client) SubscribeToCustomAudienceDeletionRequests(ctx context.Context, handler CustomAudienceDeletionHandler) error {
+	subject := fmt.Sprintf("%s.commands.audience.delete", c.config.SubjectPrefix)
+
+	subscription, err := c.queueSubscribe(ctx, subject, func(ctx context.Context, msg *nats.Msg) {
+		c.handleCustomAudienceDeletionMessage(ctx, msg, handler)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":     subject,
+		"queue_group": c.config.QueueGroup,
+	}).Info("Subscribed to custom audience deletion requests")
+
+	// Wait for context cancellation
+	<-ctx.Done()
+
+	if err := subscription.Unsubscribe(); err != nil {
+		c.logger.WithError(err).Error("Failed to unsubscribe from custom audience deletion requests")
+	}
+
+	return nil
+}
+
+// handleCustomAudienceDeletionMessage handles a custom audience deletion request and replies with the result
+func (c *Client) handleCustomAudienceDeletionMessage(ctx context.Context, msg *nats.Msg, handler CustomAudienceDeletionHandler) {
+	logger := c.logger.WithField("subject", msg.Subject)
+
+	var request models.CustomAudienceDeletionRequest
+	result := &models.CustomAudienceDeletionResult{}
+
+	if err := json.Unmarshal(msg.Data, &request); err != nil {
+		logger.WithError(err).Error("Failed to unmarshal custom audience deletion request")
+		result.Error = "invalid custom audience deletion request"
+	} else if deleted, err := handler.DeleteCustomAudience(ctx, &request); err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Deleted = deleted
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		logger.WithError(err).Error("Failed to marshal custom audience deletion result")
+		return
+	}
+
+	if err := msg.Respond(data); err != nil {
+		logger.WithError(err).Error("Failed to respond to custom audience deletion request")
+	}
+}
+
+// SubscribeToDeploymentEstimateRequests subscribes to dry-run deployment requests and
+// replies with the platform's cost estimate
+func (c *Client) SubscribeToDeploymentEstimateRequests(ctx context.Context, handler DeploymentEstimateHandler) error {
+	subject := fmt.Sprintf("%s.commands.deployment.estimate", c.config.SubjectPrefix)
+
+	subscription, err := c.queueSubscribe(ctx, subject, func(ctx context.Context, msg *nats.Msg) {
+		c.handleDeploymentEstimateMessage(ctx, msg, handler)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":     subject,
+		"queue_group": c.config.QueueGroup,
+	}).Info("Subscribed to deployment estimate requests")
+
+	// Wait for context cancellation
+	<-ctx.Done()
+
+	if err := subscription.Unsubscribe(); err != nil {
+		c.logger.WithError(err).Error("Failed to unsubscribe from deployment estimate requests")
+	}
+
+	return nil
+}
+
+// handleDeploymentEstimateMessage handles a single deployment estimate request
+func (c *Client) handleDeploymentEstimateMessage(ctx context.Context, msg *nats.Msg, handler DeploymentEstimateHandler) {
+	logger := c.logger.WithField("subject", msg.Subject)
+
+	var request models.DeploymentRequest
+	result := &models.DeploymentResult{Status: models.DeploymentStatusFailed}
+
+	if err := json.Unmarshal(msg.Data, &request); err != nil {
+		logger.WithError(err).Error("Failed to unmarshal deployment estimate request")
+		result.Error = "invalid deployment estimate request"
+	} else {
+		result.AssetID = request.AssetID
+		result.Platform = request.Platform
+		estimated, err := handler.EstimateDeployment(ctx, &request)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result = estimated
+		}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		logger.WithError(err).Error("Failed to marshal deployment estimate result")
+		return
+	}
+
+	if err := msg.Respond(data); err != nil {
+		logger.WithError(err).Error("Failed to respond to deployment estimate request")
+	}
+}
+
+// SubscribeToDeploymentDryRunRequests subscribes to dry-run approvals of assets and
+// replies with the validation report of their deployment
+func (c *Client) SubscribeToDeploymentDryRunRequests(ctx context.Context, handler DeploymentDryRunHandler) error {
+	subject := fmt.Sprintf("%s.commands.deployment.dry_run", c.config.SubjectPrefix)
+
+	subscription, err := c.queueSubscribe(ctx, subject, func(ctx context.Context, msg *nats.Msg) {
+		c.handleDeploymentDryRunMessage(ctx, msg, handler)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":     subject,
+		"queue_group": c.config.QueueGroup,
+	}).Info("Subscribed to deployment dry-run requests")
+
+	// Wait for context cancellation
+	<-ctx.Done()
+
+	if err := subscription.Unsubscribe(); err != nil {
+		c.logger.WithError(err).Error("Failed to unsubscribe from deployment dry-run requests")
+	}
+
+	return nil
+}
+
+// handleDeploymentDryRunMessage handles a single dry-run deployment request
+func (c *Client) handleDeploymentDryRunMessage(ctx context.Context, msg *nats.Msg, handler DeploymentDryRunHandler) {
+	logger := c.logger.WithField("subject", msg.Subject)
+
+	var event models.AssetStatusChangedEvent
+	result := &models.DeploymentDryRunResult{}
+
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		logger.WithError(err).Error("Failed to unmarshal deployment dry-run request")
+		result.Error = "invalid deployment dry-run request"
+	} else {
+		event.Mode = models.DeploymentModeDryRun
+		if report, err := handler.DryRunDeployment(ctx, &event); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Report = report
+		}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		logger.WithError(err).Error("Failed to marshal deployment dry-run result")
+		return
+	}
+
+	if err := msg.Respond(data); err != nil {
+		logger.WithError(err).Error("Failed to respond to deployment dry-run request")
+	}
+}
+
+// SubscribeToTemplateDeploymentRequests serves template deployment requests sent by the BFF
+func (c *Client) SubscribeToTemplateDeploymentRequests(ctx context.Context, handler TemplateDeploymentHandler) error {
+	subject := fmt.Sprintf("%s.commands.deployment.template", c.config.SubjectPrefix)
+
+	subscription, err := c.queueSubscribe(ctx, subject, func(ctx context.Context, msg *nats.Msg) {
+		c.handleTemplateDeploymentMessage(ctx, msg, handler)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":     subject,
+		"queue_group": c.config.QueueGroup,
+	}).Info("Subscribed to template deployment requests")
+
+	// Wait for context cancellation
+	<-ctx.Done()
+
+	if err := subscription.Unsubscribe(); err != nil {
+		c.logger.WithError(err).Error("Failed to unsubscribe from template deployment requests")
+	}
+
+	return nil
+}
+
+// handleTemplateDeploymentMessage handles a template deployment request and replies with the result
+func (c *Client) handleTemplateDeploymentMessage(ctx context.Context, msg *nats.Msg, handler TemplateDeploymentHandler) {
+	logger := c.logger.WithField("subject", msg.Subject)
+
+	var request models.TemplateDeploymentRequest
+	result := &models.DeploymentResult{Status: models.DeploymentStatusFailed}
+
+	if err := json.Unmarshal(msg.Data, &request); err != nil {
+		logger.WithError(err).Error("Failed to unmarshal template deployment request")
+		result.Error = "invalid template deployment request"
+	} else {
+		result.AssetID = request.Asset.AssetID
+		result.Platform = request.Template.Platform
+		deployed, err := handler.DeployAssetFromTemplate(ctx, &request)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result = deployed
+		}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		logger.WithError(err).Error("Failed to marshal template deployment result")
+		return
+	}
+
+	if err := msg.Respond(data); err != nil {
+		logger.WithError(err).Error("Failed to respond to template deployment request")
+	}
+}
+
+// SubscribeToPlatformCredentialsRequests serves platform credentials requests sent by the BFF
+func (c *Client) SubscribeToPlatformCredentialsRequests(ctx context.Context, handler PlatformCredentialsHandler) error {
+	subject := fmt.Sprintf("%s.commands.credentials.store", c.config.SubjectPrefix)
+
+	subscription, err := c.queueSubscribe(ctx, subject, func(ctx context.Context, msg *nats.Msg) {
+		c.handlePlatformCredentialsMessage(ctx, msg, handler)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":     subject,
+		"queue_group": c.config.QueueGroup,
+	}).Info("Subscribed to platform credentials requests")
+
+	// Wait for context cancellation
+	<-ctx.Done()
+
+	if err := subscription.Unsubscribe(); err != nil {
+		c.logger.WithError(err).Error("Failed to unsubscribe from platform credentials requests")
+	}
+
+	return nil
+}
+
+// handlePlatformCredentialsMessage handles a platform credentials request and replies with the result
+func (c *Client) handlePlatformCredentialsMessage(ctx context.Context, msg *nats.Msg, handler PlatformCredentialsHandler) {
+	logger := c.logger.WithField("subject", msg.Subject)
+
+	var request models.PlatformCredentialsRequest
+	result := &models.PlatformCredentialsResult{}
+
+	if err := json.Unmarshal(msg.Data, &request); err != nil {
+		logger.WithError(err).Error("Failed to unmarshal platform credentials request")
+		result.Error = "invalid platform credentials request"
+	} else if err := handler.StorePlatformCredentials(ctx, &request); err != nil {
+		result.Error = err.Error()
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		logger.WithError(err).Error("Failed to marshal platform credentials result")
+		return
+	}
+
+	if err := msg.Respond(data); err != nil {
+		logger.WithError(err).Error("Failed to respond to platform credentials request")
+	}
+}
+
+// SubscribeToCampaignScheduleRequests serves campaign schedule creation and deletion
+// requests sent by the BFF
+func (c *Client) SubscribeToCampaignScheduleRequests(ctx context.Context, handler CampaignScheduleHandler) error {
+	createSubject := fmt.Sprintf("%s.commands.campaign.schedule.create", c.config.SubjectPrefix)
+	deleteSubject := fmt.Sprintf("%s.commands.campaign.schedule.delete", c.config.SubjectPrefix)
+
+	createSubscription, err := c.queueSubscribe(ctx, createSubject, func(ctx context.Context, msg *nats.Msg) {
+		var request models.CampaignScheduleRequest
+		c.handleCampaignScheduleMessage(msg, &request, func() (*models.CampaignSchedule, error) {
+			return handler.CreateCampaignSchedule(ctx, &request)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", createSubject, err)
+	}
+
+	deleteSubscription, err := c.queueSubscribe(ctx, deleteSubject, func(ctx context.Context, msg *nats.Msg) {
+		var request models.CampaignScheduleDeletionRequest
+		c.handleCampaignScheduleMessage(msg, &request, func() (*models.CampaignSchedule, error) {
+			return nil, handler.DeleteCampaignSchedule(ctx, &request)
+		})
+	})
+	if err != nil {
+		createSubscription.Unsubscribe()
+		return fmt.Errorf("failed to subscribe to %s: %w", deleteSubject, err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subjects":    []string{createSubject, deleteSubject},
+		"queue_group": c.config.QueueGroup,
+	}).Info("Subscribed to campaign schedule requests")
+
+	// Wait for context cancellation
+	<-ctx.Done()
+
+	for _, subscription := range []*nats.Subscription{createSubscription, deleteSubscription} {
+		if err := subscription.Unsubscribe(); err != nil {
+			c.logger.WithError(err).Error("Failed to unsubscribe from campaign schedule requests")
+		}
+	}
+
+	return nil
+}
+
+// handleCampaignScheduleMessage decodes a campaign schedule request into request,
+// runs handle and replies with the resulting schedule or error
+func (c *Client) handleCampaignScheduleMessage(msg *nats.Msg, request interface{}, handle func() (*models.CampaignSchedule, error)) {
+	logger := c.logger.WithField("subject", msg.Subject)
+
+	result := &models.CampaignScheduleResult{}
+	if err := json.Unmarshal(msg.Data, request); err != nil {
+		logger.WithError(err).Error("Failed to unmarshal campaign schedule request")
+		result.Error = "invalid campaign schedule request"
+	} else if schedule, err := handle(); err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Schedule = schedule
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		logger.WithError(err).Error("Failed to marshal campaign schedule result")
+		return
+	}
+
+	if err := msg.Respond(data); err != nil {
+		logger.WithError(err).Error("Failed to respond to campaign schedule request")
+	}
+}
+
+// SubscribeToAssetSLABreaches subscribes to asset SLA breach events published by the BFF
+func (c *Client) SubscribeToAssetSLABreaches(ctx context.Context, handler SLABreachHandler) error {
+	subject := fmt.Sprintf("%s.events.asset.sla_breach", c.config.SubjectPrefix)
+
+	return c.subscribeEvents(ctx, subject, func(ctx context.Context, msg *nats.Msg) {
+		var event models.AssetSLABreachEvent
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			c.logger.WithError(err).Error("Failed to unmarshal asset SLA breach event")
+			if err := msg.Term(); err != nil {
+				c.logger.WithError(err).Error("Failed to terminate message")
+			}
+			return
+		}
+
+		if err := handler.HandleAssetSLABreach(ctx, &event); err != nil {
+			c.logger.WithError(err).WithField("asset_id", event.AssetID).Error("Failed to handle asset SLA breach event")
+			if err := msg.Nak(); err != nil {
+				c.logger.WithError(err).Error("Failed to negatively acknowledge message")
+			}
+			return
+		}
+
+		if err := msg.Ack(); err != nil {
+			c.logger.WithError(err).Error("Failed to acknowledge message")
+		}
+	})
+}
+
+// SubscribeToSuspiciousLogins subscribes to suspicious login events published by the BFF
+func (c *Client) SubscribeToSuspiciousLogins(ctx context.Context, handler SuspiciousLoginHandler) error {
+	subject := fmt.Sprintf("%s.events.auth.suspicious_login", c.config.SubjectPrefix)
+
+	return c.subscribeEvents(ctx, subject, func(ctx context.Context, msg *nats.Msg) {
+		var event models.SuspiciousLoginEvent
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			c.logger.WithError(err).Error("Failed to unmarshal suspicious login event")
+			if err := msg.Term(); err != nil {
+				c.logger.WithError(err).Error("Failed to terminate message")
+			}
+			return
+		}
+
+		if err := handler.HandleSuspiciousLogin(ctx, &event); err != nil {
+			c.logger.WithError(err).WithField("user_id", event.UserID).Error("Failed to handle suspicious login event")
+			if err := msg.Nak(); err != nil {
+				c.logger.WithError(err).Error("Failed to negatively acknowledge message")
+			}
+			return
+		}
+
+		if err := msg.Ack(); err != nil {
+			c.logger.WithError(err).Error("Failed to acknowledge message")
+		}
+	})
+}
+
 // PublishDeploymentStatusChanged publishes a deployment status changed event
 func (c *Client) PublishDeploymentStatusChanged(ctx context.Context, event *models.DeploymentStatusChangedEvent) error {
 	subject := fmt.Sprintf("%s.events.asset.status_changed", c.config.SubjectPrefix)
@@ -124,7 +739,7 @@ func (c *Client) PublishDeploymentStatusChanged(ctx context.Context, event *mode
 		return fmt.Errorf("failed to marshal deployment status changed event: %w", err)
 	}
 
-	if err := c.conn.Publish(subject, data); err != nil {
+	if err := c.publishEvent(ctx, subject, data); err != nil {
 		return fmt.Errorf("failed to publish deployment status changed event: %w", err)
 	}
 
@@ -147,7 +762,7 @@ func (c *Client) PublishAssetStatusChanged(ctx context.Context, event *models.As
 		return fmt.Errorf("failed to marshal asset status changed event: %w", err)
 	}
 
-	if err := c.conn.Publish(subject, data); err != nil {
+	if err := c.publishEvent(ctx, subject, data); err != nil {
 		return fmt.Errorf("failed to publish asset status changed event: %w", err)
 	}
 
@@ -160,6 +775,140 @@ func (c *Client) PublishAssetStatusChanged(ctx context.Context, event *models.As
 	return nil
 }
 
+// PublishAssetPlatformRejected publishes an event reporting that a platform disapproved a deployed asset's ad
+func (c *Client) PublishAssetPlatformRejected(ctx context.Context, event *models.AssetPlatformRejectedEvent) error {
+	subject := fmt.Sprintf("%s.events.asset.platform_rejected", c.config.SubjectPrefix)
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal asset platform rejected event: %w", err)
+	}
+
+	if err := c.publishEvent(ctx, subject, data); err != nil {
+		return fmt.Errorf("failed to publish asset platform rejected event: %w", err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":  subject,
+		"asset_id": event.AssetID,
+		"platform": event.Platform,
+	}).Info("Published asset platform rejected event")
+
+	return nil
+}
+
+// PublishCampaignBudgetExceeded publishes an event reporting a deployment refused
+// because its budget is above the platform's cap
+func (c *Client) PublishCampaignBudgetExceeded(ctx context.Context, event *models.CampaignBudgetExceededEvent) error {
+	subject := fmt.Sprintf("%s.events.campaign.budget_exceeded", c.config.SubjectPrefix)
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal campaign budget exceeded event: %w", err)
+	}
+
+	if err := c.publishEvent(ctx, subject, data); err != nil {
+		return fmt.Errorf("failed to publish campaign budget exceeded event: %w", err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":  subject,
+		"asset_id": event.AssetID,
+		"platform": event.Platform,
+	}).Info("Published campaign budget exceeded event")
+
+	return nil
+}
+
+// PublishAssetRolledBack publishes an event reporting that a deployed asset's ad was paused
+func (c *Client) PublishAssetRolledBack(ctx context.Context, event *models.AssetRolledBackEvent) error {
+	subject := fmt.Sprintf("%s.events.asset.rolled_back", c.config.SubjectPrefix)
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal asset rolled back event: %w", err)
+	}
+
+	if err := c.publishEvent(ctx, subject, data); err != nil {
+		return fmt.Errorf("failed to publish asset rolled back event: %w", err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":  subject,
+		"asset_id": event.AssetID,
+		"platform": event.Platform,
+	}).Info("Published asset rolled back event")
+
+	return nil
+}
+
+// PublishCampaignMetricsUpdated publishes the latest performance metrics of a deployed campaign
+func (c *Client) PublishCampaignMetricsUpdated(ctx context.Context, event *models.CampaignMetricsUpdatedEvent) error {
+	subject := fmt.Sprintf("%s.events.campaign.metrics_updated", c.config.SubjectPrefix)
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal campaign metrics updated event: %w", err)
+	}
+
+	if err := c.publishEvent(ctx, subject, data); err != nil {
+		return fmt.Errorf("failed to publish campaign metrics updated event: %w", err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":     subject,
+		"asset_id":    event.AssetID,
+		"platform":    event.Platform,
+		"campaign_id": event.CampaignID,
+	}).Info("Published campaign metrics updated event")
+
+	return nil
+}
+
+// PublishCampaignPerformanceAlert publishes an alert of an anomalous campaign metric
+func (c *Client) PublishCampaignPerformanceAlert(ctx context.Context, event *models.CampaignPerformanceAlertEvent) error {
+	subject := fmt.Sprintf("%s.events.campaign.performance_alert", c.config.SubjectPrefix)
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal campaign performance alert event: %w", err)
+	}
+
+	if err := c.publishEvent(ctx, subject, data); err != nil {
+		return fmt.Errorf("failed to publish campaign performance alert event: %w", err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":     subject,
+		"campaign_id": event.Anomaly.CampaignID,
+		"metric":      event.Anomaly.Metric,
+		"severity":    event.Anomaly.Severity,
+	}).Info("Published campaign performance alert event")
+
+	return nil
+}
+
+// queueSubscribe subscribes handler to subject in the queue group, observing the
+// time it takes to handle each message
+func (c *Client) queueSubscribe(ctx context.Context, subject string, handler func(ctx context.Context, msg *nats.Msg)) (*nats.Subscription, error) {
+	return c.conn.QueueSubscribe(subject, c.config.QueueGroup, observeHandler(ctx, subject, handler))
+}
+
+// observeHandler wraps handler to observe its duration under subject and to trace
+// it within the trace of the publisher of each message. handler is given ctx
+// carrying the span of the message.
+func observeHandler(ctx context.Context, subject string, handler func(ctx context.Context, msg *nats.Msg)) nats.MsgHandler {
+	observer := metrics.NATSHandleDuration.WithLabelValues(subject)
+	return func(msg *nats.Msg) {
+		timer := prometheus.NewTimer(observer)
+		defer timer.ObserveDuration()
+
+		ctx, span := startProcessSpan(ctx, subject, msg)
+		defer span.End()
+		handler(ctx, msg)
+	}
+}
+
 // HealthCheck checks the health of the NATS connection
 func (c *Client) HealthCheck() error {
 	if c.conn == nil {
@@ -170,6 +919,11 @@ func (c *Client) HealthCheck() error {
 		return fmt.Errorf("NATS is not connected")
 	}
 
+	// Without its consumer no instance receives the events to deploy
+	if _, err := c.GetConsumerInfo(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -0,0 +1,81 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrStreamNotFound is returned when a stream does not exist
+	ErrStreamNotFound = nats.ErrStreamNotFound
+
+	// ErrConsumerNotFound is returned when a consumer does not exist
+	ErrConsumerNotFound = nats.ErrConsumerNotFound
+)
+
+// ConsumerInfo describes the progress of a JetStream consumer
+type ConsumerInfo struct {
+	Name           string     `json:"name"`
+	NumPending     uint64     `json:"num_pending"`
+	NumAckPending  int        `json:"num_ack_pending"`
+	NumRedelivered int        `json:"num_redelivered"`
+	LastAckTime    *time.Time `json:"last_ack_time,omitempty"`
+}
+
+// ListConsumers returns the consumers of a stream sorted by name
+func (c *Client) ListConsumers(ctx context.Context, streamName string) ([]ConsumerInfo, error) {
+	js, err := c.conn.JetStream()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get JetStream context: %w", err)
+	}
+
+	// The consumer listing drops errors, so check that the stream exists first
+	if _, err := js.StreamInfo(streamName, nats.Context(ctx)); err != nil {
+		return nil, fmt.Errorf("failed to get stream %s: %w", streamName, err)
+	}
+
+	consumers := []ConsumerInfo{}
+	for info := range js.ConsumersInfo(streamName, nats.Context(ctx)) {
+		consumers = append(consumers, ConsumerInfo{
+			Name:           info.Name,
+			NumPending:     info.NumPending,
+			NumAckPending:  info.NumAckPending,
+			NumRedelivered: info.NumRedelivered,
+			LastAckTime:    info.AckFloor.Last,
+		})
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list consumers of %s: %w", streamName, err)
+	}
+
+	sort.Slice(consumers, func(i, j int) bool {
+		return consumers[i].Name < consumers[j].Name
+	})
+
+	return consumers, nil
+}
+
+// DeleteConsumer removes a consumer from a stream along with its pending
+// deliveries. Subscribers of a deleted durable consumer stop receiving messages.
+func (c *Client) DeleteConsumer(ctx context.Context, streamName, consumerName string) error {
+	js, err := c.conn.JetStream()
+	if err != nil {
+		return fmt.Errorf("failed to get JetStream context: %w", err)
+	}
+
+	if err := js.DeleteConsumer(streamName, consumerName, nats.Context(ctx)); err != nil {
+		return fmt.Errorf("failed to delete consumer %s of %s: %w", consumerName, streamName, err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"stream":   streamName,
+		"consumer": consumerName,
+	}).Info("Deleted JetStream consumer")
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"github.com/zamc/connectors/internal/metrics"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/tracing"
+)
+
+const (
+	// DelayedStream is the JetStream stream holding messages until they are due
+	DelayedStream = "ZAMC_DELAYED"
+
+	// deliverAtHeader carries the time at which a delayed message is due
+	deliverAtHeader = "Zamc-Deliver-At"
+
+	// delayedRetryDelay is how long a delayed message whose handler failed waits
+	// before it is handled again
+	delayedRetryDelay = 5 * time.Minute
+
+	// delayedMaxDeliver bounds the deliveries of a delayed message, including the
+	// one made before it is due
+	delayedMaxDeliver = 5
+)
+
+// KeywordQualityScoreHandler defines the interface for handling keyword quality score fetches
+type KeywordQualityScoreHandler interface {
+	FetchKeywordQualityScores(ctx context.Context, fetch *models.KeywordQualityScoreFetch) error
+}
+
+// AdReviewHandler defines the interface for handling ad review checks
+type AdReviewHandler interface {
+	CheckAdReview(ctx context.Context, check *models.AdReviewCheck) error
+}
+
+// PublishKeywordQualityScoreFetch schedules a keyword quality score fetch to run after delay
+func (c *Client) PublishKeywordQualityScoreFetch(ctx context.Context, fetch *models.KeywordQualityScoreFetch, delay time.Duration) error {
+	subject := fmt.Sprintf("%s.delayed.keywords.quality_scores", c.config.SubjectPrefix)
+
+	if err := c.publishDelayed(ctx, subject, fetch, delay); err != nil {
+		return fmt.Errorf("failed to publish keyword quality score fetch: %w", err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":     subject,
+		"asset_id":    fetch.AssetID,
+		"ad_group_id": fetch.AdGroupID,
+		"delay":       delay,
+	}).Info("Scheduled keyword quality score fetch")
+
+	return nil
+}
+
+// SubscribeToKeywordQualityScoreFetches runs keyword quality score fetches once they are due
+func (c *Client) SubscribeToKeywordQualityScoreFetches(ctx context.Context, handler KeywordQualityScoreHandler) error {
+	subject := fmt.Sprintf("%s.delayed.keywords.quality_scores", c.config.SubjectPrefix)
+
+	return c.subscribeDelayed(ctx, subject, func(ctx context.Context, data []byte) error {
+		var fetch models.KeywordQualityScoreFetch
+		if err := json.Unmarshal(data, &fetch); err != nil {
+			c.logger.WithError(err).Error("Failed to unmarshal keyword quality score fetch")
+			return nil
+		}
+
+		return handler.FetchKeywordQualityScores(ctx, &fetch)
+	})
+}
+
+// PublishAdReviewCheck schedules a check of a deployed ad's review status to run after delay
+func (c *Client) PublishAdReviewCheck(ctx context.Context, check *models.AdReviewCheck, delay time.Duration) error {
+	subject := fmt.Sprintf("%s.delayed.ads.review_status", c.config.SubjectPrefix)
+
+	if err := c.publishDelayed(ctx, subject, check, delay); err != nil {
+		return fmt.Errorf("failed to publish ad review check: %w", err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":        subject,
+		"asset_id":       check.AssetID,
+		"platform":       check.Platform,
+		"platform_ad_id": check.PlatformAdID,
+		"delay":          delay,
+	}).Info("Scheduled ad review check")
+
+	return nil
+}
+
+// SubscribeToAdReviewChecks runs ad review checks once they are due
+func (c *Client) SubscribeToAdReviewChecks(ctx context.Context, handler AdReviewHandler) error {
+	subject := fmt.Sprintf("%s.delayed.ads.review_status", c.config.SubjectPrefix)
+
+	return c.subscribeDelayed(ctx, subject, func(ctx context.Context, data []byte) error {
+		var check models.AdReviewCheck
+		if err := json.Unmarshal(data, &check); err != nil {
+			c.logger.WithError(err).Error("Failed to unmarshal ad review check")
+			return nil
+		}
+
+		return handler.CheckAdReview(ctx, &check)
+	})
+}
+
+// jetStream returns the JetStream context of the connection, creating the stream
+// of delayed messages if it does not exist
+func (c *Client) jetStream() (nats.JetStreamContext, error) {
+	js, err := c.conn.JetStream()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get JetStream context: %w", err)
+	}
+
+	_, err = js.StreamInfo(DelayedStream)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		_, err = js.AddStream(&nats.StreamConfig{
+			Name:      DelayedStream,
+			Subjects:  []string{fmt.Sprintf("%s.delayed.>", c.config.SubjectPrefix)},
+			Retention: nats.WorkQueuePolicy,
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up stream %s: %w", DelayedStream, err)
+	}
+
+	return js, nil
+}
+
+// publishDelayed stores v in the stream of delayed messages. It is handed to the
+// subscriber of subject once delay has passed.
+func (c *Client) publishDelayed(ctx context.Context, subject string, v interface{}, delay time.Duration) (err error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal delayed message: %w", err)
+	}
+
+	js, err := c.jetStream()
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	msg.Header.Set(deliverAtHeader, time.Now().Add(delay).UTC().Format(time.RFC3339Nano))
+
+	span := startPublishSpan(ctx, msg)
+	defer func() { tracing.End(span, err) }()
+
+	timer := prometheus.NewTimer(metrics.NATSPublishDuration.WithLabelValues(subject))
+	defer timer.ObserveDuration()
+
+	if _, err := js.PublishMsg(msg); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", subject, err)
+	}
+
+	return nil
+}
+
+// subscribeDelayed calls handle with the data of each message published to subject
+// by publishDelayed once it is due. Messages whose handler fails are retried later.
+// It blocks until ctx is cancelled.
+func (c *Client) subscribeDelayed(ctx context.Context, subject string, handle func(ctx context.Context, data []byte) error) error {
+	js, err := c.jetStream()
+	if err != nil {
+		return err
+	}
+
+	// Durable consumers are named after the subject, one per subscriber type
+	durable := c.config.QueueGroup + "_" + strings.ReplaceAll(subject, ".", "_")
+	subscription, err := js.QueueSubscribe(subject, c.config.QueueGroup, observeHandler(ctx, subject, func(ctx context.Context, msg *nats.Msg) {
+		c.handleDelayedMessage(ctx, msg, handle)
+	}), nats.Durable(durable), nats.ManualAck(), nats.MaxDeliver(delayedMaxDeliver))
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":     subject,
+		"queue_group": c.config.QueueGroup,
+	}).Info("Subscribed to delayed messages")
+
+	// Wait for context cancellation. The durable consumer keeps pending messages.
+	<-ctx.Done()
+
+	if err := subscription.Unsubscribe(); err != nil {
+		c.logger.WithError(err).WithField("subject", subject).Error("Failed to unsubscribe from delayed messages")
+	}
+
+	return nil
+}
+
+// handleDelayedMessage hands a due message to handle and puts messages that are
+// not due yet back until they are
+func (c *Client) handleDelayedMessage(ctx context.Context, msg *nats.Msg, handle func(ctx context.Context, data []byte) error) {
+	logger := c.logger.WithField("subject", msg.Subject)
+
+	if deliverAt, err := time.Parse(time.RFC3339Nano, msg.Header.Get(deliverAtHeader)); err == nil {
+		if wait := time.Until(deliverAt); wait > 0 {
+			if err := msg.NakWithDelay(wait); err != nil {
+				logger.WithError(err).Error("Failed to delay message")
+			}
+			return
+		}
+	}
+
+	if err := handle(ctx, msg.Data); err != nil {
+		logger.WithError(err).Error("Failed to handle delayed message")
+		if err := msg.NakWithDelay(delayedRetryDelay); err != nil {
+			logger.WithError(err).Error("Failed to delay message")
+		}
+		return
+	}
+
+	if err := msg.Ack(); err != nil {
+		logger.WithError(err).Error("Failed to acknowledge delayed message")
+	}
+}
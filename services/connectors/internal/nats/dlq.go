@@ -0,0 +1,158 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"github.com/zamc/connectors/internal/metrics"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/tracing"
+)
+
+const (
+	// DeadLetterStream is the JetStream stream holding the events of deployments
+	// that failed on every attempt until they are stored for replay
+	DeadLetterStream = "ZAMC_DLQ"
+
+	// failureReasonHeader carries the error of the last deployment attempt
+	failureReasonHeader = "failure_reason"
+
+	// retryCountHeader carries the number of deployment attempts made
+	retryCountHeader = "retry_count"
+
+	// deadLetterRetryDelay is how long a dead letter that could not be stored
+	// waits before it is delivered again
+	deadLetterRetryDelay = time.Minute
+
+	// deadLetterMaxDeliver bounds the deliveries of a dead letter that cannot be stored
+	deadLetterMaxDeliver = 20
+)
+
+// DeadLetterHandler defines the interface for handling dead-lettered events
+type DeadLetterHandler interface {
+	HandleDeadLetter(ctx context.Context, letter *models.DeadLetter) error
+}
+
+// setUpDeadLetterStream creates the stream of dead letters if it does not exist
+func (c *Client) setUpDeadLetterStream() error {
+	_, err := c.js.StreamInfo(DeadLetterStream)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		_, err = c.js.AddStream(&nats.StreamConfig{
+			Name:      DeadLetterStream,
+			Subjects:  []string{fmt.Sprintf("%s.dlq.>", c.config.SubjectPrefix)},
+			Retention: nats.WorkQueuePolicy,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set up stream %s: %w", DeadLetterStream, err)
+	}
+
+	return nil
+}
+
+// PublishDeadLetter stores an asset status changed event whose deployment failed
+// after retryCount attempts in the stream of dead letters
+func (c *Client) PublishDeadLetter(ctx context.Context, event *models.AssetStatusChangedEvent, failureReason string, retryCount int) (err error) {
+	subject := fmt.Sprintf("%s.dlq.asset.status_changed", c.config.SubjectPrefix)
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	msg.Header.Set(failureReasonHeader, failureReason)
+	msg.Header.Set(retryCountHeader, strconv.Itoa(retryCount))
+
+	span := startPublishSpan(ctx, msg)
+	defer func() { tracing.End(span, err) }()
+
+	timer := prometheus.NewTimer(metrics.NATSPublishDuration.WithLabelValues(subject))
+	defer timer.ObserveDuration()
+
+	if _, err := c.js.PublishMsg(msg, nats.Context(ctx)); err != nil {
+		return fmt.Errorf("failed to publish dead letter to %s: %w", subject, err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":     subject,
+		"asset_id":    event.AssetID,
+		"retry_count": retryCount,
+	}).Warn("Published dead letter")
+
+	return nil
+}
+
+// SubscribeToDeadLetters hands the dead-lettered asset status changed events to
+// handler. Dead letters stay in the stream until handler succeeds. It blocks
+// until ctx is cancelled.
+func (c *Client) SubscribeToDeadLetters(ctx context.Context, handler DeadLetterHandler) error {
+	subject := fmt.Sprintf("%s.dlq.asset.status_changed", c.config.SubjectPrefix)
+
+	// Durable consumers are named after the subject, one per subscriber type
+	durable := c.config.QueueGroup + "_" + strings.ReplaceAll(subject, ".", "_")
+	subscription, err := c.js.QueueSubscribe(subject, c.config.QueueGroup, observeHandler(ctx, subject, func(ctx context.Context, msg *nats.Msg) {
+		c.handleDeadLetterMessage(ctx, msg, handler)
+	}), nats.Durable(durable), nats.ManualAck(), nats.MaxDeliver(deadLetterMaxDeliver))
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":     subject,
+		"queue_group": c.config.QueueGroup,
+	}).Info("Subscribed to dead letters")
+
+	// Wait for context cancellation. The stream keeps pending dead letters.
+	<-ctx.Done()
+
+	if err := subscription.Unsubscribe(); err != nil {
+		c.logger.WithError(err).WithField("subject", subject).Error("Failed to unsubscribe from dead letters")
+	}
+
+	return nil
+}
+
+// handleDeadLetterMessage hands a dead letter to handler
+func (c *Client) handleDeadLetterMessage(ctx context.Context, msg *nats.Msg, handler DeadLetterHandler) {
+	logger := c.logger.WithField("subject", msg.Subject)
+
+	var event models.AssetStatusChangedEvent
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		logger.WithError(err).Error("Failed to unmarshal dead letter")
+		// Delivering the dead letter again would not make it readable
+		if err := msg.Term(); err != nil {
+			logger.WithError(err).Error("Failed to terminate message")
+		}
+		return
+	}
+
+	retryCount, _ := strconv.Atoi(msg.Header.Get(retryCountHeader))
+	letter := &models.DeadLetter{
+		AssetID:       event.AssetID,
+		Event:         json.RawMessage(msg.Data),
+		FailureReason: msg.Header.Get(failureReasonHeader),
+		RetryCount:    retryCount,
+	}
+
+	if err := handler.HandleDeadLetter(ctx, letter); err != nil {
+		logger.WithError(err).WithField("asset_id", event.AssetID).Error("Failed to handle dead letter")
+		if err := msg.NakWithDelay(deadLetterRetryDelay); err != nil {
+			logger.WithError(err).Error("Failed to delay message")
+		}
+		return
+	}
+
+	if err := msg.Ack(); err != nil {
+		logger.WithError(err).Error("Failed to acknowledge dead letter")
+	}
+}
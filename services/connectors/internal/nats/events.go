@@ -0,0 +1,272 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"github.com/zamc/connectors/internal/metrics"
+	"github.com/zamc/connectors/internal/tracing"
+)
+
+const (
+	// EventsStream is the JetStream stream persisting the events published on
+	// the events subjects, so that events survive restarts of their subscribers
+	EventsStream = "ZAMC_EVENTS"
+
+	// eventRetention is how long events are kept in the stream
+	eventRetention = 7 * 24 * time.Hour
+
+	// eventAckWait is how long the server waits for an acknowledgement before
+	// delivering an event again. Handlers running longer report their progress.
+	eventAckWait = 30 * time.Second
+
+	// eventMaxDeliver bounds the deliveries of an event whose handler keeps failing
+	eventMaxDeliver = 10
+
+	// defaultMaxConcurrentDeployments is the number of deployment events handled
+	// at once when NATSConfig.MaxConcurrentDeployments is not set
+	defaultMaxConcurrentDeployments = 32
+)
+
+// setUpEventStream creates the stream of events if it does not exist
+func (c *Client) setUpEventStream() error {
+	_, err := c.js.StreamInfo(EventsStream)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		_, err = c.js.AddStream(&nats.StreamConfig{
+			Name:      EventsStream,
+			Subjects:  []string{fmt.Sprintf("%s.events.>", c.config.SubjectPrefix)},
+			Retention: nats.LimitsPolicy,
+			MaxAge:    eventRetention,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set up stream %s: %w", EventsStream, err)
+	}
+
+	return nil
+}
+
+// publishEvent stores data in the stream of events, compressed when the client
+// compresses messages, and waits for the server to acknowledge it
+func (c *Client) publishEvent(ctx context.Context, subject string, data []byte) (err error) {
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	if err := c.transport.Encode(msg); err != nil {
+		return err
+	}
+
+	span := startPublishSpan(ctx, msg)
+	defer func() { tracing.End(span, err) }()
+
+	timer := prometheus.NewTimer(metrics.NATSPublishDuration.WithLabelValues(subject))
+	defer timer.ObserveDuration()
+
+	if _, err := c.js.PublishMsg(msg); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", subject, err)
+	}
+
+	return nil
+}
+
+// EnsureDurableConsumer creates the durable push consumer consumerName of
+// streamName, delivering the messages published to subject to the queue group,
+// if it does not exist. Existing consumers are left as they are. The consumer
+// delivers every message kept in the stream and waits for each to be
+// acknowledged, so that events survive restarts of all the instances of the
+// queue group.
+func (c *Client) EnsureDurableConsumer(streamName, consumerName, subject string) error {
+	// The consumer is created here rather than by the subscription, which would
+	// delete it on unsubscribe and lose the events published while stopped
+	_, err := c.js.ConsumerInfo(streamName, consumerName)
+	if errors.Is(err, nats.ErrConsumerNotFound) {
+		_, err = c.js.AddConsumer(streamName, &nats.ConsumerConfig{
+			Durable:        consumerName,
+			DeliverSubject: nats.NewInbox(),
+			DeliverGroup:   c.config.QueueGroup,
+			DeliverPolicy:  nats.DeliverAllPolicy,
+			AckPolicy:      nats.AckExplicitPolicy,
+			AckWait:        eventAckWait,
+			MaxDeliver:     eventMaxDeliver,
+			FilterSubject:  subject,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set up consumer %s: %w", consumerName, err)
+	}
+
+	return nil
+}
+
+// durableName returns the name of the durable consumer of the events published
+// to subject, one per subscriber type
+func (c *Client) durableName(subject string) string {
+	return c.config.QueueGroup + "_" + strings.ReplaceAll(subject, ".", "_")
+}
+
+// assetStatusChangedSubject is the subject of asset status changed events
+func (c *Client) assetStatusChangedSubject() string {
+	return fmt.Sprintf("%s.events.asset.status_changed", c.config.SubjectPrefix)
+}
+
+// GetConsumerInfo returns the state of the durable consumer of asset status
+// changed events, through which the instances of the queue group share the
+// deployments
+func (c *Client) GetConsumerInfo() (*nats.ConsumerInfo, error) {
+	consumer := c.durableName(c.assetStatusChangedSubject())
+
+	info, err := c.js.ConsumerInfo(EventsStream, consumer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer %s: %w", consumer, err)
+	}
+
+	return info, nil
+}
+
+// DeleteEventConsumer deletes the durable consumer of asset status changed
+// events along with its pending deliveries, such as when tearing down an
+// integration test. Running subscribers stop receiving events.
+func (c *Client) DeleteEventConsumer() error {
+	return c.DeleteConsumer(context.Background(), EventsStream, c.durableName(c.assetStatusChangedSubject()))
+}
+
+// subscribeEvents calls handle with each event published to subject through a
+// durable push consumer shared by the queue group. handle must acknowledge the
+// message; events it does not acknowledge are delivered again. It blocks until
+// ctx is cancelled.
+func (c *Client) subscribeEvents(ctx context.Context, subject string, handle func(ctx context.Context, msg *nats.Msg)) error {
+	return c.subscribeEventsConcurrently(ctx, subject, 1, handle)
+}
+
+// subscribeEventsConcurrently is subscribeEvents handling up to limit events at
+// once, each in its own goroutine, so that an event waiting for its deployments
+// does not hold up the events delivered after it. It returns once the events
+// received before ctx was cancelled have been handled.
+func (c *Client) subscribeEventsConcurrently(ctx context.Context, subject string, limit int, handle func(ctx context.Context, msg *nats.Msg)) error {
+	durable := c.durableName(subject)
+	if err := c.EnsureDurableConsumer(EventsStream, durable, subject); err != nil {
+		return err
+	}
+
+	handler := observeHandler(ctx, subject, handle)
+	var inFlight sync.WaitGroup
+	if limit > 1 {
+		handler = c.concurrently(ctx, limit, &inFlight, handler)
+	}
+
+	subscription, err := c.js.QueueSubscribe(subject, c.config.QueueGroup, handler,
+		nats.Bind(EventsStream, durable), nats.ManualAck())
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":     subject,
+		"queue_group": c.config.QueueGroup,
+		"consumer":    durable,
+	}).Info("Subscribed to events")
+
+	// Wait for context cancellation. The durable consumer keeps pending events.
+	<-ctx.Done()
+
+	if err := subscription.Unsubscribe(); err != nil {
+		c.logger.WithError(err).WithField("subject", subject).Error("Failed to unsubscribe from events")
+	}
+	inFlight.Wait()
+
+	return nil
+}
+
+// concurrently runs handler in a goroutine per message, at most limit at once.
+// Deliveries wait for a free slot, leaving the events beyond the limit with the
+// server; those arriving after ctx is cancelled are handed back to it.
+func (c *Client) concurrently(ctx context.Context, limit int, inFlight *sync.WaitGroup, handler nats.MsgHandler) nats.MsgHandler {
+	slots := make(chan struct{}, limit)
+
+	return func(msg *nats.Msg) {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			if err := msg.Nak(); err != nil {
+				c.logger.WithError(err).WithField("subject", msg.Subject).Error("Failed to negatively acknowledge message")
+			}
+			return
+		}
+
+		inFlight.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				inFlight.Done()
+			}()
+			handler(msg)
+		}()
+	}
+}
+
+// maxConcurrentDeployments returns the number of deployment events handled at once
+func (c *Client) maxConcurrentDeployments() int {
+	if c.config.MaxConcurrentDeployments > 0 {
+		return c.config.MaxConcurrentDeployments
+	}
+	return defaultMaxConcurrentDeployments
+}
+
+// reportProgress tells the server that msg is still being handled until the
+// returned function is called, so that it is not delivered again meanwhile
+func (c *Client) reportProgress(msg *nats.Msg) (stop func()) {
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(eventAckWait / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := msg.InProgress(); err != nil {
+					c.logger.WithError(err).WithField("subject", msg.Subject).Warn("Failed to report event progress")
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// StreamLag is the backlog of the consumers of a stream
+type StreamLag struct {
+	Stream string `json:"stream"`
+
+	// Pending counts the messages not yet delivered to the consumers
+	Pending uint64 `json:"pending"`
+
+	// AckPending counts the messages delivered but not yet acknowledged
+	AckPending int `json:"ack_pending"`
+
+	Consumers []ConsumerInfo `json:"consumers"`
+}
+
+// EventStreamLag returns the backlog of the consumers of the stream of events
+func (c *Client) EventStreamLag(ctx context.Context) (*StreamLag, error) {
+	consumers, err := c.ListConsumers(ctx, EventsStream)
+	if err != nil {
+		return nil, err
+	}
+
+	lag := &StreamLag{Stream: EventsStream, Consumers: consumers}
+	for _, consumer := range consumers {
+		lag.Pending += consumer.NumPending
+		lag.AckPending += consumer.NumAckPending
+	}
+
+	return lag, nil
+}
@@ -0,0 +1,59 @@
+package nats
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collectTimeout bounds the time a scrape waits for the consumers
+const collectTimeout = 5 * time.Second
+
+var (
+	consumerPendingDesc = prometheus.NewDesc("zamc_connectors_nats_consumer_num_pending",
+		"Number of events not yet delivered by the consumer.", []string{"consumer"}, nil)
+	consumerAckPendingDesc = prometheus.NewDesc("zamc_connectors_nats_consumer_num_ack_pending",
+		"Number of events delivered by the consumer but not yet acknowledged.", []string{"consumer"}, nil)
+	consumerRedeliveredDesc = prometheus.NewDesc("zamc_connectors_nats_consumer_num_redelivered",
+		"Number of events the consumer is delivering again.", []string{"consumer"}, nil)
+)
+
+// ConsumerExporter exports the backlog of the consumers of the stream of events
+// as Prometheus metrics. The consumers are read from the server on every scrape,
+// so that every instance exports the backlog shared by the queue group.
+type ConsumerExporter struct {
+	client *Client
+}
+
+var _ prometheus.Collector = &ConsumerExporter{}
+
+// NewConsumerExporter creates an exporter of the consumers of client's stream of events
+func NewConsumerExporter(client *Client) *ConsumerExporter {
+	return &ConsumerExporter{client: client}
+}
+
+// Describe sends the descriptors of the exported metrics
+func (e *ConsumerExporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- consumerPendingDesc
+	ch <- consumerAckPendingDesc
+	ch <- consumerRedeliveredDesc
+}
+
+// Collect reads the consumers and sends their backlog as metrics
+func (e *ConsumerExporter) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	consumers, err := e.client.ListConsumers(ctx, EventsStream)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(consumerPendingDesc, err)
+		return
+	}
+
+	for _, consumer := range consumers {
+		ch <- prometheus.MustNewConstMetric(consumerPendingDesc, prometheus.GaugeValue, float64(consumer.NumPending), consumer.Name)
+		ch <- prometheus.MustNewConstMetric(consumerAckPendingDesc, prometheus.GaugeValue, float64(consumer.NumAckPending), consumer.Name)
+		ch <- prometheus.MustNewConstMetric(consumerRedeliveredDesc, prometheus.GaugeValue, float64(consumer.NumRedelivered), consumer.Name)
+	}
+}
@@ -0,0 +1,82 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"github.com/zamc/connectors/internal/metrics"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/tracing"
+)
+
+// MaxScheduleAhead is how far ahead deployments can be scheduled. Scheduled
+// deployments wait in the stream of events, which drops older events.
+const MaxScheduleAhead = eventRetention
+
+// PublishScheduledDeployment stores an approved asset status changed event in the
+// stream of events until its ScheduledAt time, when it is handed to the
+// subscribers of scheduled deployments
+func (c *Client) PublishScheduledDeployment(ctx context.Context, event *models.AssetStatusChangedEvent) (err error) {
+	subject := fmt.Sprintf("%s.events.asset.scheduled", c.config.SubjectPrefix)
+
+	if event.ScheduledAt == nil {
+		return fmt.Errorf("asset %s has no scheduled deployment time", event.AssetID)
+	}
+	if time.Until(*event.ScheduledAt) > MaxScheduleAhead {
+		return fmt.Errorf("asset %s is scheduled more than %s ahead", event.AssetID, MaxScheduleAhead)
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal scheduled deployment: %w", err)
+	}
+
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	msg.Header.Set(deliverAtHeader, event.ScheduledAt.UTC().Format(time.RFC3339Nano))
+
+	span := startPublishSpan(ctx, msg)
+	defer func() { tracing.End(span, err) }()
+
+	timer := prometheus.NewTimer(metrics.NATSPublishDuration.WithLabelValues(subject))
+	defer timer.ObserveDuration()
+
+	if _, err := c.js.PublishMsg(msg, nats.Context(ctx)); err != nil {
+		return fmt.Errorf("failed to publish scheduled deployment to %s: %w", subject, err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"subject":      subject,
+		"asset_id":     event.AssetID,
+		"scheduled_at": event.ScheduledAt,
+	}).Info("Published scheduled deployment")
+
+	return nil
+}
+
+// SubscribeToScheduledDeployments hands scheduled deployments to handler once
+// they are due, up to NATSConfig.MaxConcurrentDeployments at once. Deployments
+// whose handler fails are retried later. It blocks until ctx is cancelled.
+func (c *Client) SubscribeToScheduledDeployments(ctx context.Context, handler EventHandler) error {
+	subject := fmt.Sprintf("%s.events.asset.scheduled", c.config.SubjectPrefix)
+
+	return c.subscribeEventsConcurrently(ctx, subject, c.maxConcurrentDeployments(), func(ctx context.Context, msg *nats.Msg) {
+		c.handleDelayedMessage(ctx, msg, func(ctx context.Context, data []byte) error {
+			var event models.AssetStatusChangedEvent
+			if err := json.Unmarshal(data, &event); err != nil {
+				c.logger.WithError(err).Error("Failed to unmarshal scheduled deployment")
+				return nil
+			}
+
+			stopProgress := c.reportProgress(msg)
+			defer stopProgress()
+
+			return handler.HandleAssetStatusChanged(ctx, &event)
+		})
+	})
+}
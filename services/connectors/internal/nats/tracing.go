@@ -0,0 +1,40 @@
+package nats
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/zamc/connectors/internal/tracing"
+)
+
+// startPublishSpan starts the span of publishing msg and injects its trace
+// context into the headers of msg
+func startPublishSpan(ctx context.Context, msg *nats.Msg) trace.Span {
+	ctx, span := tracing.Tracer.Start(ctx, msg.Subject+" publish", trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(messagingAttributes(msg.Subject)...))
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(msg.Header))
+	return span
+}
+
+// startProcessSpan extracts the trace context of the publisher of msg and starts
+// the span of handling msg within it
+func startProcessSpan(ctx context.Context, subject string, msg *nats.Msg) (context.Context, trace.Span) {
+	if msg.Header != nil {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(msg.Header))
+	}
+	return tracing.Tracer.Start(ctx, subject+" process", trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(messagingAttributes(subject)...))
+}
+
+// messagingAttributes returns the span attributes of messages on subject
+func messagingAttributes(subject string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("messaging.system", "nats"),
+		attribute.String("messaging.destination.name", subject),
+	}
+}
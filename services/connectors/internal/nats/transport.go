@@ -0,0 +1,124 @@
+package nats
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/zamc/connectors/internal/config"
+)
+
+const (
+	// ContentEncodingHeader names the encoding of the data of compressed messages
+	ContentEncodingHeader = "Content-Encoding"
+
+	// GzipEncoding is the content encoding of messages compressed with gzip
+	GzipEncoding = "gzip"
+
+	// MaxDecodedSize is the largest size of the data of a compressed message once
+	// decompressed, so that a small message cannot inflate to exhaust memory
+	MaxDecodedSize = 8 << 20
+)
+
+// CompressionTransport compresses the data of the messages published through it
+// with gzip, marking them with the Content-Encoding header so that subscribers
+// know to decompress them. Messages are published unchanged when compression is
+// disabled.
+type CompressionTransport struct {
+	conn    *nats.Conn
+	enabled bool
+
+	// writers reuses gzip writers, which allocate about 1MB each
+	writers sync.Pool
+}
+
+// NewCompressionTransport creates a transport publishing to conn, compressing
+// messages when cfg enables it. It fails for invalid compression levels.
+func NewCompressionTransport(conn *nats.Conn, cfg *config.NATSConfig) (*CompressionTransport, error) {
+	if cfg.EnableCompression {
+		if _, err := gzip.NewWriterLevel(io.Discard, cfg.CompressionLevel); err != nil {
+			return nil, fmt.Errorf("invalid NATS compression level: %w", err)
+		}
+	}
+
+	level := cfg.CompressionLevel
+	transport := &CompressionTransport{conn: conn, enabled: cfg.EnableCompression}
+	transport.writers.New = func() interface{} {
+		writer, _ := gzip.NewWriterLevel(io.Discard, level)
+		return writer
+	}
+	return transport, nil
+}
+
+// Publish publishes data to subject, compressed when compression is enabled
+func (t *CompressionTransport) Publish(subject string, data []byte) error {
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+
+	if err := t.Encode(msg); err != nil {
+		return err
+	}
+
+	return t.conn.PublishMsg(msg)
+}
+
+// Encode compresses the data of msg in place when compression is enabled
+func (t *CompressionTransport) Encode(msg *nats.Msg) error {
+	if !t.enabled {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	writer := t.writers.Get().(*gzip.Writer)
+	defer t.writers.Put(writer)
+	writer.Reset(&buf)
+
+	if _, err := writer.Write(msg.Data); err != nil {
+		return fmt.Errorf("failed to compress message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to compress message: %w", err)
+	}
+
+	if msg.Header == nil {
+		msg.Header = nats.Header{}
+	}
+	msg.Header.Set(ContentEncodingHeader, GzipEncoding)
+	msg.Data = buf.Bytes()
+	return nil
+}
+
+// DecodeData returns the data of msg, decompressed when its Content-Encoding
+// header says it was compressed with gzip
+func DecodeData(msg *nats.Msg) ([]byte, error) {
+	encoding := ""
+	if msg.Header != nil {
+		encoding = msg.Header.Get(ContentEncodingHeader)
+	}
+
+	switch encoding {
+	case "":
+		return msg.Data, nil
+	case GzipEncoding:
+		reader, err := gzip.NewReader(bytes.NewReader(msg.Data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress message: %w", err)
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(io.LimitReader(reader, MaxDecodedSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress message: %w", err)
+		}
+		if len(data) > MaxDecodedSize {
+			return nil, fmt.Errorf("decompressed message exceeds %d bytes", MaxDecodedSize)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unsupported message encoding %q", encoding)
+	}
+}
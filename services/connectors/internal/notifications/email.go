@@ -0,0 +1,205 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"html/template"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+)
+
+var deploymentEmail = template.Must(template.New("deployment").Parse(`<html>
+<body>
+<h2>{{if .Succeeded}}Asset deployed to {{.Event.Platform}}{{else}}Asset deployment to {{.Event.Platform}} failed{{end}}</h2>
+<table>
+<tr><th align="left">Status</th><td>{{.Event.Status}}</td></tr>
+<tr><th align="left">Platform</th><td>{{.Event.Platform}}</td></tr>
+<tr><th align="left">Asset</th><td>{{if .Event.Title}}{{.Event.Title}} ({{.Event.AssetID}}){{else}}{{.Event.AssetID}}{{end}}</td></tr>
+<tr><th align="left">Project</th><td>{{.Event.ProjectID}}</td></tr>
+{{if .Event.Error}}<tr><th align="left">Error</th><td><pre>{{.Event.Error}}</pre></td></tr>
+{{end}}{{if .Event.PlatformURL}}<tr><th align="left">Link</th><td><a href="{{.Event.PlatformURL}}">View on {{.Event.Platform}}</a></td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+var suspiciousLoginEmail = template.Must(template.New("suspicious_login").Parse(`<html>
+<body>
+<h2>New sign-in from an unrecognized device</h2>
+<p>Your ZAMC account was signed in to from a device that has not been used with it before. If this was not you, sign out of all sessions and change your password.</p>
+<table>
+<tr><th align="left">Time</th><td>{{.Timestamp.UTC.Format "2006-01-02 15:04 MST"}}</td></tr>
+{{if .Device}}<tr><th align="left">Device</th><td>{{.Device}}</td></tr>
+{{end}}<tr><th align="left">Browser</th><td>{{.UserAgent}}</td></tr>
+<tr><th align="left">IP address</th><td>{{.IPAddress}}</td></tr>
+{{if .Country}}<tr><th align="left">Location</th><td>{{if .City}}{{.City}}, {{end}}{{.Country}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// EmailNotifier emails deployment results as HTML through an SMTP server
+type EmailNotifier struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+	to       []string
+	timeout  time.Duration
+	logger   *logrus.Logger
+}
+
+// NewEmailNotifier creates a new email notifier
+func NewEmailNotifier(cfg *config.NotificationConfig, logger *logrus.Logger) *EmailNotifier {
+	return &EmailNotifier{
+		addr:     net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+		host:     cfg.SMTPHost,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+		from:     cfg.EmailFrom,
+		to:       cfg.EmailTo,
+		timeout:  10 * time.Second,
+		logger:   logger,
+	}
+}
+
+// Send emails the result of a deployment to every recipient
+func (n *EmailNotifier) Send(ctx context.Context, event NotificationEvent) error {
+	message, err := n.message(event)
+	if err != nil {
+		return err
+	}
+
+	if err := n.deliver(ctx, n.to, message); err != nil {
+		return err
+	}
+
+	n.logger.WithFields(logrus.Fields{
+		"asset_id":   event.AssetID,
+		"recipients": len(n.to),
+	}).Debug("Deployment email sent")
+
+	return nil
+}
+
+// HandleSuspiciousLogin warns a user by email of a login from a device their
+// client did not recognize
+func (n *EmailNotifier) HandleSuspiciousLogin(ctx context.Context, event *models.SuspiciousLoginEvent) error {
+	if event.Email == "" {
+		return fmt.Errorf("suspicious login of user %s has no email address", event.UserID)
+	}
+
+	var body bytes.Buffer
+	if err := suspiciousLoginEmail.Execute(&body, event); err != nil {
+		return fmt.Errorf("failed to render email: %w", err)
+	}
+
+	message := n.envelope([]string{event.Email}, "[ZAMC] New sign-in from an unrecognized device", event.Timestamp, body.Bytes())
+	if err := n.deliver(ctx, []string{event.Email}, message); err != nil {
+		return err
+	}
+
+	n.logger.WithField("user_id", event.UserID).Info("Suspicious login email sent")
+
+	return nil
+}
+
+// deliver sends message to the recipients in to through the SMTP server
+func (n *EmailNotifier) deliver(ctx context.Context, to []string, message []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if n.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.username, n.password, n.host)); err != nil {
+			return fmt.Errorf("failed to authenticate to SMTP server: %w", err)
+		}
+	}
+
+	if err := client.Mail(n.from); err != nil {
+		return fmt.Errorf("SMTP server refused sender: %w", err)
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("SMTP server refused recipient %s: %w", recipient, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// message builds the MIME message of the email of event
+func (n *EmailNotifier) message(event NotificationEvent) ([]byte, error) {
+	succeeded := event.Status == models.DeploymentStatusSuccess
+	subject := fmt.Sprintf("[ZAMC] Asset deployed to %s", event.Platform)
+	if !succeeded {
+		subject = fmt.Sprintf("[ZAMC] Asset deployment to %s failed", event.Platform)
+	}
+
+	var body bytes.Buffer
+	err := deploymentEmail.Execute(&body, struct {
+		Event     NotificationEvent
+		Succeeded bool
+	}{event, succeeded})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render email: %w", err)
+	}
+
+	return n.envelope(n.to, subject, event.Timestamp, body.Bytes()), nil
+}
+
+// envelope builds the MIME message of an HTML email to the recipients in to
+func (n *EmailNotifier) envelope(to []string, subject string, date time.Time, body []byte) []byte {
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", n.from)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&message, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
+	message.Write(body)
+
+	return message.Bytes()
+}
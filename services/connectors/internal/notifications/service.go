@@ -0,0 +1,109 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+// NotificationEvent is the terminal result of the deployment of an asset to a
+// platform
+type NotificationEvent struct {
+	AssetID     uuid.UUID
+	ProjectID   uuid.UUID
+	Title       string
+	Platform    models.Platform
+	Status      models.DeploymentStatus
+	PlatformURL string
+	Error       string
+	Timestamp   time.Time
+}
+
+// Channel delivers notification events to people, such as through Slack or email
+type Channel interface {
+	Send(ctx context.Context, event NotificationEvent) error
+}
+
+// DefaultQueueSize is the number of notifications a service holds while its
+// channels are slow
+const DefaultQueueSize = 256
+
+// ErrQueueFull is returned for notifications that arrive while the queue of the
+// service is full
+var ErrQueueFull = errors.New("notification queue is full")
+
+// NotificationService sends deployment results on every configured channel. The
+// results are queued and sent in the background by Run, so that a slow SMTP
+// server or webhook does not hold up deployments.
+type NotificationService struct {
+	channels []Channel
+	queue    chan NotificationEvent
+	logger   *logrus.Logger
+}
+
+// NewNotificationService creates a service sending on channels
+func NewNotificationService(logger *logrus.Logger, channels ...Channel) *NotificationService {
+	return &NotificationService{
+		channels: channels,
+		queue:    make(chan NotificationEvent, DefaultQueueSize),
+		logger:   logger,
+	}
+}
+
+// Notify queues event to be sent by Run. It does not wait for the channels;
+// ErrQueueFull is returned if the queue has no room left for event.
+func (s *NotificationService) Notify(event NotificationEvent) error {
+	select {
+	case s.queue <- event:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Run sends the queued events until ctx is done. Events still queued then are
+// dropped.
+func (s *NotificationService) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			if dropped := len(s.queue); dropped > 0 {
+				s.logger.WithField("dropped", dropped).Warn("Deployment notifications dropped on shutdown")
+			}
+			return
+		case event := <-s.queue:
+			if err := s.Send(ctx, event); err != nil {
+				s.logger.WithError(err).WithFields(logrus.Fields{
+					"asset_id": event.AssetID,
+					"platform": event.Platform,
+				}).Warn("Failed to send deployment notification")
+			}
+		}
+	}
+}
+
+// Send sends event on every channel. A channel failing does not keep the event
+// from the others; the errors of all failed channels are returned.
+func (s *NotificationService) Send(ctx context.Context, event NotificationEvent) error {
+	var errs []error
+	for _, channel := range s.channels {
+		if err := channel.Send(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"asset_id": event.AssetID,
+		"platform": event.Platform,
+		"status":   event.Status,
+		"channels": len(s.channels),
+		"failed":   len(errs),
+	}).Debug("Deployment notification sent")
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,161 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+)
+
+// SlackNotifier posts messages to a Slack incoming webhook
+type SlackNotifier struct {
+	httpClient *http.Client
+	webhookURL string
+	logger     *logrus.Logger
+
+	// projectWebhooks are the webhooks of the projects whose deployment results
+	// are posted to a channel of their own
+	projectWebhooks map[string]string
+}
+
+// NewSlackNotifier creates a new Slack notifier
+func NewSlackNotifier(cfg *config.SlackConfig, logger *logrus.Logger) *SlackNotifier {
+	return &SlackNotifier{
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		webhookURL: cfg.WebhookURL,
+		logger:     logger,
+	}
+}
+
+// SetProjectWebhooks posts the deployment results of the projects in webhooks to
+// their own webhook instead of the configured one
+func (n *SlackNotifier) SetProjectWebhooks(webhooks map[string]string) {
+	n.projectWebhooks = webhooks
+}
+
+type slackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks,omitempty"`
+}
+
+// slackBlock is a Block Kit layout block
+type slackBlock struct {
+	Type   string       `json:"type"`
+	Text   *slackText   `json:"text,omitempty"`
+	Fields []*slackText `json:"fields,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func markdown(text string) *slackText {
+	return &slackText{Type: "mrkdwn", Text: text}
+}
+
+// Notify posts text to the configured channel
+func (n *SlackNotifier) Notify(ctx context.Context, text string) error {
+	return n.post(ctx, n.webhookURL, slackMessage{Text: text})
+}
+
+// Send posts the result of a deployment as a Block Kit message, to the webhook
+// of its project if it has one
+func (n *SlackNotifier) Send(ctx context.Context, event NotificationEvent) error {
+	webhookURL := n.webhookURL
+	if projectWebhook, ok := n.projectWebhooks[event.ProjectID.String()]; ok {
+		webhookURL = projectWebhook
+	}
+	if webhookURL == "" {
+		// Only projects with a webhook of their own are posted
+		return nil
+	}
+
+	summary := fmt.Sprintf(":white_check_mark: Asset deployed to %s", event.Platform)
+	if event.Status != models.DeploymentStatusSuccess {
+		summary = fmt.Sprintf(":x: Asset deployment to %s failed", event.Platform)
+	}
+
+	title := event.Title
+	if title == "" {
+		title = event.AssetID.String()
+	}
+	fields := []*slackText{
+		markdown(fmt.Sprintf("*Status*\n%s", event.Status)),
+		markdown(fmt.Sprintf("*Platform*\n%s", event.Platform)),
+		markdown(fmt.Sprintf("*Asset*\n%s", event.AssetID)),
+		markdown(fmt.Sprintf("*Project*\n%s", event.ProjectID)),
+	}
+	blocks := []slackBlock{
+		{Type: "section", Text: markdown(fmt.Sprintf("%s: *%s*", summary, title))},
+		{Type: "section", Fields: fields},
+	}
+	if event.Error != "" {
+		blocks = append(blocks, slackBlock{Type: "section", Text: markdown(fmt.Sprintf("*Error*\n```%s```", event.Error))})
+	}
+	if event.PlatformURL != "" {
+		blocks = append(blocks, slackBlock{Type: "section", Text: markdown(fmt.Sprintf("<%s|View on %s>", event.PlatformURL, event.Platform))})
+	}
+
+	// The text is shown in notifications, where blocks are not
+	return n.post(ctx, webhookURL, slackMessage{Text: fmt.Sprintf("%s: %s", summary, title), Blocks: blocks})
+}
+
+// post sends message to the incoming webhook webhookURL
+func (n *SlackNotifier) post(ctx context.Context, webhookURL string, message slackMessage) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create Slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send Slack message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Slack webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// HandleAssetSLABreach escalates an asset that has waited too long for review
+func (n *SlackNotifier) HandleAssetSLABreach(ctx context.Context, event *models.AssetSLABreachEvent) error {
+	text := fmt.Sprintf(
+		":rotating_light: Asset *%s* has been waiting for review since %s, %.1f hours past its %d hour SLA (asset %s, project %s)",
+		event.AssetName,
+		event.ReviewStartedAt.UTC().Format(time.RFC1123),
+		event.OverdueHours,
+		event.SLAHours,
+		event.AssetID,
+		event.ProjectID,
+	)
+
+	if err := n.Notify(ctx, text); err != nil {
+		return err
+	}
+
+	n.logger.WithFields(logrus.Fields{
+		"asset_id":   event.AssetID,
+		"project_id": event.ProjectID,
+	}).Info("Asset SLA breach escalated to Slack")
+
+	return nil
+}
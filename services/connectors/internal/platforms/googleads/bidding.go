@@ -0,0 +1,116 @@
+package googleads
+
+import (
+	"fmt"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+)
+
+// Bidding strategies that can be configured for the campaigns of a content type
+const (
+	BiddingTargetCPA           = "TARGET_CPA"
+	BiddingTargetROAS          = "TARGET_ROAS"
+	BiddingMaximizeConversions = "MAXIMIZE_CONVERSIONS"
+	BiddingMaximizeClicks      = "MAXIMIZE_CLICKS"
+	BiddingTargetCPM           = "TARGET_CPM"
+)
+
+// CampaignBidding holds the bidding fields of a campaign. Only the field of its
+// BiddingStrategyType is set; the API derives the type from that field.
+type CampaignBidding struct {
+	BiddingStrategyType string               `json:"-"`
+	TargetCpa           *TargetCpa           `json:"targetCpa,omitempty"`
+	TargetRoas          *TargetRoas          `json:"targetRoas,omitempty"`
+	MaximizeConversions *MaximizeConversions `json:"maximizeConversions,omitempty"`
+	TargetSpend         *TargetSpend         `json:"targetSpend,omitempty"`
+	TargetCpm           *TargetCpm           `json:"targetCpm,omitempty"`
+}
+
+// TargetCpa bids for as many conversions as possible at the target cost per
+// conversion, left to Google when zero
+type TargetCpa struct {
+	TargetCpaMicros int64 `json:"targetCpaMicros,omitempty"`
+}
+
+// TargetRoas bids for as much conversion value as possible at the target return
+// on ad spend, left to Google when zero
+type TargetRoas struct {
+	TargetRoas float64 `json:"targetRoas,omitempty"`
+}
+
+// MaximizeConversions spends the budget on as many conversions as possible
+type MaximizeConversions struct {
+	TargetCpaMicros int64 `json:"targetCpaMicros,omitempty"`
+}
+
+// TargetSpend spends the budget on as many clicks as possible
+type TargetSpend struct{}
+
+// TargetCpm bids per thousand impressions
+type TargetCpm struct{}
+
+// BuildCampaignBidding returns the bidding of the campaign of a deployment: the
+// strategy mapped to its content type in strategies, with the targets of the
+// request. Requests with a target but no mapped strategy bid for that target.
+// It returns nil when the campaign keeps the default bidding.
+func BuildCampaignBidding(strategies config.BiddingStrategies, request *models.DeploymentRequest) (*CampaignBidding, error) {
+	metadata := request.Metadata
+
+	strategy := strategies[request.ContentType]
+	if strategy == "" {
+		switch {
+		case metadata.TargetROAS != nil:
+			strategy = BiddingTargetROAS
+		case metadata.TargetCPA != nil:
+			strategy = BiddingTargetCPA
+		default:
+			return nil, nil
+		}
+	}
+
+	var targetCPAMicros int64
+	if metadata.TargetCPA != nil {
+		targetCPAMicros = int64(*metadata.TargetCPA * 1_000_000)
+	}
+
+	switch strategy {
+	case BiddingTargetCPA:
+		return &CampaignBidding{BiddingStrategyType: strategy, TargetCpa: &TargetCpa{TargetCpaMicros: targetCPAMicros}}, nil
+	case BiddingTargetROAS:
+		bidding := &CampaignBidding{BiddingStrategyType: strategy, TargetRoas: &TargetRoas{}}
+		if metadata.TargetROAS != nil {
+			bidding.TargetRoas.TargetRoas = *metadata.TargetROAS
+		}
+		return bidding, nil
+	case BiddingMaximizeConversions:
+		return &CampaignBidding{BiddingStrategyType: strategy, MaximizeConversions: &MaximizeConversions{TargetCpaMicros: targetCPAMicros}}, nil
+	case BiddingMaximizeClicks:
+		// The API calls Maximize Clicks TARGET_SPEND
+		return &CampaignBidding{BiddingStrategyType: "TARGET_SPEND", TargetSpend: &TargetSpend{}}, nil
+	case BiddingTargetCPM:
+		return &CampaignBidding{BiddingStrategyType: strategy, TargetCpm: &TargetCpm{}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported bidding strategy %q for %s", strategy, request.ContentType)
+	}
+}
+
+// biddingStrategyType returns the strategy of bidding, or the campaign default
+func biddingStrategyType(bidding *CampaignBidding) string {
+	if bidding == nil {
+		return "default"
+	}
+	return bidding.BiddingStrategyType
+}
+
+// validateBiddingStrategies checks that every strategy of strategies is supported
+func validateBiddingStrategies(strategies config.BiddingStrategies) error {
+	for contentType, strategy := range strategies {
+		switch strategy {
+		case BiddingTargetCPA, BiddingTargetROAS, BiddingMaximizeConversions, BiddingMaximizeClicks, BiddingTargetCPM:
+		default:
+			return fmt.Errorf("unsupported bidding strategy %q for %s", strategy, contentType)
+		}
+	}
+	return nil
+}
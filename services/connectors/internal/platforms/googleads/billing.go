@@ -0,0 +1,93 @@
+package googleads
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+const (
+	// billingSetupQuery selects the billing setups of the account
+	billingSetupQuery = `SELECT billing_setup.id, billing_setup.status FROM billing_setup`
+
+	// accountBudgetQuery selects the approved spending limits of the account
+	accountBudgetQuery = `SELECT account_budget.id, account_budget.approved_spending_limit_micros, account_budget.approved_spending_limit_type, account_budget.amount_served_micros FROM account_budget WHERE account_budget.status = 'APPROVED'`
+
+	// customerCurrencyQuery selects the currency of the account
+	customerCurrencyQuery = `SELECT customer.currency_code FROM customer`
+)
+
+// billingSearchResponse holds the rows of the googleAds:search calls selecting billing
+// setups, account budgets and the customer
+type billingSearchResponse struct {
+	Results []struct {
+		BillingSetup struct {
+			Status string `json:"status"`
+		} `json:"billingSetup"`
+		AccountBudget struct {
+			ApprovedSpendingLimitMicros int64  `json:"approvedSpendingLimitMicros,string"`
+			ApprovedSpendingLimitType   string `json:"approvedSpendingLimitType"`
+			AmountServedMicros          int64  `json:"amountServedMicros,string"`
+		} `json:"accountBudget"`
+		Customer struct {
+			CurrencyCode string `json:"currencyCode"`
+		} `json:"customer"`
+	} `json:"results"`
+}
+
+// GetBillingStatus returns whether the account has an approved billing setup and
+// how much credit its approved account budget has left. Accounts billed without an
+// account budget, or with an infinite spending limit, are unlimited.
+func (c *Client) GetBillingStatus(ctx context.Context) (*models.BillingStatus, error) {
+	status := &models.BillingStatus{}
+
+	setups, err := c.searchBilling(ctx, billingSetupQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query billing setups: %w", err)
+	}
+	for _, row := range setups.Results {
+		if row.BillingSetup.Status == "APPROVED" {
+			status.Approved = true
+		}
+	}
+
+	customer, err := c.searchBilling(ctx, customerCurrencyQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account currency: %w", err)
+	}
+	if len(customer.Results) > 0 {
+		status.Currency = customer.Results[0].Customer.CurrencyCode
+	}
+
+	budgets, err := c.searchBilling(ctx, accountBudgetQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account budgets: %w", err)
+	}
+	if len(budgets.Results) == 0 {
+		status.Unlimited = true
+		return status, nil
+	}
+
+	budget := budgets.Results[0].AccountBudget
+	if budget.ApprovedSpendingLimitType == "INFINITE" {
+		status.Unlimited = true
+		return status, nil
+	}
+
+	status.CreditLimit = float64(budget.ApprovedSpendingLimitMicros) / 1e6
+	status.CurrentBalance = float64(budget.ApprovedSpendingLimitMicros-budget.AmountServedMicros) / 1e6
+
+	return status, nil
+}
+
+// searchBilling runs a GAQL query for the billing status
+func (c *Client) searchBilling(ctx context.Context, query string) (*billingSearchResponse, error) {
+	var response billingSearchResponse
+	endpoint := fmt.Sprintf("customers/%s/googleAds:search", c.customerID)
+	if err := c.postAPIObject(ctx, endpoint, map[string]string{"query": query}, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
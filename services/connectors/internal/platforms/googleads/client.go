@@ -3,28 +3,79 @@ package googleads
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/api/googleads/v16"
 	"google.golang.org/api/option"
 
 	"github.com/zamc/connectors/internal/config"
 	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/ratelimit"
+	"github.com/zamc/connectors/internal/scheduler"
+	"github.com/zamc/connectors/internal/tracing"
 )
 
 // Client represents a Google Ads API client
 type Client struct {
-	service    *googleads.Service
-	config     *config.GoogleAdsConfig
-	logger     *logrus.Logger
-	customerID string
+	httpClient  *http.Client
+	logger      *logrus.Logger
+	customerID  string
+	baseURL     string
+	scheduler   *scheduler.SchedulerWorker
+	rateLimiter *ratelimit.PlatformRateLimiter
+
+	// mu guards the configuration and the service and token source created
+	// from its credentials, replaced when the credentials are rotated
+	mu          sync.RWMutex
+	config      *config.GoogleAdsConfig
+	service     *googleads.Service
+	tokenSource oauth2.TokenSource
 }
 
 // NewClient creates a new Google Ads client
 func NewClient(cfg *config.GoogleAdsConfig, logger *logrus.Logger) (*Client, error) {
+	if err := validateBiddingStrategies(cfg.BiddingStrategyMap); err != nil {
+		return nil, err
+	}
+
+	service, tokenSource, err := newService(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Clean customer ID (remove dashes)
+	customerID := strings.ReplaceAll(cfg.CustomerID, "-", "")
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = "https://googleads.googleapis.com/v16"
+	}
+
+	logger.WithField("customer_id", customerID).Info("Google Ads client initialized")
+
+	return &Client{
+		service: service,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		tokenSource: tokenSource,
+		config:      cfg,
+		logger:      logger,
+		customerID:  customerID,
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+// newService creates the Google Ads service and the token source of the OAuth2
+// credentials of cfg
+func newService(cfg *config.GoogleAdsConfig) (*googleads.Service, oauth2.TokenSource, error) {
 	ctx := context.Background()
 
 	// Create OAuth2 config
@@ -40,20 +91,50 @@ func NewClient(cfg *config.GoogleAdsConfig, logger *logrus.Logger) (*Client, err
 	// Create Google Ads service
 	service, err := googleads.NewService(ctx, option.WithTokenSource(tokenSource))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Google Ads service: %w", err)
+		return nil, nil, fmt.Errorf("failed to create Google Ads service: %w", err)
 	}
 
-	// Clean customer ID (remove dashes)
-	customerID := strings.ReplaceAll(cfg.CustomerID, "-", "")
+	return service, tokenSource, nil
+}
 
-	logger.WithField("customer_id", customerID).Info("Google Ads client initialized")
+// Config returns the current configuration of the client
+func (c *Client) Config() *config.GoogleAdsConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config
+}
 
-	return &Client{
-		service:    service,
-		config:     cfg,
-		logger:     logger,
-		customerID: customerID,
-	}, nil
+// UpdateCredentials replaces the developer token and OAuth2 credentials of the
+// client with those of cfg, for the calls made from then on. The customer
+// accounts are kept.
+func (c *Client) UpdateCredentials(cfg *config.GoogleAdsConfig) error {
+	service, tokenSource, err := newService(cfg)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	updated := *c.config
+	updated.DeveloperToken = cfg.DeveloperToken
+	updated.ClientID = cfg.ClientID
+	updated.ClientSecret = cfg.ClientSecret
+	updated.RefreshToken = cfg.RefreshToken
+	c.config = &updated
+	c.service = service
+	c.tokenSource = tokenSource
+
+	c.logger.WithField("customer_id", c.customerID).Info("Google Ads credentials updated")
+	return nil
+}
+
+// token returns an access token for the current credentials
+func (c *Client) token() (*oauth2.Token, error) {
+	c.mu.RLock()
+	tokenSource := c.tokenSource
+	c.mu.RUnlock()
+	return tokenSource.Token()
 }
 
 // DeployAsset deploys an asset to Google Ads
@@ -67,6 +148,11 @@ func (c *Client) DeployAsset(ctx context.Context, request *models.DeploymentRequ
 
 	logger.Info("Starting Google Ads deployment")
 
+	ctx, span := tracing.Tracer.Start(ctx, "googleads.DeployAsset", trace.WithAttributes(
+		attribute.String("asset.id", request.AssetID.String()),
+		attribute.String("content_type", string(request.ContentType)),
+	))
+
 	result := &models.DeploymentResult{
 		AssetID:    request.AssetID,
 		Platform:   models.PlatformGoogleAds,
@@ -86,12 +172,15 @@ func (c *Client) DeployAsset(ctx context.Context, request *models.DeploymentRequ
 		err = c.deployResponsiveSearchAd(ctx, request, result)
 	case models.ContentTypeVideoScript:
 		err = c.deployVideoAd(ctx, request, result)
+	case models.ContentTypeInfographic:
+		err = c.deployDisplayAd(ctx, request, result)
 	default:
 		err = c.deployTextAd(ctx, request, result) // Default to text ad
 	}
 
 	// Update metrics
 	result.Metrics.Duration = time.Since(startTime)
+	tracing.End(span, err)
 
 	if err != nil {
 		result.Status = models.DeploymentStatusFailed
@@ -139,6 +228,8 @@ func (c *Client) deployTextAd(ctx context.Context, request *models.DeploymentReq
 	// Set result data
 	result.PlatformID = adID
 	result.PlatformURL = fmt.Sprintf("https://ads.google.com/aw/ads?campaignId=%s&adGroupId=%s", campaignID, adGroupID)
+	result.CampaignID = campaignID
+	result.AdGroupID = adGroupID
 
 	// Store deployment details in metadata
 	deployment := models.GoogleAdsDeployment{
@@ -174,6 +265,7 @@ func (c *Client) deployResponsiveSearchAd(ctx context.Context, request *models.D
 
 	result.PlatformID = adID
 	result.PlatformURL = fmt.Sprintf("https://ads.google.com/aw/ads?campaignId=%s&adGroupId=%s", campaignID, adGroupID)
+	result.CampaignID = campaignID
 
 	return nil
 }
@@ -198,31 +290,132 @@ func (c *Client) deployVideoAd(ctx context.Context, request *models.DeploymentRe
 
 	result.PlatformID = adID
 	result.PlatformURL = fmt.Sprintf("https://ads.google.com/aw/ads?campaignId=%s&adGroupId=%s", campaignID, adGroupID)
+	result.CampaignID = campaignID
 
 	return nil
 }
 
 // createOrGetCampaign creates a new campaign or returns existing one
 func (c *Client) createOrGetCampaign(ctx context.Context, request *models.DeploymentRequest) (string, error) {
-	// In a real implementation, you would:
-	// 1. Check if a campaign already exists for this project/strategy
-	// 2. Create a new campaign if needed
-	// 3. Configure campaign settings based on metadata
-
 	campaignName := fmt.Sprintf("ZAMC-%s-%s", request.ProjectID.String()[:8], request.StrategyID.String()[:8])
-	
-	// For demo purposes, return a mock campaign ID
-	// In production, you would use the Google Ads API to create the campaign
-	campaignID := fmt.Sprintf("campaign_%d", time.Now().Unix())
-	
+	return c.createCampaign(ctx, campaignName, "SEARCH", request)
+}
+
+// campaignBudgetMutation is the body of a campaignBudgets:mutate call creating a budget
+type campaignBudgetMutation struct {
+	Operations []struct {
+		Create campaignBudget `json:"create"`
+	} `json:"operations"`
+}
+
+type campaignBudget struct {
+	Name             string `json:"name"`
+	AmountMicros     int64  `json:"amountMicros,string"`
+	DeliveryMethod   string `json:"deliveryMethod"`
+	ExplicitlyShared bool   `json:"explicitlyShared"`
+}
+
+// campaignCreation is the body of a campaigns:mutate call creating a campaign
+// with the bidding of its deployment
+type campaignCreation struct {
+	Operations []struct {
+		Create campaign `json:"create"`
+	} `json:"operations"`
+}
+
+type campaign struct {
+	Name                   string `json:"name"`
+	Status                 string `json:"status"`
+	AdvertisingChannelType string `json:"advertisingChannelType"`
+	CampaignBudget         string `json:"campaignBudget"`
+	StartDate              string `json:"startDate"`
+	*CampaignBidding
+}
+
+// mutateResponse holds the resources created by a mutate call
+type mutateResponse struct {
+	Results []struct {
+		ResourceName string `json:"resourceName"`
+	} `json:"results"`
+}
+
+// createCampaign creates a campaign of channelType with a daily budget of the
+// deployment budget and the bidding strategy of its content type, and returns
+// its ID
+func (c *Client) createCampaign(ctx context.Context, name, channelType string, request *models.DeploymentRequest) (string, error) {
+	bidding, err := BuildCampaignBidding(c.Config().BiddingStrategyMap, request)
+	if err != nil {
+		return "", err
+	}
+
+	var budgetMutation campaignBudgetMutation
+	budgetMutation.Operations = make([]struct {
+		Create campaignBudget `json:"create"`
+	}, 1)
+	budgetMutation.Operations[0].Create = campaignBudget{
+		Name:           fmt.Sprintf("%s-%s", name, request.AssetID.String()[:8]),
+		AmountMicros:   int64(request.Metadata.Budget * 1_000_000),
+		DeliveryMethod: "STANDARD",
+	}
+	budgetID, err := c.mutate(ctx, "campaignBudgets", budgetMutation)
+	if err != nil {
+		return "", fmt.Errorf("failed to create campaign budget: %w", err)
+	}
+
+	var mutation campaignCreation
+	mutation.Operations = make([]struct {
+		Create campaign `json:"create"`
+	}, 1)
+	mutation.Operations[0].Create = campaign{
+		Name:                   name,
+		Status:                 "ENABLED",
+		AdvertisingChannelType: channelType,
+		CampaignBudget:         fmt.Sprintf("customers/%s/campaignBudgets/%s", c.customerID, budgetID),
+		StartDate:              campaignStartDate(request),
+		CampaignBidding:        bidding,
+	}
+	campaignID, err := c.mutate(ctx, "campaigns", mutation)
+	if err != nil {
+		return "", err
+	}
+
 	c.logger.WithFields(logrus.Fields{
-		"campaign_name": campaignName,
-		"campaign_id":   campaignID,
-	}).Info("Created/retrieved Google Ads campaign")
+		"campaign_name":            name,
+		"campaign_id":              campaignID,
+		"advertising_channel_type": channelType,
+		"start_date":               mutation.Operations[0].Create.StartDate,
+		"bidding_strategy":         biddingStrategyType(bidding),
+	}).Info("Created Google Ads campaign")
 
 	return campaignID, nil
 }
 
+// mutate creates the resource of the single operation of body with a
+// {resource}:mutate call, and returns its ID
+func (c *Client) mutate(ctx context.Context, resource string, body interface{}) (string, error) {
+	var response mutateResponse
+	endpoint := fmt.Sprintf("customers/%s/%s:mutate", c.customerID, resource)
+	if err := c.postAPIObject(ctx, endpoint, body, &response); err != nil {
+		return "", err
+	}
+	if len(response.Results) == 0 {
+		return "", fmt.Errorf("no %s created", resource)
+	}
+
+	resourceName := response.Results[0].ResourceName
+	return resourceName[strings.LastIndex(resourceName, "/")+1:], nil
+}
+
+// campaignStartDate returns the campaign.start_date, in the YYYY-MM-DD format of
+// the Google Ads API, of a deployment: the UTC day it was scheduled for, or today
+func campaignStartDate(request *models.DeploymentRequest) string {
+	start := time.Now()
+	if request.ScheduledAt != nil {
+		start = *request.ScheduledAt
+	}
+	return start.UTC().Format("2006-01-02")
+}
+
 // createOrGetAdGroup creates a new ad group or returns existing one
 func (c *Client) createOrGetAdGroup(ctx context.Context, campaignID string, request *models.DeploymentRequest) (string, error) {
 	adGroupName := fmt.Sprintf("AdGroup-%s", request.ContentType)
@@ -241,18 +434,18 @@ func (c *Client) createOrGetAdGroup(ctx context.Context, campaignID string, requ
 
 // createTextAd creates a text ad
 func (c *Client) createTextAd(ctx context.Context, adGroupID string, request *models.DeploymentRequest) (string, error) {
-	// Extract headlines and descriptions from content
-	headlines := c.extractHeadlines(request.Content, request.Metadata.CreativeSpecs.Headline)
-	descriptions := c.extractDescriptions(request.Content, request.Metadata.CreativeSpecs.Description)
-	
+	ad := c.BuildTextAd(request)
+
 	// For demo purposes, return a mock ad ID
 	adID := fmt.Sprintf("ad_%d", time.Now().Unix())
 	
 	c.logger.WithFields(logrus.Fields{
-		"ad_id":        adID,
-		"ad_group_id":  adGroupID,
-		"headlines":    len(headlines),
-		"descriptions": len(descriptions),
+		"ad_id":             adID,
+		"ad_group_id":       adGroupID,
+		"ad_name":           ad.Name,
+		"headlines":         len(ad.Headlines),
+		"descriptions":      len(ad.Descriptions),
+		"custom_parameters": ad.URLCustomParameters,
 	}).Info("Created Google Ads text ad")
 
 	return adID, nil
@@ -288,9 +481,18 @@ func (c *Client) createVideoAd(ctx context.Context, adGroupID string, request *m
 
 // createOrGetVideoCampaign creates a video campaign
 func (c *Client) createOrGetVideoCampaign(ctx context.Context, request *models.DeploymentRequest) (string, error) {
+	bidding, err := BuildCampaignBidding(c.Config().BiddingStrategyMap, request)
+	if err != nil {
+		return "", err
+	}
+
 	campaignID := fmt.Sprintf("video_campaign_%d", time.Now().Unix())
 	
-	c.logger.WithField("campaign_id", campaignID).Info("Created Google Ads video campaign")
+	c.logger.WithFields(logrus.Fields{
+		"campaign_id":      campaignID,
+		"start_date":       campaignStartDate(request),
+		"bidding_strategy": biddingStrategyType(bidding),
+	}).Info("Created Google Ads video campaign")
 	
 	return campaignID, nil
 }
@@ -381,8 +583,12 @@ func (c *Client) truncateText(text string, maxLength int) string {
 
 // HealthCheck checks the health of the Google Ads client
 func (c *Client) HealthCheck(ctx context.Context) error {
+	c.mu.RLock()
+	service := c.service
+	c.mu.RUnlock()
+
 	// Try to make a simple API call to verify connectivity
-	if c.service == nil {
+	if service == nil {
 		return fmt.Errorf("Google Ads service is not initialized")
 	}
 
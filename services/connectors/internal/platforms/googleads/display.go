@@ -0,0 +1,184 @@
+package googleads
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+// Responsive display ad limits
+const (
+	MaxDisplayHeadlines          = 5
+	MaxDisplayHeadlineLength     = 30
+	MaxDisplayLongHeadlineLength = 90
+	MaxDisplayDescriptions       = 5
+	MaxDisplayDescriptionLength  = 90
+	MaxDisplayBusinessNameLength = 25
+)
+
+// ResponsiveDisplayAd is the creative of a display network ad. Google assembles the
+// served ad from its headlines, descriptions, images and logos to fit each placement.
+type ResponsiveDisplayAd struct {
+	Headlines        []string `json:"headlines"`
+	LongHeadline     string   `json:"longHeadline"`
+	Descriptions     []string `json:"descriptions"`
+	BusinessName     string   `json:"businessName"`
+	MarketingImages  []string `json:"marketingImages"`
+	LogoImages       []string `json:"logoImages"`
+	CallToActionText string   `json:"callToActionText,omitempty"`
+	FinalURLs        []string `json:"finalUrls,omitempty"`
+}
+
+// Validate checks the ad against the responsive display ad limits
+func (ad *ResponsiveDisplayAd) Validate() error {
+	if len(ad.Headlines) == 0 {
+		return fmt.Errorf("at least one headline is required")
+	}
+	if len(ad.Headlines) > MaxDisplayHeadlines {
+		return fmt.Errorf("at most %d headlines are allowed, got %d", MaxDisplayHeadlines, len(ad.Headlines))
+	}
+	for _, headline := range ad.Headlines {
+		if utf8.RuneCountInString(headline) > MaxDisplayHeadlineLength {
+			return fmt.Errorf("headline %q exceeds %d characters", headline, MaxDisplayHeadlineLength)
+		}
+	}
+
+	if ad.LongHeadline == "" {
+		return fmt.Errorf("long headline is required")
+	}
+	if utf8.RuneCountInString(ad.LongHeadline) > MaxDisplayLongHeadlineLength {
+		return fmt.Errorf("long headline exceeds %d characters", MaxDisplayLongHeadlineLength)
+	}
+
+	if len(ad.Descriptions) == 0 {
+		return fmt.Errorf("at least one description is required")
+	}
+	if len(ad.Descriptions) > MaxDisplayDescriptions {
+		return fmt.Errorf("at most %d descriptions are allowed, got %d", MaxDisplayDescriptions, len(ad.Descriptions))
+	}
+	for _, description := range ad.Descriptions {
+		if utf8.RuneCountInString(description) > MaxDisplayDescriptionLength {
+			return fmt.Errorf("description %q exceeds %d characters", description, MaxDisplayDescriptionLength)
+		}
+	}
+
+	if ad.BusinessName == "" {
+		return fmt.Errorf("business name is required")
+	}
+	if utf8.RuneCountInString(ad.BusinessName) > MaxDisplayBusinessNameLength {
+		return fmt.Errorf("business name exceeds %d characters", MaxDisplayBusinessNameLength)
+	}
+
+	if len(ad.MarketingImages) == 0 {
+		return fmt.Errorf("at least one image is required")
+	}
+	if len(ad.LogoImages) == 0 {
+		return fmt.Errorf("at least one logo is required")
+	}
+
+	return nil
+}
+
+// BuildResponsiveDisplayAd constructs a responsive display ad from the request's
+// creative specs and content. Text is shortened to the display limits; the ad is
+// rejected when required creative such as the image or logo is missing.
+func (c *Client) BuildResponsiveDisplayAd(request *models.DeploymentRequest) (*ResponsiveDisplayAd, error) {
+	specs := request.Metadata.CreativeSpecs
+
+	headlines := c.extractHeadlines(request.Content, specs.Headline)
+	if len(headlines) > MaxDisplayHeadlines {
+		headlines = headlines[:MaxDisplayHeadlines]
+	}
+
+	descriptions := c.extractDescriptions(request.Content, specs.Description)
+	if len(descriptions) > MaxDisplayDescriptions {
+		descriptions = descriptions[:MaxDisplayDescriptions]
+	}
+
+	longHeadline := specs.Headline
+	if longHeadline == "" {
+		longHeadline = request.Title
+	}
+
+	ad := &ResponsiveDisplayAd{
+		Headlines:        headlines,
+		LongHeadline:     c.truncateText(longHeadline, MaxDisplayLongHeadlineLength),
+		Descriptions:     descriptions,
+		BusinessName:     specs.BusinessName,
+		CallToActionText: specs.CallToAction,
+	}
+	if specs.ImageURL != "" {
+		ad.MarketingImages = []string{specs.ImageURL}
+	}
+	if specs.LogoURL != "" {
+		ad.LogoImages = []string{specs.LogoURL}
+	}
+	if specs.LandingURL != "" {
+		ad.FinalURLs = []string{specs.LandingURL}
+	}
+
+	if err := ad.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid responsive display ad: %w", err)
+	}
+
+	return ad, nil
+}
+
+// deployDisplayAd deploys a responsive display ad on the display network
+func (c *Client) deployDisplayAd(ctx context.Context, request *models.DeploymentRequest, result *models.DeploymentResult) error {
+	// Build the creative first so that invalid specs fail before anything is created
+	ad, err := c.BuildResponsiveDisplayAd(request)
+	if err != nil {
+		return err
+	}
+
+	campaignID, err := c.createOrGetDisplayCampaign(ctx, request)
+	if err != nil {
+		return fmt.Errorf("failed to create/get display campaign: %w", err)
+	}
+
+	adGroupID, err := c.createOrGetAdGroup(ctx, campaignID, request)
+	if err != nil {
+		return fmt.Errorf("failed to create/get ad group: %w", err)
+	}
+
+	adID, err := c.createResponsiveDisplayAd(ctx, adGroupID, ad)
+	if err != nil {
+		return fmt.Errorf("failed to create responsive display ad: %w", err)
+	}
+
+	result.PlatformID = adID
+	result.PlatformURL = fmt.Sprintf("https://ads.google.com/aw/ads?campaignId=%s&adGroupId=%s", campaignID, adGroupID)
+	result.CampaignID = campaignID
+
+	return nil
+}
+
+// createOrGetDisplayCampaign creates a display network campaign
+func (c *Client) createOrGetDisplayCampaign(ctx context.Context, request *models.DeploymentRequest) (string, error) {
+	campaignName := fmt.Sprintf("ZAMC-Display-%s-%s", request.ProjectID.String()[:8], request.StrategyID.String()[:8])
+
+	return c.createCampaign(ctx, campaignName, "DISPLAY", request)
+}
+
+// createResponsiveDisplayAd creates a responsive display ad
+func (c *Client) createResponsiveDisplayAd(ctx context.Context, adGroupID string, ad *ResponsiveDisplayAd) (string, error) {
+	adID := fmt.Sprintf("rda_%d", time.Now().Unix())
+
+	c.logger.WithFields(logrus.Fields{
+		"ad_id":        adID,
+		"ad_group_id":  adGroupID,
+		"ad_type":      "responsive_display_ad",
+		"headlines":    len(ad.Headlines),
+		"descriptions": len(ad.Descriptions),
+		"images":       len(ad.MarketingImages),
+		"logos":        len(ad.LogoImages),
+	}).Info("Created Google Ads responsive display ad")
+
+	return adID, nil
+}
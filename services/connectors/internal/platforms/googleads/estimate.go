@@ -0,0 +1,184 @@
+package googleads
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+const (
+	// forecastDays is the length of the forecast period
+	forecastDays = 7
+
+	// forecastConfidenceClicks is the forecast click volume at which an
+	// estimate is considered 50% reliable
+	forecastConfidenceClicks = 100.0
+)
+
+// keywordForecastRequest is the body of a GenerateKeywordForecastMetrics call
+type keywordForecastRequest struct {
+	CurrencyCode   string           `json:"currencyCode,omitempty"`
+	ForecastPeriod forecastPeriod   `json:"forecastPeriod"`
+	Campaign       forecastCampaign `json:"campaign"`
+}
+
+type forecastPeriod struct {
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+}
+
+type forecastCampaign struct {
+	KeywordPlanNetwork string                  `json:"keywordPlanNetwork"`
+	BiddingStrategy    forecastBiddingStrategy `json:"biddingStrategy"`
+	AdGroups           []forecastAdGroup       `json:"adGroups"`
+}
+
+type forecastBiddingStrategy struct {
+	MaximizeClicksBiddingStrategy struct {
+		DailyTargetSpendMicros int64 `json:"dailyTargetSpendMicros,string"`
+	} `json:"maximizeClicksBiddingStrategy"`
+}
+
+type forecastAdGroup struct {
+	BiddableKeywords []forecastKeyword `json:"biddableKeywords"`
+}
+
+type forecastKeyword struct {
+	Keyword struct {
+		Text      string `json:"text"`
+		MatchType string `json:"matchType"`
+	} `json:"keyword"`
+}
+
+// keywordForecastResponse holds the fields read from a GenerateKeywordForecastMetrics reply
+type keywordForecastResponse struct {
+	CampaignForecastMetrics struct {
+		Impressions float64 `json:"impressions"`
+		Clicks      float64 `json:"clicks"`
+		CostMicros  int64   `json:"costMicros,string"`
+	} `json:"campaignForecastMetrics"`
+}
+
+// EstimateCampaignCost forecasts the weekly impressions, clicks and spend of a search
+// campaign for the request's keywords, capped at its daily budget. Nothing is created
+// in the account.
+func (c *Client) EstimateCampaignCost(ctx context.Context, request *models.DeploymentRequest) (*models.CostEstimate, error) {
+	if len(request.Metadata.Keywords) == 0 {
+		return nil, fmt.Errorf("keywords are required to estimate Google Ads cost")
+	}
+
+	forecast := keywordForecastRequest{
+		CurrencyCode: c.Config().Currency,
+		ForecastPeriod: forecastPeriod{
+			StartDate: time.Now().AddDate(0, 0, 1).Format("2006-01-02"),
+			EndDate:   time.Now().AddDate(0, 0, forecastDays).Format("2006-01-02"),
+		},
+		Campaign: forecastCampaign{
+			KeywordPlanNetwork: "GOOGLE_SEARCH",
+		},
+	}
+	forecast.Campaign.BiddingStrategy.MaximizeClicksBiddingStrategy.DailyTargetSpendMicros = int64(request.Metadata.Budget * 1e6)
+
+	adGroup := forecastAdGroup{}
+	for _, text := range request.Metadata.Keywords {
+		keyword := forecastKeyword{}
+		keyword.Keyword.Text = text
+		keyword.Keyword.MatchType = "BROAD"
+		adGroup.BiddableKeywords = append(adGroup.BiddableKeywords, keyword)
+	}
+	forecast.Campaign.AdGroups = []forecastAdGroup{adGroup}
+
+	var response keywordForecastResponse
+	endpoint := fmt.Sprintf("customers/%s:generateKeywordForecastMetrics", c.customerID)
+	if err := c.postAPIObject(ctx, endpoint, forecast, &response); err != nil {
+		return nil, fmt.Errorf("failed to generate keyword forecast: %w", err)
+	}
+
+	metrics := response.CampaignForecastMetrics
+	estimate := &models.CostEstimate{
+		EstimatedImpressions: int64(metrics.Impressions),
+		EstimatedClicks:      int64(metrics.Clicks),
+		EstimatedSpend:       float64(metrics.CostMicros) / 1e6,
+		Currency:             currencyOrDefault(c.Config().Currency),
+		Confidence:           metrics.Clicks / (metrics.Clicks + forecastConfidenceClicks),
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"asset_id":    request.AssetID,
+		"keywords":    len(request.Metadata.Keywords),
+		"impressions": estimate.EstimatedImpressions,
+		"spend":       estimate.EstimatedSpend,
+	}).Info("Generated Google Ads cost estimate")
+
+	return estimate, nil
+}
+
+// postAPIObject sends data to a Google Ads REST endpoint and decodes the JSON response into out
+func (c *Client) postAPIObject(ctx context.Context, endpoint string, data interface{}, out interface{}) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request data: %w", err)
+	}
+
+	if err := c.checkQuota(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/%s", c.baseURL, endpoint), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	token, err := c.token()
+	if err != nil {
+		return fmt.Errorf("failed to obtain access token: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token.AccessToken))
+	req.Header.Set("developer-token", c.Config().DeveloperToken)
+	if c.Config().LoginCustomerID != "" {
+		req.Header.Set("login-customer-id", strings.ReplaceAll(c.Config().LoginCustomerID, "-", ""))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make API call: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if err := c.observeQuota(resp, respBody); err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 400 {
+		return &models.APIError{StatusCode: resp.StatusCode, Message: string(respBody)}
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return nil
+}
+
+func currencyOrDefault(currency string) string {
+	if currency == "" {
+		return "USD"
+	}
+	return currency
+}
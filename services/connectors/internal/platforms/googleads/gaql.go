@@ -0,0 +1,22 @@
+package googleads
+
+import (
+	"fmt"
+	"strings"
+)
+
+// gaqlEscaper escapes the characters that would end a GAQL string literal
+var gaqlEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// gaqlString quotes s as a GAQL string literal
+func gaqlString(s string) string {
+	return "'" + gaqlEscaper.Replace(s) + "'"
+}
+
+// resourceName is the resource name of the object with id in collection of the
+// customer, such as customers/123/campaigns/456. Objects are filtered by their
+// resource name rather than their numeric ID in GAQL, so that platform IDs are
+// treated as opaque strings.
+func (c *Client) resourceName(collection, id string) string {
+	return fmt.Sprintf("customers/%s/%s/%s", c.customerID, collection, id)
+}
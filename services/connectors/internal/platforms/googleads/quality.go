@@ -0,0 +1,82 @@
+package googleads
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+// keywordQualityQuery selects the keywords of an ad group with their quality score
+const keywordQualityQuery = `SELECT ad_group_criterion.criterion_id, ad_group_criterion.keyword.text, ad_group_criterion.quality_info.quality_score FROM ad_group_criterion WHERE ad_group_criterion.type = 'KEYWORD' AND ad_group.resource_name = %s`
+
+// keywordQualitySearchRequest is the body of a googleAds:search call
+type keywordQualitySearchRequest struct {
+	Query     string `json:"query"`
+	PageToken string `json:"pageToken,omitempty"`
+}
+
+// keywordQualitySearchResponse holds one page of keyword quality rows
+type keywordQualitySearchResponse struct {
+	Results []struct {
+		AdGroupCriterion struct {
+			CriterionID string `json:"criterionId"`
+			Keyword     struct {
+				Text string `json:"text"`
+			} `json:"keyword"`
+			QualityInfo struct {
+				QualityScore int `json:"qualityScore"`
+			} `json:"qualityInfo"`
+		} `json:"adGroupCriterion"`
+	} `json:"results"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// FetchKeywordQualityScores returns the quality scores of the keywords of an ad
+// group. Keywords that have not served enough to be scored yet are left out.
+func (c *Client) FetchKeywordQualityScores(ctx context.Context, adGroupID string) ([]models.KeywordQualityScore, error) {
+	endpoint := fmt.Sprintf("customers/%s/googleAds:search", c.customerID)
+	request := keywordQualitySearchRequest{Query: fmt.Sprintf(keywordQualityQuery, gaqlString(c.resourceName("adGroups", adGroupID)))}
+	fetchedAt := time.Now()
+
+	var scores []models.KeywordQualityScore
+	unscored := 0
+	for {
+		var response keywordQualitySearchResponse
+		if err := c.postAPIObject(ctx, endpoint, request, &response); err != nil {
+			return nil, fmt.Errorf("failed to query keyword quality scores: %w", err)
+		}
+
+		for _, row := range response.Results {
+			criterion := row.AdGroupCriterion
+			if criterion.QualityInfo.QualityScore == 0 {
+				unscored++
+				continue
+			}
+
+			scores = append(scores, models.KeywordQualityScore{
+				KeywordID: criterion.CriterionID,
+				AdGroupID: adGroupID,
+				Keyword:   criterion.Keyword.Text,
+				Score:     criterion.QualityInfo.QualityScore,
+				FetchedAt: fetchedAt,
+			})
+		}
+
+		if response.NextPageToken == "" {
+			break
+		}
+		request.PageToken = response.NextPageToken
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"ad_group_id": adGroupID,
+		"scored":      len(scores),
+		"unscored":    unscored,
+	}).Info("Fetched Google Ads keyword quality scores")
+
+	return scores, nil
+}
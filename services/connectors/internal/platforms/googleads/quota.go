@@ -0,0 +1,37 @@
+package googleads
+
+import (
+	"net/http"
+
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/ratelimit"
+)
+
+// SetRateLimiter makes the client track the API quota of its customer account in
+// limiter and hold calls back while the account is backing off
+func (c *Client) SetRateLimiter(limiter *ratelimit.PlatformRateLimiter) {
+	c.rateLimiter = limiter
+}
+
+// checkQuota returns a *ratelimit.QuotaError while the customer account is
+// backing off
+func (c *Client) checkQuota() error {
+	if c.rateLimiter == nil {
+		return nil
+	}
+	return c.rateLimiter.Allow(models.PlatformGoogleAds, c.customerID)
+}
+
+// observeQuota records the quota left reported by resp, and returns a
+// *ratelimit.QuotaError when Google Ads refused the call with RESOURCE_EXHAUSTED
+func (c *Client) observeQuota(resp *http.Response, body []byte) error {
+	if c.rateLimiter == nil {
+		return nil
+	}
+
+	c.rateLimiter.Observe(models.PlatformGoogleAds, c.customerID, resp.Header)
+	if resp.StatusCode >= 400 && ratelimit.IsGoogleAdsQuotaError(body) {
+		return c.rateLimiter.Exhausted(models.PlatformGoogleAds, c.customerID)
+	}
+	return nil
+}
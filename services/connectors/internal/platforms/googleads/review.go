@@ -0,0 +1,64 @@
+package googleads
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+// adReviewQuery selects the policy summary of an ad
+const adReviewQuery = `SELECT ad_group_ad.ad.id, ad_group_ad.policy_summary.review_status, ad_group_ad.policy_summary.approval_status, ad_group_ad.policy_summary.policy_topic_entries FROM ad_group_ad WHERE ad_group_ad.ad.resource_name = %s`
+
+// adReviewSearchResponse holds the rows of a googleAds:search call selecting ad policy summaries
+type adReviewSearchResponse struct {
+	Results []struct {
+		AdGroupAd struct {
+			PolicySummary struct {
+				ReviewStatus       string `json:"reviewStatus"`
+				ApprovalStatus     string `json:"approvalStatus"`
+				PolicyTopicEntries []struct {
+					Topic string `json:"topic"`
+					Type  string `json:"type"`
+				} `json:"policyTopicEntries"`
+			} `json:"policySummary"`
+		} `json:"adGroupAd"`
+	} `json:"results"`
+}
+
+// GetAdReviewStatus returns the policy review outcome of an ad. The topics of
+// prohibited policy entries are the disapproval reasons; every policy topic found
+// in the ad is a policy violation.
+func (c *Client) GetAdReviewStatus(ctx context.Context, platformAdID string) (*models.AdReviewStatus, error) {
+	var response adReviewSearchResponse
+	endpoint := fmt.Sprintf("customers/%s/googleAds:search", c.customerID)
+	query := fmt.Sprintf(adReviewQuery, gaqlString(c.resourceName("ads", platformAdID)))
+	if err := c.postAPIObject(ctx, endpoint, map[string]string{"query": query}, &response); err != nil {
+		return nil, fmt.Errorf("failed to query ad review status: %w", err)
+	}
+	if len(response.Results) == 0 {
+		return nil, fmt.Errorf("ad %s not found", platformAdID)
+	}
+
+	summary := response.Results[0].AdGroupAd.PolicySummary
+	status := &models.AdReviewStatus{}
+	switch {
+	case summary.ApprovalStatus == "DISAPPROVED":
+		status.Status = models.AdReviewStatusRejected
+	case summary.ReviewStatus == "REVIEW_IN_PROGRESS" || summary.ReviewStatus == "UNDER_APPEAL":
+		status.Status = models.AdReviewStatusPending
+	case summary.ApprovalStatus == "APPROVED" || summary.ApprovalStatus == "APPROVED_LIMITED" || summary.ApprovalStatus == "AREA_OF_INTEREST_ONLY":
+		status.Status = models.AdReviewStatusApproved
+	default:
+		status.Status = models.AdReviewStatusPending
+	}
+
+	for _, entry := range summary.PolicyTopicEntries {
+		status.PolicyViolations = append(status.PolicyViolations, entry.Topic)
+		if entry.Type == "PROHIBITED" {
+			status.DisapprovalReasons = append(status.DisapprovalReasons, entry.Topic)
+		}
+	}
+
+	return status, nil
+}
@@ -0,0 +1,28 @@
+package googleads
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PauseAd stops a deployed ad from serving by pausing its campaign. Every
+// deployment creates its own campaign, so no other asset's ad is paused.
+func (c *Client) PauseAd(ctx context.Context, campaignID, adGroupID, adID string) error {
+	if campaignID == "" {
+		return fmt.Errorf("campaign of ad %s is unknown", adID)
+	}
+
+	if err := c.setCampaignStatus(ctx, campaignID, "PAUSED"); err != nil {
+		return fmt.Errorf("failed to pause ad %s: %w", adID, err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"campaign_id": campaignID,
+		"ad_group_id": adGroupID,
+		"ad_id":       adID,
+	}).Info("Paused Google Ads ad")
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package googleads
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/scheduler"
+)
+
+// campaignStatusMutation is the body of a campaigns:mutate call changing a campaign's status
+type campaignStatusMutation struct {
+	Operations []campaignStatusOperation `json:"operations"`
+}
+
+type campaignStatusOperation struct {
+	Update struct {
+		ResourceName string `json:"resourceName"`
+		Status       string `json:"status"`
+	} `json:"update"`
+	UpdateMask string `json:"updateMask"`
+}
+
+// campaignSearchResponse holds the rows of a googleAds:search call selecting campaigns
+type campaignSearchResponse struct {
+	Results []struct {
+		Campaign struct {
+			ID     string `json:"id"`
+			Status string `json:"status"`
+		} `json:"campaign"`
+	} `json:"results"`
+}
+
+// SetScheduler sets the worker that runs the campaign schedules created by the client
+func (c *Client) SetScheduler(worker *scheduler.SchedulerWorker) {
+	c.scheduler = worker
+}
+
+// ScheduleCampaignPauseResume pauses the campaign of tenantID whenever pauseCron
+// matches and resumes it whenever resumeCron matches. Both are standard
+// five-field cron expressions evaluated in timezone. Any existing schedule of the
+// campaign is replaced.
+func (c *Client) ScheduleCampaignPauseResume(ctx context.Context, tenantID, platformCampaignID, pauseCron, resumeCron string, timezone string) error {
+	if c.scheduler == nil {
+		return fmt.Errorf("campaign scheduling is not configured")
+	}
+
+	if err := scheduler.Validate(pauseCron, resumeCron, timezone); err != nil {
+		return err
+	}
+
+	var response campaignSearchResponse
+	endpoint := fmt.Sprintf("customers/%s/googleAds:search", c.customerID)
+	query := fmt.Sprintf("SELECT campaign.id, campaign.status FROM campaign WHERE campaign.resource_name = %s", gaqlString(c.resourceName("campaigns", platformCampaignID)))
+	if err := c.postAPIObject(ctx, endpoint, map[string]string{"query": query}, &response); err != nil {
+		return fmt.Errorf("failed to read campaign: %w", err)
+	}
+	if len(response.Results) == 0 {
+		return fmt.Errorf("campaign %s not found", platformCampaignID)
+	}
+
+	return c.scheduler.ScheduleCampaign(ctx, tenantID, &models.CampaignSchedule{
+		Platform:           models.PlatformGoogleAds,
+		PlatformCampaignID: platformCampaignID,
+		PauseCron:          pauseCron,
+		ResumeCron:         resumeCron,
+		Timezone:           timezone,
+	})
+}
+
+// PauseCampaign stops a campaign from serving
+func (c *Client) PauseCampaign(ctx context.Context, platformCampaignID string) error {
+	return c.setCampaignStatus(ctx, platformCampaignID, "PAUSED")
+}
+
+// ResumeCampaign lets a paused campaign serve again
+func (c *Client) ResumeCampaign(ctx context.Context, platformCampaignID string) error {
+	return c.setCampaignStatus(ctx, platformCampaignID, "ENABLED")
+}
+
+// setCampaignStatus updates the status of a campaign
+func (c *Client) setCampaignStatus(ctx context.Context, platformCampaignID, status string) error {
+	operation := campaignStatusOperation{UpdateMask: "status"}
+	operation.Update.ResourceName = c.resourceName("campaigns", platformCampaignID)
+	operation.Update.Status = status
+
+	var response struct {
+		Results []struct {
+			ResourceName string `json:"resourceName"`
+		} `json:"results"`
+	}
+	endpoint := fmt.Sprintf("customers/%s/campaigns:mutate", c.customerID)
+	if err := c.postAPIObject(ctx, endpoint, campaignStatusMutation{Operations: []campaignStatusOperation{operation}}, &response); err != nil {
+		return fmt.Errorf("failed to set campaign status to %s: %w", status, err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"campaign_id": platformCampaignID,
+		"status":      status,
+	}).Info("Updated Google Ads campaign status")
+
+	return nil
+}
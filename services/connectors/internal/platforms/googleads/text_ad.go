@@ -0,0 +1,37 @@
+package googleads
+
+import (
+	"fmt"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+// VariantIDParameter is the URL custom parameter holding the A/B test variant
+// of an ad. Google only allows alphanumeric custom parameter keys.
+const VariantIDParameter = "variantid"
+
+// TextAd is the creative of a search network text ad
+type TextAd struct {
+	Name                string            `json:"name"`
+	Headlines           []string          `json:"headlines"`
+	Descriptions        []string          `json:"descriptions"`
+	URLCustomParameters map[string]string `json:"urlCustomParameters,omitempty"`
+}
+
+// BuildTextAd constructs a text ad from the request's creative specs and content.
+// The variant label of an A/B test is appended to the ad name and its variant ID
+// kept as a custom parameter, so that the variants can be compared in reports.
+func (c *Client) BuildTextAd(request *models.DeploymentRequest) *TextAd {
+	specs := request.Metadata.CreativeSpecs
+
+	ad := &TextAd{
+		Name:         specs.VariantName(fmt.Sprintf("TextAd-%s-%s", request.ContentType, request.AssetID.String()[:8])),
+		Headlines:    c.extractHeadlines(request.Content, specs.Headline),
+		Descriptions: c.extractDescriptions(request.Content, specs.Description),
+	}
+	if specs.VariantID != "" {
+		ad.URLCustomParameters = map[string]string{VariantIDParameter: specs.VariantID}
+	}
+
+	return ad
+}
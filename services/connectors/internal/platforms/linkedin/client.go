@@ -0,0 +1,714 @@
+package linkedin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+)
+
+const (
+	// apiVersion is the LinkedIn Marketing API version requests are made against
+	apiVersion = "202401"
+
+	// maxCommentaryLength is the longest post commentary LinkedIn accepts for
+	// Sponsored Content
+	maxCommentaryLength = 600
+
+	// maxMediaSize is the largest image or video uploaded. LinkedIn accepts
+	// videos of up to 200 MB in Sponsored Content.
+	maxMediaSize = 200 << 20
+)
+
+// CreativeType is the type of a LinkedIn Sponsored Content creative
+type CreativeType string
+
+const (
+	CreativeTypeSingleImage CreativeType = "SINGLE_IMAGE"
+	CreativeTypeVideo       CreativeType = "SINGLE_VIDEO"
+)
+
+// CreativeTypeFor returns the type of the creative deploying request creates.
+// Social media content becomes a single image ad and video scripts video ads.
+func CreativeTypeFor(request *models.DeploymentRequest) (CreativeType, error) {
+	switch request.ContentType {
+	case models.ContentTypeSocialMedia:
+		return CreativeTypeSingleImage, nil
+	case models.ContentTypeVideoScript:
+		return CreativeTypeVideo, nil
+	default:
+		return "", fmt.Errorf("content type %s cannot be deployed to LinkedIn", request.ContentType)
+	}
+}
+
+// countryGeoIDs maps ISO 3166 country codes to the IDs of their LinkedIn geo URNs
+var countryGeoIDs = map[string]string{
+	"AU": "101452733",
+	"BR": "106057199",
+	"CA": "101174742",
+	"DE": "101282230",
+	"ES": "105646813",
+	"FR": "105015875",
+	"GB": "101165590",
+	"IE": "104738515",
+	"IN": "102713980",
+	"IT": "103350119",
+	"JP": "101355337",
+	"MX": "103323778",
+	"NL": "102890719",
+	"SG": "102454443",
+	"US": "103644278",
+}
+
+// GeoURNs converts locations to LinkedIn geo URNs. A location is a country code,
+// a geo ID or a geo URN.
+func GeoURNs(locations []string) ([]string, error) {
+	urns := make([]string, 0, len(locations))
+	for _, location := range locations {
+		location = strings.TrimSpace(location)
+		switch {
+		case strings.HasPrefix(location, "urn:li:geo:"):
+			urns = append(urns, location)
+		case location != "" && strings.Trim(location, "0123456789") == "":
+			urns = append(urns, "urn:li:geo:"+location)
+		default:
+			id, ok := countryGeoIDs[strings.ToUpper(location)]
+			if !ok {
+				return nil, fmt.Errorf("unknown LinkedIn location %q, use a geo URN", location)
+			}
+			urns = append(urns, "urn:li:geo:"+id)
+		}
+	}
+
+	return urns, nil
+}
+
+// Client represents a LinkedIn Marketing API client
+type Client struct {
+	httpClient   *http.Client
+	uploadClient *http.Client
+	config       *config.LinkedInConfig
+	logger       *logrus.Logger
+	baseURL      string
+}
+
+// NewClient creates a new LinkedIn Marketing API client
+func NewClient(cfg *config.LinkedInConfig, logger *logrus.Logger) (*Client, error) {
+	if cfg.AdAccountID == "" {
+		return nil, fmt.Errorf("LinkedIn ad account ID is required")
+	}
+	if cfg.CampaignGroupID == "" {
+		return nil, fmt.Errorf("LinkedIn campaign group ID is required")
+	}
+	if cfg.OrganizationID == "" {
+		return nil, fmt.Errorf("LinkedIn organization ID is required")
+	}
+	if _, err := GeoURNs(cfg.DefaultLocations); err != nil {
+		return nil, fmt.Errorf("invalid LinkedIn default locations: %w", err)
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.linkedin.com/rest"
+	}
+
+	client := &Client{
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		// Media transfers take longer than API calls
+		uploadClient: &http.Client{
+			Timeout: 5 * time.Minute,
+		},
+		config:  cfg,
+		logger:  logger,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+
+	logger.WithFields(logrus.Fields{
+		"ad_account_id":     cfg.AdAccountID,
+		"campaign_group_id": cfg.CampaignGroupID,
+	}).Info("LinkedIn Marketing API client initialized")
+
+	return client, nil
+}
+
+// DeployAsset deploys an asset to LinkedIn as a Sponsored Content campaign with
+// a single image or video creative
+func (c *Client) DeployAsset(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error) {
+	startTime := time.Now()
+	logger := c.logger.WithFields(logrus.Fields{
+		"asset_id":     request.AssetID,
+		"content_type": request.ContentType,
+		"platform":     models.PlatformLinkedIn,
+	})
+
+	logger.Info("Starting LinkedIn deployment")
+
+	result := &models.DeploymentResult{
+		AssetID:    request.AssetID,
+		Platform:   models.PlatformLinkedIn,
+		Status:     models.DeploymentStatusRunning,
+		DeployedAt: time.Now(),
+		Metrics: models.DeploymentMetrics{
+			RetryCount: 0,
+		},
+	}
+
+	creativeType, err := CreativeTypeFor(request)
+	if err == nil {
+		err = c.deploy(ctx, request, creativeType, result)
+	}
+
+	// Update metrics
+	result.Metrics.Duration = time.Since(startTime)
+
+	if err != nil {
+		result.Status = models.DeploymentStatusFailed
+		result.Error = err.Error()
+		logger.WithError(err).Error("LinkedIn deployment failed")
+		return result, err
+	}
+
+	result.Status = models.DeploymentStatusSuccess
+	logger.WithFields(logrus.Fields{
+		"platform_id":  result.PlatformID,
+		"platform_url": result.PlatformURL,
+		"duration":     result.Metrics.Duration,
+	}).Info("LinkedIn deployment successful")
+
+	return result, nil
+}
+
+// deploy uploads the asset's media, creates the campaign targeting its
+// audience and sponsors a post of the media in it
+func (c *Client) deploy(ctx context.Context, request *models.DeploymentRequest, creativeType CreativeType, result *models.DeploymentResult) error {
+	specs := request.Metadata.CreativeSpecs
+
+	// Validate before anything is created in the ad account
+	targeting, err := c.buildTargeting(request.Metadata.Demographics)
+	if err != nil {
+		return err
+	}
+	if request.Metadata.Budget <= 0 {
+		return fmt.Errorf("a daily budget is required for LinkedIn campaigns")
+	}
+
+	var mediaURN string
+	switch creativeType {
+	case CreativeTypeVideo:
+		if specs.VideoURL == "" {
+			return fmt.Errorf("video URL is required for video ads")
+		}
+		if mediaURN, err = c.uploadVideo(ctx, specs.VideoURL); err != nil {
+			return fmt.Errorf("failed to upload video: %w", err)
+		}
+	default:
+		if specs.ImageURL == "" {
+			return fmt.Errorf("image URL is required for single image ads")
+		}
+		if mediaURN, err = c.uploadImage(ctx, specs.ImageURL); err != nil {
+			return fmt.Errorf("failed to upload image: %w", err)
+		}
+	}
+
+	// The campaign is created paused and only activated once its creative
+	// exists, so that a failed deployment leaves nothing serving
+	campaignURN, err := c.createCampaign(ctx, request, creativeType, targeting)
+	if err != nil {
+		return fmt.Errorf("failed to create campaign: %w", err)
+	}
+	campaignID := campaignURN[strings.LastIndex(campaignURN, ":")+1:]
+
+	creativeURN, err := c.createCreative(ctx, request, campaignURN, mediaURN)
+	if err == nil {
+		if err = c.setCampaignStatus(ctx, campaignID, "ACTIVE"); err != nil {
+			err = fmt.Errorf("failed to activate campaign: %w", err)
+		}
+	}
+	if err != nil {
+		c.archiveCampaign(campaignID)
+		return err
+	}
+
+	result.PlatformID = creativeURN
+	result.PlatformURL = fmt.Sprintf("https://www.linkedin.com/campaignmanager/accounts/%s/campaigns/%s/creatives",
+		c.config.AdAccountID, campaignID)
+
+	return nil
+}
+
+// createCreative sponsors a post of the media in the campaign and returns the
+// URN of the creative
+func (c *Client) createCreative(ctx context.Context, request *models.DeploymentRequest, campaignURN, mediaURN string) (string, error) {
+	postURN, err := c.createPost(ctx, request, mediaURN)
+	if err != nil {
+		return "", fmt.Errorf("failed to create post: %w", err)
+	}
+
+	creativeURN, err := c.makeAPICall(ctx, http.MethodPost, c.adAccountPath()+"/creatives", map[string]interface{}{
+		"campaign":       campaignURN,
+		"content":        map[string]interface{}{"reference": postURN},
+		"intendedStatus": "ACTIVE",
+	}, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create creative: %w", err)
+	}
+	if creativeURN == "" {
+		return "", fmt.Errorf("failed to create creative: no creative ID in response")
+	}
+
+	return creativeURN, nil
+}
+
+// setCampaignStatus changes the status of the campaign campaignID
+func (c *Client) setCampaignStatus(ctx context.Context, campaignID, status string) error {
+	_, err := c.call(ctx, http.MethodPost, c.adAccountPath()+"/adCampaigns/"+campaignID, "PARTIAL_UPDATE", map[string]interface{}{
+		"patch": map[string]interface{}{
+			"$set": map[string]string{"status": status},
+		},
+	}, nil)
+	return err
+}
+
+// archiveCampaign archives the paused campaign of a failed deployment. Paused
+// campaigns cannot be deleted, and archived ones no longer show among the
+// campaigns of the ad account. It runs on a context of its own, since the
+// deployment may have failed because its context was cancelled.
+func (c *Client) archiveCampaign(campaignID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := c.setCampaignStatus(ctx, campaignID, "ARCHIVED"); err != nil {
+		c.logger.WithError(err).WithField("campaign_id", campaignID).Error("Failed to archive campaign of failed LinkedIn deployment")
+	}
+}
+
+// buildTargeting returns the targeting criteria of a campaign, which include
+// the asset's locations, or the default locations when it names none
+func (c *Client) buildTargeting(demographics models.Demographics) (map[string]interface{}, error) {
+	locations := demographics.Locations
+	if len(locations) == 0 {
+		locations = c.config.DefaultLocations
+	}
+
+	geoURNs, err := GeoURNs(locations)
+	if err != nil {
+		return nil, err
+	}
+	if len(geoURNs) == 0 {
+		return nil, fmt.Errorf("at least one location is required for LinkedIn targeting")
+	}
+
+	return map[string]interface{}{
+		"include": map[string]interface{}{
+			"and": []map[string]interface{}{
+				{"or": map[string]interface{}{"urn:li:adTargetingFacet:locations": geoURNs}},
+			},
+		},
+	}, nil
+}
+
+// createCampaign creates a paused campaign for the asset in the configured
+// campaign group and returns its URN
+func (c *Client) createCampaign(ctx context.Context, request *models.DeploymentRequest, creativeType CreativeType, targeting map[string]interface{}) (string, error) {
+	objective := "WEBSITE_VISIT"
+	format := "STANDARD_UPDATE"
+	if creativeType == CreativeTypeVideo {
+		objective = "VIDEO_VIEW"
+		format = "SINGLE_VIDEO"
+	}
+
+	campaignURN, err := c.makeAPICall(ctx, http.MethodPost, c.adAccountPath()+"/adCampaigns", map[string]interface{}{
+		"account":       "urn:li:sponsoredAccount:" + c.config.AdAccountID,
+		"campaignGroup": "urn:li:sponsoredCampaignGroup:" + c.config.CampaignGroupID,
+		"name":          fmt.Sprintf("ZAMC - %s - %s", request.Title, request.AssetID),
+		"type":          "SPONSORED_UPDATES",
+		"format":        format,
+		"objectiveType": objective,
+		"costType":      "CPM",
+		"dailyBudget": map[string]string{
+			"amount":       fmt.Sprintf("%.2f", request.Metadata.Budget),
+			"currencyCode": c.config.Currency,
+		},
+		"locale":                 map[string]string{"country": "US", "language": "en"},
+		"offsiteDeliveryEnabled": false,
+		"runSchedule":            map[string]int64{"start": time.Now().UnixMilli()},
+		"targetingCriteria":      targeting,
+		"status":                 "PAUSED",
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+	if campaignURN == "" {
+		return "", fmt.Errorf("no campaign ID in response")
+	}
+
+	return "urn:li:sponsoredCampaign:" + strings.TrimPrefix(campaignURN, "urn:li:sponsoredCampaign:"), nil
+}
+
+// createPost creates a direct sponsored post of the media by the organization.
+// It is only shown as an ad, not on the organization's page.
+func (c *Client) createPost(ctx context.Context, request *models.DeploymentRequest, mediaURN string) (string, error) {
+	media := map[string]interface{}{"id": mediaURN, "title": request.Title}
+
+	post := map[string]interface{}{
+		"author":     "urn:li:organization:" + c.config.OrganizationID,
+		"commentary": c.commentary(request),
+		"visibility": "PUBLIC",
+		"distribution": map[string]interface{}{
+			"feedDistribution":               "NONE",
+			"targetEntities":                 []interface{}{},
+			"thirdPartyDistributionChannels": []interface{}{},
+		},
+		"content":                   map[string]interface{}{"media": media},
+		"adContext":                 map[string]interface{}{"dscAdAccount": "urn:li:sponsoredAccount:" + c.config.AdAccountID, "dscStatus": "ACTIVE"},
+		"lifecycleState":            "PUBLISHED",
+		"isReshareDisabledByAuthor": false,
+	}
+	if landingURL := request.Metadata.CreativeSpecs.LandingURL; landingURL != "" {
+		post["contentLandingPage"] = landingURL
+		post["contentCallToActionLabel"] = c.getCallToActionLabel(request.Metadata.CreativeSpecs.CallToAction)
+	}
+
+	postURN, err := c.makeAPICall(ctx, http.MethodPost, "/posts", post, nil)
+	if err != nil {
+		return "", err
+	}
+	if postURN == "" {
+		return "", fmt.Errorf("no post ID in response")
+	}
+
+	return postURN, nil
+}
+
+// uploadImage uploads the image at imageURL for the organization and returns its URN
+func (c *Client) uploadImage(ctx context.Context, imageURL string) (string, error) {
+	media, err := c.download(ctx, imageURL)
+	if err != nil {
+		return "", err
+	}
+	defer media.Close()
+
+	var upload struct {
+		Value struct {
+			UploadURL string `json:"uploadUrl"`
+			Image     string `json:"image"`
+		} `json:"value"`
+	}
+	_, err = c.makeAPICall(ctx, http.MethodPost, "/images?action=initializeUpload", map[string]interface{}{
+		"initializeUploadRequest": map[string]string{
+			"owner": "urn:li:organization:" + c.config.OrganizationID,
+		},
+	}, &upload)
+	if err != nil {
+		return "", err
+	}
+	if upload.Value.UploadURL == "" || upload.Value.Image == "" {
+		return "", fmt.Errorf("no upload URL in response")
+	}
+
+	if _, err := c.upload(ctx, upload.Value.UploadURL, media.body, media.size); err != nil {
+		return "", err
+	}
+
+	return upload.Value.Image, nil
+}
+
+// uploadVideo uploads the video at videoURL for the organization in the parts
+// LinkedIn asks for and returns its URN. The parts are streamed from the
+// download one after another, so the video is never held in memory.
+func (c *Client) uploadVideo(ctx context.Context, videoURL string) (string, error) {
+	media, err := c.download(ctx, videoURL)
+	if err != nil {
+		return "", err
+	}
+	defer media.Close()
+	if media.size < 0 {
+		return "", fmt.Errorf("the size of %s is unknown", videoURL)
+	}
+
+	var upload struct {
+		Value struct {
+			Video              string `json:"video"`
+			UploadToken        string `json:"uploadToken"`
+			UploadInstructions []struct {
+				UploadURL string `json:"uploadUrl"`
+				FirstByte int64  `json:"firstByte"`
+				LastByte  int64  `json:"lastByte"`
+			} `json:"uploadInstructions"`
+		} `json:"value"`
+	}
+	_, err = c.makeAPICall(ctx, http.MethodPost, "/videos?action=initializeUpload", map[string]interface{}{
+		"initializeUploadRequest": map[string]interface{}{
+			"owner":           "urn:li:organization:" + c.config.OrganizationID,
+			"fileSizeBytes":   media.size,
+			"uploadCaptions":  false,
+			"uploadThumbnail": false,
+		},
+	}, &upload)
+	if err != nil {
+		return "", err
+	}
+	if upload.Value.Video == "" || len(upload.Value.UploadInstructions) == 0 {
+		return "", fmt.Errorf("no upload instructions in response")
+	}
+
+	partIDs := make([]string, 0, len(upload.Value.UploadInstructions))
+	var offset int64
+	for _, instruction := range upload.Value.UploadInstructions {
+		if instruction.FirstByte != offset || instruction.LastByte < instruction.FirstByte || instruction.LastByte >= media.size {
+			return "", fmt.Errorf("invalid upload part %d-%d", instruction.FirstByte, instruction.LastByte)
+		}
+
+		partSize := instruction.LastByte - instruction.FirstByte + 1
+		partID, err := c.upload(ctx, instruction.UploadURL, io.LimitReader(media.body, partSize), partSize)
+		if err != nil {
+			return "", err
+		}
+		partIDs = append(partIDs, partID)
+		offset += partSize
+	}
+	if offset != media.size {
+		return "", fmt.Errorf("upload parts cover %d of %d bytes", offset, media.size)
+	}
+
+	_, err = c.makeAPICall(ctx, http.MethodPost, "/videos?action=finalizeUpload", map[string]interface{}{
+		"finalizeUploadRequest": map[string]interface{}{
+			"video":           upload.Value.Video,
+			"uploadToken":     upload.Value.UploadToken,
+			"uploadedPartIds": partIDs,
+		},
+	}, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to finalize upload: %w", err)
+	}
+
+	return upload.Value.Video, nil
+}
+
+// mediaDownload is the body of a media download, size bytes long or -1 when
+// its server did not say
+type mediaDownload struct {
+	body io.ReadCloser
+	size int64
+}
+
+func (m *mediaDownload) Close() error {
+	return m.body.Close()
+}
+
+// download opens the content at mediaURL for streaming to LinkedIn, which
+// cannot upload media by URL. Content larger than maxMediaSize fails to read.
+func (c *Client) download(ctx context.Context, mediaURL string) (*mediaDownload, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.uploadClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", mediaURL, err)
+	}
+
+	if resp.StatusCode >= 400 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download %s: status %d", mediaURL, resp.StatusCode)
+	}
+	if resp.ContentLength > maxMediaSize {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s is larger than %d MB", mediaURL, maxMediaSize>>20)
+	}
+
+	return &mediaDownload{
+		body: &sizeLimitedBody{ReadCloser: resp.Body, remaining: maxMediaSize, url: mediaURL},
+		size: resp.ContentLength,
+	}, nil
+}
+
+// sizeLimitedBody fails reads past the first remaining bytes of a download
+type sizeLimitedBody struct {
+	io.ReadCloser
+	remaining int64
+	url       string
+}
+
+func (b *sizeLimitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		// Anything left beyond the limit makes the media too large
+		var probe [1]byte
+		if n, _ := b.ReadCloser.Read(probe[:]); n > 0 {
+			return 0, fmt.Errorf("%s is larger than %d MB", b.url, maxMediaSize>>20)
+		}
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
+
+// upload streams size bytes of body, or all of it when size is -1, to an upload
+// URL returned by LinkedIn and returns the ETag identifying the uploaded part
+func (c *Client) upload(ctx context.Context, uploadURL string, body io.Reader, size int64) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, io.NopCloser(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.AccessToken))
+
+	resp, err := c.uploadClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return resp.Header.Get("ETag"), nil
+}
+
+// commentary returns the text of the sponsored post: the asset's headline and
+// description, or its content when it has neither, cut to the length LinkedIn accepts
+func (c *Client) commentary(request *models.DeploymentRequest) string {
+	specs := request.Metadata.CreativeSpecs
+
+	var parts []string
+	for _, part := range []string{specs.Headline, specs.Description} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	text := strings.Join(parts, "\n\n")
+	if text == "" {
+		text = strings.TrimSpace(request.Content)
+	}
+	if text == "" {
+		text = request.Title
+	}
+
+	if runes := []rune(text); len(runes) > maxCommentaryLength {
+		text = string(runes[:maxCommentaryLength-3]) + "..."
+	}
+	return text
+}
+
+func (c *Client) getCallToActionLabel(cta string) string {
+	switch strings.ToLower(cta) {
+	case "learn more":
+		return "LEARN_MORE"
+	case "sign up":
+		return "SIGN_UP"
+	case "download":
+		return "DOWNLOAD"
+	case "register":
+		return "REGISTER"
+	case "apply":
+		return "APPLY"
+	case "request demo":
+		return "REQUEST_DEMO"
+	default:
+		return "LEARN_MORE"
+	}
+}
+
+// adAccountPath returns the path of the configured ad account
+func (c *Client) adAccountPath() string {
+	return "/adAccounts/" + c.config.AdAccountID
+}
+
+// apiError is the body of a LinkedIn Marketing API error response
+type apiError struct {
+	Status           int    `json:"status"`
+	ServiceErrorCode int    `json:"serviceErrorCode"`
+	Code             string `json:"code"`
+	Message          string `json:"message"`
+}
+
+// makeAPICall makes an API call to the LinkedIn Marketing API and decodes the
+// response into out, unless out is nil. It returns the ID of the entity the call
+// created, which LinkedIn sends in the x-restli-id header.
+func (c *Client) makeAPICall(ctx context.Context, method, endpoint string, data interface{}, out interface{}) (string, error) {
+	return c.call(ctx, method, endpoint, "", data, out)
+}
+
+// call makes an API call like makeAPICall, as the Rest.li method restliMethod
+// when it is not empty
+func (c *Client) call(ctx context.Context, method, endpoint, restliMethod string, data interface{}, out interface{}) (string, error) {
+	url := c.baseURL + endpoint
+
+	var body io.Reader
+	if data != nil {
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal request data: %w", err)
+		}
+		body = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.AccessToken))
+	req.Header.Set("LinkedIn-Version", apiVersion)
+	req.Header.Set("X-Restli-Protocol-Version", "2.0.0")
+	if restliMethod != "" {
+		req.Header.Set("X-RestLi-Method", restliMethod)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make API call: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		var apiErr apiError
+		if err := json.Unmarshal(respBody, &apiErr); err == nil && apiErr.Message != "" {
+			return "", &models.APIError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("%s (%s)", apiErr.Message, apiErr.Code)}
+		}
+		return "", &models.APIError{StatusCode: resp.StatusCode, Message: string(respBody)}
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return "", fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+
+	return resp.Header.Get("X-Restli-Id"), nil
+}
+
+// HealthCheck checks the health of the LinkedIn client
+func (c *Client) HealthCheck(ctx context.Context) error {
+	// Fetch the ad account to verify connectivity and the access token
+	if _, err := c.makeAPICall(ctx, http.MethodGet, c.adAccountPath(), nil, nil); err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+
+	return nil
+}
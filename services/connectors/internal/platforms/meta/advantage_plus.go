@@ -0,0 +1,22 @@
+package meta
+
+import "github.com/zamc/connectors/internal/models"
+
+// AdvantagePlusAudienceSetting is the audience of an ad set whose targeting is
+// left to Meta's Advantage+ audience. Advantage+ finds the people to reach on its
+// own, so the ad set has no manual targeting: the interests and behaviors of the
+// request are ignored, and the age range and countries only bound the audience.
+type AdvantagePlusAudienceSetting struct {
+	MinAge    int      `json:"age_min,omitempty"`
+	MaxAge    int      `json:"age_max,omitempty"`
+	Countries []string `json:"countries,omitempty"`
+}
+
+// buildAdvantagePlusAudience returns the Advantage+ audience of an ad set
+func buildAdvantagePlusAudience(demographics models.Demographics) AdvantagePlusAudienceSetting {
+	return AdvantagePlusAudienceSetting{
+		MinAge:    demographics.AgeMin,
+		MaxAge:    demographics.AgeMax,
+		Countries: demographics.Locations,
+	}
+}
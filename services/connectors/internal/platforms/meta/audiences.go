@@ -0,0 +1,89 @@
+package meta
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// customAudienceBatchSize is the most users Meta accepts in one upload to a
+// custom audience
+const customAudienceBatchSize = 10000
+
+// CreateCustomAudience creates a customer list audience in the ad account from
+// the hex-encoded SHA-256 hashes of normalized emails and returns its ID. Emails
+// are hashed by the BFF, so they never reach the service in the clear. An
+// audience whose users fail to upload is deleted.
+func (c *Client) CreateCustomAudience(ctx context.Context, accountID, name string, emailHashes []string) (audienceID string, err error) {
+	for _, hash := range emailHashes {
+		if !isEmailHash(hash) {
+			return "", fmt.Errorf("custom audience emails must be hex-encoded SHA-256 hashes")
+		}
+	}
+
+	audienceID, err = c.makeAPICall(ctx, "POST", fmt.Sprintf("act_%s/customaudiences", accountID), map[string]interface{}{
+		"name":                 name,
+		"subtype":              "CUSTOM",
+		"customer_file_source": "USER_PROVIDED_ONLY",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create custom audience: %w", err)
+	}
+
+	for start := 0; start < len(emailHashes); start += customAudienceBatchSize {
+		end := min(start+customAudienceBatchSize, len(emailHashes))
+		if _, err := c.makeAPICall(ctx, "POST", fmt.Sprintf("%s/users", audienceID), map[string]interface{}{
+			"payload": map[string]interface{}{
+				"schema": "EMAIL_SHA256",
+				"data":   emailHashes[start:end],
+			},
+		}); err != nil {
+			c.discardCustomAudience(audienceID)
+			return "", fmt.Errorf("failed to upload users to custom audience %s: %w", audienceID, err)
+		}
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"audience_id": audienceID,
+		"users":       len(emailHashes),
+	}).Info("Created Meta custom audience")
+
+	return audienceID, nil
+}
+
+// RevokeCustomAudience deletes a custom audience along with the users uploaded
+// to it, for people who withdrew their consent
+func (c *Client) RevokeCustomAudience(ctx context.Context, audienceID string) error {
+	if _, err := c.makeAPICall(ctx, "DELETE", audienceID, nil); err != nil {
+		return fmt.Errorf("failed to delete custom audience %s: %w", audienceID, err)
+	}
+
+	c.logger.WithField("audience_id", audienceID).Info("Deleted Meta custom audience")
+
+	return nil
+}
+
+// discardCustomAudience deletes the custom audience of a failed deployment,
+// which nothing would otherwise know of. It runs on a context of its own, since
+// the deployment may have failed because its context was cancelled.
+func (c *Client) discardCustomAudience(audienceID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := c.RevokeCustomAudience(ctx, audienceID); err != nil {
+		c.logger.WithError(err).WithField("audience_id", audienceID).Error("Failed to delete custom audience of failed Meta deployment")
+	}
+}
+
+// isEmailHash reports whether hash is a lowercase hex-encoded SHA-256 hash
+func isEmailHash(hash string) bool {
+	if len(hash) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil && hash == strings.ToLower(hash)
+}
@@ -0,0 +1,59 @@
+package meta
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/zamc/connectors/internal/cache"
+)
+
+// createdCampaignTTL is how long the ID of a created campaign is reused for
+// deployments creating a campaign of the same name, such as retries
+const createdCampaignTTL = 5 * time.Minute
+
+// SetResponseCache makes the client serve GET calls of its ad account from
+// responseCache, and reuse the campaigns it recently created
+func (c *Client) SetResponseCache(responseCache *cache.PlatformResponseCache) {
+	c.responseCache = responseCache
+}
+
+// getCacheKey is the cache key of the response of a GET call to url
+func (c *Client) getCacheKey(url string) string {
+	return fmt.Sprintf("meta:%s:GET:%s", c.Config().AdAccountID, url)
+}
+
+// campaignCacheKey is the cache key of the ID of the campaign named name in
+// the ad account
+func (c *Client) campaignCacheKey(name string) string {
+	return fmt.Sprintf("meta:%s:campaign:%s", c.Config().AdAccountID, name)
+}
+
+// createCampaign creates a campaign in the ad account, unless one of the same
+// name was created in the last createdCampaignTTL, whose ID is returned instead
+func (c *Client) createCampaign(ctx context.Context, campaign map[string]interface{}) (string, error) {
+	key := c.campaignCacheKey(campaign["name"].(string))
+	if campaignID, ok := c.responseCache.Get(key); ok {
+		c.logger.WithFields(logrus.Fields{
+			"campaign_name": campaign["name"],
+			"campaign_id":   campaignID,
+		}).Info("Reusing recently created Meta campaign")
+		return campaignID, nil
+	}
+
+	campaignID, err := c.makeAPICall(ctx, "POST", fmt.Sprintf("act_%s/campaigns", c.Config().AdAccountID), campaign)
+	if err != nil {
+		return "", err
+	}
+
+	c.responseCache.Set(key, campaignID, createdCampaignTTL)
+	return campaignID, nil
+}
+
+// invalidateGETResponses drops the cached GET responses of the ad account,
+// after a call that changed its objects
+func (c *Client) invalidateGETResponses() {
+	c.responseCache.Invalidate(fmt.Sprintf("meta:%s:GET:*", c.Config().AdAccountID))
+}
@@ -0,0 +1,387 @@
+package meta
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+// maxBatchRequests is the Graph API limit on requests per batch call
+const maxBatchRequests = 50
+
+// sourceCampaign holds the fields read from the campaign being duplicated
+type sourceCampaign struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Objective string `json:"objective"`
+	Status    string `json:"status"`
+}
+
+// sourceAdSet holds the fields read from each ad set being duplicated
+type sourceAdSet struct {
+	ID               string                 `json:"id"`
+	Name             string                 `json:"name"`
+	DailyBudget      string                 `json:"daily_budget"`
+	BillingEvent     string                 `json:"billing_event"`
+	OptimizationGoal string                 `json:"optimization_goal"`
+	BidAmount        interface{}            `json:"bid_amount"`
+	Targeting        map[string]interface{} `json:"targeting"`
+	PromotedObject   map[string]interface{} `json:"promoted_object"`
+	EndTime          string                 `json:"end_time"`
+}
+
+// sourceAd holds the fields read from each ad being duplicated
+type sourceAd struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Creative struct {
+		ID              string                 `json:"id"`
+		Name            string                 `json:"name"`
+		ObjectStorySpec map[string]interface{} `json:"object_story_spec"`
+	} `json:"creative"`
+}
+
+// batchRequest is a single operation within a Graph API batch call
+type batchRequest struct {
+	Method      string `json:"method"`
+	RelativeURL string `json:"relative_url"`
+	Body        string `json:"body,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+// batchResponse is a single operation result within a Graph API batch call
+type batchResponse struct {
+	Code int    `json:"code"`
+	Body string `json:"body"`
+}
+
+// DuplicateCampaign copies an existing campaign, its ad sets, ads and creatives.
+// The copy and all of its children are created in PAUSED status. newBudget is the
+// total daily budget of the copy and is split across ad sets in proportion to the
+// source ad set budgets. When an ad set or ad cannot be copied, the partial copy
+// is deleted.
+func (c *Client) DuplicateCampaign(ctx context.Context, sourceCampaignID string, newName string, newBudget float64) (newCampaignID string, err error) {
+	logger := c.logger.WithFields(logrus.Fields{
+		"source_campaign_id": sourceCampaignID,
+		"new_name":           newName,
+		"new_budget":         newBudget,
+	})
+
+	if newBudget <= 0 {
+		return "", fmt.Errorf("new budget must be positive")
+	}
+
+	var campaign sourceCampaign
+	if err := c.getAPIObject(ctx, fmt.Sprintf("%s?fields=name,objective,status", sourceCampaignID), &campaign); err != nil {
+		return "", fmt.Errorf("failed to read source campaign: %w", err)
+	}
+
+	var adSets struct {
+		Data []sourceAdSet `json:"data"`
+	}
+	adSetFields := "id,name,daily_budget,billing_event,optimization_goal,bid_amount,targeting,promoted_object,end_time"
+	if err := c.getAPIObject(ctx, fmt.Sprintf("%s/adsets?fields=%s", sourceCampaignID, adSetFields), &adSets); err != nil {
+		return "", fmt.Errorf("failed to read source ad sets: %w", err)
+	}
+
+	if newName == "" {
+		newName = fmt.Sprintf("%s (Copy)", campaign.Name)
+	}
+
+	newCampaignID, err = c.makeAPICall(ctx, "POST", fmt.Sprintf("act_%s/campaigns", c.Config().AdAccountID), map[string]interface{}{
+		"name":                  newName,
+		"objective":             campaign.Objective,
+		"status":                "PAUSED",
+		"special_ad_categories": []string{},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create campaign copy: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			c.deletePartialCopy(ctx, newCampaignID, logger)
+			newCampaignID = ""
+		}
+	}()
+
+	budgets := splitBudget(adSets.Data, newBudget)
+
+	for i, adSet := range adSets.Data {
+		newAdSetID, err := c.duplicateAdSet(ctx, adSet, newCampaignID, budgets[i])
+		if err != nil {
+			return newCampaignID, fmt.Errorf("failed to duplicate ad set %s: %w", adSet.ID, err)
+		}
+
+		if err := c.duplicateAds(ctx, adSet.ID, newAdSetID); err != nil {
+			return newCampaignID, fmt.Errorf("failed to duplicate ads of ad set %s: %w", adSet.ID, err)
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"campaign_id": newCampaignID,
+		"ad_sets":     len(adSets.Data),
+	}).Info("Duplicated Meta campaign")
+
+	return newCampaignID, nil
+}
+
+// deletePartialCopy deletes a campaign copy that could not be completed, along
+// with the ad sets and ads already copied into it. The deletion is attempted
+// even when ctx was canceled, so that no paused copy is left behind.
+func (c *Client) deletePartialCopy(ctx context.Context, campaignID string, logger *logrus.Entry) {
+	if _, err := c.makeAPICall(context.WithoutCancel(ctx), "DELETE", campaignID, nil); err != nil {
+		logger.WithError(err).WithField("campaign_id", campaignID).Error("Failed to delete partial Meta campaign copy")
+		return
+	}
+
+	logger.WithField("campaign_id", campaignID).Warn("Deleted partial Meta campaign copy")
+}
+
+// duplicateAdSet creates a paused copy of an ad set under the given campaign
+func (c *Client) duplicateAdSet(ctx context.Context, adSet sourceAdSet, campaignID string, budget float64) (string, error) {
+	adSetCopy := map[string]interface{}{
+		"name":              adSet.Name,
+		"campaign_id":       campaignID,
+		"daily_budget":      int(budget * 100), // Convert to cents
+		"billing_event":     adSet.BillingEvent,
+		"optimization_goal": adSet.OptimizationGoal,
+		"status":            "PAUSED",
+		"targeting":         adSet.Targeting,
+	}
+	if adSet.BidAmount != nil {
+		adSetCopy["bid_amount"] = adSet.BidAmount
+	}
+	if len(adSet.PromotedObject) > 0 {
+		adSetCopy["promoted_object"] = adSet.PromotedObject
+	}
+	if adSet.EndTime != "" {
+		adSetCopy["end_time"] = adSet.EndTime
+	}
+
+	adSetID, err := c.makeAPICall(ctx, "POST", fmt.Sprintf("act_%s/adsets", c.Config().AdAccountID), adSetCopy)
+	if err != nil {
+		return "", err
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"source_ad_set_id": adSet.ID,
+		"ad_set_id":        adSetID,
+		"campaign_id":      campaignID,
+	}).Info("Duplicated Meta ad set")
+
+	return adSetID, nil
+}
+
+// duplicateAds copies every ad and creative of the source ad set into the target
+// ad set. Each creative and the ad referencing it are sent in the same batch so
+// the ad can use the creative ID through a JSONPath result reference.
+func (c *Client) duplicateAds(ctx context.Context, sourceAdSetID, targetAdSetID string) error {
+	var ads struct {
+		Data []sourceAd `json:"data"`
+	}
+	if err := c.getAPIObject(ctx, fmt.Sprintf("%s/ads?fields=id,name,creative{name,object_story_spec}", sourceAdSetID), &ads); err != nil {
+		return fmt.Errorf("failed to read source ads: %w", err)
+	}
+
+	var batch []batchRequest
+	for i, ad := range ads.Data {
+		storySpec, err := json.Marshal(ad.Creative.ObjectStorySpec)
+		if err != nil {
+			return fmt.Errorf("failed to marshal creative of ad %s: %w", ad.ID, err)
+		}
+
+		creativeRef := fmt.Sprintf("creative_%d", i)
+		creativeBody := url.Values{}
+		creativeBody.Set("name", ad.Creative.Name)
+		creativeBody.Set("object_story_spec", string(storySpec))
+
+		adBody := url.Values{}
+		adBody.Set("name", ad.Name)
+		adBody.Set("adset_id", targetAdSetID)
+		adBody.Set("creative", fmt.Sprintf(`{"creative_id":"{result=%s:$.id}"}`, creativeRef))
+		adBody.Set("status", "PAUSED")
+
+		batch = append(batch,
+			batchRequest{
+				Method:      "POST",
+				RelativeURL: fmt.Sprintf("act_%s/adcreatives", c.Config().AdAccountID),
+				Body:        creativeBody.Encode(),
+				Name:        creativeRef,
+			},
+			batchRequest{
+				Method:      "POST",
+				RelativeURL: fmt.Sprintf("act_%s/ads", c.Config().AdAccountID),
+				Body:        adBody.Encode(),
+			},
+		)
+	}
+
+	// Requests come in creative/ad pairs, so an even chunk size never splits a pair
+	for start := 0; start < len(batch); start += maxBatchRequests {
+		end := start + maxBatchRequests
+		if end > len(batch) {
+			end = len(batch)
+		}
+
+		responses, err := c.makeBatchCall(ctx, batch[start:end])
+		if err != nil {
+			return err
+		}
+
+		for i, response := range responses {
+			if response.Code >= 400 {
+				return fmt.Errorf("batch request %s %s failed with status %d: %s",
+					batch[start+i].Method, batch[start+i].RelativeURL, response.Code, response.Body)
+			}
+		}
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"source_ad_set_id": sourceAdSetID,
+		"ad_set_id":        targetAdSetID,
+		"ads":              len(ads.Data),
+	}).Info("Duplicated Meta ads")
+
+	return nil
+}
+
+// splitBudget distributes the total budget across ad sets in proportion to their
+// source daily budgets, falling back to an even split when they are unknown
+func splitBudget(adSets []sourceAdSet, total float64) []float64 {
+	budgets := make([]float64, len(adSets))
+	if len(adSets) == 0 {
+		return budgets
+	}
+
+	weights := make([]float64, len(adSets))
+	var sum float64
+	for i, adSet := range adSets {
+		if cents, err := strconv.ParseFloat(adSet.DailyBudget, 64); err == nil && cents > 0 {
+			weights[i] = cents
+			sum += cents
+		}
+	}
+
+	for i := range adSets {
+		if sum > 0 {
+			budgets[i] = total * weights[i] / sum
+		} else {
+			budgets[i] = total / float64(len(adSets))
+		}
+	}
+
+	return budgets
+}
+
+// getAPIObject performs a GET request and decodes the JSON response into out.
+// Responses are cached like those of makeAPICall.
+func (c *Client) getAPIObject(ctx context.Context, endpoint string, out interface{}) error {
+	requestURL := fmt.Sprintf("%s/%s", c.baseURL, endpoint)
+	if cached, ok := c.responseCache.Get(c.getCacheKey(requestURL)); ok {
+		return json.Unmarshal([]byte(cached), out)
+	}
+
+	if err := c.checkQuota(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.Config().AccessToken))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make API call: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if err := c.observeQuota(resp, respBody); err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 400 {
+		return &models.APIError{StatusCode: resp.StatusCode, Message: string(respBody)}
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	c.responseCache.Set(c.getCacheKey(requestURL), string(respBody), c.Config().CacheGETResponsesTTL)
+	return nil
+}
+
+// makeBatchCall sends the requests to the Graph API batch endpoint
+func (c *Client) makeBatchCall(ctx context.Context, requests []batchRequest) ([]batchResponse, error) {
+	batch, err := json.Marshal(requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("batch", string(batch))
+	form.Set("include_headers", "false")
+
+	if err := c.checkQuota(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL, bytes.NewBufferString(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.Config().AccessToken))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make batch call: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Any request of the batch may have changed the ad account
+	c.invalidateGETResponses()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch response body: %w", err)
+	}
+
+	if err := c.observeQuota(resp, respBody); err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, &models.APIError{StatusCode: resp.StatusCode, Message: string(respBody)}
+	}
+
+	var responses []batchResponse
+	if err := json.Unmarshal(respBody, &responses); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal batch response: %w", err)
+	}
+
+	if len(responses) != len(requests) {
+		return nil, fmt.Errorf("batch returned %d responses for %d requests", len(responses), len(requests))
+	}
+
+	return responses, nil
+}
@@ -0,0 +1,129 @@
+package meta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+// estimateDays is the length of the estimate period
+const estimateDays = 7
+
+// deliveryOutcome is a point on the daily outcomes curve; spend is in the
+// account currency's minor unit
+type deliveryOutcome struct {
+	Spend       float64 `json:"spend"`
+	Reach       float64 `json:"reach"`
+	Impressions float64 `json:"impressions"`
+	Actions     float64 `json:"actions"`
+}
+
+// deliveryEstimateResponse holds the fields read from a delivery_estimate reply
+type deliveryEstimateResponse struct {
+	Data []struct {
+		DailyOutcomesCurve    []deliveryOutcome `json:"daily_outcomes_curve"`
+		EstimateMauLowerBound float64           `json:"estimate_mau_lower_bound"`
+		EstimateMauUpperBound float64           `json:"estimate_mau_upper_bound"`
+		EstimateReady         bool              `json:"estimate_ready"`
+	} `json:"data"`
+}
+
+// EstimateAdCost estimates the weekly impressions, link clicks and spend of an ad set
+// with the request's targeting and daily budget. Nothing is created in the account.
+func (c *Client) EstimateAdCost(ctx context.Context, request *models.DeploymentRequest) (*models.CostEstimate, error) {
+	targeting, err := json.Marshal(c.buildTargeting(request.Metadata.Demographics))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal targeting spec: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("optimization_goal", c.getOptimizationGoal(request.ContentType))
+	params.Set("targeting_spec", string(targeting))
+
+	var response deliveryEstimateResponse
+	endpoint := fmt.Sprintf("act_%s/delivery_estimate?%s", c.Config().AdAccountID, params.Encode())
+	if err := c.getAPIObject(ctx, endpoint, &response); err != nil {
+		return nil, fmt.Errorf("failed to get delivery estimate: %w", err)
+	}
+
+	if len(response.Data) == 0 || len(response.Data[0].DailyOutcomesCurve) == 0 {
+		return nil, fmt.Errorf("delivery estimate returned no outcomes")
+	}
+	data := response.Data[0]
+	sort.Slice(data.DailyOutcomesCurve, func(i, j int) bool {
+		return data.DailyOutcomesCurve[i].Spend < data.DailyOutcomesCurve[j].Spend
+	})
+
+	daily := outcomeAtSpend(data.DailyOutcomesCurve, request.Metadata.Budget*100)
+
+	// A narrow audience range means a reliable estimate
+	confidence := 0.0
+	if data.EstimateMauUpperBound > 0 {
+		confidence = data.EstimateMauLowerBound / data.EstimateMauUpperBound
+	}
+	if !data.EstimateReady {
+		confidence /= 2
+	}
+
+	estimate := &models.CostEstimate{
+		EstimatedImpressions: int64(daily.Impressions * estimateDays),
+		EstimatedClicks:      int64(daily.Actions * estimateDays),
+		EstimatedSpend:       daily.Spend * estimateDays / 100,
+		Currency:             c.Config().Currency,
+		Confidence:           confidence,
+
+		EstimatedAudienceSize: int64((data.EstimateMauLowerBound + data.EstimateMauUpperBound) / 2),
+	}
+	if estimate.Currency == "" {
+		estimate.Currency = "USD"
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"asset_id":    request.AssetID,
+		"impressions": estimate.EstimatedImpressions,
+		"spend":       estimate.EstimatedSpend,
+		"ready":       data.EstimateReady,
+	}).Info("Generated Meta cost estimate")
+
+	return estimate, nil
+}
+
+// outcomeAtSpend interpolates the outcomes curve at spend. Budgets beyond the end of
+// the curve cannot be delivered and are capped at its last point.
+func outcomeAtSpend(curve []deliveryOutcome, spend float64) deliveryOutcome {
+	if spend <= curve[0].Spend {
+		if curve[0].Spend == 0 {
+			return curve[0]
+		}
+		ratio := spend / curve[0].Spend
+		return deliveryOutcome{
+			Spend:       spend,
+			Reach:       curve[0].Reach * ratio,
+			Impressions: curve[0].Impressions * ratio,
+			Actions:     curve[0].Actions * ratio,
+		}
+	}
+
+	for i := 1; i < len(curve); i++ {
+		lower, upper := curve[i-1], curve[i]
+		if spend > upper.Spend {
+			continue
+		}
+
+		ratio := (spend - lower.Spend) / (upper.Spend - lower.Spend)
+		return deliveryOutcome{
+			Spend:       spend,
+			Reach:       lower.Reach + (upper.Reach-lower.Reach)*ratio,
+			Impressions: lower.Impressions + (upper.Impressions-lower.Impressions)*ratio,
+			Actions:     lower.Actions + (upper.Actions-lower.Actions)*ratio,
+		}
+	}
+
+	return curve[len(curve)-1]
+}
@@ -0,0 +1,145 @@
+package meta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+// insightsFields are the ad insights read for campaign metrics. action_values
+// holds the revenue of the purchases counted in actions. CPC and CPM are left
+// out as consumers derive them from spend.
+const insightsFields = "impressions,clicks,spend,actions,action_values,ctr"
+
+// purchaseActionType is the action counted as a conversion
+const purchaseActionType = "offsite_conversion.fb_pixel_purchase"
+
+const (
+	// errorCodeUnsupportedRequest is returned, as a GraphMethodException, for
+	// reads of objects that do not exist or were deleted
+	errorCodeUnsupportedRequest = 100
+
+	// errorCodeUnknownAlias is returned for reads of IDs that do not exist
+	errorCodeUnknownAlias = 803
+)
+
+// graphErrorResponse is the body of a failed Graph API call
+type graphErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+}
+
+// insightsAction is the count or value of one action type. Like the other insights
+// numbers, the value is a decimal string.
+type insightsAction struct {
+	ActionType string  `json:"action_type"`
+	Value      float64 `json:"value,string"`
+}
+
+// insightsResponse holds the insights of an ad, summed over the date preset into
+// one row. An ad without delivery in the period has no rows.
+type insightsResponse struct {
+	Data []struct {
+		Impressions  int64            `json:"impressions,string"`
+		Clicks       int64            `json:"clicks,string"`
+		Spend        float64          `json:"spend,string"`
+		CTR          float64          `json:"ctr,string"`
+		Actions      []insightsAction `json:"actions"`
+		ActionValues []insightsAction `json:"action_values"`
+		DateStart    string           `json:"date_start"`
+		DateStop     string           `json:"date_stop"`
+	} `json:"data"`
+}
+
+// FetchCampaignMetrics returns the performance of an ad over datePreset, such as
+// last_7d or maximum. Conversions are pixel purchases and ROAS is their revenue
+// divided by spend; CTR is a fraction like the one of Google Ads rather than
+// Meta's percentage.
+func (c *Client) FetchCampaignMetrics(ctx context.Context, adID string, datePreset string) (*models.CampaignMetrics, error) {
+	query := url.Values{}
+	query.Set("fields", insightsFields)
+	query.Set("date_preset", datePreset)
+
+	var response insightsResponse
+	if err := c.getAPIObject(ctx, fmt.Sprintf("%s/insights?%s", adID, query.Encode()), &response); err != nil {
+		if isMissingObject(err) {
+			return nil, fmt.Errorf("failed to read ad insights: ad %s no longer exists: %w", adID, models.ErrCampaignNotFound)
+		}
+		return nil, fmt.Errorf("failed to read ad insights: %w", err)
+	}
+
+	metrics := &models.CampaignMetrics{
+		CampaignID: adID,
+		Platform:   models.PlatformMeta,
+		FetchedAt:  time.Now(),
+	}
+
+	if len(response.Data) > 0 {
+		row := response.Data[0]
+		metrics.Impressions = row.Impressions
+		metrics.Clicks = row.Clicks
+		metrics.CostMicros = int64(math.Round(row.Spend * 1e6))
+		metrics.Conversions = actionValue(row.Actions, purchaseActionType)
+		metrics.Revenue = actionValue(row.ActionValues, purchaseActionType)
+		metrics.CTR = row.CTR / 100
+		metrics.StartDate = row.DateStart
+		metrics.EndDate = row.DateStop
+		if row.Spend > 0 {
+			metrics.ROAS = metrics.Revenue / row.Spend
+		}
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"ad_id":       adID,
+		"date_preset": datePreset,
+		"impressions": metrics.Impressions,
+		"clicks":      metrics.Clicks,
+	}).Info("Fetched Meta ad insights")
+
+	return metrics, nil
+}
+
+// isMissingObject tells whether err is the Graph API refusing to read an object
+// because it does not exist. Code 100 is also returned for invalid parameters,
+// as an OAuthException, which is not a missing object.
+func isMissingObject(err error) bool {
+	var apiErr *models.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	var response graphErrorResponse
+	if json.Unmarshal([]byte(apiErr.Message), &response) != nil {
+		return false
+	}
+
+	switch response.Error.Code {
+	case errorCodeUnknownAlias:
+		return true
+	case errorCodeUnsupportedRequest:
+		return response.Error.Type == "GraphMethodException"
+	default:
+		return false
+	}
+}
+
+// actionValue returns the value of actionType in actions, or zero when it is missing
+func actionValue(actions []insightsAction, actionType string) float64 {
+	for _, action := range actions {
+		if action.ActionType == actionType {
+			return action.Value
+		}
+	}
+	return 0
+}
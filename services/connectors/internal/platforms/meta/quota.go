@@ -0,0 +1,36 @@
+package meta
+
+import (
+	"net/http"
+
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/ratelimit"
+)
+
+// SetRateLimiter makes the client track the API quota of its ad account in
+// limiter and hold calls back while the account is backing off
+func (c *Client) SetRateLimiter(limiter *ratelimit.PlatformRateLimiter) {
+	c.rateLimiter = limiter
+}
+
+// checkQuota returns a *ratelimit.QuotaError while the ad account is backing off
+func (c *Client) checkQuota() error {
+	if c.rateLimiter == nil {
+		return nil
+	}
+	return c.rateLimiter.Allow(models.PlatformMeta, c.Config().AdAccountID)
+}
+
+// observeQuota records the quota left reported by resp, and returns a
+// *ratelimit.QuotaError when Meta refused the call with Error 17
+func (c *Client) observeQuota(resp *http.Response, body []byte) error {
+	if c.rateLimiter == nil {
+		return nil
+	}
+
+	c.rateLimiter.Observe(models.PlatformMeta, c.Config().AdAccountID, resp.Header)
+	if resp.StatusCode >= 400 && ratelimit.IsMetaRateLimitError(body) {
+		return c.rateLimiter.Exhausted(models.PlatformMeta, c.Config().AdAccountID)
+	}
+	return nil
+}
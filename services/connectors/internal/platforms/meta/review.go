@@ -0,0 +1,71 @@
+package meta
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+// adReview holds the review fields of an ad
+type adReview struct {
+	EffectiveStatus  string `json:"effective_status"`
+	AdReviewFeedback struct {
+		// Policy names mapped to the reviewer's explanation
+		Global            map[string]string            `json:"global"`
+		PlacementSpecific map[string]map[string]string `json:"placement_specific"`
+	} `json:"ad_review_feedback"`
+}
+
+// GetAdReviewStatus returns the policy review outcome of an ad. The reviewer's
+// explanations are the disapproval reasons and the policy names they refer to are
+// the policy violations.
+func (c *Client) GetAdReviewStatus(ctx context.Context, platformAdID string) (*models.AdReviewStatus, error) {
+	var ad adReview
+	if err := c.getAPIObject(ctx, fmt.Sprintf("%s?fields=effective_status,ad_review_feedback", platformAdID), &ad); err != nil {
+		return nil, fmt.Errorf("failed to read ad review status: %w", err)
+	}
+
+	status := &models.AdReviewStatus{}
+	switch ad.EffectiveStatus {
+	case "DISAPPROVED":
+		status.Status = models.AdReviewStatusRejected
+	case "PENDING_REVIEW", "IN_PROCESS":
+		status.Status = models.AdReviewStatusPending
+	default:
+		status.Status = models.AdReviewStatusApproved
+	}
+
+	addFeedback := func(feedback map[string]string, placement string) {
+		policies := make([]string, 0, len(feedback))
+		for policy := range feedback {
+			policies = append(policies, policy)
+		}
+		sort.Strings(policies)
+
+		for _, policy := range policies {
+			reason := feedback[policy]
+			if placement != "" {
+				policy = fmt.Sprintf("%s (%s)", policy, placement)
+			}
+			status.PolicyViolations = append(status.PolicyViolations, policy)
+			if reason != "" {
+				status.DisapprovalReasons = append(status.DisapprovalReasons, reason)
+			}
+		}
+	}
+
+	addFeedback(ad.AdReviewFeedback.Global, "")
+
+	placements := make([]string, 0, len(ad.AdReviewFeedback.PlacementSpecific))
+	for placement := range ad.AdReviewFeedback.PlacementSpecific {
+		placements = append(placements, placement)
+	}
+	sort.Strings(placements)
+	for _, placement := range placements {
+		addFeedback(ad.AdReviewFeedback.PlacementSpecific[placement], placement)
+	}
+
+	return status, nil
+}
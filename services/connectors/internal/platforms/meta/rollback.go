@@ -0,0 +1,17 @@
+package meta
+
+import (
+	"context"
+	"fmt"
+)
+
+// PauseAd stops a deployed ad from delivering
+func (c *Client) PauseAd(ctx context.Context, adID string) error {
+	if _, err := c.makeAPICall(ctx, "POST", fmt.Sprintf("%s?status=PAUSED", adID), nil); err != nil {
+		return fmt.Errorf("failed to pause ad %s: %w", adID, err)
+	}
+
+	c.logger.WithField("ad_id", adID).Info("Paused Meta ad")
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package meta
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/scheduler"
+)
+
+// SetScheduler sets the worker that runs the campaign schedules created by the client
+func (c *Client) SetScheduler(worker *scheduler.SchedulerWorker) {
+	c.scheduler = worker
+}
+
+// ScheduleCampaignPauseResume pauses the campaign of tenantID whenever pauseCron
+// matches and resumes it whenever resumeCron matches. Both are standard
+// five-field cron expressions evaluated in timezone. Any existing schedule of the
+// campaign is replaced.
+func (c *Client) ScheduleCampaignPauseResume(ctx context.Context, tenantID, platformCampaignID, pauseCron, resumeCron string, timezone string) error {
+	if c.scheduler == nil {
+		return fmt.Errorf("campaign scheduling is not configured")
+	}
+
+	if err := scheduler.Validate(pauseCron, resumeCron, timezone); err != nil {
+		return err
+	}
+
+	var campaign sourceCampaign
+	if err := c.getAPIObject(ctx, fmt.Sprintf("%s?fields=name,status", platformCampaignID), &campaign); err != nil {
+		return fmt.Errorf("failed to read campaign: %w", err)
+	}
+
+	return c.scheduler.ScheduleCampaign(ctx, tenantID, &models.CampaignSchedule{
+		Platform:           models.PlatformMeta,
+		PlatformCampaignID: platformCampaignID,
+		PauseCron:          pauseCron,
+		ResumeCron:         resumeCron,
+		Timezone:           timezone,
+	})
+}
+
+// PauseCampaign stops a campaign from delivering
+func (c *Client) PauseCampaign(ctx context.Context, platformCampaignID string) error {
+	return c.setCampaignStatus(ctx, platformCampaignID, "PAUSED")
+}
+
+// ResumeCampaign lets a paused campaign deliver again
+func (c *Client) ResumeCampaign(ctx context.Context, platformCampaignID string) error {
+	return c.setCampaignStatus(ctx, platformCampaignID, "ACTIVE")
+}
+
+// setCampaignStatus updates the status of a campaign
+func (c *Client) setCampaignStatus(ctx context.Context, platformCampaignID, status string) error {
+	if _, err := c.makeAPICall(ctx, "POST", platformCampaignID, map[string]interface{}{
+		"status": status,
+	}); err != nil {
+		return fmt.Errorf("failed to set campaign status to %s: %w", status, err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"campaign_id": platformCampaignID,
+		"status":      status,
+	}).Info("Updated Meta campaign status")
+
+	return nil
+}
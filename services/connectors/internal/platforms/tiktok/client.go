@@ -0,0 +1,382 @@
+package tiktok
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+)
+
+// maxAdTextLength is the longest ad text TikTok accepts
+const maxAdTextLength = 100
+
+// AdFormat is the format of a TikTok ad
+type AdFormat string
+
+const (
+	AdFormatImage AdFormat = "SINGLE_IMAGE"
+	AdFormatVideo AdFormat = "SINGLE_VIDEO"
+)
+
+// AdFormatFor returns the format of the ad deploying request creates. Video
+// scripts become video ads and infographics and blog posts image ads; social
+// media content becomes a video ad when it has a video and an image ad otherwise.
+func AdFormatFor(request *models.DeploymentRequest) (AdFormat, error) {
+	switch request.ContentType {
+	case models.ContentTypeVideoScript:
+		return AdFormatVideo, nil
+	case models.ContentTypeInfographic, models.ContentTypeBlogPost:
+		return AdFormatImage, nil
+	case models.ContentTypeSocialMedia:
+		if request.Metadata.CreativeSpecs.VideoURL != "" {
+			return AdFormatVideo, nil
+		}
+		return AdFormatImage, nil
+	default:
+		return "", fmt.Errorf("content type %s cannot be deployed to TikTok", request.ContentType)
+	}
+}
+
+// Client represents a TikTok Marketing API client
+type Client struct {
+	httpClient *http.Client
+	config     *config.TikTokConfig
+	logger     *logrus.Logger
+	baseURL    string
+}
+
+// NewClient creates a new TikTok Marketing API client
+func NewClient(cfg *config.TikTokConfig, logger *logrus.Logger) (*Client, error) {
+	if cfg.AdvertiserID == "" {
+		return nil, fmt.Errorf("TikTok advertiser ID is required")
+	}
+	if cfg.AdGroupID == "" {
+		return nil, fmt.Errorf("TikTok ad group ID is required")
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = "https://business-api.tiktok.com/open_api/v1.3"
+	}
+
+	client := &Client{
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		config:  cfg,
+		logger:  logger,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+
+	logger.WithFields(logrus.Fields{
+		"advertiser_id": cfg.AdvertiserID,
+		"ad_group_id":   cfg.AdGroupID,
+	}).Info("TikTok Marketing API client initialized")
+
+	return client, nil
+}
+
+// DeployAsset deploys an asset to TikTok as an image or video ad in the configured ad group
+func (c *Client) DeployAsset(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error) {
+	startTime := time.Now()
+	logger := c.logger.WithFields(logrus.Fields{
+		"asset_id":     request.AssetID,
+		"content_type": request.ContentType,
+		"platform":     models.PlatformTikTok,
+	})
+
+	logger.Info("Starting TikTok deployment")
+
+	result := &models.DeploymentResult{
+		AssetID:    request.AssetID,
+		Platform:   models.PlatformTikTok,
+		Status:     models.DeploymentStatusRunning,
+		DeployedAt: time.Now(),
+		Metrics: models.DeploymentMetrics{
+			RetryCount: 0,
+		},
+	}
+
+	format, err := AdFormatFor(request)
+	if err == nil {
+		switch format {
+		case AdFormatVideo:
+			err = c.deployVideoAd(ctx, request, result)
+		default:
+			err = c.deployImageAd(ctx, request, result)
+		}
+	}
+
+	// Update metrics
+	result.Metrics.Duration = time.Since(startTime)
+
+	if err != nil {
+		result.Status = models.DeploymentStatusFailed
+		result.Error = err.Error()
+		logger.WithError(err).Error("TikTok deployment failed")
+		return result, err
+	}
+
+	result.Status = models.DeploymentStatusSuccess
+	logger.WithFields(logrus.Fields{
+		"platform_id":  result.PlatformID,
+		"platform_url": result.PlatformURL,
+		"duration":     result.Metrics.Duration,
+	}).Info("TikTok deployment successful")
+
+	return result, nil
+}
+
+// deployImageAd uploads the asset's image and creates an image ad from it
+func (c *Client) deployImageAd(ctx context.Context, request *models.DeploymentRequest, result *models.DeploymentResult) error {
+	if request.Metadata.CreativeSpecs.ImageURL == "" {
+		return fmt.Errorf("image URL is required for image ads")
+	}
+
+	imageID, err := c.uploadImage(ctx, request.Metadata.CreativeSpecs.ImageURL)
+	if err != nil {
+		return fmt.Errorf("failed to upload image: %w", err)
+	}
+
+	creative := c.buildCreative(request, AdFormatImage)
+	creative["image_ids"] = []string{imageID}
+
+	return c.createAd(ctx, creative, result)
+}
+
+// deployVideoAd uploads the asset's video, and its image as the video's cover
+// when it has one, and creates a video ad from them
+func (c *Client) deployVideoAd(ctx context.Context, request *models.DeploymentRequest, result *models.DeploymentResult) error {
+	if request.Metadata.CreativeSpecs.VideoURL == "" {
+		return fmt.Errorf("video URL is required for video ads")
+	}
+
+	videoID, err := c.uploadVideo(ctx, request.Metadata.CreativeSpecs.VideoURL)
+	if err != nil {
+		return fmt.Errorf("failed to upload video: %w", err)
+	}
+
+	creative := c.buildCreative(request, AdFormatVideo)
+	creative["video_id"] = videoID
+
+	if coverURL := request.Metadata.CreativeSpecs.ImageURL; coverURL != "" {
+		coverID, err := c.uploadImage(ctx, coverURL)
+		if err != nil {
+			return fmt.Errorf("failed to upload video cover: %w", err)
+		}
+		creative["image_ids"] = []string{coverID}
+	}
+
+	return c.createAd(ctx, creative, result)
+}
+
+// uploadImage uploads the image at imageURL to the advertiser's library and returns its ID
+func (c *Client) uploadImage(ctx context.Context, imageURL string) (string, error) {
+	var image struct {
+		ImageID string `json:"image_id"`
+	}
+	err := c.makeAPICall(ctx, http.MethodPost, "file/image/ad/upload/", map[string]interface{}{
+		"advertiser_id": c.config.AdvertiserID,
+		"upload_type":   "UPLOAD_BY_URL",
+		"image_url":     imageURL,
+	}, &image)
+	if err != nil {
+		return "", err
+	}
+	if image.ImageID == "" {
+		return "", fmt.Errorf("no image ID in upload response")
+	}
+
+	return image.ImageID, nil
+}
+
+// uploadVideo uploads the video at videoURL to the advertiser's library and returns its ID
+func (c *Client) uploadVideo(ctx context.Context, videoURL string) (string, error) {
+	var videos []struct {
+		VideoID string `json:"video_id"`
+	}
+	err := c.makeAPICall(ctx, http.MethodPost, "file/video/ad/upload/", map[string]interface{}{
+		"advertiser_id": c.config.AdvertiserID,
+		"upload_type":   "UPLOAD_BY_URL",
+		"video_url":     videoURL,
+	}, &videos)
+	if err != nil {
+		return "", err
+	}
+	if len(videos) == 0 || videos[0].VideoID == "" {
+		return "", fmt.Errorf("no video ID in upload response")
+	}
+
+	return videos[0].VideoID, nil
+}
+
+// buildCreative returns the creative of an ad with the given format, without its media
+func (c *Client) buildCreative(request *models.DeploymentRequest, format AdFormat) map[string]interface{} {
+	specs := request.Metadata.CreativeSpecs
+
+	creative := map[string]interface{}{
+		"ad_name":        fmt.Sprintf("ZAMC - %s", request.Title),
+		"ad_format":      string(format),
+		"ad_text":        c.adText(request),
+		"call_to_action": c.getCallToActionType(specs.CallToAction),
+	}
+	if specs.LandingURL != "" {
+		creative["landing_page_url"] = specs.LandingURL
+	}
+	if specs.BusinessName != "" {
+		creative["display_name"] = specs.BusinessName
+	}
+
+	return creative
+}
+
+// createAd creates an ad from creative in the configured ad group
+func (c *Client) createAd(ctx context.Context, creative map[string]interface{}, result *models.DeploymentResult) error {
+	var created struct {
+		AdIDs []string `json:"ad_ids"`
+	}
+	err := c.makeAPICall(ctx, http.MethodPost, "ad/create/", map[string]interface{}{
+		"advertiser_id": c.config.AdvertiserID,
+		"adgroup_id":    c.config.AdGroupID,
+		"creatives":     []map[string]interface{}{creative},
+	}, &created)
+	if err != nil {
+		return fmt.Errorf("failed to create ad: %w", err)
+	}
+	if len(created.AdIDs) == 0 {
+		return fmt.Errorf("failed to create ad: no ad ID in response")
+	}
+
+	result.PlatformID = created.AdIDs[0]
+	result.AdGroupID = c.config.AdGroupID
+	result.PlatformURL = fmt.Sprintf("https://ads.tiktok.com/i18n/perf/creative?aadvid=%s", c.config.AdvertiserID)
+
+	return nil
+}
+
+// adText returns the headline of the asset, or the start of its content when it
+// has none, cut to the length TikTok accepts
+func (c *Client) adText(request *models.DeploymentRequest) string {
+	text := strings.TrimSpace(request.Metadata.CreativeSpecs.Headline)
+	if text == "" {
+		for _, line := range strings.Split(request.Content, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				text = line
+				break
+			}
+		}
+	}
+	if text == "" {
+		text = request.Title
+	}
+
+	if runes := []rune(text); len(runes) > maxAdTextLength {
+		text = string(runes[:maxAdTextLength-3]) + "..."
+	}
+	return text
+}
+
+func (c *Client) getCallToActionType(cta string) string {
+	switch strings.ToLower(cta) {
+	case "learn more":
+		return "LEARN_MORE"
+	case "shop now":
+		return "SHOP_NOW"
+	case "sign up":
+		return "SIGN_UP"
+	case "download":
+		return "DOWNLOAD"
+	case "watch more":
+		return "WATCH_NOW"
+	default:
+		return "LEARN_MORE"
+	}
+}
+
+// apiResponse is the envelope of every TikTok Marketing API response. A code
+// other than 0 is an error, even with an HTTP 200 status.
+type apiResponse struct {
+	Code      int             `json:"code"`
+	Message   string          `json:"message"`
+	RequestID string          `json:"request_id"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// makeAPICall makes an API call to the TikTok Marketing API and decodes the
+// response data into out, unless out is nil
+func (c *Client) makeAPICall(ctx context.Context, method, endpoint string, data interface{}, out interface{}) error {
+	url := fmt.Sprintf("%s/%s", c.baseURL, endpoint)
+
+	var body io.Reader
+	if data != nil {
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request data: %w", err)
+		}
+		body = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// TikTok reads the OAuth2 access token from the Access-Token header; it is
+	// also sent as a standard bearer token
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Access-Token", c.config.AccessToken)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.AccessToken))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make API call: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return &models.APIError{StatusCode: resp.StatusCode, Message: string(respBody)}
+	}
+
+	var response apiResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if response.Code != 0 {
+		return fmt.Errorf("API call failed with code %d: %s (request %s)", response.Code, response.Message, response.RequestID)
+	}
+
+	if out != nil && len(response.Data) > 0 {
+		if err := json.Unmarshal(response.Data, out); err != nil {
+			return fmt.Errorf("failed to unmarshal response data: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// HealthCheck checks the health of the TikTok client
+func (c *Client) HealthCheck(ctx context.Context) error {
+	// Fetch the advertiser to verify connectivity and the access token
+	query := url.Values{"advertiser_ids": {fmt.Sprintf(`["%s"]`, c.config.AdvertiserID)}}
+
+	if err := c.makeAPICall(ctx, http.MethodGet, "advertiser/info/?"+query.Encode(), nil, nil); err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+
+	return nil
+}
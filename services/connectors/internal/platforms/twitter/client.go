@@ -0,0 +1,555 @@
+package twitter
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+)
+
+// locationKeyPattern matches the targeting values Twitter identifies locations with
+var locationKeyPattern = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+// ageBuckets are the age ranges Twitter targets, as their lowest and highest
+// age; 0 is no highest age
+var ageBuckets = []struct {
+	value    string
+	min, max int
+}{
+	{"AGE_13_TO_24", 13, 24}, {"AGE_13_TO_34", 13, 34}, {"AGE_13_TO_49", 13, 49}, {"AGE_13_TO_54", 13, 54}, {"AGE_OVER_13", 13, 0},
+	{"AGE_18_TO_24", 18, 24}, {"AGE_18_TO_34", 18, 34}, {"AGE_18_TO_49", 18, 49}, {"AGE_18_TO_54", 18, 54}, {"AGE_OVER_18", 18, 0},
+	{"AGE_21_TO_34", 21, 34}, {"AGE_21_TO_49", 21, 49}, {"AGE_21_TO_54", 21, 54}, {"AGE_OVER_21", 21, 0},
+	{"AGE_25_TO_49", 25, 49}, {"AGE_25_TO_54", 25, 54}, {"AGE_OVER_25", 25, 0},
+	{"AGE_35_TO_49", 35, 49}, {"AGE_35_TO_54", 35, 54}, {"AGE_OVER_35", 35, 0},
+	{"AGE_OVER_50", 50, 0},
+}
+
+// AgeBucket returns the narrowest Twitter age range including the ages from
+// min to max, where 0 is no limit. It returns an empty string when no age is
+// targeted.
+func AgeBucket(min, max int) (string, error) {
+	if min <= 0 && max <= 0 {
+		return "", nil
+	}
+	if max > 0 && max < min {
+		return "", fmt.Errorf("invalid age range %d-%d", min, max)
+	}
+
+	best := ""
+	bestSpan := 0
+	for _, bucket := range ageBuckets {
+		if bucket.min > min && min > 0 {
+			continue
+		}
+		if max <= 0 && bucket.max != 0 {
+			continue
+		}
+		if bucket.max != 0 && bucket.max < max {
+			continue
+		}
+
+		upper := bucket.max
+		if upper == 0 {
+			upper = 100
+		}
+		if span := upper - bucket.min; best == "" || span < bestSpan {
+			best, bestSpan = bucket.value, span
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("no Twitter age range includes %d-%d", min, max)
+	}
+
+	return best, nil
+}
+
+// Client represents a Twitter Ads API client
+type Client struct {
+	httpClient *http.Client
+	config     *config.TwitterConfig
+	logger     *logrus.Logger
+	baseURL    string
+	apiBaseURL string
+}
+
+// NewClient creates a new Twitter Ads API client
+func NewClient(cfg *config.TwitterConfig, logger *logrus.Logger) (*Client, error) {
+	if cfg.ConsumerKey == "" || cfg.ConsumerSecret == "" {
+		return nil, fmt.Errorf("Twitter consumer key and secret are required")
+	}
+	if cfg.AccessTokenSecret == "" {
+		return nil, fmt.Errorf("Twitter access token secret is required")
+	}
+	if cfg.AccountID == "" {
+		return nil, fmt.Errorf("Twitter Ads account ID is required")
+	}
+	if cfg.FundingInstrumentID == "" {
+		return nil, fmt.Errorf("Twitter funding instrument ID is required")
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = "https://ads-api.twitter.com/12"
+	}
+	apiBaseURL := cfg.APIBaseURL
+	if apiBaseURL == "" {
+		apiBaseURL = "https://api.twitter.com"
+	}
+
+	client := &Client{
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		config:     cfg,
+		logger:     logger,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiBaseURL: strings.TrimSuffix(apiBaseURL, "/"),
+	}
+
+	logger.WithFields(logrus.Fields{
+		"account_id": cfg.AccountID,
+	}).Info("Twitter Ads API client initialized")
+
+	return client, nil
+}
+
+// DeployAsset deploys an asset to Twitter as a Promoted Tweet campaign
+// promoting the tweet of its creative specs
+func (c *Client) DeployAsset(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error) {
+	startTime := time.Now()
+	logger := c.logger.WithFields(logrus.Fields{
+		"asset_id":     request.AssetID,
+		"content_type": request.ContentType,
+		"platform":     models.PlatformTwitter,
+	})
+
+	logger.Info("Starting Twitter deployment")
+
+	result := &models.DeploymentResult{
+		AssetID:    request.AssetID,
+		Platform:   models.PlatformTwitter,
+		Status:     models.DeploymentStatusRunning,
+		DeployedAt: time.Now(),
+		Metrics: models.DeploymentMetrics{
+			RetryCount: 0,
+		},
+	}
+
+	err := c.deploy(ctx, request, result)
+
+	// Update metrics
+	result.Metrics.Duration = time.Since(startTime)
+
+	if err != nil {
+		result.Status = models.DeploymentStatusFailed
+		result.Error = err.Error()
+		logger.WithError(err).Error("Twitter deployment failed")
+		return result, err
+	}
+
+	result.Status = models.DeploymentStatusSuccess
+	logger.WithFields(logrus.Fields{
+		"platform_id":  result.PlatformID,
+		"platform_url": result.PlatformURL,
+		"duration":     result.Metrics.Duration,
+	}).Info("Twitter deployment successful")
+
+	return result, nil
+}
+
+// deploy creates the campaign, its line item targeting the asset's audience
+// and the Promoted Tweet of the line item
+func (c *Client) deploy(ctx context.Context, request *models.DeploymentRequest, result *models.DeploymentResult) error {
+	// Validate before anything is created in the ads account
+	tweetID := strings.TrimSpace(request.Metadata.CreativeSpecs.TweetID)
+	if tweetID == "" {
+		return fmt.Errorf("tweet ID is required for Promoted Tweets")
+	}
+	if request.Metadata.Budget <= 0 {
+		return fmt.Errorf("a daily budget is required for Twitter campaigns")
+	}
+	ageBucket, err := AgeBucket(request.Metadata.Demographics.AgeMin, request.Metadata.Demographics.AgeMax)
+	if err != nil {
+		return err
+	}
+	criteria, err := c.buildTargeting(ctx, request.Metadata.Demographics, ageBucket)
+	if err != nil {
+		return err
+	}
+
+	// The campaign is created paused and only activated once its Promoted Tweet
+	// exists, so that a failed deployment leaves nothing serving
+	var campaign struct {
+		ID string `json:"id"`
+	}
+	err = c.makeAPICall(ctx, http.MethodPost, c.accountPath()+"/campaigns", url.Values{
+		"name":                            {request.Metadata.CreativeSpecs.VariantName(fmt.Sprintf("ZAMC - %s - %s", request.Title, request.AssetID))},
+		"funding_instrument_id":           {c.config.FundingInstrumentID},
+		"daily_budget_amount_local_micro": {strconv.FormatInt(int64(request.Metadata.Budget*1e6), 10)},
+		"entity_status":                   {"PAUSED"},
+	}, nil, &campaign)
+	if err != nil {
+		return fmt.Errorf("failed to create campaign: %w", err)
+	}
+	if campaign.ID == "" {
+		return fmt.Errorf("failed to create campaign: no campaign ID in response")
+	}
+
+	promotedTweetID, lineItemID, err := c.promoteTweet(ctx, request, campaign.ID, tweetID, criteria)
+	if err == nil {
+		err = c.makeAPICall(ctx, http.MethodPut, c.accountPath()+"/campaigns/"+campaign.ID, url.Values{
+			"entity_status": {"ACTIVE"},
+		}, nil, nil)
+		if err != nil {
+			err = fmt.Errorf("failed to activate campaign: %w", err)
+		}
+	}
+	if err != nil {
+		c.deleteCampaign(campaign.ID)
+		return err
+	}
+
+	result.PlatformID = promotedTweetID
+	result.CampaignID = campaign.ID
+	result.AdGroupID = lineItemID
+	result.PlatformURL = fmt.Sprintf("https://ads.twitter.com/ads_manager/%s/campaigns/%s", c.config.AccountID, campaign.ID)
+
+	return nil
+}
+
+// promoteTweet creates the line item of the campaign campaignID targeting
+// criteria and the Promoted Tweet of tweetID in it, and returns their IDs
+func (c *Client) promoteTweet(ctx context.Context, request *models.DeploymentRequest, campaignID, tweetID string, criteria []map[string]interface{}) (string, string, error) {
+	var lineItem struct {
+		ID string `json:"id"`
+	}
+	err := c.makeAPICall(ctx, http.MethodPost, c.accountPath()+"/line_items", url.Values{
+		"campaign_id":   {campaignID},
+		"name":          {fmt.Sprintf("ZAMC - %s", request.Title)},
+		"objective":     {"ENGAGEMENTS"},
+		"placements":    {"ALL_ON_TWITTER"},
+		"product_type":  {"PROMOTED_TWEETS"},
+		"bid_strategy":  {"AUTO"},
+		"entity_status": {"ACTIVE"},
+	}, nil, &lineItem)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create line item: %w", err)
+	}
+	if lineItem.ID == "" {
+		return "", "", fmt.Errorf("failed to create line item: no line item ID in response")
+	}
+
+	if len(criteria) > 0 {
+		operations := make([]map[string]interface{}, 0, len(criteria))
+		for _, criterion := range criteria {
+			criterion["line_item_id"] = lineItem.ID
+			operations = append(operations, map[string]interface{}{
+				"operation_type": "Create",
+				"params":         criterion,
+			})
+		}
+		err = c.makeAPICall(ctx, http.MethodPost, "/batch"+c.accountPath()+"/targeting_criteria", nil, operations, nil)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to create targeting criteria: %w", err)
+		}
+	}
+
+	var promotedTweets []struct {
+		ID string `json:"id"`
+	}
+	err = c.makeAPICall(ctx, http.MethodPost, c.accountPath()+"/promoted_tweets", url.Values{
+		"line_item_id": {lineItem.ID},
+		"tweet_ids":    {tweetID},
+	}, nil, &promotedTweets)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create promoted tweet: %w", err)
+	}
+	if len(promotedTweets) == 0 || promotedTweets[0].ID == "" {
+		return "", "", fmt.Errorf("failed to create promoted tweet: no promoted tweet ID in response")
+	}
+
+	return promotedTweets[0].ID, lineItem.ID, nil
+}
+
+// deleteCampaign deletes the paused campaign of a failed deployment, with its
+// line item and Promoted Tweet. It runs on a context of its own, since the
+// deployment may have failed because its context was cancelled.
+func (c *Client) deleteCampaign(campaignID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := c.makeAPICall(ctx, http.MethodDelete, c.accountPath()+"/campaigns/"+campaignID, nil, nil, nil); err != nil {
+		c.logger.WithError(err).WithField("campaign_id", campaignID).Error("Failed to delete campaign of failed Twitter deployment")
+	}
+}
+
+// buildTargeting returns the targeting criteria of a line item for the asset's
+// locations, interests, genders and ages, looking up the targeting values of
+// locations and interests
+func (c *Client) buildTargeting(ctx context.Context, demographics models.Demographics, ageBucket string) ([]map[string]interface{}, error) {
+	var criteria []map[string]interface{}
+	add := func(targetingType, value string) {
+		criteria = append(criteria, map[string]interface{}{
+			"targeting_type":  targetingType,
+			"targeting_value": value,
+		})
+	}
+
+	for _, location := range demographics.Locations {
+		key, err := c.locationKey(ctx, location)
+		if err != nil {
+			return nil, err
+		}
+		add("LOCATION", key)
+	}
+
+	for _, interest := range demographics.Interests {
+		value, err := c.lookupTargetingValue(ctx, "interests", url.Values{"q": {interest}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up interest %q: %w", interest, err)
+		}
+		add("INTEREST", value)
+	}
+
+	for _, gender := range demographics.Genders {
+		switch strings.ToLower(strings.TrimSpace(gender)) {
+		case "male", "m", "1":
+			add("GENDER", "1")
+		case "female", "f", "2":
+			add("GENDER", "2")
+		default:
+			return nil, fmt.Errorf("unknown Twitter gender %q, use male or female", gender)
+		}
+	}
+
+	if ageBucket != "" {
+		add("AGE", ageBucket)
+	}
+
+	return criteria, nil
+}
+
+// locationKey returns the targeting value of location, which is a country code,
+// a location name or a Twitter location key
+func (c *Client) locationKey(ctx context.Context, location string) (string, error) {
+	location = strings.TrimSpace(location)
+	if locationKeyPattern.MatchString(location) {
+		return location, nil
+	}
+
+	query := url.Values{"q": {location}}
+	if len(location) == 2 {
+		query = url.Values{"country_code": {strings.ToUpper(location)}, "location_type": {"COUNTRIES"}}
+	}
+
+	key, err := c.lookupTargetingValue(ctx, "locations", query)
+	if err != nil {
+		return "", fmt.Errorf("unknown Twitter location %q: %w", location, err)
+	}
+	return key, nil
+}
+
+// lookupTargetingValue returns the targeting value of the first targeting
+// criterion of the given kind matching query
+func (c *Client) lookupTargetingValue(ctx context.Context, kind string, query url.Values) (string, error) {
+	var matches []struct {
+		TargetingValue string `json:"targeting_value"`
+	}
+	if err := c.makeAPICall(ctx, http.MethodGet, "/targeting_criteria/"+kind, query, nil, &matches); err != nil {
+		return "", err
+	}
+	if len(matches) == 0 || matches[0].TargetingValue == "" {
+		return "", fmt.Errorf("no match")
+	}
+
+	return matches[0].TargetingValue, nil
+}
+
+// accountPath returns the path of the configured ads account
+func (c *Client) accountPath() string {
+	return "/accounts/" + c.config.AccountID
+}
+
+// apiResponse is the envelope of Twitter Ads API responses
+type apiResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []apiError      `json:"errors"`
+}
+
+// apiError is an error of a Twitter API response. The Ads API names errors with
+// string codes and the standard API with numbers.
+type apiError struct {
+	Code    interface{} `json:"code"`
+	Message string      `json:"message"`
+}
+
+// makeAPICall makes an API call to the Twitter Ads API with the parameters in
+// query, or with data as its JSON body, and decodes the response data into out,
+// unless out is nil
+func (c *Client) makeAPICall(ctx context.Context, method, endpoint string, query url.Values, data interface{}, out interface{}) error {
+	return c.call(ctx, method, c.baseURL+endpoint, query, data, out, true)
+}
+
+// call makes a request signed with the OAuth 1.0a credentials of the client.
+// Ads API responses wrap their data in an envelope; others are decoded as is.
+func (c *Client) call(ctx context.Context, method, endpoint string, query url.Values, data interface{}, out interface{}, enveloped bool) error {
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if data != nil {
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request data: %w", err)
+		}
+		body = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	nonce, err := newNonce()
+	if err != nil {
+		return err
+	}
+	SignRequest(req, c.config, nonce, time.Now().Unix())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make API call: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var response apiResponse
+	if resp.StatusCode >= 400 {
+		if err := json.Unmarshal(respBody, &response); err == nil && len(response.Errors) > 0 {
+			return &models.APIError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("%s (%v)", response.Errors[0].Message, response.Errors[0].Code)}
+		}
+		return &models.APIError{StatusCode: resp.StatusCode, Message: string(respBody)}
+	}
+
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+	if !enveloped {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		return nil
+	}
+
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(response.Data) > 0 {
+		if err := json.Unmarshal(response.Data, out); err != nil {
+			return fmt.Errorf("failed to unmarshal response data: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// SignRequest sets the OAuth 1.0a Authorization header of req, signed with
+// HMAC-SHA1 for the credentials of cfg. The signature covers the query
+// parameters of req but not its body, so parameters must not be form encoded.
+func SignRequest(req *http.Request, cfg *config.TwitterConfig, nonce string, timestamp int64) {
+	oauthParams := map[string]string{
+		"oauth_consumer_key":     cfg.ConsumerKey,
+		"oauth_nonce":            nonce,
+		"oauth_signature_method": "HMAC-SHA1",
+		"oauth_timestamp":        strconv.FormatInt(timestamp, 10),
+		"oauth_token":            cfg.AccessToken,
+		"oauth_version":          "1.0",
+	}
+
+	var params []string
+	for key, values := range req.URL.Query() {
+		for _, value := range values {
+			params = append(params, percentEncode(key)+"="+percentEncode(value))
+		}
+	}
+	for key, value := range oauthParams {
+		params = append(params, percentEncode(key)+"="+percentEncode(value))
+	}
+	sort.Strings(params)
+
+	baseURL := fmt.Sprintf("%s://%s%s", strings.ToLower(req.URL.Scheme), strings.ToLower(req.URL.Host), req.URL.EscapedPath())
+	base := strings.Join([]string{
+		strings.ToUpper(req.Method),
+		percentEncode(baseURL),
+		percentEncode(strings.Join(params, "&")),
+	}, "&")
+
+	mac := hmac.New(sha1.New, []byte(percentEncode(cfg.ConsumerSecret)+"&"+percentEncode(cfg.AccessTokenSecret)))
+	mac.Write([]byte(base))
+	oauthParams["oauth_signature"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	keys := make([]string, 0, len(oauthParams))
+	for key := range oauthParams {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	header := make([]string, 0, len(keys))
+	for _, key := range keys {
+		header = append(header, fmt.Sprintf(`%s="%s"`, percentEncode(key), percentEncode(oauthParams[key])))
+	}
+	req.Header.Set("Authorization", "OAuth "+strings.Join(header, ", "))
+}
+
+// percentEncode encodes s as RFC 3986 requires for OAuth 1.0a signatures
+func percentEncode(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// newNonce returns a random nonce for a signed request
+func newNonce() (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return hex.EncodeToString(nonce), nil
+}
+
+// HealthCheck checks the health of the Twitter client
+func (c *Client) HealthCheck(ctx context.Context) error {
+	// Fetch the authenticated user to verify connectivity and the credentials
+	var user struct {
+		ID string `json:"id_str"`
+	}
+	endpoint := c.apiBaseURL + "/1.1/account/verify_credentials.json"
+	if err := c.call(ctx, http.MethodGet, endpoint, nil, nil, &user, false); err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+
+	return nil
+}
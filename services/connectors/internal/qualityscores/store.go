@@ -0,0 +1,70 @@
+package qualityscores
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+// Store persists keyword quality scores in the keyword_quality_scores table
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates a quality score store backed by db
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Open connects to the database and creates a quality score store
+func Open(databaseURL string) (*Store, error) {
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open quality scores database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping quality scores database: %w", err)
+	}
+
+	return NewStore(db), nil
+}
+
+// SaveScores saves the keyword quality scores of an asset, replacing the previous
+// score of each keyword
+func (s *Store) SaveScores(ctx context.Context, assetID uuid.UUID, scores []models.KeywordQualityScore) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, score := range scores {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO keyword_quality_scores (asset_id, keyword_id, ad_group_id, keyword, score, fetched_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (asset_id, ad_group_id, keyword_id) DO UPDATE
+			SET keyword = EXCLUDED.keyword, score = EXCLUDED.score, fetched_at = EXCLUDED.fetched_at
+		`, assetID, score.KeywordID, score.AdGroupID, score.Keyword, score.Score, score.FetchedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save quality score of keyword %s: %w", score.KeywordID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit quality scores: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the underlying database connection
+func (s *Store) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,274 @@
+// Package queue runs platform deployments on a pool of workers per platform, so
+// that a slow platform API only holds up the deployments to that platform
+package queue
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/zamc/connectors/internal/metrics"
+	"github.com/zamc/connectors/internal/models"
+)
+
+// ErrStopped is returned for requests enqueued after the queue was stopped, and
+// reported for requests still queued when it stops
+var ErrStopped = errors.New("deployment queue is stopped")
+
+// Priority orders queued deployments; lower values are deployed first
+type Priority int
+
+const (
+	PriorityHigh   Priority = 1
+	PriorityNormal Priority = 2
+	PriorityLow    Priority = 3
+)
+
+// PriorityOf returns the priority of deployments of metadata's campaign type.
+// Campaigns driving conversions go first and awareness campaigns last.
+func PriorityOf(metadata models.Metadata) Priority {
+	switch strings.ToLower(metadata.CampaignType) {
+	case "conversion", "conversions", "sales", "leads":
+		return PriorityHigh
+	case "awareness", "reach", "brand_awareness":
+		return PriorityLow
+	default:
+		return PriorityNormal
+	}
+}
+
+// Handler deploys a request to its platform, retrying failed attempts
+type Handler func(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error)
+
+// PrioritizedRequest is a deployment waiting for a worker of its platform
+type PrioritizedRequest struct {
+	Request  *models.DeploymentRequest
+	Priority Priority
+
+	// Done is called by the worker with the outcome of the deployment
+	Done func(result *models.DeploymentResult, err error)
+
+	ctx context.Context
+	seq uint64
+}
+
+// DeploymentQueue hands deployments to the workers of their platform, highest
+// priority first and in the order they were enqueued within a priority
+type DeploymentQueue struct {
+	pools   map[models.Platform]*pool
+	handler Handler
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	// mu is held for reading while requests are enqueued, so that Stop waits
+	// for them
+	mu      sync.RWMutex
+	stopped bool
+}
+
+// New creates a queue with workers deployments running at once to each of
+// platforms, each platform holding at most size waiting deployments before
+// Enqueue blocks, and starts its workers
+func New(platforms []models.Platform, workers, size int, handler Handler) *DeploymentQueue {
+	if workers <= 0 {
+		workers = 1
+	}
+	if size <= 0 {
+		size = 100
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &DeploymentQueue{
+		pools:   make(map[models.Platform]*pool, len(platforms)),
+		handler: handler,
+		cancel:  cancel,
+	}
+	for _, platform := range platforms {
+		p := &pool{platform: platform, incoming: make(chan *PrioritizedRequest, size)}
+		q.pools[platform] = p
+		metrics.DeploymentQueueDepth.WithLabelValues(string(platform)).Set(0)
+
+		for i := 0; i < workers; i++ {
+			q.wg.Add(1)
+			go func() {
+				defer q.wg.Done()
+				q.work(ctx, p)
+			}()
+		}
+	}
+	return q
+}
+
+// Enqueue queues the deployment of request with priority, calling done with its
+// outcome once a worker has deployed it. The deployment runs with ctx. Enqueue
+// blocks while the platform's queue is full, until ctx is done.
+func (q *DeploymentQueue) Enqueue(ctx context.Context, request *models.DeploymentRequest, priority Priority, done func(*models.DeploymentResult, error)) error {
+	p, ok := q.pools[request.Platform]
+	if !ok {
+		return fmt.Errorf("unsupported platform: %s", request.Platform)
+	}
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.stopped {
+		return ErrStopped
+	}
+
+	p.mu.Lock()
+	p.seq++
+	req := &PrioritizedRequest{Request: request, Priority: priority, Done: done, ctx: ctx, seq: p.seq}
+	p.mu.Unlock()
+
+	select {
+	case p.incoming <- req:
+		p.updateDepth()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Depths returns the number of deployments waiting for a worker of each platform
+func (q *DeploymentQueue) Depths() map[models.Platform]int {
+	depths := make(map[models.Platform]int, len(q.pools))
+	for platform, p := range q.pools {
+		depths[platform] = p.depth()
+	}
+	return depths
+}
+
+// Stop stops the workers once their current deployments are done. Deployments
+// still waiting fail with ErrStopped.
+func (q *DeploymentQueue) Stop() {
+	q.mu.Lock()
+	q.stopped = true
+	q.cancel()
+	q.mu.Unlock()
+	q.wg.Wait()
+
+	for _, p := range q.pools {
+		p.mu.Lock()
+		p.drain()
+		waiting := make([]*PrioritizedRequest, 0, p.waiting.Len())
+		for p.waiting.Len() > 0 {
+			waiting = append(waiting, heap.Pop(&p.waiting).(*PrioritizedRequest))
+		}
+		p.mu.Unlock()
+		p.updateDepth()
+
+		for _, req := range waiting {
+			req.Done(nil, ErrStopped)
+		}
+	}
+}
+
+// work deploys the requests of p until ctx is done
+func (q *DeploymentQueue) work(ctx context.Context, p *pool) {
+	for {
+		req, ok := p.next(ctx)
+		if !ok {
+			return
+		}
+		p.updateDepth()
+
+		// The enqueuer may have given up while the request waited
+		if err := req.ctx.Err(); err != nil {
+			req.Done(nil, err)
+			continue
+		}
+
+		result, err := q.handler(req.ctx, req.Request)
+		req.Done(result, err)
+	}
+}
+
+// pool holds the deployments waiting for the workers of a platform. Enqueued
+// requests are buffered in incoming and moved to the waiting heap by the workers.
+type pool struct {
+	platform models.Platform
+	incoming chan *PrioritizedRequest
+
+	mu      sync.Mutex
+	waiting requestHeap
+	seq     uint64
+}
+
+// next returns the waiting request of highest priority, waiting for one until
+// ctx is done
+func (p *pool) next(ctx context.Context) (*PrioritizedRequest, bool) {
+	for {
+		if ctx.Err() != nil {
+			return nil, false
+		}
+
+		p.mu.Lock()
+		p.drain()
+		if p.waiting.Len() > 0 {
+			req := heap.Pop(&p.waiting).(*PrioritizedRequest)
+			p.mu.Unlock()
+			return req, true
+		}
+		p.mu.Unlock()
+
+		select {
+		case req := <-p.incoming:
+			p.mu.Lock()
+			heap.Push(&p.waiting, req)
+			p.mu.Unlock()
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+// drain moves the buffered requests to the waiting heap. p.mu must be held.
+func (p *pool) drain() {
+	for {
+		select {
+		case req := <-p.incoming:
+			heap.Push(&p.waiting, req)
+		default:
+			return
+		}
+	}
+}
+
+// depth returns the number of requests waiting for a worker
+func (p *pool) depth() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.incoming) + p.waiting.Len()
+}
+
+// updateDepth exports the depth of the pool
+func (p *pool) updateDepth() {
+	metrics.DeploymentQueueDepth.WithLabelValues(string(p.platform)).Set(float64(p.depth()))
+}
+
+// requestHeap is a min-heap of requests by priority, then by enqueue order
+type requestHeap []*PrioritizedRequest
+
+func (h requestHeap) Len() int { return len(h) }
+
+func (h requestHeap) Less(i, j int) bool {
+	if h[i].Priority != h[j].Priority {
+		return h[i].Priority < h[j].Priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h requestHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *requestHeap) Push(x interface{}) { *h = append(*h, x.(*PrioritizedRequest)) }
+
+func (h *requestHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	req := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return req
+}
@@ -0,0 +1,316 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+const (
+	// MetaUsageHeader reports the share of its rate limits a Meta business has
+	// used, as JSON keyed by business ID
+	MetaUsageHeader = "X-Business-Use-Case-Usage"
+
+	// GoogleAdsQuotaHeader reports the percentage of its API quota a Google Ads
+	// account has left
+	GoogleAdsQuotaHeader = "X-Goog-User-Quota-Remaining"
+
+	// LowQuotaPercent is the percentage of quota left below which an account
+	// backs off
+	LowQuotaPercent = 10
+
+	// DefaultBaseBackoff and DefaultMaxBackoff bound the backoff of an account
+	// when the limiter is created without them
+	DefaultBaseBackoff = 30 * time.Second
+	DefaultMaxBackoff  = 5 * time.Minute
+)
+
+// ErrQuotaExhausted is matched by the errors of calls refused because an account
+// ran out of API quota
+var ErrQuotaExhausted = errors.New("platform API quota exhausted")
+
+// QuotaError is returned for calls to an account that is backing off, or that the
+// platform refused for the account's quota. RetryAfter is the time left until the
+// backoff ends.
+type QuotaError struct {
+	Platform   models.Platform
+	AccountID  string
+	RetryAfter time.Duration
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s API quota exhausted for account %s, retry in %s", e.Platform, e.AccountID, e.RetryAfter)
+}
+
+// Is makes errors.Is match ErrQuotaExhausted
+func (e *QuotaError) Is(target error) bool {
+	return target == ErrQuotaExhausted
+}
+
+// QuotaStatus is the API quota last reported for an account
+type QuotaStatus struct {
+	Platform         models.Platform `json:"platform"`
+	AccountID        string          `json:"account_id"`
+	RemainingPercent float64         `json:"remaining_percent"`
+	BackoffUntil     *time.Time      `json:"backoff_until,omitempty"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+// Low returns true if the account has less than LowQuotaPercent of its quota left
+func (s QuotaStatus) Low() bool {
+	return s.RemainingPercent < LowQuotaPercent
+}
+
+// accountQuota is the quota state of one account
+type accountQuota struct {
+	mu       sync.Mutex
+	status   QuotaStatus
+	backoffs int // consecutive responses with a low quota
+}
+
+// PlatformRateLimiter keeps track of the API quota the Meta and Google Ads
+// accounts have left, from the headers of their responses. Once an account has
+// less than LowQuotaPercent left, calls to it are refused for a backoff that
+// doubles with every further low response, until the quota recovers.
+type PlatformRateLimiter struct {
+	accounts    sync.Map // "platform:accountID" -> *accountQuota
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	now         func() time.Time
+}
+
+// NewPlatformRateLimiter creates a limiter backing off for baseBackoff at first,
+// and for at most maxBackoff. Zero durations use the defaults.
+func NewPlatformRateLimiter(baseBackoff, maxBackoff time.Duration) *PlatformRateLimiter {
+	if baseBackoff <= 0 {
+		baseBackoff = DefaultBaseBackoff
+	}
+	if maxBackoff < baseBackoff {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	return &PlatformRateLimiter{
+		baseBackoff: baseBackoff,
+		maxBackoff:  maxBackoff,
+		now:         time.Now,
+	}
+}
+
+// SetClock replaces the clock of the limiter, for tests
+func (l *PlatformRateLimiter) SetClock(now func() time.Time) {
+	l.now = now
+}
+
+// account returns the quota state of an account, creating it on first use
+func (l *PlatformRateLimiter) account(platform models.Platform, accountID string) *accountQuota {
+	key := fmt.Sprintf("%s:%s", platform, accountID)
+	if quota, ok := l.accounts.Load(key); ok {
+		return quota.(*accountQuota)
+	}
+
+	quota, _ := l.accounts.LoadOrStore(key, &accountQuota{status: QuotaStatus{
+		Platform:         platform,
+		AccountID:        accountID,
+		RemainingPercent: 100,
+	}})
+	return quota.(*accountQuota)
+}
+
+// Allow returns a *QuotaError while the account is backing off
+func (l *PlatformRateLimiter) Allow(platform models.Platform, accountID string) error {
+	quota := l.account(platform, accountID)
+	quota.mu.Lock()
+	defer quota.mu.Unlock()
+
+	if quota.status.BackoffUntil == nil {
+		return nil
+	}
+	if wait := quota.status.BackoffUntil.Sub(l.now()); wait > 0 {
+		return &QuotaError{Platform: platform, AccountID: accountID, RetryAfter: wait}
+	}
+	return nil
+}
+
+// Observe records the quota left that the headers of a platform response report.
+// Responses without quota headers are ignored.
+func (l *PlatformRateLimiter) Observe(platform models.Platform, accountID string, header http.Header) {
+	switch platform {
+	case models.PlatformMeta:
+		if remaining, regainAfter, ok := parseMetaUsage(header.Get(MetaUsageHeader)); ok {
+			l.record(platform, accountID, remaining, regainAfter)
+		}
+	case models.PlatformGoogleAds:
+		if remaining, ok := parseGoogleAdsQuota(header.Get(GoogleAdsQuotaHeader)); ok {
+			l.record(platform, accountID, remaining, 0)
+		}
+	}
+}
+
+// Exhausted records a call the platform refused because the account has no quota
+// left, and returns the *QuotaError to fail the call with
+func (l *PlatformRateLimiter) Exhausted(platform models.Platform, accountID string) error {
+	l.record(platform, accountID, 0, 0)
+	if err := l.Allow(platform, accountID); err != nil {
+		return err
+	}
+	return &QuotaError{Platform: platform, AccountID: accountID}
+}
+
+// record updates the quota of an account, backing off for at least minBackoff
+// when it is low and ending the backoff when it recovered
+func (l *PlatformRateLimiter) record(platform models.Platform, accountID string, remaining float64, minBackoff time.Duration) {
+	quota := l.account(platform, accountID)
+	quota.mu.Lock()
+	defer quota.mu.Unlock()
+
+	now := l.now()
+	quota.status.RemainingPercent = remaining
+	quota.status.UpdatedAt = now
+
+	if !quota.status.Low() {
+		quota.backoffs = 0
+		quota.status.BackoffUntil = nil
+		return
+	}
+
+	backoff := l.baseBackoff
+	for i := 0; i < quota.backoffs && backoff < l.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > l.maxBackoff {
+		backoff = l.maxBackoff
+	}
+	if backoff < minBackoff {
+		backoff = minBackoff
+	}
+	quota.backoffs++
+
+	until := now.Add(backoff)
+	quota.status.BackoffUntil = &until
+}
+
+// Quotas returns the quota of every account seen, ordered by platform and account
+func (l *PlatformRateLimiter) Quotas() []QuotaStatus {
+	quotas := []QuotaStatus{}
+	l.accounts.Range(func(_, value interface{}) bool {
+		quota := value.(*accountQuota)
+		quota.mu.Lock()
+		status := quota.status
+		quota.mu.Unlock()
+
+		// A backoff that is over no longer holds calls back
+		if status.BackoffUntil != nil && !status.BackoffUntil.After(l.now()) {
+			status.BackoffUntil = nil
+		}
+		quotas = append(quotas, status)
+		return true
+	})
+
+	sort.Slice(quotas, func(i, j int) bool {
+		if quotas[i].Platform != quotas[j].Platform {
+			return quotas[i].Platform < quotas[j].Platform
+		}
+		return quotas[i].AccountID < quotas[j].AccountID
+	})
+	return quotas
+}
+
+// metaUsage is the usage of one rate limit of a Meta business, in percent
+type metaUsage struct {
+	CallCount                   float64 `json:"call_count"`
+	TotalCPUTime                float64 `json:"total_cputime"`
+	TotalTime                   float64 `json:"total_time"`
+	EstimatedTimeToRegainAccess float64 `json:"estimated_time_to_regain_access"` // minutes
+}
+
+// parseMetaUsage returns the percentage of quota left by the most used rate limit
+// in a x-business-use-case-usage header, and how long Meta expects access to be
+// blocked for
+func parseMetaUsage(value string) (float64, time.Duration, bool) {
+	if value == "" {
+		return 0, 0, false
+	}
+
+	var businesses map[string][]metaUsage
+	if err := json.Unmarshal([]byte(value), &businesses); err != nil {
+		return 0, 0, false
+	}
+
+	var used float64
+	var regainAfter time.Duration
+	found := false
+	for _, usages := range businesses {
+		for _, usage := range usages {
+			found = true
+			for _, percent := range []float64{usage.CallCount, usage.TotalCPUTime, usage.TotalTime} {
+				if percent > used {
+					used = percent
+				}
+			}
+			if wait := time.Duration(usage.EstimatedTimeToRegainAccess * float64(time.Minute)); wait > regainAfter {
+				regainAfter = wait
+			}
+		}
+	}
+	if !found {
+		return 0, 0, false
+	}
+
+	return clampPercent(100 - used), regainAfter, true
+}
+
+// parseGoogleAdsQuota returns the percentage of quota left in a
+// x-goog-user-quota-remaining header
+func parseGoogleAdsQuota(value string) (float64, bool) {
+	remaining, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "%"), 64)
+	if err != nil {
+		return 0, false
+	}
+	return clampPercent(remaining), true
+}
+
+func clampPercent(percent float64) float64 {
+	if percent < 0 {
+		return 0
+	}
+	if percent > 100 {
+		return 100
+	}
+	return percent
+}
+
+// IsMetaRateLimitError returns true if body is a Meta error response for
+// Error 17: User request limit reached
+func IsMetaRateLimitError(body []byte) bool {
+	var response struct {
+		Error struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return false
+	}
+	return response.Error.Code == 17
+}
+
+// IsGoogleAdsQuotaError returns true if body is a Google Ads error response with
+// the RESOURCE_EXHAUSTED status
+func IsGoogleAdsQuotaError(body []byte) bool {
+	var response struct {
+		Error struct {
+			Status string `json:"status"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return false
+	}
+	return response.Error.Status == "RESOURCE_EXHAUSTED"
+}
@@ -0,0 +1,225 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+// ErrNotFound is returned when a campaign schedule does not exist or belongs to another tenant
+var ErrNotFound = errors.New("campaign schedule not found")
+
+// ScheduleStore persists campaign schedules and their next firings, shared by
+// every instance of the service
+type ScheduleStore interface {
+	SaveSchedule(ctx context.Context, schedule *models.CampaignSchedule) error
+	GetSchedule(ctx context.Context, platform models.Platform, platformCampaignID string) (*models.CampaignSchedule, error)
+	DeleteSchedule(ctx context.Context, id uuid.UUID, tenantID string) error
+
+	// ClaimDueSchedules passes each schedule with a firing due at now, or not
+	// run yet, to advance, which moves its next firings past now, and saves
+	// them. A schedule is claimed by one caller at a time, so each firing is
+	// passed to advance once across instances.
+	ClaimDueSchedules(ctx context.Context, now time.Time, advance func(schedule *models.CampaignSchedule)) error
+
+	// NextFiring returns the earliest next firing of all schedules
+	NextFiring(ctx context.Context) (time.Time, bool, error)
+}
+
+// claimBatch is how many due schedules are claimed at once
+const claimBatch = 100
+
+// Store persists campaign schedules in the campaign_schedules table
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates a schedule store backed by db
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Open connects to the database and creates a schedule store
+func Open(databaseURL string) (*Store, error) {
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open schedules database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping schedules database: %w", err)
+	}
+
+	return NewStore(db), nil
+}
+
+// SaveSchedule saves the schedule of a campaign, replacing its existing schedule.
+// The ID and creation time of the saved schedule are set on schedule. A campaign
+// scheduled by another tenant is left untouched and ErrNotFound is returned.
+func (s *Store) SaveSchedule(ctx context.Context, schedule *models.CampaignSchedule) error {
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO campaign_schedules (tenant_id, platform, platform_campaign_id, pause_cron, resume_cron, timezone, next_pause_at, next_resume_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (platform, platform_campaign_id) DO UPDATE
+		SET pause_cron = EXCLUDED.pause_cron, resume_cron = EXCLUDED.resume_cron, timezone = EXCLUDED.timezone,
+			next_pause_at = EXCLUDED.next_pause_at, next_resume_at = EXCLUDED.next_resume_at
+		WHERE campaign_schedules.tenant_id = EXCLUDED.tenant_id
+		RETURNING id, created_at
+	`, schedule.TenantID, schedule.Platform, schedule.PlatformCampaignID,
+		schedule.PauseCron, schedule.ResumeCron, schedule.Timezone,
+		nullTime(schedule.NextPauseAt), nullTime(schedule.NextResumeAt)).Scan(&schedule.ID, &schedule.CreatedAt)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to save campaign schedule: %w", err)
+	}
+
+	return nil
+}
+
+// GetSchedule returns the schedule of a platform campaign
+func (s *Store) GetSchedule(ctx context.Context, platform models.Platform, platformCampaignID string) (*models.CampaignSchedule, error) {
+	schedule, err := scanSchedule(s.db.QueryRowContext(ctx, `
+		SELECT id, tenant_id, platform, platform_campaign_id, pause_cron, resume_cron, timezone, created_at, next_pause_at, next_resume_at
+		FROM campaign_schedules
+		WHERE platform = $1 AND platform_campaign_id = $2
+	`, platform, platformCampaignID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query campaign schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// DeleteSchedule deletes a schedule of tenantID
+func (s *Store) DeleteSchedule(ctx context.Context, id uuid.UUID, tenantID string) error {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM campaign_schedules WHERE id = $1 AND tenant_id = $2
+	`, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete campaign schedule: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete campaign schedule: %w", err)
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ClaimDueSchedules locks the due schedules that no other instance holds, in a
+// transaction that saves the firings advance moves them to
+func (s *Store) ClaimDueSchedules(ctx context.Context, now time.Time, advance func(schedule *models.CampaignSchedule)) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, tenant_id, platform, platform_campaign_id, pause_cron, resume_cron, timezone, created_at, next_pause_at, next_resume_at
+		FROM campaign_schedules
+		WHERE next_pause_at IS NULL OR next_pause_at <= $1
+			OR next_resume_at IS NULL OR next_resume_at <= $1
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, now, claimBatch)
+	if err != nil {
+		return fmt.Errorf("failed to query due campaign schedules: %w", err)
+	}
+
+	var schedules []*models.CampaignSchedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan campaign schedule: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate campaign schedules: %w", err)
+	}
+
+	for _, schedule := range schedules {
+		advance(schedule)
+		_, err := tx.ExecContext(ctx, `
+			UPDATE campaign_schedules SET next_pause_at = $1, next_resume_at = $2 WHERE id = $3
+		`, nullTime(schedule.NextPauseAt), nullTime(schedule.NextResumeAt), schedule.ID)
+		if err != nil {
+			return fmt.Errorf("failed to save campaign schedule firings: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit campaign schedule claims: %w", err)
+	}
+
+	return nil
+}
+
+// NextFiring returns the earliest next pause or resume of all schedules
+func (s *Store) NextFiring(ctx context.Context) (time.Time, bool, error) {
+	var next sql.NullTime
+	err := s.db.QueryRowContext(ctx, `
+		SELECT MIN(LEAST(next_pause_at, next_resume_at)) FROM campaign_schedules
+	`).Scan(&next)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to query next campaign schedule firing: %w", err)
+	}
+
+	return next.Time, next.Valid, nil
+}
+
+// Close closes the underlying database connection
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSchedule(row rowScanner) (*models.CampaignSchedule, error) {
+	var schedule models.CampaignSchedule
+	var nextPause, nextResume sql.NullTime
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.TenantID,
+		&schedule.Platform,
+		&schedule.PlatformCampaignID,
+		&schedule.PauseCron,
+		&schedule.ResumeCron,
+		&schedule.Timezone,
+		&schedule.CreatedAt,
+		&nextPause,
+		&nextResume,
+	)
+	if err != nil {
+		return nil, err
+	}
+	schedule.NextPauseAt = nextPause.Time
+	schedule.NextResumeAt = nextResume.Time
+
+	return &schedule, nil
+}
+
+// nullTime stores the zero time as NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
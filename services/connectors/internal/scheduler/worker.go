@@ -0,0 +1,294 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+// Clock tells the current time and waits for time to pass
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// CampaignController pauses and resumes campaigns on a platform
+type CampaignController interface {
+	PauseCampaign(ctx context.Context, platformCampaignID string) error
+	ResumeCampaign(ctx context.Context, platformCampaignID string) error
+}
+
+// ControllerFunc returns the controller for a tenant's campaigns on a platform
+type ControllerFunc func(ctx context.Context, tenantID string, platform models.Platform) (CampaignController, error)
+
+// DefaultPollInterval is how often a worker looks for schedules created or
+// changed by other instances
+const DefaultPollInterval = time.Minute
+
+// retryDelay is how long a worker waits before claiming again a due schedule
+// that another instance holds
+const retryDelay = time.Second
+
+// Validate checks that the cron expressions and timezone of a schedule can be parsed
+func Validate(pauseCron, resumeCron, timezone string) error {
+	_, err := newJob(models.CampaignSchedule{
+		PauseCron:  pauseCron,
+		ResumeCron: resumeCron,
+		Timezone:   timezone,
+	}, time.Now())
+	return err
+}
+
+// job is a schedule with its parsed cron expressions and next activations from
+// a given time
+type job struct {
+	nextPause  time.Time
+	nextResume time.Time
+}
+
+func newJob(schedule models.CampaignSchedule, now time.Time) (*job, error) {
+	location, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", schedule.Timezone, err)
+	}
+
+	pause, err := cron.ParseStandard(schedule.PauseCron)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pause cron expression %q: %w", schedule.PauseCron, err)
+	}
+
+	resume, err := cron.ParseStandard(schedule.ResumeCron)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resume cron expression %q: %w", schedule.ResumeCron, err)
+	}
+
+	// Expressions without a CRON_TZ prefix are evaluated in the location of the
+	// time passed to Next
+	local := now.In(location)
+
+	return &job{
+		nextPause:  pause.Next(local),
+		nextResume: resume.Next(local),
+	}, nil
+}
+
+// SchedulerWorker pauses and resumes campaigns at the times of their schedules.
+// The schedules and their next firings are kept in the store, so every instance
+// runs every schedule and each firing is applied by the instance claiming it.
+type SchedulerWorker struct {
+	store        ScheduleStore
+	controllers  ControllerFunc
+	clock        Clock
+	pollInterval time.Duration
+	logger       *logrus.Logger
+
+	changed chan struct{}
+}
+
+// NewSchedulerWorker creates a worker running the schedules of store. controllers
+// provides the platform client used to pause and resume each campaign.
+func NewSchedulerWorker(store ScheduleStore, controllers ControllerFunc, logger *logrus.Logger) *SchedulerWorker {
+	return &SchedulerWorker{
+		store:        store,
+		controllers:  controllers,
+		clock:        systemClock{},
+		pollInterval: DefaultPollInterval,
+		logger:       logger,
+		changed:      make(chan struct{}, 1),
+	}
+}
+
+// SetClock replaces the clock the worker uses to decide when schedules fire
+func (w *SchedulerWorker) SetClock(clock Clock) {
+	w.clock = clock
+}
+
+// SetPollInterval sets how often the worker looks for schedules created or
+// changed by other instances
+func (w *SchedulerWorker) SetPollInterval(interval time.Duration) {
+	w.pollInterval = interval
+}
+
+// ScheduleCampaign saves a schedule of tenantID, replacing the existing schedule
+// of its campaign, to be run from now on
+func (w *SchedulerWorker) ScheduleCampaign(ctx context.Context, tenantID string, schedule *models.CampaignSchedule) error {
+	if tenantID == "" {
+		return fmt.Errorf("tenant id is required")
+	}
+	schedule.TenantID = tenantID
+
+	j, err := newJob(*schedule, w.clock.Now())
+	if err != nil {
+		return err
+	}
+	schedule.NextPauseAt = j.nextPause
+	schedule.NextResumeAt = j.nextResume
+
+	if err := w.store.SaveSchedule(ctx, schedule); err != nil {
+		return err
+	}
+	w.notify()
+
+	w.logger.WithFields(logrus.Fields{
+		"schedule_id":          schedule.ID,
+		"tenant_id":            tenantID,
+		"platform":             schedule.Platform,
+		"platform_campaign_id": schedule.PlatformCampaignID,
+		"next_pause":           j.nextPause,
+		"next_resume":          j.nextResume,
+	}).Info("Campaign schedule saved")
+
+	return nil
+}
+
+// CampaignSchedule returns the schedule of a platform campaign
+func (w *SchedulerWorker) CampaignSchedule(ctx context.Context, platform models.Platform, platformCampaignID string) (*models.CampaignSchedule, error) {
+	return w.store.GetSchedule(ctx, platform, platformCampaignID)
+}
+
+// DeleteCampaignSchedule deletes a schedule of tenantID and stops running it
+func (w *SchedulerWorker) DeleteCampaignSchedule(ctx context.Context, id uuid.UUID, tenantID string) error {
+	if err := w.store.DeleteSchedule(ctx, id, tenantID); err != nil {
+		return err
+	}
+	w.notify()
+
+	w.logger.WithField("schedule_id", id).Info("Campaign schedule deleted")
+
+	return nil
+}
+
+// Run triggers the pauses and resumes of the stored schedules as they come due
+// until ctx is cancelled
+func (w *SchedulerWorker) Run(ctx context.Context) error {
+	w.logger.Info("Campaign scheduler started")
+
+	for {
+		now := w.clock.Now()
+		if err := w.runDue(ctx, now); err != nil {
+			w.logger.WithError(err).Error("Failed to claim due campaign schedules")
+		}
+
+		wait := w.pollInterval
+		next, ok, err := w.store.NextFiring(ctx)
+		if err != nil {
+			w.logger.WithError(err).Error("Failed to query next campaign schedule firing")
+		} else if ok {
+			// A firing still due is held by another instance
+			if !next.After(now) {
+				next = now.Add(retryDelay)
+			}
+			if until := next.Sub(w.clock.Now()); until < wait {
+				wait = until
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-w.changed:
+			// Schedules were added or removed, recompute the next activation
+		case <-w.clock.After(wait):
+		}
+	}
+}
+
+// notify wakes up Run after the schedules change
+func (w *SchedulerWorker) notify() {
+	select {
+	case w.changed <- struct{}{}:
+	default:
+	}
+}
+
+// firing is a pause or resume claimed by runDue
+type firing struct {
+	schedule models.CampaignSchedule
+	pause    bool
+}
+
+// runDue claims every pause and resume due at now, advances their schedules and
+// triggers them. When both a pause and a resume of a schedule are due, only the
+// later one is triggered, as it sets the state the campaign should be in.
+func (w *SchedulerWorker) runDue(ctx context.Context, now time.Time) error {
+	var firings []firing
+	err := w.store.ClaimDueSchedules(ctx, now, func(schedule *models.CampaignSchedule) {
+		j, err := newJob(*schedule, now)
+		if err != nil {
+			w.logger.WithError(err).WithField("schedule_id", schedule.ID).Error("Skipping invalid campaign schedule")
+			// Park the schedule until it is replaced
+			schedule.NextPauseAt = time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+			schedule.NextResumeAt = schedule.NextPauseAt
+			return
+		}
+
+		pauseDue := !schedule.NextPauseAt.IsZero() && !schedule.NextPauseAt.After(now)
+		resumeDue := !schedule.NextResumeAt.IsZero() && !schedule.NextResumeAt.After(now)
+		switch {
+		case pauseDue && resumeDue:
+			firings = append(firings, firing{schedule: *schedule, pause: schedule.NextPauseAt.After(schedule.NextResumeAt)})
+		case pauseDue || resumeDue:
+			firings = append(firings, firing{schedule: *schedule, pause: pauseDue})
+		}
+
+		if pauseDue || schedule.NextPauseAt.IsZero() {
+			schedule.NextPauseAt = j.nextPause
+		}
+		if resumeDue || schedule.NextResumeAt.IsZero() {
+			schedule.NextResumeAt = j.nextResume
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, f := range firings {
+		go w.trigger(ctx, f.schedule, f.pause)
+	}
+	return nil
+}
+
+// trigger pauses or resumes the campaign of a schedule
+func (w *SchedulerWorker) trigger(ctx context.Context, schedule models.CampaignSchedule, pause bool) {
+	action := "resume"
+	if pause {
+		action = "pause"
+	}
+
+	logger := w.logger.WithFields(logrus.Fields{
+		"schedule_id":          schedule.ID,
+		"tenant_id":            schedule.TenantID,
+		"platform":             schedule.Platform,
+		"platform_campaign_id": schedule.PlatformCampaignID,
+		"action":               action,
+	})
+
+	controller, err := w.controllers(ctx, schedule.TenantID, schedule.Platform)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get campaign controller")
+		return
+	}
+
+	if pause {
+		err = controller.PauseCampaign(ctx, schedule.PlatformCampaignID)
+	} else {
+		err = controller.ResumeCampaign(ctx, schedule.PlatformCampaignID)
+	}
+	if err != nil {
+		logger.WithError(err).Error("Scheduled campaign change failed")
+		return
+	}
+
+	logger.Info("Scheduled campaign change applied")
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+)
+
+// BudgetExceededError is returned for deployments whose daily budget is above the
+// cap of their platform
+type BudgetExceededError struct {
+	Platform models.Platform
+	Budget   float64
+	Cap      float64
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("daily budget %.2f exceeds the %s cap of %.2f", e.Budget, e.Platform, e.Cap)
+}
+
+// BudgetValidator caps the daily budget of deployments per platform, so that a
+// misconfigured asset cannot spend a whole ad account
+type BudgetValidator struct {
+	caps map[models.Platform]float64
+}
+
+// NewBudgetValidator creates a budget validator with the caps of cfg. A cap of
+// zero leaves the platform uncapped.
+func NewBudgetValidator(cfg *config.DeploymentConfig) *BudgetValidator {
+	return &BudgetValidator{
+		caps: map[models.Platform]float64{
+			models.PlatformGoogleAds: cfg.MaxDailyBudgetGoogleAds,
+			models.PlatformMeta:      cfg.MaxDailyBudgetMeta,
+		},
+	}
+}
+
+// Validate returns a BudgetExceededError if budget is above the daily budget cap
+// of platform. Platforms without a cap accept any budget.
+func (v *BudgetValidator) Validate(budget float64, platform models.Platform) error {
+	limit := v.caps[platform]
+	if limit > 0 && budget > limit {
+		return &BudgetExceededError{Platform: platform, Budget: budget, Cap: limit}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/zamc/connectors/internal/cache"
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/googleads"
+	"github.com/zamc/connectors/internal/ratelimit"
+	"github.com/zamc/connectors/internal/scheduler"
+)
+
+// PlatformClient deploys assets to an advertising platform
+type PlatformClient interface {
+	DeployAsset(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error)
+	HealthCheck(ctx context.Context) error
+}
+
+// GoogleAdsClient is the Google Ads API client used by the service, implemented
+// by *googleads.Client
+type GoogleAdsClient interface {
+	PlatformClient
+	scheduler.CampaignController
+	adReviewer
+
+	Config() *config.GoogleAdsConfig
+	SetScheduler(worker *scheduler.SchedulerWorker)
+	SetRateLimiter(limiter *ratelimit.PlatformRateLimiter)
+	GetBillingStatus(ctx context.Context) (*models.BillingStatus, error)
+	EstimateCampaignCost(ctx context.Context, request *models.DeploymentRequest) (*models.CostEstimate, error)
+	FetchCampaignMetrics(ctx context.Context, campaignID string, dateRange googleads.DateRange) (*models.CampaignMetrics, error)
+	FetchKeywordQualityScores(ctx context.Context, adGroupID string) ([]models.KeywordQualityScore, error)
+	PauseAd(ctx context.Context, campaignID, adGroupID, adID string) error
+	ScheduleCampaignPauseResume(ctx context.Context, tenantID, platformCampaignID, pauseCron, resumeCron string, timezone string) error
+}
+
+// MetaClient is the Meta Marketing API client used by the service, implemented by
+// *meta.Client
+type MetaClient interface {
+	PlatformClient
+	scheduler.CampaignController
+	adReviewer
+
+	Config() *config.MetaConfig
+	SetScheduler(worker *scheduler.SchedulerWorker)
+	SetRateLimiter(limiter *ratelimit.PlatformRateLimiter)
+	SetResponseCache(responseCache *cache.PlatformResponseCache)
+	EstimateAdCost(ctx context.Context, request *models.DeploymentRequest) (*models.CostEstimate, error)
+	FetchCampaignMetrics(ctx context.Context, adID string, datePreset string) (*models.CampaignMetrics, error)
+	PauseAd(ctx context.Context, adID string) error
+	RevokeCustomAudience(ctx context.Context, audienceID string) error
+	DuplicateCampaign(ctx context.Context, sourceCampaignID string, newName string, newBudget float64) (string, error)
+	ScheduleCampaignPauseResume(ctx context.Context, tenantID, platformCampaignID, pauseCron, resumeCron string, timezone string) error
+}
+
+// EventPublisher publishes the events of the service, implemented by *nats.Client
+type EventPublisher interface {
+	PublishDeploymentStatusChanged(ctx context.Context, event *models.DeploymentStatusChangedEvent) error
+	PublishAssetStatusChanged(ctx context.Context, event *models.AssetStatusChangedEvent) error
+	PublishAssetPlatformRejected(ctx context.Context, event *models.AssetPlatformRejectedEvent) error
+	PublishCampaignBudgetExceeded(ctx context.Context, event *models.CampaignBudgetExceededEvent) error
+	PublishAssetRolledBack(ctx context.Context, event *models.AssetRolledBackEvent) error
+	PublishScheduledDeployment(ctx context.Context, event *models.AssetStatusChangedEvent) error
+	PublishDeadLetter(ctx context.Context, event *models.AssetStatusChangedEvent, failureReason string, retryCount int) error
+	PublishKeywordQualityScoreFetch(ctx context.Context, fetch *models.KeywordQualityScoreFetch, delay time.Duration) error
+	PublishAdReviewCheck(ctx context.Context, check *models.AdReviewCheck, delay time.Duration) error
+	HealthCheck() error
+}
@@ -2,41 +2,262 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/zamc/connectors/internal/campaigns"
+	"github.com/zamc/connectors/internal/backoff"
+	"github.com/zamc/connectors/internal/cache"
+	"github.com/zamc/connectors/internal/circuitbreaker"
 	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/credentials"
+	"github.com/zamc/connectors/internal/metrics"
 	"github.com/zamc/connectors/internal/models"
-	"github.com/zamc/connectors/internal/nats"
+	"github.com/zamc/connectors/internal/notifications"
 	"github.com/zamc/connectors/internal/platforms/googleads"
 	"github.com/zamc/connectors/internal/platforms/meta"
+	"github.com/zamc/connectors/internal/qualityscores"
+	"github.com/zamc/connectors/internal/queue"
+	"github.com/zamc/connectors/internal/ratelimit"
+	"github.com/zamc/connectors/internal/scheduler"
+	"github.com/zamc/connectors/internal/stats"
+	"github.com/zamc/connectors/internal/tracing"
 )
 
+// ErrInsufficientCredit is returned for Google Ads deployments to accounts whose
+// billing is not approved or whose remaining credit is below the asset's budget
+var ErrInsufficientCredit = errors.New("insufficient Google Ads credit")
+
+// ErrRetriesExhausted is returned for deployments that failed on every attempt
+var ErrRetriesExhausted = errors.New("deployment failed")
+
 // DeploymentService handles asset deployment to advertising platforms
 type DeploymentService struct {
-	googleAdsClient *googleads.Client
-	metaClient      *meta.Client
-	natsClient      *nats.Client
+	googleAdsClient GoogleAdsClient
+	metaClient      MetaClient
+	tiktokClient    PlatformClient
+	linkedinClient  PlatformClient
+	twitterClient   PlatformClient
+	natsClient      EventPublisher
+	credentialStore *credentials.CredentialStore
+	statsCollector  *stats.StatsCollector
+	scheduler       *scheduler.SchedulerWorker
+	qualityScores   *qualityscores.Store
+	campaigns       *campaigns.Store
+	rateLimiter     *ratelimit.PlatformRateLimiter
+	responseCache   *cache.PlatformResponseCache
+	budgets         *BudgetValidator
+	creatives       *CreativeValidator
+	notifications   *notifications.NotificationService
+	breakersMu      sync.Mutex
+	breakers        map[breakerKey]*circuitbreaker.Breaker
+	billingMu       sync.Mutex
+	billing         *cachedBillingStatus
+	retryBackoff    backoff.Backoff
+	queue           *queue.DeploymentQueue
 	config          *config.DeploymentConfig
 	logger          *logrus.Logger
 }
 
-// NewDeploymentService creates a new deployment service
+// NewDeploymentService creates a new deployment service. The platform clients are
+// used for tenants without stored credentials; credentialStore may be nil to use
+// them for every tenant. statsCollector may be nil to disable deployment statistics.
 func NewDeploymentService(
-	googleAdsClient *googleads.Client,
-	metaClient *meta.Client,
-	natsClient *nats.Client,
+	googleAdsClient GoogleAdsClient,
+	metaClient MetaClient,
+	natsClient EventPublisher,
+	credentialStore *credentials.CredentialStore,
+	statsCollector *stats.StatsCollector,
 	cfg *config.DeploymentConfig,
 	logger *logrus.Logger,
 ) *DeploymentService {
-	return &DeploymentService{
+	s := &DeploymentService{
 		googleAdsClient: googleAdsClient,
 		metaClient:      metaClient,
 		natsClient:      natsClient,
+		credentialStore: credentialStore,
+		statsCollector:  statsCollector,
+		budgets:         NewBudgetValidator(cfg),
+		creatives:       NewCreativeValidator(),
+		breakers:        make(map[breakerKey]*circuitbreaker.Breaker),
+		retryBackoff:    backoff.NewExponential(cfg.RetryDelay, cfg.MaxRetryDelay, cfg.RetryJitter),
 		config:          cfg,
 		logger:          logger,
 	}
+	s.queue = queue.New(deploymentPlatforms, cfg.WorkerCount, cfg.QueueSize, s.deployToplatform)
+	return s
+}
+
+// deploymentPlatforms lists the platforms assets are deployed to
+var deploymentPlatforms = []models.Platform{
+	models.PlatformGoogleAds,
+	models.PlatformMeta,
+	models.PlatformTikTok,
+	models.PlatformLinkedIn,
+	models.PlatformTwitter,
+}
+
+// Stop stops the deployment workers once their current deployments are done
+func (s *DeploymentService) Stop() {
+	s.queue.Stop()
+}
+
+// breakerKey identifies a circuit: the deployments to a platform with the
+// credentials of a tenant, so that a tenant whose account fails does not stop
+// the deployments of the others
+type breakerKey struct {
+	platform models.Platform
+	tenantID string
+}
+
+// breakerFor returns the circuit breaker of the deployments of tenantID to
+// platform, creating it closed on first use
+func (s *DeploymentService) breakerFor(platform models.Platform, tenantID string) *circuitbreaker.Breaker {
+	s.breakersMu.Lock()
+	defer s.breakersMu.Unlock()
+
+	key := breakerKey{platform: platform, tenantID: tenantID}
+	if breaker, ok := s.breakers[key]; ok {
+		return breaker
+	}
+
+	metrics.CircuitBreakers.WithLabelValues(string(platform), circuitbreaker.StateClosed.String()).Inc()
+	breaker := circuitbreaker.New(circuitbreaker.Config{
+		FailureThreshold: s.config.CircuitFailureThreshold,
+		WindowDuration:   s.config.CircuitWindow,
+		RecoveryTimeout:  s.config.CircuitRecoveryTimeout,
+		IsFailure:        isTransientFailure,
+		OnStateChange: func(from, to circuitbreaker.State) {
+			s.logger.WithFields(logrus.Fields{
+				"platform":  platform,
+				"tenant_id": tenantID,
+				"from":      from.String(),
+				"to":        to.String(),
+			}).Warn("Platform circuit breaker changed state")
+			metrics.CircuitBreakers.WithLabelValues(string(platform), from.String()).Dec()
+			metrics.CircuitBreakers.WithLabelValues(string(platform), to.String()).Inc()
+		},
+	})
+	s.breakers[key] = breaker
+	return breaker
+}
+
+// isTransientFailure tells whether a deployment error means the platform is
+// failing: server errors, throttling and timeouts. Errors about the request,
+// such as invalid creatives or credentials, and quota errors, as the platform is
+// up, do not open circuits.
+func isTransientFailure(err error) bool {
+	if errors.Is(err, ratelimit.ErrQuotaExhausted) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var apiErr *models.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Transient()
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// CircuitStates returns the state of the circuit breakers of each platform:
+// closed, open or half-open. A platform is reported open while the circuit of
+// any tenant is, and half-open while one is.
+func (s *DeploymentService) CircuitStates() map[models.Platform]string {
+	s.breakersMu.Lock()
+	defer s.breakersMu.Unlock()
+
+	states := make(map[models.Platform]string, len(deploymentPlatforms))
+	for _, platform := range deploymentPlatforms {
+		states[platform] = circuitbreaker.StateClosed.String()
+	}
+	for key, breaker := range s.breakers {
+		state := breaker.State()
+		if state == circuitbreaker.StateOpen || (state == circuitbreaker.StateHalfOpen && states[key.platform] != circuitbreaker.StateOpen.String()) {
+			states[key.platform] = state.String()
+		}
+	}
+	return states
+}
+
+// SetScheduler enables campaign schedules, run by worker, for the service and its
+// platform clients
+func (s *DeploymentService) SetScheduler(worker *scheduler.SchedulerWorker) {
+	s.scheduler = worker
+	s.googleAdsClient.SetScheduler(worker)
+	s.metaClient.SetScheduler(worker)
+}
+
+// SetTikTokClient enables deployments to TikTok with client
+func (s *DeploymentService) SetTikTokClient(client PlatformClient) {
+	s.tiktokClient = client
+}
+
+// SetLinkedInClient enables deployments to LinkedIn with client
+func (s *DeploymentService) SetLinkedInClient(client PlatformClient) {
+	s.linkedinClient = client
+}
+
+// SetTwitterClient enables deployments to Twitter with client
+func (s *DeploymentService) SetTwitterClient(client PlatformClient) {
+	s.twitterClient = client
+}
+
+// SetRetryBackoff replaces the backoff between deployment attempts
+func (s *DeploymentService) SetRetryBackoff(retryBackoff backoff.Backoff) {
+	s.retryBackoff = retryBackoff
+}
+
+// SetQualityScoreStore enables keyword quality score fetches after Google Ads
+// deployments, saving the scores to store
+func (s *DeploymentService) SetQualityScoreStore(store *qualityscores.Store) {
+	s.qualityScores = store
+}
+
+// SetCampaignStore enables campaign metrics polling and deployment rollbacks,
+// recording the Google Ads and Meta campaigns of successful deployments in store
+func (s *DeploymentService) SetCampaignStore(store *campaigns.Store) {
+	s.campaigns = store
+}
+
+// SetNotificationService sends the result of every deployment of an asset to a
+// platform, successful or failed, through notifier
+func (s *DeploymentService) SetNotificationService(notifier *notifications.NotificationService) {
+	s.notifications = notifier
+}
+
+// SetRateLimiter makes the Google Ads and Meta clients, including those of
+// tenants, back off from accounts running out of API quota as tracked by limiter
+func (s *DeploymentService) SetRateLimiter(limiter *ratelimit.PlatformRateLimiter) {
+	s.rateLimiter = limiter
+	s.googleAdsClient.SetRateLimiter(limiter)
+	s.metaClient.SetRateLimiter(limiter)
+}
+
+// SetResponseCache makes the Meta clients, including those of tenants, cache
+// their API responses in responseCache
+func (s *DeploymentService) SetResponseCache(responseCache *cache.PlatformResponseCache) {
+	s.responseCache = responseCache
+	s.metaClient.SetResponseCache(responseCache)
+}
+
+// PlatformQuotas returns the API quota last reported for each Google Ads and Meta
+// account, empty when quotas are not tracked
+func (s *DeploymentService) PlatformQuotas() []ratelimit.QuotaStatus {
+	if s.rateLimiter == nil {
+		return []ratelimit.QuotaStatus{}
+	}
+	return s.rateLimiter.Quotas()
 }
 
 // HandleAssetStatusChanged handles asset status changed events
@@ -57,51 +278,64 @@ func (s *DeploymentService) HandleAssetStatusChanged(ctx context.Context, event
 		return nil
 	}
 
-	// Create deployment request
-	deploymentRequest := &models.DeploymentRequest{
-		AssetID:     event.AssetID,
-		ProjectID:   event.ProjectID,
-		StrategyID:  event.StrategyID,
-		ContentType: event.ContentType,
-		Title:       event.Title,
-		Content:     event.Content,
-		Metadata:    event.Metadata,
-		CreatedAt:   time.Now(),
+	// Dry runs only report what the deployment would create, without publishing
+	if event.Mode == models.DeploymentModeDryRun {
+		report, err := s.DryRunDeployment(ctx, event)
+		if err != nil {
+			return err
+		}
+		logger.WithField("valid", report.Valid).Info("Asset deployment dry run completed")
+		return nil
 	}
 
-	// Deploy to all specified platforms
-	var deploymentResults []models.DeploymentResult
-	var hasErrors bool
+	// Assets scheduled to go live later come back here once their time has come
+	if event.ScheduledAt != nil && event.ScheduledAt.After(time.Now()) {
+		if err := s.natsClient.PublishScheduledDeployment(ctx, event); err != nil {
+			return err
+		}
+		logger.WithField("scheduled_at", event.ScheduledAt).Info("Scheduled asset deployment")
+		return nil
+	}
 
-	for _, platform := range event.Metadata.Platforms {
-		deploymentRequest.Platform = platform
-		
-		result, err := s.deployToplatform(ctx, deploymentRequest)
-		if err != nil {
-			logger.WithError(err).WithField("platform", platform).Error("Deployment failed")
-			hasErrors = true
-			
-			// Create failed result
-			result = &models.DeploymentResult{
-				AssetID:    event.AssetID,
-				Platform:   platform,
-				Status:     models.DeploymentStatusFailed,
-				Error:      err.Error(),
-				DeployedAt: time.Now(),
-				Metrics: models.DeploymentMetrics{
-					Duration: 0,
-				},
+	s.deployAsset(ctx, event, logger)
+
+	return nil
+}
+
+// deployAsset deploys an approved asset to every platform in its metadata, publishes
+// the resulting status events and returns one result per platform
+func (s *DeploymentService) deployAsset(ctx context.Context, event *models.AssetStatusChangedEvent, logger *logrus.Entry) []models.DeploymentResult {
+	deploymentRequest := deploymentRequestFor(event)
+
+	// Deploy to all specified platforms at once, each on the workers of its
+	// platform, so that a slow platform does not hold up the others
+	priority := queue.PriorityOf(event.Metadata)
+	outcomes := make([]chan platformOutcome, len(event.Metadata.Platforms))
+	for i, platform := range event.Metadata.Platforms {
+		request := *deploymentRequest
+		request.Platform = platform
+		outcome := make(chan platformOutcome, 1)
+		outcomes[i] = outcome
+
+		done := func(result *models.DeploymentResult, err error) {
+			outcome <- platformOutcome{
+				result: s.completePlatformDeployment(ctx, event, &request, result, err, logger),
+				failed: err != nil,
 			}
 		}
-		
-		deploymentResults = append(deploymentResults, *result)
-		
-		// Publish deployment status event for each platform
-		if err := s.publishDeploymentStatusEvent(ctx, event, *result); err != nil {
-			logger.WithError(err).Error("Failed to publish deployment status event")
+		if err := s.queue.Enqueue(ctx, &request, priority, done); err != nil {
+			done(nil, err)
 		}
 	}
 
+	var deploymentResults []models.DeploymentResult
+	var hasErrors bool
+	for _, outcome := range outcomes {
+		o := <-outcome
+		deploymentResults = append(deploymentResults, *o.result)
+		hasErrors = hasErrors || o.failed
+	}
+
 	// Update overall asset status
 	var finalStatus models.AssetStatus
 	if hasErrors {
@@ -112,17 +346,18 @@ func (s *DeploymentService) HandleAssetStatusChanged(ctx context.Context, event
 
 	// Publish final asset status update
 	finalEvent := &models.AssetStatusChangedEvent{
-		EventType:   "asset.status_changed",
-		AssetID:     event.AssetID,
-		ProjectID:   event.ProjectID,
-		StrategyID:  event.StrategyID,
-		Status:      finalStatus,
-		PrevStatus:  event.Status,
-		ContentType: event.ContentType,
-		Title:       event.Title,
-		Content:     event.Content,
-		Metadata:    event.Metadata,
-		Timestamp:   time.Now(),
+		EventType:    "asset.status_changed",
+		AssetID:      event.AssetID,
+		ProjectID:    event.ProjectID,
+		StrategyID:   event.StrategyID,
+		Status:       finalStatus,
+		PrevStatus:   event.Status,
+		ContentType:  event.ContentType,
+		Title:        event.Title,
+		Content:      event.Content,
+		Metadata:     event.Metadata,
+		Timestamp:    time.Now(),
+		VariantGroup: event.VariantGroup,
 	}
 
 	if err := s.natsClient.PublishAssetStatusChanged(ctx, finalEvent); err != nil {
@@ -135,16 +370,462 @@ func (s *DeploymentService) HandleAssetStatusChanged(ctx context.Context, event
 		"successful_deploys": len(deploymentResults) - countFailedDeployments(deploymentResults),
 	}).Info("Asset deployment processing completed")
 
+	return deploymentResults
+}
+
+// deploymentRequestFor returns the live deployment request of an approved
+// asset, without its platform
+func deploymentRequestFor(event *models.AssetStatusChangedEvent) *models.DeploymentRequest {
+	return &models.DeploymentRequest{
+		AssetID:      event.AssetID,
+		ProjectID:    event.ProjectID,
+		TenantID:     event.TenantID,
+		StrategyID:   event.StrategyID,
+		ContentType:  event.ContentType,
+		Title:        event.Title,
+		Content:      event.Content,
+		Metadata:     event.Metadata,
+		Mode:         models.DeploymentModeLive,
+		CreatedAt:    time.Now(),
+		ScheduledAt:  event.ScheduledAt,
+		VariantGroup: event.VariantGroup,
+	}
+}
+
+// platformOutcome is the result of the deployment of an asset to a platform
+type platformOutcome struct {
+	result *models.DeploymentResult
+	failed bool
+}
+
+// completePlatformDeployment records and publishes the outcome of the deployment
+// of request, run by a worker of its platform, and returns its result, a failed
+// one when err is not nil
+func (s *DeploymentService) completePlatformDeployment(ctx context.Context, event *models.AssetStatusChangedEvent, request *models.DeploymentRequest, result *models.DeploymentResult, err error, logger *logrus.Entry) *models.DeploymentResult {
+	platform := request.Platform
+	if err != nil {
+		logger.WithError(err).WithField("platform", platform).Error("Deployment failed")
+
+		if errors.Is(err, ErrRetriesExhausted) || errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+			s.publishDeadLetter(ctx, event, platform, err, logger)
+		}
+
+		// Create failed result
+		result = &models.DeploymentResult{
+			AssetID:    event.AssetID,
+			Platform:   platform,
+			Status:     models.DeploymentStatusFailed,
+			Error:      err.Error(),
+			DeployedAt: time.Now(),
+			Metrics: models.DeploymentMetrics{
+				Duration: 0,
+			},
+		}
+
+		// Tell the BFF which fields of the creative to fix
+		var validationErr *CreativeValidationError
+		if errors.As(err, &validationErr) {
+			result.ValidationErrors = validationErr.Errors
+		}
+	}
+
+	if result.Status == models.DeploymentStatusSuccess {
+		s.recordCampaignDeployment(ctx, request, result, logger)
+		s.scheduleQualityScoreFetch(ctx, request, result, logger)
+		s.scheduleAdReviewCheck(ctx, &models.AdReviewCheck{
+			AssetID:      request.AssetID,
+			ProjectID:    request.ProjectID,
+			TenantID:     request.TenantID,
+			Platform:     result.Platform,
+			PlatformAdID: result.PlatformID,
+		}, logger)
+	}
+
+	if s.statsCollector != nil {
+		if err := s.statsCollector.Record(ctx, *result); err != nil {
+			logger.WithError(err).Error("Failed to record deployment stats")
+		}
+	}
+
+	// Publish deployment status event for each platform
+	if err := s.publishDeploymentStatusEvent(ctx, event, *result); err != nil {
+		logger.WithError(err).Error("Failed to publish deployment status event")
+	}
+
+	s.notifyDeploymentResult(event, result, logger)
+
+	return result
+}
+
+// notifyDeploymentResult queues the terminal result of a deployment on the
+// notification service, if one is set
+func (s *DeploymentService) notifyDeploymentResult(event *models.AssetStatusChangedEvent, result *models.DeploymentResult, logger *logrus.Entry) {
+	if s.notifications == nil {
+		return
+	}
+	if result.Status != models.DeploymentStatusSuccess && result.Status != models.DeploymentStatusFailed {
+		return
+	}
+
+	err := s.notifications.Notify(notifications.NotificationEvent{
+		AssetID:     result.AssetID,
+		ProjectID:   event.ProjectID,
+		Title:       event.Title,
+		Platform:    result.Platform,
+		Status:      result.Status,
+		PlatformURL: result.PlatformURL,
+		Error:       result.Error,
+		Timestamp:   result.DeployedAt,
+	})
+	if err != nil {
+		logger.WithError(err).WithField("platform", result.Platform).Warn("Failed to queue deployment notification")
+	}
+}
+
+// publishDeadLetter hands the event of an asset whose deployment to platform failed
+// on every attempt to the dead letter queue. The event only keeps the failed
+// platform, so that replaying it does not deploy the asset again where it succeeded.
+func (s *DeploymentService) publishDeadLetter(ctx context.Context, event *models.AssetStatusChangedEvent, platform models.Platform, deployErr error, logger *logrus.Entry) {
+	failed := *event
+	failed.Metadata.Platforms = []models.Platform{platform}
+
+	if err := s.natsClient.PublishDeadLetter(ctx, &failed, deployErr.Error(), s.config.MaxRetryAttempts); err != nil {
+		logger.WithError(err).WithField("platform", platform).Error("Failed to publish dead letter")
+	}
+}
+
+// scheduleQualityScoreFetch schedules the fetch of the keyword quality scores of a
+// successful Google Ads deployment. Google Ads only scores keywords after they have
+// served, so the fetch is delayed by the configured quality score delay.
+func (s *DeploymentService) scheduleQualityScoreFetch(ctx context.Context, request *models.DeploymentRequest, result *models.DeploymentResult, logger *logrus.Entry) {
+	if s.qualityScores == nil || result.Platform != models.PlatformGoogleAds || result.AdGroupID == "" {
+		return
+	}
+
+	err := s.natsClient.PublishKeywordQualityScoreFetch(ctx, &models.KeywordQualityScoreFetch{
+		AssetID:   request.AssetID,
+		TenantID:  request.TenantID,
+		AdGroupID: result.AdGroupID,
+	}, s.config.QualityScoreDelay)
+	if err != nil {
+		logger.WithError(err).Error("Failed to schedule keyword quality score fetch")
+	}
+}
+
+// metaMetricsDatePreset covers the whole life of a Meta ad, like the range since
+// deployment read from Google Ads
+const metaMetricsDatePreset = "maximum"
+
+// recordCampaignDeployment records the campaign of a successful Google Ads or
+// Meta deployment so that its metrics are polled. Meta metrics are read per ad.
+func (s *DeploymentService) recordCampaignDeployment(ctx context.Context, request *models.DeploymentRequest, result *models.DeploymentResult, logger *logrus.Entry) {
+	if s.campaigns == nil {
+		return
+	}
+
+	var campaignID string
+	switch result.Platform {
+	case models.PlatformGoogleAds:
+		campaignID = result.CampaignID
+	case models.PlatformMeta:
+		campaignID = result.PlatformID
+	}
+	if campaignID == "" {
+		return
+	}
+
+	err := s.campaigns.RecordDeployment(ctx, &models.CampaignDeployment{
+		Platform:           result.Platform,
+		PlatformCampaignID: campaignID,
+		AdGroupID:          result.AdGroupID,
+		PlatformAdID:       result.PlatformID,
+		AudienceID:         result.AudienceID,
+		AssetID:            request.AssetID,
+		ProjectID:          request.ProjectID,
+		TenantID:           request.TenantID,
+		DeployedAt:         result.DeployedAt,
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to record campaign deployment")
+	}
+}
+
+// FetchCampaignMetrics fetches the metrics of a deployed campaign since it was
+// deployed
+func (s *DeploymentService) FetchCampaignMetrics(ctx context.Context, deployment *models.CampaignDeployment) (*models.CampaignMetrics, error) {
+	switch deployment.Platform {
+	case models.PlatformGoogleAds:
+		client, err := s.googleAdsClientFor(ctx, deployment.TenantID)
+		if err != nil {
+			return nil, err
+		}
+
+		return client.FetchCampaignMetrics(ctx, deployment.PlatformCampaignID, googleads.DateRange{
+			Start: deployment.DeployedAt,
+			End:   time.Now(),
+		})
+	case models.PlatformMeta:
+		client, err := s.metaClientFor(ctx, deployment.TenantID)
+		if err != nil {
+			return nil, err
+		}
+
+		return client.FetchCampaignMetrics(ctx, deployment.PlatformCampaignID, metaMetricsDatePreset)
+	default:
+		return nil, fmt.Errorf("campaign metrics are not supported for platform %s", deployment.Platform)
+	}
+}
+
+// RollbackDeployment pauses the ad of an asset's latest deployment to a platform
+// and stops polling its metrics. The ad runs on the credentials of the tenant
+// that deployed it.
+func (s *DeploymentService) RollbackDeployment(ctx context.Context, request *models.DeploymentRollbackRequest) (*models.DeploymentRollbackResult, error) {
+	if s.campaigns == nil {
+		return nil, fmt.Errorf("deployment rollbacks are not configured")
+	}
+
+	deployment, err := s.campaigns.AssetDeployment(ctx, request.AssetID, request.Platform)
+	if err != nil {
+		return nil, err
+	}
+
+	switch deployment.Platform {
+	case models.PlatformGoogleAds:
+		client, err := s.googleAdsClientFor(ctx, deployment.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		err = client.PauseAd(ctx, deployment.PlatformCampaignID, deployment.AdGroupID, deployment.PlatformAdID)
+		if err != nil {
+			return nil, err
+		}
+	case models.PlatformMeta:
+		client, err := s.metaClientFor(ctx, deployment.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		if err := client.PauseAd(ctx, deployment.PlatformAdID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("deployment rollbacks are not supported for platform %s", deployment.Platform)
+	}
+
+	logger := s.logger.WithFields(logrus.Fields{
+		"asset_id":     request.AssetID,
+		"platform":     request.Platform,
+		"requested_by": request.RequestedBy,
+	})
+
+	// The ad is paused, so failures from here on are only logged
+	if err := s.campaigns.Deactivate(ctx, deployment.Platform, deployment.PlatformCampaignID); err != nil {
+		logger.WithError(err).Error("Failed to deactivate rolled back campaign deployment")
+	}
+
+	rolledBackAt := time.Now()
+	err = s.natsClient.PublishAssetRolledBack(ctx, &models.AssetRolledBackEvent{
+		EventType:          "asset.rolled_back",
+		AssetID:            deployment.AssetID,
+		ProjectID:          deployment.ProjectID,
+		Platform:           deployment.Platform,
+		PlatformCampaignID: deployment.PlatformCampaignID,
+		PlatformAdID:       deployment.PlatformAdID,
+		RolledBackBy:       request.RequestedBy,
+		Reason:             request.Reason,
+		Timestamp:          rolledBackAt,
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to publish asset rolled back event")
+	}
+
+	logger.Info("Rolled back asset deployment")
+
+	return &models.DeploymentRollbackResult{
+		AssetID:            deployment.AssetID,
+		Platform:           deployment.Platform,
+		PlatformCampaignID: deployment.PlatformCampaignID,
+		PlatformAdID:       deployment.PlatformAdID,
+		RolledBackAt:       rolledBackAt,
+	}, nil
+}
+
+// DeleteCustomAudience deletes the Meta custom audiences the deployments of an
+// asset target, on the credentials of the tenant that deployed each. It reports
+// whether the asset had any to delete.
+func (s *DeploymentService) DeleteCustomAudience(ctx context.Context, request *models.CustomAudienceDeletionRequest) (bool, error) {
+	if s.campaigns == nil {
+		return false, fmt.Errorf("custom audience deletions are not configured")
+	}
+
+	deployments, err := s.campaigns.AudienceDeployments(ctx, request.AssetID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, deployment := range deployments {
+		client, err := s.metaClientFor(ctx, deployment.TenantID)
+		if err != nil {
+			return false, err
+		}
+		if err := client.RevokeCustomAudience(ctx, deployment.AudienceID); err != nil {
+			return false, err
+		}
+		if err := s.campaigns.ClearAudience(ctx, deployment.Platform, deployment.PlatformCampaignID); err != nil {
+			return false, err
+		}
+
+		s.logger.WithFields(logrus.Fields{
+			"asset_id":     request.AssetID,
+			"audience_id":  deployment.AudienceID,
+			"requested_by": request.RequestedBy,
+		}).Info("Deleted custom audience of asset deployment")
+	}
+
+	return len(deployments) > 0, nil
+}
+
+// FetchKeywordQualityScores fetches the keyword quality scores of a deployed
+// asset's ad group and saves them
+func (s *DeploymentService) FetchKeywordQualityScores(ctx context.Context, fetch *models.KeywordQualityScoreFetch) error {
+	if s.qualityScores == nil {
+		return fmt.Errorf("keyword quality scores are not configured")
+	}
+
+	client, err := s.googleAdsClientFor(ctx, fetch.TenantID)
+	if err != nil {
+		return err
+	}
+
+	scores, err := client.FetchKeywordQualityScores(ctx, fetch.AdGroupID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.qualityScores.SaveScores(ctx, fetch.AssetID, scores); err != nil {
+		return err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"asset_id":    fetch.AssetID,
+		"ad_group_id": fetch.AdGroupID,
+		"keywords":    len(scores),
+	}).Info("Saved keyword quality scores")
+
 	return nil
 }
 
+// adReviewer reports the policy review outcome of ads
+type adReviewer interface {
+	GetAdReviewStatus(ctx context.Context, platformAdID string) (*models.AdReviewStatus, error)
+}
+
+// scheduleAdReviewCheck schedules a check of the review status of a deployed ad
+func (s *DeploymentService) scheduleAdReviewCheck(ctx context.Context, check *models.AdReviewCheck, logger *logrus.Entry) {
+	if check.PlatformAdID == "" {
+		return
+	}
+
+	if err := s.natsClient.PublishAdReviewCheck(ctx, check, s.config.AdReviewDelay); err != nil {
+		logger.WithError(err).Error("Failed to schedule ad review check")
+	}
+}
+
+// CheckAdReview checks the review status of a deployed ad and publishes an asset
+// platform rejected event if the platform disapproved it. Ads still in review are
+// checked again later, up to AdReviewMaxChecks times.
+func (s *DeploymentService) CheckAdReview(ctx context.Context, check *models.AdReviewCheck) error {
+	logger := s.logger.WithFields(logrus.Fields{
+		"asset_id":       check.AssetID,
+		"platform":       check.Platform,
+		"platform_ad_id": check.PlatformAdID,
+	})
+
+	var reviewer adReviewer
+	switch check.Platform {
+	case models.PlatformGoogleAds:
+		client, err := s.googleAdsClientFor(ctx, check.TenantID)
+		if err != nil {
+			return err
+		}
+		reviewer = client
+	case models.PlatformMeta:
+		client, err := s.metaClientFor(ctx, check.TenantID)
+		if err != nil {
+			return err
+		}
+		reviewer = client
+	default:
+		logger.Warn("Ignoring ad review check for unsupported platform")
+		return nil
+	}
+
+	review, err := reviewer.GetAdReviewStatus(ctx, check.PlatformAdID)
+	if err != nil {
+		return err
+	}
+
+	switch review.Status {
+	case models.AdReviewStatusPending:
+		next := *check
+		next.Attempt++
+		if next.Attempt >= s.config.AdReviewMaxChecks {
+			logger.WithField("checks", next.Attempt).Warn("Ad is still in review, giving up")
+			return nil
+		}
+		s.scheduleAdReviewCheck(ctx, &next, logger)
+		return nil
+	case models.AdReviewStatusRejected:
+		logger.WithField("reasons", review.DisapprovalReasons).Warn("Ad rejected by platform review")
+
+		return s.natsClient.PublishAssetPlatformRejected(ctx, &models.AssetPlatformRejectedEvent{
+			EventType:          "asset.platform_rejected",
+			AssetID:            check.AssetID,
+			ProjectID:          check.ProjectID,
+			Platform:           check.Platform,
+			PlatformAdID:       check.PlatformAdID,
+			Reason:             review.Reason(),
+			DisapprovalReasons: review.DisapprovalReasons,
+			PolicyViolations:   review.PolicyViolations,
+			Timestamp:          time.Now(),
+		})
+	default:
+		logger.Info("Ad approved by platform review")
+		return nil
+	}
+}
+
 // deployToplatform deploys an asset to a specific platform with retry logic
-func (s *DeploymentService) deployToplatform(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error) {
+func (s *DeploymentService) deployToplatform(ctx context.Context, request *models.DeploymentRequest) (_ *models.DeploymentResult, err error) {
+	ctx, span := tracing.Tracer.Start(ctx, "DeploymentService.deployToplatform", trace.WithAttributes(
+		attribute.String("asset.id", request.AssetID.String()),
+		attribute.String("platform", string(request.Platform)),
+	))
+	defer func() { tracing.End(span, err) }()
+
 	logger := s.logger.WithFields(logrus.Fields{
 		"asset_id": request.AssetID,
 		"platform": request.Platform,
+		"trace_id": span.SpanContext().TraceID().String(),
 	})
 
+	if err := s.creatives.validateCreative(request); err != nil {
+		logger.WithError(err).Warn("Deployment creative failed validation")
+		return nil, err
+	}
+
+	if err := s.budgets.Validate(request.Metadata.Budget, request.Platform); err != nil {
+		if !request.IsDryRun() {
+			s.publishBudgetExceeded(ctx, request, err, logger)
+		}
+		return nil, err
+	}
+
+	if request.Platform == models.PlatformGoogleAds && !request.IsDryRun() {
+		if err := s.checkGoogleAdsCredit(ctx, request, logger); err != nil {
+			return nil, err
+		}
+	}
+
 	var lastErr error
 	
 	for attempt := 1; attempt <= s.config.MaxRetryAttempts; attempt++ {
@@ -153,7 +834,7 @@ func (s *DeploymentService) deployToplatform(ctx context.Context, request *model
 		// Create context with timeout
 		deployCtx, cancel := context.WithTimeout(ctx, s.config.Timeout)
 		
-		result, err := s.executeDeployment(deployCtx, request)
+		result, err := s.executeThroughBreaker(deployCtx, request)
 		cancel()
 		
 		if err == nil {
@@ -161,16 +842,28 @@ func (s *DeploymentService) deployToplatform(ctx context.Context, request *model
 			result.Metrics.RetryCount = attempt - 1
 			return result, nil
 		}
+
+		// The platform is failing, further attempts would be refused as well
+		if errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+			logger.WithField("attempt", attempt).Warn("Deployment refused by the platform circuit breaker")
+			return nil, fmt.Errorf("%s deployments are suspended: %w", request.Platform, err)
+		}
 		
 		lastErr = err
 		logger.WithError(err).WithField("attempt", attempt).Warn("Deployment attempt failed")
 		
 		// Don't retry on the last attempt
 		if attempt < s.config.MaxRetryAttempts {
-			logger.WithField("delay", s.config.RetryDelay).Info("Retrying deployment")
+			// Wait for an account out of quota to come out of its backoff
+			delay := s.retryBackoff.Next(attempt)
+			var quotaErr *ratelimit.QuotaError
+			if errors.As(err, &quotaErr) && quotaErr.RetryAfter > delay {
+				delay = quotaErr.RetryAfter
+			}
+			logger.WithField("delay", delay).Info("Retrying deployment")
 			
 			select {
-			case <-time.After(s.config.RetryDelay):
+			case <-time.After(delay):
 				// Continue to next attempt
 			case <-ctx.Done():
 				return nil, ctx.Err()
@@ -179,21 +872,282 @@ func (s *DeploymentService) deployToplatform(ctx context.Context, request *model
 	}
 	
 	logger.WithError(lastErr).Error("All deployment attempts failed")
-	return nil, fmt.Errorf("deployment failed after %d attempts: %w", s.config.MaxRetryAttempts, lastErr)
+	return nil, fmt.Errorf("%w after %d attempts: %w", ErrRetriesExhausted, s.config.MaxRetryAttempts, lastErr)
+}
+
+// publishBudgetExceeded reports a deployment refused for its budget, so that the
+// BFF can notify the project's users
+func (s *DeploymentService) publishBudgetExceeded(ctx context.Context, request *models.DeploymentRequest, err error, logger *logrus.Entry) {
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"budget": budgetErr.Budget,
+		"cap":    budgetErr.Cap,
+	}).Warn("Deployment budget exceeds the platform cap")
+
+	err = s.natsClient.PublishCampaignBudgetExceeded(ctx, &models.CampaignBudgetExceededEvent{
+		EventType: "campaign.budget_exceeded",
+		AssetID:   request.AssetID,
+		ProjectID: request.ProjectID,
+		Platform:  budgetErr.Platform,
+		Budget:    budgetErr.Budget,
+		Cap:       budgetErr.Cap,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to publish campaign budget exceeded event")
+	}
+}
+
+// checkGoogleAdsCredit fails with ErrInsufficientCredit when the tenant's Google Ads
+// account cannot pay for the budget of request. Deployments go ahead when the
+// billing status cannot be read.
+func (s *DeploymentService) checkGoogleAdsCredit(ctx context.Context, request *models.DeploymentRequest, logger *logrus.Entry) error {
+	client, err := s.googleAdsClientFor(ctx, request.TenantID)
+	if err != nil {
+		return err
+	}
+
+	billing, err := client.GetBillingStatus(ctx)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to check Google Ads billing status")
+		return nil
+	}
+
+	if !billing.Approved {
+		return fmt.Errorf("%w: billing setup is not approved", ErrInsufficientCredit)
+	}
+	if !billing.Covers(request.Metadata.Budget) {
+		return fmt.Errorf("%w: %.2f %s left, budget is %.2f", ErrInsufficientCredit, billing.CurrentBalance, billing.Currency, request.Metadata.Budget)
+	}
+
+	return nil
+}
+
+// executeThroughBreaker runs executeDeployment through the circuit breaker of the
+// request's platform and tenant. Dry runs bypass the breaker, as their errors
+// are mostly about the request.
+func (s *DeploymentService) executeThroughBreaker(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error) {
+	if request.IsDryRun() {
+		return s.executeDeployment(ctx, request)
+	}
+
+	breaker := s.breakerFor(request.Platform, request.TenantID)
+
+	var result *models.DeploymentResult
+	err := breaker.Execute(func() error {
+		var err error
+		result, err = s.executeDeployment(ctx, request)
+		return err
+	})
+	return result, err
 }
 
 // executeDeployment executes the actual deployment to a platform
 func (s *DeploymentService) executeDeployment(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error) {
+	if request.IsDryRun() {
+		return s.validateAssetDeployment(ctx, request)
+	}
+
 	switch request.Platform {
 	case models.PlatformGoogleAds:
-		return s.googleAdsClient.DeployAsset(ctx, request)
+		client, err := s.googleAdsClientFor(ctx, request.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		return client.DeployAsset(ctx, request)
 	case models.PlatformMeta:
-		return s.metaClient.DeployAsset(ctx, request)
+		client, err := s.metaClientFor(ctx, request.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		return client.DeployAsset(ctx, request)
+	case models.PlatformTikTok:
+		if s.tiktokClient == nil {
+			return nil, fmt.Errorf("TikTok deployments are not configured")
+		}
+		return s.tiktokClient.DeployAsset(ctx, request)
+	case models.PlatformLinkedIn:
+		if s.linkedinClient == nil {
+			return nil, fmt.Errorf("LinkedIn deployments are not configured")
+		}
+		return s.linkedinClient.DeployAsset(ctx, request)
+	case models.PlatformTwitter:
+		if s.twitterClient == nil {
+			return nil, fmt.Errorf("Twitter deployments are not configured")
+		}
+		return s.twitterClient.DeployAsset(ctx, request)
 	default:
 		return nil, fmt.Errorf("unsupported platform: %s", request.Platform)
 	}
 }
 
+// validateAssetDeployment checks a dry-run request and returns the platform's cost
+// estimate for it without creating anything or committing budget
+func (s *DeploymentService) validateAssetDeployment(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error) {
+	if request.Metadata.Budget <= 0 {
+		return nil, fmt.Errorf("budget must be positive")
+	}
+
+	var estimate *models.CostEstimate
+	switch request.Platform {
+	case models.PlatformGoogleAds:
+		client, err := s.googleAdsClientFor(ctx, request.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		if estimate, err = client.EstimateCampaignCost(ctx, request); err != nil {
+			return nil, err
+		}
+	case models.PlatformMeta:
+		client, err := s.metaClientFor(ctx, request.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		if estimate, err = client.EstimateAdCost(ctx, request); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", request.Platform)
+	}
+
+	return &models.DeploymentResult{
+		AssetID:      request.AssetID,
+		Platform:     request.Platform,
+		Status:       models.DeploymentStatusEstimated,
+		CostEstimate: estimate,
+	}, nil
+}
+
+// EstimateDeployment returns the cost estimate of deploying request without deploying it
+func (s *DeploymentService) EstimateDeployment(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error) {
+	request.Mode = models.DeploymentModeDryRun
+
+	estimateCtx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	result, err := s.executeDeployment(estimateCtx, request)
+	if err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"asset_id": request.AssetID,
+			"platform": request.Platform,
+		}).Error("Deployment cost estimate failed")
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeployAssetFromTemplate merges a deployment template into the asset and deploys
+// it to the template's platform like any approved asset
+func (s *DeploymentService) DeployAssetFromTemplate(ctx context.Context, request *models.TemplateDeploymentRequest) (*models.DeploymentResult, error) {
+	switch request.Template.Platform {
+	case models.PlatformGoogleAds, models.PlatformMeta:
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", request.Template.Platform)
+	}
+
+	event := request.Template.Apply(request.Asset)
+	event.EventType = "asset.status_changed"
+	event.PrevStatus = request.Asset.Status
+	event.Status = models.AssetStatusApproved
+	event.Timestamp = time.Now()
+
+	logger := s.logger.WithFields(logrus.Fields{
+		"asset_id":     event.AssetID,
+		"project_id":   event.ProjectID,
+		"template_id":  request.Template.ID,
+		"platform":     request.Template.Platform,
+		"content_type": event.ContentType,
+	})
+
+	logger.Info("Deploying asset from template")
+
+	results := s.deployAsset(ctx, &event, logger)
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no deployment was made")
+	}
+
+	return &results[0], nil
+}
+
+// tenantCredentials looks up the stored credentials of a tenant. It returns
+// false when the default platform credentials should be used instead.
+func (s *DeploymentService) tenantCredentials(ctx context.Context, tenantID string, platform models.Platform) (credentials.PlatformCreds, bool, error) {
+	if s.credentialStore == nil || tenantID == "" {
+		return credentials.PlatformCreds{}, false, nil
+	}
+
+	creds, err := s.credentialStore.GetCredentials(ctx, tenantID, string(platform))
+	if errors.Is(err, credentials.ErrNotFound) {
+		return creds, false, nil
+	} else if err != nil {
+		return creds, false, fmt.Errorf("failed to load credentials for tenant %s: %w", tenantID, err)
+	}
+
+	return creds, true, nil
+}
+
+// googleAdsClientFor returns a Google Ads client authenticated as the tenant
+func (s *DeploymentService) googleAdsClientFor(ctx context.Context, tenantID string) (GoogleAdsClient, error) {
+	creds, ok, err := s.tenantCredentials(ctx, tenantID, models.PlatformGoogleAds)
+	if err != nil || !ok {
+		return s.googleAdsClient, err
+	}
+
+	cfg := creds.GoogleAdsConfig(*s.googleAdsClient.Config())
+	client, err := googleads.NewClient(&cfg, s.logger)
+	if err != nil {
+		return nil, err
+	}
+	client.SetScheduler(s.scheduler)
+	client.SetRateLimiter(s.rateLimiter)
+
+	return client, nil
+}
+
+// metaClientFor returns a Meta client authenticated as the tenant
+func (s *DeploymentService) metaClientFor(ctx context.Context, tenantID string) (MetaClient, error) {
+	creds, ok, err := s.tenantCredentials(ctx, tenantID, models.PlatformMeta)
+	if err != nil || !ok {
+		return s.metaClient, err
+	}
+
+	cfg := creds.MetaConfig(*s.metaClient.Config())
+	client, err := meta.NewClient(&cfg, s.logger)
+	if err != nil {
+		return nil, err
+	}
+	client.SetScheduler(s.scheduler)
+	client.SetRateLimiter(s.rateLimiter)
+	client.SetResponseCache(s.responseCache)
+
+	return client, nil
+}
+
+// CampaignController returns the platform client managing a tenant's campaigns
+func (s *DeploymentService) CampaignController(ctx context.Context, tenantID string, platform models.Platform) (scheduler.CampaignController, error) {
+	switch platform {
+	case models.PlatformGoogleAds:
+		client, err := s.googleAdsClientFor(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case models.PlatformMeta:
+		client, err := s.metaClientFor(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", platform)
+	}
+}
+
 // publishDeploymentStatusEvent publishes a deployment status changed event
 func (s *DeploymentService) publishDeploymentStatusEvent(ctx context.Context, originalEvent *models.AssetStatusChangedEvent, result models.DeploymentResult) error {
 	var newStatus models.AssetStatus
@@ -216,11 +1170,136 @@ func (s *DeploymentService) publishDeploymentStatusEvent(ctx context.Context, or
 		PrevStatus:       originalEvent.Status,
 		DeploymentResult: result,
 		Timestamp:        time.Now(),
+		VariantGroup:     originalEvent.VariantGroup,
+		VariantID:        originalEvent.Metadata.CreativeSpecs.VariantID,
 	}
 
 	return s.natsClient.PublishDeploymentStatusChanged(ctx, event)
 }
 
+// DuplicateCampaign clones a deployed campaign on the requested platform
+func (s *DeploymentService) DuplicateCampaign(ctx context.Context, request *models.CampaignDuplicationRequest) (*models.CampaignDuplicationResult, error) {
+	logger := s.logger.WithFields(logrus.Fields{
+		"asset_id":           request.AssetID,
+		"platform":           request.Platform,
+		"source_campaign_id": request.SourceCampaignID,
+	})
+
+	result := &models.CampaignDuplicationResult{
+		SourceCampaignID: request.SourceCampaignID,
+	}
+
+	switch request.Platform {
+	case models.PlatformMeta:
+		client, err := s.metaClientFor(ctx, request.TenantID)
+		if err != nil {
+			return nil, err
+		}
+
+		campaignID, err := client.DuplicateCampaign(ctx, request.SourceCampaignID, request.NewName, request.NewBudget)
+		if err != nil {
+			logger.WithError(err).Error("Campaign duplication failed")
+			return nil, err
+		}
+		result.CampaignID = campaignID
+	default:
+		return nil, fmt.Errorf("campaign duplication not supported for platform: %s", request.Platform)
+	}
+
+	logger.WithField("campaign_id", result.CampaignID).Info("Campaign duplicated")
+
+	return result, nil
+}
+
+// CreateCampaignSchedule schedules the pauses and resumes of a tenant's campaign
+func (s *DeploymentService) CreateCampaignSchedule(ctx context.Context, request *models.CampaignScheduleRequest) (*models.CampaignSchedule, error) {
+	if s.scheduler == nil {
+		return nil, fmt.Errorf("campaign scheduling is not configured")
+	}
+
+	if request.TenantID == "" {
+		return nil, fmt.Errorf("tenant id is required")
+	}
+
+	logger := s.logger.WithFields(logrus.Fields{
+		"tenant_id":            request.TenantID,
+		"platform":             request.Platform,
+		"platform_campaign_id": request.PlatformCampaignID,
+	})
+
+	var schedule func(ctx context.Context, tenantID, platformCampaignID, pauseCron, resumeCron string, timezone string) error
+	switch request.Platform {
+	case models.PlatformGoogleAds:
+		client, err := s.googleAdsClientFor(ctx, request.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		schedule = client.ScheduleCampaignPauseResume
+	case models.PlatformMeta:
+		client, err := s.metaClientFor(ctx, request.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		schedule = client.ScheduleCampaignPauseResume
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", request.Platform)
+	}
+
+	err := schedule(ctx, request.TenantID, request.PlatformCampaignID, request.PauseCron, request.ResumeCron, request.Timezone)
+	if errors.Is(err, scheduler.ErrNotFound) {
+		return nil, fmt.Errorf("campaign is scheduled by another tenant")
+	} else if err != nil {
+		logger.WithError(err).Error("Campaign scheduling failed")
+		return nil, err
+	}
+
+	return s.scheduler.CampaignSchedule(ctx, request.Platform, request.PlatformCampaignID)
+}
+
+// DeleteCampaignSchedule deletes a tenant's campaign schedule
+func (s *DeploymentService) DeleteCampaignSchedule(ctx context.Context, request *models.CampaignScheduleDeletionRequest) error {
+	if s.scheduler == nil {
+		return fmt.Errorf("campaign scheduling is not configured")
+	}
+
+	if request.TenantID == "" {
+		return fmt.Errorf("tenant id is required")
+	}
+
+	return s.scheduler.DeleteCampaignSchedule(ctx, request.ID, request.TenantID)
+}
+
+// StorePlatformCredentials validates and stores a tenant's credentials for a platform
+func (s *DeploymentService) StorePlatformCredentials(ctx context.Context, request *models.PlatformCredentialsRequest) error {
+	if s.credentialStore == nil {
+		return fmt.Errorf("per-tenant credential storage is not configured")
+	}
+
+	if request.TenantID == "" {
+		return fmt.Errorf("tenant id is required")
+	}
+
+	creds, err := s.credentialStore.OpenTransported(request.TenantID, string(request.Platform), request.EncryptedCredentials)
+	if err != nil {
+		return err
+	}
+
+	if err := creds.Validate(request.Platform); err != nil {
+		return err
+	}
+
+	if err := s.credentialStore.StoreCredentials(ctx, request.TenantID, string(request.Platform), creds); err != nil {
+		return err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"tenant_id": request.TenantID,
+		"platform":  request.Platform,
+	}).Info("Platform credentials stored")
+
+	return nil
+}
+
 // HealthCheck checks the health of all platform clients
 func (s *DeploymentService) HealthCheck(ctx context.Context) map[string]string {
 	health := make(map[string]string)
@@ -239,6 +1318,33 @@ func (s *DeploymentService) HealthCheck(ctx context.Context) map[string]string {
 		health["meta"] = "healthy"
 	}
 
+	// Check TikTok client
+	if s.tiktokClient != nil {
+		if err := s.tiktokClient.HealthCheck(ctx); err != nil {
+			health["tiktok"] = fmt.Sprintf("unhealthy: %v", err)
+		} else {
+			health["tiktok"] = "healthy"
+		}
+	}
+
+	// Check LinkedIn client
+	if s.linkedinClient != nil {
+		if err := s.linkedinClient.HealthCheck(ctx); err != nil {
+			health["linkedin"] = fmt.Sprintf("unhealthy: %v", err)
+		} else {
+			health["linkedin"] = "healthy"
+		}
+	}
+
+	// Check Twitter client
+	if s.twitterClient != nil {
+		if err := s.twitterClient.HealthCheck(ctx); err != nil {
+			health["twitter"] = fmt.Sprintf("unhealthy: %v", err)
+		} else {
+			health["twitter"] = "healthy"
+		}
+	}
+
 	// Check NATS client
 	if err := s.natsClient.HealthCheck(); err != nil {
 		health["nats"] = fmt.Sprintf("unhealthy: %v", err)
@@ -249,25 +1355,92 @@ func (s *DeploymentService) HealthCheck(ctx context.Context) map[string]string {
 	return health
 }
 
+// billingStatusTTL is how long the billing status reported by health checks is
+// reused before it is read again, as reading it takes three API calls
+const billingStatusTTL = 5 * time.Minute
+
+// cachedBillingStatus is a billing status read, or the error reading it, and when
+type cachedBillingStatus struct {
+	status    *models.BillingStatus
+	err       error
+	fetchedAt time.Time
+}
+
+// GoogleAdsBillingStatus returns the billing status of the default Google Ads
+// account. The status, or the error reading it, is reused for billingStatusTTL so
+// that health probes do not spend the account's API quota.
+func (s *DeploymentService) GoogleAdsBillingStatus(ctx context.Context) (*models.BillingStatus, error) {
+	s.billingMu.Lock()
+	defer s.billingMu.Unlock()
+
+	if s.billing == nil || time.Since(s.billing.fetchedAt) >= billingStatusTTL {
+		status, err := s.googleAdsClient.GetBillingStatus(ctx)
+		s.billing = &cachedBillingStatus{status: status, err: err, fetchedAt: time.Now()}
+	}
+
+	return s.billing.status, s.billing.err
+}
+
+// DeploymentStats returns the current deployment counters
+func (s *DeploymentService) DeploymentStats(ctx context.Context) (*stats.Stats, error) {
+	if s.statsCollector == nil {
+		return &stats.Stats{Platforms: map[models.Platform]stats.PlatformStats{
+			models.PlatformGoogleAds: {},
+			models.PlatformMeta:      {},
+			models.PlatformTikTok:    {},
+			models.PlatformLinkedIn:  {},
+			models.PlatformTwitter:   {},
+		}}, nil
+	}
+
+	return s.statsCollector.Stats(ctx)
+}
+
 // GetDeploymentStats returns deployment statistics
-func (s *DeploymentService) GetDeploymentStats() map[string]interface{} {
-	// In a real implementation, you would track these metrics
+func (s *DeploymentService) GetDeploymentStats(ctx context.Context) (map[string]interface{}, error) {
+	current, err := s.DeploymentStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	platforms := make(map[string]interface{}, len(current.Platforms))
+	for platform, platformStats := range current.Platforms {
+		platforms[string(platform)] = map[string]interface{}{
+			"deployments":  int(platformStats.Total),
+			"success_rate": fmt.Sprintf("%.1f%%", platformStats.SuccessRate()),
+		}
+	}
+
 	return map[string]interface{}{
-		"total_deployments":     0,
-		"successful_deployments": 0,
-		"failed_deployments":    0,
-		"average_duration":      "0s",
-		"platforms": map[string]interface{}{
-			"google_ads": map[string]interface{}{
-				"deployments": 0,
-				"success_rate": "0%",
-			},
-			"meta": map[string]interface{}{
-				"deployments": 0,
-				"success_rate": "0%",
-			},
-		},
+		"total_deployments":      int(current.Total),
+		"successful_deployments": int(current.Success),
+		"failed_deployments":     int(current.Failed),
+		"average_duration":       current.AverageDuration().String(),
+		"platforms":              platforms,
+		"quotas":                 s.PlatformQuotas(),
+		"queue_depths":           s.QueueDepths(),
+		"response_cache":         s.responseCache.Stats(),
+	}, nil
+}
+
+// QueueDepths returns the number of deployments waiting for a worker of each
+// platform
+func (s *DeploymentService) QueueDepths() map[models.Platform]int {
+	return s.queue.Depths()
+}
+
+// ResetStats clears all deployment counters
+func (s *DeploymentService) ResetStats(ctx context.Context) error {
+	if s.statsCollector == nil {
+		return fmt.Errorf("deployment statistics are not enabled")
+	}
+
+	if err := s.statsCollector.Reset(ctx); err != nil {
+		return err
 	}
+
+	s.logger.Warn("Deployment statistics reset")
+	return nil
 }
 
 // Helper functions
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+// Ages the platforms accept in targeting
+const (
+	minTargetingAge = 13
+	maxTargetingAge = 65
+)
+
+// DryRunDeployment checks the deployment of an approved asset to every platform
+// in its metadata and returns the validation report of each. Nothing is created
+// on the platforms and no event is published.
+func (s *DeploymentService) DryRunDeployment(ctx context.Context, event *models.AssetStatusChangedEvent) (*models.DeploymentValidationReport, error) {
+	if len(event.Metadata.Platforms) == 0 {
+		return nil, fmt.Errorf("asset has no platforms to deploy to")
+	}
+
+	report := &models.DeploymentValidationReport{AssetID: event.AssetID, Valid: true}
+	for _, platform := range event.Metadata.Platforms {
+		request := deploymentRequestFor(event)
+		request.Platform = platform
+		request.Mode = models.DeploymentModeDryRun
+
+		validateCtx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+		platformReport, err := s.validateDeployment(validateCtx, request)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		report.Valid = report.Valid && platformReport.Valid
+		report.Platforms = append(report.Platforms, *platformReport)
+	}
+
+	return report, nil
+}
+
+// validateDeployment runs the checks a live deployment of request would have to
+// pass and asks the platform for its estimates. Failed checks are reported, not
+// returned as errors.
+func (s *DeploymentService) validateDeployment(ctx context.Context, request *models.DeploymentRequest) (*models.ValidationReport, error) {
+	if !slices.Contains(deploymentPlatforms, request.Platform) {
+		return nil, fmt.Errorf("unsupported platform: %s", request.Platform)
+	}
+
+	report := &models.ValidationReport{
+		AssetID:  request.AssetID,
+		Platform: request.Platform,
+	}
+
+	if err := s.checkCredentials(ctx, request); err != nil {
+		report.Errors = append(report.Errors, models.ValidationError{Field: "credentials", Message: err.Error()})
+	} else {
+		report.CredentialsValid = true
+	}
+
+	creativeErrs := s.creatives.Validate(request.ContentType, request.Platform, request.Metadata.CreativeSpecs)
+	report.CreativeComplete = len(creativeErrs) == 0
+	report.Errors = append(report.Errors, creativeErrs...)
+
+	budgetErrs := s.validateBudget(request)
+	report.BudgetWithinRange = len(budgetErrs) == 0
+	report.Errors = append(report.Errors, budgetErrs...)
+
+	targetingErrs := validateTargeting(request)
+	report.TargetingValid = len(targetingErrs) == 0
+	report.Errors = append(report.Errors, targetingErrs...)
+
+	report.Valid = report.CredentialsValid && report.CreativeComplete && report.BudgetWithinRange && report.TargetingValid
+
+	// Only Google Ads and Meta estimate deployments, with working credentials
+	if report.CredentialsValid && (request.Platform == models.PlatformGoogleAds || request.Platform == models.PlatformMeta) {
+		if result, err := s.validateAssetDeployment(ctx, request); err != nil {
+			report.EstimateError = err.Error()
+		} else {
+			report.CostEstimate = result.CostEstimate
+			report.EstimatedAudienceSize = result.CostEstimate.EstimatedAudienceSize
+		}
+	}
+
+	return report, nil
+}
+
+// checkCredentials fails when the client of the request's platform is not
+// configured or its credentials are refused by the platform
+func (s *DeploymentService) checkCredentials(ctx context.Context, request *models.DeploymentRequest) error {
+	var client PlatformClient
+	switch request.Platform {
+	case models.PlatformGoogleAds:
+		googleAdsClient, err := s.googleAdsClientFor(ctx, request.TenantID)
+		if err != nil {
+			return err
+		}
+		if googleAdsClient != nil {
+			client = googleAdsClient
+		}
+	case models.PlatformMeta:
+		metaClient, err := s.metaClientFor(ctx, request.TenantID)
+		if err != nil {
+			return err
+		}
+		if metaClient != nil {
+			client = metaClient
+		}
+	case models.PlatformTikTok:
+		client = s.tiktokClient
+	case models.PlatformLinkedIn:
+		client = s.linkedinClient
+	case models.PlatformTwitter:
+		client = s.twitterClient
+	}
+
+	if client == nil {
+		return fmt.Errorf("%s deployments are not configured", request.Platform)
+	}
+	return client.HealthCheck(ctx)
+}
+
+// validateBudget returns the problems with the daily budget of request
+func (s *DeploymentService) validateBudget(request *models.DeploymentRequest) []models.ValidationError {
+	if request.Metadata.Budget <= 0 {
+		return []models.ValidationError{{Field: "budget", Message: "must be positive"}}
+	}
+
+	var budgetErr *BudgetExceededError
+	if err := s.budgets.Validate(request.Metadata.Budget, request.Platform); errors.As(err, &budgetErr) {
+		return []models.ValidationError{{
+			Field:   "budget",
+			Message: fmt.Sprintf("must be at most the %s cap of %.2f", budgetErr.Platform, budgetErr.Cap),
+		}}
+	}
+
+	return nil
+}
+
+// validateTargeting returns the problems with the targeting of request that its
+// platform would refuse or that would keep the ads from serving
+func validateTargeting(request *models.DeploymentRequest) []models.ValidationError {
+	var errs []models.ValidationError
+	demographics := request.Metadata.Demographics
+
+	if demographics.AgeMin != 0 && demographics.AgeMin < minTargetingAge {
+		errs = append(errs, models.ValidationError{
+			Field:   "demographics.age_min",
+			Message: fmt.Sprintf("must be at least %d", minTargetingAge),
+		})
+	}
+	if demographics.AgeMax > maxTargetingAge {
+		errs = append(errs, models.ValidationError{
+			Field:   "demographics.age_max",
+			Message: fmt.Sprintf("must be at most %d", maxTargetingAge),
+		})
+	}
+	if demographics.AgeMax != 0 && demographics.AgeMin > demographics.AgeMax {
+		errs = append(errs, models.ValidationError{Field: "demographics.age_max", Message: "must not be below age_min"})
+	}
+
+	switch request.Platform {
+	case models.PlatformGoogleAds:
+		// Search ads only serve on their keywords
+		if request.ContentType != models.ContentTypeInfographic && len(request.Metadata.Keywords) == 0 {
+			errs = append(errs, models.ValidationError{Field: "keywords", Message: "are required"})
+		}
+	case models.PlatformMeta:
+		if !demographics.UseAdvantagePlus && len(demographics.Locations) == 0 {
+			errs = append(errs, models.ValidationError{Field: "demographics.locations", Message: "are required"})
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+// Limits of the creatives of Meta video ads, in characters
+const (
+	metaVideoHeadlineMaxLength    = 125
+	metaVideoDescriptionMaxLength = 30
+)
+
+// CreativeValidationError is returned for deployments whose creative is missing
+// fields the platform requires, or has fields it would reject
+type CreativeValidationError struct {
+	Platform models.Platform
+	Errors   []models.ValidationError
+}
+
+func (e *CreativeValidationError) Error() string {
+	problems := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		problems[i] = err.Error()
+	}
+	return fmt.Sprintf("invalid %s creative: %s", e.Platform, strings.Join(problems, "; "))
+}
+
+// CreativeValidator checks the creative of a deployment against the requirements
+// of its content type on its platform, so that an incomplete creative fails
+// before any call to the platform
+type CreativeValidator struct{}
+
+// NewCreativeValidator creates a creative validator
+func NewCreativeValidator() *CreativeValidator {
+	return &CreativeValidator{}
+}
+
+// Validate returns the problems with specs as the creative of contentType on
+// platform, or nil when there are none. Content types without requirements on a
+// platform are left to the platform to check.
+func (v *CreativeValidator) Validate(contentType models.ContentType, platform models.Platform, specs models.CreativeSpecs) []models.ValidationError {
+	var errs []models.ValidationError
+
+	switch {
+	case contentType == models.ContentTypeVideoScript && platform == models.PlatformMeta:
+		if specs.VideoURL == "" {
+			errs = append(errs, models.ValidationError{Field: "video_url", Message: "is required"})
+		}
+		if utf8.RuneCountInString(specs.Headline) > metaVideoHeadlineMaxLength {
+			errs = append(errs, models.ValidationError{
+				Field:   "headline",
+				Message: fmt.Sprintf("must be at most %d characters", metaVideoHeadlineMaxLength),
+			})
+		}
+		if utf8.RuneCountInString(specs.Description) > metaVideoDescriptionMaxLength {
+			errs = append(errs, models.ValidationError{
+				Field:   "description",
+				Message: fmt.Sprintf("must be at most %d characters", metaVideoDescriptionMaxLength),
+			})
+		}
+
+	case contentType == models.ContentTypeInfographic && platform == models.PlatformGoogleAds:
+		if specs.ImageURL == "" {
+			errs = append(errs, models.ValidationError{Field: "image_url", Message: "is required"})
+		} else if u, err := url.Parse(specs.ImageURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			errs = append(errs, models.ValidationError{Field: "image_url", Message: "must be an HTTPS URL"})
+		}
+	}
+
+	return errs
+}
+
+// validateCreative returns a CreativeValidationError when the creative of request
+// is invalid on its platform
+func (v *CreativeValidator) validateCreative(request *models.DeploymentRequest) error {
+	errs := v.Validate(request.ContentType, request.Platform, request.Metadata.CreativeSpecs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return &CreativeValidationError{Platform: request.Platform, Errors: errs}
+}
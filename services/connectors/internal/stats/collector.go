@@ -0,0 +1,170 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+const (
+	keyPrefix     = "deployments:"
+	keyTotal      = keyPrefix + "total"
+	keySuccess    = keyPrefix + "success"
+	keyFailed     = keyPrefix + "failed"
+	keyDurationMs = keyPrefix + "duration_ms"
+)
+
+// PlatformStats holds the deployment counters of a single platform
+type PlatformStats struct {
+	Total   int64 `json:"total"`
+	Success int64 `json:"success"`
+}
+
+// SuccessRate returns the percentage of successful deployments
+func (p PlatformStats) SuccessRate() float64 {
+	return successRate(p.Success, p.Total)
+}
+
+// Failed returns the number of deployments that did not succeed
+func (p PlatformStats) Failed() int64 {
+	return p.Total - p.Success
+}
+
+// Stats is a snapshot of the deployment counters
+type Stats struct {
+	Total      int64                             `json:"total"`
+	Success    int64                             `json:"success"`
+	Failed     int64                             `json:"failed"`
+	DurationMs int64                             `json:"duration_ms"`
+	Platforms  map[models.Platform]PlatformStats `json:"platforms"`
+}
+
+// SuccessRate returns the percentage of successful deployments
+func (s *Stats) SuccessRate() float64 {
+	return successRate(s.Success, s.Total)
+}
+
+// AverageDuration returns the mean duration of recorded deployments
+func (s *Stats) AverageDuration() time.Duration {
+	if s.Total == 0 {
+		return 0
+	}
+	return time.Duration(s.DurationMs/s.Total) * time.Millisecond
+}
+
+// StatsCollector maintains deployment counters in Redis so they are shared by
+// every connectors instance and survive restarts
+type StatsCollector struct {
+	client    *redis.Client
+	platforms []models.Platform
+}
+
+// NewStatsCollector creates a stats collector backed by client
+func NewStatsCollector(client *redis.Client) *StatsCollector {
+	return &StatsCollector{
+		client:    client,
+		platforms: []models.Platform{models.PlatformGoogleAds, models.PlatformMeta, models.PlatformTikTok, models.PlatformLinkedIn, models.PlatformTwitter},
+	}
+}
+
+// Record increments the counters for a finished deployment. All counters are
+// updated in a single MULTI/EXEC block.
+func (c *StatsCollector) Record(ctx context.Context, result models.DeploymentResult) error {
+	success := result.Status == models.DeploymentStatusSuccess
+
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, keyTotal)
+		pipe.Incr(ctx, platformKey(result.Platform, "total"))
+		if success {
+			pipe.Incr(ctx, keySuccess)
+			pipe.Incr(ctx, platformKey(result.Platform, "success"))
+		} else {
+			pipe.Incr(ctx, keyFailed)
+		}
+		pipe.IncrBy(ctx, keyDurationMs, result.Metrics.Duration.Milliseconds())
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record deployment stats: %w", err)
+	}
+
+	return nil
+}
+
+// Stats returns the current counter values
+func (c *StatsCollector) Stats(ctx context.Context) (*Stats, error) {
+	keys := []string{keyTotal, keySuccess, keyFailed, keyDurationMs}
+	for _, platform := range c.platforms {
+		keys = append(keys, platformKey(platform, "total"), platformKey(platform, "success"))
+	}
+
+	values, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deployment stats: %w", err)
+	}
+
+	counters := make([]int64, len(values))
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		if _, err := fmt.Sscan(value.(string), &counters[i]); err != nil {
+			return nil, fmt.Errorf("invalid counter %s: %w", keys[i], err)
+		}
+	}
+
+	stats := &Stats{
+		Total:      counters[0],
+		Success:    counters[1],
+		Failed:     counters[2],
+		DurationMs: counters[3],
+		Platforms:  make(map[models.Platform]PlatformStats, len(c.platforms)),
+	}
+	for i, platform := range c.platforms {
+		stats.Platforms[platform] = PlatformStats{
+			Total:   counters[4+2*i],
+			Success: counters[5+2*i],
+		}
+	}
+
+	return stats, nil
+}
+
+// Reset deletes all deployment counters
+func (c *StatsCollector) Reset(ctx context.Context) error {
+	var keys []string
+	iter := c.client.Scan(ctx, 0, keyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to list deployment stats: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if err := c.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to reset deployment stats: %w", err)
+	}
+
+	return nil
+}
+
+// Helper functions
+
+func platformKey(platform models.Platform, counter string) string {
+	return fmt.Sprintf("%s%s:%s", keyPrefix, platform, counter)
+}
+
+func successRate(success, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(success) / float64(total) * 100
+}
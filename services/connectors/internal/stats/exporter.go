@@ -0,0 +1,84 @@
+package stats
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collectTimeout bounds the time a scrape waits for the counters
+const collectTimeout = 5 * time.Second
+
+var (
+	deploymentsDesc = prometheus.NewDesc("zamc_deployments_total",
+		"Total number of deployments.", nil, nil)
+	deploymentsSuccessDesc = prometheus.NewDesc("zamc_deployments_success_total",
+		"Number of successful deployments.", nil, nil)
+	deploymentsFailedDesc = prometheus.NewDesc("zamc_deployments_failed_total",
+		"Number of failed deployments.", nil, nil)
+	deploymentDurationDesc = prometheus.NewDesc("zamc_deployment_duration_milliseconds_total",
+		"Cumulative deployment duration in milliseconds.", nil, nil)
+	platformDeploymentsDesc = prometheus.NewDesc("zamc_platform_deployments_total",
+		"Number of deployments per platform.", []string{"platform"}, nil)
+	platformDeploymentsSuccessDesc = prometheus.NewDesc("zamc_platform_deployments_success_total",
+		"Number of successful deployments per platform.", []string{"platform"}, nil)
+	platformDeploymentsFailedDesc = prometheus.NewDesc("zamc_platform_deployments_failed_total",
+		"Number of failed deployments per platform.", []string{"platform"}, nil)
+)
+
+// Source returns the current deployment counters
+type Source func(ctx context.Context) (*Stats, error)
+
+// Exporter exports the deployment counters as Prometheus metrics. The counters
+// are read from their source on every scrape, so that every connectors instance
+// exports the totals shared in Redis.
+type Exporter struct {
+	source Source
+}
+
+var _ prometheus.Collector = &Exporter{}
+
+// NewExporter creates an exporter of the counters returned by source
+func NewExporter(source Source) *Exporter {
+	return &Exporter{source: source}
+}
+
+// Describe sends the descriptors of the exported metrics
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		deploymentsDesc,
+		deploymentsSuccessDesc,
+		deploymentsFailedDesc,
+		deploymentDurationDesc,
+		platformDeploymentsDesc,
+		platformDeploymentsSuccessDesc,
+		platformDeploymentsFailedDesc,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect reads the counters and sends them as metrics. A failure to read them
+// fails the scrape rather than exporting counters reset to zero.
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	stats, err := e.source(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(deploymentsDesc, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(deploymentsDesc, prometheus.CounterValue, float64(stats.Total))
+	ch <- prometheus.MustNewConstMetric(deploymentsSuccessDesc, prometheus.CounterValue, float64(stats.Success))
+	ch <- prometheus.MustNewConstMetric(deploymentsFailedDesc, prometheus.CounterValue, float64(stats.Failed))
+	ch <- prometheus.MustNewConstMetric(deploymentDurationDesc, prometheus.CounterValue, float64(stats.DurationMs))
+
+	for platform, platformStats := range stats.Platforms {
+		ch <- prometheus.MustNewConstMetric(platformDeploymentsDesc, prometheus.CounterValue, float64(platformStats.Total), string(platform))
+		ch <- prometheus.MustNewConstMetric(platformDeploymentsSuccessDesc, prometheus.CounterValue, float64(platformStats.Success), string(platform))
+		ch <- prometheus.MustNewConstMetric(platformDeploymentsFailedDesc, prometheus.CounterValue, float64(platformStats.Failed()), string(platform))
+	}
+}
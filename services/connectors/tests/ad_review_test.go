@@ -0,0 +1,193 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/googleads"
+	"github.com/zamc/connectors/internal/platforms/meta"
+)
+
+func newGoogleAdsReviewClient(t *testing.T, response string) *googleads.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v16/customers/1234567890/googleAds:search", r.URL.Path)
+
+		var body struct {
+			Query string `json:"query"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Contains(t, body.Query, "FROM ad_group_ad WHERE ad_group_ad.ad.resource_name = 'customers/1234567890/ads/")
+
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := googleads.NewClient(&config.GoogleAdsConfig{
+		CustomerID: "1234567890",
+		BaseURL:    server.URL + "/v16",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestGoogleAdsClient_GetAdReviewStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected models.AdReviewStatus
+	}{
+		{
+			name: "disapproved",
+			response: `{"results": [{"adGroupAd": {
+				"resourceName": "customers/1234567890/adGroupAds/777~987654",
+				"ad": {"id": "987654"},
+				"policySummary": {
+					"reviewStatus": "REVIEWED",
+					"approvalStatus": "DISAPPROVED",
+					"policyTopicEntries": [
+						{"topic": "MISLEADING_CLAIMS", "type": "PROHIBITED", "evidences": [{"textList": {"texts": ["guaranteed results"]}}]},
+						{"topic": "TRADEMARKS_IN_AD_TEXT", "type": "LIMITED"}
+					]
+				}
+			}}]}`,
+			expected: models.AdReviewStatus{
+				Status:             models.AdReviewStatusRejected,
+				DisapprovalReasons: []string{"MISLEADING_CLAIMS"},
+				PolicyViolations:   []string{"MISLEADING_CLAIMS", "TRADEMARKS_IN_AD_TEXT"},
+			},
+		},
+		{
+			name: "approved with limitations",
+			response: `{"results": [{"adGroupAd": {"policySummary": {
+				"reviewStatus": "REVIEWED",
+				"approvalStatus": "APPROVED_LIMITED",
+				"policyTopicEntries": [{"topic": "ALCOHOL", "type": "LIMITED"}]
+			}}}]}`,
+			expected: models.AdReviewStatus{
+				Status:           models.AdReviewStatusApproved,
+				PolicyViolations: []string{"ALCOHOL"},
+			},
+		},
+		{
+			name:     "in review",
+			response: `{"results": [{"adGroupAd": {"policySummary": {"reviewStatus": "REVIEW_IN_PROGRESS", "approvalStatus": "UNKNOWN"}}}]}`,
+			expected: models.AdReviewStatus{Status: models.AdReviewStatusPending},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newGoogleAdsReviewClient(t, tt.response)
+
+			status, err := client.GetAdReviewStatus(context.Background(), "987654")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, *status)
+		})
+	}
+}
+
+func TestGoogleAdsClient_GetAdReviewStatusErrors(t *testing.T) {
+	client := newGoogleAdsReviewClient(t, `{"results": []}`)
+
+	_, err := client.GetAdReviewStatus(context.Background(), "987654")
+	assert.EqualError(t, err, "ad 987654 not found")
+
+	// IDs are opaque, like those of the mock clients
+	_, err = client.GetAdReviewStatus(context.Background(), "ad_1700000000")
+	assert.EqualError(t, err, "ad ad_1700000000 not found")
+}
+
+func TestMetaClient_GetAdReviewStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected models.AdReviewStatus
+	}{
+		{
+			name: "disapproved",
+			response: `{
+				"id": "120200000000009",
+				"effective_status": "DISAPPROVED",
+				"ad_review_feedback": {
+					"global": {
+						"Personal Attributes": "Ads can't assert or imply personal attributes.",
+						"Misleading Claims": "Ads can't promise unrealistic results."
+					},
+					"placement_specific": {
+						"instagram": {"Body Image": "Ads can't show before-and-after images."}
+					}
+				}
+			}`,
+			expected: models.AdReviewStatus{
+				Status: models.AdReviewStatusRejected,
+				DisapprovalReasons: []string{
+					"Ads can't promise unrealistic results.",
+					"Ads can't assert or imply personal attributes.",
+					"Ads can't show before-and-after images.",
+				},
+				PolicyViolations: []string{"Misleading Claims", "Personal Attributes", "Body Image (instagram)"},
+			},
+		},
+		{
+			name:     "in review",
+			response: `{"id": "120200000000009", "effective_status": "PENDING_REVIEW"}`,
+			expected: models.AdReviewStatus{Status: models.AdReviewStatusPending},
+		},
+		{
+			name:     "approved",
+			response: `{"id": "120200000000009", "effective_status": "ACTIVE"}`,
+			expected: models.AdReviewStatus{Status: models.AdReviewStatusApproved},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "GET", r.Method)
+				assert.Equal(t, "/v18.0/120200000000009", r.URL.Path)
+				assert.Equal(t, "effective_status,ad_review_feedback", r.URL.Query().Get("fields"))
+
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client, err := meta.NewClient(&config.MetaConfig{
+				AccessToken: "token",
+				AdAccountID: "123",
+				APIVersion:  "v18.0",
+				BaseURL:     server.URL,
+			}, logrus.New())
+			require.NoError(t, err)
+
+			status, err := client.GetAdReviewStatus(context.Background(), "120200000000009")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, *status)
+		})
+	}
+}
+
+func TestAdReviewStatus_Reason(t *testing.T) {
+	status := &models.AdReviewStatus{
+		Status:             models.AdReviewStatusRejected,
+		DisapprovalReasons: []string{"Ads can't promise unrealistic results.", "Ads can't show before-and-after images."},
+		PolicyViolations:   []string{"Misleading Claims", "Body Image"},
+	}
+	assert.True(t, status.Rejected())
+	assert.Equal(t, "Ads can't promise unrealistic results.; Ads can't show before-and-after images.", status.Reason())
+
+	status.DisapprovalReasons = nil
+	assert.Equal(t, "Policy violations: Misleading Claims, Body Image", status.Reason())
+
+	status.PolicyViolations = nil
+	assert.Equal(t, "Disapproved by the platform's ad review", status.Reason())
+}
@@ -0,0 +1,231 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/analytics"
+	"github.com/zamc/connectors/internal/campaigns"
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/mocks"
+	"github.com/zamc/connectors/internal/models"
+)
+
+// memoryAnomalyStore keeps the metrics history and anomalies of campaigns in memory
+type memoryAnomalyStore struct {
+	mu        sync.Mutex
+	history   map[string]*analytics.MetricHistory
+	anomalies map[string]models.CampaignAnomaly
+}
+
+func newMemoryAnomalyStore() *memoryAnomalyStore {
+	return &memoryAnomalyStore{
+		history:   make(map[string]*analytics.MetricHistory),
+		anomalies: make(map[string]models.CampaignAnomaly),
+	}
+}
+
+func (s *memoryAnomalyStore) SetHistory(campaignID string, projectID uuid.UUID, values ...float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history[campaignID] = &analytics.MetricHistory{
+		Platform:  models.PlatformGoogleAds,
+		AssetID:   uuid.New(),
+		ProjectID: projectID,
+		Values:    values,
+	}
+}
+
+func (s *memoryAnomalyStore) MetricHistory(ctx context.Context, campaignID, metric string, since time.Time) (*analytics.MetricHistory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.history[campaignID], nil
+}
+
+func (s *memoryAnomalyStore) SaveAnomaly(ctx context.Context, anomaly *models.CampaignAnomaly) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := anomaly.CampaignID + "/" + anomaly.Metric
+	_, existed := s.anomalies[key]
+	s.anomalies[key] = *anomaly
+	return !existed, nil
+}
+
+func (s *memoryAnomalyStore) ClearAnomaly(ctx context.Context, campaignID, metric string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.anomalies, campaignID+"/"+metric)
+	return nil
+}
+
+func (s *memoryAnomalyStore) ProjectAnomalies(ctx context.Context, projectID uuid.UUID) ([]models.CampaignAnomaly, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	anomalies := []models.CampaignAnomaly{}
+	for _, anomaly := range s.anomalies {
+		if anomaly.ProjectID == projectID {
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+	return anomalies, nil
+}
+
+func newTestAnomalyDetector(store analytics.AnomalyStore, publisher analytics.AlertPublisher) *analytics.AnomalyDetector {
+	return analytics.NewAnomalyDetector(store, publisher, &config.AnomalyConfig{ZScoreThreshold: 2, WindowDays: 14}, logrus.New())
+}
+
+// ctrHistory is 14 days of click-through rates averaging 0.05 with a standard
+// deviation of about 0.0023
+var ctrHistory = []float64{0.048, 0.052, 0.05, 0.047, 0.053, 0.05, 0.049, 0.051, 0.05, 0.046, 0.054, 0.05, 0.052, 0.048}
+
+func TestAnomalyDetector_DetectsDropBelowAverage(t *testing.T) {
+	store := newMemoryAnomalyStore()
+	natsClient := mocks.NewMockNATSClient()
+	detector := newTestAnomalyDetector(store, natsClient)
+	projectID := uuid.New()
+	store.SetHistory("555", projectID, ctrHistory...)
+
+	anomalous, z, err := detector.Detect(context.Background(), "555", analytics.MetricCTR, 0.03)
+	require.NoError(t, err)
+	assert.True(t, anomalous)
+	assert.Less(t, z, -2.0)
+
+	anomalies, err := detector.Anomalies(context.Background(), projectID)
+	require.NoError(t, err)
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, "555", anomalies[0].CampaignID)
+	assert.Equal(t, analytics.MetricCTR, anomalies[0].Metric)
+	assert.Equal(t, models.AnomalySeverityCritical, anomalies[0].Severity)
+	assert.InDelta(t, 0.05, anomalies[0].Mean, 0.0001)
+
+	published := natsClient.GetPublishedEvents()
+	require.Len(t, published, 1)
+	alert := published[0].(*models.CampaignPerformanceAlertEvent)
+	assert.Equal(t, "campaign.performance_alert", alert.EventType)
+	assert.Equal(t, projectID, alert.Anomaly.ProjectID)
+	assert.Equal(t, z, alert.Anomaly.ZScore)
+
+	// A metric staying anomalous is not alerted again
+	_, _, err = detector.Detect(context.Background(), "555", analytics.MetricCTR, 0.045)
+	require.NoError(t, err)
+	assert.Len(t, natsClient.GetPublishedEvents(), 1)
+
+	// A metric back to normal is no longer an anomaly
+	anomalous, _, err = detector.Detect(context.Background(), "555", analytics.MetricCTR, 0.05)
+	require.NoError(t, err)
+	assert.False(t, anomalous)
+	anomalies, err = detector.Anomalies(context.Background(), projectID)
+	require.NoError(t, err)
+	assert.Empty(t, anomalies)
+}
+
+func TestAnomalyDetector_WithinThreshold(t *testing.T) {
+	store := newMemoryAnomalyStore()
+	natsClient := mocks.NewMockNATSClient()
+	detector := newTestAnomalyDetector(store, natsClient)
+	store.SetHistory("555", uuid.New(), ctrHistory...)
+
+	anomalous, z, err := detector.Detect(context.Background(), "555", analytics.MetricCTR, 0.047)
+	require.NoError(t, err)
+	assert.False(t, anomalous)
+	assert.InDelta(t, -1.31, z, 0.01)
+	assert.Empty(t, natsClient.GetPublishedEvents())
+}
+
+func TestAnomalyDetector_WarningSeverity(t *testing.T) {
+	store := newMemoryAnomalyStore()
+	detector := newTestAnomalyDetector(store, mocks.NewMockNATSClient())
+	projectID := uuid.New()
+	store.SetHistory("555", projectID, ctrHistory...)
+
+	// About 2.6 standard deviations above the average
+	anomalous, z, err := detector.Detect(context.Background(), "555", analytics.MetricCTR, 0.056)
+	require.NoError(t, err)
+	assert.True(t, anomalous)
+	assert.Greater(t, z, 2.0)
+
+	anomalies, err := detector.Anomalies(context.Background(), projectID)
+	require.NoError(t, err)
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, models.AnomalySeverityWarning, anomalies[0].Severity)
+}
+
+func TestAnomalyDetector_FewerThanThreeDataPoints(t *testing.T) {
+	store := newMemoryAnomalyStore()
+	natsClient := mocks.NewMockNATSClient()
+	detector := newTestAnomalyDetector(store, natsClient)
+	store.SetHistory("555", uuid.New(), 0.05, 0.06)
+
+	anomalous, z, err := detector.Detect(context.Background(), "555", analytics.MetricCTR, 0.001)
+	require.NoError(t, err)
+	assert.False(t, anomalous)
+	assert.Zero(t, z)
+
+	// Campaigns without any history neither
+	anomalous, z, err = detector.Detect(context.Background(), "999", analytics.MetricCTR, 0.001)
+	require.NoError(t, err)
+	assert.False(t, anomalous)
+	assert.Zero(t, z)
+	assert.Empty(t, natsClient.GetPublishedEvents())
+}
+
+func TestAnomalyDetector_ZeroVariance(t *testing.T) {
+	store := newMemoryAnomalyStore()
+	natsClient := mocks.NewMockNATSClient()
+	detector := newTestAnomalyDetector(store, natsClient)
+	store.SetHistory("555", uuid.New(), 0.05, 0.05, 0.05, 0.05)
+
+	anomalous, z, err := detector.Detect(context.Background(), "555", analytics.MetricCTR, 0.01)
+	require.NoError(t, err)
+	assert.False(t, anomalous)
+	assert.Zero(t, z)
+	assert.Empty(t, natsClient.GetPublishedEvents())
+}
+
+func TestAnomalyDetector_UnknownMetric(t *testing.T) {
+	store := newMemoryAnomalyStore()
+	detector := newTestAnomalyDetector(store, mocks.NewMockNATSClient())
+	store.SetHistory("555", uuid.New(), ctrHistory...)
+
+	anomalous, _, err := detector.Detect(context.Background(), "555", "bounce_rate", 0.5)
+	assert.ErrorIs(t, err, analytics.ErrUnknownMetric)
+	assert.False(t, anomalous)
+}
+
+// recordingDetector records the metrics it is asked to check
+type recordingDetector struct {
+	mu      sync.Mutex
+	checked map[string]float64
+}
+
+func (d *recordingDetector) Detect(ctx context.Context, campaignID string, metric string, currentValue float64) (bool, float64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.checked[campaignID+"/"+metric] = currentValue
+	return false, 0, nil
+}
+
+func TestMetricsPoller_DetectsAnomalies(t *testing.T) {
+	store := &memoryDeploymentStore{deployments: []models.CampaignDeployment{
+		{Platform: models.PlatformGoogleAds, PlatformCampaignID: "555", AssetID: uuid.New(), ProjectID: uuid.New()},
+		{Platform: models.PlatformMeta, PlatformCampaignID: "777", AssetID: uuid.New(), ProjectID: uuid.New()},
+	}}
+	fetcher := &fakeMetricsFetcher{metrics: map[string]*models.CampaignMetrics{
+		"555": {CampaignID: "555", CTR: 0.04},
+		"777": {CampaignID: "777", CTR: 0.02},
+	}}
+	detector := &recordingDetector{checked: map[string]float64{}}
+
+	poller := campaigns.NewMetricsPoller(store, fetcher, &recordingMetricsPublisher{}, time.Hour, logrus.New())
+	poller.SetAnomalyDetector(detector)
+	require.NoError(t, poller.Poll(context.Background()))
+
+	assert.Equal(t, map[string]float64{"555/ctr": 0.04, "777/ctr": 0.02}, detector.checked)
+}
@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zamc/connectors/internal/backoff"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	b := backoff.NewExponential(time.Second, 10*time.Second, 0)
+
+	assert.Equal(t, time.Second, b.Next(1))
+	assert.Equal(t, 2*time.Second, b.Next(2))
+	assert.Equal(t, 4*time.Second, b.Next(3))
+	assert.Equal(t, 8*time.Second, b.Next(4))
+	assert.Equal(t, 10*time.Second, b.Next(5))
+	assert.Equal(t, 10*time.Second, b.Next(100))
+
+	// Without a cap the delay stops doubling before it overflows
+	b = backoff.NewExponential(time.Second, 0, 0)
+	assert.Greater(t, b.Next(100), b.Next(30))
+}
+
+func TestExponentialBackoff_Jitter(t *testing.T) {
+	b := backoff.NewExponential(time.Second, 5*time.Second, 500*time.Millisecond)
+	var jitter int64
+	b.SetRandom(func(n int64) int64 {
+		assert.Equal(t, int64(500*time.Millisecond), n)
+		return jitter
+	})
+
+	jitter = int64(300 * time.Millisecond)
+	assert.Equal(t, 2300*time.Millisecond, b.Next(2))
+
+	// The jitter does not take the delay over the cap
+	assert.Equal(t, 5*time.Second, b.Next(4))
+
+	// Real jitter stays within its range
+	b = backoff.NewExponential(time.Second, 0, 500*time.Millisecond)
+	for i := 0; i < 100; i++ {
+		delay := b.Next(1)
+		assert.GreaterOrEqual(t, delay, time.Second)
+		assert.Less(t, delay, 1500*time.Millisecond)
+	}
+}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/googleads"
+)
+
+func TestBiddingStrategies_Decode(t *testing.T) {
+	var strategies config.BiddingStrategies
+	require.NoError(t, strategies.Decode(`{"blog_post": "MAXIMIZE_CLICKS", "social_media": "TARGET_CPA"}`))
+	assert.Equal(t, config.BiddingStrategies{
+		models.ContentTypeBlogPost:    "MAXIMIZE_CLICKS",
+		models.ContentTypeSocialMedia: "TARGET_CPA",
+	}, strategies)
+
+	assert.Error(t, strategies.Decode(`MAXIMIZE_CLICKS`))
+}
+
+func TestBuildCampaignBidding(t *testing.T) {
+	targetCPA := 20.0
+	strategies := config.BiddingStrategies{
+		models.ContentTypeVideoScript: "MAXIMIZE_CONVERSIONS",
+		models.ContentTypeInfographic: "TARGET_CPM",
+		models.ContentTypeBlogPost:    "TARGET_ROAS",
+	}
+
+	request := &models.DeploymentRequest{ContentType: models.ContentTypeVideoScript}
+	request.Metadata.TargetCPA = &targetCPA
+	bidding, err := googleads.BuildCampaignBidding(strategies, request)
+	require.NoError(t, err)
+	assert.Equal(t, &googleads.CampaignBidding{
+		BiddingStrategyType: "MAXIMIZE_CONVERSIONS",
+		MaximizeConversions: &googleads.MaximizeConversions{TargetCpaMicros: 20000000},
+	}, bidding)
+
+	bidding, err = googleads.BuildCampaignBidding(strategies, &models.DeploymentRequest{ContentType: models.ContentTypeInfographic})
+	require.NoError(t, err)
+	assert.Equal(t, &googleads.CampaignBidding{BiddingStrategyType: "TARGET_CPM", TargetCpm: &googleads.TargetCpm{}}, bidding)
+
+	// Without a target Google picks the return on ad spend
+	bidding, err = googleads.BuildCampaignBidding(strategies, &models.DeploymentRequest{ContentType: models.ContentTypeBlogPost})
+	require.NoError(t, err)
+	assert.Equal(t, &googleads.CampaignBidding{BiddingStrategyType: "TARGET_ROAS", TargetRoas: &googleads.TargetRoas{}}, bidding)
+}
+
+func TestGoogleAdsClient_RejectsUnsupportedBiddingStrategy(t *testing.T) {
+	_, err := googleads.NewClient(&config.GoogleAdsConfig{
+		CustomerID:         "1234567890",
+		BiddingStrategyMap: config.BiddingStrategies{models.ContentTypeVideoScript: "MANUAL_CPV"},
+	}, logrus.New())
+	assert.EqualError(t, err, `unsupported bidding strategy "MANUAL_CPV" for video_script`)
+}
+
+// googleAdsMutations is a Google Ads API recording the operations of its
+// mutate calls by resource
+type googleAdsMutations struct {
+	mu         sync.Mutex
+	operations map[string][]map[string]interface{}
+}
+
+// newGoogleAdsMutateClient returns a client of a Google Ads API creating every
+// resource of its mutate calls
+func newGoogleAdsMutateClient(t *testing.T, cfg config.GoogleAdsConfig) (*googleads.Client, *googleAdsMutations) {
+	mutations := &googleAdsMutations{operations: map[string][]map[string]interface{}{}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resource := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v16/customers/1234567890/"), ":mutate")
+
+		var body struct {
+			Operations []struct {
+				Create map[string]interface{} `json:"create"`
+			} `json:"operations"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Len(t, body.Operations, 1)
+
+		mutations.mu.Lock()
+		mutations.operations[resource] = append(mutations.operations[resource], body.Operations[0].Create)
+		id := len(mutations.operations[resource])
+		mutations.mu.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"results": []map[string]string{{"resourceName": fmt.Sprintf("customers/1234567890/%s/%d", resource, 1000+id)}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	cfg.CustomerID = "1234567890"
+	cfg.BaseURL = server.URL + "/v16"
+	client, err := googleads.NewClient(&cfg, logrus.New())
+	require.NoError(t, err)
+	return client, mutations
+}
+
+func TestGoogleAdsClient_DeploySendsBiddingStrategy(t *testing.T) {
+	client, mutations := newGoogleAdsMutateClient(t, config.GoogleAdsConfig{
+		BiddingStrategyMap: config.BiddingStrategies{models.ContentTypeSocialMedia: "TARGET_CPA"},
+	})
+
+	targetCPA := 12.5
+	request := estimateRequest(models.PlatformGoogleAds, 40)
+	request.ContentType = models.ContentTypeSocialMedia
+	request.Metadata.TargetCPA = &targetCPA
+
+	result, err := client.DeployAsset(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, "1001", result.CampaignID)
+
+	require.Len(t, mutations.operations["campaignBudgets"], 1)
+	assert.Equal(t, "40000000", mutations.operations["campaignBudgets"][0]["amountMicros"])
+
+	require.Len(t, mutations.operations["campaigns"], 1)
+	campaign := mutations.operations["campaigns"][0]
+	assert.Equal(t, "SEARCH", campaign["advertisingChannelType"])
+	assert.Equal(t, "customers/1234567890/campaignBudgets/1001", campaign["campaignBudget"])
+	assert.Equal(t, map[string]interface{}{"targetCpaMicros": float64(12500000)}, campaign["targetCpa"])
+	assert.NotContains(t, campaign, "maximizeConversions")
+
+	// Content types without a strategy keep the default bidding of the campaign
+	request.ContentType = models.ContentTypeBlogPost
+	request.Metadata.TargetCPA = nil
+	_, err = client.DeployAsset(context.Background(), request)
+	require.NoError(t, err)
+	require.Len(t, mutations.operations["campaigns"], 2)
+	assert.NotContains(t, mutations.operations["campaigns"][1], "targetCpa")
+	assert.NotContains(t, mutations.operations["campaigns"][1], "targetSpend")
+}
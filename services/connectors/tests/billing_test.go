@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/mocks"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/googleads"
+	"github.com/zamc/connectors/internal/service"
+)
+
+// newGoogleAdsBillingClient returns a client whose googleAds:search calls are
+// answered with the reply for the resource the query selects from
+func newGoogleAdsBillingClient(t *testing.T, replies map[string]string) *googleads.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v16/customers/1234567890/googleAds:search", r.URL.Path)
+
+		var body struct {
+			Query string `json:"query"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		for resource, reply := range replies {
+			if strings.Contains(body.Query, "FROM "+resource) {
+				w.Write([]byte(reply))
+				return
+			}
+		}
+		t.Errorf("unexpected query %q", body.Query)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := googleads.NewClient(&config.GoogleAdsConfig{
+		CustomerID: "1234567890",
+		BaseURL:    server.URL + "/v16",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestGoogleAdsClient_GetBillingStatus(t *testing.T) {
+	client := newGoogleAdsBillingClient(t, map[string]string{
+		"billing_setup": `{"results": [
+			{"billingSetup": {"id": "1", "status": "CANCELLED"}},
+			{"billingSetup": {"id": "2", "status": "APPROVED"}}
+		]}`,
+		"customer": `{"results": [{"customer": {"currencyCode": "EUR"}}]}`,
+		"account_budget": `{"results": [{"accountBudget": {
+			"id": "55",
+			"approvedSpendingLimitMicros": "500000000",
+			"amountServedMicros": "380250000"
+		}}]}`,
+	})
+
+	status, err := client.GetBillingStatus(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, status.Approved)
+	assert.False(t, status.Unlimited)
+	assert.Equal(t, 500.0, status.CreditLimit)
+	assert.InDelta(t, 119.75, status.CurrentBalance, 0.0001)
+	assert.Equal(t, "EUR", status.Currency)
+}
+
+func TestGoogleAdsClient_GetBillingStatusWithoutSpendingLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		budgets string
+	}{
+		{"no account budget", `{}`},
+		{"infinite spending limit", `{"results": [{"accountBudget": {"approvedSpendingLimitType": "INFINITE", "amountServedMicros": "1000000"}}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newGoogleAdsBillingClient(t, map[string]string{
+				"billing_setup":  `{"results": [{"billingSetup": {"id": "2", "status": "APPROVED"}}]}`,
+				"customer":       `{"results": [{"customer": {"currencyCode": "USD"}}]}`,
+				"account_budget": tt.budgets,
+			})
+
+			status, err := client.GetBillingStatus(context.Background())
+			require.NoError(t, err)
+
+			assert.True(t, status.Approved)
+			assert.True(t, status.Unlimited)
+			assert.Zero(t, status.CreditLimit)
+			assert.True(t, status.Covers(1e9))
+		})
+	}
+}
+
+func TestGoogleAdsClient_GetBillingStatusPendingSetup(t *testing.T) {
+	client := newGoogleAdsBillingClient(t, map[string]string{
+		"billing_setup":  `{"results": [{"billingSetup": {"id": "2", "status": "PENDING"}}]}`,
+		"customer":       `{"results": [{"customer": {"currencyCode": "USD"}}]}`,
+		"account_budget": `{}`,
+	})
+
+	status, err := client.GetBillingStatus(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, status.Approved)
+	assert.False(t, status.Covers(1))
+}
+
+func TestBillingStatus_Covers(t *testing.T) {
+	status := &models.BillingStatus{Approved: true, CreditLimit: 500, CurrentBalance: 120}
+
+	assert.True(t, status.Covers(100))
+	assert.True(t, status.Covers(120))
+	assert.False(t, status.Covers(120.01))
+
+	status.Approved = false
+	assert.False(t, status.Covers(100))
+}
+
+// billingCountingClient counts the reads of the billing status
+type billingCountingClient struct {
+	*mocks.MockGoogleAdsClient
+	reads int
+}
+
+func (c *billingCountingClient) GetBillingStatus(ctx context.Context) (*models.BillingStatus, error) {
+	c.reads++
+	return c.MockGoogleAdsClient.GetBillingStatus(ctx)
+}
+
+func TestDeploymentService_GoogleAdsBillingStatusIsCached(t *testing.T) {
+	client := &billingCountingClient{MockGoogleAdsClient: mocks.NewMockGoogleAdsClient()}
+	deploymentService := service.NewDeploymentService(client, mocks.NewMockMetaClient(), mocks.NewMockNATSClient(), nil, nil, &config.DeploymentConfig{
+		MaxRetryAttempts: 1,
+		RetryDelay:       time.Millisecond,
+		Timeout:          time.Second,
+	}, logrus.New())
+
+	// Health probes read the billing status once, not on every probe
+	for i := 0; i < 3; i++ {
+		status, err := deploymentService.GoogleAdsBillingStatus(context.Background())
+		require.NoError(t, err)
+		assert.True(t, status.Approved)
+	}
+	assert.Equal(t, 1, client.reads)
+}
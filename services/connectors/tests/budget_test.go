@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/meta"
+	"github.com/zamc/connectors/internal/service"
+)
+
+func TestBudgetValidator_Validate(t *testing.T) {
+	validator := service.NewBudgetValidator(&config.DeploymentConfig{
+		MaxDailyBudgetGoogleAds: 500,
+		MaxDailyBudgetMeta:      250,
+	})
+
+	tests := []struct {
+		name     string
+		budget   float64
+		platform models.Platform
+		cap      float64
+	}{
+		{name: "google ads within cap", budget: 500, platform: models.PlatformGoogleAds},
+		{name: "google ads over cap", budget: 500.01, platform: models.PlatformGoogleAds, cap: 500},
+		{name: "meta within cap", budget: 100, platform: models.PlatformMeta},
+		{name: "meta over cap", budget: 999999, platform: models.PlatformMeta, cap: 250},
+		{name: "uncapped platform", budget: 999999, platform: models.PlatformTikTok},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.Validate(tt.budget, tt.platform)
+			if tt.cap == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var budgetErr *service.BudgetExceededError
+			require.True(t, errors.As(err, &budgetErr))
+			assert.Equal(t, tt.platform, budgetErr.Platform)
+			assert.Equal(t, tt.budget, budgetErr.Budget)
+			assert.Equal(t, tt.cap, budgetErr.Cap)
+		})
+	}
+}
+
+func TestBudgetValidator_ZeroCapDisablesCheck(t *testing.T) {
+	validator := service.NewBudgetValidator(&config.DeploymentConfig{})
+
+	assert.NoError(t, validator.Validate(999999, models.PlatformGoogleAds))
+	assert.NoError(t, validator.Validate(999999, models.PlatformMeta))
+}
+
+func TestDeploymentService_RefusesBudgetOverCap(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"id": "123456"}`))
+	}))
+	defer server.Close()
+
+	metaClient, err := meta.NewClient(&config.MetaConfig{
+		AdAccountID: "123",
+		APIVersion:  "v18.0",
+		BaseURL:     server.URL,
+	}, logrus.New())
+	require.NoError(t, err)
+
+	natsServer := runJetStreamServer(t)
+	client := newConsumersClient(t, natsServer)
+
+	conn, err := natsgo.Connect(natsServer.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	exceeded := make(chan *models.CampaignBudgetExceededEvent, 1)
+	_, err = conn.Subscribe("zamc.events.campaign.budget_exceeded", func(msg *natsgo.Msg) {
+		var event models.CampaignBudgetExceededEvent
+		if json.Unmarshal(msg.Data, &event) == nil {
+			exceeded <- &event
+		}
+	})
+	require.NoError(t, err)
+	require.NoError(t, conn.Flush())
+
+	deploymentService := service.NewDeploymentService(nil, metaClient, client, nil, nil, &config.DeploymentConfig{
+		MaxRetryAttempts:   3,
+		RetryDelay:         time.Millisecond,
+		Timeout:            time.Second,
+		MaxDailyBudgetMeta: 250,
+	}, logrus.New())
+
+	event := approvedAssetEvent()
+	event.ContentType = models.ContentTypeSocialMedia
+	event.Metadata = models.Metadata{
+		Platforms: []models.Platform{models.PlatformMeta},
+		Budget:    999999,
+	}
+	require.NoError(t, deploymentService.HandleAssetStatusChanged(context.Background(), event))
+
+	select {
+	case budgetExceeded := <-exceeded:
+		assert.Equal(t, "campaign.budget_exceeded", budgetExceeded.EventType)
+		assert.Equal(t, event.AssetID, budgetExceeded.AssetID)
+		assert.Equal(t, event.ProjectID, budgetExceeded.ProjectID)
+		assert.Equal(t, models.PlatformMeta, budgetExceeded.Platform)
+		assert.Equal(t, 999999.0, budgetExceeded.Budget)
+		assert.Equal(t, 250.0, budgetExceeded.Cap)
+	case <-time.After(5 * time.Second):
+		t.Fatal("budget exceeded event was not published")
+	}
+
+	assert.Zero(t, atomic.LoadInt32(&calls), "Meta API was called for a budget over the cap")
+}
@@ -0,0 +1,422 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/campaigns"
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/googleads"
+	"github.com/zamc/connectors/internal/platforms/meta"
+)
+
+// campaignMetricsServer replies to googleAds:search calls with body and records
+// the queries
+func campaignMetricsServer(t *testing.T, body string, queries *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v16/customers/1234567890/googleAds:search", r.URL.Path)
+
+		var request struct {
+			Query string `json:"query"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		*queries = append(*queries, request.Query)
+
+		w.Write([]byte(body))
+	}))
+}
+
+func campaignMetricsDateRange() googleads.DateRange {
+	return googleads.DateRange{
+		Start: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC),
+		End:   time.Date(2024, 3, 14, 18, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestGoogleAdsClient_FetchCampaignMetrics(t *testing.T) {
+	var queries []string
+	server := campaignMetricsServer(t, `{
+		"results": [
+			{
+				"campaign": {"resourceName": "customers/1234567890/campaigns/555", "id": "555", "status": "ENABLED"},
+				"metrics": {
+					"impressions": "12000",
+					"clicks": "480",
+					"costMicros": "96500000",
+					"conversions": 12.5,
+					"ctr": 0.04
+				}
+			}
+		],
+		"fieldMask": "campaign.id,campaign.status,metrics.impressions,metrics.clicks,metrics.costMicros,metrics.conversions,metrics.ctr"
+	}`, &queries)
+	defer server.Close()
+
+	client, err := googleads.NewClient(&config.GoogleAdsConfig{
+		CustomerID: "123-456-7890",
+		BaseURL:    server.URL + "/v16",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	metrics, err := client.FetchCampaignMetrics(context.Background(), "555", campaignMetricsDateRange())
+	require.NoError(t, err)
+
+	require.Len(t, queries, 1)
+	assert.Contains(t, queries[0], "metrics.impressions, metrics.clicks, metrics.cost_micros, metrics.conversions, metrics.ctr FROM campaign")
+	assert.Contains(t, queries[0], "WHERE campaign.resource_name = 'customers/1234567890/campaigns/555' AND segments.date BETWEEN '2024-03-01' AND '2024-03-14'")
+
+	assert.Equal(t, "555", metrics.CampaignID)
+	assert.Equal(t, models.PlatformGoogleAds, metrics.Platform)
+	assert.Equal(t, int64(12000), metrics.Impressions)
+	assert.Equal(t, int64(480), metrics.Clicks)
+	assert.Equal(t, int64(96500000), metrics.CostMicros)
+	assert.Equal(t, 12.5, metrics.Conversions)
+	assert.Equal(t, 0.04, metrics.CTR)
+	assert.Equal(t, "2024-03-01", metrics.StartDate)
+	assert.Equal(t, "2024-03-14", metrics.EndDate)
+	assert.False(t, metrics.FetchedAt.IsZero())
+}
+
+func TestGoogleAdsClient_FetchCampaignMetricsWithoutTraffic(t *testing.T) {
+	var queries []string
+	server := campaignMetricsServer(t, `{}`, &queries)
+	defer server.Close()
+
+	client, err := googleads.NewClient(&config.GoogleAdsConfig{
+		CustomerID: "1234567890",
+		BaseURL:    server.URL + "/v16",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	metrics, err := client.FetchCampaignMetrics(context.Background(), "555", campaignMetricsDateRange())
+	require.NoError(t, err)
+	assert.Zero(t, metrics.Impressions)
+	assert.Zero(t, metrics.CostMicros)
+}
+
+func TestGoogleAdsClient_FetchCampaignMetricsOfRemovedCampaign(t *testing.T) {
+	var queries []string
+	server := campaignMetricsServer(t, `{
+		"results": [{"campaign": {"id": "555", "status": "REMOVED"}, "metrics": {"impressions": "10"}}]
+	}`, &queries)
+	defer server.Close()
+
+	client, err := googleads.NewClient(&config.GoogleAdsConfig{
+		CustomerID: "1234567890",
+		BaseURL:    server.URL + "/v16",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	_, err = client.FetchCampaignMetrics(context.Background(), "555", campaignMetricsDateRange())
+	assert.ErrorIs(t, err, models.ErrCampaignNotFound)
+}
+
+func TestGoogleAdsClient_FetchCampaignMetricsRejectsInvalidInput(t *testing.T) {
+	var queries []string
+	server := campaignMetricsServer(t, `{"results": []}`, &queries)
+	defer server.Close()
+
+	client, err := googleads.NewClient(&config.GoogleAdsConfig{
+		CustomerID: "1234567890",
+		BaseURL:    server.URL + "/v16",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// The ID is quoted as a string, whatever it holds
+	_, err = client.FetchCampaignMetrics(context.Background(), "555' OR campaign.id > '0", campaignMetricsDateRange())
+	require.NoError(t, err)
+	require.Len(t, queries, 1)
+	assert.Contains(t, queries[0], `WHERE campaign.resource_name = 'customers/1234567890/campaigns/555\' OR campaign.id > \'0' AND segments`)
+
+	dateRange := campaignMetricsDateRange()
+	dateRange.Start, dateRange.End = dateRange.End, dateRange.Start
+	_, err = client.FetchCampaignMetrics(context.Background(), "555", dateRange)
+	assert.EqualError(t, err, "invalid date range: 2024-03-01 is before 2024-03-14")
+}
+
+type memoryDeploymentStore struct {
+	deployments []models.CampaignDeployment
+	polledAt    map[string]time.Time
+	deactivated []string
+}
+
+func (s *memoryDeploymentStore) ClaimDueDeployments(ctx context.Context, now, before time.Time) ([]models.CampaignDeployment, error) {
+	if s.polledAt == nil {
+		s.polledAt = make(map[string]time.Time)
+	}
+
+	var due []models.CampaignDeployment
+	for _, deployment := range s.deployments {
+		if polledAt, ok := s.polledAt[deployment.PlatformCampaignID]; ok && !polledAt.Before(before) {
+			continue
+		}
+		s.polledAt[deployment.PlatformCampaignID] = now
+		due = append(due, deployment)
+	}
+	return due, nil
+}
+
+func (s *memoryDeploymentStore) Deactivate(ctx context.Context, platform models.Platform, platformCampaignID string) error {
+	s.deactivated = append(s.deactivated, platformCampaignID)
+	return nil
+}
+
+// fakeMetricsFetcher returns the metrics or error configured for each campaign
+type fakeMetricsFetcher struct {
+	metrics map[string]*models.CampaignMetrics
+	errors  map[string]error
+}
+
+func (f *fakeMetricsFetcher) FetchCampaignMetrics(ctx context.Context, deployment *models.CampaignDeployment) (*models.CampaignMetrics, error) {
+	if err := f.errors[deployment.PlatformCampaignID]; err != nil {
+		return nil, err
+	}
+	return f.metrics[deployment.PlatformCampaignID], nil
+}
+
+type recordingMetricsPublisher struct {
+	events []*models.CampaignMetricsUpdatedEvent
+	// failures are the campaigns whose metrics fail to be published
+	failures map[string]bool
+}
+
+func (p *recordingMetricsPublisher) PublishCampaignMetricsUpdated(ctx context.Context, event *models.CampaignMetricsUpdatedEvent) error {
+	if p.failures[event.CampaignID] {
+		return errors.New("nats: connection closed")
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestMetricsPoller_Poll(t *testing.T) {
+	projectID := uuid.New()
+	assetID := uuid.New()
+	store := &memoryDeploymentStore{deployments: []models.CampaignDeployment{
+		{Platform: models.PlatformGoogleAds, PlatformCampaignID: "555", AssetID: assetID, ProjectID: projectID},
+		{Platform: models.PlatformGoogleAds, PlatformCampaignID: "556", AssetID: uuid.New(), ProjectID: projectID},
+		{Platform: models.PlatformGoogleAds, PlatformCampaignID: "557", AssetID: uuid.New(), ProjectID: projectID},
+	}}
+	fetcher := &fakeMetricsFetcher{
+		metrics: map[string]*models.CampaignMetrics{
+			"555": {CampaignID: "555", Impressions: 1000, Clicks: 25, CostMicros: 4000000},
+		},
+		errors: map[string]error{
+			"556": errors.New("quota exceeded"),
+			"557": models.ErrCampaignNotFound,
+		},
+	}
+	publisher := &recordingMetricsPublisher{}
+
+	poller := campaigns.NewMetricsPoller(store, fetcher, publisher, 15*time.Minute, logrus.New())
+	require.NoError(t, poller.Poll(context.Background()))
+
+	// A failed fetch does not stop the others and removed campaigns stop being polled
+	require.Len(t, publisher.events, 1)
+	event := publisher.events[0]
+	assert.Equal(t, "campaign.metrics_updated", event.EventType)
+	assert.Equal(t, projectID, event.ProjectID)
+	assert.Equal(t, assetID, event.AssetID)
+	assert.Equal(t, models.PlatformGoogleAds, event.Platform)
+	assert.Equal(t, "555", event.CampaignID)
+	assert.Equal(t, int64(1000), event.Metrics.Impressions)
+	assert.Equal(t, int64(4000000), event.Metrics.CostMicros)
+
+	assert.Equal(t, []string{"557"}, store.deactivated)
+
+	// Campaigns polled by this or another instance are not polled again until
+	// they are due
+	require.NoError(t, poller.Poll(context.Background()))
+	assert.Len(t, publisher.events, 1)
+}
+
+func TestMetricsPoller_PollContinuesPastPublishFailures(t *testing.T) {
+	projectID := uuid.New()
+	store := &memoryDeploymentStore{deployments: []models.CampaignDeployment{
+		{Platform: models.PlatformGoogleAds, PlatformCampaignID: "555", AssetID: uuid.New(), ProjectID: projectID},
+		{Platform: models.PlatformGoogleAds, PlatformCampaignID: "556", AssetID: uuid.New(), ProjectID: projectID},
+	}}
+	fetcher := &fakeMetricsFetcher{metrics: map[string]*models.CampaignMetrics{
+		"555": {CampaignID: "555", Impressions: 1000},
+		"556": {CampaignID: "556", Impressions: 2000},
+	}}
+	publisher := &recordingMetricsPublisher{failures: map[string]bool{"555": true}}
+
+	poller := campaigns.NewMetricsPoller(store, fetcher, publisher, 15*time.Minute, logrus.New())
+	assert.EqualError(t, poller.Poll(context.Background()), "failed to publish metrics of 1 campaigns")
+
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, "556", publisher.events[0].CampaignID)
+}
+
+func TestMetaClient_FetchCampaignMetrics(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected models.CampaignMetrics
+	}{
+		{
+			name: "purchases",
+			response: `{
+				"data": [{
+					"impressions": "8000",
+					"clicks": "200",
+					"spend": "50.25",
+					"ctr": "2.5",
+					"cpm": "6.28",
+					"cpc": "0.25",
+					"actions": [
+						{"action_type": "link_click", "value": "180"},
+						{"action_type": "offsite_conversion.fb_pixel_purchase", "value": "4"}
+					],
+					"action_values": [
+						{"action_type": "offsite_conversion.fb_pixel_purchase", "value": "201"}
+					],
+					"date_start": "2024-03-08",
+					"date_stop": "2024-03-14"
+				}],
+				"paging": {"cursors": {"before": "MAZDZD", "after": "MAZDZD"}}
+			}`,
+			expected: models.CampaignMetrics{
+				Impressions: 8000,
+				Clicks:      200,
+				CostMicros:  50250000,
+				Conversions: 4,
+				CTR:         0.025,
+				Revenue:     201,
+				ROAS:        4,
+				StartDate:   "2024-03-08",
+				EndDate:     "2024-03-14",
+			},
+		},
+		{
+			name: "no actions",
+			response: `{
+				"data": [{
+					"impressions": "1500",
+					"clicks": "12",
+					"spend": "9.00",
+					"ctr": "0.8",
+					"date_start": "2024-03-08",
+					"date_stop": "2024-03-14"
+				}]
+			}`,
+			expected: models.CampaignMetrics{
+				Impressions: 1500,
+				Clicks:      12,
+				CostMicros:  9000000,
+				CTR:         0.008,
+				StartDate:   "2024-03-08",
+				EndDate:     "2024-03-14",
+			},
+		},
+		{
+			name: "no purchases",
+			response: `{
+				"data": [{
+					"impressions": "300",
+					"clicks": "3",
+					"spend": "1.50",
+					"ctr": "1",
+					"actions": [{"action_type": "link_click", "value": "3"}],
+					"date_start": "2024-03-08",
+					"date_stop": "2024-03-14"
+				}]
+			}`,
+			expected: models.CampaignMetrics{
+				Impressions: 300,
+				Clicks:      3,
+				CostMicros:  1500000,
+				CTR:         0.01,
+				StartDate:   "2024-03-08",
+				EndDate:     "2024-03-14",
+			},
+		},
+		{
+			name:     "no delivery",
+			response: `{"data": []}`,
+			expected: models.CampaignMetrics{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "GET", r.Method)
+				assert.Equal(t, "/v18.0/120200000000009/insights", r.URL.Path)
+				assert.Equal(t, "impressions,clicks,spend,actions,action_values,ctr", r.URL.Query().Get("fields"))
+				assert.Equal(t, "last_7d", r.URL.Query().Get("date_preset"))
+
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client, err := meta.NewClient(&config.MetaConfig{
+				AccessToken: "token",
+				AdAccountID: "123",
+				APIVersion:  "v18.0",
+				BaseURL:     server.URL,
+			}, logrus.New())
+			require.NoError(t, err)
+
+			metrics, err := client.FetchCampaignMetrics(context.Background(), "120200000000009", "last_7d")
+			require.NoError(t, err)
+			assert.False(t, metrics.FetchedAt.IsZero())
+
+			expected := tt.expected
+			expected.CampaignID = "120200000000009"
+			expected.Platform = models.PlatformMeta
+			expected.FetchedAt = metrics.FetchedAt
+			assert.InDelta(t, expected.CTR, metrics.CTR, 1e-9)
+			expected.CTR = metrics.CTR
+			assert.Equal(t, expected, *metrics)
+		})
+	}
+}
+
+func TestMetaClient_FetchCampaignMetricsReportsAPIErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"message": "Invalid parameter", "type": "OAuthException", "code": 100}}`))
+	}))
+	defer server.Close()
+
+	client, err := meta.NewClient(&config.MetaConfig{APIVersion: "v18.0", BaseURL: server.URL}, logrus.New())
+	require.NoError(t, err)
+
+	_, err = client.FetchCampaignMetrics(context.Background(), "120200000000009", "last_7d")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read ad insights")
+	assert.NotErrorIs(t, err, models.ErrCampaignNotFound)
+}
+
+func TestMetaClient_FetchCampaignMetricsOfDeletedAd(t *testing.T) {
+	for name, body := range map[string]string{
+		"unsupported request": `{"error": {"message": "Unsupported get request. Object with ID '120200000000009' does not exist", "type": "GraphMethodException", "code": 100, "error_subcode": 33}}`,
+		"unknown alias":       `{"error": {"message": "(#803) Some of the aliases you requested do not exist: 120200000000009", "type": "OAuthException", "code": 803}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			client, err := meta.NewClient(&config.MetaConfig{APIVersion: "v18.0", BaseURL: server.URL}, logrus.New())
+			require.NoError(t, err)
+
+			_, err = client.FetchCampaignMetrics(context.Background(), "120200000000009", "last_7d")
+			assert.ErrorIs(t, err, models.ErrCampaignNotFound)
+		})
+	}
+}
@@ -0,0 +1,467 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/googleads"
+	"github.com/zamc/connectors/internal/platforms/meta"
+	"github.com/zamc/connectors/internal/scheduler"
+)
+
+// fakeClock only moves when advanced. Every wait the worker starts is reported on
+// waits so tests know when the worker is idle.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+	waits   chan time.Time
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, waits: make(chan time.Time, 16)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	waiter := fakeWaiter{deadline: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		waiter.ch <- c.now
+	} else {
+		c.waiters = append(c.waiters, waiter)
+	}
+	c.waits <- waiter.deadline
+	return waiter.ch
+}
+
+// Set moves the clock to now and fires every wait that has ended
+func (c *fakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+	pending := c.waiters[:0]
+	for _, waiter := range c.waiters {
+		if waiter.deadline.After(now) {
+			pending = append(pending, waiter)
+		} else {
+			waiter.ch <- now
+		}
+	}
+	c.waiters = pending
+}
+
+// memoryScheduleStore keeps campaign schedules in memory. Its mutex stands in for
+// the row locks of the database, so schedules are claimed by one worker at a time.
+type memoryScheduleStore struct {
+	mu        sync.Mutex
+	schedules map[uuid.UUID]models.CampaignSchedule
+}
+
+func newMemoryScheduleStore(schedules ...models.CampaignSchedule) *memoryScheduleStore {
+	store := &memoryScheduleStore{schedules: map[uuid.UUID]models.CampaignSchedule{}}
+	for _, schedule := range schedules {
+		store.schedules[schedule.ID] = schedule
+	}
+	return store
+}
+
+func (s *memoryScheduleStore) SaveSchedule(ctx context.Context, schedule *models.CampaignSchedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, existing := range s.schedules {
+		if existing.Platform == schedule.Platform && existing.PlatformCampaignID == schedule.PlatformCampaignID {
+			if existing.TenantID != schedule.TenantID {
+				return scheduler.ErrNotFound
+			}
+			delete(s.schedules, id)
+			schedule.ID = id
+		}
+	}
+	if schedule.ID == uuid.Nil {
+		schedule.ID = uuid.New()
+	}
+	schedule.CreatedAt = time.Now()
+	s.schedules[schedule.ID] = *schedule
+	return nil
+}
+
+func (s *memoryScheduleStore) GetSchedule(ctx context.Context, platform models.Platform, platformCampaignID string) (*models.CampaignSchedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, schedule := range s.schedules {
+		if schedule.Platform == platform && schedule.PlatformCampaignID == platformCampaignID {
+			return &schedule, nil
+		}
+	}
+	return nil, scheduler.ErrNotFound
+}
+
+func (s *memoryScheduleStore) DeleteSchedule(ctx context.Context, id uuid.UUID, tenantID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule, ok := s.schedules[id]
+	if !ok || schedule.TenantID != tenantID {
+		return scheduler.ErrNotFound
+	}
+	delete(s.schedules, id)
+	return nil
+}
+
+func (s *memoryScheduleStore) ClaimDueSchedules(ctx context.Context, now time.Time, advance func(schedule *models.CampaignSchedule)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, schedule := range s.schedules {
+		if schedule.NextPauseAt.IsZero() || !schedule.NextPauseAt.After(now) ||
+			schedule.NextResumeAt.IsZero() || !schedule.NextResumeAt.After(now) {
+			advance(&schedule)
+			s.schedules[id] = schedule
+		}
+	}
+	return nil
+}
+
+func (s *memoryScheduleStore) NextFiring(ctx context.Context) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next time.Time
+	for _, schedule := range s.schedules {
+		for _, t := range []time.Time{schedule.NextPauseAt, schedule.NextResumeAt} {
+			if !t.IsZero() && (next.IsZero() || t.Before(next)) {
+				next = t
+			}
+		}
+	}
+	return next, !next.IsZero(), nil
+}
+
+// campaignCall is a pause or resume received by recordingController
+type campaignCall struct {
+	Action     string
+	TenantID   string
+	Platform   models.Platform
+	CampaignID string
+	At         time.Time
+}
+
+// recordingController reports every pause and resume with the fake clock's time
+type recordingController struct {
+	clock    *fakeClock
+	tenantID string
+	platform models.Platform
+	calls    chan campaignCall
+}
+
+func (c *recordingController) PauseCampaign(ctx context.Context, platformCampaignID string) error {
+	c.calls <- campaignCall{"pause", c.tenantID, c.platform, platformCampaignID, c.clock.Now()}
+	return nil
+}
+
+func (c *recordingController) ResumeCampaign(ctx context.Context, platformCampaignID string) error {
+	c.calls <- campaignCall{"resume", c.tenantID, c.platform, platformCampaignID, c.clock.Now()}
+	return nil
+}
+
+// schedulerPollInterval keeps the workers of the tests from polling between the
+// firings they check
+const schedulerPollInterval = 30 * 24 * time.Hour
+
+func startSchedulerWorker(t *testing.T, clock *fakeClock, store scheduler.ScheduleStore) (*scheduler.SchedulerWorker, chan campaignCall) {
+	calls := make(chan campaignCall, 16)
+	return runSchedulerWorker(t, clock, store, calls), calls
+}
+
+// runSchedulerWorker runs a worker reporting its pauses and resumes on calls
+func runSchedulerWorker(t *testing.T, clock *fakeClock, store scheduler.ScheduleStore, calls chan campaignCall) *scheduler.SchedulerWorker {
+	controllers := func(ctx context.Context, tenantID string, platform models.Platform) (scheduler.CampaignController, error) {
+		return &recordingController{clock: clock, tenantID: tenantID, platform: platform, calls: calls}, nil
+	}
+
+	worker := scheduler.NewSchedulerWorker(store, controllers, logrus.New())
+	worker.SetClock(clock)
+	worker.SetPollInterval(schedulerPollInterval)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, worker.Run(ctx))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return worker
+}
+
+func nextWait(t *testing.T, clock *fakeClock) time.Time {
+	select {
+	case deadline := <-clock.waits:
+		return deadline
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not wait for the next activation")
+		return time.Time{}
+	}
+}
+
+func nextCall(t *testing.T, calls chan campaignCall) campaignCall {
+	select {
+	case call := <-calls:
+		return call
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not change the campaign")
+		return campaignCall{}
+	}
+}
+
+func TestSchedulerWorker_FiresAtScheduledTimes(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// Wednesday 15 October 2025, 08:00 in New York
+	clock := newFakeClock(time.Date(2025, 10, 15, 8, 0, 0, 0, newYork).UTC())
+	tenantID := uuid.New().String()
+	store := newMemoryScheduleStore(models.CampaignSchedule{
+		ID:                 uuid.New(),
+		TenantID:           tenantID,
+		Platform:           models.PlatformMeta,
+		PlatformCampaignID: "120200000000001",
+		PauseCron:          "0 18 * * 1-5",
+		ResumeCron:         "0 9 * * 1-5",
+		Timezone:           "America/New_York",
+	})
+
+	_, calls := startSchedulerWorker(t, clock, store)
+
+	resumeAt := time.Date(2025, 10, 15, 9, 0, 0, 0, newYork)
+	assert.True(t, resumeAt.Equal(nextWait(t, clock)))
+
+	// Nothing fires before the scheduled time
+	clock.Set(resumeAt.Add(-time.Second))
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, calls)
+
+	clock.Set(resumeAt)
+	call := nextCall(t, calls)
+	assert.Equal(t, "resume", call.Action)
+	assert.Equal(t, tenantID, call.TenantID)
+	assert.Equal(t, models.PlatformMeta, call.Platform)
+	assert.Equal(t, "120200000000001", call.CampaignID)
+	assert.True(t, resumeAt.Equal(call.At))
+
+	pauseAt := time.Date(2025, 10, 15, 18, 0, 0, 0, newYork)
+	assert.True(t, pauseAt.Equal(nextWait(t, clock)))
+
+	clock.Set(pauseAt)
+	call = nextCall(t, calls)
+	assert.Equal(t, "pause", call.Action)
+	assert.True(t, pauseAt.Equal(call.At))
+
+	// Of the firings missed until Friday evening only the last, a pause, is
+	// applied. The campaign then stays paused until Monday morning.
+	clock.Set(time.Date(2025, 10, 17, 18, 0, 0, 0, newYork))
+	nextWait(t, clock)
+	call = nextCall(t, calls)
+	assert.Equal(t, "pause", call.Action)
+	assert.True(t, time.Date(2025, 10, 20, 9, 0, 0, 0, newYork).Equal(nextWait(t, clock)))
+	assert.Empty(t, calls)
+}
+
+func TestSchedulerWorker_ScheduleAndDeleteCampaign(t *testing.T) {
+	start := time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	store := newMemoryScheduleStore()
+
+	worker, calls := startSchedulerWorker(t, clock, store)
+
+	// Without schedules the worker only polls
+	assert.True(t, start.Add(schedulerPollInterval).Equal(nextWait(t, clock)))
+
+	tenantID := uuid.New().String()
+	ctx := context.Background()
+	schedule := &models.CampaignSchedule{
+		Platform:           models.PlatformGoogleAds,
+		PlatformCampaignID: "987654321",
+		PauseCron:          "30 12 * * *",
+		ResumeCron:         "0 13 * * *",
+		Timezone:           "UTC",
+	}
+	require.NoError(t, worker.ScheduleCampaign(ctx, tenantID, schedule))
+	assert.Equal(t, tenantID, schedule.TenantID)
+	assert.NotEqual(t, uuid.Nil, schedule.ID)
+
+	assert.True(t, start.Add(30*time.Minute).Equal(nextWait(t, clock)))
+
+	// Another tenant cannot take over the campaign
+	other := *schedule
+	other.ID = uuid.Nil
+	assert.ErrorIs(t, worker.ScheduleCampaign(ctx, uuid.New().String(), &other), scheduler.ErrNotFound)
+
+	clock.Set(start.Add(30 * time.Minute))
+	call := nextCall(t, calls)
+	assert.Equal(t, "pause", call.Action)
+	assert.Equal(t, models.PlatformGoogleAds, call.Platform)
+	nextWait(t, clock)
+
+	assert.ErrorIs(t, worker.DeleteCampaignSchedule(context.Background(), schedule.ID, uuid.New().String()), scheduler.ErrNotFound)
+	require.NoError(t, worker.DeleteCampaignSchedule(context.Background(), schedule.ID, tenantID))
+
+	// The deleted schedule no longer fires
+	clock.Set(start.Add(2 * time.Hour))
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, calls)
+}
+
+func TestSchedulerWorker_RejectsInvalidSchedules(t *testing.T) {
+	worker := scheduler.NewSchedulerWorker(newMemoryScheduleStore(), nil, logrus.New())
+
+	tests := []struct {
+		name     string
+		schedule models.CampaignSchedule
+	}{
+		{"invalid pause cron", models.CampaignSchedule{PauseCron: "0 25 * * *", ResumeCron: "0 9 * * *", Timezone: "UTC"}},
+		{"invalid resume cron", models.CampaignSchedule{PauseCron: "0 18 * * *", ResumeCron: "every morning", Timezone: "UTC"}},
+		{"invalid timezone", models.CampaignSchedule{PauseCron: "0 18 * * *", ResumeCron: "0 9 * * *", Timezone: "Mars/Olympus_Mons"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := tt.schedule
+			assert.Error(t, worker.ScheduleCampaign(context.Background(), uuid.New().String(), &schedule))
+		})
+	}
+
+	t.Run("missing tenant", func(t *testing.T) {
+		schedule := models.CampaignSchedule{PauseCron: "0 18 * * *", ResumeCron: "0 9 * * *", Timezone: "UTC"}
+		assert.EqualError(t, worker.ScheduleCampaign(context.Background(), "", &schedule), "tenant id is required")
+	})
+}
+
+func TestSchedulerWorker_FiresOnceAcrossInstances(t *testing.T) {
+	start := time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	store := newMemoryScheduleStore(models.CampaignSchedule{
+		ID:                 uuid.New(),
+		TenantID:           uuid.New().String(),
+		Platform:           models.PlatformMeta,
+		PlatformCampaignID: "120200000000001",
+		PauseCron:          "30 12 * * *",
+		ResumeCron:         "0 13 * * *",
+		Timezone:           "UTC",
+	})
+
+	// Two instances run the same schedules
+	calls := make(chan campaignCall, 16)
+	runSchedulerWorker(t, clock, store, calls)
+	runSchedulerWorker(t, clock, store, calls)
+	nextWait(t, clock)
+	nextWait(t, clock)
+
+	// The firing is claimed by one of them
+	clock.Set(start.Add(30 * time.Minute))
+	call := nextCall(t, calls)
+	assert.Equal(t, "pause", call.Action)
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, calls)
+}
+
+func TestGoogleAdsClient_PauseAndResumeCampaign(t *testing.T) {
+	var statuses []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v16/customers/1234567890/campaigns:mutate", r.URL.Path)
+
+		var body struct {
+			Operations []struct {
+				Update struct {
+					ResourceName string `json:"resourceName"`
+					Status       string `json:"status"`
+				} `json:"update"`
+				UpdateMask string `json:"updateMask"`
+			} `json:"operations"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Len(t, body.Operations, 1)
+		assert.Equal(t, "customers/1234567890/campaigns/555", body.Operations[0].Update.ResourceName)
+		assert.Equal(t, "status", body.Operations[0].UpdateMask)
+		statuses = append(statuses, body.Operations[0].Update.Status)
+
+		w.Write([]byte(`{"results": [{"resourceName": "customers/1234567890/campaigns/555"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := googleads.NewClient(&config.GoogleAdsConfig{
+		CustomerID: "123-456-7890",
+		BaseURL:    server.URL + "/v16",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	require.NoError(t, client.PauseCampaign(context.Background(), "555"))
+	require.NoError(t, client.ResumeCampaign(context.Background(), "555"))
+	assert.Equal(t, []string{"PAUSED", "ENABLED"}, statuses)
+}
+
+func TestMetaClient_PauseAndResumeCampaign(t *testing.T) {
+	var statuses []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/v18.0/120200000000001", r.URL.Path)
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		statuses = append(statuses, body["status"])
+
+		w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	client, err := meta.NewClient(&config.MetaConfig{
+		AccessToken: "token",
+		AdAccountID: "123",
+		APIVersion:  "v18.0",
+		BaseURL:     server.URL,
+	}, logrus.New())
+	require.NoError(t, err)
+
+	require.NoError(t, client.PauseCampaign(context.Background(), "120200000000001"))
+	require.NoError(t, client.ResumeCampaign(context.Background(), "120200000000001"))
+	assert.Equal(t, []string{"PAUSED", "ACTIVE"}, statuses)
+}
+
+func TestMetaClient_ScheduleCampaignPauseResumeRequiresScheduler(t *testing.T) {
+	client, err := meta.NewClient(&config.MetaConfig{APIVersion: "v18.0"}, logrus.New())
+	require.NoError(t, err)
+
+	err = client.ScheduleCampaignPauseResume(context.Background(), uuid.New().String(), "120200000000001", "0 18 * * *", "0 9 * * *", "UTC")
+	assert.EqualError(t, err, "campaign scheduling is not configured")
+}
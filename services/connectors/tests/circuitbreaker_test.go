@@ -0,0 +1,321 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/circuitbreaker"
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/mocks"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/ratelimit"
+	"github.com/zamc/connectors/internal/service"
+)
+
+var errPlatformDown = errors.New("503 service unavailable")
+
+func newTestBreaker(now *time.Time, transitions *[]string) *circuitbreaker.Breaker {
+	breaker := circuitbreaker.New(circuitbreaker.Config{
+		FailureThreshold: 3,
+		WindowDuration:   time.Minute,
+		RecoveryTimeout:  30 * time.Second,
+		IsFailure: func(err error) bool {
+			return !errors.Is(err, ratelimit.ErrQuotaExhausted)
+		},
+		OnStateChange: func(from, to circuitbreaker.State) {
+			*transitions = append(*transitions, from.String()+" -> "+to.String())
+		},
+	})
+	breaker.SetClock(func() time.Time { return *now })
+	return breaker
+}
+
+func fail() error    { return errPlatformDown }
+func succeed() error { return nil }
+
+func TestCircuitBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var transitions []string
+	breaker := newTestBreaker(&now, &transitions)
+
+	// A success resets the count of consecutive failures
+	assert.ErrorIs(t, breaker.Execute(fail), errPlatformDown)
+	assert.ErrorIs(t, breaker.Execute(fail), errPlatformDown)
+	assert.NoError(t, breaker.Execute(succeed))
+	assert.ErrorIs(t, breaker.Execute(fail), errPlatformDown)
+	assert.ErrorIs(t, breaker.Execute(fail), errPlatformDown)
+	assert.Equal(t, circuitbreaker.StateClosed, breaker.State())
+
+	// Errors that are not failures of the platform do not count
+	quotaErr := &ratelimit.QuotaError{Platform: models.PlatformMeta, AccountID: "act_1"}
+	assert.ErrorIs(t, breaker.Execute(func() error { return quotaErr }), ratelimit.ErrQuotaExhausted)
+	assert.Equal(t, circuitbreaker.StateClosed, breaker.State())
+
+	assert.ErrorIs(t, breaker.Execute(fail), errPlatformDown)
+	assert.Equal(t, circuitbreaker.StateOpen, breaker.State())
+
+	// Calls fail at once while the circuit is open
+	called := false
+	err := breaker.Execute(func() error {
+		called = true
+		return nil
+	})
+	assert.ErrorIs(t, err, circuitbreaker.ErrCircuitOpen)
+	assert.False(t, called)
+	assert.Equal(t, []string{"closed -> open"}, transitions)
+}
+
+func TestCircuitBreaker_FailuresOutsideWindowDoNotCount(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var transitions []string
+	breaker := newTestBreaker(&now, &transitions)
+
+	assert.Error(t, breaker.Execute(fail))
+	assert.Error(t, breaker.Execute(fail))
+
+	now = now.Add(2 * time.Minute)
+	assert.Error(t, breaker.Execute(fail))
+	assert.Error(t, breaker.Execute(fail))
+	assert.Equal(t, circuitbreaker.StateClosed, breaker.State())
+
+	assert.Error(t, breaker.Execute(fail))
+	assert.Equal(t, circuitbreaker.StateOpen, breaker.State())
+}
+
+func TestCircuitBreaker_HalfOpenTrialCloses(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var transitions []string
+	breaker := newTestBreaker(&now, &transitions)
+
+	for i := 0; i < 3; i++ {
+		assert.Error(t, breaker.Execute(fail))
+	}
+	require.Equal(t, circuitbreaker.StateOpen, breaker.State())
+
+	now = now.Add(29 * time.Second)
+	assert.Equal(t, circuitbreaker.StateOpen, breaker.State())
+
+	now = now.Add(time.Second)
+	assert.Equal(t, circuitbreaker.StateHalfOpen, breaker.State())
+
+	// Only one trial call is let through; calls made meanwhile are refused
+	trial := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- breaker.Execute(func() error {
+			<-trial
+			return nil
+		})
+	}()
+	require.Eventually(t, func() bool {
+		return errors.Is(breaker.Execute(succeed), circuitbreaker.ErrCircuitOpen)
+	}, time.Second, time.Millisecond)
+	close(trial)
+	require.NoError(t, <-done)
+
+	assert.Equal(t, circuitbreaker.StateClosed, breaker.State())
+	assert.NoError(t, breaker.Execute(succeed))
+	assert.Equal(t, []string{"closed -> open", "open -> half-open", "half-open -> closed"}, transitions)
+}
+
+func TestCircuitBreaker_HalfOpenTrialReopens(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var transitions []string
+	breaker := newTestBreaker(&now, &transitions)
+
+	for i := 0; i < 3; i++ {
+		assert.Error(t, breaker.Execute(fail))
+	}
+
+	now = now.Add(30 * time.Second)
+	assert.ErrorIs(t, breaker.Execute(fail), errPlatformDown)
+	assert.Equal(t, circuitbreaker.StateOpen, breaker.State())
+
+	// The recovery timeout starts over from the failed trial
+	now = now.Add(29 * time.Second)
+	assert.ErrorIs(t, breaker.Execute(succeed), circuitbreaker.ErrCircuitOpen)
+
+	assert.Equal(t, []string{"closed -> open", "open -> half-open", "half-open -> open"}, transitions)
+}
+
+// flakyPlatformClient is a platform client whose deployments fail with a server
+// error while down is set, or with failure when it is set
+type flakyPlatformClient struct {
+	mu      sync.Mutex
+	down    bool
+	failure error
+	calls   int
+}
+
+func (c *flakyPlatformClient) DeployAsset(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls++
+	if c.down {
+		return nil, &models.APIError{StatusCode: 503, Message: "service unavailable"}
+	}
+	if c.failure != nil {
+		return nil, c.failure
+	}
+	return &models.DeploymentResult{
+		AssetID:    request.AssetID,
+		Platform:   request.Platform,
+		Status:     models.DeploymentStatusSuccess,
+		PlatformID: "ad_1",
+		DeployedAt: time.Now(),
+	}, nil
+}
+
+func (c *flakyPlatformClient) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+func (c *flakyPlatformClient) setDown(down bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.down = down
+}
+
+func (c *flakyPlatformClient) callCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+func TestDeploymentService_CircuitBreakerStopsRetries(t *testing.T) {
+	natsClient := mocks.NewMockNATSClient()
+	deploymentService := service.NewDeploymentService(mocks.NewMockGoogleAdsClient(), mocks.NewMockMetaClient(), natsClient, nil, nil, &config.DeploymentConfig{
+		MaxRetryAttempts:        3,
+		RetryDelay:              time.Millisecond,
+		Timeout:                 time.Second,
+		CircuitFailureThreshold: 2,
+		CircuitWindow:           time.Minute,
+		CircuitRecoveryTimeout:  50 * time.Millisecond,
+	}, logrus.New())
+
+	tiktok := &flakyPlatformClient{down: true}
+	deploymentService.SetTikTokClient(tiktok)
+
+	newEvent := func() *models.AssetStatusChangedEvent {
+		return &models.AssetStatusChangedEvent{
+			EventType:   "asset.status_changed",
+			AssetID:     uuid.New(),
+			ProjectID:   uuid.New(),
+			Status:      models.AssetStatusApproved,
+			ContentType: models.ContentTypeSocialMedia,
+			Title:       "Trail shoes",
+			Metadata: models.Metadata{
+				Platforms: []models.Platform{models.PlatformTikTok},
+				Budget:    50,
+			},
+		}
+	}
+	deadLetters := func() int {
+		count := 0
+		for _, event := range natsClient.GetPublishedEvents() {
+			if e, ok := event.(*models.AssetStatusChangedEvent); ok && e.Status == models.AssetStatusApproved {
+				count++
+			}
+		}
+		return count
+	}
+
+	// The circuit opens after the second attempt, so the third is not made
+	require.NoError(t, deploymentService.HandleAssetStatusChanged(context.Background(), newEvent()))
+	assert.Equal(t, 2, tiktok.callCount())
+	assert.Equal(t, "open", deploymentService.CircuitStates()[models.PlatformTikTok])
+	assert.Equal(t, "closed", deploymentService.CircuitStates()[models.PlatformMeta])
+	assert.Equal(t, 1, deadLetters())
+
+	// Deployments fail without calling the platform while the circuit is open,
+	// and are dead-lettered for replay
+	require.NoError(t, deploymentService.HandleAssetStatusChanged(context.Background(), newEvent()))
+	assert.Equal(t, 2, tiktok.callCount())
+	assert.Equal(t, 2, deadLetters())
+
+	// Once the recovery timeout has passed a trial deployment closes the circuit
+	tiktok.setDown(false)
+	require.Eventually(t, func() bool {
+		return deploymentService.CircuitStates()[models.PlatformTikTok] == "half-open"
+	}, time.Second, 5*time.Millisecond)
+	require.NoError(t, deploymentService.HandleAssetStatusChanged(context.Background(), newEvent()))
+	assert.Equal(t, 3, tiktok.callCount())
+	assert.Equal(t, "closed", deploymentService.CircuitStates()[models.PlatformTikTok])
+	assert.Equal(t, 2, deadLetters())
+}
+
+func newBreakerTestService(natsClient *mocks.MockNATSClient) *service.DeploymentService {
+	return service.NewDeploymentService(mocks.NewMockGoogleAdsClient(), mocks.NewMockMetaClient(), natsClient, nil, nil, &config.DeploymentConfig{
+		MaxRetryAttempts:        3,
+		RetryDelay:              time.Millisecond,
+		Timeout:                 time.Second,
+		CircuitFailureThreshold: 2,
+		CircuitWindow:           time.Minute,
+		CircuitRecoveryTimeout:  time.Minute,
+	}, logrus.New())
+}
+
+func newTikTokEvent(tenantID string) *models.AssetStatusChangedEvent {
+	return &models.AssetStatusChangedEvent{
+		EventType:   "asset.status_changed",
+		AssetID:     uuid.New(),
+		ProjectID:   uuid.New(),
+		TenantID:    tenantID,
+		Status:      models.AssetStatusApproved,
+		ContentType: models.ContentTypeSocialMedia,
+		Title:       "Trail shoes",
+		Metadata: models.Metadata{
+			Platforms: []models.Platform{models.PlatformTikTok},
+			Budget:    50,
+		},
+	}
+}
+
+func TestDeploymentService_CircuitBreakerIgnoresRequestErrors(t *testing.T) {
+	deploymentService := newBreakerTestService(mocks.NewMockNATSClient())
+
+	tiktok := &flakyPlatformClient{failure: &models.APIError{StatusCode: 400, Message: "invalid creative"}}
+	deploymentService.SetTikTokClient(tiktok)
+
+	// Errors about the request do not open the circuit, however many there are
+	for i := 0; i < 3; i++ {
+		require.NoError(t, deploymentService.HandleAssetStatusChanged(context.Background(), newTikTokEvent("")))
+	}
+	assert.Equal(t, 9, tiktok.callCount())
+	assert.Equal(t, "closed", deploymentService.CircuitStates()[models.PlatformTikTok])
+
+	// Throttling does
+	tiktok.failure = &models.APIError{StatusCode: 429, Message: "too many requests"}
+	require.NoError(t, deploymentService.HandleAssetStatusChanged(context.Background(), newTikTokEvent("")))
+	assert.Equal(t, 11, tiktok.callCount())
+	assert.Equal(t, "open", deploymentService.CircuitStates()[models.PlatformTikTok])
+}
+
+func TestDeploymentService_CircuitBreakerPerTenant(t *testing.T) {
+	deploymentService := newBreakerTestService(mocks.NewMockNATSClient())
+
+	tiktok := &flakyPlatformClient{down: true}
+	deploymentService.SetTikTokClient(tiktok)
+
+	// The circuit of the failing tenant opens
+	require.NoError(t, deploymentService.HandleAssetStatusChanged(context.Background(), newTikTokEvent("tenant-a")))
+	assert.Equal(t, 2, tiktok.callCount())
+	assert.Equal(t, "open", deploymentService.CircuitStates()[models.PlatformTikTok])
+
+	require.NoError(t, deploymentService.HandleAssetStatusChanged(context.Background(), newTikTokEvent("tenant-a")))
+	assert.Equal(t, 2, tiktok.callCount())
+
+	// Other tenants still deploy to the platform
+	tiktok.setDown(false)
+	require.NoError(t, deploymentService.HandleAssetStatusChanged(context.Background(), newTikTokEvent("tenant-b")))
+	assert.Equal(t, 3, tiktok.callCount())
+}
@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/nats"
+)
+
+// blogPostEvent returns an approved asset event whose content is size bytes of a
+// blog post
+func blogPostEvent(size int) *models.AssetStatusChangedEvent {
+	event := approvedAssetEvent()
+	event.ContentType = models.ContentTypeBlogPost
+	event.Title = "Ten trails to run this spring"
+	paragraph := "Spring is the best season to explore the trails around the city, with mild weather and long days. "
+	event.Content = strings.Repeat(paragraph, size/len(paragraph)+1)[:size]
+	return event
+}
+
+// connectNATS connects to an in-process NATS server without JetStream
+func connectNATS(tb testing.TB) *natsgo.Conn {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	s := natsserver.RunServer(&opts)
+	tb.Cleanup(s.Shutdown)
+
+	conn, err := natsgo.Connect(s.ClientURL())
+	require.NoError(tb, err)
+	tb.Cleanup(conn.Close)
+	return conn
+}
+
+func TestCompressionTransport_Publish(t *testing.T) {
+	conn := connectNATS(t)
+	messages := make(chan *natsgo.Msg, 1)
+	_, err := conn.ChanSubscribe("zamc.events.asset.status_changed", messages)
+	require.NoError(t, err)
+
+	transport, err := nats.NewCompressionTransport(conn, &config.NATSConfig{EnableCompression: true, CompressionLevel: 6})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(blogPostEvent(10 * 1024))
+	require.NoError(t, err)
+	require.NoError(t, transport.Publish("zamc.events.asset.status_changed", data))
+
+	select {
+	case msg := <-messages:
+		assert.Equal(t, "gzip", msg.Header.Get(nats.ContentEncodingHeader))
+		assert.Less(t, len(msg.Data), len(data)/4)
+
+		decoded, err := nats.DecodeData(msg)
+		require.NoError(t, err)
+		assert.Equal(t, data, decoded)
+	case <-time.After(time.Second):
+		t.Fatal("message was not received")
+	}
+}
+
+func TestCompressionTransport_Disabled(t *testing.T) {
+	conn := connectNATS(t)
+	messages := make(chan *natsgo.Msg, 1)
+	_, err := conn.ChanSubscribe("zamc.events.asset.status_changed", messages)
+	require.NoError(t, err)
+
+	transport, err := nats.NewCompressionTransport(conn, &config.NATSConfig{})
+	require.NoError(t, err)
+	require.NoError(t, transport.Publish("zamc.events.asset.status_changed", []byte(`{"status":"approved"}`)))
+
+	select {
+	case msg := <-messages:
+		assert.Empty(t, msg.Header.Get(nats.ContentEncodingHeader))
+		assert.Equal(t, `{"status":"approved"}`, string(msg.Data))
+
+		decoded, err := nats.DecodeData(msg)
+		require.NoError(t, err)
+		assert.Equal(t, msg.Data, decoded)
+	case <-time.After(time.Second):
+		t.Fatal("message was not received")
+	}
+}
+
+func TestNewCompressionTransport_InvalidLevel(t *testing.T) {
+	_, err := nats.NewCompressionTransport(nil, &config.NATSConfig{EnableCompression: true, CompressionLevel: 12})
+	assert.ErrorContains(t, err, "invalid NATS compression level")
+
+	// The level does not matter without compression
+	_, err = nats.NewCompressionTransport(nil, &config.NATSConfig{CompressionLevel: 12})
+	assert.NoError(t, err)
+}
+
+func TestDecodeData_UnsupportedEncoding(t *testing.T) {
+	msg := natsgo.NewMsg("zamc.events.asset.status_changed")
+	msg.Header.Set(nats.ContentEncodingHeader, "br")
+
+	_, err := nats.DecodeData(msg)
+	assert.ErrorContains(t, err, `unsupported message encoding "br"`)
+}
+
+func TestDecodeData_RejectsOversizedData(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write(make([]byte, nats.MaxDecodedSize+1))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	msg := natsgo.NewMsg("zamc.events.asset.status_changed")
+	msg.Header.Set(nats.ContentEncodingHeader, nats.GzipEncoding)
+	msg.Data = compressed.Bytes()
+
+	_, err = nats.DecodeData(msg)
+	assert.ErrorContains(t, err, "decompressed message exceeds")
+}
+
+func TestSubscribeToAssetStatusChanged_DecompressesEvents(t *testing.T) {
+	s := runJetStreamServer(t)
+	client, err := nats.NewClient(&config.NATSConfig{
+		URL:               s.ClientURL(),
+		SubjectPrefix:     "zamc",
+		QueueGroup:        "connectors",
+		EnableCompression: true,
+		CompressionLevel:  -1,
+	}, logrus.New())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	handler := &recordingEventHandler{}
+	defer subscribeStatusChanged(t, client, handler)()
+
+	event := blogPostEvent(100 * 1024)
+	require.NoError(t, client.PublishAssetStatusChanged(context.Background(), event))
+
+	require.Eventually(t, func() bool {
+		_, handled := handler.state()
+		return len(handled) == 1
+	}, 5*time.Second, 20*time.Millisecond)
+
+	_, handled := handler.state()
+	assert.Equal(t, []uuid.UUID{event.AssetID}, handled)
+}
+
+// BenchmarkCompressionTransport_Publish compares publishing blog post events of
+// 1KB, 10KB and 100KB with and without compression
+func BenchmarkCompressionTransport_Publish(b *testing.B) {
+	conn := connectNATS(b)
+
+	for _, size := range []int{1024, 10 * 1024, 100 * 1024} {
+		data, err := json.Marshal(blogPostEvent(size))
+		require.NoError(b, err)
+
+		for _, compressed := range []bool{false, true} {
+			transport, err := nats.NewCompressionTransport(conn, &config.NATSConfig{EnableCompression: compressed, CompressionLevel: -1})
+			require.NoError(b, err)
+
+			name := fmt.Sprintf("%dKB/uncompressed", size/1024)
+			if compressed {
+				name = fmt.Sprintf("%dKB/gzip", size/1024)
+			}
+			b.Run(name, func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(len(data)))
+				for i := 0; i < b.N; i++ {
+					if err := transport.Publish("bench.asset.status_changed", data); err != nil {
+						b.Fatal(err)
+					}
+				}
+				if err := conn.Flush(); err != nil {
+					b.Fatal(err)
+				}
+			})
+		}
+	}
+}
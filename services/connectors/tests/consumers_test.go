@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/nats"
+)
+
+type noopQualityScoreHandler struct{}
+
+func (noopQualityScoreHandler) FetchKeywordQualityScores(ctx context.Context, fetch *models.KeywordQualityScoreFetch) error {
+	return nil
+}
+
+// runJetStreamServer starts an in-process NATS server with JetStream enabled
+func runJetStreamServer(t *testing.T) *server.Server {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	opts.JetStream = true
+	opts.StoreDir = t.TempDir()
+
+	s := natsserver.RunServer(&opts)
+	t.Cleanup(s.Shutdown)
+
+	return s
+}
+
+func newConsumersClient(t *testing.T, s *server.Server) *nats.Client {
+	client, err := nats.NewClient(&config.NATSConfig{
+		URL:           s.ClientURL(),
+		SubjectPrefix: "zamc",
+		QueueGroup:    "connectors",
+	}, logrus.New())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+// newWorkStream creates a stream with a durable pull consumer holding three messages,
+// one of them acknowledged
+func newWorkStream(t *testing.T, s *server.Server) {
+	conn, err := natsgo.Connect(s.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	js, err := conn.JetStream()
+	require.NoError(t, err)
+
+	_, err = js.AddStream(&natsgo.StreamConfig{Name: "WORK", Subjects: []string{"work.>"}})
+	require.NoError(t, err)
+	_, err = js.AddConsumer("WORK", &natsgo.ConsumerConfig{Durable: "worker", AckPolicy: natsgo.AckExplicitPolicy})
+	require.NoError(t, err)
+	_, err = js.AddConsumer("WORK", &natsgo.ConsumerConfig{Durable: "auditor", AckPolicy: natsgo.AckExplicitPolicy})
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		_, err := js.Publish("work.items", []byte("item"))
+		require.NoError(t, err)
+	}
+
+	subscription, err := js.PullSubscribe("work.items", "worker", natsgo.Bind("WORK", "worker"))
+	require.NoError(t, err)
+	msgs, err := subscription.Fetch(1, natsgo.MaxWait(5*time.Second))
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	require.NoError(t, msgs[0].AckSync())
+}
+
+func TestListConsumers(t *testing.T) {
+	s := runJetStreamServer(t)
+	client := newConsumersClient(t, s)
+	newWorkStream(t, s)
+
+	consumers, err := client.ListConsumers(context.Background(), "WORK")
+	require.NoError(t, err)
+	require.Len(t, consumers, 2)
+
+	assert.Equal(t, "auditor", consumers[0].Name)
+	assert.Equal(t, uint64(4), consumers[0].NumPending)
+	assert.Nil(t, consumers[0].LastAckTime)
+
+	assert.Equal(t, "worker", consumers[1].Name)
+	assert.Equal(t, uint64(3), consumers[1].NumPending)
+	assert.Equal(t, 0, consumers[1].NumRedelivered)
+	require.NotNil(t, consumers[1].LastAckTime)
+	assert.WithinDuration(t, time.Now(), *consumers[1].LastAckTime, time.Minute)
+}
+
+func TestListConsumersOfDelayedStream(t *testing.T) {
+	s := runJetStreamServer(t)
+	client := newConsumersClient(t, s)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.SubscribeToKeywordQualityScoreFetches(ctx, noopQualityScoreHandler{})
+
+	require.Eventually(t, func() bool {
+		consumers, err := client.ListConsumers(context.Background(), nats.DelayedStream)
+		return err == nil && len(consumers) == 1 &&
+			consumers[0].Name == "connectors_zamc_delayed_keywords_quality_scores"
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestListConsumersUnknownStream(t *testing.T) {
+	client := newConsumersClient(t, runJetStreamServer(t))
+
+	_, err := client.ListConsumers(context.Background(), "MISSING")
+	assert.ErrorIs(t, err, nats.ErrStreamNotFound)
+}
+
+func TestDeleteConsumer(t *testing.T) {
+	s := runJetStreamServer(t)
+	client := newConsumersClient(t, s)
+	newWorkStream(t, s)
+
+	require.NoError(t, client.DeleteConsumer(context.Background(), "WORK", "worker"))
+
+	consumers, err := client.ListConsumers(context.Background(), "WORK")
+	require.NoError(t, err)
+	require.Len(t, consumers, 1)
+	assert.Equal(t, "auditor", consumers[0].Name)
+
+	err = client.DeleteConsumer(context.Background(), "WORK", "worker")
+	assert.ErrorIs(t, err, nats.ErrConsumerNotFound)
+
+	err = client.DeleteConsumer(context.Background(), "MISSING", "worker")
+	assert.ErrorIs(t, err, nats.ErrStreamNotFound)
+}
@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/googleads"
+	"github.com/zamc/connectors/internal/platforms/meta"
+)
+
+func estimateRequest(platform models.Platform, budget float64) *models.DeploymentRequest {
+	return &models.DeploymentRequest{
+		AssetID:     uuid.New(),
+		ProjectID:   uuid.New(),
+		StrategyID:  uuid.New(),
+		Platform:    platform,
+		ContentType: models.ContentTypeBlogPost,
+		Metadata: models.Metadata{
+			Budget:   budget,
+			Keywords: []string{"running shoes", "trail shoes"},
+			Demographics: models.Demographics{
+				AgeMin:    18,
+				AgeMax:    45,
+				Locations: []string{"US"},
+			},
+		},
+		Mode: models.DeploymentModeDryRun,
+	}
+}
+
+func TestGoogleAdsClient_EstimateCampaignCost(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/v16/customers/1234567890:generateKeywordForecastMetrics", r.URL.Path)
+		assert.Equal(t, "dev-token", r.Header.Get("developer-token"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		w.Write([]byte(`{
+			"campaignForecastMetrics": {
+				"impressions": 15234.7,
+				"clickThroughRate": 0.05,
+				"averageCpcMicros": "420000",
+				"clicks": 761.2,
+				"costMicros": "319704000",
+				"conversions": 12.5
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	client, err := googleads.NewClient(&config.GoogleAdsConfig{
+		DeveloperToken: "dev-token",
+		CustomerID:     "123-456-7890",
+		BaseURL:        server.URL + "/v16",
+		Currency:       "EUR",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	estimate, err := client.EstimateCampaignCost(context.Background(), estimateRequest(models.PlatformGoogleAds, 50))
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(15234), estimate.EstimatedImpressions)
+	assert.Equal(t, int64(761), estimate.EstimatedClicks)
+	assert.InDelta(t, 319.704, estimate.EstimatedSpend, 0.0001)
+	assert.Equal(t, "EUR", estimate.Currency)
+	assert.InDelta(t, 761.2/861.2, estimate.Confidence, 0.0001)
+
+	campaign := body["campaign"].(map[string]interface{})
+	bidding := campaign["biddingStrategy"].(map[string]interface{})["maximizeClicksBiddingStrategy"].(map[string]interface{})
+	assert.Equal(t, "50000000", bidding["dailyTargetSpendMicros"])
+	keywords := campaign["adGroups"].([]interface{})[0].(map[string]interface{})["biddableKeywords"].([]interface{})
+	assert.Len(t, keywords, 2)
+	assert.Equal(t, "EUR", body["currencyCode"])
+}
+
+func TestGoogleAdsClient_EstimateCampaignCostRequiresKeywords(t *testing.T) {
+	client, err := googleads.NewClient(&config.GoogleAdsConfig{CustomerID: "1234567890"}, logrus.New())
+	require.NoError(t, err)
+
+	request := estimateRequest(models.PlatformGoogleAds, 50)
+	request.Metadata.Keywords = nil
+
+	_, err = client.EstimateCampaignCost(context.Background(), request)
+	assert.Error(t, err)
+}
+
+func TestMetaClient_EstimateAdCost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/v18.0/act_123/delivery_estimate", r.URL.Path)
+		assert.Equal(t, "LINK_CLICKS", r.URL.Query().Get("optimization_goal"))
+		assert.Contains(t, r.URL.Query().Get("targeting_spec"), `"age_min":18`)
+
+		w.Write([]byte(`{
+			"data": [{
+				"daily_outcomes_curve": [
+					{"spend": 0, "reach": 0, "impressions": 0, "actions": 0},
+					{"spend": 2000, "reach": 3000, "impressions": 4000, "actions": 40},
+					{"spend": 6000, "reach": 7000, "impressions": 10000, "actions": 80}
+				],
+				"estimate_dau": 150000,
+				"estimate_mau_lower_bound": 600000,
+				"estimate_mau_upper_bound": 800000,
+				"estimate_ready": true
+			}]
+		}`))
+	}))
+	defer server.Close()
+
+	client, err := meta.NewClient(&config.MetaConfig{
+		AdAccountID: "123",
+		APIVersion:  "v18.0",
+		BaseURL:     server.URL,
+		Currency:    "GBP",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// $40 a day falls halfway between the 2000 and 6000 cent points
+	estimate, err := client.EstimateAdCost(context.Background(), estimateRequest(models.PlatformMeta, 40))
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(7*7000), estimate.EstimatedImpressions)
+	assert.Equal(t, int64(7*60), estimate.EstimatedClicks)
+	assert.InDelta(t, 280.0, estimate.EstimatedSpend, 0.0001)
+	assert.Equal(t, "GBP", estimate.Currency)
+	assert.InDelta(t, 0.75, estimate.Confidence, 0.0001)
+	assert.Equal(t, int64(700000), estimate.EstimatedAudienceSize)
+
+	// Budgets beyond the curve are capped at its last point
+	estimate, err = client.EstimateAdCost(context.Background(), estimateRequest(models.PlatformMeta, 500))
+	require.NoError(t, err)
+	assert.InDelta(t, 420.0, estimate.EstimatedSpend, 0.0001)
+	assert.Equal(t, int64(7*80), estimate.EstimatedClicks)
+}
+
+func TestMetaClient_EstimateAdCostNotReady(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": [{
+			"daily_outcomes_curve": [{"spend": 1000, "reach": 500, "impressions": 800, "actions": 10}],
+			"estimate_mau_lower_bound": 1000,
+			"estimate_mau_upper_bound": 2000,
+			"estimate_ready": false
+		}]}`))
+	}))
+	defer server.Close()
+
+	client, err := meta.NewClient(&config.MetaConfig{AdAccountID: "123", APIVersion: "v18.0", BaseURL: server.URL}, logrus.New())
+	require.NoError(t, err)
+
+	estimate, err := client.EstimateAdCost(context.Background(), estimateRequest(models.PlatformMeta, 5))
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(7*400), estimate.EstimatedImpressions)
+	assert.InDelta(t, 35.0, estimate.EstimatedSpend, 0.0001)
+	assert.Equal(t, "USD", estimate.Currency)
+	assert.InDelta(t, 0.25, estimate.Confidence, 0.0001)
+}
+
+func TestMetaClient_EstimateAdCostEmptyResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": []}`))
+	}))
+	defer server.Close()
+
+	client, err := meta.NewClient(&config.MetaConfig{AdAccountID: "123", APIVersion: "v18.0", BaseURL: server.URL}, logrus.New())
+	require.NoError(t, err)
+
+	_, err = client.EstimateAdCost(context.Background(), estimateRequest(models.PlatformMeta, 5))
+	assert.Error(t, err)
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/mocks"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/service"
+)
+
+func TestCreativeValidator_Validate(t *testing.T) {
+	validator := service.NewCreativeValidator()
+
+	tests := []struct {
+		name        string
+		contentType models.ContentType
+		platform    models.Platform
+		specs       models.CreativeSpecs
+		fields      []string
+	}{
+		{
+			name:        "complete meta video",
+			contentType: models.ContentTypeVideoScript,
+			platform:    models.PlatformMeta,
+			specs: models.CreativeSpecs{
+				VideoURL:    "https://cdn.example.com/launch.mp4",
+				Headline:    strings.Repeat("h", 125),
+				Description: strings.Repeat("d", 30),
+			},
+		},
+		{
+			name:        "meta video without video",
+			contentType: models.ContentTypeVideoScript,
+			platform:    models.PlatformMeta,
+			specs:       models.CreativeSpecs{Headline: "Trail shoes"},
+			fields:      []string{"video_url"},
+		},
+		{
+			name:        "meta video with long texts",
+			contentType: models.ContentTypeVideoScript,
+			platform:    models.PlatformMeta,
+			specs: models.CreativeSpecs{
+				VideoURL:    "https://cdn.example.com/launch.mp4",
+				Headline:    strings.Repeat("h", 126),
+				Description: strings.Repeat("é", 31),
+			},
+			fields: []string{"headline", "description"},
+		},
+		{
+			name:        "google ads infographic",
+			contentType: models.ContentTypeInfographic,
+			platform:    models.PlatformGoogleAds,
+			specs:       models.CreativeSpecs{ImageURL: "https://cdn.example.com/infographic.png"},
+		},
+		{
+			name:        "google ads infographic without image",
+			contentType: models.ContentTypeInfographic,
+			platform:    models.PlatformGoogleAds,
+			fields:      []string{"image_url"},
+		},
+		{
+			name:        "google ads infographic over http",
+			contentType: models.ContentTypeInfographic,
+			platform:    models.PlatformGoogleAds,
+			specs:       models.CreativeSpecs{ImageURL: "http://cdn.example.com/infographic.png"},
+			fields:      []string{"image_url"},
+		},
+		{
+			name:        "video without requirements on google ads",
+			contentType: models.ContentTypeVideoScript,
+			platform:    models.PlatformGoogleAds,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validator.Validate(tt.contentType, tt.platform, tt.specs)
+
+			var fields []string
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+				assert.NotEmpty(t, err.Message)
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+}
+
+func TestDeploymentService_RefusesInvalidCreative(t *testing.T) {
+	metaClient := mocks.NewMockMetaClient()
+	natsClient := mocks.NewMockNATSClient()
+	deploymentService := service.NewDeploymentService(mocks.NewMockGoogleAdsClient(), metaClient, natsClient, nil, nil, &config.DeploymentConfig{
+		MaxRetryAttempts: 3,
+		RetryDelay:       time.Millisecond,
+		Timeout:          time.Second,
+	}, logrus.New())
+
+	event := &models.AssetStatusChangedEvent{
+		EventType:   "asset.status_changed",
+		AssetID:     uuid.New(),
+		ProjectID:   uuid.New(),
+		Status:      models.AssetStatusApproved,
+		ContentType: models.ContentTypeVideoScript,
+		Title:       "Trail shoes launch",
+		Metadata: models.Metadata{
+			Platforms: []models.Platform{models.PlatformMeta},
+			Budget:    50,
+			CreativeSpecs: models.CreativeSpecs{
+				Headline: "Trail shoes",
+			},
+		},
+	}
+	require.NoError(t, deploymentService.HandleAssetStatusChanged(context.Background(), event))
+
+	// Meta is never called
+	assert.Empty(t, metaClient.GetDeployments())
+
+	statusEvents := natsClient.GetPublishedEventsOfType("asset.deployment_status_changed")
+	require.Len(t, statusEvents, 1)
+	result := statusEvents[0].(*models.DeploymentStatusChangedEvent).DeploymentResult
+	assert.Equal(t, models.DeploymentStatusFailed, result.Status)
+	assert.Equal(t, []models.ValidationError{{Field: "video_url", Message: "is required"}}, result.ValidationErrors)
+	assert.Equal(t, "invalid meta creative: video_url is required", result.Error)
+
+	// Invalid creatives would fail again, so they are not dead-lettered
+	for _, published := range natsClient.GetPublishedEvents() {
+		if e, ok := published.(*models.AssetStatusChangedEvent); ok {
+			assert.NotEqual(t, models.AssetStatusApproved, e.Status)
+		}
+	}
+}
+
+func TestCreativeValidationError(t *testing.T) {
+	var err error = &service.CreativeValidationError{
+		Platform: models.PlatformGoogleAds,
+		Errors: []models.ValidationError{
+			{Field: "image_url", Message: "must be an HTTPS URL"},
+			{Field: "headline", Message: "is required"},
+		},
+	}
+
+	var validationErr *service.CreativeValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "invalid google_ads creative: image_url must be an HTTPS URL; headline is required", err.Error())
+}
@@ -0,0 +1,244 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/credentials"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/meta"
+)
+
+// fakeCredentialBackend returns the credentials set for each platform
+type fakeCredentialBackend struct {
+	mu    sync.Mutex
+	creds map[models.Platform]credentials.PlatformCreds
+}
+
+func newFakeCredentialBackend(platform models.Platform, creds credentials.PlatformCreds) *fakeCredentialBackend {
+	return &fakeCredentialBackend{creds: map[models.Platform]credentials.PlatformCreds{platform: creds}}
+}
+
+func (b *fakeCredentialBackend) set(platform models.Platform, creds credentials.PlatformCreds) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.creds[platform] = creds
+}
+
+func (b *fakeCredentialBackend) Fetch(ctx context.Context, platform models.Platform) (credentials.PlatformCreds, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.creds[platform], nil
+}
+
+// metaCreds returns valid Meta credentials with the access token given
+func metaCreds(accessToken string) credentials.PlatformCreds {
+	return credentials.PlatformCreds{AppID: "app", AppSecret: "secret", AccessToken: accessToken, AdAccountID: "other-account"}
+}
+
+// auditEvents returns the events of the audit log entries of hook
+func auditEvents(hook *logtest.Hook) []string {
+	var events []string
+	for _, entry := range hook.AllEntries() {
+		if entry.Data["audit"] == true {
+			events = append(events, entry.Data["event"].(string))
+		}
+	}
+	return events
+}
+
+func TestRotator_RotateMeta(t *testing.T) {
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.URL.Query().Get("access_token"))
+		w.Write([]byte(`{"id":"me"}`))
+	}))
+	defer server.Close()
+
+	logger, hook := logtest.NewNullLogger()
+	client, err := meta.NewClient(&config.MetaConfig{AccessToken: "old-token", AdAccountID: "123", APIVersion: "v18.0", BaseURL: server.URL}, logger)
+	require.NoError(t, err)
+
+	backend := newFakeCredentialBackend(models.PlatformMeta, metaCreds("new-token"))
+	rotator := credentials.NewRotator(backend, nil, client, logger)
+
+	require.NoError(t, client.HealthCheck(context.Background()))
+	require.NoError(t, rotator.Rotate(context.Background(), models.PlatformMeta))
+	require.NoError(t, client.HealthCheck(context.Background()))
+
+	assert.Equal(t, []string{"old-token", "new-token"}, tokens)
+	assert.Equal(t, "123", client.Config().AdAccountID, "the ad account is kept")
+	assert.Equal(t, []string{"credentials_rotated"}, auditEvents(hook))
+}
+
+func TestRotator_RotateFailure(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	client, err := meta.NewClient(&config.MetaConfig{AccessToken: "old-token", AdAccountID: "123"}, logger)
+	require.NoError(t, err)
+
+	// The access token is missing
+	backend := newFakeCredentialBackend(models.PlatformMeta, metaCreds(""))
+	rotator := credentials.NewRotator(backend, nil, client, logger)
+
+	err = rotator.Rotate(context.Background(), models.PlatformMeta)
+	assert.ErrorContains(t, err, "missing access_token")
+	assert.Equal(t, "old-token", client.Config().AccessToken)
+	assert.Equal(t, []string{"credential_rotation_failed"}, auditEvents(hook))
+}
+
+func TestRotator_UnsupportedPlatform(t *testing.T) {
+	rotator := credentials.NewRotator(newFakeCredentialBackend(models.PlatformMeta, metaCreds("token")), nil, nil, logrus.New())
+
+	assert.ErrorIs(t, rotator.Rotate(context.Background(), models.PlatformMeta), credentials.ErrUnsupportedPlatform)
+	assert.ErrorIs(t, rotator.Rotate(context.Background(), models.PlatformTikTok), credentials.ErrUnsupportedPlatform)
+}
+
+func TestRotator_DetectsExpiry(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	client, err := meta.NewClient(&config.MetaConfig{AdAccountID: "123"}, logger)
+	require.NoError(t, err)
+
+	expiring := metaCreds("expiring-token")
+	expiresAt := time.Now().Add(time.Hour)
+	expiring.ExpiresAt = &expiresAt
+	backend := newFakeCredentialBackend(models.PlatformMeta, expiring)
+	rotator := credentials.NewRotator(backend, nil, client, logger)
+
+	require.NoError(t, rotator.Rotate(context.Background(), models.PlatformMeta))
+	assert.Equal(t, []string{"credentials_expiring", "credentials_rotated"}, auditEvents(hook))
+
+	expired := time.Now().Add(-time.Hour)
+	expiring.ExpiresAt = &expired
+	backend.set(models.PlatformMeta, expiring)
+	hook.Reset()
+
+	require.NoError(t, rotator.Rotate(context.Background(), models.PlatformMeta))
+	assert.Equal(t, []string{"credentials_expired", "credentials_rotated"}, auditEvents(hook))
+}
+
+func TestRotator_RunPicksUpNewCredentials(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	client, err := meta.NewClient(&config.MetaConfig{AppID: "app", AppSecret: "secret", AccessToken: "old-token", AdAccountID: "123"}, logger)
+	require.NoError(t, err)
+
+	backend := newFakeCredentialBackend(models.PlatformMeta, metaCreds("old-token"))
+	rotator := credentials.NewRotator(backend, nil, client, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go rotator.Run(ctx, 10*time.Millisecond)
+
+	// Unchanged credentials are not rotated
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, auditEvents(hook))
+
+	backend.set(models.PlatformMeta, metaCreds("new-token"))
+	require.Eventually(t, func() bool {
+		return client.Config().AccessToken == "new-token"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestEnvBackend_Fetch(t *testing.T) {
+	t.Setenv("META_APP_ID", "env-app")
+	t.Setenv("META_APP_SECRET", "env-secret")
+	t.Setenv("META_ACCESS_TOKEN", "env-token")
+	t.Setenv("META_AD_ACCOUNT_ID", "123")
+
+	path := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(path, []byte("META_ACCESS_TOKEN=file-token\nMETA_ACCESS_TOKEN_EXPIRES_AT=2030-01-02T15:04:05Z\n"), 0o600))
+
+	creds, err := (&credentials.EnvBackend{Path: path}).Fetch(context.Background(), models.PlatformMeta)
+	require.NoError(t, err)
+
+	assert.Equal(t, "env-app", creds.AppID)
+	assert.Equal(t, "file-token", creds.AccessToken, "the dotenv file takes priority")
+	require.NotNil(t, creds.ExpiresAt)
+	assert.Equal(t, time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC), creds.ExpiresAt.UTC())
+
+	// A missing file leaves the environment
+	creds, err = (&credentials.EnvBackend{Path: filepath.Join(t.TempDir(), ".env")}).Fetch(context.Background(), models.PlatformMeta)
+	require.NoError(t, err)
+	assert.Equal(t, "env-token", creds.AccessToken)
+}
+
+func TestVaultBackend_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/secret/data/zamc/connectors/meta", r.URL.Path)
+		assert.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+		w.Write([]byte(`{"data":{"data":{"app_id":"app","access_token":"vault-access-token"},"metadata":{"version":3}}}`))
+	}))
+	defer server.Close()
+
+	backend, err := credentials.NewBackend(&config.CredentialsConfig{
+		CredentialBackend: "vault",
+		VaultAddress:      server.URL,
+		VaultToken:        "vault-token",
+		VaultMount:        "secret",
+		SecretPrefix:      "zamc/connectors/",
+	})
+	require.NoError(t, err)
+
+	creds, err := backend.Fetch(context.Background(), models.PlatformMeta)
+	require.NoError(t, err)
+	assert.Equal(t, "vault-access-token", creds.AccessToken)
+}
+
+func TestAWSSecretsManagerBackend_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.Equal(t, "session", r.Header.Get("X-Amz-Security-Token"))
+
+		authorization := r.Header.Get("Authorization")
+		assert.True(t, strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKID/"), authorization)
+		assert.Contains(t, authorization, "/eu-west-1/secretsmanager/aws4_request")
+		assert.Contains(t, authorization, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target")
+
+		var request map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "zamc/connectors/google_ads", request["SecretId"])
+
+		w.Write([]byte(`{"Name":"zamc/connectors/google_ads","SecretString":"{\"developer_token\":\"dev\",\"refresh_token\":\"aws-refresh-token\"}"}`))
+	}))
+	defer server.Close()
+
+	backend, err := credentials.NewBackend(&config.CredentialsConfig{
+		CredentialBackend:  "aws",
+		AWSRegion:          "eu-west-1",
+		AWSAccessKeyID:     "AKID",
+		AWSSecretAccessKey: "secret",
+		AWSSessionToken:    "session",
+		AWSEndpoint:        server.URL,
+		SecretPrefix:       "zamc/connectors/",
+	})
+	require.NoError(t, err)
+
+	creds, err := backend.Fetch(context.Background(), models.PlatformGoogleAds)
+	require.NoError(t, err)
+	assert.Equal(t, "dev", creds.DeveloperToken)
+	assert.Equal(t, "aws-refresh-token", creds.RefreshToken)
+}
+
+func TestNewBackend_Invalid(t *testing.T) {
+	_, err := credentials.NewBackend(&config.CredentialsConfig{CredentialBackend: "aws"})
+	assert.ErrorContains(t, err, "requires AWS_REGION")
+
+	_, err = credentials.NewBackend(&config.CredentialsConfig{CredentialBackend: "vault"})
+	assert.ErrorContains(t, err, "requires VAULT_ADDR")
+
+	_, err = credentials.NewBackend(&config.CredentialsConfig{CredentialBackend: "gcp"})
+	assert.ErrorContains(t, err, "unsupported credential backend: gcp")
+}
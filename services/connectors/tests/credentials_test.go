@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/credentials"
+	"github.com/zamc/connectors/internal/models"
+)
+
+func newMasterKey(t *testing.T) string {
+	t.Helper()
+
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func TestCipher_RoundTrip(t *testing.T) {
+	c, err := credentials.NewCipher(newMasterKey(t))
+	require.NoError(t, err)
+
+	creds := credentials.PlatformCreds{
+		AppID:       "app-123",
+		AppSecret:   "secret",
+		AccessToken: "token",
+		AdAccountID: "act_456",
+	}
+	plaintext, err := json.Marshal(creds)
+	require.NoError(t, err)
+
+	aad := []byte("tenant-1:meta")
+	sealed, err := c.Encrypt(plaintext, aad)
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "secret")
+
+	opened, err := c.Decrypt(sealed, aad)
+	require.NoError(t, err)
+
+	var decoded credentials.PlatformCreds
+	require.NoError(t, json.Unmarshal(opened, &decoded))
+	assert.Equal(t, creds, decoded)
+}
+
+func TestCipher_UsesFreshNonce(t *testing.T) {
+	c, err := credentials.NewCipher(newMasterKey(t))
+	require.NoError(t, err)
+
+	first, err := c.Encrypt([]byte("same"), nil)
+	require.NoError(t, err)
+	second, err := c.Encrypt([]byte("same"), nil)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+}
+
+func TestCipher_RejectsTamperedCiphertext(t *testing.T) {
+	c, err := credentials.NewCipher(newMasterKey(t))
+	require.NoError(t, err)
+
+	sealed, err := c.Encrypt([]byte(`{"access_token":"token"}`), []byte("tenant-1:meta"))
+	require.NoError(t, err)
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-1] ^= 0xff
+	_, err = c.Decrypt(tampered, []byte("tenant-1:meta"))
+	assert.Error(t, err)
+
+	// Ciphertext copied to another tenant's row must not decrypt
+	_, err = c.Decrypt(sealed, []byte("tenant-2:meta"))
+	assert.Error(t, err)
+
+	_, err = c.Decrypt(sealed[:4], []byte("tenant-1:meta"))
+	assert.Error(t, err)
+}
+
+func TestCipher_RejectsWrongKey(t *testing.T) {
+	c, err := credentials.NewCipher(newMasterKey(t))
+	require.NoError(t, err)
+	other, err := credentials.NewCipher(newMasterKey(t))
+	require.NoError(t, err)
+
+	sealed, err := c.Encrypt([]byte("payload"), nil)
+	require.NoError(t, err)
+
+	_, err = other.Decrypt(sealed, nil)
+	assert.Error(t, err)
+}
+
+func TestNewCipher_InvalidMasterKey(t *testing.T) {
+	_, err := credentials.NewCipher("not base64!")
+	assert.Error(t, err)
+
+	_, err = credentials.NewCipher(base64.StdEncoding.EncodeToString([]byte("too short")))
+	assert.Error(t, err)
+}
+
+func TestPlatformCreds_Validate(t *testing.T) {
+	googleAds := credentials.PlatformCreds{
+		DeveloperToken: "dev",
+		ClientID:       "client",
+		ClientSecret:   "secret",
+		RefreshToken:   "refresh",
+		CustomerID:     "123-456-7890",
+	}
+	assert.NoError(t, googleAds.Validate(models.PlatformGoogleAds))
+	assert.Error(t, googleAds.Validate(models.PlatformMeta))
+	assert.Error(t, googleAds.Validate(models.Platform("tiktok")))
+}
+
+func TestPlatformCreds_ApplyToConfig(t *testing.T) {
+	creds := credentials.PlatformCreds{
+		AppID:       "tenant-app",
+		AppSecret:   "tenant-secret",
+		AccessToken: "tenant-token",
+		AdAccountID: "act_tenant",
+	}
+
+	base := config.MetaConfig{
+		AppID:      "default-app",
+		APIVersion: "v18.0",
+		BaseURL:    "https://graph.facebook.com",
+	}
+
+	cfg := creds.MetaConfig(base)
+	assert.Equal(t, "tenant-app", cfg.AppID)
+	assert.Equal(t, "act_tenant", cfg.AdAccountID)
+	assert.Equal(t, "v18.0", cfg.APIVersion)
+	assert.Equal(t, "https://graph.facebook.com", cfg.BaseURL)
+	assert.Equal(t, "default-app", base.AppID)
+}
+
+func TestCredentialStore_OpenTransported(t *testing.T) {
+	store, err := credentials.NewCredentialStore(nil, newMasterKey(t))
+	require.NoError(t, err)
+
+	// Without a transport key nothing sent by the BFF can be opened
+	_, err = store.OpenTransported("tenant-1", "meta", "")
+	assert.Error(t, err)
+
+	transportKey := newMasterKey(t)
+	require.NoError(t, store.UseTransportKey(transportKey))
+
+	bff, err := credentials.NewCipher(transportKey)
+	require.NoError(t, err)
+
+	creds := credentials.PlatformCreds{AppID: "app-123", AppSecret: "secret", AccessToken: "token", AdAccountID: "act_456"}
+	plaintext, err := json.Marshal(creds)
+	require.NoError(t, err)
+	sealed, err := bff.Encrypt(plaintext, []byte("tenant-1:meta"))
+	require.NoError(t, err)
+	encoded := base64.StdEncoding.EncodeToString(sealed)
+
+	opened, err := store.OpenTransported("tenant-1", "meta", encoded)
+	require.NoError(t, err)
+	assert.Equal(t, creds, opened)
+
+	// The credentials are bound to their tenant and platform
+	_, err = store.OpenTransported("tenant-2", "meta", encoded)
+	assert.Error(t, err)
+	_, err = store.OpenTransported("tenant-1", "google_ads", encoded)
+	assert.Error(t, err)
+}
@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/meta"
+)
+
+// customAudienceServer records the requests made to a fake Meta API
+type customAudienceServer struct {
+	mu            sync.Mutex
+	uploads       [][]string
+	adSets        []map[string]interface{}
+	requests      []string
+	failCreatives bool
+}
+
+func newCustomAudienceServer(t *testing.T) (*httptest.Server, *customAudienceServer) {
+	recorded := &customAudienceServer{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		recorded.mu.Lock()
+		defer recorded.mu.Unlock()
+		recorded.requests = append(recorded.requests, r.Method+" "+r.URL.Path)
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/customaudiences"):
+			w.Write([]byte(`{"id":"audience-1"}`))
+		case strings.HasSuffix(r.URL.Path, "/audience-1/users"):
+			payload := body["payload"].(map[string]interface{})
+			assert.Equal(t, "EMAIL_SHA256", payload["schema"])
+			var hashes []string
+			for _, hash := range payload["data"].([]interface{}) {
+				hashes = append(hashes, hash.(string))
+			}
+			recorded.uploads = append(recorded.uploads, hashes)
+			w.Write([]byte(`{"audience_id":"audience-1","num_received":1}`))
+		case strings.HasSuffix(r.URL.Path, "/adsets"):
+			recorded.adSets = append(recorded.adSets, body)
+			w.Write([]byte(`{"id":"adset-1"}`))
+		case strings.HasSuffix(r.URL.Path, "/adcreatives") && recorded.failCreatives:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"message":"Invalid image","type":"OAuthException","code":100}}`))
+		default:
+			w.Write([]byte(`{"id":"123456","success":true}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, recorded
+}
+
+func newCustomAudienceClient(t *testing.T, server *httptest.Server) *meta.Client {
+	client, err := meta.NewClient(&config.MetaConfig{AdAccountID: "123", APIVersion: "v18.0", BaseURL: server.URL}, logrus.New())
+	require.NoError(t, err)
+	return client
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestMetaClient_CreateCustomAudienceUploadsEmailHashes(t *testing.T) {
+	server, recorded := newCustomAudienceServer(t)
+	client := newCustomAudienceClient(t, server)
+
+	hashes := []string{sha256Hex("jane@example.com"), sha256Hex("john@example.com")}
+	audienceID, err := client.CreateCustomAudience(context.Background(), "123", "Customers", hashes)
+	require.NoError(t, err)
+	assert.Equal(t, "audience-1", audienceID)
+
+	require.Len(t, recorded.uploads, 1)
+	assert.Equal(t, hashes, recorded.uploads[0])
+	assert.Equal(t, "POST /v18.0/act_123/customaudiences", recorded.requests[0])
+}
+
+func TestMetaClient_CreateCustomAudienceRejectsEmails(t *testing.T) {
+	server, recorded := newCustomAudienceServer(t)
+	client := newCustomAudienceClient(t, server)
+
+	_, err := client.CreateCustomAudience(context.Background(), "123", "Customers", []string{"jane@example.com"})
+	assert.EqualError(t, err, "custom audience emails must be hex-encoded SHA-256 hashes")
+	assert.Empty(t, recorded.requests)
+}
+
+func TestMetaClient_CreateCustomAudienceUploadsInChunks(t *testing.T) {
+	server, recorded := newCustomAudienceServer(t)
+	client := newCustomAudienceClient(t, server)
+
+	hashes := make([]string, 10001)
+	for i := range hashes {
+		hashes[i] = sha256Hex(fmt.Sprintf("user%d@example.com", i))
+	}
+
+	_, err := client.CreateCustomAudience(context.Background(), "123", "Customers", hashes)
+	require.NoError(t, err)
+
+	require.Len(t, recorded.uploads, 2)
+	assert.Len(t, recorded.uploads[0], 10000)
+	assert.Len(t, recorded.uploads[1], 1)
+}
+
+func customAudienceRequest() *models.DeploymentRequest {
+	return &models.DeploymentRequest{
+		AssetID:     uuid.New(),
+		ProjectID:   uuid.New(),
+		StrategyID:  uuid.New(),
+		Platform:    models.PlatformMeta,
+		ContentType: models.ContentTypeSocialMedia,
+		Title:       "Spring sale",
+		Metadata: models.Metadata{
+			Budget: 50,
+			Demographics: models.Demographics{
+				AgeMin:                    18,
+				AgeMax:                    45,
+				Locations:                 []string{"US"},
+				Interests:                 []string{"running"},
+				CustomAudienceEmailHashes: []string{sha256Hex("jane@example.com")},
+			},
+			CreativeSpecs: models.CreativeSpecs{ImageURL: "https://cdn.example.com/ad.png"},
+		},
+	}
+}
+
+func TestMetaClient_DeployTargetsCustomAudience(t *testing.T) {
+	server, recorded := newCustomAudienceServer(t)
+	client := newCustomAudienceClient(t, server)
+
+	result, err := client.DeployAsset(context.Background(), customAudienceRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "audience-1", result.AudienceID)
+
+	require.Len(t, recorded.adSets, 1)
+	targeting := recorded.adSets[0]["targeting"].(map[string]interface{})
+	assert.NotContains(t, targeting, "interests")
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": "audience-1"}}, targeting["custom_audiences"])
+}
+
+func TestMetaClient_DeployDeletesCustomAudienceOnFailure(t *testing.T) {
+	server, recorded := newCustomAudienceServer(t)
+	recorded.failCreatives = true
+	client := newCustomAudienceClient(t, server)
+
+	result, err := client.DeployAsset(context.Background(), customAudienceRequest())
+	require.Error(t, err)
+	assert.Empty(t, result.AudienceID)
+
+	// The audience of the failed deployment would not be recorded, so it is deleted
+	assert.Contains(t, recorded.requests, "DELETE /v18.0/audience-1")
+}
+
+func TestMetaClient_RevokeCustomAudience(t *testing.T) {
+	server, recorded := newCustomAudienceServer(t)
+	client := newCustomAudienceClient(t, server)
+
+	require.NoError(t, client.RevokeCustomAudience(context.Background(), "audience-1"))
+	assert.Equal(t, []string{"DELETE /v18.0/audience-1"}, recorded.requests)
+}
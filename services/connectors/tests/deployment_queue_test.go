@@ -0,0 +1,201 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/metrics"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/queue"
+)
+
+func TestPriorityOf(t *testing.T) {
+	assert.Equal(t, queue.PriorityHigh, queue.PriorityOf(models.Metadata{CampaignType: "Conversion"}))
+	assert.Equal(t, queue.PriorityNormal, queue.PriorityOf(models.Metadata{CampaignType: "traffic"}))
+	assert.Equal(t, queue.PriorityNormal, queue.PriorityOf(models.Metadata{}))
+	assert.Equal(t, queue.PriorityLow, queue.PriorityOf(models.Metadata{CampaignType: "awareness"}))
+}
+
+func TestDeploymentQueue_DeploysHighestPriorityFirst(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var deployed []string
+
+	q := queue.New([]models.Platform{models.PlatformMeta}, 1, 10, func(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error) {
+		<-release
+		mu.Lock()
+		deployed = append(deployed, request.Title)
+		mu.Unlock()
+		return &models.DeploymentResult{AssetID: request.AssetID, Platform: request.Platform, Status: models.DeploymentStatusSuccess}, nil
+	})
+	defer q.Stop()
+
+	var wg sync.WaitGroup
+	enqueue := func(title string, priority queue.Priority) {
+		wg.Add(1)
+		request := &models.DeploymentRequest{AssetID: uuid.New(), Platform: models.PlatformMeta, Title: title}
+		require.NoError(t, q.Enqueue(context.Background(), request, priority, func(result *models.DeploymentResult, err error) {
+			assert.NoError(t, err)
+			assert.Equal(t, request.AssetID, result.AssetID)
+			wg.Done()
+		}))
+	}
+
+	// The first deployment keeps the only worker busy while the others queue
+	enqueue("first", queue.PriorityLow)
+	require.Eventually(t, func() bool { return q.Depths()[models.PlatformMeta] == 0 }, time.Second, time.Millisecond)
+
+	enqueue("awareness", queue.PriorityLow)
+	enqueue("traffic", queue.PriorityNormal)
+	enqueue("sales", queue.PriorityHigh)
+	enqueue("conversion", queue.PriorityHigh)
+	assert.Equal(t, 4, q.Depths()[models.PlatformMeta])
+	assert.Equal(t, 4.0, testutil.ToFloat64(metrics.DeploymentQueueDepth.WithLabelValues(string(models.PlatformMeta))))
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, []string{"first", "sales", "conversion", "traffic", "awareness"}, deployed)
+	assert.Equal(t, 0, q.Depths()[models.PlatformMeta])
+}
+
+func TestDeploymentQueue_PlatformsDoNotBlockEachOther(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	q := queue.New([]models.Platform{models.PlatformGoogleAds, models.PlatformMeta}, 1, 10, func(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error) {
+		// Google Ads is stuck
+		if request.Platform == models.PlatformGoogleAds {
+			<-release
+		}
+		return &models.DeploymentResult{Platform: request.Platform, Status: models.DeploymentStatusSuccess}, nil
+	})
+
+	for i := 0; i < 2; i++ {
+		request := &models.DeploymentRequest{AssetID: uuid.New(), Platform: models.PlatformGoogleAds}
+		require.NoError(t, q.Enqueue(context.Background(), request, queue.PriorityNormal, func(*models.DeploymentResult, error) {}))
+	}
+
+	done := make(chan *models.DeploymentResult, 1)
+	request := &models.DeploymentRequest{AssetID: uuid.New(), Platform: models.PlatformMeta}
+	require.NoError(t, q.Enqueue(context.Background(), request, queue.PriorityNormal, func(result *models.DeploymentResult, err error) {
+		done <- result
+	}))
+
+	select {
+	case result := <-done:
+		assert.Equal(t, models.PlatformMeta, result.Platform)
+	case <-time.After(time.Second):
+		t.Fatal("Meta deployment waited for Google Ads")
+	}
+	assert.Eventually(t, func() bool { return q.Depths()[models.PlatformGoogleAds] == 1 }, time.Second, time.Millisecond)
+}
+
+func TestDeploymentQueue_Stop(t *testing.T) {
+	release := make(chan struct{})
+	q := queue.New([]models.Platform{models.PlatformMeta}, 1, 10, func(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error) {
+		<-release
+		return &models.DeploymentResult{Platform: request.Platform, Status: models.DeploymentStatusSuccess}, nil
+	})
+
+	results := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		request := &models.DeploymentRequest{AssetID: uuid.New(), Platform: models.PlatformMeta}
+		require.NoError(t, q.Enqueue(context.Background(), request, queue.PriorityNormal, func(result *models.DeploymentResult, err error) {
+			results <- err
+		}))
+	}
+	require.Eventually(t, func() bool { return q.Depths()[models.PlatformMeta] == 1 }, time.Second, time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		q.Stop()
+		close(stopped)
+	}()
+
+	request := &models.DeploymentRequest{AssetID: uuid.New(), Platform: models.PlatformMeta}
+	require.Eventually(t, func() bool {
+		return q.Enqueue(context.Background(), request, queue.PriorityNormal, func(*models.DeploymentResult, error) {}) == queue.ErrStopped
+	}, time.Second, time.Millisecond)
+
+	// The running deployment finishes and the waiting one fails
+	close(release)
+	<-stopped
+	assert.NoError(t, <-results)
+	assert.ErrorIs(t, <-results, queue.ErrStopped)
+	assert.Equal(t, 0, q.Depths()[models.PlatformMeta])
+
+	request.Platform = "myspace"
+	assert.EqualError(t, q.Enqueue(context.Background(), request, queue.PriorityNormal, nil), "unsupported platform: myspace")
+}
+
+// queueingEventHandler deploys the approved assets of events on queue by
+// priority and waits for their deployments, as the deployment service does
+type queueingEventHandler struct {
+	queue *queue.DeploymentQueue
+}
+
+func (h *queueingEventHandler) HandleAssetStatusChanged(ctx context.Context, event *models.AssetStatusChangedEvent) error {
+	request := &models.DeploymentRequest{AssetID: event.AssetID, Platform: models.PlatformMeta, Title: event.Title}
+	done := make(chan error, 1)
+	if err := h.queue.Enqueue(ctx, request, queue.PriorityOf(event.Metadata), func(result *models.DeploymentResult, err error) {
+		done <- err
+	}); err != nil {
+		return err
+	}
+	return <-done
+}
+
+func TestSubscribeToAssetStatusChanged_HighPriorityOvertakesQueued(t *testing.T) {
+	s := runJetStreamServer(t)
+	client := newConsumersClient(t, s)
+
+	started := make(chan string, 3)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var deployed []string
+
+	q := queue.New([]models.Platform{models.PlatformMeta}, 1, 10, func(ctx context.Context, request *models.DeploymentRequest) (*models.DeploymentResult, error) {
+		started <- request.Title
+		<-release
+		mu.Lock()
+		deployed = append(deployed, request.Title)
+		mu.Unlock()
+		return &models.DeploymentResult{AssetID: request.AssetID, Platform: request.Platform, Status: models.DeploymentStatusSuccess}, nil
+	})
+	defer q.Stop()
+	defer subscribeStatusChanged(t, client, &queueingEventHandler{queue: q})()
+
+	publish := func(title, campaignType string) {
+		event := approvedAssetEvent()
+		event.Title = title
+		event.Metadata.CampaignType = campaignType
+		require.NoError(t, client.PublishAssetStatusChanged(context.Background(), event))
+	}
+
+	// The first deployment keeps the only worker busy while the others queue,
+	// their events still unacknowledged
+	publish("first", "awareness")
+	assert.Equal(t, "first", <-started)
+	publish("awareness", "awareness")
+	require.Eventually(t, func() bool { return q.Depths()[models.PlatformMeta] == 1 }, 5*time.Second, 10*time.Millisecond)
+	publish("conversion", "conversion")
+	require.Eventually(t, func() bool { return q.Depths()[models.PlatformMeta] == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		lag, err := client.EventStreamLag(context.Background())
+		return err == nil && lag.Pending == 0 && lag.AckPending == 0
+	}, 5*time.Second, 20*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"first", "conversion", "awareness"}, deployed)
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/platforms/googleads"
+	"github.com/zamc/connectors/internal/platforms/meta"
+)
+
+func TestGoogleAdsClient_PauseAd(t *testing.T) {
+	var mutation struct {
+		Operations []struct {
+			UpdateMask string `json:"updateMask"`
+			Update     struct {
+				ResourceName string `json:"resourceName"`
+				Status       string `json:"status"`
+			} `json:"update"`
+		} `json:"operations"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v16/customers/1234567890/campaigns:mutate", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&mutation))
+
+		w.Write([]byte(`{"results": [{"resourceName": "customers/1234567890/campaigns/555"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := googleads.NewClient(&config.GoogleAdsConfig{
+		CustomerID: "1234567890",
+		BaseURL:    server.URL + "/v16",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	require.NoError(t, client.PauseAd(context.Background(), "555", "777", "999"))
+
+	require.Len(t, mutation.Operations, 1)
+	assert.Equal(t, "status", mutation.Operations[0].UpdateMask)
+	assert.Equal(t, "customers/1234567890/campaigns/555", mutation.Operations[0].Update.ResourceName)
+	assert.Equal(t, "PAUSED", mutation.Operations[0].Update.Status)
+}
+
+func TestGoogleAdsClient_PauseAdWithoutCampaign(t *testing.T) {
+	client, err := googleads.NewClient(&config.GoogleAdsConfig{CustomerID: "1234567890"}, logrus.New())
+	require.NoError(t, err)
+
+	err = client.PauseAd(context.Background(), "", "777", "999")
+	assert.EqualError(t, err, "campaign of ad 999 is unknown")
+}
+
+func TestMetaClient_PauseAd(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr bool
+	}{
+		{
+			name:   "paused",
+			status: http.StatusOK,
+			body:   `{"success": true}`,
+		},
+		{
+			name:    "API error",
+			status:  http.StatusBadRequest,
+			body:    `{"error": {"message": "Unsupported post request", "code": 100}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/v18.0/120200000000001", r.URL.Path)
+				assert.Equal(t, "PAUSED", r.URL.Query().Get("status"))
+
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client, err := meta.NewClient(&config.MetaConfig{
+				AccessToken: "token",
+				AdAccountID: "act_123",
+				APIVersion:  "v18.0",
+				BaseURL:     server.URL,
+			}, logrus.New())
+			require.NoError(t, err)
+
+			err = client.PauseAd(context.Background(), "120200000000001")
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "failed to pause ad 120200000000001")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
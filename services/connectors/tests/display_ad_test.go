@@ -0,0 +1,225 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/googleads"
+)
+
+func infographicRequest() *models.DeploymentRequest {
+	return &models.DeploymentRequest{
+		AssetID:     uuid.New(),
+		ProjectID:   uuid.New(),
+		StrategyID:  uuid.New(),
+		Platform:    models.PlatformGoogleAds,
+		ContentType: models.ContentTypeInfographic,
+		Title:       "The state of trail running in 2024",
+		Content: strings.Join([]string{
+			"Trail running keeps growing",
+			"Runners log longer weekends",
+			"Gear spending is up sharply",
+			"Women lead new sign ups",
+			"Ultra events sell out fast",
+			"Recovery tech goes mainstream",
+			"Half of all runners now race off road at least once a year. Most of them started during the last three years.",
+		}, "\n"),
+		Metadata: models.Metadata{
+			Budget: 25,
+			CreativeSpecs: models.CreativeSpecs{
+				ImageURL:     "https://cdn.example.com/infographic.png",
+				LogoURL:      "https://cdn.example.com/logo.png",
+				Headline:     "Trail running by the numbers",
+				Description:  "See how trail running grew this year and what runners buy.",
+				CallToAction: "Learn more",
+				LandingURL:   "https://example.com/report",
+				BusinessName: "Trailhead Co",
+			},
+		},
+	}
+}
+
+func newDisplayClient(t *testing.T) *googleads.Client {
+	client, err := googleads.NewClient(&config.GoogleAdsConfig{CustomerID: "1234567890"}, logrus.New())
+	require.NoError(t, err)
+	return client
+}
+
+func validDisplayAd() *googleads.ResponsiveDisplayAd {
+	return &googleads.ResponsiveDisplayAd{
+		Headlines:       []string{"Trail running by the numbers"},
+		LongHeadline:    "Trail running by the numbers",
+		Descriptions:    []string{"See how trail running grew this year."},
+		BusinessName:    "Trailhead Co",
+		MarketingImages: []string{"https://cdn.example.com/infographic.png"},
+		LogoImages:      []string{"https://cdn.example.com/logo.png"},
+	}
+}
+
+func TestGoogleAdsClient_BuildResponsiveDisplayAd(t *testing.T) {
+	client := newDisplayClient(t)
+
+	ad, err := client.BuildResponsiveDisplayAd(infographicRequest())
+	require.NoError(t, err)
+
+	assert.Len(t, ad.Headlines, googleads.MaxDisplayHeadlines)
+	assert.Equal(t, "Trail running by the numbers", ad.Headlines[0])
+	for _, headline := range ad.Headlines {
+		assert.LessOrEqual(t, utf8.RuneCountInString(headline), googleads.MaxDisplayHeadlineLength)
+	}
+
+	assert.NotEmpty(t, ad.Descriptions)
+	assert.LessOrEqual(t, len(ad.Descriptions), googleads.MaxDisplayDescriptions)
+	for _, description := range ad.Descriptions {
+		assert.LessOrEqual(t, utf8.RuneCountInString(description), googleads.MaxDisplayDescriptionLength)
+	}
+
+	assert.Equal(t, "Trail running by the numbers", ad.LongHeadline)
+	assert.Equal(t, "Trailhead Co", ad.BusinessName)
+	assert.Equal(t, []string{"https://cdn.example.com/infographic.png"}, ad.MarketingImages)
+	assert.Equal(t, []string{"https://cdn.example.com/logo.png"}, ad.LogoImages)
+	assert.Equal(t, []string{"https://example.com/report"}, ad.FinalURLs)
+	assert.Equal(t, "Learn more", ad.CallToActionText)
+}
+
+func TestGoogleAdsClient_BuildResponsiveDisplayAdShortensText(t *testing.T) {
+	client := newDisplayClient(t)
+
+	request := infographicRequest()
+	request.Metadata.CreativeSpecs.Headline = strings.Repeat("Long headline ", 10)
+
+	ad, err := client.BuildResponsiveDisplayAd(request)
+	require.NoError(t, err)
+
+	assert.Len(t, ad.Headlines[0], googleads.MaxDisplayHeadlineLength)
+	assert.Len(t, ad.LongHeadline, googleads.MaxDisplayLongHeadlineLength)
+}
+
+func TestGoogleAdsClient_BuildResponsiveDisplayAdRequiresCreative(t *testing.T) {
+	client := newDisplayClient(t)
+
+	t.Run("Image", func(t *testing.T) {
+		request := infographicRequest()
+		request.Metadata.CreativeSpecs.ImageURL = ""
+
+		_, err := client.BuildResponsiveDisplayAd(request)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "at least one image is required")
+	})
+
+	t.Run("Logo", func(t *testing.T) {
+		request := infographicRequest()
+		request.Metadata.CreativeSpecs.LogoURL = ""
+
+		_, err := client.BuildResponsiveDisplayAd(request)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "at least one logo is required")
+	})
+
+	t.Run("Business Name", func(t *testing.T) {
+		request := infographicRequest()
+		request.Metadata.CreativeSpecs.BusinessName = ""
+
+		_, err := client.BuildResponsiveDisplayAd(request)
+		assert.Error(t, err)
+	})
+}
+
+func TestResponsiveDisplayAd_Validate(t *testing.T) {
+	assert.NoError(t, validDisplayAd().Validate())
+
+	tests := []struct {
+		name   string
+		modify func(ad *googleads.ResponsiveDisplayAd)
+		err    string
+	}{
+		{
+			name:   "Headline Too Long",
+			modify: func(ad *googleads.ResponsiveDisplayAd) { ad.Headlines = []string{strings.Repeat("a", 31)} },
+			err:    "exceeds 30 characters",
+		},
+		{
+			name: "Too Many Headlines",
+			modify: func(ad *googleads.ResponsiveDisplayAd) {
+				ad.Headlines = []string{"one", "two", "three", "four", "five", "six"}
+			},
+			err: "at most 5 headlines",
+		},
+		{
+			name:   "Long Headline Too Long",
+			modify: func(ad *googleads.ResponsiveDisplayAd) { ad.LongHeadline = strings.Repeat("a", 91) },
+			err:    "long headline exceeds 90 characters",
+		},
+		{
+			name:   "Description Too Long",
+			modify: func(ad *googleads.ResponsiveDisplayAd) { ad.Descriptions = []string{strings.Repeat("a", 91)} },
+			err:    "exceeds 90 characters",
+		},
+		{
+			name: "Too Many Descriptions",
+			modify: func(ad *googleads.ResponsiveDisplayAd) {
+				ad.Descriptions = []string{"one", "two", "three", "four", "five", "six"}
+			},
+			err: "at most 5 descriptions",
+		},
+		{
+			name:   "Business Name Too Long",
+			modify: func(ad *googleads.ResponsiveDisplayAd) { ad.BusinessName = strings.Repeat("a", 26) },
+			err:    "business name exceeds 25 characters",
+		},
+		{
+			name:   "No Image",
+			modify: func(ad *googleads.ResponsiveDisplayAd) { ad.MarketingImages = nil },
+			err:    "at least one image is required",
+		},
+		{
+			name:   "No Logo",
+			modify: func(ad *googleads.ResponsiveDisplayAd) { ad.LogoImages = nil },
+			err:    "at least one logo is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ad := validDisplayAd()
+			tt.modify(ad)
+
+			err := ad.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	// Limits count characters, not bytes
+	ad := validDisplayAd()
+	ad.Headlines = []string{strings.Repeat("é", 30)}
+	assert.NoError(t, ad.Validate())
+}
+
+func TestGoogleAdsClient_DeployInfographic(t *testing.T) {
+	client, mutations := newGoogleAdsMutateClient(t, config.GoogleAdsConfig{})
+
+	result, err := client.DeployAsset(context.Background(), infographicRequest())
+	require.NoError(t, err)
+	assert.Equal(t, models.DeploymentStatusSuccess, result.Status)
+	assert.True(t, strings.HasPrefix(result.PlatformID, "rda_"))
+	assert.Contains(t, result.PlatformURL, "campaignId=1001")
+	require.Len(t, mutations.operations["campaigns"], 1)
+	assert.Equal(t, "DISPLAY", mutations.operations["campaigns"][0]["advertisingChannelType"])
+
+	request := infographicRequest()
+	request.Metadata.CreativeSpecs.LogoURL = ""
+
+	result, err = client.DeployAsset(context.Background(), request)
+	assert.Error(t, err)
+	assert.Equal(t, models.DeploymentStatusFailed, result.Status)
+}
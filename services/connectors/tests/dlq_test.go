@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/dlq"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/nats"
+	"github.com/zamc/connectors/internal/service"
+)
+
+// memoryDLQStore keeps dead letters in memory
+type memoryDLQStore struct {
+	mu      sync.Mutex
+	entries map[uuid.UUID]models.DeadLetter
+	saved   int
+}
+
+func newMemoryDLQStore() *memoryDLQStore {
+	return &memoryDLQStore{entries: map[uuid.UUID]models.DeadLetter{}}
+}
+
+func (s *memoryDLQStore) SaveEntry(ctx context.Context, letter *models.DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saved++
+	letter.ID = uuid.New()
+	letter.CreatedAt = time.Now().Add(time.Duration(s.saved) * time.Millisecond)
+	s.entries[letter.ID] = *letter
+	return nil
+}
+
+func (s *memoryDLQStore) ListEntries(ctx context.Context) ([]models.DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	letters := []models.DeadLetter{}
+	for _, letter := range s.entries {
+		letters = append(letters, letter)
+	}
+	sort.Slice(letters, func(i, j int) bool {
+		return letters[i].CreatedAt.Before(letters[j].CreatedAt)
+	})
+	return letters, nil
+}
+
+func (s *memoryDLQStore) DeleteEntry(ctx context.Context, id uuid.UUID) (*models.DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	letter, ok := s.entries[id]
+	if !ok {
+		return nil, dlq.ErrNotFound
+	}
+	delete(s.entries, id)
+	return &letter, nil
+}
+
+func (s *memoryDLQStore) list(t *testing.T) []models.DeadLetter {
+	letters, err := s.ListEntries(context.Background())
+	require.NoError(t, err)
+	return letters
+}
+
+// runDLQProcessor runs a processor storing the dead letters of client in a memory
+// store and replaying them through handler until the test ends
+func runDLQProcessor(t *testing.T, client *nats.Client, handler nats.EventHandler) (*dlq.DLQProcessor, *memoryDLQStore) {
+	t.Helper()
+
+	store := newMemoryDLQStore()
+	processor := dlq.NewDLQProcessor(store, client, handler, logrus.New())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- processor.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	return processor, store
+}
+
+func TestDeploymentService_PublishesDeadLettersAfterRetries(t *testing.T) {
+	client := newConsumersClient(t, runJetStreamServer(t))
+	_, store := runDLQProcessor(t, client, &recordingEventHandler{})
+
+	// Neither TikTok nor LinkedIn is configured, so every attempt fails
+	deploymentService := service.NewDeploymentService(nil, nil, client, nil, nil, &config.DeploymentConfig{
+		MaxRetryAttempts: 2,
+		RetryDelay:       time.Millisecond,
+		Timeout:          time.Second,
+	}, logrus.New())
+
+	event := approvedAssetEvent()
+	event.Metadata.Platforms = []models.Platform{models.PlatformTikTok, models.PlatformLinkedIn}
+	require.NoError(t, deploymentService.HandleAssetStatusChanged(context.Background(), event))
+
+	require.Eventually(t, func() bool {
+		return len(store.list(t)) == 2
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// One dead letter per failed platform, each replaying only its platform
+	var platforms []models.Platform
+	for _, letter := range store.list(t) {
+		assert.Equal(t, event.AssetID, letter.AssetID)
+		assert.Equal(t, 2, letter.RetryCount)
+		assert.Contains(t, letter.FailureReason, "deployment failed after 2 attempts")
+
+		var dead models.AssetStatusChangedEvent
+		require.NoError(t, json.Unmarshal(letter.Event, &dead))
+		assert.Equal(t, models.AssetStatusApproved, dead.Status)
+		require.Len(t, dead.Metadata.Platforms, 1)
+		platforms = append(platforms, dead.Metadata.Platforms[0])
+	}
+	assert.ElementsMatch(t, []models.Platform{models.PlatformTikTok, models.PlatformLinkedIn}, platforms)
+}
+
+func TestDLQProcessor_ListsAndRetriesEntries(t *testing.T) {
+	client := newConsumersClient(t, runJetStreamServer(t))
+	handler := &recordingEventHandler{}
+	processor, store := runDLQProcessor(t, client, handler)
+
+	event := approvedAssetEvent()
+	require.NoError(t, client.PublishDeadLetter(context.Background(), event, "deployment failed after 3 attempts: timeout", 3))
+
+	require.Eventually(t, func() bool {
+		return len(store.list(t)) == 1
+	}, 5*time.Second, 20*time.Millisecond)
+
+	recorder := httptest.NewRecorder()
+	processor.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/dlq", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var listed struct {
+		Entries []models.DeadLetter `json:"entries"`
+	}
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&listed))
+	require.Len(t, listed.Entries, 1)
+	entry := listed.Entries[0]
+	assert.Equal(t, event.AssetID, entry.AssetID)
+	assert.Equal(t, "deployment failed after 3 attempts: timeout", entry.FailureReason)
+	assert.Equal(t, 3, entry.RetryCount)
+
+	recorder = httptest.NewRecorder()
+	processor.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/dlq/"+entry.ID.String()+"/retry", nil))
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+
+	// The event is replayed through the handler and the entry processed
+	require.Eventually(t, func() bool {
+		_, handled := handler.state()
+		return len(handled) == 1
+	}, 5*time.Second, 20*time.Millisecond)
+	_, handled := handler.state()
+	assert.Equal(t, []uuid.UUID{event.AssetID}, handled)
+	assert.Empty(t, store.list(t))
+
+	// Entries are only replayed once
+	recorder = httptest.NewRecorder()
+	processor.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/dlq/"+entry.ID.String()+"/retry", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestDLQProcessor_RestoresEntriesThatFailToReplay(t *testing.T) {
+	client := newConsumersClient(t, runJetStreamServer(t))
+	handler := &recordingEventHandler{failures: 1}
+	processor, store := runDLQProcessor(t, client, handler)
+
+	event := approvedAssetEvent()
+	require.NoError(t, client.PublishDeadLetter(context.Background(), event, "deployment failed after 3 attempts: timeout", 3))
+	require.Eventually(t, func() bool {
+		return len(store.list(t)) == 1
+	}, 5*time.Second, 20*time.Millisecond)
+
+	letter, err := processor.Retry(context.Background(), store.list(t)[0].ID)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		calls, _ := handler.state()
+		return calls == 1 && len(store.list(t)) == 1
+	}, 5*time.Second, 20*time.Millisecond)
+
+	restored := store.list(t)[0]
+	assert.Equal(t, event.AssetID, restored.AssetID)
+	assert.Equal(t, letter.FailureReason, restored.FailureReason)
+	assert.JSONEq(t, string(letter.Event), string(restored.Event))
+}
+
+func TestDLQProcessor_RejectsInvalidRequests(t *testing.T) {
+	processor := dlq.NewDLQProcessor(newMemoryDLQStore(), nil, &recordingEventHandler{}, logrus.New())
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		status int
+	}{
+		{http.MethodPost, "/dlq", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/dlq/" + uuid.NewString() + "/retry", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/dlq/not-a-uuid/retry", http.StatusBadRequest},
+		{http.MethodPost, "/dlq/" + uuid.NewString() + "/retry", http.StatusNotFound},
+		{http.MethodPost, "/dlq/" + uuid.NewString(), http.StatusNotFound},
+	} {
+		recorder := httptest.NewRecorder()
+		processor.ServeHTTP(recorder, httptest.NewRequest(tc.method, tc.path, nil))
+		assert.Equal(t, tc.status, recorder.Code, "%s %s", tc.method, tc.path)
+	}
+}
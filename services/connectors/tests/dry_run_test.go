@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/meta"
+	"github.com/zamc/connectors/internal/service"
+)
+
+// newDryRunMetaServer serves the Meta health check and delivery estimate,
+// counting every other request as one that would create something
+func newDryRunMetaServer(t *testing.T) (*httptest.Server, *int32) {
+	var writes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/me"):
+			w.Write([]byte(`{"id":"me"}`))
+		case strings.HasSuffix(r.URL.Path, "/delivery_estimate"):
+			w.Write([]byte(`{"data":[{
+				"daily_outcomes_curve": [{"spend": 0, "impressions": 0, "actions": 0}, {"spend": 10000, "impressions": 5000, "actions": 50}],
+				"estimate_mau_lower_bound": 100000,
+				"estimate_mau_upper_bound": 300000,
+				"estimate_ready": true
+			}]}`))
+		default:
+			atomic.AddInt32(&writes, 1)
+			w.Write([]byte(`{"id":"123456"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &writes
+}
+
+func dryRunAssetEvent() *models.AssetStatusChangedEvent {
+	event := approvedAssetEvent()
+	event.ContentType = models.ContentTypeSocialMedia
+	event.Mode = models.DeploymentModeDryRun
+	event.Metadata = models.Metadata{
+		Platforms: []models.Platform{models.PlatformMeta},
+		Budget:    50,
+		Demographics: models.Demographics{
+			AgeMin:    18,
+			AgeMax:    45,
+			Locations: []string{"US"},
+		},
+	}
+	return event
+}
+
+func TestDeploymentService_DryRunPublishesNothing(t *testing.T) {
+	server, writes := newDryRunMetaServer(t)
+	metaClient, err := meta.NewClient(&config.MetaConfig{AdAccountID: "123", APIVersion: "v18.0", BaseURL: server.URL}, logrus.New())
+	require.NoError(t, err)
+
+	natsServer := runJetStreamServer(t)
+	client := newConsumersClient(t, natsServer)
+
+	conn, err := natsgo.Connect(natsServer.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	var published int32
+	_, err = conn.Subscribe("zamc.>", func(msg *natsgo.Msg) {
+		atomic.AddInt32(&published, 1)
+	})
+	require.NoError(t, err)
+	require.NoError(t, conn.Flush())
+
+	deploymentService := service.NewDeploymentService(nil, metaClient, client, nil, nil, &config.DeploymentConfig{
+		MaxRetryAttempts: 1,
+		Timeout:          time.Second,
+	}, logrus.New())
+
+	require.NoError(t, deploymentService.HandleAssetStatusChanged(context.Background(), dryRunAssetEvent()))
+	require.NoError(t, conn.Flush())
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Zero(t, atomic.LoadInt32(&published), "events were published for a dry run")
+	assert.Zero(t, atomic.LoadInt32(writes), "Meta objects were created for a dry run")
+}
+
+func TestDeploymentService_DryRunReport(t *testing.T) {
+	server, writes := newDryRunMetaServer(t)
+	metaClient, err := meta.NewClient(&config.MetaConfig{AdAccountID: "123", APIVersion: "v18.0", BaseURL: server.URL}, logrus.New())
+	require.NoError(t, err)
+
+	deploymentService := service.NewDeploymentService(nil, metaClient, nil, nil, nil, &config.DeploymentConfig{
+		Timeout:            time.Second,
+		MaxDailyBudgetMeta: 100,
+	}, logrus.New())
+
+	event := dryRunAssetEvent()
+	report, err := deploymentService.DryRunDeployment(context.Background(), event)
+	require.NoError(t, err)
+
+	assert.True(t, report.Valid)
+	assert.Equal(t, event.AssetID, report.AssetID)
+	require.Len(t, report.Platforms, 1)
+
+	metaReport := report.Platforms[0]
+	assert.Equal(t, models.PlatformMeta, metaReport.Platform)
+	assert.True(t, metaReport.CredentialsValid)
+	assert.True(t, metaReport.CreativeComplete)
+	assert.True(t, metaReport.BudgetWithinRange)
+	assert.True(t, metaReport.TargetingValid)
+	assert.Empty(t, metaReport.Errors)
+	assert.Equal(t, int64(200000), metaReport.EstimatedAudienceSize)
+	require.NotNil(t, metaReport.CostEstimate)
+	assert.Zero(t, atomic.LoadInt32(writes))
+}
+
+func TestDeploymentService_DryRunReportsProblems(t *testing.T) {
+	server, _ := newDryRunMetaServer(t)
+	metaClient, err := meta.NewClient(&config.MetaConfig{AdAccountID: "123", APIVersion: "v18.0", BaseURL: server.URL}, logrus.New())
+	require.NoError(t, err)
+
+	deploymentService := service.NewDeploymentService(nil, metaClient, nil, nil, nil, &config.DeploymentConfig{
+		Timeout:            time.Second,
+		MaxDailyBudgetMeta: 100,
+	}, logrus.New())
+
+	event := dryRunAssetEvent()
+	event.ContentType = models.ContentTypeVideoScript
+	event.Metadata.Platforms = []models.Platform{models.PlatformMeta, models.PlatformGoogleAds}
+	event.Metadata.Budget = 500
+	event.Metadata.Demographics = models.Demographics{AgeMin: 10}
+
+	report, err := deploymentService.DryRunDeployment(context.Background(), event)
+	require.NoError(t, err)
+	assert.False(t, report.Valid)
+	require.Len(t, report.Platforms, 2)
+
+	metaReport := report.Platforms[0]
+	assert.False(t, metaReport.Valid)
+	assert.True(t, metaReport.CredentialsValid)
+	assert.False(t, metaReport.CreativeComplete)
+	assert.False(t, metaReport.BudgetWithinRange)
+	assert.False(t, metaReport.TargetingValid)
+	assert.ElementsMatch(t, []models.ValidationError{
+		{Field: "video_url", Message: "is required"},
+		{Field: "budget", Message: "must be at most the meta cap of 100.00"},
+		{Field: "demographics.age_min", Message: "must be at least 13"},
+		{Field: "demographics.locations", Message: "are required"},
+	}, metaReport.Errors)
+
+	// Google Ads is not configured, so it is not estimated
+	googleReport := report.Platforms[1]
+	assert.False(t, googleReport.CredentialsValid)
+	assert.Contains(t, googleReport.Errors, models.ValidationError{Field: "credentials", Message: "google_ads deployments are not configured"})
+	assert.Contains(t, googleReport.Errors, models.ValidationError{Field: "keywords", Message: "are required"})
+	assert.Nil(t, googleReport.CostEstimate)
+}
+
+func TestDeploymentService_DryRunRequiresPlatforms(t *testing.T) {
+	deploymentService := service.NewDeploymentService(nil, nil, nil, nil, nil, &config.DeploymentConfig{}, logrus.New())
+
+	event := dryRunAssetEvent()
+	event.Metadata.Platforms = nil
+	_, err := deploymentService.DryRunDeployment(context.Background(), event)
+	assert.Error(t, err)
+}
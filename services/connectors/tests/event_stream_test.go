@@ -0,0 +1,259 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/metrics"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/nats"
+)
+
+const statusChangedConsumer = "connectors_zamc_events_asset_status_changed"
+
+// recordingEventHandler records the assets it handles, failing the first failures calls
+type recordingEventHandler struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	handled  []uuid.UUID
+}
+
+func (h *recordingEventHandler) HandleAssetStatusChanged(ctx context.Context, event *models.AssetStatusChangedEvent) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.calls++
+	if h.calls <= h.failures {
+		return errors.New("deployment service unavailable")
+	}
+
+	h.handled = append(h.handled, event.AssetID)
+	return nil
+}
+
+func (h *recordingEventHandler) state() (int, []uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.calls, append([]uuid.UUID(nil), h.handled...)
+}
+
+func approvedAssetEvent() *models.AssetStatusChangedEvent {
+	return &models.AssetStatusChangedEvent{
+		EventType:  "asset.status_changed",
+		AssetID:    uuid.New(),
+		ProjectID:  uuid.New(),
+		Status:     models.AssetStatusApproved,
+		PrevStatus: models.AssetStatusReview,
+		Timestamp:  time.Now(),
+	}
+}
+
+// subscribeStatusChanged subscribes handler until the returned function is called
+// and waits for the durable consumer to exist
+func subscribeStatusChanged(t *testing.T, client *nats.Client, handler nats.EventHandler) (stop func()) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- client.SubscribeToAssetStatusChanged(ctx, handler) }()
+
+	require.Eventually(t, func() bool {
+		lag, err := client.EventStreamLag(context.Background())
+		return err == nil && len(lag.Consumers) == 1 && lag.Consumers[0].Name == statusChangedConsumer
+	}, 5*time.Second, 20*time.Millisecond)
+
+	return func() {
+		cancel()
+		require.NoError(t, <-done)
+	}
+}
+
+func TestEventStreamCreatedOnConnect(t *testing.T) {
+	s := runJetStreamServer(t)
+	newConsumersClient(t, s)
+
+	conn, err := natsgo.Connect(s.ClientURL())
+	require.NoError(t, err)
+	defer conn.Close()
+	js, err := conn.JetStream()
+	require.NoError(t, err)
+
+	info, err := js.StreamInfo(nats.EventsStream)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zamc.events.>"}, info.Config.Subjects)
+
+	// Connecting again reuses the stream
+	newConsumersClient(t, s)
+}
+
+func TestSubscribeToAssetStatusChanged_RedeliversFailedEvents(t *testing.T) {
+	client := newConsumersClient(t, runJetStreamServer(t))
+	handler := &recordingEventHandler{failures: 2}
+	defer subscribeStatusChanged(t, client, handler)()
+
+	event := approvedAssetEvent()
+	require.NoError(t, client.PublishAssetStatusChanged(context.Background(), event))
+
+	require.Eventually(t, func() bool {
+		_, handled := handler.state()
+		return len(handled) == 1
+	}, 5*time.Second, 20*time.Millisecond)
+
+	calls, handled := handler.state()
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []uuid.UUID{event.AssetID}, handled)
+
+	// The event is acknowledged once handled
+	require.Eventually(t, func() bool {
+		lag, err := client.EventStreamLag(context.Background())
+		return err == nil && lag.Pending == 0 && lag.AckPending == 0
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+// observations returns the number of durations observed by histogram under subject
+func observations(t *testing.T, histogram *prometheus.HistogramVec, subject string) uint64 {
+	t.Helper()
+
+	var m dto.Metric
+	require.NoError(t, histogram.WithLabelValues(subject).(prometheus.Metric).Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestSubscribeToAssetStatusChanged_ObservesDurations(t *testing.T) {
+	client := newConsumersClient(t, runJetStreamServer(t))
+	handler := &recordingEventHandler{}
+	defer subscribeStatusChanged(t, client, handler)()
+
+	subject := "zamc.events.asset.status_changed"
+	published := observations(t, metrics.NATSPublishDuration, subject)
+	handled := observations(t, metrics.NATSHandleDuration, subject)
+
+	require.NoError(t, client.PublishAssetStatusChanged(context.Background(), approvedAssetEvent()))
+
+	assert.Equal(t, published+1, observations(t, metrics.NATSPublishDuration, subject))
+	require.Eventually(t, func() bool {
+		return observations(t, metrics.NATSHandleDuration, subject) == handled+1
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestSubscribeToAssetStatusChanged_KeepsEventsWhileStopped(t *testing.T) {
+	client := newConsumersClient(t, runJetStreamServer(t))
+
+	stop := subscribeStatusChanged(t, client, &recordingEventHandler{})
+	stop()
+
+	first, second := approvedAssetEvent(), approvedAssetEvent()
+	require.NoError(t, client.PublishAssetStatusChanged(context.Background(), first))
+	require.NoError(t, client.PublishAssetStatusChanged(context.Background(), second))
+
+	lag, err := client.EventStreamLag(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, nats.EventsStream, lag.Stream)
+	assert.Equal(t, uint64(2), lag.Pending)
+
+	// Events published while nothing was subscribed are handled on restart
+	handler := &recordingEventHandler{}
+	defer subscribeStatusChanged(t, client, handler)()
+
+	require.Eventually(t, func() bool {
+		_, handled := handler.state()
+		return len(handled) == 2
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// Events are handled concurrently, in no particular order
+	_, handled := handler.state()
+	assert.ElementsMatch(t, []uuid.UUID{first.AssetID, second.AssetID}, handled)
+}
+
+func TestSubscribeToAssetStatusChanged_AcknowledgesIgnoredEvents(t *testing.T) {
+	client := newConsumersClient(t, runJetStreamServer(t))
+	handler := &recordingEventHandler{}
+	defer subscribeStatusChanged(t, client, handler)()
+
+	event := approvedAssetEvent()
+	event.Status = models.AssetStatusReview
+	require.NoError(t, client.PublishAssetStatusChanged(context.Background(), event))
+
+	require.Eventually(t, func() bool {
+		lag, err := client.EventStreamLag(context.Background())
+		return err == nil && lag.Pending == 0 && lag.AckPending == 0 &&
+			lag.Consumers[0].LastAckTime != nil
+	}, 5*time.Second, 20*time.Millisecond)
+
+	calls, _ := handler.state()
+	assert.Zero(t, calls)
+}
+
+func TestNewClient_CreatesStatusChangedConsumer(t *testing.T) {
+	s := runJetStreamServer(t)
+	client := newConsumersClient(t, s)
+
+	info, err := client.GetConsumerInfo()
+	require.NoError(t, err)
+	assert.Equal(t, statusChangedConsumer, info.Name)
+	assert.Equal(t, natsgo.AckExplicitPolicy, info.Config.AckPolicy)
+	assert.Equal(t, natsgo.DeliverAllPolicy, info.Config.DeliverPolicy)
+	assert.Equal(t, "connectors", info.Config.DeliverGroup)
+	assert.NotEmpty(t, info.Config.DeliverSubject)
+
+	// Events published before any instance subscribed are kept for the consumer
+	require.NoError(t, client.PublishAssetStatusChanged(context.Background(), approvedAssetEvent()))
+	info, err = client.GetConsumerInfo()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), info.NumPending)
+
+	// Connecting again keeps the consumer and its pending events
+	other := newConsumersClient(t, s)
+	info, err = other.GetConsumerInfo()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), info.NumPending)
+	assert.NoError(t, other.HealthCheck())
+}
+
+func TestDeleteEventConsumer(t *testing.T) {
+	client := newConsumersClient(t, runJetStreamServer(t))
+
+	require.NoError(t, client.DeleteEventConsumer())
+	_, err := client.GetConsumerInfo()
+	assert.ErrorIs(t, err, nats.ErrConsumerNotFound)
+
+	// The service is unhealthy until the consumer is created again
+	assert.Error(t, client.HealthCheck())
+	require.NoError(t, client.EnsureDurableConsumer(nats.EventsStream, statusChangedConsumer, "zamc.events.asset.status_changed"))
+	assert.NoError(t, client.HealthCheck())
+}
+
+func TestConsumerExporter(t *testing.T) {
+	client := newConsumersClient(t, runJetStreamServer(t))
+	require.NoError(t, client.PublishAssetStatusChanged(context.Background(), approvedAssetEvent()))
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(nats.NewConsumerExporter(client)))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	values := map[string]float64{}
+	for _, family := range families {
+		require.Len(t, family.GetMetric(), 1)
+		metric := family.GetMetric()[0]
+		assert.Equal(t, statusChangedConsumer, metric.GetLabel()[0].GetValue())
+		values[family.GetName()] = metric.GetGauge().GetValue()
+	}
+	assert.Equal(t, map[string]float64{
+		"zamc_connectors_nats_consumer_num_pending":     1,
+		"zamc_connectors_nats_consumer_num_ack_pending": 0,
+		"zamc_connectors_nats_consumer_num_redelivered": 0,
+	}, values)
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/health"
+)
+
+func newTestHealthRecorder(t *testing.T, check health.CheckFunc) (*health.HealthRecorder, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return health.NewHealthRecorder(client, "connectors", check, logrus.New()), server
+}
+
+func snapshotAt(instance string, at time.Time, services map[string]string) health.Snapshot {
+	status := health.StatusHealthy
+	for _, s := range services {
+		if s != health.StatusHealthy {
+			status = health.StatusUnhealthy
+		}
+	}
+
+	return health.Snapshot{Timestamp: at.UTC(), Instance: instance, Status: status, Services: services}
+}
+
+var (
+	allHealthy  = map[string]string{"google_ads": "healthy", "meta": "healthy", "nats": "healthy"}
+	metaFailing = map[string]string{"google_ads": "healthy", "meta": "unhealthy: timeout", "nats": "healthy"}
+)
+
+func TestHealthRecorder_Record(t *testing.T) {
+	services := allHealthy
+	recorder, server := newTestHealthRecorder(t, func(ctx context.Context) map[string]string {
+		return services
+	})
+	ctx := context.Background()
+
+	first, err := recorder.Record(ctx)
+	require.NoError(t, err)
+	assert.True(t, first.Healthy())
+
+	services = metaFailing
+	second, err := recorder.Record(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, health.StatusUnhealthy, second.Status)
+
+	members, err := server.ZMembers("health_timeline:connectors")
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+	assert.Equal(t, health.Retention, server.TTL("health_timeline:connectors"))
+
+	snapshots, err := recorder.History(ctx, time.Now().Add(-time.Minute), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, allHealthy, snapshots[0].Services)
+	assert.Equal(t, metaFailing, snapshots[1].Services)
+	assert.NotEmpty(t, snapshots[0].Instance)
+}
+
+func TestHealthRecorder_DropsSnapshotsPastRetention(t *testing.T) {
+	recorder, server := newTestHealthRecorder(t, nil)
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, recorder.Save(ctx, snapshotAt("a", now.Add(-health.Retention-time.Minute), allHealthy)))
+	require.NoError(t, recorder.Save(ctx, snapshotAt("a", now.Add(-health.Retention+time.Minute), allHealthy)))
+	require.NoError(t, recorder.Save(ctx, snapshotAt("a", now, allHealthy)))
+
+	members, err := server.ZMembers("health_timeline:connectors")
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+}
+
+func TestHealthRecorder_History(t *testing.T) {
+	recorder, _ := newTestHealthRecorder(t, nil)
+	ctx := context.Background()
+	start := time.Now().Add(-10 * time.Hour).Truncate(time.Second)
+
+	for i := 0; i < 250; i++ {
+		require.NoError(t, recorder.Save(ctx, snapshotAt("a", start.Add(time.Duration(i)*health.RecordInterval), allHealthy)))
+	}
+
+	// The range is inclusive and at most MaxHistory snapshots are returned, oldest first
+	snapshots, err := recorder.History(ctx, start.Add(10*health.RecordInterval), start.Add(19*health.RecordInterval))
+	require.NoError(t, err)
+	require.Len(t, snapshots, 10)
+	assert.True(t, snapshots[0].Timestamp.Equal(start.Add(10*health.RecordInterval)))
+
+	snapshots, err = recorder.History(ctx, start, start.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, snapshots, health.MaxHistory)
+	assert.True(t, snapshots[0].Timestamp.Equal(start))
+}
+
+func TestHealthRecorder_Incidents(t *testing.T) {
+	recorder, _ := newTestHealthRecorder(t, nil)
+	ctx := context.Background()
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	at := func(i int) time.Time { return start.Add(time.Duration(i) * health.RecordInterval) }
+
+	for i, services := range []map[string]string{allHealthy, metaFailing, metaFailing, allHealthy, allHealthy, metaFailing} {
+		require.NoError(t, recorder.Save(ctx, snapshotAt("a", at(i), services)))
+	}
+
+	incidents, err := recorder.Incidents(ctx, start, time.Now())
+	require.NoError(t, err)
+	require.Len(t, incidents, 2)
+
+	assert.True(t, incidents[0].StartedAt.Equal(at(1)))
+	require.NotNil(t, incidents[0].ResolvedAt)
+	assert.True(t, incidents[0].ResolvedAt.Equal(at(3)))
+	assert.Equal(t, map[string]string{"meta": "unhealthy: timeout"}, incidents[0].Services)
+
+	assert.True(t, incidents[1].StartedAt.Equal(at(5)))
+	assert.Nil(t, incidents[1].ResolvedAt)
+}
+
+func TestDetectIncidents(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	at := func(i int) time.Time { return start.Add(time.Duration(i) * health.RecordInterval) }
+
+	t.Run("instances are followed separately", func(t *testing.T) {
+		incidents := health.DetectIncidents([]health.Snapshot{
+			snapshotAt("a", at(0), allHealthy),
+			snapshotAt("b", at(0), allHealthy),
+			snapshotAt("a", at(1), allHealthy),
+			snapshotAt("b", at(1), metaFailing),
+			snapshotAt("a", at(2), allHealthy),
+			snapshotAt("b", at(2), allHealthy),
+		})
+
+		require.Len(t, incidents, 1)
+		assert.Equal(t, "b", incidents[0].Instance)
+		assert.Equal(t, at(1), incidents[0].StartedAt)
+		assert.Equal(t, at(2), *incidents[0].ResolvedAt)
+	})
+
+	t.Run("unhealthy from the first snapshot is not a transition", func(t *testing.T) {
+		incidents := health.DetectIncidents([]health.Snapshot{
+			snapshotAt("a", at(0), metaFailing),
+			snapshotAt("a", at(1), metaFailing),
+			snapshotAt("a", at(2), allHealthy),
+		})
+
+		assert.Empty(t, incidents)
+	})
+
+	t.Run("snapshots are ordered by time", func(t *testing.T) {
+		incidents := health.DetectIncidents([]health.Snapshot{
+			snapshotAt("a", at(2), allHealthy),
+			snapshotAt("a", at(1), metaFailing),
+			snapshotAt("a", at(0), allHealthy),
+		})
+
+		require.Len(t, incidents, 1)
+		assert.Equal(t, at(1), incidents[0].StartedAt)
+	})
+}
@@ -14,6 +14,9 @@ import (
 	"github.com/zamc/connectors/internal/config"
 	"github.com/zamc/connectors/internal/mocks"
 	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/googleads"
+	"github.com/zamc/connectors/internal/platforms/linkedin"
+	"github.com/zamc/connectors/internal/platforms/tiktok"
 	"github.com/zamc/connectors/internal/service"
 )
 
@@ -36,6 +39,8 @@ func TestDeploymentService_HandleAssetStatusChanged_Success(t *testing.T) {
 		mockGoogleAds,
 		mockMeta,
 		mockNATS,
+		nil,
+		nil,
 		deploymentConfig,
 		logger,
 	)
@@ -144,6 +149,8 @@ func TestDeploymentService_HandleAssetStatusChanged_PartialFailure(t *testing.T)
 		mockGoogleAds,
 		mockMeta,
 		mockNATS,
+		nil,
+		nil,
 		deploymentConfig,
 		logger,
 	)
@@ -206,6 +213,8 @@ func TestDeploymentService_HandleAssetStatusChanged_NonApprovedAsset(t *testing.
 		mockGoogleAds,
 		mockMeta,
 		mockNATS,
+		nil,
+		nil,
 		deploymentConfig,
 		logger,
 	)
@@ -268,6 +277,8 @@ func TestDeploymentService_RetryLogic(t *testing.T) {
 		mockGoogleAds,
 		mockMeta,
 		mockNATS,
+		nil,
+		nil,
 		deploymentConfig,
 		logger,
 	)
@@ -301,8 +312,10 @@ func TestDeploymentService_RetryLogic(t *testing.T) {
 	// Assert
 	require.NoError(t, err) // Service handles failures gracefully
 
-	// Verify retry logic was executed (should take at least retry attempts * retry delay)
-	expectedMinDuration := time.Duration(deploymentConfig.MaxRetryAttempts-1) * deploymentConfig.RetryDelay
+	// Verify the delay doubled between retries: 50ms then 100ms, on top of the
+	// three timed out attempts, rather than a fixed 50ms each time
+	expectedMinDuration := time.Duration(deploymentConfig.MaxRetryAttempts)*deploymentConfig.Timeout +
+		deploymentConfig.RetryDelay + 2*deploymentConfig.RetryDelay
 	assert.GreaterOrEqual(t, duration, expectedMinDuration)
 
 	// Verify no successful deployments due to timeout
@@ -330,6 +343,8 @@ func TestDeploymentService_HealthCheck(t *testing.T) {
 		mockGoogleAds,
 		mockMeta,
 		mockNATS,
+		nil,
+		nil,
 		deploymentConfig,
 		logger,
 	)
@@ -386,6 +401,8 @@ func TestDeploymentService_DifferentContentTypes(t *testing.T) {
 		mockGoogleAds,
 		mockMeta,
 		mockNATS,
+		nil,
+		nil,
 		deploymentConfig,
 		logger,
 	)
@@ -451,6 +468,361 @@ func TestDeploymentService_DifferentContentTypes(t *testing.T) {
 	}
 }
 
+func TestDeploymentService_GoogleAdsBiddingStrategies(t *testing.T) {
+	// Setup
+	logger := logrus.New()
+	mockGoogleAds := mocks.NewMockGoogleAdsClient()
+	mockGoogleAds.SetBiddingStrategies(config.BiddingStrategies{
+		models.ContentTypeBlogPost:    "MAXIMIZE_CLICKS",
+		models.ContentTypeSocialMedia: "TARGET_CPA",
+	})
+	mockNATS := mocks.NewMockNATSClient()
+
+	deploymentConfig := &config.DeploymentConfig{
+		MaxRetryAttempts: 1,
+		RetryDelay:       10 * time.Millisecond,
+		Timeout:          5 * time.Second,
+	}
+
+	deploymentService := service.NewDeploymentService(
+		mockGoogleAds,
+		nil,
+		mockNATS,
+		nil,
+		nil,
+		deploymentConfig,
+		logger,
+	)
+
+	targetCPA := 12.5
+	targetROAS := 4.0
+	tests := []struct {
+		name        string
+		contentType models.ContentType
+		targetCPA   *float64
+		targetROAS  *float64
+		expected    *googleads.CampaignBidding
+	}{
+		{
+			name:        "mapped strategy",
+			contentType: models.ContentTypeBlogPost,
+			expected:    &googleads.CampaignBidding{BiddingStrategyType: "TARGET_SPEND", TargetSpend: &googleads.TargetSpend{}},
+		},
+		{
+			name:        "mapped strategy with target override",
+			contentType: models.ContentTypeSocialMedia,
+			targetCPA:   &targetCPA,
+			expected:    &googleads.CampaignBidding{BiddingStrategyType: "TARGET_CPA", TargetCpa: &googleads.TargetCpa{TargetCpaMicros: 12500000}},
+		},
+		{
+			name:        "target without mapped strategy",
+			contentType: models.ContentTypeVideoScript,
+			targetROAS:  &targetROAS,
+			expected:    &googleads.CampaignBidding{BiddingStrategyType: "TARGET_ROAS", TargetRoas: &googleads.TargetRoas{TargetRoas: 4}},
+		},
+		{
+			name:        "campaign default",
+			contentType: models.ContentTypeEmailCampaign,
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGoogleAds.ClearDeployments()
+
+			event := &models.AssetStatusChangedEvent{
+				EventType:   "asset.status_changed",
+				AssetID:     uuid.New(),
+				ProjectID:   uuid.New(),
+				StrategyID:  uuid.New(),
+				Status:      models.AssetStatusApproved,
+				PrevStatus:  models.AssetStatusReview,
+				ContentType: tt.contentType,
+				Title:       fmt.Sprintf("Test %s", tt.contentType),
+				Metadata: models.Metadata{
+					Platforms:  []models.Platform{models.PlatformGoogleAds},
+					Keywords:   []string{"technology"},
+					TargetCPA:  tt.targetCPA,
+					TargetROAS: tt.targetROAS,
+					CreativeSpecs: models.CreativeSpecs{
+						VideoURL: "https://example.com/video.mp4",
+					},
+				},
+				Timestamp: time.Now(),
+			}
+
+			require.NoError(t, deploymentService.HandleAssetStatusChanged(ctx, event))
+
+			biddings := mockGoogleAds.GetCampaignBiddings()
+			require.Len(t, biddings, 1)
+			assert.Equal(t, tt.expected, biddings[0])
+		})
+	}
+}
+
+func TestDeploymentService_TikTokContentTypes(t *testing.T) {
+	// Setup
+	logger := logrus.New()
+	mockGoogleAds := mocks.NewMockGoogleAdsClient()
+	mockMeta := mocks.NewMockMetaClient()
+	mockTikTok := mocks.NewMockTikTokClient()
+	mockNATS := mocks.NewMockNATSClient()
+
+	deploymentConfig := &config.DeploymentConfig{
+		MaxRetryAttempts: 1,
+		RetryDelay:       10 * time.Millisecond,
+		Timeout:          5 * time.Second,
+	}
+
+	deploymentService := service.NewDeploymentService(
+		mockGoogleAds,
+		mockMeta,
+		mockNATS,
+		nil,
+		nil,
+		deploymentConfig,
+		logger,
+	)
+	deploymentService.SetTikTokClient(mockTikTok)
+
+	tests := []struct {
+		contentType models.ContentType
+		videoURL    string
+		adFormat    tiktok.AdFormat
+	}{
+		{models.ContentTypeVideoScript, "https://example.com/video.mp4", tiktok.AdFormatVideo},
+		{models.ContentTypeInfographic, "", tiktok.AdFormatImage},
+		{models.ContentTypeBlogPost, "", tiktok.AdFormatImage},
+		{models.ContentTypeSocialMedia, "", tiktok.AdFormatImage},
+		{models.ContentTypeSocialMedia, "https://example.com/video.mp4", tiktok.AdFormatVideo},
+	}
+
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s_%s", tt.contentType, tt.adFormat), func(t *testing.T) {
+			// Clear previous deployments
+			mockGoogleAds.ClearDeployments()
+			mockMeta.ClearDeployments()
+			mockTikTok.ClearDeployments()
+
+			event := &models.AssetStatusChangedEvent{
+				EventType:   "asset.status_changed",
+				AssetID:     uuid.New(),
+				ProjectID:   uuid.New(),
+				StrategyID:  uuid.New(),
+				Status:      models.AssetStatusApproved,
+				PrevStatus:  models.AssetStatusReview,
+				ContentType: tt.contentType,
+				Title:       fmt.Sprintf("Test %s", tt.contentType),
+				Content:     fmt.Sprintf("Test content for %s", tt.contentType),
+				Metadata: models.Metadata{
+					Platforms: []models.Platform{models.PlatformTikTok},
+					CreativeSpecs: models.CreativeSpecs{
+						ImageURL:   "https://example.com/image.jpg",
+						VideoURL:   tt.videoURL,
+						LandingURL: "https://example.com/landing",
+					},
+				},
+				Timestamp: time.Now(),
+			}
+
+			// Execute
+			err := deploymentService.HandleAssetStatusChanged(ctx, event)
+
+			// Assert
+			require.NoError(t, err)
+
+			// Verify the deployment went to TikTok only
+			tiktokDeployments := mockTikTok.GetDeployments()
+			require.Len(t, tiktokDeployments, 1)
+			assert.Empty(t, mockGoogleAds.GetDeployments())
+			assert.Empty(t, mockMeta.GetDeployments())
+
+			// Verify the content type selects the ad format
+			adFormat, err := tiktok.AdFormatFor(&tiktokDeployments[0])
+			require.NoError(t, err)
+			assert.Equal(t, tt.adFormat, adFormat)
+		})
+	}
+
+	// Email campaigns have no TikTok ad format
+	_, err := tiktok.AdFormatFor(&models.DeploymentRequest{ContentType: models.ContentTypeEmailCampaign})
+	assert.Error(t, err)
+}
+
+func TestDeploymentService_LinkedInContentTypes(t *testing.T) {
+	// Setup
+	logger := logrus.New()
+	mockGoogleAds := mocks.NewMockGoogleAdsClient()
+	mockMeta := mocks.NewMockMetaClient()
+	mockLinkedIn := mocks.NewMockLinkedInClient()
+	mockNATS := mocks.NewMockNATSClient()
+
+	deploymentConfig := &config.DeploymentConfig{
+		MaxRetryAttempts: 1,
+		RetryDelay:       10 * time.Millisecond,
+		Timeout:          5 * time.Second,
+	}
+
+	deploymentService := service.NewDeploymentService(
+		mockGoogleAds,
+		mockMeta,
+		mockNATS,
+		nil,
+		nil,
+		deploymentConfig,
+		logger,
+	)
+	deploymentService.SetLinkedInClient(mockLinkedIn)
+
+	tests := []struct {
+		contentType  models.ContentType
+		creativeType linkedin.CreativeType
+	}{
+		{models.ContentTypeSocialMedia, linkedin.CreativeTypeSingleImage},
+		{models.ContentTypeVideoScript, linkedin.CreativeTypeVideo},
+	}
+
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		t.Run(string(tt.contentType), func(t *testing.T) {
+			// Clear previous deployments
+			mockGoogleAds.ClearDeployments()
+			mockMeta.ClearDeployments()
+			mockLinkedIn.ClearDeployments()
+
+			event := &models.AssetStatusChangedEvent{
+				EventType:   "asset.status_changed",
+				AssetID:     uuid.New(),
+				ProjectID:   uuid.New(),
+				StrategyID:  uuid.New(),
+				Status:      models.AssetStatusApproved,
+				PrevStatus:  models.AssetStatusReview,
+				ContentType: tt.contentType,
+				Title:       fmt.Sprintf("Test %s", tt.contentType),
+				Content:     fmt.Sprintf("Test content for %s", tt.contentType),
+				Metadata: models.Metadata{
+					Platforms: []models.Platform{models.PlatformLinkedIn},
+					Budget:    50,
+					Demographics: models.Demographics{
+						Locations: []string{"US", "GB"},
+					},
+					CreativeSpecs: models.CreativeSpecs{
+						ImageURL:   "https://example.com/image.jpg",
+						VideoURL:   "https://example.com/video.mp4",
+						LandingURL: "https://example.com/landing",
+					},
+				},
+				Timestamp: time.Now(),
+			}
+
+			// Execute
+			err := deploymentService.HandleAssetStatusChanged(ctx, event)
+
+			// Assert
+			require.NoError(t, err)
+
+			// Verify the deployment went to LinkedIn only
+			linkedinDeployments := mockLinkedIn.GetDeployments()
+			require.Len(t, linkedinDeployments, 1)
+			assert.Empty(t, mockGoogleAds.GetDeployments())
+			assert.Empty(t, mockMeta.GetDeployments())
+
+			// Verify the content type selects the creative type
+			creativeType, err := linkedin.CreativeTypeFor(&linkedinDeployments[0])
+			require.NoError(t, err)
+			assert.Equal(t, tt.creativeType, creativeType)
+		})
+	}
+
+	// Other content types have no LinkedIn creative
+	for _, contentType := range []models.ContentType{models.ContentTypeBlogPost, models.ContentTypeInfographic, models.ContentTypeEmailCampaign} {
+		_, err := linkedin.CreativeTypeFor(&models.DeploymentRequest{ContentType: contentType})
+		assert.Error(t, err, contentType)
+	}
+}
+
+func TestDeploymentService_TwitterPromotedTweets(t *testing.T) {
+	// Setup
+	logger := logrus.New()
+	mockGoogleAds := mocks.NewMockGoogleAdsClient()
+	mockMeta := mocks.NewMockMetaClient()
+	mockTwitter := mocks.NewMockTwitterClient()
+	mockNATS := mocks.NewMockNATSClient()
+
+	deploymentConfig := &config.DeploymentConfig{
+		MaxRetryAttempts: 1,
+		RetryDelay:       10 * time.Millisecond,
+		Timeout:          5 * time.Second,
+	}
+
+	deploymentService := service.NewDeploymentService(
+		mockGoogleAds,
+		mockMeta,
+		mockNATS,
+		nil,
+		nil,
+		deploymentConfig,
+		logger,
+	)
+	deploymentService.SetTwitterClient(mockTwitter)
+
+	ctx := context.Background()
+
+	for _, contentType := range []models.ContentType{models.ContentTypeSocialMedia, models.ContentTypeVideoScript} {
+		t.Run(string(contentType), func(t *testing.T) {
+			// Clear previous deployments
+			mockGoogleAds.ClearDeployments()
+			mockMeta.ClearDeployments()
+			mockTwitter.ClearDeployments()
+
+			event := &models.AssetStatusChangedEvent{
+				EventType:   "asset.status_changed",
+				AssetID:     uuid.New(),
+				ProjectID:   uuid.New(),
+				StrategyID:  uuid.New(),
+				Status:      models.AssetStatusApproved,
+				PrevStatus:  models.AssetStatusReview,
+				ContentType: contentType,
+				Title:       fmt.Sprintf("Test %s", contentType),
+				Content:     fmt.Sprintf("Test content for %s", contentType),
+				Metadata: models.Metadata{
+					Platforms: []models.Platform{models.PlatformTwitter},
+					Budget:    50,
+					Demographics: models.Demographics{
+						AgeMin:    25,
+						AgeMax:    49,
+						Genders:   []string{"female"},
+						Locations: []string{"US"},
+					},
+					CreativeSpecs: models.CreativeSpecs{
+						TweetID: "1234567890",
+					},
+				},
+				Timestamp: time.Now(),
+			}
+
+			// Execute
+			err := deploymentService.HandleAssetStatusChanged(ctx, event)
+
+			// Assert
+			require.NoError(t, err)
+
+			// Verify the deployment went to Twitter only, promoting the tweet
+			twitterDeployments := mockTwitter.GetDeployments()
+			require.Len(t, twitterDeployments, 1)
+			assert.Empty(t, mockGoogleAds.GetDeployments())
+			assert.Empty(t, mockMeta.GetDeployments())
+			assert.Equal(t, "1234567890", twitterDeployments[0].Metadata.CreativeSpecs.TweetID)
+			assert.Equal(t, []string{"US"}, twitterDeployments[0].Metadata.Demographics.Locations)
+		})
+	}
+}
+
 func TestNATSEventFlow(t *testing.T) {
 	// Setup
 	logger := logrus.New()
@@ -468,6 +840,8 @@ func TestNATSEventFlow(t *testing.T) {
 		mockGoogleAds,
 		mockMeta,
 		mockNATS,
+		nil,
+		nil,
 		deploymentConfig,
 		logger,
 	)
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/platforms/googleads"
+)
+
+// keywordQualityPages are sample googleAds:search replies for an ad group with
+// three keywords, one of which has not been scored yet
+var keywordQualityPages = map[string]string{
+	"": `{
+		"results": [
+			{
+				"adGroupCriterion": {
+					"resourceName": "customers/1234567890/adGroupCriteria/777~101",
+					"criterionId": "101",
+					"keyword": {"text": "trail running shoes", "matchType": "BROAD"},
+					"qualityInfo": {"qualityScore": 7, "creativeQualityScore": "ABOVE_AVERAGE"}
+				}
+			},
+			{
+				"adGroupCriterion": {
+					"resourceName": "customers/1234567890/adGroupCriteria/777~102",
+					"criterionId": "102",
+					"keyword": {"text": "waterproof trail shoes", "matchType": "PHRASE"},
+					"qualityInfo": {}
+				}
+			}
+		],
+		"nextPageToken": "page-2",
+		"fieldMask": "adGroupCriterion.criterionId,adGroupCriterion.keyword.text,adGroupCriterion.qualityInfo.qualityScore"
+	}`,
+	"page-2": `{
+		"results": [
+			{
+				"adGroupCriterion": {
+					"resourceName": "customers/1234567890/adGroupCriteria/777~103",
+					"criterionId": "103",
+					"keyword": {"text": "ultralight running shoes", "matchType": "EXACT"},
+					"qualityInfo": {"qualityScore": 4}
+				}
+			}
+		]
+	}`,
+}
+
+func TestGoogleAdsClient_FetchKeywordQualityScores(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v16/customers/1234567890/googleAds:search", r.URL.Path)
+
+		var body struct {
+			Query     string `json:"query"`
+			PageToken string `json:"pageToken"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		queries = append(queries, body.Query)
+
+		page, ok := keywordQualityPages[body.PageToken]
+		require.True(t, ok, "unexpected page token %q", body.PageToken)
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	client, err := googleads.NewClient(&config.GoogleAdsConfig{
+		CustomerID: "123-456-7890",
+		BaseURL:    server.URL + "/v16",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	scores, err := client.FetchKeywordQualityScores(context.Background(), "777")
+	require.NoError(t, err)
+
+	require.Len(t, queries, 2)
+	assert.Contains(t, queries[0], "FROM ad_group_criterion WHERE ad_group_criterion.type = 'KEYWORD' AND ad_group.resource_name = 'customers/1234567890/adGroups/777'")
+	assert.Equal(t, queries[0], queries[1])
+
+	// The unscored keyword is left out
+	require.Len(t, scores, 2)
+	assert.Equal(t, "101", scores[0].KeywordID)
+	assert.Equal(t, "777", scores[0].AdGroupID)
+	assert.Equal(t, "trail running shoes", scores[0].Keyword)
+	assert.Equal(t, 7, scores[0].Score)
+	assert.False(t, scores[0].FetchedAt.IsZero())
+	assert.Equal(t, "103", scores[1].KeywordID)
+	assert.Equal(t, "ultralight running shoes", scores[1].Keyword)
+	assert.Equal(t, 4, scores[1].Score)
+}
+
+func TestGoogleAdsClient_FetchKeywordQualityScoresQuotesAdGroupID(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query string `json:"query"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		query = body.Query
+		w.Write([]byte(`{"results": []}`))
+	}))
+	defer server.Close()
+
+	client, err := googleads.NewClient(&config.GoogleAdsConfig{
+		CustomerID: "1234567890",
+		BaseURL:    server.URL + "/v16",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// IDs are opaque, like those of the mock clients
+	scores, err := client.FetchKeywordQualityScores(context.Background(), "adgroup_1700000000")
+	require.NoError(t, err)
+	assert.Empty(t, scores)
+	assert.Contains(t, query, "AND ad_group.resource_name = 'customers/1234567890/adGroups/adgroup_1700000000'")
+}
+
+func TestGoogleAdsClient_FetchKeywordQualityScoresReportsAPIErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"code": 400, "status": "INVALID_ARGUMENT"}}`))
+	}))
+	defer server.Close()
+
+	client, err := googleads.NewClient(&config.GoogleAdsConfig{
+		CustomerID: "1234567890",
+		BaseURL:    server.URL,
+	}, logrus.New())
+	require.NoError(t, err)
+
+	_, err = client.FetchKeywordQualityScores(context.Background(), "777")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to query keyword quality scores")
+}
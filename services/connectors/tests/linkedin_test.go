@@ -0,0 +1,297 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/linkedin"
+)
+
+// fakeLinkedInAPI serves the LinkedIn Marketing API and the media of the assets,
+// recording the requests made to them
+type fakeLinkedInAPI struct {
+	mu            sync.Mutex
+	calls         []string
+	bodies        map[string]map[string]interface{}
+	restliMethods map[string]string
+	uploads       map[string]string
+	versions      []string
+	failCreatives bool
+}
+
+func newFakeLinkedInAPI(t *testing.T) (*fakeLinkedInAPI, *httptest.Server, *linkedin.Client) {
+	t.Helper()
+
+	api := &fakeLinkedInAPI{
+		bodies:        make(map[string]map[string]interface{}),
+		restliMethods: make(map[string]string),
+		uploads:       make(map[string]string),
+	}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+
+		call := r.Method + " " + r.URL.RequestURI()
+		api.calls = append(api.calls, call)
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/media/"):
+			w.Write([]byte("0123456789"))
+			return
+		case strings.HasPrefix(r.URL.Path, "/upload/"):
+			data, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			api.uploads[r.URL.Path] = string(data)
+			w.Header().Set("ETag", "etag-"+strings.TrimPrefix(r.URL.Path, "/upload/"))
+			return
+		}
+
+		api.versions = append(api.versions, r.Header.Get("LinkedIn-Version"))
+		assert.Equal(t, "Bearer linkedin-token", r.Header.Get("Authorization"))
+
+		if r.Method == http.MethodPost {
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			api.bodies[call] = body
+			api.restliMethods[call] = r.Header.Get("X-RestLi-Method")
+		}
+
+		switch call {
+		case "POST /rest/images?action=initializeUpload":
+			w.Write([]byte(`{"value": {"uploadUrl": "` + server.URL + `/upload/image", "image": "urn:li:image:img-1"}}`))
+		case "POST /rest/videos?action=initializeUpload":
+			w.Write([]byte(`{"value": {"video": "urn:li:video:vid-1", "uploadToken": "token-1", "uploadInstructions": [
+				{"uploadUrl": "` + server.URL + `/upload/part-1", "firstByte": 0, "lastByte": 5},
+				{"uploadUrl": "` + server.URL + `/upload/part-2", "firstByte": 6, "lastByte": 9}
+			]}}`))
+		case "POST /rest/videos?action=finalizeUpload":
+		case "POST /rest/adAccounts/500/adCampaigns":
+			w.Header().Set("X-Restli-Id", "7001")
+			w.WriteHeader(http.StatusCreated)
+		case "POST /rest/posts":
+			w.Header().Set("X-Restli-Id", "urn:li:share:8001")
+			w.WriteHeader(http.StatusCreated)
+		case "POST /rest/adAccounts/500/creatives":
+			if api.failCreatives {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"status": 400, "code": "INVALID_ARGUMENT", "message": "Post is not eligible for sponsorship"}`))
+				return
+			}
+			w.Header().Set("X-Restli-Id", "urn:li:sponsoredCreative:9001")
+			w.WriteHeader(http.StatusCreated)
+		case "POST /rest/adAccounts/500/adCampaigns/7001":
+			w.WriteHeader(http.StatusNoContent)
+		case "GET /rest/adAccounts/500":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status": 401, "serviceErrorCode": 65600, "code": "REVOKED_ACCESS_TOKEN", "message": "The token used in the request has been revoked by the user"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := linkedin.NewClient(&config.LinkedInConfig{
+		AccessToken:      "linkedin-token",
+		AdAccountID:      "500",
+		CampaignGroupID:  "600",
+		OrganizationID:   "700",
+		DefaultLocations: []string{"US"},
+		Currency:         "USD",
+		BaseURL:          server.URL + "/rest",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	return api, server, client
+}
+
+func (a *fakeLinkedInAPI) body(t *testing.T, call string) map[string]interface{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	body, ok := a.bodies[call]
+	require.True(t, ok, "no request %s", call)
+	return body
+}
+
+// campaignLocations returns the geo URNs targeted by the created campaign
+func (a *fakeLinkedInAPI) campaignLocations(t *testing.T) []interface{} {
+	campaign := a.body(t, "POST /rest/adAccounts/500/adCampaigns")
+
+	include := campaign["targetingCriteria"].(map[string]interface{})["include"].(map[string]interface{})
+	and := include["and"].([]interface{})
+	require.Len(t, and, 1)
+	return and[0].(map[string]interface{})["or"].(map[string]interface{})["urn:li:adTargetingFacet:locations"].([]interface{})
+}
+
+func linkedinRequest(server *httptest.Server, contentType models.ContentType, locations []string) *models.DeploymentRequest {
+	return &models.DeploymentRequest{
+		AssetID:     uuid.New(),
+		Platform:    models.PlatformLinkedIn,
+		ContentType: contentType,
+		Title:       "Hiring platform",
+		Content:     "Hire engineers in days, not months",
+		Metadata: models.Metadata{
+			Budget:       25,
+			Demographics: models.Demographics{Locations: locations},
+			CreativeSpecs: models.CreativeSpecs{
+				ImageURL:     server.URL + "/media/image.jpg",
+				VideoURL:     server.URL + "/media/video.mp4",
+				LandingURL:   "https://example.com/hiring",
+				CallToAction: "Request Demo",
+			},
+		},
+	}
+}
+
+func TestLinkedInGeoURNs(t *testing.T) {
+	urns, err := linkedin.GeoURNs([]string{"us", "GB", "90000084", "urn:li:geo:102277331"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"urn:li:geo:103644278",
+		"urn:li:geo:101165590",
+		"urn:li:geo:90000084",
+		"urn:li:geo:102277331",
+	}, urns)
+
+	_, err = linkedin.GeoURNs([]string{"Atlantis"})
+	assert.ErrorContains(t, err, "Atlantis")
+}
+
+func TestLinkedInClient_DeploySingleImageAd(t *testing.T) {
+	api, server, client := newFakeLinkedInAPI(t)
+
+	result, err := client.DeployAsset(context.Background(), linkedinRequest(server, models.ContentTypeSocialMedia, []string{"US", "DE"}))
+	require.NoError(t, err)
+
+	assert.Equal(t, models.DeploymentStatusSuccess, result.Status)
+	assert.Equal(t, models.PlatformLinkedIn, result.Platform)
+	assert.Equal(t, "urn:li:sponsoredCreative:9001", result.PlatformID)
+	assert.Equal(t, "https://www.linkedin.com/campaignmanager/accounts/500/campaigns/7001/creatives", result.PlatformURL)
+
+	// The image is uploaded from its URL
+	assert.Equal(t, "urn:li:organization:700", api.body(t, "POST /rest/images?action=initializeUpload")["initializeUploadRequest"].(map[string]interface{})["owner"])
+	assert.Equal(t, "0123456789", api.uploads["/upload/image"])
+
+	campaign := api.body(t, "POST /rest/adAccounts/500/adCampaigns")
+	assert.Equal(t, "urn:li:sponsoredCampaignGroup:600", campaign["campaignGroup"])
+	assert.Equal(t, "STANDARD_UPDATE", campaign["format"])
+	assert.Equal(t, map[string]interface{}{"amount": "25.00", "currencyCode": "USD"}, campaign["dailyBudget"])
+	assert.Equal(t, []interface{}{"urn:li:geo:103644278", "urn:li:geo:101282230"}, api.campaignLocations(t))
+
+	// The campaign is created paused and activated once its creative exists
+	assert.Equal(t, "PAUSED", campaign["status"])
+	assert.Equal(t, "POST /rest/adAccounts/500/adCampaigns/7001", api.calls[len(api.calls)-1])
+	assert.Equal(t, "PARTIAL_UPDATE", api.restliMethods["POST /rest/adAccounts/500/adCampaigns/7001"])
+	assert.Equal(t, map[string]interface{}{"$set": map[string]interface{}{"status": "ACTIVE"}},
+		api.body(t, "POST /rest/adAccounts/500/adCampaigns/7001")["patch"])
+
+	post := api.body(t, "POST /rest/posts")
+	assert.Equal(t, "urn:li:organization:700", post["author"])
+	assert.Equal(t, "Hire engineers in days, not months", post["commentary"])
+	assert.Equal(t, map[string]interface{}{"id": "urn:li:image:img-1", "title": "Hiring platform"}, post["content"].(map[string]interface{})["media"])
+	assert.Equal(t, "REQUEST_DEMO", post["contentCallToActionLabel"])
+
+	creative := api.body(t, "POST /rest/adAccounts/500/creatives")
+	assert.Equal(t, "urn:li:sponsoredCampaign:7001", creative["campaign"])
+	assert.Equal(t, map[string]interface{}{"reference": "urn:li:share:8001"}, creative["content"])
+
+	for _, version := range api.versions {
+		assert.Equal(t, "202401", version)
+	}
+}
+
+func TestLinkedInClient_DeployVideoAd(t *testing.T) {
+	api, server, client := newFakeLinkedInAPI(t)
+
+	_, err := client.DeployAsset(context.Background(), linkedinRequest(server, models.ContentTypeVideoScript, nil))
+	require.NoError(t, err)
+
+	// The video is uploaded in the parts LinkedIn asked for
+	assert.Equal(t, float64(10), api.body(t, "POST /rest/videos?action=initializeUpload")["initializeUploadRequest"].(map[string]interface{})["fileSizeBytes"])
+	assert.Equal(t, "012345", api.uploads["/upload/part-1"])
+	assert.Equal(t, "6789", api.uploads["/upload/part-2"])
+	assert.Equal(t, map[string]interface{}{
+		"video":           "urn:li:video:vid-1",
+		"uploadToken":     "token-1",
+		"uploadedPartIds": []interface{}{"etag-part-1", "etag-part-2"},
+	}, api.body(t, "POST /rest/videos?action=finalizeUpload")["finalizeUploadRequest"])
+
+	campaign := api.body(t, "POST /rest/adAccounts/500/adCampaigns")
+	assert.Equal(t, "SINGLE_VIDEO", campaign["format"])
+	assert.Equal(t, "VIDEO_VIEW", campaign["objectiveType"])
+
+	// Assets without locations target the default locations
+	assert.Equal(t, []interface{}{"urn:li:geo:103644278"}, api.campaignLocations(t))
+
+	post := api.body(t, "POST /rest/posts")
+	assert.Equal(t, "urn:li:video:vid-1", post["content"].(map[string]interface{})["media"].(map[string]interface{})["id"])
+}
+
+func TestLinkedInClient_DeployRejectsUnknownLocations(t *testing.T) {
+	api, server, client := newFakeLinkedInAPI(t)
+
+	result, err := client.DeployAsset(context.Background(), linkedinRequest(server, models.ContentTypeSocialMedia, []string{"Atlantis"}))
+	require.Error(t, err)
+	assert.Equal(t, models.DeploymentStatusFailed, result.Status)
+	assert.Contains(t, result.Error, "Atlantis")
+
+	// Nothing is created in the ad account
+	assert.Empty(t, api.calls)
+}
+
+func TestLinkedInClient_DeployArchivesCampaignOnFailure(t *testing.T) {
+	api, server, client := newFakeLinkedInAPI(t)
+	api.failCreatives = true
+
+	result, err := client.DeployAsset(context.Background(), linkedinRequest(server, models.ContentTypeSocialMedia, nil))
+	require.Error(t, err)
+	assert.Equal(t, models.DeploymentStatusFailed, result.Status)
+	assert.Contains(t, result.Error, "Post is not eligible for sponsorship")
+
+	// The paused campaign is archived rather than left behind
+	assert.Equal(t, "PAUSED", api.body(t, "POST /rest/adAccounts/500/adCampaigns")["status"])
+	assert.Equal(t, map[string]interface{}{"$set": map[string]interface{}{"status": "ARCHIVED"}},
+		api.body(t, "POST /rest/adAccounts/500/adCampaigns/7001")["patch"])
+}
+
+func TestLinkedInClient_DeployRejectsOversizedMedia(t *testing.T) {
+	api, server, client := newFakeLinkedInAPI(t)
+
+	large := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "300000000")
+	}))
+	t.Cleanup(large.Close)
+
+	request := linkedinRequest(server, models.ContentTypeVideoScript, nil)
+	request.Metadata.CreativeSpecs.VideoURL = large.URL + "/video.mp4"
+
+	_, err := client.DeployAsset(context.Background(), request)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "larger than 200 MB")
+
+	// Nothing is created in the ad account
+	assert.Empty(t, api.calls)
+}
+
+func TestLinkedInClient_HealthCheckReportsAPIErrors(t *testing.T) {
+	_, _, client := newFakeLinkedInAPI(t)
+
+	err := client.HealthCheck(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "REVOKED_ACCESS_TOKEN")
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/meta"
+)
+
+// deployMetaAdSet deploys a social media ad with the given demographics and
+// returns the ad set creation payload
+func deployMetaAdSet(t *testing.T, demographics models.Demographics) map[string]interface{} {
+	return deployMetaAdSetRequest(t, &models.DeploymentRequest{
+		AssetID:     uuid.New(),
+		Platform:    models.PlatformMeta,
+		ContentType: models.ContentTypeSocialMedia,
+		Content:     "Trail shoes built for the mountains",
+		Metadata: models.Metadata{
+			Budget:       20,
+			Demographics: demographics,
+		},
+	})
+}
+
+// deployMetaAdSetRequest deploys request and returns the ad set creation payload
+func deployMetaAdSetRequest(t *testing.T, request *models.DeploymentRequest) map[string]interface{} {
+	var adSet map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/adsets") {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&adSet))
+		}
+		w.Write([]byte(`{"id": "123456"}`))
+	}))
+	defer server.Close()
+
+	client, err := meta.NewClient(&config.MetaConfig{
+		AdAccountID: "123",
+		APIVersion:  "v18.0",
+		BaseURL:     server.URL,
+	}, logrus.New())
+	require.NoError(t, err)
+
+	_, err = client.DeployAsset(context.Background(), request)
+	require.NoError(t, err)
+	require.NotNil(t, adSet, "no ad set was created")
+
+	return adSet
+}
+
+func TestMetaClient_AdvantagePlusAudience(t *testing.T) {
+	adSet := deployMetaAdSet(t, models.Demographics{
+		AgeMin:           21,
+		AgeMax:           55,
+		Genders:          []string{"female"},
+		Locations:        []string{"US", "CA"},
+		Interests:        []string{"hiking"},
+		Behaviors:        []string{"frequent travelers"},
+		UseAdvantagePlus: true,
+	})
+
+	assert.Empty(t, adSet["targeting"])
+
+	audience, ok := adSet["advantage_plus_audience"].(map[string]interface{})
+	require.True(t, ok, "advantage_plus_audience missing from %v", adSet)
+	assert.Equal(t, float64(21), audience["age_min"])
+	assert.Equal(t, float64(55), audience["age_max"])
+	assert.Equal(t, []interface{}{"US", "CA"}, audience["countries"])
+}
+
+func TestMetaClient_ManualTargeting(t *testing.T) {
+	adSet := deployMetaAdSet(t, models.Demographics{
+		AgeMin:    21,
+		AgeMax:    55,
+		Locations: []string{"US"},
+		Interests: []string{"hiking"},
+	})
+
+	assert.NotContains(t, adSet, "advantage_plus_audience")
+
+	targeting := adSet["targeting"].(map[string]interface{})
+	assert.Equal(t, float64(21), targeting["age_min"])
+	assert.Equal(t, []interface{}{"hiking"}, targeting["interests"])
+}
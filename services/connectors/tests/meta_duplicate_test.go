@@ -0,0 +1,257 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/platforms/meta"
+)
+
+// fakeGraphAPI serves a source campaign hierarchy and records every object created
+type fakeGraphAPI struct {
+	mu        sync.Mutex
+	campaigns []map[string]interface{}
+	adSets    []map[string]interface{}
+	creatives []url.Values
+	ads       []url.Values
+	deleted   []string
+	nextID    int
+
+	// failAdSet is the name of an ad set whose copy is refused
+	failAdSet string
+}
+
+func (f *fakeGraphAPI) newID(prefix string) string {
+	f.nextID++
+	return fmt.Sprintf("%s_%d", prefix, f.nextID)
+}
+
+func (f *fakeGraphAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v18.0")
+
+	switch {
+	case r.Method == "GET" && path == "/src_campaign":
+		writeTestJSON(w, map[string]interface{}{
+			"id": "src_campaign", "name": "Summer Sale", "objective": "LINK_CLICKS", "status": "ACTIVE",
+		})
+	case r.Method == "GET" && path == "/src_campaign/adsets":
+		writeTestJSON(w, map[string]interface{}{"data": []map[string]interface{}{
+			{"id": "src_adset_1", "name": "US", "daily_budget": "1000", "billing_event": "IMPRESSIONS", "optimization_goal": "LINK_CLICKS", "targeting": map[string]interface{}{"geo_locations": map[string]interface{}{"countries": []string{"US"}}}},
+			{"id": "src_adset_2", "name": "CA", "daily_budget": "3000", "billing_event": "IMPRESSIONS", "optimization_goal": "LINK_CLICKS", "targeting": map[string]interface{}{"geo_locations": map[string]interface{}{"countries": []string{"CA"}}}},
+		}})
+	case r.Method == "GET" && path == "/src_adset_1/ads":
+		writeTestJSON(w, map[string]interface{}{"data": []map[string]interface{}{
+			{"id": "src_ad_1", "name": "US Ad 1", "creative": map[string]interface{}{"name": "US Creative 1", "object_story_spec": map[string]interface{}{"page_id": "page_1"}}},
+			{"id": "src_ad_2", "name": "US Ad 2", "creative": map[string]interface{}{"name": "US Creative 2", "object_story_spec": map[string]interface{}{"page_id": "page_1"}}},
+		}})
+	case r.Method == "GET" && path == "/src_adset_2/ads":
+		writeTestJSON(w, map[string]interface{}{"data": []map[string]interface{}{
+			{"id": "src_ad_3", "name": "CA Ad", "creative": map[string]interface{}{"name": "CA Creative", "object_story_spec": map[string]interface{}{"page_id": "page_2"}}},
+		}})
+	case r.Method == "POST" && path == "/act_123/campaigns":
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		body["id"] = f.newID("campaign")
+		f.campaigns = append(f.campaigns, body)
+		writeTestJSON(w, map[string]interface{}{"id": body["id"]})
+	case r.Method == "POST" && path == "/act_123/adsets":
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["name"] == f.failAdSet {
+			http.Error(w, `{"error":{"message":"Invalid targeting","code":100}}`, http.StatusBadRequest)
+			return
+		}
+		body["id"] = f.newID("adset")
+		f.adSets = append(f.adSets, body)
+		writeTestJSON(w, map[string]interface{}{"id": body["id"]})
+	case r.Method == "POST" && path == "":
+		f.serveBatch(w, r)
+	case r.Method == "DELETE":
+		f.deleted = append(f.deleted, strings.TrimPrefix(path, "/"))
+		writeTestJSON(w, map[string]interface{}{"success": true})
+	default:
+		http.Error(w, "unexpected request "+r.Method+" "+r.URL.Path, http.StatusNotFound)
+	}
+}
+
+func (f *fakeGraphAPI) serveBatch(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var batch []struct {
+		Method      string `json:"method"`
+		RelativeURL string `json:"relative_url"`
+		Body        string `json:"body"`
+		Name        string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(r.PostForm.Get("batch")), &batch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	named := map[string]string{}
+	var responses []map[string]interface{}
+	for _, op := range batch {
+		body, _ := url.ParseQuery(op.Body)
+		id := ""
+		switch op.RelativeURL {
+		case "act_123/adcreatives":
+			id = f.newID("creative")
+			body.Set("id", id)
+			f.creatives = append(f.creatives, body)
+		case "act_123/ads":
+			// Resolve the JSONPath reference to a creative created earlier in the batch
+			creative := body.Get("creative")
+			for name, createdID := range named {
+				creative = strings.ReplaceAll(creative, fmt.Sprintf("{result=%s:$.id}", name), createdID)
+			}
+			body.Set("creative", creative)
+			id = f.newID("ad")
+			body.Set("id", id)
+			f.ads = append(f.ads, body)
+		}
+		if op.Name != "" {
+			named[op.Name] = id
+		}
+		responses = append(responses, map[string]interface{}{"code": 200, "body": fmt.Sprintf(`{"id":"%s"}`, id)})
+	}
+
+	writeTestJSON(w, responses)
+}
+
+func writeTestJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestMetaClient_DuplicateCampaign_CopiesHierarchy(t *testing.T) {
+	api := &fakeGraphAPI{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client, err := meta.NewClient(&config.MetaConfig{
+		AccessToken: "test_token",
+		AdAccountID: "123",
+		APIVersion:  "v18.0",
+		BaseURL:     server.URL,
+	}, logrus.New())
+	require.NoError(t, err)
+
+	newCampaignID, err := client.DuplicateCampaign(context.Background(), "src_campaign", "Summer Sale v2", 200)
+	require.NoError(t, err)
+
+	// Campaign copy
+	require.Len(t, api.campaigns, 1)
+	campaign := api.campaigns[0]
+	assert.Equal(t, newCampaignID, campaign["id"])
+	assert.Equal(t, "Summer Sale v2", campaign["name"])
+	assert.Equal(t, "LINK_CLICKS", campaign["objective"])
+	assert.Equal(t, "PAUSED", campaign["status"])
+
+	// Ad set copies belong to the new campaign and split the budget 1:3
+	require.Len(t, api.adSets, 2)
+	for _, adSet := range api.adSets {
+		assert.Equal(t, newCampaignID, adSet["campaign_id"])
+		assert.Equal(t, "PAUSED", adSet["status"])
+	}
+	assert.Equal(t, "US", api.adSets[0]["name"])
+	assert.Equal(t, float64(5000), api.adSets[0]["daily_budget"])
+	assert.Equal(t, "CA", api.adSets[1]["name"])
+	assert.Equal(t, float64(15000), api.adSets[1]["daily_budget"])
+
+	// Each creative is copied and each ad points at its own copied creative
+	require.Len(t, api.creatives, 3)
+	require.Len(t, api.ads, 3)
+
+	creativeIDs := map[string]url.Values{}
+	for _, creative := range api.creatives {
+		creativeIDs[creative.Get("id")] = creative
+	}
+
+	expected := []struct {
+		adName       string
+		adSetID      interface{}
+		creativeName string
+		pageID       string
+	}{
+		{"US Ad 1", api.adSets[0]["id"], "US Creative 1", "page_1"},
+		{"US Ad 2", api.adSets[0]["id"], "US Creative 2", "page_1"},
+		{"CA Ad", api.adSets[1]["id"], "CA Creative", "page_2"},
+	}
+
+	for i, want := range expected {
+		ad := api.ads[i]
+		assert.Equal(t, want.adName, ad.Get("name"))
+		assert.Equal(t, want.adSetID, ad.Get("adset_id"))
+		assert.Equal(t, "PAUSED", ad.Get("status"))
+
+		var ref struct {
+			CreativeID string `json:"creative_id"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(ad.Get("creative")), &ref))
+
+		creative, ok := creativeIDs[ref.CreativeID]
+		require.True(t, ok, "ad %q references unknown creative %q", want.adName, ref.CreativeID)
+		assert.Equal(t, want.creativeName, creative.Get("name"))
+		assert.JSONEq(t, fmt.Sprintf(`{"page_id":"%s"}`, want.pageID), creative.Get("object_story_spec"))
+	}
+}
+
+func TestMetaClient_DuplicateCampaign_RejectsInvalidBudget(t *testing.T) {
+	api := &fakeGraphAPI{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client, err := meta.NewClient(&config.MetaConfig{
+		AccessToken: "test_token",
+		AdAccountID: "123",
+		APIVersion:  "v18.0",
+		BaseURL:     server.URL,
+	}, logrus.New())
+	require.NoError(t, err)
+
+	_, err = client.DuplicateCampaign(context.Background(), "src_campaign", "Copy", 0)
+	assert.Error(t, err)
+	assert.Empty(t, api.campaigns)
+}
+
+func TestMetaClient_DuplicateCampaign_DeletesPartialCopy(t *testing.T) {
+	api := &fakeGraphAPI{failAdSet: "CA"}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client, err := meta.NewClient(&config.MetaConfig{
+		AccessToken: "test_token",
+		AdAccountID: "123",
+		APIVersion:  "v18.0",
+		BaseURL:     server.URL,
+	}, logrus.New())
+	require.NoError(t, err)
+
+	newCampaignID, err := client.DuplicateCampaign(context.Background(), "src_campaign", "Copy", 200)
+	assert.Error(t, err)
+	assert.Empty(t, newCampaignID)
+
+	// The first ad set was copied before the second one failed, so the whole
+	// copy is deleted
+	require.Len(t, api.campaigns, 1)
+	assert.Len(t, api.adSets, 1)
+	assert.Equal(t, []string{api.campaigns[0]["id"].(string)}, api.deleted)
+}
@@ -0,0 +1,398 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/mocks"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/notifications"
+	"github.com/zamc/connectors/internal/service"
+)
+
+// smtpMessage is an email received by the fake SMTP server
+type smtpMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+// fakeSMTPServer accepts every email sent to it, without TLS or authentication
+type fakeSMTPServer struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	messages []smtpMessage
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &fakeSMTPServer{listener: listener}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	reply("220 localhost ESMTP")
+	var message smtpMessage
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimSpace(line)
+		switch verb := strings.ToUpper(strings.SplitN(command, " ", 2)[0]); verb {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			message = smtpMessage{From: strings.Trim(strings.TrimPrefix(command, "MAIL FROM:"), "<>")}
+			reply("250 OK")
+		case "RCPT":
+			message.To = append(message.To, strings.Trim(strings.TrimPrefix(command, "RCPT TO:"), "<>"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			message.Data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, message)
+			s.mu.Unlock()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) Messages() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.messages...)
+}
+
+func (s *fakeSMTPServer) Config() *config.NotificationConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return &config.NotificationConfig{
+		EmailEnabled: true,
+		SMTPHost:     host,
+		SMTPPort:     portNumber,
+		EmailFrom:    "deployments@zamc.dev",
+		EmailTo:      []string{"ops@zamc.dev", "marketing@zamc.dev"},
+	}
+}
+
+// slackWebhook records the Block Kit messages posted to it
+type slackWebhook struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	messages []map[string]interface{}
+}
+
+func newSlackWebhook(t *testing.T) *slackWebhook {
+	webhook := &slackWebhook{}
+	webhook.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		webhook.mu.Lock()
+		webhook.messages = append(webhook.messages, message)
+		webhook.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(webhook.Close)
+	return webhook
+}
+
+func (w *slackWebhook) Messages() []map[string]interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]map[string]interface{}(nil), w.messages...)
+}
+
+func TestEmailNotifier_Send(t *testing.T) {
+	smtpServer := newFakeSMTPServer(t)
+	notifier := notifications.NewEmailNotifier(smtpServer.Config(), logrus.New())
+
+	event := notifications.NotificationEvent{
+		AssetID:   uuid.New(),
+		ProjectID: uuid.New(),
+		Title:     "Spring <sale> banner",
+		Platform:  models.PlatformMeta,
+		Status:    models.DeploymentStatusFailed,
+		Error:     "invalid meta creative: video_url is required",
+		Timestamp: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, notifier.Send(context.Background(), event))
+
+	messages := smtpServer.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "deployments@zamc.dev", messages[0].From)
+	assert.Equal(t, []string{"ops@zamc.dev", "marketing@zamc.dev"}, messages[0].To)
+
+	data := messages[0].Data
+	assert.Contains(t, data, "Subject: [ZAMC] Asset deployment to meta failed\r\n")
+	assert.Contains(t, data, "Content-Type: text/html; charset=UTF-8\r\n")
+	assert.Contains(t, data, event.AssetID.String())
+	assert.Contains(t, data, "invalid meta creative: video_url is required")
+	// The title is escaped in the HTML body
+	assert.Contains(t, data, "Spring &lt;sale&gt; banner")
+}
+
+func TestEmailNotifier_HandleSuspiciousLogin(t *testing.T) {
+	smtpServer := newFakeSMTPServer(t)
+	notifier := notifications.NewEmailNotifier(smtpServer.Config(), logrus.New())
+
+	event := &models.SuspiciousLoginEvent{
+		EventType: "suspicious_login",
+		UserID:    uuid.New().String(),
+		Email:     "jane@example.com",
+		Device:    "Pixel <8>",
+		IPAddress: "203.0.113.9",
+		UserAgent: "Mozilla/5.0",
+		Country:   "DE",
+		City:      "Berlin",
+		Timestamp: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, notifier.HandleSuspiciousLogin(context.Background(), event))
+
+	// Only the user is warned, not the deployment recipients
+	messages := smtpServer.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, []string{"jane@example.com"}, messages[0].To)
+
+	data := messages[0].Data
+	assert.Contains(t, data, "To: jane@example.com\r\n")
+	assert.Contains(t, data, "Subject: [ZAMC] New sign-in from an unrecognized device\r\n")
+	assert.Contains(t, data, "203.0.113.9")
+	assert.Contains(t, data, "Berlin, DE")
+	assert.Contains(t, data, "Pixel &lt;8&gt;")
+
+	event.Email = ""
+	assert.Error(t, notifier.HandleSuspiciousLogin(context.Background(), event))
+	assert.Len(t, smtpServer.Messages(), 1)
+}
+
+func TestEmailNotifier_ServerUnavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	portNumber, _ := strconv.Atoi(port)
+	notifier := notifications.NewEmailNotifier(&config.NotificationConfig{
+		SMTPHost:  host,
+		SMTPPort:  portNumber,
+		EmailFrom: "deployments@zamc.dev",
+		EmailTo:   []string{"ops@zamc.dev"},
+	}, logrus.New())
+
+	err = notifier.Send(context.Background(), notifications.NotificationEvent{AssetID: uuid.New()})
+	assert.ErrorContains(t, err, "failed to connect to SMTP server")
+}
+
+func TestSlackNotifier_SendRoutesByProject(t *testing.T) {
+	defaultWebhook := newSlackWebhook(t)
+	projectWebhook := newSlackWebhook(t)
+	routedProject := uuid.New()
+
+	notifier := notifications.NewSlackNotifier(&config.SlackConfig{WebhookURL: defaultWebhook.URL}, logrus.New())
+	notifier.SetProjectWebhooks(map[string]string{routedProject.String(): projectWebhook.URL})
+
+	event := notifications.NotificationEvent{
+		AssetID:     uuid.New(),
+		ProjectID:   routedProject,
+		Title:       "Trail shoes launch",
+		Platform:    models.PlatformGoogleAds,
+		Status:      models.DeploymentStatusSuccess,
+		PlatformURL: "https://ads.google.com/aw/ads?adId=123",
+	}
+	require.NoError(t, notifier.Send(context.Background(), event))
+
+	event.ProjectID = uuid.New()
+	event.Status = models.DeploymentStatusFailed
+	event.Error = "quota exceeded"
+	require.NoError(t, notifier.Send(context.Background(), event))
+
+	routed := projectWebhook.Messages()
+	require.Len(t, routed, 1)
+	assert.Contains(t, routed[0]["text"], "Asset deployed to google_ads")
+	blocks, err := json.Marshal(routed[0]["blocks"])
+	require.NoError(t, err)
+	assert.Contains(t, string(blocks), `"type":"section"`)
+	assert.Contains(t, string(blocks), "*Status*\\nsuccess")
+	assert.Contains(t, string(blocks), event.AssetID.String())
+	assert.Contains(t, string(blocks), "https://ads.google.com/aw/ads?adId=123")
+
+	fallback := defaultWebhook.Messages()
+	require.Len(t, fallback, 1)
+	assert.Contains(t, fallback[0]["text"], "failed")
+	blocks, err = json.Marshal(fallback[0]["blocks"])
+	require.NoError(t, err)
+	assert.Contains(t, string(blocks), "quota exceeded")
+}
+
+// failingChannel is a notification channel that is always down
+type failingChannel struct{}
+
+func (failingChannel) Send(ctx context.Context, event notifications.NotificationEvent) error {
+	return errors.New("channel down")
+}
+
+func TestNotificationService_SendsOnEveryChannel(t *testing.T) {
+	webhook := newSlackWebhook(t)
+	slackNotifier := notifications.NewSlackNotifier(&config.SlackConfig{WebhookURL: webhook.URL}, logrus.New())
+
+	notificationService := notifications.NewNotificationService(logrus.New(), failingChannel{}, slackNotifier)
+	err := notificationService.Send(context.Background(), notifications.NotificationEvent{
+		AssetID:  uuid.New(),
+		Platform: models.PlatformMeta,
+		Status:   models.DeploymentStatusSuccess,
+	})
+
+	assert.ErrorContains(t, err, "channel down")
+	assert.Len(t, webhook.Messages(), 1)
+}
+
+func TestDeploymentService_NotifiesDeploymentResults(t *testing.T) {
+	webhook := newSlackWebhook(t)
+	smtpServer := newFakeSMTPServer(t)
+	logger := logrus.New()
+
+	notificationService := notifications.NewNotificationService(logger,
+		notifications.NewSlackNotifier(&config.SlackConfig{WebhookURL: webhook.URL}, logger),
+		notifications.NewEmailNotifier(smtpServer.Config(), logger),
+	)
+
+	deploymentService := service.NewDeploymentService(mocks.NewMockGoogleAdsClient(), mocks.NewMockMetaClient(), mocks.NewMockNATSClient(), nil, nil, &config.DeploymentConfig{
+		MaxRetryAttempts: 3,
+		RetryDelay:       time.Millisecond,
+		Timeout:          time.Second,
+	}, logger)
+	deploymentService.SetNotificationService(notificationService)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go notificationService.Run(ctx)
+
+	// The Google Ads deployment succeeds; the Meta one fails validation
+	event := &models.AssetStatusChangedEvent{
+		EventType:   "asset.status_changed",
+		AssetID:     uuid.New(),
+		ProjectID:   uuid.New(),
+		Status:      models.AssetStatusApproved,
+		ContentType: models.ContentTypeVideoScript,
+		Title:       "Trail shoes launch",
+		Metadata: models.Metadata{
+			Platforms: []models.Platform{models.PlatformGoogleAds, models.PlatformMeta},
+			Budget:    50,
+			CreativeSpecs: models.CreativeSpecs{
+				Headline: "Trail shoes",
+			},
+		},
+	}
+	require.NoError(t, deploymentService.HandleAssetStatusChanged(context.Background(), event))
+
+	// The results are sent in the background
+	require.Eventually(t, func() bool {
+		return len(webhook.Messages()) == 2 && len(smtpServer.Messages()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	posted := webhook.Messages()
+	var texts []string
+	for _, message := range posted {
+		texts = append(texts, message["text"].(string))
+	}
+	assert.ElementsMatch(t, []string{
+		":white_check_mark: Asset deployed to google_ads: Trail shoes launch",
+		":x: Asset deployment to meta failed: Trail shoes launch",
+	}, texts)
+
+	emails := smtpServer.Messages()
+	for _, email := range emails {
+		assert.Contains(t, email.Data, event.AssetID.String())
+	}
+}
+
+// blockingChannel is a notification channel that waits for release before sending
+type blockingChannel struct {
+	release chan struct{}
+	sent    chan notifications.NotificationEvent
+}
+
+func (c *blockingChannel) Send(ctx context.Context, event notifications.NotificationEvent) error {
+	<-c.release
+	c.sent <- event
+	return nil
+}
+
+func TestNotificationService_NotifyDoesNotWaitForChannels(t *testing.T) {
+	channel := &blockingChannel{release: make(chan struct{}), sent: make(chan notifications.NotificationEvent, notifications.DefaultQueueSize+1)}
+	notificationService := notifications.NewNotificationService(logrus.New(), channel)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go notificationService.Run(ctx)
+
+	// The worker holds the first event while the others fill the queue
+	event := notifications.NotificationEvent{AssetID: uuid.New(), Status: models.DeploymentStatusSuccess}
+	require.NoError(t, notificationService.Notify(event))
+	require.Eventually(t, func() bool {
+		return notificationService.Notify(event) == notifications.ErrQueueFull
+	}, 5*time.Second, time.Millisecond)
+
+	close(channel.release)
+	select {
+	case sent := <-channel.sent:
+		assert.Equal(t, event.AssetID, sent.AssetID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued notification was not sent")
+	}
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/meta"
+	"github.com/zamc/connectors/internal/ratelimit"
+)
+
+func newTestRateLimiter(now *time.Time) *ratelimit.PlatformRateLimiter {
+	limiter := ratelimit.NewPlatformRateLimiter(30*time.Second, 2*time.Minute)
+	limiter.SetClock(func() time.Time { return *now })
+	return limiter
+}
+
+func TestPlatformRateLimiter_MetaUsageBackoff(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newTestRateLimiter(&now)
+
+	header := http.Header{}
+	header.Set(ratelimit.MetaUsageHeader, `{"123": [{"type": "ads_management", "call_count": 40, "total_cputime": 25, "total_time": 30}]}`)
+	limiter.Observe(models.PlatformMeta, "act_1", header)
+	require.NoError(t, limiter.Allow(models.PlatformMeta, "act_1"))
+
+	quotas := limiter.Quotas()
+	require.Len(t, quotas, 1)
+	assert.Equal(t, 60.0, quotas[0].RemainingPercent)
+	assert.False(t, quotas[0].Low())
+	assert.Nil(t, quotas[0].BackoffUntil)
+
+	// Below 10% left, the backoff doubles with every low response up to the maximum
+	header.Set(ratelimit.MetaUsageHeader, `{"123": [{"type": "ads_management", "call_count": 95, "total_cputime": 20, "total_time": 20}]}`)
+	for _, expected := range []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 2 * time.Minute} {
+		limiter.Observe(models.PlatformMeta, "act_1", header)
+
+		err := limiter.Allow(models.PlatformMeta, "act_1")
+		var quotaErr *ratelimit.QuotaError
+		require.ErrorAs(t, err, &quotaErr)
+		assert.True(t, errors.Is(err, ratelimit.ErrQuotaExhausted))
+		assert.Equal(t, expected, quotaErr.RetryAfter)
+	}
+
+	// Other accounts are not held back
+	assert.NoError(t, limiter.Allow(models.PlatformMeta, "act_2"))
+
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, limiter.Allow(models.PlatformMeta, "act_1"))
+
+	// Once the quota recovers the backoff starts over
+	header.Set(ratelimit.MetaUsageHeader, `{"123": [{"type": "ads_management", "call_count": 50}]}`)
+	limiter.Observe(models.PlatformMeta, "act_1", header)
+	header.Set(ratelimit.MetaUsageHeader, `{"123": [{"type": "ads_management", "call_count": 92}]}`)
+	limiter.Observe(models.PlatformMeta, "act_1", header)
+
+	var quotaErr *ratelimit.QuotaError
+	require.ErrorAs(t, limiter.Allow(models.PlatformMeta, "act_1"), &quotaErr)
+	assert.Equal(t, 30*time.Second, quotaErr.RetryAfter)
+}
+
+func TestPlatformRateLimiter_MetaRegainTime(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newTestRateLimiter(&now)
+
+	header := http.Header{}
+	header.Set(ratelimit.MetaUsageHeader, `{"123": [{"type": "ads_management", "call_count": 100, "estimated_time_to_regain_access": 10}]}`)
+	limiter.Observe(models.PlatformMeta, "act_1", header)
+
+	var quotaErr *ratelimit.QuotaError
+	require.ErrorAs(t, limiter.Allow(models.PlatformMeta, "act_1"), &quotaErr)
+	assert.Equal(t, 10*time.Minute, quotaErr.RetryAfter)
+}
+
+func TestPlatformRateLimiter_GoogleAdsQuota(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newTestRateLimiter(&now)
+
+	header := http.Header{}
+	header.Set(ratelimit.GoogleAdsQuotaHeader, "8.5")
+	limiter.Observe(models.PlatformGoogleAds, "1234567890", header)
+
+	// Responses without quota headers leave the quota as it was
+	limiter.Observe(models.PlatformGoogleAds, "1234567890", http.Header{})
+
+	quotas := limiter.Quotas()
+	require.Len(t, quotas, 1)
+	assert.Equal(t, models.PlatformGoogleAds, quotas[0].Platform)
+	assert.Equal(t, 8.5, quotas[0].RemainingPercent)
+	assert.True(t, quotas[0].Low())
+	require.NotNil(t, quotas[0].BackoffUntil)
+	assert.Equal(t, now.Add(30*time.Second), *quotas[0].BackoffUntil)
+
+	now = now.Add(time.Minute)
+	assert.Nil(t, limiter.Quotas()[0].BackoffUntil)
+}
+
+func TestPlatformRateLimiter_QuotaErrors(t *testing.T) {
+	assert.True(t, ratelimit.IsMetaRateLimitError([]byte(`{"error": {"message": "(#17) User request limit reached", "type": "OAuthException", "code": 17}}`)))
+	assert.False(t, ratelimit.IsMetaRateLimitError([]byte(`{"error": {"message": "Invalid parameter", "code": 100}}`)))
+	assert.False(t, ratelimit.IsMetaRateLimitError([]byte(`not json`)))
+
+	assert.True(t, ratelimit.IsGoogleAdsQuotaError([]byte(`{"error": {"code": 429, "message": "Resource has been exhausted", "status": "RESOURCE_EXHAUSTED"}}`)))
+	assert.False(t, ratelimit.IsGoogleAdsQuotaError([]byte(`{"error": {"code": 400, "status": "INVALID_ARGUMENT"}}`)))
+}
+
+func TestMetaClient_BacksOffOnUserRequestLimit(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"message": "(#17) User request limit reached", "type": "OAuthException", "code": 17}}`))
+	}))
+	defer server.Close()
+
+	client, err := meta.NewClient(&config.MetaConfig{
+		AccessToken: "token",
+		AdAccountID: "123",
+		APIVersion:  "v18.0",
+		BaseURL:     server.URL,
+	}, logrus.New())
+	require.NoError(t, err)
+
+	limiter := ratelimit.NewPlatformRateLimiter(time.Minute, 5*time.Minute)
+	client.SetRateLimiter(limiter)
+
+	_, err = client.GetAdReviewStatus(context.Background(), "120200000000009")
+	assert.True(t, errors.Is(err, ratelimit.ErrQuotaExhausted))
+
+	// The account backs off without calling the API again
+	_, err = client.GetAdReviewStatus(context.Background(), "120200000000009")
+	var quotaErr *ratelimit.QuotaError
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, "123", quotaErr.AccountID)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	quotas := limiter.Quotas()
+	require.Len(t, quotas, 1)
+	assert.Equal(t, 0.0, quotas[0].RemainingPercent)
+}
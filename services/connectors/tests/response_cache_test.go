@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/cache"
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/meta"
+)
+
+func TestPlatformResponseCache_GetSet(t *testing.T) {
+	c := cache.NewPlatformResponseCache()
+
+	_, ok := c.Get("meta:123:GET:ad_1")
+	assert.False(t, ok)
+
+	c.Set("meta:123:GET:ad_1", `{"id":"ad_1"}`, time.Minute)
+	value, ok := c.Get("meta:123:GET:ad_1")
+	require.True(t, ok)
+	assert.Equal(t, `{"id":"ad_1"}`, value)
+
+	// Values without a TTL are not cached, and expired values are not served
+	c.Set("meta:123:GET:ad_2", `{"id":"ad_2"}`, 0)
+	_, ok = c.Get("meta:123:GET:ad_2")
+	assert.False(t, ok)
+
+	c.Set("meta:123:GET:ad_3", `{"id":"ad_3"}`, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	_, ok = c.Get("meta:123:GET:ad_3")
+	assert.False(t, ok)
+
+	stats := c.Stats()
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(3), stats.Misses)
+	assert.Equal(t, 0.25, stats.HitRatio)
+}
+
+func TestPlatformResponseCache_Invalidate(t *testing.T) {
+	c := cache.NewPlatformResponseCache()
+	c.Set("meta:123:GET:https://graph.facebook.com/v18.0/ad_1", "1", time.Minute)
+	c.Set("meta:123:campaign:ZAMC-1", "campaign_1", time.Minute)
+	c.Set("meta:456:GET:https://graph.facebook.com/v18.0/ad_2", "2", time.Minute)
+
+	c.Invalidate("meta:123:GET:*")
+
+	_, ok := c.Get("meta:123:GET:https://graph.facebook.com/v18.0/ad_1")
+	assert.False(t, ok)
+	_, ok = c.Get("meta:123:campaign:ZAMC-1")
+	assert.True(t, ok)
+	_, ok = c.Get("meta:456:GET:https://graph.facebook.com/v18.0/ad_2")
+	assert.True(t, ok)
+}
+
+func TestPlatformResponseCache_Sweep(t *testing.T) {
+	c := cache.NewPlatformResponseCache()
+	c.Set("meta:123:GET:ad_1", "1", 10*time.Millisecond)
+	c.Set("meta:123:GET:ad_2", "2", time.Minute)
+	time.Sleep(20 * time.Millisecond)
+
+	// Expired entries are removed without being looked up
+	assert.Equal(t, 2, c.Len())
+	c.Sweep()
+	assert.Equal(t, 1, c.Len())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.Set("meta:123:GET:ad_3", "3", 10*time.Millisecond)
+	go c.Run(ctx, 5*time.Millisecond)
+	assert.Eventually(t, func() bool { return c.Len() == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestPlatformResponseCache_Nil(t *testing.T) {
+	var c *cache.PlatformResponseCache
+
+	c.Set("key", "value", time.Minute)
+	_, ok := c.Get("key")
+	assert.False(t, ok)
+	c.Invalidate("*")
+	c.Sweep()
+	assert.Equal(t, 0, c.Len())
+	assert.Equal(t, cache.Stats{}, c.Stats())
+}
+
+// countingGraphAPI answers every call with an ID and counts the calls made to
+// each method and path
+type countingGraphAPI struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (f *countingGraphAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[r.Method+" "+strings.TrimPrefix(r.URL.Path, "/v18.0")]++
+
+	if strings.HasSuffix(r.URL.Path, "/insights") {
+		w.Write([]byte(`{"data": [{"impressions": "100", "clicks": "5", "spend": "2.50"}]}`))
+		return
+	}
+	w.Write([]byte(`{"id": "123456"}`))
+}
+
+func (f *countingGraphAPI) count(call string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[call]
+}
+
+func newCachedMetaClient(t *testing.T, api http.Handler) (*meta.Client, *cache.PlatformResponseCache) {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	client, err := meta.NewClient(&config.MetaConfig{
+		AdAccountID:          "123",
+		APIVersion:           "v18.0",
+		BaseURL:              server.URL,
+		CacheGETResponsesTTL: time.Minute,
+	}, logrus.New())
+	require.NoError(t, err)
+
+	responseCache := cache.NewPlatformResponseCache()
+	client.SetResponseCache(responseCache)
+	return client, responseCache
+}
+
+func TestMetaClient_ReusesRecentlyCreatedCampaign(t *testing.T) {
+	api := &countingGraphAPI{calls: map[string]int{}}
+	client, _ := newCachedMetaClient(t, api)
+
+	request := &models.DeploymentRequest{
+		AssetID:     uuid.New(),
+		ProjectID:   uuid.New(),
+		StrategyID:  uuid.New(),
+		Platform:    models.PlatformMeta,
+		ContentType: models.ContentTypeSocialMedia,
+		Content:     "Trail shoes built for the mountains",
+		Metadata:    models.Metadata{Budget: 20},
+	}
+
+	// A retried deployment creates its ad set and ad again, but not its campaign
+	for i := 0; i < 2; i++ {
+		_, err := client.DeployAsset(context.Background(), request)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 1, api.count("POST /act_123/campaigns"))
+	assert.Equal(t, 2, api.count("POST /act_123/adsets"))
+}
+
+func TestMetaClient_CachesGETResponsesUntilRollback(t *testing.T) {
+	api := &countingGraphAPI{calls: map[string]int{}}
+	client, responseCache := newCachedMetaClient(t, api)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		metrics, err := client.FetchCampaignMetrics(ctx, "ad_1", "last_7d")
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), metrics.Impressions)
+	}
+	assert.Equal(t, 1, api.count("GET /ad_1/insights"))
+
+	// Pausing the ad drops the responses read before it
+	require.NoError(t, client.PauseAd(ctx, "ad_1"))
+	_, err := client.FetchCampaignMetrics(ctx, "ad_1", "last_7d")
+	require.NoError(t, err)
+	assert.Equal(t, 2, api.count("GET /ad_1/insights"))
+
+	stats := responseCache.Stats()
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
+}
+
+func TestMetaClient_DropsGETResponsesAfterEveryChange(t *testing.T) {
+	api := &countingGraphAPI{calls: map[string]int{}}
+	client, _ := newCachedMetaClient(t, api)
+	ctx := context.Background()
+
+	_, err := client.FetchCampaignMetrics(ctx, "ad_1", "last_7d")
+	require.NoError(t, err)
+
+	// Deployments create ad sets and ads, which may change the insights read
+	_, err = client.DeployAsset(ctx, &models.DeploymentRequest{
+		AssetID:     uuid.New(),
+		ProjectID:   uuid.New(),
+		StrategyID:  uuid.New(),
+		Platform:    models.PlatformMeta,
+		ContentType: models.ContentTypeSocialMedia,
+		Content:     "Trail shoes built for the mountains",
+		Metadata:    models.Metadata{Budget: 20},
+	})
+	require.NoError(t, err)
+
+	_, err = client.FetchCampaignMetrics(ctx, "ad_1", "last_7d")
+	require.NoError(t, err)
+	assert.Equal(t, 2, api.count("GET /ad_1/insights"))
+}
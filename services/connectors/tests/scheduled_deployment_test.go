@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/nats"
+	"github.com/zamc/connectors/internal/service"
+)
+
+const scheduledConsumer = "connectors_zamc_events_asset_scheduled"
+
+// subscribeScheduled subscribes handler to scheduled deployments until the test
+// ends and waits for the durable consumer to exist
+func subscribeScheduled(t *testing.T, client *nats.Client, handler nats.EventHandler) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- client.SubscribeToScheduledDeployments(ctx, handler) }()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	require.Eventually(t, func() bool {
+		lag, err := client.EventStreamLag(context.Background())
+		if err != nil {
+			return false
+		}
+		for _, consumer := range lag.Consumers {
+			if consumer.Name == scheduledConsumer {
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestDeploymentService_DefersScheduledDeployments(t *testing.T) {
+	client := newConsumersClient(t, runJetStreamServer(t))
+	handler := &recordingEventHandler{}
+	subscribeScheduled(t, client, handler)
+
+	// Deploying would fail, TikTok is not configured
+	deploymentService := service.NewDeploymentService(nil, nil, client, nil, nil, &config.DeploymentConfig{
+		MaxRetryAttempts: 1,
+		Timeout:          time.Second,
+	}, logrus.New())
+
+	scheduledAt := time.Now().Add(500 * time.Millisecond)
+	event := approvedAssetEvent()
+	event.ScheduledAt = &scheduledAt
+	event.Metadata.Platforms = []models.Platform{models.PlatformTikTok}
+	require.NoError(t, deploymentService.HandleAssetStatusChanged(context.Background(), event))
+
+	// The event comes back once it is due
+	time.Sleep(100 * time.Millisecond)
+	calls, _ := handler.state()
+	assert.Zero(t, calls)
+
+	require.Eventually(t, func() bool {
+		_, handled := handler.state()
+		return len(handled) == 1
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.False(t, time.Now().Before(scheduledAt))
+
+	_, handled := handler.state()
+	assert.Equal(t, []uuid.UUID{event.AssetID}, handled)
+}
+
+func TestPublishScheduledDeployment_RejectsDistantSchedules(t *testing.T) {
+	client := newConsumersClient(t, runJetStreamServer(t))
+
+	scheduledAt := time.Now().Add(nats.MaxScheduleAhead + time.Hour)
+	event := approvedAssetEvent()
+	event.ScheduledAt = &scheduledAt
+
+	err := client.PublishScheduledDeployment(context.Background(), event)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "scheduled more than")
+}
+
+func TestMetaClient_ScheduledAdSetStartTime(t *testing.T) {
+	scheduledAt := time.Date(2030, 6, 3, 9, 0, 0, 0, time.UTC)
+	adSet := deployMetaAdSetRequest(t, &models.DeploymentRequest{
+		AssetID:     uuid.New(),
+		Platform:    models.PlatformMeta,
+		ContentType: models.ContentTypeSocialMedia,
+		Content:     "Trail shoes built for the mountains",
+		Metadata:    models.Metadata{Budget: 20},
+		ScheduledAt: &scheduledAt,
+	})
+
+	assert.Equal(t, "2030-06-03T09:00:00+0000", adSet["start_time"])
+	assert.Equal(t, "2030-07-03T09:00:00+0000", adSet["end_time"])
+
+	// Unscheduled ad sets start right away
+	adSet = deployMetaAdSet(t, models.Demographics{})
+	assert.NotContains(t, adSet, "start_time")
+}
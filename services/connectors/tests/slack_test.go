@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/notifications"
+)
+
+func TestSlackNotifier_HandleAssetSLABreach(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := notifications.NewSlackNotifier(&config.SlackConfig{WebhookURL: server.URL}, logrus.New())
+
+	event := &models.AssetSLABreachEvent{
+		EventType:       "asset.sla_breach",
+		AssetID:         uuid.New(),
+		AssetName:       "Spring banner",
+		ProjectID:       uuid.New(),
+		ReviewStartedAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+		SLAHours:        48,
+		OverdueHours:    3.5,
+	}
+
+	require.NoError(t, notifier.HandleAssetSLABreach(context.Background(), event))
+	assert.Contains(t, received["text"], "Spring banner")
+	assert.Contains(t, received["text"], "48 hour SLA")
+	assert.Contains(t, received["text"], event.AssetID.String())
+}
+
+func TestSlackNotifier_WebhookError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	notifier := notifications.NewSlackNotifier(&config.SlackConfig{WebhookURL: server.URL}, logrus.New())
+
+	err := notifier.Notify(context.Background(), "hello")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+}
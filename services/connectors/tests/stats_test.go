@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/stats"
+)
+
+func newTestStatsCollector(t *testing.T) (*stats.StatsCollector, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return stats.NewStatsCollector(client), server
+}
+
+func deploymentResult(platform models.Platform, status models.DeploymentStatus, duration time.Duration) models.DeploymentResult {
+	return models.DeploymentResult{
+		Platform: platform,
+		Status:   status,
+		Metrics:  models.DeploymentMetrics{Duration: duration},
+	}
+}
+
+func TestStatsCollector_ConcurrentDeployments(t *testing.T) {
+	collector, _ := newTestStatsCollector(t)
+	ctx := context.Background()
+
+	const numDeployments = 100
+
+	var wg sync.WaitGroup
+	errs := make(chan error, numDeployments)
+
+	for i := 0; i < numDeployments; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+
+			platform := models.PlatformGoogleAds
+			if index%2 == 0 {
+				platform = models.PlatformMeta
+			}
+
+			status := models.DeploymentStatusSuccess
+			if index%4 == 0 {
+				status = models.DeploymentStatusFailed
+			}
+
+			errs <- collector.Record(ctx, deploymentResult(platform, status, 10*time.Millisecond))
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	current, err := collector.Stats(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(100), current.Total)
+	assert.Equal(t, int64(75), current.Success)
+	assert.Equal(t, int64(25), current.Failed)
+	assert.Equal(t, int64(1000), current.DurationMs)
+	assert.Equal(t, 10*time.Millisecond, current.AverageDuration())
+
+	assert.Equal(t, stats.PlatformStats{Total: 50, Success: 25}, current.Platforms[models.PlatformMeta])
+	assert.Equal(t, stats.PlatformStats{Total: 50, Success: 50}, current.Platforms[models.PlatformGoogleAds])
+	assert.Equal(t, 50.0, current.Platforms[models.PlatformMeta].SuccessRate())
+}
+
+func TestStatsCollector_UsesDocumentedKeys(t *testing.T) {
+	collector, server := newTestStatsCollector(t)
+	ctx := context.Background()
+
+	require.NoError(t, collector.Record(ctx, deploymentResult(models.PlatformMeta, models.DeploymentStatusSuccess, time.Second)))
+	require.NoError(t, collector.Record(ctx, deploymentResult(models.PlatformMeta, models.DeploymentStatusFailed, time.Second)))
+
+	for key, expected := range map[string]string{
+		"deployments:total":        "2",
+		"deployments:success":      "1",
+		"deployments:failed":       "1",
+		"deployments:meta:total":   "2",
+		"deployments:meta:success": "1",
+		"deployments:duration_ms":  "2000",
+	} {
+		value, err := server.Get(key)
+		require.NoError(t, err, key)
+		assert.Equal(t, expected, value, key)
+	}
+}
+
+func TestStatsCollector_Reset(t *testing.T) {
+	collector, server := newTestStatsCollector(t)
+	ctx := context.Background()
+
+	require.NoError(t, server.Set("unrelated", "keep"))
+	require.NoError(t, collector.Record(ctx, deploymentResult(models.PlatformGoogleAds, models.DeploymentStatusSuccess, time.Second)))
+
+	require.NoError(t, collector.Reset(ctx))
+
+	current, err := collector.Stats(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, current.Total)
+	assert.Zero(t, current.Platforms[models.PlatformGoogleAds].Total)
+	assert.True(t, server.Exists("unrelated"))
+
+	// Resetting empty counters is a no-op
+	assert.NoError(t, collector.Reset(ctx))
+}
+
+func TestExporter(t *testing.T) {
+	current := &stats.Stats{
+		Total:      3,
+		Success:    2,
+		Failed:     1,
+		DurationMs: 1500,
+		Platforms: map[models.Platform]stats.PlatformStats{
+			models.PlatformGoogleAds: {Total: 1, Success: 1},
+			models.PlatformMeta:      {Total: 2, Success: 1},
+		},
+	}
+	exporter := stats.NewExporter(func(ctx context.Context) (*stats.Stats, error) {
+		return current, nil
+	})
+
+	expected := `
+# HELP zamc_deployments_total Total number of deployments.
+# TYPE zamc_deployments_total counter
+zamc_deployments_total 3
+# HELP zamc_deployments_failed_total Number of failed deployments.
+# TYPE zamc_deployments_failed_total counter
+zamc_deployments_failed_total 1
+# HELP zamc_deployment_duration_milliseconds_total Cumulative deployment duration in milliseconds.
+# TYPE zamc_deployment_duration_milliseconds_total counter
+zamc_deployment_duration_milliseconds_total 1500
+# HELP zamc_platform_deployments_total Number of deployments per platform.
+# TYPE zamc_platform_deployments_total counter
+zamc_platform_deployments_total{platform="google_ads"} 1
+zamc_platform_deployments_total{platform="meta"} 2
+# HELP zamc_platform_deployments_success_total Number of successful deployments per platform.
+# TYPE zamc_platform_deployments_success_total counter
+zamc_platform_deployments_success_total{platform="google_ads"} 1
+zamc_platform_deployments_success_total{platform="meta"} 1
+# HELP zamc_platform_deployments_failed_total Number of failed deployments per platform.
+# TYPE zamc_platform_deployments_failed_total counter
+zamc_platform_deployments_failed_total{platform="google_ads"} 0
+zamc_platform_deployments_failed_total{platform="meta"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(exporter, strings.NewReader(expected),
+		"zamc_deployments_total",
+		"zamc_deployments_failed_total",
+		"zamc_deployment_duration_milliseconds_total",
+		"zamc_platform_deployments_total",
+		"zamc_platform_deployments_success_total",
+		"zamc_platform_deployments_failed_total",
+	))
+}
+
+func TestExporter_FailsScrapeWhenStatsUnavailable(t *testing.T) {
+	collector, server := newTestStatsCollector(t)
+	server.Close()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(stats.NewExporter(collector.Stats))
+
+	// Exporting zeros would read as counter resets
+	_, err := registry.Gather()
+	assert.ErrorContains(t, err, "failed to read deployment stats")
+}
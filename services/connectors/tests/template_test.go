@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zamc/connectors/internal/models"
+)
+
+func retargetingTemplate() *models.DeploymentTemplate {
+	return &models.DeploymentTemplate{
+		ID:          uuid.New(),
+		Name:        "Spring retargeting",
+		OwnerID:     uuid.New(),
+		Platform:    models.PlatformMeta,
+		ContentType: models.ContentTypeSocialMedia,
+		Metadata: models.Metadata{
+			Platforms:      []models.Platform{models.PlatformGoogleAds},
+			TargetAudience: "Returning visitors",
+			Budget:         40,
+			CampaignType:   "conversion",
+			Keywords:       []string{"spring sale"},
+			Demographics: models.Demographics{
+				AgeMin:    25,
+				AgeMax:    54,
+				Locations: []string{"US", "CA"},
+				Interests: []string{"outdoors"},
+			},
+			CreativeSpecs: models.CreativeSpecs{
+				ImageURL:     "https://cdn.example.com/template.png",
+				LogoURL:      "https://cdn.example.com/logo.png",
+				Headline:     "Spring is here",
+				CallToAction: "Shop now",
+				LandingURL:   "https://example.com/spring",
+				BusinessName: "Trailhead Co",
+				Dimensions:   map[string]string{"feed": "1080x1080", "story": "1080x1920"},
+			},
+		},
+	}
+}
+
+func TestDeploymentTemplate_ApplyFillsUnsetFields(t *testing.T) {
+	template := retargetingTemplate()
+	asset := models.AssetStatusChangedEvent{
+		AssetID:   uuid.New(),
+		ProjectID: uuid.New(),
+		Title:     "Spring launch banner",
+	}
+
+	event := template.Apply(asset)
+
+	assert.Equal(t, asset.AssetID, event.AssetID)
+	assert.Equal(t, asset.ProjectID, event.ProjectID)
+	assert.Equal(t, "Spring launch banner", event.Title)
+	assert.Equal(t, models.ContentTypeSocialMedia, event.ContentType)
+
+	metadata := event.Metadata
+	assert.Equal(t, []models.Platform{models.PlatformMeta}, metadata.Platforms)
+	assert.Equal(t, "Returning visitors", metadata.TargetAudience)
+	assert.Equal(t, 40.0, metadata.Budget)
+	assert.Equal(t, "conversion", metadata.CampaignType)
+	assert.Equal(t, []string{"spring sale"}, metadata.Keywords)
+	assert.Equal(t, template.Metadata.Demographics, metadata.Demographics)
+	assert.Equal(t, template.Metadata.CreativeSpecs, metadata.CreativeSpecs)
+}
+
+func TestDeploymentTemplate_ApplyAssetFieldsTakePriority(t *testing.T) {
+	template := retargetingTemplate()
+	asset := models.AssetStatusChangedEvent{
+		AssetID:     uuid.New(),
+		ContentType: models.ContentTypeInfographic,
+		Title:       "Spring launch banner",
+		Content:     "Everything for the trail, 20% off",
+		Metadata: models.Metadata{
+			Platforms: []models.Platform{models.PlatformGoogleAds},
+			Budget:    75,
+			Keywords:  []string{"trail shoes", "rain jackets"},
+			Demographics: models.Demographics{
+				AgeMax:    34,
+				Locations: []string{"GB"},
+			},
+			CreativeSpecs: models.CreativeSpecs{
+				ImageURL:   "https://cdn.example.com/banner.png",
+				Headline:   "20% off trail gear",
+				Dimensions: map[string]string{"story": "720x1280"},
+			},
+		},
+	}
+
+	event := template.Apply(asset)
+
+	// The template decides where and how the asset is deployed
+	assert.Equal(t, models.ContentTypeSocialMedia, event.ContentType)
+	assert.Equal(t, []models.Platform{models.PlatformMeta}, event.Metadata.Platforms)
+
+	assert.Equal(t, "Everything for the trail, 20% off", event.Content)
+
+	metadata := event.Metadata
+	assert.Equal(t, 75.0, metadata.Budget)
+	assert.Equal(t, []string{"trail shoes", "rain jackets"}, metadata.Keywords)
+	assert.Equal(t, "Returning visitors", metadata.TargetAudience)
+
+	// Demographics merge field by field
+	assert.Equal(t, 25, metadata.Demographics.AgeMin)
+	assert.Equal(t, 34, metadata.Demographics.AgeMax)
+	assert.Equal(t, []string{"GB"}, metadata.Demographics.Locations)
+	assert.Equal(t, []string{"outdoors"}, metadata.Demographics.Interests)
+
+	specs := metadata.CreativeSpecs
+	assert.Equal(t, "https://cdn.example.com/banner.png", specs.ImageURL)
+	assert.Equal(t, "20% off trail gear", specs.Headline)
+	assert.Equal(t, "https://cdn.example.com/logo.png", specs.LogoURL)
+	assert.Equal(t, "Shop now", specs.CallToAction)
+	assert.Equal(t, "https://example.com/spring", specs.LandingURL)
+	assert.Equal(t, "Trailhead Co", specs.BusinessName)
+	assert.Equal(t, map[string]string{"feed": "1080x1080", "story": "720x1280"}, specs.Dimensions)
+}
+
+func TestDeploymentTemplate_ApplyLeavesTemplateUnchanged(t *testing.T) {
+	template := retargetingTemplate()
+	asset := models.AssetStatusChangedEvent{
+		Metadata: models.Metadata{
+			CreativeSpecs: models.CreativeSpecs{Dimensions: map[string]string{"feed": "1200x628"}},
+		},
+	}
+
+	template.Apply(asset)
+
+	// Templates are reused across assets, so applying one must not modify it
+	assert.Equal(t, "1080x1080", template.Metadata.CreativeSpecs.Dimensions["feed"])
+	assert.Equal(t, []models.Platform{models.PlatformGoogleAds}, template.Metadata.Platforms)
+}
@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/tiktok"
+)
+
+// fakeTikTokAPI records the requests made to the TikTok Marketing API
+type fakeTikTokAPI struct {
+	mu       sync.Mutex
+	paths    []string
+	adCreate map[string]interface{}
+	tokens   []string
+}
+
+func newFakeTikTokAPI(t *testing.T) (*fakeTikTokAPI, *tiktok.Client) {
+	t.Helper()
+
+	api := &fakeTikTokAPI{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+
+		api.paths = append(api.paths, r.URL.Path)
+		api.tokens = append(api.tokens, r.Header.Get("Access-Token"))
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/file/image/ad/upload/"):
+			w.Write([]byte(`{"code": 0, "message": "OK", "data": {"image_id": "img-1"}}`))
+		case strings.HasSuffix(r.URL.Path, "/file/video/ad/upload/"):
+			w.Write([]byte(`{"code": 0, "message": "OK", "data": [{"video_id": "vid-1"}]}`))
+		case strings.HasSuffix(r.URL.Path, "/ad/create/"):
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&api.adCreate))
+			w.Write([]byte(`{"code": 0, "message": "OK", "data": {"ad_ids": ["ad-1"]}}`))
+		case strings.HasSuffix(r.URL.Path, "/advertiser/info/"):
+			w.Write([]byte(`{"code": 40105, "message": "Access token is incorrect or has been revoked.", "request_id": "req-1"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := tiktok.NewClient(&config.TikTokConfig{
+		AccessToken:  "tiktok-token",
+		AdvertiserID: "adv-1",
+		AdGroupID:    "group-1",
+		BaseURL:      server.URL + "/open_api/v1.3",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	return api, client
+}
+
+func (a *fakeTikTokAPI) creative(t *testing.T) map[string]interface{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	require.NotNil(t, a.adCreate, "no ad was created")
+	assert.Equal(t, "adv-1", a.adCreate["advertiser_id"])
+	assert.Equal(t, "group-1", a.adCreate["adgroup_id"])
+
+	creatives, ok := a.adCreate["creatives"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, creatives, 1)
+	return creatives[0].(map[string]interface{})
+}
+
+func tiktokRequest(contentType models.ContentType, specs models.CreativeSpecs) *models.DeploymentRequest {
+	return &models.DeploymentRequest{
+		AssetID:     uuid.New(),
+		Platform:    models.PlatformTikTok,
+		ContentType: contentType,
+		Title:       "Trail shoes",
+		Content:     "Trail shoes built for the mountains",
+		Metadata:    models.Metadata{Budget: 20, CreativeSpecs: specs},
+	}
+}
+
+func TestTikTokAdFormatFor(t *testing.T) {
+	tests := []struct {
+		contentType models.ContentType
+		videoURL    string
+		want        tiktok.AdFormat
+	}{
+		{models.ContentTypeVideoScript, "", tiktok.AdFormatVideo},
+		{models.ContentTypeInfographic, "", tiktok.AdFormatImage},
+		{models.ContentTypeBlogPost, "https://example.com/video.mp4", tiktok.AdFormatImage},
+		{models.ContentTypeSocialMedia, "", tiktok.AdFormatImage},
+		{models.ContentTypeSocialMedia, "https://example.com/video.mp4", tiktok.AdFormatVideo},
+	}
+
+	for _, tt := range tests {
+		format, err := tiktok.AdFormatFor(tiktokRequest(tt.contentType, models.CreativeSpecs{VideoURL: tt.videoURL}))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, format, "%s with video %q", tt.contentType, tt.videoURL)
+	}
+
+	_, err := tiktok.AdFormatFor(tiktokRequest(models.ContentTypeEmailCampaign, models.CreativeSpecs{}))
+	assert.Error(t, err)
+}
+
+func TestTikTokClient_DeployImageAd(t *testing.T) {
+	api, client := newFakeTikTokAPI(t)
+
+	result, err := client.DeployAsset(context.Background(), tiktokRequest(models.ContentTypeInfographic, models.CreativeSpecs{
+		ImageURL:     "https://example.com/image.jpg",
+		CallToAction: "Shop Now",
+		LandingURL:   "https://example.com/shoes",
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, models.DeploymentStatusSuccess, result.Status)
+	assert.Equal(t, models.PlatformTikTok, result.Platform)
+	assert.Equal(t, "ad-1", result.PlatformID)
+	assert.Equal(t, "group-1", result.AdGroupID)
+
+	creative := api.creative(t)
+	assert.Equal(t, "SINGLE_IMAGE", creative["ad_format"])
+	assert.Equal(t, []interface{}{"img-1"}, creative["image_ids"])
+	assert.Equal(t, "SHOP_NOW", creative["call_to_action"])
+	assert.Equal(t, "https://example.com/shoes", creative["landing_page_url"])
+	assert.Equal(t, "Trail shoes built for the mountains", creative["ad_text"])
+	assert.NotContains(t, creative, "video_id")
+
+	assert.Equal(t, []string{"/open_api/v1.3/file/image/ad/upload/", "/open_api/v1.3/ad/create/"}, api.paths)
+	assert.Equal(t, []string{"tiktok-token", "tiktok-token"}, api.tokens)
+}
+
+func TestTikTokClient_DeployVideoAd(t *testing.T) {
+	api, client := newFakeTikTokAPI(t)
+
+	_, err := client.DeployAsset(context.Background(), tiktokRequest(models.ContentTypeVideoScript, models.CreativeSpecs{
+		VideoURL: "https://example.com/video.mp4",
+		ImageURL: "https://example.com/cover.jpg",
+		Headline: "Built for the mountains",
+	}))
+	require.NoError(t, err)
+
+	creative := api.creative(t)
+	assert.Equal(t, "SINGLE_VIDEO", creative["ad_format"])
+	assert.Equal(t, "vid-1", creative["video_id"])
+	assert.Equal(t, []interface{}{"img-1"}, creative["image_ids"])
+	assert.Equal(t, "Built for the mountains", creative["ad_text"])
+}
+
+func TestTikTokClient_DeployRequiresMedia(t *testing.T) {
+	api, client := newFakeTikTokAPI(t)
+
+	result, err := client.DeployAsset(context.Background(), tiktokRequest(models.ContentTypeVideoScript, models.CreativeSpecs{
+		ImageURL: "https://example.com/image.jpg",
+	}))
+	require.Error(t, err)
+	assert.Equal(t, models.DeploymentStatusFailed, result.Status)
+	assert.Contains(t, result.Error, "video URL is required")
+	assert.Empty(t, api.paths)
+}
+
+func TestTikTokClient_HealthCheckReportsAPIErrors(t *testing.T) {
+	_, client := newFakeTikTokAPI(t)
+
+	err := client.HealthCheck(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "40105")
+}
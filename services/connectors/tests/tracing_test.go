@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/tracing"
+)
+
+// tracingEventHandler records the span context each event is handled in
+type tracingEventHandler struct {
+	handled chan trace.SpanContext
+}
+
+func (h *tracingEventHandler) HandleAssetStatusChanged(ctx context.Context, event *models.AssetStatusChangedEvent) error {
+	h.handled <- trace.SpanContextFromContext(ctx)
+	return nil
+}
+
+func TestSubscribeToAssetStatusChanged_ContinuesPublisherTrace(t *testing.T) {
+	shutdown, err := tracing.Setup(context.Background(), "connectors", &config.TracingConfig{})
+	require.NoError(t, err)
+	defer shutdown(context.Background())
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	client := newConsumersClient(t, runJetStreamServer(t))
+	handler := &tracingEventHandler{handled: make(chan trace.SpanContext, 1)}
+	defer subscribeStatusChanged(t, client, handler)()
+
+	ctx, parent := tracing.Tracer.Start(context.Background(), "graphql.request")
+	require.NoError(t, client.PublishAssetStatusChanged(ctx, approvedAssetEvent()))
+	parent.End()
+
+	var handled trace.SpanContext
+	select {
+	case handled = <-handler.handled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not handled")
+	}
+	assert.Equal(t, parent.SpanContext().TraceID(), handled.TraceID())
+
+	// The handler runs in a consumer span whose parent is the publish span
+	require.Eventually(t, func() bool { return len(recorder.Ended()) == 3 }, 5*time.Second, 20*time.Millisecond)
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	publish := spans["zamc.events.asset.status_changed publish"]
+	process := spans["zamc.events.asset.status_changed process"]
+	require.NotNil(t, publish)
+	require.NotNil(t, process)
+	assert.Equal(t, parent.SpanContext().SpanID(), publish.Parent().SpanID())
+	assert.Equal(t, publish.SpanContext().SpanID(), process.Parent().SpanID())
+	assert.Equal(t, trace.SpanKindConsumer, process.SpanKind())
+}
@@ -0,0 +1,266 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/twitter"
+)
+
+// fakeTwitterAPI serves the Twitter Ads API and the standard API, recording the
+// requests made to them
+type fakeTwitterAPI struct {
+	mu                 sync.Mutex
+	calls              []string
+	queries            map[string]url.Values
+	targeting          []map[string]interface{}
+	failPromotedTweets bool
+}
+
+func newFakeTwitterAPI(t *testing.T) (*fakeTwitterAPI, *twitter.Client) {
+	t.Helper()
+
+	api := &fakeTwitterAPI{queries: make(map[string]url.Values)}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+
+		call := r.Method + " " + r.URL.Path
+		api.calls = append(api.calls, call)
+		api.queries[call] = r.URL.Query()
+
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "OAuth "))
+		assert.Contains(t, r.Header.Get("Authorization"), `oauth_consumer_key="twitter-key"`)
+		assert.Contains(t, r.Header.Get("Authorization"), `oauth_token="twitter-token"`)
+
+		switch call {
+		case "GET /12/targeting_criteria/locations":
+			if r.URL.Query().Get("country_code") == "US" {
+				w.Write([]byte(`{"data": [{"name": "United States", "targeting_value": "96683cc9126741d1"}]}`))
+				return
+			}
+			w.Write([]byte(`{"data": []}`))
+		case "GET /12/targeting_criteria/interests":
+			w.Write([]byte(`{"data": [{"name": "Technology", "targeting_value": "19"}]}`))
+		case "POST /12/accounts/18ce54d4x5t/campaigns":
+			w.Write([]byte(`{"data": {"id": "camp-1"}}`))
+		case "POST /12/accounts/18ce54d4x5t/line_items":
+			w.Write([]byte(`{"data": {"id": "li-1"}}`))
+		case "POST /12/batch/accounts/18ce54d4x5t/targeting_criteria":
+			var operations []struct {
+				Params map[string]interface{} `json:"params"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&operations))
+			for _, operation := range operations {
+				api.targeting = append(api.targeting, operation.Params)
+			}
+			w.Write([]byte(`{"data": []}`))
+		case "POST /12/accounts/18ce54d4x5t/promoted_tweets":
+			if api.failPromotedTweets {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"errors": [{"code": "INVALID_PARAMETER", "message": "Tweet is not promotable"}]}`))
+				return
+			}
+			w.Write([]byte(`{"data": [{"id": "pt-1", "tweet_id": "1234567890"}]}`))
+		case "PUT /12/accounts/18ce54d4x5t/campaigns/camp-1", "DELETE /12/accounts/18ce54d4x5t/campaigns/camp-1":
+			w.Write([]byte(`{"data": {"id": "camp-1"}}`))
+		case "GET /1.1/account/verify_credentials.json":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"errors": [{"code": 89, "message": "Invalid or expired token."}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := twitter.NewClient(&config.TwitterConfig{
+		ConsumerKey:         "twitter-key",
+		ConsumerSecret:      "twitter-secret",
+		AccessToken:         "twitter-token",
+		AccessTokenSecret:   "twitter-token-secret",
+		AccountID:           "18ce54d4x5t",
+		FundingInstrumentID: "fi-1",
+		BaseURL:             server.URL + "/12",
+		APIBaseURL:          server.URL,
+	}, logrus.New())
+	require.NoError(t, err)
+
+	return api, client
+}
+
+func twitterRequest(demographics models.Demographics, tweetID string) *models.DeploymentRequest {
+	return &models.DeploymentRequest{
+		AssetID:     uuid.New(),
+		Platform:    models.PlatformTwitter,
+		ContentType: models.ContentTypeSocialMedia,
+		Title:       "Spring launch",
+		Content:     "Meet the new collection",
+		Metadata: models.Metadata{
+			Budget:        12.5,
+			Demographics:  demographics,
+			CreativeSpecs: models.CreativeSpecs{TweetID: tweetID},
+		},
+	}
+}
+
+func TestTwitterSignRequest(t *testing.T) {
+	// The example of Twitter's "Creating a signature" documentation
+	req, err := http.NewRequest(http.MethodPost, "https://api.twitter.com/1.1/statuses/update.json?"+url.Values{
+		"include_entities": {"true"},
+		"status":           {"Hello Ladies + Gentlemen, a signed OAuth request!"},
+	}.Encode(), nil)
+	require.NoError(t, err)
+
+	twitter.SignRequest(req, &config.TwitterConfig{
+		ConsumerKey:       "xvz1evFS4wEEPTGEFPHBog",
+		ConsumerSecret:    "kAcSOqF21Fu85e7zjz7ZN2U4ZRhfV3WpwPAoE3Z7kBw",
+		AccessToken:       "370773112-GmHxMAgYyLbNEtIKZeRNFsMKPR9EyMZeS9weJAEb",
+		AccessTokenSecret: "LswwdoUaIvS8ltyTt5jkRh4J50vUPVVHtR2YPi5kE",
+	}, "kYjzVBB8Y0ZFabxSWbWovY3uYSQ2pTgmZeNu2VS4cg", 1318622958)
+
+	authorization := req.Header.Get("Authorization")
+	assert.True(t, strings.HasPrefix(authorization, `OAuth oauth_consumer_key="xvz1evFS4wEEPTGEFPHBog", oauth_nonce=`))
+	assert.Contains(t, authorization, `oauth_signature="hCtSmYh%2BiHYCEqBWrE7C7hYmtUk%3D"`)
+}
+
+func TestTwitterAgeBucket(t *testing.T) {
+	tests := []struct {
+		min, max int
+		want     string
+	}{
+		{0, 0, ""},
+		{18, 24, "AGE_18_TO_24"},
+		{25, 45, "AGE_25_TO_49"},
+		{20, 30, "AGE_18_TO_34"},
+		{30, 0, "AGE_OVER_25"},
+		{55, 65, "AGE_OVER_50"},
+	}
+	for _, tt := range tests {
+		bucket, err := twitter.AgeBucket(tt.min, tt.max)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, bucket, "%d-%d", tt.min, tt.max)
+	}
+
+	_, err := twitter.AgeBucket(40, 30)
+	assert.Error(t, err)
+}
+
+func TestTwitterClient_DeployPromotedTweet(t *testing.T) {
+	api, client := newFakeTwitterAPI(t)
+
+	result, err := client.DeployAsset(context.Background(), twitterRequest(models.Demographics{
+		AgeMin:    18,
+		AgeMax:    34,
+		Genders:   []string{"female"},
+		Locations: []string{"US", "3c4a3b4f5e6d7a8b"},
+		Interests: []string{"Technology"},
+	}, "1234567890"))
+	require.NoError(t, err)
+
+	assert.Equal(t, models.DeploymentStatusSuccess, result.Status)
+	assert.Equal(t, models.PlatformTwitter, result.Platform)
+	assert.Equal(t, "pt-1", result.PlatformID)
+	assert.Equal(t, "camp-1", result.CampaignID)
+	assert.Equal(t, "li-1", result.AdGroupID)
+	assert.Equal(t, "https://ads.twitter.com/ads_manager/18ce54d4x5t/campaigns/camp-1", result.PlatformURL)
+
+	// The configured funding instrument funds the campaign's daily budget
+	campaign := api.queries["POST /12/accounts/18ce54d4x5t/campaigns"]
+	assert.Equal(t, "fi-1", campaign.Get("funding_instrument_id"))
+	assert.Equal(t, "12500000", campaign.Get("daily_budget_amount_local_micro"))
+
+	// The campaign is created paused and activated once its Promoted Tweet exists
+	assert.Equal(t, "PAUSED", campaign.Get("entity_status"))
+	assert.Equal(t, "PUT /12/accounts/18ce54d4x5t/campaigns/camp-1", api.calls[len(api.calls)-1])
+	assert.Equal(t, "ACTIVE", api.queries["PUT /12/accounts/18ce54d4x5t/campaigns/camp-1"].Get("entity_status"))
+
+	lineItem := api.queries["POST /12/accounts/18ce54d4x5t/line_items"]
+	assert.Equal(t, "camp-1", lineItem.Get("campaign_id"))
+	assert.Equal(t, "PROMOTED_TWEETS", lineItem.Get("product_type"))
+
+	assert.Equal(t, []map[string]interface{}{
+		{"line_item_id": "li-1", "targeting_type": "LOCATION", "targeting_value": "96683cc9126741d1"},
+		{"line_item_id": "li-1", "targeting_type": "LOCATION", "targeting_value": "3c4a3b4f5e6d7a8b"},
+		{"line_item_id": "li-1", "targeting_type": "INTEREST", "targeting_value": "19"},
+		{"line_item_id": "li-1", "targeting_type": "GENDER", "targeting_value": "2"},
+		{"line_item_id": "li-1", "targeting_type": "AGE", "targeting_value": "AGE_18_TO_34"},
+	}, api.targeting)
+
+	promotedTweet := api.queries["POST /12/accounts/18ce54d4x5t/promoted_tweets"]
+	assert.Equal(t, "li-1", promotedTweet.Get("line_item_id"))
+	assert.Equal(t, "1234567890", promotedTweet.Get("tweet_ids"))
+}
+
+func TestTwitterClient_DeployWithoutDemographics(t *testing.T) {
+	api, client := newFakeTwitterAPI(t)
+
+	_, err := client.DeployAsset(context.Background(), twitterRequest(models.Demographics{}, "1234567890"))
+	require.NoError(t, err)
+
+	// Without demographics the line item is not narrowed
+	assert.NotContains(t, api.calls, "POST /12/batch/accounts/18ce54d4x5t/targeting_criteria")
+}
+
+func TestTwitterClient_DeployDeletesCampaignOnFailure(t *testing.T) {
+	api, client := newFakeTwitterAPI(t)
+	api.failPromotedTweets = true
+
+	result, err := client.DeployAsset(context.Background(), twitterRequest(models.Demographics{}, "1234567890"))
+	require.Error(t, err)
+	assert.Equal(t, models.DeploymentStatusFailed, result.Status)
+	assert.Contains(t, result.Error, "Tweet is not promotable")
+
+	// The paused campaign is deleted rather than left behind, and never activated
+	assert.Contains(t, api.calls, "DELETE /12/accounts/18ce54d4x5t/campaigns/camp-1")
+	assert.NotContains(t, api.calls, "PUT /12/accounts/18ce54d4x5t/campaigns/camp-1")
+}
+
+func TestTwitterClient_RequiresFundingInstrument(t *testing.T) {
+	_, err := twitter.NewClient(&config.TwitterConfig{
+		ConsumerKey:       "twitter-key",
+		ConsumerSecret:    "twitter-secret",
+		AccessToken:       "twitter-token",
+		AccessTokenSecret: "twitter-token-secret",
+		AccountID:         "18ce54d4x5t",
+	}, logrus.New())
+	assert.EqualError(t, err, "Twitter funding instrument ID is required")
+}
+
+func TestTwitterClient_DeployValidatesBeforeCreating(t *testing.T) {
+	api, client := newFakeTwitterAPI(t)
+
+	result, err := client.DeployAsset(context.Background(), twitterRequest(models.Demographics{}, ""))
+	require.Error(t, err)
+	assert.Equal(t, models.DeploymentStatusFailed, result.Status)
+	assert.Contains(t, result.Error, "tweet ID")
+
+	_, err = client.DeployAsset(context.Background(), twitterRequest(models.Demographics{Locations: []string{"Atlantis"}}, "1234567890"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Atlantis")
+
+	// Only the location was looked up; nothing is created in the ads account
+	assert.Equal(t, []string{"GET /12/targeting_criteria/locations"}, api.calls)
+}
+
+func TestTwitterClient_HealthCheckReportsAPIErrors(t *testing.T) {
+	api, client := newFakeTwitterAPI(t)
+
+	err := client.HealthCheck(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid or expired token.")
+	assert.Equal(t, []string{"GET /1.1/account/verify_credentials.json"}, api.calls)
+}
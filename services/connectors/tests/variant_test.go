@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zamc/connectors/internal/config"
+	"github.com/zamc/connectors/internal/models"
+	"github.com/zamc/connectors/internal/platforms/googleads"
+	"github.com/zamc/connectors/internal/platforms/meta"
+	"github.com/zamc/connectors/internal/service"
+)
+
+func variantSpecs() models.CreativeSpecs {
+	return models.CreativeSpecs{
+		Headline:     "Trail shoes built for the mountains",
+		Description:  "Grip that holds on every descent",
+		LandingURL:   "https://example.com/trail",
+		VariantID:    "b",
+		VariantLabel: "Headline B",
+	}
+}
+
+func TestCreativeSpecs_VariantName(t *testing.T) {
+	assert.Equal(t, "Creative-1 [Headline B]", variantSpecs().VariantName("Creative-1"))
+	assert.Equal(t, "Creative-1", models.CreativeSpecs{}.VariantName("Creative-1"))
+}
+
+func TestGoogleAdsClient_BuildTextAdVariant(t *testing.T) {
+	client, err := googleads.NewClient(&config.GoogleAdsConfig{CustomerID: "1234567890"}, logrus.New())
+	require.NoError(t, err)
+
+	request := &models.DeploymentRequest{
+		AssetID:     uuid.MustParse("6f1c2a9e-0000-4000-8000-000000000000"),
+		Platform:    models.PlatformGoogleAds,
+		ContentType: models.ContentTypeSocialMedia,
+		Content:     "Trail shoes built for the mountains",
+		Metadata:    models.Metadata{CreativeSpecs: variantSpecs()},
+	}
+
+	ad := client.BuildTextAd(request)
+	assert.Equal(t, "TextAd-social_media-6f1c2a9e [Headline B]", ad.Name)
+	assert.Equal(t, map[string]string{googleads.VariantIDParameter: "b"}, ad.URLCustomParameters)
+
+	// Ads outside of A/B tests have neither
+	request.Metadata.CreativeSpecs = models.CreativeSpecs{Headline: "Trail shoes"}
+	ad = client.BuildTextAd(request)
+	assert.Equal(t, "TextAd-social_media-6f1c2a9e", ad.Name)
+	assert.Nil(t, ad.URLCustomParameters)
+}
+
+func TestMetaClient_CreativeNameCarriesVariant(t *testing.T) {
+	var creative map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/adcreatives") {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&creative))
+		}
+		w.Write([]byte(`{"id": "123456"}`))
+	}))
+	defer server.Close()
+
+	client, err := meta.NewClient(&config.MetaConfig{
+		AdAccountID: "123",
+		APIVersion:  "v18.0",
+		BaseURL:     server.URL,
+	}, logrus.New())
+	require.NoError(t, err)
+
+	_, err = client.DeployAsset(context.Background(), &models.DeploymentRequest{
+		AssetID:      uuid.MustParse("6f1c2a9e-0000-4000-8000-000000000000"),
+		Platform:     models.PlatformMeta,
+		ContentType:  models.ContentTypeSocialMedia,
+		Content:      "Trail shoes built for the mountains",
+		VariantGroup: "spring-trail-headlines",
+		Metadata: models.Metadata{
+			Budget:        20,
+			CreativeSpecs: variantSpecs(),
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, creative, "no creative was created")
+
+	assert.Equal(t, "Creative-social_media-6f1c2a9e [Headline B]", creative["name"])
+}
+
+func TestDeploymentService_DeploymentStatusCarriesVariant(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "123456"}`))
+	}))
+	defer server.Close()
+
+	metaClient, err := meta.NewClient(&config.MetaConfig{
+		AdAccountID: "123",
+		APIVersion:  "v18.0",
+		BaseURL:     server.URL,
+	}, logrus.New())
+	require.NoError(t, err)
+
+	natsServer := runJetStreamServer(t)
+	client := newConsumersClient(t, natsServer)
+
+	conn, err := natsgo.Connect(natsServer.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	statuses := make(chan *models.DeploymentStatusChangedEvent, 4)
+	_, err = conn.Subscribe("zamc.events.asset.status_changed", func(msg *natsgo.Msg) {
+		var event models.DeploymentStatusChangedEvent
+		if json.Unmarshal(msg.Data, &event) == nil && event.EventType == "asset.deployment_status_changed" {
+			statuses <- &event
+		}
+	})
+	require.NoError(t, err)
+	require.NoError(t, conn.Flush())
+
+	deploymentService := service.NewDeploymentService(nil, metaClient, client, nil, nil, &config.DeploymentConfig{
+		MaxRetryAttempts: 1,
+		RetryDelay:       time.Millisecond,
+		Timeout:          time.Second,
+	}, logrus.New())
+
+	event := approvedAssetEvent()
+	event.ContentType = models.ContentTypeSocialMedia
+	event.Content = "Trail shoes built for the mountains"
+	event.VariantGroup = "spring-trail-headlines"
+	event.Metadata = models.Metadata{
+		Platforms:     []models.Platform{models.PlatformMeta},
+		Budget:        20,
+		CreativeSpecs: variantSpecs(),
+	}
+	require.NoError(t, deploymentService.HandleAssetStatusChanged(context.Background(), event))
+
+	select {
+	case status := <-statuses:
+		assert.Equal(t, models.DeploymentStatusSuccess, status.DeploymentResult.Status)
+		assert.Equal(t, "spring-trail-headlines", status.VariantGroup)
+		assert.Equal(t, "b", status.VariantID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("deployment status was not published")
+	}
+}
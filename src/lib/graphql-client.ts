@@ -142,12 +142,20 @@ export const graphqlClient = new GraphQLClient();
 // Sample queries for demonstration
 export const SAMPLE_QUERIES = {
   GET_PROJECTS: `
-    query GetProjects {
-      projects {
-        id
-        name
-        status
-        createdAt
+    query GetProjects($first: Int! = 20, $after: String) {
+      projects(first: $first, after: $after) {
+        edges {
+          node {
+            id
+            name
+            status
+            createdAt
+          }
+        }
+        pageInfo {
+          hasNextPage
+          endCursor
+        }
       }
     }
   `,